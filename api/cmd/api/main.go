@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"log"
 	"log/slog"
 	"net/http"
@@ -11,11 +12,12 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.uber.org/dig"
 
+	"github.com/arfis/waiting-room/internal/auth"
 	"github.com/arfis/waiting-room/internal/cardreader"
 	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/encryption"
 	ngErrors "github.com/arfis/waiting-room/internal/errors"
 	"github.com/arfis/waiting-room/internal/middleware"
 	"github.com/arfis/waiting-room/internal/priority"
@@ -23,14 +25,30 @@ import (
 	"github.com/arfis/waiting-room/internal/repository"
 	"github.com/arfis/waiting-room/internal/rest"
 	adminHandler "github.com/arfis/waiting-room/internal/rest/handler/admin"
+	analyticsHandler "github.com/arfis/waiting-room/internal/rest/handler/analytics"
+	appointmentHandler "github.com/arfis/waiting-room/internal/rest/handler/appointment"
+	auditHandler "github.com/arfis/waiting-room/internal/rest/handler/audit"
+	cardreaderHandler "github.com/arfis/waiting-room/internal/rest/handler/cardreader"
 	configHandler "github.com/arfis/waiting-room/internal/rest/handler/configuration"
+	fhirHandler "github.com/arfis/waiting-room/internal/rest/handler/fhir"
+	i18nHandler "github.com/arfis/waiting-room/internal/rest/handler/i18n"
+	integrationHandler "github.com/arfis/waiting-room/internal/rest/handler/integration"
 	kioskHandler "github.com/arfis/waiting-room/internal/rest/handler/kiosk"
 	queueHandler "github.com/arfis/waiting-room/internal/rest/handler/queue"
 	servicepointHandler "github.com/arfis/waiting-room/internal/rest/handler/servicepoint"
 	adminService "github.com/arfis/waiting-room/internal/service/admin"
+	analyticsService "github.com/arfis/waiting-room/internal/service/analytics"
+	announcementService "github.com/arfis/waiting-room/internal/service/announcement"
+	appointmentService "github.com/arfis/waiting-room/internal/service/appointment"
+	auditService "github.com/arfis/waiting-room/internal/service/audit"
+	cardreaderService "github.com/arfis/waiting-room/internal/service/cardreader"
 	configService "github.com/arfis/waiting-room/internal/service/config"
 	configurationService "github.com/arfis/waiting-room/internal/service/configuration"
+	fhirService "github.com/arfis/waiting-room/internal/service/fhir"
+	i18nService "github.com/arfis/waiting-room/internal/service/i18n"
+	integrationService "github.com/arfis/waiting-room/internal/service/integration"
 	kioskService "github.com/arfis/waiting-room/internal/service/kiosk"
+	notificationService "github.com/arfis/waiting-room/internal/service/notification"
 	priorityService "github.com/arfis/waiting-room/internal/service/priority"
 	queueServiceGenerated "github.com/arfis/waiting-room/internal/service/queue"
 	servicepointService "github.com/arfis/waiting-room/internal/service/servicepoint"
@@ -62,21 +80,123 @@ func DIContainer(cfg *config.Config) *dig.Container {
 			return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
 		}},
 
-		// Repository - try MongoDB first, fallback to mock
-		{Constructor: func() repository.QueueRepository {
+		// PostgreSQL, only opened with database.driver: postgres
+		{Constructor: func() *sql.DB {
+			if cfg.GetDatabaseDriver() != config.DatabaseDriverPostgres {
+				return nil
+			}
+			db, err := repository.OpenPostgres(cfg.GetPostgresDSN())
+			if err != nil {
+				log.Printf("Failed to connect to PostgreSQL: %v", err)
+				return nil
+			}
+			log.Println("Connected to PostgreSQL successfully")
+			return db
+		}},
+
+		// Embedded mode, only opened with database.driver: embedded
+		{Constructor: func() *repository.EmbeddedQueueRepository {
+			if cfg.GetDatabaseDriver() != config.DatabaseDriverEmbedded {
+				return nil
+			}
+			embedded := cfg.Database.Embedded
+			repo, err := repository.NewEmbeddedQueueRepository(embedded.SnapshotFile, time.Duration(embedded.SnapshotIntervalSeconds)*time.Second)
+			if err != nil {
+				log.Fatalf("Failed to open embedded snapshot: %v", err)
+			}
+			log.Printf("Running embedded, snapshotting to %s every %ds", embedded.SnapshotFile, embedded.SnapshotIntervalSeconds)
+			return repo
+		}},
+
+		// Encryption of the personal card data at rest, only with a master key configured
+		{Constructor: func(pg *sql.DB, embedded *repository.EmbeddedQueueRepository) *encryption.Keyring {
+			encryptionConfig := cfg.Privacy.Encryption
+			if !encryptionConfig.Enabled() {
+				return nil
+			}
+			if embedded != nil {
+				log.Fatalf("privacy.encryption is configured, but the embedded database can't encrypt card data in its snapshot; use mongodb or postgres, or remove the encryption settings")
+			}
+
+			var master encryption.MasterKey
+			if encryptionConfig.KMS.URL != "" {
+				master = encryption.NewTransitMasterKey(encryptionConfig.KMS.URL, encryptionConfig.KMS.Token, encryptionConfig.KMS.KeyName)
+			} else {
+				key, err := encryption.LoadLocalMasterKey(encryptionConfig.MasterKeyFile)
+				if err != nil {
+					log.Fatalf("Failed to load encryption master key: %v", err)
+				}
+				master = key
+			}
+
+			var store repository.DataKeyRepository
+			if cfg.GetDatabaseDriver() == config.DatabaseDriverPostgres {
+				if pg == nil {
+					return nil
+				}
+				store = repository.NewPostgresDataKeyRepository(pg)
+			} else {
+				client, err := mongo.Connect(context.Background(), repository.MongoClientOptions(cfg.GetMongoURI(), cfg.GetMongoSlowQueryThreshold()))
+				if err != nil {
+					log.Fatalf("Failed to connect to MongoDB for data keys: %v", err)
+				}
+				store = repository.NewMongoDBDataKeyRepository(client.Database(cfg.GetMongoDatabase()))
+			}
+			log.Printf("Encrypting card data at rest with master key %s", master.ID())
+			return encryption.NewKeyring(master, store)
+		}},
+
+		// Repository - try the configured database first, fallback to mock
+		{Constructor: func(configService *configService.Service, pg *sql.DB, embedded *repository.EmbeddedQueueRepository, keyring *encryption.Keyring) repository.QueueRepository {
+			if embedded != nil {
+				embedded.SetOrderingResolver(configService)
+				return embedded
+			}
+			if cfg.GetDatabaseDriver() == config.DatabaseDriverPostgres {
+				if pg == nil {
+					log.Println("PostgreSQL is not available, using mock repository")
+					mockRepo := repository.NewMockQueueRepository()
+					mockRepo.SetOrderingResolver(configService)
+					return mockRepo
+				}
+				repo := repository.NewPostgresQueueRepository(pg)
+				repo.SetOrderingResolver(configService)
+				if keyring != nil {
+					repo.SetCardDataCipher(keyring)
+				}
+				return repo
+			}
+
 			// Try to connect to MongoDB using configuration
-			repo, err := repository.NewMongoDBQueueRepository(cfg.GetMongoURI(), cfg.GetMongoDatabase())
+			repo, err := repository.NewMongoDBQueueRepository(cfg.GetMongoURI(), cfg.GetMongoDatabase(), cfg.GetMongoSlowQueryThreshold())
 			if err != nil {
 				log.Printf("Failed to connect to MongoDB, using mock repository: %v", err)
-				return repository.NewMockQueueRepository()
+				mockRepo := repository.NewMockQueueRepository()
+				mockRepo.SetOrderingResolver(configService)
+				return mockRepo
 			}
 
+			// Rooms order their queue as configured
+			repo.SetOrderingResolver(configService)
+			if keyring != nil {
+				repo.SetCardDataCipher(keyring)
+			}
 			log.Println("Connected to MongoDB successfully")
 			return repo
 		}},
-		{Constructor: func() repository.ConfigRepository {
+		{Constructor: func(pg *sql.DB, embedded *repository.EmbeddedQueueRepository) repository.ConfigRepository {
+			if embedded != nil {
+				return embedded.ConfigRepository()
+			}
+			if cfg.GetDatabaseDriver() == config.DatabaseDriverPostgres {
+				if pg == nil {
+					return nil
+				}
+				return repository.NewPostgresConfigRepository(pg)
+			}
+
 			// Try to connect to MongoDB using configuration
-			client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(cfg.GetMongoURI()))
+			client, err := mongo.Connect(context.Background(), repository.MongoClientOptions(cfg.GetMongoURI(), cfg.GetMongoSlowQueryThreshold()))
 			if err != nil {
 				log.Printf("Failed to connect to MongoDB for config: %v", err)
 				return nil
@@ -87,9 +207,159 @@ func DIContainer(cfg *config.Config) *dig.Container {
 			log.Println("Connected to MongoDB for config successfully")
 			return repo
 		}},
-		{Constructor: func(cfg *config.Config) *priority.Repository {
+		{Constructor: func(cfg *config.Config, pg *sql.DB) repository.AppointmentRepository {
+			if cfg.GetDatabaseDriver() == config.DatabaseDriverEmbedded {
+				return repository.NewMockAppointmentRepository()
+			}
+			if cfg.GetDatabaseDriver() == config.DatabaseDriverPostgres {
+				if pg == nil {
+					return repository.NewMockAppointmentRepository()
+				}
+				return repository.NewPostgresAppointmentRepository(pg)
+			}
+
+			// Try to connect to MongoDB for appointments, fallback to mock
+			client, err := mongo.Connect(context.Background(), repository.MongoClientOptions(cfg.GetMongoURI(), cfg.GetMongoSlowQueryThreshold()))
+			if err != nil {
+				log.Printf("Failed to connect to MongoDB for appointments, using mock repository: %v", err)
+				return repository.NewMockAppointmentRepository()
+			}
+
+			db := client.Database(cfg.GetMongoDatabase())
+			repo := repository.NewMongoDBAppointmentRepository(db)
+			log.Println("Connected to MongoDB for appointments successfully")
+			return repo
+		}},
+		{Constructor: func(cfg *config.Config, pg *sql.DB) repository.EntryHistoryRepository {
+			if cfg.GetDatabaseDriver() == config.DatabaseDriverEmbedded {
+				return repository.NewMockEntryHistoryRepository()
+			}
+			if cfg.GetDatabaseDriver() == config.DatabaseDriverPostgres {
+				if pg == nil {
+					return repository.NewMockEntryHistoryRepository()
+				}
+				return repository.NewPostgresEntryHistoryRepository(pg)
+			}
+
+			// Try to connect to MongoDB for entry history, fallback to mock
+			client, err := mongo.Connect(context.Background(), repository.MongoClientOptions(cfg.GetMongoURI(), cfg.GetMongoSlowQueryThreshold()))
+			if err != nil {
+				log.Printf("Failed to connect to MongoDB for entry history, using mock repository: %v", err)
+				return repository.NewMockEntryHistoryRepository()
+			}
+
+			db := client.Database(cfg.GetMongoDatabase())
+			repo := repository.NewMongoDBEntryHistoryRepository(db)
+			log.Println("Connected to MongoDB for entry history successfully")
+			return repo
+		}},
+		{Constructor: func(cfg *config.Config, pg *sql.DB) repository.WebhookDeliveryRepository {
+			if cfg.GetDatabaseDriver() == config.DatabaseDriverEmbedded {
+				return repository.NewMockWebhookDeliveryRepository()
+			}
+			if cfg.GetDatabaseDriver() == config.DatabaseDriverPostgres {
+				if pg == nil {
+					return repository.NewMockWebhookDeliveryRepository()
+				}
+				return repository.NewPostgresWebhookDeliveryRepository(pg)
+			}
+
+			// Try to connect to MongoDB for the webhook outbox, fallback to mock
+			client, err := mongo.Connect(context.Background(), repository.MongoClientOptions(cfg.GetMongoURI(), cfg.GetMongoSlowQueryThreshold()))
+			if err != nil {
+				log.Printf("Failed to connect to MongoDB for webhook deliveries, using mock repository: %v", err)
+				return repository.NewMockWebhookDeliveryRepository()
+			}
+
+			db := client.Database(cfg.GetMongoDatabase())
+			repo := repository.NewMongoDBWebhookDeliveryRepository(db)
+			log.Println("Connected to MongoDB for webhook deliveries successfully")
+			return repo
+		}},
+		{Constructor: func(cfg *config.Config, pg *sql.DB) repository.AuditLogRepository {
+			if cfg.GetDatabaseDriver() == config.DatabaseDriverEmbedded {
+				return repository.NewMockAuditLogRepository()
+			}
+			if cfg.GetDatabaseDriver() == config.DatabaseDriverPostgres {
+				if pg == nil {
+					return repository.NewMockAuditLogRepository()
+				}
+				return repository.NewPostgresAuditLogRepository(pg)
+			}
+
+			// Try to connect to MongoDB for the audit log, fallback to mock
+			client, err := mongo.Connect(context.Background(), repository.MongoClientOptions(cfg.GetMongoURI(), cfg.GetMongoSlowQueryThreshold()))
+			if err != nil {
+				log.Printf("Failed to connect to MongoDB for the audit log, using mock repository: %v", err)
+				return repository.NewMockAuditLogRepository()
+			}
+
+			db := client.Database(cfg.GetMongoDatabase())
+			repo := repository.NewMongoDBAuditLogRepository(db)
+			log.Println("Connected to MongoDB for the audit log successfully")
+			return repo
+		}},
+		{Constructor: func(cfg *config.Config, pg *sql.DB) repository.TranslationGlossaryRepository {
+			if cfg.GetDatabaseDriver() == config.DatabaseDriverEmbedded {
+				return repository.NewMockTranslationGlossaryRepository()
+			}
+			if cfg.GetDatabaseDriver() == config.DatabaseDriverPostgres {
+				if pg == nil {
+					return repository.NewMockTranslationGlossaryRepository()
+				}
+				return repository.NewPostgresTranslationGlossaryRepository(pg)
+			}
+
+			// Try to connect to MongoDB for translation glossaries, fallback to mock
+			client, err := mongo.Connect(context.Background(), repository.MongoClientOptions(cfg.GetMongoURI(), cfg.GetMongoSlowQueryThreshold()))
+			if err != nil {
+				log.Printf("Failed to connect to MongoDB for translation glossaries, using mock repository: %v", err)
+				return repository.NewMockTranslationGlossaryRepository()
+			}
+
+			db := client.Database(cfg.GetMongoDatabase())
+			repo := repository.NewMongoDBTranslationGlossaryRepository(db)
+			log.Println("Connected to MongoDB for translation glossaries successfully")
+			return repo
+		}},
+		{Constructor: func(cfg *config.Config, pg *sql.DB) repository.TranslationCacheRepository {
+			// Embedded sites keep translations in memory only
+			if cfg.GetDatabaseDriver() == config.DatabaseDriverEmbedded {
+				return nil
+			}
+			if cfg.GetDatabaseDriver() == config.DatabaseDriverPostgres {
+				if pg == nil {
+					return nil
+				}
+				return repository.NewPostgresTranslationCacheRepository(pg)
+			}
+
+			// Try to connect to MongoDB for the persistent translation cache
+			client, err := mongo.Connect(context.Background(), repository.MongoClientOptions(cfg.GetMongoURI(), cfg.GetMongoSlowQueryThreshold()))
+			if err != nil {
+				log.Printf("Failed to connect to MongoDB for the translation cache, keeping it in memory: %v", err)
+				return nil
+			}
+
+			db := client.Database(cfg.GetMongoDatabase())
+			repo := repository.NewMongoDBTranslationCacheRepository(db)
+			log.Println("Connected to MongoDB for the translation cache successfully")
+			return repo
+		}},
+		{Constructor: func(cfg *config.Config, pg *sql.DB) *priority.Repository {
+			// Embedded sites use the default priority settings
+			if cfg.GetDatabaseDriver() == config.DatabaseDriverEmbedded {
+				return nil
+			}
+			if cfg.GetDatabaseDriver() == config.DatabaseDriverPostgres {
+				if pg == nil {
+					return nil
+				}
+				return priority.NewPostgresRepository(pg)
+			}
+
 			// Try to connect to MongoDB for priority config
-			client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(cfg.GetMongoURI()))
+			client, err := mongo.Connect(context.Background(), repository.MongoClientOptions(cfg.GetMongoURI(), cfg.GetMongoSlowQueryThreshold()))
 			if err != nil {
 				log.Printf("Failed to connect to MongoDB for priority config: %v", err)
 				return nil
@@ -102,9 +372,10 @@ func DIContainer(cfg *config.Config) *dig.Container {
 		}},
 
 		// Core services
-		{Constructor: func(repo repository.QueueRepository, cfg *config.Config, servicePointSvc *servicepointService.Service, configService *configService.Service, priorityRepo *priority.Repository) *queueService.WaitingQueue {
+		{Constructor: func(repo repository.QueueRepository, cfg *config.Config, servicePointSvc *servicepointService.Service, configService *configService.Service, priorityRepo *priority.Repository, historyRepo repository.EntryHistoryRepository) *queueService.WaitingQueue {
 			wq := queueService.NewWaitingQueue(repo, cfg, servicePointSvc, priorityRepo)
 			wq.SetConfigService(configService)
+			wq.SetHistoryRepository(historyRepo)
 			return wq
 		}},
 		{Constructor: func(cfg *config.Config) *servicepointService.Service {
@@ -113,47 +384,99 @@ func DIContainer(cfg *config.Config) *dig.Container {
 		{Constructor: cardreader.NewService},
 
 		// Middleware
-		{Constructor: middleware.NewAuthorizationMiddleware},
-		{Constructor: middleware.NewTenantMiddleware},
+		{Constructor: func() *auth.Verifier {
+			verifier, err := auth.NewVerifier(cfg.Auth)
+			if err != nil {
+				log.Fatalf("Failed to set up token verification: %v", err)
+			}
+			if verifier == nil {
+				if !cfg.Auth.AllowUnauthenticated {
+					log.Fatalf("auth configures no keys; configure an issuer, JWKS, HMAC secret or public key, or set auth.allow_unauthenticated for development")
+				}
+				log.Println("Warning: auth.allow_unauthenticated is set and auth configures no keys, the admin, staff, kiosk and display routes are not protected")
+			}
+			return verifier
+		}},
+		{Constructor: func(verifier *auth.Verifier, configService *configService.Service, responseErrorHandler *ngErrors.ResponseErrorHandler) *middleware.AuthorizationMiddleware {
+			return middleware.NewAuthorizationMiddleware(verifier, configService, responseErrorHandler)
+		}},
+		{Constructor: func(tenantService *tenantService.Service, responseErrorHandler *ngErrors.ResponseErrorHandler) *middleware.TenantMiddleware {
+			return middleware.NewTenantMiddleware(tenantService, responseErrorHandler)
+		}},
+		{Constructor: func(configService *configService.Service, responseErrorHandler *ngErrors.ResponseErrorHandler) *middleware.RateLimitMiddleware {
+			return middleware.NewRateLimitMiddleware(cfg.RateLimit, configService, responseErrorHandler)
+		}},
 		{Constructor: middleware.NewLoggingMiddleware},
+		{Constructor: func(configService *configService.Service, responseErrorHandler *ngErrors.ResponseErrorHandler) *middleware.CardReaderAuthMiddleware {
+			return middleware.NewCardReaderAuthMiddleware(configService, configService, responseErrorHandler)
+		}},
+		{Constructor: func(configService *configService.Service, responseErrorHandler *ngErrors.ResponseErrorHandler) *middleware.IntegrationAuthMiddleware {
+			return middleware.NewIntegrationAuthMiddleware(configService, responseErrorHandler)
+		}},
+		{Constructor: func(i18nService *i18nService.Service) *middleware.LanguageMiddleware {
+			return middleware.NewLanguageMiddleware(i18nService)
+		}},
 		{Constructor: ngErrors.NewResponseErrorHandler},
 
 		// Translation service
-		{Constructor: func(config *config.Config) *translation.DeepLTranslationService {
-			return translation.NewDeepLTranslationService(config.DeepL)
+		{Constructor: func(config *config.Config, cacheRepo repository.TranslationCacheRepository) *translation.DeepLTranslationService {
+			return translation.NewDeepLTranslationService(config.DeepL, cacheRepo)
 		}},
+		{Constructor: func(config *config.Config) *translation.LibreTranslateProvider {
+			return translation.NewLibreTranslateProvider(config.LibreTranslate)
+		}},
+		{Constructor: translation.NewService},
+
+		// Announcements of calls
+		{Constructor: announcementService.New},
+		// Patient notifications
+		{Constructor: notificationService.New},
 
 		// Webhook service
-		{Constructor: func(configService *configService.Service) *webhookService.Service {
-			return webhookService.NewService(configService)
+		{Constructor: func(configService *configService.Service, deliveryRepo repository.WebhookDeliveryRepository) *webhookService.Service {
+			return webhookService.NewService(configService, deliveryRepo)
 		}},
 
 		// Generated services (will be set up with broadcast function later)
-		{Constructor: func(queueService *queueService.WaitingQueue, config *config.Config, configService *configService.Service, webhookService *webhookService.Service, translationService *translation.DeepLTranslationService) *kioskService.Service {
-			return kioskService.New(queueService, nil, config, configService, webhookService, translationService)
+		{Constructor: func(queueService *queueService.WaitingQueue, config *config.Config, configService *configService.Service, webhookService *webhookService.Service, translationService *translation.Service, appointmentService *appointmentService.Service) *kioskService.Service {
+			return kioskService.New(queueService, nil, config, configService, webhookService, translationService, appointmentService)
 		}},
-		{Constructor: func(queueService *queueService.WaitingQueue, webhookService *webhookService.Service) *queueServiceGenerated.Service {
-			return queueServiceGenerated.New(queueService, nil, webhookService)
+		{Constructor: func(queueService *queueService.WaitingQueue, webhookService *webhookService.Service, configService *configService.Service, auditService *auditService.Service) *queueServiceGenerated.Service {
+			return queueServiceGenerated.New(queueService, nil, webhookService, configService, auditService)
 		}},
 		{Constructor: func(cfg *config.Config, configService *configService.Service) *configurationService.Service {
 			svc := configurationService.New(cfg)
 			svc.SetConfigService(configService)
 			return svc
 		}},
-		{Constructor: func(repo repository.ConfigRepository) *configService.Service {
-			return configService.NewService(repo)
+		{Constructor: auditService.New},
+		{Constructor: func(repo repository.ConfigRepository, auditService *auditService.Service) *configService.Service {
+			return configService.NewService(repo, auditService)
 		}},
-		{Constructor: func(repo repository.ConfigRepository) *tenantService.Service {
-			return tenantService.NewService(repo)
+		{Constructor: func(repo repository.ConfigRepository, auditService *auditService.Service, waitingQueue *queueService.WaitingQueue) *tenantService.Service {
+			return tenantService.NewService(repo, auditService, waitingQueue)
 		}},
 		{Constructor: priorityService.New},
-		{Constructor: func(configService *configService.Service, translationService *translation.DeepLTranslationService, tenantService *tenantService.Service, priorityService *priorityService.Service) *adminService.Service {
-			return adminService.NewService(configService, translationService, tenantService, priorityService)
+		{Constructor: analyticsService.New},
+		{Constructor: appointmentService.New},
+		{Constructor: cardreaderService.New},
+		{Constructor: integrationService.New},
+		{Constructor: fhirService.New},
+		{Constructor: i18nService.New},
+		{Constructor: func(configService *configService.Service, translationService *translation.Service, tenantService *tenantService.Service, priorityService *priorityService.Service, queueService *queueServiceGenerated.Service) *adminService.Service {
+			return adminService.NewService(configService, translationService, tenantService, priorityService, queueService, cfg.WebSocket.TokenSecret, cfg.Configuration.BundleSigningKey)
 		}},
 
 		// Generated handlers
 		{Constructor: adminHandler.New},
+		{Constructor: analyticsHandler.New},
+		{Constructor: appointmentHandler.New},
+		{Constructor: auditHandler.New},
+		{Constructor: cardreaderHandler.New},
 		{Constructor: configHandler.New},
+		{Constructor: fhirHandler.New},
+		{Constructor: i18nHandler.New},
+		{Constructor: integrationHandler.New},
 		{Constructor: kioskHandler.New},
 		{Constructor: queueHandler.New},
 		{Constructor: servicepointHandler.New},
@@ -209,9 +532,62 @@ func main() {
 		log.Println("ServicePoint cleanup routine started")
 	})
 
+	// Start the webhook delivery workers
+	diContainer.Invoke(func(webhookSvc *webhookService.Service) {
+		webhookSvc.StartDeliveryRoutine(context.Background(), cfg.Webhooks)
+	})
+
+	// Start the no-show expiry, priority recalculation, archival, anonymizer and change broadcast routines
+	diContainer.Invoke(func(queueSvc *queueServiceGenerated.Service) {
+		ctx := context.Background()
+		queueSvc.StartNoShowExpiryRoutine(ctx, cfg.Queue)
+		queueSvc.StartPriorityRecalculationRoutine(ctx, cfg.Queue)
+		queueSvc.StartArchiveRoutine(ctx, cfg.Queue)
+		queueSvc.StartRetentionRoutine(ctx, cfg.Privacy)
+		queueSvc.StartChangeBroadcastRoutine(ctx, cfg.Queue)
+	})
+
+	// Start the purge of deactivated tenants, with the tenant data of the other repositories
+	diContainer.Invoke(func(tenantSvc *tenantService.Service, appointmentRepo repository.AppointmentRepository, deliveryRepo repository.WebhookDeliveryRepository, glossaryRepo repository.TranslationGlossaryRepository, cacheRepo repository.TranslationCacheRepository, prioritySvc *priorityService.Service, keyring *encryption.Keyring) {
+		tenantSvc.OnPurge("appointments", func(ctx context.Context) error {
+			_, err := appointmentRepo.DeleteTenantAppointments(ctx)
+			return err
+		})
+		tenantSvc.OnPurge("webhook deliveries", func(ctx context.Context) error {
+			_, err := deliveryRepo.DeleteTenantWebhookDeliveries(ctx)
+			return err
+		})
+		tenantSvc.OnPurge("translation glossary", func(ctx context.Context) error {
+			return glossaryRepo.ReplaceGlossary(ctx, nil)
+		})
+		if cacheRepo != nil {
+			tenantSvc.OnPurge("translation cache", func(ctx context.Context) error {
+				_, err := cacheRepo.DeleteTranslations(ctx)
+				return err
+			})
+		}
+		tenantSvc.OnPurge("priority config", prioritySvc.DeleteConfig)
+		if keyring != nil {
+			tenantSvc.SetDataKeys(keyring)
+		}
+		tenantSvc.StartPurgeRoutine(context.Background(), cfg.Tenants)
+	})
+
+	// Start watching the stored configurations for changes of other replicas
+	diContainer.Invoke(func(configSvc *configService.Service) {
+		configSvc.StartChangeWatchRoutine(context.Background(), cfg.Configuration)
+	})
+
 	go func() {
-		log.Println("API listening on", server.Addr)
-		if err := server.ListenAndServe(); err != nil {
+		var err error
+		if cfg.Server.TLS.Enabled() {
+			log.Println("API listening (TLS) on", server.Addr)
+			err = server.ListenAndServeTLS(cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile)
+		} else {
+			log.Println("API listening on", server.Addr)
+			err = server.ListenAndServe()
+		}
+		if err != nil {
 			if err != http.ErrServerClosed {
 				log.Fatal("Server failed to start:", err)
 			}
@@ -219,6 +595,13 @@ func main() {
 	}()
 
 	waitAndGracefullyStop(server)
+
+	// Flush the queue, e.g. the last snapshot of the embedded mode
+	diContainer.Invoke(func(repo repository.QueueRepository) {
+		if err := repo.Close(); err != nil {
+			log.Printf("Failed to close the queue repository: %v", err)
+		}
+	})
 }
 
 func waitAndGracefullyStop(server *http.Server) {
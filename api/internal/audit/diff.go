@@ -0,0 +1,105 @@
+// Package audit computes the field changes administrative actions make, for the audit log.
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// Redacted replaces the values of secret fields in the changes
+const Redacted = "[redacted]"
+
+// secretFields are the parts of field names whose values are never recorded
+var secretFields = []string{"secret", "password", "token", "apikey", "privatekey"}
+
+// ignoredFields are timestamps the stores set on writes, left out as the entry has its own
+var ignoredFields = map[string]bool{"createdAt": true, "updatedAt": true}
+
+// Diff returns the changes between the JSON of before and after, sorted by field. Objects are
+// compared field by field, other values, arrays included, as a whole; nil stands for a value that
+// did not exist.
+func Diff(before, after any) ([]types.AuditChange, error) {
+	beforeValue, err := toJSONValue(before)
+	if err != nil {
+		return nil, err
+	}
+	afterValue, err := toJSONValue(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []types.AuditChange
+	diffValues("", beforeValue, afterValue, &changes)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+func toJSONValue(v any) (any, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func diffValues(field string, before, after any, changes *[]types.AuditChange) {
+	beforeObject, beforeIsObject := before.(map[string]any)
+	afterObject, afterIsObject := after.(map[string]any)
+	if (beforeIsObject || before == nil) && (afterIsObject || after == nil) && (beforeIsObject || afterIsObject) {
+		for name, value := range beforeObject {
+			if !ignoredFields[name] {
+				diffValues(join(field, name), value, afterObject[name], changes)
+			}
+		}
+		for name, value := range afterObject {
+			if _, found := beforeObject[name]; !found && !ignoredFields[name] {
+				diffValues(join(field, name), nil, value, changes)
+			}
+		}
+		return
+	}
+
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+	if IsSecret(field) {
+		before, after = redact(before), redact(after)
+	}
+	*changes = append(*changes, types.AuditChange{Field: field, Before: before, After: after})
+}
+
+func join(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
+
+// IsSecret reports whether the last part of the dotted field names a secret
+func IsSecret(field string) bool {
+	name := strings.ToLower(field[strings.LastIndex(field, ".")+1:])
+	for _, secret := range secretFields {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+func redact(value any) any {
+	if value == nil {
+		return nil
+	}
+	return Redacted
+}
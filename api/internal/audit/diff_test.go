@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+func TestDiffConfiguration(t *testing.T) {
+	before := &types.SystemConfiguration{
+		DefaultRoom: "triage-1",
+		ExternalAPI: types.ExternalAPIConfig{WebhookURL: "https://his.example.com/hook", WebhookSecret: "old", TimeoutSeconds: 10},
+		Rooms:       []types.RoomConfig{{ID: "triage-1", Name: "Triage"}},
+		UpdatedAt:   time.Unix(1000, 0),
+	}
+	after := &types.SystemConfiguration{
+		DefaultRoom: "triage-2",
+		ExternalAPI: types.ExternalAPIConfig{WebhookURL: "https://his.example.com/hook", WebhookSecret: "new", TimeoutSeconds: 10, SupportedLanguages: []string{"en", "sk"}},
+		Rooms:       []types.RoomConfig{{ID: "triage-1", Name: "Triage"}},
+		UpdatedAt:   time.Unix(2000, 0),
+	}
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []types.AuditChange{
+		{Field: "defaultRoom", Before: "triage-1", After: "triage-2"},
+		{Field: "externalAPI.supportedLanguages", After: []any{"en", "sk"}},
+		{Field: "externalAPI.webhookSecret", Before: Redacted, After: Redacted},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, changes)
+	}
+}
+
+func TestDiffCreateAndDelete(t *testing.T) {
+	tenant := &types.Tenant{ID: "b1:s1", BuildingID: "b1", SectionID: "s1", Name: "Cardiology"}
+
+	created, err := Diff(nil, tenant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]any{}
+	for _, change := range created {
+		if change.Before != nil {
+			t.Errorf("Expected no value before creation of %s, got %v", change.Field, change.Before)
+		}
+		fields[change.Field] = change.After
+	}
+	if fields["name"] != "Cardiology" || fields["buildingId"] != "b1" {
+		t.Errorf("Expected the fields of the created tenant, got %v", fields)
+	}
+
+	var deleted *types.Tenant
+	changes, err := Diff(tenant, deleted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != len(created) {
+		t.Errorf("Expected the %d fields of the deleted tenant, got %+v", len(created), changes)
+	}
+
+	if changes, _ := Diff(tenant, tenant); len(changes) != 0 {
+		t.Errorf("Expected no changes, got %+v", changes)
+	}
+}
@@ -0,0 +1,75 @@
+// Package auth verifies the JWTs the admin, staff, kiosk and display clients present and holds
+// the roles and tenants they grant.
+package auth
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// Roles a token grants
+const (
+	RoleAdmin   = "admin"   // Configuration and tenants; passes the requirement of every route
+	RoleStaff   = "staff"   // Service points and queue control
+	RoleKiosk   = "kiosk"   // Card swipes, walk-ins and services of patients
+	RoleDisplay = "display" // Room state and queues on waiting room screens
+)
+
+// Roles lists the roles routes can require
+var Roles = []string{RoleAdmin, RoleStaff, RoleKiosk, RoleDisplay}
+
+// AllTenants in the tenants claim grants every tenant
+const AllTenants = "*"
+
+// Claims are the verified claims of a token
+type Claims struct {
+	Subject string
+	Roles   []string
+	// Tenants are the tenant IDs (buildingId:sectionId) the token is valid for; a building ID
+	// covers all its sections
+	Tenants []string
+}
+
+// HasRole reports whether the claims grant one of the roles; admin grants them all
+func (c *Claims) HasRole(roles ...string) bool {
+	if slices.Contains(c.Roles, RoleAdmin) {
+		return true
+	}
+	for _, role := range roles {
+		if slices.Contains(c.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTenant reports whether the claims are valid for the tenant ID of a request
+func (c *Claims) HasTenant(tenantID string) bool {
+	building, _, _ := strings.Cut(tenantID, ":")
+	for _, tenant := range c.Tenants {
+		if tenant == AllTenants || tenant == tenantID || tenant == building {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAllTenants reports whether the claims are valid for every tenant, and so for requests
+// without one
+func (c *Claims) HasAllTenants() bool {
+	return slices.Contains(c.Tenants, AllTenants)
+}
+
+type claimsKey struct{}
+
+// WithClaims returns a copy of the context carrying the claims of the request
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims of the request, nil without a verified token
+func ClaimsFromContext(ctx context.Context) *Claims {
+	claims, _ := ctx.Value(claimsKey{}).(*Claims)
+	return claims
+}
@@ -0,0 +1,220 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// keySetTTL is how long fetched signing keys are used before they are fetched again
+	keySetTTL = time.Hour
+	// keySetMinRefresh limits how often a token with an unknown key ID fetches the keys again,
+	// so forged key IDs can't make every request call the issuer
+	keySetMinRefresh = time.Minute
+	// keySetTimeout bounds the discovery and JWKS requests
+	keySetTimeout = 10 * time.Second
+)
+
+// keySet holds the signing keys of a JWKS, published at its URL or named by the OIDC discovery
+// document of the issuer. Keys are fetched on first use and again when they expire or a token
+// names a key ID the set lacks, e.g. after the issuer rotated its keys.
+type keySet struct {
+	issuer string
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+func newKeySet(issuer, url string) *keySet {
+	return &keySet{
+		issuer: issuer,
+		url:    url,
+		client: &http.Client{Timeout: keySetTimeout},
+	}
+}
+
+// key returns the key with the key ID, the only key of the set for tokens without one
+func (s *keySet) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.keys == nil || time.Since(s.fetchedAt) > keySetTTL {
+		if err := s.refresh(ctx); err != nil {
+			return nil, err
+		}
+	}
+	key, found := s.find(kid)
+	if !found && time.Since(s.fetchedAt) > keySetMinRefresh {
+		if err := s.refresh(ctx); err != nil {
+			return nil, err
+		}
+		key, found = s.find(kid)
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+// find looks the key ID up; s.mu must be held
+func (s *keySet) find(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, found := s.keys[kid]
+	return key, found
+}
+
+// refresh fetches the keys; s.mu must be held
+func (s *keySet) refresh(ctx context.Context) error {
+	url := s.url
+	if url == "" {
+		discovery := struct {
+			JWKSURI string `json:"jwks_uri"`
+		}{}
+		if err := s.get(ctx, strings.TrimSuffix(s.issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+			return fmt.Errorf("failed to discover the keys of %s: %w", s.issuer, err)
+		}
+		if discovery.JWKSURI == "" {
+			return fmt.Errorf("discovery document of %s names no jwks_uri", s.issuer)
+		}
+		url = discovery.JWKSURI
+	}
+
+	var document struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := s.get(ctx, url, &document); err != nil {
+		return fmt.Errorf("failed to fetch the keys at %s: %w", url, err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(document.Keys))
+	for _, jwk := range document.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			// Keys of other types don't prevent using the rest
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	return nil
+}
+
+func (s *keySet) get(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// jsonWebKey is an RSA or EC public key of a JWKS (RFC 7517)
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
+
+// readPublicKey reads the RSA or EC public key of a PEM file, as a public key or a certificate
+func readPublicKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block")
+	}
+	if block.Type == "CERTIFICATE" {
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return certificate.PublicKey, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+}
@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+)
+
+// clockSkew is how far the clocks of the issuer and the API may be apart
+const clockSkew = time.Minute
+
+// ErrInvalidToken is returned for tokens that are malformed, not signed with a trusted key,
+// expired or issued for another issuer or audience
+var ErrInvalidToken = errors.New("invalid token")
+
+// Verifier verifies JWTs signed with HS256/384/512 by the shared secret, or with RS256/384/512 or
+// ES256/384/512 by the public key file or a key of the JWKS of the issuer
+type Verifier struct {
+	issuer       string
+	audience     string
+	rolesClaim   []string
+	tenantsClaim []string
+	hmacKey      []byte
+	publicKey    crypto.PublicKey
+	keys         *keySet
+}
+
+// NewVerifier returns the verifier of the auth configuration, nil when it configures no keys
+func NewVerifier(cfg config.AuthConfig) (*Verifier, error) {
+	if cfg.Issuer == "" && cfg.JWKSURL == "" && cfg.HMACSecret == "" && cfg.PublicKeyFile == "" {
+		return nil, nil
+	}
+	verifier := &Verifier{
+		issuer:       cfg.Issuer,
+		audience:     cfg.Audience,
+		rolesClaim:   strings.Split(cfg.RolesClaim, "."),
+		tenantsClaim: strings.Split(cfg.TenantsClaim, "."),
+	}
+	if cfg.HMACSecret != "" {
+		verifier.hmacKey = []byte(cfg.HMACSecret)
+	}
+	if cfg.PublicKeyFile != "" {
+		key, err := readPublicKey(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read public key %s: %w", cfg.PublicKeyFile, err)
+		}
+		verifier.publicKey = key
+	}
+	if cfg.Issuer != "" || cfg.JWKSURL != "" {
+		verifier.keys = newKeySet(cfg.Issuer, cfg.JWKSURL)
+	}
+	return verifier, nil
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Verify checks the signature and the registered claims of a compact JWT and returns its claims
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+	var head header
+	if err := decodeSegment(parts[0], &head); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	if err := v.verifySignature(ctx, head, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var payload map[string]any
+	if err := decodeSegment(parts[1], &payload); err != nil {
+		return nil, fmt.Errorf("%w: malformed payload", ErrInvalidToken)
+	}
+	if err := v.validate(payload, time.Now()); err != nil {
+		return nil, err
+	}
+	subject, _ := payload["sub"].(string)
+	return &Claims{
+		Subject: subject,
+		Roles:   stringsClaim(payload, v.rolesClaim),
+		Tenants: stringsClaim(payload, v.tenantsClaim),
+	}, nil
+}
+
+// verifySignature checks the signature with the key of the algorithm; the algorithm none and
+// algorithms without a configured key are rejected
+func (v *Verifier) verifySignature(ctx context.Context, head header, signed string, signature []byte) error {
+	var hashFunc crypto.Hash
+	switch head.Alg[min(2, len(head.Alg)):] {
+	case "256":
+		hashFunc = crypto.SHA256
+	case "384":
+		hashFunc = crypto.SHA384
+	case "512":
+		hashFunc = crypto.SHA512
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, head.Alg)
+	}
+
+	if strings.HasPrefix(head.Alg, "HS") {
+		if v.hmacKey == nil {
+			return fmt.Errorf("%w: no key for %s", ErrInvalidToken, head.Alg)
+		}
+		mac := hmac.New(newHash(hashFunc), v.hmacKey)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+		}
+		return nil
+	}
+
+	key, err := v.publicKeyOf(ctx, head)
+	if err != nil {
+		return err
+	}
+	digest := newHash(hashFunc)()
+	digest.Write([]byte(signed))
+	sum := digest.Sum(nil)
+
+	switch publicKey := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(head.Alg, "RS") || rsa.VerifyPKCS1v15(publicKey, hashFunc, sum, signature) != nil {
+			return fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+		}
+	case *ecdsa.PublicKey:
+		// The signature is R and S, each as long as the key
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		if !strings.HasPrefix(head.Alg, "ES") || len(signature) != 2*size {
+			return fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(publicKey, sum, r, s) {
+			return fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported key for %s", ErrInvalidToken, head.Alg)
+	}
+	return nil
+}
+
+// publicKeyOf returns the key of a token signed with a public key algorithm: the key of its key
+// ID in the JWKS, the public key file otherwise
+func (v *Verifier) publicKeyOf(ctx context.Context, head header) (crypto.PublicKey, error) {
+	if v.keys != nil && (head.Kid != "" || v.publicKey == nil) {
+		return v.keys.key(ctx, head.Kid)
+	}
+	if v.publicKey == nil {
+		return nil, fmt.Errorf("%w: no key for %s", ErrInvalidToken, head.Alg)
+	}
+	return v.publicKey, nil
+}
+
+// validate checks the expiry, the start and the issuer and audience of the token
+func (v *Verifier) validate(payload map[string]any, now time.Time) error {
+	exp, ok := payload["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("%w: no expiry", ErrInvalidToken)
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(clockSkew)) {
+		return fmt.Errorf("%w: expired", ErrInvalidToken)
+	}
+	if nbf, ok := payload["nbf"].(float64); ok && now.Add(clockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+	if v.issuer != "" && strings.TrimSuffix(fmt.Sprint(payload["iss"]), "/") != strings.TrimSuffix(v.issuer, "/") {
+		return fmt.Errorf("%w: issued by %v", ErrInvalidToken, payload["iss"])
+	}
+	if v.audience != "" && !slices.Contains(stringsClaim(payload, []string{"aud"}), v.audience) {
+		return fmt.Errorf("%w: not issued for %s", ErrInvalidToken, v.audience)
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func newHash(hashFunc crypto.Hash) func() hash.Hash {
+	switch hashFunc {
+	case crypto.SHA384:
+		return sha512.New384
+	case crypto.SHA512:
+		return sha512.New
+	default:
+		return sha256.New
+	}
+}
+
+// stringsClaim returns the strings of the claim at the path of nested objects, e.g.
+// realm_access.roles; a string claim holds one value or several separated by spaces
+func stringsClaim(payload map[string]any, path []string) []string {
+	var value any = payload
+	for _, name := range path {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+	switch claim := value.(type) {
+	case string:
+		return strings.Fields(claim)
+	case []any:
+		values := make([]string, 0, len(claim))
+		for _, item := range claim {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
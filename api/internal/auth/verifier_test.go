@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+)
+
+func encodeSegment(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func hmacToken(t *testing.T, secret string, payload map[string]any) string {
+	t.Helper()
+	signed := encodeSegment(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeSegment(t, payload)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func rsaToken(t *testing.T, key *rsa.PrivateKey, kid string, payload map[string]any) string {
+	t.Helper()
+	signed := encodeSegment(t, map[string]string{"alg": "RS256", "kid": kid}) + "." + encodeSegment(t, payload)
+	sum := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func newVerifier(t *testing.T, cfg config.AuthConfig) *Verifier {
+	t.Helper()
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+	if cfg.TenantsClaim == "" {
+		cfg.TenantsClaim = "tenants"
+	}
+	verifier, err := NewVerifier(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return verifier
+}
+
+func TestNewVerifierWithoutKeys(t *testing.T) {
+	verifier, err := NewVerifier(config.AuthConfig{RolesClaim: "roles"})
+	if err != nil || verifier != nil {
+		t.Errorf("Expected no verifier without keys, got %v, %v", verifier, err)
+	}
+}
+
+func TestVerifyHMAC(t *testing.T) {
+	verifier := newVerifier(t, config.AuthConfig{HMACSecret: "secret", Audience: "waiting-room"})
+	exp := time.Now().Add(time.Hour).Unix()
+
+	claims, err := verifier.Verify(context.Background(), hmacToken(t, "secret", map[string]any{
+		"sub": "nurse", "exp": exp, "aud": []string{"waiting-room"}, "roles": []string{"staff"}, "tenants": "b1:s1 b2",
+	}))
+	if err != nil {
+		t.Fatalf("Expected a valid token, got %v", err)
+	}
+	expected := &Claims{Subject: "nurse", Roles: []string{"staff"}, Tenants: []string{"b1:s1", "b2"}}
+	if !reflect.DeepEqual(claims, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, claims)
+	}
+
+	tests := map[string]string{
+		"wrong secret": hmacToken(t, "other", map[string]any{"exp": exp, "aud": "waiting-room"}),
+		"expired":      hmacToken(t, "secret", map[string]any{"exp": time.Now().Add(-time.Hour).Unix(), "aud": "waiting-room"}),
+		"no expiry":    hmacToken(t, "secret", map[string]any{"aud": "waiting-room"}),
+		"not yet":      hmacToken(t, "secret", map[string]any{"exp": exp, "nbf": exp, "aud": "waiting-room"}),
+		"audience":     hmacToken(t, "secret", map[string]any{"exp": exp, "aud": "other"}),
+		"unsigned":     encodeSegment(t, map[string]string{"alg": "none"}) + "." + encodeSegment(t, map[string]any{"exp": exp}) + ".",
+		"malformed":    "not-a-token",
+	}
+	for name, token := range tests {
+		if _, err := verifier.Verify(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: expected ErrInvalidToken, got %v", name, err)
+		}
+	}
+}
+
+func TestVerifyJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{"issuer": server.URL, "jwks_uri": server.URL + "/keys"})
+		case "/keys":
+			json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{
+				{
+					"kty": "RSA", "kid": "rsa-1", "use": "sig",
+					"n": base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+					"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+				},
+				{
+					"kty": "EC", "kid": "ec-1", "crv": "P-256",
+					"x": base64.RawURLEncoding.EncodeToString(ecKey.X.FillBytes(make([]byte, 32))),
+					"y": base64.RawURLEncoding.EncodeToString(ecKey.Y.FillBytes(make([]byte, 32))),
+				},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	verifier := newVerifier(t, config.AuthConfig{Issuer: server.URL, RolesClaim: "realm_access.roles"})
+	payload := map[string]any{
+		"iss": server.URL, "exp": time.Now().Add(time.Hour).Unix(),
+		"realm_access": map[string]any{"roles": []string{"admin"}},
+	}
+
+	claims, err := verifier.Verify(context.Background(), rsaToken(t, rsaKey, "rsa-1", payload))
+	if err != nil {
+		t.Fatalf("Expected a valid RS256 token, got %v", err)
+	}
+	if !reflect.DeepEqual(claims.Roles, []string{"admin"}) {
+		t.Errorf("Expected the nested roles, got %v", claims.Roles)
+	}
+
+	signed := encodeSegment(t, map[string]string{"alg": "ES256", "kid": "ec-1"}) + "." + encodeSegment(t, payload)
+	sum := sha256.Sum256([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, ecKey, sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	if _, err := verifier.Verify(context.Background(), signed+"."+base64.RawURLEncoding.EncodeToString(signature)); err != nil {
+		t.Errorf("Expected a valid ES256 token, got %v", err)
+	}
+
+	if _, err := verifier.Verify(context.Background(), rsaToken(t, rsaKey, "unknown", payload)); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken for an unknown key, got %v", err)
+	}
+	payload["iss"] = "https://other.example.com"
+	if _, err := verifier.Verify(context.Background(), rsaToken(t, rsaKey, "rsa-1", payload)); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken for another issuer, got %v", err)
+	}
+}
+
+func TestClaims(t *testing.T) {
+	claims := &Claims{Roles: []string{"staff"}, Tenants: []string{"b1:s1", "b2"}}
+	if !claims.HasRole("kiosk", "staff") || claims.HasRole("admin") || claims.HasRole("display") {
+		t.Errorf("Unexpected roles of %v", claims.Roles)
+	}
+	if !(&Claims{Roles: []string{"admin"}}).HasRole("display") {
+		t.Error("Expected admin to pass every role")
+	}
+
+	tenants := map[string]bool{"b1:s1": true, "b1:s2": false, "b2:s9": true, "b3": false}
+	for tenantID, expected := range tenants {
+		if got := claims.HasTenant(tenantID); got != expected {
+			t.Errorf("HasTenant(%s) = %v, expected %v", tenantID, got, expected)
+		}
+	}
+	if !(&Claims{Tenants: []string{AllTenants}}).HasTenant("b3:s1") {
+		t.Error("Expected * to grant every tenant")
+	}
+}
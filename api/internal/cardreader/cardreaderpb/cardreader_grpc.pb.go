@@ -0,0 +1,138 @@
+// Card reader events for TRANSPORT=grpc. This file is the one definition of the
+// payload the card reader sends and the API ingests; the Go code of both modules
+// is generated from it (make proto). The JSON names match the CardReaderEvent
+// schema of the REST and WebSocket transports.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: cardreader/v1/cardreader.proto
+
+package cardreaderpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CardReaderIngest_Ingest_FullMethodName = "/waitingroom.cardreader.v1.CardReaderIngest/Ingest"
+)
+
+// CardReaderIngestClient is the client API for CardReaderIngest service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// CardReaderIngest takes the events of authenticated card readers. The device
+// credential travels in the metadata, like the headers of the other transports:
+// x-device-id, authorization ("Bearer <device token or API key>") and x-tenant-id.
+type CardReaderIngestClient interface {
+	// Ingest files one event. A resent event (same token) is acknowledged as a
+	// duplicate without being processed again.
+	Ingest(ctx context.Context, in *CardReaderEvent, opts ...grpc.CallOption) (*CardReaderEventAck, error)
+}
+
+type cardReaderIngestClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCardReaderIngestClient(cc grpc.ClientConnInterface) CardReaderIngestClient {
+	return &cardReaderIngestClient{cc}
+}
+
+func (c *cardReaderIngestClient) Ingest(ctx context.Context, in *CardReaderEvent, opts ...grpc.CallOption) (*CardReaderEventAck, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CardReaderEventAck)
+	err := c.cc.Invoke(ctx, CardReaderIngest_Ingest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CardReaderIngestServer is the server API for CardReaderIngest service.
+// All implementations must embed UnimplementedCardReaderIngestServer
+// for forward compatibility.
+//
+// CardReaderIngest takes the events of authenticated card readers. The device
+// credential travels in the metadata, like the headers of the other transports:
+// x-device-id, authorization ("Bearer <device token or API key>") and x-tenant-id.
+type CardReaderIngestServer interface {
+	// Ingest files one event. A resent event (same token) is acknowledged as a
+	// duplicate without being processed again.
+	Ingest(context.Context, *CardReaderEvent) (*CardReaderEventAck, error)
+	mustEmbedUnimplementedCardReaderIngestServer()
+}
+
+// UnimplementedCardReaderIngestServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCardReaderIngestServer struct{}
+
+func (UnimplementedCardReaderIngestServer) Ingest(context.Context, *CardReaderEvent) (*CardReaderEventAck, error) {
+	return nil, status.Error(codes.Unimplemented, "method Ingest not implemented")
+}
+func (UnimplementedCardReaderIngestServer) mustEmbedUnimplementedCardReaderIngestServer() {}
+func (UnimplementedCardReaderIngestServer) testEmbeddedByValue()                          {}
+
+// UnsafeCardReaderIngestServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CardReaderIngestServer will
+// result in compilation errors.
+type UnsafeCardReaderIngestServer interface {
+	mustEmbedUnimplementedCardReaderIngestServer()
+}
+
+func RegisterCardReaderIngestServer(s grpc.ServiceRegistrar, srv CardReaderIngestServer) {
+	// If the following call panics, it indicates UnimplementedCardReaderIngestServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CardReaderIngest_ServiceDesc, srv)
+}
+
+func _CardReaderIngest_Ingest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CardReaderEvent)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CardReaderIngestServer).Ingest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CardReaderIngest_Ingest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CardReaderIngestServer).Ingest(ctx, req.(*CardReaderEvent))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CardReaderIngest_ServiceDesc is the grpc.ServiceDesc for CardReaderIngest service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CardReaderIngest_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "waitingroom.cardreader.v1.CardReaderIngest",
+	HandlerType: (*CardReaderIngestServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Ingest",
+			Handler:    _CardReaderIngest_Ingest_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "cardreader/v1/cardreader.proto",
+}
@@ -0,0 +1,79 @@
+package cardreader
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// EncryptedCardData is the encrypted CardData block sent by card readers that have
+// a per-device key provisioned (AES-256-GCM, associated data "deviceId|token").
+type EncryptedCardData struct {
+	Alg        string `json:"alg"`
+	KeyID      string `json:"kid"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// NewCardDataKey generates a random AES-256 key, returned base64 encoded
+func NewCardDataKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// CardDataKeyID returns the short key identifier readers put into "kid"
+func CardDataKeyID(encodedKey string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// Open decrypts the block and returns the CardData JSON
+func (e *EncryptedCardData) Open(encodedKey, deviceID, token string) ([]byte, error) {
+	if e.Alg != "A256GCM" {
+		return nil, fmt.Errorf("unsupported card data encryption %q", e.Alg)
+	}
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("invalid card data key")
+	}
+	if kid, _ := CardDataKeyID(encodedKey); e.KeyID != "" && e.KeyID != kid {
+		return nil, fmt.Errorf("card data encrypted with unknown key %s", e.KeyID)
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(e.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(e.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length %d", len(nonce))
+	}
+	plain, err := gcm.Open(nil, nonce, ciphertext, []byte(deviceID+"|"+token))
+	if err != nil {
+		return nil, fmt.Errorf("card data authentication failed: %w", err)
+	}
+	return plain, nil
+}
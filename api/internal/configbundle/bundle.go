@@ -0,0 +1,121 @@
+// Package configbundle signs the bundles the configuration of a tenant is exported in and keeps the
+// secrets it holds out of them, so a bundle can be promoted from one environment to another.
+package configbundle
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/arfis/waiting-room/internal/audit"
+)
+
+// Sign returns the hex encoded HMAC-SHA256 of the canonical JSON of content, with object keys
+// sorted, so content decoded from a bundle and encoded again has the signature it was exported with
+func Sign(content any, key string) (string, error) {
+	data, err := canonicalJSON(content)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Verify reports whether signature is the one of content
+func Verify(content any, signature, key string) (bool, error) {
+	expected, err := Sign(content, key)
+	if err != nil {
+		return false, err
+	}
+	return hmac.Equal([]byte(expected), []byte(signature)), nil
+}
+
+func canonicalJSON(content any) ([]byte, error) {
+	value, err := toJSONValue(content)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// ToObject returns the JSON object v encodes to
+func ToObject(v any) (map[string]any, error) {
+	value, err := toJSONValue(v)
+	if err != nil {
+		return nil, err
+	}
+	object, _ := value.(map[string]any)
+	if object == nil {
+		object = map[string]any{}
+	}
+	return object, nil
+}
+
+// FromObject decodes the JSON object into v
+func FromObject(object map[string]any, v any) error {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func toJSONValue(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// StripSecrets removes the fields holding secrets, as the audit log tells them, from the JSON value
+// and the objects and arrays it contains
+func StripSecrets(value any) {
+	switch value := value.(type) {
+	case map[string]any:
+		for name, field := range value {
+			if audit.IsSecret(name) {
+				delete(value, name)
+				continue
+			}
+			StripSecrets(field)
+		}
+	case []any:
+		for _, item := range value {
+			StripSecrets(item)
+		}
+	}
+}
+
+// KeepSecrets sets the secret fields the JSON value lacks to the ones stored holds at the same
+// place, so importing a bundle keeps the secrets of the environment it is imported into. Array
+// items are matched by position.
+func KeepSecrets(value, stored any) {
+	switch value := value.(type) {
+	case map[string]any:
+		storedObject, _ := stored.(map[string]any)
+		for name, storedField := range storedObject {
+			field, found := value[name]
+			if audit.IsSecret(name) {
+				if !found || field == nil || field == "" {
+					value[name] = storedField
+				}
+				continue
+			}
+			if found {
+				KeepSecrets(field, storedField)
+			}
+		}
+	case []any:
+		storedArray, _ := stored.([]any)
+		for i := 0; i < len(value) && i < len(storedArray); i++ {
+			KeepSecrets(value[i], storedArray[i])
+		}
+	}
+}
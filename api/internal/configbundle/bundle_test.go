@@ -0,0 +1,94 @@
+package configbundle
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+func TestSignSurvivesRoundTrip(t *testing.T) {
+	config := &types.SystemConfiguration{
+		DefaultRoom: "triage-1",
+		ExternalAPI: types.ExternalAPIConfig{WebhookURL: "https://his.example.com/hook?a=1&b=2", TimeoutSeconds: 10},
+		Messages:    map[string]map[string]string{"sk": {"welcome": "Vitajte"}, "en": {"welcome": "Welcome"}},
+	}
+	object, err := ToObject(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := Sign(object, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The bundle as a client sends it back, reformatted
+	data, err := json.MarshalIndent(object, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received map[string]any
+	if err := json.Unmarshal(data, &received); err != nil {
+		t.Fatal(err)
+	}
+
+	if valid, err := Verify(received, signature, "key"); err != nil || !valid {
+		t.Errorf("Expected the signature to verify, got %v %v", valid, err)
+	}
+	if valid, _ := Verify(received, signature, "other key"); valid {
+		t.Errorf("Expected the signature not to verify with another key")
+	}
+	received["defaultRoom"] = "triage-2"
+	if valid, _ := Verify(received, signature, "key"); valid {
+		t.Errorf("Expected the signature not to verify after a change")
+	}
+}
+
+func TestStripAndKeepSecrets(t *testing.T) {
+	stored := &types.SystemConfiguration{
+		ExternalAPI: types.ExternalAPIConfig{WebhookURL: "https://prod.example.com/hook", WebhookSecret: "prod-secret"},
+		Notifications: &types.NotificationSettings{
+			Push: &types.PushSettings{VAPIDPublicKey: "prod-public", VAPIDPrivateKey: "prod-private"},
+		},
+	}
+	exported := &types.SystemConfiguration{
+		ExternalAPI: types.ExternalAPIConfig{WebhookURL: "https://staging.example.com/hook", WebhookSecret: "staging-secret"},
+		Notifications: &types.NotificationSettings{
+			Push: &types.PushSettings{VAPIDPublicKey: "staging-public", VAPIDPrivateKey: "staging-private"},
+		},
+	}
+
+	object, err := ToObject(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	StripSecrets(object)
+	externalAPI := object["externalAPI"].(map[string]any)
+	if _, found := externalAPI["webhookSecret"]; found {
+		t.Errorf("Expected the webhook secret to be stripped, got %v", externalAPI)
+	}
+	push := object["notifications"].(map[string]any)["push"].(map[string]any)
+	if _, found := push["vapidPrivateKey"]; found || push["vapidPublicKey"] != "staging-public" {
+		t.Errorf("Expected only the private key to be stripped, got %v", push)
+	}
+
+	storedObject, err := ToObject(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	KeepSecrets(object, storedObject)
+	var imported types.SystemConfiguration
+	if err := FromObject(object, &imported); err != nil {
+		t.Fatal(err)
+	}
+	expected := types.SystemConfiguration{
+		ExternalAPI: types.ExternalAPIConfig{WebhookURL: "https://staging.example.com/hook", WebhookSecret: "prod-secret"},
+		Notifications: &types.NotificationSettings{
+			Push: &types.PushSettings{VAPIDPublicKey: "staging-public", VAPIDPrivateKey: "prod-private"},
+		},
+	}
+	if !reflect.DeepEqual(imported, expected) {
+		t.Errorf("Expected the stored secrets to be kept, got %+v", imported)
+	}
+}
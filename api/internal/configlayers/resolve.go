@@ -0,0 +1,150 @@
+// Package configlayers resolves the effective system configuration of a section from the
+// configurations stored globally, for its tenant and for the section itself.
+package configlayers
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// Names of the layers, from the least to the most specific
+const (
+	LayerGlobal  = "global"
+	LayerTenant  = "tenant"
+	LayerSection = "section"
+)
+
+// Layer is the configuration stored at one layer, nil when the layer has none
+type Layer struct {
+	Name   string
+	Config *types.SystemConfiguration
+}
+
+// metadataFields are the fields describing the stored document rather than configuring anything,
+// taken from the most specific layer instead of being merged
+var metadataFields = map[string]bool{"id": true, "tenantId": true, "sectionId": true, "createdAt": true, "updatedAt": true}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Resolve merges the layers, given from the least to the most specific, into the effective
+// configuration. A layer overrides the values it sets: objects and maps are merged field by
+// field and key by key, arrays and other values are replaced as a whole. An empty value (zero,
+// empty string, false, empty array or map, nil) inherits the value of the layer below; a set
+// pointer, e.g. multilingualSupport false, overrides. Fields not serialized to JSON, like the API
+// keys, are left out: they belong to the layer storing them.
+//
+// The sources map the dotted JSON path of each value set in the result, e.g. externalAPI.webhookUrl,
+// to the name of the layer it comes from. Resolve returns nil when no layer has a configuration.
+func Resolve(layers []Layer) (*types.SystemConfiguration, map[string]string) {
+	var resolved *types.SystemConfiguration
+	sources := map[string]string{}
+	for _, layer := range layers {
+		if layer.Config == nil {
+			continue
+		}
+		if resolved == nil {
+			resolved = &types.SystemConfiguration{}
+		}
+		overlay(reflect.ValueOf(resolved).Elem(), reflect.ValueOf(layer.Config).Elem(), "", layer.Name, sources)
+
+		resolved.ID = layer.Config.ID
+		resolved.TenantID = layer.Config.TenantID
+		resolved.SectionID = layer.Config.SectionID
+		resolved.CreatedAt = layer.Config.CreatedAt
+		resolved.UpdatedAt = layer.Config.UpdatedAt
+	}
+	return resolved, sources
+}
+
+// overlay sets the values src sets on dst, which only holds values allocated by Resolve or
+// shared with layers below
+func overlay(dst, src reflect.Value, path, layer string, sources map[string]string) {
+	switch {
+	case src.Kind() == reflect.Struct && src.Type() != timeType:
+		for i := 0; i < src.NumField(); i++ {
+			field := src.Type().Field(i)
+			name := jsonName(field)
+			if !field.IsExported() || name == "" || (path == "" && metadataFields[name]) {
+				continue
+			}
+			overlay(dst.Field(i), src.Field(i), join(path, name), layer, sources)
+		}
+
+	case src.Kind() == reflect.Pointer && src.Type().Elem().Kind() == reflect.Struct:
+		if src.IsNil() {
+			return
+		}
+		merged := reflect.New(src.Type().Elem())
+		if !dst.IsNil() {
+			merged.Elem().Set(dst.Elem())
+		}
+		overlay(merged.Elem(), src.Elem(), path, layer, sources)
+		dst.Set(merged)
+
+	case src.Kind() == reflect.Map:
+		if src.Len() == 0 {
+			return
+		}
+		merged := reflect.MakeMapWithSize(src.Type(), dst.Len()+src.Len())
+		for _, key := range dst.MapKeys() {
+			merged.SetMapIndex(key, dst.MapIndex(key))
+		}
+		for _, key := range src.MapKeys() {
+			value := reflect.New(src.Type().Elem()).Elem()
+			if existing := merged.MapIndex(key); existing.IsValid() {
+				value.Set(existing)
+			}
+			overlay(value, src.MapIndex(key), join(path, key.String()), layer, sources)
+			if !value.IsZero() {
+				merged.SetMapIndex(key, value)
+			}
+		}
+		dst.Set(merged)
+
+	default:
+		if isEmpty(src) {
+			return
+		}
+		dst.Set(src)
+		sources[path] = layer
+	}
+}
+
+func isEmpty(v reflect.Value) bool {
+	if v.Kind() == reflect.Slice {
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// jsonName returns the JSON name of the field, empty when it isn't serialized
+func jsonName(field reflect.StructField) string {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// Inherits reports whether the configuration of tenantID ("buildingId:sectionId", "buildingId" or
+// empty for the global one) is resolved from the layer changedTenantID stores, so a change of it
+// changes the configuration of tenantID
+func Inherits(tenantID, changedTenantID string) bool {
+	if changedTenantID == "" || tenantID == changedTenantID {
+		return true
+	}
+	return !strings.Contains(changedTenantID, ":") && strings.HasPrefix(tenantID, changedTenantID+":")
+}
@@ -0,0 +1,130 @@
+package configlayers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+func TestResolveOverridesByLayer(t *testing.T) {
+	multilingual := true
+	off := false
+	global := &types.SystemConfiguration{
+		ID:            "global",
+		DefaultRoom:   "triage-1",
+		WebSocketPath: "/ws/queue",
+		ExternalAPI:   types.ExternalAPIConfig{WebhookURL: "https://his.example.com/hook", TimeoutSeconds: 10, MultilingualSupport: &multilingual, SupportedLanguages: []string{"en", "sk"}},
+		Rooms:         []types.RoomConfig{{ID: "triage-1", Name: "Triage"}},
+		Retention:     &types.RetentionPolicy{PurgeAfterDays: 30, KeepHashedIDNumber: true},
+		Messages:      map[string]map[string]string{"en": {"welcome": "Welcome", "swipe": "Swipe your card"}},
+	}
+	tenant := &types.SystemConfiguration{
+		ID:          "b1",
+		TenantID:    "b1",
+		ExternalAPI: types.ExternalAPIConfig{TimeoutSeconds: 20, SupportedLanguages: []string{"de"}},
+		Retention:   &types.RetentionPolicy{PurgeAfterDays: 7},
+		Messages:    map[string]map[string]string{"en": {"welcome": "Welcome to b1"}},
+	}
+	section := &types.SystemConfiguration{
+		ID:          "b1:s1",
+		TenantID:    "b1",
+		SectionID:   "s1",
+		Rooms:       []types.RoomConfig{{ID: "cardio-1", Name: "Cardiology"}},
+		ExternalAPI: types.ExternalAPIConfig{MultilingualSupport: &off},
+		APIKeys:     []types.APIKey{{ID: "k1"}},
+	}
+
+	resolved, sources := Resolve([]Layer{
+		{Name: LayerGlobal, Config: global},
+		{Name: LayerTenant, Config: tenant},
+		{Name: LayerSection, Config: section},
+	})
+
+	if resolved.ID != "b1:s1" || resolved.TenantID != "b1" || resolved.SectionID != "s1" {
+		t.Errorf("Expected the metadata of the section, got %s %s %s", resolved.ID, resolved.TenantID, resolved.SectionID)
+	}
+	if resolved.DefaultRoom != "triage-1" || resolved.WebSocketPath != "/ws/queue" {
+		t.Errorf("Expected the global values to be inherited, got %q %q", resolved.DefaultRoom, resolved.WebSocketPath)
+	}
+	if resolved.ExternalAPI.WebhookURL != "https://his.example.com/hook" || resolved.ExternalAPI.TimeoutSeconds != 20 {
+		t.Errorf("Expected the external API to be merged field by field, got %+v", resolved.ExternalAPI)
+	}
+	if !reflect.DeepEqual(resolved.ExternalAPI.SupportedLanguages, []string{"de"}) {
+		t.Errorf("Expected arrays to be replaced, got %v", resolved.ExternalAPI.SupportedLanguages)
+	}
+	if resolved.ExternalAPI.MultilingualSupport == nil || *resolved.ExternalAPI.MultilingualSupport {
+		t.Errorf("Expected the section to switch off multilingual support")
+	}
+	if len(resolved.Rooms) != 1 || resolved.Rooms[0].ID != "cardio-1" {
+		t.Errorf("Expected the rooms of the section, got %+v", resolved.Rooms)
+	}
+	if !reflect.DeepEqual(*resolved.Retention, types.RetentionPolicy{PurgeAfterDays: 7, KeepHashedIDNumber: true}) {
+		t.Errorf("Expected the retention policy to be merged, got %+v", resolved.Retention)
+	}
+	expectedMessages := map[string]map[string]string{"en": {"welcome": "Welcome to b1", "swipe": "Swipe your card"}}
+	if !reflect.DeepEqual(resolved.Messages, expectedMessages) {
+		t.Errorf("Expected the message catalogs to be merged key by key, got %v", resolved.Messages)
+	}
+	if resolved.APIKeys != nil {
+		t.Errorf("Expected the API keys not to be resolved, got %+v", resolved.APIKeys)
+	}
+
+	expectedSources := map[string]string{
+		"defaultRoom":                     LayerGlobal,
+		"webSocketPath":                   LayerGlobal,
+		"externalAPI.webhookUrl":          LayerGlobal,
+		"externalAPI.timeoutSeconds":      LayerTenant,
+		"externalAPI.supportedLanguages":  LayerTenant,
+		"externalAPI.multilingualSupport": LayerSection,
+		"rooms":                           LayerSection,
+		"retention.purgeAfterDays":        LayerTenant,
+		"retention.keepHashedIdNumber":    LayerGlobal,
+		"messages.en.welcome":             LayerTenant,
+		"messages.en.swipe":               LayerGlobal,
+	}
+	if !reflect.DeepEqual(sources, expectedSources) {
+		t.Errorf("Expected sources %v, got %v", expectedSources, sources)
+	}
+
+	if global.Retention.PurgeAfterDays != 30 || global.Messages["en"]["welcome"] != "Welcome" {
+		t.Errorf("Expected the layers to be left unchanged")
+	}
+}
+
+func TestResolveMissingLayers(t *testing.T) {
+	if resolved, sources := Resolve([]Layer{{Name: LayerGlobal}, {Name: LayerTenant}}); resolved != nil || len(sources) != 0 {
+		t.Errorf("Expected nothing without configurations, got %+v %v", resolved, sources)
+	}
+
+	tenant := &types.SystemConfiguration{ID: "b1", TenantID: "b1", DefaultRoom: "triage-1"}
+	resolved, sources := Resolve([]Layer{{Name: LayerGlobal}, {Name: LayerTenant, Config: tenant}, {Name: LayerSection}})
+	if resolved == nil || resolved.ID != "b1" || resolved.DefaultRoom != "triage-1" {
+		t.Errorf("Expected the configuration of the tenant, got %+v", resolved)
+	}
+	if !reflect.DeepEqual(sources, map[string]string{"defaultRoom": LayerTenant}) {
+		t.Errorf("Expected the values to come from the tenant, got %v", sources)
+	}
+}
+
+func TestInherits(t *testing.T) {
+	tests := []struct {
+		tenantID, changedTenantID string
+		expected                  bool
+	}{
+		{"b1:s1", "", true},
+		{"", "", true},
+		{"b1:s1", "b1", true},
+		{"b1", "b1", true},
+		{"b1:s1", "b1:s1", true},
+		{"b1", "b1:s1", false},
+		{"b1:s2", "b1:s1", false},
+		{"b10:s1", "b1", false},
+		{"", "b1", false},
+	}
+	for _, test := range tests {
+		if got := Inherits(test.tenantID, test.changedTenantID); got != test.expected {
+			t.Errorf("Expected Inherits(%q, %q) to be %v", test.tenantID, test.changedTenantID, test.expected)
+		}
+	}
+}
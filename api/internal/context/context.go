@@ -9,6 +9,9 @@ var (
 	COMPANY   APP_CONTEXT = "COMPANY"
 	USER_ID   APP_CONTEXT = "USER_ID"
 	REQUESTID APP_CONTEXT = "REQUEST_ID"
+	TENANT    APP_CONTEXT = "TENANT"
+
+	ORIGINAL_TOKEN APP_CONTEXT = "ORIGINAL_TOKEN"
 
 	CLIENT_NAME string = "CLIENT_NAME"
 	CLIENT_OP   string = "CLIENT_OP"
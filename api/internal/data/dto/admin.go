@@ -1,7 +1,108 @@
 // Code generated by go generate; DO NOT EDIT.
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/arfis/waiting-room/internal/data/dto/assignmentstrategy"
+	"github.com/arfis/waiting-room/internal/data/dto/queueordering"
+	"github.com/arfis/waiting-room/internal/data/dto/servicefallback"
+)
+
+type APIKey struct {
+	CreatedAt  time.Time  `json:"createdAt" validate:"required"`
+	DeviceId   *string    `json:"deviceId,omitempty"`
+	DeviceType string     `json:"deviceType" validate:"required"`
+	Id         string     `json:"id" validate:"required"`
+	KeyPrefix  string     `json:"keyPrefix" validate:"required"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	LastUsedIp *string    `json:"lastUsedIp,omitempty"`
+	Name       string     `json:"name" validate:"required"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	TenantId   *string    `json:"tenantId,omitempty"`
+}
+
+func (aPIKey APIKey) GetCreatedAt() time.Time {
+	return aPIKey.CreatedAt
+}
+
+func (aPIKey APIKey) GetDeviceId() string {
+	var v string
+	if aPIKey.DeviceId != nil {
+		return *aPIKey.DeviceId
+	}
+	return v
+}
+
+func (aPIKey APIKey) GetDeviceType() string {
+	return aPIKey.DeviceType
+}
+
+func (aPIKey APIKey) GetId() string {
+	return aPIKey.Id
+}
+
+func (aPIKey APIKey) GetKeyPrefix() string {
+	return aPIKey.KeyPrefix
+}
+
+func (aPIKey APIKey) GetLastUsedAt() time.Time {
+	var v time.Time
+	if aPIKey.LastUsedAt != nil {
+		return *aPIKey.LastUsedAt
+	}
+	return v
+}
+
+func (aPIKey APIKey) GetLastUsedIp() string {
+	var v string
+	if aPIKey.LastUsedIp != nil {
+		return *aPIKey.LastUsedIp
+	}
+	return v
+}
+
+func (aPIKey APIKey) GetName() string {
+	return aPIKey.Name
+}
+
+func (aPIKey APIKey) GetRevokedAt() time.Time {
+	var v time.Time
+	if aPIKey.RevokedAt != nil {
+		return *aPIKey.RevokedAt
+	}
+	return v
+}
+
+func (aPIKey APIKey) GetTenantId() string {
+	var v string
+	if aPIKey.TenantId != nil {
+		return *aPIKey.TenantId
+	}
+	return v
+}
+
+type APIKeyRequest struct {
+	DeviceId   *string `json:"deviceId,omitempty"`
+	DeviceType string  `json:"deviceType" validate:"required"`
+	Name       string  `json:"name" validate:"required"`
+}
+
+func (aPIKeyRequest APIKeyRequest) GetDeviceId() string {
+	var v string
+	if aPIKeyRequest.DeviceId != nil {
+		return *aPIKeyRequest.DeviceId
+	}
+	return v
+}
+
+func (aPIKeyRequest APIKeyRequest) GetDeviceType() string {
+	return aPIKeyRequest.DeviceType
+}
+
+func (aPIKeyRequest APIKeyRequest) GetName() string {
+	return aPIKeyRequest.Name
+}
 
 type AgeConfig struct {
 	AgeThresholdSenior   int64   `json:"ageThresholdSenior"`
@@ -77,16 +178,302 @@ func (cacheClearResponse CacheClearResponse) GetMessage() string {
 	return v
 }
 
+type CardReaderCommand struct {
+	AcknowledgedAt *time.Time `json:"acknowledgedAt,omitempty"`
+	Command        string     `json:"command" validate:"required"`
+	CompletedAt    *time.Time `json:"completedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt" validate:"required"`
+	DeviceId       string     `json:"deviceId" validate:"required"`
+	Error          *string    `json:"error,omitempty"`
+	Id             string     `json:"id" validate:"required"`
+	Output         *string    `json:"output,omitempty"`
+	RequestedBy    *string    `json:"requestedBy,omitempty"`
+	SentAt         *time.Time `json:"sentAt,omitempty"`
+	Status         string     `json:"status" validate:"required"`
+}
+
+func (cardReaderCommand CardReaderCommand) GetAcknowledgedAt() time.Time {
+	var v time.Time
+	if cardReaderCommand.AcknowledgedAt != nil {
+		return *cardReaderCommand.AcknowledgedAt
+	}
+	return v
+}
+
+func (cardReaderCommand CardReaderCommand) GetCommand() string {
+	return cardReaderCommand.Command
+}
+
+func (cardReaderCommand CardReaderCommand) GetCompletedAt() time.Time {
+	var v time.Time
+	if cardReaderCommand.CompletedAt != nil {
+		return *cardReaderCommand.CompletedAt
+	}
+	return v
+}
+
+func (cardReaderCommand CardReaderCommand) GetCreatedAt() time.Time {
+	return cardReaderCommand.CreatedAt
+}
+
+func (cardReaderCommand CardReaderCommand) GetDeviceId() string {
+	return cardReaderCommand.DeviceId
+}
+
+func (cardReaderCommand CardReaderCommand) GetError() string {
+	var v string
+	if cardReaderCommand.Error != nil {
+		return *cardReaderCommand.Error
+	}
+	return v
+}
+
+func (cardReaderCommand CardReaderCommand) GetId() string {
+	return cardReaderCommand.Id
+}
+
+func (cardReaderCommand CardReaderCommand) GetOutput() string {
+	var v string
+	if cardReaderCommand.Output != nil {
+		return *cardReaderCommand.Output
+	}
+	return v
+}
+
+func (cardReaderCommand CardReaderCommand) GetRequestedBy() string {
+	var v string
+	if cardReaderCommand.RequestedBy != nil {
+		return *cardReaderCommand.RequestedBy
+	}
+	return v
+}
+
+func (cardReaderCommand CardReaderCommand) GetSentAt() time.Time {
+	var v time.Time
+	if cardReaderCommand.SentAt != nil {
+		return *cardReaderCommand.SentAt
+	}
+	return v
+}
+
+func (cardReaderCommand CardReaderCommand) GetStatus() string {
+	return cardReaderCommand.Status
+}
+
+type CardReaderCommandRequest struct {
+	Command string `json:"command" validate:"required"`
+}
+
+func (cardReaderCommandRequest CardReaderCommandRequest) GetCommand() string {
+	return cardReaderCommandRequest.Command
+}
+
+type CardReaderConfig struct {
+	AutoCheckIn                *bool      `json:"autoCheckIn,omitempty"`
+	AutoCheckInServiceDuration *int64     `json:"autoCheckInServiceDuration,omitempty"`
+	AutoCheckInServiceId       *string    `json:"autoCheckInServiceId,omitempty"`
+	ClientCertFingerprint      *string    `json:"clientCertFingerprint,omitempty"`
+	DeviceId                   string     `json:"deviceId" validate:"required"`
+	HasDeviceToken             bool       `json:"hasDeviceToken"`
+	HasEncryptionKey           bool       `json:"hasEncryptionKey"`
+	Pkcs11Module               *string    `json:"pkcs11Module,omitempty"`
+	ReaderName                 *string    `json:"readerName,omitempty"`
+	RefreshIntervalSeconds     *int64     `json:"refreshIntervalSeconds,omitempty"`
+	RoomId                     string     `json:"roomId" validate:"required"`
+	TenantId                   *string    `json:"tenantId,omitempty"`
+	UpdatedAt                  *time.Time `json:"updatedAt,omitempty"`
+	WebSocketUrl               *string    `json:"webSocketUrl,omitempty"`
+}
+
+func (cardReaderConfig CardReaderConfig) GetAutoCheckIn() bool {
+	var v bool
+	if cardReaderConfig.AutoCheckIn != nil {
+		return *cardReaderConfig.AutoCheckIn
+	}
+	return v
+}
+
+func (cardReaderConfig CardReaderConfig) GetAutoCheckInServiceDuration() int64 {
+	var v int64
+	if cardReaderConfig.AutoCheckInServiceDuration != nil {
+		return *cardReaderConfig.AutoCheckInServiceDuration
+	}
+	return v
+}
+
+func (cardReaderConfig CardReaderConfig) GetAutoCheckInServiceId() string {
+	var v string
+	if cardReaderConfig.AutoCheckInServiceId != nil {
+		return *cardReaderConfig.AutoCheckInServiceId
+	}
+	return v
+}
+
+func (cardReaderConfig CardReaderConfig) GetClientCertFingerprint() string {
+	var v string
+	if cardReaderConfig.ClientCertFingerprint != nil {
+		return *cardReaderConfig.ClientCertFingerprint
+	}
+	return v
+}
+
+func (cardReaderConfig CardReaderConfig) GetDeviceId() string {
+	return cardReaderConfig.DeviceId
+}
+
+func (cardReaderConfig CardReaderConfig) GetHasDeviceToken() bool {
+	return cardReaderConfig.HasDeviceToken
+}
+
+func (cardReaderConfig CardReaderConfig) GetHasEncryptionKey() bool {
+	return cardReaderConfig.HasEncryptionKey
+}
+
+func (cardReaderConfig CardReaderConfig) GetPkcs11Module() string {
+	var v string
+	if cardReaderConfig.Pkcs11Module != nil {
+		return *cardReaderConfig.Pkcs11Module
+	}
+	return v
+}
+
+func (cardReaderConfig CardReaderConfig) GetReaderName() string {
+	var v string
+	if cardReaderConfig.ReaderName != nil {
+		return *cardReaderConfig.ReaderName
+	}
+	return v
+}
+
+func (cardReaderConfig CardReaderConfig) GetRefreshIntervalSeconds() int64 {
+	var v int64
+	if cardReaderConfig.RefreshIntervalSeconds != nil {
+		return *cardReaderConfig.RefreshIntervalSeconds
+	}
+	return v
+}
+
+func (cardReaderConfig CardReaderConfig) GetRoomId() string {
+	return cardReaderConfig.RoomId
+}
+
+func (cardReaderConfig CardReaderConfig) GetTenantId() string {
+	var v string
+	if cardReaderConfig.TenantId != nil {
+		return *cardReaderConfig.TenantId
+	}
+	return v
+}
+
+func (cardReaderConfig CardReaderConfig) GetUpdatedAt() time.Time {
+	var v time.Time
+	if cardReaderConfig.UpdatedAt != nil {
+		return *cardReaderConfig.UpdatedAt
+	}
+	return v
+}
+
+func (cardReaderConfig CardReaderConfig) GetWebSocketUrl() string {
+	var v string
+	if cardReaderConfig.WebSocketUrl != nil {
+		return *cardReaderConfig.WebSocketUrl
+	}
+	return v
+}
+
+type CardReaderEncryptionKey struct {
+	DeviceId string `json:"deviceId" validate:"required"`
+	Key      string `json:"key" validate:"required"`
+	KeyId    string `json:"keyId" validate:"required"`
+}
+
+func (cardReaderEncryptionKey CardReaderEncryptionKey) GetDeviceId() string {
+	return cardReaderEncryptionKey.DeviceId
+}
+
+func (cardReaderEncryptionKey CardReaderEncryptionKey) GetKey() string {
+	return cardReaderEncryptionKey.Key
+}
+
+func (cardReaderEncryptionKey CardReaderEncryptionKey) GetKeyId() string {
+	return cardReaderEncryptionKey.KeyId
+}
+
+type CardReaderRelease struct {
+	Arch        string     `json:"arch" validate:"required"`
+	Os          string     `json:"os" validate:"required"`
+	PublishedAt *time.Time `json:"publishedAt,omitempty"`
+	Sha256      string     `json:"sha256" validate:"required"`
+	Signature   string     `json:"signature" validate:"required"`
+	Url         string     `json:"url" validate:"required"`
+	Version     string     `json:"version" validate:"required"`
+}
+
+func (cardReaderRelease CardReaderRelease) GetArch() string {
+	return cardReaderRelease.Arch
+}
+
+func (cardReaderRelease CardReaderRelease) GetOs() string {
+	return cardReaderRelease.Os
+}
+
+func (cardReaderRelease CardReaderRelease) GetPublishedAt() time.Time {
+	var v time.Time
+	if cardReaderRelease.PublishedAt != nil {
+		return *cardReaderRelease.PublishedAt
+	}
+	return v
+}
+
+func (cardReaderRelease CardReaderRelease) GetSha256() string {
+	return cardReaderRelease.Sha256
+}
+
+func (cardReaderRelease CardReaderRelease) GetSignature() string {
+	return cardReaderRelease.Signature
+}
+
+func (cardReaderRelease CardReaderRelease) GetUrl() string {
+	return cardReaderRelease.Url
+}
+
+func (cardReaderRelease CardReaderRelease) GetVersion() string {
+	return cardReaderRelease.Version
+}
+
+type CardReaderToken struct {
+	DeviceId string `json:"deviceId" validate:"required"`
+	Token    string `json:"token" validate:"required"`
+}
+
+func (cardReaderToken CardReaderToken) GetDeviceId() string {
+	return cardReaderToken.DeviceId
+}
+
+func (cardReaderToken CardReaderToken) GetToken() string {
+	return cardReaderToken.Token
+}
+
 type CardReaderStatus struct {
-	CreatedAt *time.Time `json:"createdAt,omitempty"`
-	Id        string     `json:"id" validate:"required"`
-	IpAddress *string    `json:"ipAddress,omitempty"`
-	LastError *string    `json:"lastError,omitempty"`
-	LastSeen  *time.Time `json:"lastSeen,omitempty"`
-	Name      string     `json:"name" validate:"required"`
-	Status    string     `json:"status" validate:"required"`
-	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
-	Version   *string    `json:"version,omitempty"`
+	ApiKeyLastUsedAt *time.Time         `json:"apiKeyLastUsedAt,omitempty"`
+	CreatedAt        *time.Time         `json:"createdAt,omitempty"`
+	Id               string             `json:"id" validate:"required"`
+	IpAddress        *string            `json:"ipAddress,omitempty"`
+	LastCommand      *CardReaderCommand `json:"lastCommand,omitempty"`
+	LastError        *string            `json:"lastError,omitempty"`
+	LastSeen         *time.Time         `json:"lastSeen,omitempty"`
+	Name             string             `json:"name" validate:"required"`
+	Status           string             `json:"status" validate:"required"`
+	UpdatedAt        *time.Time         `json:"updatedAt,omitempty"`
+	Version          *string            `json:"version,omitempty"`
+}
+
+func (cardReaderStatus CardReaderStatus) GetApiKeyLastUsedAt() time.Time {
+	var v time.Time
+	if cardReaderStatus.ApiKeyLastUsedAt != nil {
+		return *cardReaderStatus.ApiKeyLastUsedAt
+	}
+	return v
 }
 
 func (cardReaderStatus CardReaderStatus) GetCreatedAt() time.Time {
@@ -109,6 +496,14 @@ func (cardReaderStatus CardReaderStatus) GetIpAddress() string {
 	return v
 }
 
+func (cardReaderStatus CardReaderStatus) GetLastCommand() CardReaderCommand {
+	var v CardReaderCommand
+	if cardReaderStatus.LastCommand != nil {
+		return *cardReaderStatus.LastCommand
+	}
+	return v
+}
+
 func (cardReaderStatus CardReaderStatus) GetLastError() string {
 	var v string
 	if cardReaderStatus.LastError != nil {
@@ -149,6 +544,83 @@ func (cardReaderStatus CardReaderStatus) GetVersion() string {
 	return v
 }
 
+type ConfigurationBundle struct {
+	Configuration map[string]interface{}     `json:"configuration" validate:"required"`
+	ExportedAt    time.Time                  `json:"exportedAt" validate:"required"`
+	Glossary      []TranslationGlossaryEntry `json:"glossary" validate:"required,dive"`
+	Priority      *PriorityConfig            `json:"priority" validate:"required"`
+	Signature     string                     `json:"signature" validate:"required"`
+	TenantId      *string                    `json:"tenantId,omitempty"`
+	Version       int64                      `json:"version"`
+}
+
+func (configurationBundle ConfigurationBundle) GetConfiguration() map[string]interface{} {
+	return configurationBundle.Configuration
+}
+
+func (configurationBundle ConfigurationBundle) GetExportedAt() time.Time {
+	return configurationBundle.ExportedAt
+}
+
+func (configurationBundle ConfigurationBundle) GetGlossary() []TranslationGlossaryEntry {
+	return configurationBundle.Glossary
+}
+
+func (configurationBundle ConfigurationBundle) GetPriority() PriorityConfig {
+	var v PriorityConfig
+	if configurationBundle.Priority != nil {
+		return *configurationBundle.Priority
+	}
+	return v
+}
+
+func (configurationBundle ConfigurationBundle) GetSignature() string {
+	return configurationBundle.Signature
+}
+
+func (configurationBundle ConfigurationBundle) GetTenantId() string {
+	var v string
+	if configurationBundle.TenantId != nil {
+		return *configurationBundle.TenantId
+	}
+	return v
+}
+
+func (configurationBundle ConfigurationBundle) GetVersion() int64 {
+	return configurationBundle.Version
+}
+
+type ConfigurationImportResult struct {
+	Applied        bool          `json:"applied"`
+	Changes        []AuditChange `json:"changes" validate:"required,dive"`
+	ExportedAt     *time.Time    `json:"exportedAt,omitempty"`
+	SourceTenantId *string       `json:"sourceTenantId,omitempty"`
+}
+
+func (configurationImportResult ConfigurationImportResult) GetApplied() bool {
+	return configurationImportResult.Applied
+}
+
+func (configurationImportResult ConfigurationImportResult) GetChanges() []AuditChange {
+	return configurationImportResult.Changes
+}
+
+func (configurationImportResult ConfigurationImportResult) GetExportedAt() time.Time {
+	var v time.Time
+	if configurationImportResult.ExportedAt != nil {
+		return *configurationImportResult.ExportedAt
+	}
+	return v
+}
+
+func (configurationImportResult ConfigurationImportResult) GetSourceTenantId() string {
+	var v string
+	if configurationImportResult.SourceTenantId != nil {
+		return *configurationImportResult.SourceTenantId
+	}
+	return v
+}
+
 type Contributions struct {
 	Age                  *AgeConfig            `json:"age" validate:"required"`
 	AppointmentDeviation *AppointmentDeviation `json:"appointmentDeviation" validate:"required"`
@@ -229,19 +701,27 @@ type ExternalAPIConfig struct {
 	AppointmentServicesLanguageHandling *string           `json:"appointmentServicesLanguageHandling,omitempty"`
 	AppointmentServicesLanguageHeader   *string           `json:"appointmentServicesLanguageHeader,omitempty"`
 	AppointmentServicesUrl              *string           `json:"appointmentServicesUrl,omitempty"`
+	DefaultLanguage                     *string           `json:"defaultLanguage,omitempty"`
 	GenericServices                     []GenericService  `json:"genericServices,omitempty" validate:"dive"`
 	GenericServicesHttpMethod           *string           `json:"genericServicesHttpMethod,omitempty"`
 	GenericServicesLanguageHandling     *string           `json:"genericServicesLanguageHandling,omitempty"`
 	GenericServicesLanguageHeader       *string           `json:"genericServicesLanguageHeader,omitempty"`
 	GenericServicesUrl                  *string           `json:"genericServicesUrl,omitempty"`
 	Headers                             map[string]string `json:"headers,omitempty"`
+	HisProvider                         *string           `json:"hisProvider,omitempty"`
 	MultilingualSupport                 *bool             `json:"multilingualSupport,omitempty"`
+	NghisAdapterUrl                     *string           `json:"nghisAdapterUrl,omitempty"`
 	RetryAttempts                       int64             `json:"retryAttempts"`
 	SupportedLanguages                  []string          `json:"supportedLanguages,omitempty" validate:"dive"`
 	TimeoutSeconds                      int64             `json:"timeoutSeconds"`
+	TranslationProvider                 *string           `json:"translationProvider,omitempty"`
 	UseDeepLTranslation                 *bool             `json:"useDeepLTranslation,omitempty"`
+	VisitNotificationUrl                *string           `json:"visitNotificationUrl,omitempty"`
+	WebhookEvents                       []string          `json:"webhookEvents,omitempty" validate:"dive"`
 	WebhookHttpMethod                   *string           `json:"webhookHttpMethod,omitempty"`
+	WebhookPayloadTemplate              *string           `json:"webhookPayloadTemplate,omitempty"`
 	WebhookRetryAttempts                *int64            `json:"webhookRetryAttempts,omitempty"`
+	WebhookSecret                       *string           `json:"webhookSecret,omitempty"`
 	WebhookTimeoutSeconds               *int64            `json:"webhookTimeoutSeconds,omitempty"`
 	WebhookUrl                          *string           `json:"webhookUrl,omitempty"`
 }
@@ -278,6 +758,14 @@ func (externalAPIConfig ExternalAPIConfig) GetAppointmentServicesUrl() string {
 	return v
 }
 
+func (externalAPIConfig ExternalAPIConfig) GetDefaultLanguage() string {
+	var v string
+	if externalAPIConfig.DefaultLanguage != nil {
+		return *externalAPIConfig.DefaultLanguage
+	}
+	return v
+}
+
 func (externalAPIConfig ExternalAPIConfig) GetGenericServices() []GenericService {
 	return externalAPIConfig.GenericServices
 }
@@ -318,6 +806,14 @@ func (externalAPIConfig ExternalAPIConfig) GetHeaders() map[string]string {
 	return externalAPIConfig.Headers
 }
 
+func (externalAPIConfig ExternalAPIConfig) GetHisProvider() string {
+	var v string
+	if externalAPIConfig.HisProvider != nil {
+		return *externalAPIConfig.HisProvider
+	}
+	return v
+}
+
 func (externalAPIConfig ExternalAPIConfig) GetMultilingualSupport() bool {
 	var v bool
 	if externalAPIConfig.MultilingualSupport != nil {
@@ -326,6 +822,14 @@ func (externalAPIConfig ExternalAPIConfig) GetMultilingualSupport() bool {
 	return v
 }
 
+func (externalAPIConfig ExternalAPIConfig) GetNghisAdapterUrl() string {
+	var v string
+	if externalAPIConfig.NghisAdapterUrl != nil {
+		return *externalAPIConfig.NghisAdapterUrl
+	}
+	return v
+}
+
 func (externalAPIConfig ExternalAPIConfig) GetRetryAttempts() int64 {
 	return externalAPIConfig.RetryAttempts
 }
@@ -338,6 +842,14 @@ func (externalAPIConfig ExternalAPIConfig) GetTimeoutSeconds() int64 {
 	return externalAPIConfig.TimeoutSeconds
 }
 
+func (externalAPIConfig ExternalAPIConfig) GetTranslationProvider() string {
+	var v string
+	if externalAPIConfig.TranslationProvider != nil {
+		return *externalAPIConfig.TranslationProvider
+	}
+	return v
+}
+
 func (externalAPIConfig ExternalAPIConfig) GetUseDeepLTranslation() bool {
 	var v bool
 	if externalAPIConfig.UseDeepLTranslation != nil {
@@ -346,15 +858,35 @@ func (externalAPIConfig ExternalAPIConfig) GetUseDeepLTranslation() bool {
 	return v
 }
 
-func (externalAPIConfig ExternalAPIConfig) GetWebhookHttpMethod() string {
+func (externalAPIConfig ExternalAPIConfig) GetVisitNotificationUrl() string {
 	var v string
-	if externalAPIConfig.WebhookHttpMethod != nil {
-		return *externalAPIConfig.WebhookHttpMethod
+	if externalAPIConfig.VisitNotificationUrl != nil {
+		return *externalAPIConfig.VisitNotificationUrl
 	}
 	return v
 }
 
-func (externalAPIConfig ExternalAPIConfig) GetWebhookRetryAttempts() int64 {
+func (externalAPIConfig ExternalAPIConfig) GetWebhookEvents() []string {
+	return externalAPIConfig.WebhookEvents
+}
+
+func (externalAPIConfig ExternalAPIConfig) GetWebhookHttpMethod() string {
+	var v string
+	if externalAPIConfig.WebhookHttpMethod != nil {
+		return *externalAPIConfig.WebhookHttpMethod
+	}
+	return v
+}
+
+func (externalAPIConfig ExternalAPIConfig) GetWebhookPayloadTemplate() string {
+	var v string
+	if externalAPIConfig.WebhookPayloadTemplate != nil {
+		return *externalAPIConfig.WebhookPayloadTemplate
+	}
+	return v
+}
+
+func (externalAPIConfig ExternalAPIConfig) GetWebhookRetryAttempts() int64 {
 	var v int64
 	if externalAPIConfig.WebhookRetryAttempts != nil {
 		return *externalAPIConfig.WebhookRetryAttempts
@@ -362,236 +894,1122 @@ func (externalAPIConfig ExternalAPIConfig) GetWebhookRetryAttempts() int64 {
 	return v
 }
 
-func (externalAPIConfig ExternalAPIConfig) GetWebhookTimeoutSeconds() int64 {
+func (externalAPIConfig ExternalAPIConfig) GetWebhookSecret() string {
+	var v string
+	if externalAPIConfig.WebhookSecret != nil {
+		return *externalAPIConfig.WebhookSecret
+	}
+	return v
+}
+
+func (externalAPIConfig ExternalAPIConfig) GetWebhookTimeoutSeconds() int64 {
+	var v int64
+	if externalAPIConfig.WebhookTimeoutSeconds != nil {
+		return *externalAPIConfig.WebhookTimeoutSeconds
+	}
+	return v
+}
+
+func (externalAPIConfig ExternalAPIConfig) GetWebhookUrl() string {
+	var v string
+	if externalAPIConfig.WebhookUrl != nil {
+		return *externalAPIConfig.WebhookUrl
+	}
+	return v
+}
+
+type ExternalAPIEndpointStats struct {
+	AverageLatencyMs float64    `json:"averageLatencyMs"`
+	CircuitOpen      bool       `json:"circuitOpen"`
+	Endpoint         string     `json:"endpoint" validate:"required"`
+	ErrorRate        float64    `json:"errorRate"`
+	Failures         int64      `json:"failures"`
+	LastError        *string    `json:"lastError,omitempty"`
+	LastFailureAt    *time.Time `json:"lastFailureAt,omitempty"`
+	Requests         int64      `json:"requests"`
+	Retries          int64      `json:"retries"`
+}
+
+func (externalAPIEndpointStats ExternalAPIEndpointStats) GetAverageLatencyMs() float64 {
+	return externalAPIEndpointStats.AverageLatencyMs
+}
+
+func (externalAPIEndpointStats ExternalAPIEndpointStats) GetCircuitOpen() bool {
+	return externalAPIEndpointStats.CircuitOpen
+}
+
+func (externalAPIEndpointStats ExternalAPIEndpointStats) GetEndpoint() string {
+	return externalAPIEndpointStats.Endpoint
+}
+
+func (externalAPIEndpointStats ExternalAPIEndpointStats) GetErrorRate() float64 {
+	return externalAPIEndpointStats.ErrorRate
+}
+
+func (externalAPIEndpointStats ExternalAPIEndpointStats) GetFailures() int64 {
+	return externalAPIEndpointStats.Failures
+}
+
+func (externalAPIEndpointStats ExternalAPIEndpointStats) GetLastError() string {
+	var v string
+	if externalAPIEndpointStats.LastError != nil {
+		return *externalAPIEndpointStats.LastError
+	}
+	return v
+}
+
+func (externalAPIEndpointStats ExternalAPIEndpointStats) GetLastFailureAt() time.Time {
+	var v time.Time
+	if externalAPIEndpointStats.LastFailureAt != nil {
+		return *externalAPIEndpointStats.LastFailureAt
+	}
+	return v
+}
+
+func (externalAPIEndpointStats ExternalAPIEndpointStats) GetRequests() int64 {
+	return externalAPIEndpointStats.Requests
+}
+
+func (externalAPIEndpointStats ExternalAPIEndpointStats) GetRetries() int64 {
+	return externalAPIEndpointStats.Retries
+}
+
+type FhirProfile struct {
+	Profile      string `json:"profile" validate:"required"`
+	ResourceType string `json:"resourceType" validate:"required"`
+}
+
+func (fhirProfile FhirProfile) GetProfile() string {
+	return fhirProfile.Profile
+}
+
+func (fhirProfile FhirProfile) GetResourceType() string {
+	return fhirProfile.ResourceType
+}
+
+type FhirSettings struct {
+	IdentifierSystem *string       `json:"identifierSystem,omitempty"`
+	Profiles         []FhirProfile `json:"profiles,omitempty" validate:"dive"`
+}
+
+func (fhirSettings FhirSettings) GetIdentifierSystem() string {
+	var v string
+	if fhirSettings.IdentifierSystem != nil {
+		return *fhirSettings.IdentifierSystem
+	}
+	return v
+}
+
+func (fhirSettings FhirSettings) GetProfiles() []FhirProfile {
+	return fhirSettings.Profiles
+}
+
+type FitnessConfig struct {
+	Contributions *Contributions `json:"contributions" validate:"required"`
+	Explanation   *string        `json:"explanation,omitempty"`
+	ScheduleRules []ScheduleRule `json:"scheduleRules,omitempty" validate:"dive"`
+}
+
+func (fitnessConfig FitnessConfig) GetContributions() Contributions {
+	var v Contributions
+	if fitnessConfig.Contributions != nil {
+		return *fitnessConfig.Contributions
+	}
+	return v
+}
+
+func (fitnessConfig FitnessConfig) GetExplanation() string {
+	var v string
+	if fitnessConfig.Explanation != nil {
+		return *fitnessConfig.Explanation
+	}
+	return v
+}
+
+func (fitnessConfig FitnessConfig) GetScheduleRules() []ScheduleRule {
+	return fitnessConfig.ScheduleRules
+}
+
+type GenericService struct {
+	Description *string `json:"description,omitempty"`
+	Duration    *int64  `json:"duration,omitempty"`
+	Enabled     bool    `json:"enabled"`
+	Id          string  `json:"id" validate:"required"`
+	Name        string  `json:"name" validate:"required"`
+}
+
+func (genericService GenericService) GetDescription() string {
+	var v string
+	if genericService.Description != nil {
+		return *genericService.Description
+	}
+	return v
+}
+
+func (genericService GenericService) GetDuration() int64 {
+	var v int64
+	if genericService.Duration != nil {
+		return *genericService.Duration
+	}
+	return v
+}
+
+func (genericService GenericService) GetEnabled() bool {
+	return genericService.Enabled
+}
+
+func (genericService GenericService) GetId() string {
+	return genericService.Id
+}
+
+func (genericService GenericService) GetName() string {
+	return genericService.Name
+}
+
+type HttpNotificationSettings struct {
+	Token *string `json:"token,omitempty"`
+	Url   string  `json:"url" validate:"required"`
+}
+
+func (httpNotificationSettings HttpNotificationSettings) GetToken() string {
+	var v string
+	if httpNotificationSettings.Token != nil {
+		return *httpNotificationSettings.Token
+	}
+	return v
+}
+
+func (httpNotificationSettings HttpNotificationSettings) GetUrl() string {
+	return httpNotificationSettings.Url
+}
+
+type IntegrationCredentials struct {
+	ApiKey        string `json:"apiKey" validate:"required"`
+	SigningSecret string `json:"signingSecret" validate:"required"`
+}
+
+func (integrationCredentials IntegrationCredentials) GetApiKey() string {
+	return integrationCredentials.ApiKey
+}
+
+func (integrationCredentials IntegrationCredentials) GetSigningSecret() string {
+	return integrationCredentials.SigningSecret
+}
+
+type IssuedAPIKey struct {
+	ApiKey *APIKey `json:"apiKey" validate:"required"`
+	Key    string  `json:"key" validate:"required"`
+}
+
+func (issuedAPIKey IssuedAPIKey) GetApiKey() APIKey {
+	var v APIKey
+	if issuedAPIKey.ApiKey != nil {
+		return *issuedAPIKey.ApiKey
+	}
+	return v
+}
+
+func (issuedAPIKey IssuedAPIKey) GetKey() string {
+	return issuedAPIKey.Key
+}
+
+type ManualOverride struct {
+	Description *string `json:"description,omitempty"`
+	Enabled     bool    `json:"enabled"`
+	Weight      float64 `json:"weight"`
+}
+
+func (manualOverride ManualOverride) GetDescription() string {
+	var v string
+	if manualOverride.Description != nil {
+		return *manualOverride.Description
+	}
+	return v
+}
+
+func (manualOverride ManualOverride) GetEnabled() bool {
+	return manualOverride.Enabled
+}
+
+func (manualOverride ManualOverride) GetWeight() float64 {
+	return manualOverride.Weight
+}
+
+type NotificationSettings struct {
+	ApproachingMessage     *string                   `json:"approachingMessage,omitempty"`
+	ApproachingPeopleAhead int64                     `json:"approachingPeopleAhead"`
+	CalledMessage          *string                   `json:"calledMessage,omitempty"`
+	Http                   *HttpNotificationSettings `json:"http,omitempty"`
+	Push                   *PushSettings             `json:"push,omitempty"`
+	Sms                    *SmsSettings              `json:"sms,omitempty"`
+	Smtp                   *SmtpSettings             `json:"smtp,omitempty"`
+}
+
+func (notificationSettings NotificationSettings) GetApproachingMessage() string {
+	var v string
+	if notificationSettings.ApproachingMessage != nil {
+		return *notificationSettings.ApproachingMessage
+	}
+	return v
+}
+
+func (notificationSettings NotificationSettings) GetApproachingPeopleAhead() int64 {
+	return notificationSettings.ApproachingPeopleAhead
+}
+
+func (notificationSettings NotificationSettings) GetCalledMessage() string {
+	var v string
+	if notificationSettings.CalledMessage != nil {
+		return *notificationSettings.CalledMessage
+	}
+	return v
+}
+
+func (notificationSettings NotificationSettings) GetHttp() HttpNotificationSettings {
+	var v HttpNotificationSettings
+	if notificationSettings.Http != nil {
+		return *notificationSettings.Http
+	}
+	return v
+}
+
+func (notificationSettings NotificationSettings) GetPush() PushSettings {
+	var v PushSettings
+	if notificationSettings.Push != nil {
+		return *notificationSettings.Push
+	}
+	return v
+}
+
+func (notificationSettings NotificationSettings) GetSms() SmsSettings {
+	var v SmsSettings
+	if notificationSettings.Sms != nil {
+		return *notificationSettings.Sms
+	}
+	return v
+}
+
+func (notificationSettings NotificationSettings) GetSmtp() SmtpSettings {
+	var v SmtpSettings
+	if notificationSettings.Smtp != nil {
+		return *notificationSettings.Smtp
+	}
+	return v
+}
+
+type Pathway struct {
+	Id         string         `json:"id" validate:"required"`
+	Name       string         `json:"name" validate:"required"`
+	ServiceIds []string       `json:"serviceIds,omitempty"`
+	Stages     []PathwayStage `json:"stages" validate:"required,min=1,dive"`
+}
+
+func (pathway Pathway) GetId() string {
+	return pathway.Id
+}
+
+func (pathway Pathway) GetName() string {
+	return pathway.Name
+}
+
+func (pathway Pathway) GetServiceIds() []string {
+	return pathway.ServiceIds
+}
+
+func (pathway Pathway) GetStages() []PathwayStage {
+	return pathway.Stages
+}
+
+type PathwayStage struct {
+	Name           string  `json:"name" validate:"required"`
+	RoomId         string  `json:"roomId" validate:"required"`
+	ServicePointId *string `json:"servicePointId,omitempty"`
+}
+
+func (pathwayStage PathwayStage) GetName() string {
+	return pathwayStage.Name
+}
+
+func (pathwayStage PathwayStage) GetRoomId() string {
+	return pathwayStage.RoomId
+}
+
+func (pathwayStage PathwayStage) GetServicePointId() string {
+	var v string
+	if pathwayStage.ServicePointId != nil {
+		return *pathwayStage.ServicePointId
+	}
+	return v
+}
+
+type PriorityConfig struct {
+	Description   *string           `json:"description,omitempty"`
+	Holidays      []PriorityHoliday `json:"holidays,omitempty" validate:"dive"`
+	PriorityModel *PriorityModel    `json:"priorityModel" validate:"required"`
+	Symbols       []PrioritySymbol  `json:"symbols,omitempty" validate:"dive"`
+	Timezone      *string           `json:"timezone,omitempty"`
+	Version       string            `json:"version" validate:"required"`
+}
+
+func (priorityConfig PriorityConfig) GetDescription() string {
+	var v string
+	if priorityConfig.Description != nil {
+		return *priorityConfig.Description
+	}
+	return v
+}
+
+func (priorityConfig PriorityConfig) GetHolidays() []PriorityHoliday {
+	return priorityConfig.Holidays
+}
+
+func (priorityConfig PriorityConfig) GetPriorityModel() PriorityModel {
+	var v PriorityModel
+	if priorityConfig.PriorityModel != nil {
+		return *priorityConfig.PriorityModel
+	}
+	return v
+}
+
+func (priorityConfig PriorityConfig) GetSymbols() []PrioritySymbol {
+	return priorityConfig.Symbols
+}
+
+func (priorityConfig PriorityConfig) GetTimezone() string {
+	var v string
+	if priorityConfig.Timezone != nil {
+		return *priorityConfig.Timezone
+	}
+	return v
+}
+
+func (priorityConfig PriorityConfig) GetVersion() string {
+	return priorityConfig.Version
+}
+
+type PriorityConfigRevision struct {
+	Config       *PriorityConfig `json:"config" validate:"required"`
+	RestoredFrom *int64          `json:"restoredFrom,omitempty"`
+	Revision     int64           `json:"revision"`
+	SavedAt      time.Time       `json:"savedAt" validate:"required"`
+	SavedBy      *string         `json:"savedBy,omitempty"`
+}
+
+func (priorityConfigRevision PriorityConfigRevision) GetConfig() PriorityConfig {
+	var v PriorityConfig
+	if priorityConfigRevision.Config != nil {
+		return *priorityConfigRevision.Config
+	}
+	return v
+}
+
+func (priorityConfigRevision PriorityConfigRevision) GetRestoredFrom() int64 {
+	var v int64
+	if priorityConfigRevision.RestoredFrom != nil {
+		return *priorityConfigRevision.RestoredFrom
+	}
+	return v
+}
+
+func (priorityConfigRevision PriorityConfigRevision) GetRevision() int64 {
+	return priorityConfigRevision.Revision
+}
+
+func (priorityConfigRevision PriorityConfigRevision) GetSavedAt() time.Time {
+	return priorityConfigRevision.SavedAt
+}
+
+func (priorityConfigRevision PriorityConfigRevision) GetSavedBy() string {
+	var v string
+	if priorityConfigRevision.SavedBy != nil {
+		return *priorityConfigRevision.SavedBy
+	}
+	return v
+}
+
+type PriorityDryRunRequest struct {
+	At             *time.Time          `json:"at,omitempty"`
+	Config         *PriorityConfig     `json:"config,omitempty"`
+	Patient        *PatientInformation `json:"patient" validate:"required"`
+	WaitingMinutes *int64              `json:"waitingMinutes,omitempty"`
+}
+
+func (priorityDryRunRequest PriorityDryRunRequest) GetAt() time.Time {
+	var v time.Time
+	if priorityDryRunRequest.At != nil {
+		return *priorityDryRunRequest.At
+	}
+	return v
+}
+
+func (priorityDryRunRequest PriorityDryRunRequest) GetConfig() PriorityConfig {
+	var v PriorityConfig
+	if priorityDryRunRequest.Config != nil {
+		return *priorityDryRunRequest.Config
+	}
+	return v
+}
+
+func (priorityDryRunRequest PriorityDryRunRequest) GetPatient() PatientInformation {
+	var v PatientInformation
+	if priorityDryRunRequest.Patient != nil {
+		return *priorityDryRunRequest.Patient
+	}
+	return v
+}
+
+func (priorityDryRunRequest PriorityDryRunRequest) GetWaitingMinutes() int64 {
+	var v int64
+	if priorityDryRunRequest.WaitingMinutes != nil {
+		return *priorityDryRunRequest.WaitingMinutes
+	}
+	return v
+}
+
+type PriorityDryRunResult struct {
+	ActiveRules  []string                  `json:"activeRules,omitempty" validate:"dive"`
+	Escalated    *bool                     `json:"escalated,omitempty"`
+	Fitness      *PriorityFitnessBreakdown `json:"fitness" validate:"required"`
+	FitnessScore float64                   `json:"fitnessScore"`
+	Symbols      []string                  `json:"symbols,omitempty" validate:"dive"`
+	Tier         int64                     `json:"tier"`
+	TierName     *string                   `json:"tierName,omitempty"`
+}
+
+func (priorityDryRunResult PriorityDryRunResult) GetActiveRules() []string {
+	return priorityDryRunResult.ActiveRules
+}
+
+func (priorityDryRunResult PriorityDryRunResult) GetEscalated() bool {
+	var v bool
+	if priorityDryRunResult.Escalated != nil {
+		return *priorityDryRunResult.Escalated
+	}
+	return v
+}
+
+func (priorityDryRunResult PriorityDryRunResult) GetFitness() PriorityFitnessBreakdown {
+	var v PriorityFitnessBreakdown
+	if priorityDryRunResult.Fitness != nil {
+		return *priorityDryRunResult.Fitness
+	}
+	return v
+}
+
+func (priorityDryRunResult PriorityDryRunResult) GetFitnessScore() float64 {
+	return priorityDryRunResult.FitnessScore
+}
+
+func (priorityDryRunResult PriorityDryRunResult) GetSymbols() []string {
+	return priorityDryRunResult.Symbols
+}
+
+func (priorityDryRunResult PriorityDryRunResult) GetTier() int64 {
+	return priorityDryRunResult.Tier
+}
+
+func (priorityDryRunResult PriorityDryRunResult) GetTierName() string {
+	var v string
+	if priorityDryRunResult.TierName != nil {
+		return *priorityDryRunResult.TierName
+	}
+	return v
+}
+
+type PriorityFitnessBreakdown struct {
+	Age                  float64 `json:"age"`
+	AppointmentDeviation float64 `json:"appointmentDeviation"`
+	ManualOverride       float64 `json:"manualOverride"`
+	Starvation           float64 `json:"starvation"`
+	SymbolWeights        float64 `json:"symbolWeights"`
+	WaitingTime          float64 `json:"waitingTime"`
+}
+
+func (priorityFitnessBreakdown PriorityFitnessBreakdown) GetAge() float64 {
+	return priorityFitnessBreakdown.Age
+}
+
+func (priorityFitnessBreakdown PriorityFitnessBreakdown) GetAppointmentDeviation() float64 {
+	return priorityFitnessBreakdown.AppointmentDeviation
+}
+
+func (priorityFitnessBreakdown PriorityFitnessBreakdown) GetManualOverride() float64 {
+	return priorityFitnessBreakdown.ManualOverride
+}
+
+func (priorityFitnessBreakdown PriorityFitnessBreakdown) GetStarvation() float64 {
+	return priorityFitnessBreakdown.Starvation
+}
+
+func (priorityFitnessBreakdown PriorityFitnessBreakdown) GetSymbolWeights() float64 {
+	return priorityFitnessBreakdown.SymbolWeights
+}
+
+func (priorityFitnessBreakdown PriorityFitnessBreakdown) GetWaitingTime() float64 {
+	return priorityFitnessBreakdown.WaitingTime
+}
+
+type PriorityHoliday struct {
+	Date string  `json:"date" validate:"required"`
+	Name *string `json:"name,omitempty"`
+}
+
+func (priorityHoliday PriorityHoliday) GetDate() string {
+	return priorityHoliday.Date
+}
+
+func (priorityHoliday PriorityHoliday) GetName() string {
+	var v string
+	if priorityHoliday.Name != nil {
+		return *priorityHoliday.Name
+	}
+	return v
+}
+
+type PriorityModel struct {
+	Algorithm  *Algorithm            `json:"algorithm,omitempty"`
+	Fitness    *FitnessConfig        `json:"fitness" validate:"required"`
+	Starvation *StarvationProtection `json:"starvation,omitempty"`
+	Tiers      []Tier                `json:"tiers" validate:"required,dive"`
+}
+
+func (priorityModel PriorityModel) GetAlgorithm() Algorithm {
+	var v Algorithm
+	if priorityModel.Algorithm != nil {
+		return *priorityModel.Algorithm
+	}
+	return v
+}
+
+func (priorityModel PriorityModel) GetFitness() FitnessConfig {
+	var v FitnessConfig
+	if priorityModel.Fitness != nil {
+		return *priorityModel.Fitness
+	}
+	return v
+}
+
+func (priorityModel PriorityModel) GetStarvation() StarvationProtection {
+	var v StarvationProtection
+	if priorityModel.Starvation != nil {
+		return *priorityModel.Starvation
+	}
+	return v
+}
+
+func (priorityModel PriorityModel) GetTiers() []Tier {
+	return priorityModel.Tiers
+}
+
+type PrioritySymbol struct {
+	Code  string  `json:"code" validate:"required"`
+	Color *string `json:"color,omitempty"`
+	Icon  *string `json:"icon,omitempty"`
+	Label string  `json:"label" validate:"required"`
+}
+
+func (prioritySymbol PrioritySymbol) GetCode() string {
+	return prioritySymbol.Code
+}
+
+func (prioritySymbol PrioritySymbol) GetColor() string {
+	var v string
+	if prioritySymbol.Color != nil {
+		return *prioritySymbol.Color
+	}
+	return v
+}
+
+func (prioritySymbol PrioritySymbol) GetIcon() string {
+	var v string
+	if prioritySymbol.Icon != nil {
+		return *prioritySymbol.Icon
+	}
+	return v
+}
+
+func (prioritySymbol PrioritySymbol) GetLabel() string {
+	return prioritySymbol.Label
+}
+
+type PushSettings struct {
+	Milestones     []int64 `json:"milestones,omitempty"`
+	Subject        *string `json:"subject,omitempty"`
+	VapidPublicKey *string `json:"vapidPublicKey,omitempty"`
+}
+
+func (pushSettings PushSettings) GetMilestones() []int64 {
+	return pushSettings.Milestones
+}
+
+func (pushSettings PushSettings) GetSubject() string {
+	var v string
+	if pushSettings.Subject != nil {
+		return *pushSettings.Subject
+	}
+	return v
+}
+
+func (pushSettings PushSettings) GetVapidPublicKey() string {
+	var v string
+	if pushSettings.VapidPublicKey != nil {
+		return *pushSettings.VapidPublicKey
+	}
+	return v
+}
+
+type ResolvedConfiguration struct {
+	Configuration *SystemConfiguration `json:"configuration" validate:"required"`
+	Layers        []string             `json:"layers" validate:"required"`
+	Sources       map[string]string    `json:"sources" validate:"required"`
+}
+
+func (resolvedConfiguration ResolvedConfiguration) GetConfiguration() SystemConfiguration {
+	var v SystemConfiguration
+	if resolvedConfiguration.Configuration != nil {
+		return *resolvedConfiguration.Configuration
+	}
+	return v
+}
+
+func (resolvedConfiguration ResolvedConfiguration) GetLayers() []string {
+	return resolvedConfiguration.Layers
+}
+
+func (resolvedConfiguration ResolvedConfiguration) GetSources() map[string]string {
+	return resolvedConfiguration.Sources
+}
+
+type RestartResponse struct {
+	CommandId *string `json:"commandId,omitempty"`
+	Message   string  `json:"message" validate:"required"`
+	Success   bool    `json:"success"`
+}
+
+func (restartResponse RestartResponse) GetCommandId() string {
+	var v string
+	if restartResponse.CommandId != nil {
+		return *restartResponse.CommandId
+	}
+	return v
+}
+
+func (restartResponse RestartResponse) GetMessage() string {
+	return restartResponse.Message
+}
+
+func (restartResponse RestartResponse) GetSuccess() bool {
+	return restartResponse.Success
+}
+
+type RetentionPolicy struct {
+	KeepHashedIdNumber *bool    `json:"keepHashedIdNumber,omitempty"`
+	PurgeAfterDays     int64    `json:"purgeAfterDays"`
+	PurgeFields        []string `json:"purgeFields,omitempty"`
+}
+
+func (retentionPolicy RetentionPolicy) GetKeepHashedIdNumber() bool {
+	var v bool
+	if retentionPolicy.KeepHashedIdNumber != nil {
+		return *retentionPolicy.KeepHashedIdNumber
+	}
+	return v
+}
+
+func (retentionPolicy RetentionPolicy) GetPurgeAfterDays() int64 {
+	return retentionPolicy.PurgeAfterDays
+}
+
+func (retentionPolicy RetentionPolicy) GetPurgeFields() []string {
+	return retentionPolicy.PurgeFields
+}
+
+type RoomConfig struct {
+	Assignment      *assignmentstrategy.AssignmentStrategy `json:"assignment,omitempty"`
+	Description     *string                                `json:"description,omitempty"`
+	Display         *RoomDisplay                           `json:"display,omitempty"`
+	Id              string                                 `json:"id" validate:"required"`
+	IsDefault       bool                                   `json:"isDefault"`
+	MaxWaiting      *int64                                 `json:"maxWaiting,omitempty"`
+	Name            string                                 `json:"name" validate:"required"`
+	Ordering        *queueordering.QueueOrdering           `json:"ordering,omitempty"`
+	OverflowRoomId  *string                                `json:"overflowRoomId,omitempty"`
+	ServiceFallback *servicefallback.ServiceFallback       `json:"serviceFallback,omitempty"`
+	ServicePoints   []ServicePointConfig                   `json:"servicePoints" validate:"required,dive"`
+}
+
+func (roomConfig RoomConfig) GetAssignment() assignmentstrategy.AssignmentStrategy {
+	var v assignmentstrategy.AssignmentStrategy
+	if roomConfig.Assignment != nil {
+		return *roomConfig.Assignment
+	}
+	return v
+}
+
+func (roomConfig RoomConfig) GetDescription() string {
+	var v string
+	if roomConfig.Description != nil {
+		return *roomConfig.Description
+	}
+	return v
+}
+
+func (roomConfig RoomConfig) GetDisplay() RoomDisplay {
+	var v RoomDisplay
+	if roomConfig.Display != nil {
+		return *roomConfig.Display
+	}
+	return v
+}
+
+func (roomConfig RoomConfig) GetId() string {
+	return roomConfig.Id
+}
+
+func (roomConfig RoomConfig) GetIsDefault() bool {
+	return roomConfig.IsDefault
+}
+
+func (roomConfig RoomConfig) GetMaxWaiting() int64 {
+	var v int64
+	if roomConfig.MaxWaiting != nil {
+		return *roomConfig.MaxWaiting
+	}
+	return v
+}
+
+func (roomConfig RoomConfig) GetName() string {
+	return roomConfig.Name
+}
+
+func (roomConfig RoomConfig) GetOrdering() queueordering.QueueOrdering {
+	var v queueordering.QueueOrdering
+	if roomConfig.Ordering != nil {
+		return *roomConfig.Ordering
+	}
+	return v
+}
+
+func (roomConfig RoomConfig) GetOverflowRoomId() string {
+	var v string
+	if roomConfig.OverflowRoomId != nil {
+		return *roomConfig.OverflowRoomId
+	}
+	return v
+}
+
+func (roomConfig RoomConfig) GetServiceFallback() servicefallback.ServiceFallback {
+	var v servicefallback.ServiceFallback
+	if roomConfig.ServiceFallback != nil {
+		return *roomConfig.ServiceFallback
+	}
+	return v
+}
+
+func (roomConfig RoomConfig) GetServicePoints() []ServicePointConfig {
+	return roomConfig.ServicePoints
+}
+
+type RoomDeletionResult struct {
+	MigratedCardReaders []string `json:"migratedCardReaders" validate:"required"`
+	MigratedEntries     int64    `json:"migratedEntries"`
+}
+
+func (roomDeletionResult RoomDeletionResult) GetMigratedCardReaders() []string {
+	return roomDeletionResult.MigratedCardReaders
+}
+
+func (roomDeletionResult RoomDeletionResult) GetMigratedEntries() int64 {
+	return roomDeletionResult.MigratedEntries
+}
+
+type RoomDisplay struct {
+	Announce     *bool  `json:"announce,omitempty"`
+	BlinkSeconds *int64 `json:"blinkSeconds,omitempty"`
+	CallHistory  *int64 `json:"callHistory,omitempty"`
+}
+
+func (roomDisplay RoomDisplay) GetAnnounce() bool {
+	var v bool
+	if roomDisplay.Announce != nil {
+		return *roomDisplay.Announce
+	}
+	return v
+}
+
+func (roomDisplay RoomDisplay) GetBlinkSeconds() int64 {
+	var v int64
+	if roomDisplay.BlinkSeconds != nil {
+		return *roomDisplay.BlinkSeconds
+	}
+	return v
+}
+
+func (roomDisplay RoomDisplay) GetCallHistory() int64 {
 	var v int64
-	if externalAPIConfig.WebhookTimeoutSeconds != nil {
-		return *externalAPIConfig.WebhookTimeoutSeconds
+	if roomDisplay.CallHistory != nil {
+		return *roomDisplay.CallHistory
 	}
 	return v
 }
 
-func (externalAPIConfig ExternalAPIConfig) GetWebhookUrl() string {
+type ScheduleRule struct {
+	Days          []string           `json:"days,omitempty" validate:"dive"`
+	From          *string            `json:"from,omitempty"`
+	Multipliers   map[string]float64 `json:"multipliers,omitempty"`
+	Name          string             `json:"name" validate:"required"`
+	SymbolWeights map[string]float64 `json:"symbolWeights,omitempty"`
+	To            *string            `json:"to,omitempty"`
+}
+
+func (scheduleRule ScheduleRule) GetDays() []string {
+	return scheduleRule.Days
+}
+
+func (scheduleRule ScheduleRule) GetFrom() string {
 	var v string
-	if externalAPIConfig.WebhookUrl != nil {
-		return *externalAPIConfig.WebhookUrl
+	if scheduleRule.From != nil {
+		return *scheduleRule.From
 	}
 	return v
 }
 
-type FitnessConfig struct {
-	Contributions *Contributions `json:"contributions" validate:"required"`
-	Explanation   *string        `json:"explanation,omitempty"`
+func (scheduleRule ScheduleRule) GetMultipliers() map[string]float64 {
+	return scheduleRule.Multipliers
 }
 
-func (fitnessConfig FitnessConfig) GetContributions() Contributions {
-	var v Contributions
-	if fitnessConfig.Contributions != nil {
-		return *fitnessConfig.Contributions
+func (scheduleRule ScheduleRule) GetName() string {
+	return scheduleRule.Name
+}
+
+func (scheduleRule ScheduleRule) GetSymbolWeights() map[string]float64 {
+	return scheduleRule.SymbolWeights
+}
+
+func (scheduleRule ScheduleRule) GetTo() string {
+	var v string
+	if scheduleRule.To != nil {
+		return *scheduleRule.To
 	}
 	return v
 }
 
-func (fitnessConfig FitnessConfig) GetExplanation() string {
+type ServicePointConfig struct {
+	Description *string  `json:"description,omitempty"`
+	Id          string   `json:"id" validate:"required"`
+	ManagerId   *string  `json:"managerId,omitempty"`
+	ManagerName *string  `json:"managerName,omitempty"`
+	Name        string   `json:"name" validate:"required"`
+	Services    []string `json:"services,omitempty" validate:"dive"`
+}
+
+func (servicePointConfig ServicePointConfig) GetDescription() string {
 	var v string
-	if fitnessConfig.Explanation != nil {
-		return *fitnessConfig.Explanation
+	if servicePointConfig.Description != nil {
+		return *servicePointConfig.Description
 	}
 	return v
 }
 
-type GenericService struct {
-	Description *string `json:"description,omitempty"`
-	Duration    *int64  `json:"duration,omitempty"`
-	Enabled     bool    `json:"enabled"`
-	Id          string  `json:"id" validate:"required"`
-	Name        string  `json:"name" validate:"required"`
+func (servicePointConfig ServicePointConfig) GetId() string {
+	return servicePointConfig.Id
 }
 
-func (genericService GenericService) GetDescription() string {
+func (servicePointConfig ServicePointConfig) GetManagerId() string {
 	var v string
-	if genericService.Description != nil {
-		return *genericService.Description
+	if servicePointConfig.ManagerId != nil {
+		return *servicePointConfig.ManagerId
 	}
 	return v
 }
 
-func (genericService GenericService) GetDuration() int64 {
-	var v int64
-	if genericService.Duration != nil {
-		return *genericService.Duration
+func (servicePointConfig ServicePointConfig) GetManagerName() string {
+	var v string
+	if servicePointConfig.ManagerName != nil {
+		return *servicePointConfig.ManagerName
 	}
 	return v
 }
 
-func (genericService GenericService) GetEnabled() bool {
-	return genericService.Enabled
+func (servicePointConfig ServicePointConfig) GetName() string {
+	return servicePointConfig.Name
 }
 
-func (genericService GenericService) GetId() string {
-	return genericService.Id
+func (servicePointConfig ServicePointConfig) GetServices() []string {
+	return servicePointConfig.Services
 }
 
-func (genericService GenericService) GetName() string {
-	return genericService.Name
+type SmsSettings struct {
+	AccountSid string  `json:"accountSid" validate:"required"`
+	AuthToken  *string `json:"authToken,omitempty"`
+	BaseUrl    *string `json:"baseUrl,omitempty"`
+	From       string  `json:"from" validate:"required"`
 }
 
-type ManualOverride struct {
-	Description *string `json:"description,omitempty"`
-	Enabled     bool    `json:"enabled"`
-	Weight      float64 `json:"weight"`
+func (smsSettings SmsSettings) GetAccountSid() string {
+	return smsSettings.AccountSid
 }
 
-func (manualOverride ManualOverride) GetDescription() string {
+func (smsSettings SmsSettings) GetAuthToken() string {
 	var v string
-	if manualOverride.Description != nil {
-		return *manualOverride.Description
+	if smsSettings.AuthToken != nil {
+		return *smsSettings.AuthToken
 	}
 	return v
 }
 
-func (manualOverride ManualOverride) GetEnabled() bool {
-	return manualOverride.Enabled
+func (smsSettings SmsSettings) GetBaseUrl() string {
+	var v string
+	if smsSettings.BaseUrl != nil {
+		return *smsSettings.BaseUrl
+	}
+	return v
 }
 
-func (manualOverride ManualOverride) GetWeight() float64 {
-	return manualOverride.Weight
+func (smsSettings SmsSettings) GetFrom() string {
+	return smsSettings.From
 }
 
-type PriorityConfig struct {
-	Description   *string        `json:"description,omitempty"`
-	PriorityModel *PriorityModel `json:"priorityModel" validate:"required"`
-	Version       string         `json:"version" validate:"required"`
+type SmtpSettings struct {
+	From     string  `json:"from" validate:"required"`
+	Host     string  `json:"host" validate:"required"`
+	Password *string `json:"password,omitempty"`
+	Port     *int64  `json:"port,omitempty"`
+	Username *string `json:"username,omitempty"`
 }
 
-func (priorityConfig PriorityConfig) GetDescription() string {
+func (smtpSettings SmtpSettings) GetFrom() string {
+	return smtpSettings.From
+}
+
+func (smtpSettings SmtpSettings) GetHost() string {
+	return smtpSettings.Host
+}
+
+func (smtpSettings SmtpSettings) GetPassword() string {
 	var v string
-	if priorityConfig.Description != nil {
-		return *priorityConfig.Description
+	if smtpSettings.Password != nil {
+		return *smtpSettings.Password
 	}
 	return v
 }
 
-func (priorityConfig PriorityConfig) GetPriorityModel() PriorityModel {
-	var v PriorityModel
-	if priorityConfig.PriorityModel != nil {
-		return *priorityConfig.PriorityModel
+func (smtpSettings SmtpSettings) GetPort() int64 {
+	var v int64
+	if smtpSettings.Port != nil {
+		return *smtpSettings.Port
 	}
 	return v
 }
 
-func (priorityConfig PriorityConfig) GetVersion() string {
-	return priorityConfig.Version
+func (smtpSettings SmtpSettings) GetUsername() string {
+	var v string
+	if smtpSettings.Username != nil {
+		return *smtpSettings.Username
+	}
+	return v
 }
 
-type PriorityModel struct {
-	Algorithm *Algorithm     `json:"algorithm,omitempty"`
-	Fitness   *FitnessConfig `json:"fitness" validate:"required"`
-	Tiers     []Tier         `json:"tiers" validate:"required,dive"`
+type StarvationProtection struct {
+	Bonus            *float64 `json:"bonus,omitempty"`
+	Description      *string  `json:"description,omitempty"`
+	DoublingMinutes  *float64 `json:"doublingMinutes,omitempty"`
+	Enabled          bool     `json:"enabled"`
+	Mode             *string  `json:"mode,omitempty"`
+	ThresholdMinutes float64  `json:"thresholdMinutes"`
 }
 
-func (priorityModel PriorityModel) GetAlgorithm() Algorithm {
-	var v Algorithm
-	if priorityModel.Algorithm != nil {
-		return *priorityModel.Algorithm
+func (starvationProtection StarvationProtection) GetBonus() float64 {
+	var v float64
+	if starvationProtection.Bonus != nil {
+		return *starvationProtection.Bonus
 	}
 	return v
 }
 
-func (priorityModel PriorityModel) GetFitness() FitnessConfig {
-	var v FitnessConfig
-	if priorityModel.Fitness != nil {
-		return *priorityModel.Fitness
+func (starvationProtection StarvationProtection) GetDescription() string {
+	var v string
+	if starvationProtection.Description != nil {
+		return *starvationProtection.Description
 	}
 	return v
 }
 
-func (priorityModel PriorityModel) GetTiers() []Tier {
-	return priorityModel.Tiers
-}
-
-type RestartResponse struct {
-	Message string `json:"message" validate:"required"`
-	Success bool   `json:"success"`
-}
-
-func (restartResponse RestartResponse) GetMessage() string {
-	return restartResponse.Message
-}
-
-func (restartResponse RestartResponse) GetSuccess() bool {
-	return restartResponse.Success
+func (starvationProtection StarvationProtection) GetDoublingMinutes() float64 {
+	var v float64
+	if starvationProtection.DoublingMinutes != nil {
+		return *starvationProtection.DoublingMinutes
+	}
+	return v
 }
 
-type RoomConfig struct {
-	Description   *string              `json:"description,omitempty"`
-	Id            string               `json:"id" validate:"required"`
-	IsDefault     bool                 `json:"isDefault"`
-	Name          string               `json:"name" validate:"required"`
-	ServicePoints []ServicePointConfig `json:"servicePoints" validate:"required,dive"`
+func (starvationProtection StarvationProtection) GetEnabled() bool {
+	return starvationProtection.Enabled
 }
 
-func (roomConfig RoomConfig) GetDescription() string {
+func (starvationProtection StarvationProtection) GetMode() string {
 	var v string
-	if roomConfig.Description != nil {
-		return *roomConfig.Description
+	if starvationProtection.Mode != nil {
+		return *starvationProtection.Mode
 	}
 	return v
 }
 
-func (roomConfig RoomConfig) GetId() string {
-	return roomConfig.Id
-}
-
-func (roomConfig RoomConfig) GetIsDefault() bool {
-	return roomConfig.IsDefault
+func (starvationProtection StarvationProtection) GetThresholdMinutes() float64 {
+	return starvationProtection.ThresholdMinutes
 }
 
-func (roomConfig RoomConfig) GetName() string {
-	return roomConfig.Name
+type SubscriptionToken struct {
+	ExpiresAt time.Time `json:"expiresAt" validate:"required"`
+	Role      string    `json:"role" validate:"required"`
+	TenantId  *string   `json:"tenantId,omitempty"`
+	Token     string    `json:"token" validate:"required"`
 }
 
-func (roomConfig RoomConfig) GetServicePoints() []ServicePointConfig {
-	return roomConfig.ServicePoints
+func (subscriptionToken SubscriptionToken) GetExpiresAt() time.Time {
+	return subscriptionToken.ExpiresAt
 }
 
-type ServicePointConfig struct {
-	Description *string `json:"description,omitempty"`
-	Id          string  `json:"id" validate:"required"`
-	ManagerId   *string `json:"managerId,omitempty"`
-	ManagerName *string `json:"managerName,omitempty"`
-	Name        string  `json:"name" validate:"required"`
+func (subscriptionToken SubscriptionToken) GetRole() string {
+	return subscriptionToken.Role
 }
 
-func (servicePointConfig ServicePointConfig) GetDescription() string {
+func (subscriptionToken SubscriptionToken) GetTenantId() string {
 	var v string
-	if servicePointConfig.Description != nil {
-		return *servicePointConfig.Description
+	if subscriptionToken.TenantId != nil {
+		return *subscriptionToken.TenantId
 	}
 	return v
 }
 
-func (servicePointConfig ServicePointConfig) GetId() string {
-	return servicePointConfig.Id
+func (subscriptionToken SubscriptionToken) GetToken() string {
+	return subscriptionToken.Token
 }
 
-func (servicePointConfig ServicePointConfig) GetManagerId() string {
-	var v string
-	if servicePointConfig.ManagerId != nil {
-		return *servicePointConfig.ManagerId
-	}
-	return v
+type SubscriptionTokenRequest struct {
+	Role       string  `json:"role" validate:"required"`
+	TenantId   *string `json:"tenantId,omitempty"`
+	TtlSeconds *int64  `json:"ttlSeconds,omitempty"`
 }
 
-func (servicePointConfig ServicePointConfig) GetManagerName() string {
+func (subscriptionTokenRequest SubscriptionTokenRequest) GetRole() string {
+	return subscriptionTokenRequest.Role
+}
+
+func (subscriptionTokenRequest SubscriptionTokenRequest) GetTenantId() string {
 	var v string
-	if servicePointConfig.ManagerName != nil {
-		return *servicePointConfig.ManagerName
+	if subscriptionTokenRequest.TenantId != nil {
+		return *subscriptionTokenRequest.TenantId
 	}
 	return v
 }
 
-func (servicePointConfig ServicePointConfig) GetName() string {
-	return servicePointConfig.Name
+func (subscriptionTokenRequest SubscriptionTokenRequest) GetTtlSeconds() int64 {
+	var v int64
+	if subscriptionTokenRequest.TtlSeconds != nil {
+		return *subscriptionTokenRequest.TtlSeconds
+	}
+	return v
 }
 
 type SymbolWeights struct {
@@ -671,13 +2089,14 @@ func (systemConfiguration SystemConfiguration) GetWebSocketPath() string {
 }
 
 type Tenant struct {
-	BuildingId  string     `json:"buildingId" validate:"required"`
-	CreatedAt   *time.Time `json:"createdAt,omitempty"`
-	Description *string    `json:"description,omitempty"`
-	Id          *string    `json:"id,omitempty"`
-	Name        string     `json:"name" validate:"required"`
-	SectionId   string     `json:"sectionId" validate:"required"`
-	UpdatedAt   *time.Time `json:"updatedAt,omitempty"`
+	BuildingId    string     `json:"buildingId" validate:"required"`
+	CreatedAt     *time.Time `json:"createdAt,omitempty"`
+	DeactivatedAt *time.Time `json:"deactivatedAt,omitempty"`
+	Description   *string    `json:"description,omitempty"`
+	Id            *string    `json:"id,omitempty"`
+	Name          string     `json:"name" validate:"required"`
+	SectionId     string     `json:"sectionId" validate:"required"`
+	UpdatedAt     *time.Time `json:"updatedAt,omitempty"`
 }
 
 func (tenant Tenant) GetBuildingId() string {
@@ -692,6 +2111,14 @@ func (tenant Tenant) GetCreatedAt() time.Time {
 	return v
 }
 
+func (tenant Tenant) GetDeactivatedAt() time.Time {
+	var v time.Time
+	if tenant.DeactivatedAt != nil {
+		return *tenant.DeactivatedAt
+	}
+	return v
+}
+
 func (tenant Tenant) GetDescription() string {
 	var v string
 	if tenant.Description != nil {
@@ -724,6 +2151,109 @@ func (tenant Tenant) GetUpdatedAt() time.Time {
 	return v
 }
 
+type TenantProvisioningRequest struct {
+	BuildingId         string          `json:"buildingId" validate:"required"`
+	DefaultLanguage    *string         `json:"defaultLanguage,omitempty"`
+	Description        *string         `json:"description,omitempty"`
+	Devices            []APIKeyRequest `json:"devices,omitempty" validate:"dive"`
+	Name               string          `json:"name" validate:"required"`
+	Priority           *PriorityConfig `json:"priority,omitempty"`
+	Room               *RoomConfig     `json:"room,omitempty"`
+	SectionId          *string         `json:"sectionId,omitempty"`
+	SupportedLanguages []string        `json:"supportedLanguages,omitempty" validate:"dive"`
+}
+
+func (tenantProvisioningRequest TenantProvisioningRequest) GetBuildingId() string {
+	return tenantProvisioningRequest.BuildingId
+}
+
+func (tenantProvisioningRequest TenantProvisioningRequest) GetDefaultLanguage() string {
+	var v string
+	if tenantProvisioningRequest.DefaultLanguage != nil {
+		return *tenantProvisioningRequest.DefaultLanguage
+	}
+	return v
+}
+
+func (tenantProvisioningRequest TenantProvisioningRequest) GetDescription() string {
+	var v string
+	if tenantProvisioningRequest.Description != nil {
+		return *tenantProvisioningRequest.Description
+	}
+	return v
+}
+
+func (tenantProvisioningRequest TenantProvisioningRequest) GetDevices() []APIKeyRequest {
+	return tenantProvisioningRequest.Devices
+}
+
+func (tenantProvisioningRequest TenantProvisioningRequest) GetName() string {
+	return tenantProvisioningRequest.Name
+}
+
+func (tenantProvisioningRequest TenantProvisioningRequest) GetPriority() PriorityConfig {
+	var v PriorityConfig
+	if tenantProvisioningRequest.Priority != nil {
+		return *tenantProvisioningRequest.Priority
+	}
+	return v
+}
+
+func (tenantProvisioningRequest TenantProvisioningRequest) GetRoom() RoomConfig {
+	var v RoomConfig
+	if tenantProvisioningRequest.Room != nil {
+		return *tenantProvisioningRequest.Room
+	}
+	return v
+}
+
+func (tenantProvisioningRequest TenantProvisioningRequest) GetSectionId() string {
+	var v string
+	if tenantProvisioningRequest.SectionId != nil {
+		return *tenantProvisioningRequest.SectionId
+	}
+	return v
+}
+
+func (tenantProvisioningRequest TenantProvisioningRequest) GetSupportedLanguages() []string {
+	return tenantProvisioningRequest.SupportedLanguages
+}
+
+type TenantProvisioningResult struct {
+	ApiKeys  []IssuedAPIKey  `json:"apiKeys" validate:"required,dive"`
+	Priority *PriorityConfig `json:"priority" validate:"required"`
+	Room     *RoomConfig     `json:"room" validate:"required"`
+	Tenant   *Tenant         `json:"tenant" validate:"required"`
+}
+
+func (tenantProvisioningResult TenantProvisioningResult) GetApiKeys() []IssuedAPIKey {
+	return tenantProvisioningResult.ApiKeys
+}
+
+func (tenantProvisioningResult TenantProvisioningResult) GetPriority() PriorityConfig {
+	var v PriorityConfig
+	if tenantProvisioningResult.Priority != nil {
+		return *tenantProvisioningResult.Priority
+	}
+	return v
+}
+
+func (tenantProvisioningResult TenantProvisioningResult) GetRoom() RoomConfig {
+	var v RoomConfig
+	if tenantProvisioningResult.Room != nil {
+		return *tenantProvisioningResult.Room
+	}
+	return v
+}
+
+func (tenantProvisioningResult TenantProvisioningResult) GetTenant() Tenant {
+	var v Tenant
+	if tenantProvisioningResult.Tenant != nil {
+		return *tenantProvisioningResult.Tenant
+	}
+	return v
+}
+
 type Tier struct {
 	Condition   *TierCondition `json:"condition,omitempty"`
 	Description *string        `json:"description,omitempty"`
@@ -769,14 +2299,20 @@ func (tierCondition TierCondition) GetSymbolsNotAnyOf() []string {
 }
 
 type TranslationCacheStats struct {
-	Api_calls_saved *int64  `json:"api_calls_saved,omitempty"`
-	Cache_size      *int64  `json:"cache_size,omitempty"`
-	Expiration_time *string `json:"expiration_time,omitempty"`
-	Hit_rate        *string `json:"hit_rate,omitempty"`
-	Hits            *int64  `json:"hits,omitempty"`
-	Max_cache_size  *int64  `json:"max_cache_size,omitempty"`
-	Misses          *int64  `json:"misses,omitempty"`
-	Total_requests  *int64  `json:"total_requests,omitempty"`
+	Api_calls_saved     *int64  `json:"api_calls_saved,omitempty"`
+	Cache_size          *int64  `json:"cache_size,omitempty"`
+	Expiration_time     *string `json:"expiration_time,omitempty"`
+	Hit_rate            *string `json:"hit_rate,omitempty"`
+	Hits                *int64  `json:"hits,omitempty"`
+	Max_cache_size      *int64  `json:"max_cache_size,omitempty"`
+	Misses              *int64  `json:"misses,omitempty"`
+	Persistent          *bool   `json:"persistent,omitempty"`
+	Persistent_hit_rate *string `json:"persistent_hit_rate,omitempty"`
+	Persistent_hits     *int64  `json:"persistent_hits,omitempty"`
+	Persistent_misses   *int64  `json:"persistent_misses,omitempty"`
+	Persistent_size     *int64  `json:"persistent_size,omitempty"`
+	Total_requests      *int64  `json:"total_requests,omitempty"`
+	Warmed_entries      *int64  `json:"warmed_entries,omitempty"`
 }
 
 func (translationCacheStats TranslationCacheStats) GetApi_calls_saved() int64 {
@@ -835,6 +2371,46 @@ func (translationCacheStats TranslationCacheStats) GetMisses() int64 {
 	return v
 }
 
+func (translationCacheStats TranslationCacheStats) GetPersistent() bool {
+	var v bool
+	if translationCacheStats.Persistent != nil {
+		return *translationCacheStats.Persistent
+	}
+	return v
+}
+
+func (translationCacheStats TranslationCacheStats) GetPersistent_hit_rate() string {
+	var v string
+	if translationCacheStats.Persistent_hit_rate != nil {
+		return *translationCacheStats.Persistent_hit_rate
+	}
+	return v
+}
+
+func (translationCacheStats TranslationCacheStats) GetPersistent_hits() int64 {
+	var v int64
+	if translationCacheStats.Persistent_hits != nil {
+		return *translationCacheStats.Persistent_hits
+	}
+	return v
+}
+
+func (translationCacheStats TranslationCacheStats) GetPersistent_misses() int64 {
+	var v int64
+	if translationCacheStats.Persistent_misses != nil {
+		return *translationCacheStats.Persistent_misses
+	}
+	return v
+}
+
+func (translationCacheStats TranslationCacheStats) GetPersistent_size() int64 {
+	var v int64
+	if translationCacheStats.Persistent_size != nil {
+		return *translationCacheStats.Persistent_size
+	}
+	return v
+}
+
 func (translationCacheStats TranslationCacheStats) GetTotal_requests() int64 {
 	var v int64
 	if translationCacheStats.Total_requests != nil {
@@ -843,6 +2419,46 @@ func (translationCacheStats TranslationCacheStats) GetTotal_requests() int64 {
 	return v
 }
 
+func (translationCacheStats TranslationCacheStats) GetWarmed_entries() int64 {
+	var v int64
+	if translationCacheStats.Warmed_entries != nil {
+		return *translationCacheStats.Warmed_entries
+	}
+	return v
+}
+
+type TranslationGlossaryEntry struct {
+	SourceLang string     `json:"sourceLang" validate:"required"`
+	SourceText string     `json:"sourceText" validate:"required"`
+	TargetLang string     `json:"targetLang" validate:"required"`
+	TargetText string     `json:"targetText" validate:"required"`
+	UpdatedAt  *time.Time `json:"updatedAt,omitempty"`
+}
+
+func (translationGlossaryEntry TranslationGlossaryEntry) GetSourceLang() string {
+	return translationGlossaryEntry.SourceLang
+}
+
+func (translationGlossaryEntry TranslationGlossaryEntry) GetSourceText() string {
+	return translationGlossaryEntry.SourceText
+}
+
+func (translationGlossaryEntry TranslationGlossaryEntry) GetTargetLang() string {
+	return translationGlossaryEntry.TargetLang
+}
+
+func (translationGlossaryEntry TranslationGlossaryEntry) GetTargetText() string {
+	return translationGlossaryEntry.TargetText
+}
+
+func (translationGlossaryEntry TranslationGlossaryEntry) GetUpdatedAt() time.Time {
+	var v time.Time
+	if translationGlossaryEntry.UpdatedAt != nil {
+		return *translationGlossaryEntry.UpdatedAt
+	}
+	return v
+}
+
 type WaitingTime struct {
 	Description     *string `json:"description,omitempty"`
 	WeightPerMinute float64 `json:"weightPerMinute"`
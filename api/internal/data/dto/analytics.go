@@ -0,0 +1,154 @@
+// Code generated by go generate; DO NOT EDIT.
+package dto
+
+import (
+	"time"
+)
+
+type ArchivedEntry struct {
+	ArchivedAt     time.Time  `json:"archivedAt" validate:"required"`
+	CalledAt       *time.Time `json:"calledAt,omitempty"`
+	CompletedAt    *time.Time `json:"completedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt" validate:"required"`
+	Id             string     `json:"id" validate:"required"`
+	RoomId         string     `json:"roomId" validate:"required"`
+	ServiceName    *string    `json:"serviceName,omitempty"`
+	ServicePointId *string    `json:"servicePointId,omitempty"`
+	ServiceSeconds *float64   `json:"serviceSeconds,omitempty"`
+	Status         string     `json:"status" validate:"required"`
+	TicketNumber   string     `json:"ticketNumber" validate:"required"`
+	Tier           *int32     `json:"tier,omitempty"`
+	WaitSeconds    *float64   `json:"waitSeconds,omitempty"`
+}
+
+func (archivedEntry ArchivedEntry) GetArchivedAt() time.Time {
+	return archivedEntry.ArchivedAt
+}
+
+func (archivedEntry ArchivedEntry) GetCalledAt() time.Time {
+	var v time.Time
+	if archivedEntry.CalledAt != nil {
+		return *archivedEntry.CalledAt
+	}
+	return v
+}
+
+func (archivedEntry ArchivedEntry) GetCompletedAt() time.Time {
+	var v time.Time
+	if archivedEntry.CompletedAt != nil {
+		return *archivedEntry.CompletedAt
+	}
+	return v
+}
+
+func (archivedEntry ArchivedEntry) GetCreatedAt() time.Time {
+	return archivedEntry.CreatedAt
+}
+
+func (archivedEntry ArchivedEntry) GetId() string {
+	return archivedEntry.Id
+}
+
+func (archivedEntry ArchivedEntry) GetRoomId() string {
+	return archivedEntry.RoomId
+}
+
+func (archivedEntry ArchivedEntry) GetServiceName() string {
+	var v string
+	if archivedEntry.ServiceName != nil {
+		return *archivedEntry.ServiceName
+	}
+	return v
+}
+
+func (archivedEntry ArchivedEntry) GetServicePointId() string {
+	var v string
+	if archivedEntry.ServicePointId != nil {
+		return *archivedEntry.ServicePointId
+	}
+	return v
+}
+
+func (archivedEntry ArchivedEntry) GetServiceSeconds() float64 {
+	var v float64
+	if archivedEntry.ServiceSeconds != nil {
+		return *archivedEntry.ServiceSeconds
+	}
+	return v
+}
+
+func (archivedEntry ArchivedEntry) GetStatus() string {
+	return archivedEntry.Status
+}
+
+func (archivedEntry ArchivedEntry) GetTicketNumber() string {
+	return archivedEntry.TicketNumber
+}
+
+func (archivedEntry ArchivedEntry) GetTier() int32 {
+	var v int32
+	if archivedEntry.Tier != nil {
+		return *archivedEntry.Tier
+	}
+	return v
+}
+
+func (archivedEntry ArchivedEntry) GetWaitSeconds() float64 {
+	var v float64
+	if archivedEntry.WaitSeconds != nil {
+		return *archivedEntry.WaitSeconds
+	}
+	return v
+}
+
+type DailyStatistics struct {
+	AverageServiceSeconds float64 `json:"averageServiceSeconds"`
+	AverageWaitSeconds    float64 `json:"averageWaitSeconds"`
+	Date                  string  `json:"date" validate:"required"`
+	NoShowRate            float64 `json:"noShowRate"`
+	NoShows               int64   `json:"noShows"`
+	P95WaitSeconds        float64 `json:"p95WaitSeconds"`
+	RoomId                string  `json:"roomId" validate:"required"`
+	ServicePointId        *string `json:"servicePointId,omitempty"`
+	Throughput            int64   `json:"throughput"`
+}
+
+func (dailyStatistics DailyStatistics) GetAverageServiceSeconds() float64 {
+	return dailyStatistics.AverageServiceSeconds
+}
+
+func (dailyStatistics DailyStatistics) GetAverageWaitSeconds() float64 {
+	return dailyStatistics.AverageWaitSeconds
+}
+
+func (dailyStatistics DailyStatistics) GetDate() string {
+	return dailyStatistics.Date
+}
+
+func (dailyStatistics DailyStatistics) GetNoShowRate() float64 {
+	return dailyStatistics.NoShowRate
+}
+
+func (dailyStatistics DailyStatistics) GetNoShows() int64 {
+	return dailyStatistics.NoShows
+}
+
+func (dailyStatistics DailyStatistics) GetP95WaitSeconds() float64 {
+	return dailyStatistics.P95WaitSeconds
+}
+
+func (dailyStatistics DailyStatistics) GetRoomId() string {
+	return dailyStatistics.RoomId
+}
+
+func (dailyStatistics DailyStatistics) GetServicePointId() string {
+	var v string
+	if dailyStatistics.ServicePointId != nil {
+		return *dailyStatistics.ServicePointId
+	}
+	return v
+}
+
+func (dailyStatistics DailyStatistics) GetThroughput() int64 {
+	return dailyStatistics.Throughput
+}
@@ -0,0 +1,94 @@
+// Code generated by go generate; DO NOT EDIT.
+package dto
+
+import (
+	"time"
+
+	"github.com/arfis/waiting-room/internal/data/dto/appointmentstatus"
+)
+
+type Appointment struct {
+	AppointmentTime time.Time                            `json:"appointmentTime" validate:"required"`
+	EntryId         *string                              `json:"entryId,omitempty"`
+	ExternalId      string                               `json:"externalId" validate:"required"`
+	Identifier      string                               `json:"identifier" validate:"required"`
+	PatientName     *string                              `json:"patientName,omitempty"`
+	RoomId          *string                              `json:"roomId,omitempty"`
+	ServiceDuration *int64                               `json:"serviceDuration,omitempty"`
+	ServiceId       *string                              `json:"serviceId,omitempty"`
+	ServiceName     *string                              `json:"serviceName,omitempty"`
+	Status          *appointmentstatus.AppointmentStatus `json:"status,omitempty"`
+	Symbols         []string                             `json:"symbols,omitempty" validate:"dive"`
+}
+
+func (appointment Appointment) GetAppointmentTime() time.Time {
+	return appointment.AppointmentTime
+}
+
+func (appointment Appointment) GetEntryId() string {
+	var v string
+	if appointment.EntryId != nil {
+		return *appointment.EntryId
+	}
+	return v
+}
+
+func (appointment Appointment) GetExternalId() string {
+	return appointment.ExternalId
+}
+
+func (appointment Appointment) GetIdentifier() string {
+	return appointment.Identifier
+}
+
+func (appointment Appointment) GetPatientName() string {
+	var v string
+	if appointment.PatientName != nil {
+		return *appointment.PatientName
+	}
+	return v
+}
+
+func (appointment Appointment) GetRoomId() string {
+	var v string
+	if appointment.RoomId != nil {
+		return *appointment.RoomId
+	}
+	return v
+}
+
+func (appointment Appointment) GetServiceDuration() int64 {
+	var v int64
+	if appointment.ServiceDuration != nil {
+		return *appointment.ServiceDuration
+	}
+	return v
+}
+
+func (appointment Appointment) GetServiceId() string {
+	var v string
+	if appointment.ServiceId != nil {
+		return *appointment.ServiceId
+	}
+	return v
+}
+
+func (appointment Appointment) GetServiceName() string {
+	var v string
+	if appointment.ServiceName != nil {
+		return *appointment.ServiceName
+	}
+	return v
+}
+
+func (appointment Appointment) GetStatus() appointmentstatus.AppointmentStatus {
+	var v appointmentstatus.AppointmentStatus
+	if appointment.Status != nil {
+		return *appointment.Status
+	}
+	return v
+}
+
+func (appointment Appointment) GetSymbols() []string {
+	return appointment.Symbols
+}
@@ -0,0 +1,34 @@
+// Code generated by go generate; DO NOT EDIT.
+package appointmentstatus
+
+import (
+	"fmt"
+	"github.com/arfis/waiting-room/internal/errors"
+)
+
+type AppointmentStatus string
+
+var (
+	UNKNOWN_VALUE AppointmentStatus = "UNKNOWN_VALUE"
+	SCHEDULED     AppointmentStatus = "SCHEDULED"
+	CHECKED_IN    AppointmentStatus = "CHECKED_IN"
+	CANCELLED     AppointmentStatus = "CANCELLED"
+)
+
+// String gets the string representation of the AppointmentStatus
+func (c AppointmentStatus) String() string {
+	return string(c)
+}
+
+func StringToAppointmentStatus(source string) (AppointmentStatus, error) {
+	switch source {
+	case string(SCHEDULED):
+		return SCHEDULED, nil
+	case string(CHECKED_IN):
+		return CHECKED_IN, nil
+	case string(CANCELLED):
+		return CANCELLED, nil
+	default:
+		return "UNKNOWN_VALUE", errors.Validation(fmt.Errorf("invalid value ('%s') passed to AppointmentStatus", source), nil)
+	}
+}
@@ -0,0 +1,37 @@
+// Code generated by go generate; DO NOT EDIT.
+package assignmentstrategy
+
+import (
+	"fmt"
+	"github.com/arfis/waiting-room/internal/errors"
+)
+
+type AssignmentStrategy string
+
+var (
+	UNKNOWN_VALUE AssignmentStrategy = "UNKNOWN_VALUE"
+	NONE          AssignmentStrategy = "NONE"
+	ROUND_ROBIN   AssignmentStrategy = "ROUND_ROBIN"
+	LEAST_LOADED  AssignmentStrategy = "LEAST_LOADED"
+	SHORTEST_WAIT AssignmentStrategy = "SHORTEST_WAIT"
+)
+
+// String gets the string representation of the AssignmentStrategy
+func (c AssignmentStrategy) String() string {
+	return string(c)
+}
+
+func StringToAssignmentStrategy(source string) (AssignmentStrategy, error) {
+	switch source {
+	case string(NONE):
+		return NONE, nil
+	case string(ROUND_ROBIN):
+		return ROUND_ROBIN, nil
+	case string(LEAST_LOADED):
+		return LEAST_LOADED, nil
+	case string(SHORTEST_WAIT):
+		return SHORTEST_WAIT, nil
+	default:
+		return "UNKNOWN_VALUE", errors.Validation(fmt.Errorf("invalid value ('%s') passed to AssignmentStrategy", source), nil)
+	}
+}
@@ -0,0 +1,84 @@
+// Code generated by go generate; DO NOT EDIT.
+package dto
+
+import (
+	"time"
+)
+
+type AuditChange struct {
+	After  interface{} `json:"after,omitempty"`
+	Before interface{} `json:"before,omitempty"`
+	Field  string      `json:"field" validate:"required"`
+}
+
+func (auditChange AuditChange) GetAfter() interface{} {
+	return auditChange.After
+}
+
+func (auditChange AuditChange) GetBefore() interface{} {
+	return auditChange.Before
+}
+
+func (auditChange AuditChange) GetField() string {
+	return auditChange.Field
+}
+
+type AuditEntry struct {
+	Action     string        `json:"action" validate:"required"`
+	Actor      string        `json:"actor" validate:"required"`
+	Changes    []AuditChange `json:"changes,omitempty"`
+	CreatedAt  time.Time     `json:"createdAt" validate:"required"`
+	Id         string        `json:"id" validate:"required"`
+	Resource   string        `json:"resource" validate:"required"`
+	ResourceId *string       `json:"resourceId,omitempty"`
+	SectionId  *string       `json:"sectionId,omitempty"`
+	TenantId   *string       `json:"tenantId,omitempty"`
+}
+
+func (auditEntry AuditEntry) GetAction() string {
+	return auditEntry.Action
+}
+
+func (auditEntry AuditEntry) GetActor() string {
+	return auditEntry.Actor
+}
+
+func (auditEntry AuditEntry) GetChanges() []AuditChange {
+	return auditEntry.Changes
+}
+
+func (auditEntry AuditEntry) GetCreatedAt() time.Time {
+	return auditEntry.CreatedAt
+}
+
+func (auditEntry AuditEntry) GetId() string {
+	return auditEntry.Id
+}
+
+func (auditEntry AuditEntry) GetResource() string {
+	return auditEntry.Resource
+}
+
+func (auditEntry AuditEntry) GetResourceId() string {
+	var v string
+	if auditEntry.ResourceId != nil {
+		return *auditEntry.ResourceId
+	}
+	return v
+}
+
+func (auditEntry AuditEntry) GetSectionId() string {
+	var v string
+	if auditEntry.SectionId != nil {
+		return *auditEntry.SectionId
+	}
+	return v
+}
+
+func (auditEntry AuditEntry) GetTenantId() string {
+	var v string
+	if auditEntry.TenantId != nil {
+		return *auditEntry.TenantId
+	}
+	return v
+}
@@ -0,0 +1,34 @@
+// Code generated by go generate; DO NOT EDIT.
+package bulkqueueaction
+
+import (
+	"fmt"
+	"github.com/arfis/waiting-room/internal/errors"
+)
+
+type BulkQueueAction string
+
+var (
+	UNKNOWN_VALUE  BulkQueueAction = "UNKNOWN_VALUE"
+	CLEAR_WAITING  BulkQueueAction = "CLEAR_WAITING"
+	NO_SHOW_CALLED BulkQueueAction = "NO_SHOW_CALLED"
+	MOVE_WAITING   BulkQueueAction = "MOVE_WAITING"
+)
+
+// String gets the string representation of the BulkQueueAction
+func (c BulkQueueAction) String() string {
+	return string(c)
+}
+
+func StringToBulkQueueAction(source string) (BulkQueueAction, error) {
+	switch source {
+	case string(CLEAR_WAITING):
+		return CLEAR_WAITING, nil
+	case string(NO_SHOW_CALLED):
+		return NO_SHOW_CALLED, nil
+	case string(MOVE_WAITING):
+		return MOVE_WAITING, nil
+	default:
+		return "UNKNOWN_VALUE", errors.Validation(fmt.Errorf("invalid value ('%s') passed to BulkQueueAction", source), nil)
+	}
+}
@@ -0,0 +1,49 @@
+// Code generated by go generate; DO NOT EDIT.
+package dto
+
+type MessageCatalog struct {
+	Language string            `json:"language" validate:"required"`
+	Messages map[string]string `json:"messages" validate:"required"`
+}
+
+func (messageCatalog MessageCatalog) GetLanguage() string {
+	return messageCatalog.Language
+}
+
+func (messageCatalog MessageCatalog) GetMessages() map[string]string {
+	return messageCatalog.Messages
+}
+
+type MessageCatalogTranslationRequest struct {
+	Overwrite       *bool    `json:"overwrite,omitempty"`
+	SourceLanguage  *string  `json:"sourceLanguage,omitempty"`
+	TargetLanguages []string `json:"targetLanguages" validate:"required,min=1"`
+}
+
+func (messageCatalogTranslationRequest MessageCatalogTranslationRequest) GetOverwrite() bool {
+	var v bool
+	if messageCatalogTranslationRequest.Overwrite != nil {
+		return *messageCatalogTranslationRequest.Overwrite
+	}
+	return v
+}
+
+func (messageCatalogTranslationRequest MessageCatalogTranslationRequest) GetSourceLanguage() string {
+	var v string
+	if messageCatalogTranslationRequest.SourceLanguage != nil {
+		return *messageCatalogTranslationRequest.SourceLanguage
+	}
+	return v
+}
+
+func (messageCatalogTranslationRequest MessageCatalogTranslationRequest) GetTargetLanguages() []string {
+	return messageCatalogTranslationRequest.TargetLanguages
+}
+
+type MessageCatalogUpdate struct {
+	Messages map[string]string `json:"messages" validate:"required"`
+}
+
+func (messageCatalogUpdate MessageCatalogUpdate) GetMessages() map[string]string {
+	return messageCatalogUpdate.Messages
+}
@@ -0,0 +1,103 @@
+// Code generated by go generate; DO NOT EDIT.
+package dto
+
+import (
+	"github.com/arfis/waiting-room/internal/data/dto/integrationeventtype"
+)
+
+type IntegrationEvent struct {
+	Appointment     *Appointment                              `json:"appointment,omitempty"`
+	EntryId         *string                                   `json:"entryId,omitempty"`
+	ExternalId      *string                                   `json:"externalId,omitempty"`
+	ServiceDuration *int64                                    `json:"serviceDuration,omitempty"`
+	ServiceId       *string                                   `json:"serviceId,omitempty"`
+	ServiceName     *string                                   `json:"serviceName,omitempty"`
+	Type            integrationeventtype.IntegrationEventType `json:"type" validate:"required"`
+}
+
+func (integrationEvent IntegrationEvent) GetAppointment() Appointment {
+	var v Appointment
+	if integrationEvent.Appointment != nil {
+		return *integrationEvent.Appointment
+	}
+	return v
+}
+
+func (integrationEvent IntegrationEvent) GetEntryId() string {
+	var v string
+	if integrationEvent.EntryId != nil {
+		return *integrationEvent.EntryId
+	}
+	return v
+}
+
+func (integrationEvent IntegrationEvent) GetExternalId() string {
+	var v string
+	if integrationEvent.ExternalId != nil {
+		return *integrationEvent.ExternalId
+	}
+	return v
+}
+
+func (integrationEvent IntegrationEvent) GetServiceDuration() int64 {
+	var v int64
+	if integrationEvent.ServiceDuration != nil {
+		return *integrationEvent.ServiceDuration
+	}
+	return v
+}
+
+func (integrationEvent IntegrationEvent) GetServiceId() string {
+	var v string
+	if integrationEvent.ServiceId != nil {
+		return *integrationEvent.ServiceId
+	}
+	return v
+}
+
+func (integrationEvent IntegrationEvent) GetServiceName() string {
+	var v string
+	if integrationEvent.ServiceName != nil {
+		return *integrationEvent.ServiceName
+	}
+	return v
+}
+
+func (integrationEvent IntegrationEvent) GetType() integrationeventtype.IntegrationEventType {
+	return integrationEvent.Type
+}
+
+type IntegrationEventResult struct {
+	Appointment  *Appointment                              `json:"appointment,omitempty"`
+	EntryId      *string                                   `json:"entryId,omitempty"`
+	TicketNumber *string                                   `json:"ticketNumber,omitempty"`
+	Type         integrationeventtype.IntegrationEventType `json:"type" validate:"required"`
+}
+
+func (integrationEventResult IntegrationEventResult) GetAppointment() Appointment {
+	var v Appointment
+	if integrationEventResult.Appointment != nil {
+		return *integrationEventResult.Appointment
+	}
+	return v
+}
+
+func (integrationEventResult IntegrationEventResult) GetEntryId() string {
+	var v string
+	if integrationEventResult.EntryId != nil {
+		return *integrationEventResult.EntryId
+	}
+	return v
+}
+
+func (integrationEventResult IntegrationEventResult) GetTicketNumber() string {
+	var v string
+	if integrationEventResult.TicketNumber != nil {
+		return *integrationEventResult.TicketNumber
+	}
+	return v
+}
+
+func (integrationEventResult IntegrationEventResult) GetType() integrationeventtype.IntegrationEventType {
+	return integrationEventResult.Type
+}
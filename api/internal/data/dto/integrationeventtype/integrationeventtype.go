@@ -0,0 +1,37 @@
+// Code generated by go generate; DO NOT EDIT.
+package integrationeventtype
+
+import (
+	"fmt"
+	"github.com/arfis/waiting-room/internal/errors"
+)
+
+type IntegrationEventType string
+
+var (
+	UNKNOWN_VALUE  IntegrationEventType = "UNKNOWN_VALUE"
+	PRE_REGISTER   IntegrationEventType = "PRE_REGISTER"
+	ARRIVED        IntegrationEventType = "ARRIVED"
+	CANCEL         IntegrationEventType = "CANCEL"
+	CHANGE_SERVICE IntegrationEventType = "CHANGE_SERVICE"
+)
+
+// String gets the string representation of the IntegrationEventType
+func (c IntegrationEventType) String() string {
+	return string(c)
+}
+
+func StringToIntegrationEventType(source string) (IntegrationEventType, error) {
+	switch source {
+	case string(PRE_REGISTER):
+		return PRE_REGISTER, nil
+	case string(ARRIVED):
+		return ARRIVED, nil
+	case string(CANCEL):
+		return CANCEL, nil
+	case string(CHANGE_SERVICE):
+		return CHANGE_SERVICE, nil
+	default:
+		return "UNKNOWN_VALUE", errors.Validation(fmt.Errorf("invalid value ('%s') passed to IntegrationEventType", source), nil)
+	}
+}
@@ -1,12 +1,55 @@
 // Code generated by go generate; DO NOT EDIT.
 package dto
 
+type EncryptedCardData struct {
+	Alg        string `json:"alg" validate:"required"`
+	Ciphertext string `json:"ciphertext" validate:"required"`
+	DeviceId   string `json:"deviceId" validate:"required"`
+	Kid        string `json:"kid" validate:"required"`
+	Nonce      string `json:"nonce" validate:"required"`
+	Token      string `json:"token" validate:"required"`
+}
+
+func (encryptedCardData EncryptedCardData) GetAlg() string {
+	return encryptedCardData.Alg
+}
+
+func (encryptedCardData EncryptedCardData) GetCiphertext() string {
+	return encryptedCardData.Ciphertext
+}
+
+func (encryptedCardData EncryptedCardData) GetDeviceId() string {
+	return encryptedCardData.DeviceId
+}
+
+func (encryptedCardData EncryptedCardData) GetKid() string {
+	return encryptedCardData.Kid
+}
+
+func (encryptedCardData EncryptedCardData) GetNonce() string {
+	return encryptedCardData.Nonce
+}
+
+func (encryptedCardData EncryptedCardData) GetToken() string {
+	return encryptedCardData.Token
+}
+
 type JoinResult struct {
+	Duplicate       *bool   `json:"duplicate,omitempty"`
 	EntryID         string  `json:"entryID" validate:"required"`
 	QrUrl           string  `json:"qrUrl" validate:"required"`
 	ServiceDuration *int64  `json:"serviceDuration,omitempty"`
 	ServiceName     *string `json:"serviceName,omitempty"`
 	TicketNumber    string  `json:"ticketNumber" validate:"required"`
+	VisitorCode     *string `json:"visitorCode,omitempty"`
+}
+
+func (joinResult JoinResult) GetDuplicate() bool {
+	var v bool
+	if joinResult.Duplicate != nil {
+		return *joinResult.Duplicate
+	}
+	return v
 }
 
 func (joinResult JoinResult) GetEntryID() string {
@@ -37,13 +80,33 @@ func (joinResult JoinResult) GetTicketNumber() string {
 	return joinResult.TicketNumber
 }
 
+func (joinResult JoinResult) GetVisitorCode() string {
+	var v string
+	if joinResult.VisitorCode != nil {
+		return *joinResult.VisitorCode
+	}
+	return v
+}
+
 type SwipeRequest struct {
+	EncryptedCardData  *EncryptedCardData  `json:"encryptedCardData,omitempty"`
 	IdCardRaw          *string             `json:"idCardRaw,omitempty"`
+	IdempotencyKey     *string             `json:"idempotencyKey,omitempty"`
+	Language           *string             `json:"language,omitempty"`
+	PathwayId          *string             `json:"pathwayId,omitempty"`
 	PatientInformation *PatientInformation `json:"patientInformation,omitempty"`
 	ServiceDuration    *int64              `json:"serviceDuration,omitempty"`
 	ServiceId          *string             `json:"serviceId,omitempty"`
 }
 
+func (swipeRequest SwipeRequest) GetEncryptedCardData() EncryptedCardData {
+	var v EncryptedCardData
+	if swipeRequest.EncryptedCardData != nil {
+		return *swipeRequest.EncryptedCardData
+	}
+	return v
+}
+
 func (swipeRequest SwipeRequest) GetIdCardRaw() string {
 	var v string
 	if swipeRequest.IdCardRaw != nil {
@@ -52,6 +115,30 @@ func (swipeRequest SwipeRequest) GetIdCardRaw() string {
 	return v
 }
 
+func (swipeRequest SwipeRequest) GetIdempotencyKey() string {
+	var v string
+	if swipeRequest.IdempotencyKey != nil {
+		return *swipeRequest.IdempotencyKey
+	}
+	return v
+}
+
+func (swipeRequest SwipeRequest) GetLanguage() string {
+	var v string
+	if swipeRequest.Language != nil {
+		return *swipeRequest.Language
+	}
+	return v
+}
+
+func (swipeRequest SwipeRequest) GetPathwayId() string {
+	var v string
+	if swipeRequest.PathwayId != nil {
+		return *swipeRequest.PathwayId
+	}
+	return v
+}
+
 func (swipeRequest SwipeRequest) GetPatientInformation() PatientInformation {
 	var v PatientInformation
 	if swipeRequest.PatientInformation != nil {
@@ -93,3 +180,73 @@ func (userService UserService) GetId() string {
 func (userService UserService) GetServiceName() string {
 	return userService.ServiceName
 }
+
+type UserServicesResp struct {
+	Degraded bool          `json:"degraded"`
+	Services []UserService `json:"services" validate:"required,dive"`
+}
+
+func (userServicesResp UserServicesResp) GetDegraded() bool {
+	return userServicesResp.Degraded
+}
+
+func (userServicesResp UserServicesResp) GetServices() []UserService {
+	return userServicesResp.Services
+}
+
+type WalkInRequest struct {
+	IdempotencyKey  *string `json:"idempotencyKey,omitempty"`
+	Language        *string `json:"language,omitempty"`
+	PathwayId       *string `json:"pathwayId,omitempty"`
+	ServiceDuration *int64  `json:"serviceDuration,omitempty"`
+	ServiceId       *string `json:"serviceId,omitempty"`
+	ServiceName     *string `json:"serviceName,omitempty"`
+}
+
+func (walkInRequest WalkInRequest) GetIdempotencyKey() string {
+	var v string
+	if walkInRequest.IdempotencyKey != nil {
+		return *walkInRequest.IdempotencyKey
+	}
+	return v
+}
+
+func (walkInRequest WalkInRequest) GetLanguage() string {
+	var v string
+	if walkInRequest.Language != nil {
+		return *walkInRequest.Language
+	}
+	return v
+}
+
+func (walkInRequest WalkInRequest) GetPathwayId() string {
+	var v string
+	if walkInRequest.PathwayId != nil {
+		return *walkInRequest.PathwayId
+	}
+	return v
+}
+
+func (walkInRequest WalkInRequest) GetServiceDuration() int64 {
+	var v int64
+	if walkInRequest.ServiceDuration != nil {
+		return *walkInRequest.ServiceDuration
+	}
+	return v
+}
+
+func (walkInRequest WalkInRequest) GetServiceId() string {
+	var v string
+	if walkInRequest.ServiceId != nil {
+		return *walkInRequest.ServiceId
+	}
+	return v
+}
+
+func (walkInRequest WalkInRequest) GetServiceName() string {
+	var v string
+	if walkInRequest.ServiceName != nil {
+		return *walkInRequest.ServiceName
+	}
+	return v
+}
@@ -5,9 +5,138 @@ package dto
 import (
 	"time"
 
+	"github.com/arfis/waiting-room/internal/data/dto/bulkqueueaction"
 	"github.com/arfis/waiting-room/internal/data/dto/queueentrystatus"
+	"github.com/arfis/waiting-room/internal/data/dto/webhookdeliverystatus"
 )
 
+type AnnotateEntryRequest struct {
+	Notes *string  `json:"notes,omitempty" validate:"omitempty,max=2000"`
+	Tags  []string `json:"tags,omitempty" validate:"omitempty,max=20,dive,max=64"`
+}
+
+func (annotateEntryRequest AnnotateEntryRequest) GetNotes() string {
+	var v string
+	if annotateEntryRequest.Notes != nil {
+		return *annotateEntryRequest.Notes
+	}
+	return v
+}
+
+func (annotateEntryRequest AnnotateEntryRequest) GetTags() []string {
+	return annotateEntryRequest.Tags
+}
+
+type BulkQueueOperationRequest struct {
+	Action       bulkqueueaction.BulkQueueAction `json:"action" validate:"required"`
+	TargetRoomId *string                         `json:"targetRoomId,omitempty"`
+}
+
+func (bulkQueueOperationRequest BulkQueueOperationRequest) GetAction() bulkqueueaction.BulkQueueAction {
+	return bulkQueueOperationRequest.Action
+}
+
+func (bulkQueueOperationRequest BulkQueueOperationRequest) GetTargetRoomId() string {
+	var v string
+	if bulkQueueOperationRequest.TargetRoomId != nil {
+		return *bulkQueueOperationRequest.TargetRoomId
+	}
+	return v
+}
+
+type BulkQueueOperationResult struct {
+	Action   bulkqueueaction.BulkQueueAction `json:"action" validate:"required"`
+	Affected int64                           `json:"affected"`
+}
+
+func (bulkQueueOperationResult BulkQueueOperationResult) GetAction() bulkqueueaction.BulkQueueAction {
+	return bulkQueueOperationResult.Action
+}
+
+func (bulkQueueOperationResult BulkQueueOperationResult) GetAffected() int64 {
+	return bulkQueueOperationResult.Affected
+}
+
+type DataSubjectErasureRequest struct {
+	Identifier string `json:"identifier" validate:"required"`
+}
+
+func (dataSubjectErasureRequest DataSubjectErasureRequest) GetIdentifier() string {
+	return dataSubjectErasureRequest.Identifier
+}
+
+type DataSubjectErasureResult struct {
+	Deleted int64 `json:"deleted"`
+}
+
+func (dataSubjectErasureResult DataSubjectErasureResult) GetDeleted() int64 {
+	return dataSubjectErasureResult.Deleted
+}
+
+type EntryHistoryEvent struct {
+	ActorId        *string   `json:"actorId,omitempty"`
+	EntryId        string    `json:"entryId" validate:"required"`
+	Event          string    `json:"event" validate:"required"`
+	FromStatus     *string   `json:"fromStatus,omitempty"`
+	Reason         *string   `json:"reason,omitempty"`
+	RoomId         string    `json:"roomId" validate:"required"`
+	ServicePointId *string   `json:"servicePointId,omitempty"`
+	Timestamp      time.Time `json:"timestamp" validate:"required"`
+	ToStatus       string    `json:"toStatus" validate:"required"`
+}
+
+func (entryHistoryEvent EntryHistoryEvent) GetActorId() string {
+	var v string
+	if entryHistoryEvent.ActorId != nil {
+		return *entryHistoryEvent.ActorId
+	}
+	return v
+}
+
+func (entryHistoryEvent EntryHistoryEvent) GetEntryId() string {
+	return entryHistoryEvent.EntryId
+}
+
+func (entryHistoryEvent EntryHistoryEvent) GetEvent() string {
+	return entryHistoryEvent.Event
+}
+
+func (entryHistoryEvent EntryHistoryEvent) GetFromStatus() string {
+	var v string
+	if entryHistoryEvent.FromStatus != nil {
+		return *entryHistoryEvent.FromStatus
+	}
+	return v
+}
+
+func (entryHistoryEvent EntryHistoryEvent) GetReason() string {
+	var v string
+	if entryHistoryEvent.Reason != nil {
+		return *entryHistoryEvent.Reason
+	}
+	return v
+}
+
+func (entryHistoryEvent EntryHistoryEvent) GetRoomId() string {
+	return entryHistoryEvent.RoomId
+}
+
+func (entryHistoryEvent EntryHistoryEvent) GetServicePointId() string {
+	var v string
+	if entryHistoryEvent.ServicePointId != nil {
+		return *entryHistoryEvent.ServicePointId
+	}
+	return v
+}
+
+func (entryHistoryEvent EntryHistoryEvent) GetTimestamp() time.Time {
+	return entryHistoryEvent.Timestamp
+}
+
+func (entryHistoryEvent EntryHistoryEvent) GetToStatus() string {
+	return entryHistoryEvent.ToStatus
+}
+
 type MarkInRoomRequest struct {
 	EntryID string `json:"entryID" validate:"required"`
 }
@@ -16,13 +145,64 @@ func (markInRoomRequest MarkInRoomRequest) GetEntryID() string {
 	return markInRoomRequest.EntryID
 }
 
+type NotificationContact struct {
+	Email *string `json:"email,omitempty"`
+	Phone *string `json:"phone,omitempty"`
+}
+
+func (notificationContact NotificationContact) GetEmail() string {
+	var v string
+	if notificationContact.Email != nil {
+		return *notificationContact.Email
+	}
+	return v
+}
+
+func (notificationContact NotificationContact) GetPhone() string {
+	var v string
+	if notificationContact.Phone != nil {
+		return *notificationContact.Phone
+	}
+	return v
+}
+
+type PriorityBoostRequest struct {
+	ManagerId      string   `json:"managerId" validate:"required"`
+	ManualOverride *float64 `json:"manualOverride,omitempty"`
+	Reason         string   `json:"reason" validate:"required"`
+}
+
+func (priorityBoostRequest PriorityBoostRequest) GetManagerId() string {
+	return priorityBoostRequest.ManagerId
+}
+
+func (priorityBoostRequest PriorityBoostRequest) GetManualOverride() float64 {
+	var v float64
+	if priorityBoostRequest.ManualOverride != nil {
+		return *priorityBoostRequest.ManualOverride
+	}
+	return v
+}
+
+func (priorityBoostRequest PriorityBoostRequest) GetReason() string {
+	return priorityBoostRequest.Reason
+}
+
 type PublicEntry struct {
-	CanCancel    bool                              `json:"canCancel"`
-	EntryID      string                            `json:"entryID" validate:"required"`
-	EtaMinutes   int64                             `json:"etaMinutes"`
-	Position     int64                             `json:"position"`
-	Status       queueentrystatus.QueueEntryStatus `json:"status" validate:"required"`
-	TicketNumber string                            `json:"ticketNumber" validate:"required"`
+	CanCancel               bool                              `json:"canCancel"`
+	EntryID                 string                            `json:"entryID" validate:"required"`
+	EstimatedWaitSeconds    *int64                            `json:"estimatedWaitSeconds,omitempty"`
+	EtaMinutes              int64                             `json:"etaMinutes"`
+	NotificationsRegistered *bool                             `json:"notificationsRegistered,omitempty"`
+	PeopleAhead             *int64                            `json:"peopleAhead,omitempty"`
+	Position                int64                             `json:"position"`
+	QueuePaused             *bool                             `json:"queuePaused,omitempty"`
+	QueuePausedReason       *string                           `json:"queuePausedReason,omitempty"`
+	ServicePoint            *string                           `json:"servicePoint,omitempty"`
+	ServicePointName        *string                           `json:"servicePointName,omitempty"`
+	Status                  queueentrystatus.QueueEntryStatus `json:"status" validate:"required"`
+	TicketNumber            string                            `json:"ticketNumber" validate:"required"`
+	WaitingRoomID           *string                           `json:"waitingRoomID,omitempty"`
 }
 
 func (publicEntry PublicEntry) GetCanCancel() bool {
@@ -33,14 +213,70 @@ func (publicEntry PublicEntry) GetEntryID() string {
 	return publicEntry.EntryID
 }
 
+func (publicEntry PublicEntry) GetEstimatedWaitSeconds() int64 {
+	var v int64
+	if publicEntry.EstimatedWaitSeconds != nil {
+		return *publicEntry.EstimatedWaitSeconds
+	}
+	return v
+}
+
 func (publicEntry PublicEntry) GetEtaMinutes() int64 {
 	return publicEntry.EtaMinutes
 }
 
+func (publicEntry PublicEntry) GetNotificationsRegistered() bool {
+	var v bool
+	if publicEntry.NotificationsRegistered != nil {
+		return *publicEntry.NotificationsRegistered
+	}
+	return v
+}
+
+func (publicEntry PublicEntry) GetPeopleAhead() int64 {
+	var v int64
+	if publicEntry.PeopleAhead != nil {
+		return *publicEntry.PeopleAhead
+	}
+	return v
+}
+
 func (publicEntry PublicEntry) GetPosition() int64 {
 	return publicEntry.Position
 }
 
+func (publicEntry PublicEntry) GetQueuePaused() bool {
+	var v bool
+	if publicEntry.QueuePaused != nil {
+		return *publicEntry.QueuePaused
+	}
+	return v
+}
+
+func (publicEntry PublicEntry) GetQueuePausedReason() string {
+	var v string
+	if publicEntry.QueuePausedReason != nil {
+		return *publicEntry.QueuePausedReason
+	}
+	return v
+}
+
+func (publicEntry PublicEntry) GetServicePoint() string {
+	var v string
+	if publicEntry.ServicePoint != nil {
+		return *publicEntry.ServicePoint
+	}
+	return v
+}
+
+func (publicEntry PublicEntry) GetServicePointName() string {
+	var v string
+	if publicEntry.ServicePointName != nil {
+		return *publicEntry.ServicePointName
+	}
+	return v
+}
+
 func (publicEntry PublicEntry) GetStatus() queueentrystatus.QueueEntryStatus {
 	return publicEntry.Status
 }
@@ -49,19 +285,37 @@ func (publicEntry PublicEntry) GetTicketNumber() string {
 	return publicEntry.TicketNumber
 }
 
+func (publicEntry PublicEntry) GetWaitingRoomID() string {
+	var v string
+	if publicEntry.WaitingRoomID != nil {
+		return *publicEntry.WaitingRoomID
+	}
+	return v
+}
+
 type QueueEntry struct {
-	ID              string                            `json:"ID" validate:"required"`
-	Age             *int64                            `json:"age,omitempty"`
-	AppointmentTime *time.Time                        `json:"appointmentTime,omitempty"`
-	CreatedAt       *time.Time                        `json:"createdAt,omitempty"`
-	Position        int64                             `json:"position"`
-	ServiceDuration *int64                            `json:"serviceDuration,omitempty"`
-	ServiceName     *string                           `json:"serviceName,omitempty"`
-	ServicePoint    *string                           `json:"servicePoint,omitempty"`
-	Status          queueentrystatus.QueueEntryStatus `json:"status" validate:"required"`
-	Symbols         []string                          `json:"symbols,omitempty" validate:"dive"`
-	TicketNumber    string                            `json:"ticketNumber" validate:"required"`
-	WaitingRoomID   string                            `json:"waitingRoomID" validate:"required"`
+	ID                   string                            `json:"ID" validate:"required"`
+	Age                  *int64                            `json:"age,omitempty"`
+	AppointmentTime      *time.Time                        `json:"appointmentTime,omitempty"`
+	CreatedAt            *time.Time                        `json:"createdAt,omitempty"`
+	EstimatedWaitSeconds *int64                            `json:"estimatedWaitSeconds,omitempty"`
+	FitnessBreakdown     *PriorityFitnessBreakdown         `json:"fitnessBreakdown,omitempty"`
+	FitnessScore         *float64                          `json:"fitnessScore,omitempty"`
+	Notes                *string                           `json:"notes,omitempty"`
+	Position             int64                             `json:"position"`
+	QueuePaused          *bool                             `json:"queuePaused,omitempty"`
+	QueuePausedReason    *string                           `json:"queuePausedReason,omitempty"`
+	ServiceDuration      *int64                            `json:"serviceDuration,omitempty"`
+	ServiceName          *string                           `json:"serviceName,omitempty"`
+	ServicePoint         *string                           `json:"servicePoint,omitempty"`
+	Status               queueentrystatus.QueueEntryStatus `json:"status" validate:"required"`
+	Symbols              []string                          `json:"symbols,omitempty" validate:"dive"`
+	Tags                 []string                          `json:"tags,omitempty" validate:"dive"`
+	TicketNumber         string                            `json:"ticketNumber" validate:"required"`
+	Tier                 *int64                            `json:"tier,omitempty"`
+	TierEscalated        *bool                             `json:"tierEscalated,omitempty"`
+	VisitorCode          *string                           `json:"visitorCode,omitempty"`
+	WaitingRoomID        string                            `json:"waitingRoomID" validate:"required"`
 }
 
 func (queueEntry QueueEntry) GetID() string {
@@ -76,10 +330,58 @@ func (queueEntry QueueEntry) GetAge() int64 {
 	return v
 }
 
+func (queueEntry QueueEntry) GetEstimatedWaitSeconds() int64 {
+	var v int64
+	if queueEntry.EstimatedWaitSeconds != nil {
+		return *queueEntry.EstimatedWaitSeconds
+	}
+	return v
+}
+
+func (queueEntry QueueEntry) GetFitnessBreakdown() PriorityFitnessBreakdown {
+	var v PriorityFitnessBreakdown
+	if queueEntry.FitnessBreakdown != nil {
+		return *queueEntry.FitnessBreakdown
+	}
+	return v
+}
+
+func (queueEntry QueueEntry) GetFitnessScore() float64 {
+	var v float64
+	if queueEntry.FitnessScore != nil {
+		return *queueEntry.FitnessScore
+	}
+	return v
+}
+
+func (queueEntry QueueEntry) GetNotes() string {
+	var v string
+	if queueEntry.Notes != nil {
+		return *queueEntry.Notes
+	}
+	return v
+}
+
 func (queueEntry QueueEntry) GetPosition() int64 {
 	return queueEntry.Position
 }
 
+func (queueEntry QueueEntry) GetQueuePaused() bool {
+	var v bool
+	if queueEntry.QueuePaused != nil {
+		return *queueEntry.QueuePaused
+	}
+	return v
+}
+
+func (queueEntry QueueEntry) GetQueuePausedReason() string {
+	var v string
+	if queueEntry.QueuePausedReason != nil {
+		return *queueEntry.QueuePausedReason
+	}
+	return v
+}
+
 func (queueEntry QueueEntry) GetServiceDuration() int64 {
 	var v int64
 	if queueEntry.ServiceDuration != nil {
@@ -112,14 +414,150 @@ func (queueEntry QueueEntry) GetSymbols() []string {
 	return queueEntry.Symbols
 }
 
+func (queueEntry QueueEntry) GetTags() []string {
+	return queueEntry.Tags
+}
+
 func (queueEntry QueueEntry) GetTicketNumber() string {
 	return queueEntry.TicketNumber
 }
 
+func (queueEntry QueueEntry) GetTier() int64 {
+	var v int64
+	if queueEntry.Tier != nil {
+		return *queueEntry.Tier
+	}
+	return v
+}
+
+func (queueEntry QueueEntry) GetTierEscalated() bool {
+	var v bool
+	if queueEntry.TierEscalated != nil {
+		return *queueEntry.TierEscalated
+	}
+	return v
+}
+
+func (queueEntry QueueEntry) GetVisitorCode() string {
+	var v string
+	if queueEntry.VisitorCode != nil {
+		return *queueEntry.VisitorCode
+	}
+	return v
+}
+
 func (queueEntry QueueEntry) GetWaitingRoomID() string {
 	return queueEntry.WaitingRoomID
 }
 
+type TransferEntryRequest struct {
+	TargetRoomID         *string `json:"targetRoomID,omitempty"`
+	TargetServicePointID *string `json:"targetServicePointID,omitempty"`
+}
+
+func (transferEntryRequest TransferEntryRequest) GetTargetRoomID() string {
+	var v string
+	if transferEntryRequest.TargetRoomID != nil {
+		return *transferEntryRequest.TargetRoomID
+	}
+	return v
+}
+
+func (transferEntryRequest TransferEntryRequest) GetTargetServicePointID() string {
+	var v string
+	if transferEntryRequest.TargetServicePointID != nil {
+		return *transferEntryRequest.TargetServicePointID
+	}
+	return v
+}
+
+type RecallEntryRequest struct {
+	TierAdjustment *int64 `json:"tierAdjustment,omitempty"`
+}
+
+func (recallEntryRequest RecallEntryRequest) GetTierAdjustment() int64 {
+	var v int64
+	if recallEntryRequest.TierAdjustment != nil {
+		return *recallEntryRequest.TierAdjustment
+	}
+	return v
+}
+
+type RoomState struct {
+	Paused         bool       `json:"paused"`
+	Reason         *string    `json:"reason,omitempty"`
+	RedirectRoomId *string    `json:"redirectRoomId,omitempty"`
+	RoomId         string     `json:"roomId" validate:"required"`
+	ServicePointId *string    `json:"servicePointId,omitempty"`
+	UpdatedAt      *time.Time `json:"updatedAt,omitempty"`
+}
+
+func (roomState RoomState) GetPaused() bool {
+	return roomState.Paused
+}
+
+func (roomState RoomState) GetReason() string {
+	var v string
+	if roomState.Reason != nil {
+		return *roomState.Reason
+	}
+	return v
+}
+
+func (roomState RoomState) GetRedirectRoomId() string {
+	var v string
+	if roomState.RedirectRoomId != nil {
+		return *roomState.RedirectRoomId
+	}
+	return v
+}
+
+func (roomState RoomState) GetRoomId() string {
+	return roomState.RoomId
+}
+
+func (roomState RoomState) GetServicePointId() string {
+	var v string
+	if roomState.ServicePointId != nil {
+		return *roomState.ServicePointId
+	}
+	return v
+}
+
+func (roomState RoomState) GetUpdatedAt() time.Time {
+	var v time.Time
+	if roomState.UpdatedAt != nil {
+		return *roomState.UpdatedAt
+	}
+	return v
+}
+
+type RoomStateRequest struct {
+	Paused         bool    `json:"paused"`
+	Reason         *string `json:"reason,omitempty"`
+	RedirectRoomId *string `json:"redirectRoomId,omitempty"`
+}
+
+func (roomStateRequest RoomStateRequest) GetPaused() bool {
+	return roomStateRequest.Paused
+}
+
+func (roomStateRequest RoomStateRequest) GetReason() string {
+	var v string
+	if roomStateRequest.Reason != nil {
+		return *roomStateRequest.Reason
+	}
+	return v
+}
+
+func (roomStateRequest RoomStateRequest) GetRedirectRoomId() string {
+	var v string
+	if roomStateRequest.RedirectRoomId != nil {
+		return *roomStateRequest.RedirectRoomId
+	}
+	return v
+}
+
 type ServicePoint struct {
 	ID          string  `json:"ID" validate:"required"`
 	Description *string `json:"description,omitempty"`
@@ -141,3 +579,137 @@ func (servicePoint ServicePoint) GetDescription() string {
 func (servicePoint ServicePoint) GetName() string {
 	return servicePoint.Name
 }
+
+type WebPushStatus struct {
+	PublicKey  *string `json:"publicKey,omitempty"`
+	Subscribed bool    `json:"subscribed"`
+}
+
+func (webPushStatus WebPushStatus) GetPublicKey() string {
+	var v string
+	if webPushStatus.PublicKey != nil {
+		return *webPushStatus.PublicKey
+	}
+	return v
+}
+
+func (webPushStatus WebPushStatus) GetSubscribed() bool {
+	return webPushStatus.Subscribed
+}
+
+type WebPushSubscription struct {
+	Endpoint string                   `json:"endpoint" validate:"required"`
+	Keys     *WebPushSubscriptionKeys `json:"keys" validate:"required"`
+}
+
+func (webPushSubscription WebPushSubscription) GetEndpoint() string {
+	return webPushSubscription.Endpoint
+}
+
+func (webPushSubscription WebPushSubscription) GetKeys() WebPushSubscriptionKeys {
+	var v WebPushSubscriptionKeys
+	if webPushSubscription.Keys != nil {
+		return *webPushSubscription.Keys
+	}
+	return v
+}
+
+type WebPushSubscriptionKeys struct {
+	Auth   string `json:"auth" validate:"required"`
+	P256dh string `json:"p256dh" validate:"required"`
+}
+
+func (webPushSubscriptionKeys WebPushSubscriptionKeys) GetAuth() string {
+	return webPushSubscriptionKeys.Auth
+}
+
+func (webPushSubscriptionKeys WebPushSubscriptionKeys) GetP256dh() string {
+	return webPushSubscriptionKeys.P256dh
+}
+
+type WebhookDelivery struct {
+	Attempts       int64                                       `json:"attempts"`
+	CreatedAt      *time.Time                                  `json:"createdAt,omitempty"`
+	DeliveredAt    *time.Time                                  `json:"deliveredAt,omitempty"`
+	Event          string                                      `json:"event" validate:"required"`
+	Id             string                                      `json:"id" validate:"required"`
+	LastError      *string                                     `json:"lastError,omitempty"`
+	LastStatusCode *int64                                      `json:"lastStatusCode,omitempty"`
+	MaxAttempts    int64                                       `json:"maxAttempts"`
+	NextAttemptAt  *time.Time                                  `json:"nextAttemptAt,omitempty"`
+	Payload        *string                                     `json:"payload,omitempty"`
+	Status         webhookdeliverystatus.WebhookDeliveryStatus `json:"status" validate:"required"`
+	Url            string                                      `json:"url" validate:"required"`
+}
+
+func (webhookDelivery WebhookDelivery) GetAttempts() int64 {
+	return webhookDelivery.Attempts
+}
+
+func (webhookDelivery WebhookDelivery) GetCreatedAt() time.Time {
+	var v time.Time
+	if webhookDelivery.CreatedAt != nil {
+		return *webhookDelivery.CreatedAt
+	}
+	return v
+}
+
+func (webhookDelivery WebhookDelivery) GetDeliveredAt() time.Time {
+	var v time.Time
+	if webhookDelivery.DeliveredAt != nil {
+		return *webhookDelivery.DeliveredAt
+	}
+	return v
+}
+
+func (webhookDelivery WebhookDelivery) GetEvent() string {
+	return webhookDelivery.Event
+}
+
+func (webhookDelivery WebhookDelivery) GetId() string {
+	return webhookDelivery.Id
+}
+
+func (webhookDelivery WebhookDelivery) GetLastError() string {
+	var v string
+	if webhookDelivery.LastError != nil {
+		return *webhookDelivery.LastError
+	}
+	return v
+}
+
+func (webhookDelivery WebhookDelivery) GetLastStatusCode() int64 {
+	var v int64
+	if webhookDelivery.LastStatusCode != nil {
+		return *webhookDelivery.LastStatusCode
+	}
+	return v
+}
+
+func (webhookDelivery WebhookDelivery) GetMaxAttempts() int64 {
+	return webhookDelivery.MaxAttempts
+}
+
+func (webhookDelivery WebhookDelivery) GetNextAttemptAt() time.Time {
+	var v time.Time
+	if webhookDelivery.NextAttemptAt != nil {
+		return *webhookDelivery.NextAttemptAt
+	}
+	return v
+}
+
+func (webhookDelivery WebhookDelivery) GetPayload() string {
+	var v string
+	if webhookDelivery.Payload != nil {
+		return *webhookDelivery.Payload
+	}
+	return v
+}
+
+func (webhookDelivery WebhookDelivery) GetStatus() webhookdeliverystatus.WebhookDeliveryStatus {
+	return webhookDelivery.Status
+}
+
+func (webhookDelivery WebhookDelivery) GetUrl() string {
+	return webhookDelivery.Url
+}
@@ -0,0 +1,34 @@
+// Code generated by go generate; DO NOT EDIT.
+package queueordering
+
+import (
+	"fmt"
+	"github.com/arfis/waiting-room/internal/errors"
+)
+
+type QueueOrdering string
+
+var (
+	UNKNOWN_VALUE     QueueOrdering = "UNKNOWN_VALUE"
+	PRIORITY          QueueOrdering = "PRIORITY"
+	FIFO              QueueOrdering = "FIFO"
+	APPOINTMENT_FIRST QueueOrdering = "APPOINTMENT_FIRST"
+)
+
+// String gets the string representation of the QueueOrdering
+func (c QueueOrdering) String() string {
+	return string(c)
+}
+
+func StringToQueueOrdering(source string) (QueueOrdering, error) {
+	switch source {
+	case string(PRIORITY):
+		return PRIORITY, nil
+	case string(FIFO):
+		return FIFO, nil
+	case string(APPOINTMENT_FIRST):
+		return APPOINTMENT_FIRST, nil
+	default:
+		return "UNKNOWN_VALUE", errors.Validation(fmt.Errorf("invalid value ('%s') passed to QueueOrdering", source), nil)
+	}
+}
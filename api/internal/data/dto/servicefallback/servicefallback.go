@@ -0,0 +1,31 @@
+// Code generated by go generate; DO NOT EDIT.
+package servicefallback
+
+import (
+	"fmt"
+	"github.com/arfis/waiting-room/internal/errors"
+)
+
+type ServiceFallback string
+
+var (
+	UNKNOWN_VALUE ServiceFallback = "UNKNOWN_VALUE"
+	ANY           ServiceFallback = "ANY"
+	NONE          ServiceFallback = "NONE"
+)
+
+// String gets the string representation of the ServiceFallback
+func (c ServiceFallback) String() string {
+	return string(c)
+}
+
+func StringToServiceFallback(source string) (ServiceFallback, error) {
+	switch source {
+	case string(ANY):
+		return ANY, nil
+	case string(NONE):
+		return NONE, nil
+	default:
+		return "UNKNOWN_VALUE", errors.Validation(fmt.Errorf("invalid value ('%s') passed to ServiceFallback", source), nil)
+	}
+}
@@ -17,12 +17,21 @@ func (managerLoginRequest ManagerLoginRequest) GetServicePointID() string {
 }
 
 type ManagerStatus struct {
-	IsAvailable    bool      `json:"isAvailable"`
-	LastSeen       time.Time `json:"lastSeen" validate:"required"`
-	ManagerID      string    `json:"managerID" validate:"required"`
-	ManagerName    string    `json:"managerName" validate:"required"`
-	RoomID         string    `json:"roomID" validate:"required"`
-	ServicePointID string    `json:"servicePointID" validate:"required"`
+	ClaimedAt      *time.Time `json:"claimedAt,omitempty"`
+	IsAvailable    bool       `json:"isAvailable"`
+	LastSeen       time.Time  `json:"lastSeen" validate:"required"`
+	ManagerID      string     `json:"managerID" validate:"required"`
+	ManagerName    string     `json:"managerName" validate:"required"`
+	RoomID         string     `json:"roomID" validate:"required"`
+	ServicePointID string     `json:"servicePointID" validate:"required"`
+}
+
+func (managerStatus ManagerStatus) GetClaimedAt() time.Time {
+	var v time.Time
+	if managerStatus.ClaimedAt != nil {
+		return *managerStatus.ClaimedAt
+	}
+	return v
 }
 
 func (managerStatus ManagerStatus) GetIsAvailable() bool {
@@ -0,0 +1,34 @@
+// Code generated by go generate; DO NOT EDIT.
+package webhookdeliverystatus
+
+import (
+	"fmt"
+	"github.com/arfis/waiting-room/internal/errors"
+)
+
+type WebhookDeliveryStatus string
+
+var (
+	UNKNOWN_VALUE WebhookDeliveryStatus = "UNKNOWN_VALUE"
+	PENDING       WebhookDeliveryStatus = "PENDING"
+	DELIVERED     WebhookDeliveryStatus = "DELIVERED"
+	FAILED        WebhookDeliveryStatus = "FAILED"
+)
+
+// String gets the string representation of the WebhookDeliveryStatus
+func (c WebhookDeliveryStatus) String() string {
+	return string(c)
+}
+
+func StringToWebhookDeliveryStatus(source string) (WebhookDeliveryStatus, error) {
+	switch source {
+	case string(PENDING):
+		return PENDING, nil
+	case string(DELIVERED):
+		return DELIVERED, nil
+	case string(FAILED):
+		return FAILED, nil
+	default:
+		return "UNKNOWN_VALUE", errors.Validation(fmt.Errorf("invalid value ('%s') passed to WebhookDeliveryStatus", source), nil)
+	}
+}
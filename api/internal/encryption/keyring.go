@@ -0,0 +1,256 @@
+package encryption
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// encryptedPrefix marks an encrypted field value; values without it were stored in plain
+const encryptedPrefix = "enc:v1:"
+
+// DataKeyStore stores the wrapped data keys of the tenants
+type DataKeyStore interface {
+	// GetDataKey returns the data key of the tenant, nil when it has none yet
+	GetDataKey(ctx context.Context, tenantID string) (*types.DataKey, error)
+
+	// GetDataKeys returns the data keys of all tenants
+	GetDataKeys(ctx context.Context) ([]*types.DataKey, error)
+
+	// CreateDataKey stores the data key unless the tenant has one already, and returns the
+	// tenant's stored key either way
+	CreateDataKey(ctx context.Context, key *types.DataKey) (*types.DataKey, error)
+
+	// DeleteDataKey deletes the data key of the tenant, if it has one
+	DeleteDataKey(ctx context.Context, tenantID string) error
+}
+
+// Keyring encrypts and decrypts the personal fields of card data with the data key of the tenant
+// of the entry, creating the key on first use. Unwrapped keys are kept in memory, so the master
+// key is used once per tenant and process.
+type Keyring struct {
+	master MasterKey
+	store  DataKeyStore
+
+	mu   sync.Mutex
+	keys map[string]*dataKey
+}
+
+// dataKey is an unwrapped data key
+type dataKey struct {
+	gcm      cipher.AEAD
+	indexKey []byte // HMAC key of the ID number index, derived from the data key
+}
+
+func NewKeyring(master MasterKey, store DataKeyStore) *Keyring {
+	return &Keyring{
+		master: master,
+		store:  store,
+		keys:   make(map[string]*dataKey),
+	}
+}
+
+// EncryptCardData encrypts the name, ID number, date of birth and photo of card with the data key
+// of the tenant and sets the index its ID number can be found by. Fields encrypted already are
+// kept as they are.
+func (k *Keyring) EncryptCardData(ctx context.Context, tenantID string, card *types.CardData) error {
+	if !hasPersonalFields(card) {
+		return nil
+	}
+	key, err := k.dataKey(ctx, tenantID, true)
+	if err != nil {
+		return err
+	}
+
+	if card.IDNumber != "" && !strings.HasPrefix(card.IDNumber, encryptedPrefix) {
+		card.IDNumberIndex = key.index(card.IDNumber)
+	}
+	for name, field := range personalFields(card) {
+		if *field == "" || strings.HasPrefix(*field, encryptedPrefix) {
+			continue
+		}
+		sealed, err := seal(key.gcm, []byte(*field), additionalData(tenantID, name))
+		if err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", name, err)
+		}
+		*field = encryptedPrefix + base64.StdEncoding.EncodeToString(sealed)
+	}
+	return nil
+}
+
+// DecryptCardData decrypts the encrypted fields of card with the data key of the tenant; fields
+// stored before encryption was enabled are kept as they are
+func (k *Keyring) DecryptCardData(ctx context.Context, tenantID string, card *types.CardData) error {
+	var key *dataKey
+	for name, field := range personalFields(card) {
+		encoded, found := strings.CutPrefix(*field, encryptedPrefix)
+		if !found {
+			continue
+		}
+		if key == nil {
+			var err error
+			if key, err = k.dataKey(ctx, tenantID, false); err != nil {
+				return err
+			}
+			if key == nil {
+				return fmt.Errorf("no data key for tenant %q", tenantID)
+			}
+		}
+		sealed, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("invalid encrypted %s: %w", name, err)
+		}
+		plain, err := open(key.gcm, sealed, additionalData(tenantID, name))
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", name, err)
+		}
+		*field = string(plain)
+	}
+	card.IDNumberIndex = ""
+	return nil
+}
+
+// IDNumberIndexes returns the indexes the cards with idNumber are stored with: for the tenant, or
+// for each tenant with a data key when tenantID is empty
+func (k *Keyring) IDNumberIndexes(ctx context.Context, tenantID, idNumber string) ([]string, error) {
+	if idNumber == "" {
+		return nil, nil
+	}
+	tenants := []string{tenantID}
+	if tenantID == "" {
+		stored, err := k.store.GetDataKeys(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get data keys: %w", err)
+		}
+		tenants = tenants[:0]
+		for _, key := range stored {
+			tenants = append(tenants, key.TenantID)
+		}
+	}
+
+	var indexes []string
+	for _, tenant := range tenants {
+		key, err := k.dataKey(ctx, tenant, false)
+		if err != nil {
+			return nil, err
+		}
+		if key != nil {
+			indexes = append(indexes, key.index(idNumber))
+		}
+	}
+	return indexes, nil
+}
+
+// DeleteDataKey deletes the data key of the tenant from the store and from memory. Card data
+// encrypted with it can't be decrypted anymore, also from backups of the database; other replicas
+// forget the key when they restart.
+func (k *Keyring) DeleteDataKey(ctx context.Context, tenantID string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.store.DeleteDataKey(ctx, tenantID); err != nil {
+		return fmt.Errorf("failed to delete data key of tenant %q: %w", tenantID, err)
+	}
+	delete(k.keys, tenantID)
+	return nil
+}
+
+// dataKey returns the unwrapped data key of the tenant; nil when it has none and create is false
+func (k *Keyring) dataKey(ctx context.Context, tenantID string, create bool) (*dataKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if key, found := k.keys[tenantID]; found {
+		return key, nil
+	}
+
+	stored, err := k.store.GetDataKey(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data key of tenant %q: %w", tenantID, err)
+	}
+	if stored == nil {
+		if !create {
+			return nil, nil
+		}
+		if stored, err = k.createDataKey(ctx, tenantID); err != nil {
+			return nil, err
+		}
+	}
+
+	wrapped, err := base64.StdEncoding.DecodeString(stored.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key of tenant %q: %w", tenantID, err)
+	}
+	raw, err := k.master.Unwrap(ctx, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key of tenant %q with %s: %w", tenantID, stored.MasterKeyID, err)
+	}
+	gcm, err := newGCM(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key of tenant %q: %w", tenantID, err)
+	}
+	mac := hmac.New(sha256.New, raw)
+	mac.Write([]byte("idNumberIndex"))
+	key := &dataKey{gcm: gcm, indexKey: mac.Sum(nil)}
+	k.keys[tenantID] = key
+	return key, nil
+}
+
+// createDataKey generates and stores a data key for the tenant; when another replica stored one
+// first, that one is returned
+func (k *Keyring) createDataKey(ctx context.Context, tenantID string) (*types.DataKey, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	wrapped, err := k.master.Wrap(ctx, raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key of tenant %q: %w", tenantID, err)
+	}
+	stored, err := k.store.CreateDataKey(ctx, &types.DataKey{
+		TenantID:    tenantID,
+		WrappedKey:  base64.StdEncoding.EncodeToString(wrapped),
+		MasterKeyID: k.master.ID(),
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store data key of tenant %q: %w", tenantID, err)
+	}
+	return stored, nil
+}
+
+// index returns the keyed hash of an ID number
+func (k *dataKey) index(idNumber string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(idNumber))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// personalFields returns the fields of card that are encrypted, by their JSON name
+func personalFields(card *types.CardData) map[string]*string {
+	return map[string]*string{
+		"idNumber":    &card.IDNumber,
+		"firstName":   &card.FirstName,
+		"lastName":    &card.LastName,
+		"dateOfBirth": &card.DateOfBirth,
+		"photo":       &card.Photo,
+	}
+}
+
+func hasPersonalFields(card *types.CardData) bool {
+	return card.IDNumber != "" || card.FirstName != "" || card.LastName != "" || card.DateOfBirth != "" || card.Photo != ""
+}
+
+// additionalData binds a value to the tenant and field it was encrypted for, so it can't be
+// copied to another entry or field
+func additionalData(tenantID, field string) []byte {
+	return []byte(tenantID + "|" + field)
+}
@@ -0,0 +1,211 @@
+package encryption
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+type memoryStore struct {
+	mu   sync.Mutex
+	keys map[string]*types.DataKey
+}
+
+func (s *memoryStore) GetDataKey(ctx context.Context, tenantID string) (*types.DataKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keys[tenantID], nil
+}
+
+func (s *memoryStore) GetDataKeys(ctx context.Context) ([]*types.DataKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []*types.DataKey
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (s *memoryStore) CreateDataKey(ctx context.Context, key *types.DataKey) (*types.DataKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, found := s.keys[key.TenantID]; found {
+		return existing, nil
+	}
+	s.keys[key.TenantID] = key
+	return key, nil
+}
+
+func (s *memoryStore) DeleteDataKey(ctx context.Context, tenantID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, tenantID)
+	return nil
+}
+
+func newTestKeyring(t *testing.T) (*Keyring, *memoryStore) {
+	t.Helper()
+	master, err := NewLocalMasterKey(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &memoryStore{keys: map[string]*types.DataKey{}}
+	return NewKeyring(master, store), store
+}
+
+func TestKeyringRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	keyring, store := newTestKeyring(t)
+	original := types.CardData{IDNumber: "AB123456", FirstName: "Jana", LastName: "Nováková", DateOfBirth: "1990-01-02", Photo: "aGVsbG8=", Nationality: "SK"}
+
+	card := original
+	if err := keyring.EncryptCardData(ctx, "hospital-a", &card); err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range map[string]string{"idNumber": card.IDNumber, "firstName": card.FirstName, "lastName": card.LastName, "dateOfBirth": card.DateOfBirth, "photo": card.Photo} {
+		if !strings.HasPrefix(value, encryptedPrefix) {
+			t.Errorf("Expected %s to be encrypted, got %q", name, value)
+		}
+	}
+	if card.Nationality != "SK" {
+		t.Errorf("Expected the nationality to stay in plain, got %q", card.Nationality)
+	}
+	if card.IDNumberIndex == "" {
+		t.Error("Expected the ID number index to be set")
+	}
+	if store.keys["hospital-a"] == nil || store.keys["hospital-a"].WrappedKey == "" {
+		t.Fatal("Expected a wrapped data key to be stored for the tenant")
+	}
+
+	// Encrypting again keeps the values
+	encrypted := card
+	if err := keyring.EncryptCardData(ctx, "hospital-a", &encrypted); err != nil {
+		t.Fatal(err)
+	}
+	if encrypted != card {
+		t.Error("Expected encrypted fields not to be encrypted twice")
+	}
+
+	// A new keyring, as after a restart, unwraps the stored key
+	master, _ := NewLocalMasterKey(make([]byte, 32))
+	restarted := NewKeyring(master, store)
+	if err := restarted.DecryptCardData(ctx, "hospital-a", &card); err != nil {
+		t.Fatal(err)
+	}
+	if card != original {
+		t.Errorf("Expected %+v, got %+v", original, card)
+	}
+}
+
+func TestKeyringRejectsOtherTenant(t *testing.T) {
+	ctx := context.Background()
+	keyring, _ := newTestKeyring(t)
+
+	card := types.CardData{FirstName: "Jana"}
+	if err := keyring.EncryptCardData(ctx, "hospital-a", &card); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.DecryptCardData(ctx, "hospital-b", &card); err == nil {
+		t.Error("Expected card data of another tenant not to decrypt")
+	}
+
+	// Even with the tenant's key the value is bound to its tenant
+	other := types.CardData{FirstName: "Peter"}
+	if err := keyring.EncryptCardData(ctx, "hospital-b", &other); err != nil {
+		t.Fatal(err)
+	}
+	copied := types.CardData{FirstName: card.FirstName}
+	if err := keyring.DecryptCardData(ctx, "hospital-b", &copied); err == nil {
+		t.Error("Expected a value copied from another tenant not to decrypt")
+	}
+}
+
+func TestKeyringDeleteDataKey(t *testing.T) {
+	ctx := context.Background()
+	keyring, store := newTestKeyring(t)
+
+	card := types.CardData{FirstName: "Jana"}
+	if err := keyring.EncryptCardData(ctx, "hospital-a", &card); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.DeleteDataKey(ctx, "hospital-a"); err != nil {
+		t.Fatal(err)
+	}
+	if store.keys["hospital-a"] != nil {
+		t.Error("Expected the data key to be deleted from the store")
+	}
+
+	// The key is forgotten in memory too, so the card data is gone
+	encrypted := card
+	if err := keyring.DecryptCardData(ctx, "hospital-a", &encrypted); err == nil {
+		t.Error("Expected card data not to decrypt without the data key")
+	}
+}
+
+func TestKeyringKeepsPlainData(t *testing.T) {
+	keyring, store := newTestKeyring(t)
+
+	card := types.CardData{IDNumber: "AB123456", FirstName: "Jana"}
+	if err := keyring.DecryptCardData(context.Background(), "hospital-a", &card); err != nil {
+		t.Fatal(err)
+	}
+	if card.IDNumber != "AB123456" || card.FirstName != "Jana" {
+		t.Errorf("Expected card data stored in plain to be kept, got %+v", card)
+	}
+	if len(store.keys) != 0 {
+		t.Error("Expected no data key to be created for reading")
+	}
+
+	empty := types.CardData{Nationality: "SK"}
+	if err := keyring.EncryptCardData(context.Background(), "hospital-a", &empty); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.keys) != 0 {
+		t.Error("Expected no data key to be created for a card without personal fields")
+	}
+}
+
+func TestKeyringIDNumberIndexes(t *testing.T) {
+	ctx := context.Background()
+	keyring, _ := newTestKeyring(t)
+
+	a := types.CardData{IDNumber: "AB123456"}
+	b := types.CardData{IDNumber: "AB123456"}
+	again := types.CardData{IDNumber: "AB123456"}
+	for _, encrypt := range []struct {
+		tenant string
+		card   *types.CardData
+	}{{"hospital-a", &a}, {"hospital-b", &b}, {"hospital-a", &again}} {
+		if err := keyring.EncryptCardData(ctx, encrypt.tenant, encrypt.card); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a.IDNumberIndex != again.IDNumberIndex {
+		t.Error("Expected the index of an ID number to be stable within a tenant")
+	}
+	if a.IDNumberIndex == b.IDNumberIndex {
+		t.Error("Expected the index of an ID number to differ between tenants")
+	}
+
+	indexes, err := keyring.IDNumberIndexes(ctx, "hospital-a", "AB123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(indexes) != 1 || indexes[0] != a.IDNumberIndex {
+		t.Errorf("Expected the index of the tenant, got %v", indexes)
+	}
+	indexes, err = keyring.IDNumberIndexes(ctx, "", "AB123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(indexes) != 2 {
+		t.Errorf("Expected the indexes of both tenants, got %v", indexes)
+	}
+	if indexes, _ := keyring.IDNumberIndexes(ctx, "hospital-c", "AB123456"); len(indexes) != 0 {
+		t.Errorf("Expected no indexes for a tenant without a data key, got %v", indexes)
+	}
+}
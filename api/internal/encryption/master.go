@@ -0,0 +1,175 @@
+// Package encryption encrypts the personal fields of card data at rest: with a data key per
+// tenant (envelope encryption), which is stored wrapped with a master key kept out of the
+// database, in a local key file or a KMS.
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// MasterKey wraps and unwraps the data keys of the tenants
+type MasterKey interface {
+	// ID identifies the master key the data keys are wrapped with
+	ID() string
+	Wrap(ctx context.Context, dataKey []byte) ([]byte, error)
+	Unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// LocalMasterKey is an AES-256 master key read from a file
+type LocalMasterKey struct {
+	id  string
+	gcm cipher.AEAD
+}
+
+// LoadLocalMasterKey reads the base64 encoded 32 byte key in the file at path, e.g. one created
+// with `openssl rand -base64 32`
+func LoadLocalMasterKey(path string) (*LocalMasterKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read master key file: %w", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("master key file %s must hold a base64 encoded 32 byte key", path)
+	}
+	return NewLocalMasterKey(key)
+}
+
+// NewLocalMasterKey creates a master key from 32 key bytes
+func NewLocalMasterKey(key []byte) (*LocalMasterKey, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(key)
+	return &LocalMasterKey{id: "local:" + hex.EncodeToString(sum[:8]), gcm: gcm}, nil
+}
+
+func (k *LocalMasterKey) ID() string {
+	return k.id
+}
+
+func (k *LocalMasterKey) Wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	return seal(k.gcm, dataKey, []byte(k.id))
+}
+
+func (k *LocalMasterKey) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	return open(k.gcm, wrapped, []byte(k.id))
+}
+
+// TransitMasterKey is a master key held by a KMS with the API of the Vault transit secrets engine
+// (POST /v1/transit/encrypt/<key> and /v1/transit/decrypt/<key>); it never leaves the KMS
+type TransitMasterKey struct {
+	url     string
+	token   string
+	keyName string
+	client  *http.Client
+}
+
+// NewTransitMasterKey creates the master key keyName of the KMS at url, authenticated with token
+func NewTransitMasterKey(url, token, keyName string) *TransitMasterKey {
+	return &TransitMasterKey{
+		url:     strings.TrimSuffix(url, "/"),
+		token:   token,
+		keyName: keyName,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (k *TransitMasterKey) ID() string {
+	return "transit:" + k.keyName
+}
+
+func (k *TransitMasterKey) Wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	var response struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	if err := k.call(ctx, "encrypt", map[string]string{"plaintext": base64.StdEncoding.EncodeToString(dataKey)}, &response); err != nil {
+		return nil, err
+	}
+	if response.Data.Ciphertext == "" {
+		return nil, fmt.Errorf("KMS returned no ciphertext")
+	}
+	return []byte(response.Data.Ciphertext), nil
+}
+
+func (k *TransitMasterKey) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var response struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	if err := k.call(ctx, "decrypt", map[string]string{"ciphertext": string(wrapped)}, &response); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(response.Data.Plaintext)
+}
+
+func (k *TransitMasterKey) call(ctx context.Context, operation string, body map[string]string, response any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.url+"/v1/transit/"+operation+"/"+k.keyName, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", k.token)
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("KMS %s failed: %w", operation, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("KMS %s failed with status %d: %s", operation, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return fmt.Errorf("failed to decode KMS %s response: %w", operation, err)
+	}
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plain with gcm and a random nonce, which it puts in front of the ciphertext
+func seal(gcm cipher.AEAD, plain, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, additionalData), nil
+}
+
+// open decrypts what seal returned
+func open(gcm cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, additionalData)
+}
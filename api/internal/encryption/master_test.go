@@ -0,0 +1,102 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalMasterKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "master.key")
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	master, err := LoadLocalMasterKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(master.ID(), "local:") {
+		t.Errorf("Expected a local key ID, got %q", master.ID())
+	}
+
+	dataKey := bytes.Repeat([]byte{1}, 32)
+	wrapped, err := master.Wrap(context.Background(), dataKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(wrapped, dataKey) {
+		t.Error("Expected the wrapped key not to contain the data key")
+	}
+	unwrapped, err := master.Unwrap(context.Background(), wrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(unwrapped, dataKey) {
+		t.Error("Expected the data key back")
+	}
+
+	other, _ := NewLocalMasterKey(bytes.Repeat([]byte{8}, 32))
+	if _, err := other.Unwrap(context.Background(), wrapped); err == nil {
+		t.Error("Expected another master key not to unwrap the data key")
+	}
+
+	if err := os.WriteFile(path, []byte("too short"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLocalMasterKey(path); err == nil {
+		t.Error("Expected a key file without 32 bytes to be rejected")
+	}
+}
+
+func TestTransitMasterKey(t *testing.T) {
+	// The fake KMS "encrypts" by prefixing the plaintext
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "vault-token" {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/waiting-room":
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]string{"ciphertext": "vault:v1:" + body["plaintext"]}})
+		case "/v1/transit/decrypt/waiting-room":
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]string{"plaintext": strings.TrimPrefix(body["ciphertext"], "vault:v1:")}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	master := NewTransitMasterKey(server.URL+"/", "vault-token", "waiting-room")
+	if master.ID() != "transit:waiting-room" {
+		t.Errorf("Expected transit:waiting-room, got %q", master.ID())
+	}
+	dataKey := []byte("0123456789abcdef0123456789abcdef")
+	wrapped, err := master.Wrap(context.Background(), dataKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(wrapped), "vault:v1:") {
+		t.Errorf("Expected the ciphertext of the KMS, got %q", wrapped)
+	}
+	unwrapped, err := master.Unwrap(context.Background(), wrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(unwrapped, dataKey) {
+		t.Error("Expected the data key back")
+	}
+
+	denied := NewTransitMasterKey(server.URL, "wrong", "waiting-room")
+	if _, err := denied.Wrap(context.Background(), dataKey); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Expected the status of the KMS in the error, got %v", err)
+	}
+}
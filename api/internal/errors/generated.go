@@ -6,10 +6,21 @@ import (
 )
 
 const (
-	CardReadFailedCode     = "CARD_READ_FAILED"
-	InvalidRoomIdCode      = "INVALID_ROOM_ID"
-	QueueEmptyCode         = "QUEUE_EMPTY"
-	QueueEntryNotFoundCode = "QUEUE_ENTRY_NOT_FOUND"
+	CardReadFailedCode             = "CARD_READ_FAILED"
+	CardReaderOtherTenantCode      = "CARD_READER_OTHER_TENANT"
+	CardReaderUnauthorizedCode     = "CARD_READER_UNAUTHORIZED"
+	IntegrationUnauthorizedCode    = "INTEGRATION_UNAUTHORIZED"
+	InvalidConfigurationBundleCode = "INVALID_CONFIGURATION_BUNDLE"
+	InvalidRoomIdCode              = "INVALID_ROOM_ID"
+	QueueEmptyCode                 = "QUEUE_EMPTY"
+	QueueEntryConflictCode         = "QUEUE_ENTRY_CONFLICT"
+	QueueEntryNotFoundCode         = "QUEUE_ENTRY_NOT_FOUND"
+	QueueFullCode                  = "QUEUE_FULL"
+	QueuePausedCode                = "QUEUE_PAUSED"
+	RateLimitedCode                = "RATE_LIMITED"
+	TenantDeactivatedCode          = "TENANT_DEACTIVATED"
+	UnauthorizedCode               = "UNAUTHORIZED"
+	UnknownTenantCode              = "UNKNOWN_TENANT"
 )
 
 // CardReadFailed - When card reading fails.
@@ -17,6 +28,26 @@ func CardReadFailed() *ApplicationError {
 	return New(CardReadFailedCode, "Failed to read card data", 400, nil)
 }
 
+// CardReaderOtherTenant - When a card reader is configured, or sends an event, for a tenant other than the one it is registered to.
+func CardReaderOtherTenant(params ...any) *ApplicationError {
+	return New(CardReaderOtherTenantCode, fmt.Sprintf("Card reader is registered to another tenant: %s", params...), 409, nil)
+}
+
+// CardReaderUnauthorized - When a card reader connects without a registered device credential.
+func CardReaderUnauthorized(params ...any) *ApplicationError {
+	return New(CardReaderUnauthorizedCode, fmt.Sprintf("Card reader credential rejected: %s", params...), 401, nil)
+}
+
+// IntegrationUnauthorized - When an external system pushes an event without a valid API key and request signature.
+func IntegrationUnauthorized(params ...any) *ApplicationError {
+	return New(IntegrationUnauthorizedCode, fmt.Sprintf("Integration request rejected: %s", params...), 401, nil)
+}
+
+// InvalidConfigurationBundle - When an imported configuration bundle is malformed or its signature does not match.
+func InvalidConfigurationBundle(params ...any) *ApplicationError {
+	return New(InvalidConfigurationBundleCode, fmt.Sprintf("Invalid configuration bundle: %s", params...), 400, nil)
+}
+
 // InvalidRoomId - When room ID is invalid or doesn't exist.
 func InvalidRoomId(params ...any) *ApplicationError {
 	return New(InvalidRoomIdCode, fmt.Sprintf("Invalid room ID: %s", params...), 400, nil)
@@ -27,7 +58,42 @@ func QueueEmpty() *ApplicationError {
 	return New(QueueEmptyCode, "Queue is empty", 400, nil)
 }
 
+// QueueEntryConflict - When a queue entry was changed by another operator since it was read.
+func QueueEntryConflict(params ...any) *ApplicationError {
+	return New(QueueEntryConflictCode, fmt.Sprintf("Queue entry was changed concurrently: %s", params...), 409, nil)
+}
+
 // QueueEntryNotFound - When trying to find a queue entry that doesn't exist.
 func QueueEntryNotFound(params ...any) *ApplicationError {
 	return New(QueueEntryNotFoundCode, fmt.Sprintf("Queue entry not found: %s", params...), 404, nil)
 }
+
+// QueueFull - When a room already holds as many waiting entries as it takes. The values carry the suggested overflow room, if any.
+func QueueFull(params ...any) *ApplicationError {
+	return New(QueueFullCode, fmt.Sprintf("Queue is full: %s", params...), 409, nil)
+}
+
+// QueuePaused - When a paused room or service point is asked to take or call patients.
+func QueuePaused(params ...any) *ApplicationError {
+	return New(QueuePausedCode, fmt.Sprintf("Queue is paused: %s", params...), 409, nil)
+}
+
+// RateLimited - When a client used up the request budget of the endpoint class; Retry-After says when to try again.
+func RateLimited(params ...any) *ApplicationError {
+	return New(RateLimitedCode, fmt.Sprintf("Too many requests: %s", params...), 429, nil)
+}
+
+// TenantDeactivated - When a card is swiped or a WebSocket subscribes for a tenant that was deactivated.
+func TenantDeactivated(params ...any) *ApplicationError {
+	return New(TenantDeactivatedCode, fmt.Sprintf("Tenant deactivated: %s", params...), 403, nil)
+}
+
+// Unauthorized - When a request of an admin, staff, kiosk or display client carries no valid bearer token.
+func Unauthorized(params ...any) *ApplicationError {
+	return New(UnauthorizedCode, fmt.Sprintf("Authentication required: %s", params...), 401, nil)
+}
+
+// UnknownTenant - When X-Tenant-ID names a building and section that is not in the tenant registry.
+func UnknownTenant(params ...any) *ApplicationError {
+	return New(UnknownTenantCode, fmt.Sprintf("Unknown tenant: %s", params...), 403, nil)
+}
@@ -0,0 +1,290 @@
+package fhir
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	"github.com/arfis/waiting-room/internal/data/dto/appointmentstatus"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// Resource types the server supports
+const (
+	EncounterType   = "Encounter"
+	AppointmentType = "Appointment"
+)
+
+// encounterStatuses maps the status of a queue entry to the status of its encounter
+var encounterStatuses = map[string]string{
+	"WAITING":    "arrived",
+	"CALLED":     "arrived",
+	"IN_ROOM":    "in-progress",
+	"IN_SERVICE": "in-progress",
+	"COMPLETED":  "finished",
+	"SKIPPED":    "cancelled",
+	"CANCELLED":  "cancelled",
+	"NO_SHOW":    "cancelled",
+}
+
+// entryAppointmentStatuses maps the status of the queue entry of a checked-in appointment to the
+// status of the appointment
+var entryAppointmentStatuses = map[string]string{
+	"WAITING":    "checked-in",
+	"CALLED":     "checked-in",
+	"IN_ROOM":    "arrived",
+	"IN_SERVICE": "arrived",
+	"COMPLETED":  "fulfilled",
+	"SKIPPED":    "noshow",
+	"CANCELLED":  "cancelled",
+	"NO_SHOW":    "noshow",
+}
+
+// EncounterStatus returns the FHIR status of the encounter of a queue entry
+func EncounterStatus(entryStatus string) string {
+	if status, ok := encounterStatuses[entryStatus]; ok {
+		return status
+	}
+	return "unknown"
+}
+
+// EntryStatuses returns the statuses of the queue entries whose encounters have a FHIR status;
+// false when no entry has it
+func EntryStatuses(encounterStatus string) ([]string, bool) {
+	var statuses []string
+	for entryStatus, status := range encounterStatuses {
+		if status == encounterStatus {
+			statuses = append(statuses, entryStatus)
+		}
+	}
+	sort.Strings(statuses)
+	return statuses, len(statuses) > 0
+}
+
+// AppointmentStatus returns the FHIR status of an appointment; a checked-in appointment follows the
+// status of its queue entry when it is known
+func AppointmentStatus(appointmentStatus, entryStatus string) string {
+	switch appointmentStatus {
+	case "CANCELLED":
+		return "cancelled"
+	case "CHECKED_IN":
+		if status, ok := entryAppointmentStatuses[entryStatus]; ok {
+			return status
+		}
+		return "checked-in"
+	}
+	return "booked"
+}
+
+// NewEncounter returns the encounter of a queue entry
+func NewEncounter(entry *types.Entry, settings *types.FHIRSettings) *Encounter {
+	updatedAt := entry.UpdatedAt
+	encounter := &Encounter{
+		ResourceType: EncounterType,
+		ID:           entry.ID,
+		Meta:         meta(EncounterType, &updatedAt, settings),
+		Identifier:   []Identifier{{System: TicketSystem, Value: entry.TicketNumber}},
+		Status:       EncounterStatus(entry.Status),
+		Class:        Coding{System: ActCodeSystem, Code: "AMB", Display: "ambulatory"},
+		Subject:      patient(entry.CardData.IDNumber, strings.TrimSpace(entry.CardData.FirstName+" "+entry.CardData.LastName), settings),
+		Period:       &Period{Start: &entry.CreatedAt, End: entry.CompletedAt},
+	}
+	if entry.ServiceName != "" {
+		encounter.ServiceType = &CodeableConcept{Text: entry.ServiceName}
+	}
+	if entry.WaitingRoomID != "" {
+		locationStatus := "active"
+		if encounter.Status == "finished" || encounter.Status == "cancelled" {
+			locationStatus = "completed"
+		}
+		encounter.Location = []EncounterLocation{{
+			Location: Reference{Reference: "Location/" + entry.WaitingRoomID},
+			Status:   locationStatus,
+		}}
+	}
+	return encounter
+}
+
+// NewAppointment returns the FHIR appointment of an appointment, with the status of its queue entry
+// when it was checked in (empty when unknown)
+func NewAppointment(appointment *types.Appointment, entryStatus string, settings *types.FHIRSettings) *Appointment {
+	updatedAt := appointment.UpdatedAt
+	appointmentTime := appointment.AppointmentTime
+	resource := &Appointment{
+		ResourceType:    AppointmentType,
+		ID:              appointment.ExternalID,
+		Meta:            meta(AppointmentType, &updatedAt, settings),
+		Identifier:      []Identifier{{System: AppointmentSystem, Value: appointment.ExternalID}},
+		Status:          AppointmentStatus(appointment.Status, entryStatus),
+		Start:           &appointmentTime,
+		MinutesDuration: appointment.ServiceDurationSeconds / 60,
+		Participant: []AppointmentParticipant{{
+			Actor:  patient(appointment.Identifier, appointment.PatientName, settings),
+			Status: "accepted",
+		}},
+	}
+	if appointment.ServiceID != "" || appointment.ServiceName != "" {
+		serviceType := CodeableConcept{Text: appointment.ServiceName}
+		if appointment.ServiceID != "" {
+			serviceType.Coding = []Coding{{Code: appointment.ServiceID, Display: appointment.ServiceName}}
+		}
+		resource.ServiceType = []CodeableConcept{serviceType}
+	}
+	if appointment.RoomID != "" {
+		resource.Participant = append(resource.Participant, AppointmentParticipant{
+			Actor:  &Reference{Reference: "Location/" + appointment.RoomID},
+			Status: "accepted",
+		})
+	}
+	return resource
+}
+
+// ParseAppointment returns the appointment a FHIR appointment pre-registers: its identifier (or id)
+// is the external ID, the patient participant gives the card identifier and name, a Location
+// participant the room and the first service type the service
+func ParseAppointment(resource *Appointment, settings *types.FHIRSettings) (*dto.Appointment, error) {
+	if resource.ResourceType != AppointmentType {
+		return nil, errors.New("resourceType must be Appointment")
+	}
+	externalID := resource.ID
+	if len(resource.Identifier) > 0 && resource.Identifier[0].Value != "" {
+		externalID = resource.Identifier[0].Value
+	}
+	if externalID == "" {
+		return nil, errors.New("appointment needs an identifier or id")
+	}
+	if resource.Start == nil {
+		return nil, errors.New("appointment needs a start")
+	}
+	if resource.MinutesDuration < 0 {
+		return nil, errors.New("minutesDuration must not be negative")
+	}
+
+	item := &dto.Appointment{
+		AppointmentTime: *resource.Start,
+		ExternalId:      externalID,
+	}
+	for _, participant := range resource.Participant {
+		actor := participant.Actor
+		if actor == nil {
+			continue
+		}
+		if roomID, ok := strings.CutPrefix(actor.Reference, "Location/"); ok {
+			item.RoomId = &roomID
+			continue
+		}
+		if item.Identifier != "" || (actor.Reference != "" && !strings.HasPrefix(actor.Reference, "Patient/")) {
+			continue
+		}
+		switch {
+		case actor.Identifier != nil && actor.Identifier.Value != "" &&
+			(actor.Identifier.System == "" || actor.Identifier.System == identifierSystem(settings)):
+			item.Identifier = actor.Identifier.Value
+		case actor.Reference != "":
+			item.Identifier = strings.TrimPrefix(actor.Reference, "Patient/")
+		default:
+			continue
+		}
+		if actor.Display != "" {
+			display := actor.Display
+			item.PatientName = &display
+		}
+	}
+	if item.Identifier == "" {
+		return nil, errors.New("appointment needs a patient participant with an identifier")
+	}
+
+	if len(resource.ServiceType) > 0 {
+		serviceType := resource.ServiceType[0]
+		serviceName := serviceType.Text
+		if len(serviceType.Coding) > 0 {
+			serviceID := serviceType.Coding[0].Code
+			item.ServiceId = &serviceID
+			if serviceName == "" {
+				serviceName = serviceType.Coding[0].Display
+			}
+		}
+		if serviceName != "" {
+			item.ServiceName = &serviceName
+		}
+	}
+	if resource.MinutesDuration > 0 {
+		duration := resource.MinutesDuration
+		item.ServiceDuration = &duration
+	}
+
+	status := appointmentstatus.SCHEDULED
+	if resource.Status == "cancelled" {
+		status = appointmentstatus.CANCELLED
+	}
+	item.Status = &status
+	return item, nil
+}
+
+// NewCapabilityStatement returns the capability statement of the server for a tenant
+func NewCapabilityStatement(settings *types.FHIRSettings, date time.Time) *CapabilityStatement {
+	return &CapabilityStatement{
+		ResourceType: "CapabilityStatement",
+		Status:       "active",
+		Date:         date,
+		Kind:         "instance",
+		Software:     &Software{Name: "Waiting Room"},
+		FhirVersion:  Version,
+		Format:       []string{"json"},
+		Rest: []CapabilityRest{{
+			Mode: "server",
+			Resource: []CapabilityResource{
+				{
+					Type:             EncounterType,
+					SupportedProfile: profiles(EncounterType, settings),
+					Interaction:      []CapabilityCode{{Code: "read"}, {Code: "search-type"}},
+					SearchParam: []CapabilitySearchParam{
+						{Name: "location", Type: "reference"},
+						{Name: "status", Type: "token"},
+					},
+				},
+				{
+					Type:             AppointmentType,
+					SupportedProfile: profiles(AppointmentType, settings),
+					Interaction:      []CapabilityCode{{Code: "read"}, {Code: "search-type"}, {Code: "create"}, {Code: "update"}},
+					SearchParam: []CapabilitySearchParam{
+						{Name: "date", Type: "date"},
+					},
+				},
+			},
+		}},
+	}
+}
+
+// patient returns the reference to the patient with an identifier, nil without one (e.g. after
+// the retention policy purged the card data)
+func patient(identifier, name string, settings *types.FHIRSettings) *Reference {
+	if identifier == "" {
+		return nil
+	}
+	return &Reference{
+		Identifier: &Identifier{System: identifierSystem(settings), Value: identifier},
+		Display:    name,
+	}
+}
+
+func meta(resourceType string, lastUpdated *time.Time, settings *types.FHIRSettings) *Meta {
+	return &Meta{LastUpdated: lastUpdated, Profile: profiles(resourceType, settings)}
+}
+
+// profiles returns the profile a tenant configured for a resource type
+func profiles(resourceType string, settings *types.FHIRSettings) []string {
+	if settings == nil || settings.Profiles[resourceType] == "" {
+		return nil
+	}
+	return []string{settings.Profiles[resourceType]}
+}
+
+func identifierSystem(settings *types.FHIRSettings) string {
+	if settings == nil || settings.IdentifierSystem == "" {
+		return types.DefaultFHIRIdentifierSystem
+	}
+	return settings.IdentifierSystem
+}
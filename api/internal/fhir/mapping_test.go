@@ -0,0 +1,179 @@
+package fhir
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+func TestEncounterStatus(t *testing.T) {
+	tests := map[string]string{
+		"WAITING":    "arrived",
+		"CALLED":     "arrived",
+		"IN_ROOM":    "in-progress",
+		"IN_SERVICE": "in-progress",
+		"COMPLETED":  "finished",
+		"CANCELLED":  "cancelled",
+		"NO_SHOW":    "cancelled",
+		"SOMETHING":  "unknown",
+	}
+	for entryStatus, expected := range tests {
+		if got := EncounterStatus(entryStatus); got != expected {
+			t.Errorf("EncounterStatus(%s) = %s, expected %s", entryStatus, got, expected)
+		}
+	}
+}
+
+func TestEntryStatuses(t *testing.T) {
+	statuses, ok := EntryStatuses("in-progress")
+	if !ok || !reflect.DeepEqual(statuses, []string{"IN_ROOM", "IN_SERVICE"}) {
+		t.Errorf("Expected IN_ROOM and IN_SERVICE, got %v", statuses)
+	}
+	if _, ok := EntryStatuses("planned"); ok {
+		t.Error("Expected no entry statuses for planned")
+	}
+}
+
+func TestAppointmentStatus(t *testing.T) {
+	tests := []struct {
+		appointmentStatus string
+		entryStatus       string
+		expected          string
+	}{
+		{"SCHEDULED", "", "booked"},
+		{"CANCELLED", "", "cancelled"},
+		{"CHECKED_IN", "", "checked-in"},
+		{"CHECKED_IN", "WAITING", "checked-in"},
+		{"CHECKED_IN", "IN_SERVICE", "arrived"},
+		{"CHECKED_IN", "COMPLETED", "fulfilled"},
+		{"CHECKED_IN", "NO_SHOW", "noshow"},
+	}
+	for _, test := range tests {
+		if got := AppointmentStatus(test.appointmentStatus, test.entryStatus); got != test.expected {
+			t.Errorf("AppointmentStatus(%s, %s) = %s, expected %s", test.appointmentStatus, test.entryStatus, got, test.expected)
+		}
+	}
+}
+
+func TestNewEncounter(t *testing.T) {
+	settings := &types.FHIRSettings{
+		Profiles:         map[string]string{EncounterType: "http://example.org/StructureDefinition/queue-encounter"},
+		IdentifierSystem: "urn:oid:1.2.3",
+	}
+	entry := &types.Entry{
+		ID:            "entry-1",
+		WaitingRoomID: "triage-1",
+		TicketNumber:  "A-001",
+		Status:        "COMPLETED",
+		ServiceName:   "Blood test",
+		CardData:      types.CardData{IDNumber: "123", FirstName: "Jana", LastName: "Novak"},
+	}
+
+	encounter := NewEncounter(entry, settings)
+	if encounter.Status != "finished" {
+		t.Errorf("Expected status finished, got %s", encounter.Status)
+	}
+	if !reflect.DeepEqual(encounter.Meta.Profile, []string{settings.Profiles[EncounterType]}) {
+		t.Errorf("Expected the configured profile, got %v", encounter.Meta.Profile)
+	}
+	if encounter.Subject == nil || encounter.Subject.Identifier.System != "urn:oid:1.2.3" || encounter.Subject.Identifier.Value != "123" || encounter.Subject.Display != "Jana Novak" {
+		t.Errorf("Unexpected subject %+v", encounter.Subject)
+	}
+	if len(encounter.Location) != 1 || encounter.Location[0].Location.Reference != "Location/triage-1" || encounter.Location[0].Status != "completed" {
+		t.Errorf("Unexpected location %+v", encounter.Location)
+	}
+
+	// Purged card data leaves no subject, and tenants without settings no profile
+	entry.CardData = types.CardData{}
+	encounter = NewEncounter(entry, nil)
+	if encounter.Subject != nil {
+		t.Errorf("Expected no subject, got %+v", encounter.Subject)
+	}
+	if encounter.Meta.Profile != nil {
+		t.Errorf("Expected no profile, got %v", encounter.Meta.Profile)
+	}
+}
+
+func TestParseAppointment(t *testing.T) {
+	body := `{
+		"resourceType": "Appointment",
+		"identifier": [{"system": "urn:his", "value": "HIS-42"}],
+		"status": "booked",
+		"serviceType": [{"coding": [{"code": "xray", "display": "X-ray"}]}],
+		"start": "2026-03-02T09:30:00Z",
+		"minutesDuration": 15,
+		"participant": [
+			{"actor": {"reference": "Practitioner/7", "identifier": {"value": "doctor"}}, "status": "accepted"},
+			{"actor": {"reference": "Patient/p-1", "identifier": {"system": "urn:oid:1.2.3", "value": "8001011234"}, "display": "Jana Novak"}, "status": "accepted"},
+			{"actor": {"reference": "Location/radiology"}, "status": "accepted"}
+		]
+	}`
+	var resource Appointment
+	if err := json.Unmarshal([]byte(body), &resource); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	item, err := ParseAppointment(&resource, &types.FHIRSettings{IdentifierSystem: "urn:oid:1.2.3"})
+	if err != nil {
+		t.Fatalf("ParseAppointment failed: %v", err)
+	}
+	if item.ExternalId != "HIS-42" || item.Identifier != "8001011234" || item.GetPatientName() != "Jana Novak" {
+		t.Errorf("Unexpected appointment %+v", item)
+	}
+	if item.GetRoomId() != "radiology" || item.GetServiceId() != "xray" || item.GetServiceName() != "X-ray" || item.GetServiceDuration() != 15 {
+		t.Errorf("Unexpected room or service %+v", item)
+	}
+	if !item.AppointmentTime.Equal(time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected appointment time %v", item.AppointmentTime)
+	}
+	if item.GetStatus() != "SCHEDULED" {
+		t.Errorf("Expected status SCHEDULED, got %s", item.GetStatus())
+	}
+
+	// An identifier of another system falls back to the patient reference
+	item, err = ParseAppointment(&resource, nil)
+	if err != nil {
+		t.Fatalf("ParseAppointment failed: %v", err)
+	}
+	if item.Identifier != "p-1" {
+		t.Errorf("Expected identifier p-1, got %s", item.Identifier)
+	}
+
+	resource.Participant = resource.Participant[2:]
+	if _, err := ParseAppointment(&resource, nil); err == nil {
+		t.Error("Expected an error for an appointment without patient")
+	}
+	resource.ResourceType = "Encounter"
+	if _, err := ParseAppointment(&resource, nil); err == nil {
+		t.Error("Expected an error for another resource type")
+	}
+}
+
+func TestNewAppointmentRoundTrip(t *testing.T) {
+	appointment := &types.Appointment{
+		ExternalID:             "HIS-42",
+		Identifier:             "8001011234",
+		PatientName:            "Jana Novak",
+		AppointmentTime:        time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC),
+		RoomID:                 "radiology",
+		ServiceID:              "xray",
+		ServiceName:            "X-ray",
+		ServiceDurationSeconds: 900,
+		Status:                 "CHECKED_IN",
+	}
+
+	resource := NewAppointment(appointment, "IN_ROOM", nil)
+	if resource.Status != "arrived" {
+		t.Errorf("Expected status arrived, got %s", resource.Status)
+	}
+	item, err := ParseAppointment(resource, nil)
+	if err != nil {
+		t.Fatalf("ParseAppointment failed: %v", err)
+	}
+	if item.ExternalId != "HIS-42" || item.Identifier != "8001011234" || item.GetRoomId() != "radiology" || item.GetServiceDuration() != 15 {
+		t.Errorf("Unexpected appointment %+v", item)
+	}
+}
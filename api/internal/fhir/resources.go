@@ -0,0 +1,163 @@
+// Package fhir maps queue entries and appointments to FHIR R4 resources and back
+package fhir
+
+import "time"
+
+// Version is the FHIR version of the resources
+const Version = "4.0.1"
+
+// ContentType is the media type of FHIR resources in JSON
+const ContentType = "application/fhir+json"
+
+// Systems of the identifiers and codes the resources use
+const (
+	TicketSystem      = "urn:waiting-room:ticket"
+	AppointmentSystem = "urn:waiting-room:appointment"
+	ActCodeSystem     = "http://terminology.hl7.org/CodeSystem/v3-ActCode"
+)
+
+// Meta is the metadata of a resource
+type Meta struct {
+	LastUpdated *time.Time `json:"lastUpdated,omitempty"`
+	Profile     []string   `json:"profile,omitempty"`
+}
+
+// Identifier identifies a resource in a system
+type Identifier struct {
+	System string `json:"system,omitempty"`
+	Value  string `json:"value,omitempty"`
+}
+
+// Coding is a code of a code system
+type Coding struct {
+	System  string `json:"system,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+// CodeableConcept is a concept given by codes and/or text
+type CodeableConcept struct {
+	Coding []Coding `json:"coding,omitempty"`
+	Text   string   `json:"text,omitempty"`
+}
+
+// Reference points to another resource, by its relative URL or by an identifier
+type Reference struct {
+	Reference  string      `json:"reference,omitempty"`
+	Identifier *Identifier `json:"identifier,omitempty"`
+	Display    string      `json:"display,omitempty"`
+}
+
+// Period is a time range; End is empty while it lasts
+type Period struct {
+	Start *time.Time `json:"start,omitempty"`
+	End   *time.Time `json:"end,omitempty"`
+}
+
+// Encounter is the visit of a patient, here one queue entry
+type Encounter struct {
+	ResourceType string              `json:"resourceType"`
+	ID           string              `json:"id,omitempty"`
+	Meta         *Meta               `json:"meta,omitempty"`
+	Identifier   []Identifier        `json:"identifier,omitempty"`
+	Status       string              `json:"status"`
+	Class        Coding              `json:"class"`
+	ServiceType  *CodeableConcept    `json:"serviceType,omitempty"`
+	Subject      *Reference          `json:"subject,omitempty"`
+	Period       *Period             `json:"period,omitempty"`
+	Location     []EncounterLocation `json:"location,omitempty"`
+}
+
+// EncounterLocation is a location of an encounter
+type EncounterLocation struct {
+	Location Reference `json:"location"`
+	Status   string    `json:"status,omitempty"`
+}
+
+// Appointment is a scheduled visit of a patient
+type Appointment struct {
+	ResourceType    string                   `json:"resourceType"`
+	ID              string                   `json:"id,omitempty"`
+	Meta            *Meta                    `json:"meta,omitempty"`
+	Identifier      []Identifier             `json:"identifier,omitempty"`
+	Status          string                   `json:"status"`
+	ServiceType     []CodeableConcept        `json:"serviceType,omitempty"`
+	Start           *time.Time               `json:"start,omitempty"`
+	MinutesDuration int64                    `json:"minutesDuration,omitempty"`
+	Participant     []AppointmentParticipant `json:"participant"`
+}
+
+// AppointmentParticipant is a patient, practitioner or location taking part in an appointment
+type AppointmentParticipant struct {
+	Actor  *Reference `json:"actor,omitempty"`
+	Status string     `json:"status"`
+}
+
+// Bundle is the result set of a search
+type Bundle struct {
+	ResourceType string        `json:"resourceType"`
+	Type         string        `json:"type"`
+	Total        int           `json:"total"`
+	Entry        []BundleEntry `json:"entry,omitempty"`
+}
+
+// BundleEntry is one resource of a bundle
+type BundleEntry struct {
+	FullURL  string      `json:"fullUrl,omitempty"`
+	Resource interface{} `json:"resource"`
+}
+
+// OperationOutcome reports why a request failed
+type OperationOutcome struct {
+	ResourceType string  `json:"resourceType"`
+	Issue        []Issue `json:"issue"`
+}
+
+// Issue is one problem of an operation outcome
+type Issue struct {
+	Severity    string `json:"severity"`
+	Code        string `json:"code"`
+	Diagnostics string `json:"diagnostics,omitempty"`
+}
+
+// CapabilityStatement describes the resources and interactions the server supports
+type CapabilityStatement struct {
+	ResourceType string           `json:"resourceType"`
+	Status       string           `json:"status"`
+	Date         time.Time        `json:"date"`
+	Kind         string           `json:"kind"`
+	Software     *Software        `json:"software,omitempty"`
+	FhirVersion  string           `json:"fhirVersion"`
+	Format       []string         `json:"format"`
+	Rest         []CapabilityRest `json:"rest"`
+}
+
+// Software names the server
+type Software struct {
+	Name string `json:"name"`
+}
+
+// CapabilityRest lists the resources of the RESTful interface
+type CapabilityRest struct {
+	Mode     string               `json:"mode"`
+	Resource []CapabilityResource `json:"resource"`
+}
+
+// CapabilityResource is a resource type the server supports
+type CapabilityResource struct {
+	Type             string                  `json:"type"`
+	SupportedProfile []string                `json:"supportedProfile,omitempty"`
+	Interaction      []CapabilityCode        `json:"interaction"`
+	SearchParam      []CapabilitySearchParam `json:"searchParam,omitempty"`
+}
+
+// CapabilityCode is an interaction of a resource type
+type CapabilityCode struct {
+	Code string `json:"code"`
+}
+
+// CapabilitySearchParam is a search parameter of a resource type
+type CapabilitySearchParam struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
@@ -0,0 +1,153 @@
+package his
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// NGHISAdapterProvider calls the nghis-adapter, which answers from NGHIS. The headers of the
+// configuration go with every call, e.g. the x-tenant-id of the NGHIS tenant.
+type NGHISAdapterProvider struct {
+	baseURL string
+	headers map[string]string
+	retries int
+	client  *http.Client
+}
+
+func NewNGHISAdapterProvider(config *types.ExternalAPIConfig) (*NGHISAdapterProvider, error) {
+	if config.NGHISAdapterURL == "" {
+		return nil, errors.New("nghis-adapter URL is not configured")
+	}
+	return &NGHISAdapterProvider{
+		baseURL: strings.TrimSuffix(config.NGHISAdapterURL, "/"),
+		headers: config.Headers,
+		retries: config.RetryAttempts,
+		client:  httpClient(config),
+	}, nil
+}
+
+// adapterAppointment is an appointment as the nghis-adapter returns it
+type adapterAppointment struct {
+	Id            string    `json:"id"`
+	RequestedTime time.Time `json:"requestedTime"`
+	Duration      int64     `json:"duration"`
+	ServiceName   string    `json:"serviceName"`
+}
+
+// adapterPerson is a person as the nghis-adapter returns it
+type adapterPerson struct {
+	Identifier string   `json:"identifier"`
+	Name       string   `json:"name"`
+	BirthDate  *string  `json:"birthDate"`
+	Age        *int     `json:"age"`
+	Symbols    []string `json:"symbols"`
+	Insurance  *struct {
+		Code string `json:"code"`
+		Name string `json:"name"`
+	} `json:"insurance"`
+}
+
+// GetServices returns the appointments of the patient as the services to choose from; NGHIS
+// names them, so the language is not passed
+func (p *NGHISAdapterProvider) GetServices(ctx context.Context, identifier, _ string) ([]dto.UserService, error) {
+	appointments, err := p.GetAppointments(ctx, identifier)
+	if err != nil {
+		return nil, err
+	}
+	services := make([]dto.UserService, len(appointments))
+	for i, appointment := range appointments {
+		services[i] = dto.UserService{
+			Id:          appointment.ID,
+			ServiceName: appointment.ServiceName,
+			Duration:    appointment.DurationMinutes,
+		}
+	}
+	return services, nil
+}
+
+func (p *NGHISAdapterProvider) GetAppointments(ctx context.Context, identifier string) ([]Appointment, error) {
+	var found []adapterAppointment
+	ok, err := p.get(ctx, "/patient/{identifier}/appointments", "/patient/"+url.PathEscape(identifier)+"/appointments", &found)
+	if err != nil || !ok {
+		return []Appointment{}, err
+	}
+	appointments := make([]Appointment, len(found))
+	for i, appointment := range found {
+		appointments[i] = Appointment{
+			ID:              appointment.Id,
+			Time:            appointment.RequestedTime,
+			DurationMinutes: appointment.Duration,
+			ServiceName:     appointment.ServiceName,
+		}
+	}
+	return appointments, nil
+}
+
+func (p *NGHISAdapterProvider) GetPatient(ctx context.Context, identifier string) (*Patient, error) {
+	var found adapterPerson
+	ok, err := p.get(ctx, "/persons/{identifier}", "/persons/"+url.PathEscape(identifier), &found)
+	if err != nil || !ok {
+		return nil, err
+	}
+	patient := &Patient{
+		Identifier: found.Identifier,
+		Name:       found.Name,
+		Age:        found.Age,
+		Symbols:    found.Symbols,
+	}
+	if found.BirthDate != nil {
+		patient.BirthDate = *found.BirthDate
+	}
+	if found.Insurance != nil {
+		patient.InsurerCode = found.Insurance.Code
+		patient.InsurerName = found.Insurance.Name
+	}
+	return patient, nil
+}
+
+// NotifyVisit is not supported: NGHIS learns about visits through its own check-in
+func (p *NGHISAdapterProvider) NotifyVisit(context.Context, Visit) error {
+	return ErrNotSupported
+}
+
+// get decodes the response of a GET of the path of the adapter route into result; false when the
+// adapter doesn't know the patient
+func (p *NGHISAdapterProvider) get(ctx context.Context, route, path string, result any) (bool, error) {
+	resp, err := call(ctx, p.client, p.baseURL+route, p.retries, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Accept", "application/json")
+		for key, value := range p.headers {
+			req.Header.Set(key, value)
+		}
+		return req, nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to call nghis-adapter: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return false, fmt.Errorf("nghis-adapter returned status %d for %s: %s", resp.StatusCode, route, string(body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return false, fmt.Errorf("failed to decode nghis-adapter response: %w", err)
+	}
+	return true, nil
+}
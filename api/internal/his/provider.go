@@ -0,0 +1,98 @@
+// Package his connects the waiting room to the hospital information system (HIS) of a tenant.
+// Every system is one Provider; the external API configuration of the tenant selects it.
+package his
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// Providers selectable in the external API configuration
+const (
+	ProviderGenericREST  = "generic_rest"  // URLs of the external API configuration (default)
+	ProviderNGHISAdapter = "nghis_adapter" // nghis-adapter in front of NGHIS
+)
+
+// Providers lists the selectable providers
+var Providers = []string{ProviderGenericREST, ProviderNGHISAdapter}
+
+// defaultTimeoutSeconds is the timeout of HIS calls without a configured one
+const defaultTimeoutSeconds = 10
+
+var (
+	// ErrNotSupported is returned by providers for data their HIS does not offer
+	ErrNotSupported = errors.New("not supported by the HIS provider")
+	// ErrUnknownProvider is returned for a provider name that is not in Providers
+	ErrUnknownProvider = errors.New("unknown HIS provider")
+)
+
+// Appointment is an appointment of a patient in the HIS
+type Appointment struct {
+	ID              string
+	Time            time.Time
+	DurationMinutes int64
+	ServiceName     string
+}
+
+// Patient holds the demographics of a patient the priority calculation uses
+type Patient struct {
+	Identifier  string
+	Name        string
+	BirthDate   string // YYYY-MM-DD
+	Age         *int
+	Symbols     []string // Priority symbols, e.g. VIP or IMMOBILE
+	InsurerCode string
+	InsurerName string
+}
+
+// Visit is a patient who joined a queue
+type Visit struct {
+	EntryID      string    `json:"entryId"`
+	TicketNumber string    `json:"ticketNumber"`
+	Identifier   string    `json:"identifier"`
+	RoomID       string    `json:"roomId"`
+	ServiceID    string    `json:"serviceId,omitempty"`
+	ServiceName  string    `json:"serviceName,omitempty"`
+	ArrivedAt    time.Time `json:"arrivedAt"`
+}
+
+// Provider is the interface a hospital system implements to supply the kiosk. Methods return
+// ErrNotSupported for data the system does not offer.
+type Provider interface {
+	// GetServices returns the services the patient can choose at the kiosk, in the language
+	// when the system translates them
+	GetServices(ctx context.Context, identifier, language string) ([]dto.UserService, error)
+	// GetAppointments returns the upcoming appointments of the patient
+	GetAppointments(ctx context.Context, identifier string) ([]Appointment, error)
+	// GetPatient returns the demographics of the patient, nil for a patient the system doesn't know
+	GetPatient(ctx context.Context, identifier string) (*Patient, error)
+	// NotifyVisit tells the system that the patient joined a queue
+	NotifyVisit(ctx context.Context, visit Visit) error
+}
+
+// New returns the provider the external API configuration selects
+func New(config *types.ExternalAPIConfig) (Provider, error) {
+	switch config.HISProvider {
+	case "", ProviderGenericREST:
+		return NewRESTProvider(config), nil
+	case ProviderNGHISAdapter:
+		return NewNGHISAdapterProvider(config)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, config.HISProvider)
+	}
+}
+
+// httpClient returns the client of the calls with the configured timeout
+func httpClient(config *types.ExternalAPIConfig) *http.Client {
+	timeoutSeconds := config.TimeoutSeconds
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = defaultTimeoutSeconds
+	}
+	return &http.Client{Timeout: time.Duration(timeoutSeconds) * time.Second}
+}
@@ -0,0 +1,239 @@
+package his
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/metrics"
+	"github.com/arfis/waiting-room/internal/service"
+)
+
+const (
+	// breakerFailures consecutive failures of an endpoint open its circuit for breakerCooldown
+	breakerFailures = 5
+	breakerCooldown = 30 * time.Second
+	// retryBackoff is the wait before the first retry; it doubles up to maxRetryBackoff
+	retryBackoff    = 200 * time.Millisecond
+	maxRetryBackoff = 2 * time.Second
+)
+
+// ErrCircuitOpen is returned without calling an endpoint that failed repeatedly, until it has
+// cooled down
+var ErrCircuitOpen = errors.New("circuit open after repeated failures")
+
+// EndpointStats are the calls of a tenant to one HIS endpoint since the API started
+type EndpointStats struct {
+	Endpoint       string
+	Requests       int64
+	Failures       int64
+	Retries        int64
+	AverageLatency time.Duration
+	CircuitOpen    bool
+	LastError      string
+	LastFailureAt  time.Time
+}
+
+// circuitBreaker stops calling an endpoint after repeated failures; once cooled down, one call
+// probes whether it is back
+type circuitBreaker struct {
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func (b *circuitBreaker) open(now time.Time) bool {
+	return b.failures >= breakerFailures && (now.Before(b.openUntil) || b.probing)
+}
+
+type statsKey struct {
+	tenantID string
+	endpoint string
+}
+
+type endpointCounters struct {
+	requests      int64
+	failures      int64
+	retries       int64
+	latency       time.Duration
+	lastError     string
+	lastFailureAt time.Time
+}
+
+// monitor keeps the breakers of the endpoints, shared by all tenants calling them, and the call
+// statistics per tenant
+type monitor struct {
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+	counters map[statsKey]*endpointCounters
+}
+
+var calls = &monitor{
+	breakers: map[string]*circuitBreaker{},
+	counters: map[statsKey]*endpointCounters{},
+}
+
+// allow reports whether the endpoint may be called and claims the probe of a cooled down circuit
+func (m *monitor) allow(endpoint string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	breaker := m.breakers[endpoint]
+	if breaker == nil {
+		return true
+	}
+	now := time.Now()
+	if breaker.open(now) {
+		return false
+	}
+	if breaker.failures >= breakerFailures {
+		breaker.probing = true
+	}
+	return true
+}
+
+// record counts a call of the endpoint in the statistics of the tenant of the context; err is the
+// error of a failed call
+func (m *monitor) record(ctx context.Context, endpoint string, latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := statsKey{tenantID: service.GetTenantID(ctx), endpoint: endpoint}
+	counters := m.counters[key]
+	if counters == nil {
+		counters = &endpointCounters{}
+		m.counters[key] = counters
+	}
+	counters.requests++
+	counters.latency += latency
+	if err != nil {
+		counters.failures++
+		counters.lastError = err.Error()
+		counters.lastFailureAt = time.Now()
+	}
+}
+
+// result updates the breaker of the endpoint with the outcome of a call; a call that was given up
+// only releases the probe
+func (m *monitor) result(endpoint string, failed, givenUp bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	breaker := m.breakers[endpoint]
+	if breaker == nil {
+		breaker = &circuitBreaker{}
+		m.breakers[endpoint] = breaker
+	}
+	breaker.probing = false
+	switch {
+	case givenUp:
+	case !failed:
+		breaker.failures = 0
+	default:
+		breaker.failures++
+		if breaker.failures >= breakerFailures {
+			breaker.openUntil = time.Now().Add(breakerCooldown)
+		}
+	}
+}
+
+func (m *monitor) retried(ctx context.Context, endpoint string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if counters := m.counters[statsKey{tenantID: service.GetTenantID(ctx), endpoint: endpoint}]; counters != nil {
+		counters.retries++
+	}
+}
+
+// Stats returns the statistics of the HIS endpoints the tenant called, by endpoint
+func Stats(tenantID string) []EndpointStats {
+	calls.mu.Lock()
+	defer calls.mu.Unlock()
+
+	now := time.Now()
+	stats := []EndpointStats{}
+	for key, counters := range calls.counters {
+		if key.tenantID != tenantID {
+			continue
+		}
+		endpoint := EndpointStats{
+			Endpoint:      key.endpoint,
+			Requests:      counters.requests,
+			Failures:      counters.failures,
+			Retries:       counters.retries,
+			LastError:     counters.lastError,
+			LastFailureAt: counters.lastFailureAt,
+		}
+		if counters.requests > 0 {
+			endpoint.AverageLatency = counters.latency / time.Duration(counters.requests)
+		}
+		if breaker := calls.breakers[key.endpoint]; breaker != nil {
+			endpoint.CircuitOpen = breaker.open(now)
+		}
+		stats = append(stats, endpoint)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Endpoint < stats[j].Endpoint })
+	return stats
+}
+
+// retryable reports whether a call failed in a way another attempt may not
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// call sends the request newRequest creates to the endpoint, retrying network errors, 5xx and 429
+// responses up to retries times with exponential backoff. Endpoint names the URL without the
+// patient, so the statistics and the breaker of all patients are the same.
+func call(ctx context.Context, client *http.Client, endpoint string, retries int, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	backoff := retryBackoff
+	for attempt := 0; ; attempt++ {
+		if !calls.allow(endpoint) {
+			calls.record(ctx, endpoint, 0, ErrCircuitOpen)
+			return nil, ErrCircuitOpen
+		}
+		req, err := newRequest()
+		if err != nil {
+			calls.result(endpoint, false, true)
+			return nil, err
+		}
+
+		start := time.Now()
+		resp, err := client.Do(req)
+		latency := time.Since(start)
+		if ctx.Err() != nil {
+			// The caller gave up; that's no failure of the endpoint
+			calls.result(endpoint, false, true)
+			if err == nil {
+				resp.Body.Close()
+			}
+			return nil, ctx.Err()
+		}
+		failed := retryable(resp, err)
+		cause := err
+		if cause == nil && failed {
+			cause = errors.New(resp.Status)
+		}
+		calls.record(ctx, endpoint, latency, cause)
+		calls.result(endpoint, failed, false)
+		metrics.ExternalAPIDuration.ObserveDuration(latency, endpoint, metrics.Result(cause))
+		if !failed || attempt >= retries {
+			return resp, err
+		}
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxRetryBackoff)
+		calls.retried(ctx, endpoint)
+	}
+}
@@ -0,0 +1,218 @@
+package his
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// RESTProvider calls the URLs of the external API configuration: the appointment services URL
+// with the ${identifier} placeholder for the services of a patient and the visit notification
+// URL for new visits. It has no appointments or patients.
+type RESTProvider struct {
+	config *types.ExternalAPIConfig
+	client *http.Client
+}
+
+func NewRESTProvider(config *types.ExternalAPIConfig) *RESTProvider {
+	return &RESTProvider{
+		config: config,
+		client: httpClient(config),
+	}
+}
+
+// servicesRequest is a call for services with the language handling of its URL
+type servicesRequest struct {
+	endpoint         string // URL without the patient
+	url              string
+	method           *string
+	body             string
+	identifier       string
+	language         string
+	languageHandling *string
+	languageHeader   *string
+}
+
+// GetServices calls the appointment services URL; without one the patient has no services
+func (p *RESTProvider) GetServices(ctx context.Context, identifier, language string) ([]dto.UserService, error) {
+	if p.config.AppointmentServicesURL == "" {
+		return []dto.UserService{}, nil
+	}
+	return p.fetchServices(ctx, servicesRequest{
+		endpoint:         p.config.AppointmentServicesURL,
+		url:              strings.ReplaceAll(p.config.AppointmentServicesURL, "${identifier}", identifier),
+		method:           p.config.AppointmentServicesHttpMethod,
+		identifier:       identifier,
+		language:         language,
+		languageHandling: p.config.AppointmentServicesLanguageHandling,
+		languageHeader:   p.config.AppointmentServicesLanguageHeader,
+	})
+}
+
+// GetGenericServices calls the generic services URL, sending the body with POST
+func (p *RESTProvider) GetGenericServices(ctx context.Context, language, body string) ([]dto.UserService, error) {
+	if p.config.GenericServicesURL == "" {
+		return []dto.UserService{}, nil
+	}
+	return p.fetchServices(ctx, servicesRequest{
+		endpoint:         p.config.GenericServicesURL,
+		url:              p.config.GenericServicesURL,
+		method:           p.config.GenericServicesHttpMethod,
+		body:             body,
+		language:         language,
+		languageHandling: p.config.GenericServicesLanguageHandling,
+		languageHeader:   p.config.GenericServicesLanguageHeader,
+	})
+}
+
+func (p *RESTProvider) GetAppointments(context.Context, string) ([]Appointment, error) {
+	return nil, ErrNotSupported
+}
+
+func (p *RESTProvider) GetPatient(context.Context, string) (*Patient, error) {
+	return nil, ErrNotSupported
+}
+
+// NotifyVisit posts the visit to the visit notification URL, if there is one
+func (p *RESTProvider) NotifyVisit(ctx context.Context, visit Visit) error {
+	if p.config.VisitNotificationURL == "" {
+		return nil
+	}
+	body, err := json.Marshal(visit)
+	if err != nil {
+		return err
+	}
+	// Not retried: the receiver would get the visit twice when only its response was lost
+	resp, err := call(ctx, p.client, p.config.VisitNotificationURL, 0, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.VisitNotificationURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for key, value := range p.config.Headers {
+			req.Header.Set(key, value)
+		}
+		return req, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to notify visit: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("visit notification returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// fetchServices calls a services URL, passing the language as its language handling says
+func (p *RESTProvider) fetchServices(ctx context.Context, request servicesRequest) ([]dto.UserService, error) {
+	httpMethod := "GET"
+	if request.method != nil {
+		httpMethod = *request.method
+	}
+
+	resp, err := call(ctx, p.client, request.endpoint, p.config.RetryAttempts, func() (*http.Request, error) {
+		return p.newServicesRequest(ctx, httpMethod, request)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", request.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", request.url, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response of %s: %w", request.url, err)
+	}
+	// Status and size only: the body holds the patient's data
+	log.Printf("[HIS] External API responded with status %d, %d bytes", resp.StatusCode, len(body))
+
+	return parseServices(body)
+}
+
+// newServicesRequest creates the request of a services call; every attempt sends a new one
+func (p *RESTProvider) newServicesRequest(ctx context.Context, httpMethod string, request servicesRequest) (*http.Request, error) {
+	// Create request with body if POST method
+	var bodyReader io.Reader
+	if httpMethod == "POST" && request.body != "" {
+		bodyReader = strings.NewReader(request.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, httpMethod, request.url, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if bodyReader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range p.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	q := req.URL.Query()
+	if request.identifier != "" {
+		q.Add("identifier", request.identifier)
+	}
+	if request.languageHandling != nil {
+		switch *request.languageHandling {
+		case "query_param":
+			// Convert language code to uppercase for API
+			langCode := strings.ToUpper(request.language)
+			q.Add("lang", langCode)
+			log.Printf("[HIS] Added language parameter: lang=%s", langCode)
+		case "header":
+			headerName := "Accept-Language"
+			if request.languageHeader != nil && *request.languageHeader != "" {
+				headerName = *request.languageHeader
+			}
+			req.Header.Set(headerName, request.language)
+			log.Printf("[HIS] Added language header: %s=%s", headerName, request.language)
+		case "none":
+			// No language handling - will rely on DeepL translation
+			log.Printf("[HIS] No language handling configured - will use DeepL translation if enabled")
+		}
+	}
+	req.URL.RawQuery = q.Encode()
+	return req, nil
+}
+
+// parseServices reads services in the external format (numeric id, code, name, duration) or as
+// UserService
+func parseServices(body []byte) ([]dto.UserService, error) {
+	type ExternalService struct {
+		Code     string `json:"code"`
+		Duration int64  `json:"duration"`
+		Id       int64  `json:"id"`
+		Name     string `json:"name"`
+	}
+
+	var externalServices []ExternalService
+	externalErr := json.Unmarshal(body, &externalServices)
+	if externalErr == nil {
+		services := make([]dto.UserService, len(externalServices))
+		for i, ext := range externalServices {
+			services[i] = dto.UserService{
+				Id:          fmt.Sprintf("%d", ext.Id),
+				ServiceName: ext.Name,
+				Duration:    ext.Duration,
+			}
+		}
+		log.Printf("[HIS] Parsed %d services from external API format", len(services))
+		return services, nil
+	}
+
+	var services []dto.UserService
+	if err := json.Unmarshal(body, &services); err != nil {
+		return nil, fmt.Errorf("failed to parse services in both formats: external format: %v, direct format: %w", externalErr, err)
+	}
+	log.Printf("[HIS] Parsed %d services from direct UserService format", len(services))
+	return services, nil
+}
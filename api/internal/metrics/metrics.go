@@ -0,0 +1,282 @@
+// Package metrics exposes the counters, histograms and gauges of the API in the Prometheus text
+// format. A few metric types don't justify the Prometheus client library, so like the card reader
+// the exposition format is written by hand.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContentType is the content type of the text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DurationBuckets are the buckets of request and command durations, in seconds
+var DurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// WaitBuckets are the buckets of the time patients wait, in seconds: one minute to four hours
+var WaitBuckets = []float64{60, 300, 600, 900, 1200, 1800, 2700, 3600, 5400, 7200, 10800, 14400}
+
+// collector writes the samples of one metric
+type collector interface {
+	write(ctx context.Context, w io.Writer)
+}
+
+// Registry holds the metrics written on a scrape, in the order they were registered
+type Registry struct {
+	mu         sync.Mutex
+	names      map[string]bool
+	collectors []collector
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{names: map[string]bool{}}
+}
+
+// register adds a metric; registering a name twice is a programming error
+func (r *Registry) register(name string, c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[name] {
+		panic("metrics: " + name + " registered twice")
+	}
+	r.names[name] = true
+	r.collectors = append(r.collectors, c)
+}
+
+// Write writes every metric of the registry in the text exposition format
+func (r *Registry) Write(ctx context.Context, w io.Writer) {
+	r.mu.Lock()
+	collectors := slices.Clone(r.collectors)
+	r.mu.Unlock()
+	for _, c := range collectors {
+		c.write(ctx, w)
+	}
+}
+
+// Handler serves the registry. With a token, scrapers must send it as bearer token.
+func (r *Registry) Handler(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if token != "" && req.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		r.Write(req.Context(), w)
+	})
+}
+
+// CounterVec is a counter with labels
+type CounterVec struct {
+	mu     sync.Mutex
+	name   string
+	help   string
+	labels []string
+	values map[string]float64 // key: label values joined by \xff
+}
+
+// NewCounter registers a counter with the given label names
+func (r *Registry) NewCounter(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{name: name, help: help, labels: labels, values: map[string]float64{}}
+	r.register(name, c)
+	return c
+}
+
+// Inc adds one to the counter of the label values
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v to the counter of the label values
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[labelKey(labelValues)] += v
+}
+
+func (c *CounterVec) write(_ context.Context, w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	if len(c.labels) == 0 {
+		fmt.Fprintf(w, "%s %s\n", c.name, formatFloat(c.values[""]))
+		return
+	}
+	for _, k := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, labelPairs(c.labels, splitKey(k)), formatFloat(c.values[k]))
+	}
+}
+
+// HistogramVec is a histogram with labels
+type HistogramVec struct {
+	mu      sync.Mutex
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+	series  map[string]*histogramSeries // key: label values joined by \xff
+}
+
+type histogramSeries struct {
+	counts []uint64 // per bucket, non-cumulative
+	sum    float64
+	count  uint64
+}
+
+// NewHistogram registers a histogram with the given upper bucket bounds and label names
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{name: name, help: help, labels: labels, buckets: buckets, series: map[string]*histogramSeries{}}
+	r.register(name, h)
+	return h
+}
+
+// Observe adds a value to the histogram of the label values
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := labelKey(labelValues)
+	s := h.series[key]
+	if s == nil {
+		s = &histogramSeries{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, b := range h.buckets {
+		if v <= b {
+			s.counts[i]++
+			break
+		}
+	}
+	s.sum += v
+	s.count++
+}
+
+// ObserveDuration adds a duration in seconds to the histogram of the label values
+func (h *HistogramVec) ObserveDuration(d time.Duration, labelValues ...string) {
+	h.Observe(d.Seconds(), labelValues...)
+}
+
+func (h *HistogramVec) write(_ context.Context, w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	for _, k := range sortedKeys(h.series) {
+		s := h.series[k]
+		values := splitKey(k)
+		if len(h.labels) == 0 {
+			values = nil
+		}
+		var cum uint64
+		for i, b := range h.buckets {
+			cum += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labelPairs(append(slices.Clone(h.labels), "le"), append(slices.Clone(values), formatFloat(b))), cum)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labelPairs(append(slices.Clone(h.labels), "le"), append(slices.Clone(values), "+Inf")), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, labelPairs(h.labels, values), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, labelPairs(h.labels, values), s.count)
+	}
+}
+
+// Sample is one value of a gauge with its label values
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// GaugeFunc is a gauge whose samples are collected on every scrape
+type GaugeFunc struct {
+	name    string
+	help    string
+	labels  []string
+	mu      sync.Mutex
+	collect func(ctx context.Context) ([]Sample, error)
+}
+
+// NewGaugeFunc registers a gauge with the given label names. Collect is called on every scrape;
+// until it is set with SetCollect the gauge has no samples.
+func (r *Registry) NewGaugeFunc(name, help string, labels ...string) *GaugeFunc {
+	g := &GaugeFunc{name: name, help: help, labels: labels}
+	r.register(name, g)
+	return g
+}
+
+// SetCollect sets the function returning the samples of the gauge
+func (g *GaugeFunc) SetCollect(collect func(ctx context.Context) ([]Sample, error)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.collect = collect
+}
+
+func (g *GaugeFunc) write(ctx context.Context, w io.Writer) {
+	g.mu.Lock()
+	collect := g.collect
+	g.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", g.name, g.help, g.name)
+	if collect == nil {
+		return
+	}
+	samples, err := collect(ctx)
+	if err != nil {
+		// The other metrics are still worth a scrape
+		log.Printf("[Metrics] Failed to collect %s: %v", g.name, err)
+		return
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return labelKey(samples[i].LabelValues) < labelKey(samples[j].LabelValues)
+	})
+	for _, s := range samples {
+		if len(g.labels) == 0 {
+			fmt.Fprintf(w, "%s %s\n", g.name, formatFloat(s.Value))
+			continue
+		}
+		fmt.Fprintf(w, "%s%s %s\n", g.name, labelPairs(g.labels, s.LabelValues), formatFloat(s.Value))
+	}
+}
+
+func labelKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+func splitKey(key string) []string {
+	return strings.Split(key, "\xff")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func labelPairs(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, n := range names {
+		v := ""
+		if i < len(values) {
+			v = values[i]
+		}
+		pairs[i] = n + `="` + labelEscaper.Replace(v) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func scrape(t *testing.T, r *Registry) string {
+	t.Helper()
+	var b strings.Builder
+	r.Write(context.Background(), &b)
+	return b.String()
+}
+
+func TestCounterExposition(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("test_total", "Test counter.", "tenant", "room")
+	c.Inc("b1:s1", "room-2")
+	c.Inc("b1:s1", "room-1")
+	c.Add(2, "b1:s1", "room-1")
+	c.Inc(`a"b`, "x\ny")
+
+	expected := "# HELP test_total Test counter.\n# TYPE test_total counter\n" +
+		`test_total{tenant="a\"b",room="x\ny"} 1` + "\n" +
+		`test_total{tenant="b1:s1",room="room-1"} 3` + "\n" +
+		`test_total{tenant="b1:s1",room="room-2"} 1` + "\n"
+	if got := scrape(t, r); got != expected {
+		t.Errorf("Unexpected exposition:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestCounterWithoutLabels(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("idle_total", "Never incremented.")
+
+	if got := scrape(t, r); !strings.Contains(got, "\nidle_total 0\n") {
+		t.Errorf("Expected a zero sample for a counter without labels, got:\n%s", got)
+	}
+}
+
+func TestHistogramExposition(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogram("test_seconds", "Test histogram.", []float64{0.1, 1}, "result")
+	h.Observe(0.05, "ok")
+	h.Observe(0.5, "ok")
+	h.Observe(5, "ok")
+
+	expected := "# HELP test_seconds Test histogram.\n# TYPE test_seconds histogram\n" +
+		`test_seconds_bucket{result="ok",le="0.1"} 1` + "\n" +
+		`test_seconds_bucket{result="ok",le="1"} 2` + "\n" +
+		`test_seconds_bucket{result="ok",le="+Inf"} 3` + "\n" +
+		`test_seconds_sum{result="ok"} 5.55` + "\n" +
+		`test_seconds_count{result="ok"} 3` + "\n"
+	if got := scrape(t, r); got != expected {
+		t.Errorf("Unexpected exposition:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestGaugeFuncCollectsOnScrape(t *testing.T) {
+	r := NewRegistry()
+	g := r.NewGaugeFunc("test_clients", "Test gauge.", "kind")
+	if got := scrape(t, r); strings.Contains(got, "test_clients{") {
+		t.Errorf("Expected no samples before the collect function is set, got:\n%s", got)
+	}
+
+	clients := 2.0
+	g.SetCollect(func(context.Context) ([]Sample, error) {
+		return []Sample{{LabelValues: []string{"queue"}, Value: clients}, {LabelValues: []string{"kiosk"}, Value: 1}}, nil
+	})
+	clients = 3
+	got := scrape(t, r)
+	if !strings.Contains(got, "test_clients{kind=\"kiosk\"} 1\ntest_clients{kind=\"queue\"} 3\n") {
+		t.Errorf("Expected the sorted samples at the time of the scrape, got:\n%s", got)
+	}
+
+	g.SetCollect(func(context.Context) ([]Sample, error) {
+		return nil, errors.New("database down")
+	})
+	if got := scrape(t, r); !strings.Contains(got, "# TYPE test_clients gauge\n") || strings.Contains(got, "test_clients{") {
+		t.Errorf("Expected only the header of a gauge that failed to collect, got:\n%s", got)
+	}
+}
+
+func TestRegisterTwicePanics(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("dup_total", "First.")
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering a name twice to panic")
+		}
+	}()
+	r.NewCounter("dup_total", "Second.")
+}
+
+func TestHandlerToken(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("requests_total", "Test counter.").Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler("secret").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without the token, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	r.Handler("secret").ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 with the token, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Expected content type %q, got %q", ContentType, ct)
+	}
+	if !strings.Contains(rec.Body.String(), "requests_total 1\n") {
+		t.Errorf("Expected the counter in the response, got:\n%s", rec.Body.String())
+	}
+}
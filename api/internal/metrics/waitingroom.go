@@ -0,0 +1,41 @@
+package metrics
+
+// Default is the registry served at the metrics path of the API
+var Default = NewRegistry()
+
+// The metrics of the waiting room. Counters only grow; rates such as the entries created per
+// minute are taken by the queries, e.g. rate(waitingroom_entries_created_total[5m]) * 60.
+var (
+	// EntriesCreated counts the queue entries created, by tenant and room
+	EntriesCreated = Default.NewCounter("waitingroom_entries_created_total",
+		"Queue entries created, by tenant and room.", "tenant", "room")
+	// EntriesFinished counts the entries that left the queue, by tenant, room and final status
+	EntriesFinished = Default.NewCounter("waitingroom_entries_finished_total",
+		"Queue entries that left the queue, by tenant, room and status (COMPLETED, SKIPPED, CANCELLED, NO_SHOW).", "tenant", "room", "status")
+	// WaitTime observes the time from the check-in of entries to their call
+	WaitTime = Default.NewHistogram("waitingroom_wait_time_seconds",
+		"Time from the check-in of an entry to its call, by tenant and room.", WaitBuckets, "tenant", "room")
+	// QueueDepth is the number of WAITING entries of each tenant and room
+	QueueDepth = Default.NewGaugeFunc("waitingroom_queue_depth",
+		"Entries waiting to be called, by tenant and room.", "tenant", "room")
+	// WebSocketClients is the number of connected WebSocket clients, by kind
+	WebSocketClients = Default.NewGaugeFunc("waitingroom_websocket_clients",
+		"Connected WebSocket clients, by kind (queue, display, kiosk, card_reader).", "kind")
+	// WebhookDeliveries counts the webhook delivery attempts, by event and result
+	WebhookDeliveries = Default.NewCounter("waitingroom_webhook_deliveries_total",
+		"Webhook delivery attempts, by event and result (delivered, retry, failed).", "event", "result")
+	// ExternalAPIDuration observes the calls to the hospital information system
+	ExternalAPIDuration = Default.NewHistogram("waitingroom_external_api_request_duration_seconds",
+		"Duration of the calls to the hospital information system, by endpoint and result (ok, error).", DurationBuckets, "endpoint", "result")
+	// MongoCommandDuration observes the MongoDB commands
+	MongoCommandDuration = Default.NewHistogram("waitingroom_mongo_command_duration_seconds",
+		"Duration of the MongoDB commands, by command and result (ok, error).", DurationBuckets, "command", "result")
+)
+
+// Result returns the result label of an operation that failed with err
+func Result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
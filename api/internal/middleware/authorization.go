@@ -1,21 +1,137 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"strings"
+
+	"github.com/arfis/waiting-room/internal/auth"
+	appCtx "github.com/arfis/waiting-room/internal/context"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/types"
 )
 
-type AuthorizationMiddleware struct{}
+// APIKeyVerifier checks an API key against the API keys of the tenant in the context and records
+// its use; it returns nil for unknown and revoked keys
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, key, remoteAddr string) (*types.APIKey, error)
+}
+
+// AuthorizationMiddleware protects the routes of the admin, staff, kiosk and display clients. The
+// requests carry a JWT in Authorization: Bearer, valid for the tenant in X-Tenant-ID; only tokens
+// valid for all tenants may leave it out. Routes require the roles the specification lists for
+// them. Kiosks and displays may send an API key of the tenant instead, which grants the role of
+// its device type. Without a verifier, i.e. with no keys configured, every request passes.
+type AuthorizationMiddleware struct {
+	verifier             *auth.Verifier
+	apiKeyVerifier       APIKeyVerifier
+	responseErrorHandler *ngErrors.ResponseErrorHandler
+}
 
-func NewAuthorizationMiddleware() *AuthorizationMiddleware {
-	return &AuthorizationMiddleware{}
+func NewAuthorizationMiddleware(verifier *auth.Verifier, apiKeyVerifier APIKeyVerifier, responseErrorHandler *ngErrors.ResponseErrorHandler) *AuthorizationMiddleware {
+	return &AuthorizationMiddleware{
+		verifier:             verifier,
+		apiKeyVerifier:       apiKeyVerifier,
+		responseErrorHandler: responseErrorHandler,
+	}
 }
 
+// Middleware verifies the token of the request and adds its claims and subject to the context
 func (m *AuthorizationMiddleware) Middleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// For now, just pass through - no actual authorization
-			// In a real implementation, you would validate JWT tokens here
+			token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			token = strings.TrimSpace(token)
+			isAPIKey := strings.HasPrefix(token, types.APIKeyPrefix)
+			if m.verifier == nil && !isAPIKey {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !found || token == "" {
+				m.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.Unauthorized("missing bearer token"))
+				return
+			}
+			var claims *auth.Claims
+			var err error
+			if isAPIKey {
+				claims, err = m.verifyAPIKey(r, token)
+			} else {
+				claims, err = m.verifier.Verify(r.Context(), token)
+			}
+			if err != nil {
+				m.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.Unauthorized(err.Error()))
+				return
+			}
+
+			// A token of one tenant must not read or change another one, nor all of them by
+			// leaving out the tenant
+			tenantID, _ := r.Context().Value(TENANT).(string)
+			if tenantID == "" && !claims.HasAllTenants() {
+				m.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.Forbidden("missing tenant ID", nil))
+				return
+			}
+			if tenantID != "" && !claims.HasTenant(tenantID) {
+				m.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.Forbidden(fmt.Sprintf("token is not valid for tenant %s", tenantID), nil))
+				return
+			}
+			// The subject is the actor of the changes the request makes
+			ctx := auth.WithClaims(r.Context(), claims)
+			if claims.Subject != "" {
+				ctx = context.WithValue(ctx, appCtx.USER_ID, claims.Subject)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// verifyAPIKey returns the claims of an API key: the role of its device type for the tenant it
+// was issued to, which must be the tenant of the request
+func (m *AuthorizationMiddleware) verifyAPIKey(r *http.Request, key string) (*auth.Claims, error) {
+	apiKey, err := m.apiKeyVerifier.VerifyAPIKey(r.Context(), key, RemoteHost(r))
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == nil {
+		return nil, errors.New("unknown or revoked API key")
+	}
+	if tenantID, _ := r.Context().Value(TENANT).(string); apiKey.TenantID == "" || apiKey.TenantID != tenantID {
+		return nil, errors.New("API key is not valid for the tenant of the request")
+	}
+	return &auth.Claims{Subject: "api-key:" + apiKey.ID, Roles: []string{string(apiKey.DeviceType)}, Tenants: []string{apiKey.TenantID}}, nil
+}
+
+// RequireRoles rejects requests whose token grants none of the roles; admin passes every route
+func (m *AuthorizationMiddleware) RequireRoles(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Without keys only API keys carry roles; requests without one pass
+			claims := auth.ClaimsFromContext(r.Context())
+			if claims == nil && m.verifier == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if claims == nil {
+				m.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.Unauthorized("missing bearer token"))
+				return
+			}
+			if !claims.HasRole(roles...) {
+				m.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.Forbidden(fmt.Sprintf("requires role %s", strings.Join(roles, " or ")), nil))
+				return
+			}
 			next.ServeHTTP(w, r)
 		})
 	}
 }
+
+// RemoteHost returns the address of the client of the request without its port
+func RemoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/auth"
+	"github.com/arfis/waiting-room/internal/config"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// hmacJWT returns a JWT of payload signed with HS256 and secret
+func hmacJWT(t *testing.T, secret string, payload map[string]any) string {
+	t.Helper()
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := encode(map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encode(payload)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// TestAuthorizationRequiresTenant tests that only tokens valid for all tenants pass without one
+func TestAuthorizationRequiresTenant(t *testing.T) {
+	verifier, err := auth.NewVerifier(config.AuthConfig{HMACSecret: "secret", RolesClaim: "roles", TenantsClaim: "tenants"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewAuthorizationMiddleware(verifier, nil, ngErrors.NewResponseErrorHandler(slog.Default()))
+	handler := m.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	status := func(tenants, tenantID string) int {
+		token := hmacJWT(t, "secret", map[string]any{"exp": time.Now().Add(time.Hour).Unix(), "roles": []string{"staff"}, "tenants": tenants})
+		req := httptest.NewRequest(http.MethodGet, "/api/queue-entries", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if tenantID != "" {
+			req = req.WithContext(context.WithValue(req.Context(), TENANT, tenantID))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := status("b1", ""); code != http.StatusForbidden {
+		t.Errorf("Expected a token scoped to a tenant to be rejected without a tenant, got %d", code)
+	}
+	if code := status("b1", "b1:s1"); code != http.StatusOK {
+		t.Errorf("Expected a token scoped to the building to pass for its section, got %d", code)
+	}
+	if code := status("b1", "b2"); code != http.StatusForbidden {
+		t.Errorf("Expected a token scoped to another tenant to be rejected, got %d", code)
+	}
+	if code := status(auth.AllTenants, ""); code != http.StatusOK {
+		t.Errorf("Expected a token valid for all tenants to pass without a tenant, got %d", code)
+	}
+}
+
+// displayKey verifies one display API key of tenant b1
+type displayKey struct{}
+
+func (displayKey) VerifyAPIKey(_ context.Context, key, _ string) (*types.APIKey, error) {
+	if key != types.APIKeyPrefix+"display" {
+		return nil, nil
+	}
+	return &types.APIKey{ID: "key-1", TenantID: "b1", DeviceType: types.DeviceTypeDisplay}, nil
+}
+
+// TestRequireRolesWithoutKeys tests that without keys configured the roles of API keys are still
+// checked, while requests without a bearer token pass
+func TestRequireRolesWithoutKeys(t *testing.T) {
+	m := NewAuthorizationMiddleware(nil, displayKey{}, ngErrors.NewResponseErrorHandler(slog.Default()))
+	handler := m.Middleware()(m.RequireRoles(auth.RoleAdmin)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	status := func(bearer string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/tenants", nil)
+		req = req.WithContext(context.WithValue(req.Context(), TENANT, "b1"))
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := status(types.APIKeyPrefix + "display"); code != http.StatusForbidden {
+		t.Errorf("Expected a display key to be rejected from an admin route, got %d", code)
+	}
+	if code := status(types.APIKeyPrefix + "made-up"); code != http.StatusUnauthorized {
+		t.Errorf("Expected an unknown key to be rejected, got %d", code)
+	}
+	if code := status(""); code != http.StatusOK {
+		t.Errorf("Expected a request without a token to pass without keys configured, got %d", code)
+	}
+}
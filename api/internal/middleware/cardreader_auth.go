@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+const (
+	DEVICE_ID_HEADER = "X-Device-ID"
+
+	CARD_READER_DEVICE APP_CONTEXT = "CARD_READER_DEVICE"
+)
+
+// CardReaderCredentialVerifier checks a device credential against the card reader registry
+type CardReaderCredentialVerifier interface {
+	VerifyCardReaderCredential(ctx context.Context, deviceID, token, certFingerprint string) (bool, error)
+	// GetCardReaderTenant returns the tenant the device is registered to and whether it is registered
+	GetCardReaderTenant(ctx context.Context, deviceID string) (string, bool, error)
+}
+
+// CardReaderAuthMiddleware rejects card reader connections that don't present a
+// registered device credential: a bearer token (DEVICE_TOKEN) or a client certificate
+// whose fingerprint is registered for the device, or a card reader API key of the tenant.
+// A request authenticated with an API key runs in the tenant of the key.
+type CardReaderAuthMiddleware struct {
+	verifier             CardReaderCredentialVerifier
+	apiKeyVerifier       APIKeyVerifier
+	responseErrorHandler *ngErrors.ResponseErrorHandler
+}
+
+func NewCardReaderAuthMiddleware(verifier CardReaderCredentialVerifier, apiKeyVerifier APIKeyVerifier, responseErrorHandler *ngErrors.ResponseErrorHandler) *CardReaderAuthMiddleware {
+	return &CardReaderAuthMiddleware{
+		verifier:             verifier,
+		apiKeyVerifier:       apiKeyVerifier,
+		responseErrorHandler: responseErrorHandler,
+	}
+}
+
+func (m *CardReaderAuthMiddleware) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deviceID := r.Header.Get(DEVICE_ID_HEADER)
+			if deviceID == "" {
+				deviceID = r.URL.Query().Get("deviceId")
+			}
+			if deviceID == "" {
+				m.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.CardReaderUnauthorized("missing device ID"))
+				return
+			}
+
+			token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+			fingerprint := ClientCertFingerprint(r)
+			if token == "" && fingerprint == "" {
+				m.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.CardReaderUnauthorized("no device token or client certificate"))
+				return
+			}
+
+			ctx := r.Context()
+			var ok bool
+			var err error
+			if strings.HasPrefix(token, types.APIKeyPrefix) {
+				var apiKey *types.APIKey
+				apiKey, err = m.verifyAPIKey(r, deviceID, token)
+				if ok = apiKey != nil; ok {
+					ctx = context.WithValue(ctx, TENANT, apiKey.TenantID)
+				}
+			} else {
+				ok, err = m.verifier.VerifyCardReaderCredential(ctx, deviceID, token, fingerprint)
+			}
+			if err != nil {
+				m.responseErrorHandler.HandleAndWriteError(w, r, err)
+				return
+			}
+			if !ok {
+				m.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.CardReaderUnauthorized(deviceID))
+				return
+			}
+
+			ctx = context.WithValue(ctx, CARD_READER_DEVICE, deviceID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// verifyAPIKey returns the key if it is a card reader key of the tenant of the request that the
+// device may use, nil otherwise. A key for all readers of its tenant may not be used by a
+// device registered to another tenant.
+func (m *CardReaderAuthMiddleware) verifyAPIKey(r *http.Request, deviceID, key string) (*types.APIKey, error) {
+	apiKey, err := m.apiKeyVerifier.VerifyAPIKey(r.Context(), key, RemoteHost(r))
+	if err != nil || apiKey == nil || apiKey.DeviceType != types.DeviceTypeCardReader {
+		return nil, err
+	}
+	if tenantID, _ := r.Context().Value(TENANT).(string); apiKey.TenantID == "" || apiKey.TenantID != tenantID {
+		return nil, nil
+	}
+	if apiKey.DeviceID != "" {
+		if apiKey.DeviceID != deviceID {
+			return nil, nil
+		}
+		return apiKey, nil
+	}
+	registeredTenantID, registered, err := m.verifier.GetCardReaderTenant(r.Context(), deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if registered && registeredTenantID != apiKey.TenantID {
+		return nil, nil
+	}
+	return apiKey, nil
+}
+
+// ClientCertFingerprint returns the hex SHA-256 of the verified client certificate, if any
+func ClientCertFingerprint(r *http.Request) string {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(r.TLS.PeerCertificates[0].Raw)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+)
+
+const (
+	API_KEY_HEADER   = "X-API-Key"
+	TIMESTAMP_HEADER = "X-Webhook-Timestamp"
+	SIGNATURE_HEADER = "X-Webhook-Signature"
+)
+
+// signatureTolerance is how far the timestamp of a signed request may be from now, so a captured
+// request cannot be replayed later
+const signatureTolerance = 5 * time.Minute
+
+// maxIntegrationBody is the largest event body that is read to verify its signature
+const maxIntegrationBody = 1 << 20
+
+// IntegrationCredentialVerifier checks an API key against the integration credentials of the tenant
+type IntegrationCredentialVerifier interface {
+	VerifyIntegrationAPIKey(ctx context.Context, apiKey string) (signingSecret string, ok bool, err error)
+}
+
+// IntegrationAuthMiddleware rejects the requests of external systems that don't present the API key
+// of the tenant in X-Tenant-ID and a signature of the body made with the tenant's signing secret,
+// the same one outgoing webhooks carry: X-Webhook-Signature is "sha256=" and the hex HMAC-SHA256 of
+// the X-Webhook-Timestamp (Unix seconds), a dot and the body.
+type IntegrationAuthMiddleware struct {
+	verifier             IntegrationCredentialVerifier
+	responseErrorHandler *ngErrors.ResponseErrorHandler
+}
+
+func NewIntegrationAuthMiddleware(verifier IntegrationCredentialVerifier, responseErrorHandler *ngErrors.ResponseErrorHandler) *IntegrationAuthMiddleware {
+	return &IntegrationAuthMiddleware{
+		verifier:             verifier,
+		responseErrorHandler: responseErrorHandler,
+	}
+}
+
+func (m *IntegrationAuthMiddleware) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			signingSecret, err := m.authenticate(r)
+			if err != nil {
+				m.responseErrorHandler.HandleAndWriteError(w, r, err)
+				return
+			}
+
+			timestamp := r.Header.Get(TIMESTAMP_HEADER)
+			seconds, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				m.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.IntegrationUnauthorized("missing or invalid timestamp"))
+				return
+			}
+			if skew := time.Since(time.Unix(seconds, 0)); skew > signatureTolerance || skew < -signatureTolerance {
+				m.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.IntegrationUnauthorized("timestamp too far from now"))
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxIntegrationBody))
+			if err != nil {
+				m.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.IntegrationUnauthorized("unreadable body"))
+				return
+			}
+			expected := RequestSignature(signingSecret, timestamp, string(body))
+			if !hmac.Equal([]byte(r.Header.Get(SIGNATURE_HEADER)), []byte(expected)) {
+				m.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.IntegrationUnauthorized("invalid signature"))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// APIKeyMiddleware only requires the API key of the tenant, for standard clients such as FHIR
+// integration engines that cannot sign their requests
+func (m *IntegrationAuthMiddleware) APIKeyMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, err := m.authenticate(r); err != nil {
+				m.responseErrorHandler.HandleAndWriteError(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// authenticate checks the API key of a request against the credentials of its tenant and returns
+// the signing secret of the tenant
+func (m *IntegrationAuthMiddleware) authenticate(r *http.Request) (string, error) {
+	if tenantID, _ := r.Context().Value(TENANT).(string); tenantID == "" {
+		return "", ngErrors.IntegrationUnauthorized("missing tenant ID")
+	}
+	apiKey := r.Header.Get(API_KEY_HEADER)
+	if apiKey == "" {
+		return "", ngErrors.IntegrationUnauthorized("missing API key")
+	}
+
+	signingSecret, ok, err := m.verifier.VerifyIntegrationAPIKey(r.Context(), apiKey)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", ngErrors.IntegrationUnauthorized("unknown API key")
+	}
+	return signingSecret, nil
+}
+
+// RequestSignature returns the X-Webhook-Signature of a body sent at timestamp (Unix seconds): "sha256="
+// and the hex HMAC-SHA256 of "timestamp.body" with the secret
+func RequestSignature(secret, timestamp, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
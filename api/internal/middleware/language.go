@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+)
+
+const (
+	LANGUAGE_PARAM  = "language"
+	LANGUAGE_HEADER = "Content-Language"
+)
+
+// LanguageResolver returns the language the tenant serves for a requested one
+type LanguageResolver interface {
+	ResolveLanguage(ctx context.Context, requested string) (string, error)
+}
+
+// LanguageMiddleware replaces the language query parameter of requests with the language the tenant
+// of the request serves for it, so a kiosk asking for an unsupported language gets the default one
+// of the tenant from every endpoint. The resolved language is returned in Content-Language.
+type LanguageMiddleware struct {
+	resolver LanguageResolver
+}
+
+func NewLanguageMiddleware(resolver LanguageResolver) *LanguageMiddleware {
+	return &LanguageMiddleware{
+		resolver: resolver,
+	}
+}
+
+func (m *LanguageMiddleware) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			if !query.Has(LANGUAGE_PARAM) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			requested := query.Get(LANGUAGE_PARAM)
+			language, err := m.resolver.ResolveLanguage(r.Context(), requested)
+			if err != nil {
+				// Without the configuration the request proceeds in the language it asked for
+				log.Printf("[LanguageMiddleware] Failed to resolve language %q: %v", requested, err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if language != requested {
+				query.Set(LANGUAGE_PARAM, language)
+				resolved := *r.URL
+				resolved.RawQuery = query.Encode()
+				r = r.WithContext(r.Context())
+				r.URL = &resolved
+			}
+			w.Header().Set(LANGUAGE_HEADER, language)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/ratelimit"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// Endpoint classes with their own request budget
+const (
+	RATE_LIMIT_PUBLIC = "public"
+	RATE_LIMIT_KIOSK  = "kiosk"
+	RATE_LIMIT_ADMIN  = "admin"
+)
+
+// publicPaths are the routes patients' phones and the speaker systems call without credentials
+var publicPaths = []string{"/api/queue-entries/token/", "/q/", "/api/i18n/", "/announcements/"}
+
+// RateLimitMiddleware rejects the requests of clients that used up the budget of the endpoint
+// class with 429 and a Retry-After in seconds. Clients are told apart by the valid API key they
+// send, other clients by their address, so the phones polling the QR status page from one address
+// can't starve the kiosks.
+type RateLimitMiddleware struct {
+	enabled              bool
+	clientIPHeader       string
+	trustedProxies       int
+	apiKeyVerifier       APIKeyVerifier
+	limiters             map[string]*ratelimit.Limiter
+	responseErrorHandler *ngErrors.ResponseErrorHandler
+}
+
+func NewRateLimitMiddleware(cfg config.RateLimitConfig, apiKeyVerifier APIKeyVerifier, responseErrorHandler *ngErrors.ResponseErrorHandler) *RateLimitMiddleware {
+	return &RateLimitMiddleware{
+		enabled:        cfg.Enabled,
+		clientIPHeader: cfg.ClientIPHeader,
+		trustedProxies: max(cfg.TrustedProxies, 1),
+		apiKeyVerifier: apiKeyVerifier,
+		limiters: map[string]*ratelimit.Limiter{
+			RATE_LIMIT_PUBLIC: ratelimit.New(cfg.Public.RequestsPerMinute, cfg.Public.Burst),
+			RATE_LIMIT_KIOSK:  ratelimit.New(cfg.Kiosk.RequestsPerMinute, cfg.Kiosk.Burst),
+			RATE_LIMIT_ADMIN:  ratelimit.New(cfg.Admin.RequestsPerMinute, cfg.Admin.Burst),
+		},
+		responseErrorHandler: responseErrorHandler,
+	}
+}
+
+func (m *RateLimitMiddleware) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !m.enabled || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			class := endpointClass(r.URL.Path)
+			if ok, wait := m.limiters[class].Allow(m.clientKey(r)); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(min(wait, time.Hour).Seconds()))))
+				m.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RateLimited(fmt.Sprintf("%s budget used up", class)))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// endpointClass returns the class of the request budget a path is counted against
+func endpointClass(path string) string {
+	for _, prefix := range publicPaths {
+		if strings.HasPrefix(path, prefix) {
+			return RATE_LIMIT_PUBLIC
+		}
+	}
+	if strings.HasPrefix(path, "/api/admin/") {
+		return RATE_LIMIT_ADMIN
+	}
+	return RATE_LIMIT_KIOSK
+}
+
+// clientKey identifies the client by its API key, its address otherwise. Only a key the tenant
+// issued gets a budget of its own: made up keys would give every request a fresh bucket.
+func (m *RateLimitMiddleware) clientKey(r *http.Request) string {
+	if key := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")); strings.HasPrefix(key, types.APIKeyPrefix) {
+		if apiKey, err := m.apiKeyVerifier.VerifyAPIKey(r.Context(), key, RemoteHost(r)); err == nil && apiKey != nil {
+			return "key:" + apiKey.ID
+		}
+	}
+	return "ip:" + m.clientIP(r)
+}
+
+// clientIP returns the address the outermost trusted proxy got the request from. Each proxy
+// appends to the client IP header, so it is counted from the right: the entries before the ones
+// the trusted proxies added are sent by the client and would give it a fresh bucket per request.
+func (m *RateLimitMiddleware) clientIP(r *http.Request) string {
+	if m.clientIPHeader == "" {
+		return RemoteHost(r)
+	}
+	var hops []string
+	for _, value := range r.Header.Values(m.clientIPHeader) {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	if len(hops) == 0 {
+		return RemoteHost(r)
+	}
+	return hops[max(len(hops)-m.trustedProxies, 0)]
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// issuedKeys verifies the API keys of a fixed set
+type issuedKeys map[string]string
+
+func (k issuedKeys) VerifyAPIKey(_ context.Context, key, _ string) (*types.APIKey, error) {
+	if id, ok := k[key]; ok {
+		return &types.APIKey{ID: id}, nil
+	}
+	return nil, nil
+}
+
+// TestRateLimitKeysOnlyIssuedAPIKeys tests that made up API keys share the budget of their address
+func TestRateLimitKeysOnlyIssuedAPIKeys(t *testing.T) {
+	cfg := config.RateLimitConfig{Enabled: true, Kiosk: config.RateLimitBudget{RequestsPerMinute: 1, Burst: 1}}
+	m := NewRateLimitMiddleware(cfg, issuedKeys{types.APIKeyPrefix + "issued": "key-1"}, ngErrors.NewResponseErrorHandler(slog.Default()))
+	handler := m.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	status := func(bearer string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/kiosk/rooms", nil)
+		req.RemoteAddr = "192.0.2.1:4000"
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := status(types.APIKeyPrefix + "made-up-1"); code != http.StatusOK {
+		t.Fatalf("Expected the first request of the address to pass, got %d", code)
+	}
+	if code := status(types.APIKeyPrefix + "made-up-2"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected another made up key to count against the address, got %d", code)
+	}
+	if code := status(""); code != http.StatusTooManyRequests {
+		t.Errorf("Expected a request without a key to count against the address, got %d", code)
+	}
+	if code := status(types.APIKeyPrefix + "issued"); code != http.StatusOK {
+		t.Errorf("Expected an issued key to have a budget of its own, got %d", code)
+	}
+	if code := status(types.APIKeyPrefix + "issued"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected the issued key to use up its budget, got %d", code)
+	}
+}
+
+// TestRateLimitClientIP tests that the client address is taken from the entries of the client IP
+// header the trusted proxies added
+func TestRateLimitClientIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		header         string
+		trustedProxies int
+		values         []string
+		want           string
+	}{
+		{"no header configured", "", 0, []string{"198.51.100.7"}, "192.0.2.1"},
+		{"header missing", "X-Forwarded-For", 1, nil, "192.0.2.1"},
+		{"one proxy", "X-Forwarded-For", 1, []string{"198.51.100.7"}, "198.51.100.7"},
+		{"made up entries before the proxy's", "X-Forwarded-For", 1, []string{"203.0.113.1, 203.0.113.2, 198.51.100.7"}, "198.51.100.7"},
+		{"0 counts as one proxy", "X-Forwarded-For", 0, []string{"203.0.113.1, 198.51.100.7"}, "198.51.100.7"},
+		{"two proxies", "X-Forwarded-For", 2, []string{"203.0.113.1, 198.51.100.7, 10.0.0.2"}, "198.51.100.7"},
+		{"entries over several headers", "X-Forwarded-For", 2, []string{"203.0.113.1", "198.51.100.7, 10.0.0.2"}, "198.51.100.7"},
+		{"fewer entries than proxies", "X-Forwarded-For", 3, []string{"198.51.100.7, 10.0.0.2"}, "198.51.100.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.RateLimitConfig{ClientIPHeader: tt.header, TrustedProxies: tt.trustedProxies}
+			m := NewRateLimitMiddleware(cfg, issuedKeys{}, ngErrors.NewResponseErrorHandler(slog.Default()))
+			req := httptest.NewRequest(http.MethodGet, "/api/kiosk/rooms", nil)
+			req.RemoteAddr = "192.0.2.1:4000"
+			for _, value := range tt.values {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			if got := m.clientKey(req); got != "ip:"+tt.want {
+				t.Errorf("Expected ip:%s, got %s", tt.want, got)
+			}
+		})
+	}
+}
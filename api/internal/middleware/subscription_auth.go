@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// Roles a WebSocket subscription token grants. Public displays see ticket numbers only, staff
+// also see patient names, kiosks get no entries (only the room state and card reader events).
+const (
+	SubscriptionRoleDisplay = "display"
+	SubscriptionRoleStaff   = "staff"
+	SubscriptionRoleKiosk   = "kiosk"
+)
+
+// ErrInvalidSubscriptionToken is returned for a subscription token that is malformed, not signed
+// with the configured secret, expired or without a tenant
+var ErrInvalidSubscriptionToken = errors.New("invalid subscription token")
+
+// SubscriptionClaims are what a WebSocket subscription token grants
+type SubscriptionClaims struct {
+	Role string `json:"role"`
+	// TenantID is the tenant ("building[:section]") the token subscribes to; a building covers all
+	// its sections. Tokens without a tenant are invalid.
+	TenantID  string `json:"tenantId,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// IsSubscriptionRole reports whether role is one of the subscription roles
+func IsSubscriptionRole(role string) bool {
+	return role == SubscriptionRoleDisplay || role == SubscriptionRoleStaff || role == SubscriptionRoleKiosk
+}
+
+// Allows reports whether the claims cover subscribing to tenantID
+func (c SubscriptionClaims) Allows(tenantID string) bool {
+	if c.TenantID == "" {
+		return false
+	}
+	if c.TenantID == tenantID {
+		return true
+	}
+	tokenBuilding, tokenSection, _ := types.ParseTenantID(c.TenantID)
+	building, _, _ := types.ParseTenantID(tenantID)
+	return tokenSection == "" && tenantID != "" && tokenBuilding == building
+}
+
+// SignSubscriptionToken returns the token of claims: the base64url claims and their base64url
+// HMAC-SHA256 with secret, separated by a dot
+func SignSubscriptionToken(secret string, claims SubscriptionClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + subscriptionSignature(secret, encoded), nil
+}
+
+// VerifySubscriptionToken returns the claims of a token signed with secret that has not expired at now
+func VerifySubscriptionToken(secret, token string, now time.Time) (*SubscriptionClaims, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(subscriptionSignature(secret, encoded))) {
+		return nil, ErrInvalidSubscriptionToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidSubscriptionToken
+	}
+	var claims SubscriptionClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || !IsSubscriptionRole(claims.Role) || claims.TenantID == "" {
+		return nil, ErrInvalidSubscriptionToken
+	}
+	if claims.ExpiresAt != 0 && now.Unix() >= claims.ExpiresAt {
+		return nil, ErrInvalidSubscriptionToken
+	}
+	return &claims, nil
+}
+
+// SubscriptionToken returns the token of a WebSocket upgrade: the bearer token, or the token
+// query parameter for browsers, which cannot set headers on WebSocket connections
+func SubscriptionToken(r *http.Request) string {
+	if token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")); token != "" {
+		return token
+	}
+	return strings.TrimSpace(r.URL.Query().Get("token"))
+}
+
+func subscriptionSignature(secret, encoded string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
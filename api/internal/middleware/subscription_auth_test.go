@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestSubscriptionTokenRequiresTenant tests that a token without a tenant is valid for none
+func TestSubscriptionTokenRequiresTenant(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Unix()
+	token, err := SignSubscriptionToken("secret", SubscriptionClaims{Role: SubscriptionRoleDisplay, ExpiresAt: exp})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifySubscriptionToken("secret", token, time.Now()); !errors.Is(err, ErrInvalidSubscriptionToken) {
+		t.Errorf("Expected a token without a tenant to be invalid, got %v", err)
+	}
+	if (SubscriptionClaims{Role: SubscriptionRoleDisplay}).Allows("b1:s1") {
+		t.Error("Expected claims without a tenant to allow no tenant")
+	}
+
+	claims := SubscriptionClaims{Role: SubscriptionRoleDisplay, TenantID: "b1", ExpiresAt: exp}
+	token, err = SignSubscriptionToken("secret", claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verified, err := VerifySubscriptionToken("secret", token, time.Now())
+	if err != nil {
+		t.Fatalf("Expected a valid token, got %v", err)
+	}
+	if !verified.Allows("b1:s1") || verified.Allows("b2:s1") || verified.Allows("") {
+		t.Errorf("Expected a building token to allow its sections only, got %+v", verified)
+	}
+}
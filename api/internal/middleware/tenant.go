@@ -5,6 +5,9 @@ import (
 	"log"
 	"net/http"
 	"strings"
+
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/types"
 )
 
 type APP_CONTEXT string
@@ -12,17 +15,36 @@ type APP_CONTEXT string
 const (
 	TENANT_HEADER             = "X-Tenant-ID"
 	TENANT        APP_CONTEXT = "TENANT"
-	LOGIN         APP_CONTEXT = "LOGIN"
-	USER_INFO     string      = "USER_INFO"
+	// RESOLVED_TENANT is the *types.Tenant of the request, as registered
+	RESOLVED_TENANT APP_CONTEXT = "RESOLVED_TENANT"
+	LOGIN           APP_CONTEXT = "LOGIN"
+	USER_INFO       string      = "USER_INFO"
 )
 
-type TenantMiddleware struct{}
+// registryPaths manage the tenant registry itself, whichever tenant the backoffice has selected
+var registryPaths = []string{"/api/admin/tenants"}
+
+// TenantResolver looks up the tenant of a "buildingId" or "buildingId:sectionId" ID in the
+// tenant registry
+type TenantResolver interface {
+	// ResolveTenant returns the registered tenant, nil when it is not registered
+	ResolveTenant(ctx context.Context, tenantID string) (*types.Tenant, error)
+}
+
+type TenantMiddleware struct {
+	resolver             TenantResolver
+	responseErrorHandler *ngErrors.ResponseErrorHandler
+}
 
-func NewTenantMiddleware() *TenantMiddleware {
-	return &TenantMiddleware{}
+func NewTenantMiddleware(resolver TenantResolver, responseErrorHandler *ngErrors.ResponseErrorHandler) *TenantMiddleware {
+	return &TenantMiddleware{
+		resolver:             resolver,
+		responseErrorHandler: responseErrorHandler,
+	}
 }
 
-// Middleware extracts tenant ID from header or query parameter and adds it to context
+// Middleware extracts tenant ID from header or query parameter, rejects tenants that are not
+// registered with 403 and adds the ID and the resolved tenant to context
 func (m *TenantMiddleware) Middleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -45,6 +67,19 @@ func (m *TenantMiddleware) Middleware() func(http.Handler) http.Handler {
 
 			// If tenant ID is provided, add normalized version to context
 			if normalizedTenantID != "" {
+				if r.Method != http.MethodOptions && !isRegistryPath(r.URL.Path) {
+					tenant, err := m.resolver.ResolveTenant(ctx, normalizedTenantID)
+					if err != nil {
+						m.responseErrorHandler.HandleAndWriteError(w, r, err)
+						return
+					}
+					if tenant == nil {
+						log.Printf("[TenantMiddleware] Rejected unknown tenant '%s' for %s", normalizedTenantID, r.URL.Path)
+						m.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.UnknownTenant(normalizedTenantID))
+						return
+					}
+					ctx = context.WithValue(ctx, RESOLVED_TENANT, tenant)
+				}
 				ctx = context.WithValue(ctx, TENANT, normalizedTenantID)
 				log.Printf("[TenantMiddleware] Added normalized tenant ID to context: '%s' (original: '%s')", normalizedTenantID, tenantID)
 			} else {
@@ -55,3 +90,12 @@ func (m *TenantMiddleware) Middleware() func(http.Handler) http.Handler {
 		})
 	}
 }
+
+func isRegistryPath(path string) bool {
+	for _, prefix := range registryPaths {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
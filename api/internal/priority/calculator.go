@@ -6,13 +6,15 @@ import (
 
 // Calculator calculates tier and fitness score for queue entries
 type Calculator struct {
-	config *PriorityConfig
+	config   *PriorityConfig
+	location *time.Location
 }
 
 // NewCalculator creates a new priority calculator
 func NewCalculator(config *PriorityConfig) *Calculator {
 	return &Calculator{
-		config: config,
+		config:   config,
+		location: config.location(),
 	}
 }
 
@@ -26,23 +28,35 @@ type CalculationInput struct {
 	CurrentTime     time.Time
 }
 
-// CalculationResult contains the calculated tier and fitness score
+// CalculationResult contains the calculated tier and fitness score, with the contribution of each
+// factor to the score
 type CalculationResult struct {
 	Tier         int
 	FitnessScore float64
+	Breakdown    FitnessBreakdown
+	// Escalated is set when starvation protection moved the entry up one tier
+	Escalated bool
 }
 
 // Calculate determines the tier and fitness score for an entry
 func (c *Calculator) Calculate(input CalculationInput) CalculationResult {
-	// 1. Calculate tier
+	// 1. Calculate tier, one tier higher for an entry that waited past the starvation threshold
 	tier := c.calculateTier(input.Symbols)
+	escalated := false
+	if c.ShouldEscalate(input.ArrivalTime, input.CurrentTime) {
+		escalatedTier := c.EscalatedTier(tier)
+		escalated = escalatedTier != tier
+		tier = escalatedTier
+	}
 
 	// 2. Calculate fitness score
-	fitnessScore := c.calculateFitnessScore(input)
+	breakdown := c.fitnessBreakdown(input)
 
 	return CalculationResult{
 		Tier:         tier,
-		FitnessScore: fitnessScore,
+		FitnessScore: breakdown.Total(),
+		Breakdown:    breakdown,
+		Escalated:    escalated,
 	}
 }
 
@@ -97,29 +111,85 @@ func (c *Calculator) matchesTierCondition(symbolSet map[string]bool, condition C
 
 // calculateFitnessScore computes the fitness score based on all factors
 func (c *Calculator) calculateFitnessScore(input CalculationInput) float64 {
-	score := 0.0
+	return c.fitnessBreakdown(input).Total()
+}
+
+// FitnessBreakdown is the part each factor contributes to a fitness score
+type FitnessBreakdown struct {
+	SymbolWeights        float64
+	WaitingTime          float64
+	AppointmentDeviation float64
+	Age                  float64
+	ManualOverride       float64
+	Starvation           float64
+}
+
+// Total returns the fitness score of the breakdown
+func (b FitnessBreakdown) Total() float64 {
+	return b.SymbolWeights + b.WaitingTime + b.AppointmentDeviation + b.Age + b.ManualOverride + b.Starvation
+}
+
+// Explanation is a calculation result with the tier name, symbols and schedule rules used, so
+// rule changes can be tried out
+type Explanation struct {
+	CalculationResult
+	TierName    string
+	Symbols     []string
+	ActiveRules []string
+}
+
+// Explain calculates the tier and fitness score of an entry like Calculate, together with the
+// name of the tier
+func (c *Calculator) Explain(input CalculationInput) Explanation {
+	result := c.Calculate(input)
+
+	tierName := ""
+	for _, t := range c.config.PriorityModel.Tiers {
+		if t.ID == result.Tier {
+			tierName = t.Name
+			break
+		}
+	}
+
+	var activeRules []string
+	for _, rule := range c.config.activeRules(input.CurrentTime, c.location) {
+		activeRules = append(activeRules, rule.Name)
+	}
+
+	return Explanation{
+		CalculationResult: result,
+		TierName:          tierName,
+		Symbols:           input.Symbols,
+		ActiveRules:       activeRules,
+	}
+}
+
+// fitnessBreakdown computes the contribution of each factor to the fitness score
+func (c *Calculator) fitnessBreakdown(input CalculationInput) FitnessBreakdown {
+	var breakdown FitnessBreakdown
 	contrib := c.config.PriorityModel.Fitness.Contributions
+	weights := c.config.weightsAt(input.CurrentTime, c.location)
 
 	// 1. Symbol weights
 	for _, symbol := range input.Symbols {
-		if weight, ok := contrib.SymbolWeights.Values[symbol]; ok {
-			score += weight
+		if weight, ok := weights.symbolWeights[symbol]; ok {
+			breakdown.SymbolWeights += weight
 		}
 	}
 
 	// 2. Waiting time (now - arrivalTime)
 	waitingMinutes := input.CurrentTime.Sub(input.ArrivalTime).Minutes()
-	score += waitingMinutes * contrib.WaitingTime.WeightPerMinute
+	breakdown.WaitingTime = waitingMinutes * contrib.WaitingTime.WeightPerMinute
 
 	// 3. Appointment deviation (if appointment time is set)
 	if input.AppointmentTime != nil {
 		deviationMinutes := input.CurrentTime.Sub(*input.AppointmentTime).Minutes()
 		if deviationMinutes < 0 {
 			// Early (before appointment time) - penalty
-			score += (-deviationMinutes) * contrib.AppointmentDeviation.EarlyPenaltyPerMinute
+			breakdown.AppointmentDeviation = (-deviationMinutes) * contrib.AppointmentDeviation.EarlyPenaltyPerMinute
 		} else {
 			// Late (after appointment time) - bonus (negative score)
-			score += deviationMinutes * contrib.AppointmentDeviation.LateBonusPerMinute
+			breakdown.AppointmentDeviation = deviationMinutes * contrib.AppointmentDeviation.LateBonusPerMinute
 		}
 	}
 
@@ -129,18 +199,31 @@ func (c *Calculator) calculateFitnessScore(input CalculationInput) float64 {
 		if age < 6 {
 			// Children under 6: younger is higher priority
 			yearsYounger := 6 - age
-			score += float64(yearsYounger) * contrib.Age.Under6PerYearYounger
+			breakdown.Age = float64(yearsYounger) * contrib.Age.Under6PerYearYounger
 		} else if age >= contrib.Age.AgeThresholdSenior {
 			// Seniors: older is higher priority
 			yearsOlder := age - contrib.Age.AgeThresholdSenior
-			score += float64(yearsOlder) * contrib.Age.Over65PerYearOlder
+			breakdown.Age = float64(yearsOlder) * contrib.Age.Over65PerYearOlder
 		}
 	}
 
 	// 5. Manual override
 	if contrib.ManualOverride.Enabled && input.ManualOverride != nil {
-		score += (*input.ManualOverride) * contrib.ManualOverride.Weight
+		breakdown.ManualOverride = (*input.ManualOverride) * contrib.ManualOverride.Weight
+	}
+
+	// 6. Starvation bonus for entries waiting past the threshold
+	breakdown.Starvation = c.starvationBonus(input)
+
+	// 7. Schedule rules scale the contributions while they apply
+	if weights.multipliers != nil {
+		breakdown.SymbolWeights *= weights.multiplier(ContributionSymbolWeights)
+		breakdown.WaitingTime *= weights.multiplier(ContributionWaitingTime)
+		breakdown.AppointmentDeviation *= weights.multiplier(ContributionAppointmentDeviation)
+		breakdown.Age *= weights.multiplier(ContributionAge)
+		breakdown.ManualOverride *= weights.multiplier(ContributionManualOverride)
+		breakdown.Starvation *= weights.multiplier(ContributionStarvation)
 	}
 
-	return score
+	return breakdown
 }
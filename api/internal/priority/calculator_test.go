@@ -665,3 +665,35 @@ func TestCalculate_QueueOrdering(t *testing.T) {
 		t.Logf("  %s: Tier=%d, Score=%.2f", r.name, r.tier, r.score)
 	}
 }
+
+func TestExplain_MatchesCalculate(t *testing.T) {
+	config := getTestConfig()
+	calc := NewCalculator(config)
+	now := time.Now()
+
+	input := CalculationInput{
+		Symbols:         []string{"VIP", "IMMOBILE"},
+		AppointmentTime: timePtr(now.Add(-10 * time.Minute)),
+		Age:             intPtr(80),
+		ManualOverride:  float64Ptr(-5),
+		ArrivalTime:     now.Add(-20 * time.Minute),
+		CurrentTime:     now,
+	}
+
+	result := calc.Calculate(input)
+	explanation := calc.Explain(input)
+
+	if explanation.CalculationResult != result {
+		t.Errorf("Expected %+v, got %+v", result, explanation.CalculationResult)
+	}
+	if explanation.TierName != "VIP" {
+		t.Errorf("Expected tier name VIP, got %s", explanation.TierName)
+	}
+	contrib := config.PriorityModel.Fitness.Contributions
+	if want := contrib.SymbolWeights.Values["VIP"] + contrib.SymbolWeights.Values["IMMOBILE"]; explanation.Breakdown.SymbolWeights != want {
+		t.Errorf("Expected symbol weights %f, got %f", want, explanation.Breakdown.SymbolWeights)
+	}
+	if explanation.Breakdown.Total() != result.FitnessScore {
+		t.Errorf("Expected the breakdown to add up to %f, got %f", result.FitnessScore, explanation.Breakdown.Total())
+	}
+}
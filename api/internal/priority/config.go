@@ -5,6 +5,12 @@ type PriorityConfig struct {
 	Version       string        `json:"version" bson:"version"`
 	Description   string        `json:"description" bson:"description"`
 	PriorityModel PriorityModel `json:"priorityModel" bson:"priorityModel"`
+	// Symbols is the catalog of symbols swipes may carry; empty accepts any symbol
+	Symbols []Symbol `json:"symbols,omitempty" bson:"symbols,omitempty"`
+	// Timezone is the clinic's IANA timezone for schedule rules; empty uses the server's
+	Timezone string `json:"timezone,omitempty" bson:"timezone,omitempty"`
+	// Holidays is the tenant's holiday calendar for schedule rules
+	Holidays []Holiday `json:"holidays,omitempty" bson:"holidays,omitempty"`
 }
 
 // PriorityModel defines the algorithm and rules for priority calculation
@@ -12,6 +18,8 @@ type PriorityModel struct {
 	Algorithm Algorithm        `json:"algorithm" bson:"algorithm"`
 	Tiers     []Tier           `json:"tiers" bson:"tiers"`
 	Fitness   FitnessConfig    `json:"fitness" bson:"fitness"`
+	// Starvation escalates or boosts entries that waited too long
+	Starvation StarvationProtection `json:"starvation" bson:"starvation"`
 }
 
 // Algorithm describes the ordering logic
@@ -38,6 +46,8 @@ type Condition struct {
 type FitnessConfig struct {
 	Explanation   string        `json:"explanation" bson:"explanation"`
 	Contributions Contributions `json:"contributions" bson:"contributions"`
+	// ScheduleRules modify the contributions by time of day, weekday and holiday
+	ScheduleRules []ScheduleRule `json:"scheduleRules,omitempty" bson:"scheduleRules,omitempty"`
 }
 
 // Contributions defines all the factors that contribute to the fitness score
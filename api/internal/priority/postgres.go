@@ -0,0 +1,140 @@
+package priority
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// The PostgreSQL storage of the Repository, in the priority_configs and priority_config_revisions
+// tables of the repository migrations. Like the other PostgreSQL repositories it stores the
+// documents of the MongoDB collections as extended JSON; a tenant-level configuration has an
+// empty section.
+
+// NewPostgresRepository creates a priority configuration repository on a migrated PostgreSQL database
+func NewPostgresRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// queryDocuments decodes the document column of the rows selected by query into a new T each
+func queryDocuments[T any](ctx context.Context, db *sql.DB, query string, args ...any) ([]T, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := []T{}
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var document T
+		if err := bson.UnmarshalExtJSON(data, false, &document); err != nil {
+			return nil, fmt.Errorf("failed to decode document: %w", err)
+		}
+		documents = append(documents, document)
+	}
+	return documents, rows.Err()
+}
+
+func (r *Repository) getConfigPostgres(ctx context.Context, tenantID, sectionID string) (*PriorityConfig, error) {
+	// The section first, then the tenant-level config
+	sections := []string{sectionID}
+	if sectionID != "" {
+		sections = append(sections, "")
+	}
+	for _, section := range sections {
+		stored, err := queryDocuments[storedConfig](ctx, r.db, `SELECT document FROM priority_configs WHERE tenant_id = $1 AND section_id = $2`, tenantID, section)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get priority config: %w", err)
+		}
+		if len(stored) > 0 && stored[0].Config != nil {
+			log.Printf("[PriorityRepository] Found config for tenant %s, section %s", tenantID, section)
+			return stored[0].Config, nil
+		}
+	}
+
+	log.Printf("[PriorityRepository] No config found for tenant %s, section %s. Using default config", tenantID, sectionID)
+	return GetDefaultConfig(), nil
+}
+
+func (r *Repository) saveConfigPostgres(ctx context.Context, config *PriorityConfig, tenantID, sectionID string) error {
+	document, err := bson.MarshalExtJSON(bson.M{
+		"tenantId":  tenantID,
+		"sectionId": sectionID,
+		"config":    config,
+		"updatedAt": time.Now(),
+	}, false, false)
+	if err != nil {
+		return fmt.Errorf("failed to encode priority config: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx, `INSERT INTO priority_configs (tenant_id, section_id, document) VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id, section_id) DO UPDATE SET document = EXCLUDED.document`, tenantID, sectionID, string(document)); err != nil {
+		return fmt.Errorf("failed to save priority config: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) deleteConfigPostgres(ctx context.Context, tenantID, sectionID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM priority_configs WHERE tenant_id = $1 AND section_id = $2`, tenantID, sectionID); err != nil {
+		return fmt.Errorf("failed to delete priority config: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM priority_config_revisions WHERE tenant_id = $1 AND section_id = $2`, tenantID, sectionID); err != nil {
+		return fmt.Errorf("failed to delete priority config revisions: %w", err)
+	}
+	return tx.Commit()
+}
+
+// addRevisionPostgres numbers revision as the next one of its tenant/section and stores it; of two
+// concurrent saves taking the same number, the second fails on the primary key
+func (r *Repository) addRevisionPostgres(ctx context.Context, revision *ConfigRevision) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(revision), 0) + 1 FROM priority_config_revisions WHERE tenant_id = $1 AND section_id = $2`,
+		revision.TenantID, revision.SectionID).Scan(&revision.Revision); err != nil {
+		return fmt.Errorf("failed to get latest priority config revision: %w", err)
+	}
+	document, err := bson.MarshalExtJSON(revision, false, false)
+	if err != nil {
+		return fmt.Errorf("failed to encode priority config revision: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO priority_config_revisions (tenant_id, section_id, revision, document) VALUES ($1, $2, $3, $4)`,
+		revision.TenantID, revision.SectionID, revision.Revision, string(document)); err != nil {
+		return fmt.Errorf("failed to save priority config revision: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *Repository) listRevisionsPostgres(ctx context.Context, tenantID, sectionID string) ([]ConfigRevision, error) {
+	revisions, err := queryDocuments[ConfigRevision](ctx, r.db, `SELECT document FROM priority_config_revisions WHERE tenant_id = $1 AND section_id = $2 ORDER BY revision DESC`, tenantID, sectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list priority config revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+func (r *Repository) getRevisionPostgres(ctx context.Context, tenantID, sectionID string, revision int) (*ConfigRevision, error) {
+	revisions, err := queryDocuments[ConfigRevision](ctx, r.db, `SELECT document FROM priority_config_revisions WHERE tenant_id = $1 AND section_id = $2 AND revision = $3`, tenantID, sectionID, revision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get priority config revision: %w", err)
+	}
+	if len(revisions) == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrRevisionNotFound, revision)
+	}
+	return &revisions[0], nil
+}
@@ -2,6 +2,7 @@ package priority
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -12,32 +13,39 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// Repository handles loading and storing priority configurations
+// Repository handles loading and storing priority configurations, in MongoDB or, created with
+// NewPostgresRepository, in PostgreSQL
 type Repository struct {
 	collection *mongo.Collection
+	revisions  *mongo.Collection
+	db         *sql.DB
 }
 
 // NewRepository creates a new priority configuration repository
 func NewRepository(database *mongo.Database) *Repository {
 	return &Repository{
 		collection: database.Collection("priority_configs"),
+		revisions:  database.Collection("priority_config_revisions"),
 	}
 }
 
 // GetConfig retrieves the active priority configuration
 // If no config exists, it returns the default configuration
 func (r *Repository) GetConfig(ctx context.Context, tenantID, sectionID string) (*PriorityConfig, error) {
+	if r.db != nil {
+		return r.getConfigPostgres(ctx, tenantID, sectionID)
+	}
+
 	// Try to find a config for the specific tenant+section first
 	filter := bson.M{
 		"tenantId":  tenantID,
 		"sectionId": sectionID,
 	}
 
-	var config PriorityConfig
-	err := r.collection.FindOne(ctx, filter).Decode(&config)
+	config, err := decodeConfig(r.collection.FindOne(ctx, filter))
 	if err == nil {
 		log.Printf("[PriorityRepository] Found config for tenant %s, section %s", tenantID, sectionID)
-		return &config, nil
+		return config, nil
 	}
 
 	// If not found, try tenant-level config (no section)
@@ -46,10 +54,10 @@ func (r *Repository) GetConfig(ctx context.Context, tenantID, sectionID string)
 			"tenantId":  tenantID,
 			"sectionId": bson.M{"$exists": false},
 		}
-		err = r.collection.FindOne(ctx, filter).Decode(&config)
+		config, err = decodeConfig(r.collection.FindOne(ctx, filter))
 		if err == nil {
 			log.Printf("[PriorityRepository] Found tenant-level config for tenant %s", tenantID)
-			return &config, nil
+			return config, nil
 		}
 	}
 
@@ -62,8 +70,40 @@ func (r *Repository) GetConfig(ctx context.Context, tenantID, sectionID string)
 	return nil, fmt.Errorf("failed to get priority config: %w", err)
 }
 
+// storedConfig is the document SaveConfig writes
+type storedConfig struct {
+	Config *PriorityConfig `bson:"config"`
+}
+
+// decodeConfig decodes a stored configuration; documents written before SaveConfig nested the
+// configuration under "config" hold it inline
+func decodeConfig(result *mongo.SingleResult) (*PriorityConfig, error) {
+	raw, err := result.Raw()
+	if err != nil {
+		return nil, err
+	}
+
+	var stored storedConfig
+	if err := bson.Unmarshal(raw, &stored); err == nil && stored.Config != nil {
+		return stored.Config, nil
+	}
+	var config PriorityConfig
+	if err := bson.Unmarshal(raw, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
 // SaveConfig saves a priority configuration
 func (r *Repository) SaveConfig(ctx context.Context, config *PriorityConfig, tenantID, sectionID string) error {
+	if r.db != nil {
+		if err := r.saveConfigPostgres(ctx, config, tenantID, sectionID); err != nil {
+			return err
+		}
+		log.Printf("[PriorityRepository] Saved config for tenant %s, section %s", tenantID, sectionID)
+		return nil
+	}
+
 	// Add metadata
 	doc := bson.M{
 		"tenantId":  tenantID,
@@ -88,6 +128,29 @@ func (r *Repository) SaveConfig(ctx context.Context, config *PriorityConfig, ten
 	return nil
 }
 
+// DeleteConfig deletes the priority configuration of a tenant section and its revisions
+func (r *Repository) DeleteConfig(ctx context.Context, tenantID, sectionID string) error {
+	if r.db != nil {
+		if err := r.deleteConfigPostgres(ctx, tenantID, sectionID); err != nil {
+			return err
+		}
+	} else {
+		filter := bson.M{
+			"tenantId":  tenantID,
+			"sectionId": sectionID,
+		}
+		if _, err := r.collection.DeleteMany(ctx, filter); err != nil {
+			return fmt.Errorf("failed to delete priority config: %w", err)
+		}
+		if _, err := r.revisions.DeleteMany(ctx, filter); err != nil {
+			return fmt.Errorf("failed to delete priority config revisions: %w", err)
+		}
+	}
+
+	log.Printf("[PriorityRepository] Deleted config for tenant %s, section %s", tenantID, sectionID)
+	return nil
+}
+
 // GetDefaultConfig returns the default priority configuration
 func GetDefaultConfig() *PriorityConfig {
 	// Parse the default configuration from JSON
@@ -167,6 +230,12 @@ func GetDefaultConfig() *PriorityConfig {
           "weight": 1
         }
       }
+    },
+    "starvation": {
+      "description": "Entries waiting longer than thresholdMinutes move up one tier (escalate) or get a doubling waiting bonus (bonus), so lower tiers are never skipped indefinitely.",
+      "enabled": false,
+      "thresholdMinutes": 60,
+      "mode": "escalate"
     }
   }
 }`
@@ -0,0 +1,126 @@
+package priority
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrRevisionNotFound is returned when a tenant/section has no configuration revision with the number
+var ErrRevisionNotFound = errors.New("priority configuration revision not found")
+
+// ConfigRevision is a saved state of the priority configuration of a tenant/section. Revisions
+// are numbered from 1 per tenant/section and never change, so any of them can be restored.
+type ConfigRevision struct {
+	TenantID  string         `json:"tenantId" bson:"tenantId"`
+	SectionID string         `json:"sectionId" bson:"sectionId"`
+	Revision  int            `json:"revision" bson:"revision"`
+	Config    PriorityConfig `json:"config" bson:"config"`
+	SavedAt   time.Time      `json:"savedAt" bson:"savedAt"`
+	SavedBy   string         `json:"savedBy,omitempty" bson:"savedBy,omitempty"`
+	// RestoredFrom is the revision a rollback restored; 0 for edits
+	RestoredFrom int `json:"restoredFrom,omitempty" bson:"restoredFrom,omitempty"`
+}
+
+// AddRevision records config as the newest revision of the tenant/section
+func (r *Repository) AddRevision(ctx context.Context, config *PriorityConfig, tenantID, sectionID, savedBy string, restoredFrom int) (*ConfigRevision, error) {
+	if r.db != nil {
+		revision := &ConfigRevision{
+			TenantID:     tenantID,
+			SectionID:    sectionID,
+			Config:       *config,
+			SavedAt:      time.Now(),
+			SavedBy:      savedBy,
+			RestoredFrom: restoredFrom,
+		}
+		if err := r.addRevisionPostgres(ctx, revision); err != nil {
+			return nil, err
+		}
+		log.Printf("[PriorityRepository] Saved revision %d for tenant %s, section %s", revision.Revision, tenantID, sectionID)
+		return revision, nil
+	}
+
+	filter := bson.M{
+		"tenantId":  tenantID,
+		"sectionId": sectionID,
+	}
+
+	next := 1
+	var latest ConfigRevision
+	err := r.revisions.FindOne(ctx, filter, options.FindOne().SetSort(bson.D{{Key: "revision", Value: -1}})).Decode(&latest)
+	switch {
+	case err == nil:
+		next = latest.Revision + 1
+	case err != mongo.ErrNoDocuments:
+		return nil, fmt.Errorf("failed to get latest priority config revision: %w", err)
+	}
+
+	revision := &ConfigRevision{
+		TenantID:     tenantID,
+		SectionID:    sectionID,
+		Revision:     next,
+		Config:       *config,
+		SavedAt:      time.Now(),
+		SavedBy:      savedBy,
+		RestoredFrom: restoredFrom,
+	}
+	if _, err := r.revisions.InsertOne(ctx, revision); err != nil {
+		return nil, fmt.Errorf("failed to save priority config revision: %w", err)
+	}
+
+	log.Printf("[PriorityRepository] Saved revision %d for tenant %s, section %s", next, tenantID, sectionID)
+	return revision, nil
+}
+
+// ListRevisions returns the revisions of the tenant/section, newest first
+func (r *Repository) ListRevisions(ctx context.Context, tenantID, sectionID string) ([]ConfigRevision, error) {
+	if r.db != nil {
+		return r.listRevisionsPostgres(ctx, tenantID, sectionID)
+	}
+
+	filter := bson.M{
+		"tenantId":  tenantID,
+		"sectionId": sectionID,
+	}
+
+	cursor, err := r.revisions.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "revision", Value: -1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list priority config revisions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	revisions := []ConfigRevision{}
+	if err := cursor.All(ctx, &revisions); err != nil {
+		return nil, fmt.Errorf("failed to decode priority config revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+// GetRevision returns one revision of the tenant/section
+func (r *Repository) GetRevision(ctx context.Context, tenantID, sectionID string, revision int) (*ConfigRevision, error) {
+	if r.db != nil {
+		return r.getRevisionPostgres(ctx, tenantID, sectionID, revision)
+	}
+
+	filter := bson.M{
+		"tenantId":  tenantID,
+		"sectionId": sectionID,
+		"revision":  revision,
+	}
+
+	var stored ConfigRevision
+	err := r.revisions.FindOne(ctx, filter).Decode(&stored)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("%w: %d", ErrRevisionNotFound, revision)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get priority config revision: %w", err)
+	}
+	return &stored, nil
+}
@@ -0,0 +1,248 @@
+package priority
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Contribution names used by the multipliers of schedule rules
+const (
+	ContributionSymbolWeights        = "symbolWeights"
+	ContributionWaitingTime          = "waitingTime"
+	ContributionAppointmentDeviation = "appointmentDeviation"
+	ContributionAge                  = "age"
+	ContributionManualOverride       = "manualOverride"
+	ContributionStarvation           = "starvation"
+)
+
+// HolidayDay is the day name that makes a schedule rule apply on the holidays of the calendar
+const HolidayDay = "holiday"
+
+// ScheduleRule changes the fitness weights while it applies, e.g. to boost elderly patients
+// before 10:00 or to weigh waiting time differently on weekends and holidays
+type ScheduleRule struct {
+	Name string `json:"name" bson:"name"`
+	// Days are weekday names (monday ... sunday) or "holiday"; empty means every day
+	Days []string `json:"days,omitempty" bson:"days,omitempty"`
+	// From and To limit the rule to a time of day as "HH:MM", To exclusive; From after To spans
+	// midnight. Empty means the start or end of the day.
+	From string `json:"from,omitempty" bson:"from,omitempty"`
+	To   string `json:"to,omitempty" bson:"to,omitempty"`
+	// Multipliers scale contributions by name, e.g. {"age": 2}
+	Multipliers map[string]float64 `json:"multipliers,omitempty" bson:"multipliers,omitempty"`
+	// SymbolWeights replace the weights of single symbols
+	SymbolWeights map[string]float64 `json:"symbolWeights,omitempty" bson:"symbolWeights,omitempty"`
+}
+
+// Holiday is a day of the tenant's holiday calendar
+type Holiday struct {
+	// Date is "YYYY-MM-DD", or "MM-DD" for a holiday on the same date every year
+	Date string `json:"date" bson:"date"`
+	Name string `json:"name,omitempty" bson:"name,omitempty"`
+}
+
+// scheduleWeights are the fitness weights in effect at one time
+type scheduleWeights struct {
+	symbolWeights map[string]float64
+	multipliers   map[string]float64
+}
+
+// multiplier returns the factor for a contribution, 1 unless an active rule scales it
+func (w scheduleWeights) multiplier(contribution string) float64 {
+	if m, ok := w.multipliers[contribution]; ok {
+		return m
+	}
+	return 1
+}
+
+// location returns the clinic's timezone of the configuration, the process-local zone by default
+func (c *PriorityConfig) location() *time.Location {
+	if c.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		log.Printf("[PriorityCalculator] Invalid timezone %q, using local time: %v", c.Timezone, err)
+		return time.Local
+	}
+	return loc
+}
+
+// IsHoliday reports whether the day of t, in the clinic's timezone, is in the holiday calendar
+func (c *PriorityConfig) IsHoliday(t time.Time) bool {
+	return c.isHoliday(t.In(c.location()))
+}
+
+// isHoliday reports whether the day of local is in the holiday calendar
+func (c *PriorityConfig) isHoliday(local time.Time) bool {
+	date := local.Format("2006-01-02")
+	for _, holiday := range c.Holidays {
+		if holiday.Date == date || holiday.Date == date[5:] {
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveRules returns the schedule rules that apply at t, in configuration order
+func (c *PriorityConfig) ActiveRules(t time.Time) []ScheduleRule {
+	return c.activeRules(t, c.location())
+}
+
+// activeRules returns the schedule rules that apply at t in the clinic's timezone loc
+func (c *PriorityConfig) activeRules(t time.Time, loc *time.Location) []ScheduleRule {
+	rules := c.PriorityModel.Fitness.ScheduleRules
+	if len(rules) == 0 {
+		return nil
+	}
+
+	local := t.In(loc)
+	holiday := c.isHoliday(local)
+	minute := local.Hour()*60 + local.Minute()
+
+	var active []ScheduleRule
+	for _, rule := range rules {
+		if ruleAppliesOnDay(rule, local.Weekday(), holiday) && ruleAppliesAtMinute(rule, minute) {
+			active = append(active, rule)
+		}
+	}
+	return active
+}
+
+// weightsAt returns the weights in effect at t in loc: the configured symbol weights with the
+// replacements of the active rules applied in order, and the product of their multipliers
+func (c *PriorityConfig) weightsAt(t time.Time, loc *time.Location) scheduleWeights {
+	weights := scheduleWeights{symbolWeights: c.PriorityModel.Fitness.Contributions.SymbolWeights.Values}
+
+	active := c.activeRules(t, loc)
+	if len(active) == 0 {
+		return weights
+	}
+
+	symbolWeights := make(map[string]float64, len(weights.symbolWeights))
+	for symbol, weight := range weights.symbolWeights {
+		symbolWeights[symbol] = weight
+	}
+	multipliers := make(map[string]float64)
+	for _, rule := range active {
+		for symbol, weight := range rule.SymbolWeights {
+			symbolWeights[symbol] = weight
+		}
+		for contribution, m := range rule.Multipliers {
+			if current, ok := multipliers[contribution]; ok {
+				m *= current
+			}
+			multipliers[contribution] = m
+		}
+	}
+	weights.symbolWeights = symbolWeights
+	weights.multipliers = multipliers
+	return weights
+}
+
+// ruleAppliesOnDay reports whether a rule applies on a weekday, holiday or not
+func ruleAppliesOnDay(rule ScheduleRule, weekday time.Weekday, holiday bool) bool {
+	if len(rule.Days) == 0 {
+		return true
+	}
+	for _, day := range rule.Days {
+		day = strings.ToLower(day)
+		if (day == HolidayDay && holiday) || day == strings.ToLower(weekday.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleAppliesAtMinute reports whether a rule applies at a minute of the day
+func ruleAppliesAtMinute(rule ScheduleRule, minute int) bool {
+	from, to := 0, 24*60
+	if rule.From != "" {
+		from, _ = parseClock(rule.From)
+	}
+	if rule.To != "" {
+		to, _ = parseClock(rule.To)
+	}
+	if from <= to {
+		return minute >= from && minute < to
+	}
+	// The rule spans midnight, e.g. 22:00 - 06:00
+	return minute >= from || minute < to
+}
+
+// parseClock parses a time of day as "HH:MM" into minutes since midnight
+func parseClock(clock string) (int, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", clock)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// validateSchedule returns the problems of the timezone, holiday calendar and schedule rules
+func (c *PriorityConfig) validateSchedule() []string {
+	var problems []string
+
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			problems = append(problems, fmt.Sprintf("unknown timezone %s", c.Timezone))
+		}
+	}
+
+	for _, holiday := range c.Holidays {
+		_, yearly := time.Parse("01-02", holiday.Date)
+		_, dated := time.Parse("2006-01-02", holiday.Date)
+		if yearly != nil && dated != nil {
+			problems = append(problems, fmt.Sprintf("holiday %s needs a date as YYYY-MM-DD or MM-DD", holiday.Date))
+		}
+	}
+
+	contributions := map[string]bool{
+		ContributionSymbolWeights:        true,
+		ContributionWaitingTime:          true,
+		ContributionAppointmentDeviation: true,
+		ContributionAge:                  true,
+		ContributionManualOverride:       true,
+		ContributionStarvation:           true,
+	}
+	for _, rule := range c.PriorityModel.Fitness.ScheduleRules {
+		if strings.TrimSpace(rule.Name) == "" {
+			problems = append(problems, "schedule rules need a name")
+		}
+		for _, day := range rule.Days {
+			if !isDayName(day) {
+				problems = append(problems, fmt.Sprintf("schedule rule %s has an unknown day %s", rule.Name, day))
+			}
+		}
+		for _, clock := range []string{rule.From, rule.To} {
+			if clock == "" {
+				continue
+			}
+			if _, err := parseClock(clock); err != nil {
+				problems = append(problems, fmt.Sprintf("schedule rule %s: %v", rule.Name, err))
+			}
+		}
+		for contribution := range rule.Multipliers {
+			if !contributions[contribution] {
+				problems = append(problems, fmt.Sprintf("schedule rule %s has a multiplier for an unknown contribution %s", rule.Name, contribution))
+			}
+		}
+	}
+	return problems
+}
+
+// isDayName reports whether day is a weekday name or "holiday"
+func isDayName(day string) bool {
+	day = strings.ToLower(day)
+	if day == HolidayDay {
+		return true
+	}
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if day == strings.ToLower(weekday.String()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package priority
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// getScheduleConfig returns the test configuration in Bratislava time with a morning boost for
+// the elderly and a weekend and holiday rule
+func getScheduleConfig() *PriorityConfig {
+	config := getTestConfig()
+	config.Timezone = "Europe/Bratislava"
+	config.Holidays = []Holiday{
+		{Date: "2025-09-01", Name: "Constitution Day"},
+		{Date: "12-24", Name: "Christmas Eve"},
+	}
+	config.PriorityModel.Fitness.ScheduleRules = []ScheduleRule{
+		{Name: "morning seniors", To: "10:00", Multipliers: map[string]float64{ContributionAge: 2}},
+		{Name: "weekend", Days: []string{"saturday", "sunday", HolidayDay}, SymbolWeights: map[string]float64{"VIP": 0}},
+	}
+	return config
+}
+
+func TestActiveRules(t *testing.T) {
+	config := getScheduleConfig()
+	loc, err := time.LoadLocation("Europe/Bratislava")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want []string
+	}{
+		{"weekday morning", time.Date(2025, 9, 2, 8, 30, 0, 0, loc), []string{"morning seniors"}},
+		{"weekday afternoon", time.Date(2025, 9, 2, 14, 0, 0, 0, loc), nil},
+		{"end of the morning is exclusive", time.Date(2025, 9, 2, 10, 0, 0, 0, loc), nil},
+		{"saturday afternoon", time.Date(2025, 9, 6, 14, 0, 0, 0, loc), []string{"weekend"}},
+		{"dated holiday morning", time.Date(2025, 9, 1, 9, 0, 0, 0, loc), []string{"morning seniors", "weekend"}},
+		{"yearly holiday", time.Date(2026, 12, 24, 12, 0, 0, 0, loc), []string{"weekend"}},
+		// 07:30 UTC is 09:30 in Bratislava in summer
+		{"clinic timezone", time.Date(2025, 9, 2, 7, 30, 0, 0, time.UTC), []string{"morning seniors"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, rule := range config.ActiveRules(tt.at) {
+				got = append(got, rule.Name)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected rules %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expected rules %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestScheduleRules_ModifyFitness(t *testing.T) {
+	config := getScheduleConfig()
+	loc, err := time.LoadLocation("Europe/Bratislava")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+	calc := NewCalculator(config)
+	contrib := config.PriorityModel.Fitness.Contributions
+
+	morning := time.Date(2025, 9, 2, 8, 0, 0, 0, loc)
+	result := calc.Calculate(CalculationInput{Age: intPtr(75), ArrivalTime: morning, CurrentTime: morning})
+	if want := 2 * float64(75-contrib.Age.AgeThresholdSenior) * contrib.Age.Over65PerYearOlder; result.Breakdown.Age != want {
+		t.Errorf("Expected the doubled age contribution %f, got %f", want, result.Breakdown.Age)
+	}
+
+	saturday := time.Date(2025, 9, 6, 14, 0, 0, 0, loc)
+	result = calc.Calculate(CalculationInput{Symbols: []string{"VIP"}, ArrivalTime: saturday, CurrentTime: saturday})
+	if result.Breakdown.SymbolWeights != 0 {
+		t.Errorf("Expected no VIP weight on the weekend, got %f", result.Breakdown.SymbolWeights)
+	}
+
+	explanation := calc.Explain(CalculationInput{ArrivalTime: saturday, CurrentTime: saturday})
+	if len(explanation.ActiveRules) != 1 || explanation.ActiveRules[0] != "weekend" {
+		t.Errorf("Expected the weekend rule in the explanation, got %v", explanation.ActiveRules)
+	}
+}
+
+func TestValidate_Schedule(t *testing.T) {
+	config := getScheduleConfig()
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Expected a valid schedule, got %v", err)
+	}
+
+	config.Timezone = "Mars/Olympus"
+	config.Holidays = append(config.Holidays, Holiday{Date: "24.12."})
+	config.PriorityModel.Fitness.ScheduleRules = append(config.PriorityModel.Fitness.ScheduleRules, ScheduleRule{
+		Name:        "broken",
+		Days:        []string{"someday"},
+		From:        "25:00",
+		Multipliers: map[string]float64{"luck": 2},
+	})
+	if err := config.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package priority
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Starvation protection modes
+const (
+	// StarvationEscalate moves an entry that waited past the threshold up one tier, once
+	StarvationEscalate = "escalate"
+	// StarvationBonus gives an entry that waited past the threshold a waiting bonus that doubles
+	// every DoublingMinutes
+	StarvationBonus = "bonus"
+)
+
+// StarvationProtection keeps patients of lower tiers from being skipped indefinitely while a
+// stream of higher tier (e.g. STATIM or VIP) patients arrives
+type StarvationProtection struct {
+	Description string `json:"description" bson:"description"`
+	Enabled     bool   `json:"enabled" bson:"enabled"`
+	// ThresholdMinutes is how long an entry may wait before it is protected
+	ThresholdMinutes float64 `json:"thresholdMinutes" bson:"thresholdMinutes"`
+	// Mode is StarvationEscalate (the default) or StarvationBonus
+	Mode string `json:"mode,omitempty" bson:"mode,omitempty"`
+	// Bonus is the contribution after DoublingMinutes past the threshold, negative to move the
+	// entry forward; it doubles with every further DoublingMinutes
+	Bonus           float64 `json:"bonus,omitempty" bson:"bonus,omitempty"`
+	DoublingMinutes float64 `json:"doublingMinutes,omitempty" bson:"doublingMinutes,omitempty"`
+}
+
+// mode returns the configured mode, StarvationEscalate when unset
+func (s StarvationProtection) mode() string {
+	if s.Mode == "" {
+		return StarvationEscalate
+	}
+	return s.Mode
+}
+
+// overdueMinutes returns how many minutes an entry waited past the threshold, 0 when it is not
+// protected yet or protection is disabled
+func (s StarvationProtection) overdueMinutes(arrival, now time.Time) float64 {
+	if !s.Enabled {
+		return 0
+	}
+	return math.Max(0, now.Sub(arrival).Minutes()-s.ThresholdMinutes)
+}
+
+// ShouldEscalate reports whether an entry that arrived at arrival is to be moved up one tier at
+// now: escalation is enabled and the entry waited past the threshold
+func (c *Calculator) ShouldEscalate(arrival, now time.Time) bool {
+	starvation := c.config.PriorityModel.Starvation
+	return starvation.Enabled && starvation.mode() == StarvationEscalate &&
+		now.Sub(arrival).Minutes() >= starvation.ThresholdMinutes
+}
+
+// EscalatedTier returns the configured tier right before tier, i.e. the one with the next lower
+// ID, or tier itself when there is none
+func (c *Calculator) EscalatedTier(tier int) int {
+	escalated, found := tier, false
+	for _, t := range c.config.PriorityModel.Tiers {
+		if t.ID < tier && (!found || t.ID > escalated) {
+			escalated, found = t.ID, true
+		}
+	}
+	return escalated
+}
+
+// starvationBonus returns the waiting bonus of an entry in bonus mode
+func (c *Calculator) starvationBonus(input CalculationInput) float64 {
+	starvation := c.config.PriorityModel.Starvation
+	if starvation.mode() != StarvationBonus {
+		return 0
+	}
+	overdue := starvation.overdueMinutes(input.ArrivalTime, input.CurrentTime)
+	if overdue == 0 {
+		return 0
+	}
+	return starvation.Bonus * (math.Pow(2, overdue/starvation.DoublingMinutes) - 1)
+}
+
+// validateStarvation returns the problems of the starvation protection settings
+func (c *PriorityConfig) validateStarvation() []string {
+	starvation := c.PriorityModel.Starvation
+	if !starvation.Enabled {
+		return nil
+	}
+
+	var problems []string
+	if starvation.ThresholdMinutes <= 0 {
+		problems = append(problems, "starvation protection needs a positive thresholdMinutes")
+	}
+	switch starvation.mode() {
+	case StarvationEscalate:
+	case StarvationBonus:
+		if starvation.DoublingMinutes <= 0 {
+			problems = append(problems, "the starvation bonus needs a positive doublingMinutes")
+		}
+		if starvation.Bonus == 0 {
+			problems = append(problems, "the starvation bonus needs a bonus")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("unknown starvation protection mode %s", starvation.Mode))
+	}
+	return problems
+}
@@ -0,0 +1,119 @@
+package priority
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestStarvationEscalation(t *testing.T) {
+	config := getTestConfig()
+	config.PriorityModel.Starvation = StarvationProtection{Enabled: true, ThresholdMinutes: 45}
+	calculator := NewCalculator(config)
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		symbols       []string
+		waited        time.Duration
+		wantTier      int
+		wantEscalated bool
+	}{
+		{"below threshold", nil, 30 * time.Minute, 2, false},
+		{"normal patient past threshold", nil, 50 * time.Minute, 1, true},
+		{"VIP past threshold", []string{"VIP"}, 50 * time.Minute, 0, true},
+		{"highest tier stays", []string{"STATIM"}, 50 * time.Minute, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := calculator.Calculate(CalculationInput{
+				Symbols:     tt.symbols,
+				ArrivalTime: now.Add(-tt.waited),
+				CurrentTime: now,
+			})
+			if result.Tier != tt.wantTier || result.Escalated != tt.wantEscalated {
+				t.Errorf("Expected tier %d (escalated: %t), got tier %d (escalated: %t)", tt.wantTier, tt.wantEscalated, result.Tier, result.Escalated)
+			}
+		})
+	}
+}
+
+func TestEscalatedTier(t *testing.T) {
+	config := getTestConfig()
+	config.PriorityModel.Tiers[1].ID = 5
+	calculator := NewCalculator(config)
+
+	tests := []struct {
+		tier int
+		want int
+	}{
+		{2, 0},
+		{5, 2},
+		// A requeued no-show below the last tier moves back onto it
+		{6, 5},
+		{0, 0},
+	}
+	for _, tt := range tests {
+		if got := calculator.EscalatedTier(tt.tier); got != tt.want {
+			t.Errorf("EscalatedTier(%d) = %d, want %d", tt.tier, got, tt.want)
+		}
+	}
+}
+
+func TestStarvationBonus(t *testing.T) {
+	config := getTestConfig()
+	config.PriorityModel.Starvation = StarvationProtection{
+		Enabled:          true,
+		ThresholdMinutes: 30,
+		Mode:             StarvationBonus,
+		Bonus:            -100,
+		DoublingMinutes:  10,
+	}
+	calculator := NewCalculator(config)
+	now := time.Now()
+
+	tests := []struct {
+		waited time.Duration
+		want   float64
+	}{
+		{20 * time.Minute, 0},
+		{30 * time.Minute, 0},
+		{40 * time.Minute, -100},
+		{50 * time.Minute, -300},
+	}
+	for _, tt := range tests {
+		result := calculator.Calculate(CalculationInput{ArrivalTime: now.Add(-tt.waited), CurrentTime: now})
+		if math.Abs(result.Breakdown.Starvation-tt.want) > 0.01 {
+			t.Errorf("Expected starvation bonus %.2f after %v, got %.2f", tt.want, tt.waited, result.Breakdown.Starvation)
+		}
+		if result.Tier != 2 || result.Escalated {
+			t.Errorf("Expected bonus mode to keep tier 2, got tier %d", result.Tier)
+		}
+	}
+}
+
+func TestValidateStarvation(t *testing.T) {
+	tests := []struct {
+		name       string
+		starvation StarvationProtection
+		wantErr    bool
+	}{
+		{"disabled", StarvationProtection{}, false},
+		{"escalate", StarvationProtection{Enabled: true, ThresholdMinutes: 60}, false},
+		{"no threshold", StarvationProtection{Enabled: true}, true},
+		{"bonus", StarvationProtection{Enabled: true, ThresholdMinutes: 60, Mode: StarvationBonus, Bonus: -50, DoublingMinutes: 15}, false},
+		{"bonus without doubling", StarvationProtection{Enabled: true, ThresholdMinutes: 60, Mode: StarvationBonus, Bonus: -50}, true},
+		{"unknown mode", StarvationProtection{Enabled: true, ThresholdMinutes: 60, Mode: "skip"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := getTestConfig()
+			config.PriorityModel.Starvation = tt.starvation
+			if err := config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package priority
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrUnknownSymbol is returned when a symbol is not in the symbol catalog of the configuration
+	ErrUnknownSymbol = errors.New("unknown priority symbol")
+	// ErrInvalidSymbol is returned when a symbol definition cannot be stored in the catalog
+	ErrInvalidSymbol = errors.New("invalid priority symbol")
+	// ErrSymbolNotFound is returned when a symbol to change is not in the catalog
+	ErrSymbolNotFound = errors.New("priority symbol not found")
+	// ErrSymbolInUse is returned when a symbol to remove is still used by a tier or symbol weight
+	ErrSymbolInUse = errors.New("priority symbol in use")
+)
+
+// Symbol is an entry of the symbol catalog, e.g. STATIM or VIP, with how staff screens show it
+type Symbol struct {
+	Code  string `json:"code" bson:"code"`
+	Label string `json:"label" bson:"label"`
+	Color string `json:"color,omitempty" bson:"color,omitempty"`
+	Icon  string `json:"icon,omitempty" bson:"icon,omitempty"`
+}
+
+// FindSymbol returns the catalog symbol with code, ignoring case, or nil
+func (c *PriorityConfig) FindSymbol(code string) *Symbol {
+	for i := range c.Symbols {
+		if strings.EqualFold(c.Symbols[i].Code, code) {
+			return &c.Symbols[i]
+		}
+	}
+	return nil
+}
+
+// AddSymbol adds a symbol to the catalog; its code must not be taken
+func (c *PriorityConfig) AddSymbol(symbol Symbol) error {
+	symbol, err := normalizeSymbol(symbol)
+	if err != nil {
+		return err
+	}
+	if c.FindSymbol(symbol.Code) != nil {
+		return fmt.Errorf("%w: symbol %s already exists", ErrInvalidSymbol, symbol.Code)
+	}
+	c.Symbols = append(c.Symbols, symbol)
+	return nil
+}
+
+// UpdateSymbol replaces the label, color and icon of the catalog symbol with code
+func (c *PriorityConfig) UpdateSymbol(code string, symbol Symbol) error {
+	existing := c.FindSymbol(code)
+	if existing == nil {
+		return fmt.Errorf("%w: %s", ErrSymbolNotFound, code)
+	}
+	symbol.Code = existing.Code
+	symbol, err := normalizeSymbol(symbol)
+	if err != nil {
+		return err
+	}
+	*existing = symbol
+	return nil
+}
+
+// RemoveSymbol removes the symbol with code from the catalog unless a tier condition or symbol
+// weight still uses it
+func (c *PriorityConfig) RemoveSymbol(code string) error {
+	existing := c.FindSymbol(code)
+	if existing == nil {
+		return fmt.Errorf("%w: %s", ErrSymbolNotFound, code)
+	}
+	if used := c.referencedSymbols()[existing.Code]; used != "" {
+		return fmt.Errorf("%w: %s is used by %s", ErrSymbolInUse, existing.Code, used)
+	}
+
+	symbols := make([]Symbol, 0, len(c.Symbols)-1)
+	for _, symbol := range c.Symbols {
+		if symbol.Code != existing.Code {
+			symbols = append(symbols, symbol)
+		}
+	}
+	c.Symbols = symbols
+	return nil
+}
+
+// ResolveSymbols maps symbols to their catalog codes, ignoring case and dropping repeats. A
+// configuration without a catalog accepts every symbol as given. The symbols not in the catalog
+// are returned wrapped in ErrUnknownSymbol.
+func (c *PriorityConfig) ResolveSymbols(symbols []string) ([]string, error) {
+	if len(c.Symbols) == 0 {
+		return symbols, nil
+	}
+
+	resolved := make([]string, 0, len(symbols))
+	seen := make(map[string]bool, len(symbols))
+	var unknown []string
+	for _, symbol := range symbols {
+		symbol = strings.TrimSpace(symbol)
+		if symbol == "" {
+			continue
+		}
+		catalogSymbol := c.FindSymbol(symbol)
+		if catalogSymbol == nil {
+			unknown = append(unknown, symbol)
+			continue
+		}
+		if !seen[catalogSymbol.Code] {
+			seen[catalogSymbol.Code] = true
+			resolved = append(resolved, catalogSymbol.Code)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSymbol, strings.Join(unknown, ", "))
+	}
+	return resolved, nil
+}
+
+// ValidateSymbolReferences checks that the tier conditions and symbol weights only use catalog
+// symbols. A configuration without a catalog is not checked.
+func (c *PriorityConfig) ValidateSymbolReferences() error {
+	if len(c.Symbols) == 0 {
+		return nil
+	}
+
+	var unknown []string
+	for code, usedBy := range c.referencedSymbols() {
+		if catalogSymbol := c.FindSymbol(code); catalogSymbol == nil || catalogSymbol.Code != code {
+			unknown = append(unknown, code+" ("+usedBy+")")
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("%w: %s", ErrUnknownSymbol, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// referencedSymbols returns the symbols used by the tier conditions and symbol weights, each with
+// the first place it is used
+func (c *PriorityConfig) referencedSymbols() map[string]string {
+	references := make(map[string]string)
+	add := func(symbols []string, usedBy string) {
+		for _, symbol := range symbols {
+			if _, exists := references[symbol]; !exists {
+				references[symbol] = usedBy
+			}
+		}
+	}
+	for _, tier := range c.PriorityModel.Tiers {
+		add(tier.Condition.SymbolsAnyOf, "tier "+tier.Name)
+		add(tier.Condition.SymbolsNotAnyOf, "tier "+tier.Name)
+	}
+	for symbol := range c.PriorityModel.Fitness.Contributions.SymbolWeights.Values {
+		add([]string{symbol}, "symbol weights")
+	}
+	for _, rule := range c.PriorityModel.Fitness.ScheduleRules {
+		for symbol := range rule.SymbolWeights {
+			add([]string{symbol}, "schedule rule "+rule.Name)
+		}
+	}
+	return references
+}
+
+// normalizeSymbol trims a symbol definition and checks it has a code without spaces and a label
+func normalizeSymbol(symbol Symbol) (Symbol, error) {
+	symbol.Code = strings.TrimSpace(symbol.Code)
+	symbol.Label = strings.TrimSpace(symbol.Label)
+	symbol.Color = strings.TrimSpace(symbol.Color)
+	symbol.Icon = strings.TrimSpace(symbol.Icon)
+	if symbol.Code == "" || strings.ContainsAny(symbol.Code, " \t\n") {
+		return symbol, fmt.Errorf("%w: the code must be set and contain no spaces", ErrInvalidSymbol)
+	}
+	if symbol.Label == "" {
+		return symbol, fmt.Errorf("%w: symbol %s needs a label", ErrInvalidSymbol, symbol.Code)
+	}
+	return symbol, nil
+}
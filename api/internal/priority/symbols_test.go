@@ -0,0 +1,114 @@
+package priority
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// getCatalogConfig returns the test configuration with a catalog of its symbols
+func getCatalogConfig() *PriorityConfig {
+	config := getTestConfig()
+	config.Symbols = []Symbol{
+		{Code: "STATIM", Label: "Statim", Color: "#d32f2f"},
+		{Code: "VIP", Label: "VIP"},
+		{Code: "IMMOBILE", Label: "Immobile", Icon: "wheelchair"},
+	}
+	return config
+}
+
+func TestResolveSymbols_CanonicalizesCatalogSymbols(t *testing.T) {
+	config := getCatalogConfig()
+
+	resolved, err := config.ResolveSymbols([]string{"statim", " VIP ", "Statim"})
+	if err != nil {
+		t.Fatalf("ResolveSymbols failed: %v", err)
+	}
+	if want := []string{"STATIM", "VIP"}; !reflect.DeepEqual(resolved, want) {
+		t.Errorf("Expected %v, got %v", want, resolved)
+	}
+}
+
+func TestResolveSymbols_RejectsUnknownSymbols(t *testing.T) {
+	config := getCatalogConfig()
+
+	_, err := config.ResolveSymbols([]string{"STATIM", "STATUM"})
+	if !errors.Is(err, ErrUnknownSymbol) {
+		t.Fatalf("Expected ErrUnknownSymbol, got %v", err)
+	}
+}
+
+func TestResolveSymbols_WithoutCatalogAcceptsAnySymbol(t *testing.T) {
+	config := getTestConfig()
+
+	resolved, err := config.ResolveSymbols([]string{"ANYTHING"})
+	if err != nil {
+		t.Fatalf("ResolveSymbols failed: %v", err)
+	}
+	if want := []string{"ANYTHING"}; !reflect.DeepEqual(resolved, want) {
+		t.Errorf("Expected %v, got %v", want, resolved)
+	}
+}
+
+func TestAddSymbol(t *testing.T) {
+	config := getCatalogConfig()
+
+	if err := config.AddSymbol(Symbol{Code: " PREGNANT ", Label: "Pregnant"}); err != nil {
+		t.Fatalf("AddSymbol failed: %v", err)
+	}
+	if symbol := config.FindSymbol("pregnant"); symbol == nil || symbol.Code != "PREGNANT" {
+		t.Errorf("Expected PREGNANT in the catalog, got %+v", symbol)
+	}
+
+	if err := config.AddSymbol(Symbol{Code: "vip", Label: "Duplicate"}); !errors.Is(err, ErrInvalidSymbol) {
+		t.Errorf("Expected ErrInvalidSymbol for a taken code, got %v", err)
+	}
+	if err := config.AddSymbol(Symbol{Code: "NO LABEL"}); !errors.Is(err, ErrInvalidSymbol) {
+		t.Errorf("Expected ErrInvalidSymbol for an invalid symbol, got %v", err)
+	}
+}
+
+func TestUpdateSymbol_KeepsCode(t *testing.T) {
+	config := getCatalogConfig()
+
+	if err := config.UpdateSymbol("vip", Symbol{Code: "OTHER", Label: "Very important", Color: "#ffd700"}); err != nil {
+		t.Fatalf("UpdateSymbol failed: %v", err)
+	}
+	symbol := config.FindSymbol("VIP")
+	if symbol == nil || symbol.Label != "Very important" || symbol.Color != "#ffd700" {
+		t.Errorf("Expected the updated VIP symbol, got %+v", symbol)
+	}
+
+	if err := config.UpdateSymbol("UNKNOWN", Symbol{Label: "Unknown"}); !errors.Is(err, ErrSymbolNotFound) {
+		t.Errorf("Expected ErrSymbolNotFound, got %v", err)
+	}
+}
+
+func TestRemoveSymbol_RejectsSymbolsInUse(t *testing.T) {
+	config := getCatalogConfig()
+
+	if err := config.RemoveSymbol("STATIM"); !errors.Is(err, ErrSymbolInUse) {
+		t.Errorf("Expected ErrSymbolInUse for a tier symbol, got %v", err)
+	}
+	if err := config.AddSymbol(Symbol{Code: "UNUSED", Label: "Unused"}); err != nil {
+		t.Fatalf("AddSymbol failed: %v", err)
+	}
+	if err := config.RemoveSymbol("unused"); err != nil {
+		t.Fatalf("RemoveSymbol failed: %v", err)
+	}
+	if config.FindSymbol("UNUSED") != nil {
+		t.Error("Expected UNUSED to be removed")
+	}
+}
+
+func TestValidateSymbolReferences(t *testing.T) {
+	config := getCatalogConfig()
+	if err := config.ValidateSymbolReferences(); err != nil {
+		t.Fatalf("Expected a valid configuration, got %v", err)
+	}
+
+	config.PriorityModel.Tiers[0].Condition.SymbolsAnyOf = []string{"STATUM"}
+	if err := config.ValidateSymbolReferences(); !errors.Is(err, ErrUnknownSymbol) {
+		t.Errorf("Expected ErrUnknownSymbol for a tier typo, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package priority
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidConfig is returned when a priority configuration cannot be applied
+var ErrInvalidConfig = errors.New("invalid priority configuration")
+
+// Validate checks that a configuration can be used by the calculator: it has a version, at least
+// one tier, unique tier IDs and names and sensible fitness settings. All problems found are
+// reported in one error wrapping ErrInvalidConfig. The symbols of the rules are checked against
+// the catalog separately (see ValidateSymbolReferences), as the catalog may be built up one
+// symbol at a time.
+func (c *PriorityConfig) Validate() error {
+	var problems []string
+
+	if strings.TrimSpace(c.Version) == "" {
+		problems = append(problems, "version is required")
+	}
+
+	if len(c.PriorityModel.Tiers) == 0 {
+		problems = append(problems, "at least one tier is required")
+	}
+	tierIDs := make(map[int]bool)
+	tierNames := make(map[string]bool)
+	for _, tier := range c.PriorityModel.Tiers {
+		if tier.ID < 0 {
+			problems = append(problems, fmt.Sprintf("tier %s has a negative id", tier.Name))
+		}
+		if tierIDs[tier.ID] {
+			problems = append(problems, fmt.Sprintf("tier id %d is used more than once", tier.ID))
+		}
+		tierIDs[tier.ID] = true
+
+		name := strings.TrimSpace(tier.Name)
+		if name == "" {
+			problems = append(problems, fmt.Sprintf("tier %d needs a name", tier.ID))
+		} else if tierNames[name] {
+			problems = append(problems, fmt.Sprintf("tier name %s is used more than once", name))
+		}
+		tierNames[name] = true
+	}
+
+	contrib := c.PriorityModel.Fitness.Contributions
+	if contrib.Age.AgeThresholdSenior < 0 {
+		problems = append(problems, "ageThresholdSenior must not be negative")
+	}
+	if contrib.ManualOverride.Enabled && contrib.ManualOverride.Weight == 0 {
+		problems = append(problems, "an enabled manual override needs a weight")
+	}
+	problems = append(problems, c.validateSchedule()...)
+	problems = append(problems, c.validateStarvation()...)
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidConfig, strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package priority
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate_DefaultConfigIsValid(t *testing.T) {
+	if err := GetDefaultConfig().Validate(); err != nil {
+		t.Fatalf("Expected the default configuration to be valid, got %v", err)
+	}
+}
+
+func TestValidate_ReportsAllProblems(t *testing.T) {
+	config := getTestConfig()
+	config.Version = ""
+	config.PriorityModel.Tiers[1].ID = config.PriorityModel.Tiers[0].ID
+	config.PriorityModel.Fitness.Contributions.Age.AgeThresholdSenior = -1
+
+	err := config.Validate()
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+	for _, problem := range []string{"version is required", "tier id 0 is used more than once", "ageThresholdSenior"} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("Expected %q in %q", problem, err.Error())
+		}
+	}
+}
+
+func TestValidate_RequiresTiers(t *testing.T) {
+	config := getTestConfig()
+	config.PriorityModel.Tiers = nil
+
+	if err := config.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig without tiers, got %v", err)
+	}
+}
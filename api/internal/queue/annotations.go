@@ -0,0 +1,72 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// maxEntryTags is the number of tags an entry can carry
+const maxEntryTags = 20
+
+// ErrInvalidAnnotation is returned when notes or tags cannot be stored on an entry
+var ErrInvalidAnnotation = errors.New("invalid annotation")
+
+// AnnotateEntry changes the staff notes and tags of an entry of roomId, e.g. "needs wheelchair" or
+// "interpreter required". A nil notes or tags keeps the stored value; an empty one clears it.
+// Tags are trimmed and kept once, ignoring case.
+func (s *WaitingQueue) AnnotateEntry(ctx context.Context, roomId, entryId string, notes *string, tags []string, userID string) (*Entry, error) {
+	// The repositories report a missing entry as an error
+	entry, err := s.repo.GetEntryByID(ctx, entryId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEntryNotFound, err)
+	}
+	if entry == nil || entry.WaitingRoomID != roomId {
+		return nil, fmt.Errorf("%w: %s in room %s", ErrEntryNotFound, entryId, roomId)
+	}
+
+	newNotes := entry.Notes
+	if notes != nil {
+		newNotes = strings.TrimSpace(*notes)
+	}
+	newTags := entry.Tags
+	if tags != nil {
+		newTags = normalizeTags(tags)
+		if len(newTags) > maxEntryTags {
+			return nil, fmt.Errorf("%w: an entry can have at most %d tags", ErrInvalidAnnotation, maxEntryTags)
+		}
+	}
+
+	if err := s.repo.UpdateEntryAnnotations(ctx, entry.ID, newNotes, newTags, userID); err != nil {
+		return nil, fmt.Errorf("failed to update entry annotations: %w", err)
+	}
+
+	if updated, err := s.repo.GetEntryByID(ctx, entry.ID); err == nil && updated != nil {
+		entry = updated
+	} else {
+		entry.Notes = newNotes
+		entry.Tags = newTags
+		entry.AnnotatedBy = userID
+	}
+
+	log.Printf("[WaitingQueue] Annotated entry %s (ticket %s) in room %s: %d tags", entry.ID, entry.TicketNumber, roomId, len(entry.Tags))
+	return entry, nil
+}
+
+// normalizeTags trims the tags and drops empty and repeated ones
+func normalizeTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, tag)
+	}
+	return result
+}
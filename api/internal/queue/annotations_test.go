@@ -0,0 +1,55 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// TestAnnotateEntry tests the staff notes and tags of an entry
+func TestAnnotateEntry(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+	entry := &types.Entry{WaitingRoomID: "triage-1", Status: "WAITING"}
+	if err := mockRepo.CreateEntry(ctx, entry); err != nil {
+		t.Fatalf("CreateEntry failed: %v", err)
+	}
+
+	notes := " Arrived with a relative "
+	annotated, err := wq.AnnotateEntry(ctx, "triage-1", entry.ID, &notes, []string{"needs wheelchair", " interpreter required", "Needs Wheelchair", ""}, "nurse-1")
+	if err != nil {
+		t.Fatalf("AnnotateEntry failed: %v", err)
+	}
+	if annotated.Notes != "Arrived with a relative" || len(annotated.Tags) != 2 || annotated.Tags[1] != "interpreter required" {
+		t.Errorf("Expected trimmed notes and two tags, got '%s' %v", annotated.Notes, annotated.Tags)
+	}
+	if annotated.AnnotatedBy != "nurse-1" || annotated.AnnotatedAt == nil {
+		t.Errorf("Expected the annotation to be attributed to nurse-1, got '%s'", annotated.AnnotatedBy)
+	}
+
+	// Leaving out the notes keeps them; an empty tag list clears the tags
+	annotated, err = wq.AnnotateEntry(ctx, "triage-1", entry.ID, nil, []string{}, "nurse-2")
+	if err != nil {
+		t.Fatalf("AnnotateEntry failed: %v", err)
+	}
+	if annotated.Notes != "Arrived with a relative" || len(annotated.Tags) != 0 {
+		t.Errorf("Expected notes to stay and tags to be cleared, got '%s' %v", annotated.Notes, annotated.Tags)
+	}
+
+	if _, err := wq.AnnotateEntry(ctx, "xray", entry.ID, &notes, nil, "nurse-1"); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("Expected ErrEntryNotFound for another room, got %v", err)
+	}
+
+	tooMany := make([]string, maxEntryTags+1)
+	for i := range tooMany {
+		tooMany[i] = string(rune('a' + i))
+	}
+	if _, err := wq.AnnotateEntry(ctx, "triage-1", entry.ID, nil, tooMany, "nurse-1"); !errors.Is(err, ErrInvalidAnnotation) {
+		t.Errorf("Expected ErrInvalidAnnotation, got %v", err)
+	}
+}
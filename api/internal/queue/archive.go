@@ -0,0 +1,23 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ArchivedStatuses are the final statuses of entries that are moved to the archive
+var ArchivedStatuses = []string{"COMPLETED", "CANCELLED", "NO_SHOW"}
+
+// ArchiveEntries moves the entries of all tenants that reached one of ArchivedStatuses and were
+// last changed before the given time to the archive, so the queue only holds recent entries.
+// It returns the number of entries archived.
+func (s *WaitingQueue) ArchiveEntries(ctx context.Context, before time.Time) (int64, error) {
+	archived, err := s.repo.ArchiveEntries(ctx, before, ArchivedStatuses)
+	if err != nil {
+		return archived, fmt.Errorf("failed to archive entries: %w", err)
+	}
+	log.Printf("[WaitingQueue] Archived %d entries finished before %s", archived, before.Format(time.RFC3339))
+	return archived, nil
+}
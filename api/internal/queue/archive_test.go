@@ -0,0 +1,80 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// TestArchiveEntries tests that only old finished entries are moved to the archive and still count in the statistics
+func TestArchiveEntries(t *testing.T) {
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+	ctx := context.Background()
+
+	calledAt := time.Now().Add(-50 * 24 * time.Hour)
+	completedAt := calledAt.Add(5 * time.Minute)
+	oldCompleted := &types.Entry{WaitingRoomID: "triage-1", Status: "COMPLETED", CalledAt: &calledAt, CompletedAt: &completedAt}
+	oldNoShow := &types.Entry{WaitingRoomID: "triage-1", Status: "NO_SHOW"}
+	oldWaiting := &types.Entry{WaitingRoomID: "triage-1", Status: "WAITING"}
+	recentCompleted := &types.Entry{WaitingRoomID: "triage-1", Status: "COMPLETED"}
+	for _, entry := range []*types.Entry{oldCompleted, oldNoShow, oldWaiting, recentCompleted} {
+		if err := mockRepo.CreateEntry(ctx, entry); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+	}
+	for _, entry := range []*types.Entry{oldCompleted, oldNoShow, oldWaiting} {
+		entry.CreatedAt = calledAt.Add(-10 * time.Minute)
+		entry.UpdatedAt = completedAt
+	}
+
+	archived, err := wq.ArchiveEntries(ctx, time.Now().Add(-30*24*time.Hour))
+	if err != nil {
+		t.Fatalf("ArchiveEntries failed: %v", err)
+	}
+	if archived != 2 {
+		t.Fatalf("Expected 2 archived entries, got %d", archived)
+	}
+
+	for _, entry := range []*types.Entry{oldCompleted, oldNoShow} {
+		if _, err := mockRepo.GetEntryByID(ctx, entry.ID); err == nil {
+			t.Errorf("Expected entry %s to be removed from the queue", entry.ID)
+		}
+	}
+	for _, entry := range []*types.Entry{oldWaiting, recentCompleted} {
+		if _, err := mockRepo.GetEntryByID(ctx, entry.ID); err != nil {
+			t.Errorf("Expected entry %s to stay in the queue: %v", entry.ID, err)
+		}
+	}
+
+	from := calledAt.Add(-24 * time.Hour)
+	entries, err := mockRepo.GetArchivedEntries(ctx, from, time.Now(), "triage-1", 10)
+	if err != nil {
+		t.Fatalf("GetArchivedEntries failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 archived entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.ArchivedAt == nil {
+			t.Errorf("Expected archived entry %s to have archivedAt", entry.ID)
+		}
+	}
+
+	stats, err := mockRepo.GetEntryStats(ctx, from, completedAt.Add(24*time.Hour), "triage-1", time.UTC)
+	if err != nil {
+		t.Fatalf("GetEntryStats failed: %v", err)
+	}
+	var completed, noShows int64
+	for _, group := range stats {
+		completed += group.Completed
+		noShows += group.NoShows
+	}
+	if completed != 1 || noShows != 1 {
+		t.Errorf("Expected the archived entries in the statistics, got %d completed and %d no-shows", completed, noShows)
+	}
+}
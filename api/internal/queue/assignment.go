@@ -0,0 +1,165 @@
+package queue
+
+import (
+	"context"
+	"log"
+
+	"github.com/arfis/waiting-room/internal/service"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// ServicePointLoad is the work waiting for one service point of a room
+type ServicePointLoad struct {
+	ServicePointID string
+	// Waiting is the number of WAITING entries assigned to the service point
+	Waiting int
+	// ExpectedWaitSeconds is the expected duration of its assigned and current entries
+	ExpectedWaitSeconds int64
+}
+
+// AssignmentStrategy picks the service point a new entry waits for
+type AssignmentStrategy interface {
+	// Assign returns the index in loads of the service point to assign; turn counts the entries
+	// assigned in the room before
+	Assign(loads []ServicePointLoad, turn int) int
+}
+
+// NewAssignmentStrategy returns the strategy of a room's assignment, nil for entries that stay
+// unassigned
+func NewAssignmentStrategy(assignment types.AssignmentStrategy) AssignmentStrategy {
+	switch assignment {
+	case types.AssignmentRoundRobin:
+		return roundRobinAssignment{}
+	case types.AssignmentLeastLoaded:
+		return leastLoadedAssignment{}
+	case types.AssignmentShortestWait:
+		return shortestWaitAssignment{}
+	default:
+		return nil
+	}
+}
+
+// roundRobinAssignment assigns the service points in turn
+type roundRobinAssignment struct{}
+
+func (roundRobinAssignment) Assign(loads []ServicePointLoad, turn int) int {
+	return turn % len(loads)
+}
+
+// leastLoadedAssignment assigns the service point with the fewest waiting entries, the first
+// configured one on a tie
+type leastLoadedAssignment struct{}
+
+func (leastLoadedAssignment) Assign(loads []ServicePointLoad, turn int) int {
+	best := 0
+	for i, load := range loads {
+		if load.Waiting < loads[best].Waiting {
+			best = i
+		}
+	}
+	return best
+}
+
+// shortestWaitAssignment assigns the service point whose entries take the least time, the one
+// with fewer waiting entries on a tie
+type shortestWaitAssignment struct{}
+
+func (shortestWaitAssignment) Assign(loads []ServicePointLoad, turn int) int {
+	best := 0
+	for i, load := range loads {
+		if load.ExpectedWaitSeconds < loads[best].ExpectedWaitSeconds ||
+			(load.ExpectedWaitSeconds == loads[best].ExpectedWaitSeconds && load.Waiting < loads[best].Waiting) {
+			best = i
+		}
+	}
+	return best
+}
+
+// assignServicePoint returns the service point a new entry of roomId for serviceName is assigned
+// to by the room's assignment strategy, or "" when the room does not assign entries or none of its
+// open service points handles the service
+func (s *WaitingQueue) assignServicePoint(ctx context.Context, roomId, serviceName string) string {
+	if s.configService == nil {
+		return ""
+	}
+	rooms, err := s.configService.GetRoomsConfig(ctx)
+	if err != nil {
+		log.Printf("[WaitingQueue] Failed to get assignment of room %s, leaving entry unassigned: %v", roomId, err)
+		return ""
+	}
+	var room *types.RoomConfig
+	for i := range rooms {
+		if rooms[i].ID == roomId {
+			room = &rooms[i]
+			break
+		}
+	}
+	if room == nil {
+		return ""
+	}
+	strategy := NewAssignmentStrategy(room.Assignment)
+	if strategy == nil {
+		return ""
+	}
+
+	loads := s.servicePointLoads(ctx, room, serviceName)
+	if len(loads) == 0 {
+		return ""
+	}
+
+	s.assignMu.Lock()
+	key := service.GetTenantID(ctx) + "|" + roomId
+	turn := s.assignTurns[key]
+	s.assignTurns[key] = turn + 1
+	s.assignMu.Unlock()
+
+	servicePointId := loads[strategy.Assign(loads, turn)].ServicePointID
+	log.Printf("[WaitingQueue] Assigned new entry of room %s to service point %s (%s)", roomId, servicePointId, room.Assignment)
+	return servicePointId
+}
+
+// servicePointLoads returns the load of the open service points of room that handle serviceName,
+// in configuration order. While any service point of the room is claimed, unclaimed ones are left
+// out.
+func (s *WaitingQueue) servicePointLoads(ctx context.Context, room *types.RoomConfig, serviceName string) []ServicePointLoad {
+	states, err := s.GetRoomStates(ctx, room.ID)
+	if err != nil {
+		log.Printf("[WaitingQueue] Failed to get paused service points of room %s: %v", room.ID, err)
+	}
+
+	// Once staff claim service points, only the claimed ones get new entries
+	claimed := s.servicePointSvc != nil && s.servicePointSvc.HasActiveOperators(room.ID)
+
+	var loads []ServicePointLoad
+	index := make(map[string]int)
+	for _, sp := range room.ServicePoints {
+		if PausedState(states, sp.ID) != nil || !canServe(sp.Services, &Entry{ServiceName: serviceName}) {
+			continue
+		}
+		if claimed && !s.servicePointSvc.HasActiveOperator(room.ID, sp.ID) {
+			continue
+		}
+		index[sp.ID] = len(loads)
+		loads = append(loads, ServicePointLoad{ServicePointID: sp.ID})
+	}
+	if len(loads) == 0 {
+		return nil
+	}
+
+	entries, err := s.repo.GetQueueEntries(ctx, room.ID, []string{"WAITING", "CALLED", "IN_ROOM", "IN_SERVICE"})
+	if err != nil {
+		log.Printf("[WaitingQueue] Failed to get the load of the service points of room %s: %v", room.ID, err)
+		return loads
+	}
+	for _, entry := range entries {
+		i, ok := index[entry.ServicePoint]
+		if !ok {
+			continue
+		}
+		if entry.Status == "WAITING" {
+			loads[i].Waiting++
+		}
+		loads[i].ExpectedWaitSeconds += entry.ApproximateDurationSeconds
+	}
+	return loads
+}
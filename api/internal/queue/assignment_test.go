@@ -0,0 +1,83 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+func TestAssignmentStrategies(t *testing.T) {
+	loads := []ServicePointLoad{
+		{ServicePointID: "window-1", Waiting: 3, ExpectedWaitSeconds: 600},
+		{ServicePointID: "window-2", Waiting: 1, ExpectedWaitSeconds: 900},
+		{ServicePointID: "window-3", Waiting: 2, ExpectedWaitSeconds: 300},
+	}
+
+	tests := []struct {
+		assignment types.AssignmentStrategy
+		turn       int
+		want       string
+	}{
+		{types.AssignmentRoundRobin, 0, "window-1"},
+		{types.AssignmentRoundRobin, 4, "window-2"},
+		{types.AssignmentLeastLoaded, 0, "window-2"},
+		{types.AssignmentShortestWait, 0, "window-3"},
+	}
+	for _, tt := range tests {
+		got := loads[NewAssignmentStrategy(tt.assignment).Assign(loads, tt.turn)].ServicePointID
+		if got != tt.want {
+			t.Errorf("%s at turn %d assigned %s, want %s", tt.assignment, tt.turn, got, tt.want)
+		}
+	}
+
+	if NewAssignmentStrategy(types.AssignmentNone) != nil || NewAssignmentStrategy("") != nil {
+		t.Error("Expected no strategy for rooms that leave entries unassigned")
+	}
+}
+
+// TestCreateEntryAssignsServicePoint tests that new entries are spread over the service points
+func TestCreateEntryAssignsServicePoint(t *testing.T) {
+	configService := &stubConfigService{rooms: []types.RoomConfig{{
+		ID:         "triage-1",
+		Assignment: types.AssignmentLeastLoaded,
+		ServicePoints: []types.ServicePointConfig{
+			{ID: "window-1"},
+			{ID: "window-2"},
+			{ID: "xray", Services: []string{"X-ray"}},
+		},
+	}}}
+
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+	wq.SetConfigService(configService)
+	ctx := context.Background()
+
+	var assigned []string
+	var entries []*Entry
+	for i := 0; i < 4; i++ {
+		entry, err := wq.CreateEntry(ctx, "triage-1", CardData{}, 300, "Consultation", nil, nil, nil, nil, "")
+		if err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+		assigned = append(assigned, entry.ServicePoint)
+		entries = append(entries, entry)
+	}
+	want := []string{"window-1", "window-2", "window-1", "window-2"}
+	for i := range want {
+		if assigned[i] != want[i] {
+			t.Fatalf("Expected assignments %v, got %v", want, assigned)
+		}
+	}
+
+	// A service point calls its own entries first, not the longer waiting one of window-1
+	called, err := wq.CallNextForServicePoint(ctx, "triage-1", "window-2")
+	if err != nil {
+		t.Fatalf("CallNextForServicePoint failed: %v", err)
+	}
+	if called.ID != entries[1].ID {
+		t.Errorf("Expected window-2 to call ticket %s assigned to it, got %s", entries[1].TicketNumber, called.TicketNumber)
+	}
+}
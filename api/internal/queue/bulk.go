@@ -0,0 +1,82 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// ErrInvalidBulkOperation is returned when a bulk operation cannot run with the given arguments
+var ErrInvalidBulkOperation = errors.New("invalid bulk operation")
+
+// ClearWaiting cancels every WAITING entry of a room, e.g. at the end of the day
+func (s *WaitingQueue) ClearWaiting(ctx context.Context, roomId string) (int64, error) {
+	affected := s.entriesForHistory(ctx, roomId, "WAITING")
+	cleared, err := s.repo.BulkUpdateStatus(ctx, roomId, []string{"WAITING"}, "CANCELLED")
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear waiting entries: %w", err)
+	}
+	s.recordBulkTransition(ctx, affected, "CANCELLED", roomId, "cleared")
+	log.Printf("[WaitingQueue] Cancelled %d waiting entries in room %s", cleared, roomId)
+	return cleared, nil
+}
+
+// MarkCalledAsNoShow marks every CALLED entry of a room as NO_SHOW
+func (s *WaitingQueue) MarkCalledAsNoShow(ctx context.Context, roomId string) (int64, error) {
+	affected := s.entriesForHistory(ctx, roomId, "CALLED")
+	marked, err := s.repo.BulkUpdateStatus(ctx, roomId, []string{"CALLED"}, "NO_SHOW")
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark called entries as no-show: %w", err)
+	}
+	s.recordBulkTransition(ctx, affected, "NO_SHOW", roomId, "no_show")
+	log.Printf("[WaitingQueue] Marked %d called entries in room %s as no-show", marked, roomId)
+	return marked, nil
+}
+
+// MoveWaitingEntries moves every WAITING entry of a room to targetRoomId, e.g. when a room closes.
+// The entries keep their tickets and priority and may be called by any service point there.
+func (s *WaitingQueue) MoveWaitingEntries(ctx context.Context, roomId, targetRoomId string) (int64, error) {
+	if targetRoomId == "" || targetRoomId == roomId {
+		return 0, fmt.Errorf("%w: a target room other than %s is required", ErrInvalidBulkOperation, roomId)
+	}
+
+	affected := s.entriesForHistory(ctx, roomId, "WAITING")
+	moved, err := s.repo.MoveWaitingEntries(ctx, roomId, targetRoomId)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move waiting entries: %w", err)
+	}
+	s.recordBulkTransition(ctx, affected, "WAITING", targetRoomId, "transferred")
+	log.Printf("[WaitingQueue] Moved %d waiting entries from room %s to room %s", moved, roomId, targetRoomId)
+	return moved, nil
+}
+
+// entriesForHistory returns the entries of a room in the given status that a bulk operation is
+// about to change, or nil when no history is kept
+func (s *WaitingQueue) entriesForHistory(ctx context.Context, roomId, status string) []*Entry {
+	if s.historyRepo == nil {
+		return nil
+	}
+	entries, err := s.repo.GetQueueEntries(ctx, roomId, []string{status})
+	if err != nil {
+		log.Printf("[WaitingQueue] Failed to get %s entries of room %s for their history: %v", status, roomId, err)
+		return nil
+	}
+	// Copy the entries; the bulk update may change the ones the repository returned
+	copies := make([]*Entry, 0, len(entries))
+	for _, entry := range entries {
+		copied := *entry
+		copies = append(copies, &copied)
+	}
+	return copies
+}
+
+// recordBulkTransition records the move of every entry to toStatus in roomId
+func (s *WaitingQueue) recordBulkTransition(ctx context.Context, entries []*Entry, toStatus, roomId, event string) {
+	for _, entry := range entries {
+		fromStatus := entry.Status
+		entry.Status = toStatus
+		entry.WaitingRoomID = roomId
+		s.recordTransition(ctx, entry, fromStatus, event)
+	}
+}
@@ -0,0 +1,88 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// TestBulkOperations tests the bulk actions on a room's queue
+func TestBulkOperations(t *testing.T) {
+	setup := func(t *testing.T) (*WaitingQueue, *repository.MockQueueRepository) {
+		mockRepo := repository.NewMockQueueRepository()
+		wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+		for _, entry := range []*types.Entry{
+			{WaitingRoomID: "triage-1", Status: "WAITING"},
+			{WaitingRoomID: "triage-1", Status: "WAITING"},
+			{WaitingRoomID: "triage-1", Status: "CALLED", ServicePoint: "window-1"},
+			{WaitingRoomID: "triage-1", Status: "COMPLETED"},
+			{WaitingRoomID: "xray", Status: "WAITING"},
+		} {
+			if err := mockRepo.CreateEntry(context.Background(), entry); err != nil {
+				t.Fatalf("CreateEntry failed: %v", err)
+			}
+		}
+		return wq, mockRepo
+	}
+
+	countStatus := func(repo *repository.MockQueueRepository, roomId, status string) int {
+		entries, _ := repo.GetQueueEntries(context.Background(), roomId, []string{status})
+		return len(entries)
+	}
+
+	t.Run("clear waiting cancels only waiting entries", func(t *testing.T) {
+		wq, repo := setup(t)
+		cleared, err := wq.ClearWaiting(context.Background(), "triage-1")
+		if err != nil || cleared != 2 {
+			t.Fatalf("Expected 2 cleared entries, got %d, %v", cleared, err)
+		}
+		if countStatus(repo, "triage-1", "CANCELLED") != 2 || countStatus(repo, "triage-1", "CALLED") != 1 {
+			t.Error("Expected the waiting entries to be cancelled and the called one to stay")
+		}
+		if countStatus(repo, "xray", "WAITING") != 1 {
+			t.Error("Expected other rooms to be untouched")
+		}
+	})
+
+	t.Run("called entries become no-shows", func(t *testing.T) {
+		wq, repo := setup(t)
+		marked, err := wq.MarkCalledAsNoShow(context.Background(), "triage-1")
+		if err != nil || marked != 1 {
+			t.Fatalf("Expected 1 no-show, got %d, %v", marked, err)
+		}
+		if countStatus(repo, "triage-1", "NO_SHOW") != 1 || countStatus(repo, "triage-1", "WAITING") != 2 {
+			t.Error("Expected only the called entry to become a no-show")
+		}
+	})
+
+	t.Run("waiting entries move to another room", func(t *testing.T) {
+		wq, repo := setup(t)
+		moved, err := wq.MoveWaitingEntries(context.Background(), "triage-1", "xray")
+		if err != nil || moved != 2 {
+			t.Fatalf("Expected 2 moved entries, got %d, %v", moved, err)
+		}
+		entries, _ := repo.GetQueueEntries(context.Background(), "xray", []string{"WAITING"})
+		if len(entries) != 3 {
+			t.Fatalf("Expected 3 waiting entries in xray, got %d", len(entries))
+		}
+		for i, entry := range entries {
+			if entry.Position != int64(i+1) {
+				t.Errorf("Expected position %d, got %d", i+1, entry.Position)
+			}
+		}
+		if countStatus(repo, "triage-1", "CALLED") != 1 {
+			t.Error("Expected the called entry to stay in triage-1")
+		}
+	})
+
+	t.Run("moving to the same room is rejected", func(t *testing.T) {
+		wq, _ := setup(t)
+		if _, err := wq.MoveWaitingEntries(context.Background(), "triage-1", "triage-1"); !errors.Is(err, ErrInvalidBulkOperation) {
+			t.Errorf("Expected ErrInvalidBulkOperation, got %v", err)
+		}
+	})
+}
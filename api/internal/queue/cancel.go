@@ -0,0 +1,74 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/arfis/waiting-room/internal/middleware"
+)
+
+// ErrCannotCancel is returned when a patient cancels an entry that is no longer waiting
+var ErrCannotCancel = errors.New("entry cannot be cancelled")
+
+// CancelEntryByQRToken cancels the WAITING entry of a QR token on behalf of the patient (phone
+// status page). The phone page sends no tenant header, so the entry's own tenant is used to
+// recalculate the positions of its room.
+func (s *WaitingQueue) CancelEntryByQRToken(ctx context.Context, qrToken string) (*Entry, error) {
+	// The repositories report an unknown token as an error
+	entry, err := s.repo.GetEntryByQRToken(ctx, qrToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEntryNotFound, err)
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("%w: unknown QR token", ErrEntryNotFound)
+	}
+	if entry.Status != "WAITING" {
+		return nil, fmt.Errorf("%w: entry %s is %s", ErrCannotCancel, entry.ID, entry.Status)
+	}
+
+	tenantCtx := context.WithValue(ctx, middleware.TENANT, EntryTenantID(entry))
+	if err := s.repo.UpdateEntryStatus(tenantCtx, entry.ID, "CANCELLED", entry.Version); err != nil {
+		return nil, fmt.Errorf("failed to cancel entry: %w", err)
+	}
+	if err := s.repo.RecalculatePositions(tenantCtx, entry.WaitingRoomID); err != nil {
+		log.Printf("Warning: Failed to recalculate positions after cancelling entry: %v", err)
+	}
+
+	entry.Status = "CANCELLED"
+	s.recordTransition(tenantCtx, entry, "WAITING", "cancelled_by_patient")
+
+	log.Printf("[WaitingQueue] Patient cancelled entry %s (ticket %s) in room %s", entry.ID, entry.TicketNumber, entry.WaitingRoomID)
+	return entry, nil
+}
+
+// CancelEntry cancels a WAITING or CALLED entry of the tenant in the context on behalf of an external
+// system, e.g. when the hospital system cancels the visit
+func (s *WaitingQueue) CancelEntry(ctx context.Context, entryId string) (*Entry, error) {
+	// The repositories report a missing entry as an error
+	entry, err := s.repo.GetEntryByID(ctx, entryId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEntryNotFound, err)
+	}
+	if entry == nil || !sameTenant(ctx, entry) {
+		return nil, fmt.Errorf("%w: %s", ErrEntryNotFound, entryId)
+	}
+	if entry.Status != "WAITING" && entry.Status != "CALLED" {
+		return nil, fmt.Errorf("%w: entry %s is %s", ErrCannotCancel, entry.ID, entry.Status)
+	}
+
+	fromStatus := entry.Status
+	if err := s.repo.UpdateEntryStatus(ctx, entry.ID, "CANCELLED", entry.Version); err != nil {
+		return nil, fmt.Errorf("failed to cancel entry: %w", err)
+	}
+	if err := s.repo.RecalculatePositions(ctx, entry.WaitingRoomID); err != nil {
+		log.Printf("Warning: Failed to recalculate positions after cancelling entry: %v", err)
+	}
+
+	entry.Status = "CANCELLED"
+	s.recordTransition(ctx, entry, fromStatus, "cancelled_by_integration")
+
+	log.Printf("[WaitingQueue] Cancelled entry %s (ticket %s) in room %s for an external system", entry.ID, entry.TicketNumber, entry.WaitingRoomID)
+	return entry, nil
+}
@@ -0,0 +1,79 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/repository"
+)
+
+// TestCancelEntryByQRToken tests that a patient can cancel a waiting entry and the others move up
+func TestCancelEntryByQRToken(t *testing.T) {
+	ctx := context.WithValue(context.Background(), middleware.TENANT, "hospital-a:cardiology")
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+
+	first, err := wq.CreateEntry(ctx, "triage-1", CardData{IDNumber: "1"}, 300, "Service", nil, nil, nil, nil, "")
+	if err != nil {
+		t.Fatalf("CreateEntry failed: %v", err)
+	}
+	second, err := wq.CreateEntry(ctx, "triage-1", CardData{IDNumber: "2"}, 300, "Service", nil, nil, nil, nil, "")
+	if err != nil {
+		t.Fatalf("CreateEntry failed: %v", err)
+	}
+
+	// The phone page has no tenant header
+	cancelled, err := wq.CancelEntryByQRToken(context.Background(), first.QRToken)
+	if err != nil {
+		t.Fatalf("CancelEntryByQRToken failed: %v", err)
+	}
+	if cancelled.Status != "CANCELLED" {
+		t.Errorf("Expected status CANCELLED, got %s", cancelled.Status)
+	}
+
+	waiting, err := wq.GetQueueEntriesWithContext(ctx, "triage-1", []string{"WAITING"})
+	if err != nil {
+		t.Fatalf("GetQueueEntriesWithContext failed: %v", err)
+	}
+	if len(waiting) != 1 || waiting[0].ID != second.ID || waiting[0].Position != 1 {
+		t.Errorf("Expected only the second entry at position 1, got %d entries", len(waiting))
+	}
+
+	if _, err := wq.CancelEntryByQRToken(context.Background(), first.QRToken); !errors.Is(err, ErrCannotCancel) {
+		t.Errorf("Expected ErrCannotCancel for a cancelled entry, got %v", err)
+	}
+	if _, err := wq.CancelEntryByQRToken(context.Background(), "unknown"); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("Expected ErrEntryNotFound for an unknown token, got %v", err)
+	}
+}
+
+// TestCancelEntry tests that an external system can cancel waiting and called entries of its tenant only
+func TestCancelEntry(t *testing.T) {
+	ctx := context.WithValue(context.Background(), middleware.TENANT, "hospital-a:cardiology")
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+
+	entry, err := wq.CreateEntry(ctx, "triage-1", CardData{IDNumber: "1"}, 300, "Service", nil, nil, nil, nil, "")
+	if err != nil {
+		t.Fatalf("CreateEntry failed: %v", err)
+	}
+
+	otherCtx := context.WithValue(context.Background(), middleware.TENANT, "hospital-b")
+	if _, err := wq.CancelEntry(otherCtx, entry.ID); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("Expected ErrEntryNotFound for another tenant, got %v", err)
+	}
+
+	cancelled, err := wq.CancelEntry(ctx, entry.ID)
+	if err != nil {
+		t.Fatalf("CancelEntry failed: %v", err)
+	}
+	if cancelled.Status != "CANCELLED" {
+		t.Errorf("Expected status CANCELLED, got %s", cancelled.Status)
+	}
+	if _, err := wq.CancelEntry(ctx, entry.ID); !errors.Is(err, ErrCannotCancel) {
+		t.Errorf("Expected ErrCannotCancel for a cancelled entry, got %v", err)
+	}
+}
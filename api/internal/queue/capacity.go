@@ -0,0 +1,85 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// ErrQueueFull is returned when a room already holds as many WAITING entries as it takes
+var ErrQueueFull = errors.New("queue is full")
+
+// QueueFullError reports a full room and, when its overflow room still has space, the room to
+// suggest to the patient instead. It matches ErrQueueFull with errors.Is.
+type QueueFullError struct {
+	RoomID          string
+	MaxWaiting      int
+	SuggestedRoomID string
+}
+
+func (e *QueueFullError) Error() string {
+	return fmt.Sprintf("%s: room %s has %d waiting entries", ErrQueueFull, e.RoomID, e.MaxWaiting)
+}
+
+func (e *QueueFullError) Unwrap() error {
+	return ErrQueueFull
+}
+
+// roomCapacity returns the WAITING limit (0 = unlimited) and overflow room of roomId. Rooms without
+// a limit of their own use queue.max_waiting_per_room.
+func (s *WaitingQueue) roomCapacity(ctx context.Context, roomId string) (int, string) {
+	maxWaiting := 0
+	if s.config != nil {
+		maxWaiting = s.config.Queue.MaxWaitingPerRoom
+	}
+	if s.configService == nil {
+		return maxWaiting, ""
+	}
+
+	rooms, err := s.configService.GetRoomsConfig(ctx)
+	if err != nil {
+		log.Printf("[WaitingQueue] Failed to get room capacity of %s, using the default: %v", roomId, err)
+		return maxWaiting, ""
+	}
+	for _, room := range rooms {
+		if room.ID == roomId {
+			if room.MaxWaiting > 0 {
+				maxWaiting = room.MaxWaiting
+			}
+			return maxWaiting, room.OverflowRoomID
+		}
+	}
+	return maxWaiting, ""
+}
+
+// checkCapacity returns a *QueueFullError when roomId, with waiting WAITING entries, takes no more
+func (s *WaitingQueue) checkCapacity(ctx context.Context, roomId string, waiting int) error {
+	maxWaiting, overflowRoomId := s.roomCapacity(ctx, roomId)
+	if maxWaiting <= 0 || waiting < maxWaiting {
+		return nil
+	}
+
+	fullErr := &QueueFullError{RoomID: roomId, MaxWaiting: maxWaiting}
+	if overflowRoomId != "" && overflowRoomId != roomId && s.hasCapacity(ctx, overflowRoomId) {
+		fullErr.SuggestedRoomID = overflowRoomId
+	}
+	return fullErr
+}
+
+// hasCapacity reports whether roomId is open and below its WAITING limit
+func (s *WaitingQueue) hasCapacity(ctx context.Context, roomId string) bool {
+	if state, err := s.pausedState(ctx, roomId, ""); err != nil || state != nil {
+		return false
+	}
+	maxWaiting, _ := s.roomCapacity(ctx, roomId)
+	if maxWaiting <= 0 {
+		return true
+	}
+	entries, err := s.repo.GetQueueEntries(ctx, roomId, []string{"WAITING"})
+	if err != nil {
+		log.Printf("[WaitingQueue] Failed to count waiting entries of overflow room %s: %v", roomId, err)
+		return false
+	}
+	return len(entries) < maxWaiting
+}
@@ -0,0 +1,56 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// TestQueueCapacity tests that a full room rejects new entries and suggests its overflow room while it has space
+func TestQueueCapacity(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{Queue: config.QueueConfig{MaxWaitingPerRoom: 1}}
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, cfg, nil, nil)
+	wq.SetConfigService(&stubConfigService{rooms: []types.RoomConfig{
+		{ID: "triage-1", MaxWaiting: 2, OverflowRoomID: "triage-2"},
+		{ID: "triage-2"},
+	}})
+
+	for i, idNumber := range []string{"1", "2"} {
+		if _, err := wq.CreateEntry(ctx, "triage-1", CardData{IDNumber: idNumber}, 300, "Service", nil, nil, nil, nil, ""); err != nil {
+			t.Fatalf("CreateEntry %d failed: %v", i+1, err)
+		}
+	}
+
+	// The third patient is rejected; triage-2 uses the default limit of 1 and is empty
+	_, err := wq.CreateEntry(ctx, "triage-1", CardData{IDNumber: "3"}, 300, "Service", nil, nil, nil, nil, "")
+	var fullErr *QueueFullError
+	if !errors.As(err, &fullErr) || !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Expected a QueueFullError, got %v", err)
+	}
+	if fullErr.MaxWaiting != 2 || fullErr.SuggestedRoomID != "triage-2" {
+		t.Errorf("Expected limit 2 and suggestion triage-2, got %d and '%s'", fullErr.MaxWaiting, fullErr.SuggestedRoomID)
+	}
+
+	// Once the overflow room is full too nothing is suggested
+	if _, err := wq.CreateEntry(ctx, "triage-2", CardData{IDNumber: "3"}, 300, "Service", nil, nil, nil, nil, ""); err != nil {
+		t.Fatalf("CreateEntry in triage-2 failed: %v", err)
+	}
+	_, err = wq.CreateEntry(ctx, "triage-1", CardData{IDNumber: "4"}, 300, "Service", nil, nil, nil, nil, "")
+	if !errors.As(err, &fullErr) || fullErr.SuggestedRoomID != "" {
+		t.Errorf("Expected a QueueFullError without suggestion, got %v", err)
+	}
+
+	// Calling an entry frees a place
+	if _, err := wq.CallNext(ctx, "triage-1"); err != nil {
+		t.Fatalf("CallNext failed: %v", err)
+	}
+	if _, err := wq.CreateEntry(ctx, "triage-1", CardData{IDNumber: "4"}, 300, "Service", nil, nil, nil, nil, ""); err != nil {
+		t.Errorf("Expected the entry to join after a call, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/repository"
+)
+
+// changeStreamRetryInterval is how long changes are polled after a change stream failed before
+// streaming is tried again
+const changeStreamRetryInterval = time.Minute
+
+// WatchChanges calls onChange with the room and tenant of every changed entry until ctx is done,
+// so clients also see edits made outside the API. Entries of a section are reported for the
+// section ("building:section") and for the building, both of which clients subscribe to.
+// Repositories that stream their changes are watched; the others, and streams that fail, are
+// polled every pollInterval. Deleted entries are not reported.
+func (s *WaitingQueue) WatchChanges(ctx context.Context, pollInterval time.Duration, onChange func(roomId, tenantID string)) {
+	notify := func(entry *Entry) {
+		onChange(entry.WaitingRoomID, EntryTenantID(entry))
+		if entry.SectionID != "" {
+			onChange(entry.WaitingRoomID, entry.TenantID)
+		}
+	}
+
+	watcher, canWatch := s.repo.(repository.QueueChangeWatcher)
+	for ctx.Err() == nil {
+		since := time.Now()
+		if !canWatch {
+			s.pollChanges(ctx, since, pollInterval, 0, notify)
+			return
+		}
+
+		err := watcher.WatchEntryChanges(ctx, notify)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("[WaitingQueue] Change stream unavailable, polling every %s: %v", pollInterval, err)
+		s.pollChanges(ctx, since, pollInterval, changeStreamRetryInterval, notify)
+	}
+}
+
+// pollChanges reports the entries updated since the last poll, each room and tenant once per poll,
+// until ctx is done or, when positive, stopAfter has passed
+func (s *WaitingQueue) pollChanges(ctx context.Context, since time.Time, interval, stopAfter time.Duration, notify func(entry *Entry)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var stop <-chan time.Time
+	if stopAfter > 0 {
+		stop = time.After(stopAfter)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		polledAt := time.Now()
+		entries, err := s.repo.GetEntriesUpdatedSince(ctx, since)
+		if err != nil {
+			log.Printf("[WaitingQueue] Failed to poll changed entries: %v", err)
+			continue
+		}
+		since = polledAt
+
+		reported := make(map[string]bool)
+		for _, entry := range entries {
+			key := entry.WaitingRoomID + "|" + EntryTenantID(entry)
+			if reported[key] {
+				continue
+			}
+			reported[key] = true
+			notify(entry)
+		}
+	}
+}
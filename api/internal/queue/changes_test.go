@@ -0,0 +1,61 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// TestWatchChangesPolling tests that repositories without a change stream are polled and every changed room is reported for its section and building
+func TestWatchChangesPolling(t *testing.T) {
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	changed := make(map[string]int)
+	go wq.WatchChanges(ctx, 10*time.Millisecond, func(roomId, tenantID string) {
+		mu.Lock()
+		defer mu.Unlock()
+		changed[roomId+"|"+tenantID]++
+	})
+
+	time.Sleep(20 * time.Millisecond)
+	for _, entry := range []*types.Entry{
+		{WaitingRoomID: "triage-1", TenantID: "hospital", SectionID: "er", Status: "WAITING"},
+		{WaitingRoomID: "triage-1", TenantID: "hospital", SectionID: "er", Status: "WAITING"},
+		{WaitingRoomID: "lab", TenantID: "clinic", Status: "WAITING"},
+	} {
+		if err := mockRepo.CreateEntry(ctx, entry); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		done := len(changed) == 3
+		mu.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, key := range []string{"triage-1|hospital:er", "triage-1|hospital", "lab|clinic"} {
+		if changed[key] == 0 {
+			t.Errorf("Expected a change of %s, got %v", key, changed)
+		}
+	}
+	if changed["triage-1|hospital:er"] > 2 {
+		t.Errorf("Expected the room to be reported once per poll, got %d reports", changed["triage-1|hospital:er"])
+	}
+}
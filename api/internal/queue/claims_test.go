@@ -0,0 +1,62 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	appContext "github.com/arfis/waiting-room/internal/context"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/service/servicepoint"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// TestCallNextRequiresClaim tests that only claimed service points call patients and get new entries
+func TestCallNextRequiresClaim(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Queue.RequireServicePointClaim = true
+	servicePointSvc := servicepoint.NewService(cfg)
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, cfg, servicePointSvc, nil)
+	wq.SetConfigService(&stubConfigService{rooms: []types.RoomConfig{{
+		ID:            "triage-1",
+		Assignment:    types.AssignmentRoundRobin,
+		ServicePoints: []types.ServicePointConfig{{ID: "window-1"}, {ID: "window-2"}},
+	}}})
+	ctx := context.Background()
+	staffCtx := context.WithValue(ctx, appContext.USER_ID, "nurse-1")
+
+	if _, err := wq.CreateEntry(ctx, "triage-1", CardData{IDNumber: "1"}, 300, "Service", nil, nil, nil, nil, ""); err != nil {
+		t.Fatalf("CreateEntry failed: %v", err)
+	}
+	if _, err := wq.CallNextForServicePoint(staffCtx, "triage-1", "window-2"); !errors.Is(err, ErrServicePointNotClaimed) {
+		t.Fatalf("Expected ErrServicePointNotClaimed, got %v", err)
+	}
+
+	if _, err := servicePointSvc.ClaimServicePoint(staffCtx, "triage-1", "window-2"); err != nil {
+		t.Fatalf("ClaimServicePoint failed: %v", err)
+	}
+	if _, err := servicePointSvc.ClaimServicePoint(context.WithValue(ctx, appContext.USER_ID, "nurse-2"), "triage-1", "window-2"); err == nil {
+		t.Error("Expected a service point served by nurse-1 not to be claimable by nurse-2")
+	}
+
+	// Only the claimed service point gets new entries
+	entry, err := wq.CreateEntry(ctx, "triage-1", CardData{IDNumber: "2"}, 300, "Service", nil, nil, nil, nil, "")
+	if err != nil {
+		t.Fatalf("CreateEntry failed: %v", err)
+	}
+	if entry.ServicePoint != "window-2" {
+		t.Errorf("Expected the entry to be assigned to the claimed window-2, got %q", entry.ServicePoint)
+	}
+	if _, err := wq.CallNextForServicePoint(staffCtx, "triage-1", "window-2"); err != nil {
+		t.Fatalf("CallNextForServicePoint failed: %v", err)
+	}
+
+	if _, err := servicePointSvc.ReleaseServicePoint(staffCtx, "triage-1", "window-2"); err != nil {
+		t.Fatalf("ReleaseServicePoint failed: %v", err)
+	}
+	if _, err := wq.CallNextForServicePoint(staffCtx, "triage-1", "window-2"); !errors.Is(err, ErrServicePointNotClaimed) {
+		t.Errorf("Expected ErrServicePointNotClaimed after the release, got %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/service"
+)
+
+// ErrInvalidContact is returned for a notification phone number or email that cannot be used
+var ErrInvalidContact = errors.New("invalid notification contact")
+
+// ErrCannotRegisterContact is returned when a patient registers a contact for an entry that is no longer waiting
+var ErrCannotRegisterContact = errors.New("contact cannot be registered")
+
+// e164Pattern matches phone numbers in E.164 format, e.g. +421901234567
+var e164Pattern = regexp.MustCompile(`^\+[1-9]\d{6,14}$`)
+
+// NormalizeContact validates a notification phone number and email and returns them trimmed, with
+// the spaces, dashes and parentheses removed from the phone number. Both may be empty.
+func NormalizeContact(phone, email string) (string, string, error) {
+	phone = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '(' || r == ')' {
+			return -1
+		}
+		return r
+	}, phone)
+	if phone != "" && !e164Pattern.MatchString(phone) {
+		return "", "", fmt.Errorf("%w: phone number must be in international format, e.g. +421901234567", ErrInvalidContact)
+	}
+
+	email = strings.TrimSpace(email)
+	if email != "" {
+		address, err := mail.ParseAddress(email)
+		if err != nil || address.Address != email {
+			return "", "", fmt.Errorf("%w: invalid email address", ErrInvalidContact)
+		}
+	}
+	return phone, email, nil
+}
+
+// RegisterContactByQRToken sets the phone number and email the patient of the WAITING entry of a
+// QR token wants to be notified at; both empty removes them. Like CancelEntryByQRToken it uses the
+// entry's own tenant.
+func (s *WaitingQueue) RegisterContactByQRToken(ctx context.Context, qrToken, phone, email string) (*Entry, error) {
+	phone, email, err := NormalizeContact(phone, email)
+	if err != nil {
+		return nil, err
+	}
+
+	// The repositories report an unknown token as an error
+	entry, err := s.repo.GetEntryByQRToken(ctx, qrToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEntryNotFound, err)
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("%w: unknown QR token", ErrEntryNotFound)
+	}
+	if entry.Status != "WAITING" {
+		return nil, fmt.Errorf("%w: entry %s is %s", ErrCannotRegisterContact, entry.ID, entry.Status)
+	}
+
+	tenantCtx := context.WithValue(ctx, middleware.TENANT, EntryTenantID(entry))
+	if err := s.repo.UpdateEntryContact(tenantCtx, entry.ID, phone, email); err != nil {
+		return nil, fmt.Errorf("failed to update entry contact: %w", err)
+	}
+	entry.NotifyPhone = phone
+	entry.NotifyEmail = email
+
+	log.Printf("[WaitingQueue] Patient of entry %s (ticket %s) registered for notifications", entry.ID, entry.TicketNumber)
+	return entry, nil
+}
+
+// ApproachingEntries returns the WAITING entries of a room with a contact whose patients have not
+// been told yet that at most peopleAhead others wait before them, and records that they are now.
+// Each entry is only returned once, also across replicas. Only entries of the tenant in the context
+// itself are returned, the sections of a building are left to their own settings.
+func (s *WaitingQueue) ApproachingEntries(ctx context.Context, roomId string, peopleAhead int) ([]*Entry, error) {
+	if peopleAhead <= 0 {
+		return nil, nil
+	}
+	entries, err := s.repo.GetQueueEntries(ctx, roomId, []string{"WAITING"})
+	if err != nil {
+		return nil, err
+	}
+
+	tenantID := service.GetTenantID(ctx)
+	var approaching []*Entry
+	for _, entry := range entries {
+		if entry.NotifyPhone == "" && entry.NotifyEmail == "" {
+			continue
+		}
+		if tenantID != "" && EntryTenantID(entry) != tenantID {
+			continue
+		}
+		if entry.ApproachNotifiedAt != nil || entry.Position-1 > int64(peopleAhead) {
+			continue
+		}
+		marked, err := s.repo.MarkEntryApproachNotified(ctx, entry.ID)
+		if err != nil {
+			log.Printf("Warning: Failed to mark entry %s approach notified: %v", entry.ID, err)
+			continue
+		}
+		if marked {
+			approaching = append(approaching, entry)
+		}
+	}
+	return approaching, nil
+}
+
+// TakeEntryContact returns a called entry and removes its contact and push subscription, which are
+// not needed anymore once the patient was told they are called. Entries without either are
+// returned as they are.
+func (s *WaitingQueue) TakeEntryContact(ctx context.Context, entryId string) (*Entry, error) {
+	entry, err := s.repo.GetEntryByID(ctx, entryId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEntryNotFound, err)
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("%w: %s", ErrEntryNotFound, entryId)
+	}
+	if entry.NotifyPhone != "" || entry.NotifyEmail != "" {
+		if err := s.repo.UpdateEntryContact(ctx, entry.ID, "", ""); err != nil {
+			return nil, fmt.Errorf("failed to remove entry contact: %w", err)
+		}
+	}
+	if entry.PushSubscription != nil {
+		if err := s.repo.UpdateEntryPushSubscription(ctx, entry.ID, nil); err != nil {
+			return nil, fmt.Errorf("failed to remove entry push subscription: %w", err)
+		}
+	}
+	return entry, nil
+}
@@ -0,0 +1,82 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/repository"
+)
+
+// TestNormalizeContact tests the validation of notification phone numbers and emails
+func TestNormalizeContact(t *testing.T) {
+	phone, email, err := NormalizeContact("+421 901 234-567", " patient@example.com ")
+	if err != nil {
+		t.Fatalf("NormalizeContact failed: %v", err)
+	}
+	if phone != "+421901234567" || email != "patient@example.com" {
+		t.Errorf("Expected +421901234567 and patient@example.com, got %s and %s", phone, email)
+	}
+
+	for _, contact := range [][2]string{{"0901234567", ""}, {"+12", ""}, {"", "not an email"}, {"", "Patient <patient@example.com>"}} {
+		if _, _, err := NormalizeContact(contact[0], contact[1]); !errors.Is(err, ErrInvalidContact) {
+			t.Errorf("Expected ErrInvalidContact for %q, got %v", contact, err)
+		}
+	}
+}
+
+// TestApproachingEntries tests that patients with a contact are notified once when their turn is near
+func TestApproachingEntries(t *testing.T) {
+	ctx := context.WithValue(context.Background(), middleware.TENANT, "hospital-a:cardiology")
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+
+	var entries []*Entry
+	for _, id := range []string{"1", "2", "3"} {
+		entry, err := wq.CreateEntry(ctx, "triage-1", CardData{IDNumber: id}, 300, "Service", nil, nil, nil, nil, "")
+		if err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	// The phone page has no tenant header
+	if _, err := wq.RegisterContactByQRToken(context.Background(), entries[1].QRToken, "+421901234567", ""); err != nil {
+		t.Fatalf("RegisterContactByQRToken failed: %v", err)
+	}
+	if _, err := wq.RegisterContactByQRToken(context.Background(), entries[2].QRToken, "", "patient@example.com"); err != nil {
+		t.Fatalf("RegisterContactByQRToken failed: %v", err)
+	}
+
+	approaching, err := wq.ApproachingEntries(ctx, "triage-1", 1)
+	if err != nil {
+		t.Fatalf("ApproachingEntries failed: %v", err)
+	}
+	if len(approaching) != 1 || approaching[0].ID != entries[1].ID {
+		t.Fatalf("Expected only the second entry, got %d entries", len(approaching))
+	}
+	if again, _ := wq.ApproachingEntries(ctx, "triage-1", 1); len(again) != 0 {
+		t.Errorf("Expected the second entry to be notified only once, got %d entries", len(again))
+	}
+
+	// Taking the contact of a called entry removes it
+	called, err := wq.TakeEntryContact(ctx, entries[1].ID)
+	if err != nil {
+		t.Fatalf("TakeEntryContact failed: %v", err)
+	}
+	if called.NotifyPhone != "+421901234567" {
+		t.Errorf("Expected the phone number of the second entry, got %q", called.NotifyPhone)
+	}
+	if stored, _ := mockRepo.GetEntryByID(ctx, entries[1].ID); stored.NotifyPhone != "" {
+		t.Errorf("Expected the contact to be removed, got %q", stored.NotifyPhone)
+	}
+
+	if _, err := wq.RegisterContactByQRToken(context.Background(), entries[0].QRToken, "12345", ""); !errors.Is(err, ErrInvalidContact) {
+		t.Errorf("Expected ErrInvalidContact, got %v", err)
+	}
+	if _, err := wq.RegisterContactByQRToken(context.Background(), "unknown", "+421901234567", ""); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("Expected ErrEntryNotFound for an unknown token, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package queue
+
+import (
+	"context"
+	"log"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// RoomDisplay returns the display board settings of a room, with callHistory defaulted and capped
+func (s *WaitingQueue) RoomDisplay(ctx context.Context, roomId string) types.RoomDisplay {
+	display := types.RoomDisplay{CallHistory: types.DefaultDisplayCallHistory}
+	if s.configService == nil {
+		return display
+	}
+
+	rooms, err := s.configService.GetRoomsConfig(ctx)
+	if err != nil {
+		log.Printf("[WaitingQueue] Failed to get display settings of room %s, using the defaults: %v", roomId, err)
+		return display
+	}
+	for _, room := range rooms {
+		if room.ID != roomId || room.Display == nil {
+			continue
+		}
+		display = *room.Display
+		if display.CallHistory <= 0 {
+			display.CallHistory = types.DefaultDisplayCallHistory
+		}
+		if display.CallHistory > types.MaxDisplayCallHistory {
+			display.CallHistory = types.MaxDisplayCallHistory
+		}
+		if display.BlinkSeconds < 0 {
+			display.BlinkSeconds = 0
+		}
+		break
+	}
+	return display
+}
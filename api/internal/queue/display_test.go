@@ -0,0 +1,32 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// TestRoomDisplay tests that rooms get the default call history unless they set one, capped at the maximum
+func TestRoomDisplay(t *testing.T) {
+	ctx := context.Background()
+	wq := NewWaitingQueue(repository.NewMockQueueRepository(), nil, nil, nil)
+	wq.SetConfigService(&stubConfigService{rooms: []types.RoomConfig{
+		{ID: "triage-1", Display: &types.RoomDisplay{CallHistory: 8, BlinkSeconds: 10, Announce: true}},
+		{ID: "triage-2", Display: &types.RoomDisplay{CallHistory: 500, BlinkSeconds: -1}},
+		{ID: "triage-3"},
+	}})
+
+	if display := wq.RoomDisplay(ctx, "triage-1"); display != (types.RoomDisplay{CallHistory: 8, BlinkSeconds: 10, Announce: true}) {
+		t.Errorf("Expected the configured settings of triage-1, got %+v", display)
+	}
+	if display := wq.RoomDisplay(ctx, "triage-2"); display != (types.RoomDisplay{CallHistory: types.MaxDisplayCallHistory}) {
+		t.Errorf("Expected the capped settings of triage-2, got %+v", display)
+	}
+	for _, roomId := range []string{"triage-3", "unknown"} {
+		if display := wq.RoomDisplay(ctx, roomId); display != (types.RoomDisplay{CallHistory: types.DefaultDisplayCallHistory}) {
+			t.Errorf("Expected the default settings of %s, got %+v", roomId, display)
+		}
+	}
+}
@@ -12,10 +12,19 @@ import (
 )
 
 // CreateEntry creates a new queue entry with priority calculation
-// approximateDurationSeconds, symbols, appointmentTime, age, manualOverride are used for priority calculation
+// approximateDurationSeconds, symbols, appointmentTime, age, manualOverride are used for priority calculation;
+// idempotencyKey (optional) is stored so repeated swipes can find the entry (see JoinOnce)
 func (s *WaitingQueue) CreateEntry(ctx context.Context, roomId string, cardData CardData,
 	approximateDurationSeconds int64, serviceName string, symbols []string,
-	appointmentTime *time.Time, age *int, manualOverride *float64) (*Entry, error) {
+	appointmentTime *time.Time, age *int, manualOverride *float64, idempotencyKey string) (*Entry, error) {
+	return s.createEntry(ctx, roomId, cardData, "", approximateDurationSeconds, serviceName, symbols,
+		appointmentTime, age, manualOverride, idempotencyKey)
+}
+
+// createEntry creates an entry identified by its card data or, for walk-ins without a card, by visitorCode
+func (s *WaitingQueue) createEntry(ctx context.Context, roomId string, cardData CardData, visitorCode string,
+	approximateDurationSeconds int64, serviceName string, symbols []string,
+	appointmentTime *time.Time, age *int, manualOverride *float64, idempotencyKey string) (*Entry, error) {
 
 	// Extract tenant ID from context (format: "buildingId:sectionId")
 	tenantIDHeader := service.GetTenantID(ctx)
@@ -25,22 +34,8 @@ func (s *WaitingQueue) CreateEntry(ctx context.Context, roomId string, cardData
 
 	log.Printf("[WaitingQueue] Creating entry for room %s, buildingId: %s, sectionId: %s", roomId, buildingID, sectionID)
 
-	// Load priority configuration
-	var priorityConfig *priority.PriorityConfig
-	if s.priorityRepo != nil {
-		var err error
-		priorityConfig, err = s.priorityRepo.GetConfig(ctx, buildingID, sectionID)
-		if err != nil {
-			log.Printf("Warning: Failed to load priority config, using default: %v", err)
-			priorityConfig = priority.GetDefaultConfig()
-		}
-	} else {
-		// If priority repo is nil (e.g., in tests), use default config
-		priorityConfig = priority.GetDefaultConfig()
-	}
-
-	// Calculate tier and fitness score
-	calculator := priority.NewCalculator(priorityConfig)
+	// Load priority configuration and calculate tier and fitness score
+	calculator := priority.NewCalculator(s.loadPriorityConfig(ctx, buildingID, sectionID))
 	now := time.Now()
 
 	calcInput := priority.CalculationInput{
@@ -61,22 +56,31 @@ func (s *WaitingQueue) CreateEntry(ctx context.Context, roomId string, cardData
 	if err != nil {
 		log.Printf("Failed to get queue entries: %v", err)
 		// Continue with position 1 if we can't get current entries
+	} else if err := s.checkCapacity(ctx, roomId, len(entries)); err != nil {
+		return nil, err
 	}
 
 	// Calculate next position based only on WAITING entries
 	// This is a temporary position - it will be recalculated based on priority
 	nextPosition := len(entries) + 1
 
+	ticketNumber, err := s.allocateTicketNumber(ctx, roomId, buildingID, sectionID, now)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create new entry with priority metadata
 	entry := &Entry{
 		WaitingRoomID:              roomId,
 		TenantID:                   buildingID,
 		SectionID:                  sectionID,
-		TicketNumber:               "", // Will be set by repository
+		TicketNumber:               ticketNumber,
 		QRToken:                    "", // Will be set by repository
 		Status:                     "WAITING",
 		Position:                   int64(nextPosition),
 		CardData:                   cardData,
+		VisitorCode:                visitorCode,
+		IdempotencyKey:             idempotencyKey,
 		ApproximateDurationSeconds: approximateDurationSeconds,
 		ServiceName:                serviceName,
 		Symbols:                    symbols,
@@ -85,12 +89,15 @@ func (s *WaitingQueue) CreateEntry(ctx context.Context, roomId string, cardData
 		ManualOverride:             manualOverride,
 		FitnessScore:               result.FitnessScore,
 		Tier:                       result.Tier,
+		FitnessBreakdown:           entryFitnessBreakdown(result),
+		ServicePoint:               s.assignServicePoint(ctx, roomId, serviceName),
 	}
 
 	// Save to repository
 	if err := s.repo.CreateEntry(ctx, entry); err != nil {
 		return nil, fmt.Errorf("failed to create queue entry: %w", err)
 	}
+	s.recordTransition(ctx, entry, "", "created")
 
 	// Recalculate positions based on priority (tier, fitness score, arrival time)
 	if err := s.repo.RecalculatePositions(ctx, roomId); err != nil {
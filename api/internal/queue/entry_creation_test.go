@@ -181,7 +181,7 @@ func TestCreateEntry_WithPriorityCalculation(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create entry
 			entry, err := wq.CreateEntry(ctx, tt.roomId, tt.cardData, tt.duration, tt.serviceName,
-				tt.symbols, tt.appointmentTime, tt.age, tt.manualOverride)
+				tt.symbols, tt.appointmentTime, tt.age, tt.manualOverride, "")
 
 			if err != nil {
 				t.Fatalf("CreateEntry() error = %v", err)
@@ -280,7 +280,7 @@ func TestCreateMultipleEntries_PriorityOrdering(t *testing.T) {
 	var entries []*Entry
 	for _, p := range patients {
 		entry, err := wq.CreateEntry(ctx, roomId, p.cardData, 300, "Service",
-			p.symbols, nil, p.age, nil)
+			p.symbols, nil, p.age, nil, "")
 		if err != nil {
 			t.Fatalf("Failed to create entry for %s: %v", p.name, err)
 		}
@@ -336,7 +336,7 @@ func TestCreateEntry_WithWaitingTime(t *testing.T) {
 
 	// Create first entry
 	cardData1 := CardData{IDNumber: "111", FirstName: "First", LastName: "Patient"}
-	entry1, err := wq.CreateEntry(ctx, roomId, cardData1, 300, "Service", []string{}, nil, nil, nil)
+	entry1, err := wq.CreateEntry(ctx, roomId, cardData1, 300, "Service", []string{}, nil, nil, nil, "")
 	if err != nil {
 		t.Fatalf("Failed to create first entry: %v", err)
 	}
@@ -346,7 +346,7 @@ func TestCreateEntry_WithWaitingTime(t *testing.T) {
 
 	// Create second entry (same priority tier, but entered later)
 	cardData2 := CardData{IDNumber: "222", FirstName: "Second", LastName: "Patient"}
-	entry2, err := wq.CreateEntry(ctx, roomId, cardData2, 300, "Service", []string{}, nil, nil, nil)
+	entry2, err := wq.CreateEntry(ctx, roomId, cardData2, 300, "Service", []string{}, nil, nil, nil, "")
 	if err != nil {
 		t.Fatalf("Failed to create second entry: %v", err)
 	}
@@ -397,7 +397,7 @@ func TestCreateEntry_PersistsMetadata(t *testing.T) {
 	}
 
 	entry, err := wq.CreateEntry(ctx, roomId, cardData, 600, "Test Service",
-		symbols, &appointmentTime, &age, &manualOverride)
+		symbols, &appointmentTime, &age, &manualOverride, "")
 	if err != nil {
 		t.Fatalf("CreateEntry() error = %v", err)
 	}
@@ -7,7 +7,19 @@ import (
 // UpdateEntryStatus updates the status of a queue entry
 func (s *WaitingQueue) UpdateEntryStatus(id string, status string) error {
 	ctx := context.Background()
-	return s.repo.UpdateEntryStatus(ctx, id, status)
+	entry, err := s.repo.GetEntryByID(ctx, id)
+	if err != nil || entry == nil {
+		return s.repo.UpdateEntryStatus(ctx, id, status, 0)
+	}
+
+	previousStatus := entry.Status
+	if err := s.repo.UpdateEntryStatus(ctx, id, status, entry.Version); err != nil {
+		return err
+	}
+	updated := *entry
+	updated.Status = status
+	s.recordTransition(ctx, &updated, previousStatus, "status_changed")
+	return nil
 }
 
 // DeleteEntry deletes a queue entry
@@ -33,3 +33,15 @@ func (s *WaitingQueue) GetEntryByQRToken(qrToken string) (*Entry, error) {
 	}
 	return entry, nil
 }
+
+// GetEntry retrieves a queue entry of the tenant in the context by ID
+func (s *WaitingQueue) GetEntry(ctx context.Context, entryId string) (*Entry, error) {
+	entry, err := s.repo.GetEntryByID(ctx, entryId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEntryNotFound, err)
+	}
+	if !sameTenant(ctx, entry) {
+		return nil, fmt.Errorf("%w: %s", ErrEntryNotFound, entryId)
+	}
+	return entry, nil
+}
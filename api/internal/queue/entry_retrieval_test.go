@@ -0,0 +1,39 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/repository"
+)
+
+// TestGetEntry tests that an entry is only found for its own tenant
+func TestGetEntry(t *testing.T) {
+	ctx := context.WithValue(context.Background(), middleware.TENANT, "hospital-a:cardiology")
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+
+	entry, err := wq.CreateEntry(ctx, "triage-1", CardData{IDNumber: "1"}, 300, "Service", nil, nil, nil, nil, "")
+	if err != nil {
+		t.Fatalf("CreateEntry failed: %v", err)
+	}
+
+	found, err := wq.GetEntry(ctx, entry.ID)
+	if err != nil {
+		t.Fatalf("GetEntry failed: %v", err)
+	}
+	if found.ID != entry.ID {
+		t.Errorf("Expected entry %s, got %s", entry.ID, found.ID)
+	}
+
+	otherCtx := context.WithValue(context.Background(), middleware.TENANT, "hospital-b")
+	if _, err := wq.GetEntry(otherCtx, entry.ID); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("Expected ErrEntryNotFound for another tenant, got %v", err)
+	}
+	if _, err := wq.GetEntry(ctx, "unknown"); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("Expected ErrEntryNotFound for an unknown entry, got %v", err)
+	}
+}
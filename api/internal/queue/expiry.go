@@ -0,0 +1,68 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/middleware"
+)
+
+// ExpireCalledEntries moves entries that were CALLED before the given time and never
+// marked IN_ROOM to NO_SHOW. With requeue they go back to WAITING one priority tier
+// lower instead, so the patient can still be served after the people they held up.
+// Positions are recalculated per room and tenant; the changed entries are returned.
+func (s *WaitingQueue) ExpireCalledEntries(ctx context.Context, before time.Time, requeue bool) ([]*Entry, error) {
+	entries, err := s.repo.GetCalledEntriesBefore(ctx, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get called entries: %w", err)
+	}
+
+	var expired []*Entry
+	for _, entry := range entries {
+		if requeue {
+			entry.Tier++
+			if err := s.repo.RequeueEntry(ctx, entry.ID, entry.Tier); err != nil {
+				log.Printf("[WaitingQueue] Failed to requeue no-show entry %s: %v", entry.ID, err)
+				continue
+			}
+			entry.Status = "WAITING"
+			entry.ServicePoint = ""
+			s.recordTransition(ctx, entry, "CALLED", "no_show_requeued")
+		} else {
+			if err := s.repo.UpdateEntryStatus(ctx, entry.ID, "NO_SHOW", entry.Version); err != nil {
+				log.Printf("[WaitingQueue] Failed to mark entry %s as no-show: %v", entry.ID, err)
+				continue
+			}
+			entry.Status = "NO_SHOW"
+			s.recordTransition(ctx, entry, "CALLED", "no_show")
+		}
+		expired = append(expired, entry)
+		log.Printf("[WaitingQueue] Entry %s (ticket %s) in room %s expired as no-show (requeued: %t)", entry.ID, entry.TicketNumber, entry.WaitingRoomID, requeue)
+	}
+
+	recalculated := make(map[string]bool)
+	for _, entry := range expired {
+		tenantCtx := context.WithValue(ctx, middleware.TENANT, EntryTenantID(entry))
+		key := entry.WaitingRoomID + "|" + EntryTenantID(entry)
+		if recalculated[key] {
+			continue
+		}
+		recalculated[key] = true
+		if err := s.repo.RecalculatePositions(tenantCtx, entry.WaitingRoomID); err != nil {
+			log.Printf("[WaitingQueue] Failed to recalculate positions for room %s: %v", entry.WaitingRoomID, err)
+		}
+	}
+
+	return expired, nil
+}
+
+// EntryTenantID returns the tenant of an entry in the "buildingId:sectionId" format of
+// the tenant header, or just the buildingId for tenant-level entries
+func EntryTenantID(entry *Entry) string {
+	if entry.SectionID == "" {
+		return entry.TenantID
+	}
+	return entry.TenantID + ":" + entry.SectionID
+}
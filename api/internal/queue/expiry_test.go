@@ -0,0 +1,74 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// TestExpireCalledEntries tests that stale CALLED entries become NO_SHOW or are requeued one tier lower
+func TestExpireCalledEntries(t *testing.T) {
+	tests := []struct {
+		name           string
+		requeue        bool
+		expectedStatus string
+		expectedTier   int
+	}{
+		{name: "stale entry becomes NO_SHOW", requeue: false, expectedStatus: "NO_SHOW", expectedTier: 1},
+		{name: "stale entry is requeued one tier lower", requeue: true, expectedStatus: "WAITING", expectedTier: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := repository.NewMockQueueRepository()
+			wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+			ctx := context.Background()
+
+			stale := &types.Entry{WaitingRoomID: "triage-1", TenantID: "hospital", SectionID: "er", Status: "CALLED", ServicePoint: "window-1", Tier: 1}
+			fresh := &types.Entry{WaitingRoomID: "triage-1", Status: "CALLED", ServicePoint: "window-2", Tier: 1}
+			for _, entry := range []*types.Entry{stale, fresh} {
+				if err := mockRepo.CreateEntry(ctx, entry); err != nil {
+					t.Fatalf("CreateEntry failed: %v", err)
+				}
+			}
+			stale.UpdatedAt = time.Now().Add(-10 * time.Minute)
+
+			expired, err := wq.ExpireCalledEntries(ctx, time.Now().Add(-5*time.Minute), tt.requeue)
+			if err != nil {
+				t.Fatalf("ExpireCalledEntries failed: %v", err)
+			}
+			if len(expired) != 1 || expired[0].ID != stale.ID {
+				t.Fatalf("Expected only entry %s to expire, got %d entries", stale.ID, len(expired))
+			}
+
+			got, _ := mockRepo.GetEntryByID(ctx, stale.ID)
+			if got.Status != tt.expectedStatus {
+				t.Errorf("Expected status %s, got %s", tt.expectedStatus, got.Status)
+			}
+			if got.Tier != tt.expectedTier {
+				t.Errorf("Expected tier %d, got %d", tt.expectedTier, got.Tier)
+			}
+			if tt.requeue && got.ServicePoint != "" {
+				t.Errorf("Expected service point to be cleared, got %s", got.ServicePoint)
+			}
+
+			if got, _ := mockRepo.GetEntryByID(ctx, fresh.ID); got.Status != "CALLED" {
+				t.Errorf("Expected fresh entry to stay CALLED, got %s", got.Status)
+			}
+		})
+	}
+}
+
+// TestEntryTenantID tests the tenant header format of an entry
+func TestEntryTenantID(t *testing.T) {
+	if got := EntryTenantID(&Entry{TenantID: "hospital", SectionID: "er"}); got != "hospital:er" {
+		t.Errorf("Expected hospital:er, got %s", got)
+	}
+	if got := EntryTenantID(&Entry{TenantID: "hospital"}); got != "hospital" {
+		t.Errorf("Expected hospital, got %s", got)
+	}
+}
@@ -0,0 +1,85 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/service"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// SetHistoryRepository sets the repository the status transitions of entries are appended to
+func (s *WaitingQueue) SetHistoryRepository(historyRepo repository.EntryHistoryRepository) {
+	s.historyRepo = historyRepo
+}
+
+// recordTransition appends the transition of entry from fromStatus to its current status, room and
+// service point to the entry's history and counts it in the metrics. The actor is the user in the context. A failure is logged
+// and never fails the operation that changed the status.
+func (s *WaitingQueue) recordTransition(ctx context.Context, entry *Entry, fromStatus, event string) {
+	s.recordEvent(ctx, entry, fromStatus, event, service.GetUserID(ctx), "")
+}
+
+// recordEvent is recordTransition with an explicit actor and the reason staff gave for the change
+func (s *WaitingQueue) recordEvent(ctx context.Context, entry *Entry, fromStatus, event, actorID, reason string) {
+	observeTransition(entry, fromStatus, event, time.Now())
+	if s.historyRepo == nil {
+		return
+	}
+
+	historyEvent := &types.EntryHistoryEvent{
+		EntryID:        entry.ID,
+		TenantID:       entry.TenantID,
+		SectionID:      entry.SectionID,
+		RoomID:         entry.WaitingRoomID,
+		FromStatus:     fromStatus,
+		ToStatus:       entry.Status,
+		Event:          event,
+		ServicePointID: entry.ServicePoint,
+		ActorID:        actorID,
+		Reason:         reason,
+		Timestamp:      time.Now(),
+	}
+	if err := s.historyRepo.AppendEntryHistory(ctx, historyEvent); err != nil {
+		log.Printf("[WaitingQueue] Failed to record %s transition of entry %s: %v", event, entry.ID, err)
+	}
+}
+
+// GetEntryHistory returns the status transitions of an entry that is or was in roomId, oldest first
+func (s *WaitingQueue) GetEntryHistory(ctx context.Context, roomId, entryId string) ([]*types.EntryHistoryEvent, error) {
+	// The repositories report a missing entry as an error
+	entry, err := s.repo.GetEntryByID(ctx, entryId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEntryNotFound, err)
+	}
+	if entry == nil || !sameTenant(ctx, entry) {
+		return nil, fmt.Errorf("%w: %s", ErrEntryNotFound, entryId)
+	}
+	if s.historyRepo == nil {
+		return []*types.EntryHistoryEvent{}, nil
+	}
+
+	events, err := s.historyRepo.GetEntryHistory(ctx, entry.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entry history: %w", err)
+	}
+
+	// Transferred entries keep their history; it can be read from every room they were in
+	inRoom := entry.WaitingRoomID == roomId
+	for _, event := range events {
+		inRoom = inRoom || event.RoomID == roomId
+	}
+	if !inRoom {
+		return nil, fmt.Errorf("%w: %s in room %s", ErrEntryNotFound, entryId, roomId)
+	}
+	return events, nil
+}
+
+// sameTenant reports whether an entry belongs to the tenant in the context (any when there is none)
+func sameTenant(ctx context.Context, entry *Entry) bool {
+	buildingID, sectionID := service.GetTenantScope(ctx)
+	return (buildingID == "" || entry.TenantID == buildingID) && (sectionID == "" || entry.SectionID == sectionID)
+}
@@ -0,0 +1,67 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	appContext "github.com/arfis/waiting-room/internal/context"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/repository"
+)
+
+// TestEntryHistory tests that the status changes of an entry are recorded in order with their actor
+func TestEntryHistory(t *testing.T) {
+	ctx := context.WithValue(context.Background(), middleware.TENANT, "hospital-a:cardiology")
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+	wq.SetHistoryRepository(repository.NewMockEntryHistoryRepository())
+
+	entry, err := wq.CreateEntry(ctx, "triage-1", CardData{IDNumber: "1"}, 300, "Service", nil, nil, nil, nil, "")
+	if err != nil {
+		t.Fatalf("CreateEntry failed: %v", err)
+	}
+
+	staffCtx := context.WithValue(ctx, appContext.USER_ID, "nurse-1")
+	if _, err := wq.CallNextForServicePoint(staffCtx, "triage-1", "window-1"); err != nil {
+		t.Fatalf("CallNextForServicePoint failed: %v", err)
+	}
+	if _, err := wq.MarkInRoomForServicePoint(staffCtx, "triage-1", "window-1", entry.ID); err != nil {
+		t.Fatalf("MarkInRoomForServicePoint failed: %v", err)
+	}
+	if _, err := wq.FinishCurrentForServicePoint(staffCtx, "triage-1", "window-1"); err != nil {
+		t.Fatalf("FinishCurrentForServicePoint failed: %v", err)
+	}
+
+	history, err := wq.GetEntryHistory(ctx, "triage-1", entry.ID)
+	if err != nil {
+		t.Fatalf("GetEntryHistory failed: %v", err)
+	}
+	want := []struct{ from, to, event string }{
+		{"", "WAITING", "created"},
+		{"WAITING", "CALLED", "called"},
+		{"CALLED", "IN_ROOM", "in_room"},
+		{"IN_ROOM", "COMPLETED", "completed"},
+	}
+	if len(history) != len(want) {
+		t.Fatalf("Expected %d history events, got %d", len(want), len(history))
+	}
+	for i, w := range want {
+		if history[i].FromStatus != w.from || history[i].ToStatus != w.to || history[i].Event != w.event {
+			t.Errorf("Event %d: expected %s %s -> %s, got %s %s -> %s", i, w.event, w.from, w.to, history[i].Event, history[i].FromStatus, history[i].ToStatus)
+		}
+	}
+	if history[0].ActorID != "system" || history[1].ActorID != "nurse-1" || history[1].ServicePointID != "window-1" {
+		t.Errorf("Expected the call by nurse-1 at window-1, got '%s' at '%s'", history[1].ActorID, history[1].ServicePointID)
+	}
+
+	// Another tenant and an unrelated room cannot read the history
+	otherCtx := context.WithValue(context.Background(), middleware.TENANT, "hospital-b:cardiology")
+	if _, err := wq.GetEntryHistory(otherCtx, "triage-1", entry.ID); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("Expected ErrEntryNotFound for another tenant, got %v", err)
+	}
+	if _, err := wq.GetEntryHistory(ctx, "lab-1", entry.ID); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("Expected ErrEntryNotFound for another room, got %v", err)
+	}
+}
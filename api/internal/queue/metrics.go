@@ -0,0 +1,48 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/metrics"
+)
+
+// finishedStatuses are the statuses entries leave the queue with
+var finishedStatuses = []string{"COMPLETED", "SKIPPED", "CANCELLED", "NO_SHOW"}
+
+// observeTransition counts a status transition of an entry in the metrics: its creation, its call
+// with the time it waited since the check-in, and the status it left the queue with
+func observeTransition(entry *Entry, fromStatus, event string, now time.Time) {
+	tenantID := EntryTenantID(entry)
+	switch {
+	case event == "created":
+		metrics.EntriesCreated.Inc(tenantID, entry.WaitingRoomID)
+	case entry.Status == fromStatus:
+		// Boosts, escalations and transfers within a status
+	case entry.Status == "CALLED" && fromStatus == "WAITING":
+		metrics.WaitTime.ObserveDuration(now.Sub(entry.CreatedAt), tenantID, entry.WaitingRoomID)
+	case slices.Contains(finishedStatuses, entry.Status):
+		metrics.EntriesFinished.Inc(tenantID, entry.WaitingRoomID, entry.Status)
+	}
+}
+
+// CollectQueueDepth returns the number of WAITING entries of every tenant and room, for the
+// queue depth gauge
+func (s *WaitingQueue) CollectQueueDepth(ctx context.Context) ([]metrics.Sample, error) {
+	entries, err := s.repo.GetWaitingEntries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waiting entries: %w", err)
+	}
+	type room struct{ tenantID, roomID string }
+	depths := map[room]int{}
+	for _, entry := range entries {
+		depths[room{EntryTenantID(entry), entry.WaitingRoomID}]++
+	}
+	samples := make([]metrics.Sample, 0, len(depths))
+	for r, depth := range depths {
+		samples = append(samples, metrics.Sample{LabelValues: []string{r.tenantID, r.roomID}, Value: float64(depth)})
+	}
+	return samples, nil
+}
@@ -0,0 +1,70 @@
+package queue
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/metrics"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// TestObserveTransition tests the queue metrics counted for the transitions of an entry
+func TestObserveTransition(t *testing.T) {
+	now := time.Now()
+	entry := &Entry{TenantID: "metrics-b", SectionID: "s1", WaitingRoomID: "metrics-room", CreatedAt: now.Add(-10 * time.Minute), Status: "WAITING"}
+
+	observeTransition(entry, "", "created", now)
+	entry.Status = "WAITING"
+	observeTransition(entry, "WAITING", "priority_boost", now)
+	entry.Status = "CALLED"
+	observeTransition(entry, "WAITING", "called", now)
+	entry.Status = "COMPLETED"
+	observeTransition(entry, "CALLED", "completed", now)
+
+	var b strings.Builder
+	metrics.Default.Write(context.Background(), &b)
+	scraped := b.String()
+	for _, line := range []string{
+		`waitingroom_entries_created_total{tenant="metrics-b:s1",room="metrics-room"} 1`,
+		`waitingroom_entries_finished_total{tenant="metrics-b:s1",room="metrics-room",status="COMPLETED"} 1`,
+		`waitingroom_wait_time_seconds_bucket{tenant="metrics-b:s1",room="metrics-room",le="300"} 0`,
+		`waitingroom_wait_time_seconds_bucket{tenant="metrics-b:s1",room="metrics-room",le="600"} 1`,
+		`waitingroom_wait_time_seconds_count{tenant="metrics-b:s1",room="metrics-room"} 1`,
+	} {
+		if !strings.Contains(scraped, line+"\n") {
+			t.Errorf("Expected %s in the metrics", line)
+		}
+	}
+}
+
+// TestCollectQueueDepth tests the WAITING entries counted per tenant and room
+func TestCollectQueueDepth(t *testing.T) {
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+	for _, entry := range []*types.Entry{
+		{TenantID: "b1", SectionID: "s1", WaitingRoomID: "triage-1", Status: "WAITING"},
+		{TenantID: "b1", SectionID: "s1", WaitingRoomID: "triage-1", Status: "WAITING"},
+		{TenantID: "b1", SectionID: "s1", WaitingRoomID: "triage-1", Status: "CALLED"},
+		{TenantID: "b1", SectionID: "s2", WaitingRoomID: "triage-1", Status: "WAITING"},
+	} {
+		if err := mockRepo.CreateEntry(context.Background(), entry); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+	}
+
+	samples, err := wq.CollectQueueDepth(context.Background())
+	if err != nil {
+		t.Fatalf("CollectQueueDepth failed: %v", err)
+	}
+	depths := map[string]float64{}
+	for _, s := range samples {
+		depths[strings.Join(s.LabelValues, "/")] = s.Value
+	}
+	if len(depths) != 2 || depths["b1:s1/triage-1"] != 2 || depths["b1:s2/triage-1"] != 1 {
+		t.Errorf("Expected 2 waiting in b1:s1 and 1 in b1:s2, got %v", depths)
+	}
+}
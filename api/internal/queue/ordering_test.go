@@ -0,0 +1,80 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// roomOrdering resolves the ordering of every room from a map
+type roomOrdering map[string]types.QueueOrdering
+
+func (o roomOrdering) GetQueueOrdering(ctx context.Context, roomId string) types.QueueOrdering {
+	return o[roomId]
+}
+
+// TestQueueOrderingPerRoom tests that positions and the next called entry follow the ordering of the room
+func TestQueueOrderingPerRoom(t *testing.T) {
+	arrival := time.Date(2026, 3, 4, 8, 0, 0, 0, time.UTC)
+	appointment := arrival.Add(time.Hour)
+
+	tests := []struct {
+		ordering types.QueueOrdering
+		want     []string
+	}{
+		{types.QueueOrderingPriority, []string{"B", "C", "A"}},
+		{types.QueueOrderingFIFO, []string{"A", "B", "C"}},
+		{types.QueueOrderingAppointmentFirst, []string{"C", "B", "A"}},
+		{"", []string{"B", "C", "A"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.ordering), func(t *testing.T) {
+			ctx := context.Background()
+			mockRepo := repository.NewMockQueueRepository()
+			mockRepo.SetOrderingResolver(roomOrdering{"triage-1": tt.ordering})
+			wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+
+			// A arrives first with the lowest priority, B has the highest priority, C has an appointment
+			entries := []*types.Entry{
+				{TicketNumber: "A", WaitingRoomID: "triage-1", Status: "WAITING", Tier: 2},
+				{TicketNumber: "B", WaitingRoomID: "triage-1", Status: "WAITING", Tier: 0},
+				{TicketNumber: "C", WaitingRoomID: "triage-1", Status: "WAITING", Tier: 1, AppointmentTime: &appointment},
+			}
+			for i, entry := range entries {
+				if err := mockRepo.CreateEntry(ctx, entry); err != nil {
+					t.Fatalf("CreateEntry failed: %v", err)
+				}
+				entry.CreatedAt = arrival.Add(time.Duration(i) * time.Minute)
+			}
+			if err := mockRepo.RecalculatePositions(ctx, "triage-1"); err != nil {
+				t.Fatalf("RecalculatePositions failed: %v", err)
+			}
+
+			waiting, err := wq.GetQueueEntriesWithContext(ctx, "triage-1", []string{"WAITING"})
+			if err != nil {
+				t.Fatalf("GetQueueEntriesWithContext failed: %v", err)
+			}
+			if len(waiting) != len(tt.want) {
+				t.Fatalf("Expected %d waiting entries, got %d", len(tt.want), len(waiting))
+			}
+			for i, entry := range waiting {
+				if entry.TicketNumber != tt.want[i] || entry.Position != int64(i+1) {
+					t.Errorf("Position %d: expected %s, got %s at position %d", i+1, tt.want[i], entry.TicketNumber, entry.Position)
+				}
+			}
+
+			called, err := wq.CallNext(ctx, "triage-1")
+			if err != nil {
+				t.Fatalf("CallNext failed: %v", err)
+			}
+			if called.TicketNumber != tt.want[0] {
+				t.Errorf("Expected %s to be called first, got %s", tt.want[0], called.TicketNumber)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// SetStageAdvanceHandler sets the function called after an entry moved on to the next
+// stage of its pathway
+func (s *WaitingQueue) SetStageAdvanceHandler(handler func(ctx context.Context, entry *Entry, fromRoomId string)) {
+	s.stageHandler = handler
+}
+
+// StartPathway puts a new entry on a pathway: the one named by pathwayId, or else the
+// first pathway listing serviceId. The entry is moved to the first stage's room and
+// service point. It returns false when no pathway applies.
+func (s *WaitingQueue) StartPathway(ctx context.Context, entry *Entry, pathwayId, serviceId string) (bool, error) {
+	pathways, err := s.pathways(ctx, entry)
+	if err != nil {
+		return false, err
+	}
+
+	pathway := findPathway(pathways, pathwayId, serviceId)
+	if pathway == nil {
+		if pathwayId != "" {
+			return false, fmt.Errorf("pathway %s not found", pathwayId)
+		}
+		return false, nil
+	}
+	if len(pathway.Stages) == 0 {
+		return false, fmt.Errorf("pathway %s has no stages", pathway.ID)
+	}
+
+	fromRoomId := entry.WaitingRoomID
+	if err := s.moveToStage(ctx, entry, pathway, 0); err != nil {
+		return false, err
+	}
+	if fromRoomId != entry.WaitingRoomID {
+		s.recalculatePositions(ctx, entry, fromRoomId)
+	}
+
+	log.Printf("[WaitingQueue] Entry %s (ticket %s) follows pathway %s, first stage '%s' in room %s",
+		entry.ID, entry.TicketNumber, pathway.ID, pathway.Stages[0].Name, entry.WaitingRoomID)
+	return true, nil
+}
+
+// completeEntry finishes the current stage of an entry. An entry on a pathway with a
+// further stage is enqueued for it with its ticket and priority; any other entry is
+// COMPLETED. The stage handler is told about the move.
+func (s *WaitingQueue) completeEntry(ctx context.Context, entry *Entry) error {
+	if entry.PathwayID != "" {
+		advanced, err := s.advancePathway(ctx, entry)
+		if err != nil {
+			log.Printf("[WaitingQueue] Failed to advance entry %s on pathway %s, completing it: %v", entry.ID, entry.PathwayID, err)
+		} else if advanced {
+			return nil
+		}
+	}
+
+	previousStatus := entry.Status
+	if err := s.repo.UpdateEntryStatus(ctx, entry.ID, "COMPLETED", entry.Version); err != nil {
+		return err
+	}
+	entry.Status = "COMPLETED"
+	entry.UpdatedAt = time.Now()
+	s.recordTransition(ctx, entry, previousStatus, "completed")
+	return nil
+}
+
+// advancePathway moves an entry to the next stage of its pathway; false when the stage
+// was the last one
+func (s *WaitingQueue) advancePathway(ctx context.Context, entry *Entry) (bool, error) {
+	pathways, err := s.pathways(ctx, entry)
+	if err != nil {
+		return false, err
+	}
+	pathway := findPathway(pathways, entry.PathwayID, "")
+	if pathway == nil {
+		return false, fmt.Errorf("pathway %s not found", entry.PathwayID)
+	}
+
+	next := entry.PathwayStage + 1
+	if next >= len(pathway.Stages) {
+		return false, nil
+	}
+
+	fromRoomId := entry.WaitingRoomID
+	if err := s.moveToStage(ctx, entry, pathway, next); err != nil {
+		return false, err
+	}
+
+	log.Printf("[WaitingQueue] Entry %s (ticket %s) moved from room %s to stage %d '%s' of pathway %s in room %s",
+		entry.ID, entry.TicketNumber, fromRoomId, next, pathway.Stages[next].Name, pathway.ID, entry.WaitingRoomID)
+
+	if s.stageHandler != nil {
+		s.stageHandler(ctx, entry, fromRoomId)
+	}
+	return true, nil
+}
+
+// moveToStage lets the entry wait for the given stage and recalculates the stage room's positions
+func (s *WaitingQueue) moveToStage(ctx context.Context, entry *Entry, pathway *types.Pathway, stage int) error {
+	target := pathway.Stages[stage]
+	previousStatus := entry.Status
+	if err := s.repo.MoveEntryToPathwayStage(ctx, entry.ID, pathway.ID, stage, target.RoomID, target.ServicePointID); err != nil {
+		return fmt.Errorf("failed to move entry to pathway stage: %w", err)
+	}
+
+	entry.PathwayID = pathway.ID
+	entry.PathwayStage = stage
+	entry.WaitingRoomID = target.RoomID
+	entry.ServicePoint = target.ServicePointID
+	entry.Status = "WAITING"
+	entry.UpdatedAt = time.Now()
+	s.recordTransition(ctx, entry, previousStatus, "pathway_stage")
+
+	s.recalculatePositions(ctx, entry, target.RoomID)
+	return nil
+}
+
+// recalculatePositions recalculates a room's positions within the entry's tenant
+func (s *WaitingQueue) recalculatePositions(ctx context.Context, entry *Entry, roomId string) {
+	tenantCtx := context.WithValue(ctx, middleware.TENANT, EntryTenantID(entry))
+	if err := s.repo.RecalculatePositions(tenantCtx, roomId); err != nil {
+		log.Printf("Warning: Failed to recalculate positions in room %s: %v", roomId, err)
+	}
+}
+
+// pathways loads the pathways of the entry's tenant
+func (s *WaitingQueue) pathways(ctx context.Context, entry *Entry) ([]types.Pathway, error) {
+	if s.configService == nil {
+		return nil, nil
+	}
+	tenantCtx := context.WithValue(ctx, middleware.TENANT, EntryTenantID(entry))
+	pathways, err := s.configService.GetPathways(tenantCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pathways: %w", err)
+	}
+	return pathways, nil
+}
+
+// findPathway returns the pathway with the given ID or, without an ID, the first one
+// listing serviceId
+func findPathway(pathways []types.Pathway, pathwayId, serviceId string) *types.Pathway {
+	for i := range pathways {
+		if pathwayId != "" {
+			if pathways[i].ID == pathwayId {
+				return &pathways[i]
+			}
+			continue
+		}
+		if serviceId == "" {
+			continue
+		}
+		for _, id := range pathways[i].ServiceIDs {
+			if strings.EqualFold(id, serviceId) {
+				return &pathways[i]
+			}
+		}
+	}
+	return nil
+}
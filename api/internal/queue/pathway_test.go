@@ -0,0 +1,143 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// stubConfigService serves a fixed set of rooms, pathways and retention policies
+type stubConfigService struct {
+	rooms     []types.RoomConfig
+	pathways  []types.Pathway
+	retention map[string]types.RetentionPolicy
+}
+
+func (c *stubConfigService) GetRoomsConfig(ctx context.Context) ([]types.RoomConfig, error) {
+	return c.rooms, nil
+}
+
+func (c *stubConfigService) GetPathways(ctx context.Context) ([]types.Pathway, error) {
+	return c.pathways, nil
+}
+
+func (c *stubConfigService) GetRetentionPolicies(ctx context.Context) (map[string]types.RetentionPolicy, error) {
+	return c.retention, nil
+}
+
+// TestPathway tests that finishing a stage queues the entry for the next one
+func TestPathway(t *testing.T) {
+	cfg := &config.Config{Rooms: config.RoomsConfig{DefaultRoom: "registration"}}
+	configService := &stubConfigService{pathways: []types.Pathway{{
+		ID:         "checkup",
+		ServiceIDs: []string{"svc-checkup"},
+		Stages: []types.PathwayStage{
+			{Name: "registration", RoomID: "registration", ServicePointID: "desk-1"},
+			{Name: "nurse", RoomID: "nurse"},
+			{Name: "doctor", RoomID: "doctor", ServicePointID: "office-2"},
+		},
+	}}}
+
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, cfg, nil, nil)
+	wq.SetConfigService(configService)
+
+	var advanced []string
+	wq.SetStageAdvanceHandler(func(ctx context.Context, entry *Entry, fromRoomId string) {
+		advanced = append(advanced, fromRoomId+"->"+entry.WaitingRoomID)
+	})
+
+	ctx := context.Background()
+	entry := &types.Entry{WaitingRoomID: "registration", Status: "WAITING", Tier: 1, FitnessScore: 42}
+	if err := mockRepo.CreateEntry(ctx, entry); err != nil {
+		t.Fatalf("CreateEntry failed: %v", err)
+	}
+	ticket := entry.TicketNumber
+
+	started, err := wq.StartPathway(ctx, entry, "", "svc-checkup")
+	if err != nil || !started {
+		t.Fatalf("Expected pathway to start, got %t, %v", started, err)
+	}
+	if entry.PathwayID != "checkup" || entry.PathwayStage != 0 || entry.ServicePoint != "desk-1" {
+		t.Fatalf("Expected stage 0 of checkup at desk-1, got %s/%d at '%s'", entry.PathwayID, entry.PathwayStage, entry.ServicePoint)
+	}
+
+	// registration -> nurse
+	called, err := wq.CallNextForServicePoint(ctx, "registration", "desk-1")
+	if err != nil || called.ID != entry.ID {
+		t.Fatalf("Expected entry to be called at registration, got %v", err)
+	}
+	finished, err := wq.FinishCurrentForServicePoint(ctx, "registration", "desk-1")
+	if err != nil {
+		t.Fatalf("FinishCurrentForServicePoint failed: %v", err)
+	}
+	if finished.WaitingRoomID != "nurse" || string(finished.Status) != "WAITING" {
+		t.Fatalf("Expected entry to wait in nurse, got %s (%s)", finished.WaitingRoomID, finished.Status)
+	}
+
+	// nurse -> doctor
+	if _, err := wq.CallNext(ctx, "nurse"); err != nil {
+		t.Fatalf("CallNext failed: %v", err)
+	}
+	if _, err := wq.FinishCurrent("nurse"); err != nil {
+		t.Fatalf("FinishCurrent failed: %v", err)
+	}
+
+	stored, err := mockRepo.GetEntryByID(ctx, entry.ID)
+	if err != nil {
+		t.Fatalf("GetEntryByID failed: %v", err)
+	}
+	if stored.WaitingRoomID != "doctor" || stored.ServicePoint != "office-2" || stored.PathwayStage != 2 || stored.Status != "WAITING" {
+		t.Fatalf("Expected entry to wait in doctor at office-2 (stage 2), got %s at '%s' (stage %d, %s)",
+			stored.WaitingRoomID, stored.ServicePoint, stored.PathwayStage, stored.Status)
+	}
+	if stored.TicketNumber != ticket || stored.Tier != 1 || stored.FitnessScore != 42 {
+		t.Errorf("Expected ticket and priority to be inherited, got %s tier %d score %v", stored.TicketNumber, stored.Tier, stored.FitnessScore)
+	}
+
+	// doctor is the last stage
+	if _, err := wq.CallNextForServicePoint(ctx, "doctor", "office-2"); err != nil {
+		t.Fatalf("CallNextForServicePoint failed: %v", err)
+	}
+	if _, err := wq.FinishCurrentForServicePoint(ctx, "doctor", "office-2"); err != nil {
+		t.Fatalf("FinishCurrentForServicePoint failed: %v", err)
+	}
+	stored, _ = mockRepo.GetEntryByID(ctx, entry.ID)
+	if stored.Status != "COMPLETED" {
+		t.Errorf("Expected entry to be completed after the last stage, got %s", stored.Status)
+	}
+
+	if len(advanced) != 2 || advanced[0] != "registration->nurse" || advanced[1] != "nurse->doctor" {
+		t.Errorf("Expected two stage advances, got %v", advanced)
+	}
+}
+
+// TestStartPathwayWithoutMatch tests that entries of other services stay where they are
+func TestStartPathwayWithoutMatch(t *testing.T) {
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+	wq.SetConfigService(&stubConfigService{pathways: []types.Pathway{{
+		ID:         "checkup",
+		ServiceIDs: []string{"svc-checkup"},
+		Stages:     []types.PathwayStage{{Name: "nurse", RoomID: "nurse"}},
+	}}})
+
+	ctx := context.Background()
+	entry := &types.Entry{WaitingRoomID: "registration", Status: "WAITING"}
+	_ = mockRepo.CreateEntry(ctx, entry)
+
+	started, err := wq.StartPathway(ctx, entry, "", "svc-other")
+	if err != nil || started {
+		t.Fatalf("Expected no pathway, got %t, %v", started, err)
+	}
+	if entry.WaitingRoomID != "registration" || entry.PathwayID != "" {
+		t.Errorf("Expected entry to stay in registration, got %s (pathway '%s')", entry.WaitingRoomID, entry.PathwayID)
+	}
+
+	if _, err := wq.StartPathway(ctx, entry, "unknown", ""); err == nil {
+		t.Error("Expected an error for an unknown pathway")
+	}
+}
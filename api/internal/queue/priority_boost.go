@@ -0,0 +1,79 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/priority"
+)
+
+var (
+	// ErrInvalidBoost is returned when a manual priority override cannot be applied to an entry
+	ErrInvalidBoost = errors.New("invalid priority boost")
+	// ErrNotPermitted is returned when the acting manager may not change the queue of a room
+	ErrNotPermitted = errors.New("not permitted")
+)
+
+// BoostEntryPriority sets the manual override of a WAITING entry of roomId, e.g. to call an
+// urgent patient earlier, and recalculates its fitness score and the room's positions. Only a
+// manager logged in at a service point of the room may do so; the change is recorded in the
+// entry history with managerID and reason.
+func (s *WaitingQueue) BoostEntryPriority(ctx context.Context, roomId, entryId string, manualOverride float64, reason, managerID string) (*Entry, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required", ErrInvalidBoost)
+	}
+	if s.servicePointSvc == nil || !s.servicePointSvc.IsManagerActive(managerID, roomId) {
+		return nil, fmt.Errorf("%w: manager %s is not logged in at a service point of room %s", ErrNotPermitted, managerID, roomId)
+	}
+
+	// The repositories report a missing entry as an error
+	entry, err := s.repo.GetEntryByID(ctx, entryId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEntryNotFound, err)
+	}
+	if entry == nil || entry.WaitingRoomID != roomId {
+		return nil, fmt.Errorf("%w: %s in room %s", ErrEntryNotFound, entryId, roomId)
+	}
+	if entry.Status != "WAITING" {
+		return nil, fmt.Errorf("%w: only waiting entries can be boosted, entry is %s", ErrInvalidBoost, entry.Status)
+	}
+
+	priorityConfig := s.loadPriorityConfig(ctx, entry.TenantID, entry.SectionID)
+	if !priorityConfig.PriorityModel.Fitness.Contributions.ManualOverride.Enabled {
+		return nil, fmt.Errorf("%w: manual override is disabled in the priority configuration", ErrInvalidBoost)
+	}
+
+	result := priority.NewCalculator(priorityConfig).Calculate(priority.CalculationInput{
+		Symbols:         entry.Symbols,
+		AppointmentTime: entry.AppointmentTime,
+		Age:             entry.Age,
+		ManualOverride:  &manualOverride,
+		ArrivalTime:     entry.CreatedAt,
+		CurrentTime:     time.Now(),
+	})
+	if err := s.repo.UpdateEntryManualOverride(ctx, entry.ID, manualOverride, result.FitnessScore, entryFitnessBreakdown(result)); err != nil {
+		return nil, fmt.Errorf("failed to update manual override: %w", err)
+	}
+
+	if err := s.repo.RecalculatePositions(ctx, roomId); err != nil {
+		log.Printf("Warning: Failed to recalculate positions after boosting entry: %v", err)
+	}
+
+	if updated, err := s.repo.GetEntryByID(ctx, entry.ID); err == nil && updated != nil {
+		entry = updated
+	} else {
+		entry.ManualOverride = &manualOverride
+		entry.FitnessScore = result.FitnessScore
+		entry.FitnessBreakdown = entryFitnessBreakdown(result)
+	}
+	s.recordEvent(ctx, entry, entry.Status, "priority_boost", managerID, reason)
+
+	log.Printf("[WaitingQueue] Manager %s set manual override %.2f on entry %s (ticket %s) in room %s: %s (fitness score: %.2f, position: %d)",
+		managerID, manualOverride, entry.ID, entry.TicketNumber, roomId, reason, entry.FitnessScore, entry.Position)
+	return entry, nil
+}
@@ -0,0 +1,84 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/service/servicepoint"
+)
+
+// TestBoostEntryPriority tests that a logged-in manager can move a waiting entry up and the reason is recorded
+func TestBoostEntryPriority(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{}
+	servicePointSvc := servicepoint.NewService(cfg)
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, cfg, servicePointSvc, nil)
+	historyRepo := repository.NewMockEntryHistoryRepository()
+	wq.SetHistoryRepository(historyRepo)
+
+	if _, err := wq.CreateEntry(ctx, "triage-1", CardData{IDNumber: "1"}, 300, "Service", nil, nil, nil, nil, ""); err != nil {
+		t.Fatalf("CreateEntry failed: %v", err)
+	}
+	second, err := wq.CreateEntry(ctx, "triage-1", CardData{IDNumber: "2"}, 300, "Service", nil, nil, nil, nil, "")
+	if err != nil {
+		t.Fatalf("CreateEntry failed: %v", err)
+	}
+
+	// A manager who is not logged in at the room cannot change priorities
+	if _, err := wq.BoostEntryPriority(ctx, "triage-1", second.ID, -100, "chest pain", "nurse-1"); !errors.Is(err, ErrNotPermitted) {
+		t.Fatalf("Expected ErrNotPermitted, got %v", err)
+	}
+
+	if err := servicePointSvc.SetManagerAvailable(ctx, "nurse-1", "triage-1", "window-1"); err != nil {
+		t.Fatalf("SetManagerAvailable failed: %v", err)
+	}
+	if _, err := wq.BoostEntryPriority(ctx, "triage-1", second.ID, -100, " ", "nurse-1"); !errors.Is(err, ErrInvalidBoost) {
+		t.Errorf("Expected ErrInvalidBoost without a reason, got %v", err)
+	}
+
+	boosted, err := wq.BoostEntryPriority(ctx, "triage-1", second.ID, -100, "chest pain", "nurse-1")
+	if err != nil {
+		t.Fatalf("BoostEntryPriority failed: %v", err)
+	}
+	if boosted.Position != 1 || boosted.ManualOverride == nil || *boosted.ManualOverride != -100 {
+		t.Errorf("Expected the boosted entry at position 1 with override -100, got position %d", boosted.Position)
+	}
+	if boosted.FitnessBreakdown == nil || boosted.FitnessBreakdown.ManualOverride >= 0 {
+		t.Errorf("Expected the manual override in the fitness breakdown, got %+v", boosted.FitnessBreakdown)
+	} else if total := boosted.FitnessBreakdown.SymbolWeights + boosted.FitnessBreakdown.WaitingTime +
+		boosted.FitnessBreakdown.AppointmentDeviation + boosted.FitnessBreakdown.Age + boosted.FitnessBreakdown.ManualOverride; total != boosted.FitnessScore {
+		t.Errorf("Expected the fitness breakdown to add up to %f, got %f", boosted.FitnessScore, total)
+	}
+
+	called, err := wq.CallNext(ctx, "triage-1")
+	if err != nil {
+		t.Fatalf("CallNext failed: %v", err)
+	}
+	if called.ID != second.ID {
+		t.Errorf("Expected the boosted entry to be called first, got %s", called.TicketNumber)
+	}
+	if _, err := wq.BoostEntryPriority(ctx, "triage-1", second.ID, -100, "chest pain", "nurse-1"); !errors.Is(err, ErrInvalidBoost) {
+		t.Errorf("Expected ErrInvalidBoost for a called entry, got %v", err)
+	}
+
+	history, err := historyRepo.GetEntryHistory(ctx, second.ID)
+	if err != nil {
+		t.Fatalf("GetEntryHistory failed: %v", err)
+	}
+	var boostEvents int
+	for _, event := range history {
+		if event.Event == "priority_boost" {
+			boostEvents++
+			if event.ActorID != "nurse-1" || event.Reason != "chest pain" || event.ToStatus != "WAITING" {
+				t.Errorf("Expected the boost by nurse-1 for chest pain, got '%s' for '%s'", event.ActorID, event.Reason)
+			}
+		}
+	}
+	if boostEvents != 1 {
+		t.Errorf("Expected 1 priority boost event, got %d", boostEvents)
+	}
+}
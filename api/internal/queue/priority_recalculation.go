@@ -0,0 +1,151 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/priority"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// RecalculatePriorities recomputes the fitness score of every WAITING entry, so the
+// waiting-time and appointment-deviation contributions keep growing while patients wait.
+// The tier is kept as stored: it only depends on the symbols, and a re-queued no-show
+// keeps its lower tier. Only starvation protection changes it, moving an entry that waited
+// past the threshold up one tier from the stored one, once. Positions are recalculated in
+// every room whose order changed; one entry of each such room and tenant is returned.
+func (s *WaitingQueue) RecalculatePriorities(ctx context.Context) ([]*Entry, error) {
+	entries, err := s.repo.GetWaitingEntries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waiting entries: %w", err)
+	}
+
+	now := time.Now()
+	calculators := make(map[string]*priority.Calculator)
+	rooms := make(map[string][]*Entry)
+	for _, entry := range entries {
+		tenantID := EntryTenantID(entry)
+		calculator, ok := calculators[tenantID]
+		if !ok {
+			calculator = priority.NewCalculator(s.loadPriorityConfig(ctx, entry.TenantID, entry.SectionID))
+			calculators[tenantID] = calculator
+		}
+
+		result := calculator.Calculate(priority.CalculationInput{
+			Symbols:         entry.Symbols,
+			AppointmentTime: entry.AppointmentTime,
+			Age:             entry.Age,
+			ManualOverride:  entry.ManualOverride,
+			ArrivalTime:     entry.CreatedAt,
+			CurrentTime:     now,
+		})
+		if result.FitnessScore != entry.FitnessScore {
+			breakdown := entryFitnessBreakdown(result)
+			if err := s.repo.UpdateEntryFitnessScore(ctx, entry.ID, result.FitnessScore, breakdown); err != nil {
+				log.Printf("[WaitingQueue] Failed to update fitness score of entry %s: %v", entry.ID, err)
+				continue
+			}
+			entry.FitnessScore = result.FitnessScore
+			entry.FitnessBreakdown = breakdown
+		}
+
+		if !entry.TierEscalated && calculator.ShouldEscalate(entry.CreatedAt, now) {
+			s.escalateEntry(ctx, calculator, entry, now)
+		}
+
+		key := entry.WaitingRoomID + "|" + tenantID
+		rooms[key] = append(rooms[key], entry)
+	}
+
+	var changed []*Entry
+	for _, roomEntries := range rooms {
+		if !orderChanged(roomEntries) {
+			continue
+		}
+		entry := roomEntries[0]
+		tenantCtx := context.WithValue(ctx, middleware.TENANT, EntryTenantID(entry))
+		if err := s.repo.RecalculatePositions(tenantCtx, entry.WaitingRoomID); err != nil {
+			log.Printf("[WaitingQueue] Failed to recalculate positions for room %s: %v", entry.WaitingRoomID, err)
+			continue
+		}
+		changed = append(changed, entry)
+		log.Printf("[WaitingQueue] Queue order of room %s (tenant: '%s') changed after priority recalculation", entry.WaitingRoomID, EntryTenantID(entry))
+	}
+
+	return changed, nil
+}
+
+// escalateEntry moves an entry that waited past the starvation threshold up one tier and
+// records the change in its history
+func (s *WaitingQueue) escalateEntry(ctx context.Context, calculator *priority.Calculator, entry *Entry, now time.Time) {
+	tier := calculator.EscalatedTier(entry.Tier)
+	if tier == entry.Tier {
+		return
+	}
+	if err := s.repo.EscalateEntryTier(ctx, entry.ID, tier); err != nil {
+		log.Printf("[WaitingQueue] Failed to escalate tier of entry %s: %v", entry.ID, err)
+		return
+	}
+
+	reason := fmt.Sprintf("waited %.0f minutes, moved from tier %d to tier %d", now.Sub(entry.CreatedAt).Minutes(), entry.Tier, tier)
+	entry.Tier = tier
+	entry.TierEscalated = true
+	s.recordEvent(ctx, entry, entry.Status, "tier_escalated", "", reason)
+	log.Printf("[WaitingQueue] Entry %s (ticket %s) in room %s escalated by starvation protection: %s", entry.ID, entry.TicketNumber, entry.WaitingRoomID, reason)
+}
+
+// loadPriorityConfig returns the priority configuration of a tenant, or the default one
+func (s *WaitingQueue) loadPriorityConfig(ctx context.Context, buildingID, sectionID string) *priority.PriorityConfig {
+	// If priority repo is nil (e.g., in tests), use default config
+	if s.priorityRepo == nil {
+		return priority.GetDefaultConfig()
+	}
+	priorityConfig, err := s.priorityRepo.GetConfig(ctx, buildingID, sectionID)
+	if err != nil {
+		log.Printf("Warning: Failed to load priority config, using default: %v", err)
+		return priority.GetDefaultConfig()
+	}
+	return priorityConfig
+}
+
+// entryFitnessBreakdown returns the breakdown of a calculation as stored on the entry
+func entryFitnessBreakdown(result priority.CalculationResult) *types.FitnessBreakdown {
+	return &types.FitnessBreakdown{
+		SymbolWeights:        result.Breakdown.SymbolWeights,
+		WaitingTime:          result.Breakdown.WaitingTime,
+		AppointmentDeviation: result.Breakdown.AppointmentDeviation,
+		Age:                  result.Breakdown.Age,
+		ManualOverride:       result.Breakdown.ManualOverride,
+		Starvation:           result.Breakdown.Starvation,
+	}
+}
+
+// orderChanged reports whether the stored positions of the entries of one room no longer
+// match their priority order (tier, fitness score, arrival time, ticket number)
+func orderChanged(entries []*Entry) bool {
+	sorted := make([]*Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Tier != b.Tier {
+			return a.Tier < b.Tier
+		}
+		if a.FitnessScore != b.FitnessScore {
+			return a.FitnessScore < b.FitnessScore
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.TicketNumber < b.TicketNumber
+	})
+	for i, entry := range sorted {
+		if entry.Position != int64(i+1) {
+			return true
+		}
+	}
+	return false
+}
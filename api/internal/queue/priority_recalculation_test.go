@@ -0,0 +1,56 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// TestRecalculatePriorities tests that waiting time keeps counting and reorders the queue
+func TestRecalculatePriorities(t *testing.T) {
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+	ctx := context.Background()
+
+	recent := &types.Entry{WaitingRoomID: "triage-1", Status: "WAITING", Position: 1, Tier: 1}
+	waitedLong := &types.Entry{WaitingRoomID: "triage-1", Status: "WAITING", Position: 2, Tier: 1}
+	requeued := &types.Entry{WaitingRoomID: "triage-1", Status: "WAITING", Position: 3, Tier: 2}
+	for _, entry := range []*types.Entry{recent, waitedLong, requeued} {
+		if err := mockRepo.CreateEntry(ctx, entry); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+	}
+	waitedLong.CreatedAt = time.Now().Add(-30 * time.Minute)
+	requeued.CreatedAt = time.Now().Add(-60 * time.Minute)
+
+	changed, err := wq.RecalculatePriorities(ctx)
+	if err != nil {
+		t.Fatalf("RecalculatePriorities failed: %v", err)
+	}
+	if len(changed) != 1 || changed[0].WaitingRoomID != "triage-1" {
+		t.Fatalf("Expected room triage-1 to be reported as changed, got %d rooms", len(changed))
+	}
+
+	if waitedLong.FitnessScore > -29 {
+		t.Errorf("Expected fitness score of about -30 after 30 minutes, got %.2f", waitedLong.FitnessScore)
+	}
+	if waitedLong.Position != 1 || recent.Position != 2 {
+		t.Errorf("Expected the longer waiting entry first, got positions %d and %d", waitedLong.Position, recent.Position)
+	}
+	if requeued.Tier != 2 || requeued.Position != 3 {
+		t.Errorf("Expected the requeued entry to keep tier 2 at position 3, got tier %d position %d", requeued.Tier, requeued.Position)
+	}
+
+	// Nothing moves when the order is already right
+	changed, err = wq.RecalculatePriorities(ctx)
+	if err != nil {
+		t.Fatalf("RecalculatePriorities failed: %v", err)
+	}
+	if len(changed) != 0 {
+		t.Errorf("Expected no changed rooms, got %d", len(changed))
+	}
+}
@@ -0,0 +1,131 @@
+package queue
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/service"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// NormalizePushSubscription validates a Web Push subscription of a browser: an https endpoint, a
+// P-256 public key and a 16 byte authentication secret, base64url with or without padding. The
+// keys are returned without padding.
+func NormalizePushSubscription(subscription types.PushSubscription) (*types.PushSubscription, error) {
+	endpoint, err := url.Parse(subscription.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return nil, fmt.Errorf("%w: push endpoint must be an https URL", ErrInvalidContact)
+	}
+	p256dh, err := decodeBase64URL(subscription.P256dh)
+	if err != nil || len(p256dh) != 65 || p256dh[0] != 4 {
+		return nil, fmt.Errorf("%w: p256dh must be an uncompressed P-256 public key", ErrInvalidContact)
+	}
+	auth, err := decodeBase64URL(subscription.Auth)
+	if err != nil || len(auth) != 16 {
+		return nil, fmt.Errorf("%w: auth must be a 16 byte secret", ErrInvalidContact)
+	}
+	return &types.PushSubscription{
+		Endpoint: subscription.Endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(p256dh),
+		Auth:     base64.RawURLEncoding.EncodeToString(auth),
+	}, nil
+}
+
+func decodeBase64URL(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
+
+// SetPushSubscriptionByQRToken sets the Web Push subscription of the QR status page of a WAITING
+// entry; nil removes it. Like CancelEntryByQRToken it uses the entry's own tenant.
+func (s *WaitingQueue) SetPushSubscriptionByQRToken(ctx context.Context, qrToken string, subscription *types.PushSubscription) (*Entry, error) {
+	if subscription != nil {
+		normalized, err := NormalizePushSubscription(*subscription)
+		if err != nil {
+			return nil, err
+		}
+		subscription = normalized
+	}
+
+	// The repositories report an unknown token as an error
+	entry, err := s.repo.GetEntryByQRToken(ctx, qrToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEntryNotFound, err)
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("%w: unknown QR token", ErrEntryNotFound)
+	}
+	if subscription != nil && entry.Status != "WAITING" {
+		return nil, fmt.Errorf("%w: entry %s is %s", ErrCannotRegisterContact, entry.ID, entry.Status)
+	}
+
+	tenantCtx := context.WithValue(ctx, middleware.TENANT, EntryTenantID(entry))
+	if err := s.repo.UpdateEntryPushSubscription(tenantCtx, entry.ID, subscription); err != nil {
+		return nil, fmt.Errorf("failed to update entry push subscription: %w", err)
+	}
+	entry.PushSubscription = subscription
+	return entry, nil
+}
+
+// DropPushSubscription removes the Web Push subscription of an entry, e.g. after the push service
+// reported that it expired
+func (s *WaitingQueue) DropPushSubscription(ctx context.Context, entryId string) error {
+	return s.repo.UpdateEntryPushSubscription(ctx, entryId, nil)
+}
+
+// PushMilestoneEntries returns the WAITING entries of a room with a push subscription that reached
+// a people-ahead milestone smaller than the last one pushed to them, with PushMilestone set to it,
+// and records that they got it. An entry passing several milestones at once gets only the
+// smallest; each milestone is only returned once, also across replicas. Like ApproachingEntries,
+// only entries of the tenant in the context itself are returned.
+func (s *WaitingQueue) PushMilestoneEntries(ctx context.Context, roomId string, milestones []int) ([]*Entry, error) {
+	if len(milestones) == 0 {
+		return nil, nil
+	}
+	sorted := append([]int(nil), milestones...)
+	sort.Ints(sorted)
+
+	entries, err := s.repo.GetQueueEntries(ctx, roomId, []string{"WAITING"})
+	if err != nil {
+		return nil, err
+	}
+
+	tenantID := service.GetTenantID(ctx)
+	var reached []*Entry
+	for _, entry := range entries {
+		if entry.PushSubscription == nil {
+			continue
+		}
+		if tenantID != "" && EntryTenantID(entry) != tenantID {
+			continue
+		}
+
+		peopleAhead := int(entry.Position - 1)
+		milestone := -1
+		for _, m := range sorted {
+			if m >= peopleAhead {
+				milestone = m
+				break
+			}
+		}
+		if milestone < 0 || (entry.PushMilestone != nil && *entry.PushMilestone <= milestone) {
+			continue
+		}
+
+		marked, err := s.repo.MarkEntryPushMilestone(ctx, entry.ID, milestone)
+		if err != nil {
+			log.Printf("Warning: Failed to mark push milestone of entry %s: %v", entry.ID, err)
+			continue
+		}
+		if marked {
+			entry.PushMilestone = &milestone
+			reached = append(reached, entry)
+		}
+	}
+	return reached, nil
+}
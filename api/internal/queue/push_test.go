@@ -0,0 +1,102 @@
+package queue
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// testPushSubscription returns a push subscription with well-formed keys
+func testPushSubscription() *types.PushSubscription {
+	p256dh := make([]byte, 65)
+	p256dh[0] = 4
+	return &types.PushSubscription{
+		Endpoint: "https://push.example.com/send/abc",
+		P256dh:   base64.URLEncoding.EncodeToString(p256dh),
+		Auth:     base64.RawURLEncoding.EncodeToString(make([]byte, 16)),
+	}
+}
+
+// TestNormalizePushSubscription tests the validation of Web Push subscriptions
+func TestNormalizePushSubscription(t *testing.T) {
+	subscription, err := NormalizePushSubscription(*testPushSubscription())
+	if err != nil {
+		t.Fatalf("NormalizePushSubscription failed: %v", err)
+	}
+	if len(subscription.P256dh) != 87 {
+		t.Errorf("Expected the p256dh key without padding, got %q", subscription.P256dh)
+	}
+
+	invalid := []func(s *types.PushSubscription){
+		func(s *types.PushSubscription) { s.Endpoint = "http://push.example.com/send/abc" },
+		func(s *types.PushSubscription) { s.P256dh = "c2hvcnQ" },
+		func(s *types.PushSubscription) { s.Auth = "not base64!" },
+	}
+	for i, change := range invalid {
+		subscription := testPushSubscription()
+		change(subscription)
+		if _, err := NormalizePushSubscription(*subscription); !errors.Is(err, ErrInvalidContact) {
+			t.Errorf("Case %d: expected ErrInvalidContact, got %v", i, err)
+		}
+	}
+}
+
+// TestPushMilestoneEntries tests that subscribed entries get each milestone once, the smallest one they passed
+func TestPushMilestoneEntries(t *testing.T) {
+	ctx := context.WithValue(context.Background(), middleware.TENANT, "hospital-a:cardiology")
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+
+	var entries []*Entry
+	for _, id := range []string{"1", "2", "3", "4"} {
+		entry, err := wq.CreateEntry(ctx, "triage-1", CardData{IDNumber: id}, 300, "Service", nil, nil, nil, nil, "")
+		if err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	// The phone page has no tenant header
+	if _, err := wq.SetPushSubscriptionByQRToken(context.Background(), entries[3].QRToken, testPushSubscription()); err != nil {
+		t.Fatalf("SetPushSubscriptionByQRToken failed: %v", err)
+	}
+
+	// Three people ahead: only the milestone 5
+	reached, err := wq.PushMilestoneEntries(ctx, "triage-1", []int{1, 5})
+	if err != nil {
+		t.Fatalf("PushMilestoneEntries failed: %v", err)
+	}
+	if len(reached) != 1 || *reached[0].PushMilestone != 5 {
+		t.Fatalf("Expected the fourth entry at milestone 5, got %d entries", len(reached))
+	}
+	if again, _ := wq.PushMilestoneEntries(ctx, "triage-1", []int{1, 5}); len(again) != 0 {
+		t.Errorf("Expected milestone 5 only once, got %d entries", len(again))
+	}
+
+	// Two entries leave the queue at once: the fourth entry has one person ahead
+	for _, entry := range entries[:2] {
+		if _, err := wq.CancelEntryByQRToken(context.Background(), entry.QRToken); err != nil {
+			t.Fatalf("CancelEntryByQRToken failed: %v", err)
+		}
+	}
+	reached, err = wq.PushMilestoneEntries(ctx, "triage-1", []int{1, 5})
+	if err != nil {
+		t.Fatalf("PushMilestoneEntries failed: %v", err)
+	}
+	if len(reached) != 1 || *reached[0].PushMilestone != 1 {
+		t.Errorf("Expected the fourth entry at milestone 1, got %d entries", len(reached))
+	}
+
+	// Unsubscribing works whatever the status, subscribing only while waiting
+	if _, err := wq.SetPushSubscriptionByQRToken(context.Background(), entries[0].QRToken, testPushSubscription()); !errors.Is(err, ErrCannotRegisterContact) {
+		t.Errorf("Expected ErrCannotRegisterContact for a cancelled entry, got %v", err)
+	}
+	if _, err := wq.SetPushSubscriptionByQRToken(context.Background(), entries[0].QRToken, nil); err != nil {
+		t.Errorf("Expected unsubscribing a cancelled entry to work, got %v", err)
+	}
+}
@@ -10,51 +10,66 @@ import (
 func (s *WaitingQueue) CallNext(ctx context.Context, roomId string) (*Entry, error) {
 	log.Printf("CallNext: Starting for room %s", roomId)
 
-	// First, complete any currently served person
-	currentEntry, err := s.repo.GetCurrentServedEntry(ctx, roomId)
-	if err != nil {
-		log.Printf("CallNext: Failed to get current served entry: %v", err)
-		return nil, fmt.Errorf("failed to get current served entry: %w", err)
+	if err := s.checkCallable(ctx, roomId, ""); err != nil {
+		return nil, err
 	}
 
-	if currentEntry != nil {
-		log.Printf("CallNext: Found current entry %s, completing it", currentEntry.ID)
-		// Complete the current person
-		if err := s.repo.UpdateEntryStatus(ctx, currentEntry.ID, "COMPLETED"); err != nil {
-			log.Printf("CallNext: Failed to complete current entry: %v", err)
-			return nil, fmt.Errorf("failed to complete current entry: %w", err)
+	// Completing the current person, calling the next one and the new positions are stored together
+	var nextEntry *Entry
+	err := s.repo.WithTransaction(ctx, func(ctx context.Context) error {
+		// First, complete any currently served person
+		currentEntry, err := s.repo.GetCurrentServedEntry(ctx, roomId)
+		if err != nil {
+			log.Printf("CallNext: Failed to get current served entry: %v", err)
+			return fmt.Errorf("failed to get current served entry: %w", err)
 		}
-		log.Printf("Completed current entry %s", currentEntry.ID)
-	} else {
-		log.Printf("CallNext: No current entry found")
-	}
 
-	// Get the next waiting person
-	log.Printf("CallNext: Getting next waiting entry")
-	nextEntry, err := s.repo.GetNextWaitingEntry(ctx, roomId)
-	if err != nil {
-		log.Printf("CallNext: Failed to get next waiting entry: %v", err)
-		return nil, fmt.Errorf("failed to get next waiting entry: %w", err)
-	}
+		if currentEntry != nil {
+			log.Printf("CallNext: Found current entry %s, completing it", currentEntry.ID)
+			// Complete the current person
+			if err := s.completeEntry(ctx, currentEntry); err != nil {
+				log.Printf("CallNext: Failed to complete current entry: %v", err)
+				return fmt.Errorf("failed to complete current entry: %w", err)
+			}
+			log.Printf("Completed current entry %s", currentEntry.ID)
+		} else {
+			log.Printf("CallNext: No current entry found")
+		}
 
-	if nextEntry == nil {
-		log.Printf("CallNext: No waiting entries found")
-		return nil, fmt.Errorf("no waiting entries found")
-	}
+		// Get the next waiting person
+		log.Printf("CallNext: Getting next waiting entry")
+		nextEntry, err = s.repo.GetNextWaitingEntry(ctx, roomId)
+		if err != nil {
+			log.Printf("CallNext: Failed to get next waiting entry: %v", err)
+			return fmt.Errorf("failed to get next waiting entry: %w", err)
+		}
 
-	log.Printf("CallNext: Found next entry %s, calling them", nextEntry.ID)
+		if nextEntry == nil {
+			log.Printf("CallNext: No waiting entries found")
+			return fmt.Errorf("no waiting entries found")
+		}
 
-	// Call the next person
-	if err := s.repo.UpdateEntryStatus(ctx, nextEntry.ID, "CALLED"); err != nil {
-		log.Printf("CallNext: Failed to update entry status: %v", err)
-		return nil, fmt.Errorf("failed to call next entry: %w", err)
-	}
+		log.Printf("CallNext: Found next entry %s, calling them", nextEntry.ID)
+
+		// Call the next person
+		previousStatus := nextEntry.Status
+		if err := s.repo.UpdateEntryStatus(ctx, nextEntry.ID, "CALLED", nextEntry.Version); err != nil {
+			log.Printf("CallNext: Failed to update entry status: %v", err)
+			return fmt.Errorf("failed to call next entry: %w", err)
+		}
+		nextEntry.Status = "CALLED"
+		s.recordTransition(ctx, nextEntry, previousStatus, "called")
 
-	log.Printf("CallNext: Successfully called entry %s", nextEntry.ID)
+		log.Printf("CallNext: Successfully called entry %s", nextEntry.ID)
 
-	// Recalculate positions for remaining waiting entries
-	if err := s.repo.RecalculatePositions(ctx, roomId); err != nil {
-		log.Printf("Warning: Failed to recalculate positions: %v", err)
+		// Recalculate positions for remaining waiting entries
+		if err := s.repo.RecalculatePositions(ctx, roomId); err != nil {
+			return fmt.Errorf("failed to recalculate positions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	log.Printf("Called next entry %s with ticket %s", nextEntry.ID, nextEntry.TicketNumber)
@@ -75,14 +90,20 @@ func (s *WaitingQueue) FinishCurrent(roomId string) (*Entry, error) {
 		return nil, fmt.Errorf("no one is currently being served")
 	}
 
-	// Complete the current person
-	if err := s.repo.UpdateEntryStatus(ctx, currentEntry.ID, "COMPLETED"); err != nil {
-		return nil, fmt.Errorf("failed to complete current entry: %w", err)
-	}
+	err = s.repo.WithTransaction(ctx, func(ctx context.Context) error {
+		// Complete the current person
+		if err := s.completeEntry(ctx, currentEntry); err != nil {
+			return fmt.Errorf("failed to complete current entry: %w", err)
+		}
 
-	// Recalculate positions for remaining waiting entries
-	if err := s.repo.RecalculatePositions(ctx, roomId); err != nil {
-		log.Printf("Warning: Failed to recalculate positions: %v", err)
+		// Recalculate positions for remaining waiting entries
+		if err := s.repo.RecalculatePositions(ctx, roomId); err != nil {
+			return fmt.Errorf("failed to recalculate positions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	log.Printf("Finished current entry %s with ticket %s", currentEntry.ID, currentEntry.TicketNumber)
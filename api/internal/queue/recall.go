@@ -0,0 +1,63 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// ErrInvalidRecall is returned when an entry is not in a state it can be recalled from
+var ErrInvalidRecall = errors.New("invalid recall")
+
+// RecallEntry puts a NO_SHOW, SKIPPED or COMPLETED entry of roomId back into WAITING with
+// its ticket, e.g. when a patient returns after missing the call. tierAdjustment is added
+// to the priority tier (nil = configured default); the tier never goes below 0.
+func (s *WaitingQueue) RecallEntry(ctx context.Context, roomId, entryId string, tierAdjustment *int) (*Entry, error) {
+	// The repositories report a missing entry as an error
+	entry, err := s.repo.GetEntryByID(ctx, entryId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEntryNotFound, err)
+	}
+
+	if entry == nil || entry.WaitingRoomID != roomId {
+		return nil, fmt.Errorf("%w: %s in room %s", ErrEntryNotFound, entryId, roomId)
+	}
+
+	switch entry.Status {
+	case "NO_SHOW", "SKIPPED", "COMPLETED":
+	default:
+		return nil, fmt.Errorf("%w: entry cannot be recalled in status %s", ErrInvalidRecall, entry.Status)
+	}
+
+	adjustment := s.config.Queue.RecallTierAdjustment
+	if tierAdjustment != nil {
+		adjustment = *tierAdjustment
+	}
+	tier := entry.Tier + adjustment
+	if tier < 0 {
+		tier = 0
+	}
+
+	previousStatus := entry.Status
+	if err := s.repo.RequeueEntry(ctx, entry.ID, tier); err != nil {
+		return nil, fmt.Errorf("failed to recall entry: %w", err)
+	}
+
+	if err := s.repo.RecalculatePositions(ctx, roomId); err != nil {
+		log.Printf("Warning: Failed to recalculate positions after recalling entry: %v", err)
+	}
+
+	if updated, err := s.repo.GetEntryByID(ctx, entry.ID); err == nil && updated != nil {
+		entry = updated
+	} else {
+		entry.Status = "WAITING"
+		entry.ServicePoint = ""
+	}
+	s.recordTransition(ctx, entry, previousStatus, "recalled")
+
+	log.Printf("RecallEntry: Recalled entry %s (ticket %s) from %s in room %s (tier: %d, position: %d)",
+		entry.ID, entry.TicketNumber, previousStatus, roomId, entry.Tier, entry.Position)
+
+	return entry, nil
+}
@@ -0,0 +1,64 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// TestRecallEntry tests recalling entries with the configured and requested tier adjustments
+func TestRecallEntry(t *testing.T) {
+	cfg := &config.Config{Queue: config.QueueConfig{RecallTierAdjustment: 1}}
+
+	tests := []struct {
+		name           string
+		status         string
+		tierAdjustment *int
+		expectedTier   int
+		expectedErr    error
+	}{
+		{name: "no-show gets the configured penalty", status: "NO_SHOW", expectedTier: 3},
+		{name: "completed by mistake is boosted", status: "COMPLETED", tierAdjustment: intPtr(-1), expectedTier: 1},
+		{name: "tier does not go below 0", status: "SKIPPED", tierAdjustment: intPtr(-5), expectedTier: 0},
+		{name: "waiting entry cannot be recalled", status: "WAITING", expectedErr: ErrInvalidRecall},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := repository.NewMockQueueRepository()
+			wq := NewWaitingQueue(mockRepo, cfg, nil, nil)
+			ctx := context.Background()
+
+			entry := &types.Entry{WaitingRoomID: "triage-1", Status: tt.status, ServicePoint: "window-1", Tier: 2}
+			if err := mockRepo.CreateEntry(ctx, entry); err != nil {
+				t.Fatalf("CreateEntry failed: %v", err)
+			}
+			ticket := entry.TicketNumber
+
+			recalled, err := wq.RecallEntry(ctx, "triage-1", entry.ID, tt.tierAdjustment)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("Expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RecallEntry failed: %v", err)
+			}
+
+			if recalled.Status != "WAITING" || recalled.ServicePoint != "" {
+				t.Errorf("Expected WAITING without service point, got %s at '%s'", recalled.Status, recalled.ServicePoint)
+			}
+			if recalled.Tier != tt.expectedTier {
+				t.Errorf("Expected tier %d, got %d", tt.expectedTier, recalled.Tier)
+			}
+			if recalled.TicketNumber != ticket {
+				t.Errorf("Expected ticket %s to be kept, got %s", ticket, recalled.TicketNumber)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package queue
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// ErrInvalidIdentifier is returned when an erasure request names no data subject
+var ErrInvalidIdentifier = errors.New("invalid data subject identifier")
+
+// ApplyRetentionPolicies purges the card data of the finished entries, queued or archived, of
+// every tenant whose retention policy period has passed since the entry last changed. It returns
+// the number of entries anonymized.
+func (s *WaitingQueue) ApplyRetentionPolicies(ctx context.Context, now time.Time) (int64, error) {
+	if s.configService == nil {
+		return 0, nil
+	}
+	policies, err := s.configService.GetRetentionPolicies(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get retention policies: %w", err)
+	}
+
+	var anonymized int64
+	for tenantID, policy := range policies {
+		buildingID, sectionID := "", ""
+		if tenantID != "" {
+			buildingID, sectionID, _ = types.ParseTenantID(tenantID)
+		}
+		before := now.AddDate(0, 0, -policy.PurgeAfterDays)
+		entries, err := s.repo.GetEntriesToAnonymize(ctx, buildingID, sectionID, before, ArchivedStatuses)
+		if err != nil {
+			log.Printf("[WaitingQueue] Failed to get entries to anonymize of tenant '%s': %v", tenantID, err)
+			continue
+		}
+
+		for _, entry := range entries {
+			cardData, idNumberHash := s.purgeCardData(entry.CardData, policy)
+			if err := s.repo.AnonymizeEntry(ctx, entry.ID, cardData, idNumberHash); err != nil {
+				log.Printf("[WaitingQueue] Failed to anonymize entry %s: %v", entry.ID, err)
+				continue
+			}
+			anonymized++
+		}
+		if len(entries) > 0 {
+			log.Printf("[WaitingQueue] Anonymized %d entries of tenant '%s' finished before %s", len(entries), tenantID, before.Format(time.RFC3339))
+		}
+	}
+	return anonymized, nil
+}
+
+// purgeCardData returns card without the fields the policy purges (all of them when it names
+// none) and, when the policy keeps it, the hash of the ID number
+func (s *WaitingQueue) purgeCardData(card CardData, policy types.RetentionPolicy) (CardData, string) {
+	idNumberHash := ""
+	if policy.KeepHashedIDNumber {
+		idNumberHash = s.HashIdentifier(card.IDNumber)
+	}
+	if len(policy.PurgeFields) == 0 {
+		return CardData{}, idNumberHash
+	}
+
+	for _, field := range policy.PurgeFields {
+		switch field {
+		case "idNumber":
+			card.IDNumber = ""
+		case "firstName":
+			card.FirstName = ""
+		case "lastName":
+			card.LastName = ""
+		case "dateOfBirth":
+			card.DateOfBirth = ""
+		case "gender":
+			card.Gender = ""
+		case "nationality":
+			card.Nationality = ""
+		case "address":
+			card.Address = ""
+		case "issuedDate":
+			card.IssuedDate = ""
+		case "expiryDate":
+			card.ExpiryDate = ""
+		case "photo":
+			card.Photo = ""
+		case "insuranceNumber":
+			card.InsuranceNumber = ""
+		case "insurerCode":
+			card.InsurerCode = ""
+		case "insurerName":
+			card.InsurerName = ""
+		case "insuranceValidFrom":
+			card.InsuranceValidFrom = ""
+		case "insuranceValidTo":
+			card.InsuranceValidTo = ""
+		}
+	}
+	return card, idNumberHash
+}
+
+// HashIdentifier returns the HMAC-SHA256 of an ID number with privacy.id_hash_key (plain SHA-256
+// without a key), so anonymized entries of the same patient can still be matched. Empty for none.
+func (s *WaitingQueue) HashIdentifier(identifier string) string {
+	if identifier == "" {
+		return ""
+	}
+	key := ""
+	if s.config != nil {
+		key = s.config.Privacy.IDHashKey
+	}
+	if key == "" {
+		sum := sha256.Sum256([]byte(identifier))
+		return hex.EncodeToString(sum[:])
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(identifier))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// EraseDataSubject deletes the queued and archived entries of the tenant in the context whose card
+// has identifier as ID or insurance number, anonymized ones included, and their history, for a
+// data subject erasure request. The rooms of deleted WAITING entries get their positions
+// recalculated. It returns the deleted entries.
+func (s *WaitingQueue) EraseDataSubject(ctx context.Context, identifier string) ([]*Entry, error) {
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return nil, fmt.Errorf("%w: identifier must not be empty", ErrInvalidIdentifier)
+	}
+
+	deleted, err := s.repo.DeleteEntriesByIdentifier(ctx, identifier, s.HashIdentifier(identifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete entries: %w", err)
+	}
+	if len(deleted) == 0 {
+		return deleted, nil
+	}
+
+	if s.historyRepo != nil {
+		ids := make([]string, 0, len(deleted))
+		for _, entry := range deleted {
+			ids = append(ids, entry.ID)
+		}
+		if _, err := s.historyRepo.DeleteEntryHistory(ctx, ids); err != nil {
+			return deleted, fmt.Errorf("failed to delete entry history: %w", err)
+		}
+	}
+
+	recalculated := make(map[string]bool)
+	for _, entry := range deleted {
+		key := entry.WaitingRoomID + "|" + EntryTenantID(entry)
+		if entry.Status != "WAITING" || recalculated[key] {
+			continue
+		}
+		recalculated[key] = true
+		tenantCtx := context.WithValue(ctx, middleware.TENANT, EntryTenantID(entry))
+		if err := s.repo.RecalculatePositions(tenantCtx, entry.WaitingRoomID); err != nil {
+			log.Printf("Warning: Failed to recalculate positions of room %s after an erasure: %v", entry.WaitingRoomID, err)
+		}
+	}
+
+	log.Printf("[WaitingQueue] Erased %d entries of a data subject", len(deleted))
+	return deleted, nil
+}
+
+// PurgeTenantEntries deletes the queued and archived entries of the tenant in the context, their
+// history and the ticket counters and room states of the tenant, once the tenant was deactivated
+// for the retention period. It returns how many entries were deleted.
+func (s *WaitingQueue) PurgeTenantEntries(ctx context.Context) (int, error) {
+	deleted, err := s.repo.DeleteTenantEntries(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete entries: %w", err)
+	}
+	if len(deleted) > 0 && s.historyRepo != nil {
+		if _, err := s.historyRepo.DeleteEntryHistory(ctx, deleted); err != nil {
+			return len(deleted), fmt.Errorf("failed to delete entry history: %w", err)
+		}
+	}
+	if err := s.repo.DeleteTenantRoomData(ctx); err != nil {
+		return len(deleted), fmt.Errorf("failed to delete room data: %w", err)
+	}
+	return len(deleted), nil
+}
@@ -0,0 +1,185 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// TestApplyRetentionPolicies tests that only the card data of old finished entries of tenants with a policy is purged
+func TestApplyRetentionPolicies(t *testing.T) {
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{Privacy: config.PrivacyConfig{IDHashKey: "secret"}}, nil, nil)
+	wq.SetConfigService(&stubConfigService{retention: map[string]types.RetentionPolicy{
+		"hospital:er": {PurgeAfterDays: 30, PurgeFields: []string{"idNumber", "photo", "address"}, KeepHashedIDNumber: true},
+	}})
+	ctx := context.Background()
+
+	card := types.CardData{IDNumber: "8001011234", FirstName: "Jana", Photo: "base64", Address: "Main 1"}
+	old := &types.Entry{WaitingRoomID: "triage-1", TenantID: "hospital", SectionID: "er", Status: "COMPLETED", CardData: card, IdempotencyKey: "key"}
+	recent := &types.Entry{WaitingRoomID: "triage-1", TenantID: "hospital", SectionID: "er", Status: "COMPLETED", CardData: card}
+	waiting := &types.Entry{WaitingRoomID: "triage-1", TenantID: "hospital", SectionID: "er", Status: "WAITING", CardData: card}
+	otherTenant := &types.Entry{WaitingRoomID: "triage-1", TenantID: "clinic", Status: "COMPLETED", CardData: card}
+	for _, entry := range []*types.Entry{old, recent, waiting, otherTenant} {
+		if err := mockRepo.CreateEntry(ctx, entry); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+	}
+	for _, entry := range []*types.Entry{old, waiting, otherTenant} {
+		entry.UpdatedAt = time.Now().Add(-40 * 24 * time.Hour)
+	}
+
+	anonymized, err := wq.ApplyRetentionPolicies(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("ApplyRetentionPolicies failed: %v", err)
+	}
+	if anonymized != 1 {
+		t.Fatalf("Expected 1 anonymized entry, got %d", anonymized)
+	}
+
+	got, _ := mockRepo.GetEntryByID(ctx, old.ID)
+	if got.CardData.IDNumber != "" || got.CardData.Photo != "" || got.CardData.Address != "" {
+		t.Errorf("Expected purged fields to be empty, got %+v", got.CardData)
+	}
+	if got.CardData.FirstName != "Jana" {
+		t.Errorf("Expected fields outside the policy to be kept, got first name '%s'", got.CardData.FirstName)
+	}
+	if got.IDNumberHash != wq.HashIdentifier("8001011234") || got.IDNumberHash == "" {
+		t.Errorf("Expected the hashed ID number to be kept, got '%s'", got.IDNumberHash)
+	}
+	if got.IdempotencyKey != "" || got.AnonymizedAt == nil {
+		t.Errorf("Expected the swipe key to be dropped and the entry marked anonymized")
+	}
+
+	for _, entry := range []*types.Entry{recent, waiting, otherTenant} {
+		if got, _ := mockRepo.GetEntryByID(ctx, entry.ID); got.CardData.IDNumber != "8001011234" || got.AnonymizedAt != nil {
+			t.Errorf("Expected entry %s (%s, tenant %s) to keep its card data", entry.ID, entry.Status, entry.TenantID)
+		}
+	}
+}
+
+// TestEraseDataSubject tests that an erasure deletes the entries and history of a person, anonymized ones included
+func TestEraseDataSubject(t *testing.T) {
+	mockRepo := repository.NewMockQueueRepository()
+	historyRepo := repository.NewMockEntryHistoryRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+	wq.SetHistoryRepository(historyRepo)
+	ctx := context.WithValue(context.Background(), middleware.TENANT, "hospital:er")
+
+	anonymized := &types.Entry{WaitingRoomID: "triage-1", TenantID: "hospital", SectionID: "er", Status: "COMPLETED", IDNumberHash: wq.HashIdentifier("8001011234")}
+	byInsurance := &types.Entry{WaitingRoomID: "triage-1", TenantID: "hospital", SectionID: "er", Status: "NO_SHOW", CardData: types.CardData{InsuranceNumber: "8001011234"}}
+	waiting := &types.Entry{WaitingRoomID: "triage-1", TenantID: "hospital", SectionID: "er", Status: "WAITING", CardData: types.CardData{IDNumber: "8001011234"}}
+	other := &types.Entry{WaitingRoomID: "triage-1", TenantID: "hospital", SectionID: "er", Status: "WAITING", CardData: types.CardData{IDNumber: "9002025678"}}
+	for _, entry := range []*types.Entry{anonymized, byInsurance, waiting, other} {
+		if err := mockRepo.CreateEntry(ctx, entry); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+		historyRepo.AppendEntryHistory(ctx, &types.EntryHistoryEvent{EntryID: entry.ID, TenantID: "hospital", SectionID: "er", ToStatus: entry.Status})
+	}
+
+	deleted, err := wq.EraseDataSubject(ctx, " 8001011234 ")
+	if err != nil {
+		t.Fatalf("EraseDataSubject failed: %v", err)
+	}
+	if len(deleted) != 3 {
+		t.Fatalf("Expected 3 deleted entries, got %d", len(deleted))
+	}
+
+	for _, entry := range []*types.Entry{anonymized, byInsurance, waiting} {
+		if _, err := mockRepo.GetEntryByID(ctx, entry.ID); err == nil {
+			t.Errorf("Expected entry %s to be deleted", entry.ID)
+		}
+		if events, _ := historyRepo.GetEntryHistory(ctx, entry.ID); len(events) != 0 {
+			t.Errorf("Expected the history of entry %s to be deleted, got %d events", entry.ID, len(events))
+		}
+	}
+	if got, err := mockRepo.GetEntryByID(ctx, other.ID); err != nil || got.Position != 1 {
+		t.Errorf("Expected the other entry to stay and move up to position 1, got %+v (%v)", got, err)
+	}
+	if events, _ := historyRepo.GetEntryHistory(ctx, other.ID); len(events) != 1 {
+		t.Errorf("Expected the history of the other entry to be kept, got %d events", len(events))
+	}
+
+	if _, err := wq.EraseDataSubject(ctx, "  "); err == nil {
+		t.Errorf("Expected an empty identifier to be rejected")
+	}
+}
+
+// TestPurgeTenantEntries tests that purging a tenant deletes its queued and archived entries, their history
+// and its ticket counters and room states only
+func TestPurgeTenantEntries(t *testing.T) {
+	mockRepo := repository.NewMockQueueRepository()
+	historyRepo := repository.NewMockEntryHistoryRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+	wq.SetHistoryRepository(historyRepo)
+	ctx := context.WithValue(context.Background(), middleware.TENANT, "hospital:er")
+
+	waiting := &types.Entry{WaitingRoomID: "triage-1", TenantID: "hospital", SectionID: "er", Status: "WAITING"}
+	archived := &types.Entry{WaitingRoomID: "triage-1", TenantID: "hospital", SectionID: "er", Status: "COMPLETED"}
+	otherSection := &types.Entry{WaitingRoomID: "triage-1", TenantID: "hospital", SectionID: "icu", Status: "WAITING"}
+	otherTenant := &types.Entry{WaitingRoomID: "triage-1", TenantID: "clinic", SectionID: "er", Status: "WAITING"}
+	for _, entry := range []*types.Entry{waiting, archived, otherSection, otherTenant} {
+		if err := mockRepo.CreateEntry(ctx, entry); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+		historyRepo.AppendEntryHistory(ctx, &types.EntryHistoryEvent{EntryID: entry.ID, TenantID: entry.TenantID, SectionID: entry.SectionID, ToStatus: entry.Status})
+	}
+	if _, err := mockRepo.ArchiveEntries(ctx, time.Now().Add(time.Hour), []string{"COMPLETED"}); err != nil {
+		t.Fatalf("ArchiveEntries failed: %v", err)
+	}
+	otherCtx := context.WithValue(context.Background(), middleware.TENANT, "hospital:icu")
+	for _, c := range []context.Context{ctx, otherCtx} {
+		building, section, _ := types.ParseTenantID(c.Value(middleware.TENANT).(string))
+		if _, err := mockRepo.NextTicketSequence(c, "triage-1", building, section, "2026-10-14"); err != nil {
+			t.Fatalf("NextTicketSequence failed: %v", err)
+		}
+		if err := mockRepo.SetRoomState(c, &types.RoomState{RoomID: "triage-1", Paused: true}); err != nil {
+			t.Fatalf("SetRoomState failed: %v", err)
+		}
+	}
+
+	if _, err := wq.PurgeTenantEntries(context.Background()); err == nil {
+		t.Errorf("Expected a purge without tenant to be rejected")
+	}
+
+	purged, err := wq.PurgeTenantEntries(ctx)
+	if err != nil {
+		t.Fatalf("PurgeTenantEntries failed: %v", err)
+	}
+	if purged != 2 {
+		t.Fatalf("Expected 2 purged entries, got %d", purged)
+	}
+	if archivedEntries, _ := mockRepo.GetArchivedEntries(ctx, time.Time{}, time.Now().Add(time.Hour), "", 0); len(archivedEntries) != 0 {
+		t.Errorf("Expected the archived entries to be purged, got %d", len(archivedEntries))
+	}
+	if _, err := mockRepo.GetEntryByID(ctx, waiting.ID); err == nil {
+		t.Errorf("Expected the waiting entry to be purged")
+	}
+	for _, entry := range []*types.Entry{waiting, archived} {
+		if events, _ := historyRepo.GetEntryHistory(ctx, entry.ID); len(events) != 0 {
+			t.Errorf("Expected the history of entry %s to be purged, got %d events", entry.ID, len(events))
+		}
+	}
+	for _, entry := range []*types.Entry{otherSection, otherTenant} {
+		if _, err := mockRepo.GetEntryByID(ctx, entry.ID); err != nil {
+			t.Errorf("Expected entry %s of tenant %s:%s to be kept", entry.ID, entry.TenantID, entry.SectionID)
+		}
+	}
+	if states, _ := mockRepo.GetRoomStates(ctx, "triage-1"); len(states) != 0 {
+		t.Errorf("Expected the room states to be purged, got %d", len(states))
+	}
+	if sequence, _ := mockRepo.NextTicketSequence(ctx, "triage-1", "hospital", "er", "2026-10-14"); sequence != 1 {
+		t.Errorf("Expected the ticket counter to start over, got %d", sequence)
+	}
+	if states, _ := mockRepo.GetRoomStates(otherCtx, "triage-1"); len(states) != 1 {
+		t.Errorf("Expected the room state of the other section to be kept, got %d", len(states))
+	}
+	if sequence, _ := mockRepo.NextTicketSequence(otherCtx, "triage-1", "hospital", "icu", "2026-10-14"); sequence != 2 {
+		t.Errorf("Expected the ticket counter of the other section to be kept, got %d", sequence)
+	}
+}
@@ -0,0 +1,135 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+var (
+	// ErrQueuePaused is returned when a paused room or service point is asked to take or call patients
+	ErrQueuePaused = errors.New("queue is paused")
+	// ErrInvalidRoomState is returned when a pause request cannot be applied
+	ErrInvalidRoomState = errors.New("invalid room state")
+)
+
+// GetRoomStates returns the state of a room followed by the stored states of its service points.
+// A room without a stored state is open.
+func (s *WaitingQueue) GetRoomStates(ctx context.Context, roomId string) ([]*types.RoomState, error) {
+	states, err := s.repo.GetRoomStates(ctx, roomId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room states: %w", err)
+	}
+
+	result := []*types.RoomState{{RoomID: roomId}}
+	for _, state := range states {
+		if state.ServicePointID == "" {
+			result[0] = state
+		} else {
+			result = append(result, state)
+		}
+	}
+	return result, nil
+}
+
+// SetRoomPaused pauses or resumes a room, or one of its service points when servicePointId is set.
+// While a room is paused new swipes go to redirectRoomId, or are rejected without one.
+func (s *WaitingQueue) SetRoomPaused(ctx context.Context, roomId, servicePointId string, paused bool, reason, redirectRoomId, userID string) (*types.RoomState, error) {
+	if servicePointId != "" && redirectRoomId != "" {
+		return nil, fmt.Errorf("%w: only rooms can redirect new swipes", ErrInvalidRoomState)
+	}
+	if redirectRoomId == roomId {
+		return nil, fmt.Errorf("%w: a room cannot redirect to itself", ErrInvalidRoomState)
+	}
+
+	state := &types.RoomState{
+		RoomID:         roomId,
+		ServicePointID: servicePointId,
+		Paused:         paused,
+		UpdatedBy:      userID,
+	}
+	if paused {
+		state.Reason = reason
+		state.RedirectRoomID = redirectRoomId
+	}
+	if err := s.repo.SetRoomState(ctx, state); err != nil {
+		return nil, fmt.Errorf("failed to store room state: %w", err)
+	}
+
+	log.Printf("[WaitingQueue] Room %s service point '%s' paused: %t (reason: '%s', redirect: '%s')", roomId, servicePointId, paused, state.Reason, state.RedirectRoomID)
+	return state, nil
+}
+
+// IntakeRoom returns the room a new swipe for roomId joins: roomId itself, or the redirect room
+// while it is paused. ErrQueuePaused is returned when both are closed.
+func (s *WaitingQueue) IntakeRoom(ctx context.Context, roomId string) (string, error) {
+	state, err := s.pausedState(ctx, roomId, "")
+	if err != nil {
+		return "", err
+	}
+	if state == nil {
+		return roomId, nil
+	}
+	if state.RedirectRoomID == "" {
+		return "", fmt.Errorf("%w: room %s is not taking new patients (%s)", ErrQueuePaused, roomId, state.Reason)
+	}
+
+	// Redirects are followed one step only
+	redirect, err := s.pausedState(ctx, state.RedirectRoomID, "")
+	if err != nil {
+		return "", err
+	}
+	if redirect != nil {
+		return "", fmt.Errorf("%w: room %s and its redirect room %s are not taking new patients", ErrQueuePaused, roomId, state.RedirectRoomID)
+	}
+	log.Printf("[WaitingQueue] Room %s is paused, redirecting intake to room %s", roomId, state.RedirectRoomID)
+	return state.RedirectRoomID, nil
+}
+
+// checkCallable returns ErrQueuePaused when the room or the service point is paused
+func (s *WaitingQueue) checkCallable(ctx context.Context, roomId, servicePointId string) error {
+	state, err := s.pausedState(ctx, roomId, servicePointId)
+	if err != nil {
+		return err
+	}
+	if state != nil {
+		if state.ServicePointID != "" {
+			return fmt.Errorf("%w: service point %s is paused (%s)", ErrQueuePaused, state.ServicePointID, state.Reason)
+		}
+		return fmt.Errorf("%w: room %s is paused (%s)", ErrQueuePaused, roomId, state.Reason)
+	}
+	return nil
+}
+
+// pausedState returns the paused state that applies to a room and service point (empty = the room
+// only), the room's taking precedence, or nil when open
+func (s *WaitingQueue) pausedState(ctx context.Context, roomId, servicePointId string) (*types.RoomState, error) {
+	states, err := s.GetRoomStates(ctx, roomId)
+	if err != nil {
+		return nil, err
+	}
+	return PausedState(states, servicePointId), nil
+}
+
+// PausedState picks the paused state that applies to servicePointId (empty = the room only) from
+// the result of GetRoomStates, or nil when open
+func PausedState(states []*types.RoomState, servicePointId string) *types.RoomState {
+	if len(states) == 0 {
+		return nil
+	}
+	if states[0].Paused {
+		return states[0]
+	}
+	if servicePointId == "" {
+		return nil
+	}
+	for _, state := range states[1:] {
+		if state.ServicePointID == servicePointId && state.Paused {
+			return state
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// TestRoomPause tests that paused rooms and service points stop intake and calls
+func TestRoomPause(t *testing.T) {
+	ctx := context.Background()
+	setup := func(t *testing.T) *WaitingQueue {
+		mockRepo := repository.NewMockQueueRepository()
+		for _, entry := range []*types.Entry{
+			{WaitingRoomID: "triage-1", Status: "WAITING"},
+			{WaitingRoomID: "triage-1", Status: "WAITING"},
+		} {
+			if err := mockRepo.CreateEntry(ctx, entry); err != nil {
+				t.Fatalf("CreateEntry failed: %v", err)
+			}
+		}
+		return NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+	}
+
+	t.Run("open room takes swipes", func(t *testing.T) {
+		wq := setup(t)
+		room, err := wq.IntakeRoom(ctx, "triage-1")
+		if err != nil || room != "triage-1" {
+			t.Fatalf("Expected intake into triage-1, got '%s', %v", room, err)
+		}
+		states, _ := wq.GetRoomStates(ctx, "triage-1")
+		if len(states) != 1 || states[0].Paused {
+			t.Errorf("Expected one open room state, got %+v", states)
+		}
+	})
+
+	t.Run("paused room rejects swipes but keeps its queue", func(t *testing.T) {
+		wq := setup(t)
+		if _, err := wq.SetRoomPaused(ctx, "triage-1", "", true, "doctor unavailable", "", "admin"); err != nil {
+			t.Fatalf("SetRoomPaused failed: %v", err)
+		}
+		if _, err := wq.IntakeRoom(ctx, "triage-1"); !errors.Is(err, ErrQueuePaused) {
+			t.Errorf("Expected ErrQueuePaused, got %v", err)
+		}
+		if _, err := wq.CallNextForServicePoint(ctx, "triage-1", "window-1"); !errors.Is(err, ErrQueuePaused) {
+			t.Errorf("Expected ErrQueuePaused when calling, got %v", err)
+		}
+		entries, _ := wq.GetQueueEntriesWithContext(ctx, "triage-1", []string{"WAITING"})
+		if len(entries) != 2 {
+			t.Errorf("Expected waiting entries to stay, got %d", len(entries))
+		}
+
+		if _, err := wq.SetRoomPaused(ctx, "triage-1", "", false, "", "", "admin"); err != nil {
+			t.Fatalf("SetRoomPaused failed: %v", err)
+		}
+		if _, err := wq.CallNextForServicePoint(ctx, "triage-1", "window-1"); err != nil {
+			t.Errorf("Expected resumed room to call, got %v", err)
+		}
+	})
+
+	t.Run("paused room redirects swipes", func(t *testing.T) {
+		wq := setup(t)
+		if _, err := wq.SetRoomPaused(ctx, "triage-1", "", true, "closing", "triage-2", "admin"); err != nil {
+			t.Fatalf("SetRoomPaused failed: %v", err)
+		}
+		room, err := wq.IntakeRoom(ctx, "triage-1")
+		if err != nil || room != "triage-2" {
+			t.Fatalf("Expected intake into triage-2, got '%s', %v", room, err)
+		}
+
+		// A paused redirect room is not followed further
+		if _, err := wq.SetRoomPaused(ctx, "triage-2", "", true, "closed", "", "admin"); err != nil {
+			t.Fatalf("SetRoomPaused failed: %v", err)
+		}
+		if _, err := wq.IntakeRoom(ctx, "triage-1"); !errors.Is(err, ErrQueuePaused) {
+			t.Errorf("Expected ErrQueuePaused, got %v", err)
+		}
+	})
+
+	t.Run("paused service point cannot call", func(t *testing.T) {
+		wq := setup(t)
+		if _, err := wq.SetRoomPaused(ctx, "triage-1", "window-1", true, "break", "", "nurse"); err != nil {
+			t.Fatalf("SetRoomPaused failed: %v", err)
+		}
+		if _, err := wq.CallNextForServicePoint(ctx, "triage-1", "window-1"); !errors.Is(err, ErrQueuePaused) {
+			t.Errorf("Expected ErrQueuePaused, got %v", err)
+		}
+		if _, err := wq.CallNextForServicePoint(ctx, "triage-1", "window-2"); err != nil {
+			t.Errorf("Expected other service points to call, got %v", err)
+		}
+		if room, err := wq.IntakeRoom(ctx, "triage-1"); err != nil || room != "triage-1" {
+			t.Errorf("Expected the room to keep taking swipes, got '%s', %v", room, err)
+		}
+	})
+
+	t.Run("service points cannot redirect", func(t *testing.T) {
+		wq := setup(t)
+		if _, err := wq.SetRoomPaused(ctx, "triage-1", "window-1", true, "", "triage-2", "nurse"); !errors.Is(err, ErrInvalidRoomState) {
+			t.Errorf("Expected ErrInvalidRoomState, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// ErrCannotChangeService is returned when the service of an entry that is no longer waiting is changed
+var ErrCannotChangeService = errors.New("service of entry cannot be changed")
+
+// ChangeEntryService changes the service and approximate duration (seconds, 0 keeps it) of a WAITING
+// entry of the tenant in the context, e.g. when the hospital system reschedules the patient
+func (s *WaitingQueue) ChangeEntryService(ctx context.Context, entryId, serviceName string, durationSeconds int64) (*Entry, error) {
+	// The repositories report a missing entry as an error
+	entry, err := s.repo.GetEntryByID(ctx, entryId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEntryNotFound, err)
+	}
+	if entry == nil || !sameTenant(ctx, entry) {
+		return nil, fmt.Errorf("%w: %s", ErrEntryNotFound, entryId)
+	}
+	if entry.Status != "WAITING" {
+		return nil, fmt.Errorf("%w: entry %s is %s", ErrCannotChangeService, entry.ID, entry.Status)
+	}
+
+	if durationSeconds <= 0 {
+		durationSeconds = entry.ApproximateDurationSeconds
+	}
+	if err := s.repo.UpdateEntryService(ctx, entry.ID, serviceName, durationSeconds); err != nil {
+		return nil, fmt.Errorf("failed to update entry service: %w", err)
+	}
+	entry.ServiceName = serviceName
+	entry.ApproximateDurationSeconds = durationSeconds
+
+	log.Printf("[WaitingQueue] Changed service of entry %s (ticket %s) in room %s to %q", entry.ID, entry.TicketNumber, entry.WaitingRoomID, serviceName)
+	return entry, nil
+}
@@ -0,0 +1,47 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/repository"
+)
+
+// TestChangeEntryService tests that the service of a waiting entry can be changed, keeping its
+// duration when none is given
+func TestChangeEntryService(t *testing.T) {
+	ctx := context.WithValue(context.Background(), middleware.TENANT, "hospital-a:cardiology")
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+
+	entry, err := wq.CreateEntry(ctx, "triage-1", CardData{IDNumber: "1"}, 300, "ECG", nil, nil, nil, nil, "")
+	if err != nil {
+		t.Fatalf("CreateEntry failed: %v", err)
+	}
+
+	changed, err := wq.ChangeEntryService(ctx, entry.ID, "Echocardiography", 0)
+	if err != nil {
+		t.Fatalf("ChangeEntryService failed: %v", err)
+	}
+	if changed.ServiceName != "Echocardiography" || changed.ApproximateDurationSeconds != 300 {
+		t.Errorf("Expected Echocardiography for 300s, got %s for %ds", changed.ServiceName, changed.ApproximateDurationSeconds)
+	}
+
+	stored, err := mockRepo.GetEntryByID(ctx, entry.ID)
+	if err != nil {
+		t.Fatalf("GetEntryByID failed: %v", err)
+	}
+	if stored.ServiceName != "Echocardiography" {
+		t.Errorf("Expected the stored service to change, got %s", stored.ServiceName)
+	}
+
+	if _, err := wq.CancelEntry(ctx, entry.ID); err != nil {
+		t.Fatalf("CancelEntry failed: %v", err)
+	}
+	if _, err := wq.ChangeEntryService(ctx, entry.ID, "ECG", 600); !errors.Is(err, ErrCannotChangeService) {
+		t.Errorf("Expected ErrCannotChangeService for a cancelled entry, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// servicePointServices returns the services servicePointId of roomId handles (empty = all) and
+// the room's fallback for when none of them waits
+func (s *WaitingQueue) servicePointServices(ctx context.Context, roomId, servicePointId string) ([]string, types.ServiceFallback) {
+	if s.configService == nil {
+		return nil, types.ServiceFallbackAny
+	}
+
+	rooms, err := s.configService.GetRoomsConfig(ctx)
+	if err != nil {
+		log.Printf("[WaitingQueue] Failed to get services of service point %s, calling any entry: %v", servicePointId, err)
+		return nil, types.ServiceFallbackAny
+	}
+	for _, room := range rooms {
+		if room.ID != roomId {
+			continue
+		}
+		fallback := room.ServiceFallback
+		if fallback == "" {
+			fallback = types.ServiceFallbackAny
+		}
+		for _, sp := range room.ServicePoints {
+			if sp.ID == servicePointId {
+				return sp.Services, fallback
+			}
+		}
+		return nil, fallback
+	}
+	return nil, types.ServiceFallbackAny
+}
+
+// canServe reports whether a service point handling services serves entry. Entries without a
+// service can be served anywhere.
+func canServe(services []string, entry *Entry) bool {
+	if len(services) == 0 || entry.ServiceName == "" {
+		return true
+	}
+	for _, service := range services {
+		if strings.EqualFold(strings.TrimSpace(service), entry.ServiceName) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextEntryForServicePoint returns the highest-priority WAITING entry assigned to servicePointId
+// or, when none is, of roomId whose service servicePointId handles. When none waits, the room's
+// fallback decides between the highest-priority entry of any service and nobody (nil).
+func (s *WaitingQueue) nextEntryForServicePoint(ctx context.Context, roomId, servicePointId string) (*Entry, error) {
+	// An idle service point takes the entries assigned to others once its own are called
+	assigned, err := s.repo.GetNextWaitingEntryForServicePoint(ctx, roomId, servicePointId)
+	if err != nil {
+		log.Printf("[WaitingQueue] Failed to get the entries assigned to service point %s: %v", servicePointId, err)
+	} else if assigned != nil {
+		return assigned, nil
+	}
+
+	services, fallback := s.servicePointServices(ctx, roomId, servicePointId)
+	if len(services) == 0 {
+		return s.repo.GetNextWaitingEntry(ctx, roomId)
+	}
+
+	// The entries come in the ordering of the room
+	entries, err := s.repo.GetQueueEntries(ctx, roomId, []string{"WAITING"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waiting entries: %w", err)
+	}
+	for _, entry := range entries {
+		if canServe(services, entry) {
+			return entry, nil
+		}
+	}
+
+	if len(entries) > 0 && fallback == types.ServiceFallbackAny {
+		log.Printf("[WaitingQueue] No waiting entry for the services %v of service point %s, calling entry %s of service %s",
+			services, servicePointId, entries[0].ID, entries[0].ServiceName)
+		return entries[0], nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,73 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// TestCallNextMatchesServices tests that a service point calls the best entry of its services
+func TestCallNextMatchesServices(t *testing.T) {
+	room := types.RoomConfig{
+		ID: "lab",
+		ServicePoints: []types.ServicePointConfig{
+			{ID: "blood", Services: []string{"Blood test"}},
+			{ID: "xray", Services: []string{"X-ray"}},
+			{ID: "general"},
+		},
+	}
+	configService := &stubConfigService{rooms: []types.RoomConfig{room}}
+
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+	wq.SetConfigService(configService)
+	ctx := context.Background()
+
+	ecg := &types.Entry{WaitingRoomID: "lab", Status: "WAITING", Tier: 0, ServiceName: "ECG"}
+	blood := &types.Entry{WaitingRoomID: "lab", Status: "WAITING", Tier: 1, ServiceName: "blood test"}
+	unassigned := &types.Entry{WaitingRoomID: "lab", Status: "WAITING", Tier: 2}
+	for _, entry := range []*types.Entry{ecg, blood, unassigned} {
+		if err := mockRepo.CreateEntry(ctx, entry); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+	}
+
+	called, err := wq.CallNextForServicePoint(ctx, "lab", "blood")
+	if err != nil {
+		t.Fatalf("CallNextForServicePoint failed: %v", err)
+	}
+	if called.ID != blood.ID {
+		t.Errorf("Expected the blood test entry to be called at blood, got ticket %s (%s)", called.TicketNumber, called.ServiceName)
+	}
+
+	// An entry without a service can be served anywhere
+	called, err = wq.CallNextForServicePoint(ctx, "lab", "xray")
+	if err != nil {
+		t.Fatalf("CallNextForServicePoint failed: %v", err)
+	}
+	if called.ID != unassigned.ID {
+		t.Errorf("Expected the entry without a service at xray, got ticket %s (%s)", called.TicketNumber, called.ServiceName)
+	}
+
+	// Only the ECG entry waits; the NONE fallback leaves it for another service point
+	configService.rooms[0].ServiceFallback = types.ServiceFallbackNone
+	if _, err := wq.CallNextForServicePoint(ctx, "lab", "xray"); err == nil {
+		t.Error("Expected no entry to be called for xray with the NONE fallback")
+	}
+	if ecg.Status != "WAITING" {
+		t.Errorf("Expected the ECG entry to keep waiting, got %s", ecg.Status)
+	}
+
+	// The default ANY fallback calls it anyway
+	configService.rooms[0].ServiceFallback = ""
+	called, err = wq.CallNextForServicePoint(ctx, "lab", "xray")
+	if err != nil {
+		t.Fatalf("CallNextForServicePoint failed: %v", err)
+	}
+	if called.ID != ecg.ID {
+		t.Errorf("Expected the ANY fallback to call the ECG entry, got ticket %s", called.TicketNumber)
+	}
+}
@@ -2,78 +2,121 @@ package queue
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/arfis/waiting-room/internal/data/dto"
 	"github.com/arfis/waiting-room/internal/data/dto/queueentrystatus"
+	"github.com/arfis/waiting-room/internal/service"
 )
 
+// ErrServicePointNotClaimed is returned when a patient is called at a service point no active
+// staff member has claimed while queue.require_service_point_claim is set
+var ErrServicePointNotClaimed = errors.New("service point not claimed")
+
+// checkClaimed returns ErrServicePointNotClaimed when claims are required and nobody serves at
+// servicePointId; a call by the operator keeps their claim alive
+func (s *WaitingQueue) checkClaimed(ctx context.Context, roomId, servicePointId string) error {
+	if s.servicePointSvc == nil {
+		return nil
+	}
+	s.servicePointSvc.Touch(service.GetUserID(ctx))
+	if s.config != nil && s.config.Queue.RequireServicePointClaim && !s.servicePointSvc.HasActiveOperator(roomId, servicePointId) {
+		return fmt.Errorf("%w: nobody serves at service point %s of room %s", ErrServicePointNotClaimed, servicePointId, roomId)
+	}
+	return nil
+}
+
 // CallNextForServicePoint calls the next person for a specific service point
 func (s *WaitingQueue) CallNextForServicePoint(ctx context.Context, roomId, servicePointId string) (*Entry, error) {
 	log.Printf("CallNextForServicePoint: Starting for room %s, service point %s", roomId, servicePointId)
 
-	// First, complete any currently served person for this service point
-	currentEntry, err := s.repo.GetCurrentServedEntryForServicePoint(ctx, roomId, servicePointId)
-	if err != nil {
-		log.Printf("CallNextForServicePoint: Failed to get current served entry for service point: %v", err)
-		// Continue anyway, as there might not be a current entry
+	if err := s.checkCallable(ctx, roomId, servicePointId); err != nil {
+		return nil, err
+	}
+	if err := s.checkClaimed(ctx, roomId, servicePointId); err != nil {
+		return nil, err
 	}
 
-	if currentEntry != nil {
-		log.Printf("CallNextForServicePoint: Found current entry %s for service point %s, completing it", currentEntry.ID, servicePointId)
-		// Complete the current person
-		if err := s.repo.UpdateEntryStatus(ctx, currentEntry.ID, "COMPLETED"); err != nil {
-			log.Printf("CallNextForServicePoint: Failed to complete current entry: %v", err)
-			return nil, fmt.Errorf("failed to complete current entry: %w", err)
+	// Completing the current person, calling the next one and the new positions are stored together
+	var entry *Entry
+	err := s.repo.WithTransaction(ctx, func(ctx context.Context) error {
+		// First, complete any currently served person for this service point
+		currentEntry, err := s.repo.GetCurrentServedEntryForServicePoint(ctx, roomId, servicePointId)
+		if err != nil {
+			log.Printf("CallNextForServicePoint: Failed to get current served entry for service point: %v", err)
+			// Continue anyway, as there might not be a current entry
 		}
-		log.Printf("CallNextForServicePoint: Completed current entry %s", currentEntry.ID)
-	} else {
-		log.Printf("CallNextForServicePoint: No current entry found for service point %s", servicePointId)
-	}
 
-	// Get the next waiting entry for this specific service point
-	entry, err := s.repo.GetNextWaitingEntry(ctx, roomId)
+		if currentEntry != nil {
+			log.Printf("CallNextForServicePoint: Found current entry %s for service point %s, completing it", currentEntry.ID, servicePointId)
+			// Complete the current person
+			if err := s.completeEntry(ctx, currentEntry); err != nil {
+				log.Printf("CallNextForServicePoint: Failed to complete current entry: %v", err)
+				return fmt.Errorf("failed to complete current entry: %w", err)
+			}
+			log.Printf("CallNextForServicePoint: Completed current entry %s", currentEntry.ID)
+		} else {
+			log.Printf("CallNextForServicePoint: No current entry found for service point %s", servicePointId)
+		}
+
+		// Get the next waiting entry whose service this service point handles
+		entry, err = s.nextEntryForServicePoint(ctx, roomId, servicePointId)
+		if err != nil {
+			return fmt.Errorf("failed to get next waiting entry for service point %s: %w", servicePointId, err)
+		}
+
+		if entry == nil {
+			return fmt.Errorf("no waiting entries found for service point %s", servicePointId)
+		}
+
+		log.Printf("CallNextForServicePoint: Found next entry %s, calling them for service point %s", entry.ID, servicePointId)
+		return s.callEntry(ctx, entry, roomId, servicePointId)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get next waiting entry for service point %s: %w", servicePointId, err)
+		return nil, err
 	}
 
-	if entry == nil {
-		return nil, fmt.Errorf("no waiting entries found for service point %s", servicePointId)
-	}
+	log.Printf("CallNextForServicePoint: Successfully called entry %s (ticket %s) for service point %s in room %s",
+		entry.ID, entry.TicketNumber, servicePointId, roomId)
 
-	log.Printf("CallNextForServicePoint: Found next entry %s, calling them for service point %s", entry.ID, servicePointId)
+	return entry, nil
+}
 
+// callEntry calls entry to servicePointId and recalculates the positions of roomId
+func (s *WaitingQueue) callEntry(ctx context.Context, entry *Entry, roomId, servicePointId string) error {
 	// Update status to CALLED and set service point
+	previousStatus := entry.Status
 	entry.Status = "CALLED"
 	entry.UpdatedAt = time.Now()
 	entry.ServicePoint = servicePointId
 
-	if err := s.repo.UpdateEntryStatus(ctx, entry.ID, "CALLED"); err != nil {
-		return nil, fmt.Errorf("failed to update entry status: %w", err)
-	}
-
-	// Also update the service point in the database
-	if err := s.repo.UpdateEntryServicePoint(ctx, entry.ID, servicePointId); err != nil {
-		log.Printf("Warning: Failed to update service point: %v", err)
+	// Status and service point in one versioned write, so two counters cannot both claim the entry
+	if err := s.repo.UpdateEntryStatusAndServicePoint(ctx, entry.ID, "CALLED", servicePointId, entry.Version); err != nil {
+		return fmt.Errorf("failed to update entry status: %w", err)
 	}
+	s.recordTransition(ctx, entry, previousStatus, "called")
 
 	// Recalculate positions
 	if err := s.repo.RecalculatePositions(ctx, roomId); err != nil {
-		log.Printf("Warning: Failed to recalculate positions after calling next: %v", err)
+		return fmt.Errorf("failed to recalculate positions: %w", err)
 	}
-
-	log.Printf("CallNextForServicePoint: Successfully called entry %s (ticket %s) for service point %s in room %s",
-		entry.ID, entry.TicketNumber, servicePointId, roomId)
-
-	return entry, nil
+	return nil
 }
 
 // CallSpecificEntryForServicePoint calls a specific entry by ID for a service point
 func (s *WaitingQueue) CallSpecificEntryForServicePoint(ctx context.Context, roomId, servicePointId, entryId string) (*Entry, error) {
 	log.Printf("CallSpecificEntryForServicePoint: Starting for room %s, service point %s, entry %s", roomId, servicePointId, entryId)
 
+	if err := s.checkCallable(ctx, roomId, servicePointId); err != nil {
+		return nil, err
+	}
+	if err := s.checkClaimed(ctx, roomId, servicePointId); err != nil {
+		return nil, err
+	}
+
 	// Get the entry
 	entry, err := s.repo.GetEntryByID(ctx, entryId)
 	if err != nil {
@@ -94,42 +137,29 @@ func (s *WaitingQueue) CallSpecificEntryForServicePoint(ctx context.Context, roo
 		return nil, fmt.Errorf("entry is not in WAITING status (current status: %s)", entry.Status)
 	}
 
-	// First, complete any currently served person for this service point
-	currentEntry, err := s.repo.GetCurrentServedEntryForServicePoint(ctx, roomId, servicePointId)
-	if err != nil {
-		log.Printf("CallSpecificEntryForServicePoint: Failed to get current served entry for service point: %v", err)
-		// Continue anyway, as there might not be a current entry
-	}
-
-	if currentEntry != nil {
-		log.Printf("CallSpecificEntryForServicePoint: Found current entry %s for service point %s, completing it", currentEntry.ID, servicePointId)
-		// Complete the current person
-		if err := s.repo.UpdateEntryStatus(ctx, currentEntry.ID, "COMPLETED"); err != nil {
-			log.Printf("CallSpecificEntryForServicePoint: Failed to complete current entry: %v", err)
-			return nil, fmt.Errorf("failed to complete current entry: %w", err)
+	err = s.repo.WithTransaction(ctx, func(ctx context.Context) error {
+		// First, complete any currently served person for this service point
+		currentEntry, err := s.repo.GetCurrentServedEntryForServicePoint(ctx, roomId, servicePointId)
+		if err != nil {
+			log.Printf("CallSpecificEntryForServicePoint: Failed to get current served entry for service point: %v", err)
+			// Continue anyway, as there might not be a current entry
 		}
-		log.Printf("CallSpecificEntryForServicePoint: Completed current entry %s", currentEntry.ID)
-	}
-
-	log.Printf("CallSpecificEntryForServicePoint: Calling specific entry %s for service point %s", entry.ID, servicePointId)
-
-	// Update status to CALLED and set service point
-	entry.Status = "CALLED"
-	entry.UpdatedAt = time.Now()
-	entry.ServicePoint = servicePointId
-
-	if err := s.repo.UpdateEntryStatus(ctx, entry.ID, "CALLED"); err != nil {
-		return nil, fmt.Errorf("failed to update entry status: %w", err)
-	}
 
-	// Also update the service point in the database
-	if err := s.repo.UpdateEntryServicePoint(ctx, entry.ID, servicePointId); err != nil {
-		log.Printf("Warning: Failed to update service point: %v", err)
-	}
+		if currentEntry != nil {
+			log.Printf("CallSpecificEntryForServicePoint: Found current entry %s for service point %s, completing it", currentEntry.ID, servicePointId)
+			// Complete the current person
+			if err := s.completeEntry(ctx, currentEntry); err != nil {
+				log.Printf("CallSpecificEntryForServicePoint: Failed to complete current entry: %v", err)
+				return fmt.Errorf("failed to complete current entry: %w", err)
+			}
+			log.Printf("CallSpecificEntryForServicePoint: Completed current entry %s", currentEntry.ID)
+		}
 
-	// Recalculate positions
-	if err := s.repo.RecalculatePositions(ctx, roomId); err != nil {
-		log.Printf("Warning: Failed to recalculate positions after calling specific entry: %v", err)
+		log.Printf("CallSpecificEntryForServicePoint: Calling specific entry %s for service point %s", entry.ID, servicePointId)
+		return s.callEntry(ctx, entry, roomId, servicePointId)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	log.Printf("CallSpecificEntryForServicePoint: Successfully called entry %s (ticket %s) for service point %s in room %s",
@@ -156,12 +186,14 @@ func (s *WaitingQueue) MarkInRoomForServicePoint(ctx context.Context, roomId, se
 	}
 
 	// Update status to IN_ROOM
+	previousStatus := entry.Status
 	entry.Status = "IN_ROOM"
 	entry.UpdatedAt = time.Now()
 
-	if err := s.repo.UpdateEntryStatus(ctx, entry.ID, "IN_ROOM"); err != nil {
+	if err := s.repo.UpdateEntryStatus(ctx, entry.ID, "IN_ROOM", entry.Version); err != nil {
 		return nil, fmt.Errorf("failed to update entry status: %w", err)
 	}
+	s.recordTransition(ctx, entry, previousStatus, "in_room")
 
 	// Convert to DTO
 	queueEntry := &dto.QueueEntry{
@@ -200,17 +232,20 @@ func (s *WaitingQueue) FinishCurrentForServicePoint(ctx context.Context, roomId,
 		return nil, fmt.Errorf("no current served entry found for service point %s", servicePointId)
 	}
 
-	// Update status to COMPLETED
-	entry.Status = "COMPLETED"
-	entry.UpdatedAt = time.Now()
-
-	if err := s.repo.UpdateEntryStatus(ctx, entry.ID, "COMPLETED"); err != nil {
-		return nil, fmt.Errorf("failed to update entry status: %w", err)
-	}
+	err = s.repo.WithTransaction(ctx, func(ctx context.Context) error {
+		// Complete the entry, or move it on to the next stage of its pathway
+		if err := s.completeEntry(ctx, entry); err != nil {
+			return fmt.Errorf("failed to update entry status: %w", err)
+		}
 
-	// Recalculate positions
-	if err := s.repo.RecalculatePositions(ctx, roomId); err != nil {
-		log.Printf("Warning: Failed to recalculate positions after finishing current: %v", err)
+		// Recalculate positions
+		if err := s.repo.RecalculatePositions(ctx, roomId); err != nil {
+			return fmt.Errorf("failed to recalculate positions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Convert to DTO
@@ -0,0 +1,71 @@
+package queue
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// SwipeKeys returns the idempotency keys of a swipe into a room. A key sent by the client is used
+// as is; otherwise the card is keyed by the dedup time bucket it falls in and the previous one, so a
+// double-tap across a bucket boundary is still caught. The first key is the one to store.
+func (s *WaitingQueue) SwipeKeys(idNumber, roomId, clientKey string, now time.Time) []string {
+	if clientKey != "" {
+		return []string{swipeKey("client", roomId, clientKey)}
+	}
+
+	window := int64(0)
+	if s.config != nil {
+		window = int64(s.config.Queue.SwipeDedupWindowSeconds)
+	}
+	if window <= 0 || idNumber == "" {
+		return nil
+	}
+	bucket := now.Unix() / window
+	return []string{
+		swipeKey("card", roomId, idNumber, fmt.Sprint(bucket)),
+		swipeKey("card", roomId, idNumber, fmt.Sprint(bucket-1)),
+	}
+}
+
+// FindDuplicateSwipe returns the active entry an earlier swipe with one of keys created, or nil
+func (s *WaitingQueue) FindDuplicateSwipe(ctx context.Context, keys []string) (*Entry, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	entry, err := s.repo.GetActiveEntryByIdempotencyKey(ctx, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up swipe: %w", err)
+	}
+	return entry, nil
+}
+
+// JoinOnce runs create unless an earlier swipe with one of keys already created an active entry,
+// which is returned instead with duplicate set. Concurrent swipes are serialised so that two taps
+// arriving together still produce one ticket.
+func (s *WaitingQueue) JoinOnce(ctx context.Context, keys []string, create func() (*Entry, error)) (entry *Entry, duplicate bool, err error) {
+	s.swipeMu.Lock()
+	defer s.swipeMu.Unlock()
+
+	existing, err := s.FindDuplicateSwipe(ctx, keys)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		log.Printf("[WaitingQueue] Repeated swipe returns existing entry %s (ticket %s)", existing.ID, existing.TicketNumber)
+		return existing, true, nil
+	}
+
+	entry, err = create()
+	return entry, false, err
+}
+
+// swipeKey hashes the parts of a key so card numbers are not stored in the clear
+func swipeKey(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,72 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/repository"
+)
+
+// TestJoinOnce tests that repeated swipes of a card return the entry of the first one
+func TestJoinOnce(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{Queue: config.QueueConfig{SwipeDedupWindowSeconds: 60}}
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, cfg, nil, nil)
+
+	cardData := CardData{IDNumber: "123456789"}
+	join := func(keys []string) (*Entry, bool) {
+		entry, duplicate, err := wq.JoinOnce(ctx, keys, func() (*Entry, error) {
+			return wq.CreateEntry(ctx, "triage-1", cardData, 300, "Service", nil, nil, nil, nil, keys[0])
+		})
+		if err != nil {
+			t.Fatalf("JoinOnce failed: %v", err)
+		}
+		return entry, duplicate
+	}
+
+	now := time.Unix(1_000_075, 0)
+	first, duplicate := join(wq.SwipeKeys(cardData.IDNumber, "triage-1", "", now))
+	if duplicate {
+		t.Fatal("Expected the first swipe to create an entry")
+	}
+
+	// A double-tap in the next bucket still finds the entry
+	second, duplicate := join(wq.SwipeKeys(cardData.IDNumber, "triage-1", "", now.Add(10*time.Second)))
+	if !duplicate || second.ID != first.ID {
+		t.Errorf("Expected the repeated swipe to return entry %s, got %s (duplicate: %t)", first.ID, second.ID, duplicate)
+	}
+
+	// Two buckets later the card joins again
+	if _, duplicate := join(wq.SwipeKeys(cardData.IDNumber, "triage-1", "", now.Add(150*time.Second))); duplicate {
+		t.Error("Expected a swipe outside the window to create a new entry")
+	}
+
+	// Finished entries are not reused
+	_ = mockRepo.UpdateEntryStatus(ctx, first.ID, "COMPLETED", first.Version)
+	if entry, _ := wq.FindDuplicateSwipe(ctx, wq.SwipeKeys(cardData.IDNumber, "triage-1", "", now)); entry != nil {
+		t.Errorf("Expected no active entry for a completed swipe, got %s", entry.ID)
+	}
+
+	// A client key identifies a retry regardless of the time
+	keys := wq.SwipeKeys(cardData.IDNumber, "triage-1", "kiosk-1-swipe-42", now)
+	retried, _ := join(keys)
+	again, duplicate := join(wq.SwipeKeys(cardData.IDNumber, "triage-1", "kiosk-1-swipe-42", now.Add(time.Hour)))
+	if !duplicate || again.ID != retried.ID {
+		t.Errorf("Expected the retry to return entry %s, got %s (duplicate: %t)", retried.ID, again.ID, duplicate)
+	}
+}
+
+// TestSwipeKeysDisabled tests that a negative window turns deduplication off
+func TestSwipeKeysDisabled(t *testing.T) {
+	cfg := &config.Config{Queue: config.QueueConfig{SwipeDedupWindowSeconds: -1}}
+	wq := NewWaitingQueue(repository.NewMockQueueRepository(), cfg, nil, nil)
+	if keys := wq.SwipeKeys("123456789", "triage-1", "", time.Now()); len(keys) != 0 {
+		t.Errorf("Expected no keys, got %v", keys)
+	}
+	if keys := wq.SwipeKeys("123456789", "triage-1", "retry", time.Now()); len(keys) != 1 {
+		t.Errorf("Expected the client key to be kept, got %v", keys)
+	}
+}
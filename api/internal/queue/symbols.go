@@ -0,0 +1,19 @@
+package queue
+
+import (
+	"context"
+
+	"github.com/arfis/waiting-room/internal/service"
+)
+
+// NormalizeSymbols checks swipe symbols against the symbol catalog of the tenant's priority
+// configuration and returns them spelled as in the catalog, so a symbol typed in another case
+// still counts for priority. Symbols not in the catalog are an error wrapping
+// priority.ErrUnknownSymbol; without a catalog the symbols are returned unchanged.
+func (s *WaitingQueue) NormalizeSymbols(ctx context.Context, symbols []string) ([]string, error) {
+	if len(symbols) == 0 {
+		return symbols, nil
+	}
+	buildingID, sectionID := service.GetTenantScope(ctx)
+	return s.loadPriorityConfig(ctx, buildingID, sectionID).ResolveSymbols(symbols)
+}
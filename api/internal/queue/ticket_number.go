@@ -0,0 +1,90 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+)
+
+// allocateTicketNumber takes the next number of the room's ticket counter in the current period
+// and formats it with the configured template
+func (s *WaitingQueue) allocateTicketNumber(ctx context.Context, roomId, buildingID, sectionID string, now time.Time) (string, error) {
+	var tickets config.TicketConfig
+	if s.config != nil {
+		tickets = s.config.Queue.Tickets
+	}
+
+	period, start, shift := ticketPeriod(tickets, now)
+	sequence, err := s.repo.NextTicketSequence(ctx, roomId, buildingID, sectionID, period)
+	if err != nil {
+		return "", fmt.Errorf("failed to allocate ticket number: %w", err)
+	}
+	return formatTicketNumber(tickets, roomId, sequence, start, shift), nil
+}
+
+// ticketPeriod returns the counter period t falls in ("" when the counter never resets), the
+// start of the period and the 1-based shift number (0 unless the counter resets per shift)
+func ticketPeriod(tickets config.TicketConfig, t time.Time) (string, time.Time, int) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+
+	switch tickets.Reset {
+	case "daily":
+		return day.Format("20060102"), day, 0
+	case "shift":
+		starts := shiftStarts(tickets.ShiftStarts)
+		if len(starts) == 0 {
+			return day.Format("20060102"), day, 0
+		}
+		// Before the first shift of the day the last shift of the previous day is still running
+		shift := len(starts)
+		start := day.AddDate(0, 0, -1).Add(starts[shift-1])
+		for i, offset := range starts {
+			if !t.Before(day.Add(offset)) {
+				shift, start = i+1, day.Add(offset)
+			}
+		}
+		return fmt.Sprintf("%s-%d", start.Format("20060102"), shift), start, shift
+	default:
+		return "", day, 0
+	}
+}
+
+// shiftStarts parses the HH:MM shift start times into offsets from midnight, in order
+func shiftStarts(values []string) []time.Duration {
+	var starts []time.Duration
+	for _, value := range values {
+		parsed, err := time.Parse("15:04", strings.TrimSpace(value))
+		if err != nil {
+			log.Printf("[WaitingQueue] Ignoring invalid ticket shift start '%s': %v", value, err)
+			continue
+		}
+		starts = append(starts, time.Duration(parsed.Hour())*time.Hour+time.Duration(parsed.Minute())*time.Minute)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+	return starts
+}
+
+// formatTicketNumber fills the ticket template
+func formatTicketNumber(tickets config.TicketConfig, roomId string, sequence int64, start time.Time, shift int) string {
+	format := tickets.Format
+	if format == "" {
+		format = "{room}-{number}"
+	}
+	padding := tickets.Padding
+	if padding <= 0 {
+		padding = 3
+	}
+
+	return strings.NewReplacer(
+		"{room}", strings.ToUpper(roomId),
+		"{number}", fmt.Sprintf("%0*d", padding, sequence),
+		"{date}", start.Format("20060102"),
+		"{shift}", strconv.Itoa(shift),
+	).Replace(format)
+}
@@ -0,0 +1,86 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/repository"
+)
+
+// TestTicketPeriod tests the counter periods of the reset options
+func TestTicketPeriod(t *testing.T) {
+	shifts := []string{"22:00", "06:00", "14:00"}
+	tests := []struct {
+		name       string
+		tickets    config.TicketConfig
+		at         time.Time
+		wantPeriod string
+		wantShift  int
+	}{
+		{"never", config.TicketConfig{Reset: "never"}, time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC), "", 0},
+		{"daily", config.TicketConfig{Reset: "daily"}, time.Date(2026, 3, 4, 23, 59, 0, 0, time.UTC), "20260304", 0},
+		{"morning shift", config.TicketConfig{Reset: "shift", ShiftStarts: shifts}, time.Date(2026, 3, 4, 6, 0, 0, 0, time.UTC), "20260304-1", 1},
+		{"afternoon shift", config.TicketConfig{Reset: "shift", ShiftStarts: shifts}, time.Date(2026, 3, 4, 15, 30, 0, 0, time.UTC), "20260304-2", 2},
+		{"night shift before midnight", config.TicketConfig{Reset: "shift", ShiftStarts: shifts}, time.Date(2026, 3, 4, 22, 30, 0, 0, time.UTC), "20260304-3", 3},
+		{"night shift after midnight", config.TicketConfig{Reset: "shift", ShiftStarts: shifts}, time.Date(2026, 3, 5, 2, 0, 0, 0, time.UTC), "20260304-3", 3},
+		{"shift without starts resets daily", config.TicketConfig{Reset: "shift", ShiftStarts: []string{"bad"}}, time.Date(2026, 3, 4, 2, 0, 0, 0, time.UTC), "20260304", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			period, _, shift := ticketPeriod(tt.tickets, tt.at)
+			if period != tt.wantPeriod || shift != tt.wantShift {
+				t.Errorf("Expected period '%s' shift %d, got '%s' shift %d", tt.wantPeriod, tt.wantShift, period, shift)
+			}
+		})
+	}
+}
+
+// TestFormatTicketNumber tests the ticket template
+func TestFormatTicketNumber(t *testing.T) {
+	start := time.Date(2026, 3, 4, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		tickets config.TicketConfig
+		want    string
+	}{
+		{config.TicketConfig{}, "TRIAGE-1-007"},
+		{config.TicketConfig{Format: "A{number}", Padding: 2}, "A07"},
+		{config.TicketConfig{Format: "{date}/{shift}-{number}", Padding: 4}, "20260304/2-0007"},
+	}
+
+	for _, tt := range tests {
+		if got := formatTicketNumber(tt.tickets, "triage-1", 7, start, 2); got != tt.want {
+			t.Errorf("Expected %s for format '%s', got %s", tt.want, tt.tickets.Format, got)
+		}
+	}
+}
+
+// TestTicketNumbersPerTenant tests that every room and tenant counts on its own and numbers are not reused
+func TestTicketNumbersPerTenant(t *testing.T) {
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+
+	create := func(tenantID string) *Entry {
+		ctx := context.WithValue(context.Background(), middleware.TENANT, tenantID)
+		entry, err := wq.CreateEntry(ctx, "triage-1", CardData{IDNumber: "1"}, 300, "Service", nil, nil, nil, nil, "")
+		if err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+		return entry
+	}
+
+	first := create("hospital-a:cardiology")
+	_ = mockRepo.DeleteEntry(context.Background(), first.ID)
+	second := create("hospital-a:cardiology")
+	other := create("hospital-b:cardiology")
+
+	if first.TicketNumber != "TRIAGE-1-001" || second.TicketNumber != "TRIAGE-1-002" {
+		t.Errorf("Expected TRIAGE-1-001 and TRIAGE-1-002, got %s and %s", first.TicketNumber, second.TicketNumber)
+	}
+	if other.TicketNumber != "TRIAGE-1-001" {
+		t.Errorf("Expected another tenant to start at TRIAGE-1-001, got %s", other.TicketNumber)
+	}
+}
@@ -0,0 +1,99 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+var (
+	// ErrEntryNotFound is returned when a transfer names an entry that does not exist
+	ErrEntryNotFound = errors.New("entry not found")
+	// ErrInvalidTransfer is returned when an entry cannot be moved to the requested target
+	ErrInvalidTransfer = errors.New("invalid transfer")
+)
+
+// TransferEntry moves an entry of roomId to targetRoomId (empty = same room) and
+// targetServicePointId (empty = any service point), e.g. a patient redirected from triage
+// to X-ray. The entry keeps its ticket and priority and waits again in the target queue;
+// positions are recalculated in both rooms.
+func (s *WaitingQueue) TransferEntry(ctx context.Context, roomId, entryId, targetRoomId, targetServicePointId string) (*Entry, error) {
+	// The repositories report a missing entry as an error
+	entry, err := s.repo.GetEntryByID(ctx, entryId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEntryNotFound, err)
+	}
+
+	if entry == nil || entry.WaitingRoomID != roomId {
+		return nil, fmt.Errorf("%w: %s in room %s", ErrEntryNotFound, entryId, roomId)
+	}
+
+	switch entry.Status {
+	case "WAITING", "CALLED", "IN_SERVICE", "IN_ROOM":
+	default:
+		return nil, fmt.Errorf("%w: entry cannot be transferred in status %s", ErrInvalidTransfer, entry.Status)
+	}
+
+	if targetRoomId == "" {
+		targetRoomId = roomId
+	}
+	if targetRoomId == roomId && targetServicePointId == entry.ServicePoint && entry.Status == "WAITING" {
+		return nil, fmt.Errorf("%w: entry is already waiting for this room and service point", ErrInvalidTransfer)
+	}
+
+	if targetServicePointId != "" {
+		servicePoints, err := s.GetServicePoints(ctx, targetRoomId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get service points of room %s: %w", targetRoomId, err)
+		}
+		found := false
+		for _, sp := range servicePoints {
+			if sp.ID == targetServicePointId {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: service point %s does not exist in room %s", ErrInvalidTransfer, targetServicePointId, targetRoomId)
+		}
+	}
+
+	// The move and the positions of both queues are stored together
+	err = s.repo.WithTransaction(ctx, func(ctx context.Context) error {
+		previousStatus := entry.Status
+		if err := s.repo.TransferEntry(ctx, entry.ID, targetRoomId, targetServicePointId); err != nil {
+			return fmt.Errorf("failed to transfer entry: %w", err)
+		}
+
+		entry.WaitingRoomID = targetRoomId
+		entry.ServicePoint = targetServicePointId
+		entry.Status = "WAITING"
+		entry.UpdatedAt = time.Now()
+		s.recordTransition(ctx, entry, previousStatus, "transferred")
+
+		// Recalculate positions in the target queue first, then in the queue the entry left
+		if err := s.repo.RecalculatePositions(ctx, targetRoomId); err != nil {
+			return fmt.Errorf("failed to recalculate positions in room %s: %w", targetRoomId, err)
+		}
+		if targetRoomId != roomId {
+			if err := s.repo.RecalculatePositions(ctx, roomId); err != nil {
+				return fmt.Errorf("failed to recalculate positions in room %s: %w", roomId, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if updated, err := s.repo.GetEntryByID(ctx, entry.ID); err == nil && updated != nil {
+		entry = updated
+	}
+
+	log.Printf("TransferEntry: Transferred entry %s (ticket %s) from room %s to room %s (service point: '%s')",
+		entry.ID, entry.TicketNumber, roomId, targetRoomId, targetServicePointId)
+
+	return entry, nil
+}
@@ -0,0 +1,83 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// TestTransferEntry tests moving entries between rooms and service points
+func TestTransferEntry(t *testing.T) {
+	cfg := &config.Config{Rooms: config.RoomsConfig{
+		DefaultRoom: "triage-1",
+		Rooms: []config.RoomConfig{
+			{ID: "triage-1", ServicePoints: []config.ServicePointConfig{{ID: "window-1"}}},
+			{ID: "xray", ServicePoints: []config.ServicePointConfig{{ID: "xray-1"}, {ID: "xray-2"}}},
+		},
+	}}
+
+	tests := []struct {
+		name                 string
+		status               string
+		targetRoomId         string
+		targetServicePointId string
+		expectedErr          error
+		expectedRoom         string
+	}{
+		{name: "called entry moves to another room", status: "CALLED", targetRoomId: "xray", targetServicePointId: "xray-2", expectedRoom: "xray"},
+		{name: "waiting entry moves to another room without service point", status: "WAITING", targetRoomId: "xray", expectedRoom: "xray"},
+		{name: "called entry goes back to waiting in the same room", status: "CALLED", expectedRoom: "triage-1"},
+		{name: "unknown service point is rejected", status: "WAITING", targetRoomId: "xray", targetServicePointId: "window-1", expectedErr: ErrInvalidTransfer},
+		{name: "completed entry is rejected", status: "COMPLETED", targetRoomId: "xray", expectedErr: ErrInvalidTransfer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := repository.NewMockQueueRepository()
+			wq := NewWaitingQueue(mockRepo, cfg, nil, nil)
+			ctx := context.Background()
+
+			entry := &types.Entry{WaitingRoomID: "triage-1", Status: tt.status, ServicePoint: "window-1"}
+			if err := mockRepo.CreateEntry(ctx, entry); err != nil {
+				t.Fatalf("CreateEntry failed: %v", err)
+			}
+			ticket := entry.TicketNumber
+
+			moved, err := wq.TransferEntry(ctx, "triage-1", entry.ID, tt.targetRoomId, tt.targetServicePointId)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("Expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TransferEntry failed: %v", err)
+			}
+
+			if moved.WaitingRoomID != tt.expectedRoom || moved.ServicePoint != tt.targetServicePointId {
+				t.Errorf("Expected room %s and service point '%s', got %s and '%s'", tt.expectedRoom, tt.targetServicePointId, moved.WaitingRoomID, moved.ServicePoint)
+			}
+			if moved.Status != "WAITING" || moved.Position != 1 {
+				t.Errorf("Expected WAITING at position 1, got %s at position %d", moved.Status, moved.Position)
+			}
+			if moved.TicketNumber != ticket {
+				t.Errorf("Expected ticket %s to be kept, got %s", ticket, moved.TicketNumber)
+			}
+		})
+	}
+
+	t.Run("entry of another room is not found", func(t *testing.T) {
+		mockRepo := repository.NewMockQueueRepository()
+		wq := NewWaitingQueue(mockRepo, cfg, nil, nil)
+		entry := &types.Entry{WaitingRoomID: "xray", Status: "WAITING"}
+		_ = mockRepo.CreateEntry(context.Background(), entry)
+
+		if _, err := wq.TransferEntry(context.Background(), "triage-1", entry.ID, "xray", ""); !errors.Is(err, ErrEntryNotFound) {
+			t.Errorf("Expected ErrEntryNotFound, got %v", err)
+		}
+	})
+}
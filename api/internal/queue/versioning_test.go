@@ -0,0 +1,52 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/repository"
+)
+
+// TestStaleStatusUpdateConflicts tests that a transition made from an outdated read of an entry is rejected
+func TestStaleStatusUpdateConflicts(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := repository.NewMockQueueRepository()
+	wq := NewWaitingQueue(mockRepo, &config.Config{}, nil, nil)
+
+	entry, err := wq.CreateEntry(ctx, "triage-1", CardData{IDNumber: "1"}, 300, "Service", nil, nil, nil, nil, "")
+	if err != nil {
+		t.Fatalf("CreateEntry failed: %v", err)
+	}
+	// What a second operator read before the entry was called
+	read := entry.Version
+
+	if _, err := wq.CallNextForServicePoint(ctx, "triage-1", "window-1"); err != nil {
+		t.Fatalf("CallNextForServicePoint failed: %v", err)
+	}
+	if entry.Version != read+1 {
+		t.Errorf("Expected the call to increment the version to %d, got %d", read+1, entry.Version)
+	}
+
+	if err := mockRepo.UpdateEntryStatus(ctx, entry.ID, "CALLED", read); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("Expected ErrVersionConflict for the second call, got %v", err)
+	}
+	if err := mockRepo.UpdateEntryStatusAndServicePoint(ctx, entry.ID, "CALLED", "window-2", read); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("Expected ErrVersionConflict for a second counter calling the entry, got %v", err)
+	}
+	if err := mockRepo.UpdateEntryPosition(ctx, entry.ID, 5, read); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict for a position from the outdated read, got %v", err)
+	}
+	if entry.ServicePoint != "window-1" || entry.Position == 5 {
+		t.Errorf("Expected the entry to stay called at window-1, got service point %s at position %d", entry.ServicePoint, entry.Position)
+	}
+	// A position change is a change too: a writer holding the version before it conflicts
+	current := entry.Version
+	if err := mockRepo.UpdateEntryPosition(ctx, entry.ID, 2, current); err != nil {
+		t.Fatalf("UpdateEntryPosition failed: %v", err)
+	}
+	if err := mockRepo.UpdateEntryStatus(ctx, entry.ID, "IN_SERVICE", current); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict after a position change, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// defaultServiceDurationSeconds is assumed for entries without an approximate duration
+const defaultServiceDurationSeconds = 5 * 60
+
+// EstimateWaitTimes returns the estimated wait in seconds of every WAITING entry in a room
+// (filtered by the tenant in ctx), keyed by entry ID
+func (s *WaitingQueue) EstimateWaitTimes(ctx context.Context, roomId string) (map[string]int64, error) {
+	entries, err := s.repo.GetQueueEntries(ctx, roomId, []string{"WAITING", "CALLED", "IN_SERVICE"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue entries: %w", err)
+	}
+	return estimateWaitSeconds(entries, s.activeServicePointCount(ctx, roomId), time.Now()), nil
+}
+
+// activeServicePointCount is the number of service points with a manager that is logged in,
+// or the number of configured service points when nobody is logged in (never less than 1)
+func (s *WaitingQueue) activeServicePointCount(ctx context.Context, roomId string) int {
+	if s.servicePointSvc != nil {
+		if active := s.servicePointSvc.CountActiveServicePoints(roomId); active > 0 {
+			return active
+		}
+	}
+	if servicePoints, err := s.GetServicePoints(ctx, roomId); err == nil && len(servicePoints) > 0 {
+		return len(servicePoints)
+	}
+	return 1
+}
+
+// estimateWaitSeconds simulates the service points working through the queue: each starts
+// with the remaining time of the entry it is serving, and every WAITING entry in position
+// order goes to the service point that is free first
+func estimateWaitSeconds(entries []*Entry, servicePoints int, now time.Time) map[string]int64 {
+	if servicePoints < 1 {
+		servicePoints = 1
+	}
+	freeAt := make([]int64, servicePoints)
+
+	var waiting []*Entry
+	for _, entry := range entries {
+		switch entry.Status {
+		case "WAITING":
+			waiting = append(waiting, entry)
+		case "CALLED", "IN_SERVICE":
+			remaining := serviceDurationSeconds(entry) - int64(now.Sub(entry.UpdatedAt).Seconds())
+			if remaining > 0 {
+				freeAt[earliest(freeAt)] += remaining
+			}
+		}
+	}
+	sort.SliceStable(waiting, func(i, j int) bool { return waiting[i].Position < waiting[j].Position })
+
+	estimates := make(map[string]int64, len(waiting))
+	for _, entry := range waiting {
+		next := earliest(freeAt)
+		estimates[entry.ID] = freeAt[next]
+		freeAt[next] += serviceDurationSeconds(entry)
+	}
+	return estimates
+}
+
+func serviceDurationSeconds(entry *Entry) int64 {
+	if entry.ApproximateDurationSeconds > 0 {
+		return entry.ApproximateDurationSeconds
+	}
+	return defaultServiceDurationSeconds
+}
+
+// earliest returns the index of the service point that is free first
+func earliest(freeAt []int64) int {
+	next := 0
+	for i, t := range freeAt {
+		if t < freeAt[next] {
+			next = i
+		}
+	}
+	return next
+}
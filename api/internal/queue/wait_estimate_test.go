@@ -0,0 +1,53 @@
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+// TestEstimateWaitSeconds tests the wait estimate for different numbers of service points
+func TestEstimateWaitSeconds(t *testing.T) {
+	now := time.Now()
+	entries := []*Entry{
+		{ID: "served", Status: "IN_SERVICE", ApproximateDurationSeconds: 600, UpdatedAt: now.Add(-4 * time.Minute)},
+		{ID: "third", Status: "WAITING", Position: 3, ApproximateDurationSeconds: 300},
+		{ID: "first", Status: "WAITING", Position: 1, ApproximateDurationSeconds: 900},
+		{ID: "second", Status: "WAITING", Position: 2}, // no duration: default 5 minutes
+	}
+
+	tests := []struct {
+		name          string
+		servicePoints int
+		expected      map[string]int64
+	}{
+		{
+			name:          "one service point serves everyone in turn",
+			servicePoints: 1,
+			expected:      map[string]int64{"first": 360, "second": 1260, "third": 1560},
+		},
+		{
+			name:          "two service points share the queue",
+			servicePoints: 2,
+			expected:      map[string]int64{"first": 0, "second": 360, "third": 660},
+		},
+		{
+			name:          "zero service points counts as one",
+			servicePoints: 0,
+			expected:      map[string]int64{"first": 360, "second": 1260, "third": 1560},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			estimates := estimateWaitSeconds(entries, tt.servicePoints, now)
+			if len(estimates) != len(tt.expected) {
+				t.Fatalf("Expected %d estimates, got %d", len(tt.expected), len(estimates))
+			}
+			for id, expected := range tt.expected {
+				if estimates[id] != expected {
+					t.Errorf("Expected %s to wait %d seconds, got %d", id, expected, estimates[id])
+				}
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package queue
 
 import (
 	"context"
+	"sync"
 
 	"github.com/arfis/waiting-room/internal/config"
 	"github.com/arfis/waiting-room/internal/priority"
@@ -17,22 +18,55 @@ type CardData = types.CardData
 // WaitingQueue manages the queue of patients waiting for service
 // Methods are organized across multiple files:
 // - entry_creation.go: CreateEntry with priority calculation
+// - ticket_number.go: ticket number allocation and formatting
+// - walk_in.go: CreateWalkInEntry
+// - swipe_dedup.go: SwipeKeys, FindDuplicateSwipe, JoinOnce
 // - entry_retrieval.go: GetQueueEntries, GetQueueEntriesWithContext, GetEntryByQRToken
 // - entry_management.go: UpdateEntryStatus, DeleteEntry
 // - queue_operations.go: CallNext, FinishCurrent
 // - servicepoint_operations.go: CallNextForServicePoint, CallSpecificEntryForServicePoint, etc.
 // - service_points.go: GetServicePoints
+// - service_matching.go: services of service points for CallNextForServicePoint
+// - assignment.go: AssignmentStrategy of new entries to service points
+// - transfer.go: TransferEntry
+// - recall.go: RecallEntry
+// - cancel.go: CancelEntryByQRToken
+// - contact.go: RegisterContactByQRToken, ApproachingEntries, TakeEntryContact of patient notifications
+// - push.go: SetPushSubscriptionByQRToken, PushMilestoneEntries of Web Push notifications
+// - annotations.go: AnnotateEntry
+// - priority_boost.go: BoostEntryPriority
+// - history.go: GetEntryHistory, recordTransition
+// - bulk.go: ClearWaiting, MarkCalledAsNoShow, MoveWaitingEntries
+// - room_state.go: GetRoomStates, SetRoomPaused, IntakeRoom
+// - capacity.go: QueueFullError, WAITING limits of rooms
+// - symbols.go: NormalizeSymbols against the priority symbol catalog
+// - pathway.go: StartPathway, completeEntry
+// - expiry.go: ExpireCalledEntries
+// - archive.go: ArchiveEntries
+// - retention.go: ApplyRetentionPolicies, EraseDataSubject
+// - changes.go: WatchChanges
+// - display.go: RoomDisplay settings of display boards
+// - priority_recalculation.go: RecalculatePriorities
+// - wait_estimate.go: EstimateWaitTimes
+// - metrics.go: CollectQueueDepth, the queue metrics of transitions
 type WaitingQueue struct {
 	repo            repository.QueueRepository
 	config          *config.Config
 	configService   ConfigService
 	servicePointSvc *servicepoint.Service
 	priorityRepo    *priority.Repository
+	stageHandler    func(ctx context.Context, entry *Entry, fromRoomId string)
+	historyRepo     repository.EntryHistoryRepository
+	swipeMu         sync.Mutex // serialises the duplicate check and creation of swipes
+	assignMu        sync.Mutex
+	assignTurns     map[string]int // entries assigned per tenant and room, for round-robin assignment
 }
 
 // ConfigService interface for getting tenant-aware configuration
 type ConfigService interface {
 	GetRoomsConfig(ctx context.Context) ([]types.RoomConfig, error)
+	GetPathways(ctx context.Context) ([]types.Pathway, error)
+	GetRetentionPolicies(ctx context.Context) (map[string]types.RetentionPolicy, error)
 }
 
 // NewWaitingQueue creates a new waiting queue instance
@@ -42,6 +76,7 @@ func NewWaitingQueue(repo repository.QueueRepository, cfg *config.Config, servic
 		config:          cfg,
 		servicePointSvc: servicePointSvc,
 		priorityRepo:    priorityRepo,
+		assignTurns:     make(map[string]int),
 	}
 }
 
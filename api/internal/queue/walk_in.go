@@ -0,0 +1,46 @@
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"math/big"
+)
+
+// visitorCodeAlphabet leaves out characters that are easily confused when read aloud or from a screen
+const visitorCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// visitorCodeLength is the number of characters of a visitor code
+const visitorCodeLength = 6
+
+// CreateWalkInEntry creates an anonymous entry without card data for a visitor who has no readable
+// card. The entry gets a generated visitor code staff can call out when there is no name.
+func (s *WaitingQueue) CreateWalkInEntry(ctx context.Context, roomId string, approximateDurationSeconds int64,
+	serviceName string, idempotencyKey string) (*Entry, error) {
+	visitorCode, err := newVisitorCode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate visitor code: %w", err)
+	}
+
+	entry, err := s.createEntry(ctx, roomId, CardData{}, visitorCode, approximateDurationSeconds, serviceName,
+		nil, nil, nil, nil, idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[WaitingQueue] Created walk-in entry %s with visitor code %s", entry.ID, visitorCode)
+	return entry, nil
+}
+
+func newVisitorCode() (string, error) {
+	code := make([]byte, visitorCodeLength)
+	max := big.NewInt(int64(len(visitorCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = visitorCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
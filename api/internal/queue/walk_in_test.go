@@ -0,0 +1,39 @@
+package queue
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/repository"
+)
+
+// TestCreateWalkInEntry tests that anonymous entries get a visitor code instead of card data
+func TestCreateWalkInEntry(t *testing.T) {
+	wq := NewWaitingQueue(repository.NewMockQueueRepository(), &config.Config{}, nil, nil)
+
+	entry, err := wq.CreateWalkInEntry(context.Background(), "triage-1", 300, "Blood test", "")
+	if err != nil {
+		t.Fatalf("CreateWalkInEntry failed: %v", err)
+	}
+	if entry.CardData != (CardData{}) {
+		t.Errorf("Expected no card data, got %+v", entry.CardData)
+	}
+	if entry.QRToken == "" || entry.TicketNumber == "" || entry.Status != "WAITING" {
+		t.Errorf("Expected a waiting entry with ticket and QR token, got %+v", entry)
+	}
+	if len(entry.VisitorCode) != visitorCodeLength {
+		t.Fatalf("Expected a %d character visitor code, got '%s'", visitorCodeLength, entry.VisitorCode)
+	}
+	for _, c := range entry.VisitorCode {
+		if !strings.ContainsRune(visitorCodeAlphabet, c) {
+			t.Errorf("Unexpected character %q in visitor code %s", c, entry.VisitorCode)
+		}
+	}
+
+	other, _ := wq.CreateWalkInEntry(context.Background(), "triage-1", 300, "", "")
+	if other.VisitorCode == entry.VisitorCode {
+		t.Errorf("Expected different visitor codes, got %s twice", entry.VisitorCode)
+	}
+}
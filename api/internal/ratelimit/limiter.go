@@ -0,0 +1,85 @@
+// Package ratelimit limits the requests of clients with a token bucket per client.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the buckets of idle clients are dropped
+const sweepInterval = time.Minute
+
+// Limiter holds a token bucket per client key. A bucket holds up to burst tokens and is refilled
+// at the rate; every request takes one token.
+type Limiter struct {
+	rate  float64 // tokens per second
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// New returns a limiter refilling requestsPerMinute tokens a minute into buckets of burst tokens
+func New(requestsPerMinute, burst int) *Limiter {
+	return &Limiter{
+		rate:    float64(requestsPerMinute) / 60,
+		burst:   float64(max(burst, 1)),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token from the bucket of the client. When the bucket is empty it returns false
+// and how long until the next token.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	return l.allowAt(key, time.Now())
+}
+
+func (l *Limiter) allowAt(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > sweepInterval {
+		l.sweep(now)
+	}
+
+	b, found := l.buckets[key]
+	if !found {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[key] = b
+	} else {
+		b.tokens = l.refilled(b, now)
+		b.updated = now
+	}
+
+	if b.tokens < 1 {
+		if l.rate <= 0 {
+			return false, time.Duration(math.MaxInt64)
+		}
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// refilled returns the tokens of the bucket at now; l.mu must be held
+func (l *Limiter) refilled(b *bucket, now time.Time) float64 {
+	return math.Min(l.burst, b.tokens+now.Sub(b.updated).Seconds()*l.rate)
+}
+
+// sweep drops the buckets that are full again, which behave like new ones; l.mu must be held
+func (l *Limiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if l.refilled(b, now) >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
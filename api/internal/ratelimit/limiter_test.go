@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiterBurstAndRefill(t *testing.T) {
+	limiter := New(60, 3)
+	now := time.Unix(1000, 0)
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := limiter.allowAt("a", now); !ok {
+			t.Fatalf("Expected request %d of the burst to pass", i+1)
+		}
+	}
+	ok, wait := limiter.allowAt("a", now)
+	if ok {
+		t.Fatal("Expected the request after the burst to be limited")
+	}
+	if wait != time.Second {
+		t.Errorf("Expected to wait 1s for the next token, got %v", wait)
+	}
+
+	if ok, _ := limiter.allowAt("b", now); !ok {
+		t.Error("Expected another client to have its own bucket")
+	}
+
+	if ok, _ := limiter.allowAt("a", now.Add(500*time.Millisecond)); ok {
+		t.Error("Expected half a token not to pass")
+	}
+	if ok, _ := limiter.allowAt("a", now.Add(time.Second)); !ok {
+		t.Error("Expected the refilled token to pass")
+	}
+}
+
+func TestLimiterSweep(t *testing.T) {
+	limiter := New(60, 2)
+	now := time.Unix(1000, 0)
+	limiter.allowAt("idle", now)
+	limiter.allowAt("busy", now)
+	limiter.allowAt("busy", now)
+
+	// The busy client keeps using its bucket, the idle one's is full again when the sweep runs
+	limiter.allowAt("busy", now.Add(60*time.Second))
+	limiter.allowAt("busy", now.Add(60*time.Second))
+	limiter.allowAt("other", now.Add(61*time.Second))
+	if _, found := limiter.buckets["idle"]; found {
+		t.Error("Expected the full bucket of the idle client to be dropped")
+	}
+	if _, found := limiter.buckets["busy"]; !found {
+		t.Error("Expected the bucket of the busy client to be kept")
+	}
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// AppointmentRepository stores scheduled appointments. Every method is scoped to the tenant in the context.
+type AppointmentRepository interface {
+	// UpsertAppointment creates or updates an appointment by its external ID; check-in state is kept
+	UpsertAppointment(ctx context.Context, appointment *types.Appointment) error
+
+	// GetAppointments returns the appointments scheduled between from and to, ordered by time
+	GetAppointments(ctx context.Context, from, to time.Time) ([]*types.Appointment, error)
+
+	// GetScheduledAppointments returns the SCHEDULED appointments of a patient between from and to
+	GetScheduledAppointments(ctx context.Context, identifier string, from, to time.Time) ([]*types.Appointment, error)
+
+	// GetAppointmentByExternalID returns the appointment with an external ID, nil when there is none
+	GetAppointmentByExternalID(ctx context.Context, externalId string) (*types.Appointment, error)
+
+	// MarkAppointmentCheckedIn links an appointment to the queue entry created for it
+	MarkAppointmentCheckedIn(ctx context.Context, id, entryId string) error
+
+	// DeleteTenantAppointments deletes all appointments of the tenant and returns their number;
+	// without a tenant it deletes nothing
+	DeleteTenantAppointments(ctx context.Context) (int64, error)
+}
+
+type MongoDBAppointmentRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoDBAppointmentRepository(db *mongo.Database) *MongoDBAppointmentRepository {
+	return &MongoDBAppointmentRepository{
+		collection: db.Collection("appointments"),
+	}
+}
+
+// appointmentTenantFilter limits a query to the tenant in the context
+func appointmentTenantFilter(ctx context.Context) bson.M {
+	filter := bson.M{}
+	buildingID, sectionID, err := types.ParseTenantID(getTenantIDFromContext(ctx))
+	if err != nil {
+		return filter
+	}
+	filter["tenantId"] = buildingID
+	if sectionID != "" {
+		filter["sectionId"] = sectionID
+	}
+	return filter
+}
+
+func (r *MongoDBAppointmentRepository) UpsertAppointment(ctx context.Context, appointment *types.Appointment) error {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+
+	filter := appointmentTenantFilter(ctx)
+	filter["externalId"] = appointment.ExternalID
+
+	var existing types.Appointment
+	err := r.collection.FindOne(ctx, filter).Decode(&existing)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return fmt.Errorf("failed to get appointment: %w", err)
+	}
+	found := err == nil
+
+	now := time.Now()
+	appointment.TenantID = buildingID
+	appointment.SectionID = sectionID
+	appointment.Status = upsertedAppointmentStatus(appointment.Status, existing.Status)
+	appointment.UpdatedAt = now
+	if found {
+		appointment.ID = existing.ID
+		appointment.EntryID = existing.EntryID
+		appointment.CreatedAt = existing.CreatedAt
+	} else {
+		appointment.ID = uuid.New().String()
+		appointment.EntryID = ""
+		appointment.CreatedAt = now
+	}
+
+	upsert := true
+	if _, err := r.collection.ReplaceOne(ctx, bson.M{"_id": appointment.ID}, appointment, &options.ReplaceOptions{Upsert: &upsert}); err != nil {
+		return fmt.Errorf("failed to upsert appointment: %w", err)
+	}
+	return nil
+}
+
+// upsertedAppointmentStatus is the status after an import: CANCELLED when the source cancelled it,
+// otherwise a checked-in appointment stays checked in and everything else is SCHEDULED
+func upsertedAppointmentStatus(imported, existing string) string {
+	if imported == "CANCELLED" {
+		return "CANCELLED"
+	}
+	if existing == "CHECKED_IN" {
+		return "CHECKED_IN"
+	}
+	return "SCHEDULED"
+}
+
+func (r *MongoDBAppointmentRepository) GetAppointments(ctx context.Context, from, to time.Time) ([]*types.Appointment, error) {
+	filter := appointmentTenantFilter(ctx)
+	filter["appointmentTime"] = bson.M{"$gte": from, "$lt": to}
+	return r.find(ctx, filter)
+}
+
+func (r *MongoDBAppointmentRepository) GetScheduledAppointments(ctx context.Context, identifier string, from, to time.Time) ([]*types.Appointment, error) {
+	filter := appointmentTenantFilter(ctx)
+	filter["identifier"] = identifier
+	filter["status"] = "SCHEDULED"
+	filter["appointmentTime"] = bson.M{"$gte": from, "$lte": to}
+	return r.find(ctx, filter)
+}
+
+func (r *MongoDBAppointmentRepository) GetAppointmentByExternalID(ctx context.Context, externalId string) (*types.Appointment, error) {
+	filter := appointmentTenantFilter(ctx)
+	filter["externalId"] = externalId
+
+	var appointment types.Appointment
+	err := r.collection.FindOne(ctx, filter).Decode(&appointment)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appointment: %w", err)
+	}
+	return &appointment, nil
+}
+
+func (r *MongoDBAppointmentRepository) find(ctx context.Context, filter bson.M) ([]*types.Appointment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "appointmentTime", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find appointments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var appointments []*types.Appointment
+	if err := cursor.All(ctx, &appointments); err != nil {
+		return nil, fmt.Errorf("failed to decode appointments: %w", err)
+	}
+	return appointments, nil
+}
+
+func (r *MongoDBAppointmentRepository) MarkAppointmentCheckedIn(ctx context.Context, id, entryId string) error {
+	update := bson.M{"$set": bson.M{
+		"status":    "CHECKED_IN",
+		"entryId":   entryId,
+		"updatedAt": time.Now(),
+	}}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return fmt.Errorf("failed to mark appointment as checked in: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("appointment not found")
+	}
+	return nil
+}
+
+func (r *MongoDBAppointmentRepository) DeleteTenantAppointments(ctx context.Context) (int64, error) {
+	filter := appointmentTenantFilter(ctx)
+	if len(filter) == 0 {
+		return 0, fmt.Errorf("a tenant is required to delete its appointments")
+	}
+	result, err := r.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete appointments of tenant: %w", err)
+	}
+	return result.DeletedCount, nil
+}
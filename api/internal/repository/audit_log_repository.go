@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// AuditLogRepository stores the audit log. It is append-only: entries are never updated or deleted.
+type AuditLogRepository interface {
+	// AppendAuditEntry stores a new entry
+	AppendAuditEntry(ctx context.Context, entry *types.AuditEntry) error
+
+	// GetAuditEntries returns up to limit entries of the tenant in ctx matching the filter, newest first
+	GetAuditEntries(ctx context.Context, filter types.AuditFilter, limit int) ([]*types.AuditEntry, error)
+}
+
+type MongoDBAuditLogRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoDBAuditLogRepository(db *mongo.Database) *MongoDBAuditLogRepository {
+	// The values of the changes are decoded as maps, not bson.D, so they serialize to JSON as stored
+	collection := db.Collection("audit_log", options.Collection().SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "sectionId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "resource", Value: 1}, {Key: "createdAt", Value: -1}}},
+	}
+	if _, err := collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		// Log but don't fail - index might already exist
+		log.Printf("Audit log index creation warning (may already exist): %v", err)
+	}
+
+	return &MongoDBAuditLogRepository{
+		collection: collection,
+	}
+}
+
+func (r *MongoDBAuditLogRepository) AppendAuditEntry(ctx context.Context, entry *types.AuditEntry) error {
+	if _, err := r.collection.InsertOne(ctx, entry); err != nil {
+		return fmt.Errorf("failed to append audit entry: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoDBAuditLogRepository) GetAuditEntries(ctx context.Context, auditFilter types.AuditFilter, limit int) ([]*types.AuditEntry, error) {
+	filter := r.tenantFilter(ctx)
+	if auditFilter.Resource != "" {
+		filter["resource"] = auditFilter.Resource
+	}
+	if auditFilter.Action != "" {
+		filter["action"] = auditFilter.Action
+	}
+	if auditFilter.Actor != "" {
+		filter["actor"] = auditFilter.Actor
+	}
+	createdAt := bson.M{}
+	if !auditFilter.From.IsZero() {
+		createdAt["$gte"] = auditFilter.From
+	}
+	if !auditFilter.To.IsZero() {
+		createdAt["$lt"] = auditFilter.To
+	}
+	if len(createdAt) > 0 {
+		filter["createdAt"] = createdAt
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find audit entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*types.AuditEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode audit entries: %w", err)
+	}
+	return entries, nil
+}
+
+// tenantFilter filters the entries of the tenant in ctx; a building sees those of its sections
+func (r *MongoDBAuditLogRepository) tenantFilter(ctx context.Context) bson.M {
+	filter := bson.M{}
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	if buildingID != "" {
+		filter["tenantId"] = buildingID
+	}
+	if sectionID != "" {
+		filter["sectionId"] = sectionID
+	}
+	return filter
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// CardDataCipher encrypts the personal fields of card data before it is written and decrypts
+// them when it is read, with the data key of the tenant of the entry
+type CardDataCipher interface {
+	EncryptCardData(ctx context.Context, tenantID string, card *types.CardData) error
+	DecryptCardData(ctx context.Context, tenantID string, card *types.CardData) error
+
+	// IDNumberIndexes returns the indexes the cards with idNumber are stored with: for the
+	// tenant, or for each tenant when tenantID is empty
+	IDNumberIndexes(ctx context.Context, tenantID, idNumber string) ([]string, error)
+}
+
+// encryptedEntry returns a copy of entry with encrypted card data to write, entry itself without
+// a cipher
+func encryptedEntry(ctx context.Context, cipher CardDataCipher, entry *types.Entry) (*types.Entry, error) {
+	if cipher == nil {
+		return entry, nil
+	}
+	encrypted := *entry
+	if err := cipher.EncryptCardData(ctx, entry.TenantID, &encrypted.CardData); err != nil {
+		return nil, fmt.Errorf("failed to encrypt card data: %w", err)
+	}
+	return &encrypted, nil
+}
+
+// decryptEntries decrypts the card data of entries read from the database
+func decryptEntries(ctx context.Context, cipher CardDataCipher, entries ...*types.Entry) error {
+	if cipher == nil {
+		return nil
+	}
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		if err := cipher.DecryptCardData(ctx, entry.TenantID, &entry.CardData); err != nil {
+			return fmt.Errorf("failed to decrypt card data of entry %s: %w", entry.ID, err)
+		}
+	}
+	return nil
+}
+
+// idNumberIndexes returns the indexes of idNumber for the tenant in ctx, or of all tenants
+// without one; none without a cipher
+func idNumberIndexes(ctx context.Context, cipher CardDataCipher, idNumber string) ([]string, error) {
+	if cipher == nil || idNumber == "" {
+		return nil, nil
+	}
+	buildingID, _, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	indexes, err := cipher.IDNumberIndexes(ctx, buildingID, idNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ID number indexes: %w", err)
+	}
+	return indexes, nil
+}
@@ -2,8 +2,11 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"sync/atomic"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -14,11 +17,18 @@ import (
 	"github.com/arfis/waiting-room/internal/types"
 )
 
+// ErrCardReaderOtherTenant is returned when a card reader configuration is saved for the tenant in
+// the context while its device ID is registered to another tenant
+var ErrCardReaderOtherTenant = errors.New("card reader is registered to another tenant")
+
 type ConfigRepository interface {
 	// System configuration management
 	GetSystemConfiguration(ctx context.Context) (*types.SystemConfiguration, error)
 	SetSystemConfiguration(ctx context.Context, config *types.SystemConfiguration) error
 	UpdateSystemConfiguration(ctx context.Context, updates map[string]interface{}) error
+	// GetSystemConfigurationsUpdatedSince gets the configurations of all layers, the global one
+	// included, updated after since
+	GetSystemConfigurationsUpdatedSince(ctx context.Context, since time.Time) ([]*types.SystemConfiguration, error)
 
 	// Card reader management
 	GetCardReaderStatus(ctx context.Context, id string) (*types.CardReaderStatus, error)
@@ -26,6 +36,24 @@ type ConfigRepository interface {
 	GetAllCardReaders(ctx context.Context) ([]types.CardReaderStatus, error)
 	UpdateCardReaderLastSeen(ctx context.Context, id string) error
 	DeleteCardReader(ctx context.Context, id string) error
+	GetCardReaderConfig(ctx context.Context, id string) (*types.CardReaderConfig, error)
+	// SetCardReaderConfig creates or replaces the configuration of a card reader. With a tenant in
+	// the context, the reader must be of that tenant and not registered to another one
+	// (ErrCardReaderOtherTenant).
+	SetCardReaderConfig(ctx context.Context, config *types.CardReaderConfig) error
+	// GetCardReaderConfigsByRoom gets the remote configurations of the card readers of the tenant in
+	// the context that are assigned to the room
+	GetCardReaderConfigsByRoom(ctx context.Context, roomID string) ([]types.CardReaderConfig, error)
+	GetCardReaderRelease(ctx context.Context, os, arch string) (*types.CardReaderRelease, error)
+	SetCardReaderRelease(ctx context.Context, release *types.CardReaderRelease) error
+	// SaveCardReaderCommand creates or replaces a command for a card reader
+	SaveCardReaderCommand(ctx context.Context, command *types.CardReaderCommand) error
+	// GetCardReaderCommand gets a command of a card reader, nil when there is none. Like the
+	// remote configuration, the tenant filter is only applied when the context carries one.
+	GetCardReaderCommand(ctx context.Context, deviceID, id string) (*types.CardReaderCommand, error)
+	// GetCardReaderCommands gets the commands of a card reader in one of the statuses (any when
+	// empty), newest first and at most limit of them (all when 0)
+	GetCardReaderCommands(ctx context.Context, deviceID string, statuses []string, limit int) ([]types.CardReaderCommand, error)
 
 	// Tenant management
 	CreateTenant(ctx context.Context, tenant *types.Tenant) error
@@ -33,19 +61,44 @@ type ConfigRepository interface {
 	GetAllTenants(ctx context.Context) ([]types.Tenant, error)
 	UpdateTenant(ctx context.Context, tenant *types.Tenant) error
 	DeleteTenant(ctx context.Context, tenantID string) error
+	// ProvisionTenant creates a tenant and sets the fields of the configuration of its layer; both
+	// are saved or neither is
+	ProvisionTenant(ctx context.Context, tenant *types.Tenant, updates map[string]interface{}) error
+	// PurgeTenant deletes a tenant together with the configuration of its layer and its card
+	// readers and their commands, registered with the building or the section
+	PurgeTenant(ctx context.Context, tenant *types.Tenant) error
 }
 
 type MongoDBConfigRepository struct {
-	collection           *mongo.Collection
-	cardReaderCollection *mongo.Collection
-	tenantCollection     *mongo.Collection
+	collection                 *mongo.Collection
+	cardReaderCollection       *mongo.Collection
+	cardReaderConfigCollection *mongo.Collection
+	releaseCollection          *mongo.Collection
+	commandCollection          *mongo.Collection
+	tenantCollection           *mongo.Collection
+	// noTransactions is set once the server turned out not to support transactions
+	noTransactions atomic.Bool
 }
 
 func NewMongoDBConfigRepository(db *mongo.Database) *MongoDBConfigRepository {
+	cardReaderConfigCollection := db.Collection("card_reader_configs")
+
+	// A device ID is registered to one tenant; the index decides between concurrent registrations
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	index := mongo.IndexModel{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)}
+	if _, err := cardReaderConfigCollection.Indexes().CreateOne(ctx, index); err != nil {
+		// Log but don't fail - index might already exist
+		log.Printf("Card reader config index creation warning (may already exist): %v", err)
+	}
+
 	return &MongoDBConfigRepository{
-		collection:           db.Collection("system_configuration"),
-		cardReaderCollection: db.Collection("card_readers"),
-		tenantCollection:     db.Collection("tenants"),
+		collection:                 db.Collection("system_configuration"),
+		cardReaderCollection:       db.Collection("card_readers"),
+		cardReaderConfigCollection: cardReaderConfigCollection,
+		releaseCollection:          db.Collection("card_reader_releases"),
+		commandCollection:          db.Collection("card_reader_commands"),
+		tenantCollection:           db.Collection("tenants"),
 	}
 }
 
@@ -235,7 +288,13 @@ func (r *MongoDBConfigRepository) UpdateSystemConfiguration(ctx context.Context,
 		},
 	}
 
-	log.Printf("Updating MongoDB configuration with: %+v for buildingId: %s, sectionId: %s", updates, buildingID, sectionID)
+	// Log field names only: values can carry API key hashes and other secrets.
+	fields := make([]string, 0, len(updates))
+	for field := range updates {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	log.Printf("Updating MongoDB configuration fields %v for buildingId: %s, sectionId: %s", fields, buildingID, sectionID)
 	result, err := r.collection.UpdateOne(ctx, filter, update, opts)
 	if err != nil {
 		log.Printf("Failed to update MongoDB configuration: %v", err)
@@ -247,6 +306,20 @@ func (r *MongoDBConfigRepository) UpdateSystemConfiguration(ctx context.Context,
 	return nil
 }
 
+func (r *MongoDBConfigRepository) GetSystemConfigurationsUpdatedSince(ctx context.Context, since time.Time) ([]*types.SystemConfiguration, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"updatedAt": bson.M{"$gt": since}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find updated configurations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var configs []*types.SystemConfiguration
+	if err := cursor.All(ctx, &configs); err != nil {
+		return nil, fmt.Errorf("failed to decode updated configurations: %w", err)
+	}
+	return configs, nil
+}
+
 // Card reader management methods
 func (r *MongoDBConfigRepository) GetCardReaderStatus(ctx context.Context, id string) (*types.CardReaderStatus, error) {
 	// Extract tenant ID from context
@@ -363,6 +436,166 @@ func (r *MongoDBConfigRepository) DeleteCardReader(ctx context.Context, id strin
 	return err
 }
 
+// GetCardReaderConfig retrieves the remote configuration for a card reader device.
+// Devices usually pull their configuration before they know their tenant, so the
+// tenant filter is only applied when the request carries one.
+func (r *MongoDBConfigRepository) GetCardReaderConfig(ctx context.Context, id string) (*types.CardReaderConfig, error) {
+	tenantID := getTenantIDFromContext(ctx)
+
+	filter := bson.M{"id": id}
+	if tenantID != "" {
+		filter["tenantId"] = tenantID
+	}
+
+	var config types.CardReaderConfig
+	err := r.cardReaderConfigCollection.FindOne(ctx, filter).Decode(&config)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (r *MongoDBConfigRepository) SetCardReaderConfig(ctx context.Context, config *types.CardReaderConfig) error {
+	// Readers without an explicit tenant inherit the tenant of the admin request
+	if config.TenantID == "" {
+		config.TenantID = getTenantIDFromContext(ctx)
+	}
+	config.UpdatedAt = time.Now()
+
+	opts := options.Replace().SetUpsert(true)
+	filter := bson.M{"id": config.DeviceID}
+	if tenantID := getTenantIDFromContext(ctx); tenantID != "" {
+		if config.TenantID != tenantID {
+			return fmt.Errorf("%w: %s is saved for tenant %s", ErrCardReaderOtherTenant, config.DeviceID, config.TenantID)
+		}
+		others, err := r.cardReaderConfigCollection.CountDocuments(ctx, bson.M{"id": config.DeviceID, "tenantId": bson.M{"$ne": tenantID}})
+		if err != nil {
+			return err
+		}
+		if others > 0 {
+			return fmt.Errorf("%w: %s", ErrCardReaderOtherTenant, config.DeviceID)
+		}
+		filter["tenantId"] = tenantID
+	}
+
+	_, err := r.cardReaderConfigCollection.ReplaceOne(ctx, filter, config, opts)
+	if mongo.IsDuplicateKeyError(err) {
+		// Another tenant registered the device ID since it was counted
+		return fmt.Errorf("%w: %s", ErrCardReaderOtherTenant, config.DeviceID)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[ConfigRepository] Saved card reader config for device %s (tenant: %s, room: %s)", config.DeviceID, config.TenantID, config.RoomID)
+	return nil
+}
+
+func (r *MongoDBConfigRepository) GetCardReaderConfigsByRoom(ctx context.Context, roomID string) ([]types.CardReaderConfig, error) {
+	// Readers without a tenant are stored without the field
+	var tenantFilter interface{} = getTenantIDFromContext(ctx)
+	if tenantFilter == "" {
+		tenantFilter = bson.M{"$in": bson.A{"", nil}}
+	}
+	filter := bson.M{"tenantId": tenantFilter, "roomId": roomID}
+
+	cursor, err := r.cardReaderConfigCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var configs []types.CardReaderConfig
+	if err := cursor.All(ctx, &configs); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+// GetCardReaderRelease returns the current card-reader release for an os/arch.
+// Releases are global, the same binary serves every tenant.
+func (r *MongoDBConfigRepository) GetCardReaderRelease(ctx context.Context, os, arch string) (*types.CardReaderRelease, error) {
+	var release types.CardReaderRelease
+	err := r.releaseCollection.FindOne(ctx, bson.M{"os": os, "arch": arch}).Decode(&release)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &release, nil
+}
+
+func (r *MongoDBConfigRepository) SetCardReaderRelease(ctx context.Context, release *types.CardReaderRelease) error {
+	release.PublishedAt = time.Now()
+
+	opts := options.Replace().SetUpsert(true)
+	filter := bson.M{"os": release.OS, "arch": release.Arch}
+
+	_, err := r.releaseCollection.ReplaceOne(ctx, filter, release, opts)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[ConfigRepository] Published card reader release %s for %s/%s", release.Version, release.OS, release.Arch)
+	return nil
+}
+
+func (r *MongoDBConfigRepository) SaveCardReaderCommand(ctx context.Context, command *types.CardReaderCommand) error {
+	if command.TenantID == "" {
+		command.TenantID = getTenantIDFromContext(ctx)
+	}
+	opts := options.Replace().SetUpsert(true)
+	_, err := r.commandCollection.ReplaceOne(ctx, bson.M{"id": command.ID}, command, opts)
+	return err
+}
+
+func (r *MongoDBConfigRepository) GetCardReaderCommand(ctx context.Context, deviceID, id string) (*types.CardReaderCommand, error) {
+	filter := bson.M{"id": id, "deviceId": deviceID}
+	if tenantID := getTenantIDFromContext(ctx); tenantID != "" {
+		filter["tenantId"] = tenantID
+	}
+
+	var command types.CardReaderCommand
+	err := r.commandCollection.FindOne(ctx, filter).Decode(&command)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &command, nil
+}
+
+func (r *MongoDBConfigRepository) GetCardReaderCommands(ctx context.Context, deviceID string, statuses []string, limit int) ([]types.CardReaderCommand, error) {
+	filter := bson.M{"deviceId": deviceID}
+	if tenantID := getTenantIDFromContext(ctx); tenantID != "" {
+		filter["tenantId"] = tenantID
+	}
+	if len(statuses) > 0 {
+		filter["status"] = bson.M{"$in": statuses}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := r.commandCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	commands := []types.CardReaderCommand{}
+	if err := cursor.All(ctx, &commands); err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
 // Tenant management methods
 func (r *MongoDBConfigRepository) CreateTenant(ctx context.Context, tenant *types.Tenant) error {
 	now := time.Now()
@@ -439,11 +672,12 @@ func (r *MongoDBConfigRepository) UpdateTenant(ctx context.Context, tenant *type
 	filter := bson.M{"id": tenantID}
 	update := bson.M{
 		"$set": bson.M{
-			"buildingId":  tenant.BuildingID,
-			"sectionId":   tenant.SectionID,
-			"name":        tenant.Name,
-			"description": tenant.Description,
-			"updatedAt":   tenant.UpdatedAt,
+			"buildingId":    tenant.BuildingID,
+			"sectionId":     tenant.SectionID,
+			"name":          tenant.Name,
+			"description":   tenant.Description,
+			"updatedAt":     tenant.UpdatedAt,
+			"deactivatedAt": tenant.DeactivatedAt,
 		},
 	}
 
@@ -471,3 +705,55 @@ func (r *MongoDBConfigRepository) DeleteTenant(ctx context.Context, tenantID str
 	log.Printf("Deleted tenant with ID: %s", tenantID)
 	return nil
 }
+
+func (r *MongoDBConfigRepository) ProvisionTenant(ctx context.Context, tenant *types.Tenant, updates map[string]interface{}) error {
+	return r.withTransaction(ctx, func(txCtx context.Context) error {
+		if err := r.CreateTenant(txCtx, tenant); err != nil {
+			return err
+		}
+		return r.UpdateSystemConfiguration(context.WithValue(txCtx, middleware.TENANT, tenant.GetFullTenantID()), updates)
+	})
+}
+
+func (r *MongoDBConfigRepository) PurgeTenant(ctx context.Context, tenant *types.Tenant) error {
+	readerFilter := bson.M{"tenantId": bson.M{"$in": []string{tenant.GetTenantID(), tenant.GetFullTenantID()}}}
+	return r.withTransaction(ctx, func(txCtx context.Context) error {
+		if _, err := r.collection.DeleteMany(txCtx, bson.M{"tenantId": tenant.BuildingID, "sectionId": tenant.SectionID}); err != nil {
+			return fmt.Errorf("failed to delete configuration of tenant: %w", err)
+		}
+		if _, err := r.cardReaderCollection.DeleteMany(txCtx, readerFilter); err != nil {
+			return fmt.Errorf("failed to delete card readers of tenant: %w", err)
+		}
+		if _, err := r.cardReaderConfigCollection.DeleteMany(txCtx, readerFilter); err != nil {
+			return fmt.Errorf("failed to delete card reader configs of tenant: %w", err)
+		}
+		if _, err := r.commandCollection.DeleteMany(txCtx, readerFilter); err != nil {
+			return fmt.Errorf("failed to delete card reader commands of tenant: %w", err)
+		}
+		return r.DeleteTenant(txCtx, tenant.GetTenantID())
+	})
+}
+
+// withTransaction runs fn in a MongoDB transaction, or in the one of ctx when there is one. A
+// standalone server (e.g. local development) does not support transactions; fn then runs without one.
+func (r *MongoDBConfigRepository) withTransaction(ctx context.Context, fn func(txCtx context.Context) error) error {
+	if r.noTransactions.Load() || mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	session, err := r.collection.Database().Client().StartSession()
+	if err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+	if err != nil && isTransactionNotSupported(err) {
+		log.Printf("[ConfigRepository] Transactions are not supported by the server, running without them")
+		r.noTransactions.Store(true)
+		return fn(ctx)
+	}
+	return err
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// DataKeyRepository stores the wrapped data keys personal card data is encrypted with, one per
+// tenant. Keys are never updated, and only deleted with the last tenant of their building: without
+// its key, the card data of a tenant can't be read anymore.
+type DataKeyRepository interface {
+	// GetDataKey returns the data key of the tenant, nil when it has none yet
+	GetDataKey(ctx context.Context, tenantID string) (*types.DataKey, error)
+
+	// GetDataKeys returns the data keys of all tenants
+	GetDataKeys(ctx context.Context) ([]*types.DataKey, error)
+
+	// CreateDataKey stores the data key unless the tenant has one already, and returns the
+	// tenant's stored key either way
+	CreateDataKey(ctx context.Context, key *types.DataKey) (*types.DataKey, error)
+
+	// DeleteDataKey deletes the data key of the tenant, if it has one
+	DeleteDataKey(ctx context.Context, tenantID string) error
+}
+
+type MongoDBDataKeyRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoDBDataKeyRepository(db *mongo.Database) *MongoDBDataKeyRepository {
+	return &MongoDBDataKeyRepository{
+		collection: db.Collection("data_keys"),
+	}
+}
+
+// GetDataKey returns the data key of the tenant, nil when it has none yet
+func (r *MongoDBDataKeyRepository) GetDataKey(ctx context.Context, tenantID string) (*types.DataKey, error) {
+	var key types.DataKey
+	if err := r.collection.FindOne(ctx, bson.M{"_id": tenantID}).Decode(&key); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get data key: %w", err)
+	}
+	return &key, nil
+}
+
+// GetDataKeys returns the data keys of all tenants
+func (r *MongoDBDataKeyRepository) GetDataKeys(ctx context.Context) ([]*types.DataKey, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data keys: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var keys []*types.DataKey
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, fmt.Errorf("failed to decode data keys: %w", err)
+	}
+	return keys, nil
+}
+
+// CreateDataKey stores the data key unless the tenant has one already, and returns the tenant's
+// stored key either way
+func (r *MongoDBDataKeyRepository) CreateDataKey(ctx context.Context, key *types.DataKey) (*types.DataKey, error) {
+	if _, err := r.collection.InsertOne(ctx, key); err != nil {
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, fmt.Errorf("failed to create data key: %w", err)
+		}
+		// Another replica created the key first
+		return r.GetDataKey(ctx, key.TenantID)
+	}
+	return key, nil
+}
+
+// DeleteDataKey deletes the data key of the tenant, if it has one
+func (r *MongoDBDataKeyRepository) DeleteDataKey(ctx context.Context, tenantID string) error {
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": tenantID}); err != nil {
+		return fmt.Errorf("failed to delete data key: %w", err)
+	}
+	return nil
+}
+
+type PostgresDataKeyRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresDataKeyRepository(db *sql.DB) *PostgresDataKeyRepository {
+	return &PostgresDataKeyRepository{db: db}
+}
+
+// GetDataKey returns the data key of the tenant, nil when it has none yet
+func (r *PostgresDataKeyRepository) GetDataKey(ctx context.Context, tenantID string) (*types.DataKey, error) {
+	var document []byte
+	err := r.db.QueryRowContext(ctx, `SELECT document FROM data_keys WHERE tenant_id = $1`, tenantID).Scan(&document)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get data key: %w", err)
+	}
+	var key types.DataKey
+	if err := fromDocument(document, &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetDataKeys returns the data keys of all tenants
+func (r *PostgresDataKeyRepository) GetDataKeys(ctx context.Context) ([]*types.DataKey, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT document FROM data_keys`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*types.DataKey
+	for rows.Next() {
+		var document []byte
+		if err := rows.Scan(&document); err != nil {
+			return nil, fmt.Errorf("failed to read data key: %w", err)
+		}
+		var key types.DataKey
+		if err := fromDocument(document, &key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, &key)
+	}
+	return keys, rows.Err()
+}
+
+// CreateDataKey stores the data key unless the tenant has one already, and returns the tenant's
+// stored key either way
+func (r *PostgresDataKeyRepository) CreateDataKey(ctx context.Context, key *types.DataKey) (*types.DataKey, error) {
+	document, err := toDocument(key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.db.ExecContext(ctx, `INSERT INTO data_keys (tenant_id, document) VALUES ($1, $2) ON CONFLICT (tenant_id) DO NOTHING`, key.TenantID, document); err != nil {
+		return nil, fmt.Errorf("failed to create data key: %w", err)
+	}
+	return r.GetDataKey(ctx, key.TenantID)
+}
+
+// DeleteDataKey deletes the data key of the tenant, if it has one
+func (r *PostgresDataKeyRepository) DeleteDataKey(ctx context.Context, tenantID string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM data_keys WHERE tenant_id = $1`, tenantID); err != nil {
+		return fmt.Errorf("failed to delete data key: %w", err)
+	}
+	return nil
+}
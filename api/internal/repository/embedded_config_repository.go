@@ -0,0 +1,530 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// EmbeddedConfigRepository implements ConfigRepository in memory for the embedded mode, with the
+// same tenant scoping as MongoDBConfigRepository. EmbeddedQueueRepository snapshots it together
+// with the queue.
+type EmbeddedConfigRepository struct {
+	mutex             sync.RWMutex
+	systemConfigs     map[string]*types.SystemConfiguration // by "buildingId:sectionId"
+	cardReaders       map[string]*types.CardReaderStatus    // by "tenantId|id"
+	cardReaderConfigs map[string]*types.CardReaderConfig    // by device ID
+	releases          map[string]*types.CardReaderRelease   // by "os/arch"
+	commands          map[string]*types.CardReaderCommand   // by ID
+	tenants           map[string]*types.Tenant
+}
+
+// embeddedConfigSnapshot is the configuration part of an embedded snapshot file
+type embeddedConfigSnapshot struct {
+	SystemConfigs     []*types.SystemConfiguration `bson:"systemConfigs"`
+	CardReaders       []*types.CardReaderStatus    `bson:"cardReaders"`
+	CardReaderConfigs []*types.CardReaderConfig    `bson:"cardReaderConfigs"`
+	Releases          []*types.CardReaderRelease   `bson:"releases"`
+	Commands          []*types.CardReaderCommand   `bson:"commands"`
+	Tenants           []*types.Tenant              `bson:"tenants"`
+}
+
+// NewEmbeddedConfigRepository creates an empty in-memory config repository
+func NewEmbeddedConfigRepository() *EmbeddedConfigRepository {
+	return &EmbeddedConfigRepository{
+		systemConfigs:     make(map[string]*types.SystemConfiguration),
+		cardReaders:       make(map[string]*types.CardReaderStatus),
+		cardReaderConfigs: make(map[string]*types.CardReaderConfig),
+		releases:          make(map[string]*types.CardReaderRelease),
+		commands:          make(map[string]*types.CardReaderCommand),
+		tenants:           make(map[string]*types.Tenant),
+	}
+}
+
+// snapshot returns a copy of the stored configuration
+func (r *EmbeddedConfigRepository) snapshot() embeddedConfigSnapshot {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var snapshot embeddedConfigSnapshot
+	for _, config := range r.systemConfigs {
+		copied := *config
+		snapshot.SystemConfigs = append(snapshot.SystemConfigs, &copied)
+	}
+	for _, status := range r.cardReaders {
+		copied := *status
+		snapshot.CardReaders = append(snapshot.CardReaders, &copied)
+	}
+	for _, config := range r.cardReaderConfigs {
+		copied := *config
+		snapshot.CardReaderConfigs = append(snapshot.CardReaderConfigs, &copied)
+	}
+	for _, release := range r.releases {
+		copied := *release
+		snapshot.Releases = append(snapshot.Releases, &copied)
+	}
+	for _, command := range r.commands {
+		copied := *command
+		snapshot.Commands = append(snapshot.Commands, &copied)
+	}
+	for _, tenant := range r.tenants {
+		copied := *tenant
+		snapshot.Tenants = append(snapshot.Tenants, &copied)
+	}
+	return snapshot
+}
+
+// restore replaces the stored configuration with a snapshot
+func (r *EmbeddedConfigRepository) restore(snapshot embeddedConfigSnapshot) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, config := range snapshot.SystemConfigs {
+		r.systemConfigs[config.TenantID+":"+config.SectionID] = config
+	}
+	for _, status := range snapshot.CardReaders {
+		r.cardReaders[status.TenantID+"|"+status.ID] = status
+	}
+	for _, config := range snapshot.CardReaderConfigs {
+		r.cardReaderConfigs[config.DeviceID] = config
+	}
+	for _, release := range snapshot.Releases {
+		r.releases[release.OS+"/"+release.Arch] = release
+	}
+	for _, command := range snapshot.Commands {
+		r.commands[command.ID] = command
+	}
+	for _, tenant := range snapshot.Tenants {
+		r.tenants[tenant.ID] = tenant
+	}
+}
+
+// System configuration management methods
+func (r *EmbeddedConfigRepository) GetSystemConfiguration(ctx context.Context) (*types.SystemConfiguration, error) {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	config, exists := r.systemConfigs[buildingID+":"+sectionID]
+	if !exists {
+		return nil, nil
+	}
+	copied := *config
+	return &copied, nil
+}
+
+func (r *EmbeddedConfigRepository) SetSystemConfiguration(ctx context.Context, config *types.SystemConfiguration) error {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	key := buildingID + ":" + sectionID
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	config.TenantID = buildingID
+	config.SectionID = sectionID
+	config.CreatedAt = now
+	if existing, exists := r.systemConfigs[key]; exists {
+		config.CreatedAt = existing.CreatedAt
+	}
+	config.UpdatedAt = now
+
+	stored := *config
+	r.systemConfigs[key] = &stored
+	return nil
+}
+
+func (r *EmbeddedConfigRepository) UpdateSystemConfiguration(ctx context.Context, updates map[string]interface{}) error {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	key := buildingID + ":" + sectionID
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Like a MongoDB $set, the updates replace single fields of the stored configuration
+	now := time.Now()
+	config, exists := r.systemConfigs[key]
+	if !exists {
+		config = &types.SystemConfiguration{CreatedAt: now}
+	}
+	document := bson.M{}
+	if err := decodeInto(config, &document); err != nil {
+		return err
+	}
+	for field, value := range updates {
+		document[field] = value
+	}
+	document["tenantId"] = buildingID
+	document["sectionId"] = sectionID
+	document["updatedAt"] = now
+
+	var updated types.SystemConfiguration
+	if err := decodeInto(document, &updated); err != nil {
+		return err
+	}
+	r.systemConfigs[key] = &updated
+	return nil
+}
+
+func (r *EmbeddedConfigRepository) GetSystemConfigurationsUpdatedSince(ctx context.Context, since time.Time) ([]*types.SystemConfiguration, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var configs []*types.SystemConfiguration
+	for _, config := range r.systemConfigs {
+		if config.UpdatedAt.After(since) {
+			copied := *config
+			configs = append(configs, &copied)
+		}
+	}
+	return configs, nil
+}
+
+// decodeInto converts v to out through BSON, as MongoDB would store and read it
+func decodeInto(v any, out any) error {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	if err := bson.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode configuration: %w", err)
+	}
+	return nil
+}
+
+// Card reader management methods
+func (r *EmbeddedConfigRepository) GetCardReaderStatus(ctx context.Context, id string) (*types.CardReaderStatus, error) {
+	tenantID := getTenantIDFromContext(ctx)
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	status := r.findCardReader(tenantID, id)
+	if status == nil {
+		return nil, nil
+	}
+	copied := *status
+	return &copied, nil
+}
+
+// findCardReader returns the card reader with id of tenantID, of any tenant when tenantID is empty;
+// r.mutex must be held
+func (r *EmbeddedConfigRepository) findCardReader(tenantID, id string) *types.CardReaderStatus {
+	if tenantID != "" {
+		return r.cardReaders[tenantID+"|"+id]
+	}
+	for _, status := range r.cardReaders {
+		if status.ID == id {
+			return status
+		}
+	}
+	return nil
+}
+
+func (r *EmbeddedConfigRepository) SetCardReaderStatus(ctx context.Context, status *types.CardReaderStatus) error {
+	if tenantID := getTenantIDFromContext(ctx); tenantID != "" {
+		status.TenantID = tenantID
+	}
+	key := status.TenantID + "|" + status.ID
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	status.CreatedAt = now
+	if existing, exists := r.cardReaders[key]; exists {
+		status.CreatedAt = existing.CreatedAt
+	}
+	status.UpdatedAt = now
+
+	stored := *status
+	r.cardReaders[key] = &stored
+	return nil
+}
+
+func (r *EmbeddedConfigRepository) GetAllCardReaders(ctx context.Context) ([]types.CardReaderStatus, error) {
+	tenantID := getTenantIDFromContext(ctx)
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var readers []types.CardReaderStatus
+	for _, status := range r.cardReaders {
+		if tenantID == "" || status.TenantID == tenantID {
+			readers = append(readers, *status)
+		}
+	}
+	sort.Slice(readers, func(i, j int) bool { return readers[i].ID < readers[j].ID })
+	return readers, nil
+}
+
+func (r *EmbeddedConfigRepository) UpdateCardReaderLastSeen(ctx context.Context, id string) error {
+	tenantID := getTenantIDFromContext(ctx)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if status := r.findCardReader(tenantID, id); status != nil {
+		status.LastSeen = time.Now()
+		status.Status = "online"
+		status.UpdatedAt = status.LastSeen
+	}
+	return nil
+}
+
+func (r *EmbeddedConfigRepository) DeleteCardReader(ctx context.Context, id string) error {
+	tenantID := getTenantIDFromContext(ctx)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if status := r.findCardReader(tenantID, id); status != nil {
+		delete(r.cardReaders, status.TenantID+"|"+status.ID)
+	}
+	return nil
+}
+
+// GetCardReaderConfig retrieves the remote configuration for a card reader device.
+// Devices usually pull their configuration before they know their tenant, so the
+// tenant filter is only applied when the request carries one.
+func (r *EmbeddedConfigRepository) GetCardReaderConfig(ctx context.Context, id string) (*types.CardReaderConfig, error) {
+	tenantID := getTenantIDFromContext(ctx)
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	config, exists := r.cardReaderConfigs[id]
+	if !exists || (tenantID != "" && config.TenantID != tenantID) {
+		return nil, nil
+	}
+	copied := *config
+	return &copied, nil
+}
+
+func (r *EmbeddedConfigRepository) SetCardReaderConfig(ctx context.Context, config *types.CardReaderConfig) error {
+	// Readers without an explicit tenant inherit the tenant of the admin request
+	if config.TenantID == "" {
+		config.TenantID = getTenantIDFromContext(ctx)
+	}
+	config.UpdatedAt = time.Now()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if tenantID := getTenantIDFromContext(ctx); tenantID != "" {
+		if existing := r.cardReaderConfigs[config.DeviceID]; config.TenantID != tenantID || (existing != nil && existing.TenantID != tenantID) {
+			return fmt.Errorf("%w: %s", ErrCardReaderOtherTenant, config.DeviceID)
+		}
+	}
+	stored := *config
+	r.cardReaderConfigs[config.DeviceID] = &stored
+	return nil
+}
+
+func (r *EmbeddedConfigRepository) GetCardReaderConfigsByRoom(ctx context.Context, roomID string) ([]types.CardReaderConfig, error) {
+	tenantID := getTenantIDFromContext(ctx)
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var configs []types.CardReaderConfig
+	for _, config := range r.cardReaderConfigs {
+		if config.TenantID == tenantID && config.RoomID == roomID {
+			configs = append(configs, *config)
+		}
+	}
+	sort.Slice(configs, func(i, j int) bool { return configs[i].DeviceID < configs[j].DeviceID })
+	return configs, nil
+}
+
+// GetCardReaderRelease returns the current card-reader release for an os/arch.
+// Releases are global, the same binary serves every tenant.
+func (r *EmbeddedConfigRepository) GetCardReaderRelease(ctx context.Context, os, arch string) (*types.CardReaderRelease, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	release, exists := r.releases[os+"/"+arch]
+	if !exists {
+		return nil, nil
+	}
+	copied := *release
+	return &copied, nil
+}
+
+func (r *EmbeddedConfigRepository) SetCardReaderRelease(ctx context.Context, release *types.CardReaderRelease) error {
+	release.PublishedAt = time.Now()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored := *release
+	r.releases[release.OS+"/"+release.Arch] = &stored
+	return nil
+}
+
+func (r *EmbeddedConfigRepository) SaveCardReaderCommand(ctx context.Context, command *types.CardReaderCommand) error {
+	if command.TenantID == "" {
+		command.TenantID = getTenantIDFromContext(ctx)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored := *command
+	r.commands[command.ID] = &stored
+	return nil
+}
+
+func (r *EmbeddedConfigRepository) GetCardReaderCommand(ctx context.Context, deviceID, id string) (*types.CardReaderCommand, error) {
+	tenantID := getTenantIDFromContext(ctx)
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	command, exists := r.commands[id]
+	if !exists || command.DeviceID != deviceID || (tenantID != "" && command.TenantID != tenantID) {
+		return nil, nil
+	}
+	copied := *command
+	return &copied, nil
+}
+
+func (r *EmbeddedConfigRepository) GetCardReaderCommands(ctx context.Context, deviceID string, statuses []string, limit int) ([]types.CardReaderCommand, error) {
+	tenantID := getTenantIDFromContext(ctx)
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	commands := []types.CardReaderCommand{}
+	for _, command := range r.commands {
+		if command.DeviceID != deviceID || (tenantID != "" && command.TenantID != tenantID) {
+			continue
+		}
+		if len(statuses) > 0 && !containsStatus(statuses, command.Status) {
+			continue
+		}
+		commands = append(commands, *command)
+	}
+	sort.Slice(commands, func(i, j int) bool { return commands[i].CreatedAt.After(commands[j].CreatedAt) })
+	if limit > 0 && len(commands) > limit {
+		commands = commands[:limit]
+	}
+	return commands, nil
+}
+
+// Tenant management methods
+func (r *EmbeddedConfigRepository) CreateTenant(ctx context.Context, tenant *types.Tenant) error {
+	now := time.Now()
+	tenant.ID = tenant.GetTenantID()
+	tenant.CreatedAt = now
+	tenant.UpdatedAt = now
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Creating an existing tenant updates it and keeps its creation time
+	if existing, exists := r.tenants[tenant.ID]; exists {
+		tenant.CreatedAt = existing.CreatedAt
+	}
+	stored := *tenant
+	r.tenants[tenant.ID] = &stored
+	return nil
+}
+
+func (r *EmbeddedConfigRepository) GetTenant(ctx context.Context, tenantID string) (*types.Tenant, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	tenant, exists := r.tenants[tenantID]
+	if !exists {
+		return nil, nil
+	}
+	copied := *tenant
+	return &copied, nil
+}
+
+func (r *EmbeddedConfigRepository) GetAllTenants(ctx context.Context) ([]types.Tenant, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	tenants := []types.Tenant{}
+	for _, tenant := range r.tenants {
+		tenants = append(tenants, *tenant)
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].ID < tenants[j].ID })
+	return tenants, nil
+}
+
+func (r *EmbeddedConfigRepository) UpdateTenant(ctx context.Context, tenant *types.Tenant) error {
+	tenant.ID = tenant.GetTenantID()
+	tenant.UpdatedAt = time.Now()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, exists := r.tenants[tenant.ID]
+	if !exists {
+		return fmt.Errorf("tenant with ID %s not found", tenant.ID)
+	}
+	tenant.CreatedAt = existing.CreatedAt
+	stored := *tenant
+	r.tenants[tenant.ID] = &stored
+	return nil
+}
+
+func (r *EmbeddedConfigRepository) DeleteTenant(ctx context.Context, tenantID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.tenants[tenantID]; !exists {
+		return fmt.Errorf("tenant with ID %s not found", tenantID)
+	}
+	delete(r.tenants, tenantID)
+	return nil
+}
+
+func (r *EmbeddedConfigRepository) ProvisionTenant(ctx context.Context, tenant *types.Tenant, updates map[string]interface{}) error {
+	// Only the configuration can fail to be saved, creating the tenant afterwards cannot
+	if err := r.UpdateSystemConfiguration(context.WithValue(ctx, middleware.TENANT, tenant.GetFullTenantID()), updates); err != nil {
+		return err
+	}
+	return r.CreateTenant(ctx, tenant)
+}
+
+func (r *EmbeddedConfigRepository) PurgeTenant(ctx context.Context, tenant *types.Tenant) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.tenants[tenant.GetTenantID()]; !exists {
+		return fmt.Errorf("tenant with ID %s not found", tenant.GetTenantID())
+	}
+	ownsReader := func(tenantID string) bool {
+		return tenantID == tenant.GetTenantID() || tenantID == tenant.GetFullTenantID()
+	}
+	delete(r.systemConfigs, tenant.GetFullTenantID())
+	for key, status := range r.cardReaders {
+		if ownsReader(status.TenantID) {
+			delete(r.cardReaders, key)
+		}
+	}
+	for id, config := range r.cardReaderConfigs {
+		if ownsReader(config.TenantID) {
+			delete(r.cardReaderConfigs, id)
+		}
+	}
+	for id, command := range r.commands {
+		if ownsReader(command.TenantID) {
+			delete(r.commands, id)
+		}
+	}
+	delete(r.tenants, tenant.GetTenantID())
+	return nil
+}
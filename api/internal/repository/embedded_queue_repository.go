@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/types"
+	"github.com/google/uuid"
+)
+
+// EmbeddedQueueRepository is the in-memory QueueRepository of the embedded mode, for sites that run
+// the API as a single binary without a database. It writes the queue and the configuration of its
+// EmbeddedConfigRepository to a snapshot file periodically and on Close, and restores them from
+// that file when it is created.
+type EmbeddedQueueRepository struct {
+	*MockQueueRepository
+	config    *EmbeddedConfigRepository
+	path      string
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// embeddedSnapshot is the content of an embedded snapshot file
+type embeddedSnapshot struct {
+	SavedAt    time.Time              `bson:"savedAt"`
+	Counter    int                    `bson:"counter"`
+	Tickets    map[string]int64       `bson:"tickets"`
+	Entries    []*types.Entry         `bson:"entries"`
+	Archive    []*types.Entry         `bson:"archive"`
+	RoomStates []*types.RoomState     `bson:"roomStates"`
+	Config     embeddedConfigSnapshot `bson:"config"`
+}
+
+// NewEmbeddedQueueRepository creates an embedded repository restored from the snapshot file at
+// path, if there is one, and snapshots to it every interval (only on Close when interval is 0)
+func NewEmbeddedQueueRepository(path string, interval time.Duration) (*EmbeddedQueueRepository, error) {
+	r := &EmbeddedQueueRepository{
+		MockQueueRepository: NewMockQueueRepository(),
+		config:              NewEmbeddedConfigRepository(),
+		path:                path,
+		stop:                make(chan struct{}),
+		done:                make(chan struct{}),
+	}
+	if err := r.restore(); err != nil {
+		return nil, err
+	}
+
+	go r.snapshotLoop(interval)
+	return r, nil
+}
+
+// CreateEntry creates a new queue entry with an unguessable QR token and stores a copy of it, so
+// the caller's entry is not shared with the snapshots
+func (r *EmbeddedQueueRepository) CreateEntry(ctx context.Context, entry *types.Entry) error {
+	if entry.QRToken == "" {
+		entry.QRToken = uuid.NewString()
+	}
+	if err := r.MockQueueRepository.CreateEntry(ctx, entry); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	r.entries[entry.ID] = copyEntry(entry)
+	r.mutex.Unlock()
+	return nil
+}
+
+// ConfigRepository returns the configuration repository snapshotted with the queue
+func (r *EmbeddedQueueRepository) ConfigRepository() *EmbeddedConfigRepository {
+	return r.config
+}
+
+// restore loads the snapshot file; a missing file starts an empty repository
+func (r *EmbeddedQueueRepository) restore() error {
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Printf("Embedded: No snapshot at %s, starting with an empty queue", r.path)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot %s: %w", r.path, err)
+	}
+
+	var snapshot embeddedSnapshot
+	if err := fromDocument(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to restore snapshot %s: %w", r.path, err)
+	}
+
+	r.mutex.Lock()
+	r.counter = snapshot.Counter
+	for key, sequence := range snapshot.Tickets {
+		r.tickets[key] = sequence
+	}
+	for _, entry := range snapshot.Entries {
+		r.entries[entry.ID] = entry
+	}
+	for _, entry := range snapshot.Archive {
+		r.archive[entry.ID] = entry
+	}
+	for _, state := range snapshot.RoomStates {
+		r.roomStates[state.ID] = state
+	}
+	r.mutex.Unlock()
+	r.config.restore(snapshot.Config)
+
+	log.Printf("Embedded: Restored %d entries from snapshot %s saved at %s", len(snapshot.Entries), r.path, snapshot.SavedAt.Format(time.RFC3339))
+	return nil
+}
+
+// Snapshot writes the queue and configuration to the snapshot file. The file is replaced in one
+// rename, so a crash while writing leaves the previous snapshot intact.
+func (r *EmbeddedQueueRepository) Snapshot() error {
+	snapshot := embeddedSnapshot{
+		SavedAt: time.Now(),
+		Tickets: make(map[string]int64),
+		Config:  r.config.snapshot(),
+	}
+
+	r.mutex.RLock()
+	snapshot.Counter = r.counter
+	for key, sequence := range r.tickets {
+		snapshot.Tickets[key] = sequence
+	}
+	for _, entry := range r.entries {
+		snapshot.Entries = append(snapshot.Entries, copyEntry(entry))
+	}
+	for _, entry := range r.archive {
+		snapshot.Archive = append(snapshot.Archive, copyEntry(entry))
+	}
+	for _, state := range r.roomStates {
+		copied := *state
+		snapshot.RoomStates = append(snapshot.RoomStates, &copied)
+	}
+	r.mutex.RUnlock()
+
+	document, err := toDocument(snapshot)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(document); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("failed to replace snapshot %s: %w", r.path, err)
+	}
+	return nil
+}
+
+// snapshotLoop snapshots every interval until Close
+func (r *EmbeddedQueueRepository) snapshotLoop(interval time.Duration) {
+	defer close(r.done)
+	if interval <= 0 {
+		<-r.stop
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			if err := r.Snapshot(); err != nil {
+				log.Printf("Embedded: Failed to snapshot the queue: %v", err)
+			}
+		}
+	}
+}
+
+// Close stops the periodic snapshots and writes a last one
+func (r *EmbeddedQueueRepository) Close() error {
+	var err error
+	r.closeOnce.Do(func() {
+		close(r.stop)
+		<-r.done
+		err = r.Snapshot()
+		if err == nil {
+			log.Printf("Embedded: Saved snapshot %s", r.path)
+		}
+	})
+	return err
+}
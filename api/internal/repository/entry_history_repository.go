@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// EntryHistoryRepository stores the append-only status history of queue entries
+type EntryHistoryRepository interface {
+	// AppendEntryHistory stores one status transition
+	AppendEntryHistory(ctx context.Context, event *types.EntryHistoryEvent) error
+
+	// GetEntryHistory returns the transitions of an entry (filtered by tenant), oldest first
+	GetEntryHistory(ctx context.Context, entryId string) ([]*types.EntryHistoryEvent, error)
+
+	// DeleteEntryHistory deletes the transitions of the entries, e.g. for a data subject erasure
+	DeleteEntryHistory(ctx context.Context, entryIds []string) (int64, error)
+}
+
+type MongoDBEntryHistoryRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoDBEntryHistoryRepository(db *mongo.Database) *MongoDBEntryHistoryRepository {
+	collection := db.Collection("entry_history")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	index := mongo.IndexModel{Keys: bson.D{{Key: "entryId", Value: 1}, {Key: "timestamp", Value: 1}}}
+	if _, err := collection.Indexes().CreateOne(ctx, index); err != nil {
+		// Log but don't fail - index might already exist
+		log.Printf("Entry history index creation warning (may already exist): %v", err)
+	}
+
+	return &MongoDBEntryHistoryRepository{
+		collection: collection,
+	}
+}
+
+func (r *MongoDBEntryHistoryRepository) AppendEntryHistory(ctx context.Context, event *types.EntryHistoryEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if _, err := r.collection.InsertOne(ctx, event); err != nil {
+		return fmt.Errorf("failed to append entry history: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoDBEntryHistoryRepository) GetEntryHistory(ctx context.Context, entryId string) ([]*types.EntryHistoryEvent, error) {
+	filter := bson.M{"entryId": entryId}
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	if buildingID != "" {
+		filter["tenantId"] = buildingID
+	}
+	if sectionID != "" {
+		filter["sectionId"] = sectionID
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find entry history: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var events []*types.EntryHistoryEvent
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, fmt.Errorf("failed to decode entry history: %w", err)
+	}
+	return events, nil
+}
+
+func (r *MongoDBEntryHistoryRepository) DeleteEntryHistory(ctx context.Context, entryIds []string) (int64, error) {
+	if len(entryIds) == 0 {
+		return 0, nil
+	}
+	result, err := r.collection.DeleteMany(ctx, bson.M{"entryId": bson.M{"$in": entryIds}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete entry history: %w", err)
+	}
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,76 @@
+-- Documents are stored as MongoDB extended JSON in the same shape as the MongoDB collections;
+-- the columns next to them are what the repositories filter on.
+
+CREATE TABLE queue_entries (
+    id              TEXT PRIMARY KEY,
+    waiting_room_id TEXT        NOT NULL,
+    tenant_id       TEXT        NOT NULL DEFAULT '',
+    section_id      TEXT        NOT NULL DEFAULT '',
+    status          TEXT        NOT NULL,
+    service_point   TEXT        NOT NULL DEFAULT '',
+    qr_token        TEXT        NOT NULL,
+    idempotency_key TEXT        NOT NULL DEFAULT '',
+    version         BIGINT      NOT NULL DEFAULT 0,
+    created_at      TIMESTAMPTZ NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL,
+    document        JSONB       NOT NULL
+);
+
+CREATE UNIQUE INDEX queue_entries_qr_token ON queue_entries (qr_token) WHERE qr_token <> '';
+CREATE INDEX queue_entries_room_status ON queue_entries (waiting_room_id, status);
+CREATE INDEX queue_entries_status_updated ON queue_entries (status, updated_at);
+CREATE INDEX queue_entries_idempotency_key ON queue_entries (idempotency_key) WHERE idempotency_key <> '';
+-- Wait-time analytics
+CREATE INDEX queue_entries_tenant_created ON queue_entries (tenant_id, section_id, created_at);
+
+CREATE TABLE ticket_counters (
+    id         TEXT PRIMARY KEY,
+    room_id    TEXT        NOT NULL,
+    tenant_id  TEXT        NOT NULL,
+    section_id TEXT        NOT NULL,
+    period     TEXT        NOT NULL,
+    sequence   BIGINT      NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE room_states (
+    id         TEXT PRIMARY KEY,
+    tenant_id  TEXT  NOT NULL,
+    section_id TEXT  NOT NULL,
+    room_id    TEXT  NOT NULL,
+    document   JSONB NOT NULL
+);
+
+CREATE INDEX room_states_room ON room_states (room_id, tenant_id, section_id);
+
+CREATE TABLE system_configurations (
+    tenant_id  TEXT  NOT NULL,
+    section_id TEXT  NOT NULL,
+    document   JSONB NOT NULL,
+    PRIMARY KEY (tenant_id, section_id)
+);
+
+CREATE TABLE card_readers (
+    id        TEXT  NOT NULL,
+    tenant_id TEXT  NOT NULL,
+    document  JSONB NOT NULL,
+    PRIMARY KEY (id, tenant_id)
+);
+
+CREATE TABLE card_reader_configs (
+    id        TEXT PRIMARY KEY,
+    tenant_id TEXT  NOT NULL,
+    document  JSONB NOT NULL
+);
+
+CREATE TABLE card_reader_releases (
+    os       TEXT  NOT NULL,
+    arch     TEXT  NOT NULL,
+    document JSONB NOT NULL,
+    PRIMARY KEY (os, arch)
+);
+
+CREATE TABLE tenants (
+    id       TEXT PRIMARY KEY,
+    document JSONB NOT NULL
+);
@@ -0,0 +1,15 @@
+-- Finished entries moved out of queue_entries by the archival job, kept for reports
+
+CREATE TABLE queue_entries_archive (
+    id              TEXT PRIMARY KEY,
+    waiting_room_id TEXT        NOT NULL,
+    tenant_id       TEXT        NOT NULL DEFAULT '',
+    section_id      TEXT        NOT NULL DEFAULT '',
+    status          TEXT        NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL,
+    archived_at     TIMESTAMPTZ NOT NULL,
+    document        JSONB       NOT NULL
+);
+
+CREATE INDEX queue_entries_archive_tenant_created ON queue_entries_archive (tenant_id, section_id, created_at DESC);
+CREATE INDEX queue_entries_archive_room_created ON queue_entries_archive (waiting_room_id, created_at DESC);
@@ -0,0 +1,6 @@
+-- Wrapped per-tenant data keys the personal fields of card data are encrypted with
+
+CREATE TABLE data_keys (
+    tenant_id TEXT PRIMARY KEY,
+    document  JSONB NOT NULL
+);
@@ -0,0 +1,12 @@
+-- Commands sent to card readers over their WebSocket connection and the results they reported
+
+CREATE TABLE card_reader_commands (
+    id         TEXT PRIMARY KEY,
+    device_id  TEXT        NOT NULL,
+    tenant_id  TEXT        NOT NULL DEFAULT '',
+    status     TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    document   JSONB       NOT NULL
+);
+
+CREATE INDEX card_reader_commands_device ON card_reader_commands (device_id, created_at DESC);
@@ -0,0 +1,99 @@
+-- The repositories that were MongoDB only: appointments, entry history, the webhook outbox, the
+-- audit log, translation glossaries and cache, and priority configurations with their revisions
+
+CREATE TABLE appointments (
+    id               TEXT PRIMARY KEY,
+    tenant_id        TEXT        NOT NULL DEFAULT '',
+    section_id       TEXT        NOT NULL DEFAULT '',
+    external_id      TEXT        NOT NULL,
+    identifier       TEXT        NOT NULL DEFAULT '',
+    status           TEXT        NOT NULL,
+    appointment_time TIMESTAMPTZ NOT NULL,
+    document         JSONB       NOT NULL
+);
+
+CREATE UNIQUE INDEX appointments_external_id ON appointments (tenant_id, section_id, external_id);
+CREATE INDEX appointments_tenant_time ON appointments (tenant_id, section_id, appointment_time);
+
+CREATE TABLE entry_history (
+    id         TEXT PRIMARY KEY,
+    entry_id   TEXT        NOT NULL,
+    tenant_id  TEXT        NOT NULL DEFAULT '',
+    section_id TEXT        NOT NULL DEFAULT '',
+    timestamp  TIMESTAMPTZ NOT NULL,
+    document   JSONB       NOT NULL
+);
+
+CREATE INDEX entry_history_entry ON entry_history (entry_id, timestamp);
+
+CREATE TABLE webhook_deliveries (
+    id              TEXT PRIMARY KEY,
+    tenant_id       TEXT        NOT NULL DEFAULT '',
+    section_id      TEXT        NOT NULL DEFAULT '',
+    status          TEXT        NOT NULL,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    locked_until    TIMESTAMPTZ,
+    delivered_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL,
+    document        JSONB       NOT NULL
+);
+
+CREATE INDEX webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX webhook_deliveries_tenant ON webhook_deliveries (tenant_id, status, created_at DESC);
+
+CREATE TABLE audit_log (
+    id         TEXT PRIMARY KEY,
+    tenant_id  TEXT        NOT NULL DEFAULT '',
+    section_id TEXT        NOT NULL DEFAULT '',
+    actor      TEXT        NOT NULL,
+    resource   TEXT        NOT NULL,
+    action     TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    document   JSONB       NOT NULL
+);
+
+CREATE INDEX audit_log_tenant_created ON audit_log (tenant_id, section_id, created_at DESC);
+CREATE INDEX audit_log_tenant_resource ON audit_log (tenant_id, resource, created_at DESC);
+
+CREATE TABLE translation_glossary (
+    id          TEXT PRIMARY KEY,
+    tenant_id   TEXT  NOT NULL DEFAULT '',
+    section_id  TEXT  NOT NULL DEFAULT '',
+    source_lang TEXT  NOT NULL,
+    target_lang TEXT  NOT NULL,
+    source_text TEXT  NOT NULL,
+    document    JSONB NOT NULL
+);
+
+CREATE UNIQUE INDEX translation_glossary_text ON translation_glossary (tenant_id, section_id, source_lang, target_lang, source_text);
+
+-- Expired translations are ignored on read and deleted by SaveTranslation
+CREATE TABLE translation_cache (
+    tenant_id   TEXT        NOT NULL,
+    section_id  TEXT        NOT NULL,
+    source_lang TEXT        NOT NULL,
+    target_lang TEXT        NOT NULL,
+    text_hash   TEXT        NOT NULL,
+    cached_at   TIMESTAMPTZ NOT NULL,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    document    JSONB       NOT NULL,
+    PRIMARY KEY (tenant_id, section_id, source_lang, target_lang, text_hash)
+);
+
+CREATE INDEX translation_cache_cached ON translation_cache (cached_at DESC);
+CREATE INDEX translation_cache_expires ON translation_cache (expires_at);
+
+CREATE TABLE priority_configs (
+    tenant_id  TEXT  NOT NULL,
+    section_id TEXT  NOT NULL,
+    document   JSONB NOT NULL,
+    PRIMARY KEY (tenant_id, section_id)
+);
+
+CREATE TABLE priority_config_revisions (
+    tenant_id  TEXT    NOT NULL,
+    section_id TEXT    NOT NULL,
+    revision   INTEGER NOT NULL,
+    document   JSONB   NOT NULL,
+    PRIMARY KEY (tenant_id, section_id, revision)
+);
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// MockAppointmentRepository implements AppointmentRepository using in-memory storage
+type MockAppointmentRepository struct {
+	appointments map[string]*types.Appointment
+	mutex        sync.RWMutex
+}
+
+// NewMockAppointmentRepository creates a new mock appointment repository
+func NewMockAppointmentRepository() *MockAppointmentRepository {
+	return &MockAppointmentRepository{
+		appointments: make(map[string]*types.Appointment),
+	}
+}
+
+// UpsertAppointment creates or updates an appointment by its external ID; check-in state is kept
+func (r *MockAppointmentRepository) UpsertAppointment(ctx context.Context, appointment *types.Appointment) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	now := time.Now()
+	for _, existing := range r.appointments {
+		if existing.TenantID == buildingID && existing.SectionID == sectionID && existing.ExternalID == appointment.ExternalID {
+			appointment.ID = existing.ID
+			appointment.TenantID = buildingID
+			appointment.SectionID = sectionID
+			appointment.EntryID = existing.EntryID
+			appointment.CreatedAt = existing.CreatedAt
+			appointment.Status = upsertedAppointmentStatus(appointment.Status, existing.Status)
+			appointment.UpdatedAt = now
+			stored := *appointment
+			r.appointments[stored.ID] = &stored
+			return nil
+		}
+	}
+
+	appointment.ID = uuid.New().String()
+	appointment.TenantID = buildingID
+	appointment.SectionID = sectionID
+	appointment.EntryID = ""
+	appointment.Status = upsertedAppointmentStatus(appointment.Status, "")
+	appointment.CreatedAt = now
+	appointment.UpdatedAt = now
+	stored := *appointment
+	r.appointments[stored.ID] = &stored
+	return nil
+}
+
+// GetAppointments returns the appointments scheduled between from and to, ordered by time
+func (r *MockAppointmentRepository) GetAppointments(ctx context.Context, from, to time.Time) ([]*types.Appointment, error) {
+	return r.filter(ctx, func(appointment *types.Appointment) bool {
+		return !appointment.AppointmentTime.Before(from) && appointment.AppointmentTime.Before(to)
+	}), nil
+}
+
+// GetScheduledAppointments returns the SCHEDULED appointments of a patient between from and to
+func (r *MockAppointmentRepository) GetScheduledAppointments(ctx context.Context, identifier string, from, to time.Time) ([]*types.Appointment, error) {
+	return r.filter(ctx, func(appointment *types.Appointment) bool {
+		return appointment.Identifier == identifier && appointment.Status == "SCHEDULED" &&
+			!appointment.AppointmentTime.Before(from) && !appointment.AppointmentTime.After(to)
+	}), nil
+}
+
+// GetAppointmentByExternalID returns the appointment with an external ID, nil when there is none
+func (r *MockAppointmentRepository) GetAppointmentByExternalID(ctx context.Context, externalId string) (*types.Appointment, error) {
+	appointments := r.filter(ctx, func(appointment *types.Appointment) bool {
+		return appointment.ExternalID == externalId
+	})
+	if len(appointments) == 0 {
+		return nil, nil
+	}
+	return appointments[0], nil
+}
+
+// filter returns copies of the tenant's appointments matching fn, ordered by time
+func (r *MockAppointmentRepository) filter(ctx context.Context, fn func(*types.Appointment) bool) []*types.Appointment {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	buildingID, sectionID, err := types.ParseTenantID(getTenantIDFromContext(ctx))
+	var result []*types.Appointment
+	for _, appointment := range r.appointments {
+		if err == nil && (appointment.TenantID != buildingID || (sectionID != "" && appointment.SectionID != sectionID)) {
+			continue
+		}
+		if fn(appointment) {
+			copied := *appointment
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].AppointmentTime.Before(result[j].AppointmentTime) })
+	return result
+}
+
+// MarkAppointmentCheckedIn links an appointment to the queue entry created for it
+func (r *MockAppointmentRepository) MarkAppointmentCheckedIn(ctx context.Context, id, entryId string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	appointment, exists := r.appointments[id]
+	if !exists {
+		return fmt.Errorf("appointment not found")
+	}
+	appointment.Status = "CHECKED_IN"
+	appointment.EntryID = entryId
+	appointment.UpdatedAt = time.Now()
+	return nil
+}
+
+// DeleteTenantAppointments deletes all appointments of the tenant and returns their number
+func (r *MockAppointmentRepository) DeleteTenantAppointments(ctx context.Context) (int64, error) {
+	buildingID, sectionID, err := types.ParseTenantID(getTenantIDFromContext(ctx))
+	if err != nil || buildingID == "" {
+		return 0, fmt.Errorf("a tenant is required to delete its appointments")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var deleted int64
+	for id, appointment := range r.appointments {
+		if appointment.TenantID == buildingID && (sectionID == "" || appointment.SectionID == sectionID) {
+			delete(r.appointments, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
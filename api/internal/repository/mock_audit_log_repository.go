@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// MockAuditLogRepository implements AuditLogRepository using in-memory storage
+type MockAuditLogRepository struct {
+	entries []*types.AuditEntry
+	mutex   sync.RWMutex
+}
+
+// NewMockAuditLogRepository creates a new mock audit log repository
+func NewMockAuditLogRepository() *MockAuditLogRepository {
+	return &MockAuditLogRepository{}
+}
+
+// AppendAuditEntry stores a new entry
+func (r *MockAuditLogRepository) AppendAuditEntry(ctx context.Context, entry *types.AuditEntry) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored := *entry
+	r.entries = append(r.entries, &stored)
+	return nil
+}
+
+// GetAuditEntries returns up to limit entries of the tenant in ctx matching the filter, newest first
+func (r *MockAuditLogRepository) GetAuditEntries(ctx context.Context, filter types.AuditFilter, limit int) ([]*types.AuditEntry, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	var entries []*types.AuditEntry
+	for _, entry := range r.entries {
+		if (buildingID != "" && entry.TenantID != buildingID) || (sectionID != "" && entry.SectionID != sectionID) {
+			continue
+		}
+		if (filter.Resource != "" && entry.Resource != filter.Resource) ||
+			(filter.Action != "" && entry.Action != filter.Action) ||
+			(filter.Actor != "" && entry.Actor != filter.Actor) {
+			continue
+		}
+		if (!filter.From.IsZero() && entry.CreatedAt.Before(filter.From)) || (!filter.To.IsZero() && !entry.CreatedAt.Before(filter.To)) {
+			continue
+		}
+		copied := *entry
+		entries = append(entries, &copied)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
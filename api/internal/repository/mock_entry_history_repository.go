@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// MockEntryHistoryRepository implements EntryHistoryRepository using in-memory storage
+type MockEntryHistoryRepository struct {
+	events []*types.EntryHistoryEvent
+	mutex  sync.RWMutex
+}
+
+// NewMockEntryHistoryRepository creates a new mock entry history repository
+func NewMockEntryHistoryRepository() *MockEntryHistoryRepository {
+	return &MockEntryHistoryRepository{}
+}
+
+// AppendEntryHistory stores one status transition
+func (r *MockEntryHistoryRepository) AppendEntryHistory(ctx context.Context, event *types.EntryHistoryEvent) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	stored := *event
+	r.events = append(r.events, &stored)
+	return nil
+}
+
+// GetEntryHistory returns the transitions of an entry (filtered by tenant), oldest first
+func (r *MockEntryHistoryRepository) GetEntryHistory(ctx context.Context, entryId string) ([]*types.EntryHistoryEvent, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	var events []*types.EntryHistoryEvent
+	for _, event := range r.events {
+		if event.EntryID != entryId {
+			continue
+		}
+		if (buildingID != "" && event.TenantID != buildingID) || (sectionID != "" && event.SectionID != sectionID) {
+			continue
+		}
+		stored := *event
+		events = append(events, &stored)
+	}
+	return events, nil
+}
+
+// DeleteEntryHistory deletes the transitions of the entries
+func (r *MockEntryHistoryRepository) DeleteEntryHistory(ctx context.Context, entryIds []string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	deleted := make(map[string]bool, len(entryIds))
+	for _, id := range entryIds {
+		deleted[id] = true
+	}
+	var kept []*types.EntryHistoryEvent
+	for _, event := range r.events {
+		if !deleted[event.EntryID] {
+			kept = append(kept, event)
+		}
+	}
+	count := int64(len(r.events) - len(kept))
+	r.events = kept
+	return count, nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,19 +14,31 @@ import (
 
 // MockQueueRepository implements QueueRepository using in-memory storage
 type MockQueueRepository struct {
-	entries map[string]*types.Entry
-	mutex   sync.RWMutex
-	counter int
+	entries          map[string]*types.Entry
+	archive          map[string]*types.Entry
+	roomStates       map[string]*types.RoomState
+	tickets          map[string]int64
+	mutex            sync.RWMutex
+	counter          int
+	orderingResolver QueueOrderingResolver
 }
 
 // NewMockQueueRepository creates a new mock queue repository
 func NewMockQueueRepository() *MockQueueRepository {
 	return &MockQueueRepository{
-		entries: make(map[string]*types.Entry),
-		counter: 0,
+		entries:    make(map[string]*types.Entry),
+		archive:    make(map[string]*types.Entry),
+		roomStates: make(map[string]*types.RoomState),
+		tickets:    make(map[string]int64),
+		counter:    0,
 	}
 }
 
+// SetOrderingResolver sets where the ordering strategy of a room comes from (the priority order without one)
+func (r *MockQueueRepository) SetOrderingResolver(resolver QueueOrderingResolver) {
+	r.orderingResolver = resolver
+}
+
 // CreateEntry creates a new queue entry
 func (r *MockQueueRepository) CreateEntry(ctx context.Context, entry *types.Entry) error {
 	r.mutex.Lock()
@@ -36,10 +50,14 @@ func (r *MockQueueRepository) CreateEntry(ctx context.Context, entry *types.Entr
 	entry.UpdatedAt = time.Now()
 
 	// Generate ticket number
-	entry.TicketNumber = fmt.Sprintf("A-%03d", r.counter)
+	if entry.TicketNumber == "" {
+		entry.TicketNumber = fmt.Sprintf("A-%03d", r.counter)
+	}
 
-	// Generate QR token
-	entry.QRToken = fmt.Sprintf("qr-token-%d", r.counter)
+	// Generate QR token if not set
+	if entry.QRToken == "" {
+		entry.QRToken = fmt.Sprintf("qr-token-%d", r.counter)
+	}
 
 	r.entries[entry.ID] = entry
 	log.Printf("Mock: Created queue entry %s with ticket %s", entry.ID, entry.TicketNumber)
@@ -47,8 +65,19 @@ func (r *MockQueueRepository) CreateEntry(ctx context.Context, entry *types.Entr
 	return nil
 }
 
+// NextTicketSequence increments and returns the ticket counter of a room, tenant and section in a period
+func (r *MockQueueRepository) NextTicketSequence(ctx context.Context, roomId, tenantId, sectionId, period string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := tenantId + ":" + sectionId + ":" + roomId + ":" + period
+	r.tickets[key]++
+	return r.tickets[key], nil
+}
+
 // GetQueueEntries retrieves all queue entries for a room
 func (r *MockQueueRepository) GetQueueEntries(ctx context.Context, roomId string, states []string) ([]*types.Entry, error) {
+	ordering := resolveOrdering(ctx, r.orderingResolver, roomId)
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
@@ -57,12 +86,12 @@ func (r *MockQueueRepository) GetQueueEntries(ctx context.Context, roomId string
 		if entry.WaitingRoomID == roomId {
 			// If no states specified, include all entries
 			if len(states) == 0 {
-				entries = append(entries, entry)
+				entries = append(entries, copyEntry(entry))
 			} else {
 				// Check if entry status is in the states array
 				for _, state := range states {
 					if entry.Status == state {
-						entries = append(entries, entry)
+						entries = append(entries, copyEntry(entry))
 						break
 					}
 				}
@@ -70,14 +99,8 @@ func (r *MockQueueRepository) GetQueueEntries(ctx context.Context, roomId string
 		}
 	}
 
-	// Sort by position
-	for i := 0; i < len(entries)-1; i++ {
-		for j := i + 1; j < len(entries); j++ {
-			if entries[i].Position > entries[j].Position {
-				entries[i], entries[j] = entries[j], entries[i]
-			}
-		}
-	}
+	// Sort the same way as the MongoDB repository
+	sort.SliceStable(entries, func(i, j int) bool { return ordering.Before(entries[i], entries[j]) })
 
 	return entries, nil
 }
@@ -92,7 +115,7 @@ func (r *MockQueueRepository) GetEntryByID(ctx context.Context, id string) (*typ
 		return nil, fmt.Errorf("queue entry not found")
 	}
 
-	return entry, nil
+	return copyEntry(entry), nil
 }
 
 // GetEntryByQRToken retrieves a queue entry by QR token
@@ -102,32 +125,44 @@ func (r *MockQueueRepository) GetEntryByQRToken(ctx context.Context, qrToken str
 
 	for _, entry := range r.entries {
 		if entry.QRToken == qrToken {
-			return entry, nil
+			return copyEntry(entry), nil
 		}
 	}
 
 	return nil, fmt.Errorf("queue entry not found")
 }
 
-// UpdateEntryStatus updates the status of a queue entry
-func (r *MockQueueRepository) UpdateEntryStatus(ctx context.Context, id string, status string) error {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
+// GetActiveEntryByIdempotencyKey gets the oldest active entry created with one of the keys, or nil
+func (r *MockQueueRepository) GetActiveEntryByIdempotencyKey(ctx context.Context, keys []string) (*types.Entry, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
 
-	entry, exists := r.entries[id]
-	if !exists {
-		return fmt.Errorf("queue entry not found")
+	var found *types.Entry
+	for _, entry := range r.entries {
+		if entry.IdempotencyKey == "" || (entry.Status != "WAITING" && entry.Status != "CALLED" && entry.Status != "IN_SERVICE") {
+			continue
+		}
+		for _, key := range keys {
+			if entry.IdempotencyKey == key && (found == nil || entry.CreatedAt.Before(found.CreatedAt)) {
+				found = entry
+			}
+		}
 	}
+	return copyEntry(found), nil
+}
 
-	entry.Status = status
-	entry.UpdatedAt = time.Now()
+// UpdateEntryStatus updates the status of a queue entry
+func (r *MockQueueRepository) UpdateEntryStatus(ctx context.Context, id string, status string, version int64) error {
+	return r.updateEntryStatus(id, status, nil, version)
+}
 
-	log.Printf("Mock: Updated entry %s status to %s", id, status)
-	return nil
+// UpdateEntryStatusAndServicePoint updates the status and service point of a queue entry
+func (r *MockQueueRepository) UpdateEntryStatusAndServicePoint(ctx context.Context, id string, status string, servicePoint string, version int64) error {
+	return r.updateEntryStatus(id, status, &servicePoint, version)
 }
 
-// UpdateEntryPosition updates the position of a queue entry
-func (r *MockQueueRepository) UpdateEntryPosition(ctx context.Context, id string, position int) error {
+// updateEntryStatus sets status, and servicePoint when given, on the entry still at version
+func (r *MockQueueRepository) updateEntryStatus(id string, status string, servicePoint *string, version int64) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
@@ -135,15 +170,30 @@ func (r *MockQueueRepository) UpdateEntryPosition(ctx context.Context, id string
 	if !exists {
 		return fmt.Errorf("queue entry not found")
 	}
+	if entry.Version != version {
+		return fmt.Errorf("%w: expected version %d, entry is at %d", ErrVersionConflict, version, entry.Version)
+	}
 
-	entry.Position = int64(position)
-	entry.UpdatedAt = time.Now()
+	now := time.Now()
+	entry.Status = status
+	if servicePoint != nil {
+		entry.ServicePoint = *servicePoint
+	}
+	entry.Version++
+	entry.UpdatedAt = now
+	switch status {
+	case "CALLED":
+		entry.CalledAt = &now
+	case "COMPLETED":
+		entry.CompletedAt = &now
+	}
 
+	log.Printf("Mock: Updated entry %s status to %s", id, status)
 	return nil
 }
 
-// UpdateEntryServicePoint updates the service point of a queue entry
-func (r *MockQueueRepository) UpdateEntryServicePoint(ctx context.Context, id string, servicePoint string) error {
+// UpdateEntryPosition updates the position of a queue entry
+func (r *MockQueueRepository) UpdateEntryPosition(ctx context.Context, id string, position int, version int64) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
@@ -151,26 +201,27 @@ func (r *MockQueueRepository) UpdateEntryServicePoint(ctx context.Context, id st
 	if !exists {
 		return fmt.Errorf("queue entry not found")
 	}
+	if entry.Version != version {
+		return fmt.Errorf("%w: expected version %d, entry is at %d", ErrVersionConflict, version, entry.Version)
+	}
 
-	entry.ServicePoint = servicePoint
+	entry.Position = int64(position)
+	entry.Version++
 	entry.UpdatedAt = time.Now()
 
-	log.Printf("Mock: Updated entry %s service point to %s", id, servicePoint)
 	return nil
 }
 
 // GetNextWaitingEntry gets the next waiting entry for a room
 func (r *MockQueueRepository) GetNextWaitingEntry(ctx context.Context, roomId string) (*types.Entry, error) {
+	ordering := resolveOrdering(ctx, r.orderingResolver, roomId)
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
 	var nextEntry *types.Entry
-	minPosition := int(^uint(0) >> 1) // Max int
-
 	for _, entry := range r.entries {
 		if entry.WaitingRoomID == roomId && entry.Status == "WAITING" {
-			if entry.Position < int64(minPosition) {
-				minPosition = int(entry.Position)
+			if nextEntry == nil || ordering.Before(entry, nextEntry) {
 				nextEntry = entry
 			}
 		}
@@ -180,7 +231,7 @@ func (r *MockQueueRepository) GetNextWaitingEntry(ctx context.Context, roomId st
 		return nil, fmt.Errorf("no waiting entries found")
 	}
 
-	return nextEntry, nil
+	return copyEntry(nextEntry), nil
 }
 
 // GetCurrentServedEntry gets the currently served entry for a room
@@ -190,7 +241,7 @@ func (r *MockQueueRepository) GetCurrentServedEntry(ctx context.Context, roomId
 
 	for _, entry := range r.entries {
 		if entry.WaitingRoomID == roomId && (entry.Status == "CALLED" || entry.Status == "IN_SERVICE") {
-			return entry, nil
+			return copyEntry(entry), nil
 		}
 	}
 
@@ -199,6 +250,7 @@ func (r *MockQueueRepository) GetCurrentServedEntry(ctx context.Context, roomId
 
 // RecalculatePositions recalculates positions for all waiting entries in a room
 func (r *MockQueueRepository) RecalculatePositions(ctx context.Context, roomId string) error {
+	ordering := resolveOrdering(ctx, r.orderingResolver, roomId)
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
@@ -209,18 +261,16 @@ func (r *MockQueueRepository) RecalculatePositions(ctx context.Context, roomId s
 		}
 	}
 
-	// Sort by creation time
-	for i := 0; i < len(waitingEntries)-1; i++ {
-		for j := i + 1; j < len(waitingEntries); j++ {
-			if waitingEntries[i].CreatedAt.After(waitingEntries[j].CreatedAt) {
-				waitingEntries[i], waitingEntries[j] = waitingEntries[j], waitingEntries[i]
-			}
-		}
-	}
+	// Sort the same way as the MongoDB repository
+	sort.SliceStable(waitingEntries, func(i, j int) bool { return ordering.Before(waitingEntries[i], waitingEntries[j]) })
 
 	// Update positions
 	for i, entry := range waitingEntries {
+		if entry.Position == int64(i+1) {
+			continue
+		}
 		entry.Position = int64(i + 1)
+		entry.Version++
 		entry.UpdatedAt = time.Now()
 	}
 
@@ -228,6 +278,345 @@ func (r *MockQueueRepository) RecalculatePositions(ctx context.Context, roomId s
 	return nil
 }
 
+// GetCalledEntriesBefore gets the CALLED entries of all tenants last updated before the given time
+func (r *MockQueueRepository) GetCalledEntriesBefore(ctx context.Context, before time.Time) ([]*types.Entry, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var entries []*types.Entry
+	for _, entry := range r.entries {
+		if entry.Status == "CALLED" && entry.UpdatedAt.Before(before) {
+			entries = append(entries, copyEntry(entry))
+		}
+	}
+
+	return entries, nil
+}
+
+// GetEntriesUpdatedSince gets the queue entries of all tenants last updated at or after the given time
+func (r *MockQueueRepository) GetEntriesUpdatedSince(ctx context.Context, since time.Time) ([]*types.Entry, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var entries []*types.Entry
+	for _, entry := range r.entries {
+		if !entry.UpdatedAt.Before(since) {
+			entries = append(entries, copyEntry(entry))
+		}
+	}
+
+	return entries, nil
+}
+
+// GetWaitingEntries gets the WAITING entries of all rooms and tenants
+func (r *MockQueueRepository) GetWaitingEntries(ctx context.Context) ([]*types.Entry, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var entries []*types.Entry
+	for _, entry := range r.entries {
+		if entry.Status == "WAITING" {
+			entries = append(entries, copyEntry(entry))
+		}
+	}
+
+	return entries, nil
+}
+
+// UpdateEntryFitnessScore updates the fitness score of a queue entry and its breakdown
+func (r *MockQueueRepository) UpdateEntryFitnessScore(ctx context.Context, id string, fitnessScore float64, breakdown *types.FitnessBreakdown) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, exists := r.entries[id]
+	if !exists {
+		return fmt.Errorf("queue entry not found")
+	}
+
+	entry.FitnessScore = fitnessScore
+	entry.FitnessBreakdown = breakdown
+
+	log.Printf("Mock: Updated entry %s fitness score to %.2f", id, fitnessScore)
+	return nil
+}
+
+// EscalateEntryTier moves a queue entry to a higher priority tier and marks it as escalated
+func (r *MockQueueRepository) EscalateEntryTier(ctx context.Context, id string, tier int) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, exists := r.entries[id]
+	if !exists {
+		return fmt.Errorf("queue entry not found")
+	}
+
+	entry.Tier = tier
+	entry.TierEscalated = true
+	entry.UpdatedAt = time.Now()
+
+	log.Printf("Mock: Escalated entry %s to tier %d", id, tier)
+	return nil
+}
+
+// UpdateEntryManualOverride sets the manual priority override of a queue entry with the fitness score it results in
+func (r *MockQueueRepository) UpdateEntryManualOverride(ctx context.Context, id string, manualOverride float64, fitnessScore float64, breakdown *types.FitnessBreakdown) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, exists := r.entries[id]
+	if !exists {
+		return fmt.Errorf("queue entry not found")
+	}
+
+	entry.ManualOverride = &manualOverride
+	entry.FitnessScore = fitnessScore
+	entry.FitnessBreakdown = breakdown
+	entry.UpdatedAt = time.Now()
+
+	log.Printf("Mock: Updated entry %s manual override to %.2f (fitness score %.2f)", id, manualOverride, fitnessScore)
+	return nil
+}
+
+// UpdateEntryAnnotations replaces the staff notes and tags of a queue entry
+func (r *MockQueueRepository) UpdateEntryAnnotations(ctx context.Context, id string, notes string, tags []string, annotatedBy string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, exists := r.entries[id]
+	if !exists {
+		return fmt.Errorf("queue entry not found")
+	}
+
+	now := time.Now()
+	entry.Notes = notes
+	entry.Tags = tags
+	entry.AnnotatedBy = annotatedBy
+	entry.AnnotatedAt = &now
+	entry.UpdatedAt = now
+	return nil
+}
+
+// UpdateEntryContact replaces the notification phone number and email of a queue entry; empty clears them
+func (r *MockQueueRepository) UpdateEntryContact(ctx context.Context, id string, phone string, email string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, exists := r.entries[id]
+	if !exists {
+		return fmt.Errorf("queue entry not found")
+	}
+	entry.NotifyPhone = phone
+	entry.NotifyEmail = email
+	entry.UpdatedAt = time.Now()
+	return nil
+}
+
+// UpdateEntryService replaces the service name and approximate duration (seconds) of a queue entry
+func (r *MockQueueRepository) UpdateEntryService(ctx context.Context, id string, serviceName string, durationSeconds int64) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, exists := r.entries[id]
+	if !exists {
+		return fmt.Errorf("queue entry not found")
+	}
+	entry.ServiceName = serviceName
+	entry.ApproximateDurationSeconds = durationSeconds
+	entry.UpdatedAt = time.Now()
+	return nil
+}
+
+// MarkEntryApproachNotified records that the patient of a queue entry was told their turn is near;
+// false when it was recorded already
+func (r *MockQueueRepository) MarkEntryApproachNotified(ctx context.Context, id string) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, exists := r.entries[id]
+	if !exists {
+		return false, fmt.Errorf("queue entry not found")
+	}
+	if entry.ApproachNotifiedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	entry.ApproachNotifiedAt = &now
+	return true, nil
+}
+
+// UpdateEntryPushSubscription replaces the Web Push subscription of a queue entry; nil clears it
+func (r *MockQueueRepository) UpdateEntryPushSubscription(ctx context.Context, id string, subscription *types.PushSubscription) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, exists := r.entries[id]
+	if !exists {
+		return fmt.Errorf("queue entry not found")
+	}
+	entry.PushSubscription = subscription
+	entry.UpdatedAt = time.Now()
+	return nil
+}
+
+// MarkEntryPushMilestone records that a queue entry got the push of a people-ahead milestone;
+// false when it got the one of this or a smaller milestone already
+func (r *MockQueueRepository) MarkEntryPushMilestone(ctx context.Context, id string, milestone int) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, exists := r.entries[id]
+	if !exists {
+		return false, fmt.Errorf("queue entry not found")
+	}
+	if entry.PushMilestone != nil && *entry.PushMilestone <= milestone {
+		return false, nil
+	}
+	entry.PushMilestone = &milestone
+	return true, nil
+}
+
+// TransferEntry moves an entry to another room and service point (empty = any) and puts it back into WAITING
+func (r *MockQueueRepository) TransferEntry(ctx context.Context, id string, roomId string, servicePoint string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, exists := r.entries[id]
+	if !exists {
+		return fmt.Errorf("queue entry not found")
+	}
+
+	entry.WaitingRoomID = roomId
+	entry.ServicePoint = servicePoint
+	entry.Status = "WAITING"
+	entry.Version++
+	entry.UpdatedAt = time.Now()
+
+	log.Printf("Mock: Transferred entry %s to room %s (service point: '%s')", id, roomId, servicePoint)
+	return nil
+}
+
+// MoveEntryToPathwayStage sets the pathway stage of an entry and lets it wait in the stage's room and service point (empty = any)
+func (r *MockQueueRepository) MoveEntryToPathwayStage(ctx context.Context, id string, pathwayId string, stage int, roomId string, servicePoint string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, exists := r.entries[id]
+	if !exists {
+		return fmt.Errorf("queue entry not found")
+	}
+
+	entry.PathwayID = pathwayId
+	entry.PathwayStage = stage
+	entry.WaitingRoomID = roomId
+	entry.ServicePoint = servicePoint
+	entry.Status = "WAITING"
+	entry.Version++
+	entry.UpdatedAt = time.Now()
+
+	log.Printf("Mock: Moved entry %s to stage %d of pathway %s (room %s)", id, stage, pathwayId, roomId)
+	return nil
+}
+
+// BulkUpdateStatus sets every entry of a room in one of fromStatuses to toStatus and recalculates the room's positions
+func (r *MockQueueRepository) BulkUpdateStatus(ctx context.Context, roomId string, fromStatuses []string, toStatus string) (int64, error) {
+	r.mutex.Lock()
+	var changed int64
+	for _, entry := range r.entries {
+		if entry.WaitingRoomID != roomId {
+			continue
+		}
+		for _, status := range fromStatuses {
+			if entry.Status == status {
+				entry.Status = toStatus
+				entry.Version++
+				entry.UpdatedAt = time.Now()
+				changed++
+				break
+			}
+		}
+	}
+	r.mutex.Unlock()
+
+	log.Printf("Mock: Set %d entries in room %s to %s", changed, roomId, toStatus)
+	return changed, r.RecalculatePositions(ctx, roomId)
+}
+
+// MoveWaitingEntries moves every WAITING entry of a room to targetRoomId and recalculates both rooms' positions
+func (r *MockQueueRepository) MoveWaitingEntries(ctx context.Context, roomId string, targetRoomId string) (int64, error) {
+	r.mutex.Lock()
+	var moved int64
+	for _, entry := range r.entries {
+		if entry.WaitingRoomID == roomId && entry.Status == "WAITING" {
+			entry.WaitingRoomID = targetRoomId
+			entry.ServicePoint = ""
+			entry.Version++
+			entry.UpdatedAt = time.Now()
+			moved++
+		}
+	}
+	r.mutex.Unlock()
+
+	log.Printf("Mock: Moved %d waiting entries from room %s to %s", moved, roomId, targetRoomId)
+	if err := r.RecalculatePositions(ctx, targetRoomId); err != nil {
+		return moved, err
+	}
+	return moved, r.RecalculatePositions(ctx, roomId)
+}
+
+// GetRoomStates gets the stored states of a room and its service points
+func (r *MockQueueRepository) GetRoomStates(ctx context.Context, roomId string) ([]*types.RoomState, error) {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var states []*types.RoomState
+	for _, state := range r.roomStates {
+		if state.RoomID == roomId && state.TenantID == buildingID && state.SectionID == sectionID {
+			copied := *state
+			states = append(states, &copied)
+		}
+	}
+	return states, nil
+}
+
+// SetRoomState creates or replaces the state of a room or service point within the tenant in the context
+func (r *MockQueueRepository) SetRoomState(ctx context.Context, state *types.RoomState) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	state.TenantID, state.SectionID, _ = types.ParseTenantID(getTenantIDFromContext(ctx))
+	state.ID = state.RoomID + ":" + state.ServicePointID
+	state.UpdatedAt = time.Now()
+	stored := *state
+	r.roomStates[state.TenantID+":"+state.SectionID+":"+state.ID] = &stored
+	return nil
+}
+
+// RequeueEntry puts an entry back into WAITING with the given priority tier and no service point
+func (r *MockQueueRepository) RequeueEntry(ctx context.Context, id string, tier int) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, exists := r.entries[id]
+	if !exists {
+		return fmt.Errorf("queue entry not found")
+	}
+
+	entry.Status = "WAITING"
+	entry.Tier = tier
+	entry.ServicePoint = ""
+	entry.Version++
+	entry.UpdatedAt = time.Now()
+
+	log.Printf("Mock: Requeued entry %s at tier %d", id, tier)
+	return nil
+}
+
+// WithTransaction runs fn; the mock does not roll back
+func (r *MockQueueRepository) WithTransaction(ctx context.Context, fn func(txCtx context.Context) error) error {
+	return fn(ctx)
+}
+
 // DeleteEntry deletes a queue entry
 func (r *MockQueueRepository) DeleteEntry(ctx context.Context, id string) error {
 	r.mutex.Lock()
@@ -245,15 +634,19 @@ func (r *MockQueueRepository) DeleteEntry(ctx context.Context, id string) error
 
 // GetNextWaitingEntryForServicePoint gets the next waiting entry for a specific service point
 func (r *MockQueueRepository) GetNextWaitingEntryForServicePoint(ctx context.Context, roomId, servicePointId string) (*types.Entry, error) {
+	ordering := resolveOrdering(ctx, r.orderingResolver, roomId)
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
+	var nextEntry *types.Entry
 	for _, entry := range r.entries {
 		if entry.WaitingRoomID == roomId && entry.ServicePoint == servicePointId && entry.Status == "WAITING" {
-			return entry, nil
+			if nextEntry == nil || ordering.Before(entry, nextEntry) {
+				nextEntry = entry
+			}
 		}
 	}
-	return nil, nil
+	return copyEntry(nextEntry), nil
 }
 
 // GetCurrentServedEntryForServicePoint gets the currently served entry for a specific service point
@@ -264,12 +657,274 @@ func (r *MockQueueRepository) GetCurrentServedEntryForServicePoint(ctx context.C
 	for _, entry := range r.entries {
 		if entry.WaitingRoomID == roomId && entry.ServicePoint == servicePointId &&
 			(entry.Status == "CALLED" || entry.Status == "IN_ROOM" || entry.Status == "IN_SERVICE") {
-			return entry, nil
+			return copyEntry(entry), nil
 		}
 	}
 	return nil, nil
 }
 
+// GetEntryStats groups the COMPLETED and NO_SHOW entries created in [from, to) by day in loc, room and service point
+func (r *MockQueueRepository) GetEntryStats(ctx context.Context, from, to time.Time, roomId string, loc *time.Location) ([]*types.EntryStats, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	var entries []*types.Entry
+	for _, entry := range append(mapValues(r.entries), mapValues(r.archive)...) {
+		if entry.Status != "COMPLETED" && entry.Status != "NO_SHOW" {
+			continue
+		}
+		if entry.CreatedAt.Before(from) || !entry.CreatedAt.Before(to) || (roomId != "" && entry.WaitingRoomID != roomId) {
+			continue
+		}
+		if (buildingID != "" && entry.TenantID != buildingID) || (sectionID != "" && entry.SectionID != sectionID) {
+			continue
+		}
+		entries = append(entries, copyEntry(entry))
+	}
+	return groupEntryStats(entries, loc), nil
+}
+
+// ArchiveEntries moves the entries in one of statuses last updated before the given time to the archive
+func (r *MockQueueRepository) ArchiveEntries(ctx context.Context, before time.Time, statuses []string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	var archived int64
+	for id, entry := range r.entries {
+		if !entry.UpdatedAt.Before(before) || !containsStatus(statuses, entry.Status) {
+			continue
+		}
+		entry.ArchivedAt = &now
+		r.archive[id] = entry
+		delete(r.entries, id)
+		archived++
+	}
+
+	log.Printf("Mock: Archived %d entries", archived)
+	return archived, nil
+}
+
+// GetArchivedEntries gets the archived entries created in [from, to), newest first and at most limit
+func (r *MockQueueRepository) GetArchivedEntries(ctx context.Context, from, to time.Time, roomId string, limit int) ([]*types.Entry, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	var entries []*types.Entry
+	for _, entry := range r.archive {
+		if entry.CreatedAt.Before(from) || !entry.CreatedAt.Before(to) || (roomId != "" && entry.WaitingRoomID != roomId) {
+			continue
+		}
+		if (buildingID != "" && entry.TenantID != buildingID) || (sectionID != "" && entry.SectionID != sectionID) {
+			continue
+		}
+		entries = append(entries, copyEntry(entry))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].CreatedAt.After(entries[j].CreatedAt) })
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// GetEntriesToAnonymize gets the queued and archived entries of exactly the given tenant and section
+// in one of statuses, last updated before the given time and not anonymized yet
+func (r *MockQueueRepository) GetEntriesToAnonymize(ctx context.Context, tenantId, sectionId string, before time.Time, statuses []string) ([]*types.Entry, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var entries []*types.Entry
+	for _, entry := range append(mapValues(r.entries), mapValues(r.archive)...) {
+		if entry.TenantID != tenantId || entry.SectionID != sectionId || entry.AnonymizedAt != nil {
+			continue
+		}
+		if !entry.UpdatedAt.Before(before) || !containsStatus(statuses, entry.Status) {
+			continue
+		}
+		entries = append(entries, copyEntry(entry))
+	}
+	return entries, nil
+}
+
+// AnonymizeEntry replaces the card data of a queued or archived entry and marks it anonymized
+func (r *MockQueueRepository) AnonymizeEntry(ctx context.Context, id string, cardData types.CardData, idNumberHash string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, exists := r.entries[id]
+	if !exists {
+		entry, exists = r.archive[id]
+	}
+	if !exists {
+		return fmt.Errorf("queue entry not found")
+	}
+
+	now := time.Now()
+	entry.CardData = cardData
+	entry.IDNumberHash = idNumberHash
+	entry.IdempotencyKey = ""
+	entry.NotifyPhone = ""
+	entry.NotifyEmail = ""
+	entry.PushSubscription = nil
+	entry.AnonymizedAt = &now
+	return nil
+}
+
+// DeleteEntriesByIdentifier deletes the queued and archived entries (filtered by tenant) whose card
+// has identifier as ID or insurance number, or that kept identifierHash
+func (r *MockQueueRepository) DeleteEntriesByIdentifier(ctx context.Context, identifier, identifierHash string) ([]*types.Entry, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	var deleted []*types.Entry
+	for _, stored := range []map[string]*types.Entry{r.entries, r.archive} {
+		for id, entry := range stored {
+			if (buildingID != "" && entry.TenantID != buildingID) || (sectionID != "" && entry.SectionID != sectionID) {
+				continue
+			}
+			if !matchesIdentifier(entry, identifier, identifierHash) {
+				continue
+			}
+			deleted = append(deleted, entry)
+			delete(stored, id)
+		}
+	}
+
+	log.Printf("Mock: Deleted %d entries of a data subject", len(deleted))
+	return deleted, nil
+}
+
+// DeleteTenantEntries deletes the queued and archived entries of the tenant in the context and
+// returns their IDs
+func (r *MockQueueRepository) DeleteTenantEntries(ctx context.Context) ([]string, error) {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	if buildingID == "" {
+		return nil, fmt.Errorf("a tenant is required to delete its entries")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var deleted []string
+	for _, stored := range []map[string]*types.Entry{r.entries, r.archive} {
+		for id, entry := range stored {
+			if entry.TenantID != buildingID || (sectionID != "" && entry.SectionID != sectionID) {
+				continue
+			}
+			deleted = append(deleted, id)
+			delete(stored, id)
+		}
+	}
+
+	log.Printf("Mock: Deleted %d entries of tenant %s", len(deleted), getTenantIDFromContext(ctx))
+	return deleted, nil
+}
+
+// DeleteTenantRoomData deletes the ticket counters and room states of the tenant in the context
+func (r *MockQueueRepository) DeleteTenantRoomData(ctx context.Context) error {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	if buildingID == "" {
+		return fmt.Errorf("a tenant is required to delete its room data")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	prefix := buildingID + ":" + sectionID + ":"
+	for key := range r.tickets {
+		if strings.HasPrefix(key, prefix) {
+			delete(r.tickets, key)
+		}
+	}
+	for id, state := range r.roomStates {
+		if state.TenantID == buildingID && state.SectionID == sectionID {
+			delete(r.roomStates, id)
+		}
+	}
+	return nil
+}
+
+// matchesIdentifier reports whether the card of an entry has identifier as ID or insurance number,
+// or the entry kept identifierHash
+func matchesIdentifier(entry *types.Entry, identifier, identifierHash string) bool {
+	if identifier != "" && (entry.CardData.IDNumber == identifier || entry.CardData.InsuranceNumber == identifier) {
+		return true
+	}
+	return identifierHash != "" && entry.IDNumberHash == identifierHash
+}
+
+// containsStatus reports whether status is one of statuses
+func containsStatus(statuses []string, status string) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// mapValues returns the entries of a map in no particular order
+func mapValues(entries map[string]*types.Entry) []*types.Entry {
+	values := make([]*types.Entry, 0, len(entries))
+	for _, entry := range entries {
+		values = append(values, entry)
+	}
+	return values
+}
+
+// groupEntryStats groups finished entries by day in loc, room and service point, in that order
+func groupEntryStats(entries []*types.Entry, loc *time.Location) []*types.EntryStats {
+	groups := make(map[string]*types.EntryStats)
+	for _, entry := range entries {
+		date := entry.CreatedAt.In(loc).Format("2006-01-02")
+		key := date + "|" + entry.WaitingRoomID + "|" + entry.ServicePoint
+		group, exists := groups[key]
+		if !exists {
+			group = &types.EntryStats{Date: date, RoomID: entry.WaitingRoomID, ServicePointID: entry.ServicePoint}
+			groups[key] = group
+		}
+		if entry.Status == "COMPLETED" {
+			group.Completed++
+		} else {
+			group.NoShows++
+		}
+		if entry.CalledAt != nil {
+			group.WaitSeconds = append(group.WaitSeconds, entry.CalledAt.Sub(entry.CreatedAt).Seconds())
+			if entry.Status == "COMPLETED" && entry.CompletedAt != nil {
+				group.ServiceSeconds = append(group.ServiceSeconds, entry.CompletedAt.Sub(*entry.CalledAt).Seconds())
+			}
+		}
+	}
+
+	stats := make([]*types.EntryStats, 0, len(groups))
+	for _, group := range groups {
+		stats = append(stats, group)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Date != stats[j].Date {
+			return stats[i].Date < stats[j].Date
+		}
+		if stats[i].RoomID != stats[j].RoomID {
+			return stats[i].RoomID < stats[j].RoomID
+		}
+		return stats[i].ServicePointID < stats[j].ServicePointID
+	})
+	return stats
+}
+
+// copyEntry returns a copy of a stored entry (nil for none), so callers never change the
+// repository's entries without holding its lock
+func copyEntry(entry *types.Entry) *types.Entry {
+	if entry == nil {
+		return nil
+	}
+	copied := *entry
+	return &copied
+}
+
 // Close closes the repository connection (no-op for mock)
 func (r *MockQueueRepository) Close() error {
 	return nil
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// MockTranslationGlossaryRepository implements TranslationGlossaryRepository using in-memory storage
+type MockTranslationGlossaryRepository struct {
+	entries []*types.GlossaryEntry
+	mutex   sync.RWMutex
+}
+
+// NewMockTranslationGlossaryRepository creates a new mock translation glossary repository
+func NewMockTranslationGlossaryRepository() *MockTranslationGlossaryRepository {
+	return &MockTranslationGlossaryRepository{}
+}
+
+// FindGlossaryTranslation returns the entry translating the text, nil when there is none
+func (r *MockTranslationGlossaryRepository) FindGlossaryTranslation(ctx context.Context, sourceLang, targetLang, sourceText string) (*types.GlossaryEntry, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	for _, entry := range r.entries {
+		if entry.TenantID == buildingID && entry.SectionID == sectionID &&
+			entry.SourceLang == sourceLang && entry.TargetLang == targetLang && entry.SourceText == sourceText {
+			found := *entry
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetGlossary returns the entries of the glossary by language pair and text
+func (r *MockTranslationGlossaryRepository) GetGlossary(ctx context.Context) ([]*types.GlossaryEntry, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	entries := []*types.GlossaryEntry{}
+	for _, entry := range r.entries {
+		if entry.TenantID == buildingID && entry.SectionID == sectionID {
+			found := *entry
+			entries = append(entries, &found)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.SourceLang != b.SourceLang {
+			return a.SourceLang < b.SourceLang
+		}
+		if a.TargetLang != b.TargetLang {
+			return a.TargetLang < b.TargetLang
+		}
+		return a.SourceText < b.SourceText
+	})
+	return entries, nil
+}
+
+// ReplaceGlossary replaces the glossary with the entries
+func (r *MockTranslationGlossaryRepository) ReplaceGlossary(ctx context.Context, entries []*types.GlossaryEntry) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	kept := r.entries[:0]
+	for _, entry := range r.entries {
+		if entry.TenantID != buildingID || entry.SectionID != sectionID {
+			kept = append(kept, entry)
+		}
+	}
+	now := time.Now()
+	for _, entry := range entries {
+		stored := *entry
+		stored.ID = uuid.New().String()
+		stored.TenantID = buildingID
+		stored.SectionID = sectionID
+		stored.UpdatedAt = now
+		kept = append(kept, &stored)
+	}
+	r.entries = kept
+	return nil
+}
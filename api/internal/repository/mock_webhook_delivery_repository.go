@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// MockWebhookDeliveryRepository implements WebhookDeliveryRepository using in-memory storage
+type MockWebhookDeliveryRepository struct {
+	deliveries map[string]*types.WebhookDelivery
+	mutex      sync.RWMutex
+}
+
+// NewMockWebhookDeliveryRepository creates a new mock webhook delivery repository
+func NewMockWebhookDeliveryRepository() *MockWebhookDeliveryRepository {
+	return &MockWebhookDeliveryRepository{
+		deliveries: make(map[string]*types.WebhookDelivery),
+	}
+}
+
+// EnqueueWebhookDelivery stores a new delivery
+func (r *MockWebhookDeliveryRepository) EnqueueWebhookDelivery(ctx context.Context, delivery *types.WebhookDelivery) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored := *delivery
+	r.deliveries[delivery.ID] = &stored
+	return nil
+}
+
+// ClaimDueWebhookDeliveries leases up to limit due PENDING deliveries, oldest first
+func (r *MockWebhookDeliveryRepository) ClaimDueWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*types.WebhookDelivery, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var due []*types.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.Status != types.WebhookDeliveryPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		if delivery.LockedUntil != nil && !delivery.LockedUntil.Before(now) {
+			continue
+		}
+		due = append(due, delivery)
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	lockedUntil := now.Add(lease)
+	claimed := make([]*types.WebhookDelivery, 0, len(due))
+	for _, delivery := range due {
+		delivery.LockedUntil = &lockedUntil
+		copied := *delivery
+		claimed = append(claimed, &copied)
+	}
+	return claimed, nil
+}
+
+// UpdateWebhookDelivery stores the state of a delivery and releases its lease
+func (r *MockWebhookDeliveryRepository) UpdateWebhookDelivery(ctx context.Context, delivery *types.WebhookDelivery) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delivery.LockedUntil = nil
+	stored := *delivery
+	r.deliveries[delivery.ID] = &stored
+	return nil
+}
+
+// GetWebhookDeliveries returns the deliveries of the tenant in ctx with the status, newest first
+func (r *MockWebhookDeliveryRepository) GetWebhookDeliveries(ctx context.Context, status string, limit int) ([]*types.WebhookDelivery, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	var deliveries []*types.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if status != "" && delivery.Status != status {
+			continue
+		}
+		if (buildingID != "" && delivery.TenantID != buildingID) || (sectionID != "" && delivery.SectionID != sectionID) {
+			continue
+		}
+		copied := *delivery
+		deliveries = append(deliveries, &copied)
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// GetWebhookDelivery returns a delivery of the tenant in ctx, nil when there is none
+func (r *MockWebhookDeliveryRepository) GetWebhookDelivery(ctx context.Context, id string) (*types.WebhookDelivery, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return nil, nil
+	}
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	if (buildingID != "" && delivery.TenantID != buildingID) || (sectionID != "" && delivery.SectionID != sectionID) {
+		return nil, nil
+	}
+	copied := *delivery
+	return &copied, nil
+}
+
+// DeleteDeliveredWebhookDeliveries deletes the deliveries delivered before before
+func (r *MockWebhookDeliveryRepository) DeleteDeliveredWebhookDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var deleted int64
+	for id, delivery := range r.deliveries {
+		if delivery.Status == types.WebhookDeliveryDelivered && delivery.DeliveredAt != nil && delivery.DeliveredAt.Before(before) {
+			delete(r.deliveries, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// DeleteTenantWebhookDeliveries deletes the deliveries of the tenant in ctx in every status
+func (r *MockWebhookDeliveryRepository) DeleteTenantWebhookDeliveries(ctx context.Context) (int64, error) {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	if buildingID == "" {
+		return 0, fmt.Errorf("a tenant is required to delete its webhook deliveries")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var deleted int64
+	for id, delivery := range r.deliveries {
+		if delivery.TenantID == buildingID && (sectionID == "" || delivery.SectionID == sectionID) {
+			delete(r.deliveries, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/arfis/waiting-room/internal/metrics"
+)
+
+// queueEntryIndexes are the indexes of queue_entries. The compound ones follow the filters of the
+// hot queries: equality fields first (room, tenant, section, service point, status), then the
+// field they sort or range on.
+var queueEntryIndexes = []mongo.IndexModel{
+	{
+		// Queue listings, next waiting entry and position recalculation
+		Keys: bson.D{{Key: "waitingRoomId", Value: 1}, {Key: "tenantId", Value: 1}, {Key: "sectionId", Value: 1}, {Key: "status", Value: 1}, {Key: "position", Value: 1}},
+	},
+	{
+		// Next waiting and current served entry of a service point
+		Keys: bson.D{{Key: "waitingRoomId", Value: 1}, {Key: "tenantId", Value: 1}, {Key: "sectionId", Value: 1}, {Key: "servicePoint", Value: 1}, {Key: "status", Value: 1}, {Key: "position", Value: 1}},
+	},
+	{
+		// No-show expiry, archival and the anonymizer
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "updatedAt", Value: 1}},
+	},
+	{
+		Keys:    bson.D{{Key: "qrToken", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	},
+	{
+		Keys:    bson.D{{Key: "idempotencyKey", Value: 1}},
+		Options: options.Index().SetSparse(true),
+	},
+	{
+		// Wait-time analytics
+		Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "sectionId", Value: 1}, {Key: "createdAt", Value: 1}},
+	},
+	{
+		// Statistics of all tenants
+		Keys: bson.D{{Key: "createdAt", Value: 1}},
+	},
+}
+
+// archiveIndexes are the indexes of queue_entries_archive, which is only queried for reports
+var archiveIndexes = []mongo.IndexModel{
+	{
+		Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "sectionId", Value: 1}, {Key: "createdAt", Value: -1}},
+	},
+	{
+		Keys: bson.D{{Key: "waitingRoomId", Value: 1}, {Key: "createdAt", Value: -1}},
+	},
+	{
+		Keys: bson.D{{Key: "createdAt", Value: -1}},
+	},
+	{
+		// The anonymizer
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "updatedAt", Value: 1}},
+	},
+}
+
+// ensureIndexes creates the indexes of a collection. Errors are only logged, an index might
+// already exist with other options.
+func ensureIndexes(ctx context.Context, collection *mongo.Collection, indexes []mongo.IndexModel) {
+	for _, index := range indexes {
+		if _, err := collection.Indexes().CreateOne(ctx, index); err != nil {
+			log.Printf("Index creation warning on %s (may already exist): %v", collection.Name(), err)
+		}
+	}
+}
+
+// verifyIndexes checks that every index of indexes exists on the collection and returns the keys
+// of the missing ones. Indexes are compared by their keys, not by name.
+func verifyIndexes(ctx context.Context, collection *mongo.Collection, indexes []mongo.IndexModel) ([]string, error) {
+	specs, err := collection.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexes of %s: %w", collection.Name(), err)
+	}
+
+	existing := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		var keys bson.D
+		if err := bson.Unmarshal(spec.KeysDocument, &keys); err != nil {
+			continue
+		}
+		existing[indexKeyString(keys)] = true
+	}
+
+	var missing []string
+	for _, index := range indexes {
+		keys, ok := index.Keys.(bson.D)
+		if !ok {
+			continue
+		}
+		if key := indexKeyString(keys); !existing[key] {
+			missing = append(missing, key)
+		}
+	}
+	return missing, nil
+}
+
+// indexKeyString renders index keys as "field_1_field_-1", the way MongoDB names indexes
+func indexKeyString(keys bson.D) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s_%v", key.Key, indexDirection(key.Value)))
+	}
+	return strings.Join(parts, "_")
+}
+
+// indexDirection normalizes the direction of an index key, which the server returns as int32,
+// int64 or double
+func indexDirection(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int32:
+		return int64(v)
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return value
+}
+
+// VerifyIndexes logs the indexes the queue repository expects but that are missing, e.g. because
+// an older index with the same keys and other options was kept. Queries still work without them,
+// only slower.
+func (r *MongoDBQueueRepository) VerifyIndexes(ctx context.Context) {
+	for _, check := range []struct {
+		collection *mongo.Collection
+		indexes    []mongo.IndexModel
+	}{
+		{r.collection, queueEntryIndexes},
+		{r.archive, archiveIndexes},
+	} {
+		missing, err := verifyIndexes(ctx, check.collection, check.indexes)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+			continue
+		}
+		for _, key := range missing {
+			log.Printf("Warning: Index %s is missing on %s", key, check.collection.Name())
+		}
+	}
+}
+
+// MongoClientOptions returns the client options for uri. The duration of every command is observed
+// in the metrics; with a positive slowQueryThreshold, commands taking at least that long are also
+// logged with their collection and filter fields.
+func MongoClientOptions(uri string, slowQueryThreshold time.Duration) *options.ClientOptions {
+	return options.Client().ApplyURI(uri).SetMonitor(newCommandMonitor(slowQueryThreshold))
+}
+
+// newCommandMonitor returns a command monitor that observes the duration of the commands and logs
+// the ones taking at least threshold, none when it is 0. Only field names of the filter are
+// logged, its values may hold card data.
+func newCommandMonitor(threshold time.Duration) *event.CommandMonitor {
+	var started sync.Map // request ID -> description of the command
+	finished := func(requestID int64, commandName string, duration time.Duration, failure string) {
+		result := "ok"
+		if failure != "" {
+			result = "error"
+		}
+		metrics.MongoCommandDuration.ObserveDuration(duration, commandName, result)
+
+		description, ok := started.LoadAndDelete(requestID)
+		if !ok || duration < threshold {
+			return
+		}
+		if failure != "" {
+			log.Printf("MongoDB: Slow %s %s took %s and failed: %s", commandName, description, duration, failure)
+			return
+		}
+		log.Printf("MongoDB: Slow %s %s took %s", commandName, description, duration)
+	}
+
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, evt *event.CommandStartedEvent) {
+			if threshold > 0 {
+				started.Store(evt.RequestID, describeCommand(evt))
+			}
+		},
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			finished(evt.RequestID, evt.CommandName, evt.Duration, "")
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			finished(evt.RequestID, evt.CommandName, evt.Duration, evt.Failure)
+		},
+	}
+}
+
+// describeCommand returns "on <database>.<collection>" and the field names of the filter of a command
+func describeCommand(evt *event.CommandStartedEvent) string {
+	description := "on " + evt.DatabaseName
+	if collection, ok := evt.Command.Lookup(evt.CommandName).StringValueOK(); ok {
+		description += "." + collection
+	}
+
+	filter, ok := evt.Command.Lookup("filter").DocumentOK()
+	if !ok {
+		return description
+	}
+	elements, err := filter.Elements()
+	if err != nil || len(elements) == 0 {
+		return description
+	}
+	fields := make([]string, 0, len(elements))
+	for _, element := range elements {
+		fields = append(fields, element.Key())
+	}
+	return description + " filtering " + strings.Join(fields, ", ")
+}
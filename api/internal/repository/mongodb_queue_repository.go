@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -18,17 +20,23 @@ import (
 
 // MongoDBQueueRepository implements QueueRepository using MongoDB
 type MongoDBQueueRepository struct {
-	client     *mongo.Client
-	database   *mongo.Database
-	collection *mongo.Collection
+	client           *mongo.Client
+	database         *mongo.Database
+	collection       *mongo.Collection
+	archive          *mongo.Collection
+	orderingResolver QueueOrderingResolver
+	cardDataCipher   CardDataCipher
+	// noTransactions is set once the server turned out not to support transactions
+	noTransactions atomic.Bool
 }
 
-// NewMongoDBQueueRepository creates a new MongoDB queue repository
-func NewMongoDBQueueRepository(uri, dbName string) (*MongoDBQueueRepository, error) {
+// NewMongoDBQueueRepository creates a new MongoDB queue repository. With a positive
+// slowQueryThreshold, commands taking at least that long are logged.
+func NewMongoDBQueueRepository(uri, dbName string, slowQueryThreshold time.Duration) (*MongoDBQueueRepository, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	client, err := mongo.Connect(ctx, MongoClientOptions(uri, slowQueryThreshold))
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
@@ -42,30 +50,9 @@ func NewMongoDBQueueRepository(uri, dbName string) (*MongoDBQueueRepository, err
 	collection := database.Collection("queue_entries")
 
 	// Create indexes (ignore errors for existing indexes)
-	indexes := []mongo.IndexModel{
-		{
-			Keys: bson.D{{Key: "waitingRoomId", Value: 1}},
-		},
-		{
-			Keys:    bson.D{{Key: "qrToken", Value: 1}},
-			Options: options.Index().SetUnique(true),
-		},
-		{
-			Keys: bson.D{{Key: "status", Value: 1}},
-		},
-		{
-			Keys: bson.D{{Key: "position", Value: 1}},
-		},
-	}
-
-	// Try to create indexes, but don't fail if they already exist
-	for _, index := range indexes {
-		_, err := collection.Indexes().CreateOne(ctx, index)
-		if err != nil {
-			// Log but don't fail - index might already exist
-			log.Printf("Index creation warning (may already exist): %v", err)
-		}
-	}
+	archive := database.Collection("queue_entries_archive")
+	ensureIndexes(ctx, collection, queueEntryIndexes)
+	ensureIndexes(ctx, archive, archiveIndexes)
 
 	// Clean up existing entries with null qrToken values
 	cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -79,11 +66,15 @@ func NewMongoDBQueueRepository(uri, dbName string) (*MongoDBQueueRepository, err
 		log.Printf("Warning: Failed to cleanup null qrToken values: %v", err)
 	}
 
-	return &MongoDBQueueRepository{
+	repo := &MongoDBQueueRepository{
 		client:     client,
 		database:   database,
 		collection: collection,
-	}, nil
+		archive:    archive,
+	}
+	// An older index with the same keys but other options blocks creating ours
+	repo.VerifyIndexes(ctx)
+	return repo, nil
 }
 
 // CreateEntry creates a new queue entry
@@ -95,28 +86,12 @@ func (r *MongoDBQueueRepository) CreateEntry(ctx context.Context, entry *types.E
 
 	// Generate ticket number and QR token if not set
 	if entry.TicketNumber == "" {
-		// Build filter for counting entries: same room + same tenant + same section
-		// This ensures numbering is per tenant/section, not global
-		countFilter := bson.M{"waitingRoomId": entry.WaitingRoomID}
-		
-		// Only add tenant filter if tenant ID is set
-		if entry.TenantID != "" {
-			countFilter["tenantId"] = entry.TenantID
-		}
-		
-		// Only add section filter if section ID is set
-		if entry.SectionID != "" {
-			countFilter["sectionId"] = entry.SectionID
-		}
-		
-		// Get current count for this specific room + tenant + section to generate ticket number
-		count, err := r.collection.CountDocuments(ctx, countFilter)
+		sequence, err := r.NextTicketSequence(ctx, entry.WaitingRoomID, entry.TenantID, entry.SectionID, "")
 		if err != nil {
-			log.Printf("MongoDB: Failed to count documents for room %s, tenant %s, section %s: %v", entry.WaitingRoomID, entry.TenantID, entry.SectionID, err)
-			count = 0 // Fallback to 0 if count fails
+			return fmt.Errorf("failed to allocate ticket number: %w", err)
 		}
-		entry.TicketNumber = fmt.Sprintf("%s-%03d", strings.ToUpper(entry.WaitingRoomID), count+1)
-		log.Printf("MongoDB: Generated ticket number: %s for room: %s, tenant: %s, section: %s (count: %d)", entry.TicketNumber, entry.WaitingRoomID, entry.TenantID, entry.SectionID, count)
+		entry.TicketNumber = fmt.Sprintf("%s-%03d", strings.ToUpper(entry.WaitingRoomID), sequence)
+		log.Printf("MongoDB: Generated ticket number: %s for room: %s, tenant: %s, section: %s", entry.TicketNumber, entry.WaitingRoomID, entry.TenantID, entry.SectionID)
 	}
 
 	if entry.QRToken == "" {
@@ -125,8 +100,13 @@ func (r *MongoDBQueueRepository) CreateEntry(ctx context.Context, entry *types.E
 		log.Printf("MongoDB: Generated QR token: %s", entry.QRToken)
 	}
 
-	log.Printf("MongoDB: Inserting entry: %+v", entry)
-	result, err := r.collection.InsertOne(ctx, entry)
+	document, err := encryptedEntry(ctx, r.cardDataCipher, entry)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("MongoDB: Inserting entry with ticket %s in room %s", entry.TicketNumber, entry.WaitingRoomID)
+	result, err := r.collection.InsertOne(ctx, document)
 	if err != nil {
 		log.Printf("MongoDB: Insert failed: %v", err)
 		return fmt.Errorf("failed to create queue entry: %w", err)
@@ -140,6 +120,65 @@ func (r *MongoDBQueueRepository) CreateEntry(ctx context.Context, entry *types.E
 	return nil
 }
 
+// NextTicketSequence atomically increments and returns the ticket counter of a room, tenant and
+// section in a numbering period (empty = never reset); a new period starts at 1
+func (r *MongoDBQueueRepository) NextTicketSequence(ctx context.Context, roomId, tenantId, sectionId, period string) (int64, error) {
+	filter := bson.M{"_id": strings.Join([]string{tenantId, sectionId, roomId, period}, ":")}
+	update := bson.M{
+		"$inc": bson.M{"sequence": 1},
+		"$set": bson.M{
+			"roomId":    roomId,
+			"tenantId":  tenantId,
+			"sectionId": sectionId,
+			"period":    period,
+			"updatedAt": time.Now(),
+		},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var counter struct {
+		Sequence int64 `bson:"sequence"`
+	}
+	if err := r.database.Collection("ticket_counters").FindOneAndUpdate(ctx, filter, update, opts).Decode(&counter); err != nil {
+		return 0, fmt.Errorf("failed to increment ticket counter: %w", err)
+	}
+	return counter.Sequence, nil
+}
+
+// SetOrderingResolver sets where the ordering strategy of a room comes from (the priority order without one)
+func (r *MongoDBQueueRepository) SetOrderingResolver(resolver QueueOrderingResolver) {
+	r.orderingResolver = resolver
+}
+
+// SetCardDataCipher makes the repository encrypt the personal fields of card data at rest
+func (r *MongoDBQueueRepository) SetCardDataCipher(cipher CardDataCipher) {
+	r.cardDataCipher = cipher
+}
+
+// findOrdered finds the entries matching filter in the order of the room, at most limit (0 = all)
+func (r *MongoDBQueueRepository) findOrdered(ctx context.Context, roomId string, filter bson.M, limit int64) ([]*types.Entry, error) {
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: filter}}}
+	pipeline = append(pipeline, resolveOrdering(ctx, r.orderingResolver, roomId).SortStages()...)
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*types.Entry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode queue entries: %w", err)
+	}
+	if err := decryptEntries(ctx, r.cardDataCipher, entries...); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
 // GetQueueEntries retrieves all queue entries for a room (filtered by tenant if provided)
 func (r *MongoDBQueueRepository) GetQueueEntries(ctx context.Context, roomId string, states []string) ([]*types.Entry, error) {
 	// Extract tenant ID from context (format: "buildingId:sectionId")
@@ -172,25 +211,11 @@ func (r *MongoDBQueueRepository) GetQueueEntries(ctx context.Context, roomId str
 	
 	log.Printf("[QueueRepository] GetQueueEntries for room %s, tenantIDHeader: '%s', buildingId: '%s', sectionId: '%s', filter: %+v", roomId, tenantIDHeader, buildingID, sectionID, filter)
 
-	// Sort by priority: tier (lowest first), fitness score (lowest first), arrival time (earliest first), ticket number (alphabetically)
-	// This ensures proper priority-based ordering as defined in the priority config algorithm
-	opts := options.Find().SetSort(bson.D{
-		{Key: "tier", Value: 1},
-		{Key: "fitnessScore", Value: 1},
-		{Key: "createdAt", Value: 1},
-		{Key: "ticketNumber", Value: 1},
-	})
-
-	cursor, err := r.collection.Find(ctx, filter, opts)
+	// Sort by the ordering strategy of the room
+	entries, err := r.findOrdered(ctx, roomId, filter, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find queue entries: %w", err)
 	}
-	defer cursor.Close(ctx)
-
-	var entries []*types.Entry
-	if err := cursor.All(ctx, &entries); err != nil {
-		return nil, fmt.Errorf("failed to decode queue entries: %w", err)
-	}
 
 	return entries, nil
 }
@@ -215,6 +240,9 @@ func (r *MongoDBQueueRepository) GetEntryByID(ctx context.Context, id string) (*
 		return nil, fmt.Errorf("failed to find queue entry: %w", err)
 	}
 
+	if err := decryptEntries(ctx, r.cardDataCipher, &entry); err != nil {
+		return nil, err
+	}
 	return &entry, nil
 }
 
@@ -231,11 +259,57 @@ func (r *MongoDBQueueRepository) GetEntryByQRToken(ctx context.Context, qrToken
 		return nil, fmt.Errorf("failed to find queue entry: %w", err)
 	}
 
+	if err := decryptEntries(ctx, r.cardDataCipher, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetActiveEntryByIdempotencyKey gets the oldest WAITING, CALLED or IN_SERVICE entry (filtered by tenant)
+// created with one of the keys, or nil when there is none
+func (r *MongoDBQueueRepository) GetActiveEntryByIdempotencyKey(ctx context.Context, keys []string) (*types.Entry, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+
+	filter := bson.M{
+		"idempotencyKey": bson.M{"$in": keys},
+		"status":         bson.M{"$in": []string{"WAITING", "CALLED", "IN_SERVICE"}},
+	}
+	if buildingID != "" {
+		filter["tenantId"] = buildingID
+	}
+	if sectionID != "" {
+		filter["sectionId"] = sectionID
+	}
+
+	var entry types.Entry
+	opts := options.FindOne().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	if err := r.collection.FindOne(ctx, filter, opts).Decode(&entry); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find entry by idempotency key: %w", err)
+	}
+	if err := decryptEntries(ctx, r.cardDataCipher, &entry); err != nil {
+		return nil, err
+	}
 	return &entry, nil
 }
 
 // UpdateEntryStatus updates the status of a queue entry
-func (r *MongoDBQueueRepository) UpdateEntryStatus(ctx context.Context, id string, status string) error {
+func (r *MongoDBQueueRepository) UpdateEntryStatus(ctx context.Context, id string, status string, version int64) error {
+	return r.updateEntryStatus(ctx, id, status, bson.M{}, version)
+}
+
+// UpdateEntryStatusAndServicePoint updates the status and service point of a queue entry in one write
+func (r *MongoDBQueueRepository) UpdateEntryStatusAndServicePoint(ctx context.Context, id string, status string, servicePoint string, version int64) error {
+	return r.updateEntryStatus(ctx, id, status, bson.M{"servicePoint": servicePoint}, version)
+}
+
+// updateEntryStatus sets status and the fields of set on the entry still at version
+func (r *MongoDBQueueRepository) updateEntryStatus(ctx context.Context, id string, status string, set bson.M, version int64) error {
 	// Try to parse as ObjectID first, if that fails, use as string
 	var filter bson.M
 	if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
@@ -244,12 +318,18 @@ func (r *MongoDBQueueRepository) UpdateEntryStatus(ctx context.Context, id strin
 		// Use string ID (for UUIDs)
 		filter = bson.M{"_id": id}
 	}
-	update := bson.M{
-		"$set": bson.M{
-			"status":    status,
-			"updatedAt": time.Now(),
-		},
+	now := time.Now()
+	set["status"] = status
+	set["updatedAt"] = now
+	// The call and completion times feed the wait-time analytics
+	switch status {
+	case "CALLED":
+		set["calledAt"] = now
+	case "COMPLETED":
+		set["completedAt"] = now
 	}
+	filter["version"] = versionFilter(version)
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
 
 	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
@@ -257,14 +337,14 @@ func (r *MongoDBQueueRepository) UpdateEntryStatus(ctx context.Context, id strin
 	}
 
 	if result.MatchedCount == 0 {
-		return fmt.Errorf("queue entry not found")
+		return r.missedUpdateError(ctx, filter, version)
 	}
 
 	return nil
 }
 
 // UpdateEntryPosition updates the position of a queue entry
-func (r *MongoDBQueueRepository) UpdateEntryPosition(ctx context.Context, id string, position int) error {
+func (r *MongoDBQueueRepository) UpdateEntryPosition(ctx context.Context, id string, position int, version int64) error {
 	// Try to parse as ObjectID first, if that fails, use as string
 	var filter bson.M
 	if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
@@ -273,11 +353,13 @@ func (r *MongoDBQueueRepository) UpdateEntryPosition(ctx context.Context, id str
 		// Use string ID (for UUIDs)
 		filter = bson.M{"_id": id}
 	}
+	filter["version"] = versionFilter(version)
 	update := bson.M{
 		"$set": bson.M{
 			"position":  position,
 			"updatedAt": time.Now(),
 		},
+		"$inc": bson.M{"version": 1},
 	}
 
 	result, err := r.collection.UpdateOne(ctx, filter, update)
@@ -286,39 +368,35 @@ func (r *MongoDBQueueRepository) UpdateEntryPosition(ctx context.Context, id str
 	}
 
 	if result.MatchedCount == 0 {
-		return fmt.Errorf("queue entry not found")
+		return r.missedUpdateError(ctx, filter, version)
 	}
 
 	return nil
 }
 
-// UpdateEntryServicePoint updates the service point of a queue entry
-func (r *MongoDBQueueRepository) UpdateEntryServicePoint(ctx context.Context, id string, servicePoint string) error {
-	// Try to parse as ObjectID first, if that fails, use as string
-	var filter bson.M
-	if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
-		filter = bson.M{"_id": objectID}
-	} else {
-		// Use string ID (for UUIDs)
-		filter = bson.M{"_id": id}
-	}
-	update := bson.M{
-		"$set": bson.M{
-			"servicePoint": servicePoint,
-			"updatedAt":    time.Now(),
-		},
+// versionFilter matches the entries at version; entries stored before versioning have none and
+// count as version 0
+func versionFilter(version int64) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
 	}
+	return version
+}
 
-	result, err := r.collection.UpdateOne(ctx, filter, update)
-	if err != nil {
-		return fmt.Errorf("failed to update entry service point: %w", err)
+// missedUpdateError explains why an update of filter at version matched no entry: it no longer
+// exists, or it is at another version
+func (r *MongoDBQueueRepository) missedUpdateError(ctx context.Context, filter bson.M, version int64) error {
+	delete(filter, "version")
+	var current struct {
+		Version int64 `bson:"version"`
 	}
-
-	if result.MatchedCount == 0 {
-		return fmt.Errorf("queue entry not found")
+	if err := r.collection.FindOne(ctx, filter).Decode(&current); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return fmt.Errorf("queue entry not found")
+		}
+		return fmt.Errorf("failed to get entry version: %w", err)
 	}
-
-	return nil
+	return fmt.Errorf("%w: expected version %d, entry is at %d", ErrVersionConflict, version, current.Version)
 }
 
 // GetNextWaitingEntry gets the next waiting entry for a room (filtered by tenant if provided)
@@ -342,14 +420,6 @@ func (r *MongoDBQueueRepository) GetNextWaitingEntry(ctx context.Context, roomId
 		filter["sectionId"] = sectionID
 	}
 
-	// Sort by priority: tier (lowest first), fitness score (lowest first), arrival time (earliest first), ticket number (alphabetically)
-	opts := options.FindOne().SetSort(bson.D{
-		{Key: "tier", Value: 1},
-		{Key: "fitnessScore", Value: 1},
-		{Key: "createdAt", Value: 1},
-		{Key: "ticketNumber", Value: 1},
-	})
-
 	log.Printf("[QueueRepository] GetNextWaitingEntry filter: %+v", filter)
 
 	// First, let's count how many documents match this filter
@@ -360,19 +430,19 @@ func (r *MongoDBQueueRepository) GetNextWaitingEntry(ctx context.Context, roomId
 		log.Printf("MongoDB: Found %d documents matching filter", count)
 	}
 
-	var entry types.Entry
-	err = r.collection.FindOne(ctx, filter, opts).Decode(&entry)
+	// The first entry in the ordering strategy of the room
+	entries, err := r.findOrdered(ctx, roomId, filter, 1)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			log.Printf("MongoDB: No waiting entries found (FindOne returned no documents)")
-			return nil, nil // No waiting entries
-		}
 		log.Printf("MongoDB: Error finding next waiting entry: %v", err)
 		return nil, fmt.Errorf("failed to find next waiting entry: %w", err)
 	}
+	if len(entries) == 0 {
+		log.Printf("MongoDB: No waiting entries found")
+		return nil, nil // No waiting entries
+	}
 
-	log.Printf("MongoDB: Successfully found and decoded entry: %+v", entry)
-	return &entry, nil
+	log.Printf("MongoDB: Found next waiting entry %s (ticket %s)", entries[0].ID, entries[0].TicketNumber)
+	return entries[0], nil
 }
 
 // GetCurrentServedEntry gets the currently served entry for a room (filtered by tenant if provided)
@@ -405,17 +475,20 @@ func (r *MongoDBQueueRepository) GetCurrentServedEntry(ctx context.Context, room
 		return nil, fmt.Errorf("failed to find current served entry: %w", err)
 	}
 
+	if err := decryptEntries(ctx, r.cardDataCipher, &entry); err != nil {
+		return nil, err
+	}
 	return &entry, nil
 }
 
 // RecalculatePositions recalculates positions for all waiting entries in a room (filtered by tenant if provided)
-// Positions are calculated based on tier (ASC), fitness score (ASC), arrival time (ASC), and ticket number (ASC)
+// Positions follow the ordering strategy of the room (by default tier, fitness score, arrival time and ticket number)
 func (r *MongoDBQueueRepository) RecalculatePositions(ctx context.Context, roomId string) error {
 	// Extract tenant ID from context (format: "buildingId:sectionId")
 	tenantIDHeader := getTenantIDFromContext(ctx)
 	buildingID, sectionID, _ := types.ParseTenantID(tenantIDHeader)
 
-	// Get all waiting entries sorted by priority: tier ASC, fitness score ASC, arrival time ASC, ticket number ASC
+	// Get all waiting entries in the order of the room
 	filter := bson.M{
 		"waitingRoomId": roomId,
 		"status":        "WAITING",
@@ -429,30 +502,20 @@ func (r *MongoDBQueueRepository) RecalculatePositions(ctx context.Context, roomI
 		filter["sectionId"] = sectionID
 	}
 
-	// Sort by: tier (lowest first), fitness score (lowest first), arrival time (earliest first), ticket number (alphabetically)
-	opts := options.Find().SetSort(bson.D{
-		{Key: "tier", Value: 1},
-		{Key: "fitnessScore", Value: 1},
-		{Key: "createdAt", Value: 1},
-		{Key: "ticketNumber", Value: 1},
-	})
-
-	cursor, err := r.collection.Find(ctx, filter, opts)
+	entries, err := r.findOrdered(ctx, roomId, filter, 0)
 	if err != nil {
 		return fmt.Errorf("failed to find waiting entries: %w", err)
 	}
-	defer cursor.Close(ctx)
-
-	var entries []types.Entry
-	if err := cursor.All(ctx, &entries); err != nil {
-		return fmt.Errorf("failed to decode waiting entries: %w", err)
-	}
 
-	// Update positions based on the priority-sorted order
+	// Update positions based on the sorted order
 	for i, entry := range entries {
 		newPosition := i + 1
 		if entry.Position != int64(newPosition) {
-			if err := r.UpdateEntryPosition(ctx, entry.ID, newPosition); err != nil {
+			if err := r.UpdateEntryPosition(ctx, entry.ID, newPosition, entry.Version); err != nil {
+				// The entry changed since it was read; whoever changed it recalculates the positions
+				if errors.Is(err, ErrVersionConflict) {
+					continue
+				}
 				return fmt.Errorf("failed to update position for entry %s: %w", entry.ID, err)
 			}
 		}
@@ -463,8 +526,106 @@ func (r *MongoDBQueueRepository) RecalculatePositions(ctx context.Context, roomI
 	return nil
 }
 
-// DeleteEntry deletes a queue entry
-func (r *MongoDBQueueRepository) DeleteEntry(ctx context.Context, id string) error {
+// GetCalledEntriesBefore gets the CALLED entries of all tenants last updated before the given time
+func (r *MongoDBQueueRepository) GetCalledEntriesBefore(ctx context.Context, before time.Time) ([]*types.Entry, error) {
+	filter := bson.M{
+		"status":    "CALLED",
+		"updatedAt": bson.M{"$lt": before},
+	}
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find called entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*types.Entry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode called entries: %w", err)
+	}
+
+	if err := decryptEntries(ctx, r.cardDataCipher, entries...); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetEntriesUpdatedSince gets the queue entries of all tenants last updated at or after the given time
+func (r *MongoDBQueueRepository) GetEntriesUpdatedSince(ctx context.Context, since time.Time) ([]*types.Entry, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"updatedAt": bson.M{"$gte": since}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find updated entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*types.Entry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode updated entries: %w", err)
+	}
+
+	if err := decryptEntries(ctx, r.cardDataCipher, entries...); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// WatchEntryChanges calls onChange with every inserted, updated or replaced queue entry until ctx
+// is done. Deletes are not reported, the stream carries no document for them. Change streams need
+// a replica set; on a standalone server it fails right away.
+func (r *MongoDBQueueRepository) WatchEntryChanges(ctx context.Context, onChange func(entry *types.Entry)) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"operationType": bson.M{"$in": []string{"insert", "update", "replace"}}}}},
+	}
+	stream, err := r.collection.Watch(ctx, pipeline, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+	if err != nil {
+		return fmt.Errorf("failed to watch queue entries: %w", err)
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		var change struct {
+			FullDocument *types.Entry `bson:"fullDocument"`
+		}
+		if err := stream.Decode(&change); err != nil {
+			log.Printf("MongoDB: Failed to decode queue entry change: %v", err)
+			continue
+		}
+		// The entry may be gone by the time an update is looked up
+		if change.FullDocument != nil {
+			if err := decryptEntries(ctx, r.cardDataCipher, change.FullDocument); err != nil {
+				log.Printf("MongoDB: %v", err)
+				continue
+			}
+			onChange(change.FullDocument)
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return fmt.Errorf("queue entry change stream stopped: %w", stream.Err())
+}
+
+// GetWaitingEntries gets the WAITING entries of all rooms and tenants
+func (r *MongoDBQueueRepository) GetWaitingEntries(ctx context.Context) ([]*types.Entry, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"status": "WAITING"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find waiting entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*types.Entry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode waiting entries: %w", err)
+	}
+
+	if err := decryptEntries(ctx, r.cardDataCipher, entries...); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// UpdateEntryFitnessScore updates the fitness score of a queue entry and its breakdown
+func (r *MongoDBQueueRepository) UpdateEntryFitnessScore(ctx context.Context, id string, fitnessScore float64, breakdown *types.FitnessBreakdown) error {
 	// Try to parse as ObjectID first, if that fails, use as string
 	var filter bson.M
 	if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
@@ -473,80 +634,955 @@ func (r *MongoDBQueueRepository) DeleteEntry(ctx context.Context, id string) err
 		// Use string ID (for UUIDs)
 		filter = bson.M{"_id": id}
 	}
-	result, err := r.collection.DeleteOne(ctx, filter)
+	update := bson.M{
+		"$set": bson.M{
+			"fitnessScore":     fitnessScore,
+			"fitnessBreakdown": breakdown,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return fmt.Errorf("failed to delete queue entry: %w", err)
+		return fmt.Errorf("failed to update entry fitness score: %w", err)
 	}
 
-	if result.DeletedCount == 0 {
+	if result.MatchedCount == 0 {
 		return fmt.Errorf("queue entry not found")
 	}
 
 	return nil
 }
 
-// GetNextWaitingEntryForServicePoint gets the next waiting entry for a specific service point (filtered by tenant if provided)
-func (r *MongoDBQueueRepository) GetNextWaitingEntryForServicePoint(ctx context.Context, roomId, servicePointId string) (*types.Entry, error) {
-	// Extract tenant ID from context (format: "buildingId:sectionId")
-	tenantIDHeader := getTenantIDFromContext(ctx)
-	buildingID, sectionID, _ := types.ParseTenantID(tenantIDHeader)
-	
-	collection := r.database.Collection("queue_entries")
-
-	filter := bson.M{
-		"waitingRoomId": roomId,
-		"servicePoint":  servicePointId,
-		"status":        "WAITING",
-	}
-	
-	// Add tenant filtering if tenant ID is provided
-	if buildingID != "" {
-		filter["tenantId"] = buildingID
+// EscalateEntryTier moves a queue entry to a higher priority tier and marks it as escalated
+func (r *MongoDBQueueRepository) EscalateEntryTier(ctx context.Context, id string, tier int) error {
+	// Try to parse as ObjectID first, if that fails, use as string
+	var filter bson.M
+	if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+		filter = bson.M{"_id": objectID}
+	} else {
+		// Use string ID (for UUIDs)
+		filter = bson.M{"_id": id}
 	}
-	if sectionID != "" {
-		filter["sectionId"] = sectionID
+	update := bson.M{
+		"$set": bson.M{
+			"tier":          tier,
+			"tierEscalated": true,
+			"updatedAt":     time.Now(),
+		},
 	}
 
-	// Sort by priority: tier (lowest first), fitness score (lowest first), arrival time (earliest first), ticket number (alphabetically)
-	opts := options.FindOne().SetSort(bson.D{
-		{Key: "tier", Value: 1},
-		{Key: "fitnessScore", Value: 1},
-		{Key: "createdAt", Value: 1},
-		{Key: "ticketNumber", Value: 1},
-	})
-
-	var entry types.Entry
-	err := collection.FindOne(ctx, filter, opts).Decode(&entry)
+	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to get next waiting entry for service point: %w", err)
+		return fmt.Errorf("failed to escalate entry tier: %w", err)
 	}
 
-	return &entry, nil
-}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("queue entry not found")
+	}
 
-// GetCurrentServedEntryForServicePoint gets the currently served entry for a specific service point (filtered by tenant if provided)
-func (r *MongoDBQueueRepository) GetCurrentServedEntryForServicePoint(ctx context.Context, roomId, servicePointId string) (*types.Entry, error) {
-	// Extract tenant ID from context (format: "buildingId:sectionId")
-	tenantIDHeader := getTenantIDFromContext(ctx)
-	buildingID, sectionID, _ := types.ParseTenantID(tenantIDHeader)
-	
-	collection := r.database.Collection("queue_entries")
+	return nil
+}
 
-	filter := bson.M{
-		"waitingRoomId": roomId,
-		"servicePoint":  servicePointId,
-		"status":        bson.M{"$in": []string{"CALLED", "IN_ROOM", "IN_SERVICE"}},
+// UpdateEntryManualOverride sets the manual priority override of a queue entry with the fitness score it results in
+func (r *MongoDBQueueRepository) UpdateEntryManualOverride(ctx context.Context, id string, manualOverride float64, fitnessScore float64, breakdown *types.FitnessBreakdown) error {
+	// Try to parse as ObjectID first, if that fails, use as string
+	var filter bson.M
+	if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+		filter = bson.M{"_id": objectID}
+	} else {
+		// Use string ID (for UUIDs)
+		filter = bson.M{"_id": id}
 	}
-	
-	// Add tenant filtering if tenant ID is provided
-	if buildingID != "" {
-		filter["tenantId"] = buildingID
+	update := bson.M{
+		"$set": bson.M{
+			"manualOverride":   manualOverride,
+			"fitnessScore":     fitnessScore,
+			"fitnessBreakdown": breakdown,
+			"updatedAt":        time.Now(),
+		},
 	}
-	if sectionID != "" {
-		filter["sectionId"] = sectionID
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update entry manual override: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("queue entry not found")
+	}
+
+	return nil
+}
+
+// UpdateEntryAnnotations replaces the staff notes and tags of a queue entry
+func (r *MongoDBQueueRepository) UpdateEntryAnnotations(ctx context.Context, id string, notes string, tags []string, annotatedBy string) error {
+	// Try to parse as ObjectID first, if that fails, use as string
+	var filter bson.M
+	if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+		filter = bson.M{"_id": objectID}
+	} else {
+		// Use string ID (for UUIDs)
+		filter = bson.M{"_id": id}
+	}
+	now := time.Now()
+	update := bson.M{"$set": bson.M{
+		"notes":       notes,
+		"tags":        tags,
+		"annotatedBy": annotatedBy,
+		"annotatedAt": now,
+		"updatedAt":   now,
+	}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update entry annotations: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("queue entry not found")
+	}
+
+	return nil
+}
+
+// UpdateEntryContact replaces the notification phone number and email of a queue entry; empty clears them
+func (r *MongoDBQueueRepository) UpdateEntryContact(ctx context.Context, id string, phone string, email string) error {
+	// Try to parse as ObjectID first, if that fails, use as string
+	var filter bson.M
+	if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+		filter = bson.M{"_id": objectID}
+	} else {
+		// Use string ID (for UUIDs)
+		filter = bson.M{"_id": id}
+	}
+	set := bson.M{"updatedAt": time.Now()}
+	unset := bson.M{}
+	for field, value := range map[string]string{"notifyPhone": phone, "notifyEmail": email} {
+		if value == "" {
+			unset[field] = ""
+		} else {
+			set[field] = value
+		}
+	}
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update entry contact: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("queue entry not found")
+	}
+
+	return nil
+}
+
+// UpdateEntryService replaces the service name and approximate duration (seconds) of a queue entry
+func (r *MongoDBQueueRepository) UpdateEntryService(ctx context.Context, id string, serviceName string, durationSeconds int64) error {
+	// Try to parse as ObjectID first, if that fails, use as string
+	var filter bson.M
+	if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+		filter = bson.M{"_id": objectID}
+	} else {
+		// Use string ID (for UUIDs)
+		filter = bson.M{"_id": id}
+	}
+	update := bson.M{"$set": bson.M{
+		"serviceName":         serviceName,
+		"approximateDuration": durationSeconds,
+		"updatedAt":           time.Now(),
+	}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update entry service: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("queue entry not found")
+	}
+
+	return nil
+}
+
+// MarkEntryApproachNotified records that the patient of a queue entry was told their turn is near;
+// false when it was recorded already, e.g. by another replica
+func (r *MongoDBQueueRepository) MarkEntryApproachNotified(ctx context.Context, id string) (bool, error) {
+	// Try to parse as ObjectID first, if that fails, use as string
+	var filter bson.M
+	if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+		filter = bson.M{"_id": objectID}
+	} else {
+		// Use string ID (for UUIDs)
+		filter = bson.M{"_id": id}
+	}
+	filter["approachNotifiedAt"] = bson.M{"$exists": false}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"approachNotifiedAt": time.Now()}})
+	if err != nil {
+		return false, fmt.Errorf("failed to mark entry approach notified: %w", err)
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// UpdateEntryPushSubscription replaces the Web Push subscription of a queue entry; nil clears it
+func (r *MongoDBQueueRepository) UpdateEntryPushSubscription(ctx context.Context, id string, subscription *types.PushSubscription) error {
+	// Try to parse as ObjectID first, if that fails, use as string
+	var filter bson.M
+	if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+		filter = bson.M{"_id": objectID}
+	} else {
+		// Use string ID (for UUIDs)
+		filter = bson.M{"_id": id}
+	}
+	update := bson.M{"$set": bson.M{"pushSubscription": subscription, "updatedAt": time.Now()}}
+	if subscription == nil {
+		update = bson.M{"$set": bson.M{"updatedAt": time.Now()}, "$unset": bson.M{"pushSubscription": ""}}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update entry push subscription: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("queue entry not found")
+	}
+
+	return nil
+}
+
+// MarkEntryPushMilestone records that a queue entry got the push of a people-ahead milestone;
+// false when it got the one of this or a smaller milestone already, e.g. from another replica
+func (r *MongoDBQueueRepository) MarkEntryPushMilestone(ctx context.Context, id string, milestone int) (bool, error) {
+	// Try to parse as ObjectID first, if that fails, use as string
+	var filter bson.M
+	if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+		filter = bson.M{"_id": objectID}
+	} else {
+		// Use string ID (for UUIDs)
+		filter = bson.M{"_id": id}
+	}
+	filter["$or"] = bson.A{
+		bson.M{"pushMilestone": bson.M{"$exists": false}},
+		bson.M{"pushMilestone": bson.M{"$gt": milestone}},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"pushMilestone": milestone}})
+	if err != nil {
+		return false, fmt.Errorf("failed to mark entry push milestone: %w", err)
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// TransferEntry moves an entry to another room and service point (empty = any) and puts it back into WAITING.
+// Room, service point and status change in one document update.
+func (r *MongoDBQueueRepository) TransferEntry(ctx context.Context, id string, roomId string, servicePoint string) error {
+	// Try to parse as ObjectID first, if that fails, use as string
+	var filter bson.M
+	if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+		filter = bson.M{"_id": objectID}
+	} else {
+		// Use string ID (for UUIDs)
+		filter = bson.M{"_id": id}
+	}
+	set := bson.M{
+		"waitingRoomId": roomId,
+		"status":        "WAITING",
+		"updatedAt":     time.Now(),
+	}
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+	if servicePoint != "" {
+		set["servicePoint"] = servicePoint
+	} else {
+		update["$unset"] = bson.M{"servicePoint": ""}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to transfer entry: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("queue entry not found")
+	}
+
+	return nil
+}
+
+// MoveEntryToPathwayStage sets the pathway stage of an entry and lets it wait in the stage's room and service point (empty = any)
+func (r *MongoDBQueueRepository) MoveEntryToPathwayStage(ctx context.Context, id string, pathwayId string, stage int, roomId string, servicePoint string) error {
+	// Try to parse as ObjectID first, if that fails, use as string
+	var filter bson.M
+	if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+		filter = bson.M{"_id": objectID}
+	} else {
+		// Use string ID (for UUIDs)
+		filter = bson.M{"_id": id}
+	}
+	set := bson.M{
+		"pathwayId":     pathwayId,
+		"pathwayStage":  stage,
+		"waitingRoomId": roomId,
+		"status":        "WAITING",
+		"updatedAt":     time.Now(),
+	}
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+	if servicePoint != "" {
+		set["servicePoint"] = servicePoint
+	} else {
+		update["$unset"] = bson.M{"servicePoint": ""}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to move entry to pathway stage: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("queue entry not found")
+	}
+
+	return nil
+}
+
+// BulkUpdateStatus sets every entry of a room (filtered by tenant) in one of fromStatuses to toStatus
+// and recalculates the room's positions in one transaction; it returns the number of entries changed
+func (r *MongoDBQueueRepository) BulkUpdateStatus(ctx context.Context, roomId string, fromStatuses []string, toStatus string) (int64, error) {
+	var changed int64
+	err := r.WithTransaction(ctx, func(txCtx context.Context) error {
+		filter := roomTenantFilter(txCtx, roomId)
+		filter["status"] = bson.M{"$in": fromStatuses}
+		update := bson.M{
+			"$set": bson.M{"status": toStatus, "updatedAt": time.Now()},
+			"$inc": bson.M{"version": 1},
+		}
+
+		result, err := r.collection.UpdateMany(txCtx, filter, update)
+		if err != nil {
+			return fmt.Errorf("failed to update entry statuses: %w", err)
+		}
+		changed = result.ModifiedCount
+		return r.RecalculatePositions(txCtx, roomId)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
+
+// MoveWaitingEntries moves every WAITING entry of a room (filtered by tenant) to targetRoomId without
+// a service point and recalculates both rooms' positions in one transaction; it returns the number moved
+func (r *MongoDBQueueRepository) MoveWaitingEntries(ctx context.Context, roomId string, targetRoomId string) (int64, error) {
+	var moved int64
+	err := r.WithTransaction(ctx, func(txCtx context.Context) error {
+		filter := roomTenantFilter(txCtx, roomId)
+		filter["status"] = "WAITING"
+		update := bson.M{
+			"$set":   bson.M{"waitingRoomId": targetRoomId, "updatedAt": time.Now()},
+			"$unset": bson.M{"servicePoint": ""},
+			"$inc":   bson.M{"version": 1},
+		}
+
+		result, err := r.collection.UpdateMany(txCtx, filter, update)
+		if err != nil {
+			return fmt.Errorf("failed to move waiting entries: %w", err)
+		}
+		moved = result.ModifiedCount
+		if err := r.RecalculatePositions(txCtx, targetRoomId); err != nil {
+			return err
+		}
+		return r.RecalculatePositions(txCtx, roomId)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+// DeleteTenantRoomData deletes the ticket counters and room states of the tenant in the context
+func (r *MongoDBQueueRepository) DeleteTenantRoomData(ctx context.Context) error {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	if buildingID == "" {
+		return fmt.Errorf("a tenant is required to delete its room data")
+	}
+	filter := bson.M{"tenantId": buildingID, "sectionId": emptyOrMissing(sectionID)}
+	return r.WithTransaction(ctx, func(txCtx context.Context) error {
+		if _, err := r.database.Collection("ticket_counters").DeleteMany(txCtx, filter); err != nil {
+			return fmt.Errorf("failed to delete ticket counters of tenant: %w", err)
+		}
+		if _, err := r.database.Collection("room_states").DeleteMany(txCtx, filter); err != nil {
+			return fmt.Errorf("failed to delete room states of tenant: %w", err)
+		}
+		return nil
+	})
+}
+
+// WithTransaction runs fn in a MongoDB transaction, or in the one of ctx when there is one. A
+// standalone server (e.g. local development) does not support transactions; fn then runs without one.
+func (r *MongoDBQueueRepository) WithTransaction(ctx context.Context, fn func(txCtx context.Context) error) error {
+	if r.noTransactions.Load() || mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	session, err := r.client.StartSession()
+	if err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+	if err != nil && isTransactionNotSupported(err) {
+		log.Printf("[QueueRepository] Transactions are not supported by the server, running without them")
+		r.noTransactions.Store(true)
+		return fn(ctx)
+	}
+	return err
+}
+
+// isTransactionNotSupported reports whether err means the server cannot run transactions
+func isTransactionNotSupported(err error) bool {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Code == 20 { // IllegalOperation
+		return true
+	}
+	return strings.Contains(err.Error(), "Transaction numbers are only allowed")
+}
+
+// roomTenantFilter matches the entries of a room within the tenant in the context
+func roomTenantFilter(ctx context.Context, roomId string) bson.M {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	filter := bson.M{"waitingRoomId": roomId}
+	if buildingID != "" {
+		filter["tenantId"] = buildingID
+	}
+	if sectionID != "" {
+		filter["sectionId"] = sectionID
+	}
+	return filter
+}
+
+// GetRoomStates gets the stored states of a room and its service points (filtered by tenant)
+func (r *MongoDBQueueRepository) GetRoomStates(ctx context.Context, roomId string) ([]*types.RoomState, error) {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	// States are stored per tenant and section; an empty ID is stored as a missing field
+	filter := bson.M{"roomId": roomId, "tenantId": emptyOrMissing(buildingID), "sectionId": emptyOrMissing(sectionID)}
+
+	cursor, err := r.database.Collection("room_states").Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find room states: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var states []*types.RoomState
+	if err := cursor.All(ctx, &states); err != nil {
+		return nil, fmt.Errorf("failed to decode room states: %w", err)
+	}
+	return states, nil
+}
+
+// emptyOrMissing matches value, or a missing field when value is empty
+func emptyOrMissing(value string) interface{} {
+	if value == "" {
+		return bson.M{"$in": []interface{}{"", nil}}
+	}
+	return value
+}
+
+// SetRoomState creates or replaces the state of a room or service point within the tenant in the context
+func (r *MongoDBQueueRepository) SetRoomState(ctx context.Context, state *types.RoomState) error {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	state.TenantID = buildingID
+	state.SectionID = sectionID
+	state.ID = strings.Join([]string{buildingID, sectionID, state.RoomID, state.ServicePointID}, ":")
+	state.UpdatedAt = time.Now()
+
+	upsert := true
+	_, err := r.database.Collection("room_states").ReplaceOne(ctx, bson.M{"_id": state.ID}, state, &options.ReplaceOptions{Upsert: &upsert})
+	if err != nil {
+		return fmt.Errorf("failed to store room state: %w", err)
+	}
+	return nil
+}
+
+// GetEntryStats groups the COMPLETED and NO_SHOW entries created in [from, to) (filtered by tenant,
+// and by room unless roomId is empty) by day in loc, room and service point
+func (r *MongoDBQueueRepository) GetEntryStats(ctx context.Context, from, to time.Time, roomId string, loc *time.Location) ([]*types.EntryStats, error) {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	match := bson.M{
+		"status":    bson.M{"$in": []string{"COMPLETED", "NO_SHOW"}},
+		"createdAt": bson.M{"$gte": from, "$lt": to},
+	}
+	if buildingID != "" {
+		match["tenantId"] = buildingID
+	}
+	if sectionID != "" {
+		match["sectionId"] = sectionID
+	}
+	if roomId != "" {
+		match["waitingRoomId"] = roomId
+	}
+
+	seconds := func(end, start string) bson.M {
+		return bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{end, start}}, 1000}}
+	}
+	called := bson.M{"$gt": bson.A{"$calledAt", nil}}
+	completed := bson.M{"$eq": bson.A{"$status", "COMPLETED"}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$unionWith", Value: bson.M{"coll": r.archive.Name(), "pipeline": bson.A{bson.M{"$match": match}}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"date":           bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$createdAt", "timezone": mongoTimezone(loc)}},
+				"roomId":         "$waitingRoomId",
+				"servicePointId": "$servicePoint",
+			},
+			"completed": bson.M{"$sum": bson.M{"$cond": bson.A{completed, 1, 0}}},
+			"noShows":   bson.M{"$sum": bson.M{"$cond": bson.A{completed, 0, 1}}},
+			"waitSeconds": bson.M{"$push": bson.M{"$cond": bson.A{
+				called, seconds("$calledAt", "$createdAt"), "$$REMOVE",
+			}}},
+			"serviceSeconds": bson.M{"$push": bson.M{"$cond": bson.A{
+				bson.M{"$and": bson.A{completed, called, bson.M{"$gt": bson.A{"$completedAt", nil}}}},
+				seconds("$completedAt", "$calledAt"), "$$REMOVE",
+			}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.date", Value: 1}, {Key: "_id.roomId", Value: 1}, {Key: "_id.servicePointId", Value: 1}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate entry stats: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		ID struct {
+			Date           string `bson:"date"`
+			RoomID         string `bson:"roomId"`
+			ServicePointID string `bson:"servicePointId"`
+		} `bson:"_id"`
+		Completed      int64     `bson:"completed"`
+		NoShows        int64     `bson:"noShows"`
+		WaitSeconds    []float64 `bson:"waitSeconds"`
+		ServiceSeconds []float64 `bson:"serviceSeconds"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, fmt.Errorf("failed to decode entry stats: %w", err)
+	}
+
+	stats := make([]*types.EntryStats, 0, len(groups))
+	for _, group := range groups {
+		stats = append(stats, &types.EntryStats{
+			Date:           group.ID.Date,
+			RoomID:         group.ID.RoomID,
+			ServicePointID: group.ID.ServicePointID,
+			Completed:      group.Completed,
+			NoShows:        group.NoShows,
+			WaitSeconds:    group.WaitSeconds,
+			ServiceSeconds: group.ServiceSeconds,
+		})
+	}
+	return stats, nil
+}
+
+// archiveBatchSize is the number of entries ArchiveEntries moves per transaction
+const archiveBatchSize = 500
+
+// ArchiveEntries moves the entries of all tenants in one of statuses last updated before the given
+// time to queue_entries_archive, in batches of archiveBatchSize
+func (r *MongoDBQueueRepository) ArchiveEntries(ctx context.Context, before time.Time, statuses []string) (int64, error) {
+	filter := bson.M{
+		"status":    bson.M{"$in": statuses},
+		"updatedAt": bson.M{"$lt": before},
+	}
+
+	var archived int64
+	for {
+		moved := 0
+		err := r.WithTransaction(ctx, func(txCtx context.Context) error {
+			cursor, err := r.collection.Find(txCtx, filter, options.Find().SetLimit(archiveBatchSize))
+			if err != nil {
+				return err
+			}
+			// Raw documents keep _id as stored, ObjectIDs included
+			var documents []bson.M
+			if err := cursor.All(txCtx, &documents); err != nil {
+				return err
+			}
+			moved = len(documents)
+			if moved == 0 {
+				return nil
+			}
+
+			now := time.Now()
+			models := make([]mongo.WriteModel, 0, moved)
+			ids := make(bson.A, 0, moved)
+			for _, document := range documents {
+				document["archivedAt"] = now
+				// Replacing keeps a batch that was archived but not deleted by an interrupted run
+				models = append(models, mongo.NewReplaceOneModel().SetFilter(bson.M{"_id": document["_id"]}).SetReplacement(document).SetUpsert(true))
+				ids = append(ids, document["_id"])
+			}
+			if _, err := r.archive.BulkWrite(txCtx, models); err != nil {
+				return err
+			}
+			_, err = r.collection.DeleteMany(txCtx, bson.M{"_id": bson.M{"$in": ids}})
+			return err
+		})
+		if err != nil {
+			return archived, fmt.Errorf("failed to archive entries: %w", err)
+		}
+		archived += int64(moved)
+		if moved < archiveBatchSize {
+			return archived, nil
+		}
+	}
+}
+
+// GetArchivedEntries gets the archived entries created in [from, to) (filtered by tenant, and by
+// room unless roomId is empty), newest first and at most limit
+func (r *MongoDBQueueRepository) GetArchivedEntries(ctx context.Context, from, to time.Time, roomId string, limit int) ([]*types.Entry, error) {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	filter := bson.M{"createdAt": bson.M{"$gte": from, "$lt": to}}
+	if buildingID != "" {
+		filter["tenantId"] = buildingID
+	}
+	if sectionID != "" {
+		filter["sectionId"] = sectionID
+	}
+	if roomId != "" {
+		filter["waitingRoomId"] = roomId
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := r.archive.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find archived entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*types.Entry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode archived entries: %w", err)
+	}
+	if err := decryptEntries(ctx, r.cardDataCipher, entries...); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetEntriesToAnonymize gets the queued and archived entries of exactly the given tenant and section
+// in one of statuses, last updated before the given time and not anonymized yet
+func (r *MongoDBQueueRepository) GetEntriesToAnonymize(ctx context.Context, tenantId, sectionId string, before time.Time, statuses []string) ([]*types.Entry, error) {
+	filter := bson.M{
+		"tenantId":     exactValue(tenantId),
+		"sectionId":    exactValue(sectionId),
+		"status":       bson.M{"$in": statuses},
+		"updatedAt":    bson.M{"$lt": before},
+		"anonymizedAt": bson.M{"$exists": false},
+	}
+
+	var entries []*types.Entry
+	for _, collection := range []*mongo.Collection{r.collection, r.archive} {
+		cursor, err := collection.Find(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find entries to anonymize: %w", err)
+		}
+		var found []*types.Entry
+		err = cursor.All(ctx, &found)
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode entries to anonymize: %w", err)
+		}
+		entries = append(entries, found...)
+	}
+	if err := decryptEntries(ctx, r.cardDataCipher, entries...); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// exactValue matches a tenant or section field equal to value; empty also matches a missing field
+func exactValue(value string) interface{} {
+	if value == "" {
+		return bson.M{"$in": bson.A{nil, ""}}
+	}
+	return value
+}
+
+// AnonymizeEntry replaces the card data of a queued or archived entry and marks it anonymized
+func (r *MongoDBQueueRepository) AnonymizeEntry(ctx context.Context, id string, cardData types.CardData, idNumberHash string) error {
+	var filter bson.M
+	if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+		filter = bson.M{"_id": objectID}
+	} else {
+		filter = bson.M{"_id": id}
+	}
+	set := bson.M{
+		"cardData":     cardData,
+		"anonymizedAt": time.Now(),
+	}
+	if idNumberHash != "" {
+		set["idNumberHash"] = idNumberHash
+	}
+	update := bson.M{"$set": set, "$unset": bson.M{"idempotencyKey": "", "notifyPhone": "", "notifyEmail": "", "pushSubscription": ""}}
+
+	for _, collection := range []*mongo.Collection{r.collection, r.archive} {
+		if r.cardDataCipher != nil {
+			// The kept fields are encrypted with the key of the entry's tenant
+			var current types.Entry
+			if err := collection.FindOne(ctx, filter, options.FindOne().SetProjection(bson.M{"tenantId": 1})).Decode(&current); err != nil {
+				if err == mongo.ErrNoDocuments {
+					continue
+				}
+				return fmt.Errorf("failed to find queue entry: %w", err)
+			}
+			encrypted := cardData
+			if err := r.cardDataCipher.EncryptCardData(ctx, current.TenantID, &encrypted); err != nil {
+				return fmt.Errorf("failed to encrypt card data: %w", err)
+			}
+			set["cardData"] = encrypted
+		}
+		result, err := collection.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return fmt.Errorf("failed to anonymize queue entry: %w", err)
+		}
+		if result.MatchedCount > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("queue entry not found")
+}
+
+// DeleteEntriesByIdentifier deletes the queued and archived entries (filtered by tenant) whose card
+// has identifier as ID or insurance number, or that kept identifierHash
+func (r *MongoDBQueueRepository) DeleteEntriesByIdentifier(ctx context.Context, identifier, identifierHash string) ([]*types.Entry, error) {
+	var matches bson.A
+	if identifier != "" {
+		matches = append(matches, bson.M{"cardData.idNumber": identifier}, bson.M{"cardData.insuranceNumber": identifier})
+		// Encrypted ID numbers are found by their index
+		indexes, err := idNumberIndexes(ctx, r.cardDataCipher, identifier)
+		if err != nil {
+			return nil, err
+		}
+		if len(indexes) > 0 {
+			matches = append(matches, bson.M{"cardData.idNumberIndex": bson.M{"$in": indexes}})
+		}
+	}
+	if identifierHash != "" {
+		matches = append(matches, bson.M{"idNumberHash": identifierHash})
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	filter := bson.M{"$or": matches}
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	if buildingID != "" {
+		filter["tenantId"] = buildingID
+	}
+	if sectionID != "" {
+		filter["sectionId"] = sectionID
+	}
+
+	var deleted []*types.Entry
+	err := r.WithTransaction(ctx, func(txCtx context.Context) error {
+		deleted = nil
+		for _, collection := range []*mongo.Collection{r.collection, r.archive} {
+			cursor, err := collection.Find(txCtx, filter)
+			if err != nil {
+				return err
+			}
+			var found []*types.Entry
+			err = cursor.All(txCtx, &found)
+			cursor.Close(txCtx)
+			if err != nil {
+				return err
+			}
+			if len(found) == 0 {
+				continue
+			}
+			if _, err := collection.DeleteMany(txCtx, filter); err != nil {
+				return err
+			}
+			deleted = append(deleted, found...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete entries of data subject: %w", err)
+	}
+	return deleted, nil
+}
+
+// DeleteTenantEntries deletes the queued and archived entries of the tenant in the context and
+// returns their IDs
+func (r *MongoDBQueueRepository) DeleteTenantEntries(ctx context.Context) ([]string, error) {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	if buildingID == "" {
+		return nil, fmt.Errorf("a tenant is required to delete its entries")
+	}
+	filter := bson.M{"tenantId": buildingID}
+	if sectionID != "" {
+		filter["sectionId"] = sectionID
+	}
+
+	var deleted []string
+	err := r.WithTransaction(ctx, func(txCtx context.Context) error {
+		deleted = nil
+		for _, collection := range []*mongo.Collection{r.collection, r.archive} {
+			cursor, err := collection.Find(txCtx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+			if err != nil {
+				return err
+			}
+			var found []*types.Entry
+			err = cursor.All(txCtx, &found)
+			cursor.Close(txCtx)
+			if err != nil {
+				return err
+			}
+			if len(found) == 0 {
+				continue
+			}
+			if _, err := collection.DeleteMany(txCtx, filter); err != nil {
+				return err
+			}
+			for _, entry := range found {
+				deleted = append(deleted, entry.ID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete entries of tenant: %w", err)
+	}
+	return deleted, nil
+}
+
+// mongoTimezone returns the name of loc for MongoDB date operators; the process-local zone has no
+// IANA name and is sent as its current UTC offset
+func mongoTimezone(loc *time.Location) string {
+	if loc == time.Local || loc.String() == "Local" {
+		return time.Now().In(time.Local).Format("-07:00")
+	}
+	return loc.String()
+}
+
+// RequeueEntry puts an entry back into WAITING with the given priority tier and no service point
+func (r *MongoDBQueueRepository) RequeueEntry(ctx context.Context, id string, tier int) error {
+	// Try to parse as ObjectID first, if that fails, use as string
+	var filter bson.M
+	if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+		filter = bson.M{"_id": objectID}
+	} else {
+		// Use string ID (for UUIDs)
+		filter = bson.M{"_id": id}
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":    "WAITING",
+			"tier":      tier,
+			"updatedAt": time.Now(),
+		},
+		"$unset": bson.M{"servicePoint": ""},
+		"$inc":   bson.M{"version": 1},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to requeue entry: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("queue entry not found")
+	}
+
+	return nil
+}
+
+// DeleteEntry deletes a queue entry
+func (r *MongoDBQueueRepository) DeleteEntry(ctx context.Context, id string) error {
+	// Try to parse as ObjectID first, if that fails, use as string
+	var filter bson.M
+	if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+		filter = bson.M{"_id": objectID}
+	} else {
+		// Use string ID (for UUIDs)
+		filter = bson.M{"_id": id}
+	}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to delete queue entry: %w", err)
+	}
+
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("queue entry not found")
+	}
+
+	return nil
+}
+
+// GetNextWaitingEntryForServicePoint gets the next waiting entry for a specific service point (filtered by tenant if provided)
+func (r *MongoDBQueueRepository) GetNextWaitingEntryForServicePoint(ctx context.Context, roomId, servicePointId string) (*types.Entry, error) {
+	// Extract tenant ID from context (format: "buildingId:sectionId")
+	tenantIDHeader := getTenantIDFromContext(ctx)
+	buildingID, sectionID, _ := types.ParseTenantID(tenantIDHeader)
+	
+	filter := bson.M{
+		"waitingRoomId": roomId,
+		"servicePoint":  servicePointId,
+		"status":        "WAITING",
+	}
+	
+	// Add tenant filtering if tenant ID is provided
+	if buildingID != "" {
+		filter["tenantId"] = buildingID
+	}
+	if sectionID != "" {
+		filter["sectionId"] = sectionID
+	}
+
+	// The first entry in the ordering strategy of the room
+	entries, err := r.findOrdered(ctx, roomId, filter, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next waiting entry for service point: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	return entries[0], nil
+}
+
+// GetCurrentServedEntryForServicePoint gets the currently served entry for a specific service point (filtered by tenant if provided)
+func (r *MongoDBQueueRepository) GetCurrentServedEntryForServicePoint(ctx context.Context, roomId, servicePointId string) (*types.Entry, error) {
+	// Extract tenant ID from context (format: "buildingId:sectionId")
+	tenantIDHeader := getTenantIDFromContext(ctx)
+	buildingID, sectionID, _ := types.ParseTenantID(tenantIDHeader)
+	
+	collection := r.database.Collection("queue_entries")
+
+	filter := bson.M{
+		"waitingRoomId": roomId,
+		"servicePoint":  servicePointId,
+		"status":        bson.M{"$in": []string{"CALLED", "IN_ROOM", "IN_SERVICE"}},
+	}
+	
+	// Add tenant filtering if tenant ID is provided
+	if buildingID != "" {
+		filter["tenantId"] = buildingID
+	}
+	if sectionID != "" {
+		filter["sectionId"] = sectionID
 	}
 
 	opts := options.FindOne().SetSort(bson.M{"updatedAt": -1})
@@ -560,6 +1596,9 @@ func (r *MongoDBQueueRepository) GetCurrentServedEntryForServicePoint(ctx contex
 		return nil, fmt.Errorf("failed to get current served entry for service point: %w", err)
 	}
 
+	if err := decryptEntries(ctx, r.cardDataCipher, &entry); err != nil {
+		return nil, err
+	}
 	return &entry, nil
 }
 
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+//go:embed migrations/postgres/*.sql
+var postgresMigrations embed.FS
+
+// OpenPostgres connects to PostgreSQL and applies the schema migrations that have not run yet
+func OpenPostgres(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PostgreSQL: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping PostgreSQL: %w", err)
+	}
+	if err := migratePostgres(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// migratePostgres applies the migrations in migrations/postgres in file name order, each in its
+// own transaction, and records them in schema_migrations
+func migratePostgres(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	files, err := fs.Glob(postgresMigrations, "migrations/postgres/*.sql")
+	if err != nil {
+		return fmt.Errorf("failed to list migrations: %w", err)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		version := strings.TrimSuffix(file[strings.LastIndex(file, "/")+1:], ".sql")
+
+		var applied bool
+		if err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, version).Scan(&applied); err != nil {
+			return fmt.Errorf("failed to check migration %s: %w", version, err)
+		}
+		if applied {
+			continue
+		}
+
+		script, err := postgresMigrations.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read migration %s: %w", version, err)
+		}
+		err = pgWithTransaction(ctx, db, func(txCtx context.Context) error {
+			conn := pgConnFromContext(txCtx, db)
+			if _, err := conn.ExecContext(txCtx, string(script)); err != nil {
+				return err
+			}
+			_, err := conn.ExecContext(txCtx, `INSERT INTO schema_migrations (version, applied_at) VALUES ($1, $2)`, version, time.Now())
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %s: %w", version, err)
+		}
+		log.Printf("[PostgreSQL] Applied migration %s", version)
+	}
+	return nil
+}
+
+// pgConn is what the PostgreSQL repositories run statements on, the database or a transaction
+type pgConn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type pgTxKey struct{}
+
+// pgConnFromContext returns the transaction of ctx, or db outside of one
+func pgConnFromContext(ctx context.Context, db *sql.DB) pgConn {
+	if tx, ok := ctx.Value(pgTxKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// pgWithTransaction runs fn in a transaction of db, or in the one of ctx when there is one
+func pgWithTransaction(ctx context.Context, db *sql.DB, fn func(txCtx context.Context) error) error {
+	if _, ok := ctx.Value(pgTxKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := fn(context.WithValue(ctx, pgTxKey{}, tx)); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			log.Printf("[PostgreSQL] Failed to roll back transaction: %v", rollbackErr)
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// pgFilter builds the WHERE clause of a statement with numbered parameters
+type pgFilter struct {
+	conditions []string
+	args       []any
+}
+
+// eq adds column = value
+func (f *pgFilter) eq(column string, value any) {
+	f.args = append(f.args, value)
+	f.conditions = append(f.conditions, fmt.Sprintf("%s = $%d", column, len(f.args)))
+}
+
+// in adds column IN (values...); no values match nothing
+func (f *pgFilter) in(column string, values []string) {
+	if len(values) == 0 {
+		f.conditions = append(f.conditions, "FALSE")
+		return
+	}
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		f.args = append(f.args, value)
+		placeholders[i] = fmt.Sprintf("$%d", len(f.args))
+	}
+	f.conditions = append(f.conditions, fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", ")))
+}
+
+// where adds a condition whose ? placeholders take args in order
+func (f *pgFilter) where(condition string, args ...any) {
+	for _, arg := range args {
+		f.args = append(f.args, arg)
+		condition = strings.Replace(condition, "?", fmt.Sprintf("$%d", len(f.args)), 1)
+	}
+	f.conditions = append(f.conditions, condition)
+}
+
+// clause returns the WHERE clause, empty without conditions
+func (f *pgFilter) clause() string {
+	if len(f.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(f.conditions, " AND ")
+}
+
+// toDocument encodes v as the MongoDB extended JSON the document columns hold, so the documents
+// keep their BSON field names, including the ones hidden from the API
+func toDocument(v any) (string, error) {
+	data, err := bson.MarshalExtJSON(v, false, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode document: %w", err)
+	}
+	return string(data), nil
+}
+
+// fromDocument decodes a document column into v
+func fromDocument(data []byte, v any) error {
+	if err := bson.UnmarshalExtJSON(data, false, v); err != nil {
+		return fmt.Errorf("failed to decode document: %w", err)
+	}
+	return nil
+}
+
+// queryDocuments decodes the document column of the rows selected by query
+func queryDocuments[T any](ctx context.Context, conn pgConn, query string, args ...any) ([]*T, error) {
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var documents []*T
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		document := new(T)
+		if err := fromDocument(data, document); err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+	return documents, rows.Err()
+}
+
+// queryDocument decodes the document column of the first row selected by query, nil without one
+func queryDocument[T any](ctx context.Context, conn pgConn, query string, args ...any) (*T, error) {
+	documents, err := queryDocuments[T](ctx, conn, query+" LIMIT 1", args...)
+	if err != nil || len(documents) == 0 {
+		return nil, err
+	}
+	return documents[0], nil
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// PostgresAppointmentRepository implements AppointmentRepository using PostgreSQL, with the same
+// tenant scoping as MongoDBAppointmentRepository
+type PostgresAppointmentRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresAppointmentRepository creates a new PostgreSQL appointment repository on a migrated database
+func NewPostgresAppointmentRepository(db *sql.DB) *PostgresAppointmentRepository {
+	return &PostgresAppointmentRepository{db: db}
+}
+
+// appointmentTenantPgFilter limits a query to the tenant in the context
+func appointmentTenantPgFilter(ctx context.Context) *pgFilter {
+	f := &pgFilter{}
+	buildingID, sectionID, err := types.ParseTenantID(getTenantIDFromContext(ctx))
+	if err != nil {
+		return f
+	}
+	f.eq("tenant_id", buildingID)
+	if sectionID != "" {
+		f.eq("section_id", sectionID)
+	}
+	return f
+}
+
+func (r *PostgresAppointmentRepository) UpsertAppointment(ctx context.Context, appointment *types.Appointment) error {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+
+	return pgWithTransaction(ctx, r.db, func(txCtx context.Context) error {
+		conn := pgConnFromContext(txCtx, r.db)
+		f := appointmentTenantPgFilter(ctx)
+		f.eq("external_id", appointment.ExternalID)
+		existing, err := queryDocument[types.Appointment](txCtx, conn, "SELECT document FROM appointments"+f.clause()+" FOR UPDATE", f.args...)
+		if err != nil {
+			return fmt.Errorf("failed to get appointment: %w", err)
+		}
+
+		now := time.Now()
+		existingStatus := ""
+		if existing != nil {
+			existingStatus = existing.Status
+		}
+		appointment.TenantID = buildingID
+		appointment.SectionID = sectionID
+		appointment.Status = upsertedAppointmentStatus(appointment.Status, existingStatus)
+		appointment.UpdatedAt = now
+		if existing != nil {
+			appointment.ID = existing.ID
+			appointment.EntryID = existing.EntryID
+			appointment.CreatedAt = existing.CreatedAt
+		} else {
+			appointment.ID = uuid.New().String()
+			appointment.EntryID = ""
+			appointment.CreatedAt = now
+		}
+
+		if err := r.save(txCtx, appointment); err != nil {
+			return fmt.Errorf("failed to upsert appointment: %w", err)
+		}
+		return nil
+	})
+}
+
+// save creates or replaces an appointment
+func (r *PostgresAppointmentRepository) save(ctx context.Context, appointment *types.Appointment) error {
+	document, err := toDocument(appointment)
+	if err != nil {
+		return err
+	}
+	_, err = pgConnFromContext(ctx, r.db).ExecContext(ctx, `INSERT INTO appointments (id, tenant_id, section_id, external_id, identifier, status, appointment_time, document)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO UPDATE SET external_id = EXCLUDED.external_id, identifier = EXCLUDED.identifier, status = EXCLUDED.status,
+			appointment_time = EXCLUDED.appointment_time, document = EXCLUDED.document`,
+		appointment.ID, appointment.TenantID, appointment.SectionID, appointment.ExternalID, appointment.Identifier,
+		appointment.Status, appointment.AppointmentTime, document)
+	return err
+}
+
+func (r *PostgresAppointmentRepository) GetAppointments(ctx context.Context, from, to time.Time) ([]*types.Appointment, error) {
+	f := appointmentTenantPgFilter(ctx)
+	f.where("appointment_time >= ? AND appointment_time < ?", from, to)
+	return r.find(ctx, f)
+}
+
+func (r *PostgresAppointmentRepository) GetScheduledAppointments(ctx context.Context, identifier string, from, to time.Time) ([]*types.Appointment, error) {
+	f := appointmentTenantPgFilter(ctx)
+	f.eq("identifier", identifier)
+	f.eq("status", "SCHEDULED")
+	f.where("appointment_time >= ? AND appointment_time <= ?", from, to)
+	return r.find(ctx, f)
+}
+
+func (r *PostgresAppointmentRepository) GetAppointmentByExternalID(ctx context.Context, externalId string) (*types.Appointment, error) {
+	f := appointmentTenantPgFilter(ctx)
+	f.eq("external_id", externalId)
+	appointment, err := queryDocument[types.Appointment](ctx, pgConnFromContext(ctx, r.db), "SELECT document FROM appointments"+f.clause(), f.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appointment: %w", err)
+	}
+	return appointment, nil
+}
+
+func (r *PostgresAppointmentRepository) find(ctx context.Context, f *pgFilter) ([]*types.Appointment, error) {
+	appointments, err := queryDocuments[types.Appointment](ctx, pgConnFromContext(ctx, r.db), "SELECT document FROM appointments"+f.clause()+" ORDER BY appointment_time", f.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find appointments: %w", err)
+	}
+	return appointments, nil
+}
+
+func (r *PostgresAppointmentRepository) MarkAppointmentCheckedIn(ctx context.Context, id, entryId string) error {
+	return pgWithTransaction(ctx, r.db, func(txCtx context.Context) error {
+		appointment, err := queryDocument[types.Appointment](txCtx, pgConnFromContext(txCtx, r.db), "SELECT document FROM appointments WHERE id = $1 FOR UPDATE", id)
+		if err != nil {
+			return fmt.Errorf("failed to mark appointment as checked in: %w", err)
+		}
+		if appointment == nil {
+			return fmt.Errorf("appointment not found")
+		}
+		appointment.Status = "CHECKED_IN"
+		appointment.EntryID = entryId
+		appointment.UpdatedAt = time.Now()
+		if err := r.save(txCtx, appointment); err != nil {
+			return fmt.Errorf("failed to mark appointment as checked in: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *PostgresAppointmentRepository) DeleteTenantAppointments(ctx context.Context) (int64, error) {
+	f := appointmentTenantPgFilter(ctx)
+	if len(f.conditions) == 0 {
+		return 0, fmt.Errorf("a tenant is required to delete its appointments")
+	}
+	result, err := pgConnFromContext(ctx, r.db).ExecContext(ctx, "DELETE FROM appointments"+f.clause(), f.args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete appointments of tenant: %w", err)
+	}
+	return result.RowsAffected()
+}
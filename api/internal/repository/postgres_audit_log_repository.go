@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// PostgresAuditLogRepository implements AuditLogRepository using PostgreSQL. Like the MongoDB
+// audit log it is append-only.
+type PostgresAuditLogRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresAuditLogRepository creates a new PostgreSQL audit log on a migrated database
+func NewPostgresAuditLogRepository(db *sql.DB) *PostgresAuditLogRepository {
+	return &PostgresAuditLogRepository{db: db}
+}
+
+func (r *PostgresAuditLogRepository) AppendAuditEntry(ctx context.Context, entry *types.AuditEntry) error {
+	document, err := toDocument(entry)
+	if err != nil {
+		return err
+	}
+	_, err = pgConnFromContext(ctx, r.db).ExecContext(ctx, `INSERT INTO audit_log (id, tenant_id, section_id, actor, resource, action, created_at, document)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		entry.ID, entry.TenantID, entry.SectionID, entry.Actor, entry.Resource, entry.Action, entry.CreatedAt, document)
+	if err != nil {
+		return fmt.Errorf("failed to append audit entry: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresAuditLogRepository) GetAuditEntries(ctx context.Context, auditFilter types.AuditFilter, limit int) ([]*types.AuditEntry, error) {
+	f := &pgFilter{}
+	tenantFilter(ctx, f)
+	if auditFilter.Resource != "" {
+		f.eq("resource", auditFilter.Resource)
+	}
+	if auditFilter.Action != "" {
+		f.eq("action", auditFilter.Action)
+	}
+	if auditFilter.Actor != "" {
+		f.eq("actor", auditFilter.Actor)
+	}
+	if !auditFilter.From.IsZero() {
+		f.where("created_at >= ?", auditFilter.From)
+	}
+	if !auditFilter.To.IsZero() {
+		f.where("created_at < ?", auditFilter.To)
+	}
+
+	query := "SELECT document FROM audit_log" + f.clause() + fmt.Sprintf(" ORDER BY created_at DESC LIMIT %d", limit)
+	rows, err := pgConnFromContext(ctx, r.db).QueryContext(ctx, query, f.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*types.AuditEntry
+	for rows.Next() {
+		var document []byte
+		if err := rows.Scan(&document); err != nil {
+			return nil, fmt.Errorf("failed to read audit entry: %w", err)
+		}
+		var entry types.AuditEntry
+		if err := fromAuditDocument(document, &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, rows.Err()
+}
+
+// fromAuditDocument decodes an audit entry like fromDocument, with the values of the changes
+// decoded as maps, not bson.D, so they serialize to JSON as stored
+func fromAuditDocument(data []byte, entry *types.AuditEntry) error {
+	reader, err := bsonrw.NewExtJSONValueReader(bytes.NewReader(data), false)
+	if err != nil {
+		return fmt.Errorf("failed to decode document: %w", err)
+	}
+	decoder, err := bson.NewDecoder(reader)
+	if err != nil {
+		return fmt.Errorf("failed to decode document: %w", err)
+	}
+	decoder.DefaultDocumentM()
+	if err := decoder.Decode(entry); err != nil {
+		return fmt.Errorf("failed to decode document: %w", err)
+	}
+	return nil
+}
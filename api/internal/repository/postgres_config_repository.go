@@ -0,0 +1,555 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// PostgresConfigRepository implements ConfigRepository using PostgreSQL, with the same tenant
+// scoping as MongoDBConfigRepository
+type PostgresConfigRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresConfigRepository creates a new PostgreSQL config repository on a migrated database
+func NewPostgresConfigRepository(db *sql.DB) *PostgresConfigRepository {
+	return &PostgresConfigRepository{db: db}
+}
+
+// getDocument decodes the document selected by query into v and reports whether there was one
+func (r *PostgresConfigRepository) getDocument(ctx context.Context, v any, query string, args ...any) (bool, error) {
+	var document []byte
+	err := pgConnFromContext(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&document)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, fromDocument(document, v)
+}
+
+// System configuration management methods
+func (r *PostgresConfigRepository) GetSystemConfiguration(ctx context.Context) (*types.SystemConfiguration, error) {
+	// Configurations are stored per building and section; legacy/system configs have neither
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+
+	var config types.SystemConfiguration
+	found, err := r.getDocument(ctx, &config, `SELECT document FROM system_configurations WHERE tenant_id = $1 AND section_id = $2`, buildingID, sectionID)
+	if err != nil {
+		log.Printf("[ConfigRepository] Error retrieving system configuration from PostgreSQL: %v", err)
+		return nil, err
+	}
+	if !found {
+		log.Printf("[ConfigRepository] No system configuration found in PostgreSQL for buildingId: %s, sectionId: %s", buildingID, sectionID)
+		return nil, nil
+	}
+	return &config, nil
+}
+
+func (r *PostgresConfigRepository) SetSystemConfiguration(ctx context.Context, config *types.SystemConfiguration) error {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+
+	updates := map[string]interface{}{
+		"externalAPI":   config.ExternalAPI,
+		"rooms":         config.Rooms,
+		"defaultRoom":   config.DefaultRoom,
+		"webSocketPath": config.WebSocketPath,
+		"allowWildcard": config.AllowWildcard,
+	}
+	if err := r.UpdateSystemConfiguration(ctx, updates); err != nil {
+		return err
+	}
+
+	config.TenantID = buildingID
+	config.SectionID = sectionID
+	config.UpdatedAt = updates["updatedAt"].(time.Time)
+	return nil
+}
+
+func (r *PostgresConfigRepository) UpdateSystemConfiguration(ctx context.Context, updates map[string]interface{}) error {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+
+	now := time.Now()
+	updates["tenantId"] = buildingID
+	updates["sectionId"] = sectionID
+	updates["updatedAt"] = now
+
+	return pgWithTransaction(ctx, r.db, func(txCtx context.Context) error {
+		// Like a MongoDB $set, the updates replace single fields of the stored document
+		document := bson.M{}
+		found, err := r.getDocument(txCtx, &document, `SELECT document FROM system_configurations WHERE tenant_id = $1 AND section_id = $2 FOR UPDATE`, buildingID, sectionID)
+		if err != nil {
+			return fmt.Errorf("failed to read system configuration: %w", err)
+		}
+		if !found {
+			document["_id"] = buildingID + ":" + sectionID
+			document["createdAt"] = now
+		}
+		for key, value := range updates {
+			document[key] = value
+		}
+
+		encoded, err := toDocument(document)
+		if err != nil {
+			return err
+		}
+		_, err = pgConnFromContext(txCtx, r.db).ExecContext(txCtx, `INSERT INTO system_configurations (tenant_id, section_id, document) VALUES ($1, $2, $3)
+			ON CONFLICT (tenant_id, section_id) DO UPDATE SET document = EXCLUDED.document`, buildingID, sectionID, encoded)
+		if err != nil {
+			log.Printf("Failed to update PostgreSQL configuration: %v", err)
+			return err
+		}
+		log.Printf("Updated PostgreSQL configuration for buildingId: %s, sectionId: %s", buildingID, sectionID)
+		return nil
+	})
+}
+
+// GetSystemConfigurationsUpdatedSince filters in Go, the update time is only kept in the
+// document and there is one configuration per tenant
+func (r *PostgresConfigRepository) GetSystemConfigurationsUpdatedSince(ctx context.Context, since time.Time) ([]*types.SystemConfiguration, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT document FROM system_configurations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var configs []*types.SystemConfiguration
+	for rows.Next() {
+		var document []byte
+		if err := rows.Scan(&document); err != nil {
+			return nil, err
+		}
+		var config types.SystemConfiguration
+		if err := fromDocument(document, &config); err != nil {
+			return nil, err
+		}
+		if config.UpdatedAt.After(since) {
+			configs = append(configs, &config)
+		}
+	}
+	return configs, rows.Err()
+}
+
+// Card reader management methods
+func (r *PostgresConfigRepository) GetCardReaderStatus(ctx context.Context, id string) (*types.CardReaderStatus, error) {
+	f := &pgFilter{}
+	f.eq("id", id)
+	if tenantID := getTenantIDFromContext(ctx); tenantID != "" {
+		f.eq("tenant_id", tenantID)
+	}
+
+	var status types.CardReaderStatus
+	found, err := r.getDocument(ctx, &status, "SELECT document FROM card_readers"+f.clause()+" LIMIT 1", f.args...)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (r *PostgresConfigRepository) SetCardReaderStatus(ctx context.Context, status *types.CardReaderStatus) error {
+	// Extract tenant ID from context and set it on the status
+	if tenantID := getTenantIDFromContext(ctx); tenantID != "" {
+		status.TenantID = tenantID
+	}
+
+	return pgWithTransaction(ctx, r.db, func(txCtx context.Context) error {
+		var existing types.CardReaderStatus
+		found, err := r.getDocument(txCtx, &existing, `SELECT document FROM card_readers WHERE id = $1 AND tenant_id = $2 FOR UPDATE`, status.ID, status.TenantID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		status.CreatedAt = now
+		if found {
+			status.CreatedAt = existing.CreatedAt
+		}
+		status.UpdatedAt = now
+		return r.saveCardReader(txCtx, status)
+	})
+}
+
+// saveCardReader creates or replaces the stored status of a card reader
+func (r *PostgresConfigRepository) saveCardReader(ctx context.Context, status *types.CardReaderStatus) error {
+	document, err := toDocument(status)
+	if err != nil {
+		return err
+	}
+	_, err = pgConnFromContext(ctx, r.db).ExecContext(ctx, `INSERT INTO card_readers (id, tenant_id, document) VALUES ($1, $2, $3)
+		ON CONFLICT (id, tenant_id) DO UPDATE SET document = EXCLUDED.document`, status.ID, status.TenantID, document)
+	return err
+}
+
+func (r *PostgresConfigRepository) GetAllCardReaders(ctx context.Context) ([]types.CardReaderStatus, error) {
+	f := &pgFilter{}
+	if tenantID := getTenantIDFromContext(ctx); tenantID != "" {
+		f.eq("tenant_id", tenantID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, "SELECT document FROM card_readers"+f.clause(), f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var readers []types.CardReaderStatus
+	for rows.Next() {
+		var document []byte
+		if err := rows.Scan(&document); err != nil {
+			return nil, err
+		}
+		var reader types.CardReaderStatus
+		if err := fromDocument(document, &reader); err != nil {
+			return nil, err
+		}
+		readers = append(readers, reader)
+	}
+	return readers, rows.Err()
+}
+
+func (r *PostgresConfigRepository) UpdateCardReaderLastSeen(ctx context.Context, id string) error {
+	return pgWithTransaction(ctx, r.db, func(txCtx context.Context) error {
+		f := &pgFilter{}
+		f.eq("id", id)
+		if tenantID := getTenantIDFromContext(txCtx); tenantID != "" {
+			f.eq("tenant_id", tenantID)
+		}
+
+		var status types.CardReaderStatus
+		found, err := r.getDocument(txCtx, &status, "SELECT document FROM card_readers"+f.clause()+" LIMIT 1 FOR UPDATE", f.args...)
+		if err != nil || !found {
+			return err
+		}
+		status.LastSeen = time.Now()
+		status.Status = "online"
+		status.UpdatedAt = status.LastSeen
+		return r.saveCardReader(txCtx, &status)
+	})
+}
+
+func (r *PostgresConfigRepository) DeleteCardReader(ctx context.Context, id string) error {
+	f := &pgFilter{}
+	f.eq("id", id)
+	if tenantID := getTenantIDFromContext(ctx); tenantID != "" {
+		f.eq("tenant_id", tenantID)
+	}
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM card_readers"+f.clause(), f.args...)
+	return err
+}
+
+// GetCardReaderConfig retrieves the remote configuration for a card reader device.
+// Devices usually pull their configuration before they know their tenant, so the
+// tenant filter is only applied when the request carries one.
+func (r *PostgresConfigRepository) GetCardReaderConfig(ctx context.Context, id string) (*types.CardReaderConfig, error) {
+	f := &pgFilter{}
+	f.eq("id", id)
+	if tenantID := getTenantIDFromContext(ctx); tenantID != "" {
+		f.eq("tenant_id", tenantID)
+	}
+
+	var config types.CardReaderConfig
+	found, err := r.getDocument(ctx, &config, "SELECT document FROM card_reader_configs"+f.clause(), f.args...)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (r *PostgresConfigRepository) SetCardReaderConfig(ctx context.Context, config *types.CardReaderConfig) error {
+	// Readers without an explicit tenant inherit the tenant of the admin request
+	if config.TenantID == "" {
+		config.TenantID = getTenantIDFromContext(ctx)
+	}
+	config.UpdatedAt = time.Now()
+
+	document, err := toDocument(config)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO card_reader_configs (id, tenant_id, document) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET tenant_id = EXCLUDED.tenant_id, document = EXCLUDED.document`
+	tenantID := getTenantIDFromContext(ctx)
+	if tenantID != "" {
+		if config.TenantID != tenantID {
+			return fmt.Errorf("%w: %s is saved for tenant %s", ErrCardReaderOtherTenant, config.DeviceID, config.TenantID)
+		}
+		// A reader of another tenant is left alone, and no row is affected
+		query += " WHERE card_reader_configs.tenant_id = EXCLUDED.tenant_id"
+	}
+	result, err := r.db.ExecContext(ctx, query, config.DeviceID, config.TenantID, document)
+	if err != nil {
+		return err
+	}
+	if tenantID != "" {
+		if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+			return fmt.Errorf("%w: %s", ErrCardReaderOtherTenant, config.DeviceID)
+		}
+	}
+
+	log.Printf("[ConfigRepository] Saved card reader config for device %s (tenant: %s, room: %s)", config.DeviceID, config.TenantID, config.RoomID)
+	return nil
+}
+
+func (r *PostgresConfigRepository) GetCardReaderConfigsByRoom(ctx context.Context, roomID string) ([]types.CardReaderConfig, error) {
+	f := &pgFilter{}
+	f.eq("tenant_id", getTenantIDFromContext(ctx))
+	f.where("document->>'roomId' = ?", roomID)
+
+	rows, err := r.db.QueryContext(ctx, "SELECT document FROM card_reader_configs"+f.clause(), f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var configs []types.CardReaderConfig
+	for rows.Next() {
+		var document []byte
+		if err := rows.Scan(&document); err != nil {
+			return nil, err
+		}
+		var config types.CardReaderConfig
+		if err := fromDocument(document, &config); err != nil {
+			return nil, err
+		}
+		configs = append(configs, config)
+	}
+	return configs, rows.Err()
+}
+
+// GetCardReaderRelease returns the current card-reader release for an os/arch.
+// Releases are global, the same binary serves every tenant.
+func (r *PostgresConfigRepository) GetCardReaderRelease(ctx context.Context, os, arch string) (*types.CardReaderRelease, error) {
+	var release types.CardReaderRelease
+	found, err := r.getDocument(ctx, &release, `SELECT document FROM card_reader_releases WHERE os = $1 AND arch = $2`, os, arch)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &release, nil
+}
+
+func (r *PostgresConfigRepository) SetCardReaderRelease(ctx context.Context, release *types.CardReaderRelease) error {
+	release.PublishedAt = time.Now()
+
+	document, err := toDocument(release)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `INSERT INTO card_reader_releases (os, arch, document) VALUES ($1, $2, $3)
+		ON CONFLICT (os, arch) DO UPDATE SET document = EXCLUDED.document`, release.OS, release.Arch, document)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[ConfigRepository] Published card reader release %s for %s/%s", release.Version, release.OS, release.Arch)
+	return nil
+}
+
+func (r *PostgresConfigRepository) SaveCardReaderCommand(ctx context.Context, command *types.CardReaderCommand) error {
+	if command.TenantID == "" {
+		command.TenantID = getTenantIDFromContext(ctx)
+	}
+	document, err := toDocument(command)
+	if err != nil {
+		return err
+	}
+	_, err = pgConnFromContext(ctx, r.db).ExecContext(ctx, `INSERT INTO card_reader_commands (id, device_id, tenant_id, status, created_at, document) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO UPDATE SET status = EXCLUDED.status, document = EXCLUDED.document`, command.ID, command.DeviceID, command.TenantID, command.Status, command.CreatedAt, document)
+	return err
+}
+
+func (r *PostgresConfigRepository) GetCardReaderCommand(ctx context.Context, deviceID, id string) (*types.CardReaderCommand, error) {
+	f := &pgFilter{}
+	f.eq("id", id)
+	f.eq("device_id", deviceID)
+	if tenantID := getTenantIDFromContext(ctx); tenantID != "" {
+		f.eq("tenant_id", tenantID)
+	}
+
+	var command types.CardReaderCommand
+	found, err := r.getDocument(ctx, &command, "SELECT document FROM card_reader_commands"+f.clause(), f.args...)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &command, nil
+}
+
+func (r *PostgresConfigRepository) GetCardReaderCommands(ctx context.Context, deviceID string, statuses []string, limit int) ([]types.CardReaderCommand, error) {
+	f := &pgFilter{}
+	f.eq("device_id", deviceID)
+	if tenantID := getTenantIDFromContext(ctx); tenantID != "" {
+		f.eq("tenant_id", tenantID)
+	}
+	if len(statuses) > 0 {
+		f.in("status", statuses)
+	}
+	query := "SELECT document FROM card_reader_commands" + f.clause() + " ORDER BY created_at DESC"
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	commands := []types.CardReaderCommand{}
+	for rows.Next() {
+		var document []byte
+		if err := rows.Scan(&document); err != nil {
+			return nil, err
+		}
+		var command types.CardReaderCommand
+		if err := fromDocument(document, &command); err != nil {
+			return nil, err
+		}
+		commands = append(commands, command)
+	}
+	return commands, rows.Err()
+}
+
+// Tenant management methods
+func (r *PostgresConfigRepository) CreateTenant(ctx context.Context, tenant *types.Tenant) error {
+	now := time.Now()
+	tenantID := tenant.GetTenantID()
+	tenant.ID = tenantID
+	tenant.CreatedAt = now
+	tenant.UpdatedAt = now
+
+	err := pgWithTransaction(ctx, r.db, func(txCtx context.Context) error {
+		// Creating an existing tenant updates it and keeps its creation time
+		existing, err := r.GetTenant(txCtx, tenantID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			tenant.CreatedAt = existing.CreatedAt
+		}
+		return r.saveTenant(txCtx, tenant)
+	})
+	if err != nil {
+		log.Printf("Failed to create tenant in PostgreSQL: %v", err)
+		return err
+	}
+	log.Printf("Created tenant with ID: %s", tenantID)
+	return nil
+}
+
+// saveTenant creates or replaces a stored tenant
+func (r *PostgresConfigRepository) saveTenant(ctx context.Context, tenant *types.Tenant) error {
+	document, err := toDocument(tenant)
+	if err != nil {
+		return err
+	}
+	_, err = pgConnFromContext(ctx, r.db).ExecContext(ctx, `INSERT INTO tenants (id, document) VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET document = EXCLUDED.document`, tenant.ID, document)
+	return err
+}
+
+func (r *PostgresConfigRepository) GetTenant(ctx context.Context, tenantID string) (*types.Tenant, error) {
+	var tenant types.Tenant
+	found, err := r.getDocument(ctx, &tenant, `SELECT document FROM tenants WHERE id = $1`, tenantID)
+	if err != nil {
+		log.Printf("Error retrieving tenant from PostgreSQL: %v", err)
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	return &tenant, nil
+}
+
+func (r *PostgresConfigRepository) GetAllTenants(ctx context.Context) ([]types.Tenant, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT document FROM tenants ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Ensure we return an empty slice instead of nil
+	tenants := []types.Tenant{}
+	for rows.Next() {
+		var document []byte
+		if err := rows.Scan(&document); err != nil {
+			return nil, err
+		}
+		var tenant types.Tenant
+		if err := fromDocument(document, &tenant); err != nil {
+			log.Printf("Error retrieving all tenants from PostgreSQL: %v", err)
+			return nil, err
+		}
+		tenants = append(tenants, tenant)
+	}
+	return tenants, rows.Err()
+}
+
+func (r *PostgresConfigRepository) UpdateTenant(ctx context.Context, tenant *types.Tenant) error {
+	tenantID := tenant.GetTenantID()
+	tenant.ID = tenantID
+	tenant.UpdatedAt = time.Now()
+
+	err := pgWithTransaction(ctx, r.db, func(txCtx context.Context) error {
+		existing, err := r.GetTenant(txCtx, tenantID)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return fmt.Errorf("tenant with ID %s not found", tenantID)
+		}
+		tenant.CreatedAt = existing.CreatedAt
+		return r.saveTenant(txCtx, tenant)
+	})
+	if err != nil {
+		log.Printf("Failed to update tenant in PostgreSQL: %v", err)
+		return err
+	}
+	log.Printf("Updated tenant with ID: %s", tenantID)
+	return nil
+}
+
+func (r *PostgresConfigRepository) DeleteTenant(ctx context.Context, tenantID string) error {
+	result, err := pgConnFromContext(ctx, r.db).ExecContext(ctx, `DELETE FROM tenants WHERE id = $1`, tenantID)
+	if err != nil {
+		log.Printf("Failed to delete tenant from PostgreSQL: %v", err)
+		return err
+	}
+	if deleted, err := result.RowsAffected(); err == nil && deleted == 0 {
+		return fmt.Errorf("tenant with ID %s not found", tenantID)
+	}
+	log.Printf("Deleted tenant with ID: %s", tenantID)
+	return nil
+}
+
+func (r *PostgresConfigRepository) ProvisionTenant(ctx context.Context, tenant *types.Tenant, updates map[string]interface{}) error {
+	return pgWithTransaction(ctx, r.db, func(txCtx context.Context) error {
+		if err := r.CreateTenant(txCtx, tenant); err != nil {
+			return err
+		}
+		return r.UpdateSystemConfiguration(context.WithValue(txCtx, middleware.TENANT, tenant.GetFullTenantID()), updates)
+	})
+}
+
+func (r *PostgresConfigRepository) PurgeTenant(ctx context.Context, tenant *types.Tenant) error {
+	return pgWithTransaction(ctx, r.db, func(txCtx context.Context) error {
+		conn := pgConnFromContext(txCtx, r.db)
+		if _, err := conn.ExecContext(txCtx, `DELETE FROM system_configurations WHERE tenant_id = $1 AND section_id = $2`, tenant.BuildingID, tenant.SectionID); err != nil {
+			return fmt.Errorf("failed to delete configuration of tenant: %w", err)
+		}
+		for _, table := range []string{"card_readers", "card_reader_configs", "card_reader_commands"} {
+			if _, err := conn.ExecContext(txCtx, "DELETE FROM "+table+" WHERE tenant_id IN ($1, $2)", tenant.GetTenantID(), tenant.GetFullTenantID()); err != nil {
+				return fmt.Errorf("failed to delete %s of tenant: %w", table, err)
+			}
+		}
+		return r.DeleteTenant(txCtx, tenant.GetTenantID())
+	})
+}
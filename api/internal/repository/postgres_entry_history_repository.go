@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// PostgresEntryHistoryRepository implements EntryHistoryRepository using PostgreSQL
+type PostgresEntryHistoryRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresEntryHistoryRepository creates a new PostgreSQL entry history repository on a migrated database
+func NewPostgresEntryHistoryRepository(db *sql.DB) *PostgresEntryHistoryRepository {
+	return &PostgresEntryHistoryRepository{db: db}
+}
+
+func (r *PostgresEntryHistoryRepository) AppendEntryHistory(ctx context.Context, event *types.EntryHistoryEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	document, err := toDocument(event)
+	if err != nil {
+		return err
+	}
+	_, err = pgConnFromContext(ctx, r.db).ExecContext(ctx, `INSERT INTO entry_history (id, entry_id, tenant_id, section_id, timestamp, document) VALUES ($1, $2, $3, $4, $5, $6)`,
+		event.ID, event.EntryID, event.TenantID, event.SectionID, event.Timestamp, document)
+	if err != nil {
+		return fmt.Errorf("failed to append entry history: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresEntryHistoryRepository) GetEntryHistory(ctx context.Context, entryId string) ([]*types.EntryHistoryEvent, error) {
+	f := &pgFilter{}
+	f.eq("entry_id", entryId)
+	tenantFilter(ctx, f)
+
+	events, err := queryDocuments[types.EntryHistoryEvent](ctx, pgConnFromContext(ctx, r.db), "SELECT document FROM entry_history"+f.clause()+" ORDER BY timestamp", f.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find entry history: %w", err)
+	}
+	return events, nil
+}
+
+func (r *PostgresEntryHistoryRepository) DeleteEntryHistory(ctx context.Context, entryIds []string) (int64, error) {
+	if len(entryIds) == 0 {
+		return 0, nil
+	}
+	f := &pgFilter{}
+	f.in("entry_id", entryIds)
+	result, err := pgConnFromContext(ctx, r.db).ExecContext(ctx, "DELETE FROM entry_history"+f.clause(), f.args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete entry history: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,993 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/types"
+	"github.com/google/uuid"
+)
+
+// PostgresQueueRepository implements QueueRepository using PostgreSQL. Entries are stored as
+// documents next to the columns they are filtered by; rooms are ordered in memory with the same
+// QueueOrderingStrategy as the other repositories.
+type PostgresQueueRepository struct {
+	db               *sql.DB
+	orderingResolver QueueOrderingResolver
+	cardDataCipher   CardDataCipher
+}
+
+// NewPostgresQueueRepository creates a new PostgreSQL queue repository on a migrated database
+func NewPostgresQueueRepository(db *sql.DB) *PostgresQueueRepository {
+	return &PostgresQueueRepository{db: db}
+}
+
+// SetOrderingResolver sets where the ordering strategy of a room comes from (the priority order without one)
+func (r *PostgresQueueRepository) SetOrderingResolver(resolver QueueOrderingResolver) {
+	r.orderingResolver = resolver
+}
+
+// SetCardDataCipher makes the repository encrypt the personal fields of card data at rest
+func (r *PostgresQueueRepository) SetCardDataCipher(cipher CardDataCipher) {
+	r.cardDataCipher = cipher
+}
+
+// conn returns the transaction of ctx, or the database outside of one
+func (r *PostgresQueueRepository) conn(ctx context.Context) pgConn {
+	return pgConnFromContext(ctx, r.db)
+}
+
+// tenantFilter restricts f to the tenant and section in the context, when there are any
+func tenantFilter(ctx context.Context, f *pgFilter) {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	if buildingID != "" {
+		f.eq("tenant_id", buildingID)
+	}
+	if sectionID != "" {
+		f.eq("section_id", sectionID)
+	}
+}
+
+// roomFilter matches the entries of a room within the tenant in the context
+func roomFilter(ctx context.Context, roomId string) *pgFilter {
+	f := &pgFilter{}
+	f.eq("waiting_room_id", roomId)
+	tenantFilter(ctx, f)
+	return f
+}
+
+const entryColumns = "id, waiting_room_id, tenant_id, section_id, status, service_point, qr_token, idempotency_key, version, created_at, updated_at, document"
+
+// entryValues returns the values of entryColumns for entry
+func (r *PostgresQueueRepository) entryValues(ctx context.Context, entry *types.Entry) ([]any, error) {
+	document, err := r.document(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+	return []any{
+		entry.ID, entry.WaitingRoomID, entry.TenantID, entry.SectionID, entry.Status, entry.ServicePoint,
+		entry.QRToken, entry.IdempotencyKey, entry.Version, entry.CreatedAt, entry.UpdatedAt, document,
+	}, nil
+}
+
+// document returns the document column of entry, with encrypted card data
+func (r *PostgresQueueRepository) document(ctx context.Context, entry *types.Entry) (string, error) {
+	encrypted, err := encryptedEntry(ctx, r.cardDataCipher, entry)
+	if err != nil {
+		return "", err
+	}
+	return toDocument(encrypted)
+}
+
+// findEntries returns the entries matching f, with suffix (ORDER BY, LIMIT, FOR UPDATE) appended
+func (r *PostgresQueueRepository) findEntries(ctx context.Context, f *pgFilter, suffix string) ([]*types.Entry, error) {
+	return r.findDocuments(ctx, "queue_entries", f, suffix)
+}
+
+// findDocuments returns the entries of table (queue_entries or queue_entries_archive) matching f
+func (r *PostgresQueueRepository) findDocuments(ctx context.Context, table string, f *pgFilter, suffix string) ([]*types.Entry, error) {
+	rows, err := r.conn(ctx).QueryContext(ctx, "SELECT document FROM "+table+f.clause()+suffix, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*types.Entry
+	for rows.Next() {
+		var document []byte
+		if err := rows.Scan(&document); err != nil {
+			return nil, fmt.Errorf("failed to read queue entry: %w", err)
+		}
+		var entry types.Entry
+		if err := fromDocument(document, &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := decryptEntries(ctx, r.cardDataCipher, entries...); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// findEntry returns the first entry matching f, or nil
+func (r *PostgresQueueRepository) findEntry(ctx context.Context, f *pgFilter, suffix string) (*types.Entry, error) {
+	entries, err := r.findEntries(ctx, f, suffix+" LIMIT 1")
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return entries[0], nil
+}
+
+// findOrdered returns the entries matching f in the order of the room, at most limit (0 = all)
+func (r *PostgresQueueRepository) findOrdered(ctx context.Context, roomId string, f *pgFilter, limit int) ([]*types.Entry, error) {
+	entries, err := r.findEntries(ctx, f, "")
+	if err != nil {
+		return nil, err
+	}
+	ordering := resolveOrdering(ctx, r.orderingResolver, roomId)
+	sort.SliceStable(entries, func(i, j int) bool { return ordering.Before(entries[i], entries[j]) })
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// saveEntry writes entry over the stored one
+func (r *PostgresQueueRepository) saveEntry(ctx context.Context, entry *types.Entry) error {
+	values, err := r.entryValues(ctx, entry)
+	if err != nil {
+		return err
+	}
+	_, err = r.conn(ctx).ExecContext(ctx, `UPDATE queue_entries SET waiting_room_id = $2, tenant_id = $3, section_id = $4,
+		status = $5, service_point = $6, qr_token = $7, idempotency_key = $8, version = $9, created_at = $10,
+		updated_at = $11, document = $12 WHERE id = $1`, values...)
+	return err
+}
+
+// updateEntry locks the entry with id, applies update to it and stores the result
+func (r *PostgresQueueRepository) updateEntry(ctx context.Context, id string, update func(entry *types.Entry) error) error {
+	return r.WithTransaction(ctx, func(txCtx context.Context) error {
+		f := &pgFilter{}
+		f.eq("id", id)
+		entry, err := r.findEntry(txCtx, f, " FOR UPDATE")
+		if err != nil {
+			return fmt.Errorf("failed to find queue entry: %w", err)
+		}
+		if entry == nil {
+			return fmt.Errorf("queue entry not found")
+		}
+		if err := update(entry); err != nil {
+			return err
+		}
+		return r.saveEntry(txCtx, entry)
+	})
+}
+
+// checkVersion returns ErrVersionConflict when entry is no longer at version
+func checkVersion(entry *types.Entry, version int64) error {
+	if entry.Version != version {
+		return fmt.Errorf("%w: expected version %d, entry is at %d", ErrVersionConflict, version, entry.Version)
+	}
+	return nil
+}
+
+// CreateEntry creates a new queue entry
+func (r *PostgresQueueRepository) CreateEntry(ctx context.Context, entry *types.Entry) error {
+	entry.CreatedAt = time.Now()
+	entry.UpdatedAt = entry.CreatedAt
+
+	// Generate ticket number and QR token if not set
+	if entry.TicketNumber == "" {
+		sequence, err := r.NextTicketSequence(ctx, entry.WaitingRoomID, entry.TenantID, entry.SectionID, "")
+		if err != nil {
+			return fmt.Errorf("failed to allocate ticket number: %w", err)
+		}
+		entry.TicketNumber = fmt.Sprintf("%s-%03d", strings.ToUpper(entry.WaitingRoomID), sequence)
+	}
+	if entry.QRToken == "" {
+		entry.QRToken = uuid.NewString()
+	}
+	if entry.ID == "" {
+		entry.ID = uuid.NewString()
+	}
+
+	values, err := r.entryValues(ctx, entry)
+	if err != nil {
+		return err
+	}
+	if _, err := r.conn(ctx).ExecContext(ctx, "INSERT INTO queue_entries ("+entryColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)", values...); err != nil {
+		return fmt.Errorf("failed to create queue entry: %w", err)
+	}
+
+	log.Printf("PostgreSQL: Created entry %s (ticket %s) for room %s", entry.ID, entry.TicketNumber, entry.WaitingRoomID)
+	return nil
+}
+
+// NextTicketSequence atomically increments and returns the ticket counter of a room, tenant and
+// section in a numbering period (empty = never reset); a new period starts at 1
+func (r *PostgresQueueRepository) NextTicketSequence(ctx context.Context, roomId, tenantId, sectionId, period string) (int64, error) {
+	id := strings.Join([]string{tenantId, sectionId, roomId, period}, ":")
+
+	var sequence int64
+	err := r.conn(ctx).QueryRowContext(ctx, `INSERT INTO ticket_counters (id, room_id, tenant_id, section_id, period, sequence, updated_at)
+		VALUES ($1, $2, $3, $4, $5, 1, $6)
+		ON CONFLICT (id) DO UPDATE SET sequence = ticket_counters.sequence + 1, updated_at = EXCLUDED.updated_at
+		RETURNING sequence`, id, roomId, tenantId, sectionId, period, time.Now()).Scan(&sequence)
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment ticket counter: %w", err)
+	}
+	return sequence, nil
+}
+
+// GetQueueEntries retrieves all queue entries for a room (filtered by tenant if provided)
+func (r *PostgresQueueRepository) GetQueueEntries(ctx context.Context, roomId string, states []string) ([]*types.Entry, error) {
+	f := roomFilter(ctx, roomId)
+	if len(states) > 0 {
+		f.in("status", states)
+	}
+
+	entries, err := r.findOrdered(ctx, roomId, f, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find queue entries: %w", err)
+	}
+	return entries, nil
+}
+
+// GetEntryByID retrieves a queue entry by ID
+func (r *PostgresQueueRepository) GetEntryByID(ctx context.Context, id string) (*types.Entry, error) {
+	f := &pgFilter{}
+	f.eq("id", id)
+	entry, err := r.findEntry(ctx, f, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find queue entry: %w", err)
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("queue entry not found")
+	}
+	return entry, nil
+}
+
+// GetEntryByQRToken retrieves a queue entry by QR token
+func (r *PostgresQueueRepository) GetEntryByQRToken(ctx context.Context, qrToken string) (*types.Entry, error) {
+	f := &pgFilter{}
+	f.eq("qr_token", qrToken)
+	entry, err := r.findEntry(ctx, f, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find queue entry: %w", err)
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("queue entry not found")
+	}
+	return entry, nil
+}
+
+// GetActiveEntryByIdempotencyKey gets the oldest WAITING, CALLED or IN_SERVICE entry (filtered by tenant)
+// created with one of the keys, or nil when there is none
+func (r *PostgresQueueRepository) GetActiveEntryByIdempotencyKey(ctx context.Context, keys []string) (*types.Entry, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	f := &pgFilter{}
+	f.in("idempotency_key", keys)
+	f.in("status", []string{"WAITING", "CALLED", "IN_SERVICE"})
+	tenantFilter(ctx, f)
+
+	entry, err := r.findEntry(ctx, f, " ORDER BY created_at")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find entry by idempotency key: %w", err)
+	}
+	return entry, nil
+}
+
+// UpdateEntryStatus updates the status of a queue entry still at version and increments the version
+func (r *PostgresQueueRepository) UpdateEntryStatus(ctx context.Context, id string, status string, version int64) error {
+	return r.updateEntryStatus(ctx, id, status, nil, version)
+}
+
+// UpdateEntryStatusAndServicePoint updates the status and service point of a queue entry still at
+// version in one write and increments the version
+func (r *PostgresQueueRepository) UpdateEntryStatusAndServicePoint(ctx context.Context, id string, status string, servicePoint string, version int64) error {
+	return r.updateEntryStatus(ctx, id, status, &servicePoint, version)
+}
+
+// updateEntryStatus sets status, and servicePoint when given, on the entry still at version
+func (r *PostgresQueueRepository) updateEntryStatus(ctx context.Context, id string, status string, servicePoint *string, version int64) error {
+	return r.updateEntry(ctx, id, func(entry *types.Entry) error {
+		if err := checkVersion(entry, version); err != nil {
+			return err
+		}
+		now := time.Now()
+		entry.Status = status
+		if servicePoint != nil {
+			entry.ServicePoint = *servicePoint
+		}
+		entry.Version++
+		entry.UpdatedAt = now
+		// The call and completion times feed the wait-time analytics
+		switch status {
+		case "CALLED":
+			entry.CalledAt = &now
+		case "COMPLETED":
+			entry.CompletedAt = &now
+		}
+		return nil
+	})
+}
+
+// UpdateEntryPosition updates the position of a queue entry still at version and increments the version
+func (r *PostgresQueueRepository) UpdateEntryPosition(ctx context.Context, id string, position int, version int64) error {
+	return r.updateEntry(ctx, id, func(entry *types.Entry) error {
+		if err := checkVersion(entry, version); err != nil {
+			return err
+		}
+		entry.Position = int64(position)
+		entry.Version++
+		entry.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+// GetNextWaitingEntry gets the next waiting entry for a room (filtered by tenant if provided)
+func (r *PostgresQueueRepository) GetNextWaitingEntry(ctx context.Context, roomId string) (*types.Entry, error) {
+	f := roomFilter(ctx, roomId)
+	f.eq("status", "WAITING")
+
+	// The first entry in the ordering strategy of the room
+	entries, err := r.findOrdered(ctx, roomId, f, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find next waiting entry: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return entries[0], nil
+}
+
+// GetCurrentServedEntry gets the currently served entry for a room (filtered by tenant if provided)
+func (r *PostgresQueueRepository) GetCurrentServedEntry(ctx context.Context, roomId string) (*types.Entry, error) {
+	f := roomFilter(ctx, roomId)
+	f.in("status", []string{"CALLED", "IN_SERVICE"})
+
+	entry, err := r.findEntry(ctx, f, " ORDER BY updated_at DESC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find current served entry: %w", err)
+	}
+	return entry, nil
+}
+
+// GetNextWaitingEntryForServicePoint gets the next waiting entry for a specific service point (filtered by tenant if provided)
+func (r *PostgresQueueRepository) GetNextWaitingEntryForServicePoint(ctx context.Context, roomId, servicePointId string) (*types.Entry, error) {
+	f := roomFilter(ctx, roomId)
+	f.eq("service_point", servicePointId)
+	f.eq("status", "WAITING")
+
+	// The first entry in the ordering strategy of the room
+	entries, err := r.findOrdered(ctx, roomId, f, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next waiting entry for service point: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return entries[0], nil
+}
+
+// GetCurrentServedEntryForServicePoint gets the currently served entry for a specific service point (filtered by tenant if provided)
+func (r *PostgresQueueRepository) GetCurrentServedEntryForServicePoint(ctx context.Context, roomId, servicePointId string) (*types.Entry, error) {
+	f := roomFilter(ctx, roomId)
+	f.eq("service_point", servicePointId)
+	f.in("status", []string{"CALLED", "IN_ROOM", "IN_SERVICE"})
+
+	entry, err := r.findEntry(ctx, f, " ORDER BY updated_at DESC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current served entry for service point: %w", err)
+	}
+	return entry, nil
+}
+
+// RecalculatePositions recalculates positions for all waiting entries in a room (filtered by tenant if provided)
+// Positions follow the ordering strategy of the room (by default tier, fitness score, arrival time and ticket number)
+func (r *PostgresQueueRepository) RecalculatePositions(ctx context.Context, roomId string) error {
+	f := roomFilter(ctx, roomId)
+	f.eq("status", "WAITING")
+
+	entries, err := r.findOrdered(ctx, roomId, f, 0)
+	if err != nil {
+		return fmt.Errorf("failed to find waiting entries: %w", err)
+	}
+
+	for i, entry := range entries {
+		newPosition := i + 1
+		if entry.Position != int64(newPosition) {
+			if err := r.UpdateEntryPosition(ctx, entry.ID, newPosition, entry.Version); err != nil {
+				// The entry changed since it was read; whoever changed it recalculates the positions
+				if errors.Is(err, ErrVersionConflict) {
+					continue
+				}
+				return fmt.Errorf("failed to update position for entry %s: %w", entry.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// GetCalledEntriesBefore gets the CALLED entries of all tenants last updated before the given time
+func (r *PostgresQueueRepository) GetCalledEntriesBefore(ctx context.Context, before time.Time) ([]*types.Entry, error) {
+	f := &pgFilter{}
+	f.eq("status", "CALLED")
+	f.where("updated_at < ?", before)
+
+	entries, err := r.findEntries(ctx, f, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find called entries: %w", err)
+	}
+	return entries, nil
+}
+
+// GetEntriesUpdatedSince gets the queue entries of all tenants last updated at or after the given time
+func (r *PostgresQueueRepository) GetEntriesUpdatedSince(ctx context.Context, since time.Time) ([]*types.Entry, error) {
+	f := &pgFilter{}
+	f.where("updated_at >= ?", since)
+
+	entries, err := r.findEntries(ctx, f, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find updated entries: %w", err)
+	}
+	return entries, nil
+}
+
+// GetWaitingEntries gets the WAITING entries of all rooms and tenants
+func (r *PostgresQueueRepository) GetWaitingEntries(ctx context.Context) ([]*types.Entry, error) {
+	f := &pgFilter{}
+	f.eq("status", "WAITING")
+
+	entries, err := r.findEntries(ctx, f, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find waiting entries: %w", err)
+	}
+	return entries, nil
+}
+
+// UpdateEntryFitnessScore updates the fitness score of a queue entry and its breakdown
+func (r *PostgresQueueRepository) UpdateEntryFitnessScore(ctx context.Context, id string, fitnessScore float64, breakdown *types.FitnessBreakdown) error {
+	return r.updateEntry(ctx, id, func(entry *types.Entry) error {
+		entry.FitnessScore = fitnessScore
+		entry.FitnessBreakdown = breakdown
+		return nil
+	})
+}
+
+// EscalateEntryTier moves a queue entry to a higher priority tier and marks it as escalated
+func (r *PostgresQueueRepository) EscalateEntryTier(ctx context.Context, id string, tier int) error {
+	return r.updateEntry(ctx, id, func(entry *types.Entry) error {
+		entry.Tier = tier
+		entry.TierEscalated = true
+		entry.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+// UpdateEntryManualOverride sets the manual priority override of a queue entry with the fitness score it results in
+func (r *PostgresQueueRepository) UpdateEntryManualOverride(ctx context.Context, id string, manualOverride float64, fitnessScore float64, breakdown *types.FitnessBreakdown) error {
+	return r.updateEntry(ctx, id, func(entry *types.Entry) error {
+		entry.ManualOverride = &manualOverride
+		entry.FitnessScore = fitnessScore
+		entry.FitnessBreakdown = breakdown
+		entry.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+// UpdateEntryAnnotations replaces the staff notes and tags of a queue entry
+func (r *PostgresQueueRepository) UpdateEntryAnnotations(ctx context.Context, id string, notes string, tags []string, annotatedBy string) error {
+	return r.updateEntry(ctx, id, func(entry *types.Entry) error {
+		now := time.Now()
+		entry.Notes = notes
+		entry.Tags = tags
+		entry.AnnotatedBy = annotatedBy
+		entry.AnnotatedAt = &now
+		entry.UpdatedAt = now
+		return nil
+	})
+}
+
+// UpdateEntryContact replaces the notification phone number and email of a queue entry; empty clears them
+func (r *PostgresQueueRepository) UpdateEntryContact(ctx context.Context, id string, phone string, email string) error {
+	return r.updateEntry(ctx, id, func(entry *types.Entry) error {
+		entry.NotifyPhone = phone
+		entry.NotifyEmail = email
+		entry.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+// UpdateEntryService replaces the service name and approximate duration (seconds) of a queue entry
+func (r *PostgresQueueRepository) UpdateEntryService(ctx context.Context, id string, serviceName string, durationSeconds int64) error {
+	return r.updateEntry(ctx, id, func(entry *types.Entry) error {
+		entry.ServiceName = serviceName
+		entry.ApproximateDurationSeconds = durationSeconds
+		entry.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+// MarkEntryApproachNotified records that the patient of a queue entry was told their turn is near;
+// false when it was recorded already, e.g. by another replica
+func (r *PostgresQueueRepository) MarkEntryApproachNotified(ctx context.Context, id string) (bool, error) {
+	marked := false
+	err := r.updateEntry(ctx, id, func(entry *types.Entry) error {
+		if entry.ApproachNotifiedAt == nil {
+			now := time.Now()
+			entry.ApproachNotifiedAt = &now
+			marked = true
+		}
+		return nil
+	})
+	return marked, err
+}
+
+// UpdateEntryPushSubscription replaces the Web Push subscription of a queue entry; nil clears it
+func (r *PostgresQueueRepository) UpdateEntryPushSubscription(ctx context.Context, id string, subscription *types.PushSubscription) error {
+	return r.updateEntry(ctx, id, func(entry *types.Entry) error {
+		entry.PushSubscription = subscription
+		entry.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+// MarkEntryPushMilestone records that a queue entry got the push of a people-ahead milestone;
+// false when it got the one of this or a smaller milestone already, e.g. from another replica
+func (r *PostgresQueueRepository) MarkEntryPushMilestone(ctx context.Context, id string, milestone int) (bool, error) {
+	marked := false
+	err := r.updateEntry(ctx, id, func(entry *types.Entry) error {
+		if entry.PushMilestone == nil || *entry.PushMilestone > milestone {
+			entry.PushMilestone = &milestone
+			marked = true
+		}
+		return nil
+	})
+	return marked, err
+}
+
+// TransferEntry moves an entry to another room and service point (empty = any) and puts it back into WAITING
+func (r *PostgresQueueRepository) TransferEntry(ctx context.Context, id string, roomId string, servicePoint string) error {
+	return r.updateEntry(ctx, id, func(entry *types.Entry) error {
+		entry.WaitingRoomID = roomId
+		entry.ServicePoint = servicePoint
+		entry.Status = "WAITING"
+		entry.Version++
+		entry.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+// MoveEntryToPathwayStage sets the pathway stage of an entry and lets it wait in the stage's room and service point (empty = any)
+func (r *PostgresQueueRepository) MoveEntryToPathwayStage(ctx context.Context, id string, pathwayId string, stage int, roomId string, servicePoint string) error {
+	return r.updateEntry(ctx, id, func(entry *types.Entry) error {
+		entry.PathwayID = pathwayId
+		entry.PathwayStage = stage
+		entry.WaitingRoomID = roomId
+		entry.ServicePoint = servicePoint
+		entry.Status = "WAITING"
+		entry.Version++
+		entry.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+// BulkUpdateStatus sets every entry of a room (filtered by tenant) in one of fromStatuses to toStatus
+// and recalculates the room's positions in one transaction; it returns the number of entries changed
+func (r *PostgresQueueRepository) BulkUpdateStatus(ctx context.Context, roomId string, fromStatuses []string, toStatus string) (int64, error) {
+	var changed int64
+	err := r.WithTransaction(ctx, func(txCtx context.Context) error {
+		f := roomFilter(txCtx, roomId)
+		f.in("status", fromStatuses)
+		entries, err := r.findEntries(txCtx, f, " FOR UPDATE")
+		if err != nil {
+			return fmt.Errorf("failed to find entries: %w", err)
+		}
+
+		now := time.Now()
+		for _, entry := range entries {
+			entry.Status = toStatus
+			entry.Version++
+			entry.UpdatedAt = now
+			if err := r.saveEntry(txCtx, entry); err != nil {
+				return fmt.Errorf("failed to update entry statuses: %w", err)
+			}
+		}
+		changed = int64(len(entries))
+		return r.RecalculatePositions(txCtx, roomId)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
+
+// MoveWaitingEntries moves every WAITING entry of a room (filtered by tenant) to targetRoomId without
+// a service point and recalculates both rooms' positions in one transaction; it returns the number moved
+func (r *PostgresQueueRepository) MoveWaitingEntries(ctx context.Context, roomId string, targetRoomId string) (int64, error) {
+	var moved int64
+	err := r.WithTransaction(ctx, func(txCtx context.Context) error {
+		f := roomFilter(txCtx, roomId)
+		f.eq("status", "WAITING")
+		entries, err := r.findEntries(txCtx, f, " FOR UPDATE")
+		if err != nil {
+			return fmt.Errorf("failed to find waiting entries: %w", err)
+		}
+
+		now := time.Now()
+		for _, entry := range entries {
+			entry.WaitingRoomID = targetRoomId
+			entry.ServicePoint = ""
+			entry.Version++
+			entry.UpdatedAt = now
+			if err := r.saveEntry(txCtx, entry); err != nil {
+				return fmt.Errorf("failed to move waiting entries: %w", err)
+			}
+		}
+		moved = int64(len(entries))
+		if err := r.RecalculatePositions(txCtx, targetRoomId); err != nil {
+			return err
+		}
+		return r.RecalculatePositions(txCtx, roomId)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+// GetRoomStates gets the stored states of a room and its service points (filtered by tenant)
+func (r *PostgresQueueRepository) GetRoomStates(ctx context.Context, roomId string) ([]*types.RoomState, error) {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	rows, err := r.conn(ctx).QueryContext(ctx, `SELECT document FROM room_states WHERE room_id = $1 AND tenant_id = $2 AND section_id = $3`,
+		roomId, buildingID, sectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find room states: %w", err)
+	}
+	defer rows.Close()
+
+	var states []*types.RoomState
+	for rows.Next() {
+		var document []byte
+		if err := rows.Scan(&document); err != nil {
+			return nil, fmt.Errorf("failed to read room state: %w", err)
+		}
+		var state types.RoomState
+		if err := fromDocument(document, &state); err != nil {
+			return nil, err
+		}
+		states = append(states, &state)
+	}
+	return states, rows.Err()
+}
+
+// SetRoomState creates or replaces the state of a room or service point within the tenant in the context
+func (r *PostgresQueueRepository) SetRoomState(ctx context.Context, state *types.RoomState) error {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	state.TenantID = buildingID
+	state.SectionID = sectionID
+	state.ID = strings.Join([]string{buildingID, sectionID, state.RoomID, state.ServicePointID}, ":")
+	state.UpdatedAt = time.Now()
+
+	document, err := toDocument(state)
+	if err != nil {
+		return err
+	}
+	_, err = r.conn(ctx).ExecContext(ctx, `INSERT INTO room_states (id, tenant_id, section_id, room_id, document) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE SET document = EXCLUDED.document`, state.ID, buildingID, sectionID, state.RoomID, document)
+	if err != nil {
+		return fmt.Errorf("failed to store room state: %w", err)
+	}
+	return nil
+}
+
+// RequeueEntry puts an entry back into WAITING with the given priority tier and no service point
+func (r *PostgresQueueRepository) RequeueEntry(ctx context.Context, id string, tier int) error {
+	return r.updateEntry(ctx, id, func(entry *types.Entry) error {
+		entry.Status = "WAITING"
+		entry.Tier = tier
+		entry.ServicePoint = ""
+		entry.Version++
+		entry.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+// GetEntryStats groups the COMPLETED and NO_SHOW entries created in [from, to) (filtered by tenant,
+// and by room unless roomId is empty) by day in loc, room and service point
+func (r *PostgresQueueRepository) GetEntryStats(ctx context.Context, from, to time.Time, roomId string, loc *time.Location) ([]*types.EntryStats, error) {
+	f := &pgFilter{}
+	f.in("status", []string{"COMPLETED", "NO_SHOW"})
+	f.where("created_at >= ? AND created_at < ?", from, to)
+	tenantFilter(ctx, f)
+	if roomId != "" {
+		f.eq("waiting_room_id", roomId)
+	}
+
+	entries, err := r.findEntries(ctx, f, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find entries for stats: %w", err)
+	}
+	archived, err := r.findDocuments(ctx, "queue_entries_archive", f, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find archived entries for stats: %w", err)
+	}
+	return groupEntryStats(append(entries, archived...), loc), nil
+}
+
+// ArchiveEntries moves the entries of all tenants in one of statuses last updated before the given
+// time to queue_entries_archive, in batches of archiveBatchSize
+func (r *PostgresQueueRepository) ArchiveEntries(ctx context.Context, before time.Time, statuses []string) (int64, error) {
+	var archived int64
+	for {
+		moved := 0
+		err := r.WithTransaction(ctx, func(txCtx context.Context) error {
+			f := &pgFilter{}
+			f.in("status", statuses)
+			f.where("updated_at < ?", before)
+			entries, err := r.findEntries(txCtx, f, fmt.Sprintf(" LIMIT %d FOR UPDATE SKIP LOCKED", archiveBatchSize))
+			if err != nil {
+				return err
+			}
+			moved = len(entries)
+
+			now := time.Now()
+			for _, entry := range entries {
+				entry.ArchivedAt = &now
+				document, err := r.document(txCtx, entry)
+				if err != nil {
+					return err
+				}
+				if _, err := r.conn(txCtx).ExecContext(txCtx, `INSERT INTO queue_entries_archive
+					(id, waiting_room_id, tenant_id, section_id, status, created_at, archived_at, document)
+					VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (id) DO UPDATE SET document = EXCLUDED.document`,
+					entry.ID, entry.WaitingRoomID, entry.TenantID, entry.SectionID, entry.Status, entry.CreatedAt, now, document); err != nil {
+					return err
+				}
+				if _, err := r.conn(txCtx).ExecContext(txCtx, `DELETE FROM queue_entries WHERE id = $1`, entry.ID); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return archived, fmt.Errorf("failed to archive entries: %w", err)
+		}
+		archived += int64(moved)
+		if moved < archiveBatchSize {
+			return archived, nil
+		}
+	}
+}
+
+// GetArchivedEntries gets the archived entries created in [from, to) (filtered by tenant, and by
+// room unless roomId is empty), newest first and at most limit
+func (r *PostgresQueueRepository) GetArchivedEntries(ctx context.Context, from, to time.Time, roomId string, limit int) ([]*types.Entry, error) {
+	f := &pgFilter{}
+	f.where("created_at >= ? AND created_at < ?", from, to)
+	tenantFilter(ctx, f)
+	if roomId != "" {
+		f.eq("waiting_room_id", roomId)
+	}
+
+	suffix := " ORDER BY created_at DESC"
+	if limit > 0 {
+		suffix += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	entries, err := r.findDocuments(ctx, "queue_entries_archive", f, suffix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find archived entries: %w", err)
+	}
+	return entries, nil
+}
+
+// GetEntriesToAnonymize gets the queued and archived entries of exactly the given tenant and section
+// in one of statuses, last updated before the given time and not anonymized yet
+func (r *PostgresQueueRepository) GetEntriesToAnonymize(ctx context.Context, tenantId, sectionId string, before time.Time, statuses []string) ([]*types.Entry, error) {
+	// The archive has no updated_at column, its documents keep the time as extended JSON
+	updatedBefore := map[string]string{
+		"queue_entries":         "updated_at < ?",
+		"queue_entries_archive": "(document->'updatedAt'->>'$date')::timestamptz < ?",
+	}
+
+	var entries []*types.Entry
+	for _, table := range []string{"queue_entries", "queue_entries_archive"} {
+		f := &pgFilter{}
+		f.eq("tenant_id", tenantId)
+		f.eq("section_id", sectionId)
+		f.in("status", statuses)
+		f.where(updatedBefore[table], before)
+		f.where("document->'anonymizedAt' IS NULL")
+		found, err := r.findDocuments(ctx, table, f, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to find entries to anonymize: %w", err)
+		}
+		entries = append(entries, found...)
+	}
+	return entries, nil
+}
+
+// AnonymizeEntry replaces the card data of a queued or archived entry and marks it anonymized
+func (r *PostgresQueueRepository) AnonymizeEntry(ctx context.Context, id string, cardData types.CardData, idNumberHash string) error {
+	return r.WithTransaction(ctx, func(txCtx context.Context) error {
+		for _, table := range []string{"queue_entries", "queue_entries_archive"} {
+			f := &pgFilter{}
+			f.eq("id", id)
+			entries, err := r.findDocuments(txCtx, table, f, " FOR UPDATE")
+			if err != nil {
+				return fmt.Errorf("failed to find queue entry: %w", err)
+			}
+			if len(entries) == 0 {
+				continue
+			}
+
+			now := time.Now()
+			entry := entries[0]
+			entry.CardData = cardData
+			if idNumberHash != "" {
+				entry.IDNumberHash = idNumberHash
+			}
+			entry.IdempotencyKey = ""
+			entry.NotifyPhone = ""
+			entry.NotifyEmail = ""
+			entry.PushSubscription = nil
+			entry.AnonymizedAt = &now
+			document, err := r.document(txCtx, entry)
+			if err != nil {
+				return err
+			}
+			set := "document = $2"
+			if table == "queue_entries" {
+				set += ", idempotency_key = ''"
+			}
+			if _, err := r.conn(txCtx).ExecContext(txCtx, "UPDATE "+table+" SET "+set+" WHERE id = $1", id, document); err != nil {
+				return fmt.Errorf("failed to anonymize queue entry: %w", err)
+			}
+			return nil
+		}
+		return fmt.Errorf("queue entry not found")
+	})
+}
+
+// DeleteEntriesByIdentifier deletes the queued and archived entries (filtered by tenant) whose card
+// has identifier as ID or insurance number, or that kept identifierHash
+func (r *PostgresQueueRepository) DeleteEntriesByIdentifier(ctx context.Context, identifier, identifierHash string) ([]*types.Entry, error) {
+	var matches []string
+	var args []any
+	if identifier != "" {
+		matches = append(matches, "document->'cardData'->>'idNumber' = ?", "document->'cardData'->>'insuranceNumber' = ?")
+		args = append(args, identifier, identifier)
+		// Encrypted ID numbers are found by their index
+		indexes, err := idNumberIndexes(ctx, r.cardDataCipher, identifier)
+		if err != nil {
+			return nil, err
+		}
+		for _, index := range indexes {
+			matches = append(matches, "document->'cardData'->>'idNumberIndex' = ?")
+			args = append(args, index)
+		}
+	}
+	if identifierHash != "" {
+		matches = append(matches, "document->>'idNumberHash' = ?")
+		args = append(args, identifierHash)
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	f := &pgFilter{}
+	f.where("("+strings.Join(matches, " OR ")+")", args...)
+	tenantFilter(ctx, f)
+
+	var deleted []*types.Entry
+	err := r.WithTransaction(ctx, func(txCtx context.Context) error {
+		deleted = nil
+		for _, table := range []string{"queue_entries", "queue_entries_archive"} {
+			found, err := r.findDocuments(txCtx, table, f, " FOR UPDATE")
+			if err != nil {
+				return err
+			}
+			if len(found) == 0 {
+				continue
+			}
+			if _, err := r.conn(txCtx).ExecContext(txCtx, "DELETE FROM "+table+f.clause(), f.args...); err != nil {
+				return err
+			}
+			deleted = append(deleted, found...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete entries of data subject: %w", err)
+	}
+	return deleted, nil
+}
+
+// DeleteTenantEntries deletes the queued and archived entries of the tenant in the context and
+// returns their IDs
+func (r *PostgresQueueRepository) DeleteTenantEntries(ctx context.Context) ([]string, error) {
+	if getTenantIDFromContext(ctx) == "" {
+		return nil, fmt.Errorf("a tenant is required to delete its entries")
+	}
+	f := &pgFilter{}
+	tenantFilter(ctx, f)
+
+	var deleted []string
+	err := r.WithTransaction(ctx, func(txCtx context.Context) error {
+		deleted = nil
+		for _, table := range []string{"queue_entries", "queue_entries_archive"} {
+			rows, err := r.conn(txCtx).QueryContext(txCtx, "DELETE FROM "+table+f.clause()+" RETURNING id", f.args...)
+			if err != nil {
+				return err
+			}
+			for rows.Next() {
+				var id string
+				if err := rows.Scan(&id); err != nil {
+					rows.Close()
+					return err
+				}
+				deleted = append(deleted, id)
+			}
+			err = rows.Err()
+			rows.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete entries of tenant: %w", err)
+	}
+	return deleted, nil
+}
+
+// DeleteTenantRoomData deletes the ticket counters and room states of the tenant in the context
+func (r *PostgresQueueRepository) DeleteTenantRoomData(ctx context.Context) error {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	if buildingID == "" {
+		return fmt.Errorf("a tenant is required to delete its room data")
+	}
+	return r.WithTransaction(ctx, func(txCtx context.Context) error {
+		for _, table := range []string{"ticket_counters", "room_states"} {
+			if _, err := r.conn(txCtx).ExecContext(txCtx, "DELETE FROM "+table+" WHERE tenant_id = $1 AND section_id = $2", buildingID, sectionID); err != nil {
+				return fmt.Errorf("failed to delete %s of tenant: %w", table, err)
+			}
+		}
+		return nil
+	})
+}
+
+// WithTransaction runs fn in a PostgreSQL transaction, or in the one of ctx when there is one
+func (r *PostgresQueueRepository) WithTransaction(ctx context.Context, fn func(txCtx context.Context) error) error {
+	return pgWithTransaction(ctx, r.db, fn)
+}
+
+// DeleteEntry deletes a queue entry
+func (r *PostgresQueueRepository) DeleteEntry(ctx context.Context, id string) error {
+	result, err := r.conn(ctx).ExecContext(ctx, `DELETE FROM queue_entries WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete queue entry: %w", err)
+	}
+	if deleted, err := result.RowsAffected(); err == nil && deleted == 0 {
+		return fmt.Errorf("queue entry not found")
+	}
+	return nil
+}
+
+// Close closes the database connection, which the PostgreSQL config repository shares
+func (r *PostgresQueueRepository) Close() error {
+	return r.db.Close()
+}
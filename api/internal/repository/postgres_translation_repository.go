@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// PostgresTranslationGlossaryRepository implements TranslationGlossaryRepository using PostgreSQL
+type PostgresTranslationGlossaryRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresTranslationGlossaryRepository creates a new PostgreSQL glossary repository on a migrated database
+func NewPostgresTranslationGlossaryRepository(db *sql.DB) *PostgresTranslationGlossaryRepository {
+	return &PostgresTranslationGlossaryRepository{db: db}
+}
+
+func (r *PostgresTranslationGlossaryRepository) FindGlossaryTranslation(ctx context.Context, sourceLang, targetLang, sourceText string) (*types.GlossaryEntry, error) {
+	f := &pgFilter{}
+	tenantFilter(ctx, f)
+	f.eq("source_lang", sourceLang)
+	f.eq("target_lang", targetLang)
+	f.eq("source_text", sourceText)
+	entry, err := queryDocument[types.GlossaryEntry](ctx, pgConnFromContext(ctx, r.db), "SELECT document FROM translation_glossary"+f.clause(), f.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find glossary entry: %w", err)
+	}
+	return entry, nil
+}
+
+func (r *PostgresTranslationGlossaryRepository) GetGlossary(ctx context.Context) ([]*types.GlossaryEntry, error) {
+	f := &pgFilter{}
+	tenantFilter(ctx, f)
+	entries, err := queryDocuments[types.GlossaryEntry](ctx, pgConnFromContext(ctx, r.db), "SELECT document FROM translation_glossary"+f.clause()+" ORDER BY source_lang, target_lang, source_text", f.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find glossary entries: %w", err)
+	}
+	if entries == nil {
+		entries = []*types.GlossaryEntry{}
+	}
+	return entries, nil
+}
+
+func (r *PostgresTranslationGlossaryRepository) ReplaceGlossary(ctx context.Context, entries []*types.GlossaryEntry) error {
+	f := &pgFilter{}
+	tenantFilter(ctx, f)
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	now := time.Now()
+
+	return pgWithTransaction(ctx, r.db, func(txCtx context.Context) error {
+		conn := pgConnFromContext(txCtx, r.db)
+		if _, err := conn.ExecContext(txCtx, "DELETE FROM translation_glossary"+f.clause(), f.args...); err != nil {
+			return fmt.Errorf("failed to delete glossary entries: %w", err)
+		}
+		for _, entry := range entries {
+			entry.ID = uuid.New().String()
+			entry.TenantID = buildingID
+			entry.SectionID = sectionID
+			entry.UpdatedAt = now
+			document, err := toDocument(entry)
+			if err != nil {
+				return err
+			}
+			if _, err := conn.ExecContext(txCtx, `INSERT INTO translation_glossary (id, tenant_id, section_id, source_lang, target_lang, source_text, document)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)`, entry.ID, buildingID, sectionID, entry.SourceLang, entry.TargetLang, entry.SourceText, document); err != nil {
+				return fmt.Errorf("failed to insert glossary entries: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// PostgresTranslationCacheRepository implements TranslationCacheRepository using PostgreSQL.
+// Expired translations are ignored on read; saving a translation deletes them.
+type PostgresTranslationCacheRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresTranslationCacheRepository creates a new PostgreSQL translation cache on a migrated database
+func NewPostgresTranslationCacheRepository(db *sql.DB) *PostgresTranslationCacheRepository {
+	return &PostgresTranslationCacheRepository{db: db}
+}
+
+func (r *PostgresTranslationCacheRepository) FindTranslation(ctx context.Context, sourceLang, targetLang, textHash string) (*types.CachedTranslation, error) {
+	f := &pgFilter{}
+	tenantFilter(ctx, f)
+	f.eq("source_lang", sourceLang)
+	f.eq("target_lang", targetLang)
+	f.eq("text_hash", textHash)
+	f.where("expires_at > ?", time.Now())
+	translation, err := queryDocument[types.CachedTranslation](ctx, pgConnFromContext(ctx, r.db), "SELECT document FROM translation_cache"+f.clause(), f.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cached translation: %w", err)
+	}
+	return translation, nil
+}
+
+func (r *PostgresTranslationCacheRepository) SaveTranslation(ctx context.Context, translation *types.CachedTranslation) error {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	translation.TenantID = buildingID
+	translation.SectionID = sectionID
+
+	document, err := toDocument(translation)
+	if err != nil {
+		return err
+	}
+	conn := pgConnFromContext(ctx, r.db)
+	if _, err := conn.ExecContext(ctx, `DELETE FROM translation_cache WHERE expires_at <= $1`, time.Now()); err != nil {
+		return fmt.Errorf("failed to delete expired translations: %w", err)
+	}
+	// The exact tenant, so a tenant-level translation doesn't replace the one of a section
+	_, err = conn.ExecContext(ctx, `INSERT INTO translation_cache (tenant_id, section_id, source_lang, target_lang, text_hash, cached_at, expires_at, document)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (tenant_id, section_id, source_lang, target_lang, text_hash) DO UPDATE SET cached_at = EXCLUDED.cached_at,
+			expires_at = EXCLUDED.expires_at, document = EXCLUDED.document`,
+		buildingID, sectionID, translation.SourceLang, translation.TargetLang, translation.TextHash, translation.CachedAt, translation.ExpiresAt, document)
+	if err != nil {
+		return fmt.Errorf("failed to save cached translation: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresTranslationCacheRepository) FindRecentTranslations(ctx context.Context, limit int) ([]*types.CachedTranslation, error) {
+	translations, err := queryDocuments[types.CachedTranslation](ctx, pgConnFromContext(ctx, r.db),
+		fmt.Sprintf("SELECT document FROM translation_cache WHERE expires_at > $1 ORDER BY cached_at DESC LIMIT %d", limit), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cached translations: %w", err)
+	}
+	if translations == nil {
+		translations = []*types.CachedTranslation{}
+	}
+	return translations, nil
+}
+
+func (r *PostgresTranslationCacheRepository) CountTranslations(ctx context.Context) (int64, error) {
+	f := &pgFilter{}
+	tenantFilter(ctx, f)
+	f.where("expires_at > ?", time.Now())
+	var count int64
+	if err := pgConnFromContext(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM translation_cache"+f.clause(), f.args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count cached translations: %w", err)
+	}
+	return count, nil
+}
+
+func (r *PostgresTranslationCacheRepository) DeleteTranslations(ctx context.Context) (int64, error) {
+	f := &pgFilter{}
+	tenantFilter(ctx, f)
+	result, err := pgConnFromContext(ctx, r.db).ExecContext(ctx, "DELETE FROM translation_cache"+f.clause(), f.args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete cached translations: %w", err)
+	}
+	return result.RowsAffected()
+}
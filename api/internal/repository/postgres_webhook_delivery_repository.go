@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// PostgresWebhookDeliveryRepository implements WebhookDeliveryRepository using PostgreSQL. The
+// lease of a delivery is the locked_until column.
+type PostgresWebhookDeliveryRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresWebhookDeliveryRepository creates a new PostgreSQL webhook outbox on a migrated database
+func NewPostgresWebhookDeliveryRepository(db *sql.DB) *PostgresWebhookDeliveryRepository {
+	return &PostgresWebhookDeliveryRepository{db: db}
+}
+
+// save creates or replaces a delivery
+func (r *PostgresWebhookDeliveryRepository) save(ctx context.Context, delivery *types.WebhookDelivery) error {
+	document, err := toDocument(delivery)
+	if err != nil {
+		return err
+	}
+	_, err = pgConnFromContext(ctx, r.db).ExecContext(ctx, `INSERT INTO webhook_deliveries (id, tenant_id, section_id, status, next_attempt_at, locked_until, delivered_at, created_at, document)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (id) DO UPDATE SET status = EXCLUDED.status, next_attempt_at = EXCLUDED.next_attempt_at, locked_until = EXCLUDED.locked_until,
+			delivered_at = EXCLUDED.delivered_at, document = EXCLUDED.document`,
+		delivery.ID, delivery.TenantID, delivery.SectionID, delivery.Status, delivery.NextAttemptAt, delivery.LockedUntil,
+		delivery.DeliveredAt, delivery.CreatedAt, document)
+	return err
+}
+
+func (r *PostgresWebhookDeliveryRepository) EnqueueWebhookDelivery(ctx context.Context, delivery *types.WebhookDelivery) error {
+	if err := r.save(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to enqueue webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresWebhookDeliveryRepository) ClaimDueWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*types.WebhookDelivery, error) {
+	lockedUntil := now.Add(lease)
+	// SKIP LOCKED, so replicas claiming concurrently never get the same delivery
+	claimed, err := queryDocuments[types.WebhookDelivery](ctx, pgConnFromContext(ctx, r.db), `UPDATE webhook_deliveries SET locked_until = $1
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = $2 AND next_attempt_at <= $3 AND (locked_until IS NULL OR locked_until < $3)
+			ORDER BY next_attempt_at LIMIT $4 FOR UPDATE SKIP LOCKED
+		)
+		RETURNING document`, lockedUntil, types.WebhookDeliveryPending, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	for _, delivery := range claimed {
+		delivery.LockedUntil = &lockedUntil
+	}
+	sort.Slice(claimed, func(i, j int) bool { return claimed[i].NextAttemptAt.Before(claimed[j].NextAttemptAt) })
+	return claimed, nil
+}
+
+func (r *PostgresWebhookDeliveryRepository) UpdateWebhookDelivery(ctx context.Context, delivery *types.WebhookDelivery) error {
+	delivery.LockedUntil = nil
+	if err := r.save(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresWebhookDeliveryRepository) GetWebhookDeliveries(ctx context.Context, status string, limit int) ([]*types.WebhookDelivery, error) {
+	f := &pgFilter{}
+	tenantFilter(ctx, f)
+	if status != "" {
+		f.eq("status", status)
+	}
+	deliveries, err := queryDocuments[types.WebhookDelivery](ctx, pgConnFromContext(ctx, r.db), "SELECT document FROM webhook_deliveries"+f.clause()+fmt.Sprintf(" ORDER BY created_at DESC LIMIT %d", limit), f.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (r *PostgresWebhookDeliveryRepository) GetWebhookDelivery(ctx context.Context, id string) (*types.WebhookDelivery, error) {
+	f := &pgFilter{}
+	tenantFilter(ctx, f)
+	f.eq("id", id)
+	delivery, err := queryDocument[types.WebhookDelivery](ctx, pgConnFromContext(ctx, r.db), "SELECT document FROM webhook_deliveries"+f.clause(), f.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+func (r *PostgresWebhookDeliveryRepository) DeleteDeliveredWebhookDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	result, err := pgConnFromContext(ctx, r.db).ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE status = $1 AND delivered_at < $2`, types.WebhookDeliveryDelivered, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete delivered webhook deliveries: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func (r *PostgresWebhookDeliveryRepository) DeleteTenantWebhookDeliveries(ctx context.Context) (int64, error) {
+	f := &pgFilter{}
+	tenantFilter(ctx, f)
+	if len(f.conditions) == 0 {
+		return 0, fmt.Errorf("a tenant is required to delete its webhook deliveries")
+	}
+	result, err := pgConnFromContext(ctx, r.db).ExecContext(ctx, "DELETE FROM webhook_deliveries"+f.clause(), f.args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete webhook deliveries of tenant: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// QueueOrderingResolver returns the ordering configured for a room of the tenant in the context
+type QueueOrderingResolver interface {
+	GetQueueOrdering(ctx context.Context, roomId string) types.QueueOrdering
+}
+
+// QueueOrderingStrategy orders the entries of a room. The MongoDB and in-memory orders must agree
+// so positions, listings and the next called entry are the same with either repository.
+type QueueOrderingStrategy interface {
+	// SortStages returns the aggregation stages that sort the matched entries
+	SortStages() mongo.Pipeline
+	// Before reports whether a is called before b
+	Before(a, b *types.Entry) bool
+}
+
+// NewQueueOrderingStrategy returns the strategy of an ordering; unknown orderings use the priority order
+func NewQueueOrderingStrategy(ordering types.QueueOrdering) QueueOrderingStrategy {
+	switch ordering {
+	case types.QueueOrderingFIFO:
+		return fifoOrdering{}
+	case types.QueueOrderingAppointmentFirst:
+		return appointmentFirstOrdering{}
+	default:
+		return priorityOrdering{}
+	}
+}
+
+// resolveOrdering returns the strategy of a room, the priority order without a resolver
+func resolveOrdering(ctx context.Context, resolver QueueOrderingResolver, roomId string) QueueOrderingStrategy {
+	if resolver == nil {
+		return priorityOrdering{}
+	}
+	return NewQueueOrderingStrategy(resolver.GetQueueOrdering(ctx, roomId))
+}
+
+// priorityOrdering orders by tier (lowest first), fitness score (lowest first), arrival time
+// (earliest first) and ticket number, as defined in the priority config algorithm
+type priorityOrdering struct{}
+
+func (priorityOrdering) SortStages() mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{
+			{Key: "tier", Value: 1},
+			{Key: "fitnessScore", Value: 1},
+			{Key: "createdAt", Value: 1},
+			{Key: "ticketNumber", Value: 1},
+		}}},
+	}
+}
+
+func (priorityOrdering) Before(a, b *types.Entry) bool {
+	if a.Tier != b.Tier {
+		return a.Tier < b.Tier
+	}
+	if a.FitnessScore != b.FitnessScore {
+		return a.FitnessScore < b.FitnessScore
+	}
+	return arrivedBefore(a, b)
+}
+
+// fifoOrdering orders by arrival time and ticket number only
+type fifoOrdering struct{}
+
+func (fifoOrdering) SortStages() mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{
+			{Key: "createdAt", Value: 1},
+			{Key: "ticketNumber", Value: 1},
+		}}},
+	}
+}
+
+func (fifoOrdering) Before(a, b *types.Entry) bool {
+	return arrivedBefore(a, b)
+}
+
+// appointmentFirstOrdering orders entries with an appointment by appointment time ahead of the
+// others, which follow in the priority order
+type appointmentFirstOrdering struct{}
+
+func (appointmentFirstOrdering) SortStages() mongo.Pipeline {
+	// MongoDB sorts missing fields first; a helper field puts the entries without appointment last
+	return mongo.Pipeline{
+		{{Key: "$addFields", Value: bson.M{
+			"_withoutAppointment": bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$appointmentTime", nil}}, 0, 1}},
+		}}},
+		{{Key: "$sort", Value: bson.D{
+			{Key: "_withoutAppointment", Value: 1},
+			{Key: "appointmentTime", Value: 1},
+			{Key: "tier", Value: 1},
+			{Key: "fitnessScore", Value: 1},
+			{Key: "createdAt", Value: 1},
+			{Key: "ticketNumber", Value: 1},
+		}}},
+		{{Key: "$project", Value: bson.M{"_withoutAppointment": 0}}},
+	}
+}
+
+func (appointmentFirstOrdering) Before(a, b *types.Entry) bool {
+	if (a.AppointmentTime != nil) != (b.AppointmentTime != nil) {
+		return a.AppointmentTime != nil
+	}
+	if a.AppointmentTime != nil && !a.AppointmentTime.Equal(*b.AppointmentTime) {
+		return a.AppointmentTime.Before(*b.AppointmentTime)
+	}
+	return priorityOrdering{}.Before(a, b)
+}
+
+func arrivedBefore(a, b *types.Entry) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.TicketNumber < b.TicketNumber
+}
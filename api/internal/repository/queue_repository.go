@@ -2,15 +2,25 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/arfis/waiting-room/internal/types"
 )
 
+// ErrVersionConflict is returned when an entry is updated from a version that is no longer current,
+// e.g. when two operators call the same entry at once
+var ErrVersionConflict = errors.New("queue entry was changed concurrently")
+
 // QueueRepository defines the interface for queue data operations
 type QueueRepository interface {
 	// CreateEntry creates a new queue entry
 	CreateEntry(ctx context.Context, entry *types.Entry) error
 
+	// NextTicketSequence atomically increments and returns the ticket counter of a room, tenant and
+	// section in a numbering period (empty = never reset); a new period starts at 1
+	NextTicketSequence(ctx context.Context, roomId, tenantId, sectionId, period string) (int64, error)
+
 	// GetQueueEntries retrieves all queue entries for a room
 	GetQueueEntries(ctx context.Context, roomId string, states []string) ([]*types.Entry, error)
 
@@ -20,14 +30,22 @@ type QueueRepository interface {
 	// GetEntryByQRToken retrieves a queue entry by QR token
 	GetEntryByQRToken(ctx context.Context, qrToken string) (*types.Entry, error)
 
-	// UpdateEntryStatus updates the status of a queue entry
-	UpdateEntryStatus(ctx context.Context, id string, status string) error
+	// GetActiveEntryByIdempotencyKey gets the oldest WAITING, CALLED or IN_SERVICE entry (filtered by tenant)
+	// created with one of the keys, or nil when there is none
+	GetActiveEntryByIdempotencyKey(ctx context.Context, keys []string) (*types.Entry, error)
+
+	// UpdateEntryStatus updates the status of a queue entry still at version and increments the
+	// version; ErrVersionConflict when the entry changed since it was read
+	UpdateEntryStatus(ctx context.Context, id string, status string, version int64) error
 
-	// UpdateEntryPosition updates the position of a queue entry
-	UpdateEntryPosition(ctx context.Context, id string, position int) error
+	// UpdateEntryStatusAndServicePoint updates the status and service point of a queue entry still
+	// at version in one write and increments the version; ErrVersionConflict when the entry changed
+	// since it was read
+	UpdateEntryStatusAndServicePoint(ctx context.Context, id string, status string, servicePoint string, version int64) error
 
-	// UpdateEntryServicePoint updates the service point of a queue entry
-	UpdateEntryServicePoint(ctx context.Context, id string, servicePoint string) error
+	// UpdateEntryPosition updates the position of a queue entry still at version and increments the
+	// version; ErrVersionConflict when the entry changed since it was read
+	UpdateEntryPosition(ctx context.Context, id string, position int, version int64) error
 
 	// GetNextWaitingEntry gets the next waiting entry for a room
 	GetNextWaitingEntry(ctx context.Context, roomId string) (*types.Entry, error)
@@ -44,9 +62,114 @@ type QueueRepository interface {
 	// RecalculatePositions recalculates positions for all waiting entries in a room
 	RecalculatePositions(ctx context.Context, roomId string) error
 
+	// GetCalledEntriesBefore gets the CALLED entries of all tenants last updated before the given time
+	GetCalledEntriesBefore(ctx context.Context, before time.Time) ([]*types.Entry, error)
+
+	// GetEntriesUpdatedSince gets the queue entries of all tenants last updated at or after the given time
+	GetEntriesUpdatedSince(ctx context.Context, since time.Time) ([]*types.Entry, error)
+
+	// GetWaitingEntries gets the WAITING entries of all rooms and tenants
+	GetWaitingEntries(ctx context.Context) ([]*types.Entry, error)
+
+	// UpdateEntryFitnessScore updates the fitness score of a queue entry and its breakdown
+	UpdateEntryFitnessScore(ctx context.Context, id string, fitnessScore float64, breakdown *types.FitnessBreakdown) error
+
+	// EscalateEntryTier moves a queue entry to a higher priority tier and marks it as escalated
+	EscalateEntryTier(ctx context.Context, id string, tier int) error
+
+	// UpdateEntryManualOverride sets the manual priority override of a queue entry with the fitness score it results in
+	UpdateEntryManualOverride(ctx context.Context, id string, manualOverride float64, fitnessScore float64, breakdown *types.FitnessBreakdown) error
+
+	// UpdateEntryAnnotations replaces the staff notes and tags of a queue entry
+	UpdateEntryAnnotations(ctx context.Context, id string, notes string, tags []string, annotatedBy string) error
+
+	// UpdateEntryContact replaces the notification phone number and email of a queue entry; empty clears them
+	UpdateEntryContact(ctx context.Context, id string, phone string, email string) error
+
+	// UpdateEntryService replaces the service name and approximate duration (seconds) of a queue entry
+	UpdateEntryService(ctx context.Context, id string, serviceName string, durationSeconds int64) error
+
+	// MarkEntryApproachNotified records that the patient of a queue entry was told their turn is near;
+	// false when it was recorded already, e.g. by another replica
+	MarkEntryApproachNotified(ctx context.Context, id string) (bool, error)
+
+	// UpdateEntryPushSubscription replaces the Web Push subscription of a queue entry; nil clears it
+	UpdateEntryPushSubscription(ctx context.Context, id string, subscription *types.PushSubscription) error
+
+	// MarkEntryPushMilestone records that a queue entry got the push of a people-ahead milestone;
+	// false when it got the one of this or a smaller milestone already, e.g. from another replica
+	MarkEntryPushMilestone(ctx context.Context, id string, milestone int) (bool, error)
+
+	// TransferEntry moves an entry to another room and service point (empty = any) and puts it back into WAITING
+	TransferEntry(ctx context.Context, id string, roomId string, servicePoint string) error
+
+	// MoveEntryToPathwayStage sets the pathway stage of an entry and lets it wait in the stage's room and service point (empty = any)
+	MoveEntryToPathwayStage(ctx context.Context, id string, pathwayId string, stage int, roomId string, servicePoint string) error
+
+	// BulkUpdateStatus sets every entry of a room (filtered by tenant) in one of fromStatuses to toStatus
+	// and recalculates the room's positions in one transaction; it returns the number of entries changed
+	BulkUpdateStatus(ctx context.Context, roomId string, fromStatuses []string, toStatus string) (int64, error)
+
+	// MoveWaitingEntries moves every WAITING entry of a room (filtered by tenant) to targetRoomId without
+	// a service point and recalculates both rooms' positions in one transaction; it returns the number moved
+	MoveWaitingEntries(ctx context.Context, roomId string, targetRoomId string) (int64, error)
+
+	// GetRoomStates gets the stored states of a room and its service points (filtered by tenant)
+	GetRoomStates(ctx context.Context, roomId string) ([]*types.RoomState, error)
+
+	// SetRoomState creates or replaces the state of a room or service point within the tenant in the context
+	SetRoomState(ctx context.Context, state *types.RoomState) error
+
+	// RequeueEntry puts an entry back into WAITING with the given priority tier and no service point
+	RequeueEntry(ctx context.Context, id string, tier int) error
+
+	// GetEntryStats groups the COMPLETED and NO_SHOW entries created in [from, to), archived ones
+	// included (filtered by tenant, and by room unless roomId is empty) by day in loc, room and service point
+	GetEntryStats(ctx context.Context, from, to time.Time, roomId string, loc *time.Location) ([]*types.EntryStats, error)
+
+	// ArchiveEntries moves the entries of all tenants in one of statuses last updated before the given
+	// time from the queue to the archive; it returns the number archived
+	ArchiveEntries(ctx context.Context, before time.Time, statuses []string) (int64, error)
+
+	// GetArchivedEntries gets the archived entries created in [from, to) (filtered by tenant, and by
+	// room unless roomId is empty), newest first and at most limit
+	GetArchivedEntries(ctx context.Context, from, to time.Time, roomId string, limit int) ([]*types.Entry, error)
+
+	// GetEntriesToAnonymize gets the queued and archived entries of exactly the given tenant and
+	// section (empty = none) in one of statuses, last updated before the given time and not anonymized yet
+	GetEntriesToAnonymize(ctx context.Context, tenantId, sectionId string, before time.Time, statuses []string) ([]*types.Entry, error)
+
+	// AnonymizeEntry replaces the card data of a queued or archived entry, keeps idNumberHash (empty =
+	// none), drops the swipe idempotency key derived from the card and marks the entry anonymized
+	AnonymizeEntry(ctx context.Context, id string, cardData types.CardData, idNumberHash string) error
+
+	// DeleteEntriesByIdentifier deletes the queued and archived entries (filtered by tenant) whose card
+	// has identifier as ID or insurance number, or that kept identifierHash, and returns them
+	DeleteEntriesByIdentifier(ctx context.Context, identifier, identifierHash string) ([]*types.Entry, error)
+
+	// DeleteTenantEntries deletes the queued and archived entries of the tenant in the context and
+	// returns their IDs; without a tenant it deletes nothing
+	DeleteTenantEntries(ctx context.Context) ([]string, error)
+
+	// DeleteTenantRoomData deletes the ticket counters and room states of the tenant in the context;
+	// without a tenant it deletes nothing
+	DeleteTenantRoomData(ctx context.Context) error
+
 	// DeleteEntry deletes a queue entry
 	DeleteEntry(ctx context.Context, id string) error
 
+	// WithTransaction runs fn so that the repository calls made with txCtx are committed together or
+	// not at all; calls within a running transaction join it. Servers without transactions run fn as is.
+	WithTransaction(ctx context.Context, fn func(txCtx context.Context) error) error
+
 	// Close closes the repository connection
 	Close() error
 }
+
+// QueueChangeWatcher is implemented by queue repositories that can stream the changes of queue
+// entries, including the ones made outside the API. The others are polled with GetEntriesUpdatedSince.
+type QueueChangeWatcher interface {
+	// WatchEntryChanges calls onChange with every changed entry until ctx is done, when it returns
+	// nil. It returns an error when the changes cannot be streamed (any more).
+	WatchEntryChanges(ctx context.Context, onChange func(entry *types.Entry)) error
+}
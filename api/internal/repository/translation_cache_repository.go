@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// TranslationCacheRepository persists the translation cache. Every method but FindRecentTranslations
+// is scoped to the tenant in the context.
+type TranslationCacheRepository interface {
+	// FindTranslation returns the unexpired translation of the text with the hash, nil when there is none
+	FindTranslation(ctx context.Context, sourceLang, targetLang, textHash string) (*types.CachedTranslation, error)
+
+	// SaveTranslation creates or replaces the translation of its text
+	SaveTranslation(ctx context.Context, translation *types.CachedTranslation) error
+
+	// FindRecentTranslations returns up to limit unexpired translations of all tenants, the most
+	// recently cached first
+	FindRecentTranslations(ctx context.Context, limit int) ([]*types.CachedTranslation, error)
+
+	// CountTranslations returns the number of unexpired translations
+	CountTranslations(ctx context.Context) (int64, error)
+
+	// DeleteTranslations removes the translations and returns their number
+	DeleteTranslations(ctx context.Context) (int64, error)
+}
+
+type MongoDBTranslationCacheRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoDBTranslationCacheRepository(db *mongo.Database) *MongoDBTranslationCacheRepository {
+	collection := db.Collection("translation_cache")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenantId", Value: 1},
+				{Key: "sectionId", Value: 1},
+				{Key: "sourceLang", Value: 1},
+				{Key: "targetLang", Value: 1},
+				{Key: "textHash", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// Mongo removes translations once they expire
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+		{
+			Keys: bson.D{{Key: "cachedAt", Value: -1}},
+		},
+	}
+	if _, err := collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		// Log but don't fail - index might already exist
+		log.Printf("Translation cache index creation warning (may already exist): %v", err)
+	}
+
+	return &MongoDBTranslationCacheRepository{
+		collection: collection,
+	}
+}
+
+func (r *MongoDBTranslationCacheRepository) FindTranslation(ctx context.Context, sourceLang, targetLang, textHash string) (*types.CachedTranslation, error) {
+	filter := r.tenantFilter(ctx)
+	filter["sourceLang"] = sourceLang
+	filter["targetLang"] = targetLang
+	filter["textHash"] = textHash
+	// The TTL monitor runs only once a minute
+	filter["expiresAt"] = bson.M{"$gt": time.Now()}
+
+	var translation types.CachedTranslation
+	err := r.collection.FindOne(ctx, filter).Decode(&translation)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cached translation: %w", err)
+	}
+	return &translation, nil
+}
+
+func (r *MongoDBTranslationCacheRepository) SaveTranslation(ctx context.Context, translation *types.CachedTranslation) error {
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	translation.TenantID = buildingID
+	translation.SectionID = sectionID
+
+	// The exact tenant, so a tenant-level translation doesn't replace the one of a section
+	filter := bson.M{
+		"tenantId":   buildingID,
+		"sectionId":  sectionID,
+		"sourceLang": translation.SourceLang,
+		"targetLang": translation.TargetLang,
+		"textHash":   translation.TextHash,
+	}
+	if _, err := r.collection.ReplaceOne(ctx, filter, translation, options.Replace().SetUpsert(true)); err != nil {
+		return fmt.Errorf("failed to save cached translation: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoDBTranslationCacheRepository) FindRecentTranslations(ctx context.Context, limit int) ([]*types.CachedTranslation, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "cachedAt", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, bson.M{"expiresAt": bson.M{"$gt": time.Now()}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cached translations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	translations := []*types.CachedTranslation{}
+	if err := cursor.All(ctx, &translations); err != nil {
+		return nil, fmt.Errorf("failed to decode cached translations: %w", err)
+	}
+	return translations, nil
+}
+
+func (r *MongoDBTranslationCacheRepository) CountTranslations(ctx context.Context) (int64, error) {
+	filter := r.tenantFilter(ctx)
+	filter["expiresAt"] = bson.M{"$gt": time.Now()}
+	count, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count cached translations: %w", err)
+	}
+	return count, nil
+}
+
+func (r *MongoDBTranslationCacheRepository) DeleteTranslations(ctx context.Context) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, r.tenantFilter(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete cached translations: %w", err)
+	}
+	return result.DeletedCount, nil
+}
+
+func (r *MongoDBTranslationCacheRepository) tenantFilter(ctx context.Context) bson.M {
+	filter := bson.M{}
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	if buildingID != "" {
+		filter["tenantId"] = buildingID
+	}
+	if sectionID != "" {
+		filter["sectionId"] = sectionID
+	}
+	return filter
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// TranslationGlossaryRepository stores the translation glossaries of the tenants. Every method is
+// scoped to the tenant in the context.
+type TranslationGlossaryRepository interface {
+	// FindGlossaryTranslation returns the entry translating the text, nil when there is none
+	FindGlossaryTranslation(ctx context.Context, sourceLang, targetLang, sourceText string) (*types.GlossaryEntry, error)
+
+	// GetGlossary returns the entries of the glossary by language pair and text
+	GetGlossary(ctx context.Context) ([]*types.GlossaryEntry, error)
+
+	// ReplaceGlossary replaces the glossary with the entries
+	ReplaceGlossary(ctx context.Context, entries []*types.GlossaryEntry) error
+}
+
+type MongoDBTranslationGlossaryRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoDBTranslationGlossaryRepository(db *mongo.Database) *MongoDBTranslationGlossaryRepository {
+	collection := db.Collection("translation_glossary")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	index := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "tenantId", Value: 1},
+			{Key: "sectionId", Value: 1},
+			{Key: "sourceLang", Value: 1},
+			{Key: "targetLang", Value: 1},
+			{Key: "sourceText", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	if _, err := collection.Indexes().CreateOne(ctx, index); err != nil {
+		// Log but don't fail - index might already exist
+		log.Printf("Translation glossary index creation warning (may already exist): %v", err)
+	}
+
+	return &MongoDBTranslationGlossaryRepository{
+		collection: collection,
+	}
+}
+
+func (r *MongoDBTranslationGlossaryRepository) FindGlossaryTranslation(ctx context.Context, sourceLang, targetLang, sourceText string) (*types.GlossaryEntry, error) {
+	filter := r.tenantFilter(ctx)
+	filter["sourceLang"] = sourceLang
+	filter["targetLang"] = targetLang
+	filter["sourceText"] = sourceText
+
+	var entry types.GlossaryEntry
+	err := r.collection.FindOne(ctx, filter).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find glossary entry: %w", err)
+	}
+	return &entry, nil
+}
+
+func (r *MongoDBTranslationGlossaryRepository) GetGlossary(ctx context.Context) ([]*types.GlossaryEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "sourceLang", Value: 1}, {Key: "targetLang", Value: 1}, {Key: "sourceText", Value: 1}})
+	cursor, err := r.collection.Find(ctx, r.tenantFilter(ctx), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find glossary entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	entries := []*types.GlossaryEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode glossary entries: %w", err)
+	}
+	return entries, nil
+}
+
+func (r *MongoDBTranslationGlossaryRepository) ReplaceGlossary(ctx context.Context, entries []*types.GlossaryEntry) error {
+	if _, err := r.collection.DeleteMany(ctx, r.tenantFilter(ctx)); err != nil {
+		return fmt.Errorf("failed to delete glossary entries: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	now := time.Now()
+	documents := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		entry.ID = uuid.New().String()
+		entry.TenantID = buildingID
+		entry.SectionID = sectionID
+		entry.UpdatedAt = now
+		documents[i] = entry
+	}
+	if _, err := r.collection.InsertMany(ctx, documents); err != nil {
+		return fmt.Errorf("failed to insert glossary entries: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoDBTranslationGlossaryRepository) tenantFilter(ctx context.Context) bson.M {
+	filter := bson.M{}
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	if buildingID != "" {
+		filter["tenantId"] = buildingID
+	}
+	if sectionID != "" {
+		filter["sectionId"] = sectionID
+	}
+	return filter
+}
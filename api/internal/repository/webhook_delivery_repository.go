@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// WebhookDeliveryRepository stores the webhook outbox
+type WebhookDeliveryRepository interface {
+	// EnqueueWebhookDelivery stores a new delivery
+	EnqueueWebhookDelivery(ctx context.Context, delivery *types.WebhookDelivery) error
+
+	// ClaimDueWebhookDeliveries leases up to limit PENDING deliveries of all tenants whose next
+	// attempt is due and that no worker is sending, until now+lease, oldest first
+	ClaimDueWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*types.WebhookDelivery, error)
+
+	// UpdateWebhookDelivery stores the state of a delivery after an attempt or replay and
+	// releases its lease
+	UpdateWebhookDelivery(ctx context.Context, delivery *types.WebhookDelivery) error
+
+	// GetWebhookDeliveries returns the deliveries of the tenant in ctx with the status, newest first
+	GetWebhookDeliveries(ctx context.Context, status string, limit int) ([]*types.WebhookDelivery, error)
+
+	// GetWebhookDelivery returns a delivery of the tenant in ctx, nil when there is none
+	GetWebhookDelivery(ctx context.Context, id string) (*types.WebhookDelivery, error)
+
+	// DeleteDeliveredWebhookDeliveries deletes the deliveries of all tenants delivered before before
+	DeleteDeliveredWebhookDeliveries(ctx context.Context, before time.Time) (int64, error)
+
+	// DeleteTenantWebhookDeliveries deletes the deliveries of the tenant in ctx in every status;
+	// without a tenant it deletes nothing
+	DeleteTenantWebhookDeliveries(ctx context.Context) (int64, error)
+}
+
+type MongoDBWebhookDeliveryRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoDBWebhookDeliveryRepository(db *mongo.Database) *MongoDBWebhookDeliveryRepository {
+	collection := db.Collection("webhook_deliveries")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}}},
+		{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "status", Value: 1}, {Key: "createdAt", Value: -1}}},
+	}
+	if _, err := collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		// Log but don't fail - index might already exist
+		log.Printf("Webhook delivery index creation warning (may already exist): %v", err)
+	}
+
+	return &MongoDBWebhookDeliveryRepository{
+		collection: collection,
+	}
+}
+
+func (r *MongoDBWebhookDeliveryRepository) EnqueueWebhookDelivery(ctx context.Context, delivery *types.WebhookDelivery) error {
+	if _, err := r.collection.InsertOne(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to enqueue webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoDBWebhookDeliveryRepository) ClaimDueWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*types.WebhookDelivery, error) {
+	filter := bson.M{
+		"status":        types.WebhookDeliveryPending,
+		"nextAttemptAt": bson.M{"$lte": now},
+		"$or": []bson.M{
+			{"lockedUntil": bson.M{"$exists": false}},
+			{"lockedUntil": bson.M{"$lt": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"lockedUntil": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "nextAttemptAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	// One document at a time, so replicas claiming concurrently never get the same delivery
+	var claimed []*types.WebhookDelivery
+	for len(claimed) < limit {
+		var delivery types.WebhookDelivery
+		err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&delivery)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			break
+		}
+		if err != nil {
+			return claimed, fmt.Errorf("failed to claim webhook delivery: %w", err)
+		}
+		claimed = append(claimed, &delivery)
+	}
+	return claimed, nil
+}
+
+func (r *MongoDBWebhookDeliveryRepository) UpdateWebhookDelivery(ctx context.Context, delivery *types.WebhookDelivery) error {
+	delivery.LockedUntil = nil
+	if _, err := r.collection.ReplaceOne(ctx, bson.M{"_id": delivery.ID}, delivery); err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoDBWebhookDeliveryRepository) GetWebhookDeliveries(ctx context.Context, status string, limit int) ([]*types.WebhookDelivery, error) {
+	filter := r.tenantFilter(ctx)
+	if status != "" {
+		filter["status"] = status
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook deliveries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var deliveries []*types.WebhookDelivery
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (r *MongoDBWebhookDeliveryRepository) GetWebhookDelivery(ctx context.Context, id string) (*types.WebhookDelivery, error) {
+	filter := r.tenantFilter(ctx)
+	filter["_id"] = id
+
+	var delivery types.WebhookDelivery
+	err := r.collection.FindOne(ctx, filter).Decode(&delivery)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook delivery: %w", err)
+	}
+	return &delivery, nil
+}
+
+func (r *MongoDBWebhookDeliveryRepository) DeleteDeliveredWebhookDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{
+		"status":      types.WebhookDeliveryDelivered,
+		"deliveredAt": bson.M{"$lt": before},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete delivered webhook deliveries: %w", err)
+	}
+	return result.DeletedCount, nil
+}
+
+func (r *MongoDBWebhookDeliveryRepository) DeleteTenantWebhookDeliveries(ctx context.Context) (int64, error) {
+	filter := r.tenantFilter(ctx)
+	if len(filter) == 0 {
+		return 0, fmt.Errorf("a tenant is required to delete its webhook deliveries")
+	}
+	result, err := r.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete webhook deliveries of tenant: %w", err)
+	}
+	return result.DeletedCount, nil
+}
+
+// tenantFilter filters the deliveries of the tenant in ctx; a building sees those of its sections
+func (r *MongoDBWebhookDeliveryRepository) tenantFilter(ctx context.Context) bson.M {
+	filter := bson.M{}
+	buildingID, sectionID, _ := types.ParseTenantID(getTenantIDFromContext(ctx))
+	if buildingID != "" {
+		filter["tenantId"] = buildingID
+	}
+	if sectionID != "" {
+		filter["sectionId"] = sectionID
+	}
+	return filter
+}
@@ -25,10 +25,573 @@ func New(
 	}
 }
 
+func (h *Handler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	var resp []dto.APIKey
+	resp, applicationErr = h.svc.GetAPIKeys(
+		r.Context(),
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	req := dto.APIKeyRequest{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.IssuedAPIKey
+	resp, applicationErr = h.svc.CreateAPIKey(
+		r.Context(),
+		&req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	id := handler.PathParamToString(r, "id")
+	var resp *dto.APIKey
+	resp, applicationErr = h.svc.RevokeAPIKey(
+		r.Context(),
+		id,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
 func (h *Handler) GetCardReaders(w http.ResponseWriter, r *http.Request) {
 	var applicationErr error
-	var resp []dto.CardReaderStatus
-	resp, applicationErr = h.svc.GetCardReaders(
+	var resp []dto.CardReaderStatus
+	resp, applicationErr = h.svc.GetCardReaders(
+		r.Context(),
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) GetCardReaderCommands(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	id := handler.PathParamToString(r, "id")
+	limit, applicationErr := handler.QueryOptionalParamToInt32(r, "limit")
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	var resp []dto.CardReaderCommand
+	resp, applicationErr = h.svc.GetCardReaderCommands(
+		r.Context(),
+		id,
+		limit,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) EnqueueCardReaderCommand(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	id := handler.PathParamToString(r, "id")
+	req := dto.CardReaderCommandRequest{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.CardReaderCommand
+	resp, applicationErr = h.svc.EnqueueCardReaderCommand(
+		r.Context(),
+		id, &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 201, resp)
+}
+
+func (h *Handler) GetCardReaderConfiguration(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	id := handler.PathParamToString(r, "id")
+	var resp *dto.CardReaderConfig
+	resp, applicationErr = h.svc.GetCardReaderConfiguration(
+		r.Context(),
+		id,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) UpdateCardReaderConfiguration(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	id := handler.PathParamToString(r, "id")
+	req := dto.CardReaderConfig{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.CardReaderConfig
+	resp, applicationErr = h.svc.UpdateCardReaderConfiguration(
+		r.Context(),
+		id, &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) RotateCardReaderEncryptionKey(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	id := handler.PathParamToString(r, "id")
+	var resp *dto.CardReaderEncryptionKey
+	resp, applicationErr = h.svc.RotateCardReaderEncryptionKey(
+		r.Context(),
+		id,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) IssueCardReaderToken(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	id := handler.PathParamToString(r, "id")
+	var resp *dto.CardReaderToken
+	resp, applicationErr = h.svc.IssueCardReaderToken(
+		r.Context(),
+		id,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) RestartCardReader(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	id := handler.PathParamToString(r, "id")
+	var resp *dto.RestartResponse
+	resp, applicationErr = h.svc.RestartCardReader(
+		r.Context(),
+		id,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) PublishCardReaderRelease(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	req := dto.CardReaderRelease{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.CardReaderRelease
+	resp, applicationErr = h.svc.PublishCardReaderRelease(
+		r.Context(),
+		&req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) CreateSubscriptionToken(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	req := dto.SubscriptionTokenRequest{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.SubscriptionToken
+	resp, applicationErr = h.svc.CreateSubscriptionToken(
+		r.Context(),
+		&req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) GetLatestCardReaderRelease(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	os := handler.QueryParamToString(r, "os")
+	arch := handler.QueryParamToString(r, "arch")
+	var resp *dto.CardReaderRelease
+	resp, applicationErr = h.svc.GetLatestCardReaderRelease(
+		r.Context(),
+		os, arch,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) GetSystemConfiguration(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	var resp *dto.SystemConfiguration
+	resp, applicationErr = h.svc.GetSystemConfiguration(
+		r.Context(),
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) UpdateSystemConfiguration(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	req := dto.SystemConfiguration{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.SystemConfiguration
+	resp, applicationErr = h.svc.UpdateSystemConfiguration(
+		r.Context(), &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) GetResolvedConfiguration(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	var resp *dto.ResolvedConfiguration
+	resp, applicationErr = h.svc.GetResolvedConfiguration(
+		r.Context(),
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) ExportConfiguration(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	var resp *dto.ConfigurationBundle
+	resp, applicationErr = h.svc.ExportConfiguration(
+		r.Context(),
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) ImportConfiguration(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	req := dto.ConfigurationBundle{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.ConfigurationImportResult
+	resp, applicationErr = h.svc.ImportConfiguration(
+		r.Context(), &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) DryRunConfigurationImport(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	req := dto.ConfigurationBundle{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.ConfigurationImportResult
+	resp, applicationErr = h.svc.DryRunConfigurationImport(
+		r.Context(), &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) GetExternalAPIConfiguration(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	var resp *dto.ExternalAPIConfig
+	resp, applicationErr = h.svc.GetExternalAPIConfiguration(
+		r.Context(),
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) UpdateExternalAPIConfiguration(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	req := dto.ExternalAPIConfig{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.ExternalAPIConfig
+	resp, applicationErr = h.svc.UpdateExternalAPIConfiguration(
+		r.Context(), &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) GetRoomsConfiguration(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	var resp []dto.RoomConfig
+	resp, applicationErr = h.svc.GetRoomsConfiguration(
+		r.Context(),
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) UpdateRoomsConfiguration(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	var resp []dto.RoomConfig
+	req := []dto.RoomConfig{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	for _, item := range req {
+		applicationErr = handler.GetValidator().Struct(item)
+		if applicationErr != nil {
+			h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+			return
+		}
+	}
+	resp, applicationErr = h.svc.UpdateRoomsConfiguration(
+		r.Context(),
+		req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) CreateRoom(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	req := dto.RoomConfig{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.RoomConfig
+	resp, applicationErr = h.svc.CreateRoom(
+		r.Context(), &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) UpdateRoom(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	roomId := handler.PathParamToString(r, "roomId")
+	req := dto.RoomConfig{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.RoomConfig
+	resp, applicationErr = h.svc.UpdateRoom(
+		r.Context(),
+		roomId, &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) DeleteRoom(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	roomId := handler.PathParamToString(r, "roomId")
+	migrateTo := handler.QueryOptionalParamToString(r, "migrateTo")
+	var resp *dto.RoomDeletionResult
+	resp, applicationErr = h.svc.DeleteRoom(
+		r.Context(),
+		roomId,
+		migrateTo,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) AddServicePoint(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	roomId := handler.PathParamToString(r, "roomId")
+	req := dto.ServicePointConfig{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.RoomConfig
+	resp, applicationErr = h.svc.AddServicePoint(
+		r.Context(),
+		roomId, &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) RemoveServicePoint(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	roomId := handler.PathParamToString(r, "roomId")
+	servicePointId := handler.PathParamToString(r, "servicePointId")
+	var resp *dto.RoomConfig
+	resp, applicationErr = h.svc.RemoveServicePoint(
+		r.Context(),
+		roomId,
+		servicePointId,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) GetPathwaysConfiguration(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	var resp []dto.Pathway
+	resp, applicationErr = h.svc.GetPathwaysConfiguration(
 		r.Context(),
 	)
 	if applicationErr != nil {
@@ -38,13 +601,25 @@ func (h *Handler) GetCardReaders(w http.ResponseWriter, r *http.Request) {
 	handler.WriteJson(r.Context(), w, 200, resp)
 }
 
-func (h *Handler) RestartCardReader(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdatePathwaysConfiguration(w http.ResponseWriter, r *http.Request) {
 	var applicationErr error
-	id := handler.PathParamToString(r, "id")
-	var resp *dto.RestartResponse
-	resp, applicationErr = h.svc.RestartCardReader(
+	var resp []dto.Pathway
+	req := []dto.Pathway{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	for _, item := range req {
+		applicationErr = handler.GetValidator().Struct(item)
+		if applicationErr != nil {
+			h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+			return
+		}
+	}
+	resp, applicationErr = h.svc.UpdatePathwaysConfiguration(
 		r.Context(),
-		id,
+		req,
 	)
 	if applicationErr != nil {
 		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
@@ -53,10 +628,10 @@ func (h *Handler) RestartCardReader(w http.ResponseWriter, r *http.Request) {
 	handler.WriteJson(r.Context(), w, 200, resp)
 }
 
-func (h *Handler) GetSystemConfiguration(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetRetentionPolicy(w http.ResponseWriter, r *http.Request) {
 	var applicationErr error
-	var resp *dto.SystemConfiguration
-	resp, applicationErr = h.svc.GetSystemConfiguration(
+	var resp *dto.RetentionPolicy
+	resp, applicationErr = h.svc.GetRetentionPolicy(
 		r.Context(),
 	)
 	if applicationErr != nil {
@@ -66,9 +641,9 @@ func (h *Handler) GetSystemConfiguration(w http.ResponseWriter, r *http.Request)
 	handler.WriteJson(r.Context(), w, 200, resp)
 }
 
-func (h *Handler) UpdateSystemConfiguration(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdateRetentionPolicy(w http.ResponseWriter, r *http.Request) {
 	var applicationErr error
-	req := dto.SystemConfiguration{}
+	req := dto.RetentionPolicy{}
 	applicationErr = json.NewDecoder(r.Body).Decode(&req)
 	if applicationErr != nil {
 		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
@@ -79,8 +654,8 @@ func (h *Handler) UpdateSystemConfiguration(w http.ResponseWriter, r *http.Reque
 		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
 		return
 	}
-	var resp *dto.SystemConfiguration
-	resp, applicationErr = h.svc.UpdateSystemConfiguration(
+	var resp *dto.RetentionPolicy
+	resp, applicationErr = h.svc.UpdateRetentionPolicy(
 		r.Context(), &req,
 	)
 	if applicationErr != nil {
@@ -90,10 +665,10 @@ func (h *Handler) UpdateSystemConfiguration(w http.ResponseWriter, r *http.Reque
 	handler.WriteJson(r.Context(), w, 200, resp)
 }
 
-func (h *Handler) GetExternalAPIConfiguration(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetNotificationSettings(w http.ResponseWriter, r *http.Request) {
 	var applicationErr error
-	var resp *dto.ExternalAPIConfig
-	resp, applicationErr = h.svc.GetExternalAPIConfiguration(
+	var resp *dto.NotificationSettings
+	resp, applicationErr = h.svc.GetNotificationSettings(
 		r.Context(),
 	)
 	if applicationErr != nil {
@@ -103,9 +678,9 @@ func (h *Handler) GetExternalAPIConfiguration(w http.ResponseWriter, r *http.Req
 	handler.WriteJson(r.Context(), w, 200, resp)
 }
 
-func (h *Handler) UpdateExternalAPIConfiguration(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdateNotificationSettings(w http.ResponseWriter, r *http.Request) {
 	var applicationErr error
-	req := dto.ExternalAPIConfig{}
+	req := dto.NotificationSettings{}
 	applicationErr = json.NewDecoder(r.Body).Decode(&req)
 	if applicationErr != nil {
 		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
@@ -116,8 +691,8 @@ func (h *Handler) UpdateExternalAPIConfiguration(w http.ResponseWriter, r *http.
 		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
 		return
 	}
-	var resp *dto.ExternalAPIConfig
-	resp, applicationErr = h.svc.UpdateExternalAPIConfiguration(
+	var resp *dto.NotificationSettings
+	resp, applicationErr = h.svc.UpdateNotificationSettings(
 		r.Context(), &req,
 	)
 	if applicationErr != nil {
@@ -127,10 +702,10 @@ func (h *Handler) UpdateExternalAPIConfiguration(w http.ResponseWriter, r *http.
 	handler.WriteJson(r.Context(), w, 200, resp)
 }
 
-func (h *Handler) GetRoomsConfiguration(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GenerateVapidKeys(w http.ResponseWriter, r *http.Request) {
 	var applicationErr error
-	var resp []dto.RoomConfig
-	resp, applicationErr = h.svc.GetRoomsConfiguration(
+	var resp *dto.PushSettings
+	resp, applicationErr = h.svc.GenerateVapidKeys(
 		r.Context(),
 	)
 	if applicationErr != nil {
@@ -140,25 +715,48 @@ func (h *Handler) GetRoomsConfiguration(w http.ResponseWriter, r *http.Request)
 	handler.WriteJson(r.Context(), w, 200, resp)
 }
 
-func (h *Handler) UpdateRoomsConfiguration(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) IssueIntegrationCredentials(w http.ResponseWriter, r *http.Request) {
 	var applicationErr error
-	var resp []dto.RoomConfig
-	req := []dto.RoomConfig{}
+	var resp *dto.IntegrationCredentials
+	resp, applicationErr = h.svc.IssueIntegrationCredentials(
+		r.Context(),
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) GetFhirSettings(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	var resp *dto.FhirSettings
+	resp, applicationErr = h.svc.GetFhirSettings(
+		r.Context(),
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) UpdateFhirSettings(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	req := dto.FhirSettings{}
 	applicationErr = json.NewDecoder(r.Body).Decode(&req)
 	if applicationErr != nil {
 		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
 		return
 	}
-	for _, item := range req {
-		applicationErr = handler.GetValidator().Struct(item)
-		if applicationErr != nil {
-			h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
-			return
-		}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
 	}
-	resp, applicationErr = h.svc.UpdateRoomsConfiguration(
-		r.Context(),
-		req,
+	var resp *dto.FhirSettings
+	resp, applicationErr = h.svc.UpdateFhirSettings(
+		r.Context(), &req,
 	)
 	if applicationErr != nil {
 		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
@@ -217,6 +815,139 @@ func (h *Handler) GetDefaultPriorityConfiguration(w http.ResponseWriter, r *http
 	handler.WriteJson(r.Context(), w, 200, resp)
 }
 
+func (h *Handler) DryRunPriorityConfiguration(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	req := dto.PriorityDryRunRequest{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.PriorityDryRunResult
+	resp, applicationErr = h.svc.DryRunPriorityConfiguration(
+		r.Context(), &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) GetPriorityConfigurationRevisions(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	var resp []dto.PriorityConfigRevision
+	resp, applicationErr = h.svc.GetPriorityConfigurationRevisions(
+		r.Context(),
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) RollbackPriorityConfiguration(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	revision, applicationErr := handler.PathParamToInt64(r, "revision")
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	var resp *dto.PriorityConfig
+	resp, applicationErr = h.svc.RollbackPriorityConfiguration(
+		r.Context(),
+		revision,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) GetPrioritySymbols(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	var resp []dto.PrioritySymbol
+	resp, applicationErr = h.svc.GetPrioritySymbols(
+		r.Context(),
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) CreatePrioritySymbol(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	req := dto.PrioritySymbol{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.PrioritySymbol
+	resp, applicationErr = h.svc.CreatePrioritySymbol(
+		r.Context(), &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) UpdatePrioritySymbol(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	code := handler.PathParamToString(r, "code")
+	req := dto.PrioritySymbol{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.PrioritySymbol
+	resp, applicationErr = h.svc.UpdatePrioritySymbol(
+		r.Context(),
+		code, &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) DeletePrioritySymbol(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	code := handler.PathParamToString(r, "code")
+	applicationErr = h.svc.DeletePrioritySymbol(
+		r.Context(),
+		code,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	w.WriteHeader(204)
+}
+
 func (h *Handler) GetAllTenants(w http.ResponseWriter, r *http.Request) {
 	var applicationErr error
 	var resp []dto.Tenant
@@ -292,6 +1023,45 @@ func (h *Handler) DeleteTenant(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(204)
 }
 
+func (h *Handler) ReactivateTenant(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	id := handler.PathParamToString(r, "id")
+	var resp *dto.Tenant
+	resp, applicationErr = h.svc.ReactivateTenant(
+		r.Context(),
+		id,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) ProvisionTenant(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	req := dto.TenantProvisioningRequest{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.TenantProvisioningResult
+	resp, applicationErr = h.svc.ProvisionTenant(
+		r.Context(), &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 201, resp)
+}
+
 func (h *Handler) GetTenant(w http.ResponseWriter, r *http.Request) {
 	var applicationErr error
 	id := handler.PathParamToString(r, "id")
@@ -320,6 +1090,19 @@ func (h *Handler) ClearTranslationCache(w http.ResponseWriter, r *http.Request)
 	handler.WriteJson(r.Context(), w, 200, resp)
 }
 
+func (h *Handler) GetExternalAPIStats(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	var resp []dto.ExternalAPIEndpointStats
+	resp, applicationErr = h.svc.GetExternalAPIStats(
+		r.Context(),
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
 func (h *Handler) GetTranslationCacheStats(w http.ResponseWriter, r *http.Request) {
 	var applicationErr error
 	var resp *dto.TranslationCacheStats
@@ -332,3 +1115,43 @@ func (h *Handler) GetTranslationCacheStats(w http.ResponseWriter, r *http.Reques
 	}
 	handler.WriteJson(r.Context(), w, 200, resp)
 }
+
+func (h *Handler) GetTranslationGlossary(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	var resp []dto.TranslationGlossaryEntry
+	resp, applicationErr = h.svc.GetTranslationGlossary(
+		r.Context(),
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) UpdateTranslationGlossary(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	var resp []dto.TranslationGlossaryEntry
+	req := []dto.TranslationGlossaryEntry{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	for _, item := range req {
+		applicationErr = handler.GetValidator().Struct(item)
+		if applicationErr != nil {
+			h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+			return
+		}
+	}
+	resp, applicationErr = h.svc.UpdateTranslationGlossary(
+		r.Context(),
+		req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
@@ -0,0 +1,75 @@
+// Code generated by go generate; DO NOT EDIT.
+package analytics
+
+import (
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/rest/handler"
+	"github.com/arfis/waiting-room/internal/service/analytics"
+	"net/http"
+)
+
+type Handler struct {
+	svc                  *analytics.Service
+	responseErrorHandler *ngErrors.ResponseErrorHandler
+}
+
+func New(
+	svc *analytics.Service,
+	responseErrorHandler *ngErrors.ResponseErrorHandler,
+) *Handler {
+	return &Handler{
+		svc:                  svc,
+		responseErrorHandler: responseErrorHandler,
+	}
+}
+
+func (h *Handler) GetArchivedEntries(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	from := handler.QueryOptionalParamToString(r, "from")
+	to := handler.QueryOptionalParamToString(r, "to")
+	roomId := handler.QueryOptionalParamToString(r, "roomId")
+	limit, applicationErr := handler.QueryOptionalParamToInt32(r, "limit")
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	timezone := handler.QueryOptionalParamToString(r, "timezone")
+	var resp []dto.ArchivedEntry
+	resp, applicationErr = h.svc.GetArchivedEntries(
+		r.Context(),
+		from,
+		to,
+		roomId,
+		limit,
+		timezone,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) GetDailyStatistics(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	from := handler.QueryOptionalParamToString(r, "from")
+	to := handler.QueryOptionalParamToString(r, "to")
+	roomId := handler.QueryOptionalParamToString(r, "roomId")
+	groupBy := handler.QueryOptionalParamToString(r, "groupBy")
+	timezone := handler.QueryOptionalParamToString(r, "timezone")
+	var resp []dto.DailyStatistics
+	resp, applicationErr = h.svc.GetDailyStatistics(
+		r.Context(),
+		from,
+		to,
+		roomId,
+		groupBy,
+		timezone,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
@@ -0,0 +1,68 @@
+// Code generated by go generate; DO NOT EDIT.
+package appointment
+
+import (
+	"encoding/json"
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/rest/handler"
+	"github.com/arfis/waiting-room/internal/service/appointment"
+	"net/http"
+)
+
+type Handler struct {
+	svc                  *appointment.Service
+	responseErrorHandler *ngErrors.ResponseErrorHandler
+}
+
+func New(
+	svc *appointment.Service,
+	responseErrorHandler *ngErrors.ResponseErrorHandler,
+) *Handler {
+	return &Handler{
+		svc:                  svc,
+		responseErrorHandler: responseErrorHandler,
+	}
+}
+
+func (h *Handler) GetAppointments(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	date := handler.QueryOptionalParamToString(r, "date")
+	var resp []dto.Appointment
+	resp, applicationErr = h.svc.GetAppointments(
+		r.Context(),
+		date,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) UpsertAppointments(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	var resp []dto.Appointment
+	req := []dto.Appointment{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	for _, item := range req {
+		applicationErr = handler.GetValidator().Struct(item)
+		if applicationErr != nil {
+			h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+			return
+		}
+	}
+	resp, applicationErr = h.svc.UpsertAppointments(
+		r.Context(),
+		req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
@@ -0,0 +1,54 @@
+// Code generated by go generate; DO NOT EDIT.
+package audit
+
+import (
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/rest/handler"
+	"github.com/arfis/waiting-room/internal/service/audit"
+	"net/http"
+)
+
+type Handler struct {
+	svc                  *audit.Service
+	responseErrorHandler *ngErrors.ResponseErrorHandler
+}
+
+func New(
+	svc *audit.Service,
+	responseErrorHandler *ngErrors.ResponseErrorHandler,
+) *Handler {
+	return &Handler{
+		svc:                  svc,
+		responseErrorHandler: responseErrorHandler,
+	}
+}
+
+func (h *Handler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	resource := handler.QueryOptionalParamToString(r, "resource")
+	action := handler.QueryOptionalParamToString(r, "action")
+	actor := handler.QueryOptionalParamToString(r, "actor")
+	from := handler.QueryOptionalParamToString(r, "from")
+	to := handler.QueryOptionalParamToString(r, "to")
+	limit, applicationErr := handler.QueryOptionalParamToInt32(r, "limit")
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	var resp []dto.AuditEntry
+	resp, applicationErr = h.svc.GetAuditLog(
+		r.Context(),
+		resource,
+		action,
+		actor,
+		from,
+		to,
+		limit,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
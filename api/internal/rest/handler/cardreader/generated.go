@@ -0,0 +1,51 @@
+// Code generated by go generate; DO NOT EDIT.
+package cardreader
+
+import (
+	"encoding/json"
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/rest/handler"
+	"github.com/arfis/waiting-room/internal/service/cardreader"
+	"net/http"
+)
+
+type Handler struct {
+	svc                  *cardreader.Service
+	responseErrorHandler *ngErrors.ResponseErrorHandler
+}
+
+func New(
+	svc *cardreader.Service,
+	responseErrorHandler *ngErrors.ResponseErrorHandler,
+) *Handler {
+	return &Handler{
+		svc:                  svc,
+		responseErrorHandler: responseErrorHandler,
+	}
+}
+
+func (h *Handler) IngestCardReaderEvent(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	req := dto.CardReaderEvent{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.CardReaderEventAck
+	resp, applicationErr = h.svc.IngestCardReaderEvent(
+		r.Context(),
+		&req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 202, resp)
+}
@@ -0,0 +1,158 @@
+package cardreader
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/arfis/waiting-room/internal/cardreader/cardreaderpb"
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/rest/handler"
+	"github.com/arfis/waiting-room/internal/service/cardreader"
+)
+
+// GRPCServer takes the events of card readers running with TRANSPORT=grpc. It is served on the
+// API's own port behind the CardReaderAuthMiddleware, which reads the device credential from the
+// request metadata like it reads the headers of the REST endpoint.
+type GRPCServer struct {
+	cardreaderpb.UnimplementedCardReaderIngestServer
+	svc *cardreader.Service
+}
+
+func NewGRPCServer(svc *cardreader.Service) *GRPCServer {
+	return &GRPCServer{svc: svc}
+}
+
+// Handler returns the gRPC server to mount at ServicePath
+func (s *GRPCServer) Handler() http.Handler {
+	server := grpc.NewServer()
+	cardreaderpb.RegisterCardReaderIngestServer(server, s)
+	return server
+}
+
+// ServicePath is the path prefix of the calls of the CardReaderIngest service
+func (s *GRPCServer) ServicePath() string {
+	return "/" + cardreaderpb.CardReaderIngest_ServiceDesc.ServiceName + "/"
+}
+
+// Ingest validates the event like the REST endpoint does and hands it to the card reader service
+func (s *GRPCServer) Ingest(ctx context.Context, event *cardreaderpb.CardReaderEvent) (*cardreaderpb.CardReaderEventAck, error) {
+	req := EventFromProto(event)
+	if err := handler.GetValidator().Struct(req); err != nil {
+		return nil, grpcError(ngErrors.RequestValidation(err))
+	}
+	ack, err := s.svc.IngestCardReaderEvent(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return AckToProto(ack), nil
+}
+
+// grpcError turns an application error into the status of the HTTP code it has on the REST endpoint,
+// so the card reader retries and drops events the same way on every transport
+func grpcError(err error) error {
+	var appErr *ngErrors.ApplicationError
+	if !errors.As(err, &appErr) {
+		return status.Error(codes.Internal, err.Error())
+	}
+	code := codes.Internal
+	switch appErr.HttpCode {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusRequestTimeout:
+		code = codes.DeadlineExceeded
+	case http.StatusConflict:
+		code = codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		code = codes.Unavailable
+	}
+	return status.Error(code, appErr.Code+": "+appErr.Text)
+}
+
+// EventFromProto converts an event of the gRPC transport to the event of the REST and WebSocket
+// transports. Empty optional fields stay unset.
+func EventFromProto(e *cardreaderpb.CardReaderEvent) *dto.CardReaderEvent {
+	event := &dto.CardReaderEvent{
+		Atr:        optional(e.GetAtr()),
+		DeviceId:   e.GetDeviceId(),
+		Message:    optional(e.GetMessage()),
+		OccurredAt: optional(e.GetOccurredAt()),
+		Protocol:   optional(e.GetProtocol()),
+		Reader:     optional(e.GetReader()),
+		RoomId:     e.GetRoomId(),
+		SectionId:  optional(e.GetSectionId()),
+		State:      e.GetState(),
+		TenantId:   optional(e.GetTenantId()),
+		Token:      e.GetToken(),
+	}
+	if c := e.GetCardData(); c != nil {
+		event.CardData = &dto.CardReaderCardData{
+			Address:            optional(c.GetAddress()),
+			DateOfBirth:        optional(c.GetDateOfBirth()),
+			ExpiryDate:         optional(c.GetExpiryDate()),
+			FirstName:          optional(c.GetFirstName()),
+			Gender:             optional(c.GetGender()),
+			IdNumber:           optional(c.GetIdNumber()),
+			InsuranceNumber:    optional(c.GetInsuranceNumber()),
+			InsuranceValidFrom: optional(c.GetInsuranceValidFrom()),
+			InsuranceValidTo:   optional(c.GetInsuranceValidTo()),
+			InsurerCode:        optional(c.GetInsurerCode()),
+			InsurerName:        optional(c.GetInsurerName()),
+			IssuedDate:         optional(c.GetIssuedDate()),
+			LastName:           optional(c.GetLastName()),
+			Nationality:        optional(c.GetNationality()),
+			Photo:              optional(c.GetPhoto()),
+			Source:             optional(c.GetSource()),
+		}
+	}
+	if p := e.GetPin(); p != nil {
+		event.Pin = &dto.CardReaderPinStatus{
+			CountLow: optional(p.GetCountLow()),
+			FinalTry: optional(p.GetFinalTry()),
+			Locked:   optional(p.GetLocked()),
+			Message:  optional(p.GetMessage()),
+			Result:   p.GetResult(),
+		}
+	}
+	if s := e.GetEncryptedCardData(); s != nil {
+		event.EncryptedCardData = &dto.SealedCardData{
+			Alg:        s.GetAlg(),
+			Ciphertext: s.GetCiphertext(),
+			Kid:        s.GetKid(),
+			Nonce:      s.GetNonce(),
+		}
+	}
+	return event
+}
+
+// AckToProto converts the acknowledgement of an event to the one of the gRPC transport
+func AckToProto(ack *dto.CardReaderEventAck) *cardreaderpb.CardReaderEventAck {
+	return &cardreaderpb.CardReaderEventAck{
+		Token:        ack.Token,
+		Duplicate:    ack.Duplicate,
+		EntryId:      ack.GetEntryId(),
+		TicketNumber: ack.GetTicketNumber(),
+	}
+}
+
+// optional returns nil for the zero value, which proto3 doesn't tell apart from an unset field
+func optional[T comparable](v T) *T {
+	var zero T
+	if v == zero {
+		return nil
+	}
+	return &v
+}
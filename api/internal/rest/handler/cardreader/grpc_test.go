@@ -0,0 +1,130 @@
+package cardreader
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/arfis/waiting-room/internal/cardreader/cardreaderpb"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/service/audit"
+	cardreaderService "github.com/arfis/waiting-room/internal/service/cardreader"
+	configService "github.com/arfis/waiting-room/internal/service/config"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+func fullEvent() *cardreaderpb.CardReaderEvent {
+	return &cardreaderpb.CardReaderEvent{
+		DeviceId: "reader-1", RoomId: "triage-1", TenantId: "b1", SectionId: "s1", Token: "t1",
+		Reader: "ACS ACR39U", Atr: "3b8f80", Protocol: "T=1", OccurredAt: "2026-10-14T08:00:00Z",
+		State: "success", Message: "Card read",
+		CardData: &cardreaderpb.CardData{
+			IdNumber: "EA123456", FirstName: "Jana", LastName: "Nováková", DateOfBirth: "1980-01-02",
+			Gender: "F", Nationality: "SVK", Address: "Hlavná 1", IssuedDate: "2020-01-01",
+			ExpiryDate: "2030-01-01", Photo: "aGVsbG8=", Source: "insurance-egk",
+			InsuranceNumber: "8001021234", InsurerCode: "25", InsurerName: "VšZP",
+			InsuranceValidFrom: "2024-01-01", InsuranceValidTo: "2026-12-31",
+		},
+		Pin:               &cardreaderpb.PinStatus{Result: "incorrect", CountLow: true, FinalTry: true, Locked: true, Message: "Wrong PIN"},
+		EncryptedCardData: &cardreaderpb.SealedCardData{Alg: "A256GCM", Kid: "0011223344556677", Nonce: "bm9uY2U=", Ciphertext: "Y2lwaGVy"},
+	}
+}
+
+// TestEventFromProtoMatchesJSON tests that the protobuf event carries the same fields under the
+// same JSON names as the CardReaderEvent of the REST and WebSocket transports
+func TestEventFromProtoMatchesJSON(t *testing.T) {
+	event := fullEvent()
+	body, err := json.Marshal(EventFromProto(event))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	decoded := &cardreaderpb.CardReaderEvent{}
+	if err := protojson.Unmarshal(body, decoded); err != nil {
+		t.Fatalf("The JSON of the REST event is not a protobuf event: %v\n%s", err, body)
+	}
+	if !proto.Equal(event, decoded) {
+		t.Errorf("Fields were lost between the transports:\n%v\n%v", event, decoded)
+	}
+
+	// Empty optional fields stay unset
+	minimal := EventFromProto(&cardreaderpb.CardReaderEvent{DeviceId: "reader-1", RoomId: "triage-1", State: "waiting", Token: "t2"})
+	if minimal.TenantId != nil || minimal.CardData != nil || minimal.Pin != nil || minimal.EncryptedCardData != nil {
+		t.Errorf("Expected unset optional fields, got %+v", minimal)
+	}
+}
+
+// TestGRPCIngest tests events and errors of a client calling the service over unencrypted HTTP/2
+func TestGRPCIngest(t *testing.T) {
+	configRepo := repository.NewEmbeddedConfigRepository()
+	if err := configRepo.SetCardReaderConfig(context.Background(), &types.CardReaderConfig{DeviceID: "reader-1", RoomID: "triage-1"}); err != nil {
+		t.Fatalf("SetCardReaderConfig failed: %v", err)
+	}
+	svc := cardreaderService.New(configService.NewService(configRepo, audit.New(repository.NewMockAuditLogRepository())), nil)
+	grpcServer := NewGRPCServer(svc)
+
+	// Stands in for the CardReaderAuthMiddleware, which reads the device ID from the metadata
+	device := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasPrefix(r.URL.Path, grpcServer.ServicePath()) {
+				http.NotFound(w, r)
+				return
+			}
+			ctx := context.WithValue(r.Context(), middleware.CARD_READER_DEVICE, r.Header.Get(middleware.DEVICE_ID_HEADER))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+	server := httptest.NewUnstartedServer(device(grpcServer.Handler()))
+	server.Config.Protocols = new(http.Protocols)
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	defer server.Close()
+
+	conn, err := grpc.NewClient(strings.TrimPrefix(server.URL, "http://"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer conn.Close()
+	client := cardreaderpb.NewCardReaderIngestClient(conn)
+	call := func(deviceID string, event *cardreaderpb.CardReaderEvent) (*cardreaderpb.CardReaderEventAck, error) {
+		return client.Ingest(metadata.AppendToOutgoingContext(context.Background(), "x-device-id", deviceID), event)
+	}
+
+	event := &cardreaderpb.CardReaderEvent{DeviceId: "reader-1", RoomId: "triage-1", State: "waiting", Token: "t1"}
+	ack, err := call("reader-1", event)
+	if err != nil || ack.GetToken() != "t1" || ack.GetDuplicate() {
+		t.Fatalf("Expected the event to be accepted, got %v, %v", ack, err)
+	}
+	if ack, err = call("reader-1", event); err != nil || !ack.GetDuplicate() {
+		t.Errorf("Expected the resent event to be a duplicate, got %v, %v", ack, err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		deviceID string
+		event    *cardreaderpb.CardReaderEvent
+		code     codes.Code
+	}{
+		{"missing token", "reader-1", &cardreaderpb.CardReaderEvent{DeviceId: "reader-1", RoomId: "triage-1", State: "waiting"}, codes.InvalidArgument},
+		{"unknown state", "reader-1", &cardreaderpb.CardReaderEvent{DeviceId: "reader-1", RoomId: "triage-1", State: "exploded", Token: "t2"}, codes.InvalidArgument},
+		{"event of another device", "reader-2", &cardreaderpb.CardReaderEvent{DeviceId: "reader-1", RoomId: "triage-1", State: "waiting", Token: "t3"}, codes.Unauthenticated},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := call(tc.deviceID, tc.event)
+			if status.Code(err) != tc.code {
+				t.Errorf("Expected %s, got %v", tc.code, err)
+			}
+		})
+	}
+}
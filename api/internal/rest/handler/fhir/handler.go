@@ -0,0 +1,119 @@
+package fhir
+
+import (
+	"encoding/json"
+	"net/http"
+
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/fhir"
+	fhirService "github.com/arfis/waiting-room/internal/service/fhir"
+	"github.com/go-chi/chi/v5"
+)
+
+// Handler serves the FHIR R4 RESTful interface. Resources and errors (as OperationOutcome) are
+// written as application/fhir+json, so it is not generated from the OpenAPI specification.
+type Handler struct {
+	svc *fhirService.Service
+}
+
+func New(svc *fhirService.Service) *Handler {
+	return &Handler{
+		svc: svc,
+	}
+}
+
+// Routes registers the interactions of the capability statement
+func (h *Handler) Routes(r chi.Router) {
+	r.Get("/metadata", h.GetCapabilityStatement)
+	r.Get("/Encounter", h.SearchEncounters)
+	r.Get("/Encounter/{id}", h.GetEncounter)
+	r.Get("/Appointment", h.SearchAppointments)
+	r.Post("/Appointment", h.CreateAppointment)
+	r.Get("/Appointment/{id}", h.GetAppointment)
+	r.Put("/Appointment/{id}", h.UpdateAppointment)
+}
+
+func (h *Handler) GetCapabilityStatement(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.svc.GetCapabilityStatement(r.Context())
+	writeResource(w, http.StatusOK, resp, err)
+}
+
+func (h *Handler) SearchEncounters(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	resp, err := h.svc.SearchEncounters(r.Context(), query.Get("location"), query.Get("status"))
+	writeResource(w, http.StatusOK, resp, err)
+}
+
+func (h *Handler) GetEncounter(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.svc.GetEncounter(r.Context(), chi.URLParam(r, "id"))
+	writeResource(w, http.StatusOK, resp, err)
+}
+
+func (h *Handler) SearchAppointments(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.svc.SearchAppointments(r.Context(), r.URL.Query().Get("date"))
+	writeResource(w, http.StatusOK, resp, err)
+}
+
+func (h *Handler) GetAppointment(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.svc.GetAppointment(r.Context(), chi.URLParam(r, "id"))
+	writeResource(w, http.StatusOK, resp, err)
+}
+
+func (h *Handler) CreateAppointment(w http.ResponseWriter, r *http.Request) {
+	req := fhir.Appointment{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResource(w, 0, nil, ngErrors.New(ngErrors.ValidationErrorCode, "problem decoding Appointment", http.StatusBadRequest, nil))
+		return
+	}
+	resp, err := h.svc.SaveAppointment(r.Context(), "", &req)
+	writeResource(w, http.StatusCreated, resp, err)
+}
+
+func (h *Handler) UpdateAppointment(w http.ResponseWriter, r *http.Request) {
+	req := fhir.Appointment{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResource(w, 0, nil, ngErrors.New(ngErrors.ValidationErrorCode, "problem decoding Appointment", http.StatusBadRequest, nil))
+		return
+	}
+	resp, err := h.svc.SaveAppointment(r.Context(), chi.URLParam(r, "id"), &req)
+	writeResource(w, http.StatusOK, resp, err)
+}
+
+// writeResource writes a resource, or the OperationOutcome of an error
+func writeResource(w http.ResponseWriter, status int, resource interface{}, err error) {
+	if err != nil {
+		appErr, _ := ngErrors.FromError(err)
+		status = appErr.HttpCode
+		resource = &fhir.OperationOutcome{
+			ResourceType: "OperationOutcome",
+			Issue: []fhir.Issue{{
+				Severity:    "error",
+				Code:        issueCode(status),
+				Diagnostics: appErr.Text,
+			}},
+		}
+	}
+	b, err := json.Marshal(resource)
+	if err != nil {
+		http.Error(w, "problem encoding resource", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", fhir.ContentType+"; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(b)
+}
+
+// issueCode returns the OperationOutcome issue type of an HTTP status
+func issueCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "invalid"
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "security"
+	case http.StatusNotFound:
+		return "not-found"
+	case http.StatusConflict:
+		return "conflict"
+	}
+	return "exception"
+}
@@ -0,0 +1,104 @@
+// Code generated by go generate; DO NOT EDIT.
+package i18n
+
+import (
+	"encoding/json"
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/rest/handler"
+	"github.com/arfis/waiting-room/internal/service/i18n"
+	"net/http"
+)
+
+type Handler struct {
+	svc                  *i18n.Service
+	responseErrorHandler *ngErrors.ResponseErrorHandler
+}
+
+func New(
+	svc *i18n.Service,
+	responseErrorHandler *ngErrors.ResponseErrorHandler,
+) *Handler {
+	return &Handler{
+		svc:                  svc,
+		responseErrorHandler: responseErrorHandler,
+	}
+}
+
+func (h *Handler) GetMessageCatalogs(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	var resp []dto.MessageCatalog
+	resp, applicationErr = h.svc.GetMessageCatalogs(
+		r.Context(),
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) TranslateMessageCatalogs(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	req := dto.MessageCatalogTranslationRequest{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp []dto.MessageCatalog
+	resp, applicationErr = h.svc.TranslateMessageCatalogs(
+		r.Context(),
+		&req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) UpdateMessageCatalog(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	lang := handler.PathParamToString(r, "lang")
+	req := dto.MessageCatalogUpdate{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.MessageCatalog
+	resp, applicationErr = h.svc.UpdateMessageCatalog(
+		r.Context(),
+		lang, &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) DeleteMessageCatalog(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	lang := handler.PathParamToString(r, "lang")
+	applicationErr = h.svc.DeleteMessageCatalog(
+		r.Context(),
+		lang,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	w.WriteHeader(204)
+}
@@ -0,0 +1,42 @@
+package i18n
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/arfis/waiting-room/internal/rest/handler"
+	"github.com/go-chi/chi/v5"
+)
+
+// GetMessages serves the messages of the language to kiosks and displays, with the language the
+// tenant serves in Content-Language. It is public and answers requests with the current ETag in
+// If-None-Match with 304 Not Modified, so it is not generated from the OpenAPI specification.
+func (h *Handler) GetMessages(w http.ResponseWriter, r *http.Request) {
+	resp, etag, err := h.svc.GetMessages(r.Context(), chi.URLParam(r, "lang"))
+	if err != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, err)
+		return
+	}
+
+	// Clients revalidate on every load, catalogs edited by the admin apply at once
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Language", resp.Language)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Vary", "X-Tenant-ID")
+	if matchesETag(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	handler.WriteJson(r.Context(), w, http.StatusOK, resp)
+}
+
+// matchesETag reports whether an If-None-Match header lists the tag
+func matchesETag(header, etag string) bool {
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -29,7 +29,7 @@ func (h *Handler) GetAppointmentServices(w http.ResponseWriter, r *http.Request)
 	var applicationErr error
 	identifier := handler.QueryParamToString(r, "identifier")
 	language := handler.QueryOptionalParamToString(r, "language")
-	var resp []dto.UserService
+	var resp *dto.UserServicesResp
 	resp, applicationErr = h.svc.GetAppointmentServices(
 		r.Context(),
 		identifier,
@@ -60,7 +60,7 @@ func (h *Handler) GetDefaultServicePoint(w http.ResponseWriter, r *http.Request)
 func (h *Handler) GetGenericServices(w http.ResponseWriter, r *http.Request) {
 	var applicationErr error
 	language := handler.QueryOptionalParamToString(r, "language")
-	var resp []dto.UserService
+	var resp *dto.UserServicesResp
 	resp, applicationErr = h.svc.GetGenericServices(
 		r.Context(),
 		language,
@@ -76,7 +76,7 @@ func (h *Handler) GetUserServices(w http.ResponseWriter, r *http.Request) {
 	var applicationErr error
 	identifier := handler.QueryParamToString(r, "identifier")
 	language := handler.QueryOptionalParamToString(r, "language")
-	var resp []dto.UserService
+	var resp *dto.UserServicesResp
 	resp, applicationErr = h.svc.GetUserServices(
 		r.Context(),
 		identifier,
@@ -114,3 +114,29 @@ func (h *Handler) SwipeCard(w http.ResponseWriter, r *http.Request) {
 	}
 	handler.WriteJson(r.Context(), w, 201, resp)
 }
+
+func (h *Handler) IssueWalkInTicket(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	roomId := handler.PathParamToString(r, "roomId")
+	req := dto.WalkInRequest{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.JoinResult
+	resp, applicationErr = h.svc.IssueWalkInTicket(
+		r.Context(),
+		roomId, &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 201, resp)
+}
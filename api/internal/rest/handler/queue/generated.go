@@ -40,6 +40,103 @@ func (h *Handler) GetQueueEntryByToken(w http.ResponseWriter, r *http.Request) {
 	handler.WriteJson(r.Context(), w, 200, resp)
 }
 
+func (h *Handler) CancelQueueEntryByToken(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	qrToken := handler.PathParamToString(r, "qrToken")
+	var resp *dto.PublicEntry
+	resp, applicationErr = h.svc.CancelQueueEntryByToken(
+		r.Context(),
+		qrToken,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) RegisterNotificationContact(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	qrToken := handler.PathParamToString(r, "qrToken")
+	req := dto.NotificationContact{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.PublicEntry
+	resp, applicationErr = h.svc.RegisterNotificationContact(
+		r.Context(),
+		qrToken, &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) GetWebPushSubscription(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	qrToken := handler.PathParamToString(r, "qrToken")
+	var resp *dto.WebPushStatus
+	resp, applicationErr = h.svc.GetWebPushSubscription(
+		r.Context(),
+		qrToken,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) UpdateWebPushSubscription(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	qrToken := handler.PathParamToString(r, "qrToken")
+	req := dto.WebPushSubscription{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.WebPushStatus
+	resp, applicationErr = h.svc.UpdateWebPushSubscription(
+		r.Context(),
+		qrToken, &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) DeleteWebPushSubscription(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	qrToken := handler.PathParamToString(r, "qrToken")
+	var resp *dto.WebPushStatus
+	resp, applicationErr = h.svc.DeleteWebPushSubscription(
+		r.Context(),
+		qrToken,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
 func (h *Handler) FinishCurrent(w http.ResponseWriter, r *http.Request) {
 	var applicationErr error
 	roomId := handler.PathParamToString(r, "roomId")
@@ -167,3 +264,287 @@ func (h *Handler) CallNext(w http.ResponseWriter, r *http.Request) {
 	}
 	handler.WriteJson(r.Context(), w, 200, resp)
 }
+
+func (h *Handler) TransferEntry(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	roomId := handler.PathParamToString(r, "roomId")
+	entryId := handler.PathParamToString(r, "entryId")
+	req := dto.TransferEntryRequest{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.QueueEntry
+	resp, applicationErr = h.svc.TransferEntry(
+		r.Context(),
+		roomId,
+		entryId, &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) GetEntryHistory(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	roomId := handler.PathParamToString(r, "roomId")
+	entryId := handler.PathParamToString(r, "entryId")
+	var resp []dto.EntryHistoryEvent
+	resp, applicationErr = h.svc.GetEntryHistory(
+		r.Context(),
+		roomId,
+		entryId,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) BoostEntryPriority(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	roomId := handler.PathParamToString(r, "roomId")
+	entryId := handler.PathParamToString(r, "entryId")
+	req := dto.PriorityBoostRequest{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.QueueEntry
+	resp, applicationErr = h.svc.BoostEntryPriority(
+		r.Context(),
+		roomId,
+		entryId, &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) RecallEntry(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	roomId := handler.PathParamToString(r, "roomId")
+	entryId := handler.PathParamToString(r, "entryId")
+	req := dto.RecallEntryRequest{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.QueueEntry
+	resp, applicationErr = h.svc.RecallEntry(
+		r.Context(),
+		roomId,
+		entryId, &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) BulkQueueOperation(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	roomId := handler.PathParamToString(r, "roomId")
+	req := dto.BulkQueueOperationRequest{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.BulkQueueOperationResult
+	resp, applicationErr = h.svc.BulkQueueOperation(
+		r.Context(),
+		roomId, &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) EraseDataSubject(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	req := dto.DataSubjectErasureRequest{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.DataSubjectErasureResult
+	resp, applicationErr = h.svc.EraseDataSubject(
+		r.Context(), &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	status := handler.QueryOptionalParamToString(r, "status")
+	limit, applicationErr := handler.QueryOptionalParamToInt32(r, "limit")
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	var resp []dto.WebhookDelivery
+	resp, applicationErr = h.svc.GetWebhookDeliveries(
+		r.Context(),
+		status,
+		limit,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) ReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	deliveryId := handler.PathParamToString(r, "deliveryId")
+	var resp *dto.WebhookDelivery
+	resp, applicationErr = h.svc.ReplayWebhookDelivery(
+		r.Context(),
+		deliveryId,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) GetRoomState(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	roomId := handler.PathParamToString(r, "roomId")
+	var resp []dto.RoomState
+	resp, applicationErr = h.svc.GetRoomState(
+		r.Context(),
+		roomId,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) SetRoomState(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	roomId := handler.PathParamToString(r, "roomId")
+	req := dto.RoomStateRequest{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.RoomState
+	resp, applicationErr = h.svc.SetRoomState(
+		r.Context(),
+		roomId, &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) SetServicePointState(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	roomId := handler.PathParamToString(r, "roomId")
+	servicePointId := handler.PathParamToString(r, "servicePointId")
+	req := dto.RoomStateRequest{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.RoomState
+	resp, applicationErr = h.svc.SetServicePointState(
+		r.Context(),
+		roomId,
+		servicePointId, &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) AnnotateEntry(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	roomId := handler.PathParamToString(r, "roomId")
+	entryId := handler.PathParamToString(r, "entryId")
+	req := dto.AnnotateEntryRequest{}
+	applicationErr = json.NewDecoder(r.Body).Decode(&req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.New(ngErrors.InternalServerErrorCode, "problem decoding request body", http.StatusInternalServerError, nil))
+		return
+	}
+	applicationErr = handler.GetValidator().Struct(req)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, ngErrors.RequestValidation(applicationErr))
+		return
+	}
+	var resp *dto.QueueEntry
+	resp, applicationErr = h.svc.AnnotateEntry(
+		r.Context(),
+		roomId,
+		entryId, &req,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
@@ -93,3 +93,48 @@ func (h *Handler) GetManagerStatusForRoom(w http.ResponseWriter, r *http.Request
 	}
 	handler.WriteJson(r.Context(), w, 200, resp)
 }
+
+func (h *Handler) ClaimServicePoint(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	roomId := handler.PathParamToString(r, "roomId")
+	servicePointId := handler.PathParamToString(r, "servicePointId")
+	var resp *dto.ManagerStatus
+	resp, applicationErr = h.svc.ClaimServicePoint(
+		r.Context(),
+		roomId, servicePointId,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) ReleaseServicePoint(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	roomId := handler.PathParamToString(r, "roomId")
+	servicePointId := handler.PathParamToString(r, "servicePointId")
+	var resp *dto.ManagerStatus
+	resp, applicationErr = h.svc.ReleaseServicePoint(
+		r.Context(),
+		roomId, servicePointId,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
+
+func (h *Handler) GetServicePointSessions(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	var resp []dto.ManagerStatus
+	resp, applicationErr = h.svc.GetServicePointSessions(
+		r.Context(),
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	handler.WriteJson(r.Context(), w, 200, resp)
+}
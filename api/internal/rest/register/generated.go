@@ -4,7 +4,13 @@ package register
 import (
 	"github.com/arfis/waiting-room/internal/middleware"
 	"github.com/arfis/waiting-room/internal/rest/handler/admin"
+	"github.com/arfis/waiting-room/internal/rest/handler/analytics"
+	"github.com/arfis/waiting-room/internal/rest/handler/appointment"
+	"github.com/arfis/waiting-room/internal/rest/handler/audit"
+	"github.com/arfis/waiting-room/internal/rest/handler/cardreader"
 	"github.com/arfis/waiting-room/internal/rest/handler/configuration"
+	"github.com/arfis/waiting-room/internal/rest/handler/i18n"
+	"github.com/arfis/waiting-room/internal/rest/handler/integration"
 	"github.com/arfis/waiting-room/internal/rest/handler/kiosk"
 	"github.com/arfis/waiting-room/internal/rest/handler/queue"
 	"github.com/arfis/waiting-room/internal/rest/handler/servicepoint"
@@ -15,51 +21,149 @@ import (
 func Generated(r chi.Router, diContainer *dig.Container) {
 	err := diContainer.Invoke(func(
 		adminHandler *admin.Handler,
+		analyticsHandler *analytics.Handler,
+		appointmentHandler *appointment.Handler,
+		auditHandler *audit.Handler,
 		kioskHandler *kiosk.Handler,
 		configurationHandler *configuration.Handler,
 		servicepointHandler *servicepoint.Handler,
 		queueHandler *queue.Handler,
+		cardreaderHandler *cardreader.Handler,
+		integrationHandler *integration.Handler,
+		i18nHandler *i18n.Handler,
 		authorizationMiddleware *middleware.AuthorizationMiddleware,
+		cardReaderAuthMiddleware *middleware.CardReaderAuthMiddleware,
+		integrationAuthMiddleware *middleware.IntegrationAuthMiddleware,
 	) error {
 
+		// Card reader routes (require device credential)
+		r.With(cardReaderAuthMiddleware.Middleware()).Group(func(device chi.Router) {
+			device.Get("/admin/card-reader-releases/latest", adminHandler.GetLatestCardReaderRelease)
+			device.Get("/admin/card-readers/{id}/config", adminHandler.GetCardReaderConfiguration)
+			device.Post("/card-readers/events", cardreaderHandler.IngestCardReaderEvent)
+
+		})
+
+		// Integration routes (require tenant API key and request signature)
+		r.With(integrationAuthMiddleware.Middleware()).Group(func(external chi.Router) {
+			external.Post("/integrations/events", integrationHandler.IngestIntegrationEvent)
+
+		})
+
+		// Public routes
+		r.Group(func(public chi.Router) {
+			public.Get("/queue-entries/token/{qrToken}", queueHandler.GetQueueEntryByToken)
+			public.Post("/queue-entries/token/{qrToken}/cancel", queueHandler.CancelQueueEntryByToken)
+			public.Put("/queue-entries/token/{qrToken}/contact", queueHandler.RegisterNotificationContact)
+			public.Get("/queue-entries/token/{qrToken}/push-subscription", queueHandler.GetWebPushSubscription)
+			public.Put("/queue-entries/token/{qrToken}/push-subscription", queueHandler.UpdateWebPushSubscription)
+			public.Delete("/queue-entries/token/{qrToken}/push-subscription", queueHandler.DeleteWebPushSubscription)
+
+		})
+
 		// Protected routes (require JWT)
 		r.With(authorizationMiddleware.Middleware()).Group(func(protected chi.Router) {
-			protected.Get("/admin/card-readers", adminHandler.GetCardReaders)
-			protected.Post("/admin/card-readers/{id}/restart", adminHandler.RestartCardReader)
-			protected.Get("/admin/configuration", adminHandler.GetSystemConfiguration)
-			protected.Put("/admin/configuration", adminHandler.UpdateSystemConfiguration)
-			protected.Get("/admin/configuration/external-api", adminHandler.GetExternalAPIConfiguration)
-			protected.Put("/admin/configuration/external-api", adminHandler.UpdateExternalAPIConfiguration)
-			protected.Get("/admin/configuration/rooms", adminHandler.GetRoomsConfiguration)
-			protected.Put("/admin/configuration/rooms", adminHandler.UpdateRoomsConfiguration)
-			protected.Get("/admin/priority-config", adminHandler.GetPriorityConfiguration)
-			protected.Put("/admin/priority-config", adminHandler.UpdatePriorityConfiguration)
-			protected.Get("/admin/priority-config/default", adminHandler.GetDefaultPriorityConfiguration)
-			protected.Get("/admin/tenants", adminHandler.GetAllTenants)
-			protected.Post("/admin/tenants", adminHandler.CreateTenant)
-			protected.Put("/admin/tenants", adminHandler.UpdateTenant)
-			protected.Delete("/admin/tenants/{id}", adminHandler.DeleteTenant)
-			protected.Get("/admin/tenants/{id}", adminHandler.GetTenant)
-			protected.Delete("/admin/translation/cache", adminHandler.ClearTranslationCache)
-			protected.Get("/admin/translation/cache/stats", adminHandler.GetTranslationCacheStats)
-			protected.Get("/appointment-services", kioskHandler.GetAppointmentServices)
-			protected.Get("/config", configurationHandler.GetConfiguration)
-			protected.Get("/default-service-point", kioskHandler.GetDefaultServicePoint)
-			protected.Get("/generic-services", kioskHandler.GetGenericServices)
-			protected.Get("/managers/status", servicepointHandler.GetManagerStatus)
-			protected.Post("/managers/{managerId}/login", servicepointHandler.ManagerLogin)
-			protected.Post("/managers/{managerId}/logout", servicepointHandler.ManagerLogout)
-			protected.Get("/queue-entries/token/{qrToken}", queueHandler.GetQueueEntryByToken)
-			protected.Get("/user-services", kioskHandler.GetUserServices)
-			protected.Post("/waiting-rooms/{roomId}/finish", queueHandler.FinishCurrent)
-			protected.Get("/waiting-rooms/{roomId}/managers/status", servicepointHandler.GetManagerStatusForRoom)
-			protected.Get("/waiting-rooms/{roomId}/queue", queueHandler.GetQueueEntries)
-			protected.Get("/waiting-rooms/{roomId}/service-points", queueHandler.GetServicePoints)
-			protected.Post("/waiting-rooms/{roomId}/service-points/{servicePointId}/call/{entryId}", queueHandler.CallSpecificEntry)
-			protected.Post("/waiting-rooms/{roomId}/service-points/{servicePointId}/finish-current", queueHandler.FinishCurrentForServicePoint)
-			protected.Post("/waiting-rooms/{roomId}/service-points/{servicePointId}/mark-in-room", queueHandler.MarkInRoomForServicePoint)
-			protected.Post("/waiting-rooms/{roomId}/service-points/{servicePointId}/next", queueHandler.CallNext)
-			protected.Post("/waiting-rooms/{roomId}/swipe", kioskHandler.SwipeCard)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/analytics/archived-entries", analyticsHandler.GetArchivedEntries)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/analytics/daily-stats", analyticsHandler.GetDailyStatistics)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/api-keys", adminHandler.GetAPIKeys)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/api-keys", adminHandler.CreateAPIKey)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/api-keys/{id}/revoke", adminHandler.RevokeAPIKey)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/audit-log", auditHandler.GetAuditLog)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Put("/admin/card-reader-releases", adminHandler.PublishCardReaderRelease)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/card-readers", adminHandler.GetCardReaders)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/card-readers/{id}/commands", adminHandler.GetCardReaderCommands)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/card-readers/{id}/commands", adminHandler.EnqueueCardReaderCommand)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Put("/admin/card-readers/{id}/config", adminHandler.UpdateCardReaderConfiguration)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/card-readers/{id}/encryption-key", adminHandler.RotateCardReaderEncryptionKey)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/card-readers/{id}/restart", adminHandler.RestartCardReader)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/card-readers/{id}/token", adminHandler.IssueCardReaderToken)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/configuration", adminHandler.GetSystemConfiguration)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Put("/admin/configuration", adminHandler.UpdateSystemConfiguration)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/configuration/export", adminHandler.ExportConfiguration)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/configuration/external-api", adminHandler.GetExternalAPIConfiguration)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Put("/admin/configuration/external-api", adminHandler.UpdateExternalAPIConfiguration)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/configuration/fhir", adminHandler.GetFhirSettings)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Put("/admin/configuration/fhir", adminHandler.UpdateFhirSettings)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/configuration/import", adminHandler.ImportConfiguration)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/configuration/import/dry-run", adminHandler.DryRunConfigurationImport)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/configuration/integration/credentials", adminHandler.IssueIntegrationCredentials)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/configuration/notifications", adminHandler.GetNotificationSettings)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Put("/admin/configuration/notifications", adminHandler.UpdateNotificationSettings)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/configuration/notifications/vapid-keys", adminHandler.GenerateVapidKeys)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/configuration/pathways", adminHandler.GetPathwaysConfiguration)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Put("/admin/configuration/pathways", adminHandler.UpdatePathwaysConfiguration)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/configuration/resolved", adminHandler.GetResolvedConfiguration)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/configuration/retention", adminHandler.GetRetentionPolicy)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Put("/admin/configuration/retention", adminHandler.UpdateRetentionPolicy)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/configuration/rooms", adminHandler.GetRoomsConfiguration)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Put("/admin/configuration/rooms", adminHandler.UpdateRoomsConfiguration)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/configuration/rooms", adminHandler.CreateRoom)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Put("/admin/configuration/rooms/{roomId}", adminHandler.UpdateRoom)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Delete("/admin/configuration/rooms/{roomId}", adminHandler.DeleteRoom)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/configuration/rooms/{roomId}/service-points", adminHandler.AddServicePoint)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Delete("/admin/configuration/rooms/{roomId}/service-points/{servicePointId}", adminHandler.RemoveServicePoint)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/data-subjects/erasure", queueHandler.EraseDataSubject)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/external-api/stats", adminHandler.GetExternalAPIStats)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/i18n", i18nHandler.GetMessageCatalogs)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/i18n/translate", i18nHandler.TranslateMessageCatalogs)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Put("/admin/i18n/{lang}", i18nHandler.UpdateMessageCatalog)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Delete("/admin/i18n/{lang}", i18nHandler.DeleteMessageCatalog)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/priority-config", adminHandler.GetPriorityConfiguration)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Put("/admin/priority-config", adminHandler.UpdatePriorityConfiguration)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/priority-config/default", adminHandler.GetDefaultPriorityConfiguration)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/priority-config/dry-run", adminHandler.DryRunPriorityConfiguration)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/priority-config/revisions", adminHandler.GetPriorityConfigurationRevisions)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/priority-config/revisions/{revision}/rollback", adminHandler.RollbackPriorityConfiguration)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/priority-config/symbols", adminHandler.GetPrioritySymbols)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/priority-config/symbols", adminHandler.CreatePrioritySymbol)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Put("/admin/priority-config/symbols/{code}", adminHandler.UpdatePrioritySymbol)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Delete("/admin/priority-config/symbols/{code}", adminHandler.DeletePrioritySymbol)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/service-point-sessions", servicepointHandler.GetServicePointSessions)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/subscription-tokens", adminHandler.CreateSubscriptionToken)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/tenants", adminHandler.GetAllTenants)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/tenants", adminHandler.CreateTenant)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Put("/admin/tenants", adminHandler.UpdateTenant)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/tenants/provision", adminHandler.ProvisionTenant)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/tenants/{id}", adminHandler.GetTenant)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Delete("/admin/tenants/{id}", adminHandler.DeleteTenant)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/tenants/{id}/reactivate", adminHandler.ReactivateTenant)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Delete("/admin/translation/cache", adminHandler.ClearTranslationCache)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/translation/cache/stats", adminHandler.GetTranslationCacheStats)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/translation/glossary", adminHandler.GetTranslationGlossary)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Put("/admin/translation/glossary", adminHandler.UpdateTranslationGlossary)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/waiting-rooms/{roomId}/queue/bulk", queueHandler.BulkQueueOperation)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Get("/admin/webhooks/deliveries", queueHandler.GetWebhookDeliveries)
+			protected.With(authorizationMiddleware.RequireRoles("admin")).Post("/admin/webhooks/deliveries/{deliveryId}/replay", queueHandler.ReplayWebhookDelivery)
+			protected.With(authorizationMiddleware.RequireRoles("kiosk")).Get("/appointment-services", kioskHandler.GetAppointmentServices)
+			protected.With(authorizationMiddleware.RequireRoles("kiosk", "staff")).Get("/appointments", appointmentHandler.GetAppointments)
+			protected.With(authorizationMiddleware.RequireRoles("staff")).Put("/appointments", appointmentHandler.UpsertAppointments)
+			protected.With(authorizationMiddleware.RequireRoles("kiosk", "display", "staff")).Get("/config", configurationHandler.GetConfiguration)
+			protected.With(authorizationMiddleware.RequireRoles("kiosk", "staff")).Get("/default-service-point", kioskHandler.GetDefaultServicePoint)
+			protected.With(authorizationMiddleware.RequireRoles("kiosk")).Get("/generic-services", kioskHandler.GetGenericServices)
+			protected.With(authorizationMiddleware.RequireRoles("staff")).Get("/managers/status", servicepointHandler.GetManagerStatus)
+			protected.With(authorizationMiddleware.RequireRoles("staff")).Post("/managers/{managerId}/login", servicepointHandler.ManagerLogin)
+			protected.With(authorizationMiddleware.RequireRoles("staff")).Post("/managers/{managerId}/logout", servicepointHandler.ManagerLogout)
+			protected.With(authorizationMiddleware.RequireRoles("kiosk")).Get("/user-services", kioskHandler.GetUserServices)
+			protected.With(authorizationMiddleware.RequireRoles("staff")).Post("/waiting-rooms/{roomId}/finish", queueHandler.FinishCurrent)
+			protected.With(authorizationMiddleware.RequireRoles("display", "staff")).Get("/waiting-rooms/{roomId}/managers/status", servicepointHandler.GetManagerStatusForRoom)
+			protected.With(authorizationMiddleware.RequireRoles("display", "staff")).Get("/waiting-rooms/{roomId}/queue", queueHandler.GetQueueEntries)
+			protected.With(authorizationMiddleware.RequireRoles("staff")).Patch("/waiting-rooms/{roomId}/queue/{entryId}", queueHandler.AnnotateEntry)
+			protected.With(authorizationMiddleware.RequireRoles("staff")).Get("/waiting-rooms/{roomId}/queue/{entryId}/history", queueHandler.GetEntryHistory)
+			protected.With(authorizationMiddleware.RequireRoles("staff")).Post("/waiting-rooms/{roomId}/queue/{entryId}/priority", queueHandler.BoostEntryPriority)
+			protected.With(authorizationMiddleware.RequireRoles("staff")).Post("/waiting-rooms/{roomId}/queue/{entryId}/recall", queueHandler.RecallEntry)
+			protected.With(authorizationMiddleware.RequireRoles("staff")).Post("/waiting-rooms/{roomId}/queue/{entryId}/transfer", queueHandler.TransferEntry)
+			protected.With(authorizationMiddleware.RequireRoles("display", "staff")).Get("/waiting-rooms/{roomId}/service-points", queueHandler.GetServicePoints)
+			protected.With(authorizationMiddleware.RequireRoles("staff")).Post("/waiting-rooms/{roomId}/service-points/{servicePointId}/call/{entryId}", queueHandler.CallSpecificEntry)
+			protected.With(authorizationMiddleware.RequireRoles("staff")).Post("/waiting-rooms/{roomId}/service-points/{servicePointId}/claim", servicepointHandler.ClaimServicePoint)
+			protected.With(authorizationMiddleware.RequireRoles("staff")).Post("/waiting-rooms/{roomId}/service-points/{servicePointId}/finish-current", queueHandler.FinishCurrentForServicePoint)
+			protected.With(authorizationMiddleware.RequireRoles("staff")).Post("/waiting-rooms/{roomId}/service-points/{servicePointId}/mark-in-room", queueHandler.MarkInRoomForServicePoint)
+			protected.With(authorizationMiddleware.RequireRoles("staff")).Post("/waiting-rooms/{roomId}/service-points/{servicePointId}/next", queueHandler.CallNext)
+			protected.With(authorizationMiddleware.RequireRoles("staff")).Post("/waiting-rooms/{roomId}/service-points/{servicePointId}/release", servicepointHandler.ReleaseServicePoint)
+			protected.With(authorizationMiddleware.RequireRoles("staff")).Put("/waiting-rooms/{roomId}/service-points/{servicePointId}/state", queueHandler.SetServicePointState)
+			protected.With(authorizationMiddleware.RequireRoles("kiosk", "display", "staff")).Get("/waiting-rooms/{roomId}/state", queueHandler.GetRoomState)
+			protected.With(authorizationMiddleware.RequireRoles("staff")).Put("/waiting-rooms/{roomId}/state", queueHandler.SetRoomState)
+			protected.With(authorizationMiddleware.RequireRoles("kiosk")).Post("/waiting-rooms/{roomId}/swipe", kioskHandler.SwipeCard)
+			protected.With(authorizationMiddleware.RequireRoles("kiosk", "staff")).Post("/waiting-rooms/{roomId}/walk-in", kioskHandler.IssueWalkInTicket)
 
 		})
 
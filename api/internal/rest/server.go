@@ -1,8 +1,14 @@
 package rest
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
@@ -10,11 +16,21 @@ import (
 	"go.uber.org/dig"
 
 	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/metrics"
 	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/queue"
+	cardreaderHandler "github.com/arfis/waiting-room/internal/rest/handler/cardreader"
+	fhirHandler "github.com/arfis/waiting-room/internal/rest/handler/fhir"
+	i18nHandler "github.com/arfis/waiting-room/internal/rest/handler/i18n"
 	"github.com/arfis/waiting-room/internal/rest/register"
 	"github.com/arfis/waiting-room/internal/websocket"
+	announcementService "github.com/arfis/waiting-room/internal/service/announcement"
+	cardreaderService "github.com/arfis/waiting-room/internal/service/cardreader"
+	configService "github.com/arfis/waiting-room/internal/service/config"
 	kioskService "github.com/arfis/waiting-room/internal/service/kiosk"
+	notificationService "github.com/arfis/waiting-room/internal/service/notification"
 	queueServiceGenerated "github.com/arfis/waiting-room/internal/service/queue"
+	tenantService "github.com/arfis/waiting-room/internal/service/tenant"
 )
 
 // NewServer creates and configures the HTTP server with all routes and middleware
@@ -26,7 +42,7 @@ func NewServer(diContainer *dig.Container, cfg *config.Config) *http.Server {
 	r.Use(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Skip CORS for WebSocket routes
-			if strings.HasPrefix(r.URL.Path, cfg.WebSocket.Path) || r.URL.Path == "/health" {
+			if strings.HasPrefix(r.URL.Path, cfg.WebSocket.Path) || r.URL.Path == cfg.WebSocket.CardReaderPath || isGRPC(r) || r.URL.Path == "/health" || (cfg.Metrics.Enabled && r.URL.Path == cfg.Metrics.Path) {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -150,7 +166,7 @@ func NewServer(diContainer *dig.Container, cfg *config.Config) *http.Server {
 				w.Header().Set("Access-Control-Allow-Headers", corsHeaders)
 			}
 
-			w.Header().Set("Access-Control-Expose-Headers", "Content-Length, Content-Type")
+			w.Header().Set("Access-Control-Expose-Headers", "Content-Length, Content-Type, Retry-After")
 			w.Header().Set("Access-Control-Max-Age", "86400") // Cache preflight for 24 hours
 
 			// Handle preflight OPTIONS requests
@@ -171,6 +187,11 @@ func NewServer(diContainer *dig.Container, cfg *config.Config) *http.Server {
 		log.Println("Tenant middleware registered")
 	})
 
+	// Clients polling too often get 429 before their requests reach the queue
+	diContainer.Invoke(func(rateLimitMiddleware *middleware.RateLimitMiddleware) {
+		r.Use(rateLimitMiddleware.Middleware())
+	})
+
 	// Temporarily remove other middleware to isolate WebSocket issue
 	// diContainer.Invoke(func(loggingMiddleware *middleware.LoggingMiddleware) {
 	// 	r.Use(loggingMiddleware.LoggingMiddleware)
@@ -179,38 +200,217 @@ func NewServer(diContainer *dig.Container, cfg *config.Config) *http.Server {
 
 	// Create WebSocket hub for handling WebSocket connections
 	var wsHub *websocket.Hub
-	diContainer.Invoke(func(kioskService *kioskService.Service, queueServiceGenerated *queueServiceGenerated.Service) {
-		wsHub = websocket.NewHub(queueServiceGenerated)
+	// Phone pages follow the status of their QR ticket as Server-Sent Events
+	var statusStream *websocket.StatusStream
+	// Speaker systems play the announcements of calls
+	var announcements *announcementService.Service
+	diContainer.Invoke(func(kioskService *kioskService.Service, queueSvc *queueServiceGenerated.Service, announcementSvc *announcementService.Service, notifications *notificationService.Service) {
+		wsHub = websocket.NewHub(queueSvc, cfg.WebSocket.TokenSecret)
+		if cfg.WebSocket.AllowTokenlessSubscriptions {
+			wsHub.AllowTokenlessSubscriptions()
+			log.Println("Warning: websocket.allow_tokenless_subscriptions is set, WebSocket clients without a token get the staff view and card data of the tenant they name")
+		}
+		if cfg.WebSocket.TokenSecret == "" {
+			log.Println("Warning: websocket.token_secret is not set, every WebSocket subscription with a token will be rejected")
+		}
+		wsHub.StartReaper(context.Background())
+		statusStream = websocket.NewStatusStream(queueSvc)
+
+		// Set up broadcast function for services that need it. Changes broadcast by a service
+		// and reported by the change watcher are sent once. Patients whose turn became near are
+		// notified from every replica's updates, each one only once.
+		broadcast := websocket.CoalesceBroadcasts(websocket.BroadcastCoalesceWindow, func(roomId string, tenantID string) {
+			wsHub.BroadcastQueueUpdate(roomId, tenantID)
+			statusStream.NotifyRoom(roomId, tenantID)
+			notifications.NotifyRoom(roomId, tenantID)
+		})
+
+		// Display boards get every call
+		broadcastCall := wsHub.BroadcastCall
 
-		// Set up broadcast function for services that need it
-		kioskService.SetBroadcastFunc(wsHub.BroadcastQueueUpdate)
-		queueServiceGenerated.SetBroadcastFunc(wsHub.BroadcastQueueUpdate)
+		// With several replicas, the updates of each one reach the clients of the others through Redis
+		if fanoutCfg := cfg.WebSocket.Fanout; fanoutCfg.RedisURL != "" {
+			fanout, err := websocket.NewRedisFanout(fanoutCfg.RedisURL, fanoutCfg.Channel, broadcast)
+			if err != nil {
+				log.Printf("Failed to connect the WebSocket fan-out, updates stay on this replica: %v", err)
+			} else {
+				fanout.SetCallHandler(wsHub.BroadcastCall)
+				go fanout.Run(context.Background())
+				deliver := broadcast
+				broadcast = func(roomId string, tenantID string) {
+					deliver(roomId, tenantID)
+					fanout.Publish(roomId, tenantID)
+				}
+				broadcastCall = func(call queueServiceGenerated.CallEvent) {
+					wsHub.BroadcastCall(call)
+					fanout.PublishCall(call)
+				}
+			}
+		}
+		kioskService.SetBroadcastFunc(broadcast)
+		queueSvc.SetBroadcastFunc(broadcast)
+		// Calls are announced, and their patients notified, by the replica they were made on
+		announcements = announcementSvc
+		if announcements.Enabled() {
+			announcements.SetReadyHandler(wsHub.BroadcastAnnouncement)
+			toBoards := broadcastCall
+			broadcastCall = func(call queueServiceGenerated.CallEvent) {
+				toBoards(call)
+				announcements.Announce(call)
+			}
+		}
+		toListeners := broadcastCall
+		broadcastCall = func(call queueServiceGenerated.CallEvent) {
+			toListeners(call)
+			notifications.NotifyCalled(call)
+		}
+		queueSvc.SetCallHandler(broadcastCall)
 		log.Println("Broadcast function set up for kiosk and queue services")
 	})
 
+	// Kiosks and displays fetch their settings again when the configuration of their tenant changes
+	diContainer.Invoke(func(configSvc *configService.Service) {
+		if wsHub != nil {
+			configSvc.OnConfigurationChange(wsHub.BroadcastConfigUpdate)
+		}
+	})
+
+	// Deactivated tenants take no new WebSocket subscriptions; card reader events are routed to
+	// the registered tenant of their reader
+	diContainer.Invoke(func(tenantSvc *tenantService.Service, cardreaderSvc *cardreaderService.Service) {
+		if wsHub != nil {
+			wsHub.SetTenantResolver(tenantSvc)
+		}
+		cardreaderSvc.SetTenantResolver(tenantSvc)
+	})
+
+	// Card readers push their events over WebSocket; accepted events are relayed to the kiosks
+	var cardReaderHub *websocket.CardReaderHub
+	diContainer.Invoke(func(cardreaderService *cardreaderService.Service, cardReaderAuthMiddleware *middleware.CardReaderAuthMiddleware) {
+		cardReaderHub = websocket.NewCardReaderHub(cardreaderService, cardReaderAuthMiddleware, cfg.WebSocket.TokenSecret)
+		if cfg.WebSocket.AllowTokenlessSubscriptions {
+			cardReaderHub.AllowTokenlessSubscriptions()
+		}
+		cardreaderService.SetEventHandler(cardReaderHub.ForwardEvent)
+	})
+	// Commands queued by admins go out right away to the card readers connected here
+	diContainer.Invoke(func(configSvc *configService.Service) {
+		configSvc.OnCardReaderCommand(cardReaderHub.NotifyCommand)
+	})
+
+	// Card readers with TRANSPORT=grpc call the CardReaderIngest service on this port, with the
+	// device credential in the metadata
+	diContainer.Invoke(func(cardreaderService *cardreaderService.Service, cardReaderAuthMiddleware *middleware.CardReaderAuthMiddleware) {
+		grpcServer := cardreaderHandler.NewGRPCServer(cardreaderService)
+		r.With(cardReaderAuthMiddleware.Middleware()).Handle(grpcServer.ServicePath()+"*", grpcServer.Handler())
+		log.Printf("Card reader gRPC service registered at %s", grpcServer.ServicePath())
+	})
+
 	// todo: has to be later updated to use configuration.ServerContext
 	// Register API routes - CORS middleware is already applied above
 	r.Route("/api", func(router chi.Router) {
+		// Kiosks asking for a language the tenant doesn't support get its default language
+		diContainer.Invoke(func(languageMiddleware *middleware.LanguageMiddleware) {
+			router.Use(languageMiddleware.Middleware())
+		})
 		register.Generated(router, diContainer)
+
+		// Integration engines read and push FHIR resources with the API key of the tenant
+		diContainer.Invoke(func(fhirHandler *fhirHandler.Handler, integrationAuthMiddleware *middleware.IntegrationAuthMiddleware) {
+			router.With(integrationAuthMiddleware.APIKeyMiddleware()).Route("/fhir", fhirHandler.Routes)
+		})
+
+		// Kiosks and displays load their messages in the language of the patient
+		diContainer.Invoke(func(i18nHandler *i18nHandler.Handler) {
+			router.Get("/i18n/{lang}", i18nHandler.GetMessages)
+		})
 	})
 
+	if statusStream != nil {
+		r.Get("/q/{token}/events", statusStream.HandleEvents)
+		log.Println("QR status stream registered at /q/{token}/events")
+	}
+
+	if announcements != nil && announcements.Enabled() {
+		r.Get("/announcements/{id}.mp3", announcementAudio(announcements))
+		log.Println("Announcement audio registered at /announcements/{id}.mp3")
+	}
+
 	// Add WebSocket routes AFTER middleware (like the original working version)
 	if wsHub != nil && cfg.WebSocket.Enabled {
 		r.Get(cfg.WebSocket.Path+"/{roomId}", wsHub.HandleConnection)
 		r.Get("/health", healthCheck)
 		log.Printf("WebSocket routes registered at %s/{roomId}", cfg.WebSocket.Path)
+		if cardReaderHub != nil {
+			r.Get(cfg.WebSocket.CardReaderPath, cardReaderHub.HandleConnection)
+			log.Printf("Card reader WebSocket registered at %s", cfg.WebSocket.CardReaderPath)
+		}
 	} else if !cfg.WebSocket.Enabled {
 		log.Println("WebSocket disabled in configuration")
 	} else {
 		log.Println("ERROR: wsHub is nil, cannot register WebSocket routes")
 	}
 
+	// Prometheus scrapes the metrics of this replica; the gauges are collected on every scrape
+	if cfg.Metrics.Enabled {
+		diContainer.Invoke(func(waitingQueue *queue.WaitingQueue) {
+			metrics.QueueDepth.SetCollect(waitingQueue.CollectQueueDepth)
+		})
+		if wsHub != nil {
+			metrics.WebSocketClients.SetCollect(websocket.ClientCounter(wsHub, cardReaderHub))
+		}
+		r.Method(http.MethodGet, cfg.Metrics.Path, metrics.Default.Handler(cfg.Metrics.Token))
+		if cfg.Metrics.Token == "" {
+			log.Printf("Warning: metrics.token is not set, anyone who can reach %s can read the tenants and rooms in the metrics", cfg.Metrics.Path)
+		}
+		log.Printf("Metrics registered at %s", cfg.Metrics.Path)
+	}
+
 	// Create server with configuration
-	return &http.Server{
+	server := &http.Server{
 		Addr:              cfg.GetAddress(),
 		Handler:           r,
 		ReadHeaderTimeout: 2 * time.Second,
 	}
+	// gRPC needs HTTP/2, which clients without TLS speak with prior knowledge
+	server.Protocols = new(http.Protocols)
+	server.Protocols.SetHTTP1(true)
+	server.Protocols.SetHTTP2(true)
+	server.Protocols.SetUnencryptedHTTP2(true)
+
+	if cfg.Server.TLS.Enabled() && cfg.Server.TLS.ClientCAFile != "" {
+		tlsConfig, err := clientCertTLSConfig(cfg.Server.TLS.ClientCAFile)
+		if err != nil {
+			log.Fatalf("Failed to load client CA: %v", err)
+		}
+		server.TLSConfig = tlsConfig
+		log.Printf("Mutual TLS enabled for card readers (client CA: %s)", cfg.Server.TLS.ClientCAFile)
+	}
+
+	return server
+}
+
+// isGRPC reports whether the request is a gRPC call
+func isGRPC(r *http.Request) bool {
+	return r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
+
+// clientCertTLSConfig verifies client certificates when they are presented. Browsers
+// connect without one; card readers may authenticate with a certificate instead of a token.
+func clientCertTLSConfig(clientCAFile string) (*tls.Config, error) {
+	pem, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", clientCAFile)
+	}
+	return &tls.Config{
+		ClientCAs:  pool,
+		ClientAuth: tls.VerifyClientCertIfGiven,
+		MinVersion: tls.VersionTLS12,
+	}, nil
 }
 
 // healthCheck is a simple health check endpoint
@@ -220,6 +420,20 @@ func healthCheck(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`{"status":"ok"}`))
 }
 
+// announcementAudio serves the MP3 audio of a kept announcement
+func announcementAudio(announcements *announcementService.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		announcement, ok := announcements.Get(chi.URLParam(r, "id"))
+		if !ok {
+			http.Error(w, "announcement not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Header().Set("Cache-Control", "private, max-age=3600")
+		http.ServeContent(w, r, announcement.ID+".mp3", announcement.CreatedAt, bytes.NewReader(announcement.Audio))
+	}
+}
+
 // contains checks if a string slice contains a specific string
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
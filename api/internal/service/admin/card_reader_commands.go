@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// defaultCardReaderCommandLimit is how many commands of a card reader are listed when the request
+// doesn't say
+const defaultCardReaderCommandLimit = 20
+
+// EnqueueCardReaderCommand queues a command for a card reader of the tenant
+func (s *Service) EnqueueCardReaderCommand(ctx context.Context, id string, req *dto.CardReaderCommandRequest) (*dto.CardReaderCommand, error) {
+	command, err := s.configService.EnqueueCardReaderCommand(ctx, id, req.Command)
+	if err != nil {
+		return nil, err
+	}
+	return convertCardReaderCommandToDTO(*command), nil
+}
+
+// GetCardReaderCommands returns the newest commands of a card reader of the tenant
+func (s *Service) GetCardReaderCommands(ctx context.Context, id string, limit *int32) ([]dto.CardReaderCommand, error) {
+	n := defaultCardReaderCommandLimit
+	if limit != nil && *limit > 0 {
+		n = int(*limit)
+	}
+	commands, err := s.configService.GetCardReaderCommands(ctx, id, n)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]dto.CardReaderCommand, 0, len(commands))
+	for _, command := range commands {
+		result = append(result, *convertCardReaderCommandToDTO(command))
+	}
+	return result, nil
+}
+
+// RestartCardReader queues a restart command for a card reader
+func (s *Service) RestartCardReader(ctx context.Context, id string) (*dto.RestartResponse, error) {
+	command, err := s.configService.EnqueueCardReaderCommand(ctx, id, types.CardReaderCommandRestart)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.RestartResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Restart command queued for card reader %s", id),
+		CommandId: &command.ID,
+	}, nil
+}
+
+func convertCardReaderCommandToDTO(command types.CardReaderCommand) *dto.CardReaderCommand {
+	result := &dto.CardReaderCommand{
+		Id:             command.ID,
+		DeviceId:       command.DeviceID,
+		Command:        command.Command,
+		Status:         command.Status,
+		CreatedAt:      command.CreatedAt,
+		SentAt:         command.SentAt,
+		AcknowledgedAt: command.AcknowledgedAt,
+		CompletedAt:    command.CompletedAt,
+	}
+	if command.RequestedBy != "" {
+		result.RequestedBy = &command.RequestedBy
+	}
+	if command.Output != "" {
+		result.Output = &command.Output
+	}
+	if command.Error != "" {
+		result.Error = &command.Error
+	}
+	return result
+}
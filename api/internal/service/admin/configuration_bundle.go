@@ -0,0 +1,259 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/audit"
+	"github.com/arfis/waiting-room/internal/configbundle"
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/priority"
+	"github.com/arfis/waiting-room/internal/service"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// configurationBundleVersion is the version of the configuration bundle format
+const configurationBundleVersion = 1
+
+// bundleMetadataFields describe the stored configuration document rather than configuring
+// anything; the environment a bundle is imported into keeps its own
+var bundleMetadataFields = []string{"id", "tenantId", "sectionId", "createdAt", "updatedAt"}
+
+// bundleContent is what importing a bundle compares and replaces
+type bundleContent struct {
+	Configuration *types.SystemConfiguration     `json:"configuration"`
+	Priority      *priority.PriorityConfig       `json:"priority"`
+	Glossary      []dto.TranslationGlossaryEntry `json:"glossary"`
+}
+
+// ExportConfiguration returns the configuration stored at the layer of the tenant, its priority
+// configuration and its glossary as a bundle signed with the bundle signing key. Secrets are left
+// out; the environment the bundle is imported into keeps its own.
+func (s *Service) ExportConfiguration(ctx context.Context) (*dto.ConfigurationBundle, error) {
+	if s.bundleSigningKey == "" {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "configuration.bundle_signing_key is not configured", 400, nil)
+	}
+
+	stored, err := s.configService.GetStoredConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	configuration, err := configbundle.ToObject(stored)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range bundleMetadataFields {
+		delete(configuration, field)
+	}
+	configbundle.StripSecrets(configuration)
+
+	priorityConfig, err := s.priorityService.GetPriorityConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	glossary, err := s.GetTranslationGlossary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range glossary {
+		glossary[i].UpdatedAt = nil
+	}
+
+	bundle := &dto.ConfigurationBundle{
+		Version:       configurationBundleVersion,
+		ExportedAt:    time.Now().UTC().Truncate(time.Second),
+		Configuration: configuration,
+		Priority:      s.convertPriorityConfigToDTO(priorityConfig),
+		Glossary:      glossary,
+	}
+	if tenantID := service.GetTenantID(ctx); tenantID != "" {
+		bundle.TenantId = &tenantID
+	}
+	bundle.Signature, err = configbundle.Sign(bundle, s.bundleSigningKey)
+	if err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// ImportConfiguration replaces the configuration stored at the layer of the tenant, its priority
+// configuration and its glossary with the ones of a bundle exported by an environment sharing
+// the bundle signing key. Only the parts that differ are saved, each recorded in the audit log.
+func (s *Service) ImportConfiguration(ctx context.Context, bundle *dto.ConfigurationBundle) (*dto.ConfigurationImportResult, error) {
+	return s.importConfiguration(ctx, bundle, true)
+}
+
+// DryRunConfigurationImport returns the changes importing a bundle would make, without making them
+func (s *Service) DryRunConfigurationImport(ctx context.Context, bundle *dto.ConfigurationBundle) (*dto.ConfigurationImportResult, error) {
+	return s.importConfiguration(ctx, bundle, false)
+}
+
+func (s *Service) importConfiguration(ctx context.Context, bundle *dto.ConfigurationBundle, apply bool) (*dto.ConfigurationImportResult, error) {
+	if s.bundleSigningKey == "" {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "configuration.bundle_signing_key is not configured", 400, nil)
+	}
+	unsigned := *bundle
+	unsigned.Signature = ""
+	valid, err := configbundle.Verify(unsigned, bundle.Signature, s.bundleSigningKey)
+	if err != nil {
+		return nil, ngErrors.InvalidConfigurationBundle(err.Error())
+	}
+	if !valid {
+		return nil, ngErrors.InvalidConfigurationBundle("the signature does not match, was it exported with the same bundle signing key?")
+	}
+	if bundle.Version != configurationBundleVersion {
+		return nil, ngErrors.InvalidConfigurationBundle(fmt.Sprintf("version %d is not supported", bundle.Version))
+	}
+
+	before, err := s.currentBundleContent(ctx)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.importedBundleContent(ctx, bundle, before.Configuration)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := audit.Diff(before, after)
+	if err != nil {
+		return nil, err
+	}
+	changed := map[string]bool{}
+	result := &dto.ConfigurationImportResult{
+		Applied:        apply,
+		Changes:        make([]dto.AuditChange, len(changes)),
+		ExportedAt:     &bundle.ExportedAt,
+		SourceTenantId: bundle.TenantId,
+	}
+	for i, change := range changes {
+		result.Changes[i] = dto.AuditChange{Field: change.Field, Before: change.Before, After: change.After}
+		section, _, _ := strings.Cut(change.Field, ".")
+		changed[section] = true
+	}
+	if !apply {
+		return result, nil
+	}
+
+	if changed["configuration"] {
+		if err := s.configService.UpdateSystemConfiguration(ctx, configurationFields(after.Configuration)); err != nil {
+			return nil, err
+		}
+	}
+	if changed["priority"] {
+		if err := s.priorityService.SavePriorityConfig(ctx, after.Priority); err != nil {
+			return nil, priorityConfigError(err)
+		}
+	}
+	if changed["glossary"] {
+		glossary, _ := glossaryFromDTO(after.Glossary)
+		if err := s.translationService.SetGlossary(ctx, glossary); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// currentBundleContent reads what importing a bundle into the tenant replaces
+func (s *Service) currentBundleContent(ctx context.Context) (*bundleContent, error) {
+	stored, err := s.configService.GetStoredConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	priorityConfig, err := s.priorityService.GetPriorityConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	glossary, err := s.GetTranslationGlossary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &bundleContent{Configuration: stored, Priority: priorityConfig, Glossary: comparableGlossary(glossary)}, nil
+}
+
+// importedBundleContent validates the content of a bundle and completes it with the metadata and
+// the secrets of the stored configuration
+func (s *Service) importedBundleContent(ctx context.Context, bundle *dto.ConfigurationBundle, stored *types.SystemConfiguration) (*bundleContent, error) {
+	configuration := make(map[string]interface{}, len(bundle.Configuration))
+	for field, value := range bundle.Configuration {
+		configuration[field] = value
+	}
+	for _, field := range bundleMetadataFields {
+		delete(configuration, field)
+	}
+	storedObject, err := configbundle.ToObject(stored)
+	if err != nil {
+		return nil, err
+	}
+	configbundle.KeepSecrets(configuration, storedObject)
+
+	// A bundle of a layer without configuration leaves the layer without one
+	var imported *types.SystemConfiguration
+	if len(configuration) > 0 || stored != nil {
+		imported = &types.SystemConfiguration{}
+		if err := configbundle.FromObject(configuration, imported); err != nil {
+			return nil, ngErrors.InvalidConfigurationBundle(fmt.Sprintf("configuration: %v", err))
+		}
+	}
+	if stored != nil {
+		imported.ID, imported.TenantID, imported.SectionID = stored.ID, stored.TenantID, stored.SectionID
+		imported.Integration, imported.APIKeys = stored.Integration, stored.APIKeys
+	}
+
+	priorityConfig, err := s.priorityConfigFromDTO(ctx, bundle.Priority)
+	if err != nil {
+		return nil, err
+	}
+	if err := priorityConfig.Validate(); err != nil {
+		return nil, priorityConfigError(err)
+	}
+
+	glossary, err := glossaryFromDTO(bundle.Glossary)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]dto.TranslationGlossaryEntry, len(glossary))
+	for i, entry := range glossary {
+		entries[i] = dto.TranslationGlossaryEntry{SourceLang: entry.SourceLang, TargetLang: entry.TargetLang, SourceText: entry.SourceText, TargetText: entry.TargetText}
+	}
+	return &bundleContent{Configuration: imported, Priority: priorityConfig, Glossary: comparableGlossary(entries)}, nil
+}
+
+// configurationFields are the updates replacing every configured field of a stored configuration
+func configurationFields(config *types.SystemConfiguration) map[string]interface{} {
+	return map[string]interface{}{
+		"externalAPI":   config.ExternalAPI,
+		"rooms":         config.Rooms,
+		"defaultRoom":   config.DefaultRoom,
+		"webSocketPath": config.WebSocketPath,
+		"allowWildcard": config.AllowWildcard,
+		"pathways":      config.Pathways,
+		"retention":     config.Retention,
+		"notifications": config.Notifications,
+		"fhir":          config.FHIR,
+		"messages":      config.Messages,
+	}
+}
+
+// comparableGlossary returns the entries without their update times, in a stable order
+func comparableGlossary(entries []dto.TranslationGlossaryEntry) []dto.TranslationGlossaryEntry {
+	glossary := make([]dto.TranslationGlossaryEntry, len(entries))
+	for i, entry := range entries {
+		entry.UpdatedAt = nil
+		glossary[i] = entry
+	}
+	sort.Slice(glossary, func(i, j int) bool {
+		a, b := glossary[i], glossary[j]
+		if a.SourceLang != b.SourceLang {
+			return a.SourceLang < b.SourceLang
+		}
+		if a.TargetLang != b.TargetLang {
+			return a.TargetLang < b.TargetLang
+		}
+		return a.SourceText < b.SourceText
+	})
+	return glossary
+}
@@ -0,0 +1,140 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/priority"
+	"github.com/arfis/waiting-room/internal/service/config"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// defaultSectionID is the section a tenant is provisioned with when the request names none
+const defaultSectionID = "main"
+
+// ProvisionTenant stands up a site in one call: the tenant and its default section, a default
+// room with its service points, a starter priority configuration, the languages of its kiosks and
+// API keys for its devices. The tenant, its configuration and the API keys are saved together or
+// not at all; the keys are returned only once.
+func (s *Service) ProvisionTenant(ctx context.Context, req *dto.TenantProvisioningRequest) (*dto.TenantProvisioningResult, error) {
+	buildingID := strings.TrimSpace(req.BuildingId)
+	sectionID := strings.TrimSpace(req.GetSectionId())
+	if sectionID == "" {
+		sectionID = defaultSectionID
+	}
+	tenantID := buildingID + ":" + sectionID
+	if _, _, err := types.ParseTenantID(tenantID); err != nil || buildingID == "" {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("invalid tenant ID %s", tenantID), 400, nil)
+	}
+	tenantCtx := context.WithValue(ctx, middleware.TENANT, tenantID)
+
+	// Tenants are registered by building, provisioning must not take over an existing one
+	existing, err := s.tenantService.GetTenant(ctx, buildingID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ngErrors.New(ngErrors.BusinessErrorCode, fmt.Sprintf("a tenant of building %s already exists", buildingID), 409, nil)
+	}
+	stored, err := s.configService.GetStoredConfiguration(tenantCtx)
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil {
+		return nil, ngErrors.New(ngErrors.BusinessErrorCode, fmt.Sprintf("tenant %s already has a configuration", tenantID), 409, nil)
+	}
+
+	room := s.configService.GetDefaultRoomsConfig()[0]
+	if req.Room != nil {
+		if err := validateRoomDTO(*req.Room); err != nil {
+			return nil, err
+		}
+		room = s.convertDTOToRoomConfig(*req.Room)
+		room.IsDefault = true
+	}
+	if err := validateRooms([]types.RoomConfig{room}); err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{
+		"rooms":       []types.RoomConfig{room},
+		"defaultRoom": room.ID,
+	}
+
+	supportedLanguages, defaultLanguage, err := normalizeLanguages(req.SupportedLanguages, req.GetDefaultLanguage())
+	if err != nil {
+		return nil, err
+	}
+	if len(supportedLanguages) > 0 || defaultLanguage != "" {
+		updates["externalAPI"] = types.ExternalAPIConfig{
+			SupportedLanguages: supportedLanguages,
+			DefaultLanguage:    defaultLanguage,
+		}
+	}
+
+	// Without one in the request the section starts from the priority configuration it inherits
+	var priorityConfig *priority.PriorityConfig
+	if req.Priority != nil {
+		priorityConfig, err = s.priorityConfigFromDTO(tenantCtx, req.Priority)
+	} else {
+		priorityConfig, err = s.priorityService.GetPriorityConfig(tenantCtx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := priorityConfig.Validate(); err != nil {
+		return nil, priorityConfigError(err)
+	}
+
+	devices := req.Devices
+	if len(devices) == 0 {
+		for _, deviceType := range types.DeviceTypes {
+			devices = append(devices, dto.APIKeyRequest{Name: fmt.Sprintf("%s %s", req.Name, deviceType), DeviceType: string(deviceType)})
+		}
+	}
+	apiKeys := make([]types.APIKey, 0, len(devices))
+	issued := make([]dto.IssuedAPIKey, 0, len(devices))
+	for i := range devices {
+		apiKey, err := apiKeyFromRequest(&devices[i])
+		if err != nil {
+			return nil, err
+		}
+		apiKey.TenantID = tenantID
+		key, err := config.NewAPIKey(apiKey)
+		if err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, *apiKey)
+		issued = append(issued, dto.IssuedAPIKey{ApiKey: convertAPIKeyToDTO(*apiKey), Key: key})
+	}
+
+	// The priority configuration is kept in a store of its own, so it is saved first: when saving
+	// the tenant fails, it is left to a tenant that doesn't exist and replaced when it is
+	// provisioned again
+	if req.Priority != nil || s.priorityService.SavesConfigs() {
+		if err := s.priorityService.SavePriorityConfig(tenantCtx, priorityConfig); err != nil {
+			return nil, priorityConfigError(err)
+		}
+	}
+
+	tenant, err := s.tenantService.ProvisionTenant(ctx, &dto.Tenant{
+		BuildingId:  buildingID,
+		SectionId:   sectionID,
+		Name:        req.Name,
+		Description: req.Description,
+	}, updates, apiKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	roomDTO := s.convertRoomConfigToDTO(room)
+	return &dto.TenantProvisioningResult{
+		Tenant:   tenant,
+		Room:     &roomDTO,
+		Priority: s.convertPriorityConfigToDTO(priorityConfig),
+		ApiKeys:  issued,
+	}, nil
+}
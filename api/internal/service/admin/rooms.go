@@ -0,0 +1,306 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	"github.com/arfis/waiting-room/internal/data/dto/bulkqueueaction"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// CreateRoom adds a room to the rooms of the tenant. A new default room replaces the current one.
+func (s *Service) CreateRoom(ctx context.Context, req *dto.RoomConfig) (*dto.RoomConfig, error) {
+	if err := validateRoomDTO(*req); err != nil {
+		return nil, err
+	}
+	rooms, err := s.configService.GetRoomsConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if roomIndex(rooms, req.Id) >= 0 {
+		return nil, ngErrors.New(ngErrors.BusinessErrorCode, fmt.Sprintf("room %s already exists", req.Id), 409, nil)
+	}
+
+	rooms = slices.Clone(rooms)
+	room := s.convertDTOToRoomConfig(*req)
+	if room.IsDefault {
+		clearDefaultRoom(rooms)
+	}
+	rooms = append(rooms, room)
+	if err := s.saveRooms(ctx, rooms); err != nil {
+		return nil, err
+	}
+	result := s.convertRoomConfigToDTO(room)
+	return &result, nil
+}
+
+// UpdateRoom replaces the settings of a room. Service points it no longer has must not have
+// active entries or be a stage of a pathway, and the default room stays the default until
+// another room is made the default.
+func (s *Service) UpdateRoom(ctx context.Context, roomId string, req *dto.RoomConfig) (*dto.RoomConfig, error) {
+	if req.Id != roomId {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "the id of a room cannot be changed", 400, nil)
+	}
+	if err := validateRoomDTO(*req); err != nil {
+		return nil, err
+	}
+	rooms, err := s.configService.GetRoomsConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rooms = slices.Clone(rooms)
+	index := roomIndex(rooms, roomId)
+	if index < 0 {
+		return nil, ngErrors.EntityNotFound()
+	}
+
+	room := s.convertDTOToRoomConfig(*req)
+	if rooms[index].IsDefault && !room.IsDefault {
+		return nil, ngErrors.New(ngErrors.BusinessErrorCode, "the default room stays the default until another room is made the default", 409, nil)
+	}
+	for _, sp := range rooms[index].ServicePoints {
+		if !hasRoomAndServicePoint([]types.RoomConfig{room}, roomId, sp.ID) {
+			if err := s.checkServicePointUnused(ctx, roomId, sp.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if room.IsDefault {
+		clearDefaultRoom(rooms)
+	}
+	rooms[index] = room
+	if err := s.saveRooms(ctx, rooms); err != nil {
+		return nil, err
+	}
+	result := s.convertRoomConfigToDTO(room)
+	return &result, nil
+}
+
+// DeleteRoom removes a room from the rooms of the tenant. The default room, rooms other rooms
+// overflow into and rooms a pathway leads through cannot be deleted, nor rooms with entries that
+// have been called. Waiting entries and the card readers of the room are moved to migrateTo, a
+// room with any of them cannot be deleted without it.
+func (s *Service) DeleteRoom(ctx context.Context, roomId string, migrateTo *string) (*dto.RoomDeletionResult, error) {
+	rooms, err := s.configService.GetRoomsConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	index := roomIndex(rooms, roomId)
+	if index < 0 {
+		return nil, ngErrors.EntityNotFound()
+	}
+	if rooms[index].IsDefault {
+		return nil, ngErrors.New(ngErrors.BusinessErrorCode, "the default room cannot be deleted, make another room the default first", 409, nil)
+	}
+	for _, room := range rooms {
+		if room.OverflowRoomID == roomId {
+			return nil, ngErrors.New(ngErrors.BusinessErrorCode, fmt.Sprintf("room %s overflows into room %s", room.ID, roomId), 409, nil)
+		}
+	}
+	pathways, err := s.configService.GetPathways(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if pathway := pathwayThrough(pathways, roomId, ""); pathway != "" {
+		return nil, ngErrors.New(ngErrors.BusinessErrorCode, fmt.Sprintf("pathway %s leads through room %s", pathway, roomId), 409, nil)
+	}
+
+	counts, err := s.queueService.CountActiveEntries(ctx, roomId, "")
+	if err != nil {
+		return nil, err
+	}
+	if called := counts["CALLED"] + counts["IN_ROOM"] + counts["IN_SERVICE"]; called > 0 {
+		return nil, ngErrors.New(ngErrors.BusinessErrorCode, fmt.Sprintf("room %s has %d called entries, finish them first", roomId, called), 409, nil)
+	}
+	readers, err := s.configService.GetCardReaderConfigsByRoom(ctx, roomId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.RoomDeletionResult{MigratedCardReaders: []string{}}
+	if counts["WAITING"] > 0 || len(readers) > 0 {
+		if migrateTo == nil || *migrateTo == "" {
+			return nil, ngErrors.New(ngErrors.BusinessErrorCode, fmt.Sprintf("room %s has %d waiting entries and %d card readers, set migrateTo to move them", roomId, counts["WAITING"], len(readers)), 409, nil)
+		}
+		target := *migrateTo
+		if target == roomId || roomIndex(rooms, target) < 0 {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("migrateTo must be another configured room, got %s", target), 400, nil)
+		}
+
+		if counts["WAITING"] > 0 {
+			moved, err := s.queueService.BulkQueueOperation(ctx, roomId, &dto.BulkQueueOperationRequest{
+				Action:       bulkqueueaction.MOVE_WAITING,
+				TargetRoomId: &target,
+			})
+			if err != nil {
+				return nil, err
+			}
+			result.MigratedEntries = moved.Affected
+		}
+		for _, reader := range readers {
+			reader.RoomID = target
+			if err := s.configService.SetCardReaderConfig(ctx, &reader); err != nil {
+				return nil, err
+			}
+			result.MigratedCardReaders = append(result.MigratedCardReaders, reader.DeviceID)
+		}
+	}
+
+	if err := s.saveRooms(ctx, slices.Delete(slices.Clone(rooms), index, index+1)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// AddServicePoint adds a service point to a room
+func (s *Service) AddServicePoint(ctx context.Context, roomId string, req *dto.ServicePointConfig) (*dto.RoomConfig, error) {
+	rooms, err := s.configService.GetRoomsConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rooms = slices.Clone(rooms)
+	index := roomIndex(rooms, roomId)
+	if index < 0 {
+		return nil, ngErrors.EntityNotFound()
+	}
+	if hasRoomAndServicePoint(rooms, roomId, req.Id) {
+		return nil, ngErrors.New(ngErrors.BusinessErrorCode, fmt.Sprintf("room %s already has service point %s", roomId, req.Id), 409, nil)
+	}
+
+	room := s.convertRoomConfigToDTO(rooms[index])
+	room.ServicePoints = append(room.ServicePoints, *req)
+	rooms[index] = s.convertDTOToRoomConfig(room)
+	if err := s.saveRooms(ctx, rooms); err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+// RemoveServicePoint removes a service point without active entries that no pathway leads
+// through from a room
+func (s *Service) RemoveServicePoint(ctx context.Context, roomId string, servicePointId string) (*dto.RoomConfig, error) {
+	rooms, err := s.configService.GetRoomsConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rooms = slices.Clone(rooms)
+	index := roomIndex(rooms, roomId)
+	if index < 0 || !hasRoomAndServicePoint(rooms, roomId, servicePointId) {
+		return nil, ngErrors.EntityNotFound()
+	}
+	if err := s.checkServicePointUnused(ctx, roomId, servicePointId); err != nil {
+		return nil, err
+	}
+
+	room := rooms[index]
+	room.ServicePoints = slices.DeleteFunc(slices.Clone(room.ServicePoints), func(sp types.ServicePointConfig) bool {
+		return sp.ID == servicePointId
+	})
+	rooms[index] = room
+	if err := s.saveRooms(ctx, rooms); err != nil {
+		return nil, err
+	}
+	result := s.convertRoomConfigToDTO(room)
+	return &result, nil
+}
+
+// checkServicePointUnused returns a conflict when a service point of a room has active entries
+// or a pathway leads through it
+func (s *Service) checkServicePointUnused(ctx context.Context, roomId, servicePointId string) error {
+	counts, err := s.queueService.CountActiveEntries(ctx, roomId, servicePointId)
+	if err != nil {
+		return err
+	}
+	active := 0
+	for _, count := range counts {
+		active += count
+	}
+	if active > 0 {
+		return ngErrors.New(ngErrors.BusinessErrorCode, fmt.Sprintf("service point %s of room %s has %d active entries", servicePointId, roomId, active), 409, nil)
+	}
+	pathways, err := s.configService.GetPathways(ctx)
+	if err != nil {
+		return err
+	}
+	if pathway := pathwayThrough(pathways, roomId, servicePointId); pathway != "" {
+		return ngErrors.New(ngErrors.BusinessErrorCode, fmt.Sprintf("pathway %s leads through service point %s of room %s", pathway, servicePointId, roomId), 409, nil)
+	}
+	return nil
+}
+
+// saveRooms validates the rooms and saves them as the rooms of the tenant
+func (s *Service) saveRooms(ctx context.Context, rooms []types.RoomConfig) error {
+	if err := validateRooms(rooms); err != nil {
+		return err
+	}
+	return s.configService.UpdateRoomsConfiguration(ctx, rooms)
+}
+
+// validateRooms checks the rooms of a tenant together: room ids are unique, so are the ids of
+// the service points of each room, exactly one room is the default and rooms overflow into
+// configured rooms
+func validateRooms(rooms []types.RoomConfig) error {
+	roomIDs := map[string]bool{}
+	var defaults []string
+	for _, room := range rooms {
+		if strings.TrimSpace(room.ID) == "" {
+			return ngErrors.New(ngErrors.ValidationErrorCode, "every room needs an id", 400, nil)
+		}
+		if roomIDs[room.ID] {
+			return ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("room %s is configured more than once", room.ID), 400, nil)
+		}
+		roomIDs[room.ID] = true
+		if room.IsDefault {
+			defaults = append(defaults, room.ID)
+		}
+
+		servicePointIDs := map[string]bool{}
+		for _, sp := range room.ServicePoints {
+			if servicePointIDs[sp.ID] {
+				return ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("service point %s of room %s is configured more than once", sp.ID, room.ID), 400, nil)
+			}
+			servicePointIDs[sp.ID] = true
+		}
+	}
+	if len(rooms) > 0 && len(defaults) != 1 {
+		return ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("exactly one room must be the default, got %d", len(defaults)), 400, nil)
+	}
+	for _, room := range rooms {
+		if room.OverflowRoomID != "" && !roomIDs[room.OverflowRoomID] {
+			return ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("room %s overflows into unknown room %s", room.ID, room.OverflowRoomID), 400, nil)
+		}
+	}
+	return nil
+}
+
+// roomIndex returns the index of the room with roomID, -1 when there is none
+func roomIndex(rooms []types.RoomConfig, roomID string) int {
+	return slices.IndexFunc(rooms, func(room types.RoomConfig) bool {
+		return room.ID == roomID
+	})
+}
+
+// clearDefaultRoom makes none of the rooms the default
+func clearDefaultRoom(rooms []types.RoomConfig) {
+	for i := range rooms {
+		rooms[i].IsDefault = false
+	}
+}
+
+// pathwayThrough returns the id of a pathway with a stage in the room, at servicePointID when it
+// is set, empty when there is none
+func pathwayThrough(pathways []types.Pathway, roomID, servicePointID string) string {
+	for _, pathway := range pathways {
+		for _, stage := range pathway.Stages {
+			if stage.RoomID == roomID && (servicePointID == "" || stage.ServicePointID == servicePointID) {
+				return pathway.ID
+			}
+		}
+	}
+	return ""
+}
@@ -2,37 +2,64 @@ package admin
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/arfis/waiting-room/internal/cardreader"
+	"github.com/arfis/waiting-room/internal/configlayers"
 	"github.com/arfis/waiting-room/internal/data/dto"
+	"github.com/arfis/waiting-room/internal/data/dto/assignmentstrategy"
+	"github.com/arfis/waiting-room/internal/data/dto/queueordering"
+	"github.com/arfis/waiting-room/internal/data/dto/servicefallback"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/fhir"
+	"github.com/arfis/waiting-room/internal/his"
+	"github.com/arfis/waiting-room/internal/middleware"
 	"github.com/arfis/waiting-room/internal/priority"
+	"github.com/arfis/waiting-room/internal/service"
 	"github.com/arfis/waiting-room/internal/service/config"
+	"github.com/arfis/waiting-room/internal/service/notification"
 	priorityService "github.com/arfis/waiting-room/internal/service/priority"
+	queueService "github.com/arfis/waiting-room/internal/service/queue"
 	tenantService "github.com/arfis/waiting-room/internal/service/tenant"
 	"github.com/arfis/waiting-room/internal/service/translation"
+	"github.com/arfis/waiting-room/internal/service/webhook"
 	"github.com/arfis/waiting-room/internal/types"
 )
 
 type Service struct {
 	configService      *config.Service
-	translationService *translation.DeepLTranslationService
+	translationService *translation.Service
 	tenantService      *tenantService.Service
 	priorityService    *priorityService.Service
+	queueService       *queueService.Service
+	// subscriptionTokenSecret signs WebSocket subscription tokens (websocket.token_secret)
+	subscriptionTokenSecret string
+	// bundleSigningKey signs exported configuration bundles (configuration.bundle_signing_key)
+	bundleSigningKey string
 }
 
-func NewService(configService *config.Service, translationService *translation.DeepLTranslationService, tenantService *tenantService.Service, priorityService *priorityService.Service) *Service {
+// defaultSubscriptionTokenTTL is the lifetime of subscription tokens issued without ttlSeconds
+const defaultSubscriptionTokenTTL = 30 * 24 * time.Hour
+
+func NewService(configService *config.Service, translationService *translation.Service, tenantService *tenantService.Service, priorityService *priorityService.Service, queueService *queueService.Service, subscriptionTokenSecret string, bundleSigningKey string) *Service {
 	return &Service{
-		configService:      configService,
-		translationService: translationService,
-		tenantService:      tenantService,
-		priorityService:    priorityService,
+		configService:           configService,
+		translationService:      translationService,
+		tenantService:           tenantService,
+		priorityService:         priorityService,
+		queueService:            queueService,
+		subscriptionTokenSecret: subscriptionTokenSecret,
+		bundleSigningKey:        bundleSigningKey,
 	}
 }
 
 // System Configuration methods
 func (s *Service) GetSystemConfiguration(ctx context.Context) (*dto.SystemConfiguration, error) {
-	config, err := s.configService.GetSystemConfiguration(ctx)
+	config, err := s.configService.GetStoredConfiguration(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -44,6 +71,33 @@ func (s *Service) GetSystemConfiguration(ctx context.Context) (*dto.SystemConfig
 	return s.convertSystemConfigurationToDTO(config), nil
 }
 
+// GetResolvedConfiguration returns the effective configuration of the tenant and the layer each
+// of its values comes from
+func (s *Service) GetResolvedConfiguration(ctx context.Context) (*dto.ResolvedConfiguration, error) {
+	config, sources, err := s.configService.GetResolvedConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		config = &types.SystemConfiguration{}
+	}
+
+	layers := []string{}
+	for _, layer := range []string{configlayers.LayerGlobal, configlayers.LayerTenant, configlayers.LayerSection} {
+		for _, source := range sources {
+			if source == layer {
+				layers = append(layers, layer)
+				break
+			}
+		}
+	}
+	return &dto.ResolvedConfiguration{
+		Configuration: s.convertSystemConfigurationToDTO(config),
+		Layers:        layers,
+		Sources:       sources,
+	}, nil
+}
+
 func (s *Service) UpdateSystemConfiguration(ctx context.Context, config *dto.SystemConfiguration) (*dto.SystemConfiguration, error) {
 	// Convert DTO to types
 	systemConfig := s.convertDTOToSystemConfiguration(config)
@@ -124,6 +178,24 @@ func (s *Service) GetExternalAPIConfiguration(ctx context.Context) (*dto.Externa
 		retries := int64(config.WebhookRetryAttempts)
 		externalAPIConfig.WebhookRetryAttempts = &retries
 	}
+	if len(config.WebhookEvents) > 0 {
+		externalAPIConfig.WebhookEvents = config.WebhookEvents
+	}
+	if config.WebhookPayloadTemplate != "" {
+		externalAPIConfig.WebhookPayloadTemplate = &config.WebhookPayloadTemplate
+	}
+	if config.HISProvider != "" {
+		externalAPIConfig.HisProvider = &config.HISProvider
+	}
+	if config.NGHISAdapterURL != "" {
+		externalAPIConfig.NghisAdapterUrl = &config.NGHISAdapterURL
+	}
+	if config.VisitNotificationURL != "" {
+		externalAPIConfig.VisitNotificationUrl = &config.VisitNotificationURL
+	}
+	if config.TranslationProvider != "" {
+		externalAPIConfig.TranslationProvider = &config.TranslationProvider
+	}
 
 	// Add multilingual configuration
 	if config.MultilingualSupport != nil {
@@ -132,6 +204,9 @@ func (s *Service) GetExternalAPIConfiguration(ctx context.Context) (*dto.Externa
 	if len(config.SupportedLanguages) > 0 {
 		externalAPIConfig.SupportedLanguages = config.SupportedLanguages
 	}
+	if config.DefaultLanguage != "" {
+		externalAPIConfig.DefaultLanguage = &config.DefaultLanguage
+	}
 	if config.UseDeepLTranslation != nil {
 		externalAPIConfig.UseDeepLTranslation = config.UseDeepLTranslation
 	}
@@ -213,14 +288,52 @@ func (s *Service) UpdateExternalAPIConfiguration(ctx context.Context, config *dt
 	if config.WebhookRetryAttempts != nil && *config.WebhookRetryAttempts > 0 {
 		externalAPIConfig.WebhookRetryAttempts = int(*config.WebhookRetryAttempts)
 	}
+	for _, event := range config.WebhookEvents {
+		if !webhook.IsEvent(event) {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("unknown webhook event %s", event), 400, nil)
+		}
+	}
+	if len(config.WebhookEvents) > 0 {
+		externalAPIConfig.WebhookEvents = config.WebhookEvents
+	}
+	if config.GetWebhookPayloadTemplate() != "" {
+		if err := webhook.ValidateTemplate(config.GetWebhookPayloadTemplate()); err != nil {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, err.Error(), 400, nil)
+		}
+		externalAPIConfig.WebhookPayloadTemplate = config.GetWebhookPayloadTemplate()
+	}
+	if config.GetHisProvider() != "" && !slices.Contains(his.Providers, config.GetHisProvider()) {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("unknown HIS provider %s", config.GetHisProvider()), 400, nil)
+	}
+	if config.GetHisProvider() == his.ProviderNGHISAdapter && config.GetNghisAdapterUrl() == "" {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "nghisAdapterUrl is required for the nghis_adapter HIS provider", 400, nil)
+	}
+	externalAPIConfig.HISProvider = config.GetHisProvider()
+	externalAPIConfig.NGHISAdapterURL = config.GetNghisAdapterUrl()
+	externalAPIConfig.VisitNotificationURL = config.GetVisitNotificationUrl()
+	if config.GetTranslationProvider() != "" && !slices.Contains(translation.Providers, config.GetTranslationProvider()) {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("unknown translation provider %s", config.GetTranslationProvider()), 400, nil)
+	}
+	externalAPIConfig.TranslationProvider = config.GetTranslationProvider()
+	// The signing secret is never returned, so an empty one keeps the stored secret
+	if config.GetWebhookSecret() != "" {
+		externalAPIConfig.WebhookSecret = config.GetWebhookSecret()
+	} else if stored, err := s.configService.GetExternalAPIConfiguration(ctx); err == nil && stored != nil {
+		externalAPIConfig.WebhookSecret = stored.WebhookSecret
+	}
 
 	// Add multilingual configuration
 	if config.MultilingualSupport != nil {
 		externalAPIConfig.MultilingualSupport = config.MultilingualSupport
 	}
-	if len(config.SupportedLanguages) > 0 {
-		externalAPIConfig.SupportedLanguages = config.SupportedLanguages
+	supportedLanguages, defaultLanguage, err := normalizeLanguages(config.SupportedLanguages, config.GetDefaultLanguage())
+	if err != nil {
+		return nil, err
+	}
+	if len(supportedLanguages) > 0 {
+		externalAPIConfig.SupportedLanguages = supportedLanguages
 	}
+	externalAPIConfig.DefaultLanguage = defaultLanguage
 	if config.UseDeepLTranslation != nil {
 		externalAPIConfig.UseDeepLTranslation = config.UseDeepLTranslation
 	}
@@ -237,13 +350,34 @@ func (s *Service) UpdateExternalAPIConfiguration(ctx context.Context, config *dt
 		externalAPIConfig.GenericServicesLanguageHeader = config.GenericServicesLanguageHeader
 	}
 
-	err := s.configService.UpdateExternalAPIConfiguration(ctx, externalAPIConfig)
+	err = s.configService.UpdateExternalAPIConfiguration(ctx, externalAPIConfig)
 	if err != nil {
 		return nil, err
 	}
+	config.WebhookSecret = nil
 	return config, nil
 }
 
+// normalizeLanguages returns the supported languages in lower case without duplicates, as kiosks
+// request them (e.g. sk), and the default language, which must be one of them
+func normalizeLanguages(languages []string, defaultLanguage string) ([]string, string, error) {
+	var supported []string
+	for _, language := range languages {
+		language = strings.ToLower(strings.TrimSpace(language))
+		if language == "" {
+			return nil, "", ngErrors.New(ngErrors.ValidationErrorCode, "supported languages must not be empty", 400, nil)
+		}
+		if !slices.Contains(supported, language) {
+			supported = append(supported, language)
+		}
+	}
+	defaultLanguage = strings.ToLower(strings.TrimSpace(defaultLanguage))
+	if defaultLanguage != "" && len(supported) > 0 && !slices.Contains(supported, defaultLanguage) {
+		return nil, "", ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("default language %s is not a supported language", defaultLanguage), 400, nil)
+	}
+	return supported, defaultLanguage, nil
+}
+
 // Rooms Configuration methods
 func (s *Service) GetRoomsConfiguration(ctx context.Context) ([]dto.RoomConfig, error) {
 	rooms, err := s.configService.GetRoomsConfiguration(ctx)
@@ -263,8 +397,14 @@ func (s *Service) UpdateRoomsConfiguration(ctx context.Context, rooms []dto.Room
 	// Convert DTOs to types
 	var typeRooms []types.RoomConfig
 	for _, room := range rooms {
+		if err := validateRoomDTO(room); err != nil {
+			return nil, err
+		}
 		typeRooms = append(typeRooms, s.convertDTOToRoomConfig(room))
 	}
+	if err := validateRooms(typeRooms); err != nil {
+		return nil, err
+	}
 
 	err := s.configService.UpdateRoomsConfiguration(ctx, typeRooms)
 	if err != nil {
@@ -273,6 +413,384 @@ func (s *Service) UpdateRoomsConfiguration(ctx context.Context, rooms []dto.Room
 	return rooms, nil
 }
 
+// validateRoomDTO checks the settings of a room that do not depend on the other rooms
+func validateRoomDTO(room dto.RoomConfig) error {
+	if room.Ordering != nil {
+		if _, err := queueordering.StringToQueueOrdering(room.Ordering.String()); err != nil {
+			return err
+		}
+	}
+	if room.Assignment != nil {
+		if _, err := assignmentstrategy.StringToAssignmentStrategy(room.Assignment.String()); err != nil {
+			return err
+		}
+	}
+	if room.ServiceFallback != nil {
+		if _, err := servicefallback.StringToServiceFallback(room.ServiceFallback.String()); err != nil {
+			return err
+		}
+	}
+	if room.GetMaxWaiting() < 0 {
+		return ngErrors.New(ngErrors.ValidationErrorCode, "maxWaiting must not be negative", 400, nil)
+	}
+	if display := room.GetDisplay(); display.GetBlinkSeconds() < 0 || display.GetCallHistory() < 0 || display.GetCallHistory() > types.MaxDisplayCallHistory {
+		return ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("display blinkSeconds must not be negative and callHistory must be between 0 and %d", types.MaxDisplayCallHistory), 400, nil)
+	}
+	if room.GetOverflowRoomId() == room.Id {
+		return ngErrors.New(ngErrors.ValidationErrorCode, "a room cannot overflow into itself", 400, nil)
+	}
+	return nil
+}
+
+// Pathways Configuration methods
+func (s *Service) GetPathwaysConfiguration(ctx context.Context) ([]dto.Pathway, error) {
+	pathways, err := s.configService.GetPathways(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert types to DTOs
+	dtoPathways := []dto.Pathway{}
+	for _, pathway := range pathways {
+		dtoPathways = append(dtoPathways, s.convertPathwayToDTO(pathway))
+	}
+	return dtoPathways, nil
+}
+
+func (s *Service) UpdatePathwaysConfiguration(ctx context.Context, pathways []dto.Pathway) ([]dto.Pathway, error) {
+	rooms, err := s.configService.GetRoomsConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert DTOs to types, every stage must point to a configured room and service point
+	typePathways := []types.Pathway{}
+	for _, pathway := range pathways {
+		for _, stage := range pathway.Stages {
+			if !hasRoomAndServicePoint(rooms, stage.RoomId, stage.GetServicePointId()) {
+				return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("stage %s of pathway %s refers to an unknown room or service point", stage.Name, pathway.Id), 400, nil)
+			}
+		}
+		typePathways = append(typePathways, s.convertDTOToPathway(pathway))
+	}
+
+	if err := s.configService.SetPathways(ctx, typePathways); err != nil {
+		return nil, err
+	}
+	return pathways, nil
+}
+
+// Retention Policy methods
+func (s *Service) GetRetentionPolicy(ctx context.Context) (*dto.RetentionPolicy, error) {
+	policy, err := s.configService.GetRetentionPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return &dto.RetentionPolicy{}, nil
+	}
+	return convertRetentionPolicyToDTO(policy), nil
+}
+
+func (s *Service) UpdateRetentionPolicy(ctx context.Context, policy *dto.RetentionPolicy) (*dto.RetentionPolicy, error) {
+	if policy.PurgeAfterDays < 0 {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "purgeAfterDays must not be negative", 400, nil)
+	}
+	for _, field := range policy.PurgeFields {
+		if !isRetentionPurgeField(field) {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("unknown card data field '%s', expected one of %s", field, strings.Join(types.RetentionPurgeFields, ", ")), 400, nil)
+		}
+	}
+
+	typePolicy := &types.RetentionPolicy{
+		PurgeAfterDays:     int(policy.PurgeAfterDays),
+		PurgeFields:        policy.PurgeFields,
+		KeepHashedIDNumber: policy.GetKeepHashedIdNumber(),
+	}
+	if err := s.configService.SetRetentionPolicy(ctx, typePolicy); err != nil {
+		return nil, err
+	}
+	return convertRetentionPolicyToDTO(typePolicy), nil
+}
+
+// isRetentionPurgeField reports whether field is one of types.RetentionPurgeFields
+func isRetentionPurgeField(field string) bool {
+	for _, name := range types.RetentionPurgeFields {
+		if name == field {
+			return true
+		}
+	}
+	return false
+}
+
+func convertRetentionPolicyToDTO(policy *types.RetentionPolicy) *dto.RetentionPolicy {
+	keepHashedIDNumber := policy.KeepHashedIDNumber
+	return &dto.RetentionPolicy{
+		KeepHashedIdNumber: &keepHashedIDNumber,
+		PurgeAfterDays:     int64(policy.PurgeAfterDays),
+		PurgeFields:        policy.PurgeFields,
+	}
+}
+
+// Notification Settings methods
+func (s *Service) GetNotificationSettings(ctx context.Context) (*dto.NotificationSettings, error) {
+	settings, err := s.configService.GetNotificationSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		return &dto.NotificationSettings{}, nil
+	}
+	return convertNotificationSettingsToDTO(settings), nil
+}
+
+func (s *Service) UpdateNotificationSettings(ctx context.Context, settings *dto.NotificationSettings) (*dto.NotificationSettings, error) {
+	if settings.ApproachingPeopleAhead < 0 {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "approachingPeopleAhead must not be negative", 400, nil)
+	}
+	if settings.Smtp != nil && (settings.Smtp.GetPort() < 0 || settings.Smtp.GetPort() > 65535) {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "smtp port must be between 0 and 65535", 400, nil)
+	}
+	if settings.Sms != nil && settings.Sms.GetBaseUrl() != "" && !isHTTPURL(settings.Sms.GetBaseUrl()) {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "sms baseUrl must be an http(s) URL", 400, nil)
+	}
+	if settings.Http != nil && !isHTTPURL(settings.Http.Url) {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "http url must be an http(s) URL", 400, nil)
+	}
+	if push := settings.Push; push != nil {
+		if subject := push.GetSubject(); subject != "" && !strings.HasPrefix(subject, "mailto:") && !strings.HasPrefix(subject, "https://") {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, "push subject must be a mailto: or https: URL", 400, nil)
+		}
+		for _, milestone := range push.Milestones {
+			if milestone < 0 {
+				return nil, ngErrors.New(ngErrors.ValidationErrorCode, "push milestones must not be negative", 400, nil)
+			}
+		}
+	}
+
+	// Secrets are never returned, so a provider sent back without one keeps the stored one
+	stored, err := s.configService.GetNotificationSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		stored = &types.NotificationSettings{}
+	}
+
+	typeSettings := &types.NotificationSettings{
+		ApproachingPeopleAhead: int(settings.ApproachingPeopleAhead),
+		ApproachingMessage:     settings.GetApproachingMessage(),
+		CalledMessage:          settings.GetCalledMessage(),
+	}
+	if smtp := settings.Smtp; smtp != nil {
+		typeSettings.SMTP = &types.SMTPSettings{
+			Host:     smtp.Host,
+			Port:     int(smtp.GetPort()),
+			Username: smtp.GetUsername(),
+			Password: smtp.GetPassword(),
+			From:     smtp.From,
+		}
+		if typeSettings.SMTP.Password == "" && stored.SMTP != nil {
+			typeSettings.SMTP.Password = stored.SMTP.Password
+		}
+	}
+	if sms := settings.Sms; sms != nil {
+		typeSettings.SMS = &types.SMSSettings{
+			BaseURL:    sms.GetBaseUrl(),
+			AccountSID: sms.AccountSid,
+			AuthToken:  sms.GetAuthToken(),
+			From:       sms.From,
+		}
+		if typeSettings.SMS.AuthToken == "" && stored.SMS != nil {
+			typeSettings.SMS.AuthToken = stored.SMS.AuthToken
+		}
+	}
+	if http := settings.Http; http != nil {
+		typeSettings.HTTP = &types.HTTPNotificationSettings{
+			URL:   http.Url,
+			Token: http.GetToken(),
+		}
+		if typeSettings.HTTP.Token == "" && stored.HTTP != nil {
+			typeSettings.HTTP.Token = stored.HTTP.Token
+		}
+	}
+
+	if push := settings.Push; push != nil {
+		// The keys are only changed by GenerateVapidKeys
+		typeSettings.Push = &types.PushSettings{Subject: push.GetSubject()}
+		for _, milestone := range push.Milestones {
+			typeSettings.Push.Milestones = append(typeSettings.Push.Milestones, int(milestone))
+		}
+		if stored.Push != nil {
+			typeSettings.Push.VAPIDPublicKey = stored.Push.VAPIDPublicKey
+			typeSettings.Push.VAPIDPrivateKey = stored.Push.VAPIDPrivateKey
+		}
+	}
+
+	if err := s.configService.SetNotificationSettings(ctx, typeSettings); err != nil {
+		return nil, err
+	}
+	return convertNotificationSettingsToDTO(typeSettings), nil
+}
+
+// GenerateVapidKeys replaces the VAPID keys of the tenant's Web Push messages with new ones
+func (s *Service) GenerateVapidKeys(ctx context.Context) (*dto.PushSettings, error) {
+	publicKey, privateKey, err := notification.GenerateVAPIDKeys()
+	if err != nil {
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to generate VAPID keys", 500, nil)
+	}
+
+	settings, err := s.configService.GetNotificationSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &types.NotificationSettings{}
+	}
+	if settings.Push == nil {
+		settings.Push = &types.PushSettings{}
+	}
+	settings.Push.VAPIDPublicKey = publicKey
+	settings.Push.VAPIDPrivateKey = privateKey
+	if err := s.configService.SetNotificationSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	return convertNotificationSettingsToDTO(settings).Push, nil
+}
+
+// IssueIntegrationCredentials replaces the API key and signing secret external systems push queue
+// events to the tenant with
+func (s *Service) IssueIntegrationCredentials(ctx context.Context) (*dto.IntegrationCredentials, error) {
+	apiKey, signingSecret, err := s.configService.IssueIntegrationCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.IntegrationCredentials{ApiKey: apiKey, SigningSecret: signingSecret}, nil
+}
+
+// isHTTPURL reports whether value is an http or https URL
+// FHIR Settings methods
+func (s *Service) GetFhirSettings(ctx context.Context) (*dto.FhirSettings, error) {
+	settings, err := s.configService.GetFHIRSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return convertFHIRSettingsToDTO(settings), nil
+}
+
+func (s *Service) UpdateFhirSettings(ctx context.Context, req *dto.FhirSettings) (*dto.FhirSettings, error) {
+	settings := &types.FHIRSettings{
+		Profiles:         map[string]string{},
+		IdentifierSystem: req.GetIdentifierSystem(),
+	}
+	for _, profile := range req.Profiles {
+		if profile.ResourceType != fhir.EncounterType && profile.ResourceType != fhir.AppointmentType {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("profiles are supported for Encounter and Appointment, not %s", profile.ResourceType), 400, nil)
+		}
+		if _, ok := settings.Profiles[profile.ResourceType]; ok {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("duplicate profile for %s", profile.ResourceType), 400, nil)
+		}
+		if !isHTTPURL(profile.Profile) && !strings.HasPrefix(profile.Profile, "urn:") {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, "profile must be an http(s) or urn: URL", 400, nil)
+		}
+		settings.Profiles[profile.ResourceType] = profile.Profile
+	}
+
+	if err := s.configService.SetFHIRSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	return convertFHIRSettingsToDTO(settings), nil
+}
+
+func convertFHIRSettingsToDTO(settings *types.FHIRSettings) *dto.FhirSettings {
+	result := &dto.FhirSettings{}
+	if settings == nil {
+		return result
+	}
+	if settings.IdentifierSystem != "" {
+		result.IdentifierSystem = &settings.IdentifierSystem
+	}
+	for _, resourceType := range []string{fhir.EncounterType, fhir.AppointmentType} {
+		if profile := settings.Profiles[resourceType]; profile != "" {
+			result.Profiles = append(result.Profiles, dto.FhirProfile{ResourceType: resourceType, Profile: profile})
+		}
+	}
+	return result
+}
+
+func isHTTPURL(value string) bool {
+	return strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://")
+}
+
+// convertNotificationSettingsToDTO converts notification settings without their passwords and tokens
+func convertNotificationSettingsToDTO(settings *types.NotificationSettings) *dto.NotificationSettings {
+	result := &dto.NotificationSettings{
+		ApproachingPeopleAhead: int64(settings.ApproachingPeopleAhead),
+	}
+	if settings.ApproachingMessage != "" {
+		approachingMessage := settings.ApproachingMessage
+		result.ApproachingMessage = &approachingMessage
+	}
+	if settings.CalledMessage != "" {
+		calledMessage := settings.CalledMessage
+		result.CalledMessage = &calledMessage
+	}
+	if smtp := settings.SMTP; smtp != nil {
+		result.Smtp = &dto.SmtpSettings{From: smtp.From, Host: smtp.Host}
+		if smtp.Port != 0 {
+			port := int64(smtp.Port)
+			result.Smtp.Port = &port
+		}
+		if smtp.Username != "" {
+			username := smtp.Username
+			result.Smtp.Username = &username
+		}
+	}
+	if sms := settings.SMS; sms != nil {
+		result.Sms = &dto.SmsSettings{AccountSid: sms.AccountSID, From: sms.From}
+		if sms.BaseURL != "" {
+			baseURL := sms.BaseURL
+			result.Sms.BaseUrl = &baseURL
+		}
+	}
+	if http := settings.HTTP; http != nil {
+		result.Http = &dto.HttpNotificationSettings{Url: http.URL}
+	}
+	if push := settings.Push; push != nil {
+		result.Push = &dto.PushSettings{}
+		for _, milestone := range push.Milestones {
+			result.Push.Milestones = append(result.Push.Milestones, int64(milestone))
+		}
+		if push.Subject != "" {
+			subject := push.Subject
+			result.Push.Subject = &subject
+		}
+		if push.VAPIDPublicKey != "" {
+			publicKey := push.VAPIDPublicKey
+			result.Push.VapidPublicKey = &publicKey
+		}
+	}
+	return result
+}
+
+// hasRoomAndServicePoint reports whether roomID is configured and has servicePointID (empty = any)
+func hasRoomAndServicePoint(rooms []types.RoomConfig, roomID, servicePointID string) bool {
+	for _, room := range rooms {
+		if room.ID != roomID {
+			continue
+		}
+		if servicePointID == "" {
+			return true
+		}
+		for _, sp := range room.ServicePoints {
+			if sp.ID == servicePointID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Card Reader methods
 func (s *Service) GetCardReaders(ctx context.Context) ([]dto.CardReaderStatus, error) {
 	readers, err := s.configService.GetCardReaders(ctx)
@@ -280,37 +798,277 @@ func (s *Service) GetCardReaders(ctx context.Context) ([]dto.CardReaderStatus, e
 		return nil, err
 	}
 
+	keys, err := s.configService.GetAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Last use of a card reader key of each device
+	keyLastUsed := make(map[string]time.Time)
+	for _, key := range keys {
+		if key.DeviceType == types.DeviceTypeCardReader && key.DeviceID != "" && key.LastUsedAt != nil && key.LastUsedAt.After(keyLastUsed[key.DeviceID]) {
+			keyLastUsed[key.DeviceID] = *key.LastUsedAt
+		}
+	}
+
 	// Convert types to DTOs
 	var dtoReaders []dto.CardReaderStatus
 	for _, reader := range readers {
-		dtoReaders = append(dtoReaders, s.convertCardReaderStatusToDTO(reader))
+		dtoReader := s.convertCardReaderStatusToDTO(reader)
+		if lastUsed, ok := keyLastUsed[reader.ID]; ok {
+			dtoReader.ApiKeyLastUsedAt = &lastUsed
+		}
+		commands, err := s.configService.GetCardReaderCommands(ctx, reader.ID, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(commands) > 0 {
+			dtoReader.LastCommand = convertCardReaderCommandToDTO(commands[0])
+		}
+		dtoReaders = append(dtoReaders, dtoReader)
 	}
 	return dtoReaders, nil
 }
 
-func (s *Service) RestartCardReader(ctx context.Context, id string) (*dto.RestartResponse, error) {
-	result, err := s.configService.RestartCardReader(ctx, id)
+// GetAPIKeys returns the API keys of the devices of the tenant
+func (s *Service) GetAPIKeys(ctx context.Context) ([]dto.APIKey, error) {
+	keys, err := s.configService.GetAPIKeys(ctx)
 	if err != nil {
 		return nil, err
 	}
-
-	// Convert map to DTO
-	success, ok := result["success"].(bool)
-	if !ok {
-		success = false
+	result := make([]dto.APIKey, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, *convertAPIKeyToDTO(key))
 	}
+	return result, nil
+}
 
-	message, ok := result["message"].(string)
-	if !ok {
-		message = "Unknown error"
+// CreateAPIKey issues an API key for a kiosk, card reader or display of the tenant
+func (s *Service) CreateAPIKey(ctx context.Context, req *dto.APIKeyRequest) (*dto.IssuedAPIKey, error) {
+	apiKey, err := apiKeyFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.configService.CreateAPIKey(ctx, apiKey)
+	if err != nil {
+		return nil, err
 	}
+	return &dto.IssuedAPIKey{ApiKey: convertAPIKeyToDTO(*apiKey), Key: key}, nil
+}
 
-	return &dto.RestartResponse{
-		Success: success,
-		Message: message,
+// RevokeAPIKey revokes an API key of the tenant
+// apiKeyFromRequest validates the device an API key is requested for
+func apiKeyFromRequest(req *dto.APIKeyRequest) (*types.APIKey, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "name is required", 400, nil)
+	}
+	deviceType := types.DeviceType(req.DeviceType)
+	if !slices.Contains(types.DeviceTypes, deviceType) {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "deviceType must be kiosk, card_reader or display", 400, nil)
+	}
+	return &types.APIKey{
+		Name:       name,
+		DeviceType: deviceType,
+		DeviceID:   strings.TrimSpace(req.GetDeviceId()),
 	}, nil
 }
 
+func (s *Service) RevokeAPIKey(ctx context.Context, id string) (*dto.APIKey, error) {
+	apiKey, err := s.configService.RevokeAPIKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == nil {
+		return nil, ngErrors.New(ngErrors.NotFoundErrorCode, fmt.Sprintf("API key %s not found", id), 404, nil)
+	}
+	return convertAPIKeyToDTO(*apiKey), nil
+}
+
+func convertAPIKeyToDTO(key types.APIKey) *dto.APIKey {
+	result := &dto.APIKey{
+		CreatedAt:  key.CreatedAt,
+		DeviceType: string(key.DeviceType),
+		Id:         key.ID,
+		KeyPrefix:  key.KeyPrefix,
+		LastUsedAt: key.LastUsedAt,
+		Name:       key.Name,
+		RevokedAt:  key.RevokedAt,
+	}
+	if key.DeviceID != "" {
+		result.DeviceId = &key.DeviceID
+	}
+	if key.LastUsedIP != "" {
+		result.LastUsedIp = &key.LastUsedIP
+	}
+	if key.TenantID != "" {
+		result.TenantId = &key.TenantID
+	}
+	return result
+}
+
+// GetCardReaderConfiguration returns the remote configuration a card reader device pulls at
+// startup. A device only gets its own configuration, and only within the tenant it is
+// registered to.
+func (s *Service) GetCardReaderConfiguration(ctx context.Context, id string) (*dto.CardReaderConfig, error) {
+	if deviceID, _ := ctx.Value(middleware.CARD_READER_DEVICE).(string); deviceID != id {
+		return nil, ngErrors.Forbidden("a card reader may only read its own configuration", nil)
+	}
+	// The registration is looked up whatever tenant the reader sent, then scoped to its tenant
+	readerConfig, err := s.configService.GetCardReaderConfig(context.WithValue(ctx, middleware.TENANT, ""), id)
+	if err != nil {
+		return nil, err
+	}
+	if readerConfig == nil {
+		return nil, ngErrors.EntityNotFound()
+	}
+	if tenantID := service.GetTenantID(ctx); tenantID != "" && tenantID != readerConfig.TenantID {
+		return nil, ngErrors.Forbidden(fmt.Sprintf("card reader %s is not registered to tenant %s", id, tenantID), nil)
+	}
+	return s.convertCardReaderConfigToDTO(*readerConfig), nil
+}
+
+// UpdateCardReaderConfiguration creates or replaces the remote configuration of a card reader device
+func (s *Service) UpdateCardReaderConfiguration(ctx context.Context, id string, req *dto.CardReaderConfig) (*dto.CardReaderConfig, error) {
+	// Readers are registered to the tenant of the request; only requests without one may name it
+	if tenantID := service.GetTenantID(ctx); tenantID != "" && req.TenantId != nil && *req.TenantId != "" && *req.TenantId != tenantID {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("tenantId %s differs from the tenant of the request %s", *req.TenantId, tenantID), 400, nil)
+	}
+	readerConfig := &types.CardReaderConfig{
+		DeviceID:               id,
+		TenantID:               getStringValue(req.TenantId),
+		RoomID:                 req.RoomId,
+		ReaderName:             getStringValue(req.ReaderName),
+		PKCS11Module:           getStringValue(req.Pkcs11Module),
+		WebSocketURL:           getStringValue(req.WebSocketUrl),
+		RefreshIntervalSeconds: int(req.GetRefreshIntervalSeconds()),
+		AutoCheckIn:            req.GetAutoCheckIn(),
+		AutoCheckInServiceID:   req.GetAutoCheckInServiceId(),
+		AutoCheckInDuration:    int(req.GetAutoCheckInServiceDuration()),
+		ClientCertFingerprint:  strings.ToLower(strings.ReplaceAll(getStringValue(req.ClientCertFingerprint), ":", "")),
+	}
+
+	// Credentials are only changed through IssueCardReaderToken / RotateCardReaderEncryptionKey
+	existing, err := s.configService.GetCardReaderConfig(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		readerConfig.DeviceTokenHash = existing.DeviceTokenHash
+		readerConfig.CardDataKey = existing.CardDataKey
+	}
+
+	if err := s.configService.SetCardReaderConfig(ctx, readerConfig); err != nil {
+		return nil, err
+	}
+	return s.convertCardReaderConfigToDTO(*readerConfig), nil
+}
+
+// IssueCardReaderToken rotates the bearer token a card reader uses to authenticate its WebSocket
+func (s *Service) IssueCardReaderToken(ctx context.Context, id string) (*dto.CardReaderToken, error) {
+	readerConfig, err := s.configService.GetCardReaderConfig(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if readerConfig == nil {
+		return nil, ngErrors.EntityNotFound()
+	}
+	token, err := s.configService.IssueCardReaderToken(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.CardReaderToken{DeviceId: id, Token: token}, nil
+}
+
+// CreateSubscriptionToken signs a WebSocket subscription token for a role and tenant. Without a
+// tenant in the request the token covers the tenant of the request's X-Tenant-ID header.
+func (s *Service) CreateSubscriptionToken(ctx context.Context, req *dto.SubscriptionTokenRequest) (*dto.SubscriptionToken, error) {
+	if s.subscriptionTokenSecret == "" {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "websocket.token_secret is not configured", 400, nil)
+	}
+	if !middleware.IsSubscriptionRole(req.Role) {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "role must be display, staff or kiosk", 400, nil)
+	}
+	ttl := defaultSubscriptionTokenTTL
+	if req.TtlSeconds != nil {
+		if *req.TtlSeconds <= 0 {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, "ttlSeconds must be positive", 400, nil)
+		}
+		ttl = time.Duration(*req.TtlSeconds) * time.Second
+	}
+	tenantID := service.GetTenantID(ctx)
+	if req.TenantId != nil {
+		tenantID = strings.TrimSpace(*req.TenantId)
+	}
+	if tenantID == "" {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "subscription tokens are issued for a tenant, tenantId or X-Tenant-ID is required", 400, nil)
+	}
+
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+	token, err := middleware.SignSubscriptionToken(s.subscriptionTokenSecret, middleware.SubscriptionClaims{
+		Role:      req.Role,
+		TenantID:  tenantID,
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to sign subscription token", 500, nil)
+	}
+
+	result := &dto.SubscriptionToken{ExpiresAt: expiresAt, Role: req.Role, Token: token}
+	if tenantID != "" {
+		result.TenantId = &tenantID
+	}
+	return result, nil
+}
+
+// GetLatestCardReaderRelease returns the release card readers on os/arch should run
+func (s *Service) GetLatestCardReaderRelease(ctx context.Context, os string, arch string) (*dto.CardReaderRelease, error) {
+	release, err := s.configService.GetCardReaderRelease(ctx, os, arch)
+	if err != nil {
+		return nil, err
+	}
+	if release == nil {
+		return nil, ngErrors.EntityNotFound()
+	}
+	return s.convertCardReaderReleaseToDTO(*release), nil
+}
+
+// PublishCardReaderRelease publishes a signed card-reader build. The signature is only
+// checked by the readers, which hold the release public key; the API never sees the private key.
+func (s *Service) PublishCardReaderRelease(ctx context.Context, req *dto.CardReaderRelease) (*dto.CardReaderRelease, error) {
+	release := &types.CardReaderRelease{
+		Version:   strings.TrimPrefix(strings.TrimSpace(req.Version), "v"),
+		OS:        strings.ToLower(req.Os),
+		Arch:      strings.ToLower(req.Arch),
+		URL:       req.Url,
+		SHA256:    strings.ToLower(req.Sha256),
+		Signature: req.Signature,
+	}
+	if err := s.configService.PublishCardReaderRelease(ctx, release); err != nil {
+		return nil, err
+	}
+	return s.convertCardReaderReleaseToDTO(*release), nil
+}
+
+// RotateCardReaderEncryptionKey provisions a new CardData encryption key for a card reader
+func (s *Service) RotateCardReaderEncryptionKey(ctx context.Context, id string) (*dto.CardReaderEncryptionKey, error) {
+	readerConfig, err := s.configService.GetCardReaderConfig(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if readerConfig == nil {
+		return nil, ngErrors.EntityNotFound()
+	}
+	key, err := s.configService.RotateCardDataKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	keyID, err := cardreader.CardDataKeyID(key)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.CardReaderEncryptionKey{DeviceId: id, Key: key, KeyId: keyID}, nil
+}
+
 // Helper conversion methods
 func (s *Service) convertSystemConfigurationToDTO(config *types.SystemConfiguration) *dto.SystemConfiguration {
 	if config == nil {
@@ -342,6 +1100,24 @@ func (s *Service) convertSystemConfigurationToDTO(config *types.SystemConfigurat
 		retries := int64(config.ExternalAPI.WebhookRetryAttempts)
 		externalAPI.WebhookRetryAttempts = &retries
 	}
+	if len(config.ExternalAPI.WebhookEvents) > 0 {
+		externalAPI.WebhookEvents = config.ExternalAPI.WebhookEvents
+	}
+	if config.ExternalAPI.WebhookPayloadTemplate != "" {
+		externalAPI.WebhookPayloadTemplate = &config.ExternalAPI.WebhookPayloadTemplate
+	}
+	if config.ExternalAPI.HISProvider != "" {
+		externalAPI.HisProvider = &config.ExternalAPI.HISProvider
+	}
+	if config.ExternalAPI.NGHISAdapterURL != "" {
+		externalAPI.NghisAdapterUrl = &config.ExternalAPI.NGHISAdapterURL
+	}
+	if config.ExternalAPI.VisitNotificationURL != "" {
+		externalAPI.VisitNotificationUrl = &config.ExternalAPI.VisitNotificationURL
+	}
+	if config.ExternalAPI.TranslationProvider != "" {
+		externalAPI.TranslationProvider = &config.ExternalAPI.TranslationProvider
+	}
 
 	// Convert Rooms
 	var dtoRooms []dto.RoomConfig
@@ -389,6 +1165,16 @@ func (s *Service) convertDTOToSystemConfiguration(dtoConfig *dto.SystemConfigura
 	if dtoConfig.ExternalAPI.WebhookRetryAttempts != nil && *dtoConfig.ExternalAPI.WebhookRetryAttempts > 0 {
 		externalAPI.WebhookRetryAttempts = int(*dtoConfig.ExternalAPI.WebhookRetryAttempts)
 	}
+	if len(dtoConfig.ExternalAPI.WebhookEvents) > 0 {
+		externalAPI.WebhookEvents = dtoConfig.ExternalAPI.WebhookEvents
+	}
+	if dtoConfig.ExternalAPI.WebhookPayloadTemplate != nil {
+		externalAPI.WebhookPayloadTemplate = *dtoConfig.ExternalAPI.WebhookPayloadTemplate
+	}
+	externalAPI.HISProvider = dtoConfig.ExternalAPI.GetHisProvider()
+	externalAPI.NGHISAdapterURL = dtoConfig.ExternalAPI.GetNghisAdapterUrl()
+	externalAPI.VisitNotificationURL = dtoConfig.ExternalAPI.GetVisitNotificationUrl()
+	externalAPI.TranslationProvider = dtoConfig.ExternalAPI.GetTranslationProvider()
 
 	// Convert Rooms
 	var typeRooms []types.RoomConfig
@@ -434,6 +1220,7 @@ func (s *Service) convertRoomConfigToDTO(room types.RoomConfig) dto.RoomConfig {
 		if sp.ManagerName != "" {
 			spConfig.ManagerName = &sp.ManagerName
 		}
+		spConfig.Services = sp.Services
 		dtoServicePoints = append(dtoServicePoints, spConfig)
 	}
 
@@ -447,6 +1234,34 @@ func (s *Service) convertRoomConfigToDTO(room types.RoomConfig) dto.RoomConfig {
 	if room.Description != "" {
 		roomConfig.Description = &room.Description
 	}
+	if room.Ordering != "" {
+		ordering := queueordering.QueueOrdering(room.Ordering)
+		roomConfig.Ordering = &ordering
+	}
+	if room.MaxWaiting > 0 {
+		maxWaiting := int64(room.MaxWaiting)
+		roomConfig.MaxWaiting = &maxWaiting
+	}
+	if room.OverflowRoomID != "" {
+		roomConfig.OverflowRoomId = &room.OverflowRoomID
+	}
+	if room.Assignment != "" {
+		assignment := assignmentstrategy.AssignmentStrategy(room.Assignment)
+		roomConfig.Assignment = &assignment
+	}
+	if room.ServiceFallback != "" {
+		serviceFallback := servicefallback.ServiceFallback(room.ServiceFallback)
+		roomConfig.ServiceFallback = &serviceFallback
+	}
+	if room.Display != nil {
+		callHistory := int64(room.Display.CallHistory)
+		blinkSeconds := int64(room.Display.BlinkSeconds)
+		roomConfig.Display = &dto.RoomDisplay{
+			Announce:     &room.Display.Announce,
+			BlinkSeconds: &blinkSeconds,
+			CallHistory:  &callHistory,
+		}
+	}
 
 	return roomConfig
 }
@@ -468,15 +1283,70 @@ func (s *Service) convertDTOToRoomConfig(dtoRoom dto.RoomConfig) types.RoomConfi
 		if sp.ManagerName != nil {
 			spConfig.ManagerName = *sp.ManagerName
 		}
+		spConfig.Services = sp.Services
 		typeServicePoints = append(typeServicePoints, spConfig)
 	}
 
+	var display *types.RoomDisplay
+	if dtoRoom.Display != nil {
+		display = &types.RoomDisplay{
+			CallHistory:  int(dtoRoom.Display.GetCallHistory()),
+			BlinkSeconds: int(dtoRoom.Display.GetBlinkSeconds()),
+			Announce:     dtoRoom.Display.GetAnnounce(),
+		}
+	}
+
 	return types.RoomConfig{
-		ID:            dtoRoom.Id,
-		Name:          dtoRoom.Name,
-		ServicePoints: typeServicePoints,
-		IsDefault:     dtoRoom.IsDefault,
-		Description:   getStringValue(dtoRoom.Description),
+		ID:              dtoRoom.Id,
+		Name:            dtoRoom.Name,
+		ServicePoints:   typeServicePoints,
+		IsDefault:       dtoRoom.IsDefault,
+		Description:     getStringValue(dtoRoom.Description),
+		Ordering:        types.QueueOrdering(dtoRoom.GetOrdering()),
+		MaxWaiting:      int(dtoRoom.GetMaxWaiting()),
+		OverflowRoomID:  dtoRoom.GetOverflowRoomId(),
+		ServiceFallback: types.ServiceFallback(dtoRoom.GetServiceFallback()),
+		Assignment:      types.AssignmentStrategy(dtoRoom.GetAssignment()),
+		Display:         display,
+	}
+}
+
+func (s *Service) convertPathwayToDTO(pathway types.Pathway) dto.Pathway {
+	var dtoStages []dto.PathwayStage
+	for _, stage := range pathway.Stages {
+		stageConfig := dto.PathwayStage{
+			Name:   stage.Name,
+			RoomId: stage.RoomID,
+		}
+		if stage.ServicePointID != "" {
+			stageConfig.ServicePointId = &stage.ServicePointID
+		}
+		dtoStages = append(dtoStages, stageConfig)
+	}
+
+	return dto.Pathway{
+		Id:         pathway.ID,
+		Name:       pathway.Name,
+		ServiceIds: pathway.ServiceIDs,
+		Stages:     dtoStages,
+	}
+}
+
+func (s *Service) convertDTOToPathway(dtoPathway dto.Pathway) types.Pathway {
+	var typeStages []types.PathwayStage
+	for _, stage := range dtoPathway.Stages {
+		typeStages = append(typeStages, types.PathwayStage{
+			Name:           stage.Name,
+			RoomID:         stage.RoomId,
+			ServicePointID: getStringValue(stage.ServicePointId),
+		})
+	}
+
+	return types.Pathway{
+		ID:         dtoPathway.Id,
+		Name:       dtoPathway.Name,
+		ServiceIDs: dtoPathway.ServiceIds,
+		Stages:     typeStages,
 	}
 }
 
@@ -510,6 +1380,50 @@ func (s *Service) convertCardReaderStatusToDTO(reader types.CardReaderStatus) dt
 	return cardReader
 }
 
+func (s *Service) convertCardReaderConfigToDTO(readerConfig types.CardReaderConfig) *dto.CardReaderConfig {
+	result := &dto.CardReaderConfig{
+		DeviceId:         readerConfig.DeviceID,
+		RoomId:           readerConfig.RoomID,
+		HasDeviceToken:   readerConfig.DeviceTokenHash != "",
+		HasEncryptionKey: readerConfig.CardDataKey != "",
+	}
+
+	if readerConfig.TenantID != "" {
+		result.TenantId = &readerConfig.TenantID
+	}
+	if readerConfig.ReaderName != "" {
+		result.ReaderName = &readerConfig.ReaderName
+	}
+	if readerConfig.PKCS11Module != "" {
+		result.Pkcs11Module = &readerConfig.PKCS11Module
+	}
+	if readerConfig.WebSocketURL != "" {
+		result.WebSocketUrl = &readerConfig.WebSocketURL
+	}
+	if readerConfig.ClientCertFingerprint != "" {
+		result.ClientCertFingerprint = &readerConfig.ClientCertFingerprint
+	}
+	if readerConfig.RefreshIntervalSeconds > 0 {
+		refresh := int64(readerConfig.RefreshIntervalSeconds)
+		result.RefreshIntervalSeconds = &refresh
+	}
+	if readerConfig.AutoCheckIn {
+		result.AutoCheckIn = &readerConfig.AutoCheckIn
+	}
+	if readerConfig.AutoCheckInServiceID != "" {
+		result.AutoCheckInServiceId = &readerConfig.AutoCheckInServiceID
+	}
+	if readerConfig.AutoCheckInDuration > 0 {
+		duration := int64(readerConfig.AutoCheckInDuration)
+		result.AutoCheckInServiceDuration = &duration
+	}
+	if !readerConfig.UpdatedAt.IsZero() {
+		result.UpdatedAt = &readerConfig.UpdatedAt
+	}
+
+	return result
+}
+
 // Helper function to get string value from pointer
 func getStringValue(ptr *string) string {
 	if ptr == nil {
@@ -532,6 +1446,16 @@ func getInt64Value(m map[string]interface{}, key string) *int64 {
 	return nil
 }
 
+// Helper function to get bool value from map
+func getBoolValue(m map[string]interface{}, key string) *bool {
+	if val, ok := m[key]; ok {
+		if b, ok := val.(bool); ok {
+			return &b
+		}
+	}
+	return nil
+}
+
 // Helper function to get string value from map
 func getStringValueFromMap(m map[string]interface{}, key string) *string {
 	if val, ok := m[key]; ok {
@@ -547,28 +1471,116 @@ func (s *Service) GetTranslationCacheStats(ctx context.Context) (*dto.Translatio
 	if s.translationService == nil {
 		return &dto.TranslationCacheStats{}, fmt.Errorf("translation service not configured")
 	}
-	stats := s.translationService.GetCacheStats()
+	stats := s.translationService.GetCacheStats(ctx)
 
 	// Convert map to DTO
 	result := &dto.TranslationCacheStats{
-		Cache_size:      getInt64Value(stats, "cache_size"),
-		Max_cache_size:  getInt64Value(stats, "max_cache_size"),
-		Hits:            getInt64Value(stats, "hits"),
-		Misses:          getInt64Value(stats, "misses"),
-		Total_requests:  getInt64Value(stats, "total_requests"),
-		Hit_rate:        getStringValueFromMap(stats, "hit_rate"),
-		Api_calls_saved: getInt64Value(stats, "api_calls_saved"),
-		Expiration_time: getStringValueFromMap(stats, "expiration_time"),
+		Cache_size:          getInt64Value(stats, "cache_size"),
+		Max_cache_size:      getInt64Value(stats, "max_cache_size"),
+		Hits:                getInt64Value(stats, "hits"),
+		Misses:              getInt64Value(stats, "misses"),
+		Total_requests:      getInt64Value(stats, "total_requests"),
+		Hit_rate:            getStringValueFromMap(stats, "hit_rate"),
+		Api_calls_saved:     getInt64Value(stats, "api_calls_saved"),
+		Expiration_time:     getStringValueFromMap(stats, "expiration_time"),
+		Persistent:          getBoolValue(stats, "persistent"),
+		Persistent_size:     getInt64Value(stats, "persistent_size"),
+		Persistent_hits:     getInt64Value(stats, "persistent_hits"),
+		Persistent_misses:   getInt64Value(stats, "persistent_misses"),
+		Persistent_hit_rate: getStringValueFromMap(stats, "persistent_hit_rate"),
+		Warmed_entries:      getInt64Value(stats, "warmed_entries"),
+	}
+	return result, nil
+}
+
+// GetExternalAPIStats returns the call statistics of the HIS endpoints of the tenant
+func (s *Service) GetExternalAPIStats(ctx context.Context) ([]dto.ExternalAPIEndpointStats, error) {
+	endpoints := his.Stats(service.GetTenantID(ctx))
+	result := make([]dto.ExternalAPIEndpointStats, len(endpoints))
+	for i, endpoint := range endpoints {
+		stats := dto.ExternalAPIEndpointStats{
+			Endpoint:         endpoint.Endpoint,
+			Requests:         endpoint.Requests,
+			Failures:         endpoint.Failures,
+			Retries:          endpoint.Retries,
+			AverageLatencyMs: float64(endpoint.AverageLatency) / float64(time.Millisecond),
+			CircuitOpen:      endpoint.CircuitOpen,
+		}
+		if endpoint.Requests > 0 {
+			stats.ErrorRate = float64(endpoint.Failures) / float64(endpoint.Requests)
+		}
+		if endpoint.LastError != "" {
+			stats.LastError = &endpoint.LastError
+			stats.LastFailureAt = &endpoint.LastFailureAt
+		}
+		result[i] = stats
 	}
 	return result, nil
 }
 
+// GetTranslationGlossary returns the translation glossary of the tenant
+func (s *Service) GetTranslationGlossary(ctx context.Context) ([]dto.TranslationGlossaryEntry, error) {
+	entries, err := s.translationService.GetGlossary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]dto.TranslationGlossaryEntry, len(entries))
+	for i, entry := range entries {
+		result[i] = dto.TranslationGlossaryEntry{
+			SourceLang: entry.SourceLang,
+			TargetLang: entry.TargetLang,
+			SourceText: entry.SourceText,
+			TargetText: entry.TargetText,
+			UpdatedAt:  &entry.UpdatedAt,
+		}
+	}
+	return result, nil
+}
+
+// UpdateTranslationGlossary replaces the translation glossary of the tenant; a text may have one
+// translation per language pair
+func (s *Service) UpdateTranslationGlossary(ctx context.Context, entries []dto.TranslationGlossaryEntry) ([]dto.TranslationGlossaryEntry, error) {
+	glossary, err := glossaryFromDTO(entries)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.translationService.SetGlossary(ctx, glossary); err != nil {
+		return nil, err
+	}
+	return s.GetTranslationGlossary(ctx)
+}
+
+// glossaryFromDTO validates and normalizes the entries of a glossary
+func glossaryFromDTO(entries []dto.TranslationGlossaryEntry) ([]*types.GlossaryEntry, error) {
+	glossary := make([]*types.GlossaryEntry, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		sourceLang := strings.ToLower(strings.TrimSpace(entry.SourceLang))
+		targetLang := strings.ToLower(strings.TrimSpace(entry.TargetLang))
+		if sourceLang == targetLang {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("glossary entry %q translates %s to itself", entry.SourceText, sourceLang), 400, nil)
+		}
+		key := sourceLang + "|" + targetLang + "|" + entry.SourceText
+		if seen[key] {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("duplicate glossary entry %q from %s to %s", entry.SourceText, sourceLang, targetLang), 400, nil)
+		}
+		seen[key] = true
+		glossary = append(glossary, &types.GlossaryEntry{
+			SourceLang: sourceLang,
+			TargetLang: targetLang,
+			SourceText: entry.SourceText,
+			TargetText: entry.TargetText,
+		})
+	}
+	return glossary, nil
+}
+
 // ClearTranslationCache clears the translation cache
 func (s *Service) ClearTranslationCache(ctx context.Context) (*dto.CacheClearResponse, error) {
 	if s.translationService == nil {
 		return nil, fmt.Errorf("translation service not configured")
 	}
-	s.translationService.ClearCache()
+	s.translationService.ClearCache(ctx)
 	msg := "Cache cleared successfully"
 	return &dto.CacheClearResponse{Message: &msg}, nil
 }
@@ -608,6 +1620,10 @@ func (s *Service) DeleteTenant(ctx context.Context, id string) error {
 	return s.tenantService.DeleteTenant(ctx, id)
 }
 
+func (s *Service) ReactivateTenant(ctx context.Context, id string) (*dto.Tenant, error) {
+	return s.tenantService.ReactivateTenant(ctx, id)
+}
+
 // Priority Configuration methods
 func (s *Service) GetPriorityConfiguration(ctx context.Context) (*dto.PriorityConfig, error) {
 	config, err := s.priorityService.GetPriorityConfig(ctx)
@@ -618,16 +1634,147 @@ func (s *Service) GetPriorityConfiguration(ctx context.Context) (*dto.PriorityCo
 }
 
 func (s *Service) UpdatePriorityConfiguration(ctx context.Context, configDTO *dto.PriorityConfig) (*dto.PriorityConfig, error) {
-	// Convert DTO to priority config
-	config := s.convertDTOToPriorityConfig(configDTO)
+	config, err := s.priorityConfigFromDTO(ctx, configDTO)
+	if err != nil {
+		return nil, err
+	}
 
 	// Save configuration
-	err := s.priorityService.SavePriorityConfig(ctx, config)
+	err = s.priorityService.SavePriorityConfig(ctx, config)
+	if err != nil {
+		return nil, priorityConfigError(err)
+	}
+
+	return s.convertPriorityConfigToDTO(config), nil
+}
+
+func (s *Service) GetPriorityConfigurationRevisions(ctx context.Context) ([]dto.PriorityConfigRevision, error) {
+	revisions, err := s.priorityService.GetRevisions(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return configDTO, nil
+	revisionsDTO := make([]dto.PriorityConfigRevision, 0, len(revisions))
+	for _, revision := range revisions {
+		revisionDTO := dto.PriorityConfigRevision{
+			Config:   s.convertPriorityConfigToDTO(&revision.Config),
+			Revision: int64(revision.Revision),
+			SavedAt:  revision.SavedAt,
+		}
+		if revision.SavedBy != "" {
+			savedBy := revision.SavedBy
+			revisionDTO.SavedBy = &savedBy
+		}
+		if revision.RestoredFrom != 0 {
+			restoredFrom := int64(revision.RestoredFrom)
+			revisionDTO.RestoredFrom = &restoredFrom
+		}
+		revisionsDTO = append(revisionsDTO, revisionDTO)
+	}
+	return revisionsDTO, nil
+}
+
+func (s *Service) RollbackPriorityConfiguration(ctx context.Context, revision int64) (*dto.PriorityConfig, error) {
+	config, err := s.priorityService.RollbackToRevision(ctx, int(revision))
+	if err != nil {
+		return nil, priorityConfigError(err)
+	}
+	return s.convertPriorityConfigToDTO(config), nil
+}
+
+func (s *Service) DryRunPriorityConfiguration(ctx context.Context, req *dto.PriorityDryRunRequest) (*dto.PriorityDryRunResult, error) {
+	var candidate *priority.PriorityConfig
+	if req.Config != nil {
+		var err error
+		if candidate, err = s.priorityConfigFromDTO(ctx, req.Config); err != nil {
+			return nil, err
+		}
+	}
+
+	patient := req.GetPatient()
+	now := time.Now()
+	if req.At != nil {
+		now = *req.At
+	}
+	input := priority.CalculationInput{
+		Symbols:         patient.Symbols,
+		AppointmentTime: patient.GetAppointmentTimePtr(),
+		ManualOverride:  patient.ManualOverride,
+		ArrivalTime:     now.Add(-time.Duration(req.GetWaitingMinutes()) * time.Minute),
+		CurrentTime:     now,
+	}
+	if patient.Age != nil {
+		age := int(*patient.Age)
+		input.Age = &age
+	}
+
+	explanation, err := s.priorityService.DryRun(ctx, candidate, input)
+	if err != nil {
+		return nil, priorityConfigError(err)
+	}
+
+	result := &dto.PriorityDryRunResult{
+		ActiveRules: explanation.ActiveRules,
+		Fitness: &dto.PriorityFitnessBreakdown{
+			Age:                  explanation.Breakdown.Age,
+			AppointmentDeviation: explanation.Breakdown.AppointmentDeviation,
+			ManualOverride:       explanation.Breakdown.ManualOverride,
+			Starvation:           explanation.Breakdown.Starvation,
+			SymbolWeights:        explanation.Breakdown.SymbolWeights,
+			WaitingTime:          explanation.Breakdown.WaitingTime,
+		},
+		FitnessScore: explanation.FitnessScore,
+		Symbols:      explanation.Symbols,
+		Tier:         int64(explanation.Tier),
+	}
+	if explanation.TierName != "" {
+		result.TierName = &explanation.TierName
+	}
+	if explanation.Escalated {
+		result.Escalated = &explanation.Escalated
+	}
+	return result, nil
+}
+
+// priorityConfigFromDTO converts an edited priority configuration and checks its symbol catalog
+func (s *Service) priorityConfigFromDTO(ctx context.Context, configDTO *dto.PriorityConfig) (*priority.PriorityConfig, error) {
+	// Convert DTO to priority config
+	config := s.convertDTOToPriorityConfig(configDTO)
+
+	// Editors that predate the symbol catalog, holiday calendar and starvation protection do not
+	// send them; keep the stored ones
+	keepStarvation := configDTO.PriorityModel.Starvation == nil
+	if configDTO.Symbols == nil || configDTO.Holidays == nil || configDTO.Timezone == nil || keepStarvation {
+		stored, err := s.priorityService.GetPriorityConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if configDTO.Symbols == nil {
+			config.Symbols = stored.Symbols
+		}
+		if configDTO.Holidays == nil {
+			config.Holidays = stored.Holidays
+		}
+		if configDTO.Timezone == nil {
+			config.Timezone = stored.Timezone
+		}
+		if keepStarvation {
+			config.PriorityModel.Starvation = stored.PriorityModel.Starvation
+		}
+	}
+	if configDTO.Symbols != nil {
+		symbols := config.Symbols
+		config.Symbols = nil
+		for _, symbol := range symbols {
+			if err := config.AddSymbol(symbol); err != nil {
+				return nil, ngErrors.New(ngErrors.ValidationErrorCode, err.Error(), 400, nil)
+			}
+		}
+	}
+	if err := config.ValidateSymbolReferences(); err != nil {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, err.Error(), 400, nil)
+	}
+	return config, nil
 }
 
 func (s *Service) GetDefaultPriorityConfiguration(ctx context.Context) (*dto.PriorityConfig, error) {
@@ -638,6 +1785,51 @@ func (s *Service) GetDefaultPriorityConfiguration(ctx context.Context) (*dto.Pri
 	return s.convertPriorityConfigToDTO(config), nil
 }
 
+// Priority symbol catalog methods
+func (s *Service) GetPrioritySymbols(ctx context.Context) ([]dto.PrioritySymbol, error) {
+	symbols, err := s.priorityService.GetSymbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return convertPrioritySymbolsToDTO(symbols), nil
+}
+
+func (s *Service) CreatePrioritySymbol(ctx context.Context, symbolDTO *dto.PrioritySymbol) (*dto.PrioritySymbol, error) {
+	symbol, err := s.priorityService.AddSymbol(ctx, convertDTOToPrioritySymbol(*symbolDTO))
+	if err != nil {
+		return nil, priorityConfigError(err)
+	}
+	return convertPrioritySymbolToDTO(*symbol), nil
+}
+
+func (s *Service) UpdatePrioritySymbol(ctx context.Context, code string, symbolDTO *dto.PrioritySymbol) (*dto.PrioritySymbol, error) {
+	symbol, err := s.priorityService.UpdateSymbol(ctx, code, convertDTOToPrioritySymbol(*symbolDTO))
+	if err != nil {
+		return nil, priorityConfigError(err)
+	}
+	return convertPrioritySymbolToDTO(*symbol), nil
+}
+
+func (s *Service) DeletePrioritySymbol(ctx context.Context, code string) error {
+	if err := s.priorityService.RemoveSymbol(ctx, code); err != nil {
+		return priorityConfigError(err)
+	}
+	return nil
+}
+
+// priorityConfigError maps the priority configuration and symbol catalog errors to application errors
+func priorityConfigError(err error) error {
+	switch {
+	case errors.Is(err, priority.ErrSymbolNotFound), errors.Is(err, priority.ErrRevisionNotFound):
+		return ngErrors.New(ngErrors.NotFoundErrorCode, err.Error(), 404, nil)
+	case errors.Is(err, priority.ErrInvalidSymbol), errors.Is(err, priority.ErrInvalidConfig), errors.Is(err, priority.ErrUnknownSymbol):
+		return ngErrors.New(ngErrors.ValidationErrorCode, err.Error(), 400, nil)
+	case errors.Is(err, priority.ErrSymbolInUse):
+		return ngErrors.New(ngErrors.BusinessErrorCode, err.Error(), 409, nil)
+	}
+	return err
+}
+
 // Priority Config conversion methods
 func (s *Service) convertPriorityConfigToDTO(config *priority.PriorityConfig) *dto.PriorityConfig {
 	if config == nil {
@@ -658,17 +1850,66 @@ func (s *Service) convertPriorityConfigToDTO(config *priority.PriorityConfig) *d
 		tiersDTO = append(tiersDTO, tierDTO)
 	}
 
-	return &dto.PriorityConfig{
+	var holidaysDTO []dto.PriorityHoliday
+	for _, holiday := range config.Holidays {
+		holidayDTO := dto.PriorityHoliday{Date: holiday.Date}
+		if holiday.Name != "" {
+			name := holiday.Name
+			holidayDTO.Name = &name
+		}
+		holidaysDTO = append(holidaysDTO, holidayDTO)
+	}
+
+	var scheduleRulesDTO []dto.ScheduleRule
+	for _, rule := range config.PriorityModel.Fitness.ScheduleRules {
+		ruleDTO := dto.ScheduleRule{
+			Name:          rule.Name,
+			Days:          rule.Days,
+			Multipliers:   rule.Multipliers,
+			SymbolWeights: rule.SymbolWeights,
+		}
+		if rule.From != "" {
+			from := rule.From
+			ruleDTO.From = &from
+		}
+		if rule.To != "" {
+			to := rule.To
+			ruleDTO.To = &to
+		}
+		scheduleRulesDTO = append(scheduleRulesDTO, ruleDTO)
+	}
+
+	starvation := config.PriorityModel.Starvation
+	starvationDTO := &dto.StarvationProtection{
+		Description:      &starvation.Description,
+		Enabled:          starvation.Enabled,
+		ThresholdMinutes: starvation.ThresholdMinutes,
+	}
+	if starvation.Mode != "" {
+		starvationDTO.Mode = &starvation.Mode
+	}
+	if starvation.Bonus != 0 {
+		starvationDTO.Bonus = &starvation.Bonus
+	}
+	if starvation.DoublingMinutes != 0 {
+		starvationDTO.DoublingMinutes = &starvation.DoublingMinutes
+	}
+
+	configDTO := &dto.PriorityConfig{
 		Version:     config.Version,
 		Description: &config.Description,
+		Symbols:     convertPrioritySymbolsToDTO(config.Symbols),
+		Holidays:    holidaysDTO,
 		PriorityModel: &dto.PriorityModel{
 			Algorithm: &dto.Algorithm{
 				Explanation:    config.PriorityModel.Algorithm.Explanation,
 				OrderingFields: config.PriorityModel.Algorithm.OrderingFields,
 			},
-			Tiers: tiersDTO,
+			Tiers:      tiersDTO,
+			Starvation: starvationDTO,
 			Fitness: &dto.FitnessConfig{
-				Explanation: &config.PriorityModel.Fitness.Explanation,
+				Explanation:   &config.PriorityModel.Fitness.Explanation,
+				ScheduleRules: scheduleRulesDTO,
 				Contributions: &dto.Contributions{
 					SymbolWeights: &dto.SymbolWeights{
 						Description: &config.PriorityModel.Fitness.Contributions.SymbolWeights.Description,
@@ -698,6 +1939,10 @@ func (s *Service) convertPriorityConfigToDTO(config *priority.PriorityConfig) *d
 			},
 		},
 	}
+	if config.Timezone != "" {
+		configDTO.Timezone = &config.Timezone
+	}
+	return configDTO
 }
 
 func (s *Service) convertDTOToPriorityConfig(configDTO *dto.PriorityConfig) *priority.PriorityConfig {
@@ -723,18 +1968,31 @@ func (s *Service) convertDTOToPriorityConfig(configDTO *dto.PriorityConfig) *pri
 		tiers = append(tiers, tier)
 	}
 
+	var symbols []priority.Symbol
+	for _, symbolDTO := range configDTO.Symbols {
+		symbols = append(symbols, convertDTOToPrioritySymbol(symbolDTO))
+	}
+
 	config := &priority.PriorityConfig{
 		Version: configDTO.Version,
 		PriorityModel: priority.PriorityModel{
 			Tiers:   tiers,
 			Fitness: priority.FitnessConfig{},
 		},
+		Symbols: symbols,
 	}
 
 	// Copy optional fields
 	if configDTO.Description != nil {
 		config.Description = *configDTO.Description
 	}
+	config.Timezone = configDTO.GetTimezone()
+	for _, holidayDTO := range configDTO.Holidays {
+		config.Holidays = append(config.Holidays, priority.Holiday{
+			Date: holidayDTO.Date,
+			Name: holidayDTO.GetName(),
+		})
+	}
 
 	if configDTO.PriorityModel.Algorithm != nil {
 		config.PriorityModel.Algorithm = priority.Algorithm{
@@ -743,12 +2001,34 @@ func (s *Service) convertDTOToPriorityConfig(configDTO *dto.PriorityConfig) *pri
 		}
 	}
 
+	if starvationDTO := configDTO.PriorityModel.Starvation; starvationDTO != nil {
+		config.PriorityModel.Starvation = priority.StarvationProtection{
+			Description:      starvationDTO.GetDescription(),
+			Enabled:          starvationDTO.Enabled,
+			ThresholdMinutes: starvationDTO.ThresholdMinutes,
+			Mode:             starvationDTO.GetMode(),
+			Bonus:            starvationDTO.GetBonus(),
+			DoublingMinutes:  starvationDTO.GetDoublingMinutes(),
+		}
+	}
+
 	// Handle fitness config with nil checks
 	if configDTO.PriorityModel.Fitness != nil {
 		if configDTO.PriorityModel.Fitness.Explanation != nil {
 			config.PriorityModel.Fitness.Explanation = *configDTO.PriorityModel.Fitness.Explanation
 		}
 
+		for _, ruleDTO := range configDTO.PriorityModel.Fitness.ScheduleRules {
+			config.PriorityModel.Fitness.ScheduleRules = append(config.PriorityModel.Fitness.ScheduleRules, priority.ScheduleRule{
+				Name:          ruleDTO.Name,
+				Days:          ruleDTO.Days,
+				From:          ruleDTO.GetFrom(),
+				To:            ruleDTO.GetTo(),
+				Multipliers:   ruleDTO.Multipliers,
+				SymbolWeights: ruleDTO.SymbolWeights,
+			})
+		}
+
 		if configDTO.PriorityModel.Fitness.Contributions != nil {
 			contrib := configDTO.PriorityModel.Fitness.Contributions
 
@@ -800,3 +2080,50 @@ func (s *Service) convertDTOToPriorityConfig(configDTO *dto.PriorityConfig) *pri
 
 	return config
 }
+
+func convertPrioritySymbolsToDTO(symbols []priority.Symbol) []dto.PrioritySymbol {
+	symbolsDTO := make([]dto.PrioritySymbol, 0, len(symbols))
+	for _, symbol := range symbols {
+		symbolsDTO = append(symbolsDTO, *convertPrioritySymbolToDTO(symbol))
+	}
+	return symbolsDTO
+}
+
+func convertPrioritySymbolToDTO(symbol priority.Symbol) *dto.PrioritySymbol {
+	symbolDTO := &dto.PrioritySymbol{
+		Code:  symbol.Code,
+		Label: symbol.Label,
+	}
+	if symbol.Color != "" {
+		symbolDTO.Color = &symbol.Color
+	}
+	if symbol.Icon != "" {
+		symbolDTO.Icon = &symbol.Icon
+	}
+	return symbolDTO
+}
+
+func convertDTOToPrioritySymbol(symbolDTO dto.PrioritySymbol) priority.Symbol {
+	return priority.Symbol{
+		Code:  symbolDTO.Code,
+		Label: symbolDTO.Label,
+		Color: symbolDTO.GetColor(),
+		Icon:  symbolDTO.GetIcon(),
+	}
+}
+
+func (s *Service) convertCardReaderReleaseToDTO(release types.CardReaderRelease) *dto.CardReaderRelease {
+	result := &dto.CardReaderRelease{
+		Arch:      release.Arch,
+		Os:        release.OS,
+		Sha256:    release.SHA256,
+		Signature: release.Signature,
+		Url:       release.URL,
+		Version:   release.Version,
+	}
+	if !release.PublishedAt.IsZero() {
+		publishedAt := release.PublishedAt
+		result.PublishedAt = &publishedAt
+	}
+	return result
+}
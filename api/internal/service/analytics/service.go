@@ -0,0 +1,249 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// maxReportDays is the longest period a report can cover
+const maxReportDays = 366
+
+// defaultArchiveLimit and maxArchiveLimit bound the archived entries returned at once
+const (
+	defaultArchiveLimit = 100
+	maxArchiveLimit     = 1000
+)
+
+// Service aggregates finished queue entries into wait-time and throughput statistics
+type Service struct {
+	repo repository.QueueRepository
+}
+
+// New creates a new analytics service
+func New(repo repository.QueueRepository) *Service {
+	return &Service{
+		repo: repo,
+	}
+}
+
+// GetDailyStatistics returns the statistics of the entries created from the day from to the day to
+// (YYYY-MM-DD, both included; the last 7 days when omitted) per day, room and service point.
+// groupBy "room" merges the service points of a room. Days are counted in timezone (an IANA
+// name, the server's zone when omitted).
+func (s *Service) GetDailyStatistics(ctx context.Context, from *string, to *string, roomId *string, groupBy *string, timezone *string) ([]dto.DailyStatistics, error) {
+	firstDay, lastDay, loc, err := reportPeriod(from, to, timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	byRoom := false
+	if groupBy != nil {
+		switch *groupBy {
+		case "", "servicePoint":
+		case "room":
+			byRoom = true
+		default:
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("invalid groupBy '%s', expected room or servicePoint", *groupBy), 400, nil)
+		}
+	}
+
+	room := ""
+	if roomId != nil {
+		room = *roomId
+	}
+	stats, err := s.repo.GetEntryStats(ctx, firstDay, lastDay.AddDate(0, 0, 1), room, loc)
+	if err != nil {
+		log.Printf("[AnalyticsService] Failed to get entry stats from %s to %s: %v", firstDay.Format("2006-01-02"), lastDay.Format("2006-01-02"), err)
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to get statistics", 500, nil)
+	}
+	if byRoom {
+		stats = mergeServicePoints(stats)
+	}
+
+	result := make([]dto.DailyStatistics, 0, len(stats))
+	for _, group := range stats {
+		result = append(result, convertStatsToDTO(group))
+	}
+	return result, nil
+}
+
+// GetArchivedEntries returns the archived entries created from the day from to the day to, newest
+// first, at most limit of them (100 when omitted). Days are counted as in GetDailyStatistics.
+func (s *Service) GetArchivedEntries(ctx context.Context, from *string, to *string, roomId *string, limit *int32, timezone *string) ([]dto.ArchivedEntry, error) {
+	firstDay, lastDay, _, err := reportPeriod(from, to, timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	max := defaultArchiveLimit
+	if limit != nil {
+		if *limit < 1 || *limit > maxArchiveLimit {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("limit must be between 1 and %d", maxArchiveLimit), 400, nil)
+		}
+		max = int(*limit)
+	}
+
+	room := ""
+	if roomId != nil {
+		room = *roomId
+	}
+	entries, err := s.repo.GetArchivedEntries(ctx, firstDay, lastDay.AddDate(0, 0, 1), room, max)
+	if err != nil {
+		log.Printf("[AnalyticsService] Failed to get archived entries from %s to %s: %v", firstDay.Format("2006-01-02"), lastDay.Format("2006-01-02"), err)
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to get archived entries", 500, nil)
+	}
+
+	result := make([]dto.ArchivedEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, convertArchivedEntryToDTO(entry))
+	}
+	return result, nil
+}
+
+// reportPeriod parses the first and last day of a report (YYYY-MM-DD, both included; the last 7
+// days when omitted) in timezone (an IANA name, the server's zone when omitted)
+func reportPeriod(from *string, to *string, timezone *string) (time.Time, time.Time, *time.Location, error) {
+	loc := time.Local
+	if timezone != nil && *timezone != "" {
+		loaded, err := time.LoadLocation(*timezone)
+		if err != nil {
+			return time.Time{}, time.Time{}, nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("invalid timezone '%s'", *timezone), 400, nil)
+		}
+		loc = loaded
+	}
+
+	now := time.Now().In(loc)
+	lastDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if to != nil && *to != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", *to, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("invalid date '%s', expected YYYY-MM-DD", *to), 400, nil)
+		}
+		lastDay = parsed
+	}
+	firstDay := lastDay.AddDate(0, 0, -6)
+	if from != nil && *from != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", *from, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("invalid date '%s', expected YYYY-MM-DD", *from), 400, nil)
+		}
+		firstDay = parsed
+	}
+	if lastDay.Before(firstDay) {
+		return time.Time{}, time.Time{}, nil, ngErrors.New(ngErrors.ValidationErrorCode, "from must not be after to", 400, nil)
+	}
+	if lastDay.Sub(firstDay) >= maxReportDays*24*time.Hour {
+		return time.Time{}, time.Time{}, nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("a report can cover at most %d days", maxReportDays), 400, nil)
+	}
+	return firstDay, lastDay, loc, nil
+}
+
+// mergeServicePoints merges the groups of the service points of a room on a day, keeping their order
+func mergeServicePoints(stats []*types.EntryStats) []*types.EntryStats {
+	var merged []*types.EntryStats
+	index := make(map[string]*types.EntryStats)
+	for _, group := range stats {
+		key := group.Date + "|" + group.RoomID
+		target, exists := index[key]
+		if !exists {
+			target = &types.EntryStats{Date: group.Date, RoomID: group.RoomID}
+			index[key] = target
+			merged = append(merged, target)
+		}
+		target.Completed += group.Completed
+		target.NoShows += group.NoShows
+		target.WaitSeconds = append(target.WaitSeconds, group.WaitSeconds...)
+		target.ServiceSeconds = append(target.ServiceSeconds, group.ServiceSeconds...)
+	}
+	return merged
+}
+
+func convertStatsToDTO(group *types.EntryStats) dto.DailyStatistics {
+	result := dto.DailyStatistics{
+		AverageServiceSeconds: average(group.ServiceSeconds),
+		AverageWaitSeconds:    average(group.WaitSeconds),
+		Date:                  group.Date,
+		NoShows:               group.NoShows,
+		P95WaitSeconds:        percentile(group.WaitSeconds, 95),
+		RoomId:                group.RoomID,
+		Throughput:            group.Completed,
+	}
+	if finished := group.Completed + group.NoShows; finished > 0 {
+		result.NoShowRate = float64(group.NoShows) / float64(finished)
+	}
+	if group.ServicePointID != "" {
+		servicePointID := group.ServicePointID
+		result.ServicePointId = &servicePointID
+	}
+	return result
+}
+
+// convertArchivedEntryToDTO keeps the reporting fields of an archived entry, leaving out the card
+// data and staff annotations
+func convertArchivedEntryToDTO(entry *types.Entry) dto.ArchivedEntry {
+	tier := int32(entry.Tier)
+	result := dto.ArchivedEntry{
+		CalledAt:     entry.CalledAt,
+		CompletedAt:  entry.CompletedAt,
+		CreatedAt:    entry.CreatedAt,
+		Id:           entry.ID,
+		RoomId:       entry.WaitingRoomID,
+		Status:       entry.Status,
+		TicketNumber: entry.TicketNumber,
+		Tier:         &tier,
+	}
+	if entry.ArchivedAt != nil {
+		result.ArchivedAt = *entry.ArchivedAt
+	}
+	if entry.ServiceName != "" {
+		serviceName := entry.ServiceName
+		result.ServiceName = &serviceName
+	}
+	if entry.ServicePoint != "" {
+		servicePoint := entry.ServicePoint
+		result.ServicePointId = &servicePoint
+	}
+	if entry.CalledAt != nil {
+		wait := entry.CalledAt.Sub(entry.CreatedAt).Seconds()
+		result.WaitSeconds = &wait
+		if entry.CompletedAt != nil && entry.Status == "COMPLETED" {
+			service := entry.CompletedAt.Sub(*entry.CalledAt).Seconds()
+			result.ServiceSeconds = &service
+		}
+	}
+	return result
+}
+
+func average(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// percentile returns the nearest-rank percentile p of values
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,147 @@
+package announcement
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/arfis/waiting-room/internal/config"
+	queueService "github.com/arfis/waiting-room/internal/service/queue"
+)
+
+// DefaultTemplate is the announcement of languages without a template of their own
+const DefaultTemplate = "Ticket {ticket}, please proceed to {servicePoint}"
+
+// Announcement is the spoken announcement of a call in one language
+type Announcement struct {
+	ID           string    `json:"id"`
+	RoomID       string    `json:"roomId"`
+	TenantID     string    `json:"-"`
+	EntryID      string    `json:"entryId"`
+	TicketNumber string    `json:"ticketNumber"`
+	Language     string    `json:"language"`
+	Text         string    `json:"text"`
+	URL          string    `json:"url"` // path of the MP3 audio, /announcements/{id}.mp3
+	CreatedAt    time.Time `json:"createdAt"`
+	Audio        []byte    `json:"-"`
+}
+
+// Service generates an announcement for every call with the configured text-to-speech provider
+// and keeps the last ones for playback. Without a provider it does nothing.
+type Service struct {
+	synthesizer Synthesizer
+	languages   []string
+	templates   map[string]string
+	retention   int
+	onReady     func(announcement *Announcement)
+
+	mu            sync.RWMutex
+	announcements map[string]*Announcement
+	order         []string // IDs, oldest first
+}
+
+// New creates the announcement service of cfg
+func New(cfg *config.Config) *Service {
+	synthesizer, err := NewSynthesizer(cfg.Announcements)
+	if err != nil {
+		log.Printf("[Announcements] Announcements disabled: %v", err)
+	}
+	return &Service{
+		synthesizer:   synthesizer,
+		languages:     cfg.Announcements.Languages,
+		templates:     cfg.Announcements.Templates,
+		retention:     cfg.Announcements.Retention,
+		announcements: make(map[string]*Announcement),
+	}
+}
+
+// Enabled reports whether a text-to-speech provider is configured
+func (s *Service) Enabled() bool {
+	return s.synthesizer != nil
+}
+
+// SetReadyHandler sets the function told about every generated announcement, e.g. to play it on
+// the display boards of its room
+func (s *Service) SetReadyHandler(f func(announcement *Announcement)) {
+	s.onReady = f
+}
+
+// Announce generates the announcements of a call in the background, one per language
+func (s *Service) Announce(call queueService.CallEvent) {
+	if s.synthesizer == nil {
+		return
+	}
+	go func() {
+		for _, language := range s.languages {
+			announcement, err := s.generate(call, language)
+			if err != nil {
+				log.Printf("[Announcements] Failed to announce ticket %s in %s: %v", call.TicketNumber, language, err)
+				continue
+			}
+			if s.onReady != nil {
+				s.onReady(announcement)
+			}
+		}
+	}()
+}
+
+// Get returns a kept announcement
+func (s *Service) Get(id string) (*Announcement, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	announcement, ok := s.announcements[id]
+	return announcement, ok
+}
+
+// Text returns the announcement of a call in language
+func (s *Service) Text(call queueService.CallEvent, language string) string {
+	template, ok := s.templates[language]
+	if !ok || template == "" {
+		template = DefaultTemplate
+	}
+	servicePoint := call.ServicePointName
+	if servicePoint == "" {
+		servicePoint = call.ServicePointID
+	}
+	return strings.NewReplacer("{ticket}", call.TicketNumber, "{servicePoint}", servicePoint).Replace(template)
+}
+
+// generate synthesizes and keeps the announcement of a call in language
+func (s *Service) generate(call queueService.CallEvent, language string) (*Announcement, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	text := s.Text(call, language)
+	audio, err := s.synthesizer.Synthesize(ctx, text, language)
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.NewString()
+	announcement := &Announcement{
+		ID:           id,
+		RoomID:       call.RoomID,
+		TenantID:     call.TenantID,
+		EntryID:      call.EntryID,
+		TicketNumber: call.TicketNumber,
+		Language:     language,
+		Text:         text,
+		URL:          "/announcements/" + id + ".mp3",
+		CreatedAt:    time.Now(),
+		Audio:        audio,
+	}
+
+	s.mu.Lock()
+	s.announcements[id] = announcement
+	s.order = append(s.order, id)
+	for len(s.order) > s.retention {
+		delete(s.announcements, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.mu.Unlock()
+	return announcement, nil
+}
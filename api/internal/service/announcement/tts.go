@@ -0,0 +1,151 @@
+package announcement
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+)
+
+// Synthesizer turns the text of an announcement into MP3 audio
+type Synthesizer interface {
+	Synthesize(ctx context.Context, text, language string) ([]byte, error)
+}
+
+// maxAudioSize is the largest audio a provider may return
+const maxAudioSize = 5 << 20
+
+// NewSynthesizer returns the synthesizer of the configured provider, nil when announcements are disabled
+func NewSynthesizer(cfg config.AnnouncementsConfig) (Synthesizer, error) {
+	client := &http.Client{Timeout: 15 * time.Second}
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case "google":
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("the google announcement provider needs an api_key")
+		}
+		return &googleSynthesizer{apiKey: cfg.APIKey, voice: cfg.Voice, httpClient: client}, nil
+	case "http":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("the http announcement provider needs a url")
+		}
+		return &httpSynthesizer{url: cfg.URL, token: cfg.APIKey, voice: cfg.Voice, httpClient: client}, nil
+	}
+	return nil, fmt.Errorf("unknown announcement provider %q", cfg.Provider)
+}
+
+// googleSynthesizer uses the Google Cloud Text-to-Speech REST API
+type googleSynthesizer struct {
+	apiKey     string
+	voice      string
+	httpClient *http.Client
+}
+
+// googleLanguages are the BCP-47 codes Google expects for the short language codes of the UI
+var googleLanguages = map[string]string{
+	"en": "en-US",
+	"sk": "sk-SK",
+	"cs": "cs-CZ",
+	"de": "de-DE",
+	"hu": "hu-HU",
+	"pl": "pl-PL",
+	"uk": "uk-UA",
+}
+
+func (s *googleSynthesizer) Synthesize(ctx context.Context, text, language string) ([]byte, error) {
+	languageCode := language
+	if code, ok := googleLanguages[strings.ToLower(language)]; ok {
+		languageCode = code
+	}
+	voice := map[string]string{"languageCode": languageCode}
+	if s.voice != "" {
+		voice["name"] = s.voice
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"input":       map[string]string{"text": text},
+		"voice":       voice,
+		"audioConfig": map[string]string{"audioEncoding": "MP3"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := "https://texttospeech.googleapis.com/v1/text:synthesize?key=" + url.QueryEscape(s.apiKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("text-to-speech API returned status %d", resp.StatusCode)
+	}
+
+	var response struct {
+		AudioContent string `json:"audioContent"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxAudioSize*2)).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	audio, err := base64.StdEncoding.DecodeString(response.AudioContent)
+	if err != nil || len(audio) == 0 {
+		return nil, fmt.Errorf("text-to-speech API returned no audio")
+	}
+	return audio, nil
+}
+
+// httpSynthesizer posts the text to a self-hosted text-to-speech service
+type httpSynthesizer struct {
+	url        string
+	token      string
+	voice      string
+	httpClient *http.Client
+}
+
+func (s *httpSynthesizer) Synthesize(ctx context.Context, text, language string) ([]byte, error) {
+	body, err := json.Marshal(map[string]string{"text": text, "language": language, "voice": s.voice})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "audio/mpeg")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("text-to-speech service returned status %d", resp.StatusCode)
+	}
+
+	audio, err := io.ReadAll(io.LimitReader(resp.Body, maxAudioSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio: %w", err)
+	}
+	if len(audio) == 0 || len(audio) > maxAudioSize {
+		return nil, fmt.Errorf("text-to-speech service returned %d bytes of audio", len(audio))
+	}
+	return audio, nil
+}
@@ -0,0 +1,174 @@
+package appointment
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/data/dto"
+	"github.com/arfis/waiting-room/internal/data/dto/appointmentstatus"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// Service imports scheduled appointments and matches card swipes to them
+type Service struct {
+	repo repository.AppointmentRepository
+	cfg  *config.Config
+}
+
+// New creates a new appointment service
+func New(repo repository.AppointmentRepository, cfg *config.Config) *Service {
+	return &Service{
+		repo: repo,
+		cfg:  cfg,
+	}
+}
+
+// UpsertAppointments imports appointments of the tenant in the context, matched by their external ID
+func (s *Service) UpsertAppointments(ctx context.Context, req []dto.Appointment) ([]dto.Appointment, error) {
+	result := []dto.Appointment{}
+	for _, item := range req {
+		appointment := convertDTOToAppointment(item)
+		if err := s.repo.UpsertAppointment(ctx, appointment); err != nil {
+			log.Printf("[AppointmentService] Failed to upsert appointment %s: %v", item.ExternalId, err)
+			return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to store appointments", 500, nil)
+		}
+		result = append(result, ConvertAppointmentToDTO(appointment))
+	}
+
+	log.Printf("[AppointmentService] Imported %d appointments", len(result))
+	return result, nil
+}
+
+// GetAppointments returns the appointments of one day (YYYY-MM-DD, today when empty)
+func (s *Service) GetAppointments(ctx context.Context, date *string) ([]dto.Appointment, error) {
+	appointments, err := s.GetDayAppointments(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []dto.Appointment{}
+	for _, appointment := range appointments {
+		result = append(result, ConvertAppointmentToDTO(appointment))
+	}
+	return result, nil
+}
+
+// GetDayAppointments returns the stored appointments of one day (YYYY-MM-DD, today when empty)
+func (s *Service) GetDayAppointments(ctx context.Context, date *string) ([]*types.Appointment, error) {
+	day := time.Now()
+	if date != nil && *date != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", *date, time.Local)
+		if err != nil {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("invalid date '%s', expected YYYY-MM-DD", *date), 400, nil)
+		}
+		day = parsed
+	}
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+
+	appointments, err := s.repo.GetAppointments(ctx, from, from.AddDate(0, 0, 1))
+	if err != nil {
+		log.Printf("[AppointmentService] Failed to get appointments: %v", err)
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to get appointments", 500, nil)
+	}
+	return appointments, nil
+}
+
+// FindCheckInAppointment returns the scheduled appointment of the patient closest to now within
+// the check-in window, or nil
+func (s *Service) FindCheckInAppointment(ctx context.Context, identifier string, now time.Time) (*types.Appointment, error) {
+	if identifier == "" {
+		return nil, nil
+	}
+	early := time.Duration(s.cfg.Queue.AppointmentCheckInEarlyMinutes) * time.Minute
+	late := time.Duration(s.cfg.Queue.AppointmentCheckInLateMinutes) * time.Minute
+
+	// Appointments later than now + early are too far ahead, earlier than now - late are missed
+	appointments, err := s.repo.GetScheduledAppointments(ctx, identifier, now.Add(-late), now.Add(early))
+	if err != nil {
+		return nil, err
+	}
+
+	var closest *types.Appointment
+	for _, appointment := range appointments {
+		if closest == nil || absDuration(appointment.AppointmentTime.Sub(now)) < absDuration(closest.AppointmentTime.Sub(now)) {
+			closest = appointment
+		}
+	}
+	return closest, nil
+}
+
+// GetAppointment returns the appointment of the tenant in the context with an external ID, nil
+// when there is none
+func (s *Service) GetAppointment(ctx context.Context, externalID string) (*types.Appointment, error) {
+	return s.repo.GetAppointmentByExternalID(ctx, externalID)
+}
+
+// SaveAppointment stores a changed appointment, e.g. one cancelled or moved to another service by
+// the hospital system
+func (s *Service) SaveAppointment(ctx context.Context, appointment *types.Appointment) error {
+	return s.repo.UpsertAppointment(ctx, appointment)
+}
+
+// MarkCheckedIn links an appointment to the queue entry created for it
+func (s *Service) MarkCheckedIn(ctx context.Context, appointmentID, entryID string) error {
+	return s.repo.MarkAppointmentCheckedIn(ctx, appointmentID, entryID)
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func convertDTOToAppointment(item dto.Appointment) *types.Appointment {
+	return &types.Appointment{
+		ExternalID:             item.ExternalId,
+		Identifier:             item.Identifier,
+		PatientName:            item.GetPatientName(),
+		AppointmentTime:        item.AppointmentTime,
+		RoomID:                 item.GetRoomId(),
+		ServiceID:              item.GetServiceId(),
+		ServiceName:            item.GetServiceName(),
+		ServiceDurationSeconds: item.GetServiceDuration() * 60, // Convert minutes to seconds
+		Symbols:                item.Symbols,
+		Status:                 item.GetStatus().String(),
+	}
+}
+
+// ConvertAppointmentToDTO converts a stored appointment to its DTO
+func ConvertAppointmentToDTO(appointment *types.Appointment) dto.Appointment {
+	status := appointmentstatus.AppointmentStatus(appointment.Status)
+	item := dto.Appointment{
+		AppointmentTime: appointment.AppointmentTime,
+		ExternalId:      appointment.ExternalID,
+		Identifier:      appointment.Identifier,
+		Status:          &status,
+		Symbols:         appointment.Symbols,
+	}
+	if appointment.EntryID != "" {
+		item.EntryId = &appointment.EntryID
+	}
+	if appointment.PatientName != "" {
+		item.PatientName = &appointment.PatientName
+	}
+	if appointment.RoomID != "" {
+		item.RoomId = &appointment.RoomID
+	}
+	if appointment.ServiceID != "" {
+		item.ServiceId = &appointment.ServiceID
+	}
+	if appointment.ServiceName != "" {
+		item.ServiceName = &appointment.ServiceName
+	}
+	if appointment.ServiceDurationSeconds > 0 {
+		durationMinutes := appointment.ServiceDurationSeconds / 60 // Convert seconds to minutes for API
+		item.ServiceDuration = &durationMinutes
+	}
+	return item
+}
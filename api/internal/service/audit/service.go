@@ -0,0 +1,159 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	auditDiff "github.com/arfis/waiting-room/internal/audit"
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/service"
+	"github.com/arfis/waiting-room/internal/types"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// Service records administrative changes in the append-only audit log and returns them for review
+type Service struct {
+	repo repository.AuditLogRepository
+}
+
+func New(repo repository.AuditLogRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// Record appends the change of a resource in the tenant in ctx, made by the subject in ctx, with
+// the fields that differ between before and after; nil before records a creation, nil after a
+// deletion. Updates that change nothing are not recorded. Failures are only logged, the change
+// itself has already been made.
+func (s *Service) Record(ctx context.Context, resource, action, resourceID string, before, after any) {
+	changes, err := auditDiff.Diff(before, after)
+	if err != nil {
+		log.Printf("[AuditService] Failed to compute the changes of %s %s %s: %v", action, resource, resourceID, err)
+		return
+	}
+	if len(changes) == 0 && action == types.AuditActionUpdate {
+		return
+	}
+	s.append(ctx, resource, action, resourceID, changes)
+}
+
+// RecordChanges appends an action of a resource with changes that aren't a field diff, e.g. the
+// number of entries a bulk queue operation affected
+func (s *Service) RecordChanges(ctx context.Context, resource, action, resourceID string, changes []types.AuditChange) {
+	s.append(ctx, resource, action, resourceID, changes)
+}
+
+func (s *Service) append(ctx context.Context, resource, action, resourceID string, changes []types.AuditChange) {
+	buildingID, sectionID := service.GetTenantScope(ctx)
+	entry := &types.AuditEntry{
+		ID:         uuid.New().String(),
+		TenantID:   buildingID,
+		SectionID:  sectionID,
+		Actor:      service.GetUserID(ctx),
+		Resource:   resource,
+		ResourceID: resourceID,
+		Action:     action,
+		Changes:    changes,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.repo.AppendAuditEntry(context.WithoutCancel(ctx), entry); err != nil {
+		log.Printf("[AuditService] Failed to record %s %s %s by %s: %v", action, resource, resourceID, entry.Actor, err)
+	}
+}
+
+// GetAuditLog returns the entries of the tenant in ctx matching the filters, newest first, at most
+// limit of them (100 when omitted). from and to take a day (YYYY-MM-DD) in the server's zone or an
+// RFC 3339 time; a day to includes the whole day.
+func (s *Service) GetAuditLog(ctx context.Context, resource *string, action *string, actor *string, from *string, to *string, limit *int32) ([]dto.AuditEntry, error) {
+	filter := types.AuditFilter{
+		Resource: stringValue(resource),
+		Action:   stringValue(action),
+		Actor:    stringValue(actor),
+	}
+	var err error
+	if filter.From, err = parseTime(from, false); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseTime(to, true); err != nil {
+		return nil, err
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "from must be before to", 400, nil)
+	}
+
+	max := defaultAuditLimit
+	if limit != nil {
+		if *limit < 1 || *limit > maxAuditLimit {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("limit must be between 1 and %d", maxAuditLimit), 400, nil)
+		}
+		max = int(*limit)
+	}
+
+	entries, err := s.repo.GetAuditEntries(ctx, filter, max)
+	if err != nil {
+		log.Printf("[AuditService] Failed to get audit entries: %v", err)
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to get audit log", 500, nil)
+	}
+
+	result := make([]dto.AuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, convertAuditEntryToDTO(entry))
+	}
+	return result, nil
+}
+
+// parseTime parses a day or an RFC 3339 time; the day is its end, i.e. the next midnight, when end
+func parseTime(value *string, end bool) (time.Time, error) {
+	if value == nil || *value == "" {
+		return time.Time{}, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, *value); err == nil {
+		return parsed, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", *value, time.Local)
+	if err != nil {
+		return time.Time{}, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("invalid time '%s', expected YYYY-MM-DD or RFC 3339", *value), 400, nil)
+	}
+	if end {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
+
+func convertAuditEntryToDTO(entry *types.AuditEntry) dto.AuditEntry {
+	result := dto.AuditEntry{
+		Action:    entry.Action,
+		Actor:     entry.Actor,
+		CreatedAt: entry.CreatedAt,
+		Id:        entry.ID,
+		Resource:  entry.Resource,
+	}
+	if entry.ResourceID != "" {
+		result.ResourceId = &entry.ResourceID
+	}
+	if entry.TenantID != "" {
+		result.TenantId = &entry.TenantID
+	}
+	if entry.SectionID != "" {
+		result.SectionId = &entry.SectionID
+	}
+	for _, change := range entry.Changes {
+		result.Changes = append(result.Changes, dto.AuditChange{Field: change.Field, Before: change.Before, After: change.After})
+	}
+	return result
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,47 @@
+package cardreader
+
+import (
+	"context"
+
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// TakeCommands returns the commands to send to the authenticated card reader and marks them sent.
+// With resend, the ones sent before but never acknowledged are included, for a reader that just
+// connected again.
+func (s *Service) TakeCommands(ctx context.Context, resend bool) ([]types.CardReaderCommand, error) {
+	deviceID, err := commandDevice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.configService.TakeCardReaderCommands(ctx, deviceID, resend)
+}
+
+// AcknowledgeCommand records that the authenticated card reader received one of its commands
+func (s *Service) AcknowledgeCommand(ctx context.Context, id string) error {
+	deviceID, err := commandDevice(ctx)
+	if err != nil {
+		return err
+	}
+	return s.configService.AcknowledgeCardReaderCommand(ctx, deviceID, id)
+}
+
+// CompleteCommand records the result the authenticated card reader reported for one of its commands
+func (s *Service) CompleteCommand(ctx context.Context, id string, success bool, output, errorText string) error {
+	deviceID, err := commandDevice(ctx)
+	if err != nil {
+		return err
+	}
+	return s.configService.CompleteCardReaderCommand(ctx, deviceID, id, success, output, errorText)
+}
+
+// commandDevice returns the device of the card reader credential
+func commandDevice(ctx context.Context) (string, error) {
+	deviceID, _ := ctx.Value(middleware.CARD_READER_DEVICE).(string)
+	if deviceID == "" {
+		return "", ngErrors.CardReaderUnauthorized("missing device credential")
+	}
+	return deviceID, nil
+}
@@ -0,0 +1,270 @@
+package cardreader
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/cardreader"
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/service"
+	configService "github.com/arfis/waiting-room/internal/service/config"
+	kioskService "github.com/arfis/waiting-room/internal/service/kiosk"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// knownStates are the event states the card reader sends
+var knownStates = map[string]bool{
+	"waiting":        true,
+	"reading":        true,
+	"success":        true,
+	"error":          true,
+	"removed":        true,
+	"pin_required":   true,
+	"re_presented":   true,
+	"duplicate_read": true,
+}
+
+// tokenRetention is how long ingested tokens are remembered to drop resent events.
+// Spooled events are replayed well within this window.
+const tokenRetention = 30 * time.Minute
+
+// Service ingests card reader events, independent of the transport they arrive on
+type Service struct {
+	configService *configService.Service
+	kioskService  *kioskService.Service
+	eventHandler  func(ctx context.Context, event *dto.CardReaderEvent)
+	// tenants resolves the tenant events are routed to, like the TenantMiddleware does for requests
+	tenants middleware.TenantResolver
+
+	seenMux sync.Mutex
+	seen    map[string]time.Time
+	// seenPrunedAt is when expired tokens were last dropped from seen
+	seenPrunedAt time.Time
+}
+
+func New(configService *configService.Service, kioskService *kioskService.Service) *Service {
+	return &Service{
+		configService: configService,
+		kioskService:  kioskService,
+		seen:          make(map[string]time.Time),
+	}
+}
+
+// SetEventHandler sets the function accepted events are passed to
+func (s *Service) SetEventHandler(f func(ctx context.Context, event *dto.CardReaderEvent)) {
+	s.eventHandler = f
+}
+
+// SetTenantResolver makes the service resolve the tenant of events in the tenant registry
+func (s *Service) SetTenantResolver(tenants middleware.TenantResolver) {
+	s.tenants = tenants
+}
+
+// IngestCardReaderEvent validates an event from an authenticated card reader and passes it on.
+// An event whose token was already ingested is acknowledged without being processed again.
+func (s *Service) IngestCardReaderEvent(ctx context.Context, req *dto.CardReaderEvent) (*dto.CardReaderEventAck, error) {
+	deviceID, _ := ctx.Value(middleware.CARD_READER_DEVICE).(string)
+	if deviceID == "" || deviceID != req.DeviceId {
+		return nil, ngErrors.CardReaderUnauthorized("event deviceId does not match the credential")
+	}
+	if !knownStates[req.State] {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "unknown card reader event state: "+req.State, 400, nil)
+	}
+	if req.State == "success" && req.CardData == nil && req.EncryptedCardData == nil {
+		return nil, ngErrors.New(ngErrors.MissingRequiredFieldErrorCode, "success event without cardData or encryptedCardData", 400, nil)
+	}
+	// Route the event like a request carrying the X-Tenant-ID of the reader's tenant
+	eventCtx, err := s.eventContext(ctx, deviceID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.configService.UpdateCardReaderLastSeen(ctx, deviceID); err != nil {
+		log.Printf("[CardReaderService] Failed to update last seen for %s: %v", deviceID, err)
+	}
+
+	if req.EncryptedCardData != nil && req.CardData == nil {
+		cardData, err := s.openCardData(ctx, req)
+		if err != nil {
+			log.Printf("[CardReaderService] Failed to decrypt card data of event %s from %s: %v", req.Token, deviceID, err)
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, "card data could not be decrypted", 400, nil)
+		}
+		// Downstream consumers (kiosk, check-in) only ever see the plain card data
+		req.CardData, req.EncryptedCardData = cardData, nil
+	}
+
+	if s.alreadySeen(deviceID+"/"+req.Token, time.Now()) {
+		log.Printf("[CardReaderService] Duplicate event %s from %s ignored", req.Token, deviceID)
+		return &dto.CardReaderEventAck{Token: req.Token, Duplicate: true}, nil
+	}
+
+	log.Printf("[CardReaderService] Event %s from %s: %s", req.Token, deviceID, req.State)
+	ack := &dto.CardReaderEventAck{Token: req.Token, Duplicate: false}
+	if req.State == "success" {
+		s.autoCheckIn(ctx, eventCtx, req, ack)
+	}
+	if s.eventHandler != nil {
+		s.eventHandler(eventCtx, req)
+	}
+	return ack, nil
+}
+
+// autoCheckIn creates the queue entry for a card read on a reader in kiosk-less mode,
+// in the reader's configured room with its default service. A failed check-in is logged; the read
+// itself stays accepted.
+func (s *Service) autoCheckIn(ctx, eventCtx context.Context, req *dto.CardReaderEvent, ack *dto.CardReaderEventAck) {
+	readerConfig, err := s.configService.GetCardReaderConfig(ctx, req.DeviceId)
+	if err != nil {
+		log.Printf("[CardReaderService] Failed to load config of %s for auto check-in: %v", req.DeviceId, err)
+		return
+	}
+	if readerConfig == nil || !readerConfig.AutoCheckIn {
+		return
+	}
+	// The entry goes to the room the reader is configured for, never to one named by the event
+	roomID := readerConfig.RoomID
+	if roomID == "" {
+		log.Printf("[CardReaderService] Auto check-in skipped for event %s: reader %s has no room configured", req.Token, req.DeviceId)
+		return
+	}
+	if req.RoomId != "" && req.RoomId != roomID {
+		log.Printf("[CardReaderService] Auto check-in skipped for event %s: room %s does not match room %s of reader %s", req.Token, req.RoomId, roomID, req.DeviceId)
+		return
+	}
+
+	cardData := req.GetCardData()
+	identifier := cardData.GetIdNumber()
+	if identifier == "" {
+		identifier = cardData.GetInsuranceNumber()
+	}
+	if identifier == "" {
+		log.Printf("[CardReaderService] Auto check-in skipped for event %s: card has no identifier", req.Token)
+		return
+	}
+
+	swipe := &dto.SwipeRequest{IdCardRaw: &identifier}
+	if readerConfig.AutoCheckInServiceID != "" {
+		swipe.ServiceId = &readerConfig.AutoCheckInServiceID
+	}
+	if readerConfig.AutoCheckInDuration > 0 {
+		duration := int64(readerConfig.AutoCheckInDuration)
+		swipe.ServiceDuration = &duration
+	}
+	result, err := s.kioskService.SwipeCard(eventCtx, roomID, swipe)
+	if err != nil {
+		log.Printf("[CardReaderService] Auto check-in for event %s in room %s failed: %v", req.Token, roomID, err)
+		return
+	}
+	log.Printf("[CardReaderService] Auto check-in for event %s: ticket %s in room %s", req.Token, result.TicketNumber, roomID)
+	ack.EntryId = &result.EntryID
+	ack.TicketNumber = &result.TicketNumber
+}
+
+// openCardData decrypts the event's encryptedCardData with the key provisioned for the device
+func (s *Service) openCardData(ctx context.Context, req *dto.CardReaderEvent) (*dto.CardReaderCardData, error) {
+	sealed := req.GetEncryptedCardData()
+	plain, err := s.configService.DecryptCardData(ctx, req.DeviceId, req.Token, &cardreader.EncryptedCardData{
+		Alg:        sealed.Alg,
+		KeyID:      sealed.Kid,
+		Nonce:      sealed.Nonce,
+		Ciphertext: sealed.Ciphertext,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var cardData dto.CardReaderCardData
+	if err := json.Unmarshal(plain, &cardData); err != nil {
+		return nil, err
+	}
+	return &cardData, nil
+}
+
+// eventContext returns ctx with the tenant the device is registered to, resolved in the tenant
+// registry and stored like the TenantMiddleware stores the tenant of X-Tenant-ID. The tenantId and
+// sectionId of the event, and the X-Tenant-ID the reader sent, may only name that tenant again.
+func (s *Service) eventContext(ctx context.Context, deviceID string, event *dto.CardReaderEvent) (context.Context, error) {
+	tenantID, err := eventTenantID(event)
+	if err != nil {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, err.Error(), 400, nil)
+	}
+	requestTenantID := service.GetTenantID(ctx)
+
+	// The registration is looked up whatever tenant the reader sent
+	readerConfig, err := s.configService.GetCardReaderConfig(context.WithValue(ctx, middleware.TENANT, ""), deviceID)
+	if err != nil {
+		return nil, err
+	}
+	registeredTenantID := ""
+	if readerConfig != nil {
+		registeredTenantID = readerConfig.TenantID
+	}
+	for _, claimed := range []string{tenantID, requestTenantID} {
+		if claimed != "" && claimed != registeredTenantID {
+			log.Printf("[CardReaderService] Rejected event of %s for tenant %s, the reader is registered to '%s'", deviceID, claimed, registeredTenantID)
+			return nil, ngErrors.CardReaderOtherTenant(deviceID)
+		}
+	}
+	if registeredTenantID == "" {
+		return ctx, nil
+	}
+
+	ctx = context.WithValue(ctx, middleware.TENANT, registeredTenantID)
+	if s.tenants == nil {
+		return ctx, nil
+	}
+	tenant, err := s.tenants.ResolveTenant(ctx, registeredTenantID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		return nil, ngErrors.UnknownTenant(registeredTenantID)
+	}
+	return context.WithValue(ctx, middleware.RESOLVED_TENANT, tenant), nil
+}
+
+// eventTenantID returns the tenant the event names in the "buildingId:sectionId" form of
+// X-Tenant-ID, empty when it names none
+func eventTenantID(event *dto.CardReaderEvent) (string, error) {
+	tenantID := strings.TrimSpace(event.GetTenantId())
+	if tenantID == "" {
+		return "", nil
+	}
+	buildingID, sectionID, err := types.ParseTenantID(tenantID)
+	if err != nil {
+		return "", err
+	}
+	if section := strings.TrimSpace(event.GetSectionId()); section != "" {
+		sectionID = section
+	}
+	if sectionID != "" {
+		return buildingID + ":" + sectionID, nil
+	}
+	return buildingID, nil
+}
+
+// alreadySeen records key and reports whether it was recorded before within tokenRetention.
+// Expired tokens are dropped once per tokenRetention, not on every event.
+func (s *Service) alreadySeen(key string, now time.Time) bool {
+	s.seenMux.Lock()
+	defer s.seenMux.Unlock()
+
+	if now.Sub(s.seenPrunedAt) > tokenRetention {
+		for k, at := range s.seen {
+			if now.Sub(at) > tokenRetention {
+				delete(s.seen, k)
+			}
+		}
+		s.seenPrunedAt = now
+	}
+	if at, ok := s.seen[key]; ok && now.Sub(at) <= tokenRetention {
+		return true
+	}
+	s.seen[key] = now
+	return false
+}
@@ -0,0 +1,146 @@
+package cardreader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/service"
+	"github.com/arfis/waiting-room/internal/service/audit"
+	configService "github.com/arfis/waiting-room/internal/service/config"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// registryResolver resolves the tenants of a fixed registry
+type registryResolver map[string]*types.Tenant
+
+func (r registryResolver) ResolveTenant(_ context.Context, tenantID string) (*types.Tenant, error) {
+	return r[tenantID], nil
+}
+
+// TestIngestRoutesEventsToTheRegisteredTenant tests that events only reach the tenant their
+// reader is registered to
+func TestIngestRoutesEventsToTheRegisteredTenant(t *testing.T) {
+	configRepo := repository.NewEmbeddedConfigRepository()
+	registered := context.WithValue(context.Background(), middleware.TENANT, "b1:s1")
+	if err := configRepo.SetCardReaderConfig(registered, &types.CardReaderConfig{DeviceID: "reader-1", RoomID: "triage-1"}); err != nil {
+		t.Fatalf("SetCardReaderConfig failed: %v", err)
+	}
+	if err := configRepo.SetCardReaderConfig(context.Background(), &types.CardReaderConfig{DeviceID: "reader-2", RoomID: "triage-1", TenantID: "b1:s2"}); err != nil {
+		t.Fatalf("SetCardReaderConfig failed: %v", err)
+	}
+
+	svc := New(configService.NewService(configRepo, audit.New(repository.NewMockAuditLogRepository())), nil)
+	svc.SetTenantResolver(registryResolver{
+		"b1:s1": {BuildingID: "b1", SectionID: "s1"},
+		"b1:s2": {BuildingID: "b1", SectionID: "s2"},
+	})
+	var routedTo []string
+	var resolved []*types.Tenant
+	svc.SetEventHandler(func(ctx context.Context, event *dto.CardReaderEvent) {
+		routedTo = append(routedTo, service.GetTenantID(ctx))
+		resolved = append(resolved, service.GetTenant(ctx))
+	})
+
+	deviceCtx := func(deviceID, requestTenant string) context.Context {
+		ctx := context.WithValue(context.Background(), middleware.CARD_READER_DEVICE, deviceID)
+		if requestTenant != "" {
+			ctx = context.WithValue(ctx, middleware.TENANT, requestTenant)
+		}
+		return ctx
+	}
+	event := func(token, tenantID, sectionID string) *dto.CardReaderEvent {
+		e := &dto.CardReaderEvent{DeviceId: "reader-1", RoomId: "triage-1", State: "waiting", Token: token}
+		if tenantID != "" {
+			e.TenantId = &tenantID
+		}
+		if sectionID != "" {
+			e.SectionId = &sectionID
+		}
+		return e
+	}
+
+	rejected := []struct {
+		name          string
+		requestTenant string
+		event         *dto.CardReaderEvent
+	}{
+		{"tenantId of another section", "", event("t1", "b1:s2", "")},
+		{"sectionId of another section", "", event("t2", "b1", "s2")},
+		{"tenantId of another building", "", event("t3", "b2", "")},
+		{"X-Tenant-ID of another section", "b1:s2", event("t4", "", "")},
+	}
+	for _, tc := range rejected {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := svc.IngestCardReaderEvent(deviceCtx("reader-1", tc.requestTenant), tc.event)
+			var appErr *ngErrors.ApplicationError
+			if !errors.As(err, &appErr) || appErr.Code != ngErrors.CardReaderOtherTenantCode {
+				t.Errorf("Expected %s, got %v", ngErrors.CardReaderOtherTenantCode, err)
+			}
+		})
+	}
+	if len(routedTo) != 0 {
+		t.Fatalf("Expected no rejected event to be passed on, got %v", routedTo)
+	}
+
+	for _, e := range []*dto.CardReaderEvent{event("t5", "", ""), event("t6", "b1:s1", ""), event("t7", "b1", "s1")} {
+		if _, err := svc.IngestCardReaderEvent(deviceCtx("reader-1", ""), e); err != nil {
+			t.Fatalf("Expected event %s of the registered tenant to be accepted, got %v", e.Token, err)
+		}
+	}
+	for i, tenantID := range routedTo {
+		if tenantID != "b1:s1" || resolved[i] == nil || resolved[i].GetFullTenantID() != "b1:s1" {
+			t.Errorf("Expected event %d routed to the resolved tenant b1:s1, got %q (%v)", i, tenantID, resolved[i])
+		}
+	}
+
+	// The tenant of the reader must be registered
+	svc.SetTenantResolver(registryResolver{})
+	_, err := svc.IngestCardReaderEvent(deviceCtx("reader-2", ""), &dto.CardReaderEvent{DeviceId: "reader-2", RoomId: "triage-1", State: "waiting", Token: "t8"})
+	var appErr *ngErrors.ApplicationError
+	if !errors.As(err, &appErr) || appErr.Code != ngErrors.UnknownTenantCode {
+		t.Errorf("Expected %s for a reader of an unregistered tenant, got %v", ngErrors.UnknownTenantCode, err)
+	}
+}
+
+// TestAlreadySeen tests that tokens are remembered for tokenRetention, whether or not they were pruned yet
+func TestAlreadySeen(t *testing.T) {
+	start := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		key   string
+		after time.Duration
+		want  bool
+	}{
+		{"same token within the retention", "reader-1/t1", tokenRetention, true},
+		{"same token after the retention", "reader-1/t1", tokenRetention + time.Second, false},
+		{"token of another reader", "reader-2/t1", time.Second, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := New(nil, nil)
+			// The first event prunes, so the ones within the retention don't
+			svc.alreadySeen("reader-1/t0", start)
+			if svc.alreadySeen("reader-1/t1", start.Add(time.Second)) {
+				t.Fatalf("Expected the first event of a token not to be seen")
+			}
+			if got := svc.alreadySeen(tt.key, start.Add(time.Second+tt.after)); got != tt.want {
+				t.Errorf("Expected seen %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	// Expired tokens are dropped once the retention has passed since the last prune
+	svc := New(nil, nil)
+	svc.alreadySeen("reader-1/t1", start)
+	svc.alreadySeen("reader-1/t2", start.Add(2*tokenRetention))
+	if _, ok := svc.seen["reader-1/t1"]; ok || len(svc.seen) != 1 {
+		t.Errorf("Expected the expired token to be pruned, got %v", svc.seen)
+	}
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/service"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// cardReaderCommandExpiry is how long a command waits for its reader to connect and acknowledge it
+const cardReaderCommandExpiry = 15 * time.Minute
+
+// maxCardReaderCommandOutput bounds the output a reader reports, pulled logs are cut to their end
+const maxCardReaderCommandOutput = 64 << 10
+
+// OnCardReaderCommand registers f to be called with the device a command was enqueued for
+func (s *Service) OnCardReaderCommand(f func(deviceID string)) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	s.commandListeners = append(s.commandListeners, f)
+}
+
+// EnqueueCardReaderCommand stores a command for a registered card reader of the tenant. It is sent
+// when the reader is connected to this or another replica, or when it connects next.
+func (s *Service) EnqueueCardReaderCommand(ctx context.Context, deviceID, command string) (*types.CardReaderCommand, error) {
+	if !slices.Contains(types.CardReaderCommands, command) {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("unknown card reader command %s, expected one of %v", command, types.CardReaderCommands), 400, nil)
+	}
+	status, err := s.repo.GetCardReaderStatus(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	readerConfig, err := s.repo.GetCardReaderConfig(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if status == nil && readerConfig == nil {
+		return nil, ngErrors.EntityNotFound()
+	}
+
+	cmd := &types.CardReaderCommand{
+		ID:          uuid.NewString(),
+		DeviceID:    deviceID,
+		Command:     command,
+		Status:      types.CardReaderCommandPending,
+		RequestedBy: service.GetUserID(ctx),
+		CreatedAt:   time.Now(),
+	}
+	if err := s.repo.SaveCardReaderCommand(ctx, cmd); err != nil {
+		return nil, err
+	}
+	log.Printf("[ConfigService] Enqueued command %s (%s) for card reader %s", cmd.ID, command, deviceID)
+
+	s.listenersMu.Lock()
+	listeners := s.commandListeners
+	s.listenersMu.Unlock()
+	for _, f := range listeners {
+		f(deviceID)
+	}
+	return cmd, nil
+}
+
+// GetCardReaderCommands returns the newest commands of a card reader, at most limit of them
+func (s *Service) GetCardReaderCommands(ctx context.Context, deviceID string, limit int) ([]types.CardReaderCommand, error) {
+	commands, err := s.repo.GetCardReaderCommands(ctx, deviceID, nil, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range commands {
+		s.expireCardReaderCommand(ctx, &commands[i], time.Now())
+	}
+	return commands, nil
+}
+
+// TakeCardReaderCommands returns the pending commands of a card reader, oldest first, and marks
+// them sent. With resend, the commands sent but never acknowledged are returned again, for a
+// reader that lost its connection before it got them; readers ignore commands they already ran.
+func (s *Service) TakeCardReaderCommands(ctx context.Context, deviceID string, resend bool) ([]types.CardReaderCommand, error) {
+	statuses := []string{types.CardReaderCommandPending}
+	if resend {
+		statuses = append(statuses, types.CardReaderCommandSent)
+	}
+	commands, err := s.repo.GetCardReaderCommands(ctx, deviceID, statuses, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	taken := make([]types.CardReaderCommand, 0, len(commands))
+	for i := len(commands) - 1; i >= 0; i-- {
+		cmd := commands[i]
+		if s.expireCardReaderCommand(ctx, &cmd, now) {
+			continue
+		}
+		cmd.Status = types.CardReaderCommandSent
+		cmd.SentAt = &now
+		if err := s.repo.SaveCardReaderCommand(ctx, &cmd); err != nil {
+			return nil, err
+		}
+		taken = append(taken, cmd)
+	}
+	return taken, nil
+}
+
+// AcknowledgeCardReaderCommand records that the card reader received a command
+func (s *Service) AcknowledgeCardReaderCommand(ctx context.Context, deviceID, id string) error {
+	cmd, err := s.deviceCommand(ctx, deviceID, id)
+	if err != nil || cmd.IsDone() || cmd.AcknowledgedAt != nil {
+		return err
+	}
+	now := time.Now()
+	cmd.Status = types.CardReaderCommandAcknowledged
+	cmd.AcknowledgedAt = &now
+	return s.repo.SaveCardReaderCommand(ctx, cmd)
+}
+
+// CompleteCardReaderCommand records the result a card reader reported for a command
+func (s *Service) CompleteCardReaderCommand(ctx context.Context, deviceID, id string, success bool, output, errorText string) error {
+	cmd, err := s.deviceCommand(ctx, deviceID, id)
+	if err != nil {
+		return err
+	}
+	if cmd.Status == types.CardReaderCommandSucceeded || cmd.Status == types.CardReaderCommandFailed {
+		return nil
+	}
+	now := time.Now()
+	if cmd.AcknowledgedAt == nil {
+		cmd.AcknowledgedAt = &now
+	}
+	cmd.Status = types.CardReaderCommandFailed
+	if success {
+		cmd.Status = types.CardReaderCommandSucceeded
+	}
+	if len(output) > maxCardReaderCommandOutput {
+		output = output[len(output)-maxCardReaderCommandOutput:]
+	}
+	cmd.Output, cmd.Error, cmd.CompletedAt = output, errorText, &now
+	if err := s.repo.SaveCardReaderCommand(ctx, cmd); err != nil {
+		return err
+	}
+	log.Printf("[ConfigService] Card reader %s finished command %s (%s): %s", deviceID, id, cmd.Command, cmd.Status)
+	return nil
+}
+
+// deviceCommand returns a command of the card reader or a not found error
+func (s *Service) deviceCommand(ctx context.Context, deviceID, id string) (*types.CardReaderCommand, error) {
+	cmd, err := s.repo.GetCardReaderCommand(ctx, deviceID, id)
+	if err != nil {
+		return nil, err
+	}
+	if cmd == nil {
+		return nil, ngErrors.EntityNotFound()
+	}
+	return cmd, nil
+}
+
+// expireCardReaderCommand marks a command that was not acknowledged in time expired and reports
+// whether it did
+func (s *Service) expireCardReaderCommand(ctx context.Context, cmd *types.CardReaderCommand, now time.Time) bool {
+	if cmd.Status != types.CardReaderCommandPending && cmd.Status != types.CardReaderCommandSent {
+		return false
+	}
+	if now.Sub(cmd.CreatedAt) < cardReaderCommandExpiry {
+		return false
+	}
+	cmd.Status = types.CardReaderCommandExpired
+	cmd.CompletedAt = &now
+	if err := s.repo.SaveCardReaderCommand(ctx, cmd); err != nil {
+		log.Printf("[ConfigService] Failed to expire command %s of card reader %s: %v", cmd.ID, cmd.DeviceID, err)
+	}
+	return true
+}
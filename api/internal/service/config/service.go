@@ -2,25 +2,83 @@ package config
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"strconv"
-
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/cardreader"
+	"github.com/arfis/waiting-room/internal/configlayers"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/middleware"
 	"github.com/arfis/waiting-room/internal/repository"
 	"github.com/arfis/waiting-room/internal/service"
+	"github.com/arfis/waiting-room/internal/service/audit"
 	"github.com/arfis/waiting-room/internal/types"
 )
 
 type Service struct {
 	repo  repository.ConfigRepository
 	cache *ConfigCache
+	audit *audit.Service
+
+	listenersMu          sync.Mutex
+	externalAPIListeners []func(tenantID string)
+	configListeners      []func(tenantID string)
+	commandListeners     []func(deviceID string)
+
+	// versions are the last known versions of the stored configurations by layer, see watch.go
+	versions   map[string]string
+	versionsMu sync.Mutex
+
+	// apiKeysMu serializes the changes of the API keys of a tenant, which are stored as one list
+	apiKeysMu sync.Mutex
+
+	// apiKeyUses are the uses this replica recorded last, by key ID. The recorded use never
+	// reaches the cached key list, so that alone would record every request.
+	apiKeyUses   map[string]apiKeyUse
+	apiKeyUsesMu sync.Mutex
 }
 
-func NewService(repo repository.ConfigRepository) *Service {
+// apiKeyUse is when and from where an API key was used
+type apiKeyUse struct {
+	at         time.Time
+	remoteAddr string
+}
+
+func NewService(repo repository.ConfigRepository, auditService *audit.Service) *Service {
 	return &Service{
-		repo:  repo,
-		cache: NewConfigCache(repo),
+		repo:       repo,
+		cache:      NewConfigCache(repo),
+		audit:      auditService,
+		versions:   make(map[string]string),
+		apiKeyUses: make(map[string]apiKeyUse),
+	}
+}
+
+// OnExternalAPIConfigChange registers f to be called with the tenant whose external API
+// configuration was changed through this service
+func (s *Service) OnExternalAPIConfigChange(f func(tenantID string)) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	s.externalAPIListeners = append(s.externalAPIListeners, f)
+}
+
+func (s *Service) externalAPIConfigChanged(ctx context.Context) {
+	s.listenersMu.Lock()
+	listeners := s.externalAPIListeners
+	s.listenersMu.Unlock()
+	tenantID := service.GetTenantID(ctx)
+	for _, f := range listeners {
+		f(tenantID)
 	}
 }
 
@@ -31,26 +89,13 @@ func (s *Service) Stop() {
 	}
 }
 
-// GetSystemConfiguration gets the complete system configuration from cache
+// GetSystemConfiguration gets the effective system configuration of the tenant in the context,
+// resolved from the global, tenant and section layers; the global one from cache without a tenant
 func (s *Service) GetSystemConfiguration(ctx context.Context) (*types.SystemConfiguration, error) {
-	// Check if tenant ID is in context - if so, bypass cache and query repository directly
-	// This ensures tenant-specific configurations are always fresh
-	tenantID := service.GetTenantID(ctx)
-	if tenantID != "" {
-		// Bypass cache for tenant-specific requests
-		log.Printf("[ConfigService] Tenant-specific config requested for: %s, querying repository directly (bypassing cache)", tenantID)
-		config, err := s.repo.GetSystemConfiguration(ctx)
-		if err != nil {
-			log.Printf("[ConfigService] Error querying repository for tenant %s: %v", tenantID, err)
-			return nil, err
-		}
-		if config != nil {
-			log.Printf("[ConfigService] Found configuration for tenant %s - config ID: %s, config tenantId: %s, config sectionId: %s", tenantID, config.ID, config.TenantID, config.SectionID)
-			return config, nil
-		}
-		// No tenant-specific config found, return nil (do NOT fall back to default config)
-		log.Printf("[ConfigService] No configuration found for tenant: %s - returning nil (NOT falling back to default)", tenantID)
-		return nil, nil
+	// Tenant configurations bypass the cache so they are always fresh
+	if service.GetTenantID(ctx) != "" {
+		config, _, err := s.GetResolvedConfiguration(ctx)
+		return config, err
 	}
 
 	// For non-tenant requests, use cache (legacy/system configs)
@@ -71,50 +116,138 @@ func (s *Service) GetSystemConfiguration(ctx context.Context) (*types.SystemConf
 	return envConfig, nil
 }
 
+// GetStoredConfiguration gets the configuration stored at the layer of the tenant in the context,
+// without the values it inherits; nil when the layer has none
+func (s *Service) GetStoredConfiguration(ctx context.Context) (*types.SystemConfiguration, error) {
+	if service.GetTenantID(ctx) == "" {
+		return s.GetSystemConfiguration(ctx)
+	}
+	return s.repo.GetSystemConfiguration(ctx)
+}
+
+// GetResolvedConfiguration gets the effective configuration of the tenant in the context and the
+// layer each of its values comes from, keyed by the dotted JSON path of the value
+func (s *Service) GetResolvedConfiguration(ctx context.Context) (*types.SystemConfiguration, map[string]string, error) {
+	layers, err := s.configurationLayers(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	config, sources := configlayers.Resolve(layers)
+	return config, sources, nil
+}
+
+// configurationLayers reads the configurations the tenant in the context inherits from, from the
+// global one to the one of its section
+func (s *Service) configurationLayers(ctx context.Context) ([]configlayers.Layer, error) {
+	global, err := s.GetSystemConfiguration(context.WithValue(ctx, middleware.TENANT, ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get global configuration: %w", err)
+	}
+	layers := []configlayers.Layer{{Name: configlayers.LayerGlobal, Config: global}}
+
+	buildingID, sectionID := service.GetTenantScope(ctx)
+	if buildingID == "" {
+		return layers, nil
+	}
+	tenant, err := s.repo.GetSystemConfiguration(context.WithValue(ctx, middleware.TENANT, buildingID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configuration of tenant '%s': %w", buildingID, err)
+	}
+	layers = append(layers, configlayers.Layer{Name: configlayers.LayerTenant, Config: tenant})
+
+	if sectionID == "" {
+		return layers, nil
+	}
+	section, err := s.repo.GetSystemConfiguration(context.WithValue(ctx, middleware.TENANT, buildingID+":"+sectionID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configuration of section '%s:%s': %w", buildingID, sectionID, err)
+	}
+	return append(layers, configlayers.Layer{Name: configlayers.LayerSection, Config: section}), nil
+}
+
+// effectiveConfiguration gets the effective configuration of the tenant in the context, the stored
+// global one without a tenant
+func (s *Service) effectiveConfiguration(ctx context.Context) (*types.SystemConfiguration, error) {
+	if service.GetTenantID(ctx) == "" {
+		return s.repo.GetSystemConfiguration(ctx)
+	}
+	config, _, err := s.GetResolvedConfiguration(ctx)
+	return config, err
+}
+
 // SetSystemConfiguration sets the complete system configuration in MongoDB and cache
 func (s *Service) SetSystemConfiguration(ctx context.Context, config *types.SystemConfiguration) error {
+	before := s.configurationBeforeChange(ctx)
 	err := s.repo.SetSystemConfiguration(ctx, config)
 	if err != nil {
 		return err
 	}
+	s.recordConfigurationChange(ctx, before)
 
-	// Update cache immediately
-	s.cache.ReloadConfig(ctx)
+	// Update cache immediately; tenant configurations aren't cached
+	if service.GetTenantID(ctx) == "" {
+		s.cache.ReloadConfig(ctx)
+	}
+	s.externalAPIConfigChanged(ctx)
 	return nil
 }
 
 // UpdateSystemConfiguration updates specific fields in the system configuration
 func (s *Service) UpdateSystemConfiguration(ctx context.Context, updates map[string]interface{}) error {
+	before := s.configurationBeforeChange(ctx)
 	err := s.repo.UpdateSystemConfiguration(ctx, updates)
 	if err != nil {
 		return err
 	}
+	s.recordConfigurationChange(ctx, before)
 
-	// Update cache immediately
-	s.cache.ReloadConfig(ctx)
+	// Update cache immediately; tenant configurations aren't cached
+	if service.GetTenantID(ctx) == "" {
+		s.cache.ReloadConfig(ctx)
+	}
+	s.externalAPIConfigChanged(ctx)
 	return nil
 }
 
-// GetExternalAPIConfig gets external API configuration from cache
+// configurationBeforeChange reads the configuration of the tenant in the context to record a
+// change of it; nil when it doesn't exist yet or can't be read
+func (s *Service) configurationBeforeChange(ctx context.Context) *types.SystemConfiguration {
+	config, err := s.repo.GetSystemConfiguration(ctx)
+	if err != nil {
+		log.Printf("[ConfigService] Failed to read configuration before change for the audit log: %v", err)
+		return nil
+	}
+	return config
+}
+
+// recordConfigurationChange records the fields a change of the configuration of the tenant in the
+// context changed and notifies the listeners of configuration changes. API keys and integration
+// credentials aren't serialized and are recorded by the operations changing them.
+func (s *Service) recordConfigurationChange(ctx context.Context, before *types.SystemConfiguration) {
+	after, err := s.repo.GetSystemConfiguration(ctx)
+	if err != nil {
+		log.Printf("[ConfigService] Failed to read configuration after change for the audit log: %v", err)
+		return
+	}
+	action := types.AuditActionUpdate
+	if before == nil {
+		action = types.AuditActionCreate
+	}
+	s.audit.Record(ctx, types.AuditResourceSystemConfiguration, action, "", before, after)
+	if after != nil {
+		s.configurationChanged(after)
+	}
+}
+
+// GetExternalAPIConfig gets the effective external API configuration of the tenant in the
+// context, from cache without a tenant
 func (s *Service) GetExternalAPIConfig(ctx context.Context) (*types.ExternalAPIConfig, error) {
-	// Check if tenant ID is in context - if so, bypass cache and query repository directly
-	tenantID := service.GetTenantID(ctx)
-	log.Printf("[ConfigService] GetExternalAPIConfig called - tenantID from context: '%s' (length: %d)", tenantID, len(tenantID))
-	if tenantID != "" {
-		// Bypass cache for tenant-specific requests
-		log.Printf("[ConfigService] Tenant-specific external API config requested for: '%s', querying repository directly", tenantID)
-		systemConfig, err := s.repo.GetSystemConfiguration(ctx)
-		if err != nil {
-			log.Printf("[ConfigService] Error querying repository for tenant '%s': %v", tenantID, err)
+	if service.GetTenantID(ctx) != "" {
+		systemConfig, err := s.effectiveConfiguration(ctx)
+		if err != nil || systemConfig == nil {
 			return nil, err
 		}
-		if systemConfig != nil {
-			log.Printf("[ConfigService] Found external API config for tenant '%s' - config ID: %s, config tenantId: %s, config sectionId: %s", tenantID, systemConfig.ID, systemConfig.TenantID, systemConfig.SectionID)
-			return &systemConfig.ExternalAPI, nil
-		}
-		// No tenant-specific config found, return nil (do NOT fall back to default)
-		log.Printf("[ConfigService] No external API config found for tenant '%s' - returning nil (NOT falling back to default)", tenantID)
-		return nil, nil
+		return &systemConfig.ExternalAPI, nil
 	}
 
 	// For non-tenant requests, use cache (legacy/system configs)
@@ -130,30 +263,38 @@ func (s *Service) GetExternalAPIConfig(ctx context.Context) (*types.ExternalAPIC
 	return s.getExternalAPIConfigFromEnv(), nil
 }
 
-// SetExternalAPIConfig updates external API configuration
+// SetExternalAPIConfig updates external API configuration. A tenant stores only it, leaving the
+// other values to be inherited.
 func (s *Service) SetExternalAPIConfig(ctx context.Context, apiConfig *types.ExternalAPIConfig) error {
 	if apiConfig == nil {
 		return fmt.Errorf("apiConfig cannot be nil")
 	}
 	log.Printf("Updating external API config - Timeout: %d", apiConfig.TimeoutSeconds)
-	return s.cache.UpdateExternalAPIConfiguration(ctx, apiConfig)
+	before := s.configurationBeforeChange(ctx)
+	var err error
+	if service.GetTenantID(ctx) != "" {
+		err = s.repo.UpdateSystemConfiguration(ctx, map[string]interface{}{"externalAPI": apiConfig})
+	} else {
+		err = s.cache.UpdateExternalAPIConfiguration(ctx, apiConfig)
+	}
+	if err != nil {
+		return err
+	}
+	s.recordConfigurationChange(ctx, before)
+	s.externalAPIConfigChanged(ctx)
+	return nil
 }
 
-// GetRoomsConfig gets rooms configuration from cache
+// GetRoomsConfig gets the effective rooms of the tenant in the context, from cache without a tenant
 func (s *Service) GetRoomsConfig(ctx context.Context) ([]types.RoomConfig, error) {
-	// Check if tenant ID is in context - if so, bypass cache and query repository directly
-	tenantID := service.GetTenantID(ctx)
-	if tenantID != "" {
-		// Bypass cache for tenant-specific requests
-		log.Printf("Tenant-specific rooms config requested for: %s, querying repository directly", tenantID)
-		systemConfig, err := s.repo.GetSystemConfiguration(ctx)
+	if service.GetTenantID(ctx) != "" {
+		systemConfig, err := s.effectiveConfiguration(ctx)
 		if err != nil {
 			return nil, err
 		}
 		if systemConfig != nil && len(systemConfig.Rooms) > 0 {
 			return systemConfig.Rooms, nil
 		}
-		// No tenant-specific config found, return empty
 		return []types.RoomConfig{}, nil
 	}
 
@@ -164,12 +305,377 @@ func (s *Service) GetRoomsConfig(ctx context.Context) ([]types.RoomConfig, error
 	}
 
 	// Fallback to default rooms
-	return s.getDefaultRoomsConfig(), nil
+	return s.GetDefaultRoomsConfig(), nil
+}
+
+// GetQueueOrdering returns the ordering of a room of the tenant in the context; the priority order
+// when the room sets none or the configuration cannot be read
+func (s *Service) GetQueueOrdering(ctx context.Context, roomId string) types.QueueOrdering {
+	rooms, err := s.GetRoomsConfig(ctx)
+	if err != nil {
+		log.Printf("Failed to get rooms config for the ordering of room %s: %v", roomId, err)
+		return types.QueueOrderingPriority
+	}
+	for _, room := range rooms {
+		if room.ID == roomId && room.Ordering != "" {
+			return room.Ordering
+		}
+	}
+	return types.QueueOrderingPriority
 }
 
-// SetRoomsConfig updates rooms configuration
+// SetRoomsConfig updates rooms configuration. A tenant stores only them, leaving the other values
+// to be inherited.
 func (s *Service) SetRoomsConfig(ctx context.Context, rooms []types.RoomConfig) error {
-	return s.cache.UpdateRoomsConfiguration(ctx, rooms)
+	before := s.configurationBeforeChange(ctx)
+	var err error
+	if service.GetTenantID(ctx) != "" {
+		err = s.repo.UpdateSystemConfiguration(ctx, map[string]interface{}{"rooms": rooms})
+	} else {
+		err = s.cache.UpdateRoomsConfiguration(ctx, rooms)
+	}
+	if err != nil {
+		return err
+	}
+	s.recordConfigurationChange(ctx, before)
+	return nil
+}
+
+// GetPathways gets the visit pathways of the tenant in the context
+func (s *Service) GetPathways(ctx context.Context) ([]types.Pathway, error) {
+	systemConfig, err := s.effectiveConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if systemConfig == nil {
+		return []types.Pathway{}, nil
+	}
+	return systemConfig.Pathways, nil
+}
+
+// SetPathways updates the visit pathways of the tenant in the context
+func (s *Service) SetPathways(ctx context.Context, pathways []types.Pathway) error {
+	updates := map[string]interface{}{
+		"pathways": pathways,
+	}
+	return s.UpdateSystemConfiguration(ctx, updates)
+}
+
+// GetRetentionPolicy gets the card data retention policy of the tenant in the context, nil when
+// it keeps card data
+func (s *Service) GetRetentionPolicy(ctx context.Context) (*types.RetentionPolicy, error) {
+	systemConfig, err := s.effectiveConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if systemConfig == nil {
+		return nil, nil
+	}
+	return systemConfig.Retention, nil
+}
+
+// SetRetentionPolicy updates the card data retention policy of the tenant in the context
+func (s *Service) SetRetentionPolicy(ctx context.Context, policy *types.RetentionPolicy) error {
+	updates := map[string]interface{}{
+		"retention": policy,
+	}
+	return s.UpdateSystemConfiguration(ctx, updates)
+}
+
+// GetNotificationSettings gets the patient notification settings of the tenant in the context, nil
+// when it does not notify patients
+func (s *Service) GetNotificationSettings(ctx context.Context) (*types.NotificationSettings, error) {
+	systemConfig, err := s.effectiveConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if systemConfig == nil {
+		return nil, nil
+	}
+	return systemConfig.Notifications, nil
+}
+
+// SetNotificationSettings updates the patient notification settings of the tenant in the context
+func (s *Service) SetNotificationSettings(ctx context.Context, settings *types.NotificationSettings) error {
+	updates := map[string]interface{}{
+		"notifications": settings,
+	}
+	return s.UpdateSystemConfiguration(ctx, updates)
+}
+
+// GetFHIRSettings gets the FHIR settings of the tenant in the context, nil when it has none
+func (s *Service) GetFHIRSettings(ctx context.Context) (*types.FHIRSettings, error) {
+	systemConfig, err := s.effectiveConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if systemConfig == nil {
+		return nil, nil
+	}
+	return systemConfig.FHIR, nil
+}
+
+// SetFHIRSettings updates the FHIR settings of the tenant in the context
+func (s *Service) SetFHIRSettings(ctx context.Context, settings *types.FHIRSettings) error {
+	updates := map[string]interface{}{
+		"fhir": settings,
+	}
+	return s.UpdateSystemConfiguration(ctx, updates)
+}
+
+// GetMessageCatalogs gets the UI message catalogs of the tenant in the context by language
+func (s *Service) GetMessageCatalogs(ctx context.Context) (map[string]map[string]string, error) {
+	systemConfig, err := s.effectiveConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if systemConfig == nil || systemConfig.Messages == nil {
+		return map[string]map[string]string{}, nil
+	}
+	return systemConfig.Messages, nil
+}
+
+// SetMessageCatalogs replaces the UI message catalogs of the tenant in the context
+func (s *Service) SetMessageCatalogs(ctx context.Context, catalogs map[string]map[string]string) error {
+	updates := map[string]interface{}{
+		"messages": catalogs,
+	}
+	return s.UpdateSystemConfiguration(ctx, updates)
+}
+
+// IssueIntegrationCredentials generates a new API key and signing secret for the external systems of
+// the tenant in the context, replacing the previous ones. Only the hash of the key is stored; both
+// are returned once to the caller.
+func (s *Service) IssueIntegrationCredentials(ctx context.Context) (string, string, error) {
+	raw := make([]byte, 64)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("failed to generate integration credentials: %w", err)
+	}
+	apiKey := hex.EncodeToString(raw[:32])
+	signingSecret := hex.EncodeToString(raw[32:])
+
+	updates := map[string]interface{}{
+		"integration": &types.IntegrationCredentials{
+			APIKeyHash:    HashDeviceToken(apiKey),
+			SigningSecret: signingSecret,
+			IssuedAt:      time.Now(),
+		},
+	}
+	if err := s.UpdateSystemConfiguration(ctx, updates); err != nil {
+		return "", "", err
+	}
+	s.audit.Record(ctx, types.AuditResourceIntegrationCredentials, types.AuditActionCreate, "", nil, updates["integration"])
+	log.Printf("[ConfigService] Issued new integration credentials")
+	return apiKey, signingSecret, nil
+}
+
+// VerifyIntegrationAPIKey checks an API key against the integration credentials of the tenant in
+// the context and returns the secret its requests are signed with
+func (s *Service) VerifyIntegrationAPIKey(ctx context.Context, apiKey string) (string, bool, error) {
+	systemConfig, err := s.repo.GetSystemConfiguration(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	if systemConfig == nil || systemConfig.Integration == nil || systemConfig.Integration.APIKeyHash == "" {
+		return "", false, nil
+	}
+	if subtle.ConstantTimeCompare([]byte(HashDeviceToken(apiKey)), []byte(systemConfig.Integration.APIKeyHash)) != 1 {
+		return "", false, nil
+	}
+	return systemConfig.Integration.SigningSecret, true, nil
+}
+
+// apiKeyLastUsedInterval is how often the use of an API key is recorded, so a kiosk polling the
+// API doesn't write the configuration on every request
+const apiKeyLastUsedInterval = time.Minute
+
+// GetAPIKeys gets the API keys of the tenant in the context, revoked ones included
+func (s *Service) GetAPIKeys(ctx context.Context) ([]types.APIKey, error) {
+	systemConfig, err := s.repo.GetSystemConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if systemConfig == nil {
+		return nil, nil
+	}
+	return systemConfig.APIKeys, nil
+}
+
+// CreateAPIKey issues a key for the device type and optional device ID of apiKey to the tenant
+// in the context. Only the hash of the key is stored; the key is returned once to the caller.
+func (s *Service) CreateAPIKey(ctx context.Context, apiKey *types.APIKey) (string, error) {
+	apiKey.TenantID = service.GetTenantID(ctx)
+	if apiKey.TenantID == "" {
+		return "", ngErrors.New(ngErrors.ValidationErrorCode, "API keys are issued to a tenant, X-Tenant-ID is required", 400, nil)
+	}
+	key, err := NewAPIKey(apiKey)
+	if err != nil {
+		return "", err
+	}
+
+	s.apiKeysMu.Lock()
+	defer s.apiKeysMu.Unlock()
+	keys, err := s.GetAPIKeys(ctx)
+	if err != nil {
+		return "", err
+	}
+	if err := s.UpdateSystemConfiguration(ctx, map[string]interface{}{"apiKeys": append(keys, *apiKey)}); err != nil {
+		return "", err
+	}
+	s.audit.Record(ctx, types.AuditResourceAPIKey, types.AuditActionCreate, apiKey.ID, nil, apiKey)
+	log.Printf("[ConfigService] Issued API key %s for %s %s", apiKey.ID, apiKey.DeviceType, apiKey.DeviceID)
+	return key, nil
+}
+
+// NewAPIKey generates a key for apiKey and sets its ID, hash, prefix and creation time, without
+// storing it
+func NewAPIKey(apiKey *types.APIKey) (string, error) {
+	raw := make([]byte, 40)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key := types.APIKeyPrefix + hex.EncodeToString(raw[:32])
+	apiKey.ID = hex.EncodeToString(raw[32:])
+	apiKey.KeyHash = HashDeviceToken(key)
+	apiKey.KeyPrefix = key[:len(types.APIKeyPrefix)+8]
+	apiKey.CreatedAt = time.Now()
+	return key, nil
+}
+
+// RevokeAPIKey revokes the API key of the tenant in the context; nil if there is none with the ID
+func (s *Service) RevokeAPIKey(ctx context.Context, id string) (*types.APIKey, error) {
+	s.apiKeysMu.Lock()
+	defer s.apiKeysMu.Unlock()
+	keys, err := s.GetAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		if keys[i].ID != id {
+			continue
+		}
+		if keys[i].RevokedAt == nil {
+			before := keys[i]
+			now := time.Now()
+			keys[i].RevokedAt = &now
+			if err := s.UpdateSystemConfiguration(ctx, map[string]interface{}{"apiKeys": keys}); err != nil {
+				return nil, err
+			}
+			s.audit.Record(ctx, types.AuditResourceAPIKey, types.AuditActionRevoke, id, before, keys[i])
+			log.Printf("[ConfigService] Revoked API key %s", id)
+		}
+		return &keys[i], nil
+	}
+	return nil, nil
+}
+
+// VerifyAPIKey checks a key against the API keys of the tenant in the context and returns the
+// key it matches, with its tenant set, nil for unknown and revoked keys and without a tenant.
+// The use is recorded with the address of the device.
+func (s *Service) VerifyAPIKey(ctx context.Context, key, remoteAddr string) (*types.APIKey, error) {
+	// Keys are issued to tenants; the configuration without one holds none that may be used
+	tenantID := service.GetTenantID(ctx)
+	if tenantID == "" {
+		return nil, nil
+	}
+	keys, err := s.GetAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hash := HashDeviceToken(key)
+	for i := range keys {
+		apiKey := &keys[i]
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(apiKey.KeyHash)) != 1 || apiKey.RevokedAt != nil {
+			continue
+		}
+		// Keys issued before they named their tenant belong to the configuration they are in
+		if apiKey.TenantID == "" {
+			apiKey.TenantID = tenantID
+		}
+		if apiKey.TenantID != tenantID {
+			continue
+		}
+		// Recorded in the background: a flood of requests with the key must not wait for, or
+		// multiply, writes of the configuration
+		if s.claimAPIKeyUse(apiKey, remoteAddr) {
+			go s.recordAPIKeyUse(context.WithoutCancel(ctx), apiKey.ID, remoteAddr)
+		}
+		return apiKey, nil
+	}
+	return nil, nil
+}
+
+// claimAPIKeyUse reports whether the use of apiKey from remoteAddr is to be recorded: at most once
+// per apiKeyLastUsedInterval and address on this replica, and not when the stored use is as recent
+func (s *Service) claimAPIKeyUse(apiKey *types.APIKey, remoteAddr string) bool {
+	s.apiKeyUsesMu.Lock()
+	defer s.apiKeyUsesMu.Unlock()
+	last, ok := s.apiKeyUses[apiKey.ID]
+	if !ok && apiKey.LastUsedAt != nil {
+		last = apiKeyUse{at: *apiKey.LastUsedAt, remoteAddr: apiKey.LastUsedIP}
+	}
+	if time.Since(last.at) <= apiKeyLastUsedInterval && last.remoteAddr == remoteAddr {
+		return false
+	}
+	s.apiKeyUses[apiKey.ID] = apiKeyUse{at: time.Now(), remoteAddr: remoteAddr}
+	return true
+}
+
+// recordAPIKeyUse stores when and from where the key was used last. It writes through the
+// repository, as the use of a key changes no configuration the listeners care about; a failure
+// doesn't reject the request.
+func (s *Service) recordAPIKeyUse(ctx context.Context, id, remoteAddr string) {
+	s.apiKeysMu.Lock()
+	defer s.apiKeysMu.Unlock()
+	keys, err := s.GetAPIKeys(ctx)
+	if err != nil {
+		log.Printf("[ConfigService] Failed to record use of API key %s: %v", id, err)
+		return
+	}
+	for i := range keys {
+		if keys[i].ID == id {
+			now := time.Now()
+			keys[i].LastUsedAt = &now
+			keys[i].LastUsedIP = remoteAddr
+		}
+	}
+	if err := s.repo.UpdateSystemConfiguration(ctx, map[string]interface{}{"apiKeys": keys}); err != nil {
+		log.Printf("[ConfigService] Failed to record use of API key %s: %v", id, err)
+	}
+}
+
+// GetRetentionPolicies gets the retention policies that purge card data, keyed by tenant ID
+// ("buildingId:sectionId", "buildingId" or empty for entries without a tenant)
+func (s *Service) GetRetentionPolicies(ctx context.Context) (map[string]types.RetentionPolicy, error) {
+	tenants, err := s.repo.GetAllTenants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenants: %w", err)
+	}
+
+	tenantIDs := []string{""}
+	for _, tenant := range tenants {
+		tenantID := tenant.BuildingID
+		if tenant.SectionID != "" {
+			tenantID += ":" + tenant.SectionID
+		}
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+
+	policies := make(map[string]types.RetentionPolicy)
+	for _, tenantID := range tenantIDs {
+		tenantCtx := ctx
+		if tenantID != "" {
+			tenantCtx = context.WithValue(ctx, middleware.TENANT, tenantID)
+		}
+		policy, err := s.GetRetentionPolicy(tenantCtx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get retention policy of tenant '%s': %w", tenantID, err)
+		}
+		if policy != nil && policy.PurgeAfterDays > 0 {
+			policies[tenantID] = *policy
+		}
+	}
+	return policies, nil
 }
 
 // GetDefaultRoom gets the default room ID
@@ -192,7 +698,12 @@ func (s *Service) SetDefaultRoom(ctx context.Context, roomId string) error {
 	updates := map[string]interface{}{
 		"defaultRoom": roomId,
 	}
-	return s.repo.UpdateSystemConfiguration(ctx, updates)
+	before := s.configurationBeforeChange(ctx)
+	if err := s.repo.UpdateSystemConfiguration(ctx, updates); err != nil {
+		return err
+	}
+	s.recordConfigurationChange(ctx, before)
+	return nil
 }
 
 // GetCardReaders gets all card reader statuses
@@ -215,14 +726,143 @@ func (s *Service) DeleteCardReader(ctx context.Context, id string) error {
 	return s.repo.DeleteCardReader(ctx, id)
 }
 
-// RestartCardReader sends a restart signal to a card reader
-func (s *Service) RestartCardReader(ctx context.Context, id string) (map[string]interface{}, error) {
-	// In a real implementation, this would send a restart signal to the card reader
-	// For now, we'll just return a success response
-	return map[string]interface{}{
-		"success": true,
-		"message": "Restart signal sent to card reader " + id,
-	}, nil
+// GetCardReaderConfig gets the remote configuration for a card reader device
+func (s *Service) GetCardReaderConfig(ctx context.Context, id string) (*types.CardReaderConfig, error) {
+	return s.repo.GetCardReaderConfig(ctx, id)
+}
+
+// GetCardReaderConfigsByRoom gets the remote configurations of the card readers of the tenant
+// assigned to a room
+func (s *Service) GetCardReaderConfigsByRoom(ctx context.Context, roomID string) ([]types.CardReaderConfig, error) {
+	return s.repo.GetCardReaderConfigsByRoom(ctx, roomID)
+}
+
+// SetCardReaderConfig creates or replaces the remote configuration for a card reader device
+func (s *Service) SetCardReaderConfig(ctx context.Context, config *types.CardReaderConfig) error {
+	before, err := s.repo.GetCardReaderConfig(ctx, config.DeviceID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SetCardReaderConfig(ctx, config); err != nil {
+		if errors.Is(err, repository.ErrCardReaderOtherTenant) {
+			return ngErrors.CardReaderOtherTenant(config.DeviceID)
+		}
+		return err
+	}
+	action := types.AuditActionUpdate
+	if before == nil {
+		action = types.AuditActionCreate
+	}
+	s.audit.Record(ctx, types.AuditResourceCardReaderConfiguration, action, config.DeviceID, before, config)
+	return nil
+}
+
+// IssueCardReaderToken generates a new bearer token for a registered card reader.
+// Only the SHA-256 hash is stored; the plain token is returned once to the caller.
+func (s *Service) IssueCardReaderToken(ctx context.Context, id string) (string, error) {
+	readerConfig, err := s.repo.GetCardReaderConfig(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if readerConfig == nil {
+		return "", fmt.Errorf("card reader %s is not registered", id)
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate device token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	readerConfig.DeviceTokenHash = HashDeviceToken(token)
+	if err := s.repo.SetCardReaderConfig(ctx, readerConfig); err != nil {
+		return "", err
+	}
+	log.Printf("[ConfigService] Issued new device token for card reader %s", id)
+	return token, nil
+}
+
+// VerifyCardReaderCredential checks a bearer token or client certificate fingerprint
+// against the credentials registered for the device. Either one is sufficient.
+func (s *Service) VerifyCardReaderCredential(ctx context.Context, deviceID, token, certFingerprint string) (bool, error) {
+	readerConfig, err := s.repo.GetCardReaderConfig(ctx, deviceID)
+	if err != nil {
+		return false, err
+	}
+	if readerConfig == nil {
+		return false, nil
+	}
+
+	if token != "" && readerConfig.DeviceTokenHash != "" &&
+		subtle.ConstantTimeCompare([]byte(HashDeviceToken(token)), []byte(readerConfig.DeviceTokenHash)) == 1 {
+		return true, nil
+	}
+	if certFingerprint != "" && readerConfig.ClientCertFingerprint != "" &&
+		strings.EqualFold(certFingerprint, readerConfig.ClientCertFingerprint) {
+		return true, nil
+	}
+	return false, nil
+}
+
+// GetCardReaderTenant returns the tenant a card reader is registered to, whatever the tenant of
+// the request, and whether it is registered at all
+func (s *Service) GetCardReaderTenant(ctx context.Context, deviceID string) (string, bool, error) {
+	readerConfig, err := s.repo.GetCardReaderConfig(context.WithValue(ctx, middleware.TENANT, ""), deviceID)
+	if err != nil || readerConfig == nil {
+		return "", false, err
+	}
+	return readerConfig.TenantID, true, nil
+}
+
+// GetCardReaderRelease returns the card-reader release published for os/arch, nil if none
+func (s *Service) GetCardReaderRelease(ctx context.Context, os, arch string) (*types.CardReaderRelease, error) {
+	return s.repo.GetCardReaderRelease(ctx, os, arch)
+}
+
+// PublishCardReaderRelease makes a release the update target for its os/arch
+func (s *Service) PublishCardReaderRelease(ctx context.Context, release *types.CardReaderRelease) error {
+	return s.repo.SetCardReaderRelease(ctx, release)
+}
+
+// RotateCardDataKey provisions a new CardData encryption key for a card reader and
+// returns it base64 encoded. Payloads sealed with the previous key are rejected afterwards.
+func (s *Service) RotateCardDataKey(ctx context.Context, id string) (string, error) {
+	readerConfig, err := s.repo.GetCardReaderConfig(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if readerConfig == nil {
+		return "", fmt.Errorf("card reader %s is not registered", id)
+	}
+
+	key, err := cardreader.NewCardDataKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate card data key: %w", err)
+	}
+	readerConfig.CardDataKey = key
+	if err := s.repo.SetCardReaderConfig(ctx, readerConfig); err != nil {
+		return "", err
+	}
+	log.Printf("[ConfigService] Rotated card data key for card reader %s", id)
+	return key, nil
+}
+
+// DecryptCardData opens an encrypted CardData block with the device's key and returns the CardData JSON
+func (s *Service) DecryptCardData(ctx context.Context, deviceID, token string, block *cardreader.EncryptedCardData) ([]byte, error) {
+	readerConfig, err := s.repo.GetCardReaderConfig(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if readerConfig == nil || readerConfig.CardDataKey == "" {
+		return nil, fmt.Errorf("no card data key provisioned for card reader %s", deviceID)
+	}
+	return block.Open(readerConfig.CardDataKey, deviceID, token)
+}
+
+// HashDeviceToken returns the hex SHA-256 of a device token
+func HashDeviceToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 // GetExternalAPIConfiguration gets external API configuration
@@ -249,7 +889,7 @@ func (s *Service) UpdateRoomsConfiguration(ctx context.Context, rooms []types.Ro
 func (s *Service) getSystemConfigurationFromEnv() *types.SystemConfiguration {
 	return &types.SystemConfiguration{
 		ExternalAPI:   *s.getExternalAPIConfigFromEnv(),
-		Rooms:         s.getDefaultRoomsConfig(),
+		Rooms:         s.GetDefaultRoomsConfig(),
 		DefaultRoom:   os.Getenv("DEFAULT_ROOM"),
 		WebSocketPath: "/ws/queue",
 		AllowWildcard: true,
@@ -284,7 +924,8 @@ func (s *Service) getExternalAPIConfigFromEnv() *types.ExternalAPIConfig {
 	}
 }
 
-func (s *Service) getDefaultRoomsConfig() []types.RoomConfig {
+// GetDefaultRoomsConfig returns the rooms used when none are configured
+func (s *Service) GetDefaultRoomsConfig() []types.RoomConfig {
 	return []types.RoomConfig{
 		{
 			ID:          "triage-1",
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"time"
+
+	appConfig "github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// OnConfigurationChange registers f to be called with the layer whose configuration changed
+// ("buildingId:sectionId", "buildingId" or empty for the global one), through this service or, as
+// found by the change watcher, on another replica or in the database
+func (s *Service) OnConfigurationChange(f func(tenantID string)) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	s.configListeners = append(s.configListeners, f)
+}
+
+// StartChangeWatchRoutine starts a background routine that polls the stored configurations for
+// changes every WatchIntervalSeconds, reloads the cached global configuration when it changed and
+// notifies the listeners of the changed layers. It does nothing when the interval is negative.
+func (s *Service) StartChangeWatchRoutine(ctx context.Context, cfg appConfig.ConfigurationConfig) {
+	if cfg.WatchIntervalSeconds < 0 {
+		log.Printf("[ConfigService] Configuration change watching disabled")
+		return
+	}
+	interval := time.Duration(cfg.WatchIntervalSeconds) * time.Second
+
+	// The configurations stored on start are the known versions
+	since := time.Now()
+	configs, err := s.repo.GetSystemConfigurationsUpdatedSince(ctx, time.Time{})
+	if err != nil {
+		log.Printf("[ConfigService] Failed to read the configurations to watch: %v", err)
+	}
+	for _, config := range configs {
+		s.recordVersion(config)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			// Updates are stamped by the clock of the replica making them, so the last interval is
+			// read again; the versions tell the changes seen already
+			polledAt := time.Now()
+			configs, err := s.repo.GetSystemConfigurationsUpdatedSince(ctx, since.Add(-interval))
+			if err != nil {
+				log.Printf("[ConfigService] Failed to poll changed configurations: %v", err)
+				continue
+			}
+			since = polledAt
+
+			for _, config := range configs {
+				if !s.recordVersion(config) {
+					continue
+				}
+				tenantID := layerTenantID(config)
+				log.Printf("[ConfigService] Configuration of '%s' changed elsewhere, reloading", tenantID)
+				if tenantID == "" {
+					s.cache.ReloadConfig(context.WithValue(ctx, middleware.TENANT, ""))
+				}
+				s.notifyConfigurationChange(tenantID, true)
+			}
+		}
+	}()
+	log.Printf("[ConfigService] Configuration change watching started (polling every %s)", interval)
+}
+
+// configurationChanged records the version of a configuration changed through this service, so the
+// watcher doesn't report it again, and notifies the listeners when it changed
+func (s *Service) configurationChanged(config *types.SystemConfiguration) {
+	if s.recordVersion(config) {
+		s.notifyConfigurationChange(layerTenantID(config), false)
+	}
+}
+
+// notifyConfigurationChange calls the listeners with the changed layer; the external API ones only
+// for changes found by the watcher, this service calls those on its own changes
+func (s *Service) notifyConfigurationChange(tenantID string, external bool) {
+	s.listenersMu.Lock()
+	listeners := s.configListeners
+	if external {
+		listeners = append(append([]func(string){}, listeners...), s.externalAPIListeners...)
+	}
+	s.listenersMu.Unlock()
+	for _, f := range listeners {
+		f(tenantID)
+	}
+}
+
+// recordVersion stores the version of the configuration of a layer and reports whether it differs
+// from the one known before
+func (s *Service) recordVersion(config *types.SystemConfiguration) bool {
+	version := configVersion(config)
+	tenantID := layerTenantID(config)
+
+	s.versionsMu.Lock()
+	defer s.versionsMu.Unlock()
+	if s.versions[tenantID] == version {
+		return false
+	}
+	s.versions[tenantID] = version
+	return true
+}
+
+// configVersion identifies the content of a stored configuration. Fields that aren't serialized,
+// like the API keys whose use is recorded in the configuration, and the timestamps are left out.
+func configVersion(config *types.SystemConfiguration) string {
+	content := *config
+	content.CreatedAt, content.UpdatedAt = time.Time{}, time.Time{}
+	data, err := json.Marshal(content)
+	if err != nil {
+		log.Printf("[ConfigService] Failed to encode configuration version: %v", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// layerTenantID returns the tenant ID of the layer a configuration is stored for
+func layerTenantID(config *types.SystemConfiguration) string {
+	if config.SectionID != "" {
+		return config.TenantID + ":" + config.SectionID
+	}
+	return config.TenantID
+}
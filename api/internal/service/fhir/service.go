@@ -0,0 +1,182 @@
+package fhir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/fhir"
+	"github.com/arfis/waiting-room/internal/queue"
+	appointmentService "github.com/arfis/waiting-room/internal/service/appointment"
+	configService "github.com/arfis/waiting-room/internal/service/config"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// Service serves the queue entries of the tenant in the context as FHIR Encounters and its
+// appointments as FHIR Appointments, which integration engines can also push
+type Service struct {
+	queueService       *queue.WaitingQueue
+	appointmentService *appointmentService.Service
+	configService      *configService.Service
+}
+
+// New creates a new FHIR service
+func New(queueService *queue.WaitingQueue, appointmentService *appointmentService.Service, configService *configService.Service) *Service {
+	return &Service{
+		queueService:       queueService,
+		appointmentService: appointmentService,
+		configService:      configService,
+	}
+}
+
+// GetCapabilityStatement returns the capability statement of the server, with the profiles of the tenant
+func (s *Service) GetCapabilityStatement(ctx context.Context) (*fhir.CapabilityStatement, error) {
+	settings, err := s.settings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return fhir.NewCapabilityStatement(settings, time.Now()), nil
+}
+
+// GetEncounter returns the encounter of a queue entry
+func (s *Service) GetEncounter(ctx context.Context, id string) (*fhir.Encounter, error) {
+	settings, err := s.settings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := s.queueService.GetEntry(ctx, id)
+	if err != nil {
+		if errors.Is(err, queue.ErrEntryNotFound) {
+			return nil, ngErrors.New(ngErrors.NotFoundErrorCode, fmt.Sprintf("Encounter/%s not found", id), 404, nil)
+		}
+		log.Printf("[FHIRService] Failed to get entry %s: %v", id, err)
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to get encounter", 500, nil)
+	}
+	return fhir.NewEncounter(entry, settings), nil
+}
+
+// SearchEncounters returns the encounters of the queue entries of a room (location), all of them
+// or those with a FHIR status
+func (s *Service) SearchEncounters(ctx context.Context, location, status string) (*fhir.Bundle, error) {
+	roomId := strings.TrimPrefix(location, "Location/")
+	if roomId == "" {
+		return nil, ngErrors.New(ngErrors.MissingRequiredFieldErrorCode, "location is required", 400, nil)
+	}
+	var states []string
+	if status != "" {
+		var ok bool
+		if states, ok = fhir.EntryStatuses(status); !ok {
+			return searchSet(nil), nil
+		}
+	}
+	settings, err := s.settings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.queueService.GetQueueEntriesWithContext(ctx, roomId, states)
+	if err != nil {
+		log.Printf("[FHIRService] Failed to get entries of room %s: %v", roomId, err)
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to search encounters", 500, nil)
+	}
+	resources := make([]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		resources = append(resources, fhir.NewEncounter(entry, settings))
+	}
+	return searchSet(resources), nil
+}
+
+// GetAppointment returns the appointment with an external ID; a checked-in one has the status of
+// its queue entry
+func (s *Service) GetAppointment(ctx context.Context, id string) (*fhir.Appointment, error) {
+	settings, err := s.settings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	appointment, err := s.appointmentService.GetAppointment(ctx, id)
+	if err != nil {
+		log.Printf("[FHIRService] Failed to get appointment %s: %v", id, err)
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to get appointment", 500, nil)
+	}
+	if appointment == nil {
+		return nil, ngErrors.New(ngErrors.NotFoundErrorCode, fmt.Sprintf("Appointment/%s not found", id), 404, nil)
+	}
+
+	entryStatus := ""
+	if appointment.EntryID != "" {
+		if entry, err := s.queueService.GetEntry(ctx, appointment.EntryID); err == nil {
+			entryStatus = entry.Status
+		}
+	}
+	return fhir.NewAppointment(appointment, entryStatus, settings), nil
+}
+
+// SearchAppointments returns the appointments of one day (YYYY-MM-DD, today when empty)
+func (s *Service) SearchAppointments(ctx context.Context, date string) (*fhir.Bundle, error) {
+	settings, err := s.settings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	appointments, err := s.appointmentService.GetDayAppointments(ctx, &date)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]interface{}, 0, len(appointments))
+	for _, appointment := range appointments {
+		resources = append(resources, fhir.NewAppointment(appointment, "", settings))
+	}
+	return searchSet(resources), nil
+}
+
+// SaveAppointment pre-registers the visit of a FHIR appointment, matched by its identifier; an
+// update (id set) must not name another appointment
+func (s *Service) SaveAppointment(ctx context.Context, id string, resource *fhir.Appointment) (*fhir.Appointment, error) {
+	if id != "" {
+		if resource.ID != "" && resource.ID != id {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("resource id %s does not match Appointment/%s", resource.ID, id), 400, nil)
+		}
+		resource.ID = id
+		resource.Identifier = nil
+	}
+	settings, err := s.settings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	item, err := fhir.ParseAppointment(resource, settings)
+	if err != nil {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, err.Error(), 400, nil)
+	}
+
+	if _, err := s.appointmentService.UpsertAppointments(ctx, []dto.Appointment{*item}); err != nil {
+		return nil, err
+	}
+	return s.GetAppointment(ctx, item.ExternalId)
+}
+
+// settings returns the FHIR settings of the tenant, nil when it has none
+func (s *Service) settings(ctx context.Context) (*types.FHIRSettings, error) {
+	settings, err := s.configService.GetFHIRSettings(ctx)
+	if err != nil {
+		log.Printf("[FHIRService] Failed to get FHIR settings: %v", err)
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to get FHIR settings", 500, nil)
+	}
+	return settings, nil
+}
+
+// searchSet returns the bundle of the results of a search
+func searchSet(resources []interface{}) *fhir.Bundle {
+	bundle := &fhir.Bundle{
+		ResourceType: "Bundle",
+		Type:         "searchset",
+		Total:        len(resources),
+	}
+	for _, resource := range resources {
+		bundle.Entry = append(bundle.Entry, fhir.BundleEntry{Resource: resource})
+	}
+	return bundle
+}
@@ -0,0 +1,22 @@
+package i18n
+
+// DefaultLanguage is the language of the built-in messages and the fallback of every catalog
+const DefaultLanguage = "en"
+
+// defaultMessages are the built-in English messages of the kiosks and displays, which catalogs of
+// the tenants override and translate
+var defaultMessages = map[string]string{
+	"kiosk.insertCard":          "Please insert your ID card",
+	"kiosk.readingCard":         "Reading your card, please wait",
+	"kiosk.cardReadFailed":      "Your card could not be read. Please try again.",
+	"kiosk.selectService":       "Please select a service",
+	"kiosk.servicesUnavailable": "Services are temporarily unavailable",
+	"kiosk.ticket":              "Ticket",
+	"kiosk.takeTicket":          "Please take your ticket",
+	"kiosk.queueFull":           "The queue is full. Please ask at the reception.",
+	"display.ticket":            "Ticket",
+	"display.window":            "Window",
+	"display.nowServing":        "Now serving",
+	"display.waiting":           "Waiting",
+	"display.estimatedWait":     "Estimated wait",
+}
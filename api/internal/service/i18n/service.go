@@ -0,0 +1,245 @@
+// Package i18n serves the UI messages of the kiosks and displays in the language of the patient.
+// Every tenant overrides and translates the built-in messages in catalogs stored with its system
+// configuration.
+package i18n
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	configService "github.com/arfis/waiting-room/internal/service/config"
+	"github.com/arfis/waiting-room/internal/service/translation"
+)
+
+// languagePattern matches the lower case language codes of catalogs, e.g. sk or pt-br
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+// Service manages the message catalogs of the tenant in the context
+type Service struct {
+	configService      *configService.Service
+	translationService *translation.Service
+}
+
+func New(configService *configService.Service, translationService *translation.Service) *Service {
+	return &Service{
+		configService:      configService,
+		translationService: translationService,
+	}
+}
+
+// ResolveLanguage returns the language the tenant serves for the requested one: the requested
+// language, or its base language (sk for sk-sk), when the tenant supports it, its default language
+// otherwise. Tenants without supported languages serve any language, English when none is requested.
+func (s *Service) ResolveLanguage(ctx context.Context, requested string) (string, error) {
+	requested = strings.ToLower(strings.TrimSpace(requested))
+	apiConfig, err := s.configService.GetExternalAPIConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	if apiConfig == nil || len(apiConfig.SupportedLanguages) == 0 {
+		if requested != "" {
+			return requested, nil
+		}
+		if apiConfig != nil && apiConfig.DefaultLanguage != "" {
+			return apiConfig.DefaultLanguage, nil
+		}
+		return DefaultLanguage, nil
+	}
+
+	supported := func(language string) bool {
+		return slices.ContainsFunc(apiConfig.SupportedLanguages, func(candidate string) bool {
+			return strings.EqualFold(candidate, language)
+		})
+	}
+	if requested != "" && supported(requested) {
+		return requested, nil
+	}
+	if base, _, found := strings.Cut(requested, "-"); found && supported(base) {
+		return base, nil
+	}
+	if apiConfig.DefaultLanguage != "" {
+		return apiConfig.DefaultLanguage, nil
+	}
+	return strings.ToLower(apiConfig.SupportedLanguages[0]), nil
+}
+
+// GetMessages returns the messages of the language the tenant serves for the requested one and
+// their ETag: the built-in messages, replaced by the English catalog of the tenant and then by the
+// catalog of the language
+func (s *Service) GetMessages(ctx context.Context, language string) (*dto.MessageCatalog, string, error) {
+	language, err := s.ResolveLanguage(ctx, language)
+	if err != nil {
+		return nil, "", err
+	}
+	language, err = normalizeLanguage(language)
+	if err != nil {
+		return nil, "", err
+	}
+	catalogs, err := s.configService.GetMessageCatalogs(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	messages := maps.Clone(defaultMessages)
+	maps.Copy(messages, catalogs[DefaultLanguage])
+	maps.Copy(messages, catalogs[language])
+	catalog := &dto.MessageCatalog{Language: language, Messages: messages}
+
+	// Maps are encoded with sorted keys, so the same messages have the same tag
+	encoded, err := json.Marshal(catalog)
+	if err != nil {
+		return nil, "", err
+	}
+	hash := sha256.Sum256(encoded)
+	return catalog, `"` + hex.EncodeToString(hash[:16]) + `"`, nil
+}
+
+// GetMessageCatalogs returns the catalogs of the tenant by language. The English catalog always
+// exists and holds the built-in messages the tenant doesn't override.
+func (s *Service) GetMessageCatalogs(ctx context.Context) ([]dto.MessageCatalog, error) {
+	catalogs, err := s.configService.GetMessageCatalogs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	english := maps.Clone(defaultMessages)
+	maps.Copy(english, catalogs[DefaultLanguage])
+	result := []dto.MessageCatalog{{Language: DefaultLanguage, Messages: english}}
+	for _, language := range slices.Sorted(maps.Keys(catalogs)) {
+		if language != DefaultLanguage {
+			result = append(result, dto.MessageCatalog{Language: language, Messages: catalogs[language]})
+		}
+	}
+	return result, nil
+}
+
+// UpdateMessageCatalog replaces the catalog of the language
+func (s *Service) UpdateMessageCatalog(ctx context.Context, language string, req *dto.MessageCatalogUpdate) (*dto.MessageCatalog, error) {
+	language, err := normalizeLanguage(language)
+	if err != nil {
+		return nil, err
+	}
+	for key := range req.Messages {
+		if strings.TrimSpace(key) == "" {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, "message keys must not be empty", 400, nil)
+		}
+	}
+
+	catalogs, err := s.configService.GetMessageCatalogs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	catalogs = maps.Clone(catalogs)
+	catalogs[language] = req.Messages
+	if err := s.configService.SetMessageCatalogs(ctx, catalogs); err != nil {
+		return nil, err
+	}
+	return &dto.MessageCatalog{Language: language, Messages: req.Messages}, nil
+}
+
+// DeleteMessageCatalog removes the catalog of the language; without an English catalog the
+// built-in messages are served
+func (s *Service) DeleteMessageCatalog(ctx context.Context, language string) error {
+	language, err := normalizeLanguage(language)
+	if err != nil {
+		return err
+	}
+	catalogs, err := s.configService.GetMessageCatalogs(ctx)
+	if err != nil {
+		return err
+	}
+	if _, exists := catalogs[language]; !exists {
+		return ngErrors.New(ngErrors.NotFoundErrorCode, fmt.Sprintf("no message catalog for %s", language), 404, nil)
+	}
+	catalogs = maps.Clone(catalogs)
+	delete(catalogs, language)
+	return s.configService.SetMessageCatalogs(ctx, catalogs)
+}
+
+// TranslateMessageCatalogs translates the messages of the source catalog into the target languages
+// with the translation provider of the tenant. Messages the target catalogs already have are kept
+// unless the request overwrites them.
+func (s *Service) TranslateMessageCatalogs(ctx context.Context, req *dto.MessageCatalogTranslationRequest) ([]dto.MessageCatalog, error) {
+	sourceLanguage, err := normalizeLanguage(req.GetSourceLanguage())
+	if err != nil {
+		return nil, err
+	}
+	if s.translationService == nil || !s.translationService.IsConfigured(ctx) {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "no translation provider is configured", 400, nil)
+	}
+
+	catalogs, err := s.configService.GetMessageCatalogs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	catalogs = maps.Clone(catalogs)
+	source := catalogs[sourceLanguage]
+	if sourceLanguage == DefaultLanguage {
+		source = maps.Clone(defaultMessages)
+		maps.Copy(source, catalogs[DefaultLanguage])
+	}
+	if len(source) == 0 {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("no messages to translate in %s", sourceLanguage), 400, nil)
+	}
+
+	provider := s.translationService.Provider(ctx)
+	var result []dto.MessageCatalog
+	for _, targetLanguage := range req.TargetLanguages {
+		targetLanguage, err := normalizeLanguage(targetLanguage)
+		if err != nil {
+			return nil, err
+		}
+		if targetLanguage == sourceLanguage {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("cannot translate %s to itself", sourceLanguage), 400, nil)
+		}
+
+		target := maps.Clone(catalogs[targetLanguage])
+		if target == nil {
+			target = map[string]string{}
+		}
+		var keys, texts []string
+		for _, key := range slices.Sorted(maps.Keys(source)) {
+			if _, exists := target[key]; exists && !req.GetOverwrite() {
+				continue
+			}
+			keys = append(keys, key)
+			texts = append(texts, source[key])
+		}
+
+		translated, errs := translation.TranslateTexts(ctx, provider, texts, sourceLanguage, targetLanguage)
+		for i, key := range keys {
+			if errs[i] != nil {
+				return nil, fmt.Errorf("failed to translate message %s to %s: %w", key, targetLanguage, errs[i])
+			}
+			target[key] = translated[i]
+		}
+		catalogs[targetLanguage] = target
+		result = append(result, dto.MessageCatalog{Language: targetLanguage, Messages: target})
+	}
+
+	if err := s.configService.SetMessageCatalogs(ctx, catalogs); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// normalizeLanguage returns the language code in lower case, English for none
+func normalizeLanguage(language string) (string, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" {
+		return DefaultLanguage, nil
+	}
+	if !languagePattern.MatchString(language) {
+		return "", ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("invalid language code %s", language), 400, nil)
+	}
+	return language, nil
+}
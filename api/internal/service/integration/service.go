@@ -0,0 +1,194 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	"github.com/arfis/waiting-room/internal/data/dto/integrationeventtype"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/queue"
+	appointmentService "github.com/arfis/waiting-room/internal/service/appointment"
+	kioskService "github.com/arfis/waiting-room/internal/service/kiosk"
+	queueService "github.com/arfis/waiting-room/internal/service/queue"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// integrationUser is the actor of the changes external systems make, in webhooks and history
+const integrationUser = "integration"
+
+// Service applies the queue events external systems push for the tenant in the context
+type Service struct {
+	queueService       *queueService.Service
+	kioskService       *kioskService.Service
+	appointmentService *appointmentService.Service
+}
+
+// New creates a new integration service
+func New(queueService *queueService.Service, kioskService *kioskService.Service, appointmentService *appointmentService.Service) *Service {
+	return &Service{
+		queueService:       queueService,
+		kioskService:       kioskService,
+		appointmentService: appointmentService,
+	}
+}
+
+// IngestIntegrationEvent applies an event of an external system: pre-registers an appointment,
+// checks in its patient on arrival, or cancels or changes the service of an appointment or entry
+func (s *Service) IngestIntegrationEvent(ctx context.Context, req *dto.IntegrationEvent) (*dto.IntegrationEventResult, error) {
+	log.Printf("[IntegrationService] %s event (appointment: '%s', entry: '%s')", req.Type, req.GetExternalId(), req.GetEntryId())
+	switch req.Type {
+	case integrationeventtype.PRE_REGISTER:
+		return s.preRegister(ctx, req)
+	case integrationeventtype.ARRIVED:
+		return s.arrived(ctx, req)
+	case integrationeventtype.CANCEL:
+		return s.cancel(ctx, req)
+	case integrationeventtype.CHANGE_SERVICE:
+		return s.changeService(ctx, req)
+	}
+	return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("unknown event type %s", req.Type), 400, nil)
+}
+
+// preRegister stores the appointment of the event, matched by its external ID
+func (s *Service) preRegister(ctx context.Context, req *dto.IntegrationEvent) (*dto.IntegrationEventResult, error) {
+	if req.Appointment == nil {
+		return nil, ngErrors.New(ngErrors.MissingRequiredFieldErrorCode, "appointment is required for PRE_REGISTER", 400, nil)
+	}
+	appointments, err := s.appointmentService.UpsertAppointments(ctx, []dto.Appointment{*req.Appointment})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.IntegrationEventResult{Type: req.Type, Appointment: &appointments[0]}, nil
+}
+
+// arrived queues the patient of a scheduled appointment
+func (s *Service) arrived(ctx context.Context, req *dto.IntegrationEvent) (*dto.IntegrationEventResult, error) {
+	appointment, err := s.appointment(ctx, req.GetExternalId())
+	if err != nil {
+		return nil, err
+	}
+	if appointment.Status == "CANCELLED" {
+		return nil, ngErrors.New(ngErrors.BusinessErrorCode, fmt.Sprintf("appointment %s is cancelled", appointment.ExternalID), 409, nil)
+	}
+
+	joined, err := s.kioskService.CheckInAppointment(ctx, appointment)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.IntegrationEventResult{
+		Type:         req.Type,
+		EntryId:      &joined.EntryID,
+		TicketNumber: &joined.TicketNumber,
+	}, nil
+}
+
+// cancel cancels the entry of the event, or its appointment when the patient has not arrived yet
+func (s *Service) cancel(ctx context.Context, req *dto.IntegrationEvent) (*dto.IntegrationEventResult, error) {
+	entryId, appointment, err := s.target(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if entryId == "" {
+		appointment.Status = "CANCELLED"
+		if err := s.appointmentService.SaveAppointment(ctx, appointment); err != nil {
+			log.Printf("[IntegrationService] Failed to cancel appointment %s: %v", appointment.ExternalID, err)
+			return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to cancel appointment", 500, nil)
+		}
+		return appointmentResult(req.Type, appointment), nil
+	}
+
+	entry, err := s.queueService.CancelEntry(ctx, entryId, integrationUser)
+	if err != nil {
+		return nil, err
+	}
+	return entryResult(req.Type, entry), nil
+}
+
+// changeService moves the entry of the event, or its appointment when the patient has not arrived
+// yet, to the service of the event
+func (s *Service) changeService(ctx context.Context, req *dto.IntegrationEvent) (*dto.IntegrationEventResult, error) {
+	if req.GetServiceId() == "" && req.GetServiceName() == "" {
+		return nil, ngErrors.New(ngErrors.MissingRequiredFieldErrorCode, "serviceId or serviceName is required for CHANGE_SERVICE", 400, nil)
+	}
+	if req.GetServiceDuration() < 0 {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "serviceDuration must not be negative", 400, nil)
+	}
+	durationSeconds := req.GetServiceDuration() * 60 // Convert minutes to seconds
+
+	entryId, appointment, err := s.target(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if entryId == "" {
+		appointment.ServiceID = req.GetServiceId()
+		appointment.ServiceName = req.GetServiceName()
+		if durationSeconds > 0 {
+			appointment.ServiceDurationSeconds = durationSeconds
+		}
+		if err := s.appointmentService.SaveAppointment(ctx, appointment); err != nil {
+			log.Printf("[IntegrationService] Failed to change the service of appointment %s: %v", appointment.ExternalID, err)
+			return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to change appointment", 500, nil)
+		}
+		return appointmentResult(req.Type, appointment), nil
+	}
+
+	serviceName := req.GetServiceName()
+	if serviceName == "" {
+		serviceName = req.GetServiceId()
+	}
+	entry, err := s.queueService.ChangeEntryService(ctx, entryId, req.GetServiceId(), serviceName, durationSeconds, integrationUser)
+	if err != nil {
+		return nil, err
+	}
+	return entryResult(req.Type, entry), nil
+}
+
+// target returns the entry an event is about: entryId, or the entry the appointment of externalId
+// was checked in for. Without one the scheduled appointment is returned instead.
+func (s *Service) target(ctx context.Context, req *dto.IntegrationEvent) (string, *types.Appointment, error) {
+	if req.GetEntryId() != "" {
+		return req.GetEntryId(), nil, nil
+	}
+	appointment, err := s.appointment(ctx, req.GetExternalId())
+	if err != nil {
+		return "", nil, err
+	}
+	switch {
+	case appointment.Status == "CHECKED_IN" && appointment.EntryID != "":
+		return appointment.EntryID, appointment, nil
+	case appointment.Status == "CANCELLED":
+		return "", nil, ngErrors.New(ngErrors.BusinessErrorCode, fmt.Sprintf("appointment %s is cancelled", appointment.ExternalID), 409, nil)
+	}
+	return "", appointment, nil
+}
+
+// appointment returns the appointment of the tenant with an external ID
+func (s *Service) appointment(ctx context.Context, externalID string) (*types.Appointment, error) {
+	if externalID == "" {
+		return nil, ngErrors.New(ngErrors.MissingRequiredFieldErrorCode, "externalId or entryId is required", 400, nil)
+	}
+	appointment, err := s.appointmentService.GetAppointment(ctx, externalID)
+	if err != nil {
+		log.Printf("[IntegrationService] Failed to get appointment %s: %v", externalID, err)
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to get appointment", 500, nil)
+	}
+	if appointment == nil {
+		return nil, ngErrors.New(ngErrors.NotFoundErrorCode, fmt.Sprintf("appointment %s not found", externalID), 404, nil)
+	}
+	return appointment, nil
+}
+
+func appointmentResult(eventType integrationeventtype.IntegrationEventType, appointment *types.Appointment) *dto.IntegrationEventResult {
+	converted := appointmentService.ConvertAppointmentToDTO(appointment)
+	return &dto.IntegrationEventResult{Type: eventType, Appointment: &converted}
+}
+
+func entryResult(eventType integrationeventtype.IntegrationEventType, entry *queue.Entry) *dto.IntegrationEventResult {
+	return &dto.IntegrationEventResult{
+		Type:         eventType,
+		EntryId:      &entry.ID,
+		TicketNumber: &entry.TicketNumber,
+	}
+}
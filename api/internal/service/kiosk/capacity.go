@@ -0,0 +1,102 @@
+package kiosk
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/queue"
+)
+
+// queueFullMessage is the text the kiosk shows when a room is full, with and without an overflow room
+type queueFullMessage struct {
+	full       string
+	suggestion string
+}
+
+// queueFullMessages holds the texts by kiosk language; other languages are translated from English
+var queueFullMessages = map[string]queueFullMessage{
+	"en": {
+		full:       "The queue for %s is full. Please ask at the reception.",
+		suggestion: "The queue for %s is full. Please go to %s.",
+	},
+	"sk": {
+		full:       "Rad pre %s je plný. Obráťte sa, prosím, na recepciu.",
+		suggestion: "Rad pre %s je plný. Prejdite, prosím, do %s.",
+	},
+}
+
+// queueFullError is the QUEUE_FULL error of a full room in the kiosk language. Its values carry the
+// room, its limit and the suggested overflow room, if any.
+func (s *Service) queueFullError(ctx context.Context, fullErr *queue.QueueFullError, language string) *ngErrors.ApplicationError {
+	language = strings.ToLower(language)
+	if language == "" {
+		language = "en"
+	}
+
+	roomNames := s.roomNames(ctx)
+	text := s.localizedQueueFullText(ctx, language, roomName(roomNames, fullErr.RoomID), roomName(roomNames, fullErr.SuggestedRoomID), fullErr.SuggestedRoomID != "")
+
+	values := ngErrors.ErrorValues{
+		"roomId":     fullErr.RoomID,
+		"maxWaiting": fullErr.MaxWaiting,
+	}
+	if fullErr.SuggestedRoomID != "" {
+		values["suggestedRoomId"] = fullErr.SuggestedRoomID
+		values["suggestedRoomName"] = roomName(roomNames, fullErr.SuggestedRoomID)
+	}
+
+	log.Printf("[KioskService] Rejected swipe: %v (suggested room: '%s')", fullErr, fullErr.SuggestedRoomID)
+	return ngErrors.New(ngErrors.QueueFullCode, text, 409, values)
+}
+
+// localizedQueueFullText formats the queue full message in language, falling back to a
+// translation of the English text by the provider of the tenant and then to English
+func (s *Service) localizedQueueFullText(ctx context.Context, language, room, suggestedRoom string, withSuggestion bool) string {
+	format := func(message queueFullMessage) string {
+		if withSuggestion {
+			return fmt.Sprintf(message.suggestion, room, suggestedRoom)
+		}
+		return fmt.Sprintf(message.full, room)
+	}
+
+	if message, ok := queueFullMessages[language]; ok {
+		return format(message)
+	}
+	text := format(queueFullMessages["en"])
+	if s.translationService.IsConfigured(ctx) {
+		translated, err := s.translationService.Translate(ctx, text, "en", language)
+		if err == nil {
+			return translated
+		}
+		log.Printf("[KioskService] Failed to translate queue full message to %s: %v", language, err)
+	}
+	return text
+}
+
+// roomNames returns the configured names of the rooms by ID
+func (s *Service) roomNames(ctx context.Context) map[string]string {
+	names := make(map[string]string)
+	if s.configService == nil {
+		return names
+	}
+	rooms, err := s.configService.GetRoomsConfig(ctx)
+	if err != nil {
+		log.Printf("[KioskService] Failed to get room names: %v", err)
+		return names
+	}
+	for _, room := range rooms {
+		names[room.ID] = room.Name
+	}
+	return names
+}
+
+// roomName returns the name of a room, or its ID when it has none
+func roomName(names map[string]string, roomId string) string {
+	if name := names[roomId]; name != "" {
+		return name
+	}
+	return roomId
+}
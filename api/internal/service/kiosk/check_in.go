@@ -0,0 +1,93 @@
+package kiosk
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/queue"
+	"github.com/arfis/waiting-room/internal/service"
+	"github.com/arfis/waiting-room/internal/service/webhook"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// CheckInAppointment queues the patient of a scheduled appointment without a card swipe, when an
+// external system reports their arrival. The entry gets the room, service, duration, time and
+// symbols of the appointment; a repeated report returns the ticket of the first one.
+func (s *Service) CheckInAppointment(ctx context.Context, appointment *types.Appointment) (*dto.JoinResult, error) {
+	if appointment.RoomID == "" {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "appointment has no room", 400, nil)
+	}
+	roomId, err := s.intakeRoom(ctx, appointment.RoomID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The appointment identifies a retry, whatever room its patient joined
+	arrivalKeys := s.queueService.SwipeKeys(appointment.Identifier, "", "appointment:"+appointment.ID, time.Now())
+	approximateDurationSeconds := appointment.ServiceDurationSeconds
+	if approximateDurationSeconds == 0 {
+		approximateDurationSeconds = 300 // Default fallback: 5 minutes = 300 seconds
+	}
+	appointmentTime := appointment.AppointmentTime
+	cardData := queue.CardData{
+		IDNumber: appointment.Identifier,
+		Source:   "integration",
+	}
+
+	entry, duplicate, err := s.queueService.JoinOnce(ctx, arrivalKeys, func() (*queue.Entry, error) {
+		return s.queueService.CreateEntry(ctx, roomId, cardData, approximateDurationSeconds, appointment.ServiceName,
+			appointment.Symbols, &appointmentTime, nil, nil, arrivalKeys[0])
+	})
+	if err != nil {
+		var fullErr *queue.QueueFullError
+		if errors.As(err, &fullErr) {
+			return nil, s.queueFullError(ctx, fullErr, "")
+		}
+		log.Printf("[KioskService] Failed to create entry for appointment %s: %v", appointment.ExternalID, err)
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to create queue entry", 500, nil)
+	}
+	if duplicate {
+		return duplicateJoinResult(entry), nil
+	}
+
+	if err := s.appointmentService.MarkCheckedIn(ctx, appointment.ID, entry.ID); err != nil {
+		log.Printf("[KioskService] Failed to mark appointment %s as checked in: %v", appointment.ID, err)
+	}
+	if _, err := s.queueService.StartPathway(ctx, entry, "", appointment.ServiceID); err != nil {
+		log.Printf("[KioskService] Failed to start pathway for entry %s: %v", entry.ID, err)
+	}
+
+	if s.broadcastFunc != nil {
+		tenantID := service.GetTenantID(ctx)
+		s.broadcastFunc(roomId, tenantID)
+		if entry.WaitingRoomID != roomId {
+			s.broadcastFunc(entry.WaitingRoomID, tenantID)
+		}
+	}
+
+	if s.webhookService != nil {
+		created := webhook.EntryPayload(webhook.EventEntryCreated, entry)
+		created.AdditionalData = map[string]interface{}{
+			"appointmentId": appointment.ExternalID,
+		}
+		s.webhookService.Enqueue(ctx, created)
+	}
+
+	log.Printf("[KioskService] Entry %s checked in for appointment %s on arrival", entry.ID, appointment.ExternalID)
+
+	result := &dto.JoinResult{
+		EntryID:      entry.ID,
+		TicketNumber: entry.TicketNumber,
+		QrUrl:        "http://localhost:4204/q/" + entry.QRToken,
+	}
+	durationMinutes := approximateDurationSeconds / 60
+	result.ServiceDuration = &durationMinutes
+	if entry.ServiceName != "" {
+		result.ServiceName = &entry.ServiceName
+	}
+	return result, nil
+}
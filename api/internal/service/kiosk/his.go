@@ -0,0 +1,86 @@
+package kiosk
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/arfis/waiting-room/internal/his"
+	"github.com/arfis/waiting-room/internal/queue"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// hisProvider returns the HIS provider of the tenant and its external API configuration, the
+// generic REST provider of the environment variables when the configuration can't be loaded,
+// and nil when there is none
+func (s *Service) hisProvider(ctx context.Context) (his.Provider, *types.ExternalAPIConfig) {
+	apiConfig, err := s.configService.GetExternalAPIConfig(ctx)
+	if err != nil {
+		externalAPIURL := s.config.GetExternalAPIUserServicesURL()
+		if externalAPIURL == "" {
+			return nil, nil
+		}
+		log.Printf("[KioskService] Using fallback HIS config - URL: %s, Error: %v", externalAPIURL, err)
+		return his.NewRESTProvider(&types.ExternalAPIConfig{
+			AppointmentServicesURL: externalAPIURL,
+			TimeoutSeconds:         s.config.GetExternalAPITimeout(),
+		}), nil
+	}
+	if apiConfig == nil {
+		return nil, nil
+	}
+
+	provider, err := his.New(apiConfig)
+	if err != nil {
+		log.Printf("[KioskService] Failed to create HIS provider %q: %v", apiConfig.HISProvider, err)
+		return nil, apiConfig
+	}
+	return provider, apiConfig
+}
+
+// patientFromHIS completes the age and priority symbols the swipe didn't carry with the
+// demographics of the HIS; the swipe proceeds without them when the HIS has none
+func (s *Service) patientFromHIS(ctx context.Context, provider his.Provider, cardData queue.CardData, age *int, symbols []string) (*int, []string) {
+	patient, err := provider.GetPatient(ctx, cardData.IDNumber)
+	if err != nil {
+		if !errors.Is(err, his.ErrNotSupported) {
+			log.Printf("[KioskService] Failed to get patient from HIS: %v", err)
+		}
+		return age, symbols
+	}
+	if patient == nil {
+		return age, symbols
+	}
+
+	if age == nil && patient.Age != nil {
+		age = patient.Age
+	}
+	if len(symbols) == 0 && len(patient.Symbols) > 0 {
+		normalized, err := s.queueService.NormalizeSymbols(ctx, patient.Symbols)
+		if err != nil {
+			log.Printf("[KioskService] Ignoring HIS symbols of patient: %v", err)
+		} else {
+			symbols = normalized
+		}
+	}
+	return age, symbols
+}
+
+// notifyVisit tells the HIS about the new entry without holding up the response
+func (s *Service) notifyVisit(ctx context.Context, provider his.Provider, entry *queue.Entry, identifier, serviceID string) {
+	visit := his.Visit{
+		EntryID:      entry.ID,
+		TicketNumber: entry.TicketNumber,
+		Identifier:   identifier,
+		RoomID:       entry.WaitingRoomID,
+		ServiceID:    serviceID,
+		ServiceName:  entry.ServiceName,
+		ArrivedAt:    entry.CreatedAt,
+	}
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if err := provider.NotifyVisit(ctx, visit); err != nil && !errors.Is(err, his.ErrNotSupported) {
+			log.Printf("[KioskService] Failed to notify HIS of entry %s: %v", entry.ID, err)
+		}
+	}()
+}
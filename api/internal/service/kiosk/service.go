@@ -3,21 +3,24 @@ package kiosk
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"strings"
 	"time"
 
+	"github.com/arfis/waiting-room/internal/cardreader"
 	"github.com/arfis/waiting-room/internal/config"
 	"github.com/arfis/waiting-room/internal/data/dto"
 	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/his"
 	"github.com/arfis/waiting-room/internal/queue"
 	"github.com/arfis/waiting-room/internal/service"
+	appointmentService "github.com/arfis/waiting-room/internal/service/appointment"
 	configService "github.com/arfis/waiting-room/internal/service/config"
 	"github.com/arfis/waiting-room/internal/service/translation"
 	"github.com/arfis/waiting-room/internal/service/webhook"
+	"github.com/arfis/waiting-room/internal/types"
 )
 
 type Service struct {
@@ -26,18 +29,28 @@ type Service struct {
 	config             *config.Config
 	configService      *configService.Service
 	webhookService     *webhook.Service
-	translationService *translation.DeepLTranslationService
+	translationService *translation.Service
+	appointmentService *appointmentService.Service
+	servicesCache      *servicesCache
 }
 
-func New(queueService *queue.WaitingQueue, broadcastFunc func(string, string), config *config.Config, configService *configService.Service, webhookService *webhook.Service, translationService *translation.DeepLTranslationService) *Service {
-	return &Service{
+func New(queueService *queue.WaitingQueue, broadcastFunc func(string, string), config *config.Config, configService *configService.Service, webhookService *webhook.Service, translationService *translation.Service, appointmentService *appointmentService.Service) *Service {
+	s := &Service{
 		queueService:       queueService,
 		broadcastFunc:      broadcastFunc,
 		config:             config,
 		configService:      configService,
 		webhookService:     webhookService,
 		translationService: translationService,
+		appointmentService: appointmentService,
+		servicesCache:      newServicesCache(config.GetExternalAPIServicesCacheTTL()),
 	}
+	// Services of another HIS configuration or translation glossary are stale
+	configService.OnExternalAPIConfigChange(s.servicesCache.invalidate)
+	if translationService != nil {
+		translationService.OnGlossaryChange(s.servicesCache.invalidate)
+	}
+	return s
 }
 
 func (s *Service) SetBroadcastFunc(f func(string, string)) {
@@ -45,11 +58,50 @@ func (s *Service) SetBroadcastFunc(f func(string, string)) {
 }
 
 func (s *Service) SwipeCard(ctx context.Context, roomId string, req *dto.SwipeRequest) (*dto.JoinResult, error) {
-	// Create CardData from the raw card data
+	roomId, err := s.intakeRoom(ctx, roomId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create CardData from the raw card data, or from the reader's sealed CardData block
 	cardData := queue.CardData{
-		IDNumber: *req.IdCardRaw,
+		IDNumber: req.GetIdCardRaw(),
 		Source:   "card-reader",
 	}
+	if req.EncryptedCardData != nil {
+		decrypted, err := s.openCardData(ctx, req.EncryptedCardData)
+		if err != nil {
+			return nil, err
+		}
+		cardData = *decrypted
+	}
+	if cardData.IDNumber == "" {
+		// insurance cards identify the patient by insurance number
+		cardData.IDNumber = cardData.InsuranceNumber
+	}
+	if cardData.IDNumber == "" {
+		return nil, ngErrors.New(ngErrors.MissingRequiredFieldErrorCode, "idCardRaw or encryptedCardData is required", 400, nil)
+	}
+
+	// A repeated swipe (double-tap, kiosk retry) returns the ticket the first one created
+	swipeKeys := s.queueService.SwipeKeys(cardData.IDNumber, roomId, req.GetIdempotencyKey(), time.Now())
+	if existing, err := s.queueService.FindDuplicateSwipe(ctx, swipeKeys); err != nil {
+		log.Printf("[KioskService] Failed to check for a repeated swipe: %v", err)
+	} else if existing != nil {
+		return duplicateJoinResult(existing), nil
+	}
+
+	// A pre-registered appointment supplies the service and duration the patient did not select
+	appointment := s.findAppointment(ctx, cardData)
+	if appointment != nil {
+		if req.GetServiceId() == "" && appointment.ServiceID != "" {
+			req.ServiceId = &appointment.ServiceID
+		}
+		if req.GetServiceDuration() == 0 && appointment.ServiceDurationSeconds > 0 {
+			durationMinutes := appointment.ServiceDurationSeconds / 60
+			req.ServiceDuration = &durationMinutes
+		}
+	}
 
 	// Use service duration from request, convert from minutes to seconds
 	// Fallback to 5 minutes (300 seconds) if not provided
@@ -65,7 +117,7 @@ func (s *Service) SwipeCard(ctx context.Context, roomId string, req *dto.SwipeRe
 		defaultLang := "en"
 		services, err := s.GetUserServices(ctx, cardData.IDNumber, &defaultLang)
 		if err == nil {
-			for _, service := range services {
+			for _, service := range services.Services {
 				if service.Id == *req.ServiceId {
 					serviceName = service.ServiceName
 					break
@@ -84,9 +136,13 @@ func (s *Service) SwipeCard(ctx context.Context, roomId string, req *dto.SwipeRe
 	if req.PatientInformation != nil {
 		patientInfo := req.PatientInformation
 
-		// Extract symbols
+		// Extract symbols; a typo would otherwise silently lose the patient's priority
 		if patientInfo.Symbols != nil && len(patientInfo.Symbols) > 0 {
-			symbols = patientInfo.Symbols
+			symbols, err = s.queueService.NormalizeSymbols(ctx, patientInfo.Symbols)
+			if err != nil {
+				log.Printf("[KioskService] Rejected swipe: %v", err)
+				return nil, ngErrors.New(ngErrors.ValidationErrorCode, err.Error(), 400, ngErrors.ErrorValues{"symbols": patientInfo.Symbols})
+			}
 		}
 
 		// Extract appointment time using the helper method (handles FlexibleTime conversion)
@@ -104,12 +160,63 @@ func (s *Service) SwipeCard(ctx context.Context, roomId string, req *dto.SwipeRe
 		}
 	}
 
+	// The appointment supplies the appointment time and adds its priority symbols
+	if appointment != nil {
+		if appointmentTimePtr == nil {
+			appointmentTime := appointment.AppointmentTime
+			appointmentTimePtr = &appointmentTime
+		}
+		for _, symbol := range appointment.Symbols {
+			if !containsString(symbols, symbol) {
+				symbols = append(symbols, symbol)
+			}
+		}
+		if serviceName == "" {
+			serviceName = appointment.ServiceName
+		}
+	}
+
+	// The HIS of the tenant completes the age and symbols the swipe didn't carry
+	provider, _ := s.hisProvider(ctx)
+	if provider != nil && (agePtr == nil || len(symbols) == 0) {
+		agePtr, symbols = s.patientFromHIS(ctx, provider, cardData, agePtr, symbols)
+	}
+
 	// Create queue entry using the existing queue service (pass context for tenant info + priority metadata)
-	entry, err := s.queueService.CreateEntry(ctx, roomId, cardData, approximateDurationSeconds, serviceName,
-		symbols, appointmentTimePtr, agePtr, manualOverridePtr)
+	idempotencyKey := ""
+	if len(swipeKeys) > 0 {
+		idempotencyKey = swipeKeys[0]
+	}
+	entry, duplicate, err := s.queueService.JoinOnce(ctx, swipeKeys, func() (*queue.Entry, error) {
+		return s.queueService.CreateEntry(ctx, roomId, cardData, approximateDurationSeconds, serviceName,
+			symbols, appointmentTimePtr, agePtr, manualOverridePtr, idempotencyKey)
+	})
 	if err != nil {
+		var fullErr *queue.QueueFullError
+		if errors.As(err, &fullErr) {
+			return nil, s.queueFullError(ctx, fullErr, req.GetLanguage())
+		}
 		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to create queue entry", 500, nil)
 	}
+	if duplicate {
+		return duplicateJoinResult(entry), nil
+	}
+
+	if appointment != nil {
+		if err := s.appointmentService.MarkCheckedIn(ctx, appointment.ID, entry.ID); err != nil {
+			log.Printf("[KioskService] Failed to mark appointment %s as checked in: %v", appointment.ID, err)
+		}
+		log.Printf("[KioskService] Entry %s checked in for appointment %s at %s", entry.ID, appointment.ExternalID, appointment.AppointmentTime.Format(time.RFC3339))
+	}
+
+	if provider != nil {
+		s.notifyVisit(ctx, provider, entry, cardData.IDNumber, req.GetServiceId())
+	}
+
+	// Put the entry on its visit pathway; it then waits in the room of the first stage
+	if _, err := s.queueService.StartPathway(ctx, entry, req.GetPathwayId(), req.GetServiceId()); err != nil {
+		log.Printf("[KioskService] Failed to start pathway for entry %s: %v", entry.ID, err)
+	}
 
 	// Generate QR URL
 	qrUrl := "http://localhost:4204/q/" + entry.QRToken
@@ -123,15 +230,20 @@ func (s *Service) SwipeCard(ctx context.Context, roomId string, req *dto.SwipeRe
 			log.Printf("[KioskService] WARNING: tenantID is empty, broadcasting to all clients")
 		}
 		s.broadcastFunc(roomId, tenantID)
+		if entry.WaitingRoomID != roomId {
+			s.broadcastFunc(entry.WaitingRoomID, tenantID)
+		}
 	}
 
-	// Send webhook notification for service selected (if service was selected)
-	if s.webhookService != nil && req.ServiceId != nil && *req.ServiceId != "" {
-		go func() {
-			if err := s.webhookService.SendServiceSelectedWebhook(ctx, entry.ID, *req.ServiceId, roomId, "", cardData.IDNumber); err != nil {
-				log.Printf("Failed to send webhook notification for service selected: %v", err)
-			}
-		}()
+	// Send webhook notifications for the new entry and the service selected (if one was selected)
+	if s.webhookService != nil {
+		s.webhookService.Enqueue(ctx, webhook.EntryPayload(webhook.EventEntryCreated, entry))
+		if req.ServiceId != nil && *req.ServiceId != "" {
+			selected := webhook.EntryPayload(webhook.EventEntryServiceSelected, entry)
+			selected.ServiceID = *req.ServiceId
+			selected.UserID = cardData.IDNumber
+			s.webhookService.Enqueue(ctx, selected)
+		}
 	}
 
 	// Return the join result
@@ -154,7 +266,7 @@ func (s *Service) SwipeCard(ctx context.Context, roomId string, req *dto.SwipeRe
 		defaultLang := "en"
 		services, err := s.GetUserServices(ctx, cardData.IDNumber, &defaultLang)
 		if err == nil {
-			for _, service := range services {
+			for _, service := range services.Services {
 				if service.Id == *req.ServiceId {
 					result.ServiceName = &service.ServiceName
 					break
@@ -171,66 +283,162 @@ func (s *Service) SwipeCard(ctx context.Context, roomId string, req *dto.SwipeRe
 	return result, nil
 }
 
-func (s *Service) GetUserServices(ctx context.Context, identifier string, language *string) ([]dto.UserService, error) {
+// findAppointment returns the scheduled appointment the card holder checks in for, matched by ID
+// or insurance number within the check-in window
+func (s *Service) findAppointment(ctx context.Context, cardData queue.CardData) *types.Appointment {
+	if s.appointmentService == nil {
+		return nil
+	}
+	for _, identifier := range []string{cardData.IDNumber, cardData.InsuranceNumber} {
+		appointment, err := s.appointmentService.FindCheckInAppointment(ctx, identifier, time.Now())
+		if err != nil {
+			log.Printf("[KioskService] Failed to look up appointments: %v", err)
+			return nil
+		}
+		if appointment != nil {
+			return appointment
+		}
+	}
+	return nil
+}
+
+// intakeRoom returns the room a new ticket for roomId joins. A paused room redirects new
+// tickets or turns them away; patients already waiting stay. A deactivated tenant takes none.
+func (s *Service) intakeRoom(ctx context.Context, roomId string) (string, error) {
+	if tenant := service.GetTenant(ctx); tenant.IsDeactivated() {
+		return "", ngErrors.TenantDeactivated(tenant.GetFullTenantID())
+	}
+	intakeRoomId, err := s.queueService.IntakeRoom(ctx, roomId)
+	if err != nil {
+		if errors.Is(err, queue.ErrQueuePaused) {
+			return "", ngErrors.QueuePaused(strings.TrimPrefix(err.Error(), queue.ErrQueuePaused.Error()+": "))
+		}
+		return "", ngErrors.New(ngErrors.InternalServerErrorCode, "failed to check room state", 500, nil)
+	}
+	return intakeRoomId, nil
+}
+
+// duplicateJoinResult is the join result of a repeated swipe: the ticket of the existing entry
+func duplicateJoinResult(entry *queue.Entry) *dto.JoinResult {
+	duplicate := true
+	result := &dto.JoinResult{
+		EntryID:      entry.ID,
+		TicketNumber: entry.TicketNumber,
+		QrUrl:        "http://localhost:4204/q/" + entry.QRToken,
+		Duplicate:    &duplicate,
+	}
+	if entry.VisitorCode != "" {
+		result.VisitorCode = &entry.VisitorCode
+	}
+	if entry.ApproximateDurationSeconds > 0 {
+		durationMinutes := entry.ApproximateDurationSeconds / 60
+		result.ServiceDuration = &durationMinutes
+	}
+	if entry.ServiceName != "" {
+		result.ServiceName = &entry.ServiceName
+	}
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUserServices returns the services of the patient, cached per language for the services cache TTL
+func (s *Service) GetUserServices(ctx context.Context, identifier string, language *string) (*dto.UserServicesResp, error) {
 	// Default language to English if not provided
 	lang := "en"
 	if language != nil {
 		lang = *language
 	}
 
-	// Get external API configuration from cache
-	apiConfig, err := s.configService.GetExternalAPIConfig(ctx)
-	if err != nil {
-		// Fallback to environment variables if cache fails
-		externalAPIURL := s.config.GetExternalAPIUserServicesURL()
-		if externalAPIURL == "" {
-			log.Printf("No external API URL configured for user services (fallback)")
-			return []dto.UserService{}, nil // Return empty list if not configured
-		}
-		timeoutSeconds := s.config.GetExternalAPITimeout()
-		log.Printf("Using fallback config - URL: %s, Timeout: %d, Error: %v", externalAPIURL, timeoutSeconds, err)
-		return s.makeExternalAPICall(ctx, externalAPIURL, timeoutSeconds, nil, identifier, lang, true, "")
+	key := servicesCacheKey{tenantID: service.GetTenantID(ctx), identifier: identifier, language: lang}
+	if cached, ok := s.servicesCache.get(key); ok {
+		return cached, nil
+	}
+	resp, err := s.loadUserServices(ctx, identifier, lang)
+	if err == nil {
+		s.servicesCache.set(key, resp)
 	}
+	return resp, err
+}
+
+func (s *Service) loadUserServices(ctx context.Context, identifier, lang string) (*dto.UserServicesResp, error) {
 
-	// Check if config is nil or appointment services URL is not configured
-	if apiConfig == nil || apiConfig.AppointmentServicesURL == "" {
-		log.Printf("Appointment services URL not configured for user services")
-		return []dto.UserService{}, nil // Return empty list if not configured
+	provider, apiConfig := s.hisProvider(ctx)
+	if provider == nil {
+		log.Printf("No HIS configured for user services")
+		return &dto.UserServicesResp{Services: []dto.UserService{}}, nil // Return empty list if not configured
 	}
 
-	// Replace ${identifier} placeholder with actual identifier value
-	actualURL := s.replaceIdentifierInURL(apiConfig.AppointmentServicesURL, identifier)
+	services, err := provider.GetServices(ctx, identifier, lang)
+	if err != nil {
+		// Proceed without services rather than failing the kiosk, which shows that the HIS is down
+		log.Printf("Failed to get user services from HIS: %v", err)
+		return &dto.UserServicesResp{Services: []dto.UserService{}, Degraded: true}, nil
+	}
 
-	timeoutSeconds := apiConfig.TimeoutSeconds
-	if timeoutSeconds == 0 {
-		timeoutSeconds = 10 // Default timeout
+	// Apply the translation provider if configured; appointment services are returned directly
+	if apiConfig != nil && apiConfig.UseDeepLTranslation != nil && *apiConfig.UseDeepLTranslation {
+		if s.translationService == nil {
+			log.Printf("WARNING: Translation is enabled but translation service is nil")
+		} else {
+			// Appointment services are typically in English, so use "en" as source
+			log.Printf("Attempting to translate %d appointment services from en to %s", len(services), lang)
+			translatedServices, err := s.translateServices(ctx, services, "en", lang)
+			if err != nil {
+				log.Printf("Failed to translate appointment services: %v", err)
+				// Return original services if translation fails
+			} else {
+				services = translatedServices
+			}
+		}
 	}
 
-	return s.makeExternalAPICall(ctx, actualURL, timeoutSeconds, apiConfig.Headers, identifier, lang, true, "")
+	return &dto.UserServicesResp{Services: services}, nil
 }
 
 // GetGenericServices returns generic services available
-func (s *Service) GetGenericServices(ctx context.Context, language *string) ([]dto.UserService, error) {
+func (s *Service) GetGenericServices(ctx context.Context, language *string) (*dto.UserServicesResp, error) {
 	// Default language to English if not provided
 	lang := "en"
 	if language != nil {
 		lang = *language
 	}
 
+	key := servicesCacheKey{tenantID: service.GetTenantID(ctx), generic: true, language: lang}
+	if cached, ok := s.servicesCache.get(key); ok {
+		return cached, nil
+	}
+	resp, err := s.loadGenericServices(ctx, lang)
+	if err == nil {
+		s.servicesCache.set(key, resp)
+	}
+	return resp, err
+}
+
+func (s *Service) loadGenericServices(ctx context.Context, lang string) (*dto.UserServicesResp, error) {
+
 	// Get external API configuration from cache
 	apiConfig, err := s.configService.GetExternalAPIConfig(ctx)
 	if err != nil {
 		log.Printf("Failed to get external API config for generic services: %v", err)
-		return []dto.UserService{}, nil // Return empty list if config fails
+		return &dto.UserServicesResp{Services: []dto.UserService{}}, nil // Return empty list if config fails
 	}
 
 	// If no config found, return empty list
 	if apiConfig == nil {
 		log.Printf("No external API config found for generic services")
-		return []dto.UserService{}, nil
+		return &dto.UserServicesResp{Services: []dto.UserService{}}, nil
 	}
 
-	var services []dto.UserService
+	services := []dto.UserService{}
+	degraded := false
 	var adminCreatedServices []dto.UserService
 
 	// First, try to get admin-created generic services
@@ -254,24 +462,16 @@ func (s *Service) GetGenericServices(ctx context.Context, language *string) ([]d
 	if apiConfig.GenericServicesURL != "" {
 		log.Printf("Fetching generic services from external URL: %s", apiConfig.GenericServicesURL)
 
-		var actualURL string
+		// For POST requests, replace placeholders in the body (no servicePointId needed)
 		var postBody string
-
-		if apiConfig.GenericServicesHttpMethod != nil && *apiConfig.GenericServicesHttpMethod == "POST" {
-			// For POST requests, use the URL as-is and prepare the body
-			actualURL = apiConfig.GenericServicesURL
-			if apiConfig.GenericServicesPostBody != "" {
-				// Replace placeholders in POST body (no servicePointId needed)
-				postBody = strings.ReplaceAll(apiConfig.GenericServicesPostBody, "${language}", lang)
-			}
-		} else {
-			// For GET requests, use the URL as-is (no servicePointId replacement needed)
-			actualURL = apiConfig.GenericServicesURL
+		if apiConfig.GenericServicesHttpMethod != nil && *apiConfig.GenericServicesHttpMethod == "POST" && apiConfig.GenericServicesPostBody != "" {
+			postBody = strings.ReplaceAll(apiConfig.GenericServicesPostBody, "${language}", lang)
 		}
 
-		externalServices, err := s.makeExternalAPICall(ctx, actualURL, apiConfig.TimeoutSeconds, apiConfig.Headers, "", lang, false, postBody)
+		externalServices, err := his.NewRESTProvider(apiConfig).GetGenericServices(ctx, lang, postBody)
 		if err != nil {
 			log.Printf("Failed to fetch external generic services: %v", err)
+			degraded = true
 		} else {
 			// Append external services to admin-created services
 			log.Printf("Fetched %d external generic services, appending to %d admin-created services", len(externalServices), len(services))
@@ -283,15 +483,15 @@ func (s *Service) GetGenericServices(ctx context.Context, language *string) ([]d
 	// If no admin-created services and no external URL, return empty list
 	if len(apiConfig.GenericServices) == 0 && apiConfig.GenericServicesURL == "" {
 		log.Printf("No generic services configured (neither admin-created nor external URL)")
-		return []dto.UserService{}, nil
+		return &dto.UserServicesResp{Services: []dto.UserService{}}, nil
 	}
 
-	// Apply DeepL translation if configured for all generic services (both admin-created and external)
+	// Apply the translation provider if configured for all generic services (both admin-created and external)
 	if apiConfig != nil && apiConfig.UseDeepLTranslation != nil && *apiConfig.UseDeepLTranslation {
 		log.Printf("DeepL translation is enabled for generic services (admin-created + external)")
 
 		if s.translationService == nil {
-			log.Printf("WARNING: DeepL translation is enabled but translation service is nil")
+			log.Printf("WARNING: Translation is enabled but translation service is nil")
 		} else {
 			// Always attempt translation if we have external services (they might be in Slovak)
 			// or if target language is not English
@@ -351,7 +551,7 @@ func (s *Service) GetGenericServices(ctx context.Context, language *string) ([]d
 					if len(adminServices) > 0 {
 						if lang != "en" {
 							log.Printf("Translating %d admin-created services from en to %s", len(adminServices), lang)
-							translatedAdmin, err := s.translateServices(adminServices, "en", lang)
+							translatedAdmin, err := s.translateServices(ctx, adminServices, "en", lang)
 							if err != nil {
 								log.Printf("Failed to translate admin-created services: %v (keeping original)", err)
 								allTranslatedServices = append(allTranslatedServices, adminServices...)
@@ -369,7 +569,7 @@ func (s *Service) GetGenericServices(ctx context.Context, language *string) ([]d
 						// Only translate if source and target are different
 						if externalSourceLanguage != lang {
 							log.Printf("Translating %d external services from %s to %s", len(externalServices), externalSourceLanguage, lang)
-							translatedExternal, err := s.translateServices(externalServices, externalSourceLanguage, lang)
+							translatedExternal, err := s.translateServices(ctx, externalServices, externalSourceLanguage, lang)
 							if err != nil {
 								log.Printf("Failed to translate external services: %v (keeping original)", err)
 								allTranslatedServices = append(allTranslatedServices, externalServices...)
@@ -394,44 +594,12 @@ func (s *Service) GetGenericServices(ctx context.Context, language *string) ([]d
 	}
 
 	log.Printf("Returning %d total generic services", len(services))
-	return services, nil
+	return &dto.UserServicesResp{Services: services, Degraded: degraded}, nil
 }
 
 // GetAppointmentServices returns appointment-specific services for a user
-func (s *Service) GetAppointmentServices(ctx context.Context, identifier string, language *string) ([]dto.UserService, error) {
-	// Default language to English if not provided
-	lang := "en"
-	if language != nil {
-		lang = *language
-	}
-
-	// Get external API configuration from cache
-	apiConfig, err := s.configService.GetExternalAPIConfig(ctx)
-	if err != nil {
-		log.Printf("Failed to get external API config for appointment services: %v", err)
-		return []dto.UserService{}, nil // Return empty list if config fails
-	}
-
-	// Check if config is nil or appointment services URL is not configured
-	if apiConfig == nil || apiConfig.AppointmentServicesURL == "" {
-		log.Printf("Appointment services URL not configured")
-		return []dto.UserService{}, nil // Return empty list if not configured
-	}
-
-	// Replace ${identifier} placeholder with actual identifier value
-	actualURL := s.replaceIdentifierInURL(apiConfig.AppointmentServicesURL, identifier)
-
-	timeoutSeconds := apiConfig.TimeoutSeconds
-	if timeoutSeconds == 0 {
-		timeoutSeconds = 10 // Default timeout
-	}
-
-	return s.makeExternalAPICall(ctx, actualURL, timeoutSeconds, apiConfig.Headers, identifier, lang, true, "")
-}
-
-// replaceIdentifierInURL replaces ${identifier} placeholder with the actual identifier value
-func (s *Service) replaceIdentifierInURL(urlTemplate, identifier string) string {
-	return strings.ReplaceAll(urlTemplate, "${identifier}", identifier)
+func (s *Service) GetAppointmentServices(ctx context.Context, identifier string, language *string) (*dto.UserServicesResp, error) {
+	return s.GetUserServices(ctx, identifier, language)
 }
 
 // replaceServicePointIdInURL replaces ${servicePointId} placeholder with the actual service point ID
@@ -449,222 +617,19 @@ func (s *Service) GetDefaultServicePoint(ctx context.Context, roomId string) (*s
 	return &servicePointId, nil
 }
 
-// makeExternalAPICall makes the actual HTTP call to the external API
-func (s *Service) makeExternalAPICall(ctx context.Context, externalAPIURL string, timeoutSeconds int, headers map[string]string, identifier string, language string, isAppointmentServices bool, postBody string) ([]dto.UserService, error) {
-	// Get external API configuration to check multilingual settings
-	apiConfig, err := s.configService.GetExternalAPIConfig(ctx)
-	if err != nil {
-		log.Printf("Failed to get external API config: %v", err)
-		// Continue with basic call if config fails
-	}
-
-	// Determine HTTP method
-	httpMethod := "GET"
-	if apiConfig != nil {
-		if isAppointmentServices && apiConfig.AppointmentServicesHttpMethod != nil {
-			httpMethod = *apiConfig.AppointmentServicesHttpMethod
-		} else if !isAppointmentServices && apiConfig.GenericServicesHttpMethod != nil {
-			httpMethod = *apiConfig.GenericServicesHttpMethod
-		}
-	}
-
-	// Ensure timeout is valid (default to 10 seconds if 0)
-	if timeoutSeconds == 0 {
-		timeoutSeconds = 10
-	}
-
-	// Create HTTP client with configured timeout
-	client := &http.Client{
-		Timeout: time.Duration(timeoutSeconds) * time.Second,
-	}
-
-	// Create request with body if POST method
-	var bodyReader io.Reader
-	if httpMethod == "POST" && postBody != "" {
-		bodyReader = strings.NewReader(postBody)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, httpMethod, externalAPIURL, bodyReader)
-	if err != nil {
-		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to create request", 500, nil)
-	}
-
-	// Set content type for POST requests
-	if httpMethod == "POST" && postBody != "" {
-		req.Header.Set("Content-Type", "application/json")
-	}
-
-	// Add custom headers if configured
-	if headers != nil {
-		for key, value := range headers {
-			req.Header.Set(key, value)
-		}
-	}
-
-	// Add query parameters
-	q := req.URL.Query()
-	if identifier != "" {
-		q.Add("identifier", identifier)
-	}
-
-	// Add language parameter based on language handling configuration
-	var languageHandling *string
-	var languageHeader *string
-
-	if isAppointmentServices {
-		if apiConfig != nil && apiConfig.AppointmentServicesLanguageHandling != nil {
-			languageHandling = apiConfig.AppointmentServicesLanguageHandling
-			languageHeader = apiConfig.AppointmentServicesLanguageHeader
-		}
-	} else {
-		if apiConfig != nil && apiConfig.GenericServicesLanguageHandling != nil {
-			languageHandling = apiConfig.GenericServicesLanguageHandling
-			languageHeader = apiConfig.GenericServicesLanguageHeader
-		}
-	}
-
-	if languageHandling != nil {
-		switch *languageHandling {
-		case "query_param":
-			// Convert language code to uppercase for API
-			langCode := strings.ToUpper(language)
-			q.Add("lang", langCode)
-			log.Printf("Added language parameter: lang=%s", langCode)
-		case "header":
-			// Add language to HTTP header
-			headerName := "Accept-Language"
-			if languageHeader != nil && *languageHeader != "" {
-				headerName = *languageHeader
-			}
-			req.Header.Set(headerName, language)
-			log.Printf("Added language header: %s=%s", headerName, language)
-		case "none":
-			// No language handling - will rely on DeepL translation
-			log.Printf("No language handling configured - will use DeepL translation if enabled")
-		}
-	}
-
-	req.URL.RawQuery = q.Encode()
-
-	// Make request
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Printf("Failed to call external API %s: %v", externalAPIURL, err)
-		// Return empty list instead of error to allow proceeding without services
-		return []dto.UserService{}, nil
-	}
-	defer resp.Body.Close()
-
-	// Check response status
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("External API %s returned status %d", externalAPIURL, resp.StatusCode)
-		// Return empty list instead of error to allow proceeding without services
-		return []dto.UserService{}, nil
-	}
-
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("Failed to read response body from external API %s: %v", externalAPIURL, err)
-		// Return empty list instead of error to allow proceeding without services
-		return []dto.UserService{}, nil
-	}
-
-	log.Printf("External API response body: %s", string(body))
-
-	// Try to parse as external API format first (with code, id as int64, name)
-	type ExternalService struct {
-		Code     string `json:"code"`
-		Duration int64  `json:"duration"`
-		Id       int64  `json:"id"`
-		Name     string `json:"name"`
-	}
-
-	var externalServices []ExternalService
-	var services []dto.UserService
-
-	// Try parsing as external API format first
-	if err := json.Unmarshal(body, &externalServices); err == nil {
-		if len(externalServices) > 0 {
-			// Transform external format to UserService format
-			services = make([]dto.UserService, len(externalServices))
-			for i, ext := range externalServices {
-				services[i] = dto.UserService{
-					Id:          fmt.Sprintf("%d", ext.Id), // Convert int64 id to string
-					ServiceName: ext.Name,
-					Duration:    ext.Duration,
-				}
-			}
-			log.Printf("Successfully parsed %d services from external API format", len(services))
-		} else {
-			log.Printf("External API format parsed successfully but returned empty array")
-			services = []dto.UserService{}
-		}
-	} else {
-		// Fallback: try parsing as direct UserService format
-		externalErr := err
-		if parseErr := json.Unmarshal(body, &services); parseErr != nil {
-			log.Printf("Failed to parse response in both formats. External format error: %v, Direct format error: %v", externalErr, parseErr)
-			log.Printf("Response body was: %s", string(body))
-			// Return empty list instead of error to allow proceeding without services
-			return []dto.UserService{}, nil
-		}
-		log.Printf("Successfully parsed %d services from direct UserService format", len(services))
-	}
-
-	// Apply DeepL translation if configured and needed
-	// Note: For generic services, translation will be handled in GetGenericServices after merging with admin-created services
-	// For appointment services, translation happens here since they are returned directly
-	if apiConfig != nil && apiConfig.UseDeepLTranslation != nil && *apiConfig.UseDeepLTranslation {
-		// Only translate here for appointment services (isAppointmentServices = true)
-		// Generic services will be translated later in GetGenericServices after merging
-		if isAppointmentServices {
-			log.Printf("DeepL translation is enabled in config for appointment services")
-
-			if s.translationService == nil {
-				log.Printf("WARNING: DeepL translation is enabled but translation service is nil")
-			} else {
-				// Always attempt translation for appointment services
-				needsTranslation := true
-
-				log.Printf("Translation decision for appointment services: needsTranslation=%v, targetLanguage=%s", needsTranslation, language)
-
-				if needsTranslation {
-					// Appointment services are typically in English, so use "en" as source
-					sourceLanguage := "en"
-					log.Printf("Attempting to translate %d appointment services from %s to %s", len(services), sourceLanguage, language)
-					translatedServices, err := s.translateServices(services, sourceLanguage, language)
-					if err != nil {
-						log.Printf("Failed to translate appointment services: %v", err)
-						// Return original services if translation fails
-					} else {
-						log.Printf("Successfully translated appointment services")
-						services = translatedServices
-					}
-				}
-			}
-		} else {
-			log.Printf("Skipping translation in makeExternalAPICall for generic services - will be handled in GetGenericServices after merging")
-		}
-	} else {
-		log.Printf("DeepL translation not enabled or not configured properly")
-	}
-
-	return services, nil
-}
-
-// translateServices translates service names and descriptions using DeepL
-func (s *Service) translateServices(services []dto.UserService, sourceLanguage, targetLanguage string) ([]dto.UserService, error) {
+// translateServices translates service names with the translation provider of the tenant
+func (s *Service) translateServices(ctx context.Context, services []dto.UserService, sourceLanguage, targetLanguage string) ([]dto.UserService, error) {
 	log.Printf("translateServices called with %d services, sourceLanguage=%s, targetLanguage=%s", len(services), sourceLanguage, targetLanguage)
 
 	if s.translationService == nil {
 		log.Printf("ERROR: translationService is nil")
-		return services, fmt.Errorf("DeepL translation service is nil")
+		return services, fmt.Errorf("translation service is nil")
 	}
 
-	if !s.translationService.IsConfigured() {
-		log.Printf("ERROR: translationService is not configured")
-		return services, fmt.Errorf("DeepL translation service not configured")
+	provider := s.translationService.Provider(ctx)
+	if !provider.IsConfigured() {
+		log.Printf("ERROR: translation provider is not configured")
+		return services, fmt.Errorf("translation provider not configured")
 	}
 
 	// Skip translation if source and target languages are the same
@@ -675,32 +640,97 @@ func (s *Service) translateServices(services []dto.UserService, sourceLanguage,
 
 	log.Printf("Starting translation of %d services from %s to %s", len(services), sourceLanguage, targetLanguage)
 	translatedServices := make([]dto.UserService, len(services))
-	successCount := 0
-	failCount := 0
+	copy(translatedServices, services)
 
+	// Translate the service names at once, so the provider can batch them
+	var names []string
+	var positions []int
 	for i, service := range services {
-		translatedService := service
-
-		// Translate service name
-		if service.ServiceName != "" {
-			log.Printf("Translating service %d: '%s' from %s to %s", i, service.ServiceName, sourceLanguage, targetLanguage)
-			translatedName, err := s.translationService.Translate(service.ServiceName, sourceLanguage, targetLanguage)
-			if err != nil {
-				log.Printf("Failed to translate service name '%s': %v (keeping original)", service.ServiceName, err)
-				failCount++
-				// Keep original name if translation fails
-			} else {
-				log.Printf("Successfully translated '%s' -> '%s'", service.ServiceName, translatedName)
-				translatedService.ServiceName = translatedName
-				successCount++
-			}
-		} else {
+		if service.ServiceName == "" {
 			log.Printf("Service %d has empty ServiceName, skipping translation", i)
+			continue
 		}
+		names = append(names, service.ServiceName)
+		positions = append(positions, i)
+	}
+	translatedNames, errs := translation.TranslateTexts(ctx, provider, names, sourceLanguage, targetLanguage)
 
-		translatedServices[i] = translatedService
+	successCount := 0
+	failCount := 0
+	for j, i := range positions {
+		if errs[j] != nil {
+			log.Printf("Failed to translate service name '%s': %v (keeping original)", names[j], errs[j])
+			failCount++
+			// Keep original name if translation fails
+			continue
+		}
+		translatedServices[i].ServiceName = translatedNames[j]
+		successCount++
 	}
 
 	log.Printf("Translation complete: %d succeeded, %d failed out of %d total", successCount, failCount, len(services))
 	return translatedServices, nil
 }
+
+// readerCardData mirrors the CardData JSON produced by the card-reader
+type readerCardData struct {
+	IDNumber    string `json:"id_number"`
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	DateOfBirth string `json:"date_of_birth"`
+	Gender      string `json:"gender"`
+	Nationality string `json:"nationality"`
+	Address     string `json:"address"`
+	IssuedDate  string `json:"issued_date"`
+	ExpiryDate  string `json:"expiry_date"`
+	Photo       string `json:"photo"`
+	Source      string `json:"source"`
+
+	InsuranceNumber    string `json:"insurance_number"`
+	InsurerCode        string `json:"insurer_code"`
+	InsurerName        string `json:"insurer_name"`
+	InsuranceValidFrom string `json:"insurance_valid_from"`
+	InsuranceValidTo   string `json:"insurance_valid_to"`
+}
+
+// openCardData decrypts an encrypted CardData block with the key provisioned for the sending card reader
+func (s *Service) openCardData(ctx context.Context, block *dto.EncryptedCardData) (*queue.CardData, error) {
+	plain, err := s.configService.DecryptCardData(ctx, block.DeviceId, block.Token, &cardreader.EncryptedCardData{
+		Alg:        block.Alg,
+		KeyID:      block.Kid,
+		Nonce:      block.Nonce,
+		Ciphertext: block.Ciphertext,
+	})
+	if err != nil {
+		log.Printf("[KioskService] Failed to decrypt card data from %s: %v", block.DeviceId, err)
+		return nil, ngErrors.CardReaderUnauthorized(block.DeviceId)
+	}
+
+	var rc readerCardData
+	if err := json.Unmarshal(plain, &rc); err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted card data: %w", err)
+	}
+	source := rc.Source
+	if source == "" {
+		source = "card-reader"
+	}
+	return &queue.CardData{
+		IDNumber:    rc.IDNumber,
+		FirstName:   rc.FirstName,
+		LastName:    rc.LastName,
+		DateOfBirth: rc.DateOfBirth,
+		Gender:      rc.Gender,
+		Nationality: rc.Nationality,
+		Address:     rc.Address,
+		IssuedDate:  rc.IssuedDate,
+		ExpiryDate:  rc.ExpiryDate,
+		Photo:       rc.Photo,
+		Source:      source,
+
+		InsuranceNumber:    rc.InsuranceNumber,
+		InsurerCode:        rc.InsurerCode,
+		InsurerName:        rc.InsurerName,
+		InsuranceValidFrom: rc.InsuranceValidFrom,
+		InsuranceValidTo:   rc.InsuranceValidTo,
+	}, nil
+}
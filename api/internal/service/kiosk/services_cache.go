@@ -0,0 +1,79 @@
+package kiosk
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/configlayers"
+	"github.com/arfis/waiting-room/internal/data/dto"
+)
+
+// servicesCache keeps the services the HIS returned for a patient for a short time, so a swipe and
+// the kiosk screens refreshing after it don't call the HIS again. Degraded responses are not kept.
+type servicesCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[servicesCacheKey]servicesCacheEntry
+}
+
+// servicesCacheKey identifies the services of a patient in a language; generic services have no
+// identifier
+type servicesCacheKey struct {
+	tenantID   string
+	generic    bool
+	identifier string
+	language   string
+}
+
+type servicesCacheEntry struct {
+	services  []dto.UserService
+	expiresAt time.Time
+}
+
+func newServicesCache(ttl time.Duration) *servicesCache {
+	return &servicesCache{
+		ttl:     ttl,
+		entries: map[servicesCacheKey]servicesCacheEntry{},
+	}
+}
+
+func (c *servicesCache) get(key servicesCacheKey) (*dto.UserServicesResp, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return &dto.UserServicesResp{Services: slices.Clone(entry.services)}, true
+}
+
+func (c *servicesCache) set(key servicesCacheKey, resp *dto.UserServicesResp) {
+	if c.ttl <= 0 || resp.Degraded {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = servicesCacheEntry{services: slices.Clone(resp.Services), expiresAt: now.Add(c.ttl)}
+}
+
+// invalidate drops the services of the tenants that inherit the configuration of tenantID after
+// its external API configuration changed
+func (c *servicesCache) invalidate(tenantID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if configlayers.Inherits(key.tenantID, tenantID) {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package kiosk
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/queue"
+	"github.com/arfis/waiting-room/internal/service"
+	"github.com/arfis/waiting-room/internal/service/webhook"
+)
+
+// IssueWalkInTicket issues an anonymous ticket for a visitor without a readable card ("no card"
+// button on the kiosk). The entry has no card data; the visitor is identified by a generated
+// visitor code and still gets a QR token and the usual webhook events.
+func (s *Service) IssueWalkInTicket(ctx context.Context, roomId string, req *dto.WalkInRequest) (*dto.JoinResult, error) {
+	roomId, err := s.intakeRoom(ctx, roomId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without a card only a key sent by the kiosk identifies a retry
+	walkInKeys := s.queueService.SwipeKeys("", roomId, req.GetIdempotencyKey(), time.Now())
+	idempotencyKey := ""
+	if len(walkInKeys) > 0 {
+		idempotencyKey = walkInKeys[0]
+	}
+
+	approximateDurationSeconds := req.GetServiceDuration() * 60
+	if approximateDurationSeconds == 0 {
+		approximateDurationSeconds = 300 // Default fallback: 5 minutes = 300 seconds
+	}
+
+	entry, duplicate, err := s.queueService.JoinOnce(ctx, walkInKeys, func() (*queue.Entry, error) {
+		return s.queueService.CreateWalkInEntry(ctx, roomId, approximateDurationSeconds, req.GetServiceName(), idempotencyKey)
+	})
+	if err != nil {
+		var fullErr *queue.QueueFullError
+		if errors.As(err, &fullErr) {
+			return nil, s.queueFullError(ctx, fullErr, req.GetLanguage())
+		}
+		log.Printf("[KioskService] Failed to create walk-in entry for room %s: %v", roomId, err)
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to create queue entry", 500, nil)
+	}
+	if duplicate {
+		return duplicateJoinResult(entry), nil
+	}
+
+	// Put the entry on its visit pathway; it then waits in the room of the first stage
+	if _, err := s.queueService.StartPathway(ctx, entry, req.GetPathwayId(), req.GetServiceId()); err != nil {
+		log.Printf("[KioskService] Failed to start pathway for walk-in entry %s: %v", entry.ID, err)
+	}
+
+	if s.broadcastFunc != nil {
+		tenantID := service.GetTenantID(ctx)
+		s.broadcastFunc(roomId, tenantID)
+		if entry.WaitingRoomID != roomId {
+			s.broadcastFunc(entry.WaitingRoomID, tenantID)
+		}
+	}
+
+	if s.webhookService != nil {
+		created := webhook.EntryPayload(webhook.EventEntryCreated, entry)
+		created.AdditionalData = map[string]interface{}{
+			"walkIn":      true,
+			"visitorCode": entry.VisitorCode,
+		}
+		s.webhookService.Enqueue(ctx, created)
+		if req.GetServiceId() != "" {
+			selected := webhook.EntryPayload(webhook.EventEntryServiceSelected, entry)
+			selected.ServiceID = req.GetServiceId()
+			s.webhookService.Enqueue(ctx, selected)
+		}
+	}
+
+	log.Printf("[KioskService] Issued walk-in ticket %s (visitor code %s) for room %s", entry.TicketNumber, entry.VisitorCode, roomId)
+
+	result := &dto.JoinResult{
+		EntryID:      entry.ID,
+		TicketNumber: entry.TicketNumber,
+		QrUrl:        "http://localhost:4204/q/" + entry.QRToken,
+		VisitorCode:  &entry.VisitorCode,
+	}
+	durationMinutes := approximateDurationSeconds / 60
+	result.ServiceDuration = &durationMinutes
+	if entry.ServiceName != "" {
+		result.ServiceName = &entry.ServiceName
+	}
+	return result, nil
+}
@@ -0,0 +1,149 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// Channels of a notification
+const (
+	ChannelSMS   = "sms"
+	ChannelEmail = "email"
+)
+
+// Sender delivers a notification to one phone number or email address
+type Sender interface {
+	Send(ctx context.Context, to, subject, message string) error
+}
+
+// defaultSMSBaseURL is the API of SMSSettings without a base URL
+const defaultSMSBaseURL = "https://api.twilio.com"
+
+// defaultSMTPPort is the submission port of SMTPSettings without a port
+const defaultSMTPPort = 587
+
+// senderFor returns the sender of a channel in settings: the provider of the channel, else the
+// HTTP provider, nil when there is neither
+func senderFor(settings *types.NotificationSettings, channel string, client *http.Client) Sender {
+	switch {
+	case channel == ChannelEmail && settings.SMTP != nil && settings.SMTP.Host != "":
+		return &smtpSender{settings: *settings.SMTP}
+	case channel == ChannelSMS && settings.SMS != nil && settings.SMS.AccountSID != "":
+		return &smsSender{settings: *settings.SMS, httpClient: client}
+	case settings.HTTP != nil && settings.HTTP.URL != "":
+		return &httpSender{settings: *settings.HTTP, channel: channel, httpClient: client}
+	}
+	return nil
+}
+
+// smtpSender sends emails through a mail server, with STARTTLS when the server offers it
+type smtpSender struct {
+	settings types.SMTPSettings
+}
+
+func (s *smtpSender) Send(ctx context.Context, to, subject, message string) error {
+	port := s.settings.Port
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+	var auth smtp.Auth
+	if s.settings.Username != "" {
+		auth = smtp.PlainAuth("", s.settings.Username, s.settings.Password, s.settings.Host)
+	}
+
+	var mail bytes.Buffer
+	fmt.Fprintf(&mail, "From: %s\r\n", s.settings.From)
+	fmt.Fprintf(&mail, "To: %s\r\n", to)
+	fmt.Fprintf(&mail, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&mail, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	mail.WriteString("MIME-Version: 1.0\r\n")
+	mail.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	mail.WriteString(message + "\r\n")
+
+	addr := net.JoinHostPort(s.settings.Host, strconv.Itoa(port))
+	if err := smtp.SendMail(addr, auth, s.settings.From, []string{to}, mail.Bytes()); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// smsSender sends SMS through the Messages resource of a Twilio-compatible API
+type smsSender struct {
+	settings   types.SMSSettings
+	httpClient *http.Client
+}
+
+func (s *smsSender) Send(ctx context.Context, to, subject, message string) error {
+	baseURL := strings.TrimSuffix(s.settings.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultSMSBaseURL
+	}
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", baseURL, url.PathEscape(s.settings.AccountSID))
+	form := url.Values{"To": {to}, "From": {s.settings.From}, "Body": {message}}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.settings.AccountSID, s.settings.AuthToken)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send SMS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("SMS API returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// httpSender posts notifications as JSON to a service that delivers them itself
+type httpSender struct {
+	settings   types.HTTPNotificationSettings
+	channel    string
+	httpClient *http.Client
+}
+
+func (s *httpSender) Send(ctx context.Context, to, subject, message string) error {
+	body, err := json.Marshal(map[string]string{
+		"channel": s.channel,
+		"to":      to,
+		"subject": subject,
+		"message": message,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.settings.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.settings.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.settings.Token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification service returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/queue"
+	configService "github.com/arfis/waiting-room/internal/service/config"
+	queueService "github.com/arfis/waiting-room/internal/service/queue"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// Messages of tenants without messages of their own
+const (
+	DefaultApproachingMessage = "Ticket {ticket}: your turn is near ({peopleAhead} ahead of you), please return to the waiting room."
+	DefaultCalledMessage      = "Ticket {ticket}: it is your turn, please proceed to {servicePoint}."
+)
+
+// sendTimeout is how long the notifications of one entry may take
+const sendTimeout = 30 * time.Second
+
+// Service notifies the patients who registered a phone number or email on the QR page when their
+// turn is near and when they are called, with the providers the tenant configured in its
+// notification settings, and pushes the same to QR pages with a Web Push subscription. Tenants
+// without settings notify nobody.
+type Service struct {
+	configService *configService.Service
+	queue         *queue.WaitingQueue
+	httpClient    *http.Client
+}
+
+// New creates the notification service
+func New(configService *configService.Service, waitingQueue *queue.WaitingQueue) *Service {
+	return &Service{
+		configService: configService,
+		queue:         waitingQueue,
+		httpClient:    &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// pushMessage is the payload the service worker of the QR status page gets
+type pushMessage struct {
+	Type         string `json:"type"` // approaching or called
+	Title        string `json:"title"`
+	Body         string `json:"body"`
+	TicketNumber string `json:"ticketNumber"`
+	PeopleAhead  *int64 `json:"peopleAhead,omitempty"`
+	ServicePoint string `json:"servicePoint,omitempty"`
+}
+
+// NotifyRoom tells the patients of a room and tenant whose turn became near in the background,
+// and pushes the people-ahead milestones to the subscribed QR status pages; call it with every
+// queue update. Patients are told once, also with several replicas.
+func (s *Service) NotifyRoom(roomId, tenantID string) {
+	go func() {
+		ctx := tenantContext(tenantID)
+		settings := s.settings(ctx)
+		if settings == nil {
+			return
+		}
+		if settings.ApproachingPeopleAhead > 0 {
+			s.notifyApproaching(ctx, settings, roomId)
+		}
+		if pushEnabled(settings) {
+			s.pushMilestones(ctx, settings, roomId)
+		}
+	}()
+}
+
+func (s *Service) notifyApproaching(ctx context.Context, settings *types.NotificationSettings, roomId string) {
+	entries, err := s.queue.ApproachingEntries(ctx, roomId, settings.ApproachingPeopleAhead)
+	if err != nil {
+		log.Printf("[Notifications] Failed to get approaching entries of room %s: %v", roomId, err)
+		return
+	}
+	for _, entry := range entries {
+		s.send(ctx, settings, entry, approachingMessage(settings, entry))
+	}
+}
+
+func (s *Service) pushMilestones(ctx context.Context, settings *types.NotificationSettings, roomId string) {
+	milestones := settings.Push.Milestones
+	if len(milestones) == 0 {
+		milestones = types.DefaultPushMilestones
+	}
+	entries, err := s.queue.PushMilestoneEntries(ctx, roomId, milestones)
+	if err != nil {
+		log.Printf("[Notifications] Failed to get push milestones of room %s: %v", roomId, err)
+		return
+	}
+	for _, entry := range entries {
+		peopleAhead := peopleAheadOf(entry)
+		s.push(ctx, settings, entry, pushMessage{
+			Type:         "approaching",
+			Title:        "Ticket " + entry.TicketNumber,
+			Body:         approachingMessage(settings, entry),
+			TicketNumber: entry.TicketNumber,
+			PeopleAhead:  &peopleAhead,
+		})
+	}
+}
+
+// NotifyCalled tells the patient of a call in the background and removes their contact, which is
+// not needed anymore afterwards
+func (s *Service) NotifyCalled(call queueService.CallEvent) {
+	go func() {
+		ctx := tenantContext(call.TenantID)
+		settings := s.settings(ctx)
+		if settings == nil {
+			return
+		}
+		entry, err := s.queue.TakeEntryContact(ctx, call.EntryID)
+		if err != nil {
+			log.Printf("[Notifications] Failed to get contact of entry %s: %v", call.EntryID, err)
+			return
+		}
+		servicePoint := call.ServicePointName
+		if servicePoint == "" {
+			servicePoint = call.ServicePointID
+		}
+		message := render(settings.CalledMessage, DefaultCalledMessage, map[string]string{
+			"{ticket}":       call.TicketNumber,
+			"{servicePoint}": servicePoint,
+		})
+		if entry.NotifyPhone != "" || entry.NotifyEmail != "" {
+			s.send(ctx, settings, entry, message)
+		}
+		if entry.PushSubscription != nil && pushEnabled(settings) {
+			s.push(ctx, settings, entry, pushMessage{
+				Type:         "called",
+				Title:        "Ticket " + call.TicketNumber,
+				Body:         message,
+				TicketNumber: call.TicketNumber,
+				ServicePoint: servicePoint,
+			})
+		}
+	}()
+}
+
+// settings returns the notification settings of the tenant in ctx, nil when it has none or they
+// cannot be read
+func (s *Service) settings(ctx context.Context) *types.NotificationSettings {
+	settings, err := s.configService.GetNotificationSettings(ctx)
+	if err != nil {
+		log.Printf("[Notifications] Failed to get notification settings: %v", err)
+		return nil
+	}
+	return settings
+}
+
+// send sends message to the phone number and email of an entry; failures are only logged, a
+// notification is never retried
+func (s *Service) send(ctx context.Context, settings *types.NotificationSettings, entry *queue.Entry, message string) {
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	subject := "Ticket " + entry.TicketNumber
+	for _, recipient := range []struct{ channel, to string }{
+		{ChannelSMS, entry.NotifyPhone},
+		{ChannelEmail, entry.NotifyEmail},
+	} {
+		if recipient.to == "" {
+			continue
+		}
+		sender := senderFor(settings, recipient.channel, s.httpClient)
+		if sender == nil {
+			log.Printf("[Notifications] No %s provider configured for entry %s", recipient.channel, entry.ID)
+			continue
+		}
+		if err := sender.Send(ctx, recipient.to, subject, message); err != nil {
+			log.Printf("[Notifications] Failed to send %s of entry %s: %v", recipient.channel, entry.ID, err)
+			continue
+		}
+		log.Printf("[Notifications] Sent %s of entry %s (ticket %s)", recipient.channel, entry.ID, entry.TicketNumber)
+	}
+}
+
+// push sends a push message to the QR status page of an entry and drops the subscription when the
+// push service no longer knows it
+func (s *Service) push(ctx context.Context, settings *types.NotificationSettings, entry *queue.Entry, message pushMessage) {
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("[Notifications] Failed to encode push message of entry %s: %v", entry.ID, err)
+		return
+	}
+	sender := &webPushSender{settings: *settings.Push, httpClient: s.httpClient}
+	err = sender.Send(ctx, *entry.PushSubscription, payload)
+	switch {
+	case errors.Is(err, ErrPushSubscriptionGone):
+		log.Printf("[Notifications] Push subscription of entry %s expired, dropping it", entry.ID)
+		if err := s.queue.DropPushSubscription(ctx, entry.ID); err != nil {
+			log.Printf("[Notifications] Failed to drop push subscription of entry %s: %v", entry.ID, err)
+		}
+	case err != nil:
+		log.Printf("[Notifications] Failed to push %s message of entry %s: %v", message.Type, entry.ID, err)
+	default:
+		log.Printf("[Notifications] Pushed %s message of entry %s (ticket %s)", message.Type, entry.ID, entry.TicketNumber)
+	}
+}
+
+// pushEnabled reports whether the tenant has VAPID keys to push with
+func pushEnabled(settings *types.NotificationSettings) bool {
+	return settings.Push != nil && settings.Push.VAPIDPrivateKey != ""
+}
+
+// approachingMessage returns the message telling the patient of entry that their turn is near
+func approachingMessage(settings *types.NotificationSettings, entry *queue.Entry) string {
+	return render(settings.ApproachingMessage, DefaultApproachingMessage, map[string]string{
+		"{ticket}":      entry.TicketNumber,
+		"{peopleAhead}": strconv.FormatInt(peopleAheadOf(entry), 10),
+	})
+}
+
+// peopleAheadOf returns the number of entries waiting before a WAITING entry
+func peopleAheadOf(entry *queue.Entry) int64 {
+	if entry.Position < 1 {
+		return 0
+	}
+	return entry.Position - 1
+}
+
+// tenantContext returns a background context with tenantID, the tenant of an update or call
+func tenantContext(tenantID string) context.Context {
+	ctx := context.Background()
+	if tenantID != "" {
+		ctx = context.WithValue(ctx, middleware.TENANT, tenantID)
+	}
+	return ctx
+}
+
+// render replaces the placeholders of template, or of fallback when template is empty
+func render(template, fallback string, values map[string]string) string {
+	if template == "" {
+		template = fallback
+	}
+	for placeholder, value := range values {
+		template = strings.ReplaceAll(template, placeholder, value)
+	}
+	return template
+}
@@ -0,0 +1,214 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// ErrPushSubscriptionGone is returned when the push service no longer knows a subscription, e.g.
+// because the patient revoked the permission; the subscription should be dropped
+var ErrPushSubscriptionGone = errors.New("push subscription expired")
+
+// pushTTL is how long push services keep a message for a phone that is offline; an older
+// "your turn" message is of no use
+const pushTTL = 10 * time.Minute
+
+// pushRecordSize is the record size of the aes128gcm content encoding; a notification always fits
+// into one record
+const pushRecordSize = 4096
+
+// GenerateVAPIDKeys returns a new VAPID key pair: the base64url uncompressed P-256 public key the
+// browsers subscribe with, and the base64url private scalar
+func GenerateVAPIDKeys() (publicKey string, privateKey string, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate VAPID key: %w", err)
+	}
+	public, err := key.PublicKey.Bytes()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode VAPID public key: %w", err)
+	}
+	private, err := key.Bytes()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode VAPID private key: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(public), base64.RawURLEncoding.EncodeToString(private), nil
+}
+
+// webPushSender sends Web Push messages (RFC 8030) signed with the VAPID keys of a tenant (RFC 8292)
+// and encrypted for the subscription (RFC 8291)
+type webPushSender struct {
+	settings   types.PushSettings
+	httpClient *http.Client
+}
+
+// Send encrypts payload for subscription and posts it to the push service of the subscription
+func (s *webPushSender) Send(ctx context.Context, subscription types.PushSubscription, payload []byte) error {
+	body, err := encryptPushPayload(subscription, payload)
+	if err != nil {
+		return err
+	}
+	authorization, err := s.vapidAuthorization(subscription.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", fmt.Sprintf("%d", int(pushTTL.Seconds())))
+	req.Header.Set("Urgency", "high")
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send push message: %w", err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrPushSubscriptionGone
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return fmt.Errorf("push service returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// vapidAuthorization returns the Authorization header of a push to endpoint: a JWT for the origin
+// of the push service signed with the private VAPID key, and the public key
+func (s *webPushSender) vapidAuthorization(endpoint string) (string, error) {
+	target, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid push endpoint: %w", err)
+	}
+	private, err := base64.RawURLEncoding.DecodeString(s.settings.VAPIDPrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), private)
+	if err != nil {
+		return "", fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	claims := map[string]interface{}{
+		"aud": target.Scheme + "://" + target.Host,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+	}
+	if s.settings.Subject != "" {
+		claims["sub"] = s.settings.Subject
+	}
+	encodedClaims, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal VAPID claims: %w", err)
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`)) + "." + base64.RawURLEncoding.EncodeToString(encodedClaims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	r, sig, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	sig.FillBytes(signature[32:])
+
+	token := unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)
+	return fmt.Sprintf("vapid t=%s, k=%s", token, s.settings.VAPIDPublicKey), nil
+}
+
+// encryptPushPayload encrypts payload for a subscription with the aes128gcm content encoding
+// (RFC 8188) and the key derivation of RFC 8291, as one record
+func encryptPushPayload(subscription types.PushSubscription, payload []byte) ([]byte, error) {
+	userAgentPublic, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(subscription.P256dh, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	authSecret, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(subscription.Auth, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+	userAgentKey, err := ecdh.P256().NewPublicKey(userAgentPublic)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+
+	// A new key pair and salt for every message
+	serverKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	sharedSecret, err := serverKey.ECDH(userAgentKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive shared secret: %w", err)
+	}
+	serverPublic := serverKey.PublicKey().Bytes()
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	keyInfo := "WebPush: info\x00" + string(userAgentPublic) + string(serverPublic)
+	authKey, err := hkdf.Extract(sha256.New, sharedSecret, authSecret)
+	if err != nil {
+		return nil, err
+	}
+	ikm, err := hkdf.Expand(sha256.New, authKey, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	contentKey, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// The padding delimiter 2 marks the last (and only) record
+	plaintext := append(append([]byte{}, payload...), 2)
+	if len(plaintext)+gcm.Overhead() > pushRecordSize {
+		return nil, fmt.Errorf("push payload of %d bytes is too large", len(payload))
+	}
+
+	header := make([]byte, 0, 16+4+1+len(serverPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, pushRecordSize)
+	header = append(header, byte(len(serverPublic)))
+	header = append(header, serverPublic...)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
@@ -2,24 +2,37 @@ package priority
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"strconv"
+	"strings"
 
 	"github.com/arfis/waiting-room/internal/priority"
 	"github.com/arfis/waiting-room/internal/service"
+	"github.com/arfis/waiting-room/internal/service/audit"
+	"github.com/arfis/waiting-room/internal/types"
 )
 
 // Service handles priority configuration management
 type Service struct {
 	priorityRepo *priority.Repository
+	audit        *audit.Service
 }
 
 // New creates a new priority configuration service
-func New(priorityRepo *priority.Repository) *Service {
+func New(priorityRepo *priority.Repository, auditService *audit.Service) *Service {
 	return &Service{
 		priorityRepo: priorityRepo,
+		audit:        auditService,
 	}
 }
 
+// SavesConfigs reports whether priority configurations can be saved; embedded sites use the
+// default priority settings
+func (s *Service) SavesConfigs() bool {
+	return s.priorityRepo != nil
+}
+
 // GetPriorityConfig retrieves the priority configuration for a tenant/section
 func (s *Service) GetPriorityConfig(ctx context.Context) (*priority.PriorityConfig, error) {
 	// Extract tenant ID from context
@@ -28,6 +41,11 @@ func (s *Service) GetPriorityConfig(ctx context.Context) (*priority.PriorityConf
 
 	log.Printf("[PriorityService] Getting config for tenant: %s, section: %s", buildingID, sectionID)
 
+	// Embedded sites have no priority repository and use the default priority settings
+	if s.priorityRepo == nil {
+		return priority.GetDefaultConfig(), nil
+	}
+
 	config, err := s.priorityRepo.GetConfig(ctx, buildingID, sectionID)
 	if err != nil {
 		log.Printf("[PriorityService] Error getting config: %v", err)
@@ -37,30 +55,167 @@ func (s *Service) GetPriorityConfig(ctx context.Context) (*priority.PriorityConf
 	return config, nil
 }
 
-// SavePriorityConfig saves the priority configuration for a tenant/section
+// SavePriorityConfig validates and saves the priority configuration for a tenant/section and
+// records it as a new revision
 func (s *Service) SavePriorityConfig(ctx context.Context, config *priority.PriorityConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	return s.saveRevision(ctx, config, 0)
+}
+
+// GetRevisions returns the saved revisions of the priority configuration for a tenant/section,
+// newest first
+func (s *Service) GetRevisions(ctx context.Context) ([]priority.ConfigRevision, error) {
+	buildingID, sectionID := parseTenantID(service.GetTenantID(ctx))
+	return s.priorityRepo.ListRevisions(ctx, buildingID, sectionID)
+}
+
+// RollbackToRevision makes a saved revision the priority configuration of the tenant/section
+// again. The rollback is itself recorded as a new revision, so it can be undone.
+func (s *Service) RollbackToRevision(ctx context.Context, revision int) (*priority.PriorityConfig, error) {
+	buildingID, sectionID := parseTenantID(service.GetTenantID(ctx))
+
+	stored, err := s.priorityRepo.GetRevision(ctx, buildingID, sectionID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[PriorityService] Rolling back config for tenant: %s, section: %s to revision %d", buildingID, sectionID, revision)
+	if err := s.saveRevision(ctx, &stored.Config, revision); err != nil {
+		return nil, err
+	}
+	return &stored.Config, nil
+}
+
+// DryRun calculates the tier and fitness score a patient would get, without creating an entry.
+// A candidate configuration is validated and used instead of the saved one, so rule changes can
+// be tried out before they are applied.
+func (s *Service) DryRun(ctx context.Context, candidate *priority.PriorityConfig, input priority.CalculationInput) (*priority.Explanation, error) {
+	config := candidate
+	if config == nil {
+		var err error
+		if config, err = s.GetPriorityConfig(ctx); err != nil {
+			return nil, err
+		}
+	} else if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	symbols, err := config.ResolveSymbols(input.Symbols)
+	if err != nil {
+		return nil, err
+	}
+	input.Symbols = symbols
+
+	explanation := priority.NewCalculator(config).Explain(input)
+	return &explanation, nil
+}
+
+// saveRevision saves the priority configuration for a tenant/section and records the revision
+func (s *Service) saveRevision(ctx context.Context, config *priority.PriorityConfig, restoredFrom int) error {
 	// Extract tenant ID from context
 	tenantIDHeader := service.GetTenantID(ctx)
 	buildingID, sectionID := parseTenantID(tenantIDHeader)
 
 	log.Printf("[PriorityService] Saving config for tenant: %s, section: %s", buildingID, sectionID)
+	if s.priorityRepo == nil {
+		return fmt.Errorf("%w: embedded sites use the default priority settings", priority.ErrInvalidConfig)
+	}
 
-	err := s.priorityRepo.SaveConfig(ctx, config, buildingID, sectionID)
+	before, err := s.priorityRepo.GetConfig(ctx, buildingID, sectionID)
+	if err != nil {
+		log.Printf("[PriorityService] Warning: failed to read config before change for the audit log: %v", err)
+		before = nil
+	}
+
+	err = s.priorityRepo.SaveConfig(ctx, config, buildingID, sectionID)
 	if err != nil {
 		log.Printf("[PriorityService] Error saving config: %v", err)
 		return err
 	}
 
+	// The configuration is live already; a missing revision only shortens the history
+	resourceID := ""
+	if saved, err := s.priorityRepo.AddRevision(ctx, config, buildingID, sectionID, service.GetUserID(ctx), restoredFrom); err != nil {
+		log.Printf("[PriorityService] Warning: failed to record config revision: %v", err)
+	} else {
+		resourceID = strconv.Itoa(saved.Revision)
+	}
+
+	action := types.AuditActionUpdate
+	if restoredFrom > 0 {
+		action = types.AuditActionRollback
+	}
+	s.audit.Record(ctx, types.AuditResourcePriorityConfiguration, action, resourceID, before, config)
+
 	log.Printf("[PriorityService] Config saved successfully")
 	return nil
 }
 
+// DeleteConfig deletes the priority configuration of a tenant/section and its revisions, when the
+// tenant is purged
+func (s *Service) DeleteConfig(ctx context.Context) error {
+	if s.priorityRepo == nil {
+		return nil
+	}
+	buildingID, sectionID := parseTenantID(service.GetTenantID(ctx))
+	return s.priorityRepo.DeleteConfig(ctx, buildingID, sectionID)
+}
+
 // GetDefaultConfig returns the default priority configuration
 func (s *Service) GetDefaultConfig(ctx context.Context) (*priority.PriorityConfig, error) {
 	log.Printf("[PriorityService] Getting default config")
 	return priority.GetDefaultConfig(), nil
 }
 
+// GetSymbols returns the symbol catalog of the tenant/section
+func (s *Service) GetSymbols(ctx context.Context) ([]priority.Symbol, error) {
+	config, err := s.GetPriorityConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return config.Symbols, nil
+}
+
+// AddSymbol adds a symbol to the catalog of the tenant/section
+func (s *Service) AddSymbol(ctx context.Context, symbol priority.Symbol) (*priority.Symbol, error) {
+	return s.changeSymbols(ctx, symbol.Code, func(config *priority.PriorityConfig) error {
+		return config.AddSymbol(symbol)
+	})
+}
+
+// UpdateSymbol changes the label, color and icon of a catalog symbol of the tenant/section
+func (s *Service) UpdateSymbol(ctx context.Context, code string, symbol priority.Symbol) (*priority.Symbol, error) {
+	return s.changeSymbols(ctx, code, func(config *priority.PriorityConfig) error {
+		return config.UpdateSymbol(code, symbol)
+	})
+}
+
+// RemoveSymbol removes a symbol from the catalog of the tenant/section
+func (s *Service) RemoveSymbol(ctx context.Context, code string) error {
+	_, err := s.changeSymbols(ctx, code, func(config *priority.PriorityConfig) error {
+		return config.RemoveSymbol(code)
+	})
+	return err
+}
+
+// changeSymbols applies change to the priority configuration of the tenant/section, saves it and
+// returns the catalog symbol with code afterwards
+func (s *Service) changeSymbols(ctx context.Context, code string, change func(config *priority.PriorityConfig) error) (*priority.Symbol, error) {
+	config, err := s.GetPriorityConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := change(config); err != nil {
+		return nil, err
+	}
+	if err := s.SavePriorityConfig(ctx, config); err != nil {
+		return nil, err
+	}
+	return config.FindSymbol(strings.TrimSpace(code)), nil
+}
+
 // parseTenantID parses the tenant ID header format "buildingId:sectionId"
 func parseTenantID(tenantIDHeader string) (buildingID, sectionID string) {
 	if tenantIDHeader == "" {
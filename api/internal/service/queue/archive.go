@@ -0,0 +1,49 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+)
+
+// StartArchiveRoutine starts a background routine that archives the finished entries older
+// than the configured number of days every night at the configured hour. It does nothing
+// when the archival is disabled.
+func (s *Service) StartArchiveRoutine(ctx context.Context, cfg config.QueueConfig) {
+	if cfg.ArchiveAfterDays <= 0 {
+		log.Printf("[QueueService] Archival disabled")
+		return
+	}
+	retention := time.Duration(cfg.ArchiveAfterDays) * 24 * time.Hour
+	go func() {
+		for {
+			timer := time.NewTimer(time.Until(nextArchiveRun(time.Now(), cfg.ArchiveHour)))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				s.ArchiveEntries(ctx, retention)
+			}
+		}
+	}()
+	log.Printf("[QueueService] Archival started (after %d days, at %02d:00)", cfg.ArchiveAfterDays, cfg.ArchiveHour)
+}
+
+// nextArchiveRun returns the next time after now at hour:00 local time
+func nextArchiveRun(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// ArchiveEntries archives the finished entries last changed more than retention ago
+func (s *Service) ArchiveEntries(ctx context.Context, retention time.Duration) {
+	if _, err := s.queueService.ArchiveEntries(ctx, time.Now().Add(-retention)); err != nil {
+		log.Printf("[QueueService] Archival failed: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	"github.com/arfis/waiting-room/internal/data/dto/bulkqueueaction"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/queue"
+	"github.com/arfis/waiting-room/internal/service"
+	"github.com/arfis/waiting-room/internal/service/webhook"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// BulkQueueOperation runs one bulk action on a room's queue, broadcasts each affected room once
+// and sends the webhook event of the action per affected entry
+func (s *Service) BulkQueueOperation(ctx context.Context, roomId string, req *dto.BulkQueueOperationRequest) (*dto.BulkQueueOperationResult, error) {
+	var affected int64
+	var err error
+	var entries []*queue.Entry
+	var event string
+	rooms := []string{roomId}
+
+	switch req.Action {
+	case bulkqueueaction.CLEAR_WAITING:
+		entries, event = s.entriesForEvents(ctx, roomId, "WAITING"), webhook.EventEntryCancelled
+		affected, err = s.queueService.ClearWaiting(ctx, roomId)
+	case bulkqueueaction.NO_SHOW_CALLED:
+		entries, event = s.entriesForEvents(ctx, roomId, "CALLED"), webhook.EventEntryNoShow
+		affected, err = s.queueService.MarkCalledAsNoShow(ctx, roomId)
+	case bulkqueueaction.MOVE_WAITING:
+		entries, event = s.entriesForEvents(ctx, roomId, "WAITING"), webhook.EventEntryTransferred
+		affected, err = s.queueService.MoveWaitingEntries(ctx, roomId, req.GetTargetRoomId())
+		rooms = append(rooms, req.GetTargetRoomId())
+	default:
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "unknown bulk action "+req.Action.String(), 400, nil)
+	}
+	if err != nil {
+		log.Printf("[QueueService] BulkQueueOperation: %s failed for room %s: %v", req.Action, roomId, err)
+		if errors.Is(err, queue.ErrInvalidBulkOperation) {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, err.Error(), 400, nil)
+		}
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to run bulk queue operation", 500, nil)
+	}
+
+	// One broadcast per affected room instead of one per entry
+	if affected > 0 && s.broadcastFunc != nil {
+		tenantID := service.GetTenantID(ctx)
+		for _, room := range rooms {
+			s.broadcastFunc(room, tenantID)
+		}
+	}
+
+	s.sendBulkEvents(ctx, event, entries, req)
+
+	changes := []types.AuditChange{{Field: "affected", After: affected}}
+	if req.Action == bulkqueueaction.MOVE_WAITING {
+		changes = append(changes, types.AuditChange{Field: "targetRoomId", After: req.GetTargetRoomId()})
+	}
+	s.auditService.RecordChanges(ctx, types.AuditResourceQueue, req.Action.String(), roomId, changes)
+
+	log.Printf("[QueueService] BulkQueueOperation: %s affected %d entries in room %s", req.Action, affected, roomId)
+	return &dto.BulkQueueOperationResult{
+		Action:   req.Action,
+		Affected: affected,
+	}, nil
+}
+
+// activeStatuses are the statuses of entries still waiting for or in their visit
+var activeStatuses = []string{"WAITING", "CALLED", "IN_SERVICE", "IN_ROOM"}
+
+// CountActiveEntries counts the entries of a room still waiting for or in their visit by status,
+// only the ones of servicePointId when it is set
+func (s *Service) CountActiveEntries(ctx context.Context, roomId, servicePointId string) (map[string]int, error) {
+	entries, err := s.queueService.GetQueueEntriesWithContext(ctx, roomId, activeStatuses)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for _, entry := range entries {
+		if servicePointId == "" || entry.ServicePoint == servicePointId {
+			counts[entry.Status]++
+		}
+	}
+	return counts, nil
+}
+
+// sendBulkEvents sends the webhook event of a bulk action for the entries it changed, in the state
+// the action left them in
+func (s *Service) sendBulkEvents(ctx context.Context, event string, entries []*queue.Entry, req *dto.BulkQueueOperationRequest) {
+	userID := service.GetUserID(ctx)
+	for _, entry := range entries {
+		var additionalData map[string]interface{}
+		switch req.Action {
+		case bulkqueueaction.CLEAR_WAITING:
+			entry.Status = "CANCELLED"
+		case bulkqueueaction.NO_SHOW_CALLED:
+			entry.Status = "NO_SHOW"
+		case bulkqueueaction.MOVE_WAITING:
+			additionalData = map[string]interface{}{"fromRoomId": entry.WaitingRoomID}
+			entry.WaitingRoomID = req.GetTargetRoomId()
+			entry.ServicePoint = ""
+		}
+		s.sendEntryEvent(ctx, event, entry, userID, additionalData)
+	}
+}
@@ -0,0 +1,26 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+)
+
+// StartChangeBroadcastRoutine starts a background routine that broadcasts the rooms of changed
+// entries, so clients see every change, edits made directly in the database included, not only
+// the ones a service broadcasts itself. It does nothing when the poll interval is negative.
+func (s *Service) StartChangeBroadcastRoutine(ctx context.Context, cfg config.QueueConfig) {
+	if cfg.ChangePollIntervalSeconds < 0 {
+		log.Printf("[QueueService] Change broadcasting disabled")
+		return
+	}
+	interval := time.Duration(cfg.ChangePollIntervalSeconds) * time.Second
+	go s.queueService.WatchChanges(ctx, interval, func(roomId, tenantID string) {
+		if s.broadcastFunc != nil {
+			s.broadcastFunc(roomId, tenantID)
+		}
+	})
+	log.Printf("[QueueService] Change broadcasting started (polling every %s without a change stream)", interval)
+}
@@ -0,0 +1,55 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/queue"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// CallEvent is an entry called to a service point, as display boards announce it
+type CallEvent struct {
+	RoomID           string    `json:"roomId"`
+	TenantID         string    `json:"tenantId"` // tenant of the entry, "building[:section]"
+	EntryID          string    `json:"entryId"`
+	TicketNumber     string    `json:"ticketNumber"`
+	ServicePointID   string    `json:"servicePointId"`
+	ServicePointName string    `json:"servicePointName,omitempty"`
+	CalledAt         time.Time `json:"calledAt"`
+}
+
+// SetCallHandler sets the function told about every entry called to a service point
+func (s *Service) SetCallHandler(f func(call CallEvent)) {
+	s.callHandler = f
+}
+
+// GetRoomDisplay returns the display board settings of a room
+func (s *Service) GetRoomDisplay(ctx context.Context, roomId string) types.RoomDisplay {
+	return s.queueService.RoomDisplay(ctx, roomId)
+}
+
+// notifyCall tells the call handler that entry was called to servicePointId
+func (s *Service) notifyCall(ctx context.Context, entry *queue.Entry, servicePointId string) {
+	if s.callHandler == nil {
+		return
+	}
+
+	call := CallEvent{
+		RoomID:         entry.WaitingRoomID,
+		TenantID:       queue.EntryTenantID(entry),
+		EntryID:        entry.ID,
+		TicketNumber:   entry.TicketNumber,
+		ServicePointID: servicePointId,
+		CalledAt:       time.Now(),
+	}
+	if servicePoints, err := s.queueService.GetServicePoints(ctx, entry.WaitingRoomID); err == nil {
+		for _, servicePoint := range servicePoints {
+			if servicePoint.ID == servicePointId {
+				call.ServicePointName = servicePoint.Name
+				break
+			}
+		}
+	}
+	s.callHandler(call)
+}
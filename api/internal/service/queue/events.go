@@ -0,0 +1,51 @@
+package queue
+
+import (
+	"context"
+	"log"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	"github.com/arfis/waiting-room/internal/queue"
+	"github.com/arfis/waiting-room/internal/service/webhook"
+)
+
+// sendEntryEvent queues an entry.* webhook event about entry, for the tenant in ctx
+func (s *Service) sendEntryEvent(ctx context.Context, event string, entry *queue.Entry, userID string, additionalData map[string]interface{}) {
+	if s.webhookService == nil {
+		return
+	}
+	payload := webhook.EntryPayload(event, entry)
+	payload.UserID = userID
+	payload.AdditionalData = additionalData
+	s.webhookService.Enqueue(ctx, payload)
+}
+
+// sendQueueEntryEvent sends an entry.* webhook event about an entry the queue layer returned as DTO
+func (s *Service) sendQueueEntryEvent(ctx context.Context, event string, entry *dto.QueueEntry, userID string) {
+	s.sendEntryEvent(ctx, event, &queue.Entry{
+		ID:            entry.ID,
+		WaitingRoomID: entry.WaitingRoomID,
+		TicketNumber:  entry.TicketNumber,
+		Status:        string(entry.Status),
+		ServicePoint:  entry.GetServicePoint(),
+	}, userID, nil)
+}
+
+// entriesForEvents returns the entries of a room in status that a bulk operation is about to
+// change, to send their webhook events afterwards; nil without webhooks
+func (s *Service) entriesForEvents(ctx context.Context, roomId, status string) []*queue.Entry {
+	if s.webhookService == nil {
+		return nil
+	}
+	entries, err := s.queueService.GetQueueEntriesWithContext(ctx, roomId, []string{status})
+	if err != nil {
+		log.Printf("[QueueService] Failed to get %s entries of room %s for their webhooks: %v", status, roomId, err)
+		return nil
+	}
+	copies := make([]*queue.Entry, 0, len(entries))
+	for _, entry := range entries {
+		copied := *entry
+		copies = append(copies, &copied)
+	}
+	return copies
+}
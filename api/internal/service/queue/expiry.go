@@ -0,0 +1,71 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/queue"
+	"github.com/arfis/waiting-room/internal/service/webhook"
+)
+
+// StartNoShowExpiryRoutine starts a background routine that expires CALLED entries which
+// were not marked IN_ROOM within the configured timeout, so they stop blocking their
+// service point. It does nothing when the timeout is 0.
+func (s *Service) StartNoShowExpiryRoutine(ctx context.Context, cfg config.QueueConfig) {
+	if cfg.NoShowTimeoutSeconds <= 0 {
+		log.Printf("[QueueService] No-show expiry disabled")
+		return
+	}
+	timeout := time.Duration(cfg.NoShowTimeoutSeconds) * time.Second
+	ticker := time.NewTicker(time.Duration(cfg.NoShowCheckIntervalSeconds) * time.Second)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.ExpireNoShows(ctx, timeout, cfg.NoShowRequeue)
+			}
+		}
+	}()
+	log.Printf("[QueueService] No-show expiry started (timeout: %s, requeue: %t)", timeout, cfg.NoShowRequeue)
+}
+
+// ExpireNoShows expires the CALLED entries older than timeout, broadcasts a queue update
+// once per affected room and tenant and sends an entry.no_show webhook per entry
+func (s *Service) ExpireNoShows(ctx context.Context, timeout time.Duration, requeue bool) {
+	expired, err := s.queueService.ExpireCalledEntries(ctx, time.Now().Add(-timeout), requeue)
+	if err != nil {
+		log.Printf("[QueueService] No-show expiry failed: %v", err)
+		return
+	}
+	if len(expired) == 0 {
+		return
+	}
+	s.broadcastRoomsOf(expired)
+	for _, entry := range expired {
+		tenantCtx := context.WithValue(ctx, middleware.TENANT, queue.EntryTenantID(entry))
+		s.sendEntryEvent(tenantCtx, webhook.EventEntryNoShow, entry, "", map[string]interface{}{"requeued": requeue})
+	}
+	log.Printf("[QueueService] Expired %d no-show entries", len(expired))
+}
+
+// broadcastRoomsOf broadcasts a queue update once per room and tenant of the entries
+func (s *Service) broadcastRoomsOf(entries []*queue.Entry) {
+	if s.broadcastFunc == nil {
+		return
+	}
+	broadcast := make(map[[2]string]bool)
+	for _, entry := range entries {
+		key := [2]string{entry.WaitingRoomID, queue.EntryTenantID(entry)}
+		if broadcast[key] {
+			continue
+		}
+		broadcast[key] = true
+		s.broadcastFunc(key[0], key[1])
+	}
+}
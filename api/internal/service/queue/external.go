@@ -0,0 +1,63 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/queue"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/service/webhook"
+)
+
+// CancelEntry cancels a waiting or called entry of the tenant on behalf of an external system and
+// broadcasts its room
+func (s *Service) CancelEntry(ctx context.Context, entryId, userID string) (*queue.Entry, error) {
+	entry, err := s.queueService.CancelEntry(ctx, entryId)
+	if err != nil {
+		log.Printf("[QueueService] CancelEntry: Failed to cancel entry: %v", err)
+		switch {
+		case errors.Is(err, queue.ErrEntryNotFound):
+			return nil, ngErrors.New(ngErrors.NotFoundErrorCode, "queue entry not found", 404, nil)
+		case errors.Is(err, queue.ErrCannotCancel):
+			return nil, ngErrors.New(ngErrors.BusinessErrorCode, "only waiting or called entries can be cancelled", 409, nil)
+		case errors.Is(err, repository.ErrVersionConflict):
+			return nil, ngErrors.QueueEntryConflict("the entry was changed while cancelling it")
+		}
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to cancel entry", 500, nil)
+	}
+
+	if s.broadcastFunc != nil {
+		s.broadcastFunc(entry.WaitingRoomID, queue.EntryTenantID(entry))
+	}
+	s.sendEntryEvent(ctx, webhook.EventEntryCancelled, entry, userID, nil)
+	return entry, nil
+}
+
+// ChangeEntryService moves a waiting entry of the tenant to another service on behalf of an external
+// system and broadcasts its room
+func (s *Service) ChangeEntryService(ctx context.Context, entryId, serviceId, serviceName string, durationSeconds int64, userID string) (*queue.Entry, error) {
+	entry, err := s.queueService.ChangeEntryService(ctx, entryId, serviceName, durationSeconds)
+	if err != nil {
+		log.Printf("[QueueService] ChangeEntryService: Failed to change service: %v", err)
+		switch {
+		case errors.Is(err, queue.ErrEntryNotFound):
+			return nil, ngErrors.New(ngErrors.NotFoundErrorCode, "queue entry not found", 404, nil)
+		case errors.Is(err, queue.ErrCannotChangeService):
+			return nil, ngErrors.New(ngErrors.BusinessErrorCode, "only the service of waiting entries can be changed", 409, nil)
+		}
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to change service", 500, nil)
+	}
+
+	if s.broadcastFunc != nil {
+		s.broadcastFunc(entry.WaitingRoomID, queue.EntryTenantID(entry))
+	}
+	if s.webhookService != nil {
+		payload := webhook.EntryPayload(webhook.EventEntryServiceSelected, entry)
+		payload.ServiceID = serviceId
+		payload.UserID = userID
+		s.webhookService.Enqueue(ctx, payload)
+	}
+	return entry, nil
+}
@@ -0,0 +1,28 @@
+package queue
+
+import (
+	"context"
+	"log"
+
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/queue"
+	"github.com/arfis/waiting-room/internal/service/webhook"
+)
+
+// onStageAdvanced is called when a finished entry moved on to the next stage of its
+// pathway. The room it left is broadcast by the finishing call; the stage room and a
+// webhook are handled here.
+func (s *Service) onStageAdvanced(ctx context.Context, entry *queue.Entry, fromRoomId string) {
+	if entry.WaitingRoomID != fromRoomId {
+		s.broadcastRoomsOf([]*queue.Entry{entry})
+	}
+
+	tenantCtx := context.WithValue(ctx, middleware.TENANT, queue.EntryTenantID(entry))
+	s.sendEntryEvent(tenantCtx, webhook.EventEntryStageAdvanced, entry, "", map[string]interface{}{
+		"pathwayId":    entry.PathwayID,
+		"pathwayStage": entry.PathwayStage,
+		"fromRoomId":   fromRoomId,
+	})
+
+	log.Printf("[QueueService] Entry %s advanced to stage %d of pathway %s in room %s", entry.ID, entry.PathwayStage, entry.PathwayID, entry.WaitingRoomID)
+}
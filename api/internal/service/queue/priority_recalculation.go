@@ -0,0 +1,39 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+)
+
+// StartPriorityRecalculationRoutine starts a background routine that refreshes the fitness
+// scores of waiting entries at the configured interval
+func (s *Service) StartPriorityRecalculationRoutine(ctx context.Context, cfg config.QueueConfig) {
+	interval := time.Duration(cfg.PriorityRecalculationIntervalSeconds) * time.Second
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.RecalculatePriorities(ctx)
+			}
+		}
+	}()
+	log.Printf("[QueueService] Priority recalculation started (interval: %s)", interval)
+}
+
+// RecalculatePriorities refreshes the fitness scores and broadcasts the rooms whose
+// queue order changed
+func (s *Service) RecalculatePriorities(ctx context.Context) {
+	changed, err := s.queueService.RecalculatePriorities(ctx)
+	if err != nil {
+		log.Printf("[QueueService] Priority recalculation failed: %v", err)
+		return
+	}
+	s.broadcastRoomsOf(changed)
+}
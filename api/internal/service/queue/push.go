@@ -0,0 +1,91 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/queue"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// GetWebPushSubscription returns the VAPID public key the QR status page of a token subscribes
+// with and whether it is subscribed
+func (s *Service) GetWebPushSubscription(ctx context.Context, qrToken string) (*dto.WebPushStatus, error) {
+	entry, err := s.queueService.GetEntryByQRToken(qrToken)
+	if err != nil {
+		return nil, ngErrors.New(ngErrors.NotFoundErrorCode, "queue entry not found", 404, nil)
+	}
+	return webPushStatus(entry, s.vapidPublicKey(ctx, entry)), nil
+}
+
+// UpdateWebPushSubscription subscribes the QR status page of a token to the push messages of its tenant
+func (s *Service) UpdateWebPushSubscription(ctx context.Context, qrToken string, subscription *dto.WebPushSubscription) (*dto.WebPushStatus, error) {
+	entry, err := s.queueService.GetEntryByQRToken(qrToken)
+	if err != nil {
+		return nil, ngErrors.New(ngErrors.NotFoundErrorCode, "queue entry not found", 404, nil)
+	}
+	publicKey := s.vapidPublicKey(ctx, entry)
+	if publicKey == "" {
+		return nil, ngErrors.New(ngErrors.BusinessErrorCode, "push messages are not set up for this waiting room", 409, nil)
+	}
+
+	keys := subscription.GetKeys()
+	entry, err = s.queueService.SetPushSubscriptionByQRToken(ctx, qrToken, &types.PushSubscription{
+		Endpoint: subscription.Endpoint,
+		P256dh:   keys.P256dh,
+		Auth:     keys.Auth,
+	})
+	if err != nil {
+		return nil, pushSubscriptionError(err)
+	}
+	return webPushStatus(entry, publicKey), nil
+}
+
+// DeleteWebPushSubscription unsubscribes the QR status page of a token
+func (s *Service) DeleteWebPushSubscription(ctx context.Context, qrToken string) (*dto.WebPushStatus, error) {
+	entry, err := s.queueService.SetPushSubscriptionByQRToken(ctx, qrToken, nil)
+	if err != nil {
+		return nil, pushSubscriptionError(err)
+	}
+	return webPushStatus(entry, s.vapidPublicKey(ctx, entry)), nil
+}
+
+func webPushStatus(entry *queue.Entry, publicKey string) *dto.WebPushStatus {
+	status := &dto.WebPushStatus{Subscribed: entry.PushSubscription != nil}
+	if publicKey != "" {
+		status.PublicKey = &publicKey
+	}
+	return status
+}
+
+// vapidPublicKey returns the VAPID public key of the tenant of an entry, empty when it does not push
+func (s *Service) vapidPublicKey(ctx context.Context, entry *queue.Entry) string {
+	// The QR status page has no tenant header, so look up within the entry's own tenant
+	tenantCtx := context.WithValue(ctx, middleware.TENANT, queue.EntryTenantID(entry))
+	settings, err := s.configService.GetNotificationSettings(tenantCtx)
+	if err != nil {
+		log.Printf("[QueueService] Failed to get notification settings for entry %s: %v", entry.ID, err)
+		return ""
+	}
+	if settings == nil || settings.Push == nil || settings.Push.VAPIDPrivateKey == "" {
+		return ""
+	}
+	return settings.Push.VAPIDPublicKey
+}
+
+func pushSubscriptionError(err error) error {
+	log.Printf("[QueueService] Failed to update push subscription: %v", err)
+	switch {
+	case errors.Is(err, queue.ErrInvalidContact):
+		return ngErrors.New(ngErrors.ValidationErrorCode, err.Error(), 400, nil)
+	case errors.Is(err, queue.ErrEntryNotFound):
+		return ngErrors.New(ngErrors.NotFoundErrorCode, "queue entry not found", 404, nil)
+	case errors.Is(err, queue.ErrCannotRegisterContact):
+		return ngErrors.New(ngErrors.BusinessErrorCode, "only waiting entries can subscribe to push messages", 409, nil)
+	}
+	return ngErrors.New(ngErrors.InternalServerErrorCode, "failed to update push subscription", 500, nil)
+}
@@ -0,0 +1,69 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/queue"
+)
+
+// StartRetentionRoutine starts a background routine that applies the retention policies of the
+// tenants every night at the configured hour. Tenants without a policy keep their card data.
+func (s *Service) StartRetentionRoutine(ctx context.Context, cfg config.PrivacyConfig) {
+	if cfg.IDHashKey == "" {
+		log.Printf("[QueueService] Warning: privacy.id_hash_key is not set, kept ID numbers are hashed without a key")
+	}
+	go func() {
+		for {
+			timer := time.NewTimer(time.Until(nextArchiveRun(time.Now(), cfg.AnonymizeHour)))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				s.ApplyRetentionPolicies(ctx)
+			}
+		}
+	}()
+	log.Printf("[QueueService] Anonymizer started (at %02d:00)", cfg.AnonymizeHour)
+}
+
+// ApplyRetentionPolicies purges the card data the retention policies of the tenants no longer allow
+func (s *Service) ApplyRetentionPolicies(ctx context.Context) {
+	anonymized, err := s.queueService.ApplyRetentionPolicies(ctx, time.Now())
+	if err != nil {
+		log.Printf("[QueueService] Anonymizer failed: %v", err)
+		return
+	}
+	log.Printf("[QueueService] Anonymizer purged the card data of %d entries", anonymized)
+}
+
+// EraseDataSubject deletes the entries of a person for a data subject erasure request and
+// broadcasts the rooms that lost active entries
+func (s *Service) EraseDataSubject(ctx context.Context, req *dto.DataSubjectErasureRequest) (*dto.DataSubjectErasureResult, error) {
+	deleted, err := s.queueService.EraseDataSubject(ctx, req.Identifier)
+	if err != nil {
+		log.Printf("[QueueService] EraseDataSubject failed: %v", err)
+		if errors.Is(err, queue.ErrInvalidIdentifier) {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, err.Error(), 400, nil)
+		}
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to erase data subject", 500, nil)
+	}
+
+	var active []*queue.Entry
+	for _, entry := range deleted {
+		if entry.Status == "WAITING" || entry.Status == "CALLED" || entry.Status == "IN_ROOM" || entry.Status == "IN_SERVICE" {
+			active = append(active, entry)
+		}
+	}
+	s.broadcastRoomsOf(active)
+
+	return &dto.DataSubjectErasureResult{
+		Deleted: int64(len(deleted)),
+	}, nil
+}
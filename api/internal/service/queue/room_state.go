@@ -0,0 +1,126 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/queue"
+	"github.com/arfis/waiting-room/internal/service"
+	"github.com/arfis/waiting-room/internal/service/webhook"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// GetRoomState returns the pause state of a room followed by the states of its service points
+func (s *Service) GetRoomState(ctx context.Context, roomId string) ([]dto.RoomState, error) {
+	states, err := s.queueService.GetRoomStates(ctx, roomId)
+	if err != nil {
+		log.Printf("[QueueService] GetRoomState: failed for room %s: %v", roomId, err)
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to get room state", 500, nil)
+	}
+
+	result := make([]dto.RoomState, 0, len(states))
+	for _, state := range states {
+		result = append(result, convertRoomStateToDTO(state))
+	}
+	return result, nil
+}
+
+// SetRoomState pauses or resumes intake of a room
+func (s *Service) SetRoomState(ctx context.Context, roomId string, req *dto.RoomStateRequest) (*dto.RoomState, error) {
+	return s.setRoomState(ctx, roomId, "", req)
+}
+
+// SetServicePointState pauses or resumes a service point
+func (s *Service) SetServicePointState(ctx context.Context, roomId, servicePointId string, req *dto.RoomStateRequest) (*dto.RoomState, error) {
+	return s.setRoomState(ctx, roomId, servicePointId, req)
+}
+
+func (s *Service) setRoomState(ctx context.Context, roomId, servicePointId string, req *dto.RoomStateRequest) (*dto.RoomState, error) {
+	state, err := s.queueService.SetRoomPaused(ctx, roomId, servicePointId, req.Paused, req.GetReason(), req.GetRedirectRoomId(), service.GetUserID(ctx))
+	if err != nil {
+		log.Printf("[QueueService] SetRoomState: failed for room %s service point '%s': %v", roomId, servicePointId, err)
+		if errors.Is(err, queue.ErrInvalidRoomState) {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, err.Error(), 400, nil)
+		}
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to set room state", 500, nil)
+	}
+
+	// Displays and service point screens show the paused state
+	if s.broadcastFunc != nil {
+		s.broadcastFunc(roomId, service.GetTenantID(ctx))
+	}
+	s.sendRoomStateEvent(ctx, state)
+
+	result := convertRoomStateToDTO(state)
+	return &result, nil
+}
+
+// sendRoomStateEvent queues queue.paused or queue.resumed about a room or service point
+func (s *Service) sendRoomStateEvent(ctx context.Context, state *types.RoomState) {
+	if s.webhookService == nil {
+		return
+	}
+	event := webhook.EventQueueResumed
+	if state.Paused {
+		event = webhook.EventQueuePaused
+	}
+	payload := webhook.QueuePayload(event, state.RoomID, state.ServicePointID)
+	payload.UserID = service.GetUserID(ctx)
+	if state.Paused {
+		payload.AdditionalData = map[string]interface{}{
+			"reason":         state.Reason,
+			"redirectRoomId": state.RedirectRoomID,
+		}
+	}
+	s.webhookService.Enqueue(ctx, payload)
+}
+
+// queuePausedError converts ErrQueuePaused into the API error, keeping the detail of the queue layer
+func queuePausedError(err error) error {
+	detail := strings.TrimPrefix(err.Error(), queue.ErrQueuePaused.Error()+": ")
+	return ngErrors.QueuePaused(detail)
+}
+
+// servicePointNotClaimedError asks staff to claim servicePointId before calling patients there
+func servicePointNotClaimedError(servicePointId string) error {
+	return ngErrors.New(ngErrors.BusinessErrorCode,
+		fmt.Sprintf("service point %s must be claimed before calling patients", servicePointId), 409, nil)
+}
+
+func convertRoomStateToDTO(state *types.RoomState) dto.RoomState {
+	result := dto.RoomState{
+		RoomId: state.RoomID,
+		Paused: state.Paused,
+	}
+	if state.ServicePointID != "" {
+		result.ServicePointId = &state.ServicePointID
+	}
+	if state.Reason != "" {
+		result.Reason = &state.Reason
+	}
+	if state.RedirectRoomID != "" {
+		result.RedirectRoomId = &state.RedirectRoomID
+	}
+	if !state.UpdatedAt.IsZero() {
+		result.UpdatedAt = &state.UpdatedAt
+	}
+	return result
+}
+
+// setPausedFields marks a queue entry whose room or service point is paused
+func setPausedFields(queueEntry *dto.QueueEntry, states []*types.RoomState, servicePointId string) {
+	state := queue.PausedState(states, servicePointId)
+	if state == nil {
+		return
+	}
+	paused := true
+	queueEntry.QueuePaused = &paused
+	if state.Reason != "" {
+		queueEntry.QueuePausedReason = &state.Reason
+	}
+}
@@ -2,29 +2,41 @@ package queue
 
 import (
 	"context"
+	"errors"
 	"log"
+	"strings"
 
 	"github.com/arfis/waiting-room/internal/data/dto"
 	"github.com/arfis/waiting-room/internal/data/dto/queueentrystatus"
 	ngErrors "github.com/arfis/waiting-room/internal/errors"
 	"github.com/arfis/waiting-room/internal/middleware"
 	"github.com/arfis/waiting-room/internal/queue"
+	"github.com/arfis/waiting-room/internal/repository"
 	"github.com/arfis/waiting-room/internal/service"
+	"github.com/arfis/waiting-room/internal/service/audit"
+	"github.com/arfis/waiting-room/internal/service/config"
 	"github.com/arfis/waiting-room/internal/service/webhook"
 )
 
 type Service struct {
 	queueService   *queue.WaitingQueue
 	broadcastFunc  func(string, string) // Function to broadcast queue updates (roomId, tenantID)
+	callHandler    func(CallEvent)      // Told about every call, for display boards
 	webhookService *webhook.Service
+	configService  *config.Service // Notification settings of the tenants, for Web Push
+	auditService   *audit.Service  // Records the bulk operations of the admins
 }
 
-func New(queueService *queue.WaitingQueue, broadcastFunc func(string, string), webhookService *webhook.Service) *Service {
-	return &Service{
+func New(queueService *queue.WaitingQueue, broadcastFunc func(string, string), webhookService *webhook.Service, configService *config.Service, auditService *audit.Service) *Service {
+	s := &Service{
 		queueService:   queueService,
 		broadcastFunc:  broadcastFunc,
 		webhookService: webhookService,
+		configService:  configService,
+		auditService:   auditService,
 	}
+	queueService.SetStageAdvanceHandler(s.onStageAdvanced)
+	return s
 }
 
 // convertEntryToDTO converts an internal entry to a DTO with all fields including age, symbols, appointmentTime, and createdAt
@@ -43,6 +55,15 @@ func convertEntryToDTO(entry *queue.Entry) dto.QueueEntry {
 	if entry.ServiceName != "" {
 		queueEntry.ServiceName = &entry.ServiceName
 	}
+	if entry.VisitorCode != "" {
+		queueEntry.VisitorCode = &entry.VisitorCode
+	}
+	if entry.Notes != "" {
+		queueEntry.Notes = &entry.Notes
+	}
+	if len(entry.Tags) > 0 {
+		queueEntry.Tags = entry.Tags
+	}
 	if entry.ApproximateDurationSeconds > 0 {
 		durationMinutes := entry.ApproximateDurationSeconds / 60 // Convert seconds to minutes for API
 		queueEntry.ServiceDuration = &durationMinutes
@@ -60,6 +81,22 @@ func convertEntryToDTO(entry *queue.Entry) dto.QueueEntry {
 	if !entry.CreatedAt.IsZero() {
 		queueEntry.CreatedAt = &entry.CreatedAt
 	}
+	tier := int64(entry.Tier)
+	queueEntry.Tier = &tier
+	queueEntry.FitnessScore = &entry.FitnessScore
+	if entry.FitnessBreakdown != nil {
+		queueEntry.FitnessBreakdown = &dto.PriorityFitnessBreakdown{
+			Age:                  entry.FitnessBreakdown.Age,
+			AppointmentDeviation: entry.FitnessBreakdown.AppointmentDeviation,
+			ManualOverride:       entry.FitnessBreakdown.ManualOverride,
+			Starvation:           entry.FitnessBreakdown.Starvation,
+			SymbolWeights:        entry.FitnessBreakdown.SymbolWeights,
+			WaitingTime:          entry.FitnessBreakdown.WaitingTime,
+		}
+	}
+	if entry.TierEscalated {
+		queueEntry.TierEscalated = &entry.TierEscalated
+	}
 
 	return queueEntry
 }
@@ -83,14 +120,117 @@ func (s *Service) GetQueueEntryByToken(ctx context.Context, qrToken string) (*dt
 		EtaMinutes:   entry.Position * 5, // Simple calculation: 5 minutes per position
 		CanCancel:    entry.Status == "WAITING",
 	}
+	if entry.WaitingRoomID != "" {
+		publicEntry.WaitingRoomID = &entry.WaitingRoomID
+	}
+	if entry.NotifyPhone != "" || entry.NotifyEmail != "" {
+		registered := true
+		publicEntry.NotificationsRegistered = &registered
+	}
+
+	// The QR status page has no tenant header, so look up within the entry's own tenant
+	tenantCtx := context.WithValue(ctx, middleware.TENANT, queue.EntryTenantID(entry))
+	if entry.Status != "WAITING" && entry.ServicePoint != "" {
+		publicEntry.ServicePoint = &entry.ServicePoint
+		if servicePoints, err := s.queueService.GetServicePoints(tenantCtx, entry.WaitingRoomID); err == nil {
+			for _, servicePoint := range servicePoints {
+				if servicePoint.ID == entry.ServicePoint && servicePoint.Name != "" {
+					publicEntry.ServicePointName = &servicePoint.Name
+					break
+				}
+			}
+		}
+	}
+
+	if entry.Status == "WAITING" {
+		peopleAhead := entry.Position - 1
+		if peopleAhead < 0 {
+			peopleAhead = 0
+		}
+		publicEntry.PeopleAhead = &peopleAhead
+		if states, err := s.queueService.GetRoomStates(tenantCtx, entry.WaitingRoomID); err != nil {
+			log.Printf("[QueueService] Failed to get room state for entry %s: %v", entry.ID, err)
+		} else if state := queue.PausedState(states, entry.ServicePoint); state != nil {
+			paused := true
+			publicEntry.QueuePaused = &paused
+			if state.Reason != "" {
+				publicEntry.QueuePausedReason = &state.Reason
+			}
+		}
+		if estimates, err := s.queueService.EstimateWaitTimes(tenantCtx, entry.WaitingRoomID); err != nil {
+			log.Printf("[QueueService] Failed to estimate wait time for entry %s: %v", entry.ID, err)
+		} else if estimate, ok := estimates[entry.ID]; ok {
+			publicEntry.EstimatedWaitSeconds = &estimate
+			publicEntry.EtaMinutes = (estimate + 59) / 60
+		}
+	}
 
 	return publicEntry, nil
 }
 
+// CancelQueueEntryByToken lets a patient cancel their own waiting entry from the QR status page
+func (s *Service) CancelQueueEntryByToken(ctx context.Context, qrToken string) (*dto.PublicEntry, error) {
+	entry, err := s.queueService.CancelEntryByQRToken(ctx, qrToken)
+	if err != nil {
+		log.Printf("[QueueService] CancelQueueEntryByToken: Failed to cancel entry: %v", err)
+		switch {
+		case errors.Is(err, queue.ErrEntryNotFound):
+			return nil, ngErrors.New(ngErrors.NotFoundErrorCode, "queue entry not found", 404, nil)
+		case errors.Is(err, queue.ErrCannotCancel):
+			return nil, ngErrors.New(ngErrors.BusinessErrorCode, "only waiting entries can be cancelled", 409, nil)
+		case errors.Is(err, repository.ErrVersionConflict):
+			return nil, ngErrors.QueueEntryConflict("the entry was changed while cancelling it")
+		}
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to cancel entry", 500, nil)
+	}
+
+	// The entry has left the queue; its tenant gets the update and the cancellation webhook
+	tenantID := queue.EntryTenantID(entry)
+	if s.broadcastFunc != nil {
+		s.broadcastFunc(entry.WaitingRoomID, tenantID)
+	}
+	tenantCtx := context.WithValue(ctx, middleware.TENANT, tenantID)
+	s.sendEntryEvent(tenantCtx, webhook.EventEntryCancelled, entry, "patient", nil)
+
+	return &dto.PublicEntry{
+		EntryID:      entry.ID,
+		TicketNumber: entry.TicketNumber,
+		Status:       queueentrystatus.QueueEntryStatus(entry.Status),
+		CanCancel:    false,
+	}, nil
+}
+
+// RegisterNotificationContact sets the phone number and email the patient of a QR token wants to
+// be notified at when their turn is near and when they are called
+func (s *Service) RegisterNotificationContact(ctx context.Context, qrToken string, contact *dto.NotificationContact) (*dto.PublicEntry, error) {
+	if _, err := s.queueService.RegisterContactByQRToken(ctx, qrToken, contact.GetPhone(), contact.GetEmail()); err != nil {
+		log.Printf("[QueueService] RegisterNotificationContact: Failed to register contact: %v", err)
+		switch {
+		case errors.Is(err, queue.ErrInvalidContact):
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, err.Error(), 400, nil)
+		case errors.Is(err, queue.ErrEntryNotFound):
+			return nil, ngErrors.New(ngErrors.NotFoundErrorCode, "queue entry not found", 404, nil)
+		case errors.Is(err, queue.ErrCannotRegisterContact):
+			return nil, ngErrors.New(ngErrors.BusinessErrorCode, "only waiting entries can register for notifications", 409, nil)
+		}
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to register contact", 500, nil)
+	}
+	return s.GetQueueEntryByToken(ctx, qrToken)
+}
+
 func (s *Service) CallNext(ctx context.Context, roomId string, servicePointId string) (*dto.QueueEntry, error) {
 
 	entry, err := s.queueService.CallNextForServicePoint(ctx, roomId, servicePointId)
 	if err != nil {
+		if errors.Is(err, queue.ErrQueuePaused) {
+			return nil, queuePausedError(err)
+		}
+		if errors.Is(err, queue.ErrServicePointNotClaimed) {
+			return nil, servicePointNotClaimedError(servicePointId)
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, ngErrors.QueueEntryConflict("the entry was called by another service point")
+		}
 		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to call next", 500, nil)
 	}
 
@@ -111,15 +251,9 @@ func (s *Service) CallNext(ctx context.Context, roomId string, servicePointId st
 	} else {
 		log.Printf("[QueueService] CallNext: WARNING: broadcastFunc is nil, cannot broadcast update")
 	}
+	s.notifyCall(ctx, entry, servicePointId)
 
-	// Send webhook notification for ticket called
-	if s.webhookService != nil {
-		go func() {
-			if err := s.webhookService.SendTicketCalledWebhook(ctx, entry.ID, roomId, servicePointId, ""); err != nil {
-				log.Printf("Failed to send webhook notification for ticket called: %v", err)
-			}
-		}()
-	}
+	s.sendEntryEvent(ctx, webhook.EventEntryCalled, entry, service.GetUserID(ctx), nil)
 
 	return &queueEntry, nil
 }
@@ -127,6 +261,9 @@ func (s *Service) CallNext(ctx context.Context, roomId string, servicePointId st
 func (s *Service) FinishCurrent(ctx context.Context, roomId string) (*dto.QueueEntry, error) {
 	entry, err := s.queueService.FinishCurrent(roomId)
 	if err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, ngErrors.QueueEntryConflict("the entry was finished by someone else")
+		}
 		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to finish current", 500, nil)
 	}
 
@@ -148,14 +285,7 @@ func (s *Service) FinishCurrent(ctx context.Context, roomId string) (*dto.QueueE
 		s.broadcastFunc(roomId, tenantID)
 	}
 
-	// Send webhook notification for ticket completed
-	if s.webhookService != nil {
-		go func() {
-			if err := s.webhookService.SendTicketCompletedWebhook(ctx, entry.ID, roomId, entry.ServicePoint, ""); err != nil {
-				log.Printf("Failed to send webhook notification for ticket completed: %v", err)
-			}
-		}()
-	}
+	s.sendEntryEvent(ctx, webhook.EventEntryCompleted, entry, service.GetUserID(ctx), nil)
 
 	return &queueEntry, nil
 }
@@ -163,6 +293,15 @@ func (s *Service) FinishCurrent(ctx context.Context, roomId string) (*dto.QueueE
 func (s *Service) CallSpecificEntry(ctx context.Context, entryId string, roomId string, servicePointId string) (*dto.QueueEntry, error) {
 	entry, err := s.queueService.CallSpecificEntryForServicePoint(ctx, roomId, servicePointId, entryId)
 	if err != nil {
+		if errors.Is(err, queue.ErrQueuePaused) {
+			return nil, queuePausedError(err)
+		}
+		if errors.Is(err, queue.ErrServicePointNotClaimed) {
+			return nil, servicePointNotClaimedError(servicePointId)
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, ngErrors.QueueEntryConflict("the entry was called by another service point")
+		}
 		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to call specific entry", 500, nil)
 	}
 
@@ -182,15 +321,9 @@ func (s *Service) CallSpecificEntry(ctx context.Context, entryId string, roomId
 	} else {
 		log.Printf("[QueueService] CallSpecificEntry: WARNING: broadcastFunc is nil, cannot broadcast update")
 	}
+	s.notifyCall(ctx, entry, servicePointId)
 
-	// Send webhook notification for ticket called
-	if s.webhookService != nil {
-		go func() {
-			if err := s.webhookService.SendTicketCalledWebhook(ctx, entry.ID, roomId, servicePointId, ""); err != nil {
-				log.Printf("Failed to send webhook notification for ticket called: %v", err)
-			}
-		}()
-	}
+	s.sendEntryEvent(ctx, webhook.EventEntryCalled, entry, service.GetUserID(ctx), nil)
 
 	return &queueEntry, nil
 }
@@ -212,37 +345,231 @@ func (s *Service) GetQueueEntries(ctx context.Context, roomId string, states []s
 	
 	log.Printf("[QueueService] GetQueueEntries returned %d entries for room %s", len(entries), roomId)
 
+	estimates, err := s.queueService.EstimateWaitTimes(ctx, roomId)
+	if err != nil {
+		log.Printf("[QueueService] Failed to estimate wait times for room %s: %v", roomId, err)
+	}
+
+	roomStates, err := s.queueService.GetRoomStates(ctx, roomId)
+	if err != nil {
+		log.Printf("[QueueService] Failed to get room states for room %s: %v", roomId, err)
+	}
+
 	// Convert to DTOs using the helper function
 	var queueEntries []dto.QueueEntry
 	for _, entry := range entries {
-		queueEntries = append(queueEntries, convertEntryToDTO(entry))
+		queueEntry := convertEntryToDTO(entry)
+		if estimate, ok := estimates[entry.ID]; ok {
+			queueEntry.EstimatedWaitSeconds = &estimate
+		}
+		setPausedFields(&queueEntry, roomStates, entry.ServicePoint)
+		queueEntries = append(queueEntries, queueEntry)
 	}
 
 	return queueEntries, nil
 }
 
+// GetPatientNames returns the names on the cards of the entries of a room in the given states by
+// entry ID, for the staff views of the queue. Entries without a name are left out.
+func (s *Service) GetPatientNames(ctx context.Context, roomId string, states []string) (map[string]string, error) {
+	entries, err := s.queueService.GetQueueEntriesWithContext(ctx, roomId, states)
+	if err != nil {
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to get queue entries", 500, nil)
+	}
+
+	names := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if name := strings.TrimSpace(entry.CardData.FirstName + " " + entry.CardData.LastName); name != "" {
+			names[entry.ID] = name
+		}
+	}
+	return names, nil
+}
+
 func (s *Service) GetServicePoints(ctx context.Context, roomId string) ([]dto.ServicePoint, error) {
 	return s.queueService.GetServicePoints(ctx, roomId)
 }
 
 func (s *Service) MarkInRoomForServicePoint(ctx context.Context, roomId, servicePointId string, req *dto.MarkInRoomRequest) (*dto.QueueEntry, error) {
-	return s.queueService.MarkInRoomForServicePoint(ctx, roomId, servicePointId, req.EntryID)
+	entry, err := s.queueService.MarkInRoomForServicePoint(ctx, roomId, servicePointId, req.EntryID)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return nil, ngErrors.QueueEntryConflict("the entry was changed while marking it in room")
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.sendQueueEntryEvent(ctx, webhook.EventEntryInRoom, entry, service.GetUserID(ctx))
+	return entry, nil
 }
 
 func (s *Service) FinishCurrentForServicePoint(ctx context.Context, roomId, servicePointId string) (*dto.QueueEntry, error) {
 	entry, err := s.queueService.FinishCurrentForServicePoint(ctx, roomId, servicePointId)
 	if err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, ngErrors.QueueEntryConflict("the entry was finished by someone else")
+		}
 		return nil, err
 	}
 
-	// Send webhook notification for ticket completed
-	if s.webhookService != nil {
-		go func() {
-			if err := s.webhookService.SendTicketCompletedWebhook(ctx, entry.ID, roomId, servicePointId, ""); err != nil {
-				log.Printf("Failed to send webhook notification for ticket completed: %v", err)
-			}
-		}()
-	}
+	s.sendQueueEntryEvent(ctx, webhook.EventEntryCompleted, entry, service.GetUserID(ctx))
 
 	return entry, nil
 }
+
+func (s *Service) TransferEntry(ctx context.Context, roomId string, entryId string, req *dto.TransferEntryRequest) (*dto.QueueEntry, error) {
+	if req.GetTargetRoomID() == "" && req.GetTargetServicePointID() == "" {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "targetRoomID or targetServicePointID is required", 400, nil)
+	}
+
+	entry, err := s.queueService.TransferEntry(ctx, roomId, entryId, req.GetTargetRoomID(), req.GetTargetServicePointID())
+	if err != nil {
+		log.Printf("[QueueService] TransferEntry: Failed to transfer entry %s from room %s: %v", entryId, roomId, err)
+		switch {
+		case errors.Is(err, queue.ErrEntryNotFound):
+			return nil, ngErrors.New(ngErrors.NotFoundErrorCode, "queue entry not found", 404, nil)
+		case errors.Is(err, queue.ErrInvalidTransfer):
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, err.Error(), 400, nil)
+		}
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to transfer entry", 500, nil)
+	}
+
+	queueEntry := convertEntryToDTO(entry)
+
+	// Broadcast queue update to both rooms of the tenant
+	if s.broadcastFunc != nil {
+		tenantID := service.GetTenantID(ctx)
+		s.broadcastFunc(entry.WaitingRoomID, tenantID)
+		if entry.WaitingRoomID != roomId {
+			s.broadcastFunc(roomId, tenantID)
+		}
+		log.Printf("[QueueService] TransferEntry: broadcastFunc called for rooms %s and %s, tenantID '%s'", roomId, entry.WaitingRoomID, tenantID)
+	} else {
+		log.Printf("[QueueService] TransferEntry: WARNING: broadcastFunc is nil, cannot broadcast update")
+	}
+
+	s.sendEntryEvent(ctx, webhook.EventEntryTransferred, entry, service.GetUserID(ctx), map[string]interface{}{"fromRoomId": roomId})
+
+	return &queueEntry, nil
+}
+
+func (s *Service) RecallEntry(ctx context.Context, roomId string, entryId string, req *dto.RecallEntryRequest) (*dto.QueueEntry, error) {
+	var tierAdjustment *int
+	if req.TierAdjustment != nil {
+		adjustment := int(*req.TierAdjustment)
+		tierAdjustment = &adjustment
+	}
+
+	entry, err := s.queueService.RecallEntry(ctx, roomId, entryId, tierAdjustment)
+	if err != nil {
+		log.Printf("[QueueService] RecallEntry: Failed to recall entry %s in room %s: %v", entryId, roomId, err)
+		switch {
+		case errors.Is(err, queue.ErrEntryNotFound):
+			return nil, ngErrors.New(ngErrors.NotFoundErrorCode, "queue entry not found", 404, nil)
+		case errors.Is(err, queue.ErrInvalidRecall):
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, err.Error(), 400, nil)
+		}
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to recall entry", 500, nil)
+	}
+
+	queueEntry := convertEntryToDTO(entry)
+
+	// Broadcast queue update - only to the tenant that changed
+	if s.broadcastFunc != nil {
+		tenantID := service.GetTenantID(ctx)
+		s.broadcastFunc(roomId, tenantID)
+		log.Printf("[QueueService] RecallEntry: broadcastFunc called for room %s, tenantID '%s'", roomId, tenantID)
+	} else {
+		log.Printf("[QueueService] RecallEntry: WARNING: broadcastFunc is nil, cannot broadcast update")
+	}
+
+	s.sendEntryEvent(ctx, webhook.EventEntryRecalled, entry, service.GetUserID(ctx), map[string]interface{}{"tier": entry.Tier})
+
+	return &queueEntry, nil
+}
+
+func (s *Service) BoostEntryPriority(ctx context.Context, roomId string, entryId string, req *dto.PriorityBoostRequest) (*dto.QueueEntry, error) {
+	entry, err := s.queueService.BoostEntryPriority(ctx, roomId, entryId, req.GetManualOverride(), req.Reason, req.ManagerId)
+	if err != nil {
+		log.Printf("[QueueService] BoostEntryPriority: Failed to boost entry %s in room %s: %v", entryId, roomId, err)
+		switch {
+		case errors.Is(err, queue.ErrEntryNotFound):
+			return nil, ngErrors.New(ngErrors.NotFoundErrorCode, "queue entry not found", 404, nil)
+		case errors.Is(err, queue.ErrInvalidBoost):
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, err.Error(), 400, nil)
+		case errors.Is(err, queue.ErrNotPermitted):
+			return nil, ngErrors.Forbidden("only a manager logged in at a service point of the room can change priorities", nil)
+		}
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to change entry priority", 500, nil)
+	}
+
+	queueEntry := convertEntryToDTO(entry)
+
+	// Broadcast queue update - only to the tenant that changed
+	if s.broadcastFunc != nil {
+		s.broadcastFunc(roomId, service.GetTenantID(ctx))
+	}
+
+	additionalData := map[string]interface{}{"manualOverride": req.GetManualOverride(), "reason": req.Reason}
+	s.sendEntryEvent(ctx, webhook.EventEntryPriorityBoosted, entry, req.ManagerId, additionalData)
+
+	return &queueEntry, nil
+}
+
+func (s *Service) AnnotateEntry(ctx context.Context, roomId string, entryId string, req *dto.AnnotateEntryRequest) (*dto.QueueEntry, error) {
+	entry, err := s.queueService.AnnotateEntry(ctx, roomId, entryId, req.Notes, req.Tags, service.GetUserID(ctx))
+	if err != nil {
+		log.Printf("[QueueService] AnnotateEntry: Failed to annotate entry %s in room %s: %v", entryId, roomId, err)
+		switch {
+		case errors.Is(err, queue.ErrEntryNotFound):
+			return nil, ngErrors.New(ngErrors.NotFoundErrorCode, "queue entry not found", 404, nil)
+		case errors.Is(err, queue.ErrInvalidAnnotation):
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, err.Error(), 400, nil)
+		}
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to annotate entry", 500, nil)
+	}
+
+	queueEntry := convertEntryToDTO(entry)
+
+	// Service point screens show the notes and tags
+	if s.broadcastFunc != nil {
+		s.broadcastFunc(roomId, service.GetTenantID(ctx))
+	}
+
+	return &queueEntry, nil
+}
+
+func (s *Service) GetEntryHistory(ctx context.Context, roomId string, entryId string) ([]dto.EntryHistoryEvent, error) {
+	events, err := s.queueService.GetEntryHistory(ctx, roomId, entryId)
+	if err != nil {
+		log.Printf("[QueueService] GetEntryHistory: Failed to get history of entry %s in room %s: %v", entryId, roomId, err)
+		if errors.Is(err, queue.ErrEntryNotFound) {
+			return nil, ngErrors.New(ngErrors.NotFoundErrorCode, "queue entry not found", 404, nil)
+		}
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to get entry history", 500, nil)
+	}
+
+	history := make([]dto.EntryHistoryEvent, 0, len(events))
+	for _, event := range events {
+		historyEvent := dto.EntryHistoryEvent{
+			EntryId:   event.EntryID,
+			Event:     event.Event,
+			RoomId:    event.RoomID,
+			Timestamp: event.Timestamp,
+			ToStatus:  event.ToStatus,
+		}
+		if event.ActorID != "" {
+			historyEvent.ActorId = &event.ActorID
+		}
+		if event.FromStatus != "" {
+			historyEvent.FromStatus = &event.FromStatus
+		}
+		if event.ServicePointID != "" {
+			historyEvent.ServicePointId = &event.ServicePointID
+		}
+		if event.Reason != "" {
+			historyEvent.Reason = &event.Reason
+		}
+		history = append(history, historyEvent)
+	}
+	return history, nil
+}
@@ -0,0 +1,100 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	"github.com/arfis/waiting-room/internal/data/dto/webhookdeliverystatus"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/service/webhook"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// maxWebhookDeliveries is the most deliveries one listing returns
+const maxWebhookDeliveries = 1000
+
+// GetWebhookDeliveries returns the webhook deliveries of the tenant with the status, FAILED by
+// default, newest first
+func (s *Service) GetWebhookDeliveries(ctx context.Context, status *string, limit *int32) ([]dto.WebhookDelivery, error) {
+	if s.webhookService == nil {
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "webhooks are not available", 500, nil)
+	}
+
+	deliveryStatus := webhookdeliverystatus.FAILED
+	if status != nil && *status != "" {
+		var err error
+		if deliveryStatus, err = webhookdeliverystatus.StringToWebhookDeliveryStatus(*status); err != nil {
+			return nil, err
+		}
+	}
+	max := 100
+	if limit != nil {
+		if *limit < 1 || *limit > maxWebhookDeliveries {
+			return nil, ngErrors.New(ngErrors.ValidationErrorCode, fmt.Sprintf("limit must be between 1 and %d", maxWebhookDeliveries), 400, nil)
+		}
+		max = int(*limit)
+	}
+
+	deliveries, err := s.webhookService.GetDeliveries(ctx, deliveryStatus.String(), max)
+	if err != nil {
+		log.Printf("[QueueService] GetWebhookDeliveries failed: %v", err)
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to get webhook deliveries", 500, nil)
+	}
+
+	result := make([]dto.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		result = append(result, convertWebhookDelivery(delivery))
+	}
+	return result, nil
+}
+
+// ReplayWebhookDelivery queues a delivered or failed webhook delivery of the tenant again
+func (s *Service) ReplayWebhookDelivery(ctx context.Context, deliveryId string) (*dto.WebhookDelivery, error) {
+	if s.webhookService == nil {
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "webhooks are not available", 500, nil)
+	}
+
+	delivery, err := s.webhookService.ReplayDelivery(ctx, deliveryId)
+	if err != nil {
+		log.Printf("[QueueService] ReplayWebhookDelivery failed: %v", err)
+		switch {
+		case errors.Is(err, webhook.ErrDeliveryNotFound):
+			return nil, ngErrors.New(ngErrors.NotFoundErrorCode, err.Error(), 404, nil)
+		case errors.Is(err, webhook.ErrDeliveryPending):
+			return nil, ngErrors.New(ngErrors.BusinessErrorCode, err.Error(), 409, nil)
+		}
+		return nil, ngErrors.New(ngErrors.InternalServerErrorCode, "failed to replay webhook delivery", 500, nil)
+	}
+
+	result := convertWebhookDelivery(delivery)
+	return &result, nil
+}
+
+// convertWebhookDelivery converts a stored webhook delivery to its DTO
+func convertWebhookDelivery(delivery *types.WebhookDelivery) dto.WebhookDelivery {
+	result := dto.WebhookDelivery{
+		Attempts:    int64(delivery.Attempts),
+		CreatedAt:   &delivery.CreatedAt,
+		DeliveredAt: delivery.DeliveredAt,
+		Event:       delivery.Event,
+		Id:          delivery.ID,
+		MaxAttempts: int64(delivery.MaxAttempts),
+		Payload:     &delivery.Payload,
+		Status:      webhookdeliverystatus.WebhookDeliveryStatus(delivery.Status),
+		Url:         delivery.URL,
+	}
+	if delivery.Status == types.WebhookDeliveryPending {
+		result.NextAttemptAt = &delivery.NextAttemptAt
+	}
+	if delivery.LastError != "" {
+		result.LastError = &delivery.LastError
+	}
+	if delivery.LastStatusCode != 0 {
+		statusCode := int64(delivery.LastStatusCode)
+		result.LastStatusCode = &statusCode
+	}
+	return result
+}
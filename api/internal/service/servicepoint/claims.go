@@ -0,0 +1,133 @@
+package servicepoint
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/service"
+)
+
+// activeTimeout is how long a manager counts as serving at a service point after they were last
+// seen; the cleanup routine releases claims older than this
+const activeTimeout = 5 * time.Minute
+
+// isActive reports whether a status is an available manager seen within activeTimeout
+func isActive(status *dto.ManagerStatus) bool {
+	return status.IsAvailable && time.Since(status.LastSeen) < activeTimeout
+}
+
+// ClaimServicePoint opens servicePointID of roomID for the staff member of the request, who then
+// serves there until they release it or are inactive for activeTimeout. Claiming again keeps the
+// claim alive; claiming another service point releases the previous one. A service point served
+// by another active staff member cannot be claimed.
+func (s *Service) ClaimServicePoint(ctx context.Context, roomID string, servicePointID string) (*dto.ManagerStatus, error) {
+	userID := service.GetUserID(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if operator := s.activeOperator(roomID, servicePointID); operator != nil && operator.ManagerID != userID {
+		return nil, ngErrors.New(ngErrors.BusinessErrorCode,
+			fmt.Sprintf("service point %s is served by %s", servicePointID, operator.ManagerName), 409,
+			map[string]interface{}{"managerID": operator.ManagerID})
+	}
+
+	now := time.Now()
+	status, exists := s.managerStatus[userID]
+	if !exists || !isActive(status) || status.RoomID != roomID || status.ServicePointID != servicePointID {
+		status = &dto.ManagerStatus{
+			ManagerID:      userID,
+			ManagerName:    s.getManagerName(roomID, servicePointID, userID),
+			RoomID:         roomID,
+			ServicePointID: servicePointID,
+			ClaimedAt:      &now,
+		}
+		s.managerStatus[userID] = status
+		log.Printf("Staff %s (%s) claimed service point %s in room %s", status.ManagerName, userID, servicePointID, roomID)
+	}
+	status.IsAvailable = true
+	status.LastSeen = now
+
+	claimed := *status
+	return &claimed, nil
+}
+
+// ReleaseServicePoint closes servicePointID of roomID claimed by the staff member of the request
+func (s *Service) ReleaseServicePoint(ctx context.Context, roomID string, servicePointID string) (*dto.ManagerStatus, error) {
+	userID := service.GetUserID(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status, exists := s.managerStatus[userID]
+	if !exists || !status.IsAvailable || status.RoomID != roomID || status.ServicePointID != servicePointID {
+		return nil, ngErrors.New(ngErrors.NotFoundErrorCode,
+			fmt.Sprintf("service point %s is not claimed by %s", servicePointID, userID), 404, nil)
+	}
+
+	status.IsAvailable = false
+	status.LastSeen = time.Now()
+	log.Printf("Staff %s (%s) released service point %s in room %s", status.ManagerName, userID, servicePointID, roomID)
+
+	released := *status
+	return &released, nil
+}
+
+// GetServicePointSessions returns the active claims of all rooms, ordered by room and service
+// point, for the admin dashboard
+func (s *Service) GetServicePointSessions(ctx context.Context) ([]dto.ManagerStatus, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sessions := []dto.ManagerStatus{}
+	for _, status := range s.managerStatus {
+		if isActive(status) {
+			sessions = append(sessions, *status)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].RoomID != sessions[j].RoomID {
+			return sessions[i].RoomID < sessions[j].RoomID
+		}
+		return sessions[i].ServicePointID < sessions[j].ServicePointID
+	})
+	return sessions, nil
+}
+
+// HasActiveOperator reports whether a staff member serves at servicePointID of roomID
+func (s *Service) HasActiveOperator(roomID, servicePointID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.activeOperator(roomID, servicePointID) != nil
+}
+
+// HasActiveOperators reports whether a staff member serves at any service point of roomID
+func (s *Service) HasActiveOperators(roomID string) bool {
+	return s.CountActiveServicePoints(roomID) > 0
+}
+
+// Touch keeps the claim of a staff member alive, e.g. when they call the next patient
+func (s *Service) Touch(managerID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if status, exists := s.managerStatus[managerID]; exists && isActive(status) {
+		status.LastSeen = time.Now()
+	}
+}
+
+// activeOperator returns the active manager at servicePointID of roomID, or nil; s.mu must be held
+func (s *Service) activeOperator(roomID, servicePointID string) *dto.ManagerStatus {
+	for _, status := range s.managerStatus {
+		if status.RoomID == roomID && status.ServicePointID == servicePointID && isActive(status) {
+			return status
+		}
+	}
+	return nil
+}
@@ -34,13 +34,15 @@ func (s *Service) SetManagerAvailable(ctx context.Context, managerID, roomID, se
 	// Get manager name from config
 	managerName := s.getManagerName(roomID, servicePointID, managerID)
 
+	now := time.Now()
 	status := &dto.ManagerStatus{
 		ManagerID:      managerID,
 		ManagerName:    managerName,
 		ServicePointID: servicePointID,
 		RoomID:         roomID,
 		IsAvailable:    true,
-		LastSeen:       time.Now(),
+		LastSeen:       now,
+		ClaimedAt:      &now,
 	}
 
 	s.managerStatus[managerID] = status
@@ -79,8 +81,8 @@ func (s *Service) GetAvailableServicePoint(ctx context.Context, roomID string) (
 		}
 
 		if status, exists := s.managerStatus[sp.ManagerID]; exists && status.IsAvailable {
-			// Check if manager was seen recently (within last 5 minutes)
-			if time.Since(status.LastSeen) < 5*time.Minute {
+			// Check if manager was seen recently (within activeTimeout)
+			if time.Since(status.LastSeen) < activeTimeout {
 				log.Printf("Found available service point %s with manager %s for room %s",
 					sp.ID, status.ManagerName, roomID)
 				return sp.ID, nil
@@ -126,13 +128,45 @@ func (s *Service) GetManagerStatusForRoom(ctx context.Context, roomID string) ([
 	return statuses, nil
 }
 
-// CleanupInactiveManagers removes managers that haven't been seen for more than 10 minutes
+// IsManagerActive reports whether a manager is logged in at a service point of roomID and was
+// seen within activeTimeout
+func (s *Service) IsManagerActive(managerID, roomID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status, exists := s.managerStatus[managerID]
+	return exists && status.RoomID == roomID && isActive(status)
+}
+
+// CountActiveServicePoints returns the number of service points in a room with an available
+// manager seen within activeTimeout
+func (s *Service) CountActiveServicePoints(roomID string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	active := make(map[string]bool)
+	for _, status := range s.managerStatus {
+		if status.RoomID == roomID && isActive(status) {
+			active[status.ServicePointID] = true
+		}
+	}
+
+	return len(active)
+}
+
+// CleanupInactiveManagers releases the service points of managers not seen within activeTimeout
+// and removes managers that haven't been seen for more than 10 minutes
 func (s *Service) CleanupInactiveManagers(ctx context.Context) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	cutoff := time.Now().Add(-10 * time.Minute)
 	for managerID, status := range s.managerStatus {
+		if status.IsAvailable && !isActive(status) {
+			status.IsAvailable = false
+			log.Printf("Releasing service point %s in room %s of inactive manager %s (%s) - last seen: %s",
+				status.ServicePointID, status.RoomID, status.ManagerName, managerID, status.LastSeen.Format(time.RFC3339))
+		}
 		if status.LastSeen.Before(cutoff) {
 			log.Printf("Removing inactive manager %s (%s) - last seen: %s",
 				status.ManagerName, managerID, status.LastSeen.Format(time.RFC3339))
@@ -154,7 +188,7 @@ func (s *Service) getManagerName(roomID, servicePointID, managerID string) strin
 
 // StartCleanupRoutine starts a background routine to clean up inactive managers
 func (s *Service) StartCleanupRoutine(ctx context.Context) {
-	ticker := time.NewTicker(5 * time.Minute)
+	ticker := time.NewTicker(time.Minute)
 	go func() {
 		defer ticker.Stop()
 		for {
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 
 	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/types"
 )
 
 // GetTenantID returns tenant ID as string from context (used by middleware.TENANT)
@@ -19,3 +20,20 @@ func GetTenantID(ctx context.Context) string {
 	slog.Error("tenant in context is not string", "tenant", tenant)
 	return ""
 }
+
+// GetTenant returns the tenant the TenantMiddleware resolved from the registry, nil outside of a
+// request with a tenant
+func GetTenant(ctx context.Context) *types.Tenant {
+	tenant, _ := ctx.Value(middleware.RESOLVED_TENANT).(*types.Tenant)
+	return tenant
+}
+
+// GetTenantScope returns the building and section of the tenant in context: the resolved tenant
+// of a request, or the ID set by background jobs
+func GetTenantScope(ctx context.Context) (buildingID, sectionID string) {
+	if tenant := GetTenant(ctx); tenant != nil {
+		return tenant.BuildingID, tenant.SectionID
+	}
+	buildingID, sectionID, _ = types.ParseTenantID(GetTenantID(ctx))
+	return buildingID, sectionID
+}
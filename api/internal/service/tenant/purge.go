@@ -0,0 +1,148 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// DataKeyDeleter deletes the data key the card data of a building is encrypted with
+type DataKeyDeleter interface {
+	DeleteDataKey(ctx context.Context, tenantID string) error
+}
+
+// purgeStep deletes one kind of data of the tenant in the context
+type purgeStep struct {
+	name  string
+	purge func(ctx context.Context) error
+}
+
+// OnPurge registers purge to delete the data of the tenant in the context that is kept outside the
+// queue and configuration repositories, such as appointments or webhook deliveries, when the tenant
+// is purged. A failing step keeps the tenant for the next run, so steps must be safe to repeat.
+func (s *Service) OnPurge(name string, purge func(ctx context.Context) error) {
+	s.purgeMu.Lock()
+	defer s.purgeMu.Unlock()
+	s.purgeSteps = append(s.purgeSteps, purgeStep{name: name, purge: purge})
+}
+
+// SetDataKeys sets where the data key of a building is deleted once its last tenant was purged, so
+// the card data left in backups can't be decrypted anymore
+func (s *Service) SetDataKeys(dataKeys DataKeyDeleter) {
+	s.purgeMu.Lock()
+	defer s.purgeMu.Unlock()
+	s.dataKeys = dataKeys
+}
+
+// StartPurgeRoutine starts a background routine that purges the tenants deactivated more than the
+// configured number of days ago every night at the configured hour. It does nothing when the purge
+// is disabled.
+func (s *Service) StartPurgeRoutine(ctx context.Context, cfg config.TenantsConfig) {
+	if cfg.PurgeAfterDays <= 0 {
+		log.Printf("[TenantService] Purge of deactivated tenants disabled")
+		return
+	}
+	retention := time.Duration(cfg.PurgeAfterDays) * 24 * time.Hour
+	go func() {
+		for {
+			timer := time.NewTimer(time.Until(nextPurgeRun(time.Now(), cfg.PurgeHour)))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				s.PurgeDeactivatedTenants(ctx, time.Now().Add(-retention))
+			}
+		}
+	}()
+	log.Printf("[TenantService] Purge of deactivated tenants started (after %d days, at %02d:00)", cfg.PurgeAfterDays, cfg.PurgeHour)
+}
+
+// purgeDataKey deletes the data key of the building of tenant unless another of its tenants, active
+// or not purged yet, still has card data encrypted with it
+func (s *Service) purgeDataKey(ctx context.Context, dataKeys DataKeyDeleter, tenant *types.Tenant) error {
+	tenants, err := s.repo.GetAllTenants(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load tenants: %w", err)
+	}
+	for _, other := range tenants {
+		if other.BuildingID == tenant.BuildingID && other.SectionID != tenant.SectionID {
+			return nil
+		}
+	}
+	if err := dataKeys.DeleteDataKey(ctx, tenant.BuildingID); err != nil {
+		return err
+	}
+	log.Printf("[TenantService] Deleted the data key of building %s", tenant.BuildingID)
+	return nil
+}
+
+// nextPurgeRun returns the next time after now at hour:00 local time
+func nextPurgeRun(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// PurgeDeactivatedTenants deletes the tenants deactivated before the cutoff with their entries,
+// entry history, ticket counters, room states, configuration and card readers, the data of the
+// steps registered with OnPurge and, with the last tenant of a building, its data key. Their audit
+// logs are kept as the record of who did what, and cached translations in memory expire on their
+// own. A tenant whose data can't be deleted keeps its record, so the next run tries again.
+func (s *Service) PurgeDeactivatedTenants(ctx context.Context, before time.Time) {
+	tenants, err := s.repo.GetAllTenants(ctx)
+	if err != nil {
+		log.Printf("[TenantService] Purge failed to load tenants: %v", err)
+		return
+	}
+	for i := range tenants {
+		tenant := &tenants[i]
+		if !tenant.IsDeactivated() || !tenant.DeactivatedAt.Before(before) {
+			continue
+		}
+		if err := s.purgeTenant(ctx, tenant); err != nil {
+			log.Printf("[TenantService] Purge of tenant %s failed: %v", tenant.GetFullTenantID(), err)
+		}
+	}
+}
+
+func (s *Service) purgeTenant(ctx context.Context, tenant *types.Tenant) error {
+	tenantCtx := context.WithValue(ctx, middleware.TENANT, tenant.GetFullTenantID())
+	entries := 0
+	if s.queue != nil {
+		var err error
+		if entries, err = s.queue.PurgeTenantEntries(tenantCtx); err != nil {
+			return err
+		}
+	}
+
+	s.purgeMu.Lock()
+	steps := s.purgeSteps
+	dataKeys := s.dataKeys
+	s.purgeMu.Unlock()
+	for _, step := range steps {
+		if err := step.purge(tenantCtx); err != nil {
+			return fmt.Errorf("failed to purge %s: %w", step.name, err)
+		}
+	}
+	if dataKeys != nil {
+		if err := s.purgeDataKey(ctx, dataKeys, tenant); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.PurgeTenant(tenantCtx, tenant); err != nil {
+		return err
+	}
+	s.invalidateRegistry()
+	s.recordChange(ctx, types.AuditActionDelete, tenant, nil)
+	log.Printf("[TenantService] Purged tenant %s deactivated at %s with %d entries", tenant.GetFullTenantID(), tenant.DeactivatedAt.Format(time.RFC3339), entries)
+	return nil
+}
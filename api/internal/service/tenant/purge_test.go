@@ -0,0 +1,119 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/service"
+	"github.com/arfis/waiting-room/internal/service/audit"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// deletedKeys records the buildings whose data key was deleted
+type deletedKeys []string
+
+func (d *deletedKeys) DeleteDataKey(ctx context.Context, tenantID string) error {
+	*d = append(*d, tenantID)
+	return nil
+}
+
+// withTenants adds tenants to the ones of the repository, like the other sections of a building
+type withTenants struct {
+	repository.ConfigRepository
+	extra []types.Tenant
+}
+
+func (r withTenants) GetAllTenants(ctx context.Context) ([]types.Tenant, error) {
+	tenants, err := r.ConfigRepository.GetAllTenants(ctx)
+	return append(tenants, r.extra...), err
+}
+
+func newPurgeService(t *testing.T, extra ...types.Tenant) (*Service, repository.ConfigRepository) {
+	t.Helper()
+	repo := withTenants{ConfigRepository: repository.NewEmbeddedConfigRepository(), extra: extra}
+	deactivatedAt := time.Now().Add(-48 * time.Hour)
+	for _, tenant := range []*types.Tenant{
+		{BuildingID: "hospital", SectionID: "er", DeactivatedAt: &deactivatedAt},
+		{BuildingID: "clinic", SectionID: "er"},
+	} {
+		if err := repo.CreateTenant(context.Background(), tenant); err != nil {
+			t.Fatalf("CreateTenant failed: %v", err)
+		}
+	}
+	return NewService(repo, audit.New(repository.NewMockAuditLogRepository()), nil), repo
+}
+
+// TestPurgeDeactivatedTenants tests that the purge runs the registered steps within the purged
+// tenant and deletes the data key of its building
+func TestPurgeDeactivatedTenants(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newPurgeService(t)
+	var purged []string
+	svc.OnPurge("appointments", func(ctx context.Context) error {
+		purged = append(purged, service.GetTenantID(ctx))
+		return nil
+	})
+	keys := &deletedKeys{}
+	svc.SetDataKeys(keys)
+
+	svc.PurgeDeactivatedTenants(ctx, time.Now().Add(-24*time.Hour))
+
+	if len(purged) != 1 || purged[0] != "hospital:er" {
+		t.Errorf("Expected the step to run for hospital:er only, got %v", purged)
+	}
+	if len(*keys) != 1 || (*keys)[0] != "hospital" {
+		t.Errorf("Expected the data key of hospital to be deleted, got %v", *keys)
+	}
+	if tenant, _ := repo.GetTenant(ctx, "hospital"); tenant != nil {
+		t.Errorf("Expected the deactivated tenant to be purged")
+	}
+	if tenant, _ := repo.GetTenant(ctx, "clinic"); tenant == nil {
+		t.Errorf("Expected the active tenant to be kept")
+	}
+}
+
+// TestPurgeKeepsSharedDataKey tests that the data key of a building is kept while another of its
+// sections remains
+func TestPurgeKeepsSharedDataKey(t *testing.T) {
+	svc, _ := newPurgeService(t, types.Tenant{BuildingID: "hospital", SectionID: "icu"})
+	keys := &deletedKeys{}
+	svc.SetDataKeys(keys)
+
+	svc.PurgeDeactivatedTenants(context.Background(), time.Now().Add(-24*time.Hour))
+
+	if len(*keys) != 0 {
+		t.Errorf("Expected the data key shared with hospital:icu to be kept, got %v deleted", *keys)
+	}
+}
+
+// TestPurgeStepFailureKeepsTenant tests that a tenant whose data can't be deleted is purged by a later run
+func TestPurgeStepFailureKeepsTenant(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newPurgeService(t)
+	failing := true
+	svc.OnPurge("webhook deliveries", func(ctx context.Context) error {
+		if failing {
+			return errors.New("database unavailable")
+		}
+		return nil
+	})
+	keys := &deletedKeys{}
+	svc.SetDataKeys(keys)
+
+	svc.PurgeDeactivatedTenants(ctx, time.Now().Add(-24*time.Hour))
+	if tenant, _ := repo.GetTenant(ctx, "hospital"); tenant == nil {
+		t.Fatalf("Expected the tenant to be kept when a step fails")
+	}
+	if len(*keys) != 0 {
+		t.Errorf("Expected the data key to be kept when a step fails, got %v deleted", *keys)
+	}
+
+	failing = false
+	svc.PurgeDeactivatedTenants(ctx, time.Now().Add(-24*time.Hour))
+	if tenant, _ := repo.GetTenant(ctx, "hospital"); tenant != nil {
+		t.Errorf("Expected the next run to purge the tenant")
+	}
+}
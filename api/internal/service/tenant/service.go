@@ -3,19 +3,43 @@ package tenant
 import (
 	"context"
 	"fmt"
+	"log"
+	"maps"
+	"sync"
+	"time"
 
 	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/queue"
 	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/service/audit"
 	"github.com/arfis/waiting-room/internal/types"
 )
 
+// registryCacheTTL is how long the tenant registry is kept in memory; changes made on this
+// replica apply right away, the ones of other replicas within it
+const registryCacheTTL = 30 * time.Second
+
 type Service struct {
-	repo repository.ConfigRepository
+	repo  repository.ConfigRepository
+	audit *audit.Service
+	queue *queue.WaitingQueue
+
+	mu       sync.Mutex
+	registry []types.Tenant
+	loadedAt time.Time
+
+	purgeMu    sync.Mutex
+	purgeSteps []purgeStep
+	dataKeys   DataKeyDeleter
 }
 
-func NewService(repo repository.ConfigRepository) *Service {
+func NewService(repo repository.ConfigRepository, auditService *audit.Service, waitingQueue *queue.WaitingQueue) *Service {
 	return &Service{
-		repo: repo,
+		repo:  repo,
+		audit: auditService,
+		queue: waitingQueue,
 	}
 }
 
@@ -42,6 +66,49 @@ func (s *Service) CreateTenant(ctx context.Context, tenantDTO *dto.Tenant) (*dto
 	if err != nil {
 		return nil, err
 	}
+	s.invalidateRegistry()
+	s.recordChange(ctx, types.AuditActionCreate, nil, tenant)
+
+	return s.convertToDTO(tenant), nil
+}
+
+// ProvisionTenant creates a tenant together with the configuration fields of its layer and the
+// API keys of its devices; none of them is saved when one fails
+func (s *Service) ProvisionTenant(ctx context.Context, tenantDTO *dto.Tenant, updates map[string]interface{}, apiKeys []types.APIKey) (*dto.Tenant, error) {
+	tenant := &types.Tenant{
+		BuildingID:  tenantDTO.BuildingId,
+		SectionID:   tenantDTO.SectionId,
+		Name:        tenantDTO.Name,
+		Description: getStringValue(tenantDTO.Description),
+	}
+
+	// Validate tenant ID format
+	if tenant.BuildingID == "" || tenant.SectionID == "" {
+		return nil, fmt.Errorf("building ID and section ID are required")
+	}
+	if _, _, err := types.ParseTenantID(tenant.GetFullTenantID()); err != nil {
+		return nil, err
+	}
+
+	fields := maps.Clone(updates)
+	fields["apiKeys"] = apiKeys
+	if err := s.repo.ProvisionTenant(ctx, tenant, fields); err != nil {
+		return nil, err
+	}
+	s.invalidateRegistry()
+	s.recordChange(ctx, types.AuditActionCreate, nil, tenant)
+
+	tenantCtx := context.WithValue(ctx, middleware.TENANT, tenant.GetFullTenantID())
+	config, err := s.repo.GetSystemConfiguration(tenantCtx)
+	if err != nil {
+		log.Printf("[TenantService] Failed to read provisioned configuration for the audit log: %v", err)
+	} else {
+		s.audit.Record(tenantCtx, types.AuditResourceSystemConfiguration, types.AuditActionCreate, "", nil, config)
+	}
+	for i := range apiKeys {
+		s.audit.Record(tenantCtx, types.AuditResourceAPIKey, types.AuditActionCreate, apiKeys[i].ID, nil, &apiKeys[i])
+	}
+	log.Printf("[TenantService] Provisioned tenant %s with %d API keys", tenant.GetFullTenantID(), len(apiKeys))
 
 	return s.convertToDTO(tenant), nil
 }
@@ -96,17 +163,150 @@ func (s *Service) UpdateTenant(ctx context.Context, tenantDTO *dto.Tenant) (*dto
 		return nil, err
 	}
 
-	err := s.repo.UpdateTenant(ctx, tenant)
+	before, err := s.repo.GetTenant(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
+	// Deactivation is changed by DeleteTenant and ReactivateTenant only
+	if before != nil {
+		tenant.DeactivatedAt = before.DeactivatedAt
+	}
+	err = s.repo.UpdateTenant(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateRegistry()
+	s.recordChange(ctx, types.AuditActionUpdate, before, tenant)
 
 	return s.convertToDTO(tenant), nil
 }
 
-// DeleteTenant deletes a tenant and its configuration
+// DeleteTenant deactivates a tenant: it stops taking swipes and WebSocket subscriptions, and its
+// data is kept until the purge job deletes it after the retention period
 func (s *Service) DeleteTenant(ctx context.Context, tenantID string) error {
-	return s.repo.DeleteTenant(ctx, tenantID)
+	before, err := s.repo.GetTenant(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return ngErrors.EntityNotFound()
+	}
+	if before.IsDeactivated() {
+		return nil
+	}
+
+	tenant := *before
+	now := time.Now()
+	tenant.DeactivatedAt = &now
+	if err := s.repo.UpdateTenant(ctx, &tenant); err != nil {
+		return err
+	}
+	s.invalidateRegistry()
+	s.recordChange(ctx, types.AuditActionDeactivate, before, &tenant)
+	log.Printf("[TenantService] Deactivated tenant %s", tenant.GetFullTenantID())
+	return nil
+}
+
+// ReactivateTenant undoes the deactivation of a tenant whose data was not purged yet
+func (s *Service) ReactivateTenant(ctx context.Context, tenantID string) (*dto.Tenant, error) {
+	before, err := s.repo.GetTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if before == nil {
+		return nil, ngErrors.EntityNotFound()
+	}
+	if !before.IsDeactivated() {
+		return s.convertToDTO(before), nil
+	}
+
+	tenant := *before
+	tenant.DeactivatedAt = nil
+	if err := s.repo.UpdateTenant(ctx, &tenant); err != nil {
+		return nil, err
+	}
+	s.invalidateRegistry()
+	s.recordChange(ctx, types.AuditActionReactivate, before, &tenant)
+	log.Printf("[TenantService] Reactivated tenant %s", tenant.GetFullTenantID())
+	return s.convertToDTO(&tenant), nil
+}
+
+// ResolveTenant returns the registered tenant of a "buildingId:sectionId" ID, nil when there is
+// none. A building ID alone resolves to the building, without section, when one of its sections
+// is registered. Until the first tenant is registered, every well-formed ID resolves, so
+// deployments that don't use the registry keep working.
+func (s *Service) ResolveTenant(ctx context.Context, tenantID string) (*types.Tenant, error) {
+	buildingID, sectionID, err := types.ParseTenantID(tenantID)
+	if err != nil {
+		return nil, nil
+	}
+	registry, err := s.getRegistry(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(registry) == 0 {
+		return &types.Tenant{ID: buildingID, BuildingID: buildingID, SectionID: sectionID}, nil
+	}
+
+	for _, tenant := range registry {
+		if tenant.BuildingID != buildingID {
+			continue
+		}
+		if sectionID == "" {
+			return &types.Tenant{ID: tenant.ID, BuildingID: tenant.BuildingID, Name: tenant.Name, DeactivatedAt: tenant.DeactivatedAt}, nil
+		}
+		if tenant.SectionID == sectionID {
+			resolved := tenant
+			return &resolved, nil
+		}
+	}
+	return nil, nil
+}
+
+// getRegistry returns the registered tenants, from memory unless registryCacheTTL passed
+func (s *Service) getRegistry(ctx context.Context) ([]types.Tenant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		return nil, nil
+	}
+	if s.registry != nil && time.Since(s.loadedAt) < registryCacheTTL {
+		return s.registry, nil
+	}
+
+	tenants, err := s.repo.GetAllTenants(ctx)
+	if err != nil {
+		// A registry that can't be read doesn't lock every tenant out until it can
+		if s.registry != nil {
+			log.Printf("[TenantService] Failed to reload tenant registry, using the one of %s: %v", s.loadedAt.Format(time.RFC3339), err)
+			return s.registry, nil
+		}
+		return nil, fmt.Errorf("failed to load tenant registry: %w", err)
+	}
+	if tenants == nil {
+		tenants = []types.Tenant{}
+	}
+	s.registry = tenants
+	s.loadedAt = time.Now()
+	return s.registry, nil
+}
+
+// invalidateRegistry makes the next resolution reload the registry
+func (s *Service) invalidateRegistry() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.registry = nil
+}
+
+// recordChange records the change of a tenant in its own audit log, whichever tenant the request
+// was made for
+func (s *Service) recordChange(ctx context.Context, action string, before, after *types.Tenant) {
+	tenant := after
+	if tenant == nil {
+		tenant = before
+	}
+	tenantCtx := context.WithValue(ctx, middleware.TENANT, tenant.GetFullTenantID())
+	s.audit.Record(tenantCtx, types.AuditResourceTenant, action, tenant.GetTenantID(), before, after)
 }
 
 // Helper function to convert types.Tenant to dto.Tenant
@@ -130,6 +330,8 @@ func (s *Service) convertToDTO(tenant *types.Tenant) *dto.Tenant {
 		dtoResult.UpdatedAt = &tenant.UpdatedAt
 	}
 
+	dtoResult.DeactivatedAt = tenant.DeactivatedAt
+
 	return dtoResult
 }
 
@@ -0,0 +1,73 @@
+package translation
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+const (
+	// maxBatchSize is the number of texts sent in one request; DeepL accepts up to 50
+	maxBatchSize = 50
+	// batchWorkers is the number of batch requests of one call sent at once
+	batchWorkers = 4
+)
+
+// BatchTranslator is implemented by providers translating many texts at once more cheaply than
+// one by one
+type BatchTranslator interface {
+	// TranslateBatch returns the translations in the order of the texts and the error of every
+	// text that failed, which is returned unchanged
+	TranslateBatch(ctx context.Context, texts []string, sourceLang, targetLang string) ([]string, []error)
+}
+
+// TranslateTexts translates the texts with the provider, in batches when it supports them. It
+// returns the translations in the order of the texts and the error of every text that failed,
+// which is returned unchanged.
+func TranslateTexts(ctx context.Context, provider TranslationProvider, texts []string, sourceLang, targetLang string) ([]string, []error) {
+	if batcher, ok := provider.(BatchTranslator); ok {
+		return batcher.TranslateBatch(ctx, texts, sourceLang, targetLang)
+	}
+
+	translations := slices.Clone(texts)
+	errs := make([]error, len(texts))
+	for i, text := range texts {
+		translations[i], errs[i] = provider.Translate(ctx, text, sourceLang, targetLang)
+	}
+	return translations, errs
+}
+
+// translateInBatches splits the texts into batches of up to maxBatchSize, translates up to
+// batchWorkers of them at once with translate and puts the translations back in order. A failed
+// batch returns its texts unchanged with the error of the batch.
+func translateInBatches(ctx context.Context, texts []string, translate func(ctx context.Context, batch []string) ([]string, error)) ([]string, []error) {
+	translations := slices.Clone(texts)
+	errs := make([]error, len(texts))
+
+	starts := make(chan int)
+	var wg sync.WaitGroup
+	for range min(batchWorkers, (len(texts)+maxBatchSize-1)/maxBatchSize) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Every batch writes its own range of the results
+			for start := range starts {
+				end := min(start+maxBatchSize, len(texts))
+				translated, err := translate(ctx, texts[start:end])
+				if err != nil {
+					for i := start; i < end; i++ {
+						errs[i] = err
+					}
+					continue
+				}
+				copy(translations[start:end], translated)
+			}
+		}()
+	}
+	for start := 0; start < len(texts); start += maxBatchSize {
+		starts <- start
+	}
+	close(starts)
+	wg.Wait()
+	return translations, errs
+}
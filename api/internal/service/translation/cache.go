@@ -1,16 +1,28 @@
 package translation
 
 import (
+	"context"
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"log"
 	"sync"
 	"time"
+
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/service"
+	"github.com/arfis/waiting-room/internal/types"
 )
 
-// CacheEntry represents a cached translation - todo: for now it is being saved in memory, but in the future it should be saved in a database
+// storeTimeout bounds the calls of the persistent cache, so a slow database falls back to the
+// translation provider
+const storeTimeout = 2 * time.Second
+
+// CacheEntry represents a cached translation of a tenant
 type CacheEntry struct {
+	TenantID       string
+	SectionID      string
 	SourceText     string
 	TargetText     string
 	SourceLang     string
@@ -20,10 +32,12 @@ type CacheEntry struct {
 	LastAccessedAt time.Time
 }
 
-// TranslationCache provides in-memory caching for translations
+// TranslationCache caches translations per tenant in memory (L1) and, with a store, in the database
+// (L2), so they survive restarts. L1 misses are looked up in L2, which warms L1 at startup.
 type TranslationCache struct {
 	cache map[string]*CacheEntry
 	mutex sync.RWMutex
+	store repository.TranslationCacheRepository // nil keeps the cache in memory only
 	// Configuration
 	maxCacheSize   int
 	expirationTime time.Duration
@@ -31,13 +45,17 @@ type TranslationCache struct {
 	// Statistics
 	hits         int64
 	misses       int64
+	storeHits    int64
+	storeMisses  int64
+	warmed       int64 // L1 entries loaded from L2 at startup
 	totalSavings int64 // Estimated cost savings (number of API calls avoided)
 }
 
-// NewTranslationCache creates a new translation cache
-func NewTranslationCache(maxSize int, expirationDuration time.Duration) *TranslationCache {
+// NewTranslationCache creates a new translation cache; store is the persistent L2, nil for none
+func NewTranslationCache(maxSize int, expirationDuration time.Duration, store repository.TranslationCacheRepository) *TranslationCache {
 	cache := &TranslationCache{
 		cache:          make(map[string]*CacheEntry),
+		store:          store,
 		maxCacheSize:   maxSize,
 		expirationTime: expirationDuration,
 		enableStats:    true,
@@ -45,78 +63,158 @@ func NewTranslationCache(maxSize int, expirationDuration time.Duration) *Transla
 
 	// Start cleanup goroutine
 	go cache.cleanupExpiredEntries()
+	if store != nil {
+		go cache.warm()
+	}
 
-	log.Printf("Translation cache initialized: maxSize=%d, expiration=%s", maxSize, expirationDuration)
+	log.Printf("Translation cache initialized: maxSize=%d, expiration=%s, persistent=%v", maxSize, expirationDuration, store != nil)
 	return cache
 }
 
 // generateCacheKey creates a unique key for the cache entry
-func (c *TranslationCache) generateCacheKey(text, sourceLang, targetLang string) string {
-	// Create a hash of the text + language pair for efficient lookup
-	data := fmt.Sprintf("%s|%s|%s", text, sourceLang, targetLang)
+func (c *TranslationCache) generateCacheKey(tenantID, sectionID, text, sourceLang, targetLang string) string {
+	// Create a hash of the tenant + text + language pair for efficient lookup
+	data := fmt.Sprintf("%s|%s|%s|%s|%s", tenantID, sectionID, text, sourceLang, targetLang)
 	hash := md5.Sum([]byte(data))
 	return hex.EncodeToString(hash[:])
 }
 
-// Get retrieves a translation from cache
-func (c *TranslationCache) Get(text, sourceLang, targetLang string) (string, bool) {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
+// textHash is the hash the persistent cache keys a text by
+func textHash(text string) string {
+	hash := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(hash[:])
+}
 
-	key := c.generateCacheKey(text, sourceLang, targetLang)
-	entry, exists := c.cache[key]
+// Get retrieves a translation of the tenant of the context from L1, or from L2 on an L1 miss
+func (c *TranslationCache) Get(ctx context.Context, text, sourceLang, targetLang string) (string, bool) {
+	tenantID, sectionID := service.GetTenantScope(ctx)
+	key := c.generateCacheKey(tenantID, sectionID, text, sourceLang, targetLang)
 
-	if !exists {
-		c.misses++
+	c.mutex.Lock()
+	entry, exists := c.cache[key]
+	if exists && time.Since(entry.CachedAt) <= c.expirationTime {
+		// Update access statistics
+		entry.AccessCount++
+		entry.LastAccessedAt = time.Now()
+		c.hits++
+		c.totalSavings++
+
+		log.Printf("Cache HIT for text: '%s' (%s->%s) [hits: %d, savings: %d]",
+			truncateText(text, 50), sourceLang, targetLang, c.hits, c.totalSavings)
+		c.mutex.Unlock()
+		return entry.TargetText, true
+	}
+	if exists {
+		// Note: Don't delete here, cleanup goroutine will handle it
+		log.Printf("Cache entry EXPIRED for text: '%s' (age: %s)", truncateText(text, 50), time.Since(entry.CachedAt))
+	} else {
 		log.Printf("Cache MISS for text: '%s' (%s->%s)", truncateText(text, 50), sourceLang, targetLang)
-		return "", false
 	}
+	c.misses++
+	c.mutex.Unlock()
 
-	// Check if entry has expired
-	if time.Since(entry.CachedAt) > c.expirationTime {
-		log.Printf("Cache entry EXPIRED for text: '%s' (age: %s)", truncateText(text, 50), time.Since(entry.CachedAt))
-		c.misses++
-		// Note: Don't delete here to avoid deadlock, cleanup goroutine will handle it
+	if c.store == nil {
 		return "", false
 	}
+	storeCtx, cancel := context.WithTimeout(ctx, storeTimeout)
+	defer cancel()
+	cached, err := c.store.FindTranslation(storeCtx, sourceLang, targetLang, textHash(text))
+	if err != nil {
+		log.Printf("Failed to read persistent translation cache: %v", err)
+	}
 
-	// Update access statistics
-	entry.AccessCount++
-	entry.LastAccessedAt = time.Now()
-	c.hits++
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if cached == nil || cached.SourceText != text {
+		c.storeMisses++
+		return "", false
+	}
+	c.storeHits++
 	c.totalSavings++
-
-	log.Printf("Cache HIT for text: '%s' (%s->%s) [hits: %d, savings: %d]",
-		truncateText(text, 50), sourceLang, targetLang, c.hits, c.totalSavings)
-
-	return entry.TargetText, true
+	c.add(key, cached)
+	return cached.TargetText, true
 }
 
-// Set stores a translation in cache
-func (c *TranslationCache) Set(text, translatedText, sourceLang, targetLang string) {
+// Set stores a translation of the tenant of the context in L1 and L2
+func (c *TranslationCache) Set(ctx context.Context, text, translatedText, sourceLang, targetLang string) {
+	tenantID, sectionID := service.GetTenantScope(ctx)
+	now := time.Now()
+
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	c.add(c.generateCacheKey(tenantID, sectionID, text, sourceLang, targetLang), &types.CachedTranslation{
+		TenantID:   tenantID,
+		SectionID:  sectionID,
+		SourceLang: sourceLang,
+		TargetLang: targetLang,
+		SourceText: text,
+		TargetText: translatedText,
+		CachedAt:   now,
+	})
+	log.Printf("Cache SET for text: '%s' -> '%s' (%s->%s) [cache size: %d/%d]",
+		truncateText(text, 30), truncateText(translatedText, 30),
+		sourceLang, targetLang, len(c.cache), c.maxCacheSize)
+	c.mutex.Unlock()
 
-	// Check cache size limit
-	if len(c.cache) >= c.maxCacheSize {
-		c.evictLeastRecentlyUsed()
+	if c.store == nil {
+		return
+	}
+	storeCtx, cancel := context.WithTimeout(ctx, storeTimeout)
+	defer cancel()
+	err := c.store.SaveTranslation(storeCtx, &types.CachedTranslation{
+		SourceLang: sourceLang,
+		TargetLang: targetLang,
+		TextHash:   textHash(text),
+		SourceText: text,
+		TargetText: translatedText,
+		CachedAt:   now,
+		ExpiresAt:  now.Add(c.expirationTime),
+	})
+	if err != nil {
+		log.Printf("Failed to write persistent translation cache: %v", err)
 	}
+}
 
-	key := c.generateCacheKey(text, sourceLang, targetLang)
-	entry := &CacheEntry{
-		SourceText:     text,
-		TargetText:     translatedText,
-		SourceLang:     sourceLang,
-		TargetLang:     targetLang,
-		CachedAt:       time.Now(),
-		AccessCount:    0,
+// add stores a translation in L1, evicting the least recently used entry when it is full; the
+// caller holds the lock
+func (c *TranslationCache) add(key string, translation *types.CachedTranslation) {
+	if _, exists := c.cache[key]; !exists && len(c.cache) >= c.maxCacheSize {
+		c.evictLeastRecentlyUsed()
+	}
+	c.cache[key] = &CacheEntry{
+		TenantID:       translation.TenantID,
+		SectionID:      translation.SectionID,
+		SourceText:     translation.SourceText,
+		TargetText:     translation.TargetText,
+		SourceLang:     translation.SourceLang,
+		TargetLang:     translation.TargetLang,
+		CachedAt:       translation.CachedAt,
 		LastAccessedAt: time.Now(),
 	}
+}
 
-	c.cache[key] = entry
-	log.Printf("Cache SET for text: '%s' -> '%s' (%s->%s) [cache size: %d/%d]",
-		truncateText(text, 30), truncateText(translatedText, 30),
-		sourceLang, targetLang, len(c.cache), c.maxCacheSize)
+// warm loads the most recent translations of L2 into L1, so a restart doesn't translate the
+// service catalog again
+func (c *TranslationCache) warm() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	translations, err := c.store.FindRecentTranslations(ctx, c.maxCacheSize)
+	if err != nil {
+		log.Printf("Failed to warm translation cache: %v", err)
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, translation := range translations {
+		key := c.generateCacheKey(translation.TenantID, translation.SectionID, translation.SourceText, translation.SourceLang, translation.TargetLang)
+		if _, exists := c.cache[key]; exists {
+			continue
+		}
+		c.add(key, translation)
+		c.warmed++
+	}
+	log.Printf("Translation cache warmed with %d persisted translations", c.warmed)
 }
 
 // evictLeastRecentlyUsed removes the least recently used entry
@@ -163,8 +261,20 @@ func (c *TranslationCache) cleanupExpiredEntries() {
 	}
 }
 
-// GetStats returns cache statistics
-func (c *TranslationCache) GetStats() map[string]interface{} {
+// GetStats returns the statistics of both tiers: hits and misses of L1 and L2 since the start, and
+// the persistent_size of the translations of the tenant of the context in L2
+func (c *TranslationCache) GetStats(ctx context.Context) map[string]interface{} {
+	var persistentSize int64
+	if c.store != nil {
+		storeCtx, cancel := context.WithTimeout(ctx, storeTimeout)
+		defer cancel()
+		count, err := c.store.CountTranslations(storeCtx)
+		if err != nil {
+			log.Printf("Failed to count persistent translation cache: %v", err)
+		}
+		persistentSize = count
+	}
+
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 
@@ -173,32 +283,57 @@ func (c *TranslationCache) GetStats() map[string]interface{} {
 	if totalRequests > 0 {
 		hitRate = float64(c.hits) / float64(totalRequests) * 100
 	}
+	persistentRequests := c.storeHits + c.storeMisses
+	persistentHitRate := float64(0)
+	if persistentRequests > 0 {
+		persistentHitRate = float64(c.storeHits) / float64(persistentRequests) * 100
+	}
 
 	return map[string]interface{}{
-		"cache_size":      len(c.cache),
-		"max_cache_size":  c.maxCacheSize,
-		"hits":            c.hits,
-		"misses":          c.misses,
-		"total_requests":  totalRequests,
-		"hit_rate":        fmt.Sprintf("%.2f%%", hitRate),
-		"api_calls_saved": c.totalSavings,
-		"expiration_time": c.expirationTime.String(),
+		"cache_size":          len(c.cache),
+		"max_cache_size":      c.maxCacheSize,
+		"hits":                c.hits,
+		"misses":              c.misses,
+		"total_requests":      totalRequests,
+		"hit_rate":            fmt.Sprintf("%.2f%%", hitRate),
+		"api_calls_saved":     c.totalSavings,
+		"expiration_time":     c.expirationTime.String(),
+		"persistent":          c.store != nil,
+		"persistent_size":     persistentSize,
+		"persistent_hits":     c.storeHits,
+		"persistent_misses":   c.storeMisses,
+		"persistent_hit_rate": fmt.Sprintf("%.2f%%", persistentHitRate),
+		"warmed_entries":      c.warmed,
 	}
 }
 
 // LogStats logs current cache statistics
 func (c *TranslationCache) LogStats() {
-	stats := c.GetStats()
+	stats := c.GetStats(context.Background())
 	log.Printf("Translation Cache Stats: %+v", stats)
 }
 
-// Clear removes all entries from cache
-func (c *TranslationCache) Clear() {
+// Clear removes the entries of the tenant of the context from both tiers
+func (c *TranslationCache) Clear(ctx context.Context) {
+	tenantID, sectionID := service.GetTenantScope(ctx)
+
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	for key, entry := range c.cache {
+		// Like the store, no tenant clears all and a building all of its sections
+		if tenantID == "" || entry.TenantID == tenantID && (sectionID == "" || entry.SectionID == sectionID) {
+			delete(c.cache, key)
+		}
+	}
+	c.mutex.Unlock()
 
-	c.cache = make(map[string]*CacheEntry)
-	log.Printf("Translation cache cleared")
+	if c.store != nil {
+		storeCtx, cancel := context.WithTimeout(ctx, storeTimeout)
+		defer cancel()
+		if _, err := c.store.DeleteTranslations(storeCtx); err != nil {
+			log.Printf("Failed to clear persistent translation cache: %v", err)
+		}
+	}
+	log.Printf("Translation cache cleared for tenant %s", service.GetTenantID(ctx))
 }
 
 // truncateText helper function to limit text length in logs
@@ -0,0 +1,82 @@
+package translation
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// ChainProvider asks its providers in order and returns the first translation, e.g. the glossary
+// and then DeepL for the texts the glossary lacks
+type ChainProvider struct {
+	providers []TranslationProvider
+}
+
+func NewChainProvider(providers ...TranslationProvider) *ChainProvider {
+	return &ChainProvider{providers: providers}
+}
+
+// Translate returns the translation of the first configured provider that has one, and the error
+// of the last one when none has
+func (p *ChainProvider) Translate(ctx context.Context, text, sourceLang, targetLang string) (string, error) {
+	err := fmt.Errorf("%w: no provider in the chain", ErrNotConfigured)
+	for _, provider := range p.providers {
+		if !provider.IsConfigured() {
+			continue
+		}
+		var translated string
+		translated, err = provider.Translate(ctx, text, sourceLang, targetLang)
+		if err == nil {
+			return translated, nil
+		}
+	}
+	return text, err
+}
+
+// TranslateBatch asks the providers in order for the texts the previous ones had no translation
+// of, in batches where they support them
+func (p *ChainProvider) TranslateBatch(ctx context.Context, texts []string, sourceLang, targetLang string) ([]string, []error) {
+	translations := slices.Clone(texts)
+	errs := make([]error, len(texts))
+	pending := make([]int, len(texts))
+	for i := range texts {
+		pending[i] = i
+		errs[i] = fmt.Errorf("%w: no provider in the chain", ErrNotConfigured)
+	}
+
+	for _, provider := range p.providers {
+		if len(pending) == 0 {
+			break
+		}
+		if !provider.IsConfigured() {
+			continue
+		}
+		pendingTexts := make([]string, len(pending))
+		for j, i := range pending {
+			pendingTexts[j] = texts[i]
+		}
+		translated, providerErrs := TranslateTexts(ctx, provider, pendingTexts, sourceLang, targetLang)
+		var failed []int
+		for j, i := range pending {
+			if providerErrs[j] != nil {
+				errs[i] = providerErrs[j]
+				failed = append(failed, i)
+				continue
+			}
+			translations[i] = translated[j]
+			errs[i] = nil
+		}
+		pending = failed
+	}
+	return translations, errs
+}
+
+// IsConfigured returns true if any provider of the chain is configured
+func (p *ChainProvider) IsConfigured() bool {
+	for _, provider := range p.providers {
+		if provider.IsConfigured() {
+			return true
+		}
+	}
+	return false
+}
@@ -2,12 +2,15 @@ package translation
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/repository"
 )
 
 // DeepLTranslationService handles translation using DeepL API
@@ -33,8 +36,9 @@ type TranslationResponse struct {
 	} `json:"translations"`
 }
 
-// NewDeepLTranslationService creates a new DeepL translation service
-func NewDeepLTranslationService(config config.DeepLConfig) *DeepLTranslationService {
+// NewDeepLTranslationService creates a new DeepL translation service; cacheStore persists its
+// cache, nil keeps it in memory only
+func NewDeepLTranslationService(config config.DeepLConfig, cacheStore repository.TranslationCacheRepository) *DeepLTranslationService {
 	apiKey := config.APIKey
 	if apiKey == "" {
 		// Return nil if no API key is configured
@@ -43,7 +47,7 @@ func NewDeepLTranslationService(config config.DeepLConfig) *DeepLTranslationServ
 
 	// Create cache with configurable settings
 	// Max 10000 entries, 7 days expiration
-	cache := NewTranslationCache(10000, 7*24*time.Hour)
+	cache := NewTranslationCache(10000, 7*24*time.Hour, cacheStore)
 
 	return &DeepLTranslationService{
 		apiKey:  apiKey,
@@ -56,35 +60,77 @@ func NewDeepLTranslationService(config config.DeepLConfig) *DeepLTranslationServ
 }
 
 // Translate translates text from source language to target language
-func (s *DeepLTranslationService) Translate(text string, sourceLang, targetLang string) (string, error) {
+func (s *DeepLTranslationService) Translate(ctx context.Context, text string, sourceLang, targetLang string) (string, error) {
+	translations, errs := s.TranslateBatch(ctx, []string{text}, sourceLang, targetLang)
+	return translations[0], errs[0]
+}
+
+// TranslateBatch translates the texts from source language to target language, sending the ones
+// not in the cache once each in requests of up to maxBatchSize texts
+func (s *DeepLTranslationService) TranslateBatch(ctx context.Context, texts []string, sourceLang, targetLang string) ([]string, []error) {
+	translations := slices.Clone(texts)
+	errs := make([]error, len(texts))
 	if s == nil {
-		return text, fmt.Errorf("DeepL service not configured - no API key provided")
+		for i := range errs {
+			errs[i] = fmt.Errorf("%w: DeepL has no API key", ErrNotConfigured)
+		}
+		return translations, errs
 	}
 
 	// Convert language codes to DeepL format
 	sourceLangCode := s.convertLanguageCode(sourceLang)
 	targetLangCode := s.convertLanguageCode(targetLang)
 
-	// Check cache first
-	if cachedTranslation, found := s.cache.Get(text, sourceLangCode, targetLangCode); found {
-		return cachedTranslation, nil
+	// Check cache first; a text that appears twice is requested once
+	var missing []string
+	positions := make(map[string][]int)
+	for i, text := range texts {
+		if cachedTranslation, found := s.cache.Get(ctx, text, sourceLangCode, targetLangCode); found {
+			translations[i] = cachedTranslation
+			continue
+		}
+		if _, seen := positions[text]; !seen {
+			missing = append(missing, text)
+		}
+		positions[text] = append(positions[text], i)
+	}
+	if len(missing) == 0 {
+		return translations, errs
 	}
 
-	// Cache miss - make API call
+	// Cache miss - make API calls
+	translated, batchErrs := translateInBatches(ctx, missing, func(ctx context.Context, batch []string) ([]string, error) {
+		return s.request(ctx, batch, sourceLangCode, targetLangCode)
+	})
+	for j, text := range missing {
+		if batchErrs[j] == nil {
+			// Store in cache for future use
+			s.cache.Set(ctx, text, translated[j], sourceLangCode, targetLangCode)
+		}
+		for _, i := range positions[text] {
+			translations[i] = translated[j]
+			errs[i] = batchErrs[j]
+		}
+	}
+	return translations, errs
+}
+
+// request sends one DeepL request translating the texts and returns the translations in their order
+func (s *DeepLTranslationService) request(ctx context.Context, texts []string, sourceLangCode, targetLangCode string) ([]string, error) {
 	request := TranslationRequest{
-		Text:       []string{text},
+		Text:       texts,
 		SourceLang: sourceLangCode,
 		TargetLang: targetLangCode,
 	}
 
 	jsonData, err := json.Marshal(request)
 	if err != nil {
-		return text, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", s.baseURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", s.baseURL, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return text, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "DeepL-Auth-Key "+s.apiKey)
@@ -92,33 +138,32 @@ func (s *DeepLTranslationService) Translate(text string, sourceLang, targetLang
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return text, fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return text, fmt.Errorf("DeepL API returned status %d", resp.StatusCode)
+		return nil, fmt.Errorf("DeepL API returned status %d", resp.StatusCode)
 	}
 
 	var response TranslationResponse
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return text, fmt.Errorf("failed to decode response: %w", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	if len(response.Translations) == 0 {
-		return text, fmt.Errorf("no translations returned")
+	// DeepL returns the translations in the order of the texts
+	if len(response.Translations) != len(texts) {
+		return nil, fmt.Errorf("DeepL returned %d translations for %d texts", len(response.Translations), len(texts))
 	}
-
-	translatedText := response.Translations[0].Text
-
-	// Store in cache for future use
-	s.cache.Set(text, translatedText, sourceLangCode, targetLangCode)
-
-	return translatedText, nil
+	translations := make([]string, len(texts))
+	for i, translation := range response.Translations {
+		translations[i] = translation.Text
+	}
+	return translations, nil
 }
 
 // TranslateService translates a service object
-func (s *DeepLTranslationService) TranslateService(service map[string]interface{}, sourceLang, targetLang string) (map[string]interface{}, error) {
+func (s *DeepLTranslationService) TranslateService(ctx context.Context, service map[string]interface{}, sourceLang, targetLang string) (map[string]interface{}, error) {
 	if s == nil {
 		return service, fmt.Errorf("DeepL service not configured")
 	}
@@ -132,7 +177,7 @@ func (s *DeepLTranslationService) TranslateService(service map[string]interface{
 
 	// Translate name field
 	if name, ok := service["name"].(string); ok && name != "" {
-		translatedName, err := s.Translate(name, sourceLang, targetLang)
+		translatedName, err := s.Translate(ctx, name, sourceLang, targetLang)
 		if err != nil {
 			// If translation fails, keep original name
 			translatedService["name"] = name
@@ -143,7 +188,7 @@ func (s *DeepLTranslationService) TranslateService(service map[string]interface{
 
 	// Translate description field if it exists
 	if description, ok := service["description"].(string); ok && description != "" {
-		translatedDescription, err := s.Translate(description, sourceLang, targetLang)
+		translatedDescription, err := s.Translate(ctx, description, sourceLang, targetLang)
 		if err != nil {
 			// If translation fails, keep original description
 			translatedService["description"] = description
@@ -156,7 +201,7 @@ func (s *DeepLTranslationService) TranslateService(service map[string]interface{
 }
 
 // TranslateServices translates an array of services
-func (s *DeepLTranslationService) TranslateServices(services []map[string]interface{}, sourceLang, targetLang string) ([]map[string]interface{}, error) {
+func (s *DeepLTranslationService) TranslateServices(ctx context.Context, services []map[string]interface{}, sourceLang, targetLang string) ([]map[string]interface{}, error) {
 	if s == nil {
 		return services, fmt.Errorf("DeepL service not configured")
 	}
@@ -164,7 +209,7 @@ func (s *DeepLTranslationService) TranslateServices(services []map[string]interf
 	translatedServices := make([]map[string]interface{}, len(services))
 
 	for i, service := range services {
-		translatedService, err := s.TranslateService(service, sourceLang, targetLang)
+		translatedService, err := s.TranslateService(ctx, service, sourceLang, targetLang)
 		if err != nil {
 			// If translation fails for one service, keep original
 			translatedServices[i] = service
@@ -212,13 +257,13 @@ func (s *DeepLTranslationService) IsConfigured() bool {
 }
 
 // GetCacheStats returns translation cache statistics
-func (s *DeepLTranslationService) GetCacheStats() map[string]interface{} {
+func (s *DeepLTranslationService) GetCacheStats(ctx context.Context) map[string]interface{} {
 	if s == nil || s.cache == nil {
 		return map[string]interface{}{
 			"error": "Translation service or cache not initialized",
 		}
 	}
-	return s.cache.GetStats()
+	return s.cache.GetStats(ctx)
 }
 
 // LogCacheStats logs the current cache statistics
@@ -228,9 +273,9 @@ func (s *DeepLTranslationService) LogCacheStats() {
 	}
 }
 
-// ClearCache clears all cached translations
-func (s *DeepLTranslationService) ClearCache() {
+// ClearCache clears the cached translations of the tenant of the context
+func (s *DeepLTranslationService) ClearCache(ctx context.Context) {
 	if s != nil && s.cache != nil {
-		s.cache.Clear()
+		s.cache.Clear(ctx)
 	}
 }
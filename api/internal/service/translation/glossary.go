@@ -0,0 +1,39 @@
+package translation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/arfis/waiting-room/internal/repository"
+)
+
+// GlossaryProvider translates with the glossary of the tenant of the context: fixed translations
+// of exact texts, e.g. of service names a machine translation gets wrong
+type GlossaryProvider struct {
+	repo repository.TranslationGlossaryRepository
+}
+
+func NewGlossaryProvider(repo repository.TranslationGlossaryRepository) *GlossaryProvider {
+	return &GlossaryProvider{repo: repo}
+}
+
+// Translate returns the glossary translation of the text, ErrNoTranslation when it has none
+func (p *GlossaryProvider) Translate(ctx context.Context, text, sourceLang, targetLang string) (string, error) {
+	if !p.IsConfigured() {
+		return text, fmt.Errorf("%w: no glossary repository", ErrNotConfigured)
+	}
+	entry, err := p.repo.FindGlossaryTranslation(ctx, strings.ToLower(sourceLang), strings.ToLower(targetLang), text)
+	if err != nil {
+		return text, err
+	}
+	if entry == nil {
+		return text, ErrNoTranslation
+	}
+	return entry.TargetText, nil
+}
+
+// IsConfigured returns true if the glossary has a repository
+func (p *GlossaryProvider) IsConfigured() bool {
+	return p != nil && p.repo != nil
+}
@@ -0,0 +1,114 @@
+package translation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+)
+
+// LibreTranslateProvider translates with a self-hosted LibreTranslate, for sites without internet
+// access or a DeepL subscription
+type LibreTranslateProvider struct {
+	url        string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// libreTranslateRequest translates the texts of Q at once; the response has their translations in order
+type libreTranslateRequest struct {
+	Q      []string `json:"q"`
+	Source string   `json:"source"`
+	Target string   `json:"target"`
+	Format string   `json:"format"`
+	APIKey string   `json:"api_key,omitempty"`
+}
+
+type libreTranslateResponse struct {
+	TranslatedText []string `json:"translatedText"`
+}
+
+// NewLibreTranslateProvider creates a LibreTranslate provider, nil when no URL is configured
+func NewLibreTranslateProvider(config config.LibreTranslateConfig) *LibreTranslateProvider {
+	if config.URL == "" {
+		return nil
+	}
+	return &LibreTranslateProvider{
+		url:    strings.TrimSuffix(config.URL, "/") + "/translate",
+		apiKey: config.APIKey,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Translate translates text from source language to target language
+func (p *LibreTranslateProvider) Translate(ctx context.Context, text, sourceLang, targetLang string) (string, error) {
+	translations, errs := p.TranslateBatch(ctx, []string{text}, sourceLang, targetLang)
+	return translations[0], errs[0]
+}
+
+// TranslateBatch translates the texts from source language to target language in requests of up
+// to maxBatchSize texts
+func (p *LibreTranslateProvider) TranslateBatch(ctx context.Context, texts []string, sourceLang, targetLang string) ([]string, []error) {
+	if p == nil {
+		errs := make([]error, len(texts))
+		for i := range errs {
+			errs[i] = fmt.Errorf("%w: LibreTranslate has no URL", ErrNotConfigured)
+		}
+		return slices.Clone(texts), errs
+	}
+	return translateInBatches(ctx, texts, func(ctx context.Context, batch []string) ([]string, error) {
+		return p.request(ctx, batch, strings.ToLower(sourceLang), strings.ToLower(targetLang))
+	})
+}
+
+// request sends one request translating the texts and returns the translations in their order
+func (p *LibreTranslateProvider) request(ctx context.Context, texts []string, sourceLang, targetLang string) ([]string, error) {
+	jsonData, err := json.Marshal(libreTranslateRequest{
+		Q:      texts,
+		Source: sourceLang,
+		Target: targetLang,
+		Format: "text",
+		APIKey: p.apiKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("LibreTranslate returned status %d", resp.StatusCode)
+	}
+
+	var response libreTranslateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(response.TranslatedText) != len(texts) {
+		return nil, fmt.Errorf("LibreTranslate returned %d translations for %d texts", len(response.TranslatedText), len(texts))
+	}
+	return response.TranslatedText, nil
+}
+
+// IsConfigured returns true if the LibreTranslate URL is configured
+func (p *LibreTranslateProvider) IsConfigured() bool {
+	return p != nil
+}
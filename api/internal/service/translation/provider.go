@@ -0,0 +1,37 @@
+// Package translation translates the texts the kiosk shows, e.g. service names, into the language
+// of the patient. Every backend is one TranslationProvider; the external API configuration of the
+// tenant selects it.
+package translation
+
+import (
+	"context"
+	"errors"
+)
+
+// Providers selectable in the external API configuration
+const (
+	ProviderDeepL                  = "deepl"                   // DeepL API (default)
+	ProviderLibreTranslate         = "libretranslate"          // Self-hosted LibreTranslate
+	ProviderGlossary               = "glossary"                // Glossary of the tenant only
+	ProviderGlossaryDeepL          = "glossary_deepl"          // Glossary, then DeepL for texts it lacks
+	ProviderGlossaryLibreTranslate = "glossary_libretranslate" // Glossary, then LibreTranslate for texts it lacks
+)
+
+// Providers lists the selectable providers
+var Providers = []string{ProviderDeepL, ProviderLibreTranslate, ProviderGlossary, ProviderGlossaryDeepL, ProviderGlossaryLibreTranslate}
+
+var (
+	// ErrNotConfigured is returned by providers missing their URL or API key
+	ErrNotConfigured = errors.New("translation provider not configured")
+	// ErrNoTranslation is returned by the glossary for texts it has no translation of
+	ErrNoTranslation = errors.New("no translation of the text")
+)
+
+// TranslationProvider is the interface a translation backend implements. Language codes are the
+// lower case codes of the kiosk, e.g. en or sk; on error the text is returned unchanged.
+type TranslationProvider interface {
+	// Translate translates the text from the source to the target language
+	Translate(ctx context.Context, text, sourceLang, targetLang string) (string, error)
+	// IsConfigured reports whether the provider can translate at all
+	IsConfigured() bool
+}
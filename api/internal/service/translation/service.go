@@ -0,0 +1,110 @@
+package translation
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/service"
+	configService "github.com/arfis/waiting-room/internal/service/config"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// Service translates with the provider the external API configuration of the tenant of the
+// context selects
+type Service struct {
+	configService  *configService.Service
+	deepL          *DeepLTranslationService
+	libreTranslate *LibreTranslateProvider
+	glossary       *GlossaryProvider
+	glossaryRepo   repository.TranslationGlossaryRepository
+
+	listenersMu       sync.Mutex
+	glossaryListeners []func(tenantID string)
+}
+
+func NewService(configService *configService.Service, deepL *DeepLTranslationService, libreTranslate *LibreTranslateProvider, glossaryRepo repository.TranslationGlossaryRepository) *Service {
+	return &Service{
+		configService:  configService,
+		deepL:          deepL,
+		libreTranslate: libreTranslate,
+		glossary:       NewGlossaryProvider(glossaryRepo),
+		glossaryRepo:   glossaryRepo,
+	}
+}
+
+// OnGlossaryChange registers f to be called with the tenant whose glossary was replaced
+func (s *Service) OnGlossaryChange(f func(tenantID string)) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	s.glossaryListeners = append(s.glossaryListeners, f)
+}
+
+// Provider returns the translation provider of the tenant, DeepL when it selects none or its
+// configuration can't be loaded
+func (s *Service) Provider(ctx context.Context) TranslationProvider {
+	name := ProviderDeepL
+	if s.configService != nil {
+		if apiConfig, err := s.configService.GetExternalAPIConfig(ctx); err == nil && apiConfig != nil && apiConfig.TranslationProvider != "" {
+			name = apiConfig.TranslationProvider
+		}
+	}
+
+	switch name {
+	case ProviderDeepL:
+		return s.deepL
+	case ProviderLibreTranslate:
+		return s.libreTranslate
+	case ProviderGlossary:
+		return s.glossary
+	case ProviderGlossaryDeepL:
+		return NewChainProvider(s.glossary, s.deepL)
+	case ProviderGlossaryLibreTranslate:
+		return NewChainProvider(s.glossary, s.libreTranslate)
+	default:
+		log.Printf("[Translation] Unknown translation provider %q, using DeepL", name)
+		return s.deepL
+	}
+}
+
+// Translate translates text with the provider of the tenant
+func (s *Service) Translate(ctx context.Context, text, sourceLang, targetLang string) (string, error) {
+	return s.Provider(ctx).Translate(ctx, text, sourceLang, targetLang)
+}
+
+// IsConfigured reports whether the provider of the tenant can translate
+func (s *Service) IsConfigured(ctx context.Context) bool {
+	return s.Provider(ctx).IsConfigured()
+}
+
+// GetGlossary returns the glossary of the tenant
+func (s *Service) GetGlossary(ctx context.Context) ([]*types.GlossaryEntry, error) {
+	return s.glossaryRepo.GetGlossary(ctx)
+}
+
+// SetGlossary replaces the glossary of the tenant
+func (s *Service) SetGlossary(ctx context.Context, entries []*types.GlossaryEntry) error {
+	if err := s.glossaryRepo.ReplaceGlossary(ctx, entries); err != nil {
+		return err
+	}
+
+	s.listenersMu.Lock()
+	listeners := s.glossaryListeners
+	s.listenersMu.Unlock()
+	tenantID := service.GetTenantID(ctx)
+	for _, f := range listeners {
+		f(tenantID)
+	}
+	return nil
+}
+
+// GetCacheStats returns the statistics of the DeepL translation cache
+func (s *Service) GetCacheStats(ctx context.Context) map[string]interface{} {
+	return s.deepL.GetCacheStats(ctx)
+}
+
+// ClearCache clears the DeepL translation cache of the tenant
+func (s *Service) ClearCache(ctx context.Context) {
+	s.deepL.ClearCache(ctx)
+}
@@ -0,0 +1,242 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/config"
+	"github.com/arfis/waiting-room/internal/metrics"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// deliveryLease is how long a claimed delivery is reserved for the worker of one replica; a
+// replica that dies while sending leaves it to the others after the lease
+const deliveryLease = 2 * time.Minute
+
+// purgeInterval is how often delivered events older than the retention are deleted
+const purgeInterval = time.Hour
+
+// errorBodyLimit is how much of the response of a failed attempt is kept as its error
+const errorBodyLimit = 512
+
+// ErrDeliveryNotFound is returned for a delivery that is not in the outbox of the tenant
+var ErrDeliveryNotFound = errors.New("webhook delivery not found")
+
+// ErrDeliveryPending is returned when replaying a delivery that is still being retried
+var ErrDeliveryPending = errors.New("webhook delivery is still pending")
+
+// StartDeliveryRoutine starts the workers sending the outbox until ctx is done. Due deliveries
+// are claimed every poll interval and right after an event was queued.
+func (s *Service) StartDeliveryRoutine(ctx context.Context, cfg config.WebhooksConfig) {
+	jobs := make(chan *types.WebhookDelivery)
+	for i := 0; i < cfg.Workers; i++ {
+		go func() {
+			for delivery := range jobs {
+				s.deliver(ctx, delivery, cfg)
+			}
+		}()
+	}
+
+	poll := time.NewTicker(time.Duration(cfg.PollIntervalSeconds) * time.Second)
+	purge := time.NewTicker(purgeInterval)
+	go func() {
+		defer close(jobs)
+		defer poll.Stop()
+		defer purge.Stop()
+		for {
+			s.dispatchDue(ctx, jobs, cfg.Workers)
+			select {
+			case <-ctx.Done():
+				return
+			case <-poll.C:
+			case <-s.wakeup:
+			case <-purge.C:
+				s.purgeDelivered(ctx, cfg.RetentionDays)
+			}
+		}
+	}()
+	log.Printf("[WebhookService] Delivery started (workers: %d, poll interval: %ds)", cfg.Workers, cfg.PollIntervalSeconds)
+}
+
+// dispatchDue hands the due deliveries to the workers, claiming one batch per free worker round
+func (s *Service) dispatchDue(ctx context.Context, jobs chan<- *types.WebhookDelivery, batch int) {
+	for ctx.Err() == nil {
+		claimed, err := s.repo.ClaimDueWebhookDeliveries(ctx, time.Now(), deliveryLease, batch)
+		if err != nil {
+			log.Printf("[WebhookService] Failed to claim due deliveries: %v", err)
+		}
+		for _, delivery := range claimed {
+			select {
+			case jobs <- delivery:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if len(claimed) < batch {
+			return
+		}
+	}
+}
+
+// deliver makes one attempt of a delivery and stores its outcome: DELIVERED, PENDING with the next
+// attempt after the backoff, or FAILED once the attempts of the tenant are used up
+func (s *Service) deliver(ctx context.Context, delivery *types.WebhookDelivery, cfg config.WebhooksConfig) {
+	tenantCtx := context.WithValue(ctx, middleware.TENANT, deliveryTenantID(delivery))
+	statusCode, err := s.attempt(tenantCtx, delivery)
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.UpdatedAt = now
+	delivery.LastStatusCode = statusCode
+	switch {
+	case err == nil:
+		delivery.Status = types.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		metrics.WebhookDeliveries.Inc(delivery.Event, "delivered")
+	case delivery.Attempts >= delivery.MaxAttempts:
+		delivery.Status = types.WebhookDeliveryFailed
+		delivery.LastError = err.Error()
+		log.Printf("[WebhookService] Delivery %s (%s) failed after %d attempts: %v", delivery.ID, delivery.Event, delivery.Attempts, err)
+		metrics.WebhookDeliveries.Inc(delivery.Event, "failed")
+	default:
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = now.Add(backoff(delivery.Attempts, cfg))
+		metrics.WebhookDeliveries.Inc(delivery.Event, "retry")
+	}
+
+	if err := s.repo.UpdateWebhookDelivery(tenantCtx, delivery); err != nil {
+		log.Printf("[WebhookService] Failed to store delivery %s: %v", delivery.ID, err)
+	}
+}
+
+// attempt posts a delivery to its URL with the current headers, timeout and signing secret of
+// the tenant and returns the status code of the response
+func (s *Service) attempt(ctx context.Context, delivery *types.WebhookDelivery) (int, error) {
+	webhookConfig, err := s.getWebhookConfig(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get webhook config: %w", err)
+	}
+
+	timeout := time.Duration(webhookConfig.WebhookTimeoutSeconds) * time.Second
+	if timeout == 0 {
+		timeout = 5 * time.Second // Default timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	// Custom headers first, so they cannot replace the ones receivers verify
+	for key, value := range webhookConfig.Headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "WaitingRoom-Webhook/1.0")
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("X-Webhook-Id", delivery.ID)
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	if webhookConfig.Secret != "" {
+		req.Header.Set("X-Webhook-Signature", Signature(webhookConfig.Secret, timestamp, delivery.Payload))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, errorBodyLimit))
+	return resp.StatusCode, fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, string(body))
+}
+
+// Signature returns the X-Webhook-Signature of a body sent at timestamp (Unix seconds): the hex
+// HMAC-SHA256 of "timestamp.body" with the webhook secret of the tenant
+func Signature(secret, timestamp, body string) string {
+	return middleware.RequestSignature(secret, timestamp, body)
+}
+
+// backoff returns the delay after the given number of failed attempts: the initial backoff,
+// doubled with every further attempt, at most the maximum backoff
+func backoff(attempts int, cfg config.WebhooksConfig) time.Duration {
+	delay := time.Duration(cfg.InitialBackoffSeconds) * time.Second
+	maxDelay := time.Duration(cfg.MaxBackoffSeconds) * time.Second
+	for i := 1; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
+
+// purgeDelivered deletes the delivered events older than the retention
+func (s *Service) purgeDelivered(ctx context.Context, retentionDays int) {
+	deleted, err := s.repo.DeleteDeliveredWebhookDeliveries(ctx, time.Now().AddDate(0, 0, -retentionDays))
+	if err != nil {
+		log.Printf("[WebhookService] Failed to purge delivered webhooks: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("[WebhookService] Purged %d delivered webhooks", deleted)
+	}
+}
+
+// deliveryTenantID returns the tenant of a delivery in the format of middleware.TENANT
+func deliveryTenantID(delivery *types.WebhookDelivery) string {
+	if delivery.SectionID == "" {
+		return delivery.TenantID
+	}
+	return delivery.TenantID + ":" + delivery.SectionID
+}
+
+// GetDeliveries returns up to limit deliveries of the tenant in ctx with the status, newest first
+func (s *Service) GetDeliveries(ctx context.Context, status string, limit int) ([]*types.WebhookDelivery, error) {
+	return s.repo.GetWebhookDeliveries(ctx, status, limit)
+}
+
+// ReplayDelivery queues a delivery of the tenant in ctx again, to the current webhook URL and with
+// the retry attempts of the tenant; the event keeps its id, so receivers can recognize it
+func (s *Service) ReplayDelivery(ctx context.Context, id string) (*types.WebhookDelivery, error) {
+	delivery, err := s.repo.GetWebhookDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery == nil {
+		return nil, fmt.Errorf("%w: %s", ErrDeliveryNotFound, id)
+	}
+	if delivery.Status == types.WebhookDeliveryPending {
+		return nil, fmt.Errorf("%w: %s", ErrDeliveryPending, id)
+	}
+
+	webhookConfig, err := s.getWebhookConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook config: %w", err)
+	}
+	if webhookConfig.WebhookURL != "" {
+		delivery.URL = webhookConfig.WebhookURL
+	}
+	now := time.Now()
+	delivery.Status = types.WebhookDeliveryPending
+	delivery.MaxAttempts = delivery.Attempts + webhookConfig.WebhookRetryAttempts + 1
+	delivery.NextAttemptAt = now
+	delivery.UpdatedAt = now
+	delivery.DeliveredAt = nil
+	if err := s.repo.UpdateWebhookDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	s.wake()
+	log.Printf("[WebhookService] Replaying delivery %s (%s)", delivery.ID, delivery.Event)
+	return delivery, nil
+}
@@ -1,27 +1,80 @@
 package webhook
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
+	"log"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/arfis/waiting-room/internal/queue"
+	"github.com/arfis/waiting-room/internal/repository"
+	"github.com/arfis/waiting-room/internal/service"
 	"github.com/arfis/waiting-room/internal/service/config"
+	"github.com/arfis/waiting-room/internal/types"
+	"github.com/google/uuid"
 )
 
+// Events of the webhook catalog
+const (
+	EventEntryCreated         = "entry.created"
+	EventEntryServiceSelected = "entry.service_selected"
+	EventEntryCalled          = "entry.called"
+	EventEntryRecalled        = "entry.recalled"
+	EventEntryInRoom          = "entry.in_room"
+	EventEntryCompleted       = "entry.completed"
+	EventEntryNoShow          = "entry.no_show"
+	EventEntryCancelled       = "entry.cancelled"
+	EventEntryTransferred     = "entry.transferred"
+	EventEntryStageAdvanced   = "entry.stage_advanced"
+	EventEntryPriorityBoosted = "entry.priority_boosted"
+	EventQueuePaused          = "queue.paused"
+	EventQueueResumed         = "queue.resumed"
+)
+
+// Events is the webhook catalog; a tenant receives all of them unless it enabled only some in
+// the webhook events of its external API configuration
+var Events = []string{
+	EventEntryCreated,
+	EventEntryServiceSelected,
+	EventEntryCalled,
+	EventEntryRecalled,
+	EventEntryInRoom,
+	EventEntryCompleted,
+	EventEntryNoShow,
+	EventEntryCancelled,
+	EventEntryTransferred,
+	EventEntryStageAdvanced,
+	EventEntryPriorityBoosted,
+	EventQueuePaused,
+	EventQueueResumed,
+}
+
+// IsEvent reports whether event is in the webhook catalog
+func IsEvent(event string) bool {
+	return slices.Contains(Events, event)
+}
+
 type Service struct {
 	configService *config.Service
+	repo          repository.WebhookDeliveryRepository
 	httpClient    *http.Client
+	wakeup        chan struct{} // Wakes the delivery routine when an event was queued
 }
 
+// WebhookPayload is the envelope of every webhook event. ID is unique per event, so receivers
+// can drop the duplicates of retried deliveries; the ticket fields are empty for queue.* events.
 type WebhookPayload struct {
+	ID             string                 `json:"id"`
 	Event          string                 `json:"event"`
-	TicketID       string                 `json:"ticketId"`
+	TenantID       string                 `json:"tenantId,omitempty"`
+	TicketID       string                 `json:"ticketId,omitempty"`
+	TicketNumber   string                 `json:"ticketNumber,omitempty"`
 	ServiceID      string                 `json:"serviceId,omitempty"`
-	State          string                 `json:"state"`
+	State          string                 `json:"state,omitempty"`
 	Timestamp      time.Time              `json:"timestamp"`
 	RoomID         string                 `json:"roomId"`
 	ServicePointID string                 `json:"servicePointId,omitempty"`
@@ -29,16 +82,17 @@ type WebhookPayload struct {
 	AdditionalData map[string]interface{} `json:"additionalData,omitempty"`
 }
 
-func NewService(configService *config.Service) *Service {
+func NewService(configService *config.Service, repo repository.WebhookDeliveryRepository) *Service {
 	return &Service{
 		configService: configService,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second, // Default timeout, will be overridden by config
-		},
+		repo:          repo,
+		httpClient:    &http.Client{}, // Timeouts come from the configuration of the tenant
+		wakeup:        make(chan struct{}, 1),
 	}
 }
 
-// SendWebhook sends a webhook notification for ticket state changes
+// SendWebhook stores a webhook event of the tenant in ctx in the outbox, from where the delivery
+// workers send it
 func (s *Service) SendWebhook(ctx context.Context, payload WebhookPayload) error {
 	// Get webhook configuration
 	webhookConfig, err := s.getWebhookConfig(ctx)
@@ -46,82 +100,74 @@ func (s *Service) SendWebhook(ctx context.Context, payload WebhookPayload) error
 		return fmt.Errorf("failed to get webhook config: %w", err)
 	}
 
-	// If no webhook URL is configured, skip
+	// If no webhook URL is configured or the tenant disabled the event, skip
 	if webhookConfig.WebhookURL == "" {
 		return nil
 	}
+	if len(webhookConfig.Events) > 0 && !slices.Contains(webhookConfig.Events, payload.Event) {
+		return nil
+	}
+
+	if payload.ID == "" {
+		payload.ID = uuid.NewString()
+	}
+	if payload.Timestamp.IsZero() {
+		payload.Timestamp = time.Now()
+	}
+	if payload.TenantID == "" {
+		payload.TenantID = service.GetTenantID(ctx)
+	}
 
-	// Create HTTP request
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal webhook payload: %w", err)
 	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", webhookConfig.WebhookURL, bytes.NewBuffer(jsonPayload))
-	if err != nil {
-		return fmt.Errorf("failed to create webhook request: %w", err)
+	// A template the receiver cannot be sent with is logged, and the event sent as envelope
+	if webhookConfig.PayloadTemplate != "" {
+		if rendered, err := RenderPayload(webhookConfig.PayloadTemplate, payload); err != nil {
+			log.Printf("Failed to render %s webhook with the payload template, sending the envelope: %v", payload.Event, err)
+		} else {
+			jsonPayload = rendered
+		}
 	}
 
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "WaitingRoom-Webhook/1.0")
-
-	// Add custom headers from configuration
-	for key, value := range webhookConfig.Headers {
-		req.Header.Set(key, value)
+	buildingID, sectionID, _ := types.ParseTenantID(payload.TenantID)
+	now := time.Now()
+	delivery := &types.WebhookDelivery{
+		ID:            payload.ID,
+		TenantID:      buildingID,
+		SectionID:     sectionID,
+		Event:         payload.Event,
+		URL:           webhookConfig.WebhookURL,
+		Payload:       string(jsonPayload),
+		Status:        types.WebhookDeliveryPending,
+		MaxAttempts:   webhookConfig.WebhookRetryAttempts + 1,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
 	}
-
-	// Set timeout from configuration
-	timeout := time.Duration(webhookConfig.WebhookTimeoutSeconds) * time.Second
-	if timeout == 0 {
-		timeout = 5 * time.Second // Default timeout
+	if err := s.repo.EnqueueWebhookDelivery(ctx, delivery); err != nil {
+		return err
 	}
+	s.wake()
+	return nil
+}
 
-	// Create client with timeout
-	client := &http.Client{
-		Timeout: timeout,
+// Enqueue stores a webhook event like SendWebhook and only logs failures, for the transitions
+// that must not fail because of their webhook. The event outlives the request, so only the
+// values of ctx are kept.
+func (s *Service) Enqueue(ctx context.Context, payload WebhookPayload) {
+	if err := s.SendWebhook(context.WithoutCancel(ctx), payload); err != nil {
+		log.Printf("Failed to queue %s webhook: %v", payload.Event, err)
 	}
-
-	// Send webhook with retry logic
-	return s.sendWithRetry(ctx, client, req, webhookConfig.WebhookRetryAttempts)
 }
 
-// sendWithRetry sends the webhook with retry logic
-func (s *Service) sendWithRetry(ctx context.Context, client *http.Client, req *http.Request, maxRetries int) error {
-	var lastErr error
-
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		resp, err := client.Do(req)
-		if err != nil {
-			lastErr = fmt.Errorf("webhook request failed (attempt %d/%d): %w", attempt+1, maxRetries+1, err)
-			if attempt < maxRetries {
-				// Wait before retry (exponential backoff)
-				waitTime := time.Duration(attempt+1) * time.Second
-				time.Sleep(waitTime)
-				continue
-			}
-			return lastErr
-		}
-		defer resp.Body.Close()
-
-		// Check response status
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			return nil // Success
-		}
-
-		// Read response body for error details
-		body, _ := io.ReadAll(resp.Body)
-		lastErr = fmt.Errorf("webhook returned status %d (attempt %d/%d): %s",
-			resp.StatusCode, attempt+1, maxRetries+1, string(body))
-
-		if attempt < maxRetries {
-			// Wait before retry
-			waitTime := time.Duration(attempt+1) * time.Second
-			time.Sleep(waitTime)
-		}
+// wake tells the delivery routine that an event is due, without waiting for it
+func (s *Service) wake() {
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
 	}
-
-	return lastErr
 }
 
 // getWebhookConfig retrieves webhook configuration
@@ -136,6 +182,9 @@ func (s *Service) getWebhookConfig(ctx context.Context) (*WebhookConfig, error)
 		WebhookTimeoutSeconds: config.WebhookTimeoutSeconds,
 		WebhookRetryAttempts:  config.WebhookRetryAttempts,
 		Headers:               config.Headers,
+		Events:                config.WebhookEvents,
+		Secret:                config.WebhookSecret,
+		PayloadTemplate:       config.WebhookPayloadTemplate,
 	}, nil
 }
 
@@ -144,78 +193,30 @@ type WebhookConfig struct {
 	WebhookTimeoutSeconds int
 	WebhookRetryAttempts  int
 	Headers               map[string]string
+	Events                []string // Events sent; empty sends the whole catalog
+	Secret                string   // Key of the signature header; unsigned without one
+	PayloadTemplate       string   // Template of the body; the envelope without one
 }
 
-// Helper methods for different webhook events
-
-// SendServiceSelectedWebhook sends webhook when a service is selected
-func (s *Service) SendServiceSelectedWebhook(ctx context.Context, ticketID, serviceID, roomID, servicePointID, userID string) error {
-	payload := WebhookPayload{
-		Event:          "service_selected",
-		TicketID:       ticketID,
-		ServiceID:      serviceID,
-		State:          "service_selected",
-		Timestamp:      time.Now(),
-		RoomID:         roomID,
-		ServicePointID: servicePointID,
-		UserID:         userID,
-	}
-	return s.SendWebhook(ctx, payload)
-}
-
-// SendTicketCalledWebhook sends webhook when a ticket is called
-func (s *Service) SendTicketCalledWebhook(ctx context.Context, ticketID, roomID, servicePointID, userID string) error {
-	payload := WebhookPayload{
-		Event:          "ticket_called",
-		TicketID:       ticketID,
-		State:          "called",
+// EntryPayload returns the envelope of an entry.* event about entry in its current state
+func EntryPayload(event string, entry *queue.Entry) WebhookPayload {
+	return WebhookPayload{
+		Event:          event,
+		TicketID:       entry.ID,
+		TicketNumber:   entry.TicketNumber,
+		State:          strings.ToLower(entry.Status),
 		Timestamp:      time.Now(),
-		RoomID:         roomID,
-		ServicePointID: servicePointID,
-		UserID:         userID,
-	}
-	return s.SendWebhook(ctx, payload)
-}
-
-// SendTicketCompletedWebhook sends webhook when a ticket is completed
-func (s *Service) SendTicketCompletedWebhook(ctx context.Context, ticketID, roomID, servicePointID, userID string) error {
-	payload := WebhookPayload{
-		Event:          "ticket_completed",
-		TicketID:       ticketID,
-		State:          "completed",
-		Timestamp:      time.Now(),
-		RoomID:         roomID,
-		ServicePointID: servicePointID,
-		UserID:         userID,
-	}
-	return s.SendWebhook(ctx, payload)
-}
-
-// SendTicketCancelledWebhook sends webhook when a ticket is cancelled
-func (s *Service) SendTicketCancelledWebhook(ctx context.Context, ticketID, roomID, servicePointID, userID string) error {
-	payload := WebhookPayload{
-		Event:          "ticket_cancelled",
-		TicketID:       ticketID,
-		State:          "cancelled",
-		Timestamp:      time.Now(),
-		RoomID:         roomID,
-		ServicePointID: servicePointID,
-		UserID:         userID,
+		RoomID:         entry.WaitingRoomID,
+		ServicePointID: entry.ServicePoint,
 	}
-	return s.SendWebhook(ctx, payload)
 }
 
-// SendGenericStateChangeWebhook sends webhook for any state change
-func (s *Service) SendGenericStateChangeWebhook(ctx context.Context, ticketID, state, roomID, servicePointID, userID string, additionalData map[string]interface{}) error {
-	payload := WebhookPayload{
-		Event:          "ticket_state_changed",
-		TicketID:       ticketID,
-		State:          state,
+// QueuePayload returns the envelope of a queue.* event about a room or one of its service points
+func QueuePayload(event, roomID, servicePointID string) WebhookPayload {
+	return WebhookPayload{
+		Event:          event,
 		Timestamp:      time.Now(),
 		RoomID:         roomID,
 		ServicePointID: servicePointID,
-		UserID:         userID,
-		AdditionalData: additionalData,
 	}
-	return s.SendWebhook(ctx, payload)
 }
@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"text/template"
+	"time"
+)
+
+// templateFuncs are the functions available in payload templates besides the text/template builtins
+var templateFuncs = template.FuncMap{
+	// json writes a value as JSON, so strings are quoted and escaped
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	// default returns fallback for an empty value
+	"default": func(fallback, v interface{}) interface{} {
+		if v == nil || v == "" {
+			return fallback
+		}
+		return v
+	},
+}
+
+// ParseTemplate parses a payload template of a tenant: a Go text/template rendering the JSON body
+// from the fields of the envelope, named as in the envelope (e.g. {{json .ticketNumber}}); missing
+// keys of additionalData are null in json
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("webhook").Funcs(templateFuncs).Parse(text)
+}
+
+// ValidateTemplate checks that a payload template parses and renders valid JSON for a sample
+// entry.called event
+func ValidateTemplate(text string) error {
+	_, err := RenderPayload(text, WebhookPayload{
+		ID:             "00000000-0000-0000-0000-000000000000",
+		Event:          EventEntryCalled,
+		TenantID:       "building:section",
+		TicketID:       "entry",
+		TicketNumber:   "A-001",
+		ServiceID:      "service",
+		State:          "called",
+		Timestamp:      time.Now(),
+		RoomID:         "room",
+		ServicePointID: "window-1",
+		UserID:         "user",
+		AdditionalData: map[string]interface{}{},
+	})
+	return err
+}
+
+// RenderPayload renders the body of an event with a payload template
+func RenderPayload(text string, payload WebhookPayload) ([]byte, error) {
+	tmpl, err := ParseTemplate(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload template: %w", err)
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, templateData(payload)); err != nil {
+		return nil, fmt.Errorf("failed to render payload template: %w", err)
+	}
+	if !json.Valid(body.Bytes()) {
+		return nil, errors.New("payload template does not render valid JSON")
+	}
+	return body.Bytes(), nil
+}
+
+// templateData returns the fields of the envelope under their JSON names; unlike the envelope it
+// has every field, so templates can use those an event leaves empty
+func templateData(payload WebhookPayload) map[string]interface{} {
+	additionalData := payload.AdditionalData
+	if additionalData == nil {
+		additionalData = map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"id":             payload.ID,
+		"event":          payload.Event,
+		"tenantId":       payload.TenantID,
+		"ticketId":       payload.TicketID,
+		"ticketNumber":   payload.TicketNumber,
+		"serviceId":      payload.ServiceID,
+		"state":          payload.State,
+		"timestamp":      payload.Timestamp.UTC().Format(time.RFC3339),
+		"roomId":         payload.RoomID,
+		"servicePointId": payload.ServicePointID,
+		"userId":         payload.UserID,
+		"additionalData": additionalData,
+	}
+}
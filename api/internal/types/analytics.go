@@ -0,0 +1,13 @@
+package types
+
+// EntryStats are the finished entries of one room and service point on one day, the input of
+// the wait-time analytics
+type EntryStats struct {
+	Date           string    `bson:"date" json:"date"` // YYYY-MM-DD in the time zone of the report
+	RoomID         string    `bson:"roomId" json:"roomId"`
+	ServicePointID string    `bson:"servicePointId,omitempty" json:"servicePointId,omitempty"`
+	Completed      int64     `bson:"completed" json:"completed"`
+	NoShows        int64     `bson:"noShows" json:"noShows"`
+	WaitSeconds    []float64 `bson:"waitSeconds" json:"waitSeconds"`       // From creation to the call, per called entry
+	ServiceSeconds []float64 `bson:"serviceSeconds" json:"serviceSeconds"` // From the call to completion, per completed entry
+}
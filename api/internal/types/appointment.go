@@ -0,0 +1,24 @@
+package types
+
+import "time"
+
+// Appointment is a scheduled visit imported ahead of time (e.g. from the hospital information system).
+// A card swipe of the patient within the check-in window turns it into a queue entry.
+type Appointment struct {
+	ID                     string    `bson:"_id,omitempty" json:"id"`
+	TenantID               string    `bson:"tenantId,omitempty" json:"tenantId,omitempty"`
+	SectionID              string    `bson:"sectionId,omitempty" json:"sectionId,omitempty"`
+	ExternalID             string    `bson:"externalId" json:"externalId"` // ID in the source system, unique per tenant
+	Identifier             string    `bson:"identifier" json:"identifier"` // Patient ID or insurance number read from the card
+	PatientName            string    `bson:"patientName,omitempty" json:"patientName,omitempty"`
+	AppointmentTime        time.Time `bson:"appointmentTime" json:"appointmentTime"`
+	RoomID                 string    `bson:"roomId,omitempty" json:"roomId,omitempty"`
+	ServiceID              string    `bson:"serviceId,omitempty" json:"serviceId,omitempty"`
+	ServiceName            string    `bson:"serviceName,omitempty" json:"serviceName,omitempty"`
+	ServiceDurationSeconds int64     `bson:"serviceDuration,omitempty" json:"serviceDuration,omitempty"`
+	Symbols                []string  `bson:"symbols,omitempty" json:"symbols,omitempty"` // Priority symbols given to the entry
+	Status                 string    `bson:"status" json:"status"`                       // SCHEDULED, CHECKED_IN, CANCELLED
+	EntryID                string    `bson:"entryId,omitempty" json:"entryId,omitempty"` // Queue entry created at check-in
+	CreatedAt              time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt              time.Time `bson:"updatedAt" json:"updatedAt"`
+}
@@ -0,0 +1,56 @@
+package types
+
+import "time"
+
+// Resources administrative changes are recorded for
+const (
+	AuditResourceSystemConfiguration     = "system_configuration"
+	AuditResourceCardReaderConfiguration = "card_reader_configuration"
+	AuditResourceAPIKey                  = "api_key"
+	AuditResourceIntegrationCredentials  = "integration_credentials"
+	AuditResourcePriorityConfiguration   = "priority_configuration"
+	AuditResourceTenant                  = "tenant"
+	AuditResourceQueue                   = "queue"
+)
+
+// Actions of the audit log; bulk queue operations are recorded with their bulk action
+const (
+	AuditActionCreate     = "create"
+	AuditActionUpdate     = "update"
+	AuditActionDelete     = "delete"
+	AuditActionRevoke     = "revoke"
+	AuditActionRollback   = "rollback"
+	AuditActionDeactivate = "deactivate"
+	AuditActionReactivate = "reactivate"
+)
+
+// AuditEntry records one administrative change: who made it, in which tenant, to what, and the
+// values of the fields it changed. Entries are only ever added, never changed or deleted.
+type AuditEntry struct {
+	ID         string        `bson:"_id" json:"id"`
+	TenantID   string        `bson:"tenantId,omitempty" json:"tenantId,omitempty"`
+	SectionID  string        `bson:"sectionId,omitempty" json:"sectionId,omitempty"`
+	Actor      string        `bson:"actor" json:"actor"` // Subject of the token, "system" without one
+	Resource   string        `bson:"resource" json:"resource"`
+	ResourceID string        `bson:"resourceId,omitempty" json:"resourceId,omitempty"`
+	Action     string        `bson:"action" json:"action"`
+	Changes    []AuditChange `bson:"changes,omitempty" json:"changes,omitempty"`
+	CreatedAt  time.Time     `bson:"createdAt" json:"createdAt"`
+}
+
+// AuditChange is the value of a field before and after a change; the field is the dotted path
+// of its JSON name, e.g. externalAPI.webhookUrl
+type AuditChange struct {
+	Field  string `bson:"field" json:"field"`
+	Before any    `bson:"before,omitempty" json:"before,omitempty"`
+	After  any    `bson:"after,omitempty" json:"after,omitempty"`
+}
+
+// AuditFilter selects audit entries; empty fields match all
+type AuditFilter struct {
+	Resource string
+	Action   string
+	Actor    string
+	From     time.Time
+	To       time.Time // Exclusive
+}
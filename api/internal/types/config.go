@@ -7,16 +7,23 @@ import (
 
 // SystemConfiguration represents the complete system configuration stored in MongoDB
 type SystemConfiguration struct {
-	ID            string            `bson:"_id,omitempty" json:"id"`
-	TenantID      string            `bson:"tenantId,omitempty" json:"tenantId,omitempty"` // Building/Hospital ID (e.g., "Nemocnica Spiska nova ves")
-	SectionID     string            `bson:"sectionId,omitempty" json:"sectionId,omitempty"` // Section/Department within tenant (e.g., "Kardiologia pavilon B", "Dentist")
-	ExternalAPI   ExternalAPIConfig `bson:"externalAPI" json:"externalAPI"`
-	Rooms         []RoomConfig      `bson:"rooms" json:"rooms"`
-	DefaultRoom   string            `bson:"defaultRoom" json:"defaultRoom"`
-	WebSocketPath string            `bson:"webSocketPath" json:"webSocketPath"`
-	AllowWildcard bool              `bson:"allowWildcard" json:"allowWildcard"`
-	CreatedAt     time.Time         `bson:"createdAt" json:"createdAt"`
-	UpdatedAt     time.Time         `bson:"updatedAt" json:"updatedAt"`
+	ID            string                       `bson:"_id,omitempty" json:"id"`
+	TenantID      string                       `bson:"tenantId,omitempty" json:"tenantId,omitempty"`   // Building/Hospital ID (e.g., "Nemocnica Spiska nova ves")
+	SectionID     string                       `bson:"sectionId,omitempty" json:"sectionId,omitempty"` // Section/Department within tenant (e.g., "Kardiologia pavilon B", "Dentist")
+	ExternalAPI   ExternalAPIConfig            `bson:"externalAPI" json:"externalAPI"`
+	Rooms         []RoomConfig                 `bson:"rooms" json:"rooms"`
+	DefaultRoom   string                       `bson:"defaultRoom" json:"defaultRoom"`
+	WebSocketPath string                       `bson:"webSocketPath" json:"webSocketPath"`
+	AllowWildcard bool                         `bson:"allowWildcard" json:"allowWildcard"`
+	Pathways      []Pathway                    `bson:"pathways,omitempty" json:"pathways,omitempty"`
+	Retention     *RetentionPolicy             `bson:"retention,omitempty" json:"retention,omitempty"`
+	Notifications *NotificationSettings        `bson:"notifications,omitempty" json:"notifications,omitempty"`
+	Integration   *IntegrationCredentials      `bson:"integration,omitempty" json:"-"`
+	APIKeys       []APIKey                     `bson:"apiKeys,omitempty" json:"-"`
+	FHIR          *FHIRSettings                `bson:"fhir,omitempty" json:"fhir,omitempty"`
+	Messages      map[string]map[string]string `bson:"messages,omitempty" json:"messages,omitempty"` // UI message catalogs of kiosks and displays by language and key
+	CreatedAt     time.Time                    `bson:"createdAt" json:"createdAt"`
+	UpdatedAt     time.Time                    `bson:"updatedAt" json:"updatedAt"`
 }
 
 // ExternalAPIConfig represents external API configuration
@@ -31,13 +38,22 @@ type ExternalAPIConfig struct {
 	WebhookHttpMethod             *string           `bson:"webhookHttpMethod,omitempty" json:"webhookHttpMethod,omitempty"`
 	WebhookTimeoutSeconds         int               `bson:"webhookTimeoutSeconds,omitempty" json:"webhookTimeoutSeconds,omitempty"`
 	WebhookRetryAttempts          int               `bson:"webhookRetryAttempts,omitempty" json:"webhookRetryAttempts,omitempty"`
+	WebhookEvents                 []string          `bson:"webhookEvents,omitempty" json:"webhookEvents,omitempty"`                   // Events sent; empty sends all
+	WebhookSecret                 string            `bson:"webhookSecret,omitempty" json:"webhookSecret,omitempty"`                   // Key of the HMAC signature header
+	WebhookPayloadTemplate        string            `bson:"webhookPayloadTemplate,omitempty" json:"webhookPayloadTemplate,omitempty"` // Go template of the body; the envelope when empty
 	TimeoutSeconds                int               `bson:"timeoutSeconds" json:"timeoutSeconds"`
 	RetryAttempts                 int               `bson:"retryAttempts" json:"retryAttempts"`
 	Headers                       map[string]string `bson:"headers,omitempty" json:"headers,omitempty"`
+	// HIS provider; the URLs above when empty
+	HISProvider          string `bson:"hisProvider,omitempty" json:"hisProvider,omitempty"`                   // generic_rest or nghis_adapter
+	NGHISAdapterURL      string `bson:"nghisAdapterUrl,omitempty" json:"nghisAdapterUrl,omitempty"`           // Base URL of the nghis-adapter
+	VisitNotificationURL string `bson:"visitNotificationUrl,omitempty" json:"visitNotificationUrl,omitempty"` // Receives new visits from generic_rest
 	// Multilingual configuration
 	MultilingualSupport *bool    `bson:"multilingualSupport,omitempty" json:"multilingualSupport,omitempty"`
 	SupportedLanguages  []string `bson:"supportedLanguages,omitempty" json:"supportedLanguages,omitempty"`
-	UseDeepLTranslation *bool    `bson:"useDeepLTranslation,omitempty" json:"useDeepLTranslation,omitempty"`
+	DefaultLanguage     string   `bson:"defaultLanguage,omitempty" json:"defaultLanguage,omitempty"`         // Served for unsupported languages; the first supported when empty
+	UseDeepLTranslation *bool    `bson:"useDeepLTranslation,omitempty" json:"useDeepLTranslation,omitempty"` // Translates the services
+	TranslationProvider string   `bson:"translationProvider,omitempty" json:"translationProvider,omitempty"` // Translates with it; deepl when empty
 	// Appointment services language handling
 	AppointmentServicesLanguageHandling *string `bson:"appointmentServicesLanguageHandling,omitempty" json:"appointmentServicesLanguageHandling,omitempty"`
 	AppointmentServicesLanguageHeader   *string `bson:"appointmentServicesLanguageHeader,omitempty" json:"appointmentServicesLanguageHeader,omitempty"`
@@ -57,20 +73,208 @@ type GenericService struct {
 
 // RoomConfig represents room configuration
 type RoomConfig struct {
-	ID            string               `bson:"id" json:"id"`
-	Name          string               `bson:"name" json:"name"`
-	Description   string               `bson:"description,omitempty" json:"description,omitempty"`
-	ServicePoints []ServicePointConfig `bson:"servicePoints" json:"servicePoints"`
-	IsDefault     bool                 `bson:"isDefault" json:"isDefault"`
+	ID              string               `bson:"id" json:"id"`
+	Name            string               `bson:"name" json:"name"`
+	Description     string               `bson:"description,omitempty" json:"description,omitempty"`
+	ServicePoints   []ServicePointConfig `bson:"servicePoints" json:"servicePoints"`
+	IsDefault       bool                 `bson:"isDefault" json:"isDefault"`
+	Ordering        QueueOrdering        `bson:"ordering,omitempty" json:"ordering,omitempty"`               // Order the room calls its waiting entries in
+	MaxWaiting      int                  `bson:"maxWaiting,omitempty" json:"maxWaiting,omitempty"`           // WAITING entries the room takes (0 = queue.max_waiting_per_room)
+	OverflowRoomID  string               `bson:"overflowRoomId,omitempty" json:"overflowRoomId,omitempty"`   // Room suggested to patients while this one is full
+	ServiceFallback ServiceFallback      `bson:"serviceFallback,omitempty" json:"serviceFallback,omitempty"` // What a service point calls when no entry of its services waits
+	Assignment      AssignmentStrategy   `bson:"assignment,omitempty" json:"assignment,omitempty"`           // How new entries are assigned to service points
+	Display         *RoomDisplay         `bson:"display,omitempty" json:"display,omitempty"`                 // What the display boards of the room show of calls
 }
 
+// RoomDisplay are the settings of the display boards of a room
+type RoomDisplay struct {
+	CallHistory  int  `bson:"callHistory,omitempty" json:"callHistory,omitempty"`   // Last calls a board lists (0 = DefaultDisplayCallHistory)
+	BlinkSeconds int  `bson:"blinkSeconds,omitempty" json:"blinkSeconds,omitempty"` // How long a new call blinks (0 = no blinking)
+	Announce     bool `bson:"announce,omitempty" json:"announce,omitempty"`         // Whether boards announce new calls with a chime or speech
+}
+
+// DefaultDisplayCallHistory is the number of calls boards list in rooms without a callHistory,
+// MaxDisplayCallHistory the most a room can set
+const (
+	DefaultDisplayCallHistory = 5
+	MaxDisplayCallHistory     = 50
+)
+
+// QueueOrdering is the order a room calls its waiting entries in
+type QueueOrdering string
+
+const (
+	// QueueOrderingPriority orders by priority tier, fitness score and arrival (the default)
+	QueueOrderingPriority QueueOrdering = "PRIORITY"
+	// QueueOrderingFIFO orders by arrival only
+	QueueOrderingFIFO QueueOrdering = "FIFO"
+	// QueueOrderingAppointmentFirst calls entries with an appointment by appointment time, then the
+	// others by priority
+	QueueOrderingAppointmentFirst QueueOrdering = "APPOINTMENT_FIRST"
+)
+
+// ServiceFallback is what call-next does at a service point with services when no waiting entry
+// has one of them
+type ServiceFallback string
+
+const (
+	// ServiceFallbackAny calls the highest-priority waiting entry regardless of its service (the default)
+	ServiceFallbackAny ServiceFallback = "ANY"
+	// ServiceFallbackNone calls nobody, leaving the other entries to the service points that handle them
+	ServiceFallbackNone ServiceFallback = "NONE"
+)
+
+// AssignmentStrategy is how a room assigns new entries to its service points
+type AssignmentStrategy string
+
+const (
+	// AssignmentNone leaves new entries unassigned; any service point calls them (the default)
+	AssignmentNone AssignmentStrategy = "NONE"
+	// AssignmentRoundRobin assigns new entries to the service points in turn
+	AssignmentRoundRobin AssignmentStrategy = "ROUND_ROBIN"
+	// AssignmentLeastLoaded assigns to the service point with the fewest waiting entries
+	AssignmentLeastLoaded AssignmentStrategy = "LEAST_LOADED"
+	// AssignmentShortestWait assigns to the service point whose assigned entries take the least time
+	AssignmentShortestWait AssignmentStrategy = "SHORTEST_WAIT"
+)
+
 // ServicePointConfig represents service point configuration
 type ServicePointConfig struct {
-	ID          string `bson:"id" json:"id"`
-	Name        string `bson:"name" json:"name"`
-	Description string `bson:"description,omitempty" json:"description,omitempty"`
-	ManagerID   string `bson:"managerId,omitempty" json:"managerId,omitempty"`
-	ManagerName string `bson:"managerName,omitempty" json:"managerName,omitempty"`
+	ID          string   `bson:"id" json:"id"`
+	Name        string   `bson:"name" json:"name"`
+	Description string   `bson:"description,omitempty" json:"description,omitempty"`
+	ManagerID   string   `bson:"managerId,omitempty" json:"managerId,omitempty"`
+	ManagerName string   `bson:"managerName,omitempty" json:"managerName,omitempty"`
+	Services    []string `bson:"services,omitempty" json:"services,omitempty"` // Service names the point handles (empty = all)
+}
+
+// Pathway is an ordered multi-stage visit (e.g. registration -> nurse -> doctor -> checkout).
+// An entry that completes one stage waits again, with its ticket and priority, for the next.
+type Pathway struct {
+	ID         string         `bson:"id" json:"id"`
+	Name       string         `bson:"name" json:"name"`
+	ServiceIDs []string       `bson:"serviceIds,omitempty" json:"serviceIds,omitempty"` // visits for these services follow the pathway
+	Stages     []PathwayStage `bson:"stages" json:"stages"`
+}
+
+// PathwayStage is one step of a pathway
+type PathwayStage struct {
+	Name           string `bson:"name" json:"name"`
+	RoomID         string `bson:"roomId" json:"roomId"`
+	ServicePointID string `bson:"servicePointId,omitempty" json:"servicePointId,omitempty"` // empty = any service point of the room
+}
+
+// RetentionPolicy is how long a tenant keeps the card data of finished entries. After
+// PurgeAfterDays the anonymizer removes the PurgeFields of the card data (all of them when
+// empty), keeping a keyed hash of the ID number when KeepHashedIDNumber is set.
+type RetentionPolicy struct {
+	PurgeAfterDays     int      `bson:"purgeAfterDays" json:"purgeAfterDays"`               // 0 = card data is kept
+	PurgeFields        []string `bson:"purgeFields,omitempty" json:"purgeFields,omitempty"` // CardData field names, e.g. "photo", "address"
+	KeepHashedIDNumber bool     `bson:"keepHashedIdNumber" json:"keepHashedIdNumber"`       // so returning patients can still be counted
+}
+
+// NotificationSettings are how a tenant notifies the patients who left a phone number or an email
+// on the QR page that their turn is near and that they are called. Phone numbers get an SMS and
+// emails a mail; HTTP gets the ones whose channel has no provider of its own.
+type NotificationSettings struct {
+	ApproachingPeopleAhead int                       `bson:"approachingPeopleAhead" json:"approachingPeopleAhead"`             // notify when this many or fewer wait ahead (0 = only when called)
+	ApproachingMessage     string                    `bson:"approachingMessage,omitempty" json:"approachingMessage,omitempty"` // with {ticket} and {peopleAhead}
+	CalledMessage          string                    `bson:"calledMessage,omitempty" json:"calledMessage,omitempty"`           // with {ticket} and {servicePoint}
+	SMTP                   *SMTPSettings             `bson:"smtp,omitempty" json:"smtp,omitempty"`
+	SMS                    *SMSSettings              `bson:"sms,omitempty" json:"sms,omitempty"`
+	HTTP                   *HTTPNotificationSettings `bson:"http,omitempty" json:"http,omitempty"`
+	Push                   *PushSettings             `bson:"push,omitempty" json:"push,omitempty"`
+}
+
+// PushSettings are the VAPID keys the tenant signs Web Push messages to QR status pages with, and
+// the people-ahead milestones the pages get a push at
+type PushSettings struct {
+	VAPIDPublicKey  string `bson:"vapidPublicKey" json:"vapidPublicKey"`             // base64url uncompressed P-256 point
+	VAPIDPrivateKey string `bson:"vapidPrivateKey" json:"vapidPrivateKey"`           // base64url P-256 scalar
+	Subject         string `bson:"subject,omitempty" json:"subject,omitempty"`       // mailto: or https: contact of the tenant
+	Milestones      []int  `bson:"milestones,omitempty" json:"milestones,omitempty"` // DefaultPushMilestones when empty
+}
+
+// DefaultPushMilestones are the people-ahead milestones of PushSettings without milestones
+var DefaultPushMilestones = []int{5, 1}
+
+// IntegrationCredentials authenticate the external systems that push queue events to the tenant:
+// an API key, stored as its hash, and the secret their requests are signed with
+type IntegrationCredentials struct {
+	APIKeyHash    string    `bson:"apiKeyHash" json:"-"`    // hex SHA-256 of the API key
+	SigningSecret string    `bson:"signingSecret" json:"-"` // HMAC-SHA256 key of X-Webhook-Signature
+	IssuedAt      time.Time `bson:"issuedAt" json:"issuedAt"`
+}
+
+// APIKey authenticates a kiosk, card reader or display of the tenant. Only the hash of the key is
+// stored; revoked keys are kept so the admin views still list them.
+type APIKey struct {
+	ID         string     `bson:"id" json:"id"`
+	Name       string     `bson:"name" json:"name"`
+	DeviceType DeviceType `bson:"deviceType" json:"deviceType"`
+	DeviceID   string     `bson:"deviceId,omitempty" json:"deviceId,omitempty"` // Only this device may use the key; any of the type when empty
+	TenantID   string     `bson:"tenantId,omitempty" json:"tenantId,omitempty"` // Tenant ("buildingId[:sectionId]") the key was issued to
+	KeyHash    string     `bson:"keyHash" json:"-"`                             // hex SHA-256 of the key
+	KeyPrefix  string     `bson:"keyPrefix" json:"keyPrefix"`                   // Start of the key, to tell keys apart
+	CreatedAt  time.Time  `bson:"createdAt" json:"createdAt"`
+	RevokedAt  *time.Time `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+	LastUsedAt *time.Time `bson:"lastUsedAt,omitempty" json:"lastUsedAt,omitempty"`
+	LastUsedIP string     `bson:"lastUsedIp,omitempty" json:"lastUsedIp,omitempty"`
+}
+
+// APIKeyPrefix starts every API key, which tells keys from JWTs
+const APIKeyPrefix = "wrk_"
+
+// DeviceType is the kind of device an API key authenticates; kiosk and display keys grant the
+// role of the same name
+type DeviceType string
+
+const (
+	DeviceTypeKiosk      DeviceType = "kiosk"
+	DeviceTypeCardReader DeviceType = "card_reader"
+	DeviceTypeDisplay    DeviceType = "display"
+)
+
+// DeviceTypes lists the device types API keys can be issued for
+var DeviceTypes = []DeviceType{DeviceTypeKiosk, DeviceTypeCardReader, DeviceTypeDisplay}
+
+// FHIRSettings adapt the FHIR resources of the tenant to the profiles of its integration engine
+type FHIRSettings struct {
+	Profiles         map[string]string `bson:"profiles,omitempty" json:"profiles,omitempty"`                 // Profile URL per resource type (e.g., "Encounter")
+	IdentifierSystem string            `bson:"identifierSystem,omitempty" json:"identifierSystem,omitempty"` // System of patient identifiers, DefaultFHIRIdentifierSystem when empty
+}
+
+// DefaultFHIRIdentifierSystem is the system of the patient identifiers of FHIRSettings without one
+const DefaultFHIRIdentifierSystem = "urn:waiting-room:patient-id"
+
+// SMTPSettings are the mail server notification emails are sent through
+type SMTPSettings struct {
+	Host     string `bson:"host" json:"host"`
+	Port     int    `bson:"port,omitempty" json:"port,omitempty"` // 587 when unset
+	Username string `bson:"username,omitempty" json:"username,omitempty"`
+	Password string `bson:"password,omitempty" json:"password,omitempty"`
+	From     string `bson:"from" json:"from"`
+}
+
+// SMSSettings are the account of a Twilio-compatible SMS API
+type SMSSettings struct {
+	BaseURL    string `bson:"baseUrl,omitempty" json:"baseUrl,omitempty"` // https://api.twilio.com when unset
+	AccountSID string `bson:"accountSid" json:"accountSid"`
+	AuthToken  string `bson:"authToken,omitempty" json:"authToken,omitempty"`
+	From       string `bson:"from" json:"from"`
+}
+
+// HTTPNotificationSettings are a service that gets notifications as JSON and delivers them itself
+type HTTPNotificationSettings struct {
+	URL   string `bson:"url" json:"url"`
+	Token string `bson:"token,omitempty" json:"token,omitempty"` // sent as bearer token
+}
+
+// RetentionPurgeFields are the CardData fields a retention policy can purge, by their JSON names
+var RetentionPurgeFields = []string{
+	"idNumber", "firstName", "lastName", "dateOfBirth", "gender", "nationality", "address",
+	"issuedDate", "expiryDate", "photo", "insuranceNumber", "insurerCode", "insurerName",
+	"insuranceValidFrom", "insuranceValidTo",
 }
 
 // CardReaderStatus represents the status of a card reader
@@ -87,6 +291,79 @@ type CardReaderStatus struct {
 	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
 }
 
+// CardReaderConfig represents the remotely managed configuration of a card reader device
+type CardReaderConfig struct {
+	DeviceID               string    `bson:"id" json:"deviceId"`
+	TenantID               string    `bson:"tenantId,omitempty" json:"tenantId,omitempty"`
+	RoomID                 string    `bson:"roomId" json:"roomId"`
+	ReaderName             string    `bson:"readerName,omitempty" json:"readerName,omitempty"`
+	PKCS11Module           string    `bson:"pkcs11Module,omitempty" json:"pkcs11Module,omitempty"`
+	WebSocketURL           string    `bson:"webSocketUrl,omitempty" json:"webSocketUrl,omitempty"`
+	RefreshIntervalSeconds int       `bson:"refreshIntervalSeconds,omitempty" json:"refreshIntervalSeconds,omitempty"`
+	AutoCheckIn            bool      `bson:"autoCheckIn,omitempty" json:"autoCheckIn,omitempty"`                     // a successful read creates the queue entry, no kiosk needed
+	AutoCheckInServiceID   string    `bson:"autoCheckInServiceId,omitempty" json:"autoCheckInServiceId,omitempty"`   // default service for auto check-in
+	AutoCheckInDuration    int       `bson:"autoCheckInDuration,omitempty" json:"autoCheckInDuration,omitempty"`     // expected service duration in minutes
+	DeviceTokenHash        string    `bson:"deviceTokenHash,omitempty" json:"-"`                                     // SHA-256 of the bearer token, never the token itself
+	ClientCertFingerprint  string    `bson:"clientCertFingerprint,omitempty" json:"clientCertFingerprint,omitempty"` // SHA-256 of the DER client certificate
+	CardDataKey            string    `bson:"cardDataKey,omitempty" json:"-"`                                         // base64 AES-256 key for CardData encryption
+	UpdatedAt              time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Commands the API sends a card reader over its WebSocket connection
+const (
+	CardReaderCommandRestart      = "restart"
+	CardReaderCommandIdentify     = "identify"      // blink and beep so the reader can be found
+	CardReaderCommandReloadConfig = "reload_config" // fetch the remote configuration again
+	CardReaderCommandPullLogs     = "pull_logs"     // report the recent log lines
+)
+
+// CardReaderCommands are the commands a card reader understands
+var CardReaderCommands = []string{CardReaderCommandRestart, CardReaderCommandIdentify, CardReaderCommandReloadConfig, CardReaderCommandPullLogs}
+
+// States of a card reader command. A command is pending until it is sent to the connected reader,
+// which acknowledges it and reports whether it succeeded; commands not sent in time expire.
+const (
+	CardReaderCommandPending      = "pending"
+	CardReaderCommandSent         = "sent"
+	CardReaderCommandAcknowledged = "acknowledged"
+	CardReaderCommandSucceeded    = "succeeded"
+	CardReaderCommandFailed       = "failed"
+	CardReaderCommandExpired      = "expired"
+)
+
+// CardReaderCommand is a command for a card reader and the result the reader reported
+type CardReaderCommand struct {
+	ID             string     `bson:"id" json:"id"`
+	DeviceID       string     `bson:"deviceId" json:"deviceId"`
+	TenantID       string     `bson:"tenantId,omitempty" json:"tenantId,omitempty"`
+	Command        string     `bson:"command" json:"command"`
+	Status         string     `bson:"status" json:"status"`
+	RequestedBy    string     `bson:"requestedBy,omitempty" json:"requestedBy,omitempty"`
+	Output         string     `bson:"output,omitempty" json:"output,omitempty"` // reported by the reader, e.g. its recent logs
+	Error          string     `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt      time.Time  `bson:"createdAt" json:"createdAt"`
+	SentAt         *time.Time `bson:"sentAt,omitempty" json:"sentAt,omitempty"`
+	AcknowledgedAt *time.Time `bson:"acknowledgedAt,omitempty" json:"acknowledgedAt,omitempty"`
+	CompletedAt    *time.Time `bson:"completedAt,omitempty" json:"completedAt,omitempty"`
+}
+
+// IsDone reports whether the command reached a final state
+func (c *CardReaderCommand) IsDone() bool {
+	return c.Status == CardReaderCommandSucceeded || c.Status == CardReaderCommandFailed || c.Status == CardReaderCommandExpired
+}
+
+// CardReaderRelease is the card-reader binary published for one os/arch. Signature is
+// an Ed25519 signature (base64) over "waiting-room-card-reader|<version>|<os>/<arch>|<sha256>".
+type CardReaderRelease struct {
+	Version     string    `bson:"version" json:"version"`
+	OS          string    `bson:"os" json:"os"`
+	Arch        string    `bson:"arch" json:"arch"`
+	URL         string    `bson:"url" json:"url"`
+	SHA256      string    `bson:"sha256" json:"sha256"`
+	Signature   string    `bson:"signature" json:"signature"`
+	PublishedAt time.Time `bson:"publishedAt" json:"publishedAt"`
+}
+
 // Tenant represents a tenant in the system
 type Tenant struct {
 	ID          string    `bson:"id" json:"id"`
@@ -96,6 +373,14 @@ type Tenant struct {
 	Description string    `bson:"description,omitempty" json:"description,omitempty"`
 	CreatedAt   time.Time `bson:"createdAt" json:"createdAt"`
 	UpdatedAt   time.Time `bson:"updatedAt" json:"updatedAt"`
+	// DeactivatedAt is when the tenant was deactivated; its data is purged once the retention
+	// period after it passed
+	DeactivatedAt *time.Time `bson:"deactivatedAt,omitempty" json:"deactivatedAt,omitempty"`
+}
+
+// IsDeactivated reports whether the tenant was deactivated
+func (t *Tenant) IsDeactivated() bool {
+	return t != nil && t.DeactivatedAt != nil
 }
 
 // GetTenantID returns the tenant ID (just the buildingId - the hospital/building)
@@ -118,7 +403,7 @@ func ParseTenantID(tenantID string) (buildingID, sectionID string, err error) {
 	if tenantID == "" {
 		return "", "", fmt.Errorf("invalid tenant ID format: tenant ID must not be empty")
 	}
-	
+
 	// Check if it contains a colon (format: "buildingId:sectionId")
 	for i, char := range tenantID {
 		if char == ':' {
@@ -0,0 +1,12 @@
+package types
+
+import "time"
+
+// DataKey is the key the personal card data of a tenant's entries is encrypted with, stored
+// wrapped (encrypted) with the master key, never in plain
+type DataKey struct {
+	TenantID    string    `bson:"_id" json:"tenantId"`            // Building ID, empty for entries without tenant
+	WrappedKey  string    `bson:"wrappedKey" json:"-"`            // Data key encrypted with the master key
+	MasterKeyID string    `bson:"masterKeyId" json:"masterKeyId"` // Master key it was wrapped with
+	CreatedAt   time.Time `bson:"createdAt" json:"createdAt"`
+}
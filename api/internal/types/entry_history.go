@@ -0,0 +1,20 @@
+package types
+
+import "time"
+
+// EntryHistoryEvent is one status transition of a queue entry. Events are only ever appended,
+// so the history shows when a patient really waited, was called and was served.
+type EntryHistoryEvent struct {
+	ID             string    `bson:"_id,omitempty" json:"id"`
+	EntryID        string    `bson:"entryId" json:"entryId"`
+	TenantID       string    `bson:"tenantId,omitempty" json:"tenantId,omitempty"`
+	SectionID      string    `bson:"sectionId,omitempty" json:"sectionId,omitempty"`
+	RoomID         string    `bson:"roomId" json:"roomId"`                                     // Room the entry is in after the transition
+	FromStatus     string    `bson:"fromStatus,omitempty" json:"fromStatus,omitempty"`         // Empty for the creation of the entry
+	ToStatus       string    `bson:"toStatus" json:"toStatus"`                                 // WAITING, CALLED, IN_ROOM, COMPLETED, ...
+	Event          string    `bson:"event" json:"event"`                                       // What caused it, e.g. "called", "transferred", "expired"
+	ServicePointID string    `bson:"servicePointId,omitempty" json:"servicePointId,omitempty"` // Service point involved, if any
+	ActorID        string    `bson:"actorId,omitempty" json:"actorId,omitempty"`               // Staff user, empty for the kiosk and background jobs
+	Reason         string    `bson:"reason,omitempty" json:"reason,omitempty"`                 // Reason given by staff, e.g. for a priority boost
+	Timestamp      time.Time `bson:"timestamp" json:"timestamp"`
+}
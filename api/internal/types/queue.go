@@ -17,6 +17,14 @@ type Entry struct {
 	ApproximateDurationSeconds int64      `bson:"approximateDuration" json:"approximateDuration"` // Duration in seconds
 	ServiceName                string     `bson:"serviceName,omitempty" json:"serviceName,omitempty"`
 	CardData                   CardData   `bson:"cardData,omitempty" json:"cardData,omitempty"`
+	VisitorCode                string     `bson:"visitorCode,omitempty" json:"visitorCode,omitempty"` // Code of an anonymous walk-in without card data
+	IdempotencyKey             string     `bson:"idempotencyKey,omitempty" json:"-"` // Key of the swipe that created the entry
+	CalledAt                   *time.Time `bson:"calledAt,omitempty" json:"calledAt,omitempty"`       // When the entry was last called
+	CompletedAt                *time.Time `bson:"completedAt,omitempty" json:"completedAt,omitempty"` // When the service of the entry was completed
+	Version                    int64      `bson:"version" json:"version"`                             // Counts the status changes, for compare-and-swap updates
+	ArchivedAt                 *time.Time `bson:"archivedAt,omitempty" json:"archivedAt,omitempty"`   // When the entry was moved to the archive
+	AnonymizedAt               *time.Time `bson:"anonymizedAt,omitempty" json:"anonymizedAt,omitempty"` // When the retention policy purged the card data
+	IDNumberHash               string     `bson:"idNumberHash,omitempty" json:"-"`                      // Keyed hash of the purged ID number, for analytics
 
 	// Priority calculation metadata
 	Symbols          []string   `bson:"symbols,omitempty" json:"symbols,omitempty"`                   // Priority symbols (e.g., "STATIM", "VIP", "IMMOBILE")
@@ -25,6 +33,45 @@ type Entry struct {
 	ManualOverride   *float64   `bson:"manualOverride,omitempty" json:"manualOverride,omitempty"`     // Manual priority override value
 	FitnessScore     float64    `bson:"fitnessScore" json:"fitnessScore"`                             // Calculated fitness score (lower = higher priority)
 	Tier             int        `bson:"tier" json:"tier"`                                             // Priority tier (0 = highest)
+	FitnessBreakdown *FitnessBreakdown `bson:"fitnessBreakdown,omitempty" json:"fitnessBreakdown,omitempty"` // Contribution of each factor to the fitness score
+	TierEscalated    bool       `bson:"tierEscalated,omitempty" json:"tierEscalated,omitempty"`       // Moved up one tier by starvation protection
+
+	// Staff annotations, not shown on the public status page
+	Notes       string     `bson:"notes,omitempty" json:"notes,omitempty"`             // Free-text notes of the staff
+	Tags        []string   `bson:"tags,omitempty" json:"tags,omitempty"`               // Structured tags (e.g., "needs wheelchair", "interpreter required")
+	AnnotatedBy string     `bson:"annotatedBy,omitempty" json:"annotatedBy,omitempty"` // User who last changed the notes or tags
+	AnnotatedAt *time.Time `bson:"annotatedAt,omitempty" json:"annotatedAt,omitempty"` // When the notes or tags were last changed
+
+	// Multi-stage visit
+	PathwayID    string `bson:"pathwayId,omitempty" json:"pathwayId,omitempty"`       // Pathway the visit follows
+	PathwayStage int    `bson:"pathwayStage,omitempty" json:"pathwayStage,omitempty"` // Index of the current stage
+
+	// Contact the patient left on the QR page for notifications, cleared once they are called
+	NotifyPhone        string     `bson:"notifyPhone,omitempty" json:"notifyPhone,omitempty"`               // E.164 phone number for SMS
+	NotifyEmail        string     `bson:"notifyEmail,omitempty" json:"notifyEmail,omitempty"`               // Email address
+	ApproachNotifiedAt *time.Time `bson:"approachNotifiedAt,omitempty" json:"approachNotifiedAt,omitempty"` // When the patient was told their turn is near
+
+	// Web Push subscription of the QR status page, cleared once the patient is called
+	PushSubscription *PushSubscription `bson:"pushSubscription,omitempty" json:"pushSubscription,omitempty"`
+	PushMilestone    *int              `bson:"pushMilestone,omitempty" json:"pushMilestone,omitempty"` // Smallest people-ahead milestone pushed so far
+}
+
+// PushSubscription is the Web Push subscription of a browser, as returned by PushSubscription.toJSON()
+type PushSubscription struct {
+	Endpoint string `bson:"endpoint" json:"endpoint"`
+	P256dh   string `bson:"p256dh" json:"p256dh"` // base64url public key of the browser
+	Auth     string `bson:"auth" json:"auth"`     // base64url authentication secret
+}
+
+// FitnessBreakdown is the part each priority factor contributed to the fitness score of an entry,
+// so staff can see why the entry is ordered where it is
+type FitnessBreakdown struct {
+	SymbolWeights        float64 `bson:"symbolWeights" json:"symbolWeights"`
+	WaitingTime          float64 `bson:"waitingTime" json:"waitingTime"`
+	AppointmentDeviation float64 `bson:"appointmentDeviation" json:"appointmentDeviation"`
+	Age                  float64 `bson:"age" json:"age"`
+	ManualOverride       float64 `bson:"manualOverride" json:"manualOverride"`
+	Starvation           float64 `bson:"starvation" json:"starvation"`
 }
 
 type CardData struct {
@@ -39,4 +86,29 @@ type CardData struct {
 	ExpiryDate  string `bson:"expiryDate" json:"expiryDate"`
 	Photo       string `bson:"photo" json:"photo"`
 	Source      string `bson:"source" json:"source"`
+
+	// Health insurance cards (eGK / EHIC)
+	InsuranceNumber    string `bson:"insuranceNumber,omitempty" json:"insuranceNumber,omitempty"`
+	InsurerCode        string `bson:"insurerCode,omitempty" json:"insurerCode,omitempty"`
+	InsurerName        string `bson:"insurerName,omitempty" json:"insurerName,omitempty"`
+	InsuranceValidFrom string `bson:"insuranceValidFrom,omitempty" json:"insuranceValidFrom,omitempty"`
+	InsuranceValidTo   string `bson:"insuranceValidTo,omitempty" json:"insuranceValidTo,omitempty"`
+
+	// Keyed hash of the ID number, set when the personal fields are encrypted at rest so entries
+	// can still be found by it
+	IDNumberIndex string `bson:"idNumberIndex,omitempty" json:"-"`
+}
+
+// RoomState is the intake state of a room, or of one service point of it
+type RoomState struct {
+	ID             string    `bson:"_id,omitempty" json:"id"`
+	TenantID       string    `bson:"tenantId,omitempty" json:"tenantId,omitempty"`
+	SectionID      string    `bson:"sectionId,omitempty" json:"sectionId,omitempty"`
+	RoomID         string    `bson:"roomId" json:"roomId"`
+	ServicePointID string    `bson:"servicePointId,omitempty" json:"servicePointId,omitempty"` // Empty for the whole room
+	Paused         bool      `bson:"paused" json:"paused"`
+	Reason         string    `bson:"reason,omitempty" json:"reason,omitempty"`                 // Shown to patients, e.g. "Lunch break"
+	RedirectRoomID string    `bson:"redirectRoomId,omitempty" json:"redirectRoomId,omitempty"` // Room new swipes go to while paused (rooms only)
+	UpdatedBy      string    `bson:"updatedBy,omitempty" json:"updatedBy,omitempty"`
+	UpdatedAt      time.Time `bson:"updatedAt" json:"updatedAt"`
 }
@@ -0,0 +1,30 @@
+package types
+
+import "time"
+
+// GlossaryEntry is the fixed translation of a term of a tenant, e.g. of a service name, used
+// instead of a machine translation
+type GlossaryEntry struct {
+	ID         string    `bson:"_id,omitempty" json:"id"`
+	TenantID   string    `bson:"tenantId,omitempty" json:"tenantId,omitempty"`
+	SectionID  string    `bson:"sectionId,omitempty" json:"sectionId,omitempty"`
+	SourceLang string    `bson:"sourceLang" json:"sourceLang"` // Lower case language code, e.g. en
+	TargetLang string    `bson:"targetLang" json:"targetLang"`
+	SourceText string    `bson:"sourceText" json:"sourceText"`
+	TargetText string    `bson:"targetText" json:"targetText"`
+	UpdatedAt  time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// CachedTranslation is a machine translation kept across restarts, so texts are not sent to the
+// translation provider again
+type CachedTranslation struct {
+	TenantID   string    `bson:"tenantId" json:"tenantId"`
+	SectionID  string    `bson:"sectionId" json:"sectionId"`
+	SourceLang string    `bson:"sourceLang" json:"sourceLang"`
+	TargetLang string    `bson:"targetLang" json:"targetLang"`
+	TextHash   string    `bson:"textHash" json:"textHash"` // Hex SHA-256 of the source text
+	SourceText string    `bson:"sourceText" json:"sourceText"`
+	TargetText string    `bson:"targetText" json:"targetText"`
+	CachedAt   time.Time `bson:"cachedAt" json:"cachedAt"`
+	ExpiresAt  time.Time `bson:"expiresAt" json:"expiresAt"` // Removed by the TTL index once passed
+}
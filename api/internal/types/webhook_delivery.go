@@ -0,0 +1,31 @@
+package types
+
+import "time"
+
+// Statuses of a webhook delivery
+const (
+	WebhookDeliveryPending   = "PENDING"   // Waiting for its next attempt
+	WebhookDeliveryDelivered = "DELIVERED" // The webhook answered with a 2xx status
+	WebhookDeliveryFailed    = "FAILED"    // All attempts failed; replay it to try again
+)
+
+// WebhookDelivery is one webhook event in the outbox. The event is stored before it is sent, so
+// it survives restarts, and delivered by the workers of any replica, which lease it while sending.
+type WebhookDelivery struct {
+	ID             string     `bson:"_id,omitempty" json:"id"` // The id of the event envelope
+	TenantID       string     `bson:"tenantId,omitempty" json:"tenantId,omitempty"`
+	SectionID      string     `bson:"sectionId,omitempty" json:"sectionId,omitempty"`
+	Event          string     `bson:"event" json:"event"`
+	URL            string     `bson:"url" json:"url"`         // Webhook URL of the tenant when the event happened
+	Payload        string     `bson:"payload" json:"payload"` // JSON envelope sent as the body
+	Status         string     `bson:"status" json:"status"`
+	Attempts       int        `bson:"attempts" json:"attempts"`
+	MaxAttempts    int        `bson:"maxAttempts" json:"maxAttempts"`
+	NextAttemptAt  time.Time  `bson:"nextAttemptAt" json:"nextAttemptAt"`
+	LockedUntil    *time.Time `bson:"lockedUntil,omitempty" json:"lockedUntil,omitempty"` // Lease of the worker sending it
+	LastStatusCode int        `bson:"lastStatusCode,omitempty" json:"lastStatusCode,omitempty"`
+	LastError      string     `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	CreatedAt      time.Time  `bson:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time  `bson:"updatedAt" json:"updatedAt"`
+	DeliveredAt    *time.Time `bson:"deliveredAt,omitempty" json:"deliveredAt,omitempty"`
+}
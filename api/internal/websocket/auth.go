@@ -0,0 +1,108 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/middleware"
+)
+
+// displayFields are the entry fields public displays see: enough to show who is called where
+var displayFields = map[string]bool{
+	"id":            true,
+	"waitingRoomId": true,
+	"ticketNumber":  true,
+	"status":        true,
+	"position":      true,
+	"servicePoint":  true,
+}
+
+// listedStatuses are the statuses of the entries sent to queue clients
+var listedStatuses = []string{"WAITING", "CALLED", "IN_SERVICE"}
+
+// authorizeSubscription checks the subscription token of a WebSocket upgrade for tenantID and
+// returns the role it grants and the tenant to subscribe to: tenantID, or the token's tenant when
+// the client gave none. A client without a token gets tokenlessRole for the tenant it names, as
+// long as websocket.allow_tokenless_subscriptions is set; an empty tokenlessRole rejects it.
+// Without a secret no client with a token is let in. On failure it returns the HTTP status to
+// reject the upgrade with.
+func authorizeSubscription(secret, tokenlessRole string, r *http.Request, tenantID string) (string, string, int, error) {
+	token := middleware.SubscriptionToken(r)
+	if token == "" && tokenlessRole != "" {
+		if tenantID == "" {
+			return "", "", http.StatusForbidden, errors.New("subscriptions without a token require a tenant")
+		}
+		return tokenlessRole, tenantID, 0, nil
+	}
+	if secret == "" {
+		return "", "", http.StatusUnauthorized, errors.New("subscriptions require a token, but websocket.token_secret is not set")
+	}
+	if token == "" {
+		return "", "", http.StatusUnauthorized, errors.New("subscription token required")
+	}
+	claims, err := middleware.VerifySubscriptionToken(secret, token, time.Now())
+	if err != nil {
+		return "", "", http.StatusUnauthorized, err
+	}
+	if tenantID == "" {
+		tenantID = claims.TenantID
+	}
+	if !claims.Allows(tenantID) {
+		return "", "", http.StatusForbidden, errors.New("subscription token is not valid for this tenant")
+	}
+	return claims.Role, tenantID, 0, nil
+}
+
+// entriesForRole returns the entries a role may see: only the display fields for displays, none
+// for kiosks, and for staff all fields plus the patient name. Other roles see none.
+func entriesForRole(role string, entries []map[string]interface{}, names map[string]string) []map[string]interface{} {
+	switch role {
+	case middleware.SubscriptionRoleKiosk:
+		return []map[string]interface{}{}
+	case middleware.SubscriptionRoleDisplay:
+		filtered := make([]map[string]interface{}, 0, len(entries))
+		for _, entry := range entries {
+			visible := make(map[string]interface{}, len(displayFields))
+			for field, value := range entry {
+				if displayFields[field] {
+					visible[field] = value
+				}
+			}
+			filtered = append(filtered, visible)
+		}
+		return filtered
+	case middleware.SubscriptionRoleStaff:
+		filtered := make([]map[string]interface{}, 0, len(entries))
+		for _, entry := range entries {
+			named := make(map[string]interface{}, len(entry)+1)
+			for field, value := range entry {
+				named[field] = value
+			}
+			if id, _ := entry["id"].(string); names[id] != "" {
+				named["patientName"] = names[id]
+			}
+			filtered = append(filtered, named)
+		}
+		return filtered
+	}
+	return []map[string]interface{}{}
+}
+
+// patientNames returns the patient names of the listed entries of a room when one of clients is
+// staff, nil otherwise
+func (h *Hub) patientNames(ctx context.Context, roomId string, clients []*ClientInfo) map[string]string {
+	for _, client := range clients {
+		if client.role != middleware.SubscriptionRoleStaff {
+			continue
+		}
+		names, err := h.queueService.GetPatientNames(ctx, roomId, listedStatuses)
+		if err != nil {
+			log.Printf("[WebSocket] Failed to get patient names of room %s: %v", roomId, err)
+		}
+		return names
+	}
+	return nil
+}
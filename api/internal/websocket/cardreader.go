@@ -0,0 +1,428 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/internal/errors"
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/rest/handler"
+	cardreaderService "github.com/arfis/waiting-room/internal/service/cardreader"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// maxCardReaderMessageSize bounds one event; card data with a photo stays well below it
+const maxCardReaderMessageSize = 4 << 20
+
+// commandPollInterval is how often the commands of a connected card reader are looked up, to
+// pick up the ones queued on another replica
+const commandPollInterval = 10 * time.Second
+
+// DeviceClient is the connection of a card reader
+type DeviceClient struct {
+	conn     *websocket.Conn
+	deviceID string
+	notify   chan struct{} // a command was queued for the reader
+	writeMux sync.Mutex
+}
+
+// commandMessage sends a command to a card reader
+type commandMessage struct {
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Command string `json:"command"`
+}
+
+// commandReply is a card reader acknowledging a command (command_ack) or reporting its result
+// (command_result)
+type commandReply struct {
+	Type      string `json:"type"`
+	CommandID string `json:"commandId"`
+	Success   bool   `json:"success"`
+	Output    string `json:"output,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// KioskClient is a kiosk UI listening for card reader events
+type KioskClient struct {
+	conn     *websocket.Conn
+	roomID   string // only events for this room; all rooms when empty
+	tenantID string // only events for this tenant (a building covers its sections)
+	role     string // subscription role; only kiosks get the card data
+	writeMux sync.Mutex
+}
+
+// CardReaderHub serves the card reader WebSocket. Card readers connect with their device
+// credential (X-Device-ID plus bearer token or client certificate) and push events; kiosk
+// UIs connect with a subscription token of their tenant and receive the accepted events of
+// their room.
+type CardReaderHub struct {
+	cardReaderService *cardreaderService.Service
+	authMiddleware    *middleware.CardReaderAuthMiddleware
+	upgrader          websocket.Upgrader
+	// tokenSecret signs the subscription tokens kiosks must present; without it no kiosk connects
+	tokenSecret string
+	// tokenlessRole is the role of kiosks without a token, empty when they are rejected
+	tokenlessRole string
+
+	kiosks    map[*websocket.Conn]*KioskClient
+	kiosksMux sync.RWMutex
+
+	devices    map[string]*DeviceClient // by device ID
+	devicesMux sync.RWMutex
+}
+
+// NewCardReaderHub creates a new card reader WebSocket hub
+func NewCardReaderHub(cardReaderService *cardreaderService.Service, authMiddleware *middleware.CardReaderAuthMiddleware, tokenSecret string) *CardReaderHub {
+	return &CardReaderHub{
+		cardReaderService: cardReaderService,
+		authMiddleware:    authMiddleware,
+		tokenSecret:       tokenSecret,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				return true // Allow all origins for development
+			},
+		},
+		kiosks:  make(map[*websocket.Conn]*KioskClient),
+		devices: make(map[string]*DeviceClient),
+	}
+}
+
+// HandleConnection routes a connection to the device or the kiosk side
+func (h *CardReaderHub) HandleConnection(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get(middleware.DEVICE_ID_HEADER) != "" || r.URL.Query().Get("deviceId") != "" {
+		h.authMiddleware.Middleware()(http.HandlerFunc(h.handleDevice)).ServeHTTP(w, r)
+		return
+	}
+	h.handleKiosk(w, r)
+}
+
+// handleDevice reads events from an authenticated card reader until it disconnects.
+// Every event is answered with its ack or an error message. Readers that keep their
+// command connection open (?commands=true) get the commands queued for them on it.
+func (h *CardReaderHub) handleDevice(w http.ResponseWriter, r *http.Request) {
+	deviceID, _ := r.Context().Value(middleware.CARD_READER_DEVICE).(string)
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("[CardReaderWebSocket] Failed to upgrade connection of card reader %s: %v", deviceID, err)
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(maxCardReaderMessageSize)
+	log.Printf("[CardReaderWebSocket] Card reader %s connected", deviceID)
+
+	client := &DeviceClient{conn: conn, deviceID: deviceID, notify: make(chan struct{}, 1)}
+	if r.URL.Query().Get("commands") == "true" {
+		h.devicesMux.Lock()
+		h.devices[deviceID] = client
+		h.devicesMux.Unlock()
+		defer func() {
+			h.devicesMux.Lock()
+			if h.devices[deviceID] == client {
+				delete(h.devices, deviceID)
+			}
+			h.devicesMux.Unlock()
+		}()
+		done := make(chan struct{})
+		defer close(done)
+		go h.deliverCommands(r.Context(), client, done)
+	}
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("[CardReaderWebSocket] Card reader %s connection error: %v", deviceID, err)
+			}
+			return
+		}
+		if string(message) == "ping" {
+			client.write([]byte("pong"))
+			continue
+		}
+
+		reply := commandReply{}
+		if json.Unmarshal(message, &reply) == nil && (reply.Type == "command_ack" || reply.Type == "command_result") {
+			if err := h.handleCommandReply(r.Context(), &reply); err != nil {
+				log.Printf("[CardReaderWebSocket] Rejected %s of command %s from card reader %s: %v", reply.Type, reply.CommandID, deviceID, err)
+				client.writeJSON(errorMessage(err))
+			}
+			continue
+		}
+
+		ack, err := h.ingest(r.Context(), message)
+		if err != nil {
+			log.Printf("[CardReaderWebSocket] Rejected event from card reader %s: %v", deviceID, err)
+			client.writeJSON(errorMessage(err))
+			continue
+		}
+		client.writeJSON(map[string]interface{}{"type": "ack", "token": ack.Token, "duplicate": ack.Duplicate})
+	}
+}
+
+// deliverCommands sends the commands of a connected card reader until done is closed: the ones
+// left from an earlier connection first, then the new ones as they are queued
+func (h *CardReaderHub) deliverCommands(ctx context.Context, client *DeviceClient, done <-chan struct{}) {
+	ticker := time.NewTicker(commandPollInterval)
+	defer ticker.Stop()
+	resend := true
+	for {
+		commands, err := h.cardReaderService.TakeCommands(ctx, resend)
+		if err != nil {
+			log.Printf("[CardReaderWebSocket] Failed to load commands of card reader %s: %v", client.deviceID, err)
+		} else {
+			resend = false
+		}
+		for _, command := range commands {
+			if !client.writeJSON(commandMessage{Type: "command", ID: command.ID, Command: command.Command}) {
+				return
+			}
+			log.Printf("[CardReaderWebSocket] Sent command %s (%s) to card reader %s", command.ID, command.Command, client.deviceID)
+		}
+
+		select {
+		case <-done:
+			return
+		case <-client.notify:
+		case <-ticker.C:
+		}
+	}
+}
+
+// handleCommandReply records a card reader acknowledging a command or reporting its result
+func (h *CardReaderHub) handleCommandReply(ctx context.Context, reply *commandReply) error {
+	if reply.CommandID == "" {
+		return ngErrors.New(ngErrors.MissingRequiredFieldErrorCode, reply.Type+" without commandId", http.StatusBadRequest, nil)
+	}
+	if reply.Type == "command_ack" {
+		return h.cardReaderService.AcknowledgeCommand(ctx, reply.CommandID)
+	}
+	return h.cardReaderService.CompleteCommand(ctx, reply.CommandID, reply.Success, reply.Output, reply.Error)
+}
+
+// NotifyCommand wakes up the command delivery of a card reader connected to this replica; the
+// others pick the command up when they poll
+func (h *CardReaderHub) NotifyCommand(deviceID string) {
+	h.devicesMux.RLock()
+	client := h.devices[deviceID]
+	h.devicesMux.RUnlock()
+	if client == nil {
+		return
+	}
+	select {
+	case client.notify <- struct{}{}:
+	default:
+	}
+}
+
+// ingest decodes and validates one event and hands it to the card reader service
+func (h *CardReaderHub) ingest(ctx context.Context, message []byte) (*dto.CardReaderEventAck, error) {
+	event := dto.CardReaderEvent{}
+	if err := json.Unmarshal(message, &event); err != nil {
+		return nil, ngErrors.New(ngErrors.ValidationErrorCode, "invalid card reader event", http.StatusBadRequest, nil)
+	}
+	if err := handler.GetValidator().Struct(event); err != nil {
+		return nil, ngErrors.RequestValidation(err)
+	}
+	return h.cardReaderService.IngestCardReaderEvent(ctx, &event)
+}
+
+// errorMessage is the reply to a rejected event
+func errorMessage(err error) map[string]interface{} {
+	message := map[string]interface{}{"type": "error", "message": err.Error()}
+	var appErr *ngErrors.ApplicationError
+	if errors.As(err, &appErr) {
+		message["code"] = appErr.Code
+		message["message"] = appErr.Text
+	}
+	return message
+}
+
+// AllowTokenlessSubscriptions lets kiosks without a token connect to the tenant they name, with
+// the kiosk role, until the kiosk UI presents tokens (websocket.allow_tokenless_subscriptions)
+func (h *CardReaderHub) AllowTokenlessSubscriptions() {
+	h.tokenlessRole = middleware.SubscriptionRoleKiosk
+}
+
+// handleKiosk keeps a kiosk UI connection open and answers its health-check pings. Only kiosk
+// and staff tokens bound to a tenant are let in, and only kiosks get the card data.
+func (h *CardReaderHub) handleKiosk(w http.ResponseWriter, r *http.Request) {
+	role, tenantID, status, err := authorizeSubscription(h.tokenSecret, h.tokenlessRole, r, strings.TrimSpace(extractTenantID(r)))
+	if err == nil && role == middleware.SubscriptionRoleDisplay {
+		status, err = http.StatusForbidden, errors.New("display tokens get no card reader events")
+	}
+	if err != nil {
+		log.Printf("[CardReaderWebSocket] Rejected kiosk connection: %v", err)
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("[CardReaderWebSocket] Failed to upgrade kiosk connection: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	client := &KioskClient{
+		conn:     conn,
+		roomID:   strings.TrimSpace(r.URL.Query().Get("roomId")),
+		tenantID: tenantID,
+		role:     role,
+	}
+	h.kiosksMux.Lock()
+	h.kiosks[conn] = client
+	h.kiosksMux.Unlock()
+	log.Printf("[CardReaderWebSocket] Kiosk connected (room: '%s', tenantID: '%s')", client.roomID, client.tenantID)
+
+	defer func() {
+		h.kiosksMux.Lock()
+		delete(h.kiosks, conn)
+		h.kiosksMux.Unlock()
+		log.Printf("[CardReaderWebSocket] Kiosk disconnected (room: '%s', tenantID: '%s')", client.roomID, client.tenantID)
+	}()
+
+	// Kiosks that stop answering pings are dropped like queue clients
+	conn.SetReadLimit(maxClientMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	done := make(chan struct{})
+	defer close(done)
+	go client.keepAlive(done)
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				log.Printf("[CardReaderWebSocket] Kiosk connection error: %v", err)
+			}
+			return
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+		if string(message) == "ping" {
+			client.write(websocket.TextMessage, []byte("pong"))
+		}
+	}
+}
+
+// ForwardEvent sends an accepted card reader event to the kiosks of its room and tenant,
+// in the payload format the kiosk UI already understands. Staff subscriptions get the event
+// without its card data.
+func (h *CardReaderHub) ForwardEvent(ctx context.Context, event *dto.CardReaderEvent) {
+	message, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("[CardReaderWebSocket] Failed to encode event %s: %v", event.Token, err)
+		return
+	}
+	withoutCardData := *event
+	withoutCardData.CardData, withoutCardData.EncryptedCardData = nil, nil
+	strippedMessage, err := json.Marshal(&withoutCardData)
+	if err != nil {
+		log.Printf("[CardReaderWebSocket] Failed to encode event %s: %v", event.Token, err)
+		return
+	}
+	tenantID, _ := ctx.Value(middleware.TENANT).(string)
+
+	h.kiosksMux.RLock()
+	var targets []*KioskClient
+	for _, client := range h.kiosks {
+		if client.wants(event.RoomId, tenantID) {
+			targets = append(targets, client)
+		}
+	}
+	h.kiosksMux.RUnlock()
+
+	sent := 0
+	for _, client := range targets {
+		payload := strippedMessage
+		if client.role == middleware.SubscriptionRoleKiosk {
+			payload = message
+		}
+		if client.write(websocket.TextMessage, payload) {
+			sent++
+		}
+	}
+	log.Printf("[CardReaderWebSocket] Forwarded event %s (%s) for room %s to %d kiosk(s)", event.Token, event.State, event.RoomId, sent)
+}
+
+// wants reports whether the kiosk listens to events of roomID and tenantID. A kiosk
+// subscribed to a whole building (no section) receives the events of all its sections; events
+// and kiosks without a tenant match nothing.
+func (c *KioskClient) wants(roomID, tenantID string) bool {
+	if c.roomID != "" && c.roomID != roomID {
+		return false
+	}
+	if c.tenantID == "" || tenantID == "" {
+		return false
+	}
+	if c.tenantID == tenantID {
+		return true
+	}
+	clientBuilding, clientSection, _ := types.ParseTenantID(c.tenantID)
+	eventBuilding, _, _ := types.ParseTenantID(tenantID)
+	return clientSection == "" && clientBuilding == eventBuilding
+}
+
+// keepAlive pings the kiosk every pingPeriod until done is closed or a ping fails
+func (c *KioskClient) keepAlive(done <-chan struct{}) {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if !c.write(websocket.PingMessage, nil) {
+				return
+			}
+		}
+	}
+}
+
+// write sends one message; concurrent forwards to the same kiosk are serialized
+func (c *KioskClient) write(messageType int, data []byte) bool {
+	c.writeMux.Lock()
+	defer c.writeMux.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	if err := c.conn.WriteMessage(messageType, data); err != nil {
+		log.Printf("[CardReaderWebSocket] Failed to send to kiosk: %v", err)
+		c.conn.Close()
+		return false
+	}
+	return true
+}
+
+// write sends one text message; command delivery and replies to events are serialized
+func (c *DeviceClient) write(data []byte) bool {
+	c.writeMux.Lock()
+	defer c.writeMux.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		log.Printf("[CardReaderWebSocket] Failed to send to card reader %s: %v", c.deviceID, err)
+		c.conn.Close()
+		return false
+	}
+	return true
+}
+
+// writeJSON sends one JSON message
+func (c *DeviceClient) writeJSON(v interface{}) bool {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("[CardReaderWebSocket] Failed to encode message for card reader %s: %v", c.deviceID, err)
+		return false
+	}
+	return c.write(data)
+}
@@ -0,0 +1,33 @@
+package websocket
+
+import (
+	"sync"
+	"time"
+)
+
+// BroadcastCoalesceWindow is how long queue updates of a room and tenant are collected before
+// they are broadcast once
+const BroadcastCoalesceWindow = 100 * time.Millisecond
+
+// CoalesceBroadcasts returns a broadcast function that calls broadcast once per room and tenant
+// for all calls within window of the first one. A service broadcasting a change and the change
+// watcher reporting the same change then cause a single queue update.
+func CoalesceBroadcasts(window time.Duration, broadcast func(roomId string, tenantID string)) func(roomId string, tenantID string) {
+	var mu sync.Mutex
+	pending := make(map[[2]string]bool)
+	return func(roomId string, tenantID string) {
+		key := [2]string{roomId, tenantID}
+		mu.Lock()
+		defer mu.Unlock()
+		if pending[key] {
+			return
+		}
+		pending[key] = true
+		time.AfterFunc(window, func() {
+			mu.Lock()
+			delete(pending, key)
+			mu.Unlock()
+			broadcast(roomId, tenantID)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/arfis/waiting-room/internal/configlayers"
+)
+
+// BroadcastConfigUpdate tells every client whose tenant's configuration is resolved from the
+// changed layer to fetch its settings again:
+//
+//	config_update  the layer whose configuration changed ("tenantId", "buildingId:sectionId",
+//	               "buildingId" or empty for the global configuration)
+//
+// Clients of all channels and protocols get it; it carries no sequence number.
+func (h *Hub) BroadcastConfigUpdate(tenantID string) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"type":     "config_update",
+		"tenantId": tenantID,
+	})
+	if err != nil {
+		log.Printf("[WebSocket] Failed to encode config update: %v", err)
+		return
+	}
+
+	h.clientsMux.RLock()
+	var clients []*ClientInfo
+	for _, tenants := range h.clients {
+		for tenantKey, tenantClients := range tenants {
+			clientTenantID := tenantKey
+			if tenantKey == "default" {
+				clientTenantID = ""
+			}
+			if configlayers.Inherits(clientTenantID, tenantID) {
+				clients = append(clients, tenantClients...)
+			}
+		}
+	}
+	h.clientsMux.RUnlock()
+
+	for _, client := range clients {
+		client.enqueue(payload)
+	}
+	log.Printf("[WebSocket] Sent config update of '%s' to %d clients", tenantID, len(clients))
+}
@@ -25,6 +25,9 @@ func convertEntriesToWebSocketFormat(entries []dto.QueueEntry) []map[string]inte
 		if entry.ServiceName != nil {
 			wsEntry["serviceName"] = *entry.ServiceName
 		}
+		if entry.VisitorCode != nil {
+			wsEntry["visitorCode"] = *entry.VisitorCode
+		}
 		if entry.ServiceDuration != nil {
 			wsEntry["serviceDuration"] = *entry.ServiceDuration
 		}
@@ -34,6 +37,21 @@ func convertEntriesToWebSocketFormat(entries []dto.QueueEntry) []map[string]inte
 		if len(entry.Symbols) > 0 {
 			wsEntry["symbols"] = entry.Symbols
 		}
+		if entry.Notes != nil {
+			wsEntry["notes"] = *entry.Notes
+		}
+		if len(entry.Tags) > 0 {
+			wsEntry["tags"] = entry.Tags
+		}
+		if entry.EstimatedWaitSeconds != nil {
+			wsEntry["estimatedWaitSeconds"] = *entry.EstimatedWaitSeconds
+		}
+		if entry.QueuePaused != nil {
+			wsEntry["queuePaused"] = *entry.QueuePaused
+		}
+		if entry.QueuePausedReason != nil {
+			wsEntry["queuePausedReason"] = *entry.QueuePausedReason
+		}
 		if entry.AppointmentTime != nil {
 			wsEntry["appointmentTime"] = entry.AppointmentTime.Format(time.RFC3339)
 		}
@@ -49,3 +67,26 @@ func convertEntriesToWebSocketFormat(entries []dto.QueueEntry) []map[string]inte
 	}
 	return wsEntries
 }
+
+// convertRoomStatesToWebSocketFormat converts the pause state of a room and its service points
+// to WebSocket message format
+func convertRoomStatesToWebSocketFormat(states []dto.RoomState) []map[string]interface{} {
+	var wsStates []map[string]interface{}
+	for _, state := range states {
+		wsState := map[string]interface{}{
+			"roomId": state.RoomId,
+			"paused": state.Paused,
+		}
+		if state.ServicePointId != nil {
+			wsState["servicePointId"] = *state.ServicePointId
+		}
+		if state.Reason != nil {
+			wsState["reason"] = *state.Reason
+		}
+		if state.RedirectRoomId != nil {
+			wsState["redirectRoomId"] = *state.RedirectRoomId
+		}
+		wsStates = append(wsStates, wsState)
+	}
+	return wsStates
+}
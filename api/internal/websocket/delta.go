@@ -0,0 +1,315 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/arfis/waiting-room/internal/middleware"
+)
+
+// Clients connecting with ?protocol=delta get typed messages instead of a queue_update with the
+// whole queue on every change. Every message of a room and tenant carries the next sequence
+// number of its stream:
+//
+//	queue_snapshot  the whole queue ("entries", in queue order) and "roomState", with the "stream" id
+//	entry_added     a new entry ("entry")
+//	entry_updated   the changed fields of an entry ("entry", always with its "id") and the ones it
+//	                lost ("unset")
+//	entry_removed   an entry that left the listed statuses ("entryId")
+//	room_state      the new pause state of the room ("roomState")
+//
+// A client that missed messages sends {"type": "resync", "stream": <id>, "fromSeq": <last seq>}
+// or reconnects with ?protocol=delta&stream=<id>&fromSeq=<last seq>; it gets the messages after
+// fromSeq, or a new queue_snapshot when they are no longer kept or the stream was restarted.
+const protocolDelta = "delta"
+
+// deltaHistorySize is the number of messages a stream keeps for resyncs
+const deltaHistorySize = 256
+
+// maxDeltaBatch is the number of messages sent for one change or resync; when more are due, e.g.
+// because every position moved, the client gets a queue_snapshot instead
+const maxDeltaBatch = sendBufferSize / 2
+
+// deltaMessage is a message of a stream and its sequence number
+type deltaMessage struct {
+	seq     int64
+	payload []byte
+}
+
+// roomStream is the state the delta clients of a room, tenant and role were last sent. It is only
+// used under the lock of its room and tenant, see Hub.lockStreams.
+type roomStream struct {
+	id        string
+	seq       int64
+	order     []string                              // entry IDs in queue order
+	entries   map[string]map[string]json.RawMessage // entry ID -> field -> value
+	roomState json.RawMessage
+	history   []deltaMessage
+}
+
+// resyncRequest is the message a delta client sends after missing messages
+type resyncRequest struct {
+	Type    string `json:"type"`
+	Stream  string `json:"stream"`
+	FromSeq int64  `json:"fromSeq"`
+}
+
+// lockStreams locks the streams of a room and tenant key and returns the unlock function
+func (h *Hub) lockStreams(roomId, tenantKey string) func() {
+	h.streamsMux.Lock()
+	key := roomId + "|" + tenantKey
+	lock, ok := h.streamLocks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		h.streamLocks[key] = lock
+	}
+	h.streamsMux.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// stream returns the stream of a room, tenant key and role, creating it when needed
+func (h *Hub) stream(roomId, tenantKey, role string) *roomStream {
+	h.streamsMux.Lock()
+	defer h.streamsMux.Unlock()
+
+	key := roomId + "|" + tenantKey + "|" + role
+	stream, ok := h.streams[key]
+	if !ok {
+		stream = &roomStream{id: uuid.NewString(), entries: make(map[string]map[string]json.RawMessage)}
+		h.streams[key] = stream
+	}
+	return stream
+}
+
+// apply records the current entries and room state of the stream and returns the messages that
+// bring its clients from the previous state to it, in sequence order
+func (s *roomStream) apply(roomId string, entries []map[string]interface{}, roomState []map[string]interface{}) [][]byte {
+	var messages [][]byte
+	emit := func(message map[string]interface{}) {
+		s.seq++
+		message["roomId"] = roomId
+		message["seq"] = s.seq
+		payload, err := json.Marshal(message)
+		if err != nil {
+			log.Printf("[WebSocket] Failed to encode %s message: %v", message["type"], err)
+			return
+		}
+		messages = append(messages, payload)
+		s.history = append(s.history, deltaMessage{seq: s.seq, payload: payload})
+		if len(s.history) > deltaHistorySize {
+			s.history = s.history[len(s.history)-deltaHistorySize:]
+		}
+	}
+
+	order := make([]string, 0, len(entries))
+	current := make(map[string]map[string]json.RawMessage, len(entries))
+	for _, entry := range entries {
+		id, _ := entry["id"].(string)
+		fields := make(map[string]json.RawMessage, len(entry))
+		for field, value := range entry {
+			raw, err := json.Marshal(value)
+			if err != nil {
+				continue
+			}
+			fields[field] = raw
+		}
+		order = append(order, id)
+		current[id] = fields
+
+		previous, known := s.entries[id]
+		if !known {
+			emit(map[string]interface{}{"type": "entry_added", "entry": fields})
+			continue
+		}
+		changed := map[string]json.RawMessage{}
+		var unset []string
+		for field, raw := range fields {
+			if string(previous[field]) != string(raw) {
+				changed[field] = raw
+			}
+		}
+		for field := range previous {
+			if _, ok := fields[field]; !ok {
+				unset = append(unset, field)
+			}
+		}
+		if len(changed) == 0 && len(unset) == 0 {
+			continue
+		}
+		changed["id"] = fields["id"]
+		message := map[string]interface{}{"type": "entry_updated", "entry": changed}
+		if len(unset) > 0 {
+			message["unset"] = unset
+		}
+		emit(message)
+	}
+	for _, id := range s.order {
+		if _, ok := current[id]; !ok {
+			emit(map[string]interface{}{"type": "entry_removed", "entryId": id})
+		}
+	}
+
+	// A room state that could not be read is left as it was
+	state, err := json.Marshal(roomState)
+	if roomState != nil && err == nil && string(state) != string(s.roomState) {
+		if s.roomState != nil {
+			emit(map[string]interface{}{"type": "room_state", "roomState": json.RawMessage(state)})
+		}
+		s.roomState = state
+	}
+
+	s.order = order
+	s.entries = current
+	return messages
+}
+
+// snapshot returns a queue_snapshot of the current state of the stream
+func (s *roomStream) snapshot(roomId string) ([]byte, error) {
+	entries := make([]map[string]json.RawMessage, 0, len(s.order))
+	for _, id := range s.order {
+		entries = append(entries, s.entries[id])
+	}
+	roomState := s.roomState
+	if roomState == nil {
+		roomState = json.RawMessage("null")
+	}
+	return json.Marshal(map[string]interface{}{
+		"type":      "queue_snapshot",
+		"roomId":    roomId,
+		"stream":    s.id,
+		"seq":       s.seq,
+		"entries":   entries,
+		"roomState": roomState,
+	})
+}
+
+// batch returns the messages to send for one change: messages, or a queue_snapshot when there are
+// more than maxDeltaBatch of them
+func (s *roomStream) batch(roomId string, messages [][]byte) ([][]byte, error) {
+	if len(messages) <= maxDeltaBatch {
+		return messages, nil
+	}
+	snapshot, err := s.snapshot(roomId)
+	if err != nil {
+		return nil, err
+	}
+	return [][]byte{snapshot}, nil
+}
+
+// since returns the messages after fromSeq, false when the stream no longer keeps all of them
+func (s *roomStream) since(fromSeq int64) ([][]byte, bool) {
+	if fromSeq > s.seq {
+		return nil, false
+	}
+	if fromSeq == s.seq {
+		return nil, true
+	}
+	if len(s.history) == 0 || s.history[0].seq > fromSeq+1 {
+		return nil, false
+	}
+	var messages [][]byte
+	for _, message := range s.history {
+		if message.seq > fromSeq {
+			messages = append(messages, message.payload)
+		}
+	}
+	return messages, true
+}
+
+// resync brings a delta client up to date: the stream is refreshed first, its other delta clients
+// get the resulting messages, and the client gets the messages after fromSeq of streamID or, when
+// they cannot be replayed, a queue_snapshot
+func (h *Hub) resync(client *ClientInfo, roomId, normalizedTenantID, tenantKey, streamID string, fromSeq int64) {
+	ctx := context.Background()
+	if normalizedTenantID != "" && normalizedTenantID != "default" {
+		ctx = context.WithValue(ctx, middleware.TENANT, normalizedTenantID)
+	}
+
+	unlock := h.lockStreams(roomId, tenantKey)
+	defer unlock()
+
+	entries, roomState, err := h.fetchRoom(ctx, roomId)
+	if err != nil {
+		log.Printf("[WebSocket] Failed to get queue entries for resync (tenantID: '%s'): %v", normalizedTenantID, err)
+		return
+	}
+	entries = entriesForRole(client.role, entries, h.patientNames(ctx, roomId, []*ClientInfo{client}))
+	stream := h.stream(roomId, tenantKey, client.role)
+	messages, err := stream.batch(roomId, stream.apply(roomId, entries, roomState))
+	if err != nil {
+		log.Printf("[WebSocket] Failed to encode queue snapshot: %v", err)
+		return
+	}
+	for _, other := range h.deltaClients(roomId, tenantKey, client.role) {
+		if other == client {
+			continue
+		}
+		for _, message := range messages {
+			if !other.enqueue(message) {
+				break
+			}
+		}
+	}
+
+	if streamID == stream.id {
+		if replay, ok := stream.since(fromSeq); ok && len(replay) <= maxDeltaBatch {
+			for _, message := range replay {
+				if !client.enqueue(message) {
+					return
+				}
+			}
+			log.Printf("[WebSocket] Replayed %d messages after seq %d of room %s for tenant '%s'", len(replay), fromSeq, roomId, tenantKey)
+			return
+		}
+	}
+
+	payload, err := stream.snapshot(roomId)
+	if err != nil {
+		log.Printf("[WebSocket] Failed to encode queue snapshot: %v", err)
+		return
+	}
+	client.enqueue(payload)
+	log.Printf("[WebSocket] Sent queue snapshot (seq %d) of room %s for tenant '%s'", stream.seq, roomId, tenantKey)
+}
+
+// handleClientMessage answers the resync requests of a delta client; other messages are ignored
+func (h *Hub) handleClientMessage(client *ClientInfo, roomId, normalizedTenantID, tenantKey string, message []byte) {
+	if !client.delta {
+		return
+	}
+	var request resyncRequest
+	if err := json.Unmarshal(message, &request); err != nil || request.Type != "resync" {
+		return
+	}
+	h.resync(client, roomId, normalizedTenantID, tenantKey, request.Stream, request.FromSeq)
+}
+
+// deltaClients returns the delta clients of a room, tenant key and role
+func (h *Hub) deltaClients(roomId, tenantKey, role string) []*ClientInfo {
+	h.clientsMux.RLock()
+	defer h.clientsMux.RUnlock()
+
+	var clients []*ClientInfo
+	for _, client := range h.clients[roomId][tenantKey] {
+		if client.delta && client.role == role {
+			clients = append(clients, client)
+		}
+	}
+	return clients
+}
+
+// parseFromSeq returns the fromSeq query parameter of a reconnecting delta client, -1 without one
+func parseFromSeq(value string) int64 {
+	seq, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return seq
+}
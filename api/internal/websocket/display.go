@@ -0,0 +1,188 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/arfis/waiting-room/internal/middleware"
+	"github.com/arfis/waiting-room/internal/service/announcement"
+	queueService "github.com/arfis/waiting-room/internal/service/queue"
+	"github.com/arfis/waiting-room/internal/types"
+)
+
+// Clients connecting with ?channel=display are display boards. Instead of the queue they get the
+// calls of their room and tenant, so they need not find them by comparing queue updates:
+//
+//	display_state  the display settings of the room ("display") and its last calls ("calls",
+//	               newest first), on connect
+//	call           an entry called to a service point ("call") and the last calls ("calls")
+//	announcement   the spoken announcement of a call ("announcement", with the "url" of its MP3
+//	               audio), when announcements are configured
+//
+// A call carries the ticket number, the service point and its name, the time of the call and the
+// "blink" and "announce" flags of the room's display settings; "repeat" is set when the entry is
+// among the last calls already. The last calls are kept in memory per replica and are lost on restart.
+const channelDisplay = "display"
+
+// displayCall is a call in display board messages
+type displayCall struct {
+	EntryID          string    `json:"entryId"`
+	TicketNumber     string    `json:"ticketNumber"`
+	ServicePointID   string    `json:"servicePointId"`
+	ServicePointName string    `json:"servicePointName,omitempty"`
+	CalledAt         time.Time `json:"calledAt"`
+	Blink            bool      `json:"blink"`
+	BlinkSeconds     int       `json:"blinkSeconds,omitempty"`
+	Announce         bool      `json:"announce"`
+	Repeat           bool      `json:"repeat,omitempty"`
+}
+
+// BroadcastCall records a call and sends it to the display boards of its room that subscribed to
+// the entry's tenant or to its building
+func (h *Hub) BroadcastCall(call queueService.CallEvent) {
+	ctx := context.Background()
+	if call.TenantID != "" {
+		ctx = context.WithValue(ctx, middleware.TENANT, call.TenantID)
+	}
+	display := h.queueService.GetRoomDisplay(ctx, call.RoomID)
+
+	for _, tenantKey := range callTenantKeys(call.TenantID) {
+		entry, calls := h.recordCall(call.RoomID, tenantKey, displayCall{
+			EntryID:          call.EntryID,
+			TicketNumber:     call.TicketNumber,
+			ServicePointID:   call.ServicePointID,
+			ServicePointName: call.ServicePointName,
+			CalledAt:         call.CalledAt,
+			Blink:            display.BlinkSeconds > 0,
+			BlinkSeconds:     display.BlinkSeconds,
+			Announce:         display.Announce,
+		}, display.CallHistory)
+
+		clients := h.displayClients(call.RoomID, tenantKey)
+		if len(clients) == 0 {
+			continue
+		}
+		payload, err := json.Marshal(map[string]interface{}{
+			"type":   "call",
+			"roomId": call.RoomID,
+			"call":   entry,
+			"calls":  calls,
+		})
+		if err != nil {
+			log.Printf("[WebSocket] Failed to encode call: %v", err)
+			return
+		}
+		for _, client := range clients {
+			client.enqueue(payload)
+		}
+		log.Printf("[WebSocket] Sent call of ticket %s to %s to %d display boards of room %s for tenant '%s'", call.TicketNumber, call.ServicePointID, len(clients), call.RoomID, tenantKey)
+	}
+}
+
+// BroadcastAnnouncement sends the announcement of a call to the display boards of its room that
+// subscribed to the entry's tenant or to its building
+func (h *Hub) BroadcastAnnouncement(announcement *announcement.Announcement) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"type":         "announcement",
+		"roomId":       announcement.RoomID,
+		"announcement": announcement,
+	})
+	if err != nil {
+		log.Printf("[WebSocket] Failed to encode announcement: %v", err)
+		return
+	}
+	for _, tenantKey := range callTenantKeys(announcement.TenantID) {
+		for _, client := range h.displayClients(announcement.RoomID, tenantKey) {
+			client.enqueue(payload)
+		}
+	}
+}
+
+// callTenantKeys returns the tenant keys of the display boards that get the calls of an entry of
+// tenantID: its own and, for a section, the one of its building
+func callTenantKeys(tenantID string) []string {
+	building, section, err := types.ParseTenantID(tenantID)
+	if err != nil {
+		return []string{"default"}
+	}
+	if section != "" {
+		return []string{tenantID, building}
+	}
+	return []string{tenantID}
+}
+
+// recordCall adds a call to the last calls of a room and tenant key, keeping at most history, and
+// returns it, marked as repeat when the entry was called before, and the last calls
+func (h *Hub) recordCall(roomId, tenantKey string, call displayCall, history int) (displayCall, []displayCall) {
+	h.callsMux.Lock()
+	defer h.callsMux.Unlock()
+
+	key := roomId + "|" + tenantKey
+	calls := make([]displayCall, 0, history)
+	for _, previous := range h.calls[key] {
+		if previous.EntryID == call.EntryID {
+			call.Repeat = true
+			continue
+		}
+		calls = append(calls, previous)
+	}
+	calls = append([]displayCall{call}, calls...)
+	if len(calls) > history {
+		calls = calls[:history]
+	}
+	h.calls[key] = calls
+	return call, append([]displayCall(nil), calls...)
+}
+
+// recentCalls returns the last calls of a room and tenant key, newest first
+func (h *Hub) recentCalls(roomId, tenantKey string, history int) []displayCall {
+	h.callsMux.Lock()
+	defer h.callsMux.Unlock()
+
+	calls := h.calls[roomId+"|"+tenantKey]
+	if len(calls) > history {
+		calls = calls[:history]
+	}
+	return append([]displayCall{}, calls...)
+}
+
+// sendDisplayState sends the display settings and the last calls to a newly connected display board
+func (h *Hub) sendDisplayState(client *ClientInfo, roomId, normalizedTenantID, tenantKey string) {
+	ctx := context.Background()
+	if normalizedTenantID != "" && normalizedTenantID != "default" {
+		ctx = context.WithValue(ctx, middleware.TENANT, normalizedTenantID)
+	}
+	display := h.queueService.GetRoomDisplay(ctx, roomId)
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"type":   "display_state",
+		"roomId": roomId,
+		"display": map[string]interface{}{
+			"callHistory":  display.CallHistory,
+			"blinkSeconds": display.BlinkSeconds,
+			"announce":     display.Announce,
+		},
+		"calls": h.recentCalls(roomId, tenantKey, display.CallHistory),
+	})
+	if err != nil {
+		log.Printf("[WebSocket] Failed to encode display state: %v", err)
+		return
+	}
+	client.enqueue(payload)
+}
+
+// displayClients returns the display boards of a room and tenant key
+func (h *Hub) displayClients(roomId, tenantKey string) []*ClientInfo {
+	h.clientsMux.RLock()
+	defer h.clientsMux.RUnlock()
+
+	var clients []*ClientInfo
+	for _, client := range h.clients[roomId][tenantKey] {
+		if client.display {
+			clients = append(clients, client)
+		}
+	}
+	return clients
+}
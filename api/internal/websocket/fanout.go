@@ -0,0 +1,136 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	queueService "github.com/arfis/waiting-room/internal/service/queue"
+)
+
+// fanoutQueueSize is the number of queue updates waiting to be published before new ones are dropped
+const fanoutQueueSize = 256
+
+// fanoutMessage is a queue update or, with Call, a call relayed between API replicas
+type fanoutMessage struct {
+	Instance string                  `json:"instance"`
+	RoomID   string                  `json:"roomId"`
+	TenantID string                  `json:"tenantId"`
+	Call     *queueService.CallEvent `json:"call,omitempty"`
+}
+
+// RedisFanout relays queue updates between API replicas through a Redis pub/sub channel, so
+// clients connected to any replica get the updates of all of them. Each replica delivers its own
+// updates to its clients directly and only the ones of the other replicas from the channel.
+// Calls for display boards are relayed the same way.
+type RedisFanout struct {
+	client      *redis.Client
+	channel     string
+	instance    string
+	deliver     func(roomId string, tenantID string)
+	deliverCall func(call queueService.CallEvent)
+	outgoing    chan fanoutMessage
+}
+
+// NewRedisFanout connects to the Redis server at url (redis://[user:password@]host:port/db) and
+// returns a fan-out that calls deliver with the queue updates of the other replicas once Run
+func NewRedisFanout(url, channel string, deliver func(roomId string, tenantID string)) (*RedisFanout, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to ping Redis: %w", err)
+	}
+
+	return &RedisFanout{
+		client:   client,
+		channel:  channel,
+		instance: uuid.NewString(),
+		deliver:  deliver,
+		outgoing: make(chan fanoutMessage, fanoutQueueSize),
+	}, nil
+}
+
+// Publish queues a queue update of this replica for the other replicas without blocking
+func (f *RedisFanout) Publish(roomId string, tenantID string) {
+	select {
+	case f.outgoing <- fanoutMessage{Instance: f.instance, RoomID: roomId, TenantID: tenantID}:
+	default:
+		log.Printf("[Fanout] Publish queue full, dropping update of room %s, tenantID '%s'", roomId, tenantID)
+	}
+}
+
+// SetCallHandler sets the function that gets the calls of the other replicas; call it before Run
+func (f *RedisFanout) SetCallHandler(deliverCall func(call queueService.CallEvent)) {
+	f.deliverCall = deliverCall
+}
+
+// PublishCall queues a call of this replica for the other replicas without blocking
+func (f *RedisFanout) PublishCall(call queueService.CallEvent) {
+	select {
+	case f.outgoing <- fanoutMessage{Instance: f.instance, RoomID: call.RoomID, TenantID: call.TenantID, Call: &call}:
+	default:
+		log.Printf("[Fanout] Publish queue full, dropping call of ticket %s in room %s", call.TicketNumber, call.RoomID)
+	}
+}
+
+// Run publishes the queued updates and delivers the ones of the other replicas until ctx is done.
+// The subscription reconnects by itself when Redis goes away.
+func (f *RedisFanout) Run(ctx context.Context) {
+	subscription := f.client.Subscribe(ctx, f.channel)
+	defer subscription.Close()
+	incoming := subscription.Channel()
+	log.Printf("[Fanout] Relaying queue updates through Redis channel %s", f.channel)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message := <-f.outgoing:
+			payload, err := json.Marshal(message)
+			if err != nil {
+				continue
+			}
+			publishCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+			if err := f.client.Publish(publishCtx, f.channel, payload).Err(); err != nil {
+				log.Printf("[Fanout] Failed to publish update of room %s: %v", message.RoomID, err)
+			}
+			cancel()
+		case received, ok := <-incoming:
+			if !ok {
+				return
+			}
+			var message fanoutMessage
+			if err := json.Unmarshal([]byte(received.Payload), &message); err != nil {
+				log.Printf("[Fanout] Ignoring invalid message: %v", err)
+				continue
+			}
+			if message.Instance == f.instance || message.RoomID == "" {
+				continue
+			}
+			if message.Call != nil {
+				if f.deliverCall != nil {
+					f.deliverCall(*message.Call)
+				}
+				continue
+			}
+			f.deliver(message.RoomID, message.TenantID)
+		}
+	}
+}
+
+// Close closes the connection to Redis
+func (f *RedisFanout) Close() error {
+	return f.client.Close()
+}
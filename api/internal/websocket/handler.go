@@ -4,8 +4,10 @@ import (
 	"context"
 	"log"
 	"net/http"
+	"encoding/json"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -18,33 +20,71 @@ import (
 // ClientInfo stores information about a WebSocket client
 type ClientInfo struct {
 	conn     *websocket.Conn
-	tenantID string // tenantID from query parameter or header
+	tenantID string      // tenantID from query parameter or header
+	role     string      // role of the subscription token
+	delta    bool        // the client speaks the delta protocol, see delta.go
+	display  bool        // the client is a display board and only gets calls, see display.go
+	send     chan []byte // messages waiting for writePump
+	done     chan struct{}
+	// lastSeen is the UnixNano time of the last pong or message of the client
+	lastSeen  atomic.Int64
+	closeOnce sync.Once
 }
 
 // Hub manages WebSocket connections and broadcasts
 type Hub struct {
 	queueService *queueService.Service
 	upgrader     websocket.Upgrader
+	// tokenSecret signs subscription tokens; without it every subscription is rejected
+	tokenSecret string
+	// tokenlessRole is the role of clients without a token, empty when they are rejected
+	tokenlessRole string
 	// clients structure: roomId -> tenantID -> []*ClientInfo
 	// This allows us to efficiently find all clients for a specific tenant
 	clients    map[string]map[string][]*ClientInfo
 	clientsMux sync.RWMutex
+	// streams holds the delta state per room, tenant key and role: roomId|tenantKey|role -> stream.
+	// The streams of a room and tenant key are only used under its lock: roomId|tenantKey -> lock
+	streams     map[string]*roomStream
+	streamLocks map[string]*sync.Mutex
+	streamsMux  sync.Mutex
+	// calls holds the last calls for display boards: roomId|tenantKey -> calls, newest first
+	calls    map[string][]displayCall
+	callsMux sync.Mutex
+	// tenants resolves the tenant of a subscription, so deactivated ones are turned away
+	tenants middleware.TenantResolver
 }
 
-// NewHub creates a new WebSocket hub
-func NewHub(queueService *queueService.Service) *Hub {
+// NewHub creates a new WebSocket hub. Clients must present a subscription token signed with
+// tokenSecret and get the entries their role may see.
+func NewHub(queueService *queueService.Service, tokenSecret string) *Hub {
 	return &Hub{
 		queueService: queueService,
+		tokenSecret:  tokenSecret,
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				return true // Allow all origins for development
 			},
 		},
 		clients: make(map[string]map[string][]*ClientInfo),
+		streams:     make(map[string]*roomStream),
+		streamLocks: make(map[string]*sync.Mutex),
+		calls:       make(map[string][]displayCall),
 	}
 }
 
 // HandleConnection handles a WebSocket connection for queue updates
+// AllowTokenlessSubscriptions lets clients without a token subscribe to the tenant they name, with
+// the staff role, until the UIs present tokens (websocket.allow_tokenless_subscriptions)
+func (h *Hub) AllowTokenlessSubscriptions() {
+	h.tokenlessRole = middleware.SubscriptionRoleStaff
+}
+
+// SetTenantResolver makes the hub reject the subscriptions of deactivated tenants
+func (h *Hub) SetTenantResolver(tenants middleware.TenantResolver) {
+	h.tenants = tenants
+}
+
 func (h *Hub) HandleConnection(w http.ResponseWriter, r *http.Request) {
 	log.Printf("WebSocket handler called for room: %s", chi.URLParam(r, "roomId"))
 
@@ -59,6 +99,35 @@ func (h *Hub) HandleConnection(w http.ResponseWriter, r *http.Request) {
 	tenantID := extractTenantID(r)
 	log.Printf("[WebSocket] Attempting to upgrade WebSocket connection for room: %s, tenantID: '%s'", roomId, tenantID)
 
+	// Normalize tenant ID: trim whitespace for consistency
+	normalizedTenantID := strings.TrimSpace(tenantID)
+
+	// With authentication, the token decides what the client sees and of which tenant
+	role, normalizedTenantID, status, err := authorizeSubscription(h.tokenSecret, h.tokenlessRole, r, normalizedTenantID)
+	if err != nil {
+		log.Printf("[WebSocket] Rejected subscription to room %s: %v", roomId, err)
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if h.tenants != nil && normalizedTenantID != "" {
+		tenant, err := h.tenants.ResolveTenant(r.Context(), normalizedTenantID)
+		if err != nil {
+			log.Printf("[WebSocket] Failed to resolve tenant %s: %v", normalizedTenantID, err)
+			http.Error(w, "failed to resolve tenant", http.StatusInternalServerError)
+			return
+		}
+		if tenant.IsDeactivated() {
+			log.Printf("[WebSocket] Rejected subscription to room %s of deactivated tenant %s", roomId, normalizedTenantID)
+			http.Error(w, "tenant "+normalizedTenantID+" is deactivated", http.StatusForbidden)
+			return
+		}
+	}
+	display := r.URL.Query().Get("channel") == channelDisplay
+	if display && role == middleware.SubscriptionRoleKiosk {
+		http.Error(w, "kiosk tokens cannot subscribe to display boards", http.StatusForbidden)
+		return
+	}
+
 	// Check if the response writer supports hijacking
 	if _, ok := w.(http.Hijacker); !ok {
 		log.Printf("ERROR: Response writer does not implement http.Hijacker")
@@ -73,14 +142,13 @@ func (h *Hub) HandleConnection(w http.ResponseWriter, r *http.Request) {
 	}
 	defer conn.Close()
 
-	// Normalize tenant ID: trim whitespace for consistency
-	normalizedTenantID := strings.TrimSpace(tenantID)
-
 	// Store client info with normalized tenantID
-	clientInfo := &ClientInfo{
-		conn:     conn,
-		tenantID: normalizedTenantID,
-	}
+	clientInfo := newClientInfo(conn, normalizedTenantID)
+	clientInfo.role = role
+	clientInfo.display = display
+	clientInfo.delta = !display && r.URL.Query().Get("protocol") == protocolDelta
+	go clientInfo.writePump()
+	defer clientInfo.close()
 
 	// Use normalized tenant ID as key (use "default" for empty tenant ID)
 	tenantKey := normalizedTenantID
@@ -97,27 +165,41 @@ func (h *Hub) HandleConnection(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[WebSocket] Client connected to room: %s, tenantID: '%s' (stored under key: '%s')", roomId, tenantID, tenantKey)
 
 	// Send initial queue data to the newly connected client
-	go h.sendInitialData(conn, roomId, normalizedTenantID, tenantKey)
+	if clientInfo.display {
+		go h.sendDisplayState(clientInfo, roomId, normalizedTenantID, tenantKey)
+	} else if clientInfo.delta {
+		go h.resync(clientInfo, roomId, normalizedTenantID, tenantKey, r.URL.Query().Get("stream"), parseFromSeq(r.URL.Query().Get("fromSeq")))
+	} else {
+		go h.sendInitialData(clientInfo, roomId, normalizedTenantID, tenantKey)
+	}
 
 	// Remove client when connection closes
 	defer h.removeClient(roomId, tenantKey, conn)
 
-	// Keep connection alive
+	// Keep connection alive while the client answers pings; a client silent for longer than
+	// pongWait is dropped
+	conn.SetReadLimit(maxClientMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		clientInfo.touch()
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
 	for {
-		_, _, err := conn.ReadMessage()
+		_, message, err := conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket error: %v", err)
 			}
 			break
 		}
+		clientInfo.touch()
+		_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+		h.handleClientMessage(clientInfo, roomId, normalizedTenantID, tenantKey, message)
 	}
 }
 
 // sendInitialData sends initial queue data to a newly connected client
-func (h *Hub) sendInitialData(conn *websocket.Conn, roomId, normalizedTenantID, tenantKey string) {
-	// Small delay to ensure the client is fully connected
-	time.Sleep(100 * time.Millisecond)
+func (h *Hub) sendInitialData(client *ClientInfo, roomId, normalizedTenantID, tenantKey string) {
 	log.Printf("[WebSocket] Sending initial queue data to newly connected client for tenantID: '%s' (key: '%s')", normalizedTenantID, tenantKey)
 
 	// Create context with normalized tenantID for filtering
@@ -129,47 +211,29 @@ func (h *Hub) sendInitialData(conn *websocket.Conn, roomId, normalizedTenantID,
 		log.Printf("[WebSocket] WARNING: normalized tenantID is empty or 'default', will get all entries (no tenant filter)")
 	}
 
-	// Get queue entries from service
-	entries, err := h.queueService.GetQueueEntries(ctx, roomId, []string{"WAITING", "CALLED", "IN_SERVICE"})
+	wsEntries, roomState, err := h.fetchRoom(ctx, roomId)
 	if err != nil {
 		log.Printf("[WebSocket] Failed to get initial queue entries for tenantID '%s': %v", normalizedTenantID, err)
 		return
 	}
+	wsEntries = entriesForRole(client.role, wsEntries, h.patientNames(ctx, roomId, []*ClientInfo{client}))
 
-	log.Printf("[WebSocket] Retrieved %d initial entries for tenantID '%s' (key: '%s') in room %s", len(entries), normalizedTenantID, tenantKey, roomId)
-
-	// Convert to WebSocket format
-	wsEntries := convertEntriesToWebSocketFormat(entries)
+	log.Printf("[WebSocket] Retrieved %d initial entries for tenantID '%s' (key: '%s') in room %s", len(wsEntries), normalizedTenantID, tenantKey, roomId)
 
 	message := map[string]interface{}{
 		"type":    "queue_update",
 		"roomId":  roomId,
 		"entries": wsEntries,
 	}
-
-	// Send to only this specific client
-	h.clientsMux.RLock()
-	var foundClient *ClientInfo
-	if roomClients, exists := h.clients[roomId]; exists {
-		if tenantClients, exists := roomClients[tenantKey]; exists {
-			for _, client := range tenantClients {
-				if client.conn == conn {
-					foundClient = client
-					break
-				}
-			}
-		}
+	if roomState != nil {
+		message["roomState"] = roomState
 	}
-	h.clientsMux.RUnlock()
 
-	if foundClient != nil {
-		if err := foundClient.conn.WriteJSON(message); err != nil {
-			log.Printf("[WebSocket] Failed to send initial queue data: %v", err)
-		} else {
-			log.Printf("[WebSocket] Successfully sent initial queue data (%d entries) to client with tenantID: '%s'", len(wsEntries), normalizedTenantID)
-		}
-	} else {
-		log.Printf("[WebSocket] Client not found in room %s for tenant '%s' for initial data send", roomId, tenantKey)
+	payload, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("[WebSocket] Failed to encode initial queue data: %v", err)
+	} else if client.enqueue(payload) {
+		log.Printf("[WebSocket] Queued initial queue data (%d entries) for client with tenantID: '%s'", len(wsEntries), normalizedTenantID)
 	}
 }
 
@@ -194,12 +258,17 @@ func (h *Hub) BroadcastQueueUpdate(roomId string, targetTenantID string) {
 
 	log.Printf("[WebSocket] Broadcasting for tenantID: '%s' (normalized: '%s', key: '%s')", targetTenantID, normalizedTargetTenantID, tenantKey)
 
-	// Get clients for this specific tenant
+	// Get clients for this specific tenant; display boards only get calls
 	h.clientsMux.RLock()
-	tenantClients, tenantExists := h.clients[roomId][tenantKey]
+	var tenantClients []*ClientInfo
+	for _, clientInfo := range h.clients[roomId][tenantKey] {
+		if !clientInfo.display {
+			tenantClients = append(tenantClients, clientInfo)
+		}
+	}
 	h.clientsMux.RUnlock()
 
-	if !tenantExists || len(tenantClients) == 0 {
+	if len(tenantClients) == 0 {
 		log.Printf("[WebSocket] No clients found for tenantID '%s' (key: '%s') in room %s", targetTenantID, tenantKey, roomId)
 		return
 	}
@@ -216,38 +285,99 @@ func (h *Hub) BroadcastQueueUpdate(roomId string, targetTenantID string) {
 		log.Printf("[WebSocket] WARNING: Using default context (no tenantID) for %d clients - this will return all entries!", len(tenantClients))
 	}
 
-	// Get queue entries from service
-	entries, err := h.queueService.GetQueueEntries(ctx, roomId, []string{"WAITING", "CALLED", "IN_SERVICE"})
+	// Broadcasts of a room and tenant are serialized, so delta clients get their messages in
+	// sequence order
+	unlock := h.lockStreams(roomId, tenantKey)
+	defer unlock()
+
+	wsEntries, roomState, err := h.fetchRoom(ctx, roomId)
 	if err != nil {
 		log.Printf("[WebSocket] Failed to get queue entries for broadcast (tenantID: '%s'): %v", targetTenantID, err)
 		return
 	}
 
-	log.Printf("[WebSocket] Retrieved %d entries for tenantID '%s' in room %s", len(entries), targetTenantID, roomId)
+	log.Printf("[WebSocket] Retrieved %d entries for tenantID '%s' in room %s", len(wsEntries), targetTenantID, roomId)
 
-	// Convert to WebSocket format
-	wsEntries := convertEntriesToWebSocketFormat(entries)
-
-	message := map[string]interface{}{
-		"type":    "queue_update",
-		"roomId":  roomId,
-		"entries": wsEntries,
+	// Each role gets the entries it may see
+	names := h.patientNames(ctx, roomId, tenantClients)
+	byRole := make(map[string][]*ClientInfo)
+	for _, clientInfo := range tenantClients {
+		byRole[clientInfo.role] = append(byRole[clientInfo.role], clientInfo)
 	}
+	sentCount := 0
+	for role, clients := range byRole {
+		sentCount += h.sendUpdate(roomId, tenantKey, role, clients, entriesForRole(role, wsEntries, names), roomState)
+	}
+	log.Printf("[WebSocket] Queued queue update (%d entries) for %d/%d clients for tenantID '%s'", len(wsEntries), sentCount, len(tenantClients), targetTenantID)
+}
 
-	log.Printf("[WebSocket] Broadcasting queue update to %d clients with tenantID '%s' in room %s: %d entries", len(tenantClients), targetTenantID, roomId, len(wsEntries))
+// sendUpdate queues the update of a room for clients of one role: the messages of the role's
+// delta stream for delta clients, a queue_update for the others. Each client's writePump sends
+// them, so a slow client cannot hold up the others. It returns the number of clients queued for.
+func (h *Hub) sendUpdate(roomId, tenantKey, role string, clients []*ClientInfo, entries, roomState []map[string]interface{}) int {
+	var legacyClients, deltaClients []*ClientInfo
+	for _, clientInfo := range clients {
+		if clientInfo.delta {
+			deltaClients = append(deltaClients, clientInfo)
+		} else {
+			legacyClients = append(legacyClients, clientInfo)
+		}
+	}
 
-	// Send to clients in this tenant group
 	sentCount := 0
-	for _, clientInfo := range tenantClients {
-		if err := clientInfo.conn.WriteJSON(message); err != nil {
-			log.Printf("[WebSocket] Failed to send WebSocket message: %v", err)
-			clientInfo.conn.Close()
-		} else {
+	stream := h.stream(roomId, tenantKey, role)
+	deltas, err := stream.batch(roomId, stream.apply(roomId, entries, roomState))
+	if err != nil {
+		log.Printf("[WebSocket] Failed to encode queue snapshot: %v", err)
+		return 0
+	}
+	for _, clientInfo := range deltaClients {
+		queued := true
+		for _, payload := range deltas {
+			if queued = clientInfo.enqueue(payload); !queued {
+				break
+			}
+		}
+		if queued {
 			sentCount++
-			log.Printf("[WebSocket] Successfully sent message to client with tenantID: '%s'", clientInfo.tenantID)
 		}
 	}
-	log.Printf("[WebSocket] Successfully sent queue update to %d/%d clients for tenantID '%s'", sentCount, len(tenantClients), targetTenantID)
+
+	if len(legacyClients) > 0 {
+		message := map[string]interface{}{
+			"type":    "queue_update",
+			"roomId":  roomId,
+			"entries": entries,
+		}
+		if roomState != nil {
+			message["roomState"] = roomState
+		}
+		payload, err := json.Marshal(message)
+		if err != nil {
+			log.Printf("[WebSocket] Failed to encode queue update: %v", err)
+			return sentCount
+		}
+		for _, clientInfo := range legacyClients {
+			if clientInfo.enqueue(payload) {
+				sentCount++
+			}
+		}
+	}
+	return sentCount
+}
+
+// fetchRoom returns the listed entries and the room state of a room in WebSocket message format;
+// the room state is nil when it cannot be read, empty when nothing is paused
+func (h *Hub) fetchRoom(ctx context.Context, roomId string) ([]map[string]interface{}, []map[string]interface{}, error) {
+	entries, err := h.queueService.GetQueueEntries(ctx, roomId, listedStatuses)
+	if err != nil {
+		return nil, nil, err
+	}
+	var roomState []map[string]interface{}
+	if states, err := h.queueService.GetRoomState(ctx, roomId); err == nil {
+		roomState = append([]map[string]interface{}{}, convertRoomStatesToWebSocketFormat(states)...)
+	}
+	return convertEntriesToWebSocketFormat(entries), roomState, nil
 }
 
 // addClient adds a client to the hub
@@ -0,0 +1,136 @@
+package websocket
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// writeWait is how long writing one message to a client may take
+	writeWait = 10 * time.Second
+	// pongWait is how long a client may stay silent (no pong, no message) before it is dropped
+	pongWait = 60 * time.Second
+	// pingPeriod is how often clients are pinged, below pongWait so a live client always answers in time
+	pingPeriod = pongWait * 9 / 10
+	// sendBufferSize is the number of messages queued for a client; a client that lets it fill
+	// up is not keeping up and is dropped
+	sendBufferSize = 128
+	// maxClientMessageSize bounds what queue clients send, which is only keep-alive chatter
+	maxClientMessageSize = 4096
+)
+
+// newClientInfo returns a client of conn whose messages are written by writePump
+func newClientInfo(conn *websocket.Conn, tenantID string) *ClientInfo {
+	client := &ClientInfo{
+		conn:     conn,
+		tenantID: tenantID,
+		send:     make(chan []byte, sendBufferSize),
+		done:     make(chan struct{}),
+	}
+	client.touch()
+	return client
+}
+
+// touch records that the client is alive
+func (c *ClientInfo) touch() {
+	c.lastSeen.Store(time.Now().UnixNano())
+}
+
+// enqueue queues message for the client without blocking. A client whose queue is full is closed.
+func (c *ClientInfo) enqueue(message []byte) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+	select {
+	case c.send <- message:
+		return true
+	default:
+		log.Printf("[WebSocket] Client with tenantID '%s' is not keeping up, closing it", c.tenantID)
+		c.close()
+		return false
+	}
+}
+
+// writePump is the only writer of the connection: it sends the queued messages and the pings,
+// each within writeWait, until the client is closed
+func (c *ClientInfo) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case message := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				log.Printf("[WebSocket] Failed to send WebSocket message to client with tenantID '%s': %v", c.tenantID, err)
+				c.close()
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+// close stops writePump and closes the connection, which ends the read loop of HandleConnection
+// and removes the client from the hub
+func (c *ClientInfo) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// StartReaper starts a background routine that closes the clients not heard from within pongWait,
+// in case their read deadline did not fire
+func (h *Hub) StartReaper(ctx context.Context) {
+	ticker := time.NewTicker(pingPeriod)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				h.reap(time.Now())
+			}
+		}
+	}()
+}
+
+// reap closes the clients last seen before now minus pongWait and returns how many
+func (h *Hub) reap(now time.Time) int {
+	deadline := now.Add(-pongWait).UnixNano()
+
+	h.clientsMux.RLock()
+	var stale []*ClientInfo
+	for _, roomClients := range h.clients {
+		for _, tenantClients := range roomClients {
+			for _, client := range tenantClients {
+				if client.lastSeen.Load() < deadline {
+					stale = append(stale, client)
+				}
+			}
+		}
+	}
+	h.clientsMux.RUnlock()
+
+	for _, client := range stale {
+		client.close()
+	}
+	if len(stale) > 0 {
+		log.Printf("[WebSocket] Reaped %d unresponsive clients", len(stale))
+	}
+	return len(stale)
+}
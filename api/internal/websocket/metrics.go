@@ -0,0 +1,55 @@
+package websocket
+
+import (
+	"context"
+
+	"github.com/arfis/waiting-room/internal/metrics"
+)
+
+// ClientCounter returns the collect function of the WebSocket clients gauge: the queue and display
+// clients of hub and the kiosks and card readers with a command connection of cardReaders, which
+// may be nil
+func ClientCounter(hub *Hub, cardReaders *CardReaderHub) func(ctx context.Context) ([]metrics.Sample, error) {
+	return func(context.Context) ([]metrics.Sample, error) {
+		queue, display := hub.clientCounts()
+		var kiosks, devices int
+		if cardReaders != nil {
+			kiosks, devices = cardReaders.clientCounts()
+		}
+		return []metrics.Sample{
+			{LabelValues: []string{"queue"}, Value: float64(queue)},
+			{LabelValues: []string{"display"}, Value: float64(display)},
+			{LabelValues: []string{"kiosk"}, Value: float64(kiosks)},
+			{LabelValues: []string{"card_reader"}, Value: float64(devices)},
+		}, nil
+	}
+}
+
+// clientCounts returns the number of connected queue clients and display boards
+func (h *Hub) clientCounts() (queue, display int) {
+	h.clientsMux.RLock()
+	defer h.clientsMux.RUnlock()
+	for _, tenants := range h.clients {
+		for _, clients := range tenants {
+			for _, client := range clients {
+				if client.display {
+					display++
+				} else {
+					queue++
+				}
+			}
+		}
+	}
+	return queue, display
+}
+
+// clientCounts returns the number of connected kiosks and card readers
+func (h *CardReaderHub) clientCounts() (kiosks, devices int) {
+	h.kiosksMux.RLock()
+	kiosks = len(h.kiosks)
+	h.kiosksMux.RUnlock()
+	h.devicesMux.RLock()
+	devices = len(h.devices)
+	h.devicesMux.RUnlock()
+	return kiosks, devices
+}
@@ -0,0 +1,160 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/arfis/waiting-room/internal/data/dto"
+	queueService "github.com/arfis/waiting-room/internal/service/queue"
+)
+
+// statusKeepAliveInterval is how often an idle status stream sends a comment, so proxies keep it open
+const statusKeepAliveInterval = 25 * time.Second
+
+// statusClient is one phone page following the status of its entry
+type statusClient struct {
+	updates chan struct{}
+}
+
+// StatusStream pushes the QR status of entries to phone pages as Server-Sent Events, so the
+// patient page updates live without the tenant-scoped WebSocket
+type StatusStream struct {
+	queueService *queueService.Service
+	// clients structure: roomId -> clients whose entry waits in the room
+	clients    map[string]map[*statusClient]bool
+	clientsMux sync.RWMutex
+}
+
+// NewStatusStream creates a new status stream
+func NewStatusStream(queueService *queueService.Service) *StatusStream {
+	return &StatusStream{
+		queueService: queueService,
+		clients:      make(map[string]map[*statusClient]bool),
+	}
+}
+
+// HandleEvents streams the status of the entry of the token in the URL: once on connect, then
+// whenever the queue of its room changes
+func (s *StatusStream) HandleEvents(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		log.Printf("ERROR: Response writer does not implement http.Flusher")
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	status, err := s.queueService.GetQueueEntryByToken(r.Context(), token)
+	if err != nil {
+		http.Error(w, "Queue entry not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	client := &statusClient{updates: make(chan struct{}, 1)}
+	roomId := status.GetWaitingRoomID()
+	s.addClient(roomId, client)
+	defer func() { s.removeClient(roomId, client) }()
+
+	last, err := writeStatusEvent(w, flusher, status, nil)
+	if err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(statusKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-client.updates:
+			status, err := s.queueService.GetQueueEntryByToken(context.Background(), token)
+			if err != nil {
+				log.Printf("[StatusStream] Failed to get status of QR token: %v", err)
+				continue
+			}
+			// A transferred entry follows the queue of its new room
+			if newRoomId := status.GetWaitingRoomID(); newRoomId != roomId {
+				s.removeClient(roomId, client)
+				roomId = newRoomId
+				s.addClient(roomId, client)
+			}
+			if last, err = writeStatusEvent(w, flusher, status, last); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// NotifyRoom makes the status streams of the entries of roomId send their new status. It has the
+// signature of the services' broadcast function; clients are found by room only, so an entry of
+// another tenant with the same room ID re-reads its unchanged status and sends nothing.
+func (s *StatusStream) NotifyRoom(roomId string, tenantID string) {
+	s.clientsMux.RLock()
+	defer s.clientsMux.RUnlock()
+
+	for client := range s.clients[roomId] {
+		// A pending update already reads the latest status
+		select {
+		case client.updates <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// writeStatusEvent writes status as a "status" event unless it equals the last one sent, and
+// returns the data sent last
+func writeStatusEvent(w http.ResponseWriter, flusher http.Flusher, status *dto.PublicEntry, last []byte) ([]byte, error) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return last, err
+	}
+	if bytes.Equal(data, last) {
+		return last, nil
+	}
+	if _, err := fmt.Fprintf(w, "event: status\ndata: %s\n\n", data); err != nil {
+		return last, err
+	}
+	flusher.Flush()
+	return data, nil
+}
+
+// addClient subscribes a client to the updates of a room
+func (s *StatusStream) addClient(roomId string, client *statusClient) {
+	s.clientsMux.Lock()
+	defer s.clientsMux.Unlock()
+
+	if s.clients[roomId] == nil {
+		s.clients[roomId] = make(map[*statusClient]bool)
+	}
+	s.clients[roomId][client] = true
+}
+
+// removeClient unsubscribes a client from the updates of a room
+func (s *StatusStream) removeClient(roomId string, client *statusClient) {
+	s.clientsMux.Lock()
+	defer s.clientsMux.Unlock()
+
+	delete(s.clients[roomId], client)
+	if len(s.clients[roomId]) == 0 {
+		delete(s.clients, roomId)
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ebfe/scard"
+)
+
+// -----------------------------
+// APDU transport
+// -----------------------------
+
+// All card parsers go through transport instead of raw card.Transmit, so status word
+// handling is the same everywhere:
+//   - 61xx: the rest of the response is fetched with GET RESPONSE
+//   - 6Cxx: the command is repeated with the Le the card asked for
+//   - T=0 case 4 commands are sent without Le (the card answers 61xx)
+//   - commands with more than 255 data bytes use extended length if enabled,
+//     command chaining otherwise
+//   - READ BINARY is chunked and stops cleanly at the end of the file
+
+// apduCommand is one command APDU. Ne is the number of expected response bytes,
+// 0 = no Le field, 256 = "00" (as much as the card has in a short APDU).
+type apduCommand struct {
+	CLA, INS, P1, P2 byte
+	Data             []byte
+	Ne               int
+}
+
+// statusWordError is a non-9000 status word. Its text contains "SW=XXXX", which
+// explainSW and the failure metrics rely on.
+type statusWordError uint16
+
+func (e statusWordError) Error() string { return fmt.Sprintf("SW=%04X", uint16(e)) }
+
+const (
+	swOK            = 0x9000
+	swEndOfFile     = 0x6282 // fewer bytes than Le: end of file reached
+	maxShortData    = 255
+	maxGetResponses = 64
+)
+
+// apduCard is the part of *scard.Card the transport uses.
+type apduCard interface {
+	Transmit(cmd []byte) ([]byte, error)
+	BeginTransaction() error
+	EndTransaction(d scard.Disposition) error
+	Disconnect(d scard.Disposition) error
+}
+
+type transport struct {
+	card     apduCard
+	t0       bool
+	extended bool // card accepts extended-length APDUs
+}
+
+func newTransport(card *scard.Card) *transport {
+	t := &transport{card: card, t0: card.ActiveProtocol() == scard.ProtocolT0}
+	if settings != nil {
+		t.extended = settings.get().ExtendedAPDU
+	}
+	return t
+}
+
+// connectTransport opens a shared connection; close it with t.close().
+func connectTransport(ctx scard.Context, reader string) (*transport, error) {
+	card, err := ctx.Connect(reader, scard.ShareShared, scard.ProtocolAny)
+	if err != nil {
+		return nil, err
+	}
+	return newTransport(card), nil
+}
+
+func (t *transport) close() { _ = t.card.Disconnect(scard.LeaveCard) }
+
+// encode builds the wire form; extended selects the 3-byte Lc / 2-byte Le encoding.
+func (c apduCommand) encode(extended bool) []byte {
+	b := []byte{c.CLA, c.INS, c.P1, c.P2}
+	if extended {
+		if len(c.Data) > 0 {
+			b = append(b, 0x00, byte(len(c.Data)>>8), byte(len(c.Data)))
+			b = append(b, c.Data...)
+		}
+		if c.Ne > 0 {
+			if len(c.Data) == 0 {
+				b = append(b, 0x00)
+			}
+			ne := c.Ne
+			if ne >= 65536 {
+				ne = 0
+			}
+			b = append(b, byte(ne>>8), byte(ne))
+		}
+		return b
+	}
+	if len(c.Data) > 0 {
+		b = append(b, byte(len(c.Data)))
+		b = append(b, c.Data...)
+	}
+	if c.Ne > 0 {
+		b = append(b, byte(c.Ne)) // 256 -> 0x00
+	}
+	return b
+}
+
+// transceive sends cmd and returns the complete response data and the final status
+// word. Only transport errors are returned as error; use command for "must be 9000".
+func (t *transport) transceive(cmd apduCommand) ([]byte, uint16, error) {
+	if len(cmd.Data) > maxShortData && !t.extended {
+		return t.chain(cmd)
+	}
+	if !t.extended && cmd.Ne > 256 {
+		cmd.Ne = 256 // the rest comes via 61xx or further reads
+	}
+	if t.t0 && len(cmd.Data) > 0 {
+		cmd.Ne = 0 // T=0 can't carry Lc and Le together
+	}
+	extended := t.extended && (len(cmd.Data) > maxShortData || cmd.Ne > 256)
+
+	data, sw, err := t.exchange(cmd.encode(extended))
+	if err != nil {
+		return nil, 0, err
+	}
+	if sw>>8 == 0x6C {
+		// wrong Le: repeat with the length the card reported
+		cmd.Ne = lengthFromSW2(sw)
+		if data, sw, err = t.exchange(cmd.encode(false)); err != nil {
+			return nil, 0, err
+		}
+	}
+	for i := 0; sw>>8 == 0x61 && i < maxGetResponses; i++ {
+		get := apduCommand{CLA: cmd.CLA &^ 0x10, INS: 0xC0, Ne: lengthFromSW2(sw)}
+		var more []byte
+		if more, sw, err = t.exchange(get.encode(false)); err != nil {
+			return nil, 0, err
+		}
+		data = append(data, more...)
+	}
+	return data, sw, nil
+}
+
+// chain splits the data over several APDUs with the chaining bit set on all but the last.
+func (t *transport) chain(cmd apduCommand) ([]byte, uint16, error) {
+	rest := cmd.Data
+	for len(rest) > maxShortData {
+		part := cmd
+		part.CLA |= 0x10
+		part.Data, part.Ne = rest[:maxShortData], 0
+		_, sw, err := t.exchange(part.encode(false))
+		if err != nil {
+			return nil, 0, err
+		}
+		if sw != swOK {
+			return nil, sw, nil
+		}
+		rest = rest[maxShortData:]
+	}
+	last := cmd
+	last.Data = rest
+	return t.transceive(last)
+}
+
+func (t *transport) exchange(raw []byte) ([]byte, uint16, error) {
+	resp, err := t.card.Transmit(raw)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(resp) < 2 {
+		return nil, 0, errors.New("short response")
+	}
+	n := len(resp) - 2
+	return resp[:n], uint16(resp[n])<<8 | uint16(resp[n+1]), nil
+}
+
+// command is transceive for commands that must succeed with 9000.
+func (t *transport) command(cmd apduCommand) ([]byte, error) {
+	data, sw, err := t.transceive(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if sw != swOK {
+		return nil, statusWordError(sw)
+	}
+	return data, nil
+}
+
+// transaction runs fn inside one PC/SC transaction, so no other application can
+// interleave commands (and e.g. change the selected file) halfway through.
+func (t *transport) transaction(fn func() error) error {
+	if err := t.card.BeginTransaction(); err != nil {
+		return err
+	}
+	defer t.card.EndTransaction(scard.LeaveCard)
+	return fn()
+}
+
+// batch runs cmds in order in one transaction. It stops at the first failure and
+// returns the responses collected so far.
+func (t *transport) batch(cmds ...apduCommand) ([][]byte, error) {
+	out := make([][]byte, 0, len(cmds))
+	err := t.transaction(func() error {
+		for i, cmd := range cmds {
+			data, err := t.command(cmd)
+			if err != nil {
+				return fmt.Errorf("command %d (INS %02X): %w", i+1, cmd.INS, err)
+			}
+			out = append(out, data)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// selectAID selects an application by AID without asking for FCI.
+func (t *transport) selectAID(aid []byte) error {
+	_, err := t.command(apduCommand{CLA: 0x00, INS: 0xA4, P1: 0x04, P2: 0x0C, Data: aid})
+	return err
+}
+
+// readBinarySFI reads a whole transparent EF: the first READ BINARY selects it by
+// short file identifier, the following ones continue at the offset. Reading stops
+// at the end of the file (6282 / 6B00 / short block).
+func (t *transport) readBinarySFI(sfi byte) ([]byte, error) {
+	block := 0xE0
+	if t.extended {
+		block = 0x0800
+	}
+	data, sw, err := t.transceive(apduCommand{CLA: 0x00, INS: 0xB0, P1: 0x80 | sfi, Ne: block})
+	if err != nil {
+		return nil, err
+	}
+	if sw != swOK && sw != swEndOfFile {
+		return nil, statusWordError(sw)
+	}
+	for sw == swOK && len(data) > 0 && len(data)%block == 0 && len(data) < 0x8000 {
+		off := len(data)
+		var chunk []byte
+		chunk, sw, err = t.transceive(apduCommand{CLA: 0x00, INS: 0xB0, P1: byte(off >> 8), P2: byte(off), Ne: block})
+		if err != nil || (sw != swOK && sw != swEndOfFile) || len(chunk) == 0 {
+			break
+		}
+		data = append(data, chunk...)
+	}
+	return data, nil
+}
+
+// lengthFromSW2 reads the length in a 61xx/6Cxx status word (00 = 256).
+func lengthFromSW2(sw uint16) int {
+	if n := int(sw & 0xFF); n != 0 {
+		return n
+	}
+	return 256
+}
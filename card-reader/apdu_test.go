@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ebfe/scard"
+)
+
+// scriptedCard answers the commands of a transport with the responses of a script, in order
+type scriptedCard struct {
+	t         *testing.T
+	responses []string // hex, data followed by the status word
+	sent      []string // hex of the commands received
+}
+
+func (c *scriptedCard) Transmit(cmd []byte) ([]byte, error) {
+	c.sent = append(c.sent, fmt.Sprintf("%X", cmd))
+	if len(c.responses) == 0 {
+		return nil, errors.New("no response scripted")
+	}
+	resp, err := hex.DecodeString(c.responses[0])
+	if err != nil {
+		c.t.Fatalf("Invalid scripted response %s: %v", c.responses[0], err)
+	}
+	c.responses = c.responses[1:]
+	return resp, nil
+}
+
+func (c *scriptedCard) BeginTransaction() error                  { return nil }
+func (c *scriptedCard) EndTransaction(d scard.Disposition) error { return nil }
+func (c *scriptedCard) Disconnect(d scard.Disposition) error     { return nil }
+
+// TestAPDUEncode tests the short and extended wire forms of commands
+func TestAPDUEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      apduCommand
+		extended bool
+		want     string
+	}{
+		{"case 1", apduCommand{CLA: 0x00, INS: 0xA4, P1: 0x04, P2: 0x0C}, false, "00A4040C"},
+		{"case 2 short", apduCommand{CLA: 0x00, INS: 0xB0, Ne: 0xE0}, false, "00B00000E0"},
+		{"case 2 short 256", apduCommand{CLA: 0x00, INS: 0xB0, Ne: 256}, false, "00B0000000"},
+		{"case 3 short", apduCommand{CLA: 0x00, INS: 0xA4, P1: 0x04, Data: []byte{0xD2, 0x76}}, false, "00A4040002D276"},
+		{"case 4 short", apduCommand{CLA: 0x00, INS: 0x88, Data: []byte{0x01}, Ne: 256}, false, "00880000010100"},
+		{"case 2 extended", apduCommand{CLA: 0x00, INS: 0xB0, Ne: 0x0800}, true, "00B00000000800"},
+		{"case 2 extended 65536", apduCommand{CLA: 0x00, INS: 0xB0, Ne: 65536}, true, "00B00000000000"},
+		{"case 4 extended", apduCommand{CLA: 0x00, INS: 0x88, Data: []byte{0x01}, Ne: 0x0100}, true, "00880000000001010100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fmt.Sprintf("%X", tt.cmd.encode(tt.extended)); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestTransportTransceive tests the status word handling: GET RESPONSE for 61xx, repeating
+// with the reported Le for 6Cxx, Le left out for T=0 case 4 and command chaining
+func TestTransportTransceive(t *testing.T) {
+	long := bytes.Repeat([]byte{0xAA}, maxShortData+10)
+	tests := []struct {
+		name      string
+		t0        bool
+		cmd       apduCommand
+		responses []string
+		wantData  string
+		wantSW    uint16
+		wantSent  []string
+	}{
+		{
+			name:      "plain",
+			cmd:       apduCommand{CLA: 0x00, INS: 0xCA, Ne: 256},
+			responses: []string{"01029000"},
+			wantData:  "0102",
+			wantSW:    swOK,
+			wantSent:  []string{"00CA000000"},
+		},
+		{
+			name:      "61xx fetches the rest",
+			cmd:       apduCommand{CLA: 0x00, INS: 0xCA, Ne: 256},
+			responses: []string{"01026102", "03049000"},
+			wantData:  "01020304",
+			wantSW:    swOK,
+			wantSent:  []string{"00CA000000", "00C0000002"},
+		},
+		{
+			name:      "6Cxx repeats with the reported Le",
+			cmd:       apduCommand{CLA: 0x00, INS: 0xCA, Ne: 256},
+			responses: []string{"6C03", "0102039000"},
+			wantData:  "010203",
+			wantSW:    swOK,
+			wantSent:  []string{"00CA000000", "00CA000003"},
+		},
+		{
+			name:      "T=0 case 4 without Le",
+			t0:        true,
+			cmd:       apduCommand{CLA: 0x00, INS: 0x88, Data: []byte{0x01}, Ne: 256},
+			responses: []string{"6102", "01029000"},
+			wantData:  "0102",
+			wantSW:    swOK,
+			wantSent:  []string{"008800000101", "00C0000002"},
+		},
+		{
+			name:      "long data is chained",
+			cmd:       apduCommand{CLA: 0x00, INS: 0xDA, Data: long},
+			responses: []string{"9000", "9000"},
+			wantData:  "",
+			wantSW:    swOK,
+			wantSent: []string{
+				fmt.Sprintf("10DA0000FF%X", long[:maxShortData]),
+				fmt.Sprintf("00DA00000A%X", long[maxShortData:]),
+			},
+		},
+		{
+			name:      "chaining stops at an error",
+			cmd:       apduCommand{CLA: 0x00, INS: 0xDA, Data: long},
+			responses: []string{"6A80"},
+			wantData:  "",
+			wantSW:    0x6A80,
+			wantSent:  []string{fmt.Sprintf("10DA0000FF%X", long[:maxShortData])},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := &scriptedCard{t: t, responses: tt.responses}
+			tr := &transport{card: card, t0: tt.t0}
+			data, sw, err := tr.transceive(tt.cmd)
+			if err != nil {
+				t.Fatalf("transceive failed: %v", err)
+			}
+			if fmt.Sprintf("%X", data) != tt.wantData || sw != tt.wantSW {
+				t.Errorf("Expected %s SW=%04X, got %X SW=%04X", tt.wantData, tt.wantSW, data, sw)
+			}
+			if fmt.Sprint(card.sent) != fmt.Sprint(tt.wantSent) {
+				t.Errorf("Expected commands %v, got %v", tt.wantSent, card.sent)
+			}
+		})
+	}
+}
+
+// TestTransportReadBinarySFI tests that transparent files are read in blocks up to their end
+func TestTransportReadBinarySFI(t *testing.T) {
+	block := bytes.Repeat([]byte{0x11}, 0xE0)
+	full := fmt.Sprintf("%X9000", block)
+	tests := []struct {
+		name      string
+		responses []string
+		wantLen   int
+		wantErr   bool
+	}{
+		{"short file", []string{"01029000"}, 2, false},
+		{"end of file warning", []string{"01026282"}, 2, false},
+		{"two blocks", []string{full, "01029000"}, 0xE0 + 2, false},
+		{"file ends on a block boundary", []string{full, "6B00"}, 0xE0, false},
+		{"file not found", []string{"6A82"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := &transport{card: &scriptedCard{t: t, responses: tt.responses}}
+			data, err := tr.readBinarySFI(sfiEGKPersonalData)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if len(data) != tt.wantLen {
+				t.Errorf("Expected %d bytes, got %d", tt.wantLen, len(data))
+			}
+		})
+	}
+}
+
+// gzipXML compresses an eGK XML document
+func gzipXML(t *testing.T, doc string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(doc)); err != nil {
+		t.Fatalf("gzip failed: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("gzip failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// egkPD builds the contents of EF.PD: the length of the compressed XML, then the XML
+func egkPD(t *testing.T, doc string) []byte {
+	xml := gzipXML(t, doc)
+	return append(binary.BigEndian.AppendUint16(nil, uint16(len(xml))), xml...)
+}
+
+// egkVD builds the contents of EF.VD: the offsets of the first and last byte of the VD, then the VD
+func egkVD(t *testing.T, doc string) []byte {
+	xml := gzipXML(t, doc)
+	vd := binary.BigEndian.AppendUint16(nil, 8)
+	vd = binary.BigEndian.AppendUint16(vd, uint16(8+len(xml)-1))
+	vd = append(vd, 0, 0, 0, 0)
+	return append(vd, xml...)
+}
+
+// TestParseEGK tests decoding the personal and insurance data of a German eGK
+func TestParseEGK(t *testing.T) {
+	pd := `<?xml version="1.0" encoding="ISO-8859-15"?>
+<UC_PersoenlicheVersichertendatenXML><Versicherter><Versicherten_ID>A123456789</Versicherten_ID>
+<Person><Geburtsdatum>19800102</Geburtsdatum><Vorname>Erika</Vorname><Nachname>Mustermann</Nachname><Geschlecht>W</Geschlecht>
+<StrassenAdresse><Postleitzahl>10115</Postleitzahl><Ort>Berlin</Ort><Strasse>Hauptstr.</Strasse><Hausnummer>1</Hausnummer>
+<Land><Wohnsitzlaendercode>D</Wohnsitzlaendercode></Land></StrassenAdresse></Person></Versicherter></UC_PersoenlicheVersichertendatenXML>`
+	vd := `<?xml version="1.0" encoding="ISO-8859-15"?>
+<UC_AllgemeineVersicherungsdatenXML><Versicherter><Versicherungsschutz><Beginn>20200101</Beginn><Ende>20301231</Ende>
+<Kostentraeger><Kostentraegerkennung>101575519</Kostentraegerkennung><Kostentraegerlaendercode>D</Kostentraegerlaendercode>
+<Name>Techniker Krankenkasse</Name></Kostentraeger></Versicherungsschutz></Versicherter></UC_AllgemeineVersicherungsdatenXML>`
+	noID := `<UC_PersoenlicheVersichertendatenXML><Versicherter><Person><Vorname>Erika</Vorname></Person></Versicherter></UC_PersoenlicheVersichertendatenXML>`
+
+	tests := []struct {
+		name    string
+		pd, vd  []byte
+		want    CardData
+		wantErr bool
+	}{
+		{
+			name: "personal and insurance data",
+			pd:   egkPD(t, pd), vd: egkVD(t, vd),
+			want: CardData{Source: "insurance-egk", IDNumber: "A123456789", InsuranceNumber: "A123456789",
+				FirstName: "Erika", LastName: "Mustermann", DateOfBirth: "1980-01-02", Gender: "W",
+				Address: "Hauptstr. 1, 10115 Berlin", Nationality: "D", InsurerCode: "101575519",
+				InsurerName: "Techniker Krankenkasse", InsuranceValidFrom: "2020-01-01", InsuranceValidTo: "2030-12-31"},
+		},
+		{
+			name: "EF.VD not readable",
+			pd:   egkPD(t, pd),
+			want: CardData{Source: "insurance-egk", IDNumber: "A123456789", InsuranceNumber: "A123456789",
+				FirstName: "Erika", LastName: "Mustermann", DateOfBirth: "1980-01-02", Gender: "W",
+				Address: "Hauptstr. 1, 10115 Berlin", Nationality: "D"},
+		},
+		{name: "EF.PD too short", pd: []byte{0x00}, wantErr: true},
+		{name: "EF.PD length out of range", pd: []byte{0x01, 0x00, 0x1F}, wantErr: true},
+		{name: "EF.PD not compressed", pd: []byte{0x00, 0x02, 0x01, 0x02}, wantErr: true},
+		{name: "no insurance number", pd: egkPD(t, noID), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cd, err := parseEGK(tt.pd, tt.vd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if err == nil && *cd != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, *cd)
+			}
+		})
+	}
+}
+
+// TestLatinCharsetReader tests the ISO-8859-15 characters that differ from ISO-8859-1
+func TestLatinCharsetReader(t *testing.T) {
+	doc := "<?xml version=\"1.0\" encoding=\"%s\"?><Name>\xA4 M\xFCller \xBD</Name>"
+	tests := []struct {
+		charset string
+		want    string
+		wantErr bool
+	}{
+		{"ISO-8859-15", "€ Müller œ", false},
+		{"ISO-8859-1", "¤ Müller ½", false},
+		{"UTF-16", "", true},
+	}
+
+	for _, tt := range tests {
+		var name string
+		err := unmarshalGzipXML(gzipXML(t, fmt.Sprintf(doc, tt.charset)), &name)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("Expected error %v for %s, got %v", tt.wantErr, tt.charset, err)
+		}
+		if name != tt.want {
+			t.Errorf("Expected '%s' for %s, got '%s'", tt.want, tt.charset, name)
+		}
+	}
+}
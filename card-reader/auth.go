@@ -0,0 +1,118 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// -----------------------------
+// Transport security (wss://, mTLS, device token)
+// -----------------------------
+
+// deviceHeaders identifies the reader to the API: tenant, device ID and, when
+// issued, the device token (POST /api/admin/card-readers/{id}/token).
+func deviceHeaders(cfg Config) http.Header {
+	h := http.Header{}
+	h.Set("X-Device-ID", cfg.DeviceID)
+	if id := tenantHeader(cfg); id != "" {
+		h.Set("X-Tenant-ID", id)
+	}
+	if cfg.DeviceToken != "" {
+		h.Set("Authorization", "Bearer "+cfg.DeviceToken)
+	}
+	return h
+}
+
+// tenantHeader is the X-Tenant-ID value the backend expects: "<tenant>:<section>",
+// or just the tenant when no section is configured.
+func tenantHeader(cfg Config) string {
+	if cfg.TenantID == "" || cfg.SectionID == "" {
+		return cfg.TenantID
+	}
+	return cfg.TenantID + ":" + cfg.SectionID
+}
+
+// clientTLSConfig returns nil when no custom CA or client certificate is configured.
+func clientTLSConfig(cfg Config) (*tls.Config, error) {
+	if cfg.TLSCAFile == "" && cfg.TLSClientCert == "" {
+		return nil, nil
+	}
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.TLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read TLS_CA_FILE: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", cfg.TLSCAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.TLSClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSClientCert, cfg.TLSClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	return tlsCfg, nil
+}
+
+var (
+	dialerOnce sync.Once
+	wsDialer   = websocket.DefaultDialer
+)
+
+// dialer builds the WebSocket dialer once; TLS files are read at first use.
+func dialer() *websocket.Dialer {
+	dialerOnce.Do(func() {
+		tlsCfg, err := clientTLSConfig(settings.get())
+		if err != nil {
+			slog.Warn("TLS config error, using system defaults", "err", err)
+			return
+		}
+		if tlsCfg != nil {
+			d := *websocket.DefaultDialer
+			d.TLSClientConfig = tlsCfg
+			wsDialer = &d
+		}
+	})
+	return wsDialer
+}
+
+// insecureDenied reports whether card data must not go out over plain ws:// or http://.
+func insecureDenied(target string, payload Payload) bool {
+	t := strings.ToLower(target)
+	return payload.CardData != nil && !settings.get().AllowInsecure && (strings.HasPrefix(t, "ws://") || strings.HasPrefix(t, "http://"))
+}
+
+// errInsecureTarget means card data was not sent because the target is unencrypted. The event
+// counts as not delivered: it stays in the spool until the target is fixed and is not deduped.
+var errInsecureTarget = errors.New("refusing to send card data over an unencrypted connection (ALLOW_INSECURE_WS=false)")
+
+// refuseInsecure returns errInsecureTarget, logged and shown on the status endpoint, when the card
+// data of payload must not go out over target.
+func refuseInsecure(target string, payload Payload) error {
+	if !insecureDenied(target, payload) {
+		return nil
+	}
+	err := fmt.Errorf("%w: %s", errInsecureTarget, target)
+	slog.Error("Card data not sent", "token", payload.Token, "err", err)
+	status.recordRefusedInsecure(err)
+	return err
+}
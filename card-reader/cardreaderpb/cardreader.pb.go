@@ -0,0 +1,690 @@
+// Card reader events for TRANSPORT=grpc. This file is the one definition of the
+// payload the card reader sends and the API ingests; the Go code of both modules
+// is generated from it (make proto). The JSON names match the CardReaderEvent
+// schema of the REST and WebSocket transports.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: cardreader/v1/cardreader.proto
+
+package cardreaderpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CardReaderEvent struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	RoomId   string                 `protobuf:"bytes,2,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	// Tenant queue the swipe belongs to: "<building>" or "<building>:<section>"
+	TenantId  string `protobuf:"bytes,3,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	SectionId string `protobuf:"bytes,4,opt,name=section_id,json=sectionId,proto3" json:"section_id,omitempty"`
+	// Random per insertion
+	Token  string `protobuf:"bytes,5,opt,name=token,proto3" json:"token,omitempty"`
+	Reader string `protobuf:"bytes,6,opt,name=reader,proto3" json:"reader,omitempty"`
+	// Hex
+	Atr string `protobuf:"bytes,7,opt,name=atr,proto3" json:"atr,omitempty"`
+	// T=0, T=1 or unknown
+	Protocol string `protobuf:"bytes,8,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	// RFC 3339
+	OccurredAt string `protobuf:"bytes,9,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	// waiting, reading, success, error, removed, pin_required, re_presented or duplicate_read
+	State    string    `protobuf:"bytes,10,opt,name=state,proto3" json:"state,omitempty"`
+	Message  string    `protobuf:"bytes,11,opt,name=message,proto3" json:"message,omitempty"`
+	CardData *CardData `protobuf:"bytes,12,opt,name=card_data,json=cardData,proto3" json:"card_data,omitempty"`
+	// Set when a PIN login was attempted
+	Pin *PinStatus `protobuf:"bytes,13,opt,name=pin,proto3" json:"pin,omitempty"`
+	// Replaces card_data when the reader has a card data key
+	EncryptedCardData *SealedCardData `protobuf:"bytes,14,opt,name=encrypted_card_data,json=encryptedCardData,proto3" json:"encrypted_card_data,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *CardReaderEvent) Reset() {
+	*x = CardReaderEvent{}
+	mi := &file_cardreader_v1_cardreader_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CardReaderEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CardReaderEvent) ProtoMessage() {}
+
+func (x *CardReaderEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_cardreader_v1_cardreader_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CardReaderEvent.ProtoReflect.Descriptor instead.
+func (*CardReaderEvent) Descriptor() ([]byte, []int) {
+	return file_cardreader_v1_cardreader_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CardReaderEvent) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *CardReaderEvent) GetRoomId() string {
+	if x != nil {
+		return x.RoomId
+	}
+	return ""
+}
+
+func (x *CardReaderEvent) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *CardReaderEvent) GetSectionId() string {
+	if x != nil {
+		return x.SectionId
+	}
+	return ""
+}
+
+func (x *CardReaderEvent) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *CardReaderEvent) GetReader() string {
+	if x != nil {
+		return x.Reader
+	}
+	return ""
+}
+
+func (x *CardReaderEvent) GetAtr() string {
+	if x != nil {
+		return x.Atr
+	}
+	return ""
+}
+
+func (x *CardReaderEvent) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *CardReaderEvent) GetOccurredAt() string {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return ""
+}
+
+func (x *CardReaderEvent) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *CardReaderEvent) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CardReaderEvent) GetCardData() *CardData {
+	if x != nil {
+		return x.CardData
+	}
+	return nil
+}
+
+func (x *CardReaderEvent) GetPin() *PinStatus {
+	if x != nil {
+		return x.Pin
+	}
+	return nil
+}
+
+func (x *CardReaderEvent) GetEncryptedCardData() *SealedCardData {
+	if x != nil {
+		return x.EncryptedCardData
+	}
+	return nil
+}
+
+type CardData struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Cert serial, IC serial or UID
+	IdNumber    string `protobuf:"bytes,1,opt,name=id_number,proto3" json:"id_number,omitempty"`
+	FirstName   string `protobuf:"bytes,2,opt,name=first_name,proto3" json:"first_name,omitempty"`
+	LastName    string `protobuf:"bytes,3,opt,name=last_name,proto3" json:"last_name,omitempty"`
+	DateOfBirth string `protobuf:"bytes,4,opt,name=date_of_birth,proto3" json:"date_of_birth,omitempty"`
+	Gender      string `protobuf:"bytes,5,opt,name=gender,proto3" json:"gender,omitempty"`
+	Nationality string `protobuf:"bytes,6,opt,name=nationality,proto3" json:"nationality,omitempty"`
+	Address     string `protobuf:"bytes,7,opt,name=address,proto3" json:"address,omitempty"`
+	IssuedDate  string `protobuf:"bytes,8,opt,name=issued_date,proto3" json:"issued_date,omitempty"`
+	ExpiryDate  string `protobuf:"bytes,9,opt,name=expiry_date,proto3" json:"expiry_date,omitempty"`
+	// Base64
+	Photo string `protobuf:"bytes,10,opt,name=photo,proto3" json:"photo,omitempty"`
+	// pkcs11-cert, insurance-egk, cplc or uid
+	Source             string `protobuf:"bytes,11,opt,name=source,proto3" json:"source,omitempty"`
+	InsuranceNumber    string `protobuf:"bytes,12,opt,name=insurance_number,proto3" json:"insurance_number,omitempty"`
+	InsurerCode        string `protobuf:"bytes,13,opt,name=insurer_code,proto3" json:"insurer_code,omitempty"`
+	InsurerName        string `protobuf:"bytes,14,opt,name=insurer_name,proto3" json:"insurer_name,omitempty"`
+	InsuranceValidFrom string `protobuf:"bytes,15,opt,name=insurance_valid_from,proto3" json:"insurance_valid_from,omitempty"`
+	InsuranceValidTo   string `protobuf:"bytes,16,opt,name=insurance_valid_to,proto3" json:"insurance_valid_to,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *CardData) Reset() {
+	*x = CardData{}
+	mi := &file_cardreader_v1_cardreader_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CardData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CardData) ProtoMessage() {}
+
+func (x *CardData) ProtoReflect() protoreflect.Message {
+	mi := &file_cardreader_v1_cardreader_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CardData.ProtoReflect.Descriptor instead.
+func (*CardData) Descriptor() ([]byte, []int) {
+	return file_cardreader_v1_cardreader_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CardData) GetIdNumber() string {
+	if x != nil {
+		return x.IdNumber
+	}
+	return ""
+}
+
+func (x *CardData) GetFirstName() string {
+	if x != nil {
+		return x.FirstName
+	}
+	return ""
+}
+
+func (x *CardData) GetLastName() string {
+	if x != nil {
+		return x.LastName
+	}
+	return ""
+}
+
+func (x *CardData) GetDateOfBirth() string {
+	if x != nil {
+		return x.DateOfBirth
+	}
+	return ""
+}
+
+func (x *CardData) GetGender() string {
+	if x != nil {
+		return x.Gender
+	}
+	return ""
+}
+
+func (x *CardData) GetNationality() string {
+	if x != nil {
+		return x.Nationality
+	}
+	return ""
+}
+
+func (x *CardData) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *CardData) GetIssuedDate() string {
+	if x != nil {
+		return x.IssuedDate
+	}
+	return ""
+}
+
+func (x *CardData) GetExpiryDate() string {
+	if x != nil {
+		return x.ExpiryDate
+	}
+	return ""
+}
+
+func (x *CardData) GetPhoto() string {
+	if x != nil {
+		return x.Photo
+	}
+	return ""
+}
+
+func (x *CardData) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *CardData) GetInsuranceNumber() string {
+	if x != nil {
+		return x.InsuranceNumber
+	}
+	return ""
+}
+
+func (x *CardData) GetInsurerCode() string {
+	if x != nil {
+		return x.InsurerCode
+	}
+	return ""
+}
+
+func (x *CardData) GetInsurerName() string {
+	if x != nil {
+		return x.InsurerName
+	}
+	return ""
+}
+
+func (x *CardData) GetInsuranceValidFrom() string {
+	if x != nil {
+		return x.InsuranceValidFrom
+	}
+	return ""
+}
+
+func (x *CardData) GetInsuranceValidTo() string {
+	if x != nil {
+		return x.InsuranceValidTo
+	}
+	return ""
+}
+
+type PinStatus struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ok, incorrect, locked, timeout, cancelled or error
+	Result string `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	// The token reports at least one failed attempt
+	CountLow bool `protobuf:"varint,2,opt,name=count_low,json=countLow,proto3" json:"count_low,omitempty"`
+	// The next wrong PIN locks the card
+	FinalTry      bool   `protobuf:"varint,3,opt,name=final_try,json=finalTry,proto3" json:"final_try,omitempty"`
+	Locked        bool   `protobuf:"varint,4,opt,name=locked,proto3" json:"locked,omitempty"`
+	Message       string `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PinStatus) Reset() {
+	*x = PinStatus{}
+	mi := &file_cardreader_v1_cardreader_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PinStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PinStatus) ProtoMessage() {}
+
+func (x *PinStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_cardreader_v1_cardreader_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PinStatus.ProtoReflect.Descriptor instead.
+func (*PinStatus) Descriptor() ([]byte, []int) {
+	return file_cardreader_v1_cardreader_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PinStatus) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *PinStatus) GetCountLow() bool {
+	if x != nil {
+		return x.CountLow
+	}
+	return false
+}
+
+func (x *PinStatus) GetFinalTry() bool {
+	if x != nil {
+		return x.FinalTry
+	}
+	return false
+}
+
+func (x *PinStatus) GetLocked() bool {
+	if x != nil {
+		return x.Locked
+	}
+	return false
+}
+
+func (x *PinStatus) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// SealedCardData is the card data sealed with the reader's card data key
+type SealedCardData struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// A256GCM
+	Alg string `protobuf:"bytes,1,opt,name=alg,proto3" json:"alg,omitempty"`
+	// First 8 bytes of SHA-256(key), hex
+	Kid           string `protobuf:"bytes,2,opt,name=kid,proto3" json:"kid,omitempty"`
+	Nonce         string `protobuf:"bytes,3,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	Ciphertext    string `protobuf:"bytes,4,opt,name=ciphertext,proto3" json:"ciphertext,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SealedCardData) Reset() {
+	*x = SealedCardData{}
+	mi := &file_cardreader_v1_cardreader_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SealedCardData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SealedCardData) ProtoMessage() {}
+
+func (x *SealedCardData) ProtoReflect() protoreflect.Message {
+	mi := &file_cardreader_v1_cardreader_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SealedCardData.ProtoReflect.Descriptor instead.
+func (*SealedCardData) Descriptor() ([]byte, []int) {
+	return file_cardreader_v1_cardreader_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SealedCardData) GetAlg() string {
+	if x != nil {
+		return x.Alg
+	}
+	return ""
+}
+
+func (x *SealedCardData) GetKid() string {
+	if x != nil {
+		return x.Kid
+	}
+	return ""
+}
+
+func (x *SealedCardData) GetNonce() string {
+	if x != nil {
+		return x.Nonce
+	}
+	return ""
+}
+
+func (x *SealedCardData) GetCiphertext() string {
+	if x != nil {
+		return x.Ciphertext
+	}
+	return ""
+}
+
+type CardReaderEventAck struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Token     string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Duplicate bool                   `protobuf:"varint,2,opt,name=duplicate,proto3" json:"duplicate,omitempty"`
+	// Set when the reader checked the patient in (auto check-in)
+	EntryId       string `protobuf:"bytes,3,opt,name=entry_id,json=entryId,proto3" json:"entry_id,omitempty"`
+	TicketNumber  string `protobuf:"bytes,4,opt,name=ticket_number,json=ticketNumber,proto3" json:"ticket_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CardReaderEventAck) Reset() {
+	*x = CardReaderEventAck{}
+	mi := &file_cardreader_v1_cardreader_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CardReaderEventAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CardReaderEventAck) ProtoMessage() {}
+
+func (x *CardReaderEventAck) ProtoReflect() protoreflect.Message {
+	mi := &file_cardreader_v1_cardreader_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CardReaderEventAck.ProtoReflect.Descriptor instead.
+func (*CardReaderEventAck) Descriptor() ([]byte, []int) {
+	return file_cardreader_v1_cardreader_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CardReaderEventAck) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *CardReaderEventAck) GetDuplicate() bool {
+	if x != nil {
+		return x.Duplicate
+	}
+	return false
+}
+
+func (x *CardReaderEventAck) GetEntryId() string {
+	if x != nil {
+		return x.EntryId
+	}
+	return ""
+}
+
+func (x *CardReaderEventAck) GetTicketNumber() string {
+	if x != nil {
+		return x.TicketNumber
+	}
+	return ""
+}
+
+var File_cardreader_v1_cardreader_proto protoreflect.FileDescriptor
+
+const file_cardreader_v1_cardreader_proto_rawDesc = "" +
+	"\n" +
+	"\x1ecardreader/v1/cardreader.proto\x12\x19waitingroom.cardreader.v1\"\x85\x04\n" +
+	"\x0fCardReaderEvent\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x17\n" +
+	"\aroom_id\x18\x02 \x01(\tR\x06roomId\x12\x1b\n" +
+	"\ttenant_id\x18\x03 \x01(\tR\btenantId\x12\x1d\n" +
+	"\n" +
+	"section_id\x18\x04 \x01(\tR\tsectionId\x12\x14\n" +
+	"\x05token\x18\x05 \x01(\tR\x05token\x12\x16\n" +
+	"\x06reader\x18\x06 \x01(\tR\x06reader\x12\x10\n" +
+	"\x03atr\x18\a \x01(\tR\x03atr\x12\x1a\n" +
+	"\bprotocol\x18\b \x01(\tR\bprotocol\x12\x1f\n" +
+	"\voccurred_at\x18\t \x01(\tR\n" +
+	"occurredAt\x12\x14\n" +
+	"\x05state\x18\n" +
+	" \x01(\tR\x05state\x12\x18\n" +
+	"\amessage\x18\v \x01(\tR\amessage\x12@\n" +
+	"\tcard_data\x18\f \x01(\v2#.waitingroom.cardreader.v1.CardDataR\bcardData\x126\n" +
+	"\x03pin\x18\r \x01(\v2$.waitingroom.cardreader.v1.PinStatusR\x03pin\x12Y\n" +
+	"\x13encrypted_card_data\x18\x0e \x01(\v2).waitingroom.cardreader.v1.SealedCardDataR\x11encryptedCardData\"\xaa\x04\n" +
+	"\bCardData\x12\x1c\n" +
+	"\tid_number\x18\x01 \x01(\tR\tid_number\x12\x1e\n" +
+	"\n" +
+	"first_name\x18\x02 \x01(\tR\n" +
+	"first_name\x12\x1c\n" +
+	"\tlast_name\x18\x03 \x01(\tR\tlast_name\x12$\n" +
+	"\rdate_of_birth\x18\x04 \x01(\tR\rdate_of_birth\x12\x16\n" +
+	"\x06gender\x18\x05 \x01(\tR\x06gender\x12 \n" +
+	"\vnationality\x18\x06 \x01(\tR\vnationality\x12\x18\n" +
+	"\aaddress\x18\a \x01(\tR\aaddress\x12 \n" +
+	"\vissued_date\x18\b \x01(\tR\vissued_date\x12 \n" +
+	"\vexpiry_date\x18\t \x01(\tR\vexpiry_date\x12\x14\n" +
+	"\x05photo\x18\n" +
+	" \x01(\tR\x05photo\x12\x16\n" +
+	"\x06source\x18\v \x01(\tR\x06source\x12*\n" +
+	"\x10insurance_number\x18\f \x01(\tR\x10insurance_number\x12\"\n" +
+	"\finsurer_code\x18\r \x01(\tR\finsurer_code\x12\"\n" +
+	"\finsurer_name\x18\x0e \x01(\tR\finsurer_name\x122\n" +
+	"\x14insurance_valid_from\x18\x0f \x01(\tR\x14insurance_valid_from\x12.\n" +
+	"\x12insurance_valid_to\x18\x10 \x01(\tR\x12insurance_valid_to\"\x8f\x01\n" +
+	"\tPinStatus\x12\x16\n" +
+	"\x06result\x18\x01 \x01(\tR\x06result\x12\x1b\n" +
+	"\tcount_low\x18\x02 \x01(\bR\bcountLow\x12\x1b\n" +
+	"\tfinal_try\x18\x03 \x01(\bR\bfinalTry\x12\x16\n" +
+	"\x06locked\x18\x04 \x01(\bR\x06locked\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\"j\n" +
+	"\x0eSealedCardData\x12\x10\n" +
+	"\x03alg\x18\x01 \x01(\tR\x03alg\x12\x10\n" +
+	"\x03kid\x18\x02 \x01(\tR\x03kid\x12\x14\n" +
+	"\x05nonce\x18\x03 \x01(\tR\x05nonce\x12\x1e\n" +
+	"\n" +
+	"ciphertext\x18\x04 \x01(\tR\n" +
+	"ciphertext\"\x88\x01\n" +
+	"\x12CardReaderEventAck\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1c\n" +
+	"\tduplicate\x18\x02 \x01(\bR\tduplicate\x12\x19\n" +
+	"\bentry_id\x18\x03 \x01(\tR\aentryId\x12#\n" +
+	"\rticket_number\x18\x04 \x01(\tR\fticketNumber2w\n" +
+	"\x10CardReaderIngest\x12c\n" +
+	"\x06Ingest\x12*.waitingroom.cardreader.v1.CardReaderEvent\x1a-.waitingroom.cardreader.v1.CardReaderEventAckb\x06proto3"
+
+var (
+	file_cardreader_v1_cardreader_proto_rawDescOnce sync.Once
+	file_cardreader_v1_cardreader_proto_rawDescData []byte
+)
+
+func file_cardreader_v1_cardreader_proto_rawDescGZIP() []byte {
+	file_cardreader_v1_cardreader_proto_rawDescOnce.Do(func() {
+		file_cardreader_v1_cardreader_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_cardreader_v1_cardreader_proto_rawDesc), len(file_cardreader_v1_cardreader_proto_rawDesc)))
+	})
+	return file_cardreader_v1_cardreader_proto_rawDescData
+}
+
+var file_cardreader_v1_cardreader_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_cardreader_v1_cardreader_proto_goTypes = []any{
+	(*CardReaderEvent)(nil),    // 0: waitingroom.cardreader.v1.CardReaderEvent
+	(*CardData)(nil),           // 1: waitingroom.cardreader.v1.CardData
+	(*PinStatus)(nil),          // 2: waitingroom.cardreader.v1.PinStatus
+	(*SealedCardData)(nil),     // 3: waitingroom.cardreader.v1.SealedCardData
+	(*CardReaderEventAck)(nil), // 4: waitingroom.cardreader.v1.CardReaderEventAck
+}
+var file_cardreader_v1_cardreader_proto_depIdxs = []int32{
+	1, // 0: waitingroom.cardreader.v1.CardReaderEvent.card_data:type_name -> waitingroom.cardreader.v1.CardData
+	2, // 1: waitingroom.cardreader.v1.CardReaderEvent.pin:type_name -> waitingroom.cardreader.v1.PinStatus
+	3, // 2: waitingroom.cardreader.v1.CardReaderEvent.encrypted_card_data:type_name -> waitingroom.cardreader.v1.SealedCardData
+	0, // 3: waitingroom.cardreader.v1.CardReaderIngest.Ingest:input_type -> waitingroom.cardreader.v1.CardReaderEvent
+	4, // 4: waitingroom.cardreader.v1.CardReaderIngest.Ingest:output_type -> waitingroom.cardreader.v1.CardReaderEventAck
+	4, // [4:5] is the sub-list for method output_type
+	3, // [3:4] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_cardreader_v1_cardreader_proto_init() }
+func file_cardreader_v1_cardreader_proto_init() {
+	if File_cardreader_v1_cardreader_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cardreader_v1_cardreader_proto_rawDesc), len(file_cardreader_v1_cardreader_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_cardreader_v1_cardreader_proto_goTypes,
+		DependencyIndexes: file_cardreader_v1_cardreader_proto_depIdxs,
+		MessageInfos:      file_cardreader_v1_cardreader_proto_msgTypes,
+	}.Build()
+	File_cardreader_v1_cardreader_proto = out.File
+	file_cardreader_v1_cardreader_proto_goTypes = nil
+	file_cardreader_v1_cardreader_proto_depIdxs = nil
+}
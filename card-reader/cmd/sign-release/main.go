@@ -0,0 +1,84 @@
+// Command sign-release signs a card-reader binary for self-update and prints the
+// release manifest to PUT to /api/admin/card-reader-releases.
+//
+//	sign-release -keygen                      # prints a new key pair
+//	sign-release -key release.key -version 1.4.0 -os linux -arch amd64 \
+//	    -url https://downloads.example.com/card-reader-1.4.0-linux-amd64 card-reader
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+)
+
+func main() {
+	keygen := flag.Bool("keygen", false, "generate a new Ed25519 key pair")
+	keyFile := flag.String("key", "", "file with the base64 Ed25519 private key")
+	version := flag.String("version", "", "release version, e.g. 1.4.0")
+	goos := flag.String("os", runtime.GOOS, "target GOOS of the binary")
+	goarch := flag.String("arch", runtime.GOARCH, "target GOARCH of the binary")
+	url := flag.String("url", "", "download URL of the binary")
+	flag.Parse()
+
+	if *keygen {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("private key (keep offline): %s\n", base64.StdEncoding.EncodeToString(priv))
+		fmt.Printf("UPDATE_PUBLIC_KEY=%s\n", base64.StdEncoding.EncodeToString(pub))
+		return
+	}
+
+	if *keyFile == "" || *version == "" || *url == "" || flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	raw, err := os.ReadFile(*keyFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		log.Fatal("key file must hold a base64 Ed25519 private key (use -keygen)")
+	}
+
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		log.Fatal(err)
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	v := strings.TrimPrefix(*version, "v")
+
+	// Must match releaseSigningMessage in the card-reader.
+	msg := fmt.Sprintf("waiting-room-card-reader|%s|%s/%s|%s", v, *goos, *goarch, sum)
+	sig := ed25519.Sign(ed25519.PrivateKey(key), []byte(msg))
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(map[string]string{
+		"version":   v,
+		"os":        *goos,
+		"arch":      *goarch,
+		"url":       *url,
+		"sha256":    sum,
+		"signature": base64.StdEncoding.EncodeToString(sig),
+	})
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// -----------------------------
+// Remote commands
+// -----------------------------
+
+// With the WebSocket transport the reader keeps one connection to WS_URL open with
+// ?commands=true. The API sends the commands an admin queued for the reader on it
+// (POST /api/admin/card-readers/{id}/commands); the reader acknowledges each one
+// when it arrives and reports its result once it ran:
+//
+//	API:    {"type":"command","id":"…","command":"identify"}
+//	reader: {"type":"command_ack","commandId":"…"}
+//	reader: {"type":"command_result","commandId":"…","success":true,"output":"…"}
+
+// errRestartRequested is the cancel cause used for a restart command.
+var errRestartRequested = errors.New("restart requested by the API")
+
+// commandPingInterval keeps the idle command connection open through proxies and NAT.
+const commandPingInterval = 30 * time.Second
+
+// identifyDuration is how long the identify pattern shows before the reader state is back.
+const identifyDuration = 3 * time.Second
+
+type remoteCommand struct {
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Command string `json:"command"`
+}
+
+type commandReply struct {
+	Type      string `json:"type"`
+	CommandID string `json:"commandId"`
+	Success   bool   `json:"success"`
+	Output    string `json:"output,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// watchCommands keeps the command connection open until ctx is cancelled, reconnecting
+// with backoff. A restart command cancels the reader context with errRestartRequested.
+func watchCommands(ctx context.Context, cancel context.CancelCauseFunc) {
+	if settings.get().Transport != "ws" {
+		slog.Info("Remote commands need the WebSocket transport; disabled")
+		return
+	}
+	// Commands the API sends again after a reconnect are answered without running twice.
+	results := map[string]commandReply{}
+	backoff := time.Second
+	for {
+		connected, err := runCommandChannel(ctx, cancel, results)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			backoff = time.Second
+		}
+		slog.Debug("Command connection closed", "err", err, "retryIn", backoff)
+		if err := sleepCtx(ctx, backoff); err != nil {
+			return
+		}
+		backoff = min(backoff*2, time.Minute)
+	}
+}
+
+// runCommandChannel handles the commands of one connection and reports whether it got connected.
+func runCommandChannel(ctx context.Context, cancel context.CancelCauseFunc, results map[string]commandReply) (bool, error) {
+	cfg := settings.get()
+	u, err := url.Parse(cfg.WSURL)
+	if err != nil {
+		return false, err
+	}
+	q := u.Query()
+	q.Set("commands", "true")
+	u.RawQuery = q.Encode()
+
+	conn, _, err := dialer().DialContext(ctx, u.String(), deviceHeaders(cfg))
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	slog.Info("Command connection open", "wsUrl", cfg.WSURL)
+
+	var writeMu sync.Mutex
+	write := func(data []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_ = conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		return conn.WriteMessage(websocket.TextMessage, data)
+	}
+	reply := func(r commandReply) error {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		return write(data)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(commandPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if write([]byte("ping")) != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return true, err
+		}
+		var cmd remoteCommand
+		if json.Unmarshal(data, &cmd) != nil || cmd.Type != "command" {
+			continue // pong, or an error about an earlier reply
+		}
+		if err := reply(commandReply{Type: "command_ack", CommandID: cmd.ID}); err != nil {
+			return true, err
+		}
+
+		result, ran := results[cmd.ID]
+		if !ran {
+			slog.Info("Remote command received", "command", cmd.Command, "commandId", cmd.ID)
+			result = runCommand(ctx, cmd)
+			results[cmd.ID] = result
+		}
+		if err := reply(result); err != nil {
+			return true, err
+		}
+		if cmd.Command == "restart" && result.Success && !ran {
+			slog.Info("Restarting on request of the API")
+			cancel(errRestartRequested)
+			return true, nil
+		}
+	}
+}
+
+// runCommand runs one command and returns the result to report.
+func runCommand(ctx context.Context, cmd remoteCommand) commandReply {
+	r := commandReply{Type: "command_result", CommandID: cmd.ID, Success: true}
+	fail := func(err error) commandReply {
+		slog.Warn("Remote command failed", "command", cmd.Command, "commandId", cmd.ID, "err", err)
+		r.Success, r.Error = false, err.Error()
+		return r
+	}
+
+	switch cmd.Command {
+	case "restart":
+		// Like an update, a restart waits for the card being handled.
+		for status.isCardPresent() {
+			if err := sleepCtx(ctx, time.Second); err != nil {
+				return fail(err)
+			}
+		}
+		r.Output = "restarting"
+	case "identify":
+		if !feedback.identify(identifyDuration) {
+			return fail(errors.New("the reader has no LED/buzzer feedback (FEEDBACK)"))
+		}
+		r.Output = "identify pattern shown"
+	case "reload_config":
+		if settings.get().ConfigURL == "" {
+			return fail(errors.New("CONFIG_URL is not set"))
+		}
+		if err := settings.refresh(ctx); err != nil {
+			return fail(err)
+		}
+		r.Output = "remote config reloaded"
+	case "pull_logs":
+		r.Output = recentLogs.String()
+	default:
+		return fail(errors.New("unknown command " + cmd.Command))
+	}
+	return r
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// -----------------------------
+// Configuration (env + remote pull)
+// -----------------------------
+
+// Config holds everything the reader needs at runtime. It starts from env vars and,
+// when CONFIG_URL is set, is overridden by whatever the API has stored for DEVICE_ID.
+type Config struct {
+	DeviceID        string
+	RoomID          string
+	TenantID        string
+	SectionID       string // with TenantID, sent as X-Tenant-ID "<tenant>:<section>"
+	ReaderName      string
+	PKCS11Module    string
+	WSURL           string
+	Transport       string        // "ws" (default), "http" or "grpc"
+	IngestURL       string        // event endpoint for TRANSPORT=http
+	GRPCURL         string        // API server for TRANSPORT=grpc, e.g. https://api.example.org
+	ConfigURL       string        // API base URL, e.g. http://localhost:8080/api
+	RefreshInterval time.Duration // how often to re-pull remote config
+	StatusAddr      string        // local status endpoint, "" disables it
+	SpoolDir        string        // offline spool directory, "" disables it
+	SpoolRetention  time.Duration // spooled events older than this are dropped
+	SpoolMaxBytes   int64         // oldest spooled events are dropped beyond this size
+
+	ContactlessMode     string        // "auto" (by ATR), "on" or "off"
+	ContactlessDebounce time.Duration // a contactless removal is only reported after this long
+	ContactlessReread   time.Duration // same UID within this interval -> "re_presented", no read
+	DedupWindow         time.Duration // same card read again within this window -> "duplicate_read", not sent
+
+	FeedbackMode     string                     // LED/buzzer: "auto" (ACS readers), "on" or "off"
+	FeedbackPatterns map[string]feedbackPattern // per state: waiting, reading, success, error, identify
+
+	ExtendedAPDU bool // send extended-length APDUs instead of chaining / short reads
+
+	InsuranceCards    bool // try reading German eGK health insurance data
+	CryptoAPIFallback bool // Windows: read the certificate via the card's minidriver when PKCS#11 fails
+
+	PINLogin         bool          // C_Login before reading data objects (needs STATUS_ADDR)
+	PINTimeout       time.Duration // how long to wait for the kiosk to submit a PIN
+	PINAllowedOrigin string        // CORS origin allowed to POST /pin, "" = same machine tools only
+
+	DeviceToken   string // bearer token issued by the API for this device
+	TLSCAFile     string // extra CA to trust for wss:// / https://
+	TLSClientCert string // client certificate for mutual TLS
+	TLSClientKey  string
+	AllowInsecure bool   // allow sending card data over plain ws://
+	CardDataKey   []byte // AES-256 key for CardData encryption, nil = send plaintext
+
+	UpdateInterval  time.Duration     // how often to check for a new release, 0 = never
+	UpdatePublicKey ed25519.PublicKey // Ed25519 key releases must be signed with, nil disables self-update
+
+	Simulate         bool   // generate card events from fixtures instead of PC/SC
+	SimulateFixtures string // fixtures file, "" or "-" = stdin
+
+	PhotoEnabled bool   // extract the holder photo into CardData.Photo
+	PhotoMaxSide int    // longer side of the emitted JPEG in pixels, 0 = original
+	JP2Decoder   string // OpenJPEG CLI used for JPEG2000 photos
+}
+
+// remoteConfig mirrors the API's CardReaderConfig DTO.
+type remoteConfig struct {
+	DeviceID               string `json:"deviceId"`
+	TenantID               string `json:"tenantId,omitempty"` // "buildingId:sectionId"
+	RoomID                 string `json:"roomId"`
+	ReaderName             string `json:"readerName,omitempty"`
+	PKCS11Module           string `json:"pkcs11Module,omitempty"`
+	WebSocketURL           string `json:"webSocketUrl,omitempty"`
+	RefreshIntervalSeconds int64  `json:"refreshIntervalSeconds,omitempty"`
+}
+
+// splitTenantID accepts the tenant either as "<tenant>:<section>" (the X-Tenant-ID
+// format) or as separate values; an explicit section wins.
+func splitTenantID(tenant, section string) (string, string) {
+	tenant, section = strings.TrimSpace(tenant), strings.TrimSpace(section)
+	if t, s, ok := strings.Cut(tenant, ":"); ok {
+		tenant = t
+		if section == "" {
+			section = s
+		}
+	}
+	return tenant, section
+}
+
+func loadEnvConfig() Config {
+	refresh := envDuration("CONFIG_REFRESH", 5*time.Minute)
+	spoolDir := envOr("SPOOL_DIR", defaultSpoolDir())
+	if strings.EqualFold(spoolDir, "off") {
+		spoolDir = ""
+	}
+	photoMax, err := strconv.Atoi(envOr("PHOTO_MAX_PX", "480"))
+	if err != nil || photoMax < 0 {
+		slog.Warn("Invalid PHOTO_MAX_PX, using 480", "value", os.Getenv("PHOTO_MAX_PX"))
+		photoMax = 480
+	}
+	cardDataKey, err := parseCardDataKey(os.Getenv("CARD_DATA_KEY"))
+	if err != nil {
+		fatal("Invalid CARD_DATA_KEY", "err", err)
+	}
+	updateKey, err := parseUpdatePublicKey(os.Getenv("UPDATE_PUBLIC_KEY"))
+	if err != nil {
+		fatal("Invalid UPDATE_PUBLIC_KEY", "err", err)
+	}
+	feedbackPatterns := map[string]feedbackPattern{}
+	for state, def := range map[string]string{"waiting": "off", "reading": "orange", "success": "green:beep", "error": "red:beep3", "identify": "orange:beep5"} {
+		key := "FEEDBACK_" + strings.ToUpper(state)
+		p, err := parseFeedbackPattern(envOr(key, def))
+		if err != nil {
+			slog.Warn("Invalid "+key+", using "+def, "err", err)
+			p, _ = parseFeedbackPattern(def)
+		}
+		feedbackPatterns[state] = p
+	}
+	configURL := strings.TrimRight(strings.TrimSpace(os.Getenv("CONFIG_URL")), "/")
+	transport := strings.ToLower(envOr("TRANSPORT", "ws"))
+	ingestURL := strings.TrimSpace(os.Getenv("INGEST_URL"))
+	if ingestURL == "" && configURL != "" {
+		ingestURL = configURL + "/card-readers/events"
+	}
+	grpcURL := strings.TrimRight(strings.TrimSpace(os.Getenv("GRPC_URL")), "/")
+	if u, err := url.Parse(configURL); grpcURL == "" && err == nil && u.Host != "" {
+		grpcURL = u.Scheme + "://" + u.Host
+	}
+	switch transport {
+	case "ws":
+	case "http":
+		if ingestURL == "" {
+			fatal("TRANSPORT=http needs INGEST_URL or CONFIG_URL")
+		}
+	case "grpc":
+		if grpcURL == "" {
+			fatal("TRANSPORT=grpc needs GRPC_URL or CONFIG_URL")
+		}
+		if _, _, err := grpcTarget(grpcURL); err != nil {
+			fatal("Invalid GRPC_URL", "err", err)
+		}
+	default:
+		fatal("Invalid TRANSPORT, use ws, http or grpc", "value", transport)
+	}
+	tenantID, sectionID := splitTenantID(os.Getenv("TENANT_ID"), os.Getenv("SECTION_ID"))
+	spoolMaxMB, err := strconv.ParseInt(envOr("SPOOL_MAX_MB", "50"), 10, 64)
+	if err != nil || spoolMaxMB < 0 {
+		slog.Warn("Invalid SPOOL_MAX_MB, using 50", "value", os.Getenv("SPOOL_MAX_MB"))
+		spoolMaxMB = 50
+	}
+	return Config{
+		DeviceID:        envOr("DEVICE_ID", "reader-01"),
+		RoomID:          envOr("ROOM_ID", "triage-1"),
+		TenantID:        tenantID,
+		SectionID:       sectionID,
+		ReaderName:      strings.TrimSpace(os.Getenv("READER_NAME")),
+		PKCS11Module:    strings.TrimSpace(os.Getenv("PKCS11_MODULE")),
+		WSURL:           envOr("WS_URL", "ws://localhost:4201/ws/card-reader"),
+		Transport:       transport,
+		IngestURL:       ingestURL,
+		GRPCURL:         grpcURL,
+		ConfigURL:       configURL,
+		RefreshInterval: refresh,
+		StatusAddr:      statusAddr(),
+		SpoolDir:        spoolDir,
+		SpoolRetention:  envDuration("SPOOL_RETENTION", 24*time.Hour),
+		SpoolMaxBytes:   spoolMaxMB << 20,
+
+		ContactlessMode:     envOr("CONTACTLESS_MODE", "auto"),
+		ContactlessDebounce: envDurationAllowZero("CONTACTLESS_DEBOUNCE", 1500*time.Millisecond),
+		ContactlessReread:   envDurationAllowZero("CONTACTLESS_REREAD_INTERVAL", 30*time.Second),
+		DedupWindow:         envDurationAllowZero("DEDUP_WINDOW", 10*time.Second),
+
+		FeedbackMode:     envOr("FEEDBACK", "auto"),
+		FeedbackPatterns: feedbackPatterns,
+
+		ExtendedAPDU: strings.EqualFold(os.Getenv("EXTENDED_APDU"), "true"),
+
+		InsuranceCards:    !strings.EqualFold(os.Getenv("INSURANCE_CARDS"), "false"),
+		CryptoAPIFallback: runtime.GOOS == "windows" && !strings.EqualFold(os.Getenv("CRYPTOAPI_FALLBACK"), "false"),
+
+		PINLogin:         strings.EqualFold(os.Getenv("PKCS11_PIN_LOGIN"), "true"),
+		PINTimeout:       envDuration("PIN_TIMEOUT", 60*time.Second),
+		PINAllowedOrigin: strings.TrimSpace(os.Getenv("PIN_ALLOWED_ORIGIN")),
+
+		DeviceToken:   strings.TrimSpace(os.Getenv("DEVICE_TOKEN")),
+		TLSCAFile:     strings.TrimSpace(os.Getenv("TLS_CA_FILE")),
+		TLSClientCert: strings.TrimSpace(os.Getenv("TLS_CLIENT_CERT")),
+		TLSClientKey:  strings.TrimSpace(os.Getenv("TLS_CLIENT_KEY")),
+		AllowInsecure: !strings.EqualFold(os.Getenv("ALLOW_INSECURE_WS"), "false"),
+		CardDataKey:   cardDataKey,
+
+		UpdateInterval:  envDurationAllowZero("UPDATE_CHECK_INTERVAL", 6*time.Hour),
+		UpdatePublicKey: updateKey,
+
+		Simulate:         strings.EqualFold(os.Getenv("SIMULATE"), "true"),
+		SimulateFixtures: strings.TrimSpace(os.Getenv("SIMULATE_FIXTURES")),
+
+		PhotoEnabled: !strings.EqualFold(os.Getenv("PHOTO_ENABLED"), "false"),
+		PhotoMaxSide: photoMax,
+		JP2Decoder:   envOr("JP2_DECODER", "opj_decompress"),
+	}
+}
+
+func envDuration(k string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(envOr(k, def.String()))
+	if err != nil || d <= 0 {
+		slog.Warn("Invalid duration, using default", "env", k, "default", def.String())
+		return def
+	}
+	return d
+}
+
+// envDurationAllowZero is envDuration for settings where "0" switches a feature off.
+func envDurationAllowZero(k string, def time.Duration) time.Duration {
+	if strings.TrimSpace(os.Getenv(k)) == "0" {
+		return 0
+	}
+	return envDuration(k, def)
+}
+
+func statusAddr() string {
+	addr := envOr("STATUS_ADDR", "127.0.0.1:8091")
+	if strings.EqualFold(addr, "off") {
+		return ""
+	}
+	return addr
+}
+
+// configStore guards the live config; readers of the config always go through get().
+type configStore struct {
+	mu     sync.RWMutex
+	cfg    Config
+	client *http.Client
+}
+
+var settings *configStore
+
+func newConfigStore(cfg Config) *configStore {
+	client := &http.Client{Timeout: 10 * time.Second}
+	if tlsCfg, err := clientTLSConfig(cfg); err != nil {
+		slog.Warn("TLS config error, using system defaults", "err", err)
+	} else if tlsCfg != nil {
+		client.Transport = &http.Transport{TLSClientConfig: tlsCfg}
+	}
+	return &configStore{
+		cfg:    cfg,
+		client: client,
+	}
+}
+
+func (s *configStore) get() Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg
+}
+
+// refresh pulls the device config from the API and merges non-empty fields into the
+// current config. Env values stay in place for anything the API leaves blank.
+func (s *configStore) refresh(ctx context.Context) error {
+	cur := s.get()
+	if cur.ConfigURL == "" {
+		return nil
+	}
+
+	endpoint := fmt.Sprintf("%s/admin/card-readers/%s/config", cur.ConfigURL, url.PathEscape(cur.DeviceID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range deviceHeaders(cur) {
+		req.Header[k] = v
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("no remote config registered for device %s", cur.DeviceID)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("config endpoint returned %s", resp.Status)
+	}
+
+	var rc remoteConfig
+	if err := json.NewDecoder(resp.Body).Decode(&rc); err != nil {
+		return fmt.Errorf("decode remote config: %w", err)
+	}
+
+	next := cur
+	if rc.RoomID != "" {
+		next.RoomID = rc.RoomID
+	}
+	if rc.TenantID != "" {
+		next.TenantID, next.SectionID = splitTenantID(rc.TenantID, "")
+		if next.SectionID == "" {
+			next.SectionID = cur.SectionID
+		}
+	}
+	if rc.ReaderName != "" {
+		next.ReaderName = rc.ReaderName
+	}
+	if rc.PKCS11Module != "" {
+		next.PKCS11Module = rc.PKCS11Module
+	}
+	if rc.WebSocketURL != "" {
+		next.WSURL = rc.WebSocketURL
+	}
+	if rc.RefreshIntervalSeconds > 0 {
+		next.RefreshInterval = time.Duration(rc.RefreshIntervalSeconds) * time.Second
+	}
+
+	if next.ReaderName != cur.ReaderName && cur.ReaderName != "" {
+		slog.Warn("Remote config changed reader; restart the card-reader to switch readers", "from", cur.ReaderName, "to", next.ReaderName)
+	}
+
+	s.mu.Lock()
+	s.cfg = next
+	s.mu.Unlock()
+
+	if next.RoomID != cur.RoomID || next.TenantID != cur.TenantID || next.SectionID != cur.SectionID || next.WSURL != cur.WSURL ||
+		next.PKCS11Module != cur.PKCS11Module || next.RefreshInterval != cur.RefreshInterval {
+		slog.Info("Remote config applied", "roomId", next.RoomID, "tenantId", next.TenantID, "sectionId", next.SectionID, "wsUrl", next.WSURL)
+	}
+	return nil
+}
+
+// watchConfig re-pulls the remote config on SIGHUP and every RefreshInterval.
+func watchConfig(ctx context.Context, s *configStore) {
+	if s.get().ConfigURL == "" {
+		return
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	interval := s.get().RefreshInterval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			slog.Info("SIGHUP received - reloading remote config")
+		case <-timer.C:
+		}
+
+		if err := s.refresh(ctx); err != nil {
+			slog.Warn("Remote config refresh failed", "err", err)
+		}
+
+		// interval may have been changed remotely
+		interval = s.get().RefreshInterval
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(interval)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestSplitTenantID tests the accepted forms of the tenant
+func TestSplitTenantID(t *testing.T) {
+	tests := []struct {
+		tenant, section         string
+		wantTenant, wantSection string
+	}{
+		{"hospital-a", "", "hospital-a", ""},
+		{"hospital-a:cardiology", "", "hospital-a", "cardiology"},
+		{" hospital-a:cardiology ", "radiology", "hospital-a", "radiology"},
+		{"hospital-a", " radiology ", "hospital-a", "radiology"},
+	}
+
+	for _, tt := range tests {
+		tenant, section := splitTenantID(tt.tenant, tt.section)
+		if tenant != tt.wantTenant || section != tt.wantSection {
+			t.Errorf("Expected %s / %s for '%s' / '%s', got %s / %s", tt.wantTenant, tt.wantSection, tt.tenant, tt.section, tenant, section)
+		}
+	}
+}
+
+// TestConfigRefresh tests that the remote config overrides the env config field by field
+func TestConfigRefresh(t *testing.T) {
+	env := Config{
+		DeviceID:        "reader-1",
+		RoomID:          "triage-1",
+		TenantID:        "hospital-a",
+		SectionID:       "cardiology",
+		ReaderName:      "ACS ACR39U",
+		WSURL:           "wss://env.example.org/ws/card-reader",
+		RefreshInterval: 5 * time.Minute,
+		DeviceToken:     "device-token",
+	}
+
+	tests := []struct {
+		name    string
+		status  int
+		remote  remoteConfig
+		want    Config
+		wantErr bool
+	}{
+		{
+			name:   "empty remote config keeps the env values",
+			status: http.StatusOK,
+			want:   env,
+		},
+		{
+			name:   "remote values win",
+			status: http.StatusOK,
+			remote: remoteConfig{RoomID: "triage-2", WebSocketURL: "wss://api.example.org/ws/card-reader", PKCS11Module: "/usr/lib/opensc-pkcs11.so", RefreshIntervalSeconds: 60},
+			want: func() Config {
+				c := env
+				c.RoomID, c.WSURL, c.PKCS11Module, c.RefreshInterval = "triage-2", "wss://api.example.org/ws/card-reader", "/usr/lib/opensc-pkcs11.so", time.Minute
+				return c
+			}(),
+		},
+		{
+			name:   "tenant with section",
+			status: http.StatusOK,
+			remote: remoteConfig{TenantID: "hospital-b:radiology"},
+			want: func() Config {
+				c := env
+				c.TenantID, c.SectionID = "hospital-b", "radiology"
+				return c
+			}(),
+		},
+		{
+			name:   "tenant without section keeps the env section",
+			status: http.StatusOK,
+			remote: remoteConfig{TenantID: "hospital-b"},
+			want: func() Config {
+				c := env
+				c.TenantID = "hospital-b"
+				return c
+			}(),
+		},
+		{name: "not registered", status: http.StatusNotFound, want: env, wantErr: true},
+		{name: "API error", status: http.StatusInternalServerError, want: env, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			var gotHeader http.Header
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath, gotHeader = r.URL.Path, r.Header
+				w.WriteHeader(tt.status)
+				_ = json.NewEncoder(w).Encode(tt.remote)
+			}))
+			defer srv.Close()
+
+			cfg := env
+			cfg.ConfigURL = srv.URL + "/api"
+			s := newConfigStore(cfg)
+			err := s.refresh(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+
+			got := s.get()
+			got.ConfigURL = ""
+			if got.DeviceID != tt.want.DeviceID || got.RoomID != tt.want.RoomID || got.TenantID != tt.want.TenantID ||
+				got.SectionID != tt.want.SectionID || got.ReaderName != tt.want.ReaderName || got.PKCS11Module != tt.want.PKCS11Module ||
+				got.WSURL != tt.want.WSURL || got.RefreshInterval != tt.want.RefreshInterval {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+			if gotPath != "/api/admin/card-readers/reader-1/config" {
+				t.Errorf("Expected the config of reader-1 to be fetched, got %s", gotPath)
+			}
+			if gotHeader.Get("Authorization") != "Bearer device-token" || gotHeader.Get("X-Tenant-ID") != "hospital-a:cardiology" {
+				t.Errorf("Expected the device headers, got %v", gotHeader)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// -----------------------------
+// CardData end-to-end encryption
+// -----------------------------
+
+// EncryptedCardData replaces Payload.CardData when CARD_DATA_KEY is set, so
+// relays and proxies between the reader and the API only see ciphertext.
+// AES-256-GCM; the associated data binds the block to deviceId and token so it
+// can't be replayed inside another payload.
+type EncryptedCardData struct {
+	Alg        string `json:"alg"` // "A256GCM"
+	KeyID      string `json:"kid"` // first 8 bytes of SHA-256(key), hex
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// parseCardDataKey accepts a base64 (std or URL) encoded 32-byte key.
+func parseCardDataKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if key, err := enc.DecodeString(s); err == nil {
+			if len(key) != 32 {
+				return nil, fmt.Errorf("CARD_DATA_KEY must be 32 bytes, got %d", len(key))
+			}
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("CARD_DATA_KEY is not valid base64")
+}
+
+func cardDataKeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+func cardDataAAD(deviceID, token string) []byte {
+	return []byte(deviceID + "|" + token)
+}
+
+func encryptCardData(key []byte, cd *CardData, deviceID, token string) (*EncryptedCardData, error) {
+	plain, err := json.Marshal(cd)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &EncryptedCardData{
+		Alg:        "A256GCM",
+		KeyID:      cardDataKeyID(key),
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plain, cardDataAAD(deviceID, token))),
+	}, nil
+}
+
+// sealPayload encrypts CardData in place when a key is configured.
+func sealPayload(pl *Payload) error {
+	key := settings.get().CardDataKey
+	if len(key) == 0 || pl.CardData == nil {
+		return nil
+	}
+	enc, err := encryptCardData(key, pl.CardData, pl.DeviceID, pl.Token)
+	if err != nil {
+		return fmt.Errorf("encrypt card data: %w", err)
+	}
+	pl.EncryptedCardData = enc
+	pl.CardData = nil
+	return nil
+}
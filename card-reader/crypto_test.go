@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// openCardData decrypts enc the way the API does
+func openCardData(t *testing.T, key []byte, enc *EncryptedCardData, deviceID, token string) (*CardData, error) {
+	t.Helper()
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("NewCipher failed: %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatalf("NewGCM failed: %v", err)
+	}
+	nonce, _ := base64.StdEncoding.DecodeString(enc.Nonce)
+	sealed, _ := base64.StdEncoding.DecodeString(enc.Ciphertext)
+	plain, err := gcm.Open(nil, nonce, sealed, cardDataAAD(deviceID, token))
+	if err != nil {
+		return nil, err
+	}
+	var cd CardData
+	if err := json.Unmarshal(plain, &cd); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	return &cd, nil
+}
+
+// TestParseCardDataKey tests the accepted encodings and lengths of CARD_DATA_KEY
+func TestParseCardDataKey(t *testing.T) {
+	key := bytes.Repeat([]byte{0xFB}, 32)
+	tests := []struct {
+		name    string
+		value   string
+		want    []byte
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"standard base64", base64.StdEncoding.EncodeToString(key), key, false},
+		{"URL base64", base64.URLEncoding.EncodeToString(key), key, false},
+		{"unpadded URL base64", " " + base64.RawURLEncoding.EncodeToString(key) + "\n", key, false},
+		{"too short", base64.StdEncoding.EncodeToString(key[:16]), nil, true},
+		{"not base64", "not a key!", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCardDataKey(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Expected key %x, got %x", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestSealPayload tests that card data is only readable with the key, inside the payload it
+// was sealed for
+func TestSealPayload(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	previous := settings
+	defer func() { settings = previous }()
+
+	tests := []struct {
+		name     string
+		key      []byte
+		openKey  []byte
+		deviceID string
+		token    string
+		wantOpen bool
+	}{
+		{"same payload", key, key, "reader-1", "t1", true},
+		{"other token", key, key, "reader-1", "t2", false},
+		{"other device", key, key, "reader-2", "t1", false},
+		{"other key", key, bytes.Repeat([]byte{0x43}, 32), "reader-1", "t1", false},
+		{"no key sends plaintext", nil, nil, "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings = newConfigStore(Config{CardDataKey: tt.key})
+			cd := &CardData{IDNumber: "123", FirstName: "Erika"}
+			pl := Payload{DeviceID: "reader-1", Token: "t1", CardData: cd}
+			if err := sealPayload(&pl); err != nil {
+				t.Fatalf("sealPayload failed: %v", err)
+			}
+
+			if tt.key == nil {
+				if pl.CardData != cd || pl.EncryptedCardData != nil {
+					t.Fatalf("Expected the card data to stay in plaintext without a key")
+				}
+				return
+			}
+			if pl.CardData != nil || pl.EncryptedCardData == nil {
+				t.Fatalf("Expected the card data to be replaced by its ciphertext")
+			}
+			if enc := pl.EncryptedCardData; enc.Alg != "A256GCM" || enc.KeyID != cardDataKeyID(key) || strings.Contains(enc.Ciphertext, "Erika") {
+				t.Errorf("Unexpected encrypted card data %+v", enc)
+			}
+
+			opened, err := openCardData(t, tt.openKey, pl.EncryptedCardData, tt.deviceID, tt.token)
+			if (err == nil) != tt.wantOpen {
+				t.Fatalf("Expected open %v, got error %v", tt.wantOpen, err)
+			}
+			if err == nil && *opened != *cd {
+				t.Errorf("Expected %+v, got %+v", *cd, *opened)
+			}
+		})
+	}
+}
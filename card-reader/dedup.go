@@ -0,0 +1,57 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// -----------------------------
+// Duplicate read suppression
+// -----------------------------
+
+// Patients fumbling a contact card often pull it and push it back in right away,
+// which would create two swipes. A successful read of the same card (same IDNumber,
+// or same ATR when the card yields no ID) within DEDUP_WINDOW is not sent as a card
+// event again; the reader sends a "duplicate_read" state update carrying the token
+// of the original read instead. Contactless cards are mostly caught earlier by the
+// UID check in nfc.go, before they are read at all.
+
+type readDeduper struct {
+	mu    sync.Mutex
+	key   string
+	token string
+	at    time.Time
+}
+
+var dedup = &readDeduper{}
+
+// dedupKey identifies the card behind a successful read.
+func dedupKey(pl Payload) string {
+	if pl.CardData != nil && pl.CardData.IDNumber != "" {
+		return "id:" + pl.CardData.IDNumber
+	}
+	return "atr:" + pl.ATR
+}
+
+// check returns the token of the original read if pl duplicates it, "" otherwise.
+// Only reads that were recorded after being sent count as originals.
+func (d *readDeduper) check(pl Payload, now time.Time, window time.Duration) string {
+	if window <= 0 || pl.State != "success" {
+		return ""
+	}
+	key := dedupKey(pl)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if key == d.key && now.Sub(d.at) < window {
+		return d.token
+	}
+	return ""
+}
+
+// record remembers a read that was sent (or spooled for sending) under the dedupKey taken
+// before its card data was sealed, so a read whose send failed is never suppressed.
+func (d *readDeduper) record(key, token string, at time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.key, d.token, d.at = key, token, at
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestReadDeduper tests which reads are suppressed as duplicates of a recorded read
+func TestReadDeduper(t *testing.T) {
+	start := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	window := 10 * time.Second
+	card := func(id, atr string) Payload {
+		pl := Payload{State: "success", ATR: atr, Token: "new"}
+		if id != "" {
+			pl.CardData = &CardData{IDNumber: id}
+		}
+		return pl
+	}
+
+	tests := []struct {
+		name     string
+		recorded bool
+		read     Payload
+		after    time.Duration
+		window   time.Duration
+		want     string
+	}{
+		{"same card within the window", true, card("123", "3B00"), 5 * time.Second, window, "original"},
+		{"same card after the window", true, card("123", "3B00"), 10 * time.Second, window, ""},
+		{"other card", true, card("456", "3B00"), time.Second, window, ""},
+		{"same ATR without ID", true, card("", "3B00"), time.Second, window, ""},
+		{"failed read", true, Payload{State: "error", CardData: &CardData{IDNumber: "123"}}, time.Second, window, ""},
+		{"disabled", true, card("123", "3B00"), time.Second, 0, ""},
+		{"original was never sent", false, card("123", "3B00"), time.Second, window, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &readDeduper{}
+			original := card("123", "3B00")
+			if d.check(original, start, tt.window) != "" {
+				t.Fatalf("Expected the first read not to be a duplicate")
+			}
+			if tt.recorded {
+				d.record(dedupKey(original), "original", start)
+			}
+			if got := d.check(tt.read, start.Add(tt.after), tt.window); got != tt.want {
+				t.Errorf("Expected original token '%s', got '%s'", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestDedupKey tests that reads are identified by ID number and by ATR without one
+func TestDedupKey(t *testing.T) {
+	tests := []struct {
+		pl   Payload
+		want string
+	}{
+		{Payload{ATR: "3B00", CardData: &CardData{IDNumber: "123"}}, "id:123"},
+		{Payload{ATR: "3B00", CardData: &CardData{}}, "atr:3B00"},
+		{Payload{ATR: "3B00"}, "atr:3B00"},
+	}
+
+	for _, tt := range tests {
+		if got := dedupKey(tt.pl); got != tt.want {
+			t.Errorf("Expected key %s, got %s", tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ebfe/scard"
+)
+
+// -----------------------------
+// Buzzer/LED feedback (ACR122U escape commands)
+// -----------------------------
+
+// ACR122U-style readers drive their bi-colour LED and buzzer through the pseudo-APDU
+// FF 00 40 <LED state> 04 <T1> <T2> <repetitions> <buzzer>, sent as a PC/SC escape
+// (IOCTL_CCID_ESCAPE) on a direct connection so it works without a card present.
+// Each reader state gets a pattern from FEEDBACK_<STATE>, e.g. "green:beep".
+
+const escapeControlCode = 3500 // IOCTL_CCID_ESCAPE = SCARD_CTL_CODE(3500)
+
+// feedbackPattern is the parsed form of "<led>[:<buzzer>]".
+type feedbackPattern struct {
+	led     byte // bit 0 red, bit 1 green
+	beeps   byte // repetitions, 0 = silent
+	beepLen byte // in units of 100 ms
+}
+
+const (
+	ledRed   byte = 0x01
+	ledGreen byte = 0x02
+)
+
+// parseFeedbackPattern accepts led off|red|green|orange and buzzer none|beep|beepN|long.
+func parseFeedbackPattern(s string) (feedbackPattern, error) {
+	var p feedbackPattern
+	led, buzzer, _ := strings.Cut(strings.ToLower(strings.TrimSpace(s)), ":")
+	switch led {
+	case "", "off":
+	case "red":
+		p.led = ledRed
+	case "green":
+		p.led = ledGreen
+	case "orange":
+		p.led = ledRed | ledGreen
+	default:
+		return p, fmt.Errorf("unknown LED colour %q", led)
+	}
+	switch {
+	case buzzer == "" || buzzer == "none":
+	case buzzer == "long":
+		p.beeps, p.beepLen = 1, 5
+	case strings.HasPrefix(buzzer, "beep"):
+		n := 1
+		if rest := strings.TrimPrefix(buzzer, "beep"); rest != "" {
+			var err error
+			if n, err = strconv.Atoi(rest); err != nil || n < 1 || n > 9 {
+				return p, fmt.Errorf("invalid beep count %q", rest)
+			}
+		}
+		p.beeps, p.beepLen = byte(n), 1
+	default:
+		return p, fmt.Errorf("unknown buzzer pattern %q", buzzer)
+	}
+	return p, nil
+}
+
+// apdu builds the ACR122U LED/buzzer command. While beeping the LED blinks in the
+// final colour; afterwards it stays in that colour.
+func (p feedbackPattern) apdu() apduCommand {
+	state := p.led | 0x0C // final red/green + update both
+	t1, t2, reps, buzzer := byte(1), byte(1), byte(1), byte(0)
+	if p.beeps > 0 {
+		state |= p.led<<4 | p.led<<6 // initial blink state + blink mask
+		t1, reps, buzzer = p.beepLen, p.beeps, 0x01
+	}
+	return apduCommand{CLA: 0xFF, INS: 0x00, P1: 0x40, P2: state, Data: []byte{t1, t2, reps, buzzer}}
+}
+
+// readerFeedback sends the pattern for a state to the reader. All methods are safe
+// on a nil receiver, which is what readers without LED/buzzer support get.
+type readerFeedback struct {
+	mu       sync.Mutex
+	sc       *scard.Context
+	reader   string
+	patterns map[string]feedbackPattern
+	state    string // last state shown, restored after identify
+	warned   bool
+}
+
+var feedback *readerFeedback
+
+// newFeedback returns nil unless FEEDBACK is "on", or "auto" and the reader name
+// looks like an ACS reader that understands the escape commands.
+func newFeedback(cfg Config, reader string) *readerFeedback {
+	switch strings.ToLower(cfg.FeedbackMode) {
+	case "off", "false":
+		return nil
+	case "on", "true":
+	default:
+		name := strings.ToUpper(reader)
+		if !strings.Contains(name, "ACR122") && !strings.Contains(name, "ACR1252") {
+			return nil
+		}
+	}
+	slog.Info("Reader LED/buzzer feedback enabled")
+	return &readerFeedback{reader: reader, patterns: cfg.FeedbackPatterns}
+}
+
+// attach sets the PC/SC context used for escape commands (again after a reconnect).
+func (f *readerFeedback) attach(sc *scard.Context) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sc = sc
+}
+
+// show signals state ("waiting", "reading", "success" or "error"). Failures never
+// affect the card flow; the first one is logged as a warning.
+func (f *readerFeedback) show(state string) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if state != "identify" {
+		f.state = state
+	}
+	p, ok := f.patterns[state]
+	if !ok || f.sc == nil {
+		return
+	}
+	if err := f.send(p.apdu()); err != nil {
+		if !f.warned {
+			f.warned = true
+			slog.Warn("Reader LED/buzzer command failed; on Linux the CCID driver needs ifdDriverOptions 0x0001 to allow escape commands", "err", err)
+		} else {
+			slog.Debug("Reader LED/buzzer command failed", "state", state, "err", err)
+		}
+	}
+}
+
+// identify shows the identify pattern for d, so staff can find the reader, and then the
+// last state again. It reports false for readers without LED/buzzer support.
+func (f *readerFeedback) identify(d time.Duration) bool {
+	if f == nil {
+		return false
+	}
+	f.show("identify")
+	time.Sleep(d)
+	f.mu.Lock()
+	state := f.state
+	f.mu.Unlock()
+	f.show(state)
+	return true
+}
+
+// send tries the escape IOCTL first; if the driver refuses it and a card is present,
+// the pseudo-APDU also works as a regular transmit.
+func (f *readerFeedback) send(cmd apduCommand) error {
+	card, err := f.sc.Connect(f.reader, scard.ShareDirect, scard.ProtocolUndefined)
+	if err == nil {
+		_, err = card.Control(scard.CtlCode(escapeControlCode), cmd.encode(false))
+		card.Disconnect(scard.LeaveCard)
+		if err == nil {
+			return nil
+		}
+	}
+	t, cerr := connectTransport(*f.sc, f.reader)
+	if cerr != nil {
+		return err
+	}
+	defer t.close()
+	if _, err := t.command(cmd); err != nil {
+		return fmt.Errorf("LED/buzzer command rejected: %w", err)
+	}
+	return nil
+}
@@ -6,6 +6,13 @@ require (
 	github.com/ebfe/scard v0.0.0-20241214075232-7af069cabc25
 	github.com/gorilla/websocket v1.5.1
 	github.com/miekg/pkcs11 v1.1.1
+	golang.org/x/sys v0.36.0
+	google.golang.org/grpc v1.64.1
+	google.golang.org/protobuf v1.36.12
 )
 
-require golang.org/x/net v0.25.0 // indirect
+require (
+	golang.org/x/net v0.26.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237 // indirect
+)
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	grpcstatus "google.golang.org/grpc/status"
+
+	"github.com/arfis/waiting-room/card-reader/cardreaderpb"
+)
+
+// -----------------------------
+// gRPC transport (TRANSPORT=grpc)
+// -----------------------------
+
+// With TRANSPORT=grpc every payload is a unary CardReaderIngest.Ingest call to the API
+// at GRPC_URL, on the API's own port. The messages are generated from
+// proto/cardreader/v1/cardreader.proto, which the API shares; the device headers go
+// out as metadata. Errors are retried and dropped like the HTTP transport does.
+
+var (
+	grpcMu    sync.Mutex
+	grpcConns = map[string]*grpc.ClientConn{}
+)
+
+// grpcTarget returns the host:port to dial for an http(s):// GRPC_URL and whether it uses TLS.
+func grpcTarget(rawURL string) (target string, useTLS bool, err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false, err
+	}
+	if u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", false, fmt.Errorf("%q is not an http:// or https:// URL", rawURL)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return u.Hostname() + ":" + port, u.Scheme == "https", nil
+}
+
+// grpcClient returns the client of the connection to grpcURL, connecting on first use.
+// https:// uses the TLS_CA_FILE and client certificate of the other transports.
+func grpcClient(grpcURL string) (cardreaderpb.CardReaderIngestClient, error) {
+	grpcMu.Lock()
+	defer grpcMu.Unlock()
+	if conn := grpcConns[grpcURL]; conn != nil {
+		return cardreaderpb.NewCardReaderIngestClient(conn), nil
+	}
+
+	target, useTLS, err := grpcTarget(grpcURL)
+	if err != nil {
+		return nil, err
+	}
+	creds := insecure.NewCredentials()
+	if useTLS {
+		tlsCfg, err := clientTLSConfig(settings.get())
+		if err != nil {
+			slog.Warn("TLS config error, using system defaults", "err", err)
+		}
+		if tlsCfg == nil {
+			tlsCfg = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		creds = credentials.NewTLS(tlsCfg)
+	}
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	grpcConns[grpcURL] = conn
+	return cardreaderpb.NewCardReaderIngestClient(conn), nil
+}
+
+// sendGRPC sends one event. Like a 4xx on the HTTP transport, an error status other
+// than an authentication or availability problem means the API will never accept this
+// event, so it is logged and dropped instead of spooled forever.
+func sendGRPC(grpcURL string, payload Payload) error {
+	if err := refuseInsecure(grpcURL, payload); err != nil {
+		return err
+	}
+	client, err := grpcClient(grpcURL)
+	if err != nil {
+		slog.Error("Invalid gRPC URL", "grpcUrl", grpcURL, "err", err)
+		return err
+	}
+
+	md := metadata.MD{}
+	for k, v := range deviceHeaders(settings.get()) {
+		md.Append(strings.ToLower(k), v...)
+	}
+	ctx, cancel := context.WithTimeout(metadata.NewOutgoingContext(context.Background(), md), 10*time.Second)
+	defer cancel()
+
+	ack, err := client.Ingest(ctx, payloadToProto(payload))
+	switch grpcstatus.Code(err) {
+	case codes.OK:
+	case codes.Unauthenticated, codes.PermissionDenied:
+		slog.Error("API rejected device credential; check DEVICE_TOKEN / client certificate", "err", err)
+		fallthrough
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Unknown, codes.Canceled, codes.Aborted:
+		slog.Warn("Failed to send event over gRPC", "grpcUrl", grpcURL, "err", err)
+		metrics.grpcSendFailures.inc()
+		status.recordError(err)
+		return err
+	default:
+		slog.Error("API rejected event, dropping it", "token", payload.Token, "err", err)
+		metrics.grpcSendFailures.inc()
+		return nil
+	}
+
+	if payload.State != "" && payload.CardData == nil && payload.EncryptedCardData == nil {
+		slog.Debug("State update sent", "token", payload.Token, "state", payload.State, "message", payload.Message)
+	} else {
+		slog.Info("Card data sent to API", "token", payload.Token)
+	}
+	// With auto check-in configured for this reader the API queued the patient right away.
+	if ack.GetTicketNumber() != "" {
+		slog.Info("Checked in", "token", payload.Token, "ticket", ack.GetTicketNumber())
+	}
+	return nil
+}
+
+// payloadToProto converts a payload to the message of the gRPC transport.
+func payloadToProto(p Payload) *cardreaderpb.CardReaderEvent {
+	event := &cardreaderpb.CardReaderEvent{
+		DeviceId:   p.DeviceID,
+		RoomId:     p.RoomID,
+		TenantId:   p.TenantID,
+		SectionId:  p.SectionID,
+		Token:      p.Token,
+		Reader:     p.Reader,
+		Atr:        p.ATR,
+		Protocol:   p.Protocol,
+		OccurredAt: p.OccurredAt,
+		State:      p.State,
+		Message:    p.Message,
+	}
+	if c := p.CardData; c != nil {
+		event.CardData = &cardreaderpb.CardData{
+			IdNumber:           c.IDNumber,
+			FirstName:          c.FirstName,
+			LastName:           c.LastName,
+			DateOfBirth:        c.DateOfBirth,
+			Gender:             c.Gender,
+			Nationality:        c.Nationality,
+			Address:            c.Address,
+			IssuedDate:         c.IssuedDate,
+			ExpiryDate:         c.ExpiryDate,
+			Photo:              c.Photo,
+			Source:             c.Source,
+			InsuranceNumber:    c.InsuranceNumber,
+			InsurerCode:        c.InsurerCode,
+			InsurerName:        c.InsurerName,
+			InsuranceValidFrom: c.InsuranceValidFrom,
+			InsuranceValidTo:   c.InsuranceValidTo,
+		}
+	}
+	if pin := p.PIN; pin != nil {
+		event.Pin = &cardreaderpb.PinStatus{
+			Result:   pin.Result,
+			CountLow: pin.CountLow,
+			FinalTry: pin.FinalTry,
+			Locked:   pin.Locked,
+			Message:  pin.Message,
+		}
+	}
+	if s := p.EncryptedCardData; s != nil {
+		event.EncryptedCardData = &cardreaderpb.SealedCardData{
+			Alg:        s.Alg,
+			Kid:        s.KeyID,
+			Nonce:      s.Nonce,
+			Ciphertext: s.Ciphertext,
+		}
+	}
+	return event
+}
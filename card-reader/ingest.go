@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// -----------------------------
+// HTTP transport (TRANSPORT=http)
+// -----------------------------
+
+// Some sites run proxies that break WebSocket upgrades. With TRANSPORT=http every
+// payload is POSTed to the API's ingestion endpoint (POST /card-readers/events),
+// with the same device headers and the same JSON as on the WebSocket. The OpenAPI
+// CardReaderEvent schema is the shared definition of that JSON; Payload mirrors it.
+
+// postPayload sends one event. A 4xx other than 401/403/408/429 means the API will
+// never accept this event, so it is logged and dropped instead of spooled forever.
+func postPayload(endpoint string, payload Payload) error {
+	if err := refuseInsecure(endpoint, payload); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("Failed to marshal payload", "err", err)
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		slog.Error("Invalid ingest URL", "ingestUrl", endpoint, "err", err)
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range deviceHeaders(settings.get()) {
+		req.Header[k] = v
+	}
+
+	resp, err := settings.client.Do(req)
+	if err != nil {
+		slog.Warn("Failed to post event", "ingestUrl", endpoint, "err", err)
+		metrics.httpSendFailures.inc()
+		status.recordError(err)
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	switch {
+	case resp.StatusCode/100 == 2:
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		slog.Error("API rejected device credential; check DEVICE_TOKEN / client certificate", "status", resp.Status)
+		fallthrough
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests:
+		err := fmt.Errorf("ingest endpoint returned %s", resp.Status)
+		metrics.httpSendFailures.inc()
+		status.recordError(err)
+		return err
+	default:
+		slog.Error("API rejected event, dropping it", "token", payload.Token, "status", resp.Status, "body", string(msg))
+		metrics.httpSendFailures.inc()
+		return nil
+	}
+
+	if payload.State != "" && payload.CardData == nil && payload.EncryptedCardData == nil {
+		slog.Debug("State update sent", "token", payload.Token, "state", payload.State, "message", payload.Message)
+	} else {
+		slog.Info("Card data sent to API", "token", payload.Token)
+	}
+	// With auto check-in configured for this reader the API queued the patient right away.
+	var ack struct {
+		TicketNumber string `json:"ticketNumber"`
+	}
+	if json.Unmarshal(msg, &ack) == nil && ack.TicketNumber != "" {
+		slog.Info("Checked in", "token", payload.Token, "ticket", ack.TicketNumber)
+	}
+	return nil
+}
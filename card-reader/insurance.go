@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ebfe/scard"
+)
+
+// -----------------------------
+// Health insurance cards
+// -----------------------------
+
+// Many waiting rooms identify patients by insurance number rather than national ID.
+// Two sources are supported:
+//   - German eGK (elektronische Gesundheitskarte): DF.HCA holds the personal data
+//     (EF.PD) and insurance data (EF.VD) as gzip-compressed XML. The EHIC data on
+//     the back of the card is the same insurance number / insurer / validity.
+//   - National insurer cards whose PKCS#11 middleware exposes the insurance data as
+//     data objects (labels handled in applyDataObject).
+
+var aidEGKHealthApp = []byte{0xD2, 0x76, 0x00, 0x00, 0x01, 0x02} // DF.HCA
+
+const (
+	sfiEGKPersonalData  = 0x01 // EF.PD
+	sfiEGKInsuranceData = 0x02 // EF.VD
+)
+
+// egkPersonalData is the part of UC_PersoenlicheVersichertendatenXML we use.
+type egkPersonalData struct {
+	Versicherter struct {
+		VersichertenID string `xml:"Versicherten_ID"`
+		Person         struct {
+			Geburtsdatum string `xml:"Geburtsdatum"`
+			Vorname      string `xml:"Vorname"`
+			Nachname     string `xml:"Nachname"`
+			Geschlecht   string `xml:"Geschlecht"`
+			Adresse      struct {
+				Postleitzahl string `xml:"Postleitzahl"`
+				Ort          string `xml:"Ort"`
+				Strasse      string `xml:"Strasse"`
+				Hausnummer   string `xml:"Hausnummer"`
+				Land         string `xml:"Land>Wohnsitzlaendercode"`
+			} `xml:"StrassenAdresse"`
+		} `xml:"Person"`
+	} `xml:"Versicherter"`
+}
+
+// egkInsuranceData is the part of UC_AllgemeineVersicherungsdatenXML we use.
+type egkInsuranceData struct {
+	Versicherter struct {
+		Versicherungsschutz struct {
+			Beginn        string `xml:"Beginn"`
+			Ende          string `xml:"Ende"`
+			Kostentraeger struct {
+				Kennung     string `xml:"Kostentraegerkennung"`
+				Laendercode string `xml:"Kostentraegerlaendercode"`
+				Name        string `xml:"Name"`
+			} `xml:"Kostentraeger"`
+		} `xml:"Versicherungsschutz"`
+	} `xml:"Versicherter"`
+}
+
+// readInsuranceCard reads a German eGK. It returns an error quickly on other cards
+// (the SELECT of DF.HCA fails).
+func readInsuranceCard(ctx scard.Context, reader string) (*CardData, error) {
+	t, err := connectTransport(ctx, reader)
+	if err != nil {
+		return nil, err
+	}
+	defer t.close()
+
+	// SELECT and both reads in one transaction: another application selecting a
+	// different DF in between would make the SFI reads hit the wrong files.
+	var pdRaw, vdRaw []byte
+	var vdErr error
+	err = t.transaction(func() error {
+		if err := t.selectAID(aidEGKHealthApp); err != nil {
+			return fmt.Errorf("SELECT DF.HCA: %w", err)
+		}
+		var err error
+		if pdRaw, err = t.readBinarySFI(sfiEGKPersonalData); err != nil {
+			return fmt.Errorf("read EF.PD: %w", err)
+		}
+		vdRaw, vdErr = t.readBinarySFI(sfiEGKInsuranceData)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if vdErr != nil {
+		vdRaw = nil
+	}
+	return parseEGK(pdRaw, vdRaw)
+}
+
+// parseEGK decodes the contents of EF.PD and, when it could be read, EF.VD.
+func parseEGK(pdRaw, vdRaw []byte) (*CardData, error) {
+	if len(pdRaw) < 2 {
+		return nil, errors.New("EF.PD too short")
+	}
+	pdLen := int(binary.BigEndian.Uint16(pdRaw[:2]))
+	if pdLen > len(pdRaw)-2 {
+		return nil, errors.New("EF.PD length out of range")
+	}
+	var pd egkPersonalData
+	if err := unmarshalGzipXML(pdRaw[2:2+pdLen], &pd); err != nil {
+		return nil, fmt.Errorf("EF.PD: %w", err)
+	}
+
+	cd := &CardData{Source: "insurance-egk"}
+	v := pd.Versicherter
+	cd.InsuranceNumber = strings.TrimSpace(v.VersichertenID)
+	cd.IDNumber = cd.InsuranceNumber
+	cd.FirstName = strings.TrimSpace(v.Person.Vorname)
+	cd.LastName = strings.TrimSpace(v.Person.Nachname)
+	cd.DateOfBirth = isoDate(v.Person.Geburtsdatum)
+	cd.Gender = strings.TrimSpace(v.Person.Geschlecht)
+	if a := v.Person.Adresse; a.Ort != "" {
+		cd.Address = strings.TrimSpace(fmt.Sprintf("%s %s, %s %s", a.Strasse, a.Hausnummer, a.Postleitzahl, a.Ort))
+		cd.Nationality = a.Land
+	}
+
+	// EF.VD: 4 big-endian offsets (start/end VD, start/end GVD), then the data
+	if len(vdRaw) >= 8 {
+		start, end := int(binary.BigEndian.Uint16(vdRaw[0:2])), int(binary.BigEndian.Uint16(vdRaw[2:4]))
+		if start < end && end < len(vdRaw) {
+			var vd egkInsuranceData
+			if err := unmarshalGzipXML(vdRaw[start:end+1], &vd); err == nil {
+				s := vd.Versicherter.Versicherungsschutz
+				cd.InsurerCode = strings.TrimSpace(s.Kostentraeger.Kennung)
+				cd.InsurerName = strings.TrimSpace(s.Kostentraeger.Name)
+				cd.InsuranceValidFrom = isoDate(s.Beginn)
+				cd.InsuranceValidTo = isoDate(s.Ende)
+				if cd.Nationality == "" {
+					cd.Nationality = s.Kostentraeger.Laendercode
+				}
+			}
+		}
+	}
+	if cd.InsuranceNumber == "" {
+		return nil, errors.New("no insurance number on card")
+	}
+	return cd, nil
+}
+
+func unmarshalGzipXML(b []byte, v any) error {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	dec := xml.NewDecoder(zr)
+	dec.CharsetReader = latinCharsetReader
+	return dec.Decode(v)
+}
+
+// latinCharsetReader handles the ISO-8859-15 declared by eGK XML.
+func latinCharsetReader(charset string, in io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-15", "iso-8859-1", "latin1", "latin-1":
+	default:
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+	raw, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	latin9 := map[byte]rune{0xA4: '€', 0xA6: 'Š', 0xA8: 'š', 0xB4: 'Ž', 0xB8: 'ž', 0xBC: 'Œ', 0xBD: 'œ', 0xBE: 'Ÿ'}
+	var sb strings.Builder
+	for _, c := range raw {
+		if r, ok := latin9[c]; ok && strings.EqualFold(charset, "iso-8859-15") {
+			sb.WriteRune(r)
+			continue
+		}
+		sb.WriteRune(rune(c))
+	}
+	return strings.NewReader(sb.String()), nil
+}
+
+// isoDate turns the cards' YYYYMMDD into YYYY-MM-DD; anything else is returned trimmed.
+func isoDate(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) == 8 && strings.Trim(s, "0123456789") == "" {
+		return s[:4] + "-" + s[4:6] + "-" + s[6:]
+	}
+	return s
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// -----------------------------
+// Structured logging
+// -----------------------------
+
+// Every record carries the device ID and, while a card is being handled, the reader,
+// ATR and correlation token of that card, so fleet logs can be filtered centrally.
+// Only one card is handled at a time, which keeps the card fields a simple global.
+
+type logFields struct {
+	mu     sync.RWMutex
+	reader string
+	atr    string
+	token  string
+}
+
+var logCtx = &logFields{}
+
+func (f *logFields) setReader(reader string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reader = reader
+}
+
+func (f *logFields) setCard(atr, token string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.atr, f.token = atr, token
+}
+
+func (f *logFields) clearCard() {
+	f.setCard("", "")
+}
+
+func (f *logFields) attrs() []slog.Attr {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	var out []slog.Attr
+	if settings != nil {
+		out = append(out, slog.String("deviceId", settings.get().DeviceID))
+	} else {
+		out = append(out, slog.String("deviceId", envOr("DEVICE_ID", "reader-01")))
+	}
+	if f.reader != "" {
+		out = append(out, slog.String("reader", f.reader))
+	}
+	if f.atr != "" {
+		out = append(out, slog.String("atr", f.atr))
+	}
+	if f.token != "" {
+		out = append(out, slog.String("token", f.token))
+	}
+	return out
+}
+
+// fieldsHandler adds logCtx fields to each record unless the call already set them.
+type fieldsHandler struct {
+	slog.Handler
+}
+
+func (h fieldsHandler) Handle(ctx context.Context, r slog.Record) error {
+	set := make(map[string]bool, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		set[a.Key] = true
+		return true
+	})
+	for _, a := range logCtx.attrs() {
+		if !set[a.Key] {
+			r.AddAttrs(a)
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h fieldsHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return fieldsHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h fieldsHandler) WithGroup(name string) slog.Handler {
+	return fieldsHandler{h.Handler.WithGroup(name)}
+}
+
+// setupLogging installs the default slog logger from LOG_LEVEL (debug|info|warn|error)
+// and LOG_FORMAT (text|json). It runs before the config is loaded so config
+// warnings already go through it.
+func setupLogging() {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(envOr("LOG_LEVEL", "info"))); err != nil {
+		level = slog.LevelInfo
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	// A Windows service has no stderr, so LOG_FILE is the way to keep its logs.
+	var out io.Writer = os.Stderr
+	var fileErr error
+	if path := os.Getenv("LOG_FILE"); path != "" {
+		if f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640); err == nil {
+			out = f
+		} else {
+			fileErr = err
+		}
+	}
+
+	out = io.MultiWriter(out, recentLogs)
+
+	var h slog.Handler
+	if strings.EqualFold(envOr("LOG_FORMAT", "text"), "json") {
+		h = slog.NewJSONHandler(out, opts)
+	} else {
+		h = slog.NewTextHandler(out, opts)
+	}
+	slog.SetDefault(slog.New(fieldsHandler{h}))
+	if fileErr != nil {
+		slog.Warn("LOG_FILE unusable, logging to stderr", "err", fileErr)
+	}
+}
+
+// recentLogs keeps the last log lines for the pull_logs command.
+var recentLogs = &logRing{max: 200}
+
+// logRing is an io.Writer keeping the last max lines written to it.
+type logRing struct {
+	mu    sync.Mutex
+	lines []string
+	max   int
+}
+
+func (r *logRing) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		r.lines = append(r.lines, line)
+	}
+	if over := len(r.lines) - r.max; over > 0 {
+		r.lines = append(r.lines[:0:0], r.lines[over:]...)
+	}
+	return len(p), nil
+}
+
+func (r *logRing) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.lines, "\n")
+}
+
+// fatal logs at error level and exits, replacing log.Fatalf.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -10,12 +10,18 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
 	"runtime"
+	"sort"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/ebfe/scard"
@@ -24,16 +30,21 @@ import (
 )
 
 type Payload struct {
-	DeviceID   string    `json:"deviceId"`
-	RoomID     string    `json:"roomId"`
-	Token      string    `json:"token"`      // random per insertion
-	Reader     string    `json:"reader"`     // reader name
-	ATR        string    `json:"atr"`        // hex
-	Protocol   string    `json:"protocol"`   // T=0/T=1/unknown
-	OccurredAt string    `json:"occurredAt"` // RFC3339
-	CardData   *CardData `json:"cardData,omitempty"`
-	State      string    `json:"state"`   // "waiting", "reading", "success", "error"
-	Message    string    `json:"message"` // Human readable message
+	DeviceID   string     `json:"deviceId"`
+	RoomID     string     `json:"roomId"`
+	TenantID   string     `json:"tenantId,omitempty"`  // tenant queue the swipe belongs to (X-Tenant-ID)
+	SectionID  string     `json:"sectionId,omitempty"` // section/department within the tenant
+	Token      string     `json:"token"`               // random per insertion
+	Reader     string     `json:"reader"`              // reader name
+	ATR        string     `json:"atr"`                 // hex
+	Protocol   string     `json:"protocol"`            // T=0/T=1/unknown
+	OccurredAt string     `json:"occurredAt"`          // RFC3339
+	CardData   *CardData  `json:"cardData,omitempty"`
+	State      string     `json:"state"`         // "waiting", "reading", "success", "error"
+	Message    string     `json:"message"`       // Human readable message
+	PIN        *PINStatus `json:"pin,omitempty"` // set when a PIN login was attempted
+
+	EncryptedCardData *EncryptedCardData `json:"encryptedCardData,omitempty"` // replaces CardData when CARD_DATA_KEY is set
 }
 
 type CardData struct {
@@ -47,24 +58,130 @@ type CardData struct {
 	IssuedDate  string `json:"issued_date,omitempty"`
 	ExpiryDate  string `json:"expiry_date,omitempty"`
 	Photo       string `json:"photo,omitempty"`
-	Source      string `json:"source,omitempty"` // "pkcs11-cert" | "cplc" | "uid"
+	Source      string `json:"source,omitempty"` // "pkcs11-cert" | "insurance-egk" | "cplc" | "uid"
+
+	// Health insurance cards (eGK / EHIC data, insurer middleware data objects)
+	InsuranceNumber    string `json:"insurance_number,omitempty"`
+	InsurerCode        string `json:"insurer_code,omitempty"`
+	InsurerName        string `json:"insurer_name,omitempty"`
+	InsuranceValidFrom string `json:"insurance_valid_from,omitempty"`
+	InsuranceValidTo   string `json:"insurance_valid_to,omitempty"`
 }
 
+// eventSpool is nil when SPOOL_DIR=off or the directory couldn't be created.
+var eventSpool *spool
+
 func main() {
-	roomID := envOr("ROOM_ID", "triage-1")
-	deviceID := envOr("DEVICE_ID", "reader-01")
-	wantReader := strings.TrimSpace(os.Getenv("READER_NAME"))
-	wsURL := envOr("WS_URL", "ws://localhost:4201/ws/card-reader")
+	simulate := flag.Bool("simulate", false, "generate card events from fixtures instead of a PC/SC reader (SIMULATE)")
+	fixtures := flag.String("fixtures", "", "simulation fixtures file, \"-\" or empty for stdin (SIMULATE_FIXTURES)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [run|install|uninstall]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	setupLogging()
+	switch cmd := flag.Arg(0); cmd {
+	case "install":
+		if err := installService(); err != nil {
+			fatal("Service install failed", "err", err)
+		}
+		return
+	case "uninstall":
+		if err := uninstallService(); err != nil {
+			fatal("Service uninstall failed", "err", err)
+		}
+		return
+	case "", "run":
+	default:
+		fatal("Unknown command", "command", cmd)
+	}
+
+	run := func(ctx context.Context) bool { return runReader(ctx, *simulate, *fixtures) }
+	if runningAsService() {
+		runService(run)
+		return
+	}
+
+	// SIGINT/SIGTERM cancel ctx; everything below winds down from there. A second
+	// signal kills the process the default way.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	context.AfterFunc(ctx, stop)
+	if run(ctx) {
+		restartAfterUpdate()
+	}
+}
+
+// runReader runs the card loop until ctx is cancelled. It reports whether it stopped
+// because an update was installed or the API asked for a restart, in which case the
+// caller restarts the process.
+func runReader(parent context.Context, simulate bool, fixtures string) (updated bool) {
+	slog.Info("Card reader starting", "version", version)
+
+	ctx, cancelForUpdate := context.WithCancelCause(parent)
+	defer cancelForUpdate(nil)
+	defer func() {
+		cause := context.Cause(ctx)
+		updated = errors.Is(cause, errRestartForUpdate) || errors.Is(cause, errRestartRequested)
+	}()
+	context.AfterFunc(ctx, func() {
+		slog.Info("Shutdown requested")
+		pins.cancelPending()
+	})
+
+	settings = newConfigStore(loadEnvConfig())
+	if err := settings.refresh(ctx); err != nil {
+		slog.Warn("Remote config unavailable, using env config", "err", err)
+	}
+	go watchConfig(ctx, settings)
+	go watchUpdates(ctx, cancelForUpdate)
+	go watchCommands(ctx, cancelForUpdate)
+
+	cfg := settings.get()
+	deviceID := cfg.DeviceID
+	wantReader := cfg.ReaderName
+	statusSrv := startStatusServer(cfg.StatusAddr)
+	defer shutdown(statusSrv)
+	if cfg.PINLogin && cfg.StatusAddr == "" {
+		slog.Warn("PKCS11_PIN_LOGIN needs STATUS_ADDR for the kiosk to submit PINs; PIN login disabled")
+	}
+
+	if cfg.SpoolDir != "" {
+		sp, err := newSpool(cfg.SpoolDir, cfg.SpoolRetention, cfg.SpoolMaxBytes)
+		if err != nil {
+			slog.Warn("Offline spool disabled", "err", err)
+		} else {
+			eventSpool = sp
+			slog.Info("Offline spool enabled", "dir", sp.dir, "pending", sp.pending())
+			go sp.replayLoop(ctx, 15*time.Second)
+		}
+	}
 
-	// PC/SC context
-	ctx, err := scard.EstablishContext()
+	if simulate || cfg.Simulate {
+		path := fixtures
+		if path == "" {
+			path = cfg.SimulateFixtures
+		}
+		if err := runSimulation(ctx, path); err != nil && !errors.Is(err, context.Canceled) {
+			fatal("Simulation failed", "err", err)
+		} else if err == nil {
+			slog.Info("Simulation: fixtures exhausted")
+		}
+		return
+	}
+
+	// PC/SC context (released by monitor)
+	sc, err := scard.EstablishContext()
 	must(err, "establish PC/SC context")
-	defer ctx.Release()
 
-	readers, err := ctx.ListReaders()
-	must(err, "list readers")
-	if len(readers) == 0 {
-		log.Fatal("no smart card readers found")
+	readers, err := sc.ListReaders()
+	if err == nil && len(readers) == 0 {
+		err = errors.New("no smart card readers found")
+	}
+	if err != nil {
+		_ = sc.Release()
+		fatal("Failed to list readers", "err", err)
 	}
 
 	var reader string
@@ -78,101 +195,260 @@ func main() {
 			}
 		}
 		if !found {
-			log.Fatalf("reader %q not found. available: %v", wantReader, readers)
+			_ = sc.Release()
+			fatal("reader not found", "reader", wantReader, "available", readers)
 		}
 	} else {
 		reader = readers[0]
 	}
-	log.Printf("Using reader: %s", reader)
-	log.Printf("WebSocket URL: %s", wsURL)
-	log.Println("Waiting for card...")
+	logCtx.setReader(reader)
+	slog.Info("Using reader")
+	status.setReader(reader)
+	switch cfg.Transport {
+	case "http":
+		slog.Info("HTTP transport configured", "ingestUrl", cfg.IngestURL)
+	case "grpc":
+		slog.Info("gRPC transport configured", "grpcUrl", cfg.GRPCURL)
+	default:
+		slog.Info("WebSocket configured", "wsUrl", cfg.WSURL)
+	}
+	slog.Info("Waiting for card")
+
+	feedback = newFeedback(cfg, reader)
+	feedback.attach(sc)
 
 	// Send initial waiting state
-	sendStateUpdate(wsURL, deviceID, roomID, reader, "waiting", "Please insert your ID card")
+	sendStateUpdate(deviceID, cfg.RoomID, reader, "waiting", "Please insert your ID card")
+	feedback.show("waiting")
 
 	// Event-driven monitor (no polling races)
-	monitor(context.Background(), *ctx, reader, func(atr []byte, proto string) {
-		token := randToken(16)
-
-		// Send reading state
-		sendStateUpdate(wsURL, deviceID, roomID, reader, "reading", "Reading card data...")
-
-		pl := Payload{
-			DeviceID:   deviceID,
-			RoomID:     roomID,
-			Token:      token,
-			Reader:     reader,
-			ATR:        strings.ToUpper(hex.EncodeToString(atr)),
-			Protocol:   proto,
-			OccurredAt: time.Now().Format(time.RFC3339),
-		}
-
-		// Read while the card is present
-		cardData := readCardData(*ctx, reader, proto, atr)
-		if cardData != nil {
-			pl.CardData = cardData
-			pl.State = "success"
-			pl.Message = "Card read successfully"
-		} else {
-			pl.State = "error"
-			pl.Message = "Failed to read card data"
+	monitor(ctx, sc, reader, cardHandlers{
+		inserted: func(c *scard.Context, atr []byte, proto string) {
+			handleCardInserted(reader, atr, proto, func(token string) (*CardData, *PINStatus) {
+				// Read while the card is present
+				return readCardData(*c, reader, proto, atr, token)
+			})
+		},
+		removed: func() {
+			handleCardRemoved(reader)
+		},
+		represented: func(atr []byte, proto, uid string) {
+			handleCardRepresented(reader, atr, proto, uid)
+		},
+	})
+	return
+}
+
+// shutdown runs once the card loop has stopped: one last attempt to deliver spooled
+// events, then the status endpoint goes down.
+func shutdown(statusSrv *http.Server) {
+	if eventSpool != nil && eventSpool.pending() > 0 {
+		if left := eventSpool.flush(deliverPayload); left > 0 {
+			slog.Warn("Spooled events kept on disk for the next start", "pending", left)
 		}
+	}
+	if statusSrv != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = statusSrv.Shutdown(ctx)
+	}
+	slog.Info("Card reader stopped")
+}
 
-		// Send final result to WebSocket
-		sendToWebSocket(wsURL, pl)
+// handleCardInserted reports one card: "reading" state, then the read result as a
+// card event. read does the actual card I/O (or returns fixture data when simulating).
+func handleCardInserted(reader string, atr []byte, proto string, read func(token string) (*CardData, *PINStatus)) {
+	token := randToken(16)
+	logCtx.setCard(strings.ToUpper(hex.EncodeToString(atr)), token)
+	// pick up remote config changes between cards
+	cfg := settings.get()
+
+	// Send reading state
+	sendStateUpdate(cfg.DeviceID, cfg.RoomID, reader, "reading", "Reading card data...")
+	feedback.show("reading")
+
+	pl := Payload{
+		DeviceID:   cfg.DeviceID,
+		RoomID:     cfg.RoomID,
+		TenantID:   cfg.TenantID,
+		SectionID:  cfg.SectionID,
+		Token:      token,
+		Reader:     reader,
+		ATR:        strings.ToUpper(hex.EncodeToString(atr)),
+		Protocol:   proto,
+		OccurredAt: time.Now().Format(time.RFC3339),
+	}
 
-		// Also print to console for debugging
-		b, _ := json.MarshalIndent(pl, "", "  ")
-		fmt.Println(string(b))
-	}, func() {
-		// Card removed callback
-		sendStateUpdate(wsURL, deviceID, roomID, reader, "removed", "Card removed - ready for next card")
-	})
+	started := time.Now()
+	cardData, pinStatus := read(token)
+	metrics.readDuration.observe(time.Since(started).Seconds())
+	pl.PIN = pinStatus
+	if pinStatus != nil && pinStatus.Result != "ok" {
+		pl.CardData = cardData
+		pl.State = "error"
+		pl.Message = "PIN login failed: " + pinStatus.Result
+		metrics.readFailures.inc("pin-"+pinStatus.Result, "none")
+	} else if cardData != nil {
+		pl.CardData = cardData
+		pl.State = "success"
+		pl.Message = "Card read successfully"
+		metrics.cardsRead.inc(cardData.Source)
+	} else {
+		pl.State = "error"
+		pl.Message = "Failed to read card data"
+		metrics.readFailures.inc("read", "none")
+	}
+
+	readAt := time.Now()
+	if original := dedup.check(pl, readAt, cfg.DedupWindow); original != "" {
+		slog.Info("Same card read again within dedup window, not sent", "originalToken", original, "source", pl.CardData.Source)
+		metrics.duplicateReads.inc()
+		feedback.show("success")
+		sendStateUpdateWithToken(cfg.DeviceID, cfg.RoomID, reader, original, "duplicate_read",
+			"Same card read again within "+cfg.DedupWindow.String()+"; not sent again")
+		return
+	}
+
+	status.recordRead(pl)
+	feedback.show(pl.State)
+
+	// Field names only: the values are personal data and are not yet encrypted here
+	if pl.CardData != nil && slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		slog.Debug("Card payload", "state", pl.State, "source", pl.CardData.Source, "fields", cardDataFields(pl.CardData))
+	}
+
+	readKey := dedupKey(pl)
+	if err := sealPayload(&pl); err != nil {
+		slog.Error("Card data not sent", "err", err)
+		pl.CardData = nil
+		pl.State = "error"
+		pl.Message = "Failed to encrypt card data"
+	}
+
+	// Send final result to WebSocket (spooled if it's unreachable)
+	if sendCardEvent(pl) && pl.State == "success" {
+		dedup.record(readKey, pl.Token, readAt)
+	}
+}
+
+// cardDataFields lists the JSON names of the fields that were read, without their values.
+func cardDataFields(cd *CardData) []string {
+	b, _ := json.Marshal(cd)
+	var m map[string]json.RawMessage
+	_ = json.Unmarshal(b, &m)
+	fields := make([]string, 0, len(m))
+	for k := range m {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func handleCardRemoved(reader string) {
+	status.cardRemoved()
+	logCtx.clearCard()
+	cfg := settings.get()
+	sendStateUpdate(cfg.DeviceID, cfg.RoomID, reader, "removed", "Card removed - ready for next card")
+	feedback.show("waiting")
 }
 
 // -----------------------------
 // Event-driven monitor
 // -----------------------------
 
+// cardHandlers are the monitor callbacks. inserted gets the PC/SC context that is
+// current at that moment; represented is only used for debounced contactless cards.
+type cardHandlers struct {
+	inserted    func(c *scard.Context, atr []byte, proto string)
+	removed     func()
+	represented func(atr []byte, proto, uid string)
+}
+
 // monitor waits for insert/remove using PC/SC GetStatusChange.
-// On insert -> onInsert(); then blocks until removal; then goes back to waiting.
-// REPLACE your monitor(...) with this version.
-// (Fix: copy updated ReaderState back from the slice after GetStatusChange.)
-func monitor(_ context.Context, c scard.Context, reader string, onInsert func(atr []byte, proto string), onRemove func()) {
+// On insert -> inserted(); then blocks until removal; then goes back to waiting.
+// Contactless cards are debounced (see nfc.go). It returns when ctx is cancelled
+// and releases the PC/SC context (including any context re-established after a
+// service restart) on the way out.
+func monitor(ctx context.Context, c *scard.Context, reader string, h cardHandlers) {
+	var current atomic.Pointer[scard.Context]
+	current.Store(c)
+	defer func() {
+		if err := current.Load().Release(); err != nil {
+			slog.Warn("Failed to release PC/SC context", "err", err)
+		}
+	}()
+	// unblock a pending GetStatusChange right away instead of waiting for its timeout
+	stopCancel := context.AfterFunc(ctx, func() { _ = current.Load().Cancel() })
+	defer stopCancel()
+
 	state := scard.ReaderState{
 		Reader:       reader,
 		CurrentState: scard.StateUnaware,
 	}
 	var seenATR string
+	var filter presenceFilter
+
+	removed := func() {
+		seenATR = ""
+		filter.identity, filter.removedAt = "", time.Time{}
+		slog.Info("Card removed")
+		if h.removed != nil {
+			h.removed()
+		}
+		slog.Info("Waiting for card")
+	}
+
+	for ctx.Err() == nil {
+		debounce := settings.get().ContactlessDebounce
+		wait := 2 * time.Second
+		if pending, left := filter.removalPending(time.Now(), debounce); pending {
+			if left <= 0 {
+				removed()
+				continue
+			}
+			if left < wait {
+				wait = left
+			}
+		}
 
-	for {
 		// Arm for next change based on the last event state
 		state.CurrentState = state.EventState
 
-		// Block (up to 2s) for a state change
+		// Block (up to 2s, less while a removal is debounced) for a state change
 		states := []scard.ReaderState{state}
-		if err := c.GetStatusChange(states, 2000); err != nil && !errors.Is(err, scard.ErrTimeout) {
-			log.Printf("GetStatusChange error: %v", err)
+		if err := c.GetStatusChange(states, wait); err != nil && !errors.Is(err, scard.ErrTimeout) {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Error("GetStatusChange failed", "err", err)
+			status.recordError(err)
 			// If service is not available, try to reconnect
 			if strings.Contains(err.Error(), "Service not available") {
-				log.Println("PC/SC service not available, attempting to reconnect...")
-				time.Sleep(5 * time.Second)
+				slog.Warn("PC/SC service not available, attempting to reconnect")
+				if sleepCtx(ctx, 5*time.Second) != nil {
+					return
+				}
 				// Try to establish a new context
 				newCtx, err := scard.EstablishContext()
 				if err != nil {
-					log.Printf("Failed to re-establish context: %v", err)
-					time.Sleep(time.Second)
+					slog.Error("Failed to re-establish PC/SC context", "err", err)
+					_ = sleepCtx(ctx, time.Second)
 					continue
 				}
-				c = *newCtx
+				_ = c.Release()
+				c = newCtx
+				current.Store(c)
+				feedback.attach(c)
+				metrics.pcscReconnects.inc()
 				// Reset state
 				state = scard.ReaderState{
 					Reader:       reader,
 					CurrentState: scard.StateUnaware,
 				}
 				seenATR = ""
+				filter = presenceFilter{}
 			}
-			time.Sleep(time.Second)
+			_ = sleepCtx(ctx, time.Second)
 			continue
 		}
 		// 🔧 IMPORTANT: read back the updated state
@@ -197,27 +473,67 @@ func monitor(_ context.Context, c scard.Context, reader string, onInsert func(at
 			atrHex := strings.ToUpper(hex.EncodeToString(status.Atr))
 			proto := protocolName(status.ActiveProtocol)
 
-			if atrHex != seenATR {
-				seenATR = atrHex
-				log.Printf("Card inserted (ATR=%s, proto=%s)", atrHex, proto)
-				onInsert(status.Atr, proto)
-				log.Println("Reading done - waiting for removal")
+			pending, _ := filter.removalPending(time.Now(), debounce)
+			if atrHex == seenATR && !pending {
+				continue // still the same card
 			}
+
+			identity := atrHex
+			var uid string
+			contactless := contactlessMode(status.Atr)
+			if contactless {
+				if u, err := readUID(*c, reader); err == nil && u != "" {
+					uid, identity = u, u
+				}
+			}
+
+			if pending {
+				if identity == filter.identity {
+					// flap: same card is back within the debounce window
+					filter.removedAt = time.Time{}
+					slog.Debug("Contactless card back within debounce window", "uid", uid)
+					continue
+				}
+				removed()
+			}
+
+			seenATR = atrHex
+			filter.identity = identity
+			now := time.Now()
+			if contactless && filter.recentlyRead(identity, now, settings.get().ContactlessReread) {
+				slog.Info("Same contactless card re-presented", "uid", uid, "atr", atrHex)
+				if h.represented != nil {
+					h.represented(status.Atr, proto, uid)
+				}
+				continue
+			}
+			slog.Info("Card inserted", "atr", atrHex, "protocol", proto)
+			h.inserted(c, status.Atr, proto)
+			filter.markRead(identity, now)
+			slog.Debug("Reading done - waiting for removal")
 			continue
 		}
 
 		// Card removed?
 		if state.EventState&scard.StateEmpty != 0 && seenATR != "" {
-			seenATR = ""
-			log.Println("Card removed")
-			if onRemove != nil {
-				onRemove()
+			if filter.removedAt.IsZero() && debounce > 0 && contactlessMode(mustHex(seenATR)) {
+				// wait out the debounce window before reporting the removal
+				filter.removedAt = time.Now()
+				continue
+			}
+			if filter.removedAt.IsZero() {
+				removed()
 			}
-			log.Println("Waiting for card...")
 		}
 	}
 }
 
+// mustHex decodes a hex string produced by this program.
+func mustHex(s string) []byte {
+	b, _ := hex.DecodeString(s)
+	return b
+}
+
 func waitUntilRemoved(c scard.Context, reader string) {
 	for {
 		// Try a quick connect: if it fails, the card is gone
@@ -258,7 +574,7 @@ func envOr(k, def string) string {
 
 func must(err error, msg string) {
 	if err != nil {
-		log.Fatalf("%s: %v", msg, err)
+		fatal("Failed to "+msg, "err", err)
 	}
 }
 
@@ -272,19 +588,58 @@ type CertInfo struct {
 	RawDER  []byte
 }
 
-// readCardData tries (1) PKCS#11 certs, then (2) CPLC serial, then (3) UID.
-func readCardData(ctx scard.Context, reader string, protocol string, atr []byte) *CardData {
-	log.Printf("Reading card data (protocol: %s)", protocol)
+// readCardData tries (1) PKCS#11 certs / insurance card, then (2) CPLC serial, then (3) UID.
+// The PINStatus is only non-nil when PKCS11_PIN_LOGIN is on and a login was attempted.
+func readCardData(ctx scard.Context, reader string, protocol string, atr []byte, token string) (*CardData, *PINStatus) {
+	slog.Debug("Reading card data", "protocol", protocol)
 
 	// 1) Public certificates via PKCS#11 (no PIN needed to read certs)
-	if info, ok := readCertSubject(); ok {
+	if info, module, ok := readCertSubject(); ok {
 		first, last := extractName(info.Subject)
-		return &CardData{
+		cd := &CardData{
 			IDNumber:  strings.TrimSpace(info.Subject.SerialNumber), // may or may not be personal
 			FirstName: first,
 			LastName:  last,
 			Source:    "pkcs11-cert",
 		}
+		if settings.get().PhotoEnabled {
+			if photo, err := readPhotoPKCS11(module); err == nil {
+				cd.Photo = photo
+			} else {
+				slog.Debug("Photo not available", "err", err)
+			}
+		}
+		// 1b) name/DOB data objects behind CKU_USER login
+		if cfg := settings.get(); cfg.PINLogin && cfg.StatusAddr != "" {
+			pinStatus := readPINProtectedData(module, token, cd)
+			if pinStatus.Result == "ok" {
+				cd.Source = "pkcs11-login"
+			}
+			return cd, pinStatus
+		}
+		return cd, nil
+	}
+
+	// 1c) Windows: certificate via the card's minidriver (CryptoAPI)
+	if settings.get().CryptoAPIFallback {
+		if info, ok := readCertSubjectCryptoAPI(reader); ok {
+			first, last := extractName(info.Subject)
+			return &CardData{
+				IDNumber:  strings.TrimSpace(info.Subject.SerialNumber),
+				FirstName: first,
+				LastName:  last,
+				Source:    "cryptoapi-cert",
+			}, nil
+		}
+	}
+
+	// 1d) Health insurance card (eGK)
+	if settings.get().InsuranceCards {
+		if cd, err := readInsuranceCard(ctx, reader); err == nil {
+			return cd, nil
+		} else {
+			slog.Debug("Not an insurance card", "err", err)
+		}
 	}
 
 	// 2) CPLC (chip serial) via APDU GET DATA 9F7F (GlobalPlatform)
@@ -293,12 +648,13 @@ func readCardData(ctx scard.Context, reader string, protocol string, atr []byte)
 		return &CardData{
 			IDNumber: strings.ToUpper(icSerial),
 			Source:   "cplc",
-		}
+		}, nil
 	} else {
+		metrics.readFailures.inc("cplc", swCode(err))
 		if swmsg := explainSW(err); swmsg != "" {
-			log.Printf("CPLC read failed: %s", swmsg)
+			slog.Debug("CPLC read failed", "sw", swmsg)
 		} else {
-			log.Printf("CPLC read failed: %v", err)
+			slog.Debug("CPLC read failed", "err", err)
 		}
 	}
 
@@ -307,19 +663,20 @@ func readCardData(ctx scard.Context, reader string, protocol string, atr []byte)
 		return &CardData{
 			IDNumber: strings.ToUpper(uid),
 			Source:   "uid",
-		}
+		}, nil
 	} else if err != nil {
+		metrics.readFailures.inc("uid", swCode(err))
 		if swmsg := explainSW(err); swmsg != "" {
-			log.Printf("UID read failed: %s", swmsg)
+			slog.Debug("UID read failed", "sw", swmsg)
 		} else {
-			log.Printf("UID read failed: %v", err)
+			slog.Debug("UID read failed", "err", err)
 		}
 	}
 	// 4) ATR hash
 	return &CardData{
 		IDNumber: atrID(atr),
 		Source:   "atr-hash",
-	}
+	}, nil
 }
 
 // ----- PKCS#11 cert reading -----
@@ -474,23 +831,16 @@ func extractName(n pkix.Name) (given, surname string) {
 
 // readCPLC tries GlobalPlatform GET DATA (CPLC) 9F7F and extracts an IC serial
 func readCPLC(ctx scard.Context, reader string) (cplcHex string, icSerial string, err error) {
-	card, err := ctx.Connect(reader, scard.ShareShared, scard.ProtocolAny)
+	t, err := connectTransport(ctx, reader)
 	if err != nil {
 		return "", "", err
 	}
-	defer card.Disconnect(scard.LeaveCard)
-
-	apdu := []byte{0x80, 0xCA, 0x9F, 0x7F, 0x00}
-	resp, err := card.Transmit(apdu)
-	if err != nil || len(resp) < 2 {
-		return "", "", fmt.Errorf("CPLC transmit failed")
-	}
+	defer t.close()
 
-	sw1, sw2 := resp[len(resp)-2], resp[len(resp)-1]
-	if sw1 != 0x90 || sw2 != 0x00 {
-		return "", "", fmt.Errorf("GET DATA 9F7F failed SW=%02X%02X", sw1, sw2)
+	data, err := t.command(apduCommand{CLA: 0x80, INS: 0xCA, P1: 0x9F, P2: 0x7F, Ne: 256})
+	if err != nil {
+		return "", "", fmt.Errorf("GET DATA 9F7F failed %w", err)
 	}
-	data := resp[:len(resp)-2]
 
 	// Optional TLV 9F7F <len> <value>
 	if len(data) > 3 && data[0] == 0x9F && data[1] == 0x7F {
@@ -510,22 +860,16 @@ func readCPLC(ctx scard.Context, reader string) (cplcHex string, icSerial string
 
 // readUID tries PC/SC vendor GET DATA for contactless UID (not universal)
 func readUID(ctx scard.Context, reader string) (string, error) {
-	card, err := ctx.Connect(reader, scard.ShareShared, scard.ProtocolAny)
+	t, err := connectTransport(ctx, reader)
 	if err != nil {
 		return "", err
 	}
-	defer card.Disconnect(scard.LeaveCard)
+	defer t.close()
 
-	apdu := []byte{0xFF, 0xCA, 0x00, 0x00, 0x00}
-	resp, err := card.Transmit(apdu)
-	if err != nil || len(resp) < 2 {
-		return "", fmt.Errorf("UID transmit failed")
-	}
-	sw1, sw2 := resp[len(resp)-2], resp[len(resp)-1]
-	if sw1 != 0x90 || sw2 != 0x00 {
-		return "", fmt.Errorf("UID get failed SW=%02X%02X", sw1, sw2)
+	data, err := t.command(apduCommand{CLA: 0xFF, INS: 0xCA, Ne: 256})
+	if err != nil {
+		return "", fmt.Errorf("UID get failed %w", err)
 	}
-	data := resp[:len(resp)-2]
 	return strings.ToUpper(hex.EncodeToString(data)), nil
 }
 
@@ -561,11 +905,11 @@ func firstExistingPath(paths ...string) string {
 }
 
 // OPTIONAL: SMALL LOGGING TWEAK — REPLACE readCertSubject()’s module resolution with this snippet
-func readCertSubject() (CertInfo, bool) {
+func readCertSubject() (CertInfo, string, bool) {
 	cands := pkcs11ModuleCandidates()
 	if len(cands) == 0 {
-		log.Println("PKCS#11: no candidate module paths; set PKCS11_MODULE or install OpenSC.")
-		return CertInfo{}, false
+		slog.Warn("PKCS#11: no candidate module paths; set PKCS11_MODULE or install OpenSC")
+		return CertInfo{}, "", false
 	}
 	for _, mod := range cands {
 		if mod == "" {
@@ -574,21 +918,22 @@ func readCertSubject() (CertInfo, bool) {
 		if _, err := os.Stat(mod); err != nil {
 			continue
 		}
-		log.Printf("Trying PKCS#11 module: %s", mod)
+		slog.Debug("Trying PKCS#11 module", "module", mod)
 		certs, err := readPublicCertsPKCS11(mod)
 		if err != nil {
-			log.Printf("PKCS#11 attempt failed (%s): %v", mod, err)
+			slog.Debug("PKCS#11 attempt failed", "module", mod, "err", err)
 			continue
 		}
 		if len(certs) == 0 {
-			log.Printf("PKCS#11 found no certificates (%s)", mod)
+			slog.Debug("PKCS#11 found no certificates", "module", mod)
 			continue
 		}
 		// success
-		return certs[0], true
+		return certs[0], mod, true
 	}
-	log.Println("PKCS#11: no usable module initialized; all candidates failed.")
-	return CertInfo{}, false
+	metrics.readFailures.inc("pkcs11", "none")
+	slog.Info("PKCS#11: no usable module initialized; all candidates failed")
+	return CertInfo{}, "", false
 }
 
 func atrID(atr []byte) string {
@@ -599,8 +944,8 @@ func atrID(atr []byte) string {
 
 // ADD: candidate list helper (near your utils)
 func pkcs11ModuleCandidates() []string {
-	// Respect explicit override first
-	if m := strings.TrimSpace(os.Getenv("PKCS11_MODULE")); m != "" {
+	// Respect explicit override first (env or remote config)
+	if m := settings.get().PKCS11Module; m != "" {
 		return []string{m}
 	}
 	switch runtime.GOOS {
@@ -620,6 +965,8 @@ func pkcs11ModuleCandidates() []string {
 			"/usr/local/lib/opensc-pkcs11.so",
 			"/usr/lib/x86_64-linux-gnu/libeopproxy11.so",
 		}
+	case "windows":
+		return windowsPKCS11Candidates()
 	default:
 		return nil
 	}
@@ -629,11 +976,19 @@ func pkcs11ModuleCandidates() []string {
 // WebSocket Communication
 // -----------------------------
 
-func sendStateUpdate(wsURL string, deviceID, roomID, reader, state, message string) {
+func sendStateUpdate(deviceID, roomID, reader, state, message string) {
+	sendStateUpdateWithToken(deviceID, roomID, reader, randToken(8), state, message) // Shorter token for state updates
+}
+
+// sendStateUpdateWithToken is used when the UI must reply for a specific card (e.g. PIN entry).
+func sendStateUpdateWithToken(deviceID, roomID, reader, token, state, message string) {
+	cfg := settings.get()
 	payload := Payload{
 		DeviceID:   deviceID,
 		RoomID:     roomID,
-		Token:      randToken(8), // Shorter token for state updates
+		TenantID:   cfg.TenantID,
+		SectionID:  cfg.SectionID,
+		Token:      token,
 		Reader:     reader,
 		ATR:        "",
 		Protocol:   "",
@@ -642,41 +997,105 @@ func sendStateUpdate(wsURL string, deviceID, roomID, reader, state, message stri
 		Message:    message,
 	}
 
-	sendToWebSocket(wsURL, payload)
+	_ = deliverPayload(payload)
 }
 
-func sendToWebSocket(wsURL string, payload Payload) {
+// sendCardEvent delivers a card event after any spooled backlog, so the API
+// always sees events in the order they happened. On failure it goes to the spool.
+// Reports whether the event was delivered or spooled; card data refused over an
+// unencrypted target is spooled but reported as not sent.
+func sendCardEvent(payload Payload) bool {
+	if eventSpool == nil {
+		return deliverPayload(payload) == nil
+	}
+	if left := eventSpool.flush(deliverPayload); left > 0 {
+		slog.Warn("API still unreachable, spooling event", "token", payload.Token, "pending", left)
+		if err := eventSpool.enqueue(payload); err != nil {
+			slog.Error("Failed to spool event", "token", payload.Token, "err", err)
+			return false
+		}
+		return !insecureDenied(deliveryTarget(settings.get()), payload)
+	}
+	if err := deliverPayload(payload); err != nil {
+		slog.Warn("Spooling event for later delivery", "token", payload.Token)
+		if err := eventSpool.enqueue(payload); err != nil {
+			slog.Error("Failed to spool event", "token", payload.Token, "err", err)
+			return false
+		}
+		return !errors.Is(err, errInsecureTarget)
+	}
+	return true
+}
+
+// deliveryTarget is the WS_URL / INGEST_URL / GRPC_URL of the configured TRANSPORT.
+func deliveryTarget(cfg Config) string {
+	switch cfg.Transport {
+	case "http":
+		return cfg.IngestURL
+	case "grpc":
+		return cfg.GRPCURL
+	}
+	return cfg.WSURL
+}
+
+// deliverPayload sends over the configured TRANSPORT to whatever WS_URL / INGEST_URL / GRPC_URL
+// is currently configured (also used by replay).
+func deliverPayload(payload Payload) error {
+	cfg := settings.get()
+	switch cfg.Transport {
+	case "http":
+		return postPayload(cfg.IngestURL, payload)
+	case "grpc":
+		return sendGRPC(cfg.GRPCURL, payload)
+	}
+	return sendToWebSocket(cfg.WSURL, payload)
+}
+
+func sendToWebSocket(wsURL string, payload Payload) error {
 	// Parse WebSocket URL
 	u, err := url.Parse(wsURL)
 	if err != nil {
-		log.Printf("Invalid WebSocket URL: %v", err)
-		return
+		slog.Error("Invalid WebSocket URL", "wsUrl", wsURL, "err", err)
+		return err
+	}
+
+	if err := refuseInsecure(wsURL, payload); err != nil {
+		return err
 	}
 
-	// Connect to WebSocket
-	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	// Connect to WebSocket (device headers let the API authenticate and scope the reader)
+	conn, resp, err := dialer().Dial(u.String(), deviceHeaders(settings.get()))
+	if resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+		slog.Error("WebSocket rejected device credential; check DEVICE_TOKEN / client certificate", "status", resp.Status)
+	}
 	if err != nil {
-		log.Printf("Failed to connect to WebSocket: %v", err)
-		return
+		slog.Warn("Failed to connect to WebSocket", "wsUrl", wsURL, "err", err)
+		metrics.wsSendFailures.inc()
+		status.recordError(err)
+		return err
 	}
 	defer conn.Close()
 
 	// Send payload
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		log.Printf("Failed to marshal payload: %v", err)
-		return
+		slog.Error("Failed to marshal payload", "err", err)
+		return err
 	}
 
 	err = conn.WriteMessage(websocket.TextMessage, payloadBytes)
 	if err != nil {
-		log.Printf("Failed to send message: %v", err)
-		return
+		slog.Warn("Failed to send message", "err", err)
+		metrics.wsSendFailures.inc()
+		return err
 	}
+	// close handshake so the server doesn't log an abnormal closure
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
 
 	if payload.State != "" {
-		log.Printf("State update sent: %s - %s", payload.State, payload.Message)
+		slog.Debug("State update sent", "token", payload.Token, "state", payload.State, "message", payload.Message)
 	} else {
-		log.Printf("Card data sent to WebSocket successfully")
+		slog.Info("Card data sent to WebSocket", "token", payload.Token)
 	}
+	return nil
 }
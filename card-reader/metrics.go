@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// -----------------------------
+// Prometheus metrics (/metrics on STATUS_ADDR)
+// -----------------------------
+
+// A handful of counters and one histogram don't justify pulling in the Prometheus
+// client library, so the text exposition format is written by hand.
+
+type counterVec struct {
+	mu     sync.Mutex
+	name   string
+	help   string
+	labels []string
+	values map[string]float64 // key: label values joined by \xff
+}
+
+func newCounterVec(name, help string, labels ...string) *counterVec {
+	return &counterVec{name: name, help: help, labels: labels, values: map[string]float64{}}
+}
+
+func (c *counterVec) inc(labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[strings.Join(labelValues, "\xff")]++
+}
+
+func (c *counterVec) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	if len(c.labels) == 0 {
+		fmt.Fprintf(w, "%s %s\n", c.name, formatFloat(c.values[""]))
+		return
+	}
+	keys := make([]string, 0, len(c.values))
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, labelPairs(c.labels, strings.Split(k, "\xff")), formatFloat(c.values[k]))
+	}
+}
+
+type histogram struct {
+	mu      sync.Mutex
+	name    string
+	help    string
+	buckets []float64
+	counts  []uint64 // per bucket, non-cumulative
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(name, help string, buckets ...float64) *histogram {
+	return &histogram{name: name, help: help, buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, b := range h.buckets {
+		if v <= b {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+func (h *histogram) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	var cum uint64
+	for i, b := range h.buckets {
+		cum += h.counts[i]
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", h.name, formatFloat(b), cum)
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", h.name, h.count)
+	fmt.Fprintf(w, "%s_sum %s\n%s_count %d\n", h.name, formatFloat(h.sum), h.name, h.count)
+}
+
+func labelPairs(names, values []string) string {
+	pairs := make([]string, len(names))
+	for i, n := range names {
+		v := ""
+		if i < len(values) {
+			v = values[i]
+		}
+		pairs[i] = n + `="` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var metrics = struct {
+	cardsRead        *counterVec
+	readFailures     *counterVec
+	readDuration     *histogram
+	wsSendFailures   *counterVec
+	httpSendFailures *counterVec
+	grpcSendFailures *counterVec
+	pcscReconnects   *counterVec
+	cardsRepresented *counterVec
+	duplicateReads   *counterVec
+}{
+	cardsRead:        newCounterVec("cardreader_cards_read_total", "Cards read successfully, by data source.", "source"),
+	readFailures:     newCounterVec("cardreader_read_failures_total", "Failed read steps, by stage and ISO 7816 status word.", "stage", "sw"),
+	readDuration:     newHistogram("cardreader_read_duration_seconds", "Time from card insertion to a complete read.", 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60),
+	wsSendFailures:   newCounterVec("cardreader_ws_send_failures_total", "WebSocket messages that could not be delivered."),
+	httpSendFailures: newCounterVec("cardreader_http_send_failures_total", "Events that could not be posted to the API (TRANSPORT=http)."),
+	grpcSendFailures: newCounterVec("cardreader_grpc_send_failures_total", "Events the API did not take over gRPC (TRANSPORT=grpc)."),
+	pcscReconnects:   newCounterVec("cardreader_pcsc_reconnects_total", "PC/SC contexts re-established after the service went away."),
+	cardsRepresented: newCounterVec("cardreader_cards_represented_total", "Contactless cards presented again within the re-read interval (not read again)."),
+	duplicateReads:   newCounterVec("cardreader_duplicate_reads_total", "Successful reads suppressed as duplicates within the dedup window."),
+}
+
+var swPattern = regexp.MustCompile(`SW=([0-9A-Fa-f]{4})`)
+
+// swCode extracts the status word from an APDU error, "none" when there is none.
+func swCode(err error) string {
+	if err == nil {
+		return "none"
+	}
+	if m := swPattern.FindStringSubmatch(err.Error()); m != nil {
+		return strings.ToUpper(m[1])
+	}
+	return "none"
+}
+
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	metrics.cardsRead.write(w)
+	metrics.readFailures.write(w)
+	metrics.readDuration.write(w)
+	metrics.wsSendFailures.write(w)
+	metrics.httpSendFailures.write(w)
+	metrics.grpcSendFailures.write(w)
+	metrics.pcscReconnects.write(w)
+	metrics.cardsRepresented.write(w)
+	metrics.duplicateReads.write(w)
+
+	pending := 0
+	if eventSpool != nil {
+		pending = eventSpool.pending()
+	}
+	fmt.Fprintf(w, "# HELP cardreader_spool_pending Card events waiting in the offline spool.\n# TYPE cardreader_spool_pending gauge\ncardreader_spool_pending %d\n", pending)
+
+	present := 0
+	status.mu.RLock()
+	if status.cardPresent {
+		present = 1
+	}
+	status.mu.RUnlock()
+	fmt.Fprintf(w, "# HELP cardreader_card_present Whether a card is in the reader.\n# TYPE cardreader_card_present gauge\ncardreader_card_present %d\n", present)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// -----------------------------
+// Contactless (NFC) debounce
+// -----------------------------
+
+// Contactless cards flap: a card held loosely over the antenna produces bursts of
+// insert/remove events. In contactless mode a removal is only reported once the
+// card has stayed away for the debounce window, and a card whose UID was read less
+// than the re-read interval ago is announced as "re_presented" instead of being
+// read (and swiped) again.
+
+// isContactlessATR matches the PC/SC Part 3 pseudo-ATR that readers synthesize
+// for contactless cards: 3B 8n 80 01 ...
+func isContactlessATR(atr []byte) bool {
+	return len(atr) >= 4 && atr[0] == 0x3B && atr[1]&0xF0 == 0x80 && atr[2] == 0x80 && atr[3] == 0x01
+}
+
+// contactlessMode reports whether the debounce logic applies to a card with this ATR.
+func contactlessMode(atr []byte) bool {
+	switch strings.ToLower(settings.get().ContactlessMode) {
+	case "on":
+		return true
+	case "off":
+		return false
+	default:
+		return isContactlessATR(atr)
+	}
+}
+
+// presenceFilter is owned by monitor; no locking needed.
+type presenceFilter struct {
+	// card currently considered present (identity = UID, or ATR when there's no UID)
+	identity  string
+	removedAt time.Time // non-zero while a removal is being debounced
+
+	lastReadID string
+	lastReadAt time.Time
+}
+
+// removalPending reports whether a debounced removal is in progress and how long it still has to run.
+func (f *presenceFilter) removalPending(now time.Time, debounce time.Duration) (bool, time.Duration) {
+	if f.removedAt.IsZero() {
+		return false, 0
+	}
+	return true, debounce - now.Sub(f.removedAt)
+}
+
+// recentlyRead reports whether identity was read within the re-read interval.
+func (f *presenceFilter) recentlyRead(identity string, now time.Time, interval time.Duration) bool {
+	return identity != "" && identity == f.lastReadID && interval > 0 && now.Sub(f.lastReadAt) < interval
+}
+
+func (f *presenceFilter) markRead(identity string, now time.Time) {
+	f.lastReadID, f.lastReadAt = identity, now
+}
+
+// handleCardRepresented tells the kiosk the same card came back without reading it again.
+func handleCardRepresented(reader string, atr []byte, proto, uid string) {
+	metrics.cardsRepresented.inc()
+	feedback.show("success") // the card was already accepted, acknowledge the tap
+	cfg := settings.get()
+	_ = deliverPayload(Payload{
+		DeviceID:   cfg.DeviceID,
+		RoomID:     cfg.RoomID,
+		TenantID:   cfg.TenantID,
+		SectionID:  cfg.SectionID,
+		Token:      randToken(8),
+		Reader:     reader,
+		ATR:        strings.ToUpper(hex.EncodeToString(atr)),
+		Protocol:   proto,
+		OccurredAt: time.Now().Format(time.RFC3339),
+		State:      "re_presented",
+		Message:    "Same card presented again (UID " + uid + ")",
+	})
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/miekg/pkcs11"
+)
+
+// -----------------------------
+// Photo extraction (DG2 / card photo objects)
+// -----------------------------
+
+// Photos come either as a bare JPEG / JPEG2000 blob or wrapped in an ICAO DG2
+// (ISO 19794-5) container. Rather than parsing the full biometric template we
+// look for the image signature and decode from there; everything is re-encoded
+// as a size-capped JPEG so the dashboard gets one predictable format.
+
+var (
+	sigJPEG = []byte{0xFF, 0xD8, 0xFF}
+	sigJP2  = []byte{0x00, 0x00, 0x00, 0x0C, 0x6A, 0x50, 0x20, 0x20, 0x0D, 0x0A, 0x87, 0x0A}
+	sigJ2K  = []byte{0xFF, 0x4F, 0xFF, 0x51}
+)
+
+// isPhotoLabel matches data object labels used for the holder's photo.
+func isPhotoLabel(label string) bool {
+	l := strings.ToLower(label)
+	return strings.Contains(l, "photo") || strings.Contains(l, "portrait") ||
+		strings.Contains(l, "facial") || l == "dg2" || strings.HasSuffix(l, ".dg2")
+}
+
+// findImage returns the embedded image and its format ("jpeg", "jp2", "j2k").
+func findImage(raw []byte) ([]byte, string, error) {
+	best, format := -1, ""
+	for f, sig := range map[string][]byte{"jpeg": sigJPEG, "jp2": sigJP2, "j2k": sigJ2K} {
+		if i := bytes.Index(raw, sig); i >= 0 && (best < 0 || i < best) {
+			best, format = i, f
+		}
+	}
+	if best < 0 {
+		return nil, "", errors.New("no JPEG/JPEG2000 signature found")
+	}
+	return raw[best:], format, nil
+}
+
+// photoToJPEGBase64 decodes a photo blob and returns a base64 JPEG whose longer
+// side is at most maxSide pixels (0 = keep original size).
+func photoToJPEGBase64(raw []byte, maxSide int) (string, error) {
+	data, format, err := findImage(raw)
+	if err != nil {
+		return "", err
+	}
+
+	var img image.Image
+	switch format {
+	case "jpeg":
+		img, err = jpeg.Decode(bytes.NewReader(data))
+	default:
+		img, err = decodeJPEG2000(data, format)
+	}
+	if err != nil {
+		return "", fmt.Errorf("decode %s photo: %w", format, err)
+	}
+
+	img = fitWithin(img, maxSide)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// decodeJPEG2000 shells out to OpenJPEG (opj_decompress, or JP2_DECODER) since
+// there's no JPEG2000 decoder in the standard library.
+func decodeJPEG2000(data []byte, format string) (image.Image, error) {
+	bin := settings.get().JP2Decoder
+	if _, err := exec.LookPath(bin); err != nil {
+		return nil, fmt.Errorf("JPEG2000 decoder %q not found (install OpenJPEG or set JP2_DECODER)", bin)
+	}
+
+	dir, err := os.MkdirTemp("", "card-photo-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	in := filepath.Join(dir, "photo."+format)
+	out := filepath.Join(dir, "photo.png")
+	if err := os.WriteFile(in, data, 0o600); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if b, err := exec.CommandContext(ctx, bin, "-i", in, "-o", out).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("%s failed: %v: %s", bin, err, strings.TrimSpace(string(b)))
+	}
+
+	f, err := os.Open(out)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+// fitWithin downsamples with a box filter so the longer side is <= maxSide.
+func fitWithin(src image.Image, maxSide int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if maxSide <= 0 || (w <= maxSide && h <= maxSide) {
+		return src
+	}
+	nw, nh := maxSide, h*maxSide/w
+	if h > w {
+		nw, nh = w*maxSide/h, maxSide
+	}
+	if nw < 1 {
+		nw = 1
+	}
+	if nh < 1 {
+		nh = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, nw, nh))
+	for y := 0; y < nh; y++ {
+		y0, y1 := b.Min.Y+y*h/nh, b.Min.Y+(y+1)*h/nh
+		for x := 0; x < nw; x++ {
+			x0, x1 := b.Min.X+x*w/nw, b.Min.X+(x+1)*w/nw
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a, n = r+cr, g+cg, bl+cb, a+ca, n+1
+				}
+			}
+			if n == 0 {
+				continue
+			}
+			dst.Set(x, y, color.RGBA64{uint16(r / n), uint16(g / n), uint16(bl / n), uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+// readPhotoPKCS11 looks for a public photo data object (no login needed).
+func readPhotoPKCS11(modulePath string) (string, error) {
+	p := pkcs11.New(modulePath)
+	if p == nil {
+		return "", fmt.Errorf("pkcs11.New returned nil for %q", modulePath)
+	}
+	if err := p.Initialize(); err != nil {
+		return "", fmt.Errorf("pkcs11 initialize %q failed: %w", modulePath, err)
+	}
+	defer func() {
+		p.Destroy()
+		p.Finalize()
+	}()
+
+	slots, err := p.GetSlotList(true)
+	if err != nil {
+		return "", fmt.Errorf("GetSlotList: %w", err)
+	}
+	for _, slot := range slots {
+		sess, err := p.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+		if err != nil {
+			continue
+		}
+		photo, err := findPhotoObject(p, sess)
+		p.CloseSession(sess)
+		if err == nil && photo != "" {
+			return photo, nil
+		}
+	}
+	return "", errors.New("no photo object found via PKCS#11")
+}
+
+// findPhotoObject scans CKO_DATA objects of an open session for a photo.
+func findPhotoObject(p *pkcs11.Ctx, sess pkcs11.SessionHandle) (string, error) {
+	if err := p.FindObjectsInit(sess, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_DATA),
+	}); err != nil {
+		return "", err
+	}
+	objs, _, err := p.FindObjects(sess, 100)
+	p.FindObjectsFinal(sess)
+	if err != nil {
+		return "", err
+	}
+
+	for _, o := range objs {
+		attrs, err := p.GetAttributeValue(sess, o, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, nil),
+		})
+		if err != nil || len(attrs) == 0 || !isPhotoLabel(string(attrs[0].Value)) {
+			continue
+		}
+		vals, err := p.GetAttributeValue(sess, o, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_VALUE, nil),
+		})
+		if err != nil || len(vals) == 0 {
+			continue
+		}
+		photo, err := photoToJPEGBase64(vals[0].Value, settings.get().PhotoMaxSide)
+		if err != nil {
+			slog.Warn("Photo object could not be decoded", "label", string(attrs[0].Value), "err", err)
+			continue
+		}
+		return photo, nil
+	}
+	return "", nil
+}
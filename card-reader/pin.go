@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/pkcs11"
+)
+
+// -----------------------------
+// PIN-protected data (PKCS#11 C_Login)
+// -----------------------------
+
+// Some eID cards only expose name/DOB data objects after a CKU_USER login.
+// With PKCS11_PIN_LOGIN=true the reader announces "pin_required" over the
+// WebSocket, the kiosk UI collects the PIN and POSTs it to the local status
+// server (/pin), and the reader logs in before enumerating CKO_DATA objects.
+
+// PINStatus is attached to the payload whenever a PIN login was attempted.
+type PINStatus struct {
+	Result   string `json:"result"`             // "ok" | "incorrect" | "locked" | "timeout" | "cancelled" | "error"
+	CountLow bool   `json:"countLow,omitempty"` // token reports at least one failed attempt
+	FinalTry bool   `json:"finalTry,omitempty"` // next wrong PIN locks the card
+	Locked   bool   `json:"locked,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+var errPINCancelled = errors.New("PIN entry cancelled")
+var errPINTimeout = errors.New("PIN entry timed out")
+
+// pinBroker hands a PIN from the local HTTP channel to the card read in progress.
+// Only one card is read at a time, so a single pending slot is enough.
+type pinBroker struct {
+	mu    sync.Mutex
+	token string
+	ch    chan string
+}
+
+var pins = &pinBroker{}
+
+// wait blocks until the kiosk submits a PIN for token, cancels, or timeout expires.
+func (b *pinBroker) wait(token string, timeout time.Duration) (string, error) {
+	ch := make(chan string, 1)
+	b.mu.Lock()
+	b.token, b.ch = token, ch
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		b.token, b.ch = "", nil
+		b.mu.Unlock()
+	}()
+
+	select {
+	case pin := <-ch:
+		if pin == "" {
+			return "", errPINCancelled
+		}
+		return pin, nil
+	case <-time.After(timeout):
+		return "", errPINTimeout
+	}
+}
+
+// submit delivers a PIN (empty = cancel). Returns false if nothing is waiting for token.
+func (b *pinBroker) submit(token, pin string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.ch == nil || b.token != token {
+		return false
+	}
+	b.ch <- pin
+	b.ch = nil
+	return true
+}
+
+// cancelPending aborts a PIN request in progress, e.g. on shutdown.
+func (b *pinBroker) cancelPending() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.ch != nil {
+		b.ch <- ""
+		b.ch = nil
+	}
+}
+
+// handlePIN is mounted on the local status server: POST /pin {"token": "...", "pin": "..."}.
+// An empty pin cancels the pending request.
+func handlePIN(w http.ResponseWriter, r *http.Request) {
+	if origin := settings.get().PINAllowedOrigin; origin != "" {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	}
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeStatusJSON(w, http.StatusMethodNotAllowed, map[string]string{"message": "use POST"})
+		return
+	}
+
+	var body struct {
+		Token string `json:"token"`
+		PIN   string `json:"pin"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&body); err != nil {
+		writeStatusJSON(w, http.StatusBadRequest, map[string]string{"message": "invalid body"})
+		return
+	}
+	if !pins.submit(body.Token, body.PIN) {
+		writeStatusJSON(w, http.StatusConflict, map[string]string{"message": "no PIN request pending for this token"})
+		return
+	}
+	writeStatusJSON(w, http.StatusAccepted, map[string]string{"status": "accepted"})
+}
+
+// readPINProtectedData asks for a PIN, logs in as CKU_USER and fills cd from the
+// card's data objects. The returned PINStatus is always non-nil.
+func readPINProtectedData(modulePath, token string, cd *CardData) *PINStatus {
+	cfg := settings.get()
+	sendStateUpdateWithToken(cfg.DeviceID, cfg.RoomID, status.currentReader(), token, "pin_required", "Please enter your PIN")
+
+	pin, err := pins.wait(token, cfg.PINTimeout)
+	switch {
+	case errors.Is(err, errPINCancelled):
+		return &PINStatus{Result: "cancelled", Message: err.Error()}
+	case errors.Is(err, errPINTimeout):
+		return &PINStatus{Result: "timeout", Message: err.Error()}
+	}
+
+	st, err := loginAndReadDataObjects(modulePath, pin, cd)
+	if err != nil {
+		slog.Warn("PKCS#11 login read failed", "err", err)
+		if st.Message == "" {
+			st.Message = err.Error()
+		}
+	}
+	return st
+}
+
+func loginAndReadDataObjects(modulePath, pin string, cd *CardData) (*PINStatus, error) {
+	p := pkcs11.New(modulePath)
+	if p == nil {
+		return &PINStatus{Result: "error"}, fmt.Errorf("pkcs11.New returned nil for %q", modulePath)
+	}
+	if err := p.Initialize(); err != nil {
+		return &PINStatus{Result: "error"}, fmt.Errorf("pkcs11 initialize %q failed: %w", modulePath, err)
+	}
+	defer func() {
+		p.Destroy()
+		p.Finalize()
+	}()
+
+	slots, err := p.GetSlotList(true)
+	if err != nil || len(slots) == 0 {
+		return &PINStatus{Result: "error"}, fmt.Errorf("no PKCS#11 slots with token: %v", err)
+	}
+	slot := slots[0]
+
+	sess, err := p.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return &PINStatus{Result: "error"}, fmt.Errorf("OpenSession: %w", err)
+	}
+	defer p.CloseSession(sess)
+
+	if err := p.Login(sess, pkcs11.CKU_USER, pin); err != nil {
+		st := pinCounterStatus(p, slot)
+		var perr pkcs11.Error
+		if errors.As(err, &perr) {
+			switch uint(perr) {
+			case pkcs11.CKR_PIN_INCORRECT:
+				st.Result = "incorrect"
+			case pkcs11.CKR_PIN_LOCKED:
+				st.Result = "locked"
+				st.Locked = true
+			default:
+				st.Result = "error"
+			}
+		} else {
+			st.Result = "error"
+		}
+		return st, fmt.Errorf("C_Login: %w", err)
+	}
+	defer p.Logout(sess)
+
+	if err := p.FindObjectsInit(sess, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_DATA),
+	}); err != nil {
+		return &PINStatus{Result: "error"}, fmt.Errorf("FindObjectsInit: %w", err)
+	}
+	objs, _, err := p.FindObjects(sess, 100)
+	p.FindObjectsFinal(sess)
+	if err != nil {
+		return &PINStatus{Result: "error"}, fmt.Errorf("FindObjects: %w", err)
+	}
+
+	for _, o := range objs {
+		attrs, err := p.GetAttributeValue(sess, o, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, nil),
+			pkcs11.NewAttribute(pkcs11.CKA_VALUE, nil),
+		})
+		if err != nil {
+			continue
+		}
+		var label string
+		var raw []byte
+		for _, a := range attrs {
+			switch a.Type {
+			case pkcs11.CKA_LABEL:
+				label = string(a.Value)
+			case pkcs11.CKA_VALUE:
+				raw = a.Value
+			}
+		}
+		if isPhotoLabel(label) {
+			if cfg := settings.get(); cfg.PhotoEnabled && cd.Photo == "" {
+				if photo, err := photoToJPEGBase64(raw, cfg.PhotoMaxSide); err == nil {
+					cd.Photo = photo
+				} else {
+					slog.Warn("Photo object could not be decoded", "label", label, "err", err)
+				}
+			}
+			continue
+		}
+		applyDataObject(cd, label, strings.TrimSpace(string(raw)))
+	}
+
+	slog.Debug("PKCS#11 login read", "objects", len(objs))
+	return &PINStatus{Result: "ok"}, nil
+}
+
+// pinCounterStatus reports the retry-counter flags of the token. PKCS#11 has no
+// exact retry count, only "count low", "final try" and "locked".
+func pinCounterStatus(p *pkcs11.Ctx, slot uint) *PINStatus {
+	st := &PINStatus{}
+	info, err := p.GetTokenInfo(slot)
+	if err != nil {
+		return st
+	}
+	st.CountLow = info.Flags&pkcs11.CKF_USER_PIN_COUNT_LOW != 0
+	st.FinalTry = info.Flags&pkcs11.CKF_USER_PIN_FINAL_TRY != 0
+	st.Locked = info.Flags&pkcs11.CKF_USER_PIN_LOCKED != 0
+	return st
+}
+
+// applyDataObject maps data object labels used by common eID middlewares onto CardData.
+func applyDataObject(cd *CardData, label, value string) {
+	if value == "" {
+		return
+	}
+	key := strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(label))
+	switch key {
+	case "givenname", "firstname", "firstnames":
+		cd.FirstName = value
+	case "surname", "lastname", "familyname":
+		cd.LastName = value
+	case "dateofbirth", "birthdate", "dob":
+		cd.DateOfBirth = value
+	case "gender", "sex":
+		cd.Gender = value
+	case "nationality":
+		cd.Nationality = value
+	case "address", "permanentaddress":
+		cd.Address = value
+	case "personalnumber", "birthnumber", "idnumber", "documentnumber":
+		cd.IDNumber = value
+	case "dateofissue", "issueddate":
+		cd.IssuedDate = value
+	case "dateofexpiry", "expirydate":
+		cd.ExpiryDate = value
+	case "insurancenumber", "insuranceid", "healthinsurancenumber", "ehicpersonalnumber":
+		cd.InsuranceNumber = value
+	case "insurercode", "insurerid", "institutionid", "ehicinstitutionid":
+		cd.InsurerCode = value
+	case "insurername", "institutionname":
+		cd.InsurerName = value
+	case "insurancevalidfrom", "insurancestart":
+		cd.InsuranceValidFrom = isoDate(value)
+	case "insurancevalidto", "insuranceexpiry", "ehicexpirydate":
+		cd.InsuranceValidTo = isoDate(value)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// -----------------------------
+// OS service wrapper
+// -----------------------------
+
+// "card-reader install" registers the binary as a managed service that starts at
+// boot and is restarted whenever it exits: a systemd unit on Linux, a Service
+// Control Manager entry with recovery actions on Windows. The configuration in the
+// installing shell's environment is captured into the service definition, so
+//
+//	DEVICE_ID=... CONFIG_URL=... DEVICE_TOKEN=... card-reader install
+//
+// is all an operator needs. "card-reader uninstall" stops and removes it again and
+// "card-reader run" (the default) runs in the foreground.
+
+const (
+	serviceName        = "waiting-room-card-reader"
+	serviceDisplayName = "Waiting Room Card Reader"
+	serviceDescription = "Reads ID cards and reports them to the waiting-room API"
+)
+
+// serviceEnvKeys are the variables copied into the service definition at install time.
+var serviceEnvKeys = []string{
+	"DEVICE_ID", "DEVICE_TOKEN", "ROOM_ID", "TENANT_ID", "SECTION_ID", "READER_NAME",
+	"WS_URL", "CONFIG_URL", "CONFIG_REFRESH", "TRANSPORT", "INGEST_URL", "GRPC_URL", "ALLOW_INSECURE_WS",
+	"TLS_CA_FILE", "TLS_CLIENT_CERT", "TLS_CLIENT_KEY", "CARD_DATA_KEY",
+	"PKCS11_MODULE", "PKCS11_PIN_LOGIN", "PIN_TIMEOUT", "PIN_ALLOWED_ORIGIN", "CRYPTOAPI_FALLBACK",
+	"INSURANCE_CARDS", "EXTENDED_APDU", "PHOTO_ENABLED", "PHOTO_MAX_PX", "JP2_DECODER",
+	"CONTACTLESS_MODE", "CONTACTLESS_DEBOUNCE", "CONTACTLESS_REREAD_INTERVAL", "DEDUP_WINDOW",
+	"FEEDBACK", "STATUS_ADDR", "SPOOL_DIR", "SPOOL_RETENTION", "SPOOL_MAX_MB",
+	"UPDATE_CHECK_INTERVAL", "UPDATE_PUBLIC_KEY", "LOG_LEVEL", "LOG_FORMAT", "LOG_FILE",
+	"SIMULATE", "SIMULATE_FIXTURES",
+}
+
+// serviceEnvironment returns the reader configuration of the current environment as
+// sorted KEY=value pairs. FEEDBACK_<STATE> patterns are included as well.
+func serviceEnvironment() []string {
+	keep := make(map[string]bool, len(serviceEnvKeys))
+	for _, k := range serviceEnvKeys {
+		keep[k] = true
+	}
+	var env []string
+	for _, kv := range os.Environ() {
+		k, _, _ := strings.Cut(kv, "=")
+		if keep[k] || strings.HasPrefix(k, "FEEDBACK_") {
+			env = append(env, kv)
+		}
+	}
+	sort.Strings(env)
+	return env
+}
+
+// serviceArgs are the command-line flags used for the installed service: the flags
+// given before "install", followed by "run".
+func serviceArgs(nonFlagArgs int) []string {
+	args := append([]string(nil), os.Args[1:len(os.Args)-nonFlagArgs]...)
+	return append(args, "run")
+}
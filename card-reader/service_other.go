@@ -0,0 +1,126 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// On Linux the service is a systemd unit with Restart=always. The captured
+// configuration goes into a separate 0600 environment file, since it usually
+// contains DEVICE_TOKEN and the unit file itself is world-readable.
+
+const (
+	systemdUnitPath = "/etc/systemd/system/" + serviceName + ".service"
+	systemdEnvPath  = "/etc/" + serviceName + ".env"
+)
+
+// runningAsService is only meaningful on Windows; systemd runs the reader as a
+// normal foreground process.
+func runningAsService() bool { return false }
+
+// runService is never called outside Windows.
+func runService(run func(ctx context.Context) bool) {}
+
+func installService() error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("service install is not supported on %s", runtime.GOOS)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+
+	var env strings.Builder
+	for _, kv := range serviceEnvironment() {
+		k, v, _ := strings.Cut(kv, "=")
+		fmt.Fprintf(&env, "%s=\"%s\"\n", k, strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v))
+	}
+	if err := os.WriteFile(systemdEnvPath, []byte(env.String()), 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", systemdEnvPath, err)
+	}
+
+	args := []string{systemdQuote(exe)}
+	for _, a := range serviceArgs(flag.NArg()) {
+		args = append(args, systemdQuote(a))
+	}
+	unit := fmt.Sprintf(`[Unit]
+Description=%s
+Wants=network-online.target
+After=network-online.target pcscd.service
+
+[Service]
+EnvironmentFile=%s
+ExecStart=%s
+Restart=always
+RestartSec=5
+
+[Install]
+WantedBy=multi-user.target
+`, serviceDescription, systemdEnvPath, strings.Join(args, " "))
+	if err := os.WriteFile(systemdUnitPath, []byte(unit), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", systemdUnitPath, err)
+	}
+
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	if err := systemctl("enable", "--now", serviceName); err != nil {
+		return err
+	}
+	slog.Info("Service installed and started", "unit", systemdUnitPath)
+	return nil
+}
+
+func uninstallService() error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("service uninstall is not supported on %s", runtime.GOOS)
+	}
+	if _, err := os.Stat(systemdUnitPath); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("service %s is not installed", serviceName)
+	}
+	if err := systemctl("disable", "--now", serviceName); err != nil {
+		slog.Warn("Stopping service failed", "err", err)
+	}
+	if err := os.Remove(systemdUnitPath); err != nil {
+		return err
+	}
+	if err := os.Remove(systemdEnvPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("Removing service environment failed", "err", err)
+	}
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	slog.Info("Service removed", "unit", systemdUnitPath)
+	return nil
+}
+
+func systemctl(args ...string) error {
+	out, err := exec.Command("systemctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// systemdQuote quotes an ExecStart word if needed, escaping what systemd would
+// otherwise expand (specifiers and variables).
+func systemdQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\$%;") {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$")
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,157 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// On Windows the reader registers with the Service Control Manager. Recovery
+// actions restart it after any exit with a non-zero code, which is also how an
+// installed update takes effect. The captured configuration is stored in the
+// service's "Environment" registry value, which the SCM passes to the process.
+
+func runningAsService() bool {
+	ok, err := svc.IsWindowsService()
+	return err == nil && ok
+}
+
+// readerService adapts runReader to the SCM control protocol.
+type readerService struct {
+	run func(ctx context.Context) bool
+}
+
+func (s readerService) Execute(_ []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	changes <- svc.Status{State: svc.StartPending}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan bool, 1)
+	go func() { done <- s.run(ctx) }()
+	changes <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case updated := <-done:
+			if updated {
+				// Exit "failed" so the recovery action starts the new binary.
+				return false, 1
+			}
+			return false, 0
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				changes <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				cancel()
+				<-done
+				return false, 0
+			}
+		}
+	}
+}
+
+// runService blocks until the SCM stops the service.
+func runService(run func(ctx context.Context) bool) {
+	if err := svc.Run(serviceName, readerService{run: run}); err != nil {
+		fatal("Service failed", "err", err)
+	}
+}
+
+func installService() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.Abs(exe); err != nil {
+		return err
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to service manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(serviceName); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s already exists", serviceName)
+	}
+	s, err := m.CreateService(serviceName, exe, mgr.Config{
+		DisplayName: serviceDisplayName,
+		Description: serviceDescription,
+		StartType:   mgr.StartAutomatic,
+	}, serviceArgs(flag.NArg())...)
+	if err != nil {
+		return fmt.Errorf("create service: %w", err)
+	}
+	defer s.Close()
+
+	restart := mgr.RecoveryAction{Type: mgr.ServiceRestart, Delay: 5 * time.Second}
+	if err := s.SetRecoveryActions([]mgr.RecoveryAction{restart, restart, restart}, uint32((24 * time.Hour).Seconds())); err != nil {
+		return fmt.Errorf("set recovery actions: %w", err)
+	}
+	if err := s.SetRecoveryActionsOnNonCrashFailures(true); err != nil {
+		return fmt.Errorf("set recovery actions: %w", err)
+	}
+	if err := setServiceEnvironment(serviceEnvironment()); err != nil {
+		return err
+	}
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("start service: %w", err)
+	}
+	slog.Info("Service installed and started", "name", serviceName)
+	return nil
+}
+
+func uninstallService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to service manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", serviceName)
+	}
+	defer s.Close()
+
+	if st, err := s.Control(svc.Stop); err == nil {
+		for deadline := time.Now().Add(20 * time.Second); st.State != svc.Stopped && time.Now().Before(deadline); {
+			time.Sleep(300 * time.Millisecond)
+			if st, err = s.Query(); err != nil {
+				break
+			}
+		}
+	}
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("delete service: %w", err)
+	}
+	slog.Info("Service removed", "name", serviceName)
+	return nil
+}
+
+func setServiceEnvironment(env []string) error {
+	if len(env) == 0 {
+		return nil
+	}
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\`+serviceName, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("open service registry key: %w", err)
+	}
+	defer k.Close()
+	if err := k.SetStringsValue("Environment", env); err != nil {
+		return fmt.Errorf("store service environment: %w", err)
+	}
+	return nil
+}
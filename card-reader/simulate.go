@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// -----------------------------
+// Simulation mode (no PC/SC hardware)
+// -----------------------------
+
+// simulatedReader is reported as the reader name for simulated events.
+const simulatedReader = "Simulated Reader"
+
+// simFixture describes one simulated card insertion. Everything is optional: an
+// empty object produces a card with a random ID number.
+type simFixture struct {
+	ATR      string     `json:"atr,omitempty"`      // hex, default: a fixed fake ATR
+	Protocol string     `json:"protocol,omitempty"` // default "T=1"
+	CardData *CardData  `json:"cardData,omitempty"` // nil = generated
+	PIN      *PINStatus `json:"pin,omitempty"`      // simulate a PIN login outcome
+	Fail     bool       `json:"fail,omitempty"`     // simulate an unreadable card
+	Delay    string     `json:"delay,omitempty"`    // wait before inserting, e.g. "3s"
+	Present  string     `json:"present,omitempty"`  // time until removal, default "2s"
+}
+
+var defaultSimATR = []byte{0x3B, 0x8F, 0x80, 0x01, 0x80, 0x4F, 0x0C, 0xA0, 0x00, 0x00, 0x03, 0x06}
+
+// runSimulation replays fixtures from path (a JSON array or a stream of JSON
+// objects, one per line) or from stdin when path is "" or "-". Each fixture goes
+// through the same insert/remove handling as a physical card.
+func runSimulation(ctx context.Context, path string) error {
+	var in io.Reader = os.Stdin
+	if path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("open fixtures: %w", err)
+		}
+		defer f.Close()
+		in = f
+	} else {
+		slog.Info("Simulation: reading fixtures from stdin, one JSON object per line ({} for a random card)")
+	}
+
+	logCtx.setReader(simulatedReader)
+	status.setReader(simulatedReader)
+	cfg := settings.get()
+	sendStateUpdate(cfg.DeviceID, cfg.RoomID, simulatedReader, "waiting", "Please insert your ID card")
+
+	// decode in the background: a read from stdin would otherwise block shutdown
+	fixtures := make(chan simFixture)
+	decodeErr := make(chan error, 1)
+	go func() {
+		defer close(fixtures)
+		decodeErr <- decodeFixtures(ctx, in, fixtures)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case fx, ok := <-fixtures:
+			if !ok {
+				return <-decodeErr
+			}
+			if err := simulateCard(ctx, fx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func decodeFixtures(ctx context.Context, in io.Reader, out chan<- simFixture) error {
+	br := bufio.NewReader(in)
+	dec := json.NewDecoder(br)
+	if first, err := peekNonSpace(br); err == nil && first == '[' {
+		if _, err := dec.Token(); err != nil {
+			return fmt.Errorf("fixtures: %w", err)
+		}
+	}
+
+	for n := 1; dec.More(); n++ {
+		var fx simFixture
+		if err := dec.Decode(&fx); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("fixture %d: %w", n, err)
+		}
+		select {
+		case out <- fx:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return nil
+}
+
+func simulateCard(ctx context.Context, fx simFixture) error {
+	if err := sleepCtx(ctx, parseSimDuration(fx.Delay, 0)); err != nil {
+		return err
+	}
+
+	atr := defaultSimATR
+	if fx.ATR != "" {
+		b, err := hex.DecodeString(strings.ReplaceAll(fx.ATR, " ", ""))
+		if err != nil {
+			return fmt.Errorf("fixture atr: %w", err)
+		}
+		atr = b
+	}
+	proto := fx.Protocol
+	if proto == "" {
+		proto = "T=1"
+	}
+
+	slog.Info("Simulation: card inserted", "atr", strings.ToUpper(hex.EncodeToString(atr)))
+	handleCardInserted(simulatedReader, atr, proto, func(token string) (*CardData, *PINStatus) {
+		if fx.Fail {
+			return nil, nil
+		}
+		cd := fx.CardData
+		if cd == nil {
+			cd = &CardData{IDNumber: randToken(5)}
+		}
+		if cd.Source == "" {
+			cd.Source = "simulated"
+		}
+		return cd, fx.PIN
+	})
+
+	if err := sleepCtx(ctx, parseSimDuration(fx.Present, 2*time.Second)); err != nil {
+		return err
+	}
+	slog.Info("Simulation: card removed")
+	handleCardRemoved(simulatedReader)
+	return nil
+}
+
+func parseSimDuration(s string, def time.Duration) time.Duration {
+	if s == "" {
+		return def
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		slog.Warn("Simulation: invalid duration, using default", "value", s, "default", def.String())
+		return def
+	}
+	return d
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// peekNonSpace returns the first non-whitespace byte without consuming it.
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			_, _ = br.ReadByte()
+		default:
+			return b[0], nil
+		}
+	}
+}
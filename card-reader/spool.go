@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// -----------------------------
+// Offline spool
+// -----------------------------
+
+// spool keeps card events on disk while the WebSocket is unreachable and replays
+// them in insertion order once it's back. One JSON file per payload; the file name
+// starts with a zero-padded unix-nano timestamp so a lexical sort is chronological.
+type spool struct {
+	mu        sync.Mutex
+	dir       string
+	retention time.Duration
+	maxBytes  int64
+}
+
+func newSpool(dir string, retention time.Duration, maxBytes int64) (*spool, error) {
+	// payloads contain personal data: keep them readable by this user only
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create spool dir: %w", err)
+	}
+	return &spool{dir: dir, retention: retention, maxBytes: maxBytes}, nil
+}
+
+func defaultSpoolDir() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "waiting-room-card-reader", "spool")
+}
+
+// enqueue persists a payload at the tail of the spool.
+func (s *spool) enqueue(pl Payload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := json.Marshal(pl)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%020d-%s.json", time.Now().UnixNano(), pl.Token)
+	tmp := filepath.Join(s.dir, name+".tmp")
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	// rename so a crash never leaves a half-written payload in the queue
+	if err := os.Rename(tmp, filepath.Join(s.dir, name)); err != nil {
+		return err
+	}
+	s.pruneLocked()
+	return nil
+}
+
+// flush replays spooled payloads oldest first and stops at the first failure so
+// ordering is preserved. It returns how many payloads are still pending.
+func (s *spool) flush(send func(Payload) error) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked()
+	files := s.filesLocked()
+	for i, f := range files {
+		path := filepath.Join(s.dir, f.Name())
+		b, err := os.ReadFile(path)
+		if err != nil {
+			// Stop here like a failed send: later events must not overtake this one
+			slog.Error("Spool: cannot read entry", "file", f.Name(), "err", err)
+			return len(files) - i
+		}
+		var pl Payload
+		if err := json.Unmarshal(b, &pl); err != nil {
+			slog.Warn("Spool: dropping corrupt entry", "file", f.Name(), "err", err)
+			_ = os.Remove(path)
+			continue
+		}
+		if err := send(pl); err != nil {
+			return len(files) - i
+		}
+		_ = os.Remove(path)
+		slog.Info("Spool: replayed event", "token", pl.Token, "occurredAt", pl.OccurredAt)
+	}
+	return 0
+}
+
+func (s *spool) pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.filesLocked())
+}
+
+func (s *spool) filesLocked() []os.DirEntry {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		slog.Error("Spool: cannot list directory", "dir", s.dir, "err", err)
+		return nil
+	}
+	files := entries[:0]
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			files = append(files, e)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
+	return files
+}
+
+// pruneLocked drops entries older than the retention window, then the oldest
+// entries until the spool fits into maxBytes.
+func (s *spool) pruneLocked() {
+	files := s.filesLocked()
+	cutoff := time.Now().Add(-s.retention)
+
+	var total int64
+	kept := files[:0]
+	for _, f := range files {
+		if s.retention > 0 && spoolEntryTime(f.Name()).Before(cutoff) {
+			slog.Warn("Spool: dropping expired event", "file", f.Name())
+			_ = os.Remove(filepath.Join(s.dir, f.Name()))
+			continue
+		}
+		if info, err := f.Info(); err == nil {
+			total += info.Size()
+		}
+		kept = append(kept, f)
+	}
+
+	for len(kept) > 0 && s.maxBytes > 0 && total > s.maxBytes {
+		f := kept[0]
+		if info, err := f.Info(); err == nil {
+			total -= info.Size()
+		}
+		slog.Warn("Spool: size limit reached, dropping oldest event", "file", f.Name())
+		_ = os.Remove(filepath.Join(s.dir, f.Name()))
+		kept = kept[1:]
+	}
+}
+
+func spoolEntryTime(name string) time.Time {
+	prefix, _, _ := strings.Cut(name, "-")
+	n, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
+
+// replayLoop retries the spool periodically so events go out even when no new card arrives.
+func (s *spool) replayLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if s.pending() == 0 {
+				continue
+			}
+			if left := s.flush(deliverPayload); left > 0 {
+				slog.Info("Spool: events still waiting for WebSocket", "pending", left)
+			}
+		}
+	}
+}
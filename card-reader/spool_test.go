@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSpoolEntry puts a payload into the spool as if it had been enqueued at at
+func writeSpoolEntry(t *testing.T, s *spool, at time.Time, token string, body string) {
+	t.Helper()
+	name := fmt.Sprintf("%020d-%s.json", at.UnixNano(), token)
+	if err := os.WriteFile(filepath.Join(s.dir, name), []byte(body), 0o600); err != nil {
+		t.Fatalf("Failed to write spool entry: %v", err)
+	}
+}
+
+// TestSpoolFlushOrder tests that spooled events are replayed oldest first and stay
+// spooled from the first failed send on
+func TestSpoolFlushOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		failAt      string
+		wantSent    []string
+		wantPending int
+	}{
+		{"all delivered", "", []string{"t1", "t2", "t3"}, 0},
+		{"stops at the failed send", "t2", []string{"t1"}, 2},
+		{"oldest fails", "t1", nil, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := newSpool(t.TempDir(), time.Hour, 0)
+			if err != nil {
+				t.Fatalf("newSpool failed: %v", err)
+			}
+			for _, token := range []string{"t1", "t2", "t3"} {
+				if err := s.enqueue(Payload{Token: token}); err != nil {
+					t.Fatalf("enqueue failed: %v", err)
+				}
+			}
+
+			var sent []string
+			pending := s.flush(func(pl Payload) error {
+				if pl.Token == tt.failAt {
+					return errors.New("unreachable")
+				}
+				sent = append(sent, pl.Token)
+				return nil
+			})
+			if fmt.Sprint(sent) != fmt.Sprint(tt.wantSent) {
+				t.Errorf("Expected %v to be sent, got %v", tt.wantSent, sent)
+			}
+			if pending != tt.wantPending || s.pending() != tt.wantPending {
+				t.Errorf("Expected %d pending, flush reported %d and %d are left", tt.wantPending, pending, s.pending())
+			}
+		})
+	}
+}
+
+// TestSpoolFlushDamagedEntries tests that corrupt entries are dropped, while an entry that
+// cannot be read holds back the events after it
+func TestSpoolFlushDamagedEntries(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		damage      func(t *testing.T, s *spool)
+		wantSent    []string
+		wantPending int
+	}{
+		{
+			name: "corrupt entry is dropped",
+			damage: func(t *testing.T, s *spool) {
+				writeSpoolEntry(t, s, now.Add(-2*time.Second), "bad", "{not json")
+			},
+			wantSent:    []string{"t1", "t3"},
+			wantPending: 0,
+		},
+		{
+			name: "unreadable entry stops the replay",
+			damage: func(t *testing.T, s *spool) {
+				name := fmt.Sprintf("%020d-gone.json", now.Add(-2*time.Second).UnixNano())
+				if err := os.Symlink(filepath.Join(s.dir, "missing"), filepath.Join(s.dir, name)); err != nil {
+					t.Fatalf("Failed to create dangling entry: %v", err)
+				}
+			},
+			wantSent:    []string{"t1"},
+			wantPending: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := newSpool(t.TempDir(), time.Hour, 0)
+			if err != nil {
+				t.Fatalf("newSpool failed: %v", err)
+			}
+			writeSpoolEntry(t, s, now.Add(-3*time.Second), "t1", `{"token":"t1"}`)
+			tt.damage(t, s)
+			writeSpoolEntry(t, s, now.Add(-time.Second), "t3", `{"token":"t3"}`)
+
+			var sent []string
+			pending := s.flush(func(pl Payload) error {
+				sent = append(sent, pl.Token)
+				return nil
+			})
+			if fmt.Sprint(sent) != fmt.Sprint(tt.wantSent) {
+				t.Errorf("Expected %v to be sent, got %v", tt.wantSent, sent)
+			}
+			if pending != tt.wantPending {
+				t.Errorf("Expected %d pending, got %d", tt.wantPending, pending)
+			}
+		})
+	}
+}
+
+// TestSpoolPrune tests that expired entries and, beyond the size limit, the oldest entries are dropped
+func TestSpoolPrune(t *testing.T) {
+	now := time.Now()
+	body := `{"token":"x"}`
+	tests := []struct {
+		name      string
+		retention time.Duration
+		maxBytes  int64
+		wantKept  []string
+	}{
+		{"nothing to drop", time.Hour, 0, []string{"old", "mid", "new"}},
+		{"expired entries", 30 * time.Minute, 0, []string{"mid", "new"}},
+		{"oldest beyond the size limit", time.Hour, int64(2 * len(body)), []string{"mid", "new"}},
+		{"no retention keeps everything", 0, 0, []string{"old", "mid", "new"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := newSpool(t.TempDir(), tt.retention, tt.maxBytes)
+			if err != nil {
+				t.Fatalf("newSpool failed: %v", err)
+			}
+			writeSpoolEntry(t, s, now.Add(-45*time.Minute), "old", body)
+			writeSpoolEntry(t, s, now.Add(-10*time.Minute), "mid", body)
+			writeSpoolEntry(t, s, now.Add(-time.Minute), "new", body)
+
+			s.mu.Lock()
+			s.pruneLocked()
+			files := s.filesLocked()
+			s.mu.Unlock()
+
+			var kept []string
+			for _, f := range files {
+				kept = append(kept, f.Name()[21:len(f.Name())-len(".json")])
+			}
+			if fmt.Sprint(kept) != fmt.Sprint(tt.wantKept) {
+				t.Errorf("Expected %v to be kept, got %v", tt.wantKept, kept)
+			}
+		})
+	}
+}
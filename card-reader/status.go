@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// -----------------------------
+// Local HTTP status endpoint
+// -----------------------------
+
+// readerStatus is a snapshot of what the reader is doing, served on STATUS_ADDR
+// so kiosk front-ends and monitoring agents on the same machine can check on it
+// without going through the central API.
+type readerStatus struct {
+	mu sync.RWMutex
+
+	startedAt   time.Time
+	reader      string
+	cardPresent bool
+	lastError   string
+	lastErrorAt time.Time
+	lastRead    *lastRead
+	// card events held back because the target is unencrypted, see refuseInsecure
+	refusedInsecure int
+}
+
+// lastRead deliberately leaves out CardData: the endpoint is unauthenticated.
+type lastRead struct {
+	Token      string `json:"token"`
+	State      string `json:"state"`
+	Message    string `json:"message"`
+	Source     string `json:"source,omitempty"`
+	ATR        string `json:"atr"`
+	Protocol   string `json:"protocol"`
+	OccurredAt string `json:"occurredAt"`
+}
+
+var status = &readerStatus{startedAt: time.Now()}
+
+func (s *readerStatus) setReader(reader string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reader = reader
+}
+
+func (s *readerStatus) currentReader() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.reader
+}
+
+func (s *readerStatus) recordRead(pl Payload) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cardPresent = true
+	lr := &lastRead{
+		Token:      pl.Token,
+		State:      pl.State,
+		Message:    pl.Message,
+		ATR:        pl.ATR,
+		Protocol:   pl.Protocol,
+		OccurredAt: pl.OccurredAt,
+	}
+	if pl.CardData != nil {
+		lr.Source = pl.CardData.Source
+	}
+	s.lastRead = lr
+}
+
+func (s *readerStatus) cardRemoved() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cardPresent = false
+}
+
+func (s *readerStatus) isCardPresent() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cardPresent
+}
+
+func (s *readerStatus) recordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastError = err.Error()
+	s.lastErrorAt = time.Now()
+}
+
+func (s *readerStatus) recordRefusedInsecure(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refusedInsecure++
+	s.lastError = err.Error()
+	s.lastErrorAt = time.Now()
+}
+
+// startStatusServer serves /healthz, /status, /last-read, /pin and /metrics. Disabled (nil) when addr is empty.
+func startStatusServer(addr string) *http.Server {
+	if addr == "" {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		status.mu.RLock()
+		ok := status.reader != ""
+		status.mu.RUnlock()
+		if !ok {
+			writeStatusJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "starting"})
+			return
+		}
+		writeStatusJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		cfg := settings.get()
+		status.mu.RLock()
+		body := map[string]any{
+			"deviceId":      cfg.DeviceID,
+			"version":       version,
+			"roomId":        cfg.RoomID,
+			"tenantId":      cfg.TenantID,
+			"sectionId":     cfg.SectionID,
+			"reader":        status.reader,
+			"wsUrl":         cfg.WSURL,
+			"transport":     cfg.Transport,
+			"cardPresent":   status.cardPresent,
+			"startedAt":     status.startedAt.Format(time.RFC3339),
+			"uptimeSeconds": int64(time.Since(status.startedAt).Seconds()),
+		}
+		if status.lastRead != nil {
+			body["lastReadAt"] = status.lastRead.OccurredAt
+		}
+		if status.refusedInsecure > 0 {
+			body["refusedInsecureEvents"] = status.refusedInsecure
+		}
+		if status.lastError != "" {
+			body["lastError"] = status.lastError
+			body["lastErrorAt"] = status.lastErrorAt.Format(time.RFC3339)
+		}
+		status.mu.RUnlock()
+		writeStatusJSON(w, http.StatusOK, body)
+	})
+	mux.HandleFunc("/last-read", func(w http.ResponseWriter, r *http.Request) {
+		status.mu.RLock()
+		lr := status.lastRead
+		status.mu.RUnlock()
+		if lr == nil {
+			writeStatusJSON(w, http.StatusNotFound, map[string]string{"message": "no card read yet"})
+			return
+		}
+		writeStatusJSON(w, http.StatusOK, lr)
+	})
+
+	mux.HandleFunc("/pin", handlePIN)
+	mux.HandleFunc("/metrics", handleMetrics)
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		slog.Info("Status endpoint listening", "addr", "http://"+addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("Status endpoint stopped", "err", err)
+		}
+	}()
+	return srv
+}
+
+func writeStatusJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// -----------------------------
+// Self-update
+// -----------------------------
+
+// The API publishes one release per os/arch (PUT /admin/card-reader-releases). The
+// reader polls GET /admin/card-reader-releases/latest, downloads a newer binary next
+// to its own executable, checks the SHA-256 and the Ed25519 release signature, swaps
+// the files and restarts itself. Nothing is replaced unless the signature verifies
+// against UPDATE_PUBLIC_KEY, so a compromised API or download host can't push code.
+
+// version is set at build time: go build -ldflags "-X main.version=1.4.0".
+var version = "dev"
+
+const maxUpdateSize = 200 << 20
+
+// errRestartForUpdate is the cancel cause used once a new binary is in place.
+var errRestartForUpdate = errors.New("restarting into updated binary")
+
+// releaseManifest mirrors the API's CardReaderRelease DTO.
+type releaseManifest struct {
+	Version   string `json:"version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	URL       string `json:"url"`
+	SHA256    string `json:"sha256"`
+	Signature string `json:"signature"`
+}
+
+// releaseSigningMessage is what the release signature covers. Binding version and
+// platform stops an old or foreign-platform build from being replayed as an update.
+func releaseSigningMessage(version, goos, goarch, sha256hex string) []byte {
+	return []byte(fmt.Sprintf("waiting-room-card-reader|%s|%s/%s|%s", version, goos, goarch, strings.ToLower(sha256hex)))
+}
+
+func parseUpdatePublicKey(s string) (ed25519.PublicKey, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("not base64: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("want %d bytes, got %d", ed25519.PublicKeySize, len(raw))
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// watchUpdates checks for a new release every UpdateInterval and, once one is
+// installed, cancels the reader context with errRestartForUpdate.
+func watchUpdates(ctx context.Context, cancel context.CancelCauseFunc) {
+	cfg := settings.get()
+	if cfg.ConfigURL == "" || cfg.UpdatePublicKey == nil || cfg.UpdateInterval == 0 {
+		return
+	}
+	if version == "dev" {
+		slog.Info("Self-update disabled for development builds")
+		return
+	}
+	removeOldBinary()
+
+	// First check shortly after start, so a reader that was offline catches up quickly.
+	wait := time.Minute
+	for {
+		if err := sleepCtx(ctx, wait); err != nil {
+			return
+		}
+		wait = settings.get().UpdateInterval
+
+		installed, err := checkForUpdate(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("Update check failed", "err", err)
+			}
+			continue
+		}
+		if installed == "" {
+			continue
+		}
+
+		// Don't pull the binary from under a card that is being handled.
+		for status.isCardPresent() {
+			if err := sleepCtx(ctx, 10*time.Second); err != nil {
+				return
+			}
+		}
+		slog.Info("Restarting into updated binary", "from", version, "to", installed)
+		cancel(errRestartForUpdate)
+		return
+	}
+}
+
+// checkForUpdate installs the published release if it is newer than the running
+// binary and returns its version, or "" when already up to date.
+func checkForUpdate(ctx context.Context) (string, error) {
+	cfg := settings.get()
+	m, err := fetchReleaseManifest(ctx, cfg)
+	if err != nil || m == nil {
+		return "", err
+	}
+	if compareVersions(m.Version, version) <= 0 {
+		return "", nil
+	}
+	if m.OS != runtime.GOOS || m.Arch != runtime.GOARCH {
+		return "", fmt.Errorf("manifest is for %s/%s", m.OS, m.Arch)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return "", err
+	}
+
+	slog.Info("Downloading update", "version", m.Version, "url", m.URL)
+	tmp, err := downloadRelease(ctx, cfg, m, filepath.Dir(exe))
+	if err != nil {
+		return "", err
+	}
+	if err := swapBinary(exe, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	return m.Version, nil
+}
+
+func fetchReleaseManifest(ctx context.Context, cfg Config) (*releaseManifest, error) {
+	endpoint := fmt.Sprintf("%s/admin/card-reader-releases/latest?os=%s&arch=%s",
+		cfg.ConfigURL, url.QueryEscape(runtime.GOOS), url.QueryEscape(runtime.GOARCH))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range deviceHeaders(cfg) {
+		req.Header[k] = v
+	}
+
+	resp, err := settings.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("release endpoint returned %s", resp.Status)
+	}
+	var m releaseManifest
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&m); err != nil {
+		return nil, fmt.Errorf("decode release manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// downloadRelease writes the release into dir (same filesystem as the executable, so
+// the swap is a rename) and returns the temp path once hash and signature check out.
+func downloadRelease(ctx context.Context, cfg Config, m *releaseManifest, dir string) (string, error) {
+	sig, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil {
+		return "", fmt.Errorf("invalid release signature encoding: %w", err)
+	}
+	if !ed25519.Verify(cfg.UpdatePublicKey, releaseSigningMessage(m.Version, m.OS, m.Arch, m.SHA256), sig) {
+		return "", errors.New("release signature does not verify")
+	}
+
+	src, err := url.Parse(cfg.ConfigURL + "/")
+	if err != nil {
+		return "", err
+	}
+	if src, err = src.Parse(m.URL); err != nil {
+		return "", fmt.Errorf("invalid release url: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	// Device credentials only go to our own API, not to an external download host.
+	if strings.HasPrefix(src.String(), cfg.ConfigURL+"/") {
+		for k, v := range deviceHeaders(cfg) {
+			req.Header[k] = v
+		}
+	}
+
+	client := *settings.client
+	client.Timeout = 10 * time.Minute
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download returned %s", resp.Status)
+	}
+
+	f, err := os.CreateTemp(dir, ".card-reader-update-*")
+	if err != nil {
+		return "", err
+	}
+	tmp := f.Name()
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(resp.Body, maxUpdateSize+1))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && n > maxUpdateSize {
+		err = fmt.Errorf("release larger than %d MB", maxUpdateSize>>20)
+	}
+	if err == nil && !strings.EqualFold(hex.EncodeToString(h.Sum(nil)), m.SHA256) {
+		err = errors.New("release checksum mismatch")
+	}
+	if err == nil {
+		err = os.Chmod(tmp, 0o755)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	return tmp, nil
+}
+
+// swapBinary moves the running executable to <exe>.old and the new one into its
+// place. Windows can't overwrite a running .exe but does allow renaming it.
+func swapBinary(exe, tmp string) error {
+	old := exe + ".old"
+	_ = os.Remove(old)
+	if err := os.Rename(exe, old); err != nil {
+		return fmt.Errorf("move current binary aside: %w", err)
+	}
+	if err := os.Rename(tmp, exe); err != nil {
+		if rerr := os.Rename(old, exe); rerr != nil {
+			slog.Error("Restoring previous binary failed", "err", rerr)
+		}
+		return fmt.Errorf("install new binary: %w", err)
+	}
+	return nil
+}
+
+// removeOldBinary cleans up after a previous update, once the old process is gone.
+func removeOldBinary() {
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+	if err := os.Remove(exe + ".old"); err == nil {
+		slog.Info("Removed previous binary after update", "version", version)
+	}
+}
+
+// restartAfterUpdate re-executes the updated binary. It only returns if that failed.
+// Under the Windows service manager the recovery actions restart it instead.
+func restartAfterUpdate() {
+	exe, err := os.Executable()
+	if err != nil {
+		slog.Error("Restart after update failed", "err", err)
+		return
+	}
+	if err := restartSelf(exe); err != nil {
+		slog.Error("Restart after update failed", "err", err)
+	}
+}
+
+// compareVersions compares dotted numeric versions ("1.10.2" > "1.9"); a leading
+// "v" is ignored and a pre-release ("1.2.0-rc1") sorts before its release.
+func compareVersions(a, b string) int {
+	splitPre := func(v string) (string, string) {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+		main, pre, _ := strings.Cut(v, "-")
+		return main, pre
+	}
+	am, ap := splitPre(a)
+	bm, bp := splitPre(b)
+
+	as, bs := strings.Split(am, "."), strings.Split(bm, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case ap == bp:
+		return 0
+	case ap == "":
+		return 1
+	case bp == "":
+		return -1
+	}
+	return strings.Compare(ap, bp)
+}
@@ -0,0 +1,195 @@
+//go:build windows
+
+package main
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// -----------------------------
+// Windows: PKCS#11 discovery + CryptoAPI fallback
+// -----------------------------
+
+// windowsPKCS11Candidates lists PKCS#11 DLLs found through the registry first,
+// then the usual install locations of OpenSC and the national eID middlewares.
+func windowsPKCS11Candidates() []string {
+	sys32 := filepath.Join(envOr("SystemRoot", `C:\Windows`), "System32")
+	pf := envOr("ProgramFiles", `C:\Program Files`)
+	pf86 := envOr("ProgramFiles(x86)", `C:\Program Files (x86)`)
+
+	cands := registryPKCS11Modules()
+	cands = append(cands,
+		filepath.Join(pf, "OpenSC Project", "OpenSC", "pkcs11", "opensc-pkcs11.dll"),
+		filepath.Join(sys32, "opensc-pkcs11.dll"),
+		filepath.Join(sys32, "eopproxy11.dll"),              // eObčanka (CZ)
+		filepath.Join(pf86, "eID_klient", "pkcs11_x64.dll"), // eID klient (SK)
+		filepath.Join(sys32, "beidpkcs11.dll"),              // Belgian eID
+	)
+
+	seen := make(map[string]bool, len(cands))
+	out := cands[:0]
+	for _, c := range cands {
+		key := strings.ToLower(filepath.Clean(c))
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, c)
+	}
+	return out
+}
+
+// middlewareProducts are matched against installed programs' DisplayName.
+var middlewareProducts = []string{"opensc", "eobčanka", "eobcanka", "eid klient", "eid_klient", "eid middleware", "belgium e-id", "beid"}
+
+// registryPKCS11Modules looks for PKCS#11 DLLs of installed eID middlewares. OpenSC
+// records its install directory; for the others we search the InstallLocation of
+// their uninstall entry. Both registry views are checked since 32-bit installers
+// write to WOW6432Node.
+func registryPKCS11Modules() []string {
+	var out []string
+	for _, view := range []uint32{registry.WOW64_64KEY, registry.WOW64_32KEY} {
+		if k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\OpenSC Project\OpenSC`, registry.QUERY_VALUE|view); err == nil {
+			if dir, _, err := k.GetStringValue("InstallDir"); err == nil && dir != "" {
+				out = append(out, filepath.Join(dir, "pkcs11", "opensc-pkcs11.dll"))
+			}
+			k.Close()
+		}
+
+		uninstall, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`, registry.ENUMERATE_SUB_KEYS|view)
+		if err != nil {
+			continue
+		}
+		names, _ := uninstall.ReadSubKeyNames(-1)
+		for _, name := range names {
+			k, err := registry.OpenKey(uninstall, name, registry.QUERY_VALUE)
+			if err != nil {
+				continue
+			}
+			display, _, _ := k.GetStringValue("DisplayName")
+			location, _, _ := k.GetStringValue("InstallLocation")
+			k.Close()
+			if location == "" || !isMiddlewareProduct(display) {
+				continue
+			}
+			out = append(out, findPKCS11DLLs(location)...)
+		}
+		uninstall.Close()
+	}
+	return out
+}
+
+func isMiddlewareProduct(displayName string) bool {
+	d := strings.ToLower(displayName)
+	for _, p := range middlewareProducts {
+		if strings.Contains(d, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// findPKCS11DLLs globs dir and its direct subdirectories for *pkcs11*.dll / *p11*.dll.
+func findPKCS11DLLs(dir string) []string {
+	var out []string
+	for _, pattern := range []string{"*pkcs11*.dll", "*p11*.dll", `*\*pkcs11*.dll`, `*\*p11*.dll`} {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		out = append(out, matches...)
+	}
+	return out
+}
+
+// CryptoAPI / minidriver fallback. Cards without a PKCS#11 module usually ship a
+// Windows smart card minidriver; the Base Smart Card CSP exposes their certificate
+// through the reader-qualified default container "\\.\<reader>\". Only RSA key
+// containers are reachable this way (ECC keys live behind the CNG KSP).
+
+const (
+	msSmartCardProv = "Microsoft Base Smart Card Crypto Provider"
+	atKeyExchange   = 1
+	atSignature     = 2
+	kpCertificate   = 26
+)
+
+var (
+	modAdvapi32          = windows.NewLazySystemDLL("advapi32.dll")
+	procCryptGetUserKey  = modAdvapi32.NewProc("CryptGetUserKey")
+	procCryptGetKeyParam = modAdvapi32.NewProc("CryptGetKeyParam")
+	procCryptDestroyKey  = modAdvapi32.NewProc("CryptDestroyKey")
+)
+
+// readCertSubjectCryptoAPI reads the certificate of the card in reader via its minidriver.
+func readCertSubjectCryptoAPI(reader string) (CertInfo, bool) {
+	container, err := windows.UTF16PtrFromString(`\\.\` + reader + `\`)
+	if err != nil {
+		return CertInfo{}, false
+	}
+	provider, _ := windows.UTF16PtrFromString(msSmartCardProv)
+
+	var prov windows.Handle
+	if err := windows.CryptAcquireContext(&prov, container, provider, windows.PROV_RSA_FULL, windows.CRYPT_SILENT); err != nil {
+		slog.Debug("CryptoAPI: no minidriver container", "err", err)
+		return CertInfo{}, false
+	}
+	defer windows.CryptReleaseContext(prov, 0)
+
+	for _, spec := range []uintptr{atKeyExchange, atSignature} {
+		der, err := containerCertificate(prov, spec)
+		if err != nil {
+			continue
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			slog.Warn("CryptoAPI: certificate not parseable", "err", err)
+			continue
+		}
+		return CertInfo{Label: fmt.Sprintf("cryptoapi-%d", spec), Subject: cert.Subject, RawDER: der}, true
+	}
+	slog.Debug("CryptoAPI: no certificate in default container")
+	return CertInfo{}, false
+}
+
+// containerCertificate returns KP_CERTIFICATE of the container's key pair.
+func containerCertificate(prov windows.Handle, keySpec uintptr) ([]byte, error) {
+	var key uintptr
+	if r, _, err := procCryptGetUserKey.Call(uintptr(prov), keySpec, uintptr(unsafe.Pointer(&key))); r == 0 {
+		return nil, fmt.Errorf("CryptGetUserKey: %w", err)
+	}
+	defer procCryptDestroyKey.Call(key)
+
+	var size uint32
+	if r, _, err := procCryptGetKeyParam.Call(key, kpCertificate, 0, uintptr(unsafe.Pointer(&size)), 0); r == 0 {
+		return nil, fmt.Errorf("CryptGetKeyParam: %w", err)
+	}
+	if size == 0 {
+		return nil, errors.New("empty certificate")
+	}
+	buf := make([]byte, size)
+	if r, _, err := procCryptGetKeyParam.Call(key, kpCertificate, uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size)), 0); r == 0 {
+		return nil, fmt.Errorf("CryptGetKeyParam: %w", err)
+	}
+	return buf[:size], nil
+}
+
+// restartSelf starts the updated binary with the same arguments and exits. Windows
+// has no exec(), so the new process gets a new PID.
+func restartSelf(exe string) error {
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	os.Exit(0)
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+func windowsPKCS11Candidates() []string { return nil }
+
+// readCertSubjectCryptoAPI is only available on Windows.
+func readCertSubjectCryptoAPI(reader string) (CertInfo, bool) { return CertInfo{}, false }
+
+// restartSelf replaces the process image, keeping PID, arguments and environment,
+// so supervisors (systemd, Docker) don't see the reader exit.
+func restartSelf(exe string) error {
+	return syscall.Exec(exe, os.Args, os.Environ())
+}
@@ -10,16 +10,24 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/arfis/waiting-room/nghis-adapter/internal/cache"
 	"github.com/arfis/waiting-room/nghis-adapter/internal/client"
 	"github.com/arfis/waiting-room/nghis-adapter/internal/config/service"
 	nghisContext "github.com/arfis/waiting-room/nghis-adapter/internal/context"
 	ngErrors "github.com/arfis/waiting-room/nghis-adapter/internal/errors"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/hl7"
 	"github.com/arfis/waiting-room/nghis-adapter/internal/logger"
 	"github.com/arfis/waiting-room/nghis-adapter/internal/middleware"
 	"github.com/arfis/waiting-room/nghis-adapter/internal/rest"
 	appointmentRest "github.com/arfis/waiting-room/nghis-adapter/internal/rest/handler/appointment"
+	cacheRest "github.com/arfis/waiting-room/nghis-adapter/internal/rest/handler/cache"
+	personRest "github.com/arfis/waiting-room/nghis-adapter/internal/rest/handler/person"
 	svcRest "github.com/arfis/waiting-room/nghis-adapter/internal/rest/handler/services"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/service/adt"
 	"github.com/arfis/waiting-room/nghis-adapter/internal/service/appointment"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/service/appointmentsync"
+	cacheService "github.com/arfis/waiting-room/nghis-adapter/internal/service/cache"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/service/person"
 	svcService "github.com/arfis/waiting-room/nghis-adapter/internal/service/services"
 	"go.uber.org/dig"
 )
@@ -44,17 +52,34 @@ func DIContainer() *dig.Container {
 		{Constructor: middleware.NewPagingMiddleware},
 		{Constructor: ngErrors.NewResponseErrorHandler},
 
+		{Constructor: client.NewTokenSource},
 		{Constructor: client.NewHTTPClient},
 		{Constructor: client.NewClinicalClient},
 		{Constructor: client.NewPersonClient},
+		{Constructor: client.NewWaitingRoomClient},
+
+		{Constructor: cache.NewCache},
 
 		{Constructor: rest.NewServer},
 
 		{Constructor: appointmentRest.New},
 		{Constructor: appointment.NewService},
 
+		{Constructor: personRest.New},
+		{Constructor: person.NewService},
+
 		{Constructor: svcRest.New},
 		{Constructor: svcService.NewService},
+
+		{Constructor: cacheRest.New},
+		{Constructor: cacheService.NewService},
+
+		{Constructor: adt.NewService},
+		{Constructor: func(logger *slog.Logger, adtService *adt.Service) *hl7.Listener {
+			return hl7.NewListener(logger, adtService)
+		}},
+
+		{Constructor: appointmentsync.NewService},
 	}
 
 	container := dig.New()
@@ -85,9 +110,12 @@ func main() {
 	err := diContainer.Invoke(func(
 		logger *slog.Logger,
 		configuration *service.Configuration,
+		ctx context.Context,
 		cancelFunc context.CancelFunc,
 		server *http.Server,
 		httpClient *http.Client,
+		mllpListener *hl7.Listener,
+		appointmentSync *appointmentsync.Service,
 	) error {
 		go func() {
 			if err := server.ListenAndServe(); err != nil && errors.Is(err, http.ErrServerClosed) {
@@ -95,10 +123,27 @@ func main() {
 			}
 		}()
 
+		// The HIS pushes ADT messages over MLLP when a port is configured
+		if configuration.MLLPPort != "" {
+			go func() {
+				if err := mllpListener.ListenAndServe(ctx, ":"+configuration.MLLPPort); err != nil {
+					logger.Error("HL7 listener stopped", "port", configuration.MLLPPort, "err", err)
+				}
+			}()
+			logger.Info("HL7 listener running", "port", configuration.MLLPPort)
+		}
+
+		// Today's appointments of the configured departments are pushed to the waiting-room API
+		if appointmentSync.Enabled() {
+			go appointmentSync.Run(ctx)
+			logger.Info("appointment sync running", "orgUnits", configuration.AppointmentSyncOrgUnits,
+				"interval", configuration.AppointmentSyncInterval)
+		}
+
 		logger.Info("running", "service", configuration.ServiceName, "instance", configuration.ServiceInstanceName,
 			"port", configuration.ServerPort, "context", configuration.ServerContext)
 
-		waitAndGracefullyStop(logger, make(chan os.Signal, 1), cancelFunc, server, httpClient)
+		waitAndGracefullyStop(logger, make(chan os.Signal, 1), cancelFunc, server, httpClient, mllpListener)
 		return nil
 	})
 	if err != nil {
@@ -111,6 +156,7 @@ func waitAndGracefullyStop(logger *slog.Logger,
 	cancelFunc context.CancelFunc,
 	server *http.Server,
 	httpClient *http.Client,
+	mllpListener *hl7.Listener,
 ) {
 	signal.Notify(signalChan, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 	<-signalChan
@@ -126,4 +172,5 @@ func waitAndGracefullyStop(logger *slog.Logger,
 	}
 
 	httpClient.CloseIdleConnections()
+	mllpListener.Close()
 }
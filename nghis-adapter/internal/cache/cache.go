@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/nghis-adapter/internal/config/service"
+	appCtx "github.com/arfis/waiting-room/nghis-adapter/internal/context"
+)
+
+// Namespaces of the cached NGHIS data
+const (
+	NamespaceServices = "services" // Kiosk bookable services per org units
+	NamespacePatients = "patients" // Patients per national ID
+	NamespacePersons  = "persons"  // Demographics with priority symbols per national ID
+)
+
+// Namespaces lists the namespaces entries can be invalidated in
+var Namespaces = []string{NamespacePatients, NamespacePersons, NamespaceServices}
+
+// keyPrefix prefixes the keys of all entries, so a Redis database can be shared
+const keyPrefix = "nghis-adapter:cache:"
+
+// Store keeps the encoded entries until they expire
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// DeletePrefix removes the entries whose key starts with the prefix and returns their number
+	DeletePrefix(ctx context.Context, prefix string) (int64, error)
+}
+
+type cacheControl string
+
+const cacheControlKey cacheControl = "CACHE_CONTROL"
+
+// Cache keeps NGHIS data that changes slowly, so the kiosk doesn't call NGHIS again on every
+// swipe. Entries are kept per tenant, requests without a tenant always load.
+type Cache struct {
+	logger *slog.Logger
+	store  Store
+	ttls   map[string]time.Duration
+}
+
+// NewCache prepares the cache in Redis when CACHE_REDIS_URL is set, in memory otherwise
+func NewCache(configuration *service.Configuration, logger *slog.Logger) (*Cache, error) {
+	var store Store = NewMemoryStore()
+	if configuration.CacheRedisURL != "" {
+		redisStore, err := NewRedisStore(configuration.CacheRedisURL)
+		if err != nil {
+			return nil, err
+		}
+		store = redisStore
+	}
+	return &Cache{
+		logger: logger,
+		store:  store,
+		ttls: map[string]time.Duration{
+			NamespacePatients: configuration.CachePatientsTTL,
+			NamespacePersons:  configuration.CachePatientsTTL,
+			NamespaceServices: configuration.CacheServicesTTL,
+		},
+	}, nil
+}
+
+// Load returns the cached value of a key in a namespace, or loads it and caches it for the TTL of
+// the namespace. Failed loads are not cached and cache errors only skip the cache.
+func Load[T any](ctx context.Context, c *Cache, namespace, key string, load func(ctx context.Context) (T, error)) (T, error) {
+	tenantKey, ok := c.key(ctx, namespace, key)
+	ttl := c.ttls[namespace]
+	if !ok || ttl <= 0 {
+		return load(ctx)
+	}
+
+	control, _ := ctx.Value(cacheControlKey).(string)
+	if control == "" {
+		if encoded, found, err := c.store.Get(ctx, tenantKey); err != nil {
+			c.logger.Warn("unable to read cache", "namespace", namespace, "err", err)
+		} else if found {
+			var value T
+			if err := json.Unmarshal(encoded, &value); err == nil {
+				return value, nil
+			}
+			c.logger.Warn("dropping undecodable cache entry", "namespace", namespace, "err", err)
+		}
+	}
+
+	value, err := load(ctx)
+	if err != nil || control == "no-store" {
+		return value, err
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		c.logger.Warn("unable to encode cache entry", "namespace", namespace, "err", err)
+		return value, nil
+	}
+	if err := c.store.Set(ctx, tenantKey, encoded, ttl); err != nil {
+		c.logger.Warn("unable to write cache", "namespace", namespace, "err", err)
+	}
+	return value, nil
+}
+
+// Invalidate removes the entries of the tenant of the context in a namespace, or in all of them
+// when the namespace is empty
+func (c *Cache) Invalidate(ctx context.Context, namespace string) (int64, error) {
+	prefix, ok := c.key(ctx, namespace, "")
+	if !ok {
+		return 0, fmt.Errorf("missing tenant")
+	}
+	return c.store.DeletePrefix(ctx, prefix)
+}
+
+// key returns the key of an entry of the tenant of the context
+func (c *Cache) key(ctx context.Context, namespace, key string) (string, bool) {
+	var tenant string
+	switch tenantID := ctx.Value(appCtx.TENANT).(type) {
+	case string:
+		tenant = tenantID
+	case int:
+		tenant = strconv.Itoa(tenantID)
+	case int32:
+		tenant = strconv.Itoa(int(tenantID))
+	}
+	if tenant == "" {
+		return "", false
+	}
+	if namespace == "" {
+		return keyPrefix + tenant + ":", true
+	}
+	return keyPrefix + tenant + ":" + namespace + ":" + key, true
+}
+
+// Middleware honors the Cache-Control header of requests: no-cache and max-age=0 load the data
+// from NGHIS and cache it again, no-store loads it without caching it
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		control := ""
+		for directive := range strings.SplitSeq(strings.ToLower(r.Header.Get("Cache-Control")), ",") {
+			switch strings.TrimSpace(directive) {
+			case "no-store":
+				control = "no-store"
+			case "no-cache", "max-age=0":
+				if control == "" {
+					control = "no-cache"
+				}
+			}
+		}
+		if control != "" {
+			r = r.WithContext(context.WithValue(r.Context(), cacheControlKey, control))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the least time between removals of the expired entries of a memory store
+const sweepInterval = time.Minute
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryStore keeps the entries in the memory of the instance
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]memoryEntry{}}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, entry := range s.entries {
+			if now.After(entry.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	s.entries[key] = memoryEntry{value: value, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) DeletePrefix(_ context.Context, prefix string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var removed int64
+	for key := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.entries, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// scanCount is the number of keys asked for per SCAN when invalidating
+const scanCount = 500
+
+// RedisStore keeps the entries in Redis, so all instances of the adapter share them
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore connects to the Redis server at url (redis://[user:password@]host:port/db)
+func NewRedisStore(url string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to ping Redis: %w", err)
+	}
+	return &RedisStore{client: client}, nil
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (s *RedisStore) DeletePrefix(ctx context.Context, prefix string) (int64, error) {
+	var removed int64
+	iter := s.client.Scan(ctx, 0, prefix+"*", scanCount).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == scanCount {
+			deleted, err := s.client.Del(ctx, keys...).Result()
+			if err != nil {
+				return removed, err
+			}
+			removed += deleted
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return removed, err
+	}
+	if len(keys) > 0 {
+		deleted, err := s.client.Del(ctx, keys...).Result()
+		if err != nil {
+			return removed, err
+		}
+		removed += deleted
+	}
+	return removed, nil
+}
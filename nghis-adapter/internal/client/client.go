@@ -3,7 +3,6 @@ package client
 import (
 	"log/slog"
 	"net/http"
-	"time"
 
 	"github.com/arfis/waiting-room/nghis-adapter/internal/config/service"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
@@ -13,13 +12,22 @@ import (
 func NewHTTPClient(
 	configuration *service.Configuration,
 	logger *slog.Logger,
+	tokenSource *TokenSource,
 ) *http.Client {
 	return &http.Client{
 		Transport: AuthorizationTransport(
-			LoggerTransport(
-				otelhttp.NewTransport(&http.Transport{}, otelhttp.WithSpanNameFormatter(spanNameFormatter)),
-				logger,
+			OAuth2Transport(
+				ResilienceTransport(
+					LoggerTransport(
+						otelhttp.NewTransport(&http.Transport{}, otelhttp.WithSpanNameFormatter(spanNameFormatter)),
+						logger,
+					),
+					configuration,
+					logger,
+				),
+				tokenSource,
 			)),
-		Timeout: time.Second * configuration.HTTPClientTimeout,
+		// Attempts are limited to HTTPClientTimeout, the request with its retries to the budget
+		Timeout: configuration.HTTPClientTimeoutBudget,
 	}
 }
@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arfis/waiting-room/nghis-adapter/internal/config/service"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/middleware"
+)
+
+// tokenExpiryMargin refreshes tokens this long before they expire, so requests never carry a
+// token that expires on the way
+const tokenExpiryMargin = 30 * time.Second
+
+// defaultTokenLifetime is assumed for tokens issued without expires_in
+const defaultTokenLifetime = time.Minute
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// TokenSource obtains access tokens with the OAuth2 client credentials grant and keeps the current
+// one until shortly before it expires
+type TokenSource struct {
+	logger       *slog.Logger
+	httpClient   *http.Client
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewTokenSource prepares the token source of the client credentials in the configuration; it is
+// disabled without a client ID. The token URL is OAUTH2_TOKEN_URL, or the one of the environment
+// in OAUTH2_TOKEN_URLS.
+func NewTokenSource(configuration *service.Configuration, logger *slog.Logger) (*TokenSource, error) {
+	source := &TokenSource{
+		logger:       logger,
+		httpClient:   &http.Client{Timeout: configuration.HTTPClientTimeout},
+		clientID:     configuration.OAuth2ClientID,
+		clientSecret: configuration.OAuth2ClientSecret,
+		scopes:       configuration.OAuth2Scopes,
+	}
+	if source.clientID == "" {
+		return source, nil
+	}
+
+	source.tokenURL = configuration.OAuth2TokenURL
+	if source.tokenURL == "" {
+		source.tokenURL = configuration.OAuth2TokenURLs[configuration.Environment]
+	}
+	if source.tokenURL == "" {
+		return nil, fmt.Errorf("missing OAuth2 token URL of environment %q", configuration.Environment)
+	}
+	return source, nil
+}
+
+// Enabled reports whether client credentials are configured
+func (s *TokenSource) Enabled() bool {
+	return s.clientID != ""
+}
+
+// Token returns the current access token, obtaining a new one when it is about to expire
+func (s *TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Now().Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	token, err := s.requestToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	lifetime := time.Duration(token.ExpiresIn) * time.Second
+	if lifetime <= 0 {
+		lifetime = defaultTokenLifetime
+	}
+	s.token = token.AccessToken
+	s.expiresAt = time.Now().Add(lifetime - min(tokenExpiryMargin, lifetime/2))
+	return s.token, nil
+}
+
+// Invalidate drops the current token after a service rejected it
+func (s *TokenSource) Invalidate() {
+	s.mu.Lock()
+	s.token = ""
+	s.mu.Unlock()
+}
+
+func (s *TokenSource) requestToken(ctx context.Context) (*tokenResponse, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(s.scopes) > 0 {
+		form.Set("scope", strings.Join(s.scopes, " "))
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	request.SetBasicAuth(url.QueryEscape(s.clientID), url.QueryEscape(s.clientSecret))
+
+	response, err := s.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("unable to request OAuth2 token: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read OAuth2 token: %w", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		s.logger.Error("token endpoint rejected client credentials", "status", response.StatusCode, "body", string(body))
+		return nil, fmt.Errorf("token endpoint responded with status %d", response.StatusCode)
+	}
+
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("unable to decode OAuth2 token: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("token endpoint responded without access token")
+	}
+	if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+		return nil, fmt.Errorf("unsupported OAuth2 token type %q", token.TokenType)
+	}
+	return &token, nil
+}
+
+type oauth2RoundTripper struct {
+	rt     http.RoundTripper
+	source *TokenSource
+}
+
+func (t oauth2RoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	if !t.source.Enabled() || request.Header.Get(middleware.AUTH_HEADER) != "" {
+		return t.rt.RoundTrip(request)
+	}
+
+	token, err := t.source.Token(request.Context())
+	if err != nil {
+		return nil, err
+	}
+	authorized := request.Clone(request.Context())
+	authorized.Header.Set(middleware.AUTH_HEADER, middleware.Bearer+token)
+
+	response, err := t.rt.RoundTrip(authorized)
+	if err == nil && response.StatusCode == http.StatusUnauthorized {
+		// A revoked token is replaced on the next request
+		t.source.Invalidate()
+	}
+	return response, err
+}
+
+// OAuth2Transport sends requests with the bearer token of the token source when it is enabled
+func OAuth2Transport(transport http.RoundTripper, source *TokenSource) http.RoundTripper {
+	return oauth2RoundTripper{rt: transport, source: source}
+}
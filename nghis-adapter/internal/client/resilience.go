@@ -0,0 +1,298 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/arfis/waiting-room/nghis-adapter/internal/config/service"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ErrCircuitOpen is returned without calling a host after its consecutive failures opened its
+// circuit; the next request after the cooldown probes the host again
+var ErrCircuitOpen = errors.New("circuit open")
+
+const meterName = "github.com/arfis/waiting-room/nghis-adapter/internal/client"
+
+// Failure reasons of the failure metric
+const (
+	failureError       = "error"
+	failureStatus      = "status"
+	failureCircuitOpen = "circuit_open"
+)
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreaker tracks the consecutive failures of one host
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     circuitState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+}
+
+// allow reports whether a request may be sent; once the cooldown passed a single probe is let
+// through
+func (b *circuitBreaker) allow(now time.Time) bool {
+	if b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if now.Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = circuitHalfOpen
+		b.probing = true
+		return true
+	case circuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record counts the outcome of a request and reports whether it opened the circuit
+func (b *circuitBreaker) record(success bool, now time.Time) bool {
+	if b.threshold <= 0 {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if success {
+		b.state = circuitClosed
+		b.failures = 0
+		return false
+	}
+	b.failures++
+	if b.state == circuitHalfOpen || b.failures >= b.threshold {
+		b.state = circuitOpen
+		b.openedAt = now
+		return true
+	}
+	return false
+}
+
+// release frees the probe of a request the caller abandoned
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
+
+type resilienceRoundTripper struct {
+	rt              http.RoundTripper
+	logger          *slog.Logger
+	attemptTimeout  time.Duration
+	retries         int
+	backoff         time.Duration
+	breakerFailures int
+	breakerCooldown time.Duration
+
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+
+	failureCounter metric.Int64Counter
+	retryCounter   metric.Int64Counter
+}
+
+func (t *resilienceRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	ctx := request.Context()
+	host := request.URL.Host
+	breaker := t.breaker(host)
+
+	attempts := 1
+	if retryable(request) {
+		attempts += t.retries
+	}
+
+	var response *http.Response
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if !wait(ctx, t.backoff<<(attempt-1)) {
+				break
+			}
+			closeBody(response)
+			t.retryCounter.Add(ctx, 1, metric.WithAttributes(attribute.String("host", host)))
+		}
+
+		if !breaker.allow(time.Now()) {
+			closeBody(response)
+			t.failureCounter.Add(ctx, 1, metric.WithAttributes(attribute.String("host", host), attribute.String("reason", failureCircuitOpen)))
+			return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, host)
+		}
+
+		response, err = t.attempt(request, attempt)
+		if ctx.Err() != nil {
+			// The caller gave up, which says nothing about the host
+			breaker.release()
+			return response, err
+		}
+
+		reason := ""
+		if err != nil {
+			reason = failureError
+		} else if response.StatusCode >= http.StatusInternalServerError {
+			reason = failureStatus
+		}
+		if breaker.record(reason == "", time.Now()) {
+			t.logger.Warn("circuit opened", "host", host, "cooldown", t.breakerCooldown)
+		}
+		if reason == "" {
+			return response, nil
+		}
+		t.failureCounter.Add(ctx, 1, metric.WithAttributes(attribute.String("host", host), attribute.String("reason", reason)))
+
+		if err == nil && !retryableStatus(response.StatusCode) {
+			break
+		}
+	}
+	return response, err
+}
+
+// attempt sends a request once within the attempt timeout; the timeout is released with the body
+func (t *resilienceRoundTripper) attempt(request *http.Request, attempt int) (*http.Response, error) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if t.attemptTimeout > 0 {
+		ctx, cancel = context.WithTimeout(request.Context(), t.attemptTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(request.Context())
+	}
+
+	attemptRequest := request.Clone(ctx)
+	if attempt > 0 && request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		attemptRequest.Body = body
+	}
+
+	response, err := t.rt.RoundTrip(attemptRequest)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	response.Body = &cancelOnClose{ReadCloser: response.Body, cancel: cancel}
+	return response, nil
+}
+
+func (t *resilienceRoundTripper) breaker(host string) *circuitBreaker {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	breaker, ok := t.breakers[host]
+	if !ok {
+		breaker = &circuitBreaker{threshold: t.breakerFailures, cooldown: t.breakerCooldown}
+		t.breakers[host] = breaker
+	}
+	return breaker
+}
+
+// retryable reports whether a request may be sent again: idempotent methods whose body can be
+// read again
+func retryable(request *http.Request) bool {
+	switch request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return request.Body == nil || request.Body == http.NoBody || request.GetBody != nil
+	default:
+		return false
+	}
+}
+
+// retryableStatus reports whether a status is worth another attempt
+func retryableStatus(status int) bool {
+	return status == http.StatusBadGateway || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
+
+// wait sleeps for the backoff unless the context is done first
+func wait(ctx context.Context, backoff time.Duration) bool {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// closeBody discards the response of a failed attempt before the next one
+func closeBody(response *http.Response) {
+	if response == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, response.Body)
+	_ = response.Body.Close()
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// ResilienceTransport retries failed idempotent requests with exponential backoff, limits every
+// attempt to the client timeout and stops calling hosts that keep failing until their cooldown
+// passes. Failures and retries are counted per host in the nghis_client.failures and
+// nghis_client.retries metrics.
+func ResilienceTransport(transport http.RoundTripper, configuration *service.Configuration, logger *slog.Logger) http.RoundTripper {
+	meter := otel.Meter(meterName)
+	failureCounter, err := meter.Int64Counter("nghis_client.failures",
+		metric.WithDescription("Failed requests to the NGHIS services by host and reason"))
+	if err != nil {
+		logger.Warn("unable to create failure metric", "err", err)
+	}
+	retryCounter, err := meter.Int64Counter("nghis_client.retries",
+		metric.WithDescription("Retried requests to the NGHIS services by host"))
+	if err != nil {
+		logger.Warn("unable to create retry metric", "err", err)
+	}
+
+	return &resilienceRoundTripper{
+		rt:              transport,
+		logger:          logger,
+		attemptTimeout:  configuration.HTTPClientTimeout,
+		retries:         max(configuration.HTTPClientRetries, 0),
+		backoff:         configuration.HTTPClientRetryBackoff,
+		breakerFailures: configuration.HTTPClientBreakerFailures,
+		breakerCooldown: configuration.HTTPClientBreakerCooldown,
+		breakers:        map[string]*circuitBreaker{},
+		failureCounter:  failureCounter,
+		retryCounter:    retryCounter,
+	}
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/nghis-adapter/internal/config/service"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/middleware"
+)
+
+// Event types of the integration endpoint of the waiting-room API
+const (
+	EventPreRegister = "PRE_REGISTER"
+	EventArrived     = "ARRIVED"
+)
+
+// WaitingRoomAppointment is an appointment as the waiting-room API imports it
+type WaitingRoomAppointment struct {
+	AppointmentTime time.Time `json:"appointmentTime"`
+	ExternalID      string    `json:"externalId"`
+	Identifier      string    `json:"identifier"`
+	PatientName     string    `json:"patientName,omitempty"`
+	RoomID          string    `json:"roomId,omitempty"`
+	ServiceDuration int64     `json:"serviceDuration,omitempty"` // Minutes
+	ServiceID       string    `json:"serviceId,omitempty"`
+	ServiceName     string    `json:"serviceName,omitempty"`
+}
+
+// WaitingRoomEvent is an event for the integration endpoint of the waiting-room API
+type WaitingRoomEvent struct {
+	Type        string                  `json:"type"`
+	Appointment *WaitingRoomAppointment `json:"appointment,omitempty"`
+	ExternalID  string                  `json:"externalId,omitempty"`
+}
+
+// WaitingRoomClient sends events to the integration endpoint of the waiting-room API, signed with
+// the credentials issued to the tenant
+type WaitingRoomClient struct {
+	logger        *slog.Logger
+	httpClient    *http.Client
+	eventsURL     string
+	tenantID      string
+	apiKey        string
+	signingSecret string
+}
+
+// NewWaitingRoomClient prepares client for the waiting-room API
+func NewWaitingRoomClient(configuration *service.Configuration, logger *slog.Logger) *WaitingRoomClient {
+	return &WaitingRoomClient{
+		logger:        logger,
+		httpClient:    &http.Client{Timeout: configuration.HTTPClientTimeout},
+		eventsURL:     strings.TrimSuffix(configuration.WaitingRoomAPIURL, "/") + "/integrations/events",
+		tenantID:      configuration.WaitingRoomTenantID,
+		apiKey:        configuration.WaitingRoomAPIKey,
+		signingSecret: configuration.WaitingRoomSigningSecret,
+	}
+}
+
+// SendEvent posts an event to the waiting-room API; X-Webhook-Signature is "sha256=" and the hex
+// HMAC-SHA256 of the timestamp, a dot and the body
+func (c *WaitingRoomClient) SendEvent(ctx context.Context, event WaitingRoomEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(c.signingSecret))
+	mac.Write([]byte(timestamp + "." + string(body)))
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.eventsURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(middleware.TENANT_HEADER, c.tenantID)
+	request.Header.Set("X-API-Key", c.apiKey)
+	request.Header.Set("X-Webhook-Timestamp", timestamp)
+	request.Header.Set("X-Webhook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("unable to send %s event: %w", event.Type, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusMultipleChoices {
+		responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		c.logger.Error("waiting-room API rejected event", "type", event.Type, "status", response.StatusCode, "body", string(responseBody))
+		return fmt.Errorf("waiting-room API rejected %s event with status %d", event.Type, response.StatusCode)
+	}
+	return nil
+}
@@ -16,7 +16,26 @@ type Configuration struct {
 	ContextFields []string `env:"CONTEXT_FIELDS,omitempty"`
 
 	// HTTP client
-	HTTPClientTimeout time.Duration `env:"HTTP_CLIENT_TIMEOUT" env-default:"2s"`
+	HTTPClientTimeout         time.Duration `env:"HTTP_CLIENT_TIMEOUT" env-default:"2s"`          // Timeout of one attempt
+	HTTPClientTimeoutBudget   time.Duration `env:"HTTP_CLIENT_TIMEOUT_BUDGET" env-default:"5s"`   // Timeout of a request with its retries
+	HTTPClientRetries         int           `env:"HTTP_CLIENT_RETRIES" env-default:"2"`           // Retries of idempotent requests
+	HTTPClientRetryBackoff    time.Duration `env:"HTTP_CLIENT_RETRY_BACKOFF" env-default:"100ms"` // Doubled with every retry
+	HTTPClientBreakerFailures int           `env:"HTTP_CLIENT_BREAKER_FAILURES" env-default:"5"`  // Consecutive failures opening the circuit of a host
+	HTTPClientBreakerCooldown time.Duration `env:"HTTP_CLIENT_BREAKER_COOLDOWN" env-default:"30s"`
+
+	// cache of slowly changing NGHIS data, in memory without a Redis URL; a zero TTL disables it
+	CacheRedisURL    string        `env:"CACHE_REDIS_URL"` // e.g. "redis://localhost:6379/0"
+	CacheServicesTTL time.Duration `env:"CACHE_SERVICES_TTL" env-default:"10m"`
+	CachePatientsTTL time.Duration `env:"CACHE_PATIENTS_TTL" env-default:"1m"`
+
+	// OAuth2 client credentials the NGHIS services are called with, disabled without a client ID;
+	// the token URL of the environment is used unless OAUTH2_TOKEN_URL is set
+	Environment        string            `env:"ENVIRONMENT" env-default:"dev"`
+	OAuth2ClientID     string            `env:"OAUTH2_CLIENT_ID"`
+	OAuth2ClientSecret string            `env:"OAUTH2_CLIENT_SECRET"`
+	OAuth2Scopes       []string          `env:"OAUTH2_SCOPES"`
+	OAuth2TokenURL     string            `env:"OAUTH2_TOKEN_URL"`
+	OAuth2TokenURLs    map[string]string `env:"OAUTH2_TOKEN_URLS"` // Token URL per environment, e.g. "dev:https://...,prod:https://..."
 
 	// clinical client
 	ClinicalClientHost    string `env:"CLINICAL_CLIENT_HOST" env-default:"api.dev.nghis.prosoftke.sk"`
@@ -28,6 +47,28 @@ type Configuration struct {
 	PersonClientScheme  string `env:"PERSON_CLIENT_SCHEME" env-default:"https"`
 	PersonClientContext string `env:"PERSON_CLIENT_CONTEXT" env-default:"person"`
 
+	// waiting-room API, which gets the events of the HIS on its integration endpoint
+	WaitingRoomAPIURL        string `env:"WAITING_ROOM_API_URL" env-default:"http://localhost:8080/api"`
+	WaitingRoomTenantID      string `env:"WAITING_ROOM_TENANT_ID"`
+	WaitingRoomAPIKey        string `env:"WAITING_ROOM_API_KEY"`
+	WaitingRoomSigningSecret string `env:"WAITING_ROOM_SIGNING_SECRET"`
+
+	// HL7 v2 listener, disabled without a port
+	MLLPPort       string `env:"MLLP_PORT"`
+	ADTDefaultRoom string `env:"ADT_DEFAULT_ROOM"` // Room of visits whose PV1-3 has no point of care
+
+	// appointment sync, which pushes the planned care items of the departments to the waiting-room
+	// API; disabled without departments
+	AppointmentSyncOrgUnits []string          `env:"APPOINTMENT_SYNC_ORG_UNITS"` // Org unit codes of the departments
+	AppointmentSyncInterval time.Duration     `env:"APPOINTMENT_SYNC_INTERVAL" env-default:"5m"`
+	AppointmentSyncTenantID int32             `env:"APPOINTMENT_SYNC_TENANT_ID"` // NGHIS tenant of the departments
+	AppointmentSyncToken    string            `env:"APPOINTMENT_SYNC_TOKEN"`     // Token the NGHIS services are called with
+	OrgUnitRooms            map[string]string `env:"ORG_UNIT_ROOMS"`             // Waiting room per org unit code, e.g. "CARD:cardiology,RAD:radiology"
+	ServiceCodes            map[string]string `env:"SERVICE_CODES"`              // Waiting-room service per NGHIS service code; the code itself when missing
+
+	// person demographics, whose active alerts with a mapped code give the priority symbols
+	PersonAlertSymbols map[string]string `env:"PERSON_ALERT_SYMBOLS" env-default:"VIP:VIP,IMMOBILE:IMMOBILE"` // Priority symbol per NGHIS alert type code
+
 	// server
 	ServerPort    string        `env:"APP_PORT" env-default:"8060"`
 	ServerContext string        `env:"APP_CONTEXT" env-default:"/nghis-adapter"`
@@ -0,0 +1,11 @@
+// Code generated by go generate; DO NOT EDIT.
+
+package dto
+
+type CacheInvalidationResp struct {
+	Removed int64 `json:"removed"`
+}
+
+func (cacheInvalidationResp CacheInvalidationResp) GetRemoved() int64 {
+	return cacheInvalidationResp.Removed
+}
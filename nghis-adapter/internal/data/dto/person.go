@@ -0,0 +1,59 @@
+// Code generated by go generate; DO NOT EDIT.
+
+package dto
+
+type PersonInsurance struct {
+	Code string `json:"code" validate:"required"`
+	Name string `json:"name"`
+}
+
+func (personInsurance PersonInsurance) GetCode() string {
+	return personInsurance.Code
+}
+
+func (personInsurance PersonInsurance) GetName() string {
+	return personInsurance.Name
+}
+
+type PersonResp struct {
+	Age        *int32           `json:"age,omitempty"`
+	BirthDate  *string          `json:"birthDate,omitempty"`
+	FirstName  string           `json:"firstName"`
+	Identifier string           `json:"identifier" validate:"required"`
+	Insurance  *PersonInsurance `json:"insurance,omitempty"`
+	LastName   string           `json:"lastName"`
+	Name       string           `json:"name" validate:"required"`
+	Symbols    []string         `json:"symbols" validate:"required,dive"`
+}
+
+func (personResp PersonResp) GetAge() *int32 {
+	return personResp.Age
+}
+
+func (personResp PersonResp) GetBirthDate() *string {
+	return personResp.BirthDate
+}
+
+func (personResp PersonResp) GetFirstName() string {
+	return personResp.FirstName
+}
+
+func (personResp PersonResp) GetIdentifier() string {
+	return personResp.Identifier
+}
+
+func (personResp PersonResp) GetInsurance() *PersonInsurance {
+	return personResp.Insurance
+}
+
+func (personResp PersonResp) GetLastName() string {
+	return personResp.LastName
+}
+
+func (personResp PersonResp) GetName() string {
+	return personResp.Name
+}
+
+func (personResp PersonResp) GetSymbols() []string {
+	return personResp.Symbols
+}
@@ -0,0 +1,138 @@
+package hl7
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+// idleTimeout closes connections that send nothing for this long
+const idleTimeout = 5 * time.Minute
+
+// Handler processes the messages the listener receives; ErrUnsupportedMessage and ErrInvalidMessage
+// reject a message (AR), other errors report it as failed (AE) so the sender can retry it
+type Handler interface {
+	HandleMessage(ctx context.Context, message *Message) error
+}
+
+// Listener accepts MLLP connections, passes their messages to a handler and acknowledges each one
+type Listener struct {
+	logger  *slog.Logger
+	handler Handler
+
+	mu          sync.Mutex
+	listener    net.Listener
+	connections map[net.Conn]struct{}
+	wg          sync.WaitGroup
+}
+
+// NewListener prepares a listener passing messages to a handler
+func NewListener(logger *slog.Logger, handler Handler) *Listener {
+	return &Listener{
+		logger:      logger,
+		handler:     handler,
+		connections: map[net.Conn]struct{}{},
+	}
+}
+
+// ListenAndServe accepts connections on a TCP address until Close is called
+func (l *Listener) ListenAndServe(ctx context.Context, address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.listener = listener
+	l.mu.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			l.logger.Error("unable to accept MLLP connection", "err", err)
+			continue
+		}
+		l.mu.Lock()
+		l.connections[conn] = struct{}{}
+		l.mu.Unlock()
+
+		l.wg.Add(1)
+		go func() {
+			defer l.wg.Done()
+			l.serve(ctx, conn)
+		}()
+	}
+}
+
+// Close stops accepting connections and closes the open ones
+func (l *Listener) Close() {
+	l.mu.Lock()
+	if l.listener != nil {
+		if err := l.listener.Close(); err != nil {
+			l.logger.Warn("unable to close MLLP listener", "err", err)
+		}
+	}
+	for conn := range l.connections {
+		l.closeConnection(conn)
+	}
+	l.mu.Unlock()
+	l.wg.Wait()
+}
+
+// serve handles the messages of one connection in order
+func (l *Listener) serve(ctx context.Context, conn net.Conn) {
+	defer func() {
+		l.closeConnection(conn)
+		l.mu.Lock()
+		delete(l.connections, conn)
+		l.mu.Unlock()
+	}()
+
+	reader := bufio.NewReader(conn)
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(idleTimeout)); err != nil {
+			return
+		}
+		frame, err := ReadFrame(reader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				l.logger.Warn("closing MLLP connection", "remote", conn.RemoteAddr().String(), "err", err)
+			}
+			return
+		}
+
+		message, err := Parse(frame)
+		if err != nil {
+			// Without a header there is nothing to acknowledge
+			l.logger.Warn("dropping invalid HL7 message", "remote", conn.RemoteAddr().String(), "err", err)
+			continue
+		}
+
+		code, text := "AA", ""
+		if err := l.handler.HandleMessage(ctx, message); err != nil {
+			code, text = "AE", err.Error()
+			if errors.Is(err, ErrUnsupportedMessage) || errors.Is(err, ErrInvalidMessage) {
+				code = "AR"
+			}
+			messageType, trigger := message.Type()
+			l.logger.Error("unable to process HL7 message", "type", messageType+"^"+trigger, "controlID", message.ControlID(), "err", err)
+		}
+		if err := WriteFrame(conn, message.Ack(code, text, time.Now())); err != nil {
+			l.logger.Warn("unable to acknowledge HL7 message", "controlID", message.ControlID(), "err", err)
+			return
+		}
+	}
+}
+
+func (l *Listener) closeConnection(conn net.Conn) {
+	if err := conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		l.logger.Warn("unable to close MLLP connection", "remote", conn.RemoteAddr().String(), "err", err)
+	}
+}
@@ -0,0 +1,170 @@
+package hl7
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidMessage is returned for data that is not an HL7 v2 message
+	ErrInvalidMessage = errors.New("invalid HL7 message")
+	// ErrUnsupportedMessage is returned by handlers for message types they do not process
+	ErrUnsupportedMessage = errors.New("unsupported HL7 message")
+)
+
+// Segment is one segment of a message, its fields split but not unescaped
+type Segment struct {
+	Name   string
+	fields []string
+}
+
+// Message is a parsed HL7 v2 message
+type Message struct {
+	Segments []Segment
+
+	fieldSeparator        string
+	componentSeparator    string
+	repetitionSeparator   string
+	escapeCharacter       string
+	subcomponentSeparator string
+}
+
+// Parse parses an HL7 v2 message; segments end with a carriage return (a line feed is accepted too)
+func Parse(raw []byte) (*Message, error) {
+	text := strings.ReplaceAll(strings.TrimSpace(string(raw)), "\r\n", "\r")
+	text = strings.ReplaceAll(text, "\n", "\r")
+	if !strings.HasPrefix(text, "MSH") || len(text) < 8 {
+		return nil, fmt.Errorf("%w: missing MSH segment", ErrInvalidMessage)
+	}
+
+	message := &Message{
+		fieldSeparator:        text[3:4],
+		componentSeparator:    text[4:5],
+		repetitionSeparator:   text[5:6],
+		escapeCharacter:       text[6:7],
+		subcomponentSeparator: text[7:8],
+	}
+	for _, line := range strings.Split(text, "\r") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, message.fieldSeparator)
+		if len(fields[0]) != 3 {
+			return nil, fmt.Errorf("%w: bad segment %q", ErrInvalidMessage, fields[0])
+		}
+		if fields[0] == "MSH" {
+			// MSH-1 is the field separator itself, so MSH-n is the n-1th field after the name
+			fields = append([]string{"MSH", message.fieldSeparator}, fields[1:]...)
+		}
+		message.Segments = append(message.Segments, Segment{Name: fields[0], fields: fields})
+	}
+	return message, nil
+}
+
+// Segment returns the first segment with a name
+func (m *Message) Segment(name string) (Segment, bool) {
+	for _, segment := range m.Segments {
+		if segment.Name == name {
+			return segment, true
+		}
+	}
+	return Segment{}, false
+}
+
+// Get returns the unescaped component (1-based) of the first repetition of a field of the first
+// segment with a name, e.g. Get("PID", 5, 1) for the family name; empty when it is missing
+func (m *Message) Get(segmentName string, field, component int) string {
+	segment, ok := m.Segment(segmentName)
+	if !ok || field >= len(segment.fields) {
+		return ""
+	}
+	repetition := strings.SplitN(segment.fields[field], m.repetitionSeparator, 2)[0]
+	if segmentName == "MSH" && field <= 2 {
+		return repetition
+	}
+	components := strings.Split(repetition, m.componentSeparator)
+	if component < 1 || component > len(components) {
+		return ""
+	}
+	value := strings.SplitN(components[component-1], m.subcomponentSeparator, 2)[0]
+	return m.unescape(value)
+}
+
+// Type returns the message type and trigger event of MSH-9, e.g. "ADT" and "A04"
+func (m *Message) Type() (string, string) {
+	return m.Get("MSH", 9, 1), m.Get("MSH", 9, 2)
+}
+
+// ControlID returns the message control ID of MSH-10
+func (m *Message) ControlID() string {
+	return m.Get("MSH", 10, 1)
+}
+
+// Ack returns the acknowledgement of the message with an acknowledgement code (AA, AE or AR) and
+// an optional text
+func (m *Message) Ack(code, text string, now time.Time) []byte {
+	_, trigger := m.Type()
+	separator := m.fieldSeparator
+	encoding := m.componentSeparator + m.repetitionSeparator + m.escapeCharacter + m.subcomponentSeparator
+	msh := strings.Join([]string{
+		"MSH", encoding,
+		m.Get("MSH", 5, 1), m.Get("MSH", 6, 1), // The receiver of the message sends the ACK
+		m.Get("MSH", 3, 1), m.Get("MSH", 4, 1),
+		now.Format("20060102150405"), "",
+		"ACK" + m.componentSeparator + trigger,
+		m.ControlID() + "-ACK", m.Get("MSH", 11, 1), m.Get("MSH", 12, 1),
+	}, separator)
+	msa := strings.Join([]string{"MSA", code, m.ControlID(), m.escape(text)}, separator)
+	return []byte(msh + "\r" + msa + "\r")
+}
+
+// ParseTime parses an HL7 timestamp (YYYY[MM[DD[HH[MM[SS[.S...]]]]]][+/-ZZZZ]); one without an
+// offset is in loc
+func ParseTime(value string, loc *time.Location) (time.Time, error) {
+	offset := ""
+	if i := strings.IndexAny(value, "+-"); i >= 0 {
+		value, offset = value[:i], value[i:]
+	}
+	if i := strings.Index(value, "."); i >= 0 {
+		value = value[:i]
+	}
+	layouts := map[int]string{4: "2006", 6: "200601", 8: "20060102", 10: "2006010215", 12: "200601021504", 14: "20060102150405"}
+	layout, ok := layouts[len(value)]
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid HL7 timestamp %q", value)
+	}
+	if offset != "" {
+		return time.Parse(layout+"-0700", value+offset)
+	}
+	return time.ParseInLocation(layout, value, loc)
+}
+
+// unescape replaces the escape sequences of the delimiters in a value
+func (m *Message) unescape(value string) string {
+	if !strings.Contains(value, m.escapeCharacter) {
+		return value
+	}
+	e := m.escapeCharacter
+	return strings.NewReplacer(
+		e+"F"+e, m.fieldSeparator,
+		e+"S"+e, m.componentSeparator,
+		e+"R"+e, m.repetitionSeparator,
+		e+"T"+e, m.subcomponentSeparator,
+		e+"E"+e, m.escapeCharacter,
+	).Replace(value)
+}
+
+// escape escapes the delimiters in a value
+func (m *Message) escape(value string) string {
+	e := m.escapeCharacter
+	return strings.NewReplacer(
+		m.escapeCharacter, e+"E"+e,
+		m.fieldSeparator, e+"F"+e,
+		m.componentSeparator, e+"S"+e,
+		m.repetitionSeparator, e+"R"+e,
+		m.subcomponentSeparator, e+"T"+e,
+		"\r", " ",
+	).Replace(value)
+}
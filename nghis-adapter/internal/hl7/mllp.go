@@ -0,0 +1,58 @@
+package hl7
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MLLP frames messages between a start block and an end block followed by a carriage return
+const (
+	startBlock     byte = 0x0b
+	endBlock       byte = 0x1c
+	carriageReturn byte = 0x0d
+)
+
+// maxFrameSize is the largest message a frame may carry
+const maxFrameSize = 1 << 20
+
+// ReadFrame reads the next MLLP frame and returns the message in it; data before the start block
+// is skipped
+func ReadFrame(r *bufio.Reader) ([]byte, error) {
+	if _, err := r.ReadBytes(startBlock); err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		if b == endBlock {
+			if next, err := r.ReadByte(); err != nil || next != carriageReturn {
+				return nil, fmt.Errorf("%w: end block not followed by a carriage return", ErrInvalidMessage)
+			}
+			return message.Bytes(), nil
+		}
+		if message.Len() >= maxFrameSize {
+			return nil, fmt.Errorf("%w: frame larger than %d bytes", ErrInvalidMessage, maxFrameSize)
+		}
+		message.WriteByte(b)
+	}
+}
+
+// WriteFrame writes a message in an MLLP frame
+func WriteFrame(w io.Writer, message []byte) error {
+	frame := make([]byte, 0, len(message)+3)
+	frame = append(frame, startBlock)
+	frame = append(frame, message...)
+	frame = append(frame, endBlock, carriageReturn)
+	_, err := w.Write(frame)
+	return err
+}
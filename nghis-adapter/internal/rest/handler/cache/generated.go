@@ -0,0 +1,51 @@
+// Code generated by go generate; DO NOT EDIT.
+
+package cache
+
+import (
+	"github.com/arfis/waiting-room/nghis-adapter/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/nghis-adapter/internal/errors"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/rest/handler"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/service/cache"
+	"log/slog"
+	"net/http"
+)
+
+type Handler struct {
+	svc                  *cache.Service
+	responseErrorHandler *ngErrors.ResponseErrorHandler
+}
+
+func New(
+	svc *cache.Service,
+	responseErrorHandler *ngErrors.ResponseErrorHandler,
+) *Handler {
+	return &Handler{
+		svc:                  svc,
+		responseErrorHandler: responseErrorHandler,
+	}
+}
+
+func init() {
+	slog.Debug("Generated handler 'cache/Handler' initialized")
+}
+
+func (h *Handler) InvalidateCache(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	namespace := handler.QueryOptionalParamToString(r, "namespace")
+	var resp *dto.CacheInvalidationResp
+	resp, applicationErr = h.svc.InvalidateCache(
+		r.Context(),
+		namespace,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	err := handler.WriteJson(r.Context(), w, http.StatusOK, resp)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "InvalidateCache - error writing response", "error", err)
+		h.responseErrorHandler.HandleAndWriteError(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,51 @@
+// Code generated by go generate; DO NOT EDIT.
+
+package person
+
+import (
+	"github.com/arfis/waiting-room/nghis-adapter/internal/data/dto"
+	ngErrors "github.com/arfis/waiting-room/nghis-adapter/internal/errors"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/rest/handler"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/service/person"
+	"log/slog"
+	"net/http"
+)
+
+type Handler struct {
+	svc                  *person.Service
+	responseErrorHandler *ngErrors.ResponseErrorHandler
+}
+
+func New(
+	svc *person.Service,
+	responseErrorHandler *ngErrors.ResponseErrorHandler,
+) *Handler {
+	return &Handler{
+		svc:                  svc,
+		responseErrorHandler: responseErrorHandler,
+	}
+}
+
+func init() {
+	slog.Debug("Generated handler 'person/Handler' initialized")
+}
+
+func (h *Handler) GetPerson(w http.ResponseWriter, r *http.Request) {
+	var applicationErr error
+	identifier := handler.PathParamToString(r, "identifier")
+	var resp *dto.PersonResp
+	resp, applicationErr = h.svc.GetPerson(
+		r.Context(),
+		identifier,
+	)
+	if applicationErr != nil {
+		h.responseErrorHandler.HandleAndWriteError(w, r, applicationErr)
+		return
+	}
+	err := handler.WriteJson(r.Context(), w, http.StatusOK, resp)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "GetPerson - error writing response", "error", err)
+		h.responseErrorHandler.HandleAndWriteError(w, r, err)
+		return
+	}
+}
@@ -4,6 +4,8 @@ package register
 
 import (
 	"github.com/arfis/waiting-room/nghis-adapter/internal/rest/handler/appointment"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/rest/handler/cache"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/rest/handler/person"
 	"github.com/arfis/waiting-room/nghis-adapter/internal/rest/handler/services"
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/dig"
@@ -12,9 +14,13 @@ import (
 func Generated(r chi.Router, diContainer *dig.Container) {
 	err := diContainer.Invoke(func(
 		appointmentHandler *appointment.Handler,
+		cacheHandler *cache.Handler,
+		personHandler *person.Handler,
 		servicesHandler *services.Handler,
 	) error {
+		r.Delete("/admin/cache", cacheHandler.InvalidateCache)
 		r.Get("/patient/{nationalID}/appointments", appointmentHandler.GetAppointmentsForPatient)
+		r.Get("/persons/{identifier}", personHandler.GetPerson)
 		r.Post("/services", servicesHandler.FindServices)
 		return nil
 	})
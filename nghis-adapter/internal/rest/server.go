@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/arfis/waiting-room/nghis-adapter/internal/cache"
 	"github.com/arfis/waiting-room/nghis-adapter/internal/config/service"
 	"github.com/arfis/waiting-room/nghis-adapter/internal/middleware"
 	"github.com/arfis/waiting-room/nghis-adapter/internal/rest/register"
@@ -21,6 +22,7 @@ func NewServer(configuration *service.Configuration, loggingMiddleware *middlewa
 		router.Use(loggingMiddleware.LoggingMiddleware)
 		router.Use(chimiddleware.Timeout(configuration.HTTPTimeout))
 		router.Use(middleware.Cors())
+		router.Use(cache.Middleware)
 		register.Generated(router, diContainer)
 	})
 	http.Handle("/", r)
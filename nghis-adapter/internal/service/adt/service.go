@@ -0,0 +1,105 @@
+package adt
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/arfis/waiting-room/nghis-adapter/internal/client"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/config/service"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/hl7"
+)
+
+// Service forwards the ADT messages of the HIS to the waiting-room API: A04 (patient registered)
+// pre-registers the visit and queues the patient, A08 (patient information updated) updates the
+// visit
+type Service struct {
+	logger            *slog.Logger
+	waitingRoomClient *client.WaitingRoomClient
+	defaultRoom       string
+}
+
+func NewService(
+	logger *slog.Logger,
+	configuration *service.Configuration,
+	waitingRoomClient *client.WaitingRoomClient,
+) *Service {
+	return &Service{
+		logger:            logger,
+		waitingRoomClient: waitingRoomClient,
+		defaultRoom:       configuration.ADTDefaultRoom,
+	}
+}
+
+// HandleMessage converts an ADT^A04 or ADT^A08 message to waiting-room events and sends them
+func (s *Service) HandleMessage(ctx context.Context, message *hl7.Message) error {
+	messageType, trigger := message.Type()
+	if messageType != "ADT" || (trigger != "A04" && trigger != "A08") {
+		return fmt.Errorf("%w: %s^%s", hl7.ErrUnsupportedMessage, messageType, trigger)
+	}
+
+	appointment, err := s.appointment(message)
+	if err != nil {
+		return err
+	}
+	s.logger.Info("received ADT message", "trigger", trigger, "controlID", message.ControlID(), "visit", appointment.ExternalID)
+
+	if err := s.waitingRoomClient.SendEvent(ctx, client.WaitingRoomEvent{
+		Type:        client.EventPreRegister,
+		Appointment: appointment,
+	}); err != nil {
+		return err
+	}
+	if trigger == "A08" {
+		return nil
+	}
+	return s.waitingRoomClient.SendEvent(ctx, client.WaitingRoomEvent{
+		Type:       client.EventArrived,
+		ExternalID: appointment.ExternalID,
+	})
+}
+
+// appointment returns the visit of an ADT message: the visit number (PV1-19) is its ID, the
+// first patient identifier (PID-3) the card identifier, the point of care (PV1-3) the room and the
+// hospital service (PV1-10) the service
+func (s *Service) appointment(message *hl7.Message) (*client.WaitingRoomAppointment, error) {
+	if _, ok := message.Segment("PID"); !ok {
+		return nil, fmt.Errorf("%w: missing PID segment", hl7.ErrInvalidMessage)
+	}
+	identifier := message.Get("PID", 3, 1)
+	if identifier == "" {
+		return nil, fmt.Errorf("%w: missing patient identifier in PID-3", hl7.ErrInvalidMessage)
+	}
+
+	externalID := message.Get("PV1", 19, 1)
+	if externalID == "" {
+		// Without a visit number the message registers a visit of its own
+		externalID = message.ControlID()
+	}
+	roomID := message.Get("PV1", 3, 1)
+	if roomID == "" {
+		roomID = s.defaultRoom
+	}
+
+	appointmentTime := time.Now()
+	if admitted := message.Get("PV1", 44, 1); admitted != "" {
+		parsed, err := hl7.ParseTime(admitted, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("%w: PV1-44: %w", hl7.ErrInvalidMessage, err)
+		}
+		appointmentTime = parsed
+	}
+
+	patientName := strings.TrimSpace(message.Get("PID", 5, 2) + " " + message.Get("PID", 5, 1))
+	return &client.WaitingRoomAppointment{
+		AppointmentTime: appointmentTime,
+		ExternalID:      externalID,
+		Identifier:      identifier,
+		PatientName:     patientName,
+		RoomID:          roomID,
+		ServiceID:       message.Get("PV1", 10, 1),
+		ServiceName:     message.Get("PV1", 10, 1),
+	}, nil
+}
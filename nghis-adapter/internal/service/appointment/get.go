@@ -10,6 +10,7 @@ import (
 
 	"git.prosoftke.sk/nghis/openapi/clients/go/nghisclinicalclient/v2"
 	"git.prosoftke.sk/nghis/openapi/clients/go/nghispersonserviceclient"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/cache"
 	"github.com/arfis/waiting-room/nghis-adapter/internal/data/dto"
 	"github.com/arfis/waiting-room/nghis-adapter/internal/errors"
 )
@@ -18,17 +19,20 @@ type Service struct {
 	logger         *slog.Logger
 	clinicalClient *nghisclinicalclient.APIClient
 	personClient   *nghispersonserviceclient.APIClient
+	cache          *cache.Cache
 }
 
 func NewService(
 	logger *slog.Logger,
 	clinicalClient *nghisclinicalclient.APIClient,
 	personClient *nghispersonserviceclient.APIClient,
+	cache *cache.Cache,
 ) *Service {
 	return &Service{
 		logger:         logger,
 		clinicalClient: clinicalClient,
 		personClient:   personClient,
+		cache:          cache,
 	}
 }
 
@@ -36,13 +40,11 @@ func (s *Service) GetAppointmentsForPatient(ctx context.Context, nationalID stri
 	currTime := time.Now()
 	resp := make([]dto.AppointmentResp, 0)
 
-	patient, httpResp, err := s.personClient.PatientAPI.FindPatientByNationalID(ctx, nationalID).Execute()
+	patient, err := cache.Load(ctx, s.cache, cache.NamespacePatients, nationalID, func(ctx context.Context) (*nghispersonserviceclient.Patient, error) {
+		return s.findPatient(ctx, nationalID)
+	})
 	if err != nil {
-		s.logger.Error("unable to find patient by national ID", "nationalID", nationalID, "httpResp", httpResp, "err", err)
-		if httpResp.StatusCode == 404 {
-			return nil, errors.NotFound(errs.New("patient not found"), nil)
-		}
-		return nil, errors.ServiceCall(err, nil)
+		return nil, err
 	}
 
 	events, httpResp, err := s.clinicalClient.CareItemAPI.FindAllCareItems(ctx).
@@ -69,3 +71,16 @@ func (s *Service) GetAppointmentsForPatient(ctx context.Context, nationalID stri
 
 	return resp, nil
 }
+
+// findPatient loads the demographics of a patient, which the kiosk asks for on every swipe
+func (s *Service) findPatient(ctx context.Context, nationalID string) (*nghispersonserviceclient.Patient, error) {
+	patient, httpResp, err := s.personClient.PatientAPI.FindPatientByNationalID(ctx, nationalID).Execute()
+	if err != nil {
+		s.logger.Error("unable to find patient by national ID", "nationalID", nationalID, "httpResp", httpResp, "err", err)
+		if httpResp != nil && httpResp.StatusCode == 404 {
+			return nil, errors.NotFound(errs.New("patient not found"), nil)
+		}
+		return nil, errors.ServiceCall(err, nil)
+	}
+	return patient, nil
+}
@@ -0,0 +1,169 @@
+package appointmentsync
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"git.prosoftke.sk/nghis/openapi/clients/go/nghisclinicalclient/v2"
+	"git.prosoftke.sk/nghis/openapi/clients/go/nghispersonserviceclient"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/client"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/config/service"
+	appCtx "github.com/arfis/waiting-room/nghis-adapter/internal/context"
+)
+
+// pageSize is the number of care items loaded per request
+const pageSize = 100
+
+// Service pushes the planned care items of today of the configured departments to the waiting-room
+// API as appointments, so patients swiping their card are matched to them. Care items are pushed
+// again only when they change.
+type Service struct {
+	logger            *slog.Logger
+	clinicalClient    *nghisclinicalclient.APIClient
+	personClient      *nghispersonserviceclient.APIClient
+	waitingRoomClient *client.WaitingRoomClient
+	configuration     *service.Configuration
+
+	mu     sync.Mutex
+	day    string
+	pushed map[string]client.WaitingRoomAppointment // Last pushed appointment per care item of the day
+}
+
+func NewService(
+	logger *slog.Logger,
+	configuration *service.Configuration,
+	clinicalClient *nghisclinicalclient.APIClient,
+	personClient *nghispersonserviceclient.APIClient,
+	waitingRoomClient *client.WaitingRoomClient,
+) *Service {
+	return &Service{
+		logger:            logger,
+		clinicalClient:    clinicalClient,
+		personClient:      personClient,
+		waitingRoomClient: waitingRoomClient,
+		configuration:     configuration,
+		pushed:            map[string]client.WaitingRoomAppointment{},
+	}
+}
+
+// Enabled reports whether departments to sync are configured
+func (s *Service) Enabled() bool {
+	return len(s.configuration.AppointmentSyncOrgUnits) > 0 && s.configuration.AppointmentSyncInterval > 0
+}
+
+// Run syncs the appointments at once and then every interval until the context is done
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.configuration.AppointmentSyncInterval)
+	defer ticker.Stop()
+	for {
+		s.Sync(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync pushes the new and changed care items of today of every department
+func (s *Service) Sync(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if day := now.Format(time.DateOnly); day != s.day {
+		s.day = day
+		s.pushed = map[string]client.WaitingRoomAppointment{}
+	}
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	// The NGHIS services are called as the tenant of the departments
+	ctx = context.WithValue(ctx, appCtx.TENANT, s.configuration.AppointmentSyncTenantID)
+	if s.configuration.AppointmentSyncToken != "" {
+		ctx = context.WithValue(ctx, appCtx.ORIGINAL_TOKEN, s.configuration.AppointmentSyncToken)
+	}
+
+	pushed := 0
+	patients := map[string]*nghispersonserviceclient.Patient{}
+	for _, orgUnit := range s.configuration.AppointmentSyncOrgUnits {
+		for page := int32(0); ; page++ {
+			careItems, httpResp, err := s.clinicalClient.CareItemAPI.FindAllCareItems(ctx).
+				OrgUnitCodes([]string{orgUnit}).
+				AfterRequestedTime(from).
+				BeforeRequestedTime(from.AddDate(0, 0, 1)).
+				CareItemStatuses([]nghisclinicalclient.CareItemStatusEnum{nghisclinicalclient.CAREITEMSTATUSENUM_PLANNED}).
+				Size(pageSize).
+				Page(page).
+				Execute()
+			if err != nil {
+				s.logger.Error("unable to load care items of org unit", "orgUnit", orgUnit, "httpResp", httpResp, "err", err)
+				break
+			}
+
+			for _, careItem := range careItems.GetContent() {
+				appointment, ok := s.appointment(ctx, orgUnit, careItem, patients)
+				if !ok || s.pushed[appointment.ExternalID] == appointment {
+					continue
+				}
+				if err := s.waitingRoomClient.SendEvent(ctx, client.WaitingRoomEvent{
+					Type:        client.EventPreRegister,
+					Appointment: &appointment,
+				}); err != nil {
+					s.logger.Error("unable to push appointment", "careItemID", appointment.ExternalID, "err", err)
+					continue
+				}
+				s.pushed[appointment.ExternalID] = appointment
+				pushed++
+			}
+			if len(careItems.GetContent()) < pageSize {
+				break
+			}
+		}
+	}
+	if pushed > 0 {
+		s.logger.Info("pushed appointments to the waiting-room API", "count", pushed)
+	}
+}
+
+// appointment maps a care item of an org unit to a waiting-room appointment: the room of the org
+// unit and the waiting-room service of its service code; false when its patient cannot be found
+func (s *Service) appointment(
+	ctx context.Context,
+	orgUnit string,
+	careItem nghisclinicalclient.CareItem,
+	patients map[string]*nghispersonserviceclient.Patient,
+) (client.WaitingRoomAppointment, bool) {
+	patientID := strconv.FormatInt(careItem.GetPatientId(), 10)
+	patient, ok := patients[patientID]
+	if !ok {
+		found, httpResp, err := s.personClient.PatientAPI.ShowPatientDetail(ctx, careItem.GetPatientId()).Execute()
+		if err != nil {
+			s.logger.Error("unable to load patient of care item", "careItemID", careItem.GetId(), "patientID", patientID, "httpResp", httpResp, "err", err)
+			return client.WaitingRoomAppointment{}, false
+		}
+		patient = found
+		patients[patientID] = patient
+	}
+	if patient.GetNationalId() == "" {
+		return client.WaitingRoomAppointment{}, false
+	}
+
+	serviceCode := careItem.RequestedService.GetCode()
+	serviceID, ok := s.configuration.ServiceCodes[serviceCode]
+	if !ok {
+		serviceID = serviceCode
+	}
+	return client.WaitingRoomAppointment{
+		AppointmentTime: careItem.GetRequestedTime(),
+		ExternalID:      strconv.FormatInt(careItem.GetId(), 10),
+		Identifier:      patient.GetNationalId(),
+		PatientName:     patient.GetFirstName() + " " + patient.GetLastName(),
+		RoomID:          s.configuration.OrgUnitRooms[orgUnit],
+		ServiceDuration: careItem.GetDuration(),
+		ServiceID:       serviceID,
+		ServiceName:     careItem.RequestedService.GetName(),
+	}, true
+}
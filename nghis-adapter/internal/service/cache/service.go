@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"context"
+	errs "errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	cacheStore "github.com/arfis/waiting-room/nghis-adapter/internal/cache"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/data/dto"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/errors"
+)
+
+type Service struct {
+	logger *slog.Logger
+	cache  *cacheStore.Cache
+}
+
+func NewService(
+	logger *slog.Logger,
+	cache *cacheStore.Cache,
+) *Service {
+	return &Service{
+		logger: logger,
+		cache:  cache,
+	}
+}
+
+// InvalidateCache removes the cached entries of the tenant in a namespace, or in all of them, so
+// the next requests load the data from NGHIS again
+func (s *Service) InvalidateCache(ctx context.Context, namespace *string) (*dto.CacheInvalidationResp, error) {
+	name := ""
+	if namespace != nil {
+		name = *namespace
+		if !slices.Contains(cacheStore.Namespaces, name) {
+			return nil, errors.Validation(fmt.Errorf("unknown cache namespace %q", name), nil)
+		}
+	}
+
+	removed, err := s.cache.Invalidate(ctx, name)
+	if err != nil {
+		s.logger.Error("unable to invalidate cache", "namespace", name, "err", err)
+		return nil, errors.System(errs.New("unable to invalidate cache"), nil)
+	}
+	s.logger.Info("invalidated cache", "namespace", name, "removed", removed)
+	return &dto.CacheInvalidationResp{Removed: removed}, nil
+}
@@ -0,0 +1,120 @@
+package person
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	errs "errors"
+
+	"git.prosoftke.sk/nghis/openapi/clients/go/nghispersonserviceclient"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/cache"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/config/service"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/data/dto"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/errors"
+)
+
+type Service struct {
+	logger       *slog.Logger
+	personClient *nghispersonserviceclient.APIClient
+	cache        *cache.Cache
+	alertSymbols map[string]string
+}
+
+func NewService(
+	logger *slog.Logger,
+	configuration *service.Configuration,
+	personClient *nghispersonserviceclient.APIClient,
+	cache *cache.Cache,
+) *Service {
+	alertSymbols := make(map[string]string, len(configuration.PersonAlertSymbols))
+	for alert, symbol := range configuration.PersonAlertSymbols {
+		alertSymbols[strings.ToUpper(alert)] = strings.ToUpper(symbol)
+	}
+	return &Service{
+		logger:       logger,
+		personClient: personClient,
+		cache:        cache,
+		alertSymbols: alertSymbols,
+	}
+}
+
+// GetPerson returns the demographics of the patient with a national ID in the shape the priority
+// calculator of the waiting-room API takes: the age and the priority symbols of the active alerts
+func (s *Service) GetPerson(ctx context.Context, identifier string) (*dto.PersonResp, error) {
+	return cache.Load(ctx, s.cache, cache.NamespacePersons, identifier, func(ctx context.Context) (*dto.PersonResp, error) {
+		return s.getPerson(ctx, identifier)
+	})
+}
+
+func (s *Service) getPerson(ctx context.Context, identifier string) (*dto.PersonResp, error) {
+	patient, httpResp, err := s.personClient.PatientAPI.FindPatientByNationalID(ctx, identifier).Execute()
+	if err != nil {
+		s.logger.Error("unable to find patient by national ID", "nationalID", identifier, "httpResp", httpResp, "err", err)
+		if httpResp != nil && httpResp.StatusCode == 404 {
+			return nil, errors.NotFound(errs.New("patient not found"), nil)
+		}
+		return nil, errors.ServiceCall(err, nil)
+	}
+
+	now := time.Now()
+	resp := &dto.PersonResp{
+		FirstName:  patient.GetFirstName(),
+		Identifier: identifier,
+		LastName:   patient.GetLastName(),
+		Name:       strings.TrimSpace(patient.GetFirstName() + " " + patient.GetLastName()),
+		Symbols:    make([]string, 0),
+	}
+	if birthDate, err := time.Parse(time.DateOnly, patient.GetBirthDate()); err == nil {
+		formatted := birthDate.Format(time.DateOnly)
+		age := ageAt(birthDate, now)
+		resp.BirthDate = &formatted
+		resp.Age = &age
+	}
+
+	insurances, httpResp, err := s.personClient.PatientInsuranceAPI.FindAllPatientInsurances(ctx).
+		PatientId(patient.GetId()).
+		ValidAt(now).
+		Execute()
+	if err != nil {
+		s.logger.Error("unable to load insurances of patient", "patientID", patient.GetId(), "httpResp", httpResp, "err", err)
+		return nil, errors.ServiceCall(err, nil)
+	}
+	// The first valid insurance is the one the visit is covered by
+	if content := insurances.GetContent(); len(content) > 0 {
+		resp.Insurance = &dto.PersonInsurance{
+			Code: content[0].InsuranceCompany.GetCode(),
+			Name: content[0].InsuranceCompany.GetName(),
+		}
+	}
+
+	alerts, httpResp, err := s.personClient.PatientAlertAPI.FindAllPatientAlerts(ctx).
+		PatientId(patient.GetId()).
+		ValidAt(now).
+		Execute()
+	if err != nil {
+		s.logger.Error("unable to load alerts of patient", "patientID", patient.GetId(), "httpResp", httpResp, "err", err)
+		return nil, errors.ServiceCall(err, nil)
+	}
+	for _, alert := range alerts.GetContent() {
+		// Only alerts mapped to a priority symbol matter to the waiting room
+		symbol, ok := s.alertSymbols[strings.ToUpper(alert.AlertType.GetCode())]
+		if ok && !slices.Contains(resp.Symbols, symbol) {
+			resp.Symbols = append(resp.Symbols, symbol)
+		}
+	}
+	slices.Sort(resp.Symbols)
+
+	return resp, nil
+}
+
+// ageAt returns the age in whole years at a time
+func ageAt(birthDate, at time.Time) int32 {
+	years := at.Year() - birthDate.Year()
+	if at.Month() < birthDate.Month() || (at.Month() == birthDate.Month() && at.Day() < birthDate.Day()) {
+		years--
+	}
+	return int32(max(years, 0))
+}
@@ -3,28 +3,43 @@ package services
 import (
 	"context"
 	"log/slog"
+	"slices"
+	"strings"
 	"time"
 
 	"git.prosoftke.sk/nghis/openapi/clients/go/nghisclinicalclient/v2"
+	"github.com/arfis/waiting-room/nghis-adapter/internal/cache"
 	"github.com/arfis/waiting-room/nghis-adapter/internal/data/dto"
 )
 
 type Service struct {
 	logger         *slog.Logger
 	clinicalClient *nghisclinicalclient.APIClient
+	cache          *cache.Cache
 }
 
 func NewService(
 	logger *slog.Logger,
 	clinicalClient *nghisclinicalclient.APIClient,
+	cache *cache.Cache,
 ) *Service {
 	return &Service{
 		logger:         logger,
 		clinicalClient: clinicalClient,
+		cache:          cache,
 	}
 }
 
+// FindServices returns the kiosk bookable services of the org units, cached per set of org units
 func (s *Service) FindServices(ctx context.Context, req *dto.FindServicesReq) ([]dto.ServicesResp, error) {
+	orgUnitCodes := slices.Clone(req.OrgUnitCodes)
+	slices.Sort(orgUnitCodes)
+	return cache.Load(ctx, s.cache, cache.NamespaceServices, strings.Join(orgUnitCodes, ","), func(ctx context.Context) ([]dto.ServicesResp, error) {
+		return s.findServices(ctx, req)
+	})
+}
+
+func (s *Service) findServices(ctx context.Context, req *dto.FindServicesReq) ([]dto.ServicesResp, error) {
 	servicesFiltered, httpResp, err := s.clinicalClient.ServiceByProviderAPI.FilterServiceByProvider(ctx).ServiceByProviderFilterReq(nghisclinicalclient.ServiceByProviderFilterReq{
 		ValidAt:               time.Now().UTC(),
 		OrgUnitCodes:          req.OrgUnitCodes,
@@ -29,6 +29,12 @@ export interface QueueUpdate {
   entries: WebSocketQueueEntry[];
 }
 
+// Sent when the configuration the tenant inherits changed; empty tenantId is the global one
+export interface ConfigUpdate {
+  type: 'config_update';
+  tenantId: string;
+}
+
 @Injectable({
   providedIn: 'root'
 })
@@ -49,6 +55,8 @@ export class QueueWebSocketService {
   queueEntries = signal<WebSocketQueueEntry[]>([]);
   isConnected = signal<boolean>(false);
   error = signal<string | null>(null);
+  // Last configuration change of the tenant, so settings can be fetched again in an effect
+  configUpdate = signal<ConfigUpdate | null>(null);
 
   // Helper methods to get tenant ID without direct dependency on TenantService
   // This avoids build-time TypeScript errors when processing the tenant library
@@ -367,9 +375,12 @@ export class QueueWebSocketService {
       this.ws.onmessage = (event) => {
         try {
           console.log('[QueueWebSocket] Raw WebSocket message received:', event.data);
-          const data: QueueUpdate = JSON.parse(event.data);
+          const data: QueueUpdate | ConfigUpdate = JSON.parse(event.data);
           console.log('[QueueWebSocket] Parsed WebSocket message:', data);
-          if (data.type === 'queue_update') {
+          if (data.type === 'config_update') {
+            console.log('[QueueWebSocket] Configuration changed for tenant:', data.tenantId);
+            this.configUpdate.set(data);
+          } else if (data.type === 'queue_update') {
             console.log('[QueueWebSocket] Queue update received:', data.entries.length, 'entries');
             console.log('[QueueWebSocket] Entries:', JSON.stringify(data.entries, null, 2));
             // Normalize entries to handle snake_case from backend
@@ -377,7 +388,7 @@ export class QueueWebSocketService {
             this.queueEntries.set(normalizedEntries);
             console.log('[QueueWebSocket] Queue entries updated. New count:', this.queueEntries().length);
           } else {
-            console.warn('[QueueWebSocket] Unknown message type:', data.type);
+            console.warn('[QueueWebSocket] Unknown message type:', (data as { type: string }).type);
           }
         } catch (error) {
           console.error('[QueueWebSocket] Failed to parse WebSocket message:', error);
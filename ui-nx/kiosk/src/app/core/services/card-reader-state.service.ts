@@ -247,6 +247,7 @@ export class CardReaderStateService {
     let appointmentLoaded = false;
     let genericLoaded = false;
     let userServicesLoaded = false;
+    let degraded = false;
     
     // Initialize service sections
     const sections: ServiceSection[] = [
@@ -275,7 +276,8 @@ export class CardReaderStateService {
         const allSections = this.serviceSections();
         const hasAnyServices = allSections.some(section => section.services.length > 0) || this.userServices().length > 0;
         
-        if (!hasAnyServices) {
+        // Without the HIS the patient sees that services are unavailable instead of an empty screen
+        if (!hasAnyServices && !degraded) {
           console.log('No services available - automatically generating ticket without service');
           this.isLoadingServices.set(false);
           this.isManualIdSubmitting.set(false);
@@ -290,10 +292,11 @@ export class CardReaderStateService {
     const appointmentLang = this.currentLanguage();
     console.log('CardReaderState: Loading appointment services with language:', appointmentLang);
     this.userServicesService.getAppointmentServices(identifier, appointmentLang).subscribe({
-      next: (services) => {
+      next: ({ services, degraded: appointmentDegraded }) => {
         console.log('Appointment services loaded:', services);
         appointmentLoaded = true;
-        this.updateServiceSection('appointment', services, false, null);
+        degraded = degraded || appointmentDegraded;
+        this.updateServiceSection('appointment', services, false, appointmentDegraded ? 'Services are temporarily unavailable' : null);
         checkAndProceedIfNoServices();
       },
       error: (error) => {
@@ -308,10 +311,11 @@ export class CardReaderStateService {
     // Load generic services directly (no servicePointId needed)
     console.log('CardReaderState: Loading generic services with language:', genericLang);
     this.userServicesService.getGenericServices(genericLang).subscribe({
-      next: (services) => {
+      next: ({ services, degraded: genericDegraded }) => {
         console.log('Generic services loaded:', services);
         genericLoaded = true;
-        this.updateServiceSection('generic', services, false, null);
+        degraded = degraded || genericDegraded;
+        this.updateServiceSection('generic', services, false, genericDegraded ? 'Services are temporarily unavailable' : null);
         checkAndProceedIfNoServices();
       },
       error: (error) => {
@@ -326,8 +330,9 @@ export class CardReaderStateService {
     const userLang = this.currentLanguage();
     console.log('CardReaderState: Loading user services with language:', userLang);
     this.userServicesService.getUserServices(identifier, userLang).subscribe({
-      next: (services) => {
+      next: ({ services, degraded: userDegraded }) => {
         console.log('User services loaded:', services);
+        degraded = degraded || userDegraded;
         this.userServices.set(services);
         userServicesLoaded = true;
         this.isLoadingServices.set(false);
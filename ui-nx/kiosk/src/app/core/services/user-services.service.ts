@@ -9,6 +9,12 @@ export interface UserService {
   id: string;
 }
 
+export interface UserServicesResponse {
+  services: UserService[];
+  // The HIS could not be reached, so services may be missing
+  degraded: boolean;
+}
+
 export interface ServiceSection {
   title: string;
   services: UserService[];
@@ -24,9 +30,9 @@ export class UserServicesService {
   private readonly http = inject(HttpClient);
   private readonly apiUrl = environment.apiUrl || 'http://localhost:8080/api';
 
-  getUserServices(identifier: string, language: string = 'en'): Observable<UserService[]> {
+  getUserServices(identifier: string, language: string = 'en'): Observable<UserServicesResponse> {
     // Call backend API which will then call external API
-    return this.http.get<UserService[]>(`${this.apiUrl}/user-services`, {
+    return this.http.get<UserServicesResponse>(`${this.apiUrl}/user-services`, {
       params: { identifier, language },
       headers: {
         'Cache-Control': 'no-cache, no-store, must-revalidate',
@@ -36,9 +42,9 @@ export class UserServicesService {
     });
   }
 
-  getAppointmentServices(identifier: string, language: string = 'en'): Observable<UserService[]> {
+  getAppointmentServices(identifier: string, language: string = 'en'): Observable<UserServicesResponse> {
     // Call backend API for appointment-specific services
-    return this.http.get<UserService[]>(`${this.apiUrl}/appointment-services`, {
+    return this.http.get<UserServicesResponse>(`${this.apiUrl}/appointment-services`, {
       params: { identifier, language },
       headers: {
         'Cache-Control': 'no-cache, no-store, must-revalidate',
@@ -48,9 +54,9 @@ export class UserServicesService {
     });
   }
 
-  getGenericServices(language: string = 'en'): Observable<UserService[]> {
+  getGenericServices(language: string = 'en'): Observable<UserServicesResponse> {
     // Call backend API for generic services
-    return this.http.get<UserService[]>(`${this.apiUrl}/generic-services`, {
+    return this.http.get<UserServicesResponse>(`${this.apiUrl}/generic-services`, {
       params: { language },
       headers: {
         'Cache-Control': 'no-cache, no-store, must-revalidate',